@@ -0,0 +1,878 @@
+// Package client wraps the manager's v2 API: jobs, their results and the
+// worker endpoints. The worker talks to the manager through it, and so can
+// any automation outside this repository. The API is described at
+// /api/v2/openapi.json.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// Types shared with the manager
+type (
+	Job                = domain.Job
+	JobConfig          = domain.JobConfig
+	JobStatus          = domain.JobStatus
+	JobStats           = domain.JobStats
+	RetryResult        = domain.RetryResult
+	JobImport          = domain.JobImport
+	ImportSection      = domain.ImportSection
+	BoundingBox        = domain.BoundingBox
+	CoverageMode       = domain.CoverageMode
+	KeywordLocation    = domain.KeywordLocation
+	BusinessListing    = domain.BusinessListing
+	ResultBatch        = domain.ResultBatch
+	Worker             = domain.Worker
+	WorkerStatus       = domain.WorkerStatus
+	WorkerStats        = domain.WorkerStats
+	WorkerHeartbeat    = domain.WorkerHeartbeat
+	WorkerDirectives   = domain.WorkerDirectives
+	JobOutput          = domain.JobOutput
+	JobParseReport     = domain.JobParseReport
+	PatchJobRequest    = domain.PatchJobRequest
+	ProxyLease         = domain.ProxyLease
+	LeasedProxy        = domain.LeasedProxy
+	ProxyFailure       = domain.ProxyFailure
+	ProxyFailureReport = domain.ProxyFailureReport
+	QueueStatus        = domain.QueueStatus
+	QueueDepth         = domain.QueueDepth
+	JobEvent           = domain.JobEvent
+	JobLink            = domain.JobLink
+	JobPreview         = domain.JobPreview
+)
+
+// CreateJobRequest is the body of POST /api/v2/jobs. Fields left unset are
+// taken from the template given by TemplateID, then from the manager's
+// defaults.
+type CreateJobRequest struct {
+	Name     string   `json:"name"`
+	Keywords []string `json:"keywords"`
+
+	// InputType "place_urls" scrapes PlaceURLs, Google Maps place URLs or
+	// place IDs, instead of searching for Keywords
+	InputType string   `json:"input_type,omitempty"`
+	PlaceURLs []string `json:"place_urls,omitempty"`
+
+	Lang         string   `json:"lang"`
+	LangFallback []string `json:"lang_fallback,omitempty"`
+	Lat          *float64 `json:"lat,omitempty"`
+	Lon          *float64 `json:"lon,omitempty"`
+	Zoom         int      `json:"zoom"`
+	Radius       int      `json:"radius"`
+	Depth        int      `json:"depth"`
+	FastMode     *bool    `json:"fast_mode"`
+	ExtractEmail *bool    `json:"extract_email"`
+	MaxTime      int      `json:"max_time"` // seconds
+	MaxResults   int      `json:"max_results,omitempty"`
+	Proxies      []string `json:"proxies,omitempty"`
+	ProxyCountry string   `json:"proxy_country,omitempty"`
+	GoogleDomain string   `json:"google_domain,omitempty"` // e.g. google.de, or auto
+	MaxReviews   int      `json:"max_reviews,omitempty"`
+	ReviewsSort  string   `json:"reviews_sort,omitempty"`
+	MaxImages    int      `json:"max_images,omitempty"`
+	Priority     int      `json:"priority"`
+
+	LocationName string       `json:"location_name,omitempty"`
+	BoundingBox  *BoundingBox `json:"boundingbox,omitempty"`
+	CoverageMode CoverageMode `json:"coverage_mode,omitempty"`
+
+	MaxGridPoints int  `json:"max_grid_points,omitempty"`
+	DensityCheck  bool `json:"density_check,omitempty"`
+
+	BrowserProfile string `json:"browser_profile,omitempty"`
+	UserAgent      string `json:"user_agent,omitempty"`
+	AcceptLanguage string `json:"accept_language,omitempty"`
+
+	Incremental  *bool       `json:"incremental,omitempty"`
+	GlobalDedupe *bool       `json:"global_dedupe,omitempty"`
+	Outputs      []JobOutput `json:"outputs,omitempty"`
+	NotifyEmails []string    `json:"notify_emails,omitempty"`
+
+	RetryOnTimeout *bool `json:"retry_on_timeout,omitempty"`
+	CheckWebsite   *bool `json:"check_website,omitempty"`
+
+	BaseKeywords []string          `json:"base_keywords,omitempty"`
+	Locations    []KeywordLocation `json:"locations,omitempty"`
+
+	TemplateID *uuid.UUID `json:"template_id,omitempty"`
+
+	Tags  []string `json:"tags,omitempty"`
+	Notes string   `json:"notes,omitempty"`
+
+	DependsOn *uuid.UUID `json:"depends_on,omitempty"`
+	RunIf     string     `json:"run_if,omitempty"`
+
+	Shards int `json:"shards,omitempty"`
+}
+
+// ListJobsParams filters GET /api/v2/jobs. Zero values use the manager's
+// defaults.
+type ListJobsParams struct {
+	Page           int
+	PerPage        int
+	Status         JobStatus
+	IncludeDeleted bool     // Needs the admin scope
+	Tags           []string // Jobs carrying all of these tags
+}
+
+// JobPage is a page of jobs
+type JobPage struct {
+	Data       []*Job `json:"data"`
+	Total      int    `json:"total"`
+	Page       int    `json:"page"`
+	PerPage    int    `json:"per_page"`
+	TotalPages int    `json:"total_pages"`
+}
+
+// PageMeta describes a page of a listing
+type PageMeta struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// ListingPageMeta describes a page of business listings. Pages listed
+// by cursor have no page number and a zero total.
+type ListingPageMeta struct {
+	PageMeta
+	NextCursor string `json:"next_cursor"` // Empty after the last page
+}
+
+// ListingPage is a page of business listings
+type ListingPage struct {
+	Data []*BusinessListing `json:"data"`
+	Meta ListingPageMeta    `json:"meta"`
+}
+
+// JobEventPage is a page of the audit trail of a job
+type JobEventPage struct {
+	Data []*JobEvent `json:"data"`
+	Meta PageMeta    `json:"meta"`
+}
+
+// RegisterWorkerRequest is the body of POST /api/v2/workers/register
+type RegisterWorkerRequest struct {
+	WorkerID string `json:"worker_id"`
+}
+
+// CompleteJobRequest is the body of POST /api/v2/workers/{id}/complete
+type CompleteJobRequest struct {
+	JobID          uuid.UUID `json:"job_id"`
+	PlacesScraped  int       `json:"places_scraped"`
+	DedupedPlaces  int       `json:"deduped_places,omitempty"` // Places skipped as already scraped
+	FailedKeywords []string  `json:"failed_keywords,omitempty"`
+	StoppedReason  string    `json:"stopped_reason,omitempty"`
+	OutputErrors   []string  `json:"output_errors,omitempty"` // Job outputs that could not be written
+
+	// ParseReport counts the fields the run could not read from Google's
+	// place data
+	ParseReport *JobParseReport `json:"parse_report,omitempty"`
+}
+
+// FailJobRequest is the body of POST /api/v2/workers/{id}/fail
+type FailJobRequest struct {
+	JobID          uuid.UUID `json:"job_id"`
+	Message        string    `json:"message"`
+	ErrorCode      string    `json:"error_code,omitempty"` // proxy_exhausted, blocked_by_google, timeout, ...
+	FailedKeywords []string  `json:"failed_keywords,omitempty"`
+	DedupedPlaces  int       `json:"deduped_places,omitempty"`
+}
+
+// ReleaseJobRequest is the body of POST /api/v2/workers/{id}/release
+type ReleaseJobRequest struct {
+	JobID uuid.UUID `json:"job_id"`
+}
+
+// Error is an error response of the API
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// IsStatus returns true if err is an API error with the given status code
+func IsStatus(err error, code int) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == code
+}
+
+// Client talks to the manager's v2 API
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// New creates a Client for the manager at baseURL. token is the API_TOKEN
+// or an API key, empty when the manager runs without authentication.
+func New(baseURL, token string) *Client {
+	// No overall timeout: downloads stream for as long as they take
+	return NewWithHTTPClient(baseURL, token, &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: 2 * time.Minute,
+			IdleConnTimeout:       90 * time.Second,
+		},
+	})
+}
+
+// NewWithHTTPClient creates a Client that sends its requests with httpClient
+func NewWithHTTPClient(baseURL, token string, httpClient *http.Client) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+// CloseIdleConnections closes the connections kept alive for reuse
+func (c *Client) CloseIdleConnections() {
+	c.httpClient.CloseIdleConnections()
+}
+
+// CreateJob creates a job
+func (c *Client) CreateJob(ctx context.Context, req *CreateJobRequest) (*Job, error) {
+	var job Job
+	if err := c.call(ctx, http.MethodPost, "/api/v2/jobs", req, &job, http.StatusCreated); err != nil {
+		return nil, fmt.Errorf("create job: %w", err)
+	}
+	return &job, nil
+}
+
+// PreviewJob returns the seed jobs, places and runtime req would create
+// without creating the job
+func (c *Client) PreviewJob(ctx context.Context, req *CreateJobRequest) (*JobPreview, error) {
+	var preview JobPreview
+	if err := c.call(ctx, http.MethodPost, "/api/v2/jobs/preview", req, &preview, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("preview job: %w", err)
+	}
+	return &preview, nil
+}
+
+// CloneJob creates a pending copy of a job. Fields set in overrides
+// replace those of the source job; overrides may be nil.
+func (c *Client) CloneJob(ctx context.Context, id uuid.UUID, overrides *CreateJobRequest) (*Job, error) {
+	if overrides == nil {
+		overrides = &CreateJobRequest{}
+	}
+
+	var job Job
+	if err := c.call(ctx, http.MethodPost, "/api/v2/jobs/"+id.String()+"/clone", overrides, &job, http.StatusCreated); err != nil {
+		return nil, fmt.Errorf("clone job: %w", err)
+	}
+	return &job, nil
+}
+
+// BackfillJob creates a pending job that scrapes the places a finished job
+// kept partial. Its results replace the partial ones.
+func (c *Client) BackfillJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	var job Job
+	if err := c.call(ctx, http.MethodPost, "/api/v2/jobs/"+id.String()+"/backfill", nil, &job, http.StatusCreated); err != nil {
+		return nil, fmt.Errorf("backfill job: %w", err)
+	}
+	return &job, nil
+}
+
+// GetJob returns a job. A job that does not exist is an Error with
+// status 404.
+func (c *Client) GetJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	var job Job
+	if err := c.call(ctx, http.MethodGet, "/api/v2/jobs/"+id.String(), nil, &job, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("get job: %w", err)
+	}
+	return &job, nil
+}
+
+// GetParseReport returns, per field, how many places of a job's last
+// completed run could not be read from Google's place data
+func (c *Client) GetParseReport(ctx context.Context, id uuid.UUID) (*JobParseReport, error) {
+	var report JobParseReport
+	if err := c.call(ctx, http.MethodGet, "/api/v2/jobs/"+id.String()+"/parse-report", nil, &report, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("get parse report: %w", err)
+	}
+	return &report, nil
+}
+
+// ArchiveJob writes the archive of a job, its definition and raw results
+// as a tar.gz, to w
+func (c *Client) ArchiveJob(ctx context.Context, id uuid.UUID, w io.Writer) error {
+	resp, err := c.do(ctx, http.MethodGet, "/api/v2/jobs/"+id.String()+"/archive", nil)
+	if err != nil {
+		return fmt.Errorf("archive job: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("archive job: %w", parseError(resp))
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("archive job: %w", err)
+	}
+	return nil
+}
+
+// ImportJob uploads an archive made by ArchiveJob, which the manager stores
+// as a new completed job
+func (c *Client) ImportJob(ctx context.Context, archive io.Reader) (*JobImport, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v2/jobs/import", archive)
+	if err != nil {
+		return nil, fmt.Errorf("import job: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("import job: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("import job: %w", parseError(resp))
+	}
+
+	var imported JobImport
+	if err := json.NewDecoder(resp.Body).Decode(&imported); err != nil {
+		return nil, fmt.Errorf("import job: decode response: %w", err)
+	}
+	return &imported, nil
+}
+
+// ListJobs returns a page of jobs, newest first
+func (c *Client) ListJobs(ctx context.Context, params ListJobsParams) (*JobPage, error) {
+	query := url.Values{}
+	if params.Page > 0 {
+		query.Set("page", strconv.Itoa(params.Page))
+	}
+	if params.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(params.PerPage))
+	}
+	if params.Status != "" {
+		query.Set("status", string(params.Status))
+	}
+	if params.IncludeDeleted {
+		query.Set("include_deleted", "true")
+	}
+	for _, tag := range params.Tags {
+		query.Add("tag", tag)
+	}
+
+	var page JobPage
+	if err := c.call(ctx, http.MethodGet, withQuery("/api/v2/jobs", query), nil, &page, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+	return &page, nil
+}
+
+// PatchJob edits the tags and notes of a job; nil fields are kept
+func (c *Client) PatchJob(ctx context.Context, id uuid.UUID, req *PatchJobRequest) (*Job, error) {
+	var job Job
+	if err := c.call(ctx, http.MethodPatch, "/api/v2/jobs/"+id.String(), req, &job, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("patch job: %w", err)
+	}
+	return &job, nil
+}
+
+// DeleteJob deletes a job; its results are kept until it is purged
+func (c *Client) DeleteJob(ctx context.Context, id uuid.UUID) error {
+	if err := c.call(ctx, http.MethodDelete, "/api/v2/jobs/"+id.String(), nil, nil, http.StatusNoContent); err != nil {
+		return fmt.Errorf("delete job: %w", err)
+	}
+	return nil
+}
+
+// PurgeJob deletes a job and its results for good. It needs the admin
+// scope.
+func (c *Client) PurgeJob(ctx context.Context, id uuid.UUID) error {
+	if err := c.call(ctx, http.MethodDelete, "/api/v2/jobs/"+id.String()+"?hard=true", nil, nil, http.StatusNoContent); err != nil {
+		return fmt.Errorf("purge job: %w", err)
+	}
+	return nil
+}
+
+// RestoreJob restores a deleted job
+func (c *Client) RestoreJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	return c.jobAction(ctx, id, "restore")
+}
+
+// PauseJob pauses a queued or running job
+func (c *Client) PauseJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	return c.jobAction(ctx, id, "pause")
+}
+
+// ResumeJob resumes a paused job
+func (c *Client) ResumeJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	return c.jobAction(ctx, id, "resume")
+}
+
+// CancelJob cancels a job
+func (c *Client) CancelJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	return c.jobAction(ctx, id, "cancel")
+}
+
+// RequeueJob publishes the queue message of a pending job again, for a
+// message that was lost or is stuck
+func (c *Client) RequeueJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	return c.jobAction(ctx, id, "requeue")
+}
+
+// GetQueueStatus returns the depth, consumers and oldest message age of
+// the manager's job queues
+func (c *Client) GetQueueStatus(ctx context.Context) (*QueueStatus, error) {
+	var status QueueStatus
+	if err := c.call(ctx, http.MethodGet, "/api/v2/queue/status", nil, &status, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("get queue status: %w", err)
+	}
+	return &status, nil
+}
+
+// ListJobEvents returns a page of the audit trail of a job, oldest first.
+// Zero page or limit use the manager's defaults.
+func (c *Client) ListJobEvents(ctx context.Context, id uuid.UUID, page, limit int) (*JobEventPage, error) {
+	query := url.Values{}
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var events JobEventPage
+	if err := c.call(ctx, http.MethodGet, withQuery("/api/v2/jobs/"+id.String()+"/events", query), nil, &events, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("list job events: %w", err)
+	}
+	return &events, nil
+}
+
+func (c *Client) jobAction(ctx context.Context, id uuid.UUID, action string) (*Job, error) {
+	var job Job
+	if err := c.call(ctx, http.MethodPost, "/api/v2/jobs/"+id.String()+"/"+action, nil, &job, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("%s job: %w", action, err)
+	}
+	return &job, nil
+}
+
+// RetryFailed runs the failed searches of a job again. maxAttempts 0 uses
+// the manager's default.
+func (c *Client) RetryFailed(ctx context.Context, id uuid.UUID, maxAttempts int) (*RetryResult, error) {
+	query := url.Values{}
+	if maxAttempts > 0 {
+		query.Set("max_attempts", strconv.Itoa(maxAttempts))
+	}
+
+	var result RetryResult
+	if err := c.call(ctx, http.MethodPost, withQuery("/api/v2/jobs/"+id.String()+"/retry-failed", query), nil, &result, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("retry job: %w", err)
+	}
+	return &result, nil
+}
+
+// ShardJob splits the keywords of a pending or waiting job over count
+// shards that workers claim separately. The job returned is their parent,
+// which aggregates them.
+func (c *Client) ShardJob(ctx context.Context, id uuid.UUID, count int) (*Job, error) {
+	query := url.Values{"count": {strconv.Itoa(count)}}
+
+	var job Job
+	if err := c.call(ctx, http.MethodPost, withQuery("/api/v2/jobs/"+id.String()+"/shard", query), nil, &job, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("shard job: %w", err)
+	}
+	return &job, nil
+}
+
+// GetJobConfig returns the config of a job, proxies masked
+func (c *Client) GetJobConfig(ctx context.Context, id uuid.UUID) (*JobConfig, error) {
+	var cfg JobConfig
+	if err := c.call(ctx, http.MethodGet, "/api/v2/jobs/"+id.String()+"/config", nil, &cfg, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("get job config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// EditJobConfig changes the config of a pending or paused job. Fields set
+// in changes replace those of the job; name, priority, tags, notes,
+// depends_on, run_if, shards and template_id must be left out.
+func (c *Client) EditJobConfig(ctx context.Context, id uuid.UUID, changes *CreateJobRequest) (*Job, error) {
+	var job Job
+	if err := c.call(ctx, http.MethodPatch, "/api/v2/jobs/"+id.String()+"/config", changes, &job, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("edit job config: %w", err)
+	}
+	return &job, nil
+}
+
+// ListResults returns a page of the listings of a job. Zero page or limit
+// use the manager's defaults.
+func (c *Client) ListResults(ctx context.Context, jobID uuid.UUID, page, limit int) (*ListingPage, error) {
+	query := url.Values{}
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var listings ListingPage
+	if err := c.call(ctx, http.MethodGet, withQuery("/api/v2/jobs/"+jobID.String()+"/results", query), nil, &listings, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("list results: %w", err)
+	}
+	return &listings, nil
+}
+
+// ListResultsAfter returns the page of a job's listings after cursor, the
+// NextCursor of the previous page, newest first. An empty cursor starts at
+// the newest listing. Unlike ListResults, pages do not shift while results
+// are still coming in.
+func (c *Client) ListResultsAfter(ctx context.Context, jobID uuid.UUID, cursor string, limit int) (*ListingPage, error) {
+	query := url.Values{}
+	query.Set("cursor", cursor)
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var listings ListingPage
+	if err := c.call(ctx, http.MethodGet, withQuery("/api/v2/jobs/"+jobID.String()+"/results", query), nil, &listings, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("list results: %w", err)
+	}
+	return &listings, nil
+}
+
+// StreamResults calls fn for every listing of a job as the manager streams
+// them, without holding the whole job in memory. An error returned by fn
+// stops the stream and is returned.
+func (c *Client) StreamResults(ctx context.Context, jobID uuid.UUID, fn func(*BusinessListing) error) error {
+	resp, err := c.do(ctx, http.MethodGet, "/api/v2/jobs/"+jobID.String()+"/download?format=json", nil)
+	if err != nil {
+		return fmt.Errorf("stream results: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("stream results: %w", parseError(resp))
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("stream results: %w", err)
+	}
+	for dec.More() {
+		var listing BusinessListing
+		if err := dec.Decode(&listing); err != nil {
+			return fmt.Errorf("stream results: %w", err)
+		}
+		if err := fn(&listing); err != nil {
+			return err
+		}
+	}
+	// The closing bracket is only sent once every listing was, so a
+	// stream the manager cut short fails here
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("stream results: %w", err)
+	}
+
+	return nil
+}
+
+// SubmitResults sends one batch of scraped results. A batch the manager
+// already stored is not stored again.
+func (c *Client) SubmitResults(ctx context.Context, batch ResultBatch) error {
+	path := "/api/v2/jobs/" + batch.JobID.String() + "/results"
+	if err := c.call(ctx, http.MethodPost, path, batch, nil, http.StatusCreated, http.StatusOK, http.StatusNoContent); err != nil {
+		return fmt.Errorf("submit results: %w", err)
+	}
+	return nil
+}
+
+// RegisterWorker registers a worker
+func (c *Client) RegisterWorker(ctx context.Context, workerID string) (*Worker, error) {
+	var worker Worker
+	if err := c.call(ctx, http.MethodPost, "/api/v2/workers/register", RegisterWorkerRequest{WorkerID: workerID}, &worker, http.StatusCreated); err != nil {
+		return nil, fmt.Errorf("register worker: %w", err)
+	}
+	return &worker, nil
+}
+
+// Heartbeat reports a worker's status and returns what the manager asks of
+// it, nil for nothing
+func (c *Client) Heartbeat(ctx context.Context, hb WorkerHeartbeat) (*WorkerDirectives, error) {
+	resp, err := c.do(ctx, http.MethodPost, "/api/v2/workers/heartbeat", hb)
+	if err != nil {
+		return nil, fmt.Errorf("send heartbeat: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil, nil
+	case http.StatusOK:
+		var directives WorkerDirectives
+		if err := json.NewDecoder(resp.Body).Decode(&directives); err != nil {
+			return nil, fmt.Errorf("send heartbeat: decode response: %w", err)
+		}
+		return &directives, nil
+	default:
+		return nil, fmt.Errorf("send heartbeat: %w", parseError(resp))
+	}
+}
+
+// ListWorkers returns the registered workers, all of them for an empty
+// status
+func (c *Client) ListWorkers(ctx context.Context, status WorkerStatus) ([]*Worker, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", string(status))
+	}
+
+	var workers []*Worker
+	if err := c.call(ctx, http.MethodGet, withQuery("/api/v2/workers", query), nil, &workers, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("list workers: %w", err)
+	}
+	return workers, nil
+}
+
+// GetWorker returns a worker
+func (c *Client) GetWorker(ctx context.Context, workerID string) (*Worker, error) {
+	var worker Worker
+	if err := c.call(ctx, http.MethodGet, workerPath(workerID, ""), nil, &worker, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("get worker: %w", err)
+	}
+	return &worker, nil
+}
+
+// UnregisterWorker removes a worker
+func (c *Client) UnregisterWorker(ctx context.Context, workerID string) error {
+	if err := c.call(ctx, http.MethodDelete, workerPath(workerID, ""), nil, nil, http.StatusNoContent); err != nil {
+		return fmt.Errorf("unregister worker: %w", err)
+	}
+	return nil
+}
+
+// DrainWorker takes a worker out of rotation. A zero timeout waits for its
+// job however long it takes.
+func (c *Client) DrainWorker(ctx context.Context, workerID string, timeout time.Duration) (*Worker, error) {
+	query := url.Values{}
+	if timeout > 0 {
+		query.Set("timeout", timeout.String())
+	}
+
+	var worker Worker
+	if err := c.call(ctx, http.MethodPost, withQuery(workerPath(workerID, "drain"), query), nil, &worker, http.StatusAccepted); err != nil {
+		return nil, fmt.Errorf("drain worker: %w", err)
+	}
+	return &worker, nil
+}
+
+// ClaimJob claims the next pending job for a worker, nil when none is
+// pending
+func (c *Client) ClaimJob(ctx context.Context, workerID string) (*Job, error) {
+	var result struct {
+		Job *Job `json:"job"`
+	}
+	if err := c.call(ctx, http.MethodPost, workerPath(workerID, "claim"), nil, &result, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("claim job: %w", err)
+	}
+	return result.Job, nil
+}
+
+// GetWorkerJob returns a job for the worker to run, its proxies unmasked
+func (c *Client) GetWorkerJob(ctx context.Context, workerID string, id uuid.UUID) (*Job, error) {
+	var job Job
+	if err := c.call(ctx, http.MethodGet, workerPath(workerID, "jobs/"+id.String()), nil, &job, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("get worker job: %w", err)
+	}
+	return &job, nil
+}
+
+// ClaimJobWait claims a pending job for a worker like ClaimJob, but asks
+// the manager to hold the request up to wait for a job to turn up. waited
+// reports whether the manager honoured the wait; older ones answer at
+// once, so the caller should then poll on its own.
+func (c *Client) ClaimJobWait(ctx context.Context, workerID string, wait time.Duration) (job *Job, waited bool, err error) {
+	path := workerPath(workerID, "claim") + "?wait=" + url.QueryEscape(wait.String())
+
+	resp, err := c.do(ctx, http.MethodPost, path, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("claim job: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("claim job: %w", parseError(resp))
+	}
+
+	var result struct {
+		Job *Job `json:"job"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, false, fmt.Errorf("claim job: decode response: %w", err)
+	}
+	return result.Job, resp.Header.Get(domain.ClaimWaitHeader) != "", nil
+}
+
+// CompleteJob marks a worker's job completed
+func (c *Client) CompleteJob(ctx context.Context, workerID string, req CompleteJobRequest) error {
+	if err := c.call(ctx, http.MethodPost, workerPath(workerID, "complete"), req, nil, http.StatusNoContent); err != nil {
+		return fmt.Errorf("complete job: %w", err)
+	}
+	return nil
+}
+
+// FailJob marks a worker's job failed
+func (c *Client) FailJob(ctx context.Context, workerID string, req FailJobRequest) error {
+	if err := c.call(ctx, http.MethodPost, workerPath(workerID, "fail"), req, nil, http.StatusNoContent); err != nil {
+		return fmt.Errorf("fail job: %w", err)
+	}
+	return nil
+}
+
+// ReleaseJob hands a worker's job back to pending
+func (c *Client) ReleaseJob(ctx context.Context, workerID string, jobID uuid.UUID) error {
+	if err := c.call(ctx, http.MethodPost, workerPath(workerID, "release"), ReleaseJobRequest{JobID: jobID}, nil, http.StatusNoContent); err != nil {
+		return fmt.Errorf("release job: %w", err)
+	}
+	return nil
+}
+
+// LeaseProxies leases up to count healthy manager proxies to a worker, in
+// country when one is given. The lease replaces the worker's previous one.
+func (c *Client) LeaseProxies(ctx context.Context, workerID, country string, count int) (*ProxyLease, error) {
+	query := url.Values{}
+	query.Set("worker_id", workerID)
+	if country != "" {
+		query.Set("country", country)
+	}
+	if count > 0 {
+		query.Set("count", strconv.Itoa(count))
+	}
+
+	var result struct {
+		Data *ProxyLease `json:"data"`
+	}
+	if err := c.call(ctx, http.MethodGet, withQuery("/api/v2/proxygate/lease", query), nil, &result, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("lease proxies: %w", err)
+	}
+	return result.Data, nil
+}
+
+// ReportProxyFailures reports leased proxies that failed a worker so the
+// manager demotes them
+func (c *Client) ReportProxyFailures(ctx context.Context, report ProxyFailureReport) error {
+	if err := c.call(ctx, http.MethodPost, "/api/v2/proxygate/lease/failures", report, nil, http.StatusOK); err != nil {
+		return fmt.Errorf("report proxy failures: %w", err)
+	}
+	return nil
+}
+
+func workerPath(workerID, action string) string {
+	path := "/api/v2/workers/" + url.PathEscape(workerID)
+	if action != "" {
+		path += "/" + action
+	}
+	return path
+}
+
+func withQuery(path string, query url.Values) string {
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+// call sends a request and decodes the response into out (may be nil).
+// Any status but one of ok is returned as an Error.
+func (c *Client) call(ctx context.Context, method, path string, body, out interface{}, ok ...int) error {
+	resp, err := c.do(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	accepted := false
+	for _, code := range ok {
+		if resp.StatusCode == code {
+			accepted = true
+			break
+		}
+	}
+	if !accepted {
+		return parseError(resp)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
+
+// do sends an authenticated request with body encoded as JSON
+func (c *Client) do(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal body: %w", err)
+		}
+		bodyReader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+// parseError turns an error response into an Error. The jobs and workers
+// API answer with a message, the results API with an error field.
+func parseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	var apiErr struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	msg := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &apiErr); err == nil {
+		if apiErr.Message != "" {
+			msg = apiErr.Message
+		} else if apiErr.Error != "" {
+			msg = apiErr.Error
+		}
+	}
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+
+	return &Error{StatusCode: resp.StatusCode, Message: msg}
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamResults(t *testing.T) {
+	jobID := uuid.New()
+
+	tests := []struct {
+		name      string
+		body      string
+		wantTitle []string
+		wantErr   bool
+	}{
+		{
+			name:      "complete stream",
+			body:      "[\n{\"id\": 1, \"title\": \"A\"},\n{\"id\": 2, \"title\": \"B\"}\n]",
+			wantTitle: []string{"A", "B"},
+		},
+		{
+			name:      "empty job",
+			body:      "[\n\n]",
+			wantTitle: nil,
+		},
+		{
+			name:      "stream cut short",
+			body:      "[\n{\"id\": 1, \"title\": \"A\"},\n",
+			wantTitle: []string{"A"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/v2/jobs/"+jobID.String()+"/download", r.URL.Path)
+				assert.Equal(t, "json", r.URL.Query().Get("format"))
+				assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			var titles []string
+			err := New(srv.URL, "secret").StreamResults(context.Background(), jobID, func(l *BusinessListing) error {
+				titles = append(titles, l.Title)
+				return nil
+			})
+
+			assert.Equal(t, tt.wantTitle, titles)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantMessage string
+	}{
+		{"jobs API message", http.StatusNotFound, `{"code":404,"message":"Job not found"}`, "Job not found"},
+		{"results API error field", http.StatusBadRequest, `{"error":"Invalid job ID format"}`, "Invalid job ID format"},
+		{"plain text", http.StatusBadGateway, "upstream down\n", "upstream down"},
+		{"empty body", http.StatusServiceUnavailable, "", "Service Unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := New(srv.URL, "").GetJob(context.Background(), uuid.New())
+			require.Error(t, err)
+			assert.True(t, IsStatus(err, tt.status))
+
+			var apiErr *Error
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.wantMessage, apiErr.Message)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package deduper
+
+import (
+	"context"
+	"math"
+	"sync"
+)
+
+var _ Deduper = (*bloom)(nil)
+
+// bloom remembers keys as bits of a filter sized up front, a few bytes per
+// expected key whatever their length. A key it saw is never reported new;
+// a new key is reported as seen with about the configured probability
+// once the filter holds the expected number of keys, more past it.
+type bloom struct {
+	mu   sync.Mutex
+	bits []uint64
+	m    uint64 // Bits in the filter
+	k    uint64 // Bits set per key
+}
+
+func newBloom(expected int, fpRate float64) *bloom {
+	n := float64(expected)
+	m := uint64(math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	m = max(m, 64)
+	k := uint64(max(math.Round(float64(m)/n*math.Ln2), 1))
+
+	return &bloom{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+func (b *bloom) AddIfNotExists(_ context.Context, key string) bool {
+	h := hashKey(key)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.add(h)
+}
+
+// add sets the bits of the key hashed to h and reports whether any was
+// unset, that is whether the key is new
+func (b *bloom) add(h uint64) bool {
+	added := false
+
+	h1, h2 := h, splitmix(h)|1
+	for i := uint64(0); i < b.k; i++ {
+		idx := (h1 + i*h2) % b.m
+		word, bit := idx/64, uint64(1)<<(idx%64)
+
+		if b.bits[word]&bit == 0 {
+			b.bits[word] |= bit
+			added = true
+		}
+	}
+
+	return added
+}
+
+// contains reports whether every bit of the key hashed to h is set
+func (b *bloom) contains(h uint64) bool {
+	h1, h2 := h, splitmix(h)|1
+	for i := uint64(0); i < b.k; i++ {
+		idx := (h1 + i*h2) % b.m
+		if b.bits[idx/64]&(uint64(1)<<(idx%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hashKey is FNV-1a over key, without the allocations of hash/fnv
+func hashKey(key string) uint64 {
+	const (
+		offset = 14695981039346656037
+		prime  = 1099511628211
+	)
+
+	h := uint64(offset)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= prime
+	}
+
+	return h
+}
+
+// splitmix derives the second hash of the double hashing the filter uses
+// for its k bits
+func splitmix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+
+	return x ^ (x >> 31)
+}
@@ -2,6 +2,8 @@ package deduper
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 )
 
@@ -15,3 +17,92 @@ func New() Deduper {
 		mux:  &sync.RWMutex{},
 	}
 }
+
+// Backends a Deduper is built on. The redis backend lives with the Redis
+// client, in internal/queue, so NewFromConfig leaves it to the caller.
+const (
+	BackendMap    = "map"    // Exact, every key in memory
+	BackendBloom  = "bloom"  // Fixed memory, some new keys reported as seen
+	BackendTiered = "tiered" // Bloom filter behind an exact set of recent keys
+	BackendRedis  = "redis"  // Shared by all processes using the same Redis
+)
+
+// Defaults of the bloom filter backends
+const (
+	DefaultExpectedItems     = 10_000_000
+	DefaultFalsePositiveRate = 0.001
+	DefaultRecentItems       = 100_000
+)
+
+// ErrSharedBackend is returned by NewFromConfig for the redis backend
+var ErrSharedBackend = errors.New("the redis deduper is built from the Redis settings")
+
+// Config selects and sizes a Deduper. Zero values take the defaults.
+type Config struct {
+	Backend string // Empty leaves the choice to the run mode
+
+	// ExpectedItems and FalsePositiveRate size the bloom filter: once it
+	// holds ExpectedItems keys, about FalsePositiveRate of new keys are
+	// reported as seen
+	ExpectedItems     int
+	FalsePositiveRate float64
+
+	// RecentItems is how many of the latest keys the tiered backend holds
+	// exactly in front of its bloom filter
+	RecentItems int
+}
+
+// Validate checks the backend and the sizes of the filter
+func (c Config) Validate() error {
+	switch c.Backend {
+	case "", BackendMap, BackendBloom, BackendTiered, BackendRedis:
+	default:
+		return fmt.Errorf("unknown deduper %q, use map, bloom, tiered or redis", c.Backend)
+	}
+
+	if c.ExpectedItems < 0 {
+		return fmt.Errorf("deduper expected items must not be negative")
+	}
+	if c.FalsePositiveRate < 0 || c.FalsePositiveRate >= 1 {
+		return fmt.Errorf("deduper false positive rate must be between 0 and 1")
+	}
+	if c.RecentItems < 0 {
+		return fmt.Errorf("deduper recent items must not be negative")
+	}
+
+	return nil
+}
+
+// NewFromConfig builds the in-process Deduper of cfg, the map for an empty
+// backend
+func NewFromConfig(cfg Config) (Deduper, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	expected := cfg.ExpectedItems
+	if expected == 0 {
+		expected = DefaultExpectedItems
+	}
+
+	fpRate := cfg.FalsePositiveRate
+	if fpRate == 0 {
+		fpRate = DefaultFalsePositiveRate
+	}
+
+	switch cfg.Backend {
+	case BackendBloom:
+		return newBloom(expected, fpRate), nil
+	case BackendTiered:
+		recent := cfg.RecentItems
+		if recent == 0 {
+			recent = DefaultRecentItems
+		}
+
+		return newTiered(expected, fpRate, recent), nil
+	case BackendRedis:
+		return nil, ErrSharedBackend
+	default:
+		return New(), nil
+	}
+}
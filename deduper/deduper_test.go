@@ -0,0 +1,123 @@
+package deduper
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func placeKey(i int) string {
+	return "https://www.google.com/maps/place/data=!4m7!3m6!1s0x" + strconv.Itoa(i)
+}
+
+// falsePositives adds n keys to d, checks they are all reported as seen,
+// and returns the share of n other keys reported as seen too. The other
+// keys are added as well, so d ends up holding 2n keys.
+func falsePositives(t *testing.T, d Deduper, n int) float64 {
+	ctx := context.Background()
+
+	for i := 0; i < n; i++ {
+		d.AddIfNotExists(ctx, placeKey(i))
+	}
+	for i := 0; i < n; i++ {
+		require.False(t, d.AddIfNotExists(ctx, placeKey(i)), "key %d seen before", i)
+	}
+
+	fp := 0
+	for i := n; i < 2*n; i++ {
+		if !d.AddIfNotExists(ctx, placeKey(i)) {
+			fp++
+		}
+	}
+
+	return float64(fp) / float64(n)
+}
+
+func TestBloomFalsePositiveRate(t *testing.T) {
+	d, err := NewFromConfig(Config{Backend: BackendBloom, ExpectedItems: 200_000, FalsePositiveRate: 0.01})
+	require.NoError(t, err)
+	assert.Less(t, falsePositives(t, d, 100_000), 0.01)
+
+	// Past the expected keys the rate climbs
+	d, err = NewFromConfig(Config{Backend: BackendBloom, ExpectedItems: 100_000, FalsePositiveRate: 0.01})
+	require.NoError(t, err)
+	assert.Greater(t, falsePositives(t, d, 100_000), 0.01)
+}
+
+func TestTieredRecentKeysAreExact(t *testing.T) {
+	d, err := NewFromConfig(Config{Backend: BackendTiered, ExpectedItems: 1000, FalsePositiveRate: 0.01, RecentItems: 1000})
+	require.NoError(t, err)
+
+	assert.Zero(t, falsePositives(t, d, 500), "all keys fit in the recent set")
+
+	d, err = NewFromConfig(Config{Backend: BackendTiered, ExpectedItems: 200_000, FalsePositiveRate: 0.01, RecentItems: 10_000})
+	require.NoError(t, err)
+	assert.Less(t, falsePositives(t, d, 100_000), 0.01)
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Backend: BackendRedis}.Validate())
+	assert.Error(t, Config{Backend: "lru"}.Validate())
+	assert.Error(t, Config{Backend: BackendBloom, FalsePositiveRate: 1}.Validate())
+
+	_, err := NewFromConfig(Config{Backend: BackendRedis})
+	assert.ErrorIs(t, err, ErrSharedBackend)
+}
+
+// benchmarkDeduper fills a deduper with n keys, reports the heap it took,
+// then measures adding keys, half of them seen before
+func benchmarkDeduper(b *testing.B, cfg Config, n int) {
+	ctx := context.Background()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	d, err := NewFromConfig(cfg)
+	require.NoError(b, err)
+	for i := 0; i < n; i++ {
+		d.AddIfNotExists(ctx, placeKey(i))
+	}
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.AddIfNotExists(ctx, placeKey(n/2+i))
+	}
+	b.StopTimer()
+
+	// Reported after ResetTimer, which drops metrics
+	b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/(1<<20), "MB")
+	runtime.KeepAlive(d)
+}
+
+func BenchmarkMap1M(b *testing.B) {
+	benchmarkDeduper(b, Config{Backend: BackendMap}, 1_000_000)
+}
+
+func BenchmarkBloom1M(b *testing.B) {
+	benchmarkDeduper(b, Config{Backend: BackendBloom, ExpectedItems: 1_000_000}, 1_000_000)
+}
+
+func BenchmarkTiered1M(b *testing.B) {
+	benchmarkDeduper(b, Config{Backend: BackendTiered, ExpectedItems: 1_000_000}, 1_000_000)
+}
+
+func BenchmarkMap10M(b *testing.B) {
+	benchmarkDeduper(b, Config{Backend: BackendMap}, 10_000_000)
+}
+
+func BenchmarkBloom10M(b *testing.B) {
+	benchmarkDeduper(b, Config{Backend: BackendBloom}, 10_000_000)
+}
+
+func BenchmarkTiered10M(b *testing.B) {
+	benchmarkDeduper(b, Config{Backend: BackendTiered}, 10_000_000)
+}
@@ -0,0 +1,60 @@
+package deduper
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+var _ Deduper = (*tiered)(nil)
+
+// tiered holds the latest keys exactly, least recently seen first out, in
+// front of a bloom filter. Keys only enter the filter when they leave the
+// recent set, so the filter holds fewer keys than a plain one and a new key
+// is never mistaken for one of the recent keys: places repeated across
+// nearby searches are told apart exactly.
+type tiered struct {
+	mu     sync.Mutex
+	filter *bloom
+	size   int
+	order  *list.List // Key hashes, most recently seen first
+	recent map[uint64]*list.Element
+}
+
+func newTiered(expected int, fpRate float64, recent int) *tiered {
+	return &tiered{
+		filter: newBloom(expected, fpRate),
+		size:   recent,
+		order:  list.New(),
+		recent: make(map[uint64]*list.Element, recent),
+	}
+}
+
+func (t *tiered) AddIfNotExists(_ context.Context, key string) bool {
+	h := hashKey(key)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if e, ok := t.recent[h]; ok {
+		t.order.MoveToFront(e)
+		return false
+	}
+
+	if t.filter.contains(h) {
+		return false
+	}
+
+	t.recent[h] = t.order.PushFront(h)
+
+	if t.order.Len() > t.size {
+		oldest := t.order.Back()
+		t.order.Remove(oldest)
+
+		old := oldest.Value.(uint64)
+		delete(t.recent, old)
+		t.filter.add(old)
+	}
+
+	return true
+}
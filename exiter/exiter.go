@@ -8,18 +8,51 @@ import (
 
 type Exiter interface {
 	SetSeedCount(int)
+	SetMaxResults(int)
 	SetCancelFunc(context.CancelFunc)
 	IncrSeedCompleted(int)
 	IncrPlacesFound(int)
 	IncrPlacesCompleted(int)
+	IncrPlacesPartial(int)
+	IncrBlocked(int)
+	Blocked() int
+	Reason() Reason
+	Progress() Progress
 	Run(context.Context)
 }
 
+// Progress is how far the scrape got
+type Progress struct {
+	SeedCount       int
+	SeedCompleted   int
+	PlacesCompleted int
+	PlacesPartial   int // Kept from the search results, their page failed
+}
+
+// Reason tells why the exiter cancelled the scrape
+type Reason string
+
+const (
+	ReasonNone       Reason = ""
+	ReasonExhausted  Reason = "exhausted"
+	ReasonBlocked    Reason = "blocked"
+	ReasonMaxResults Reason = "max_results"
+)
+
+// maxConsecutiveBlocks is the number of block signals without any
+// completed seed or place in between after which the scrape is stopped.
+const maxConsecutiveBlocks = 10
+
 type exiter struct {
 	seedCount       int
 	seedCompleted   int
 	placesFound     int
 	placesCompleted int
+	placesPartial   int
+	maxResults      int
+	blocked         int
+	blockStreak     int
+	reason          Reason
 
 	mu         *sync.Mutex
 	cancelFunc context.CancelFunc
@@ -38,6 +71,15 @@ func (e *exiter) SetSeedCount(val int) {
 	e.seedCount = val
 }
 
+// SetMaxResults cancels the scrape as soon as val places were completed,
+// without waiting for the next tick (0 means no cap)
+func (e *exiter) SetMaxResults(val int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.maxResults = val
+}
+
 func (e *exiter) SetCancelFunc(fn context.CancelFunc) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -50,6 +92,7 @@ func (e *exiter) IncrSeedCompleted(val int) {
 	defer e.mu.Unlock()
 
 	e.seedCompleted += val
+	e.blockStreak = 0
 }
 
 func (e *exiter) IncrPlacesFound(val int) {
@@ -64,6 +107,64 @@ func (e *exiter) IncrPlacesCompleted(val int) {
 	defer e.mu.Unlock()
 
 	e.placesCompleted += val
+	e.blockStreak = 0
+
+	if e.reason == ReasonNone && e.reachedMaxResults() {
+		e.reason = ReasonMaxResults
+
+		if e.cancelFunc != nil {
+			e.cancelFunc()
+		}
+	}
+}
+
+// IncrPlacesPartial counts places whose page failed and that were kept with
+// what the search results listed. They end the place like a completion but
+// do not count towards the max results, nor reset the block streak.
+func (e *exiter) IncrPlacesPartial(val int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.placesPartial += val
+}
+
+// reachedMaxResults must be called with mu held
+func (e *exiter) reachedMaxResults() bool {
+	return e.maxResults > 0 && e.placesCompleted >= e.maxResults
+}
+
+func (e *exiter) IncrBlocked(val int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.blocked += val
+	e.blockStreak += val
+}
+
+func (e *exiter) Blocked() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.blocked
+}
+
+func (e *exiter) Reason() Reason {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.reason
+}
+
+func (e *exiter) Progress() Progress {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return Progress{
+		SeedCount:       e.seedCount,
+		SeedCompleted:   e.seedCompleted,
+		PlacesCompleted: e.placesCompleted,
+		PlacesPartial:   e.placesPartial,
+	}
 }
 
 func (e *exiter) Run(ctx context.Context) {
@@ -88,13 +189,27 @@ func (e *exiter) isDone() bool {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	if e.reachedMaxResults() {
+		e.reason = ReasonMaxResults
+
+		return true
+	}
+
+	if e.blockStreak >= maxConsecutiveBlocks {
+		e.reason = ReasonBlocked
+
+		return true
+	}
+
 	if e.seedCompleted != e.seedCount {
 		return false
 	}
 
-	if e.placesFound != e.placesCompleted {
+	if e.placesFound != e.placesCompleted+e.placesPartial {
 		return false
 	}
 
+	e.reason = ReasonExhausted
+
 	return true
 }
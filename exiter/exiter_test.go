@@ -0,0 +1,161 @@
+package exiter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keyword is one seed search: the places it finds, of which the first
+// completed are scraped before the next keyword starts
+type keyword struct {
+	found     int
+	completed int
+}
+
+// newCounted returns an exiter for seeds searches whose cancel func counts
+// its calls
+func newCounted(seeds, maxResults int) (*exiter, *int) {
+	e := New().(*exiter)
+	e.SetSeedCount(seeds)
+	e.SetMaxResults(maxResults)
+
+	cancels := 0
+	e.SetCancelFunc(func() { cancels++ })
+
+	return e, &cancels
+}
+
+// run feeds the keywords to e one place at a time and returns the places
+// completed when the scrape was cancelled, or -1
+func run(e *exiter, cancels *int, keywords []keyword) int {
+	completedAt := -1
+
+	for _, kw := range keywords {
+		e.IncrPlacesFound(kw.found)
+		for range kw.completed {
+			e.IncrPlacesCompleted(1)
+			if *cancels > 0 && completedAt < 0 {
+				completedAt = e.placesCompleted
+			}
+		}
+		if kw.completed == kw.found {
+			e.IncrSeedCompleted(1)
+		}
+	}
+
+	return completedAt
+}
+
+func TestMaxResultsAcrossKeywords(t *testing.T) {
+	e, cancels := newCounted(3, 10)
+
+	// The first two keywords are exhausted below the cap, the third
+	// reaches it; places in flight still complete afterwards
+	completedAt := run(e, cancels, []keyword{
+		{found: 3, completed: 3},
+		{found: 4, completed: 4},
+		{found: 20, completed: 5},
+	})
+
+	assert.Equal(t, 10, completedAt)
+	assert.Equal(t, 1, *cancels)
+	assert.Equal(t, ReasonMaxResults, e.Reason())
+	assert.True(t, e.isDone())
+	assert.Equal(t, ReasonMaxResults, e.Reason())
+}
+
+func TestKeywordsExhaustedBeforeMaxResults(t *testing.T) {
+	e, cancels := newCounted(3, 10)
+
+	completedAt := run(e, cancels, []keyword{
+		{found: 3, completed: 3},
+		{found: 0, completed: 0},
+		{found: 4, completed: 4},
+	})
+
+	assert.Equal(t, -1, completedAt)
+	assert.Zero(t, *cancels)
+	require.True(t, e.isDone())
+	assert.Equal(t, ReasonExhausted, e.Reason())
+}
+
+func TestMaxResultsReachedByLastPlace(t *testing.T) {
+	e, cancels := newCounted(2, 7)
+
+	completedAt := run(e, cancels, []keyword{
+		{found: 3, completed: 3},
+		{found: 4, completed: 4},
+	})
+
+	assert.Equal(t, 7, completedAt)
+	assert.Equal(t, ReasonMaxResults, e.Reason())
+	assert.True(t, e.isDone())
+	assert.Equal(t, ReasonMaxResults, e.Reason())
+}
+
+func TestMaxResultsWhileKeywordsPending(t *testing.T) {
+	e, cancels := newCounted(3, 5)
+
+	// The cap is reached by the first keyword, the others never finish
+	completedAt := run(e, cancels, []keyword{
+		{found: 8, completed: 6},
+	})
+
+	assert.Equal(t, 5, completedAt)
+	assert.Equal(t, 1, *cancels)
+	assert.True(t, e.isDone())
+	assert.Equal(t, ReasonMaxResults, e.Reason())
+}
+
+func TestNoMaxResults(t *testing.T) {
+	e, cancels := newCounted(2, 0)
+
+	assert.False(t, e.isDone())
+
+	completedAt := run(e, cancels, []keyword{
+		{found: 300, completed: 300},
+		{found: 200, completed: 200},
+	})
+
+	assert.Equal(t, -1, completedAt)
+	require.True(t, e.isDone())
+	assert.Equal(t, ReasonExhausted, e.Reason())
+}
+
+func TestBlockedBeforeMaxResults(t *testing.T) {
+	e, cancels := newCounted(2, 10)
+
+	run(e, cancels, []keyword{{found: 5, completed: 2}})
+	e.IncrBlocked(maxConsecutiveBlocks)
+
+	require.True(t, e.isDone())
+	assert.Equal(t, ReasonBlocked, e.Reason())
+	assert.Zero(t, *cancels)
+}
+
+func TestProgress(t *testing.T) {
+	e, cancels := newCounted(3, 0)
+
+	run(e, cancels, []keyword{
+		{found: 20, completed: 20},
+		{found: 10, completed: 4},
+	})
+
+	assert.Equal(t, Progress{SeedCount: 3, SeedCompleted: 1, PlacesCompleted: 24}, e.Progress())
+}
+
+func TestPartialPlacesEndTheScrape(t *testing.T) {
+	e, cancels := newCounted(1, 3)
+
+	e.IncrPlacesFound(4)
+	e.IncrPlacesCompleted(2)
+	e.IncrPlacesPartial(2)
+	e.IncrSeedCompleted(1)
+
+	require.True(t, e.isDone())
+	assert.Equal(t, ReasonExhausted, e.Reason(), "partial places do not count towards max results")
+	assert.Zero(t, *cancels)
+	assert.Equal(t, Progress{SeedCount: 1, SeedCompleted: 1, PlacesCompleted: 2, PlacesPartial: 2}, e.Progress())
+}
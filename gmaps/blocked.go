@@ -0,0 +1,110 @@
+package gmaps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/sadewadee/google-scraper/exiter"
+	"github.com/sadewadee/google-scraper/ratelimit"
+)
+
+// BlockedError is returned when Google answers with a captcha, a /sorry/
+// page or an unusual-traffic notice instead of Maps content.
+type BlockedError struct {
+	URL    string
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("blocked by google (%s): %s", e.Reason, e.URL)
+}
+
+// IsBlocked reports whether err is or wraps a BlockedError
+func IsBlocked(err error) bool {
+	var blockedErr *BlockedError
+
+	return errors.As(err, &blockedErr)
+}
+
+var blockMarkers = []struct {
+	marker string
+	reason string
+}{
+	{"unusual traffic from your computer network", "unusual traffic"},
+	{"g-recaptcha", "captcha"},
+	{`id="captcha-form"`, "captcha"},
+	{"recaptcha/api.js", "captcha"},
+}
+
+// detectBlock inspects the final URL, status code and (optionally) body
+// of a response. It returns nil when nothing looks like a block page.
+func detectBlock(pageURL string, statusCode int, body string) *BlockedError {
+	if strings.Contains(pageURL, "/sorry/") {
+		return &BlockedError{URL: pageURL, Reason: "sorry page"}
+	}
+
+	if statusCode == http.StatusTooManyRequests {
+		return &BlockedError{URL: pageURL, Reason: "too many requests"}
+	}
+
+	if body == "" {
+		return nil
+	}
+
+	lower := strings.ToLower(body)
+	for _, m := range blockMarkers {
+		if strings.Contains(lower, m.marker) {
+			return &BlockedError{URL: pageURL, Reason: m.reason}
+		}
+	}
+
+	return nil
+}
+
+// detectBlockOnPage checks the page currently loaded in the browser
+func detectBlockOnPage(page scrapemate.BrowserPage, statusCode int) *BlockedError {
+	if blocked := detectBlock(page.URL(), statusCode, ""); blocked != nil {
+		return blocked
+	}
+
+	body, err := page.Content()
+	if err != nil {
+		return nil
+	}
+
+	return detectBlock(page.URL(), statusCode, body)
+}
+
+// waitForSlot paces a request through the shared rate limiter, if any
+func waitForSlot(ctx context.Context, limiter ratelimit.Limiter) error {
+	if limiter == nil {
+		return nil
+	}
+
+	return limiter.Wait(ctx)
+}
+
+// reportBlocked slows down the limiter and tells the exit monitor about the
+// block before handing the error back to scrapemate.
+func reportBlocked(limiter ratelimit.Limiter, exitMonitor exiter.Exiter, blockedErr *BlockedError) error {
+	if limiter != nil {
+		limiter.OnBlock()
+	}
+
+	if exitMonitor != nil {
+		exitMonitor.IncrBlocked(1)
+	}
+
+	return blockedErr
+}
+
+func reportSuccess(limiter ratelimit.Limiter) {
+	if limiter != nil {
+		limiter.OnSuccess()
+	}
+}
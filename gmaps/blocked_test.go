@@ -0,0 +1,78 @@
+package gmaps
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/ratelimit"
+)
+
+func TestDetectBlock(t *testing.T) {
+	const mapsURL = "https://www.google.com/maps/search/pizza"
+
+	tests := []struct {
+		name       string
+		url        string
+		statusCode int
+		body       string
+		reason     string // empty when the page is not blocked
+	}{
+		{"maps page", mapsURL, http.StatusOK, "<html>Pizza Place</html>", ""},
+		{"empty body", mapsURL, http.StatusOK, "", ""},
+		{"sorry page", "https://www.google.com/sorry/index?continue=x", http.StatusOK, "", "sorry page"},
+		{"too many requests", mapsURL, http.StatusTooManyRequests, "", "too many requests"},
+		{"unusual traffic", mapsURL, http.StatusOK, "Our systems have detected Unusual Traffic from your computer network.", "unusual traffic"},
+		{"recaptcha widget", mapsURL, http.StatusOK, `<div class="g-recaptcha"></div>`, "captcha"},
+		{"captcha form", mapsURL, http.StatusOK, `<form ID="captcha-form">`, "captcha"},
+		{"recaptcha script", mapsURL, http.StatusOK, `<script src="https://www.google.com/recaptcha/api.js"></script>`, "captcha"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocked := detectBlock(tt.url, tt.statusCode, tt.body)
+			if tt.reason == "" {
+				assert.Nil(t, blocked)
+				return
+			}
+
+			require.NotNil(t, blocked)
+			assert.Equal(t, tt.reason, blocked.Reason)
+			assert.Equal(t, tt.url, blocked.URL)
+		})
+	}
+}
+
+func TestIsBlocked(t *testing.T) {
+	blocked := &BlockedError{URL: "https://www.google.com/sorry/", Reason: "sorry page"}
+
+	assert.True(t, IsBlocked(blocked))
+	assert.True(t, IsBlocked(fmt.Errorf("search failed: %w", blocked)))
+	assert.False(t, IsBlocked(fmt.Errorf("search failed")))
+	assert.False(t, IsBlocked(nil))
+}
+
+func TestSearchJobBlockBackoffEndsWithContext(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{MaxRate: 0.1, MinRate: 0.01})
+
+	job := NewSearchJob(&MapSearchParams{Query: "pizza"}, WithSearchJobRateLimiter(limiter))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	job.SetContext(ctx)
+
+	// The limiter's delay after the block is 20s, the cancelled context
+	// must cut it short
+	start := time.Now()
+	ok := job.DoCheckResponse(&scrapemate.Response{URL: "https://www.google.com/sorry/index", StatusCode: http.StatusOK})
+
+	assert.False(t, ok)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, int64(1), limiter.Blocks())
+}
@@ -0,0 +1,80 @@
+package gmaps
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultDomain is the Google domain searched unless a job picks another
+const DefaultDomain = "google.com"
+
+// consentRejectTexts is a JavaScript array of the lowercased labels of the
+// reject button on Google's cookie consent page, in the languages it shows
+// on the country domains
+const consentRejectTexts = `[
+	'reject', 'decline', 'ablehnen', 'refuser', 'rechazar', 'rifiuta',
+	'rejeitar', 'recusar', 'weigeren', 'odrzuć', 'odmítnout', 'elutasít',
+	'respinge', 'avvisa', 'afvis', 'avvis', 'hylkää', 'απόρριψη', 'reddet',
+	'відхилити', 'отклонить', 'tolak', 'từ chối', 'ปฏิเสธ', '拒否', '거부', '拒绝', '拒絕'
+]`
+
+// withDomain moves a Google URL to domain, keeping its subdomain, so
+// www.google.com becomes www.google.de and maps.google.com maps.google.de.
+// Other URLs and an empty domain leave rawURL as is.
+func withDomain(rawURL, domain string) string {
+	if domain == "" {
+		return rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	sub, ok := googleSubdomain(u.Hostname())
+	if !ok {
+		return rawURL
+	}
+
+	u.Host = sub + domain
+	return u.String()
+}
+
+// URLDomain returns the Google domain of a Google URL, like google.de for
+// https://www.google.de/maps/place/..., and DefaultDomain for others
+func URLDomain(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return DefaultDomain
+	}
+
+	host := u.Hostname()
+	sub, ok := googleSubdomain(host)
+	if !ok {
+		return DefaultDomain
+	}
+
+	return strings.TrimPrefix(host, sub)
+}
+
+// googleSubdomain splits the www. or maps. off a Google host and tells
+// whether the rest is a Google domain
+func googleSubdomain(host string) (string, bool) {
+	for _, sub := range []string{"www.", "maps.", ""} {
+		if strings.HasPrefix(host, sub+"google.") {
+			return sub, true
+		}
+	}
+
+	return "", false
+}
+
+// SetDomain moves the search to the Google domain d, e.g. google.de
+func (j *GmapJob) SetDomain(d string) {
+	j.URL = withDomain(j.URL, d)
+}
+
+// SetDomain moves the search to the Google domain d, e.g. google.de
+func (j *SearchJob) SetDomain(d string) {
+	j.URL = withDomain(j.URL, d)
+}
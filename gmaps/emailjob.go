@@ -2,6 +2,7 @@ package gmaps
 
 import (
 	"context"
+	"net/url"
 	"regexp"
 	"strings"
 
@@ -21,6 +22,8 @@ type EmailExtractJob struct {
 	Entry       *Entry
 	ExitMonitor exiter.Exiter
 	Validator   emailvalidator.Validator
+	PlaceURL    string // URL of the place job that queued this one
+	Pages       int    // Contact, imprint and about pages fetched besides the website
 }
 
 func NewEmailJob(parentID string, entry *Entry, opts ...EmailExtractJobOptions) *EmailExtractJob {
@@ -55,12 +58,27 @@ func WithEmailJobExitMonitor(exitMonitor exiter.Exiter) EmailExtractJobOptions {
 	}
 }
 
+// WithEmailJobPlaceURL records the place the email job belongs to
+func WithEmailJobPlaceURL(placeURL string) EmailExtractJobOptions {
+	return func(j *EmailExtractJob) {
+		j.PlaceURL = placeURL
+	}
+}
+
 func WithEmailValidatorOption(validator emailvalidator.Validator) EmailExtractJobOptions {
 	return func(j *EmailExtractJob) {
 		j.Validator = validator
 	}
 }
 
+// WithEmailJobPages sets how many contact, imprint and about pages of the
+// website are searched for emails too
+func WithEmailJobPages(pages int) EmailExtractJobOptions {
+	return func(j *EmailExtractJob) {
+		j.Pages = pages
+	}
+}
+
 func (j *EmailExtractJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
@@ -87,11 +105,43 @@ func (j *EmailExtractJob) Process(ctx context.Context, resp *scrapemate.Response
 		return j.Entry, nil, nil
 	}
 
+	// The website is already downloaded, so pick up the other contact
+	// details it links to before looking for emails
+	j.Entry.SocialLinks = extractSocialLinks(doc)
+	j.Entry.WebsitePhone = extractTelPhone(doc)
+	j.Entry.WebsiteDescription = extractMetaDescription(doc)
+
 	emails := docEmailExtractor(doc)
 	if len(emails) == 0 {
 		emails = regexEmailExtractor(resp.Body)
 	}
 
+	sources := addEmailSources(nil, j.URL, emails)
+
+	// Most businesses list their email on a contact or imprint page rather
+	// than the home page
+	if j.Pages > 0 {
+		siteURL := resp.URL
+		if siteURL == "" {
+			siteURL = j.URL
+		}
+
+		if base, err := url.Parse(siteURL); err == nil {
+			if links := emailPageLinks(doc, base, j.Pages); len(links) > 0 {
+				pages := fetchEmailPages(ctx, emailPageClient, base, links, emailSiteMaxBytes-int64(len(resp.Body)))
+				for _, page := range pages {
+					sources = addEmailSources(sources, page.url, page.emails)
+				}
+				log.Info("Searched contact pages for emails", "url", j.URL, "pages", len(links), "emails", len(sources))
+			}
+		}
+	}
+
+	emails = make([]string, 0, len(sources))
+	for _, source := range sources {
+		emails = append(emails, source.Email)
+	}
+
 	// Filter out placeholder/protected emails
 	emails = filterInvalidEmails(emails)
 
@@ -142,6 +192,7 @@ func (j *EmailExtractJob) Process(ctx context.Context, resp *scrapemate.Response
 	}
 
 	j.Entry.Emails = emails
+	j.Entry.EmailSources = emailSourcesOf(sources, emails)
 
 	return j.Entry, nil, nil
 }
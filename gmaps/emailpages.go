@@ -0,0 +1,359 @@
+package gmaps
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultEmailPages is how many contact, imprint and about pages the email
+// job fetches besides the website
+const DefaultEmailPages = 3
+
+const (
+	// emailSiteMaxBytes and emailSiteTimeout bound what one site costs,
+	// the website included
+	emailSiteMaxBytes = 2 << 20
+	emailSiteTimeout  = 20 * time.Second
+
+	// emailPageParallelism caps the pages of one site fetched at once
+	emailPageParallelism = 2
+
+	emailPageUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:128.0) Gecko/20100101 Firefox/128.0"
+)
+
+// emailPageClient fetches robots.txt and the extra pages. Redirects may
+// leave the site, the pages are only read.
+var emailPageClient = &http.Client{Timeout: emailSiteTimeout}
+
+// emailPageWords are the path words of pages that list contact details, in
+// many languages. Contact pages come first, then imprints, which list an
+// email by law in some countries, then about pages.
+var emailPageWords = [][]string{
+	{
+		"contact", "kontakt", "contacto", "contato", "contatti", "contatto",
+		"contactez", "kontakty", "iletisim", "hubungi", "lien-he", "lienhe",
+		"yhteystiedot", "kapcsolat", "epikoinonia", "kontaktai",
+	},
+	{
+		"impressum", "imprint", "mentions-legales", "aviso-legal",
+		"colofon", "legal-notice", "note-legali",
+	},
+	{
+		"about", "ueber-uns", "uber-uns", "über-uns", "a-propos", "apropos",
+		"quienes-somos", "sobre-nos", "sobre-nosotros", "chi-siamo",
+		"over-ons", "om-oss", "om-os", "o-nas", "tentang", "hakkimizda",
+		"company", "team",
+	},
+}
+
+// EmailSource is the page an email was found on
+type EmailSource struct {
+	Email string `json:"email"`
+	URL   string `json:"url"`
+}
+
+// addEmailSources appends the emails found on the page at pageURL that no
+// page before had
+func addEmailSources(sources []EmailSource, pageURL string, emails []string) []EmailSource {
+	for _, email := range emails {
+		known := slices.ContainsFunc(sources, func(s EmailSource) bool {
+			return strings.EqualFold(s.Email, email)
+		})
+		if !known {
+			sources = append(sources, EmailSource{Email: email, URL: pageURL})
+		}
+	}
+
+	return sources
+}
+
+// emailSourcesOf returns the sources of emails, the ones kept of all found
+func emailSourcesOf(sources []EmailSource, emails []string) []EmailSource {
+	var kept []EmailSource
+	for _, source := range sources {
+		if slices.Contains(emails, source.Email) {
+			kept = append(kept, source)
+		}
+	}
+
+	return kept
+}
+
+// emailPageLinks returns up to limit links of doc to pages of the site at
+// base whose path names a contact, imprint or about page, most likely
+// first
+func emailPageLinks(doc *goquery.Document, base *url.URL, limit int) []string {
+	type candidate struct {
+		url  string
+		rank int
+	}
+
+	var (
+		candidates []candidate
+		seen       = map[string]bool{strings.TrimSuffix(base.Path, "/"): true}
+	)
+
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+
+		u, err := base.Parse(strings.TrimSpace(href))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !sameSite(u.Host, base.Host) {
+			return
+		}
+
+		u.Fragment = ""
+		key := strings.TrimSuffix(u.Path, "/")
+		if seen[key] {
+			return
+		}
+
+		rank := emailPageRank(u.Path)
+		if rank < 0 {
+			return
+		}
+		seen[key] = true
+
+		candidates = append(candidates, candidate{url: u.String(), rank: rank})
+	})
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].rank < candidates[j].rank
+	})
+
+	var links []string
+	for _, c := range candidates {
+		if len(links) == limit {
+			break
+		}
+		links = append(links, c.url)
+	}
+
+	return links
+}
+
+// emailPageRank returns the index in emailPageWords of the first words a
+// segment of p contains, -1 for none
+func emailPageRank(p string) int {
+	p = strings.ToLower(p)
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		p = unescaped
+	}
+
+	for rank, words := range emailPageWords {
+		for _, segment := range strings.Split(p, "/") {
+			segment = strings.TrimSuffix(segment, path.Ext(segment))
+			for _, word := range words {
+				if strings.Contains(segment, word) {
+					return rank
+				}
+			}
+		}
+	}
+
+	return -1
+}
+
+// sameSite tells whether two hosts are one site, with or without www
+func sameSite(a, b string) bool {
+	return strings.TrimPrefix(strings.ToLower(a), "www.") == strings.TrimPrefix(strings.ToLower(b), "www.")
+}
+
+// pageEmails are the emails found on one page
+type pageEmails struct {
+	url    string
+	emails []string
+}
+
+// fetchEmailPages fetches the pages robots.txt allows, a few at a time,
+// until the site's budget of bytes or time runs out, and returns the
+// emails of each page in the order of pages
+func fetchEmailPages(ctx context.Context, client *http.Client, base *url.URL, pages []string, budget int64) []pageEmails {
+	ctx, cancel := context.WithTimeout(ctx, emailSiteTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	take := func(n int64) {
+		mu.Lock()
+		budget -= n
+		mu.Unlock()
+	}
+	left := func() int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return budget
+	}
+
+	robotsBody, _ := fetchLimited(ctx, client, base.ResolveReference(&url.URL{Path: "/robots.txt"}).String(), min(left(), 512<<10))
+	take(int64(len(robotsBody)))
+	robots := parseRobots(robotsBody)
+
+	found := make([]pageEmails, len(pages))
+	slots := make(chan struct{}, emailPageParallelism)
+
+	var wg sync.WaitGroup
+	for i, page := range pages {
+		u, err := url.Parse(page)
+		if err != nil || !robots.allowed(u.EscapedPath()) {
+			continue
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil || left() <= 0 {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			body, err := fetchLimited(ctx, client, page, left())
+			take(int64(len(body)))
+			if err != nil {
+				return
+			}
+
+			found[i] = pageEmails{url: page, emails: bodyEmails(body)}
+		}()
+	}
+	wg.Wait()
+
+	return found
+}
+
+// fetchLimited gets url and reads at most limit bytes of a successful
+// response
+func fetchLimited(ctx context.Context, client *http.Client, u string, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return nil, errors.New("site budget spent")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", emailPageUserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: unexpected status code: %d", u, resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, limit))
+}
+
+// bodyEmails returns the emails of an HTML page, from its mailto links or
+// else from its text
+func bodyEmails(body []byte) []string {
+	if doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body)); err == nil {
+		if emails := docEmailExtractor(doc); len(emails) > 0 {
+			return emails
+		}
+	}
+
+	return regexEmailExtractor(body)
+}
+
+// robotsRules are the Allow and Disallow lines of robots.txt that apply to
+// every user agent
+type robotsRules struct {
+	allow, disallow []string
+}
+
+// parseRobots reads the groups of robots.txt for user agent *. A missing
+// or unreadable robots.txt allows everything.
+func parseRobots(body []byte) robotsRules {
+	var (
+		rules   robotsRules
+		inGroup bool // The current group applies to *
+		agents  bool // The previous line was a User-agent line
+		scanner = bufio.NewScanner(bytes.NewReader(body))
+	)
+
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive User-agent lines share a group
+			if !agents {
+				inGroup = false
+			}
+			agents = true
+			if value == "*" {
+				inGroup = true
+			}
+			continue
+		case "allow":
+			if inGroup && value != "" {
+				rules.allow = append(rules.allow, value)
+			}
+		case "disallow":
+			if inGroup && value != "" {
+				rules.disallow = append(rules.disallow, value)
+			}
+		}
+		agents = false
+	}
+
+	return rules
+}
+
+// allowed applies the most specific rule matching p, Allow winning a tie
+func (r robotsRules) allowed(p string) bool {
+	if p == "" {
+		p = "/"
+	}
+
+	longest := func(patterns []string) int {
+		n := -1
+		for _, pattern := range patterns {
+			if len(pattern) > n && robotsMatch(pattern, p) {
+				n = len(pattern)
+			}
+		}
+		return n
+	}
+
+	return longest(r.allow) >= longest(r.disallow)
+}
+
+// robotsMatch matches a robots.txt path pattern, with * for any characters
+// and a trailing $ anchoring the end, against the start of p
+func robotsMatch(pattern, p string) bool {
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.TrimSuffix(pattern, "$")), `\*`, ".*")
+	if strings.HasSuffix(pattern, "$") {
+		expr += "$"
+	}
+
+	re, err := regexp.Compile(expr)
+	return err == nil && re.MatchString(p)
+}
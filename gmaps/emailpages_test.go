@@ -0,0 +1,92 @@
+package gmaps_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/gmaps"
+)
+
+func TestEmailJobSearchesContactPages(t *testing.T) {
+	pages := map[string]string{
+		"/robots.txt": "User-agent: Googlebot\nDisallow: /\n\nUser-agent: *\nDisallow: /impressum\n",
+		"/": `<html><body>
+			<a href="mailto:hello@trattoria-napoli.it">Mail us</a>
+			<a href="/blog">Blog</a>
+			<a href="/about-us/">About</a>
+			<a href="/contact#form">Contact</a>
+			<a href="/impressum">Impressum</a>
+			<a href="https://other-site.it/contact">Partner</a>
+		</body></html>`,
+		"/contact":   `<p>Write to bookings@trattoria-napoli.it or HELLO@trattoria-napoli.it</p>`,
+		"/about-us/": `<a href="mailto:chef@trattoria-napoli.it">Chef</a>`,
+		"/impressum": `<a href="mailto:legal@trattoria-napoli.it">Legal</a>`,
+		"/blog":      `<a href="mailto:blog@trattoria-napoli.it">Blog</a>`,
+	}
+
+	var (
+		srv     *httptest.Server
+		fetched = make(chan string, 10)
+	)
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched <- r.URL.Path
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	process := func(t *testing.T, pageCount int) *gmaps.Entry {
+		t.Helper()
+
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(pages["/"]))
+		require.NoError(t, err)
+
+		job := gmaps.NewEmailJob("parent", &gmaps.Entry{WebSite: srv.URL + "/"}, gmaps.WithEmailJobPages(pageCount))
+		out, _, err := job.Process(context.Background(), &scrapemate.Response{
+			URL:      srv.URL + "/",
+			Body:     []byte(pages["/"]),
+			Document: doc,
+		})
+		require.NoError(t, err)
+
+		return out.(*gmaps.Entry)
+	}
+
+	t.Run("contact and about pages", func(t *testing.T) {
+		entry := process(t, gmaps.DefaultEmailPages)
+
+		assert.Equal(t, []string{"hello@trattoria-napoli.it", "bookings@trattoria-napoli.it", "chef@trattoria-napoli.it"}, entry.Emails)
+		assert.Equal(t, []gmaps.EmailSource{
+			{Email: "hello@trattoria-napoli.it", URL: srv.URL + "/"},
+			{Email: "bookings@trattoria-napoli.it", URL: srv.URL + "/contact"},
+			{Email: "chef@trattoria-napoli.it", URL: srv.URL + "/about-us/"},
+		}, entry.EmailSources)
+
+		close(fetched)
+		var paths []string
+		for p := range fetched {
+			paths = append(paths, p)
+		}
+		// The imprint is disallowed and the blog is no contact page
+		assert.ElementsMatch(t, []string{"/robots.txt", "/contact", "/about-us/"}, paths)
+	})
+
+	t.Run("home page only", func(t *testing.T) {
+		entry := process(t, 0)
+
+		assert.Equal(t, []string{"hello@trattoria-napoli.it"}, entry.Emails)
+		assert.Equal(t, []gmaps.EmailSource{{Email: "hello@trattoria-napoli.it", URL: srv.URL + "/"}}, entry.EmailSources)
+	})
+}
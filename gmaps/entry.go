@@ -55,6 +55,7 @@ type Review struct {
 	Description    string   `json:"description"`
 	Images         []string `json:"images"`
 	When           string   `json:"when"`
+	OwnerReply     string   `json:"owner_reply,omitempty"`
 }
 
 // EmailValidation holds validation result for a single email
@@ -79,6 +80,10 @@ type Entry struct {
 	Category   string              `json:"category"`
 	Address    string              `json:"address"`
 	OpenHours  map[string][]string `json:"open_hours"`
+	// OpenHoursParsed is OpenHours as a schedule, nil when there are no
+	// hours or they did not parse; OpenHoursParseError then says why
+	OpenHoursParsed     *ParsedHours `json:"open_hours_parsed,omitempty"`
+	OpenHoursParseError string       `json:"open_hours_parse_error,omitempty"`
 	// PopularTImes is a map with keys the days of the week
 	// and value is a map with key the hour and value the traffic in that time
 	PopularTimes        map[string]map[int]int `json:"popular_times"`
@@ -87,6 +92,8 @@ type Entry struct {
 	PlusCode            string                 `json:"plus_code"`
 	ReviewCount         int                    `json:"review_count"`
 	ReviewRating        float64                `json:"review_rating"`
+	// ReviewsPerRating counts the reviews by star, 1 to 5; nil when the
+	// place data has no histogram
 	ReviewsPerRating    map[int]int            `json:"reviews_per_rating"`
 	Latitude            float64                `json:"latitude"`
 	Longitude          float64                `json:"longitude"`
@@ -99,16 +106,30 @@ type Entry struct {
 	DataID              string                 `json:"data_id"`
 	PlaceID             string                 `json:"place_id"`
 	Images              []Image                `json:"images"`
+	ImageURLs           []string               `json:"image_urls"`
 	Reservations        []LinkSource           `json:"reservations"`
 	OrderOnline         []LinkSource           `json:"order_online"`
 	Menu                LinkSource             `json:"menu"`
 	Owner               Owner                  `json:"owner"`
 	CompleteAddress     Address                `json:"complete_address"`
 	About               []About                `json:"about"`
+	Attributes          map[string][]string    `json:"attributes"`
 	UserReviews         []Review               `json:"user_reviews"`
 	UserReviewsExtended []Review               `json:"user_reviews_extended"`
 	Emails              []string               `json:"emails"`
 	EmailValidations    []EmailValidation      `json:"email_validations,omitempty"` // Validation metadata for emails
+	EmailSources        []EmailSource          `json:"email_sources,omitempty"`     // Page each email was found on
+	SocialLinks         map[string]string      `json:"social_links,omitempty"`      // Network -> profile URL, from the website
+	WebsitePhone        string                 `json:"website_phone,omitempty"`     // First tel: link on the website
+	WebsiteDescription  string                 `json:"website_description,omitempty"`
+	Lang                string                 `json:"lang,omitempty"` // Interface language (hl) the place was scraped in
+	// DataCompleteness is CompletenessPartial for a place whose page could
+	// not be scraped, kept with what the search results listed
+	DataCompleteness string `json:"data_completeness,omitempty"`
+
+	// ParseReport lists the fields that could not be read from the place
+	// data, nil for entries that were not parsed from it
+	ParseReport *ParseReport `json:"-"`
 }
 
 func (e *Entry) haversineDistance(lat, lon float64) float64 {
@@ -297,7 +318,42 @@ func extractReviews(data []byte) []Review {
 	return parseReviews(reviewsI)
 }
 
-//nolint:gomnd // it's ok, I need the indexes
+// Indexes into the place data, as Google lays it out. Fields are read from
+// darray, the element placeDataIndex of the payload; the comments give the
+// path below an index where a field sits deeper.
+const (
+	placeDataIndex = 6  // darray
+	cidIndex       = 25 // [3][0][13][0][0][1], in the payload rather than darray
+
+	idxRating          = 4 // [2] price range, [3][0] reviews link, [7] rating, [8] review count
+	idxWebsite         = 7 // [0] Google redirect to the website
+	idxCoordinates     = 9 // [2] latitude, [3] longitude
+	idxDataID          = 10
+	idxTitle           = 11
+	idxCategories      = 13
+	idxAddress         = 18 // Title, a comma, then the address
+	idxLink            = 27
+	idxTimezone        = 30
+	idxDescription     = 32 // [1][1]
+	idxStatus          = 34 // [4][4] open now; [1] opening hours before Nov 2025
+	idxMenu            = 38 // [0] link, [1] source
+	idxReservations    = 46
+	idxOwner           = 57 // [1] name, [2] ID
+	idxThumbnail       = 72 // [0][1][6][0]
+	idxOrderOnline     = 75 // [0][1][2], or [0][0][2]
+	idxPlaceID         = 78
+	idxPopularTimes    = 84  // [0]
+	idxAbout           = 100 // [1]
+	idxImages          = 171 // [0]
+	idxReviews         = 175 // [3] count per rating, [9][0][0] or [9][0] inline reviews
+	idxPhone           = 178 // [0][0]
+	idxCompleteAddress = 183 // [1] address parts, [2][2][0] plus code, [2][1][0] global plus code
+	idxHours           = 203 // [0], as of Nov 2025
+)
+
+// EntryFromJSON parses the place data of a place page. Fields that cannot
+// be read are left empty and listed in the entry's ParseReport; only a
+// payload without place data is an error.
 func EntryFromJSON(raw []byte, reviewCountOnly ...bool) (entry Entry, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -318,25 +374,28 @@ func EntryFromJSON(raw []byte, reviewCountOnly ...bool) (entry Entry, err error)
 		return entry, err
 	}
 
-	if len(jd) < 7 {
+	if len(jd) <= placeDataIndex {
 		return entry, fmt.Errorf("invalid json")
 	}
 
-	darray, ok := jd[6].([]any)
+	darray, ok := jd[placeDataIndex].([]any)
 	if !ok {
 		return entry, fmt.Errorf("invalid json")
 	}
 
-	entry.ReviewCount = int(getNthElementAndCast[float64](darray, 4, 8))
+	report := &ParseReport{}
+	entry.ParseReport = report
+
+	entry.ReviewCount = int(readField[float64](report, "review_count", darray, idxRating, 8))
 
 	if onlyReviewCount {
 		return entry, nil
 	}
 
-	entry.Link = getNthElementAndCast[string](darray, 27)
-	entry.Title = getNthElementAndCast[string](darray, 11)
+	entry.Link = readField[string](report, "link", darray, idxLink)
+	entry.Title = readField[string](report, "title", darray, idxTitle)
 
-	categoriesI := getNthElementAndCast[[]any](darray, 13)
+	categoriesI := readField[[]any](report, "categories", darray, idxCategories)
 
 	entry.Categories = make([]string, len(categoriesI))
 	for i := range categoriesI {
@@ -348,28 +407,34 @@ func EntryFromJSON(raw []byte, reviewCountOnly ...bool) (entry Entry, err error)
 	}
 
 	entry.Address = strings.TrimSpace(
-		strings.TrimPrefix(getNthElementAndCast[string](darray, 18), entry.Title+","),
+		strings.TrimPrefix(readField[string](report, "address", darray, idxAddress), entry.Title+","),
 	)
-	entry.OpenHours = getHours(darray)
-	entry.PopularTimes = getPopularTimes(darray)
-	entry.WebSite = extractActualURL(getNthElementAndCast[string](darray, 7, 0))
-	entry.Phone = getNthElementAndCast[string](darray, 178, 0, 0)
-	entry.PlusCode = getNthElementAndCast[string](darray, 183, 2, 2, 0)
-	entry.ReviewRating = getNthElementAndCast[float64](darray, 4, 7)
-	entry.Latitude = getNthElementAndCast[float64](darray, 9, 2)
-	entry.Longitude = getNthElementAndCast[float64](darray, 9, 3)
-	entry.Cid = getNthElementAndCast[string](jd, 25, 3, 0, 13, 0, 0, 1)
-	entry.Status = getNthElementAndCast[string](darray, 34, 4, 4)
-	entry.Description = getNthElementAndCast[string](darray, 32, 1, 1)
-	entry.ReviewsLink = getNthElementAndCast[string](darray, 4, 3, 0)
-	entry.Thumbnail = getNthElementAndCast[string](darray, 72, 0, 1, 6, 0)
-	entry.Timezone = getNthElementAndCast[string](darray, 30)
-	entry.PriceRange = getNthElementAndCast[string](darray, 4, 2)
-	entry.DataID = getNthElementAndCast[string](darray, 10)
-	entry.PlaceID = getNthElementAndCast[string](darray, 78)
+	entry.OpenHours = hoursFromItems(readFirst[[]any](report, "open_hours", darray, []int{idxHours, 0}, []int{idxStatus, 1}))
+
+	popularTimesI := readField[[]any](report, "popular_times", darray, idxPopularTimes, 0)
+	entry.PopularTimes = popularTimesFromItems(popularTimesI)
+	if entry.PopularTimes == nil && len(popularTimesI) > 0 {
+		report.record("popular_times", fieldFailed)
+	}
+
+	entry.WebSite = extractActualURL(readField[string](report, "web_site", darray, idxWebsite, 0))
+	entry.Phone = readField[string](report, "phone", darray, idxPhone, 0, 0)
+	entry.PlusCode = readFirst[string](report, "plus_code", darray, []int{idxCompleteAddress, 2, 2, 0}, []int{idxCompleteAddress, 2, 1, 0})
+	entry.ReviewRating = readField[float64](report, "review_rating", darray, idxRating, 7)
+	entry.Latitude = readField[float64](report, "latitude", darray, idxCoordinates, 2)
+	entry.Longitude = readField[float64](report, "longitude", darray, idxCoordinates, 3)
+	entry.Cid = readField[string](report, "cid", jd, cidIndex, 3, 0, 13, 0, 0, 1)
+	entry.Status = readField[string](report, "status", darray, idxStatus, 4, 4)
+	entry.Description = readField[string](report, "description", darray, idxDescription, 1, 1)
+	entry.ReviewsLink = readField[string](report, "reviews_link", darray, idxRating, 3, 0)
+	entry.Thumbnail = readField[string](report, "thumbnail", darray, idxThumbnail, 0, 1, 6, 0)
+	entry.Timezone = readTimezone(report, jd, darray)
+	entry.PriceRange = readField[string](report, "price_range", darray, idxRating, 2)
+	entry.DataID = readField[string](report, "data_id", darray, idxDataID)
+	entry.PlaceID = readField[string](report, "place_id", darray, idxPlaceID)
 
 	items := getLinkSource(getLinkSourceParams{
-		arr:    getNthElementAndCast[[]any](darray, 171, 0),
+		arr:    readField[[]any](report, "images", darray, idxImages, 0),
 		link:   []int{3, 0, 6, 0},
 		source: []int{2},
 	})
@@ -383,48 +448,46 @@ func EntryFromJSON(raw []byte, reviewCountOnly ...bool) (entry Entry, err error)
 		}
 	}
 
+	entry.ImageURLs = cleanImageURLs(entry.Images)
+
 	entry.Reservations = getLinkSource(getLinkSourceParams{
-		arr:    getNthElementAndCast[[]any](darray, 46),
+		arr:    readField[[]any](report, "reservations", darray, idxReservations),
 		link:   []int{0},
 		source: []int{1},
 	})
 
-	orderOnlineI := getNthElementAndCast[[]any](darray, 75, 0, 1, 2)
-
-	if len(orderOnlineI) == 0 {
-		orderOnlineI = getNthElementAndCast[[]any](darray, 75, 0, 0, 2)
-	}
-
 	entry.OrderOnline = getLinkSource(getLinkSourceParams{
-		arr:    orderOnlineI,
+		arr:    readFirst[[]any](report, "order_online", darray, []int{idxOrderOnline, 0, 1, 2}, []int{idxOrderOnline, 0, 0, 2}),
 		link:   []int{1, 2, 0},
 		source: []int{0, 0},
 	})
 
 	entry.Menu = LinkSource{
-		Link:   getNthElementAndCast[string](darray, 38, 0),
-		Source: getNthElementAndCast[string](darray, 38, 1),
+		Link:   readField[string](report, "menu", darray, idxMenu, 0),
+		Source: getNthElementAndCast[string](darray, idxMenu, 1),
 	}
 
 	entry.Owner = Owner{
-		ID:   getNthElementAndCast[string](darray, 57, 2),
-		Name: getNthElementAndCast[string](darray, 57, 1),
+		ID:   getNthElementAndCast[string](darray, idxOwner, 2),
+		Name: readField[string](report, "owner", darray, idxOwner, 1),
 	}
 
 	if entry.Owner.ID != "" {
 		entry.Owner.Link = fmt.Sprintf("https://www.google.com/maps/contrib/%s", entry.Owner.ID)
 	}
 
+	addressI := readField[[]any](report, "complete_address", darray, idxCompleteAddress, 1)
+
 	entry.CompleteAddress = Address{
-		Borough:    getNthElementAndCast[string](darray, 183, 1, 0),
-		Street:     getNthElementAndCast[string](darray, 183, 1, 1),
-		City:       getNthElementAndCast[string](darray, 183, 1, 3),
-		PostalCode: getNthElementAndCast[string](darray, 183, 1, 4),
-		State:      getNthElementAndCast[string](darray, 183, 1, 5),
-		Country:    getNthElementAndCast[string](darray, 183, 1, 6),
+		Borough:    getNthElementAndCast[string](addressI, 0),
+		Street:     getNthElementAndCast[string](addressI, 1),
+		City:       getNthElementAndCast[string](addressI, 3),
+		PostalCode: getNthElementAndCast[string](addressI, 4),
+		State:      getNthElementAndCast[string](addressI, 5),
+		Country:    getNthElementAndCast[string](addressI, 6),
 	}
 
-	aboutI := getNthElementAndCast[[]any](darray, 100, 1)
+	aboutI := readField[[]any](report, "about", darray, idxAbout, 1)
 
 	for i := range aboutI {
 		el := getNthElementAndCast[[]any](aboutI, i)
@@ -449,31 +512,47 @@ func EntryFromJSON(raw []byte, reviewCountOnly ...bool) (entry Entry, err error)
 		entry.About = append(entry.About, about)
 	}
 
-	entry.ReviewsPerRating = map[int]int{
-		1: int(getNthElementAndCast[float64](darray, 175, 3, 0)),
-		2: int(getNthElementAndCast[float64](darray, 175, 3, 1)),
-		3: int(getNthElementAndCast[float64](darray, 175, 3, 2)),
-		4: int(getNthElementAndCast[float64](darray, 175, 3, 3)),
-		5: int(getNthElementAndCast[float64](darray, 175, 3, 4)),
+	entry.Attributes = attributesFromAbout(entry.About)
+
+	perRatingI := readField[[]any](report, "reviews_per_rating", darray, idxReviews, 3)
+	entry.ReviewsPerRating = reviewsPerRating(perRatingI)
+	if entry.ReviewsPerRating == nil && len(perRatingI) > 0 {
+		report.record("reviews_per_rating", fieldFailed)
 	}
 
-	// Parse inline reviews from the page data
-	reviewsI := getNthElementAndCast[[]any](darray, 175, 9, 0, 0)
+	// Parse inline reviews from the page data, at either place Google uses
+	reviewsI := readFirst[[]any](report, "user_reviews", darray, []int{idxReviews, 9, 0, 0}, []int{idxReviews, 9, 0})
 	if len(reviewsI) > 0 {
 		entry.UserReviews = parseReviews(reviewsI)
 	} else {
-		// Try alternative location for reviews
-		reviewsI = getNthElementAndCast[[]any](darray, 175, 9, 0)
-		if len(reviewsI) > 0 {
-			entry.UserReviews = parseReviews(reviewsI)
-		} else {
-			entry.UserReviews = make([]Review, 0)
-		}
+		entry.UserReviews = make([]Review, 0)
 	}
 
+	entry.FillLocation()
+
 	return entry, nil
 }
 
+// reviewsPerRating reads the review histogram, the counts of 1 to 5 stars
+// in order, a null counting none. It returns nil when there is none or it
+// is not five counts.
+func reviewsPerRating(items []any) map[int]int {
+	if len(items) != 5 {
+		return nil
+	}
+
+	ans := make(map[int]int, len(items))
+	for i, item := range items {
+		count, ok := item.(float64)
+		if !ok && item != nil {
+			return nil
+		}
+		ans[i+1] = int(count)
+	}
+
+	return ans
+}
+
 func parseReviews(reviewsI []any) []Review {
 	ans := make([]Review, 0, len(reviewsI))
 
@@ -529,6 +608,9 @@ func parseReviews(reviewsI []any) []Review {
 			}
 		}
 
+		// Response from the owner, when the business replied
+		ownerReply := getNthElementAndCast[string](el, 3, 14, 0, 0)
+
 		review := Review{
 			Name:           authorName,
 			ProfilePicture: profilePic,
@@ -541,6 +623,7 @@ func parseReviews(reviewsI []any) []Review {
 			}(),
 			Rating:      rating,
 			Description: description,
+			OwnerReply:  ownerReply,
 		}
 
 		if review.Name == "" {
@@ -590,15 +673,16 @@ func getLinkSource(params getLinkSourceParams) []LinkSource {
 	return result
 }
 
-//nolint:gomnd // it's ok, I need the indexes
+// getHours reads the opening hours of a place's data, from the structure
+// used since Nov 2025 or the older one
 func getHours(darray []any) map[string][]string {
-	// Try new structure first (as of Nov 2025) - darray[203][0]
-	items := getNthElementAndCast[[]any](darray, 203, 0)
-	if len(items) == 0 {
-		// Fall back to old structure - darray[34][1]
-		items = getNthElementAndCast[[]any](darray, 34, 1)
-	}
+	var report ParseReport
 
+	return hoursFromItems(readFirst[[]any](&report, "open_hours", darray, []int{idxHours, 0}, []int{idxStatus, 1}))
+}
+
+// hoursFromItems turns the days of the opening hours into day -> time slots
+func hoursFromItems(items []any) map[string][]string {
 	hours := make(map[string][]string, len(items))
 
 	for _, item := range items {
@@ -655,8 +739,10 @@ func getHours(darray []any) map[string][]string {
 	return hours
 }
 
-func getPopularTimes(darray []any) map[string]map[int]int {
-	items := getNthElementAndCast[[]any](darray, 84, 0) //nolint:gomnd // it's ok, I need the indexes
+// popularTimesFromItems turns the days of the popular times into day ->
+// hour -> traffic. It returns nil when a day or hour is not laid out as
+// expected.
+func popularTimesFromItems(items []any) map[string]map[int]int {
 	popularTimes := make(map[string]map[int]int, len(items))
 
 	dayOfWeek := map[int]string{
@@ -683,7 +769,7 @@ func getPopularTimes(darray []any) map[string]map[int]int {
 
 		for i := range timesI {
 			t, ok := timesI[i].([]any)
-			if !ok {
+			if !ok || len(t) < 2 {
 				return nil
 			}
 
@@ -753,6 +839,85 @@ func getNthElementAndCast[T any](arr []any, indexes ...int) T {
 	return ans
 }
 
+// attributesFromAbout keeps the enabled options of each about section,
+// keyed by the section name as displayed (e.g. "Service options" ->
+// ["Delivery", "Dine-in"]). Sections without enabled options are left out.
+func attributesFromAbout(about []About) map[string][]string {
+	var attrs map[string][]string
+
+	for _, section := range about {
+		if section.Name == "" {
+			continue
+		}
+
+		for _, opt := range section.Options {
+			if !opt.Enabled || slices.Contains(attrs[section.Name], opt.Name) {
+				continue
+			}
+
+			if attrs == nil {
+				attrs = make(map[string][]string)
+			}
+
+			attrs[section.Name] = append(attrs[section.Name], opt.Name)
+		}
+	}
+
+	return attrs
+}
+
+// FlattenAttributes renders attributes as "Section: a, b; Other: c" with
+// sections in alphabetical order, for tabular exports.
+func FlattenAttributes(attrs map[string][]string) string {
+	sections := make([]string, 0, len(attrs))
+	for section := range attrs {
+		sections = append(sections, section)
+	}
+
+	slices.Sort(sections)
+
+	parts := make([]string, 0, len(sections))
+	for _, section := range sections {
+		parts = append(parts, section+": "+strings.Join(attrs[section], ", "))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// cleanImageURLs returns the distinct photo URLs of images, dropping
+// anything that is not an absolute http(s) URL. Google serves the same
+// photo at several sizes via a "=w..-h.." suffix, so URLs are compared
+// without it.
+func cleanImageURLs(images []Image) []string {
+	var urls []string
+
+	seen := make(map[string]bool, len(images))
+
+	for _, img := range images {
+		raw := strings.TrimSpace(img.Image)
+
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			continue
+		}
+
+		key := u.Host + u.Path
+		if i := strings.LastIndex(key, "="); i > len(u.Host) {
+			key = key[:i]
+		}
+
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+
+		urls = append(urls, raw)
+	}
+
+	return urls
+}
+
 func stringSliceToString(s []string) string {
 	return strings.Join(s, ", ")
 }
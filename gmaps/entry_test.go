@@ -98,6 +98,18 @@ func Test_EntryFromJSON(t *testing.T) {
 				Image: "https://lh5.googleusercontent.com/p/AF1QipMwkHP8GmDCSuwnWS7pYVQvtDWdsdk-CUwxtsXL=w224-h298-k-no-pi-23.425545-ya289.20517-ro-8.658787-fo100",
 			},
 		},
+		ImageURLs: []string{
+			"https://lh5.googleusercontent.com/p/AF1QipP4Y7A8nYL3KKXznSl69pXSq9p2IXCYUjVvOh0F=w298-h298-k-no",
+			"https://lh5.googleusercontent.com/p/AF1QipNgMqyaQs2MqH1oiGC44eDcvudurxQfNb2RuDsd=w224-h298-k-no",
+			"https://lh5.googleusercontent.com/p/AF1QipPZbq8v8K8RZfvL6gZ_4Dw6qwNJ_MUxxOOfBo7h=w224-h398-k-no",
+			"https://lh5.googleusercontent.com/p/AF1QipNhoFtPcaLCIhdN3GhlJ6sQIvdhaESnRG8nyeC8=w397-h298-k-no",
+			"https://lh5.googleusercontent.com/p/AF1QipMbu-iiWkE4DsXx3aI7nGaqyXJKbBYCrBXvzOnu=w298-h298-k-no",
+			"https://lh5.googleusercontent.com/p/AF1QipOGg_vrD4bzkOre5Ly6CFXuO3YCOGfFxQ-EiEkW=w224-h398-k-no",
+			"https://lh5.googleusercontent.com/p/AF1QipOziHd2hqM1jnK9KfCGf1zVhcOrx8Bj7VdJXj0=w397-h298-k-no",
+			"https://lh5.googleusercontent.com/p/AF1QipNJyq7nAlKtsxxbNy4PHUZOhJ0k7HPP8tTAlwcV=w397-h298-k-no",
+			"https://lh5.googleusercontent.com/p/AF1QipNRE2R5k13zT-0WG4b6XOD_BES9-nMK04hlCMVV=w298-h298-k-no",
+			"https://lh5.googleusercontent.com/p/AF1QipMwkHP8GmDCSuwnWS7pYVQvtDWdsdk-CUwxtsXL=w224-h298-k-no-pi-23.425545-ya289.20517-ro-8.658787-fo100",
+		},
 		OrderOnline: []gmaps.LinkSource{
 			{
 				Link:   "https://foody.com.cy/delivery/lemesos/to-kypriakon?utm_source=google&utm_medium=organic&utm_campaign=google_reserve_place_order_action",
@@ -146,6 +158,7 @@ func Test_EntryFromJSON(t *testing.T) {
 	}
 
 	entry.About = nil
+	entry.Attributes = nil
 
 	require.Len(t, entry.PopularTimes, 7)
 
@@ -173,9 +186,71 @@ func Test_EntryFromJSON(t *testing.T) {
 	entry.PopularTimes = nil
 	entry.UserReviews = nil
 
+	require.NotNil(t, entry.ParseReport)
+	require.Empty(t, entry.ParseReport.Failed)
+	require.Equal(t, []string{"web_site", "description", "reservations", "menu"}, entry.ParseReport.Missing)
+	entry.ParseReport = nil
+
 	require.Equal(t, expected, entry)
 }
 
+func Test_EntryFromJSONMangled(t *testing.T) {
+	// raw.json with the title, categories, coordinates and rating of
+	// another type, hours without a traffic value, and the place data
+	// cut short before the phone
+	raw, err := os.ReadFile("../testdata/raw_mangled.json")
+	require.NoError(t, err)
+
+	entry, err := gmaps.EntryFromJSON(raw)
+	require.NoError(t, err, "a changed layout must not fail the whole entry")
+
+	require.Empty(t, entry.Title)
+	require.Empty(t, entry.Categories)
+	require.Zero(t, entry.Latitude)
+	require.Zero(t, entry.ReviewRating)
+	require.Empty(t, entry.Phone)
+
+	require.ElementsMatch(t, []string{
+		"title", "categories", "popular_times", "review_rating", "latitude", "longitude",
+	}, entry.ParseReport.Failed)
+	require.Subset(t, entry.ParseReport.Missing, []string{"phone", "plus_code", "complete_address"})
+
+	// Fields before the cut are still read
+	require.Equal(t, 396, entry.ReviewCount)
+	require.NotEmpty(t, entry.PlaceID)
+	require.NotEmpty(t, entry.OpenHours)
+}
+
+func Test_EntryFromJSONReviewsPerRating(t *testing.T) {
+	// raw.json without the review histogram
+	raw, err := os.ReadFile("../testdata/raw_no_histogram.json")
+	require.NoError(t, err)
+
+	entry, err := gmaps.EntryFromJSON(raw)
+	require.NoError(t, err)
+
+	require.Nil(t, entry.ReviewsPerRating)
+	require.Equal(t, 396, entry.ReviewCount)
+	require.Equal(t, 4.2, entry.ReviewRating)
+	require.Contains(t, entry.ParseReport.Missing, "reviews_per_rating")
+	require.Empty(t, entry.ParseReport.Failed)
+
+	// raw.json of a place nobody reviewed: no rating, count, reviews link
+	// or reviews section
+	raw, err = os.ReadFile("../testdata/raw_no_reviews.json")
+	require.NoError(t, err)
+
+	entry, err = gmaps.EntryFromJSON(raw)
+	require.NoError(t, err)
+
+	require.Nil(t, entry.ReviewsPerRating)
+	require.Zero(t, entry.ReviewCount)
+	require.Zero(t, entry.ReviewRating)
+	require.Empty(t, entry.UserReviews)
+	require.Subset(t, entry.ParseReport.Missing, []string{"review_count", "review_rating", "reviews_per_rating"})
+	require.Empty(t, entry.ParseReport.Failed)
+}
+
 func Test_EntryFromJSON2(t *testing.T) {
 	fnames := []string{
 		"../testdata/panic.json",
@@ -203,6 +278,52 @@ func Test_EntryFromJSONRaw2(t *testing.T) {
 	require.Greater(t, len(entry.About), 0)
 }
 
+func Test_EntryAttributes(t *testing.T) {
+	raw, err := os.ReadFile("../testdata/raw.json")
+
+	require.NoError(t, err)
+	require.NotEmpty(t, raw)
+
+	entry, err := gmaps.EntryFromJSON(raw)
+
+	require.NoError(t, err)
+	require.Len(t, entry.Attributes, 10)
+
+	require.Equal(t, []string{"Outdoor seating", "Delivery", "Takeaway", "Dine-in"}, entry.Attributes["Service options"])
+	require.Equal(t, []string{"Wheelchair-accessible entrance", "Wheelchair-accessible seating"}, entry.Attributes["Accessibility"])
+	require.Equal(t, []string{"Accepts reservations"}, entry.Attributes["Planning"])
+
+	// "Credit cards" is listed both enabled and disabled; it must appear once
+	require.Equal(t, []string{"Credit cards", "Debit cards", "NFC mobile payments"}, entry.Attributes["Payments"])
+}
+
+func Test_EntryAttributesLocalized(t *testing.T) {
+	raw, err := os.ReadFile("../testdata/raw2.json")
+
+	require.NoError(t, err)
+	require.NotEmpty(t, raw)
+
+	entry, err := gmaps.EntryFromJSON(raw)
+
+	require.NoError(t, err)
+	require.Len(t, entry.Attributes, len(entry.About))
+	require.Equal(t, []string{"Τουαλέτα"}, entry.Attributes["Παροχές"])
+	require.Len(t, entry.Attributes["Χώροι στάθμευσης"], 3)
+}
+
+func Test_FlattenAttributes(t *testing.T) {
+	attrs := map[string][]string{
+		"Service options": {"Delivery", "Dine-in"},
+		"Accessibility":   {"Wheelchair-accessible entrance"},
+	}
+
+	require.Equal(t,
+		"Accessibility: Wheelchair-accessible entrance; Service options: Delivery, Dine-in",
+		gmaps.FlattenAttributes(attrs),
+	)
+	require.Empty(t, gmaps.FlattenAttributes(nil))
+}
+
 func Test_EntryFromJsonC(t *testing.T) {
 	raw, err := os.ReadFile("../testdata/output.json")
 
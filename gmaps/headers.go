@@ -0,0 +1,36 @@
+package gmaps
+
+import (
+	"fmt"
+
+	"github.com/gosom/scrapemate"
+)
+
+// setPageHeaders makes the browser page send headers with every request.
+// Browser jobs do not go through the HTTP fetcher that reads a job's
+// Headers, so they are set on the underlying Playwright or rod page.
+func setPageHeaders(page scrapemate.BrowserPage, headers map[string]string) error {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	switch p := page.Unwrap().(type) {
+	case interface {
+		SetExtraHTTPHeaders(map[string]string) error
+	}: // playwright.Page
+		return p.SetExtraHTTPHeaders(headers)
+	case interface {
+		SetExtraHeaders([]string) (func(), error)
+	}: // *rod.Page
+		dict := make([]string, 0, 2*len(headers))
+		for k, v := range headers {
+			dict = append(dict, k, v)
+		}
+
+		_, err := p.SetExtraHeaders(dict)
+
+		return err
+	default:
+		return fmt.Errorf("browser page %T cannot set request headers", p)
+	}
+}
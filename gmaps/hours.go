@@ -0,0 +1,352 @@
+package gmaps
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Weekdays are the keys of ParsedHours.Days, whatever the language of the
+// raw opening hours
+var Weekdays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// ParsedHours is the machine-readable form of Entry.OpenHours. Days the
+// place does not list are missing from Days.
+type ParsedHours struct {
+	Days    map[string]DayHours `json:"days"`
+	Open24h bool                `json:"open_24h"` // Every day around the clock
+}
+
+// DayHours is the schedule of one day. A day is either closed, open around
+// the clock or open in its intervals.
+type DayHours struct {
+	Closed    bool       `json:"closed,omitempty"`
+	Open24h   bool       `json:"open_24h,omitempty"`
+	Intervals []Interval `json:"intervals,omitempty"`
+}
+
+// Interval is an opening period in minutes since midnight. Close is past
+// 1440 when the place closes after midnight ("6 pm–2 am" is 1080–1560).
+type Interval struct {
+	Open  int `json:"open"`
+	Close int `json:"close"`
+}
+
+// IsOpen reports whether the place opens on day, one of Weekdays. known is
+// false when the place does not list the day.
+func (h *ParsedHours) IsOpen(day string) (open, known bool) {
+	if h == nil {
+		return false, false
+	}
+
+	d, ok := h.Days[day]
+	if !ok {
+		return false, false
+	}
+
+	return !d.Closed, true
+}
+
+// hoursLocale is the vocabulary Google Maps displays opening hours with in
+// a language
+type hoursLocale struct {
+	days    map[string]string // Day name -> weekday
+	closed  []string
+	open24h []string
+}
+
+var hoursLocales = map[string]hoursLocale{
+	"en": {
+		days: map[string]string{
+			"monday": "monday", "tuesday": "tuesday", "wednesday": "wednesday", "thursday": "thursday",
+			"friday": "friday", "saturday": "saturday", "sunday": "sunday",
+		},
+		closed:  []string{"closed"},
+		open24h: []string{"open 24 hours", "24 hours"},
+	},
+	"de": {
+		days: map[string]string{
+			"montag": "monday", "dienstag": "tuesday", "mittwoch": "wednesday", "donnerstag": "thursday",
+			"freitag": "friday", "samstag": "saturday", "sonnabend": "saturday", "sonntag": "sunday",
+		},
+		closed:  []string{"geschlossen"},
+		open24h: []string{"24 stunden geöffnet", "rund um die uhr geöffnet", "durchgehend geöffnet"},
+	},
+	"es": {
+		days: map[string]string{
+			"lunes": "monday", "martes": "tuesday", "miércoles": "wednesday", "miercoles": "wednesday",
+			"jueves": "thursday", "viernes": "friday", "sábado": "saturday", "sabado": "saturday", "domingo": "sunday",
+		},
+		closed:  []string{"cerrado"},
+		open24h: []string{"abierto las 24 horas", "abierto 24 horas", "24 horas"},
+	},
+	"fr": {
+		days: map[string]string{
+			"lundi": "monday", "mardi": "tuesday", "mercredi": "wednesday", "jeudi": "thursday",
+			"vendredi": "friday", "samedi": "saturday", "dimanche": "sunday",
+		},
+		closed:  []string{"fermé", "ferme"},
+		open24h: []string{"ouvert 24h/24", "ouvert 24 h/24", "ouvert 24 heures sur 24", "24h/24"},
+	},
+	"id": {
+		days: map[string]string{
+			"senin": "monday", "selasa": "tuesday", "rabu": "wednesday", "kamis": "thursday",
+			"jumat": "friday", "sabtu": "saturday", "minggu": "sunday",
+		},
+		closed:  []string{"tutup"},
+		open24h: []string{"buka 24 jam", "24 jam"},
+	},
+}
+
+// hoursLocaleOrder is the order the other languages are tried in after the
+// job's
+var hoursLocaleOrder = []string{"en", "de", "es", "fr", "id"}
+
+var (
+	hoursSpaceReplacer = strings.NewReplacer("\u202f", " ", "\u00a0", " ", "\u2009", " ")
+	hoursReplacer      = strings.NewReplacer(
+		"\u2013", "-", "\u2014", "-", "\u2011", "-",
+		"a. m.", "am", "p. m.", "pm", "a.m.", "am", "p.m.", "pm",
+		" uhr", "",
+	)
+
+	hoursRangeRe = regexp.MustCompile(`\s*(?:-|\bto\b)\s*`)
+	hoursTimeRe  = regexp.MustCompile(`^(\d{1,2})(?:\s?[:.h]\s?(\d{2}))?\s*(am|pm)?$`)
+)
+
+// ParseHours parses opening hours as Google Maps displays them in lang
+// ("9 AM–5 PM", "09:00–17:00", "Geschlossen"). Languages other than lang
+// are tried when a string is not in lang, so a job language Google Maps
+// ignored still parses. An error means the hours cannot be trusted as a
+// whole; no partial schedule is returned.
+func ParseHours(hours map[string][]string, lang string) (*ParsedHours, error) {
+	if len(hours) == 0 {
+		return nil, nil
+	}
+
+	locales := localesFor(lang)
+
+	parsed := &ParsedHours{Days: make(map[string]DayHours, len(hours))}
+
+	for name, slots := range hours {
+		day, ok := parseWeekday(name, locales)
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q", name)
+		}
+
+		dh, err := parseDayHours(slots, locales)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		parsed.Days[day] = dh
+	}
+
+	parsed.Open24h = len(parsed.Days) == len(Weekdays)
+	for _, dh := range parsed.Days {
+		parsed.Open24h = parsed.Open24h && dh.Open24h
+	}
+
+	return parsed, nil
+}
+
+func localesFor(lang string) []hoursLocale {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+
+	locales := make([]hoursLocale, 0, len(hoursLocaleOrder))
+	if l, ok := hoursLocales[lang]; ok {
+		locales = append(locales, l)
+	}
+
+	for _, code := range hoursLocaleOrder {
+		if code != lang {
+			locales = append(locales, hoursLocales[code])
+		}
+	}
+
+	return locales
+}
+
+// normalizeHours lowercases s and folds the spacing, dashes and meridiem
+// spellings Google Maps uses
+func normalizeHours(s string) string {
+	s = strings.ToLower(s)
+
+	// Spaces first, "a.\u00a0m." is a meridiem too
+	s = hoursSpaceReplacer.Replace(s)
+	s = hoursReplacer.Replace(s)
+
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// parseWeekday reads the day a raw day name starts with; Google Maps may
+// add a holiday note after it
+func parseWeekday(name string, locales []hoursLocale) (string, bool) {
+	name = strings.ReplaceAll(normalizeHours(name), "'", "")
+
+	word := strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == '(' || r == ',' || r == '\n'
+	})
+	if len(word) == 0 {
+		return "", false
+	}
+
+	for _, l := range locales {
+		if day, ok := l.days[word[0]]; ok {
+			return day, true
+		}
+	}
+
+	return "", false
+}
+
+func parseDayHours(slots []string, locales []hoursLocale) (DayHours, error) {
+	var dh DayHours
+
+	for _, slot := range slots {
+		// Some layouts put several intervals in one string
+		for _, part := range strings.Split(normalizeHours(slot), ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			switch {
+			case matchesAny(part, locales, func(l hoursLocale) []string { return l.open24h }):
+				dh.Open24h = true
+			case matchesAny(part, locales, func(l hoursLocale) []string { return l.closed }):
+				dh.Closed = true
+			default:
+				iv, err := parseInterval(part)
+				if err != nil {
+					return DayHours{}, err
+				}
+				if iv.Open == 0 && iv.Close == minutesPerDay {
+					dh.Open24h = true
+					continue
+				}
+				dh.Intervals = append(dh.Intervals, iv)
+			}
+		}
+	}
+
+	switch {
+	case dh.Open24h:
+		dh.Closed = false
+		dh.Intervals = nil
+	case dh.Closed && len(dh.Intervals) > 0:
+		return DayHours{}, fmt.Errorf("closed with opening times")
+	case !dh.Closed && len(dh.Intervals) == 0:
+		return DayHours{}, fmt.Errorf("no opening times")
+	}
+
+	return dh, nil
+}
+
+func matchesAny(s string, locales []hoursLocale, phrases func(hoursLocale) []string) bool {
+	for _, l := range locales {
+		for _, p := range phrases(l) {
+			if s == p {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+const minutesPerDay = 24 * 60
+
+// parseInterval parses "9 am-5 pm", "11-2:30 pm" or "09.00-17.00". An open
+// time without a meridiem takes the close time's, unless that puts it after
+// the close time ("11-2 pm").
+func parseInterval(s string) (Interval, error) {
+	bounds := hoursRangeRe.Split(s, -1)
+	if len(bounds) != 2 {
+		return Interval{}, fmt.Errorf("not a time range: %q", s)
+	}
+
+	open, openMeridiem, err := parseClock(bounds[0])
+	if err != nil {
+		return Interval{}, err
+	}
+
+	closing, closeMeridiem, err := parseClock(bounds[1])
+	if err != nil {
+		return Interval{}, err
+	}
+
+	if openMeridiem == "" && closeMeridiem != "" {
+		open = applyMeridiem(open, closeMeridiem)
+		if open > applyMeridiem(closing, closeMeridiem) {
+			open = applyMeridiem(open%(12*60), "am")
+		}
+	} else {
+		open = applyMeridiem(open, openMeridiem)
+	}
+
+	closing = applyMeridiem(closing, closeMeridiem)
+
+	// Closing at or after midnight
+	if closing <= open {
+		closing += minutesPerDay
+	}
+
+	return Interval{Open: open, Close: closing}, nil
+}
+
+// parseClock returns the minutes of a clock time, before its meridiem
+func parseClock(s string) (minutes int, meridiem string, err error) {
+	m := hoursTimeRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, "", fmt.Errorf("not a time: %q", s)
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+
+	if hour > 24 || minute > 59 || (m[3] != "" && (hour == 0 || hour > 12)) {
+		return 0, "", fmt.Errorf("not a time: %q", s)
+	}
+
+	return hour*60 + minute, m[3], nil
+}
+
+// applyMeridiem turns a 12-hour clock time into minutes since midnight
+func applyMeridiem(minutes int, meridiem string) int {
+	switch meridiem {
+	case "am":
+		if minutes >= 12*60 {
+			minutes -= 12 * 60
+		}
+	case "pm":
+		if minutes < 12*60 {
+			minutes += 12 * 60
+		}
+	}
+
+	return minutes
+}
+
+// ParseOpenHours sets OpenHoursParsed from OpenHours, read in lang. Hours
+// that do not parse leave only the raw strings and record why in
+// OpenHoursParseError; the entry is kept either way.
+func (e *Entry) ParseOpenHours(lang string) {
+	e.OpenHoursParsed = nil
+	e.OpenHoursParseError = ""
+
+	parsed, err := ParseHours(e.OpenHours, lang)
+	if err != nil {
+		e.OpenHoursParseError = err.Error()
+		return
+	}
+
+	e.OpenHoursParsed = parsed
+}
@@ -0,0 +1,135 @@
+package gmaps_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/gmaps"
+)
+
+func TestParseHours(t *testing.T) {
+	tests := []struct {
+		name  string
+		lang  string
+		hours map[string][]string
+		want  map[string]gmaps.DayHours
+	}{
+		{
+			name: "english 12-hour clock",
+			lang: "en",
+			hours: map[string][]string{
+				"Monday":   {"9 AM–5 PM"},
+				"Tuesday":  {"11–2:30 pm", "5:30–10 pm"},
+				"Saturday": {"6 pm–2 am"},
+				"Sunday":   {"Closed"},
+			},
+			want: map[string]gmaps.DayHours{
+				"monday":   {Intervals: []gmaps.Interval{{Open: 540, Close: 1020}}},
+				"tuesday":  {Intervals: []gmaps.Interval{{Open: 660, Close: 870}, {Open: 1050, Close: 1320}}},
+				"saturday": {Intervals: []gmaps.Interval{{Open: 1080, Close: 1560}}},
+				"sunday":   {Closed: true},
+			},
+		},
+		{
+			name: "german",
+			lang: "de",
+			hours: map[string][]string{
+				"Montag":  {"08:00–18:30 Uhr"},
+				"Sonntag": {"Geschlossen"},
+				"Samstag": {"24 Stunden geöffnet"},
+			},
+			want: map[string]gmaps.DayHours{
+				"monday":   {Intervals: []gmaps.Interval{{Open: 480, Close: 1110}}},
+				"saturday": {Open24h: true},
+				"sunday":   {Closed: true},
+			},
+		},
+		{
+			name: "spanish meridiem",
+			lang: "es",
+			hours: map[string][]string{
+				"miércoles": {"9 a.\u00a0m.–1 p.\u00a0m."},
+				"domingo":   {"Cerrado"},
+			},
+			want: map[string]gmaps.DayHours{
+				"wednesday": {Intervals: []gmaps.Interval{{Open: 540, Close: 780}}},
+				"sunday":    {Closed: true},
+			},
+		},
+		{
+			name: "french",
+			lang: "fr",
+			hours: map[string][]string{
+				"lundi":    {"09:00–12:00, 14:00–19:00"},
+				"dimanche": {"Fermé"},
+			},
+			want: map[string]gmaps.DayHours{
+				"monday": {Intervals: []gmaps.Interval{{Open: 540, Close: 720}, {Open: 840, Close: 1140}}},
+				"sunday": {Closed: true},
+			},
+		},
+		{
+			name: "indonesian",
+			lang: "id",
+			hours: map[string][]string{
+				"Jum'at": {"09.00–17.00"},
+				"Minggu": {"Tutup"},
+				"Senin":  {"00.00–24.00"},
+			},
+			want: map[string]gmaps.DayHours{
+				"friday": {Intervals: []gmaps.Interval{{Open: 540, Close: 1020}}},
+				"sunday": {Closed: true},
+				"monday": {Open24h: true},
+			},
+		},
+		{
+			name: "other language than the job's",
+			lang: "de",
+			hours: map[string][]string{
+				"Sunday": {"Open 24 hours"},
+			},
+			want: map[string]gmaps.DayHours{
+				"sunday": {Open24h: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gmaps.ParseHours(tt.hours, tt.lang)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.Days)
+			assert.False(t, got.Open24h)
+		})
+	}
+}
+
+func TestParseOpenHoursKeepsRawOnFailure(t *testing.T) {
+	e := gmaps.Entry{OpenHours: map[string][]string{
+		"Monday":  {"9 AM–5 PM"},
+		"Tuesday": {"by appointment"},
+	}}
+
+	e.ParseOpenHours("en")
+
+	assert.Nil(t, e.OpenHoursParsed)
+	assert.NotEmpty(t, e.OpenHoursParseError)
+	assert.Len(t, e.OpenHours, 2)
+
+	e.OpenHours = map[string][]string{}
+	for _, day := range []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"} {
+		e.OpenHours[day] = []string{"Open 24 hours"}
+	}
+
+	e.ParseOpenHours("en")
+
+	require.NotNil(t, e.OpenHoursParsed)
+	assert.Empty(t, e.OpenHoursParseError)
+	assert.True(t, e.OpenHoursParsed.Open24h)
+
+	open, known := e.OpenHoursParsed.IsOpen("sunday")
+	assert.True(t, open)
+	assert.True(t, known)
+}
@@ -15,10 +15,20 @@ import (
 	"github.com/sadewadee/google-scraper/deduper"
 	"github.com/sadewadee/google-scraper/exiter"
 	"github.com/sadewadee/google-scraper/internal/emailvalidator"
+	"github.com/sadewadee/google-scraper/ratelimit"
 )
 
 type GmapJobOptions func(*GmapJob)
 
+// TaskReporter is told how a seed search ended and the language it ended
+// in. Job providers that track seed jobs, like the gmaps_jobs table, set it
+// on the jobs they hand out. A search that falls back to another language
+// is reported once, when the last language was tried. placesFound counts
+// the places the search listed, also those skipped as already scraped.
+type TaskReporter interface {
+	TaskFinished(ctx context.Context, jobID, lang string, placesFound int, err error)
+}
+
 type GmapJob struct {
 	scrapemate.Job
 
@@ -26,10 +36,22 @@ type GmapJob struct {
 	LangCode     string
 	ExtractEmail bool
 
+	// LangFallback are the languages the search is retried in, in order,
+	// when it finds no places; LangTried those it already found none in
+	LangFallback []string
+	LangTried    []string
+
 	Deduper             deduper.Deduper
 	ExitMonitor         exiter.Exiter
 	ExtractExtraReviews bool
+	MaxReviews          int
+	ReviewsSort         ReviewSort
+	MaxImages           int
 	EmailValidator      emailvalidator.Validator
+	EmailPages          int // Contact pages searched for emails besides the website
+	RateLimiter         ratelimit.Limiter
+	PageCache           PageCache
+	TaskReporter        TaskReporter
 }
 
 func NewGmapJob(
@@ -98,52 +120,165 @@ func WithExtraReviews() GmapJobOptions {
 	}
 }
 
+// WithReviewLimit caps the extra reviews fetched per place (0 means all)
+// and sets the order they are fetched in
+func WithReviewLimit(maxReviews int, sort ReviewSort) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.MaxReviews = maxReviews
+		j.ReviewsSort = sort
+	}
+}
+
+// WithMaxImages caps the photo URLs kept per place (0 means DefaultMaxImages)
+func WithMaxImages(maxImages int) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.MaxImages = maxImages
+	}
+}
+
+// WithHeaders sets the request headers, like User-Agent, of the search and
+// the place jobs it creates
+func WithHeaders(headers map[string]string) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.Headers = headers
+	}
+}
+
 func WithEmailValidator(v emailvalidator.Validator) GmapJobOptions {
 	return func(j *GmapJob) {
 		j.EmailValidator = v
 	}
 }
 
+// WithEmailPages makes the email jobs also search up to pages contact,
+// imprint and about pages of each website
+func WithEmailPages(pages int) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.EmailPages = pages
+	}
+}
+
+func WithRateLimiter(l ratelimit.Limiter) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.RateLimiter = l
+	}
+}
+
+// WithPageCache stores the raw payloads of the job's place pages in cache
+func WithPageCache(cache PageCache) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.PageCache = cache
+	}
+}
+
 func (j *GmapJob) UseInResults() bool {
 	return false
 }
 
+// ProcessOnFetchError lets Process report failed searches to the
+// TaskReporter once scrapemate has given up retrying
+func (j *GmapJob) ProcessOnFetchError() bool {
+	return j.TaskReporter != nil
+}
+
+// SearchQuery returns the searched keyword and the "lat,lon" it is
+// centered on, empty when the search is not geo-targeted
+func (j *GmapJob) SearchQuery() (query, geo string) {
+	_, path, ok := strings.Cut(j.URL, "/maps/search/")
+	if !ok {
+		return "", ""
+	}
+
+	path, at, _ := strings.Cut(path, "/@")
+	if q, err := url.QueryUnescape(path); err == nil {
+		query = q
+	} else {
+		query = path
+	}
+
+	// at is "lat,lon,<zoom>z"
+	if i := strings.LastIndex(at, ","); i > 0 {
+		geo = at[:i]
+	}
+
+	return query, geo
+}
+
+func (j *GmapJob) reportTask(ctx context.Context, placesFound int, err error) {
+	if j.TaskReporter != nil {
+		j.TaskReporter.TaskFinished(ctx, j.ID, j.LangCode, placesFound, err)
+	}
+}
+
+// IsLangFallback reports whether the job is a search retried in another
+// language. It has the ID of the search it retries.
+func (j *GmapJob) IsLangFallback() bool {
+	return len(j.LangTried) > 0
+}
+
+// langFallback returns the search in the next language of LangFallback,
+// nil when none is left
+func (j *GmapJob) langFallback() *GmapJob {
+	if len(j.LangFallback) == 0 {
+		return nil
+	}
+
+	next := *j
+	next.Response = scrapemate.Response{}
+	next.LangCode = j.LangFallback[0]
+	next.LangFallback = j.LangFallback[1:]
+	next.LangTried = append(append([]string(nil), j.LangTried...), j.LangCode)
+	next.URLParams = make(map[string]string, len(j.URLParams))
+	for k, v := range j.URLParams {
+		next.URLParams[k] = v
+	}
+	next.URLParams["hl"] = next.LangCode
+
+	return &next
+}
+
 func (j *GmapJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
 		resp.Body = nil
 	}()
 
+	if resp.Error != nil {
+		j.reportTask(ctx, 0, resp.Error)
+
+		return nil, nil, resp.Error
+	}
+
 	log := scrapemate.GetLoggerFromContext(ctx)
 
 	doc, ok := resp.Document.(*goquery.Document)
 	if !ok {
-		return nil, nil, fmt.Errorf("could not convert to goquery document")
+		err := fmt.Errorf("could not convert to goquery document")
+		j.reportTask(ctx, 0, err)
+
+		return nil, nil, err
 	}
 
 	var next []scrapemate.IJob
 
+	// Places on the page, including those the deduper skips
+	found := 0
+
 	if strings.Contains(resp.URL, "/maps/place/") {
-		jopts := []PlaceJobOptions{}
-		if j.ExitMonitor != nil {
-			jopts = append(jopts, WithPlaceJobExitMonitor(j.ExitMonitor))
-		}
-		if j.EmailValidator != nil {
-			jopts = append(jopts, WithPlaceJobEmailValidator(j.EmailValidator))
-		}
+		found = 1
 
-		placeJob := NewPlaceJob(j.ID, j.LangCode, resp.URL, j.ExtractEmail, j.ExtractExtraReviews, jopts...)
+		placeJob := NewPlaceJob(j.ID, j.LangCode, resp.URL, j.ExtractEmail, j.ExtractExtraReviews, j.placeJobOptions()...)
 
 		next = append(next, placeJob)
 	} else {
 		doc.Find(`div[role=feed] div[jsaction]>a`).Each(func(_ int, s *goquery.Selection) {
 			if href := s.AttrOr("href", ""); href != "" {
-				jopts := []PlaceJobOptions{}
-				if j.ExitMonitor != nil {
-					jopts = append(jopts, WithPlaceJobExitMonitor(j.ExitMonitor))
-				}
-				if j.EmailValidator != nil {
-					jopts = append(jopts, WithPlaceJobEmailValidator(j.EmailValidator))
+				found++
+
+				jopts := j.placeJobOptions()
+				// Kept should the page of the place fail
+				if partial := partialEntry(s, href); partial != nil {
+					jopts = append(jopts, WithPlaceJobPartial(partial))
 				}
 
 				nextJob := NewPlaceJob(j.ID, j.LangCode, href, j.ExtractEmail, j.ExtractExtraReviews, jopts...)
@@ -155,6 +290,15 @@ func (j *GmapJob) Process(ctx context.Context, resp *scrapemate.Response) (any,
 		})
 	}
 
+	// The search counts as completed once the last language was tried
+	if found == 0 {
+		if fallback := j.langFallback(); fallback != nil {
+			log.Info(fmt.Sprintf("no places found with hl=%s, retrying with hl=%s", j.LangCode, fallback.LangCode))
+
+			return nil, []scrapemate.IJob{fallback}, nil
+		}
+	}
+
 	if j.ExitMonitor != nil {
 		j.ExitMonitor.IncrPlacesFound(len(next))
 		j.ExitMonitor.IncrSeedCompleted(1)
@@ -162,12 +306,57 @@ func (j *GmapJob) Process(ctx context.Context, resp *scrapemate.Response) (any,
 
 	log.Info(fmt.Sprintf("%d places found", len(next)))
 
+	j.reportTask(ctx, found, nil)
+
 	return nil, next, nil
 }
 
+// placeJobOptions are the options of the place jobs the search creates
+func (j *GmapJob) placeJobOptions() []PlaceJobOptions {
+	jopts := []PlaceJobOptions{}
+	if j.ExitMonitor != nil {
+		jopts = append(jopts, WithPlaceJobExitMonitor(j.ExitMonitor))
+	}
+	if j.EmailValidator != nil {
+		jopts = append(jopts, WithPlaceJobEmailValidator(j.EmailValidator))
+	}
+	if j.EmailPages > 0 {
+		jopts = append(jopts, WithPlaceJobEmailPages(j.EmailPages))
+	}
+	if j.RateLimiter != nil {
+		jopts = append(jopts, WithPlaceJobRateLimiter(j.RateLimiter))
+	}
+	if j.MaxReviews > 0 || j.ReviewsSort != "" {
+		jopts = append(jopts, WithPlaceJobReviewLimit(j.MaxReviews, j.ReviewsSort))
+	}
+	if j.MaxImages > 0 {
+		jopts = append(jopts, WithPlaceJobMaxImages(j.MaxImages))
+	}
+	if len(j.Headers) > 0 {
+		jopts = append(jopts, WithPlaceJobHeaders(j.Headers))
+	}
+	if j.PageCache != nil {
+		jopts = append(jopts, WithPlaceJobPageCache(j.PageCache))
+	}
+
+	return jopts
+}
+
 func (j *GmapJob) BrowserActions(ctx context.Context, page scrapemate.BrowserPage) scrapemate.Response {
 	var resp scrapemate.Response
 
+	if err := waitForSlot(ctx, j.RateLimiter); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
+	if err := setPageHeaders(page, j.Headers); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
 	pageResponse, err := page.Goto(j.GetFullURL(), scrapemate.WaitUntilDOMContentLoaded)
 	if err != nil {
 		resp.Error = err
@@ -177,6 +366,12 @@ func (j *GmapJob) BrowserActions(ctx context.Context, page scrapemate.BrowserPag
 
 	clickRejectCookiesIfRequired(page)
 
+	if blocked := detectBlock(page.URL(), pageResponse.StatusCode, ""); blocked != nil {
+		resp.Error = reportBlocked(j.RateLimiter, j.ExitMonitor, blocked)
+
+		return resp
+	}
+
 	const defaultTimeout = 5 * time.Second
 
 	err = page.WaitForURL(page.URL(), defaultTimeout)
@@ -205,6 +400,15 @@ func (j *GmapJob) BrowserActions(ctx context.Context, page scrapemate.BrowserPag
 		singlePlace = waitUntilURLContains(waitCtx, page, "/maps/place/")
 
 		waitCancel()
+
+		// no feed and no place: check whether we got a block page instead
+		if !singlePlace {
+			if blocked := detectBlockOnPage(page, pageResponse.StatusCode); blocked != nil {
+				resp.Error = reportBlocked(j.RateLimiter, j.ExitMonitor, blocked)
+
+				return resp
+			}
+		}
 	}
 
 	if singlePlace {
@@ -220,6 +424,8 @@ func (j *GmapJob) BrowserActions(ctx context.Context, page scrapemate.BrowserPag
 
 		resp.Body = []byte(body)
 
+		reportSuccess(j.RateLimiter)
+
 		return resp
 	}
 
@@ -240,6 +446,8 @@ func (j *GmapJob) BrowserActions(ctx context.Context, page scrapemate.BrowserPag
 
 	resp.Body = []byte(body)
 
+	reportSuccess(j.RateLimiter)
+
 	return resp
 }
 
@@ -262,20 +470,22 @@ func waitUntilURLContains(ctx context.Context, page scrapemate.BrowserPage, s st
 func clickRejectCookiesIfRequired(page scrapemate.BrowserPage) {
 	// Use JavaScript to find and click - faster than multiple locator calls
 	_, _ = page.Eval(`() => {
-		// Try consent form buttons first
-		const consentForm = document.querySelector('form[action*="consent.google"]');
-		if (consentForm) {
-			const btn = consentForm.querySelector('button, input[type="submit"]');
-			if (btn) {
+		// Try reject/decline buttons, in the languages of the Google domains
+		const rejects = ` + consentRejectTexts + `;
+		const buttons = document.querySelectorAll('button, input[type="submit"]');
+		for (const btn of buttons) {
+			const text = (btn.textContent || btn.value || '').toLowerCase();
+			if (rejects.some((r) => text.includes(r))) {
 				btn.click();
 				return true;
 			}
 		}
-		// Try reject/decline buttons
-		const buttons = document.querySelectorAll('button, input[type="submit"]');
-		for (const btn of buttons) {
-			const text = (btn.textContent || btn.value || '').toLowerCase();
-			if (text.includes('reject') || text.includes('decline') || text.includes('ablehnen')) {
+		// Fall back to the first button of a consent form, whose action is
+		// on consent.google.com or the consent host of a country domain
+		const consentForm = document.querySelector('form[action*="consent.google."]');
+		if (consentForm) {
+			const btn = consentForm.querySelector('button, input[type="submit"]');
+			if (btn) {
 				btn.click();
 				return true;
 			}
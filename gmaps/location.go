@@ -0,0 +1,56 @@
+package gmaps
+
+import (
+	olc "github.com/google/open-location-code/go"
+
+	"github.com/sadewadee/google-scraper/internal/timezone"
+)
+
+// timezoneIndex is the element of the payload that repeats the timezone of
+// the place data, at [1][0][0]
+const timezoneIndex = 31
+
+// plusCodeLength is the length of the plus codes encoded from coordinates,
+// e.g. 8G6MM2CR+6X, about 14 by 14 meters
+const plusCodeLength = 10
+
+// readTimezone reads the timezone from the place data, falling back to its
+// copy next to it. A value that is no IANA timezone counts as failed.
+func readTimezone(report *ParseReport, jd, darray []any) string {
+	worst := fieldMissing
+
+	for _, read := range []func() (string, fieldStatus){
+		func() (string, fieldStatus) { return readAt[string](darray, idxTimezone) },
+		func() (string, fieldStatus) { return readAt[string](jd, timezoneIndex, 1, 0, 0) },
+	} {
+		tz, status := read()
+		if status == fieldOK && timezone.Valid(tz) {
+			return tz
+		}
+		if status == fieldFailed || (status == fieldOK && tz != "") {
+			worst = fieldFailed
+		}
+	}
+
+	report.record("timezone", worst)
+
+	return ""
+}
+
+// FillLocation sets the plus code and timezone Google left out from the
+// coordinates of the place: the plus code is encoded from them and the
+// timezone is that of the nearest known location in the country of the
+// place. Places without coordinates are left as they are.
+func (e *Entry) FillLocation() {
+	if e.Latitude == 0 && e.Longitude == 0 {
+		return
+	}
+
+	if e.PlusCode == "" {
+		e.PlusCode = olc.Encode(e.Latitude, e.Longitude, plusCodeLength)
+	}
+
+	if !timezone.Valid(e.Timezone) {
+		e.Timezone = timezone.Lookup(e.Latitude, e.Longitude, e.CompleteAddress.Country)
+	}
+}
@@ -0,0 +1,107 @@
+package gmaps_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/gmaps"
+)
+
+// rawWith returns raw.json after change edited its place data
+func rawWith(t *testing.T, change func(jd, darray []any)) []byte {
+	t.Helper()
+
+	raw, err := os.ReadFile("../testdata/raw.json")
+	require.NoError(t, err)
+
+	var jd []any
+	require.NoError(t, json.Unmarshal(raw, &jd))
+	change(jd, jd[6].([]any))
+
+	raw, err = json.Marshal(jd)
+	require.NoError(t, err)
+
+	return raw
+}
+
+func TestEntryFromJSONLocation(t *testing.T) {
+	tests := []struct {
+		name         string
+		change       func(jd, darray []any)
+		wantPlusCode string
+		wantTimezone string
+		wantMissing  []string
+		wantFailed   []string
+	}{
+		{
+			name:         "as scraped",
+			change:       func(_, _ []any) {},
+			wantPlusCode: "M2CR+6X Limassol",
+			wantTimezone: "Asia/Nicosia",
+		},
+		{
+			name: "global plus code and repeated timezone",
+			change: func(jd, darray []any) {
+				darray[183].([]any)[2].([]any)[2] = nil
+				darray[30] = nil
+			},
+			wantPlusCode: "8G6MM2CR+6X",
+			wantTimezone: "Asia/Nicosia",
+		},
+		{
+			name: "filled in from the coordinates",
+			change: func(jd, darray []any) {
+				darray[183].([]any)[2] = nil
+				darray[30] = nil
+				jd[31] = nil
+			},
+			wantPlusCode: "8G6MM2CR+6X",
+			wantTimezone: "Asia/Nicosia",
+			wantMissing:  []string{"plus_code", "timezone"},
+		},
+		{
+			name: "timezone that is not one",
+			change: func(jd, darray []any) {
+				darray[30] = "Closed ⋅ Opens 9 AM"
+				jd[31] = nil
+			},
+			wantPlusCode: "M2CR+6X Limassol",
+			wantTimezone: "Asia/Nicosia",
+			wantFailed:   []string{"timezone"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := gmaps.EntryFromJSON(rawWith(t, tt.change))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantPlusCode, entry.PlusCode)
+			assert.Equal(t, tt.wantTimezone, entry.Timezone)
+			assert.Subset(t, entry.ParseReport.Missing, tt.wantMissing)
+			assert.Equal(t, tt.wantFailed, entry.ParseReport.Failed)
+		})
+	}
+}
+
+func TestFillLocation(t *testing.T) {
+	entry := gmaps.Entry{Latitude: 41.8781, Longitude: -87.6298, Timezone: "Local"}
+	entry.CompleteAddress.Country = "US"
+	entry.FillLocation()
+
+	assert.Equal(t, "86HJV9HC+63", entry.PlusCode)
+	assert.Equal(t, "America/Chicago", entry.Timezone)
+
+	kept := gmaps.Entry{Latitude: 41.8781, Longitude: -87.6298, PlusCode: "V9HC+6C Chicago", Timezone: "America/Chicago"}
+	kept.FillLocation()
+	assert.Equal(t, "V9HC+6C Chicago", kept.PlusCode)
+
+	var none gmaps.Entry
+	none.FillLocation()
+	assert.Empty(t, none.PlusCode)
+	assert.Empty(t, none.Timezone)
+}
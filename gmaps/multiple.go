@@ -4,8 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
-
-	olc "github.com/google/open-location-code/go"
 )
 
 func ParseSearchResults(raw []byte) ([]*Entry, error) {
@@ -72,7 +70,7 @@ func ParseSearchResults(raw []byte) ([]*Entry, error) {
 		entry.Timezone = getNthElementAndCast[string](business, 30)
 		entry.DataID = getNthElementAndCast[string](business, 10)
 
-		entry.PlusCode = olc.Encode(entry.Latitude, entry.Longitude, 10)
+		entry.FillLocation()
 
 		entries = append(entries, &entry)
 	}
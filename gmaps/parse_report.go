@@ -0,0 +1,125 @@
+package gmaps
+
+// ParseReport lists the fields of an Entry that could not be read from
+// Google's place data, by their JSON name. A missing field has no value
+// where Google puts it, which is normal for optional fields like the menu;
+// a failed field holds a value of another type, or sits below an element
+// that is not an array, which means the layout changed.
+type ParseReport struct {
+	Missing []string `json:"missing,omitempty"`
+	Failed  []string `json:"failed,omitempty"`
+}
+
+// OK reports whether every field was read
+func (r *ParseReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.Failed) == 0
+}
+
+// fieldStatus is the outcome of reading a field from the place data
+type fieldStatus int
+
+const (
+	fieldOK fieldStatus = iota
+	fieldMissing
+	fieldFailed
+)
+
+// record notes the outcome of reading a field, once per field
+func (r *ParseReport) record(field string, status fieldStatus) {
+	switch status {
+	case fieldMissing:
+		r.Missing = append(r.Missing, field)
+	case fieldFailed:
+		r.Failed = append(r.Failed, field)
+	}
+}
+
+// lookup follows indexes into arr. An index past the end or a null is a
+// value Google left out; an element that should be an array and is not is
+// a layout that does not match.
+func lookup(arr []any, indexes ...int) (any, fieldStatus) {
+	if len(indexes) == 0 {
+		return nil, fieldFailed
+	}
+
+	var cur any = arr
+
+	for _, idx := range indexes {
+		if cur == nil {
+			return nil, fieldMissing
+		}
+
+		next, ok := cur.([]any)
+		if !ok || idx < 0 {
+			return nil, fieldFailed
+		}
+
+		if idx >= len(next) {
+			return nil, fieldMissing
+		}
+
+		cur = next[idx]
+	}
+
+	if cur == nil {
+		return nil, fieldMissing
+	}
+
+	return cur, fieldOK
+}
+
+// readAt returns the value at indexes as a T, and how reading it went
+func readAt[T any](arr []any, indexes ...int) (T, fieldStatus) {
+	var zero T
+
+	v, status := lookup(arr, indexes...)
+	if status != fieldOK {
+		return zero, status
+	}
+
+	ans, ok := v.(T)
+	if !ok {
+		return zero, fieldFailed
+	}
+
+	return ans, fieldOK
+}
+
+// readField reads the named field at indexes as a T, recording in report
+// when it is missing or has another type. It returns the zero value then
+// and never panics.
+func readField[T any](report *ParseReport, field string, arr []any, indexes ...int) T {
+	v, status := readAt[T](arr, indexes...)
+	report.record(field, status)
+
+	return v
+}
+
+// readFirst is readField for a field Google keeps at one of several
+// places: the first path holding a T, other than an empty array, wins. A
+// field none of them holds is failed when one of the paths did not match
+// the layout, missing otherwise.
+func readFirst[T any](report *ParseReport, field string, arr []any, paths ...[]int) T {
+	var zero T
+
+	worst := fieldMissing
+
+	for _, path := range paths {
+		v, status := readAt[T](arr, path...)
+		if items, ok := any(v).([]any); ok && status == fieldOK && len(items) == 0 {
+			continue
+		}
+
+		if status == fieldOK {
+			return v
+		}
+
+		if status == fieldFailed {
+			worst = fieldFailed
+		}
+	}
+
+	report.record(field, worst)
+
+	return zero
+}
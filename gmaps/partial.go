@@ -0,0 +1,82 @@
+package gmaps
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// CompletenessPartial marks an entry read from the search results alone,
+// as the page of the place failed. Entries scraped from their page leave
+// DataCompleteness empty.
+const CompletenessPartial = "partial"
+
+var (
+	// Place links carry the data ID as !1s0x..:0x.. and the coordinates as
+	// !3d<lat>!4d<lon>
+	placeDataIDRe = regexp.MustCompile(`!1s(0x[0-9a-f]+:0x[0-9a-f]+)`)
+	placeCoordsRe = regexp.MustCompile(`!3d(-?\d+(?:\.\d+)?)!4d(-?\d+(?:\.\d+)?)`)
+	reviewCountRe = regexp.MustCompile(`[\d.,\s]+`)
+)
+
+// partialEntry reads what the search results card of link shows of the
+// place: its title, category, rating and coordinates. It returns nil when
+// the card has no title.
+func partialEntry(link *goquery.Selection, href string) *Entry {
+	card := link.Parent()
+
+	entry := Entry{
+		Link:             href,
+		Title:            strings.TrimSpace(link.AttrOr("aria-label", "")),
+		DataCompleteness: CompletenessPartial,
+	}
+	if entry.Title == "" {
+		entry.Title = strings.TrimSpace(card.Find(".qBF1Pd").First().Text())
+	}
+	if entry.Title == "" {
+		return nil
+	}
+
+	if m := placeDataIDRe.FindStringSubmatch(href); m != nil {
+		entry.DataID = m[1]
+		if _, cid, ok := strings.Cut(m[1], ":"); ok {
+			if n, err := strconv.ParseUint(strings.TrimPrefix(cid, "0x"), 16, 64); err == nil {
+				entry.Cid = strconv.FormatUint(n, 10)
+			}
+		}
+	}
+
+	if m := placeCoordsRe.FindStringSubmatch(href); m != nil {
+		entry.Latitude, _ = strconv.ParseFloat(m[1], 64)
+		entry.Longitude, _ = strconv.ParseFloat(m[2], 64)
+	}
+
+	if rating := card.Find("span.MW4etd").First().Text(); rating != "" {
+		entry.ReviewRating, _ = strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(rating), ",", "."), 64)
+	}
+
+	if count := reviewCountRe.FindString(card.Find("span.UY7F9").First().Text()); count != "" {
+		entry.ReviewCount, _ = strconv.Atoi(strings.NewReplacer(",", "", ".", "", " ", "").Replace(count))
+	}
+
+	// The category opens the first line of details below the rating, as
+	// "Category · Address"
+	card.Find(".W4Efsd .W4Efsd").EachWithBreak(func(_ int, line *goquery.Selection) bool {
+		category, _, _ := strings.Cut(line.Text(), "·")
+		category = strings.TrimSpace(category)
+		if category == "" || strings.ContainsAny(category[:1], "0123456789(") {
+			return true
+		}
+
+		entry.Category = category
+		entry.Categories = []string{category}
+
+		return false
+	})
+
+	entry.FillLocation()
+
+	return &entry
+}
@@ -0,0 +1,66 @@
+package gmaps_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/exiter"
+	"github.com/sadewadee/google-scraper/gmaps"
+)
+
+const searchResults = `<div role="feed">
+	<div><div jsaction="mouseover:pane">
+		<a class="hfpxzc" aria-label="Trattoria Napoli" href="https://www.google.com/maps/place/Trattoria+Napoli/data=!4m7!3m6!1s0x47e66e2964e34e2d:0x8ddca9ee380ef7e0!8m2!3d48.8583701!4d2.2944813!16s"></a>
+		<div class="qBF1Pd">Trattoria Napoli</div>
+		<div class="W4Efsd"><span class="MW4etd">4,6</span><span class="UY7F9">(1.234)</span></div>
+		<div class="W4Efsd"><div class="W4Efsd"><span>Italian restaurant</span> · <span>Rue Cler 12</span></div></div>
+	</div></div>
+</div>`
+
+func TestPlaceJobKeepsSearchResultOnFailure(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(searchResults))
+	require.NoError(t, err)
+
+	progress := exiter.New()
+	search := gmaps.NewGmapJob("kw-1", "fr", "restaurants", 1, false, "", 0, gmaps.WithExitMonitor(progress))
+
+	_, next, err := search.Process(context.Background(), &scrapemate.Response{URL: search.URL, Document: doc})
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+
+	place, ok := next[0].(*gmaps.PlaceJob)
+	require.True(t, ok)
+	assert.True(t, place.ProcessOnFetchError())
+
+	result, _, err := place.Process(context.Background(), &scrapemate.Response{Error: errors.New("timeout")})
+	require.NoError(t, err)
+
+	entry, ok := result.(*gmaps.Entry)
+	require.True(t, ok)
+	assert.Equal(t, gmaps.CompletenessPartial, entry.DataCompleteness)
+	assert.Equal(t, "kw-1", entry.ID)
+	assert.Equal(t, "fr", entry.Lang)
+	assert.Equal(t, "Trattoria Napoli", entry.Title)
+	assert.Equal(t, "Italian restaurant", entry.Category)
+	assert.InDelta(t, 4.6, entry.ReviewRating, 0.001)
+	assert.Equal(t, 1234, entry.ReviewCount)
+	assert.InDelta(t, 48.8583701, entry.Latitude, 1e-7)
+	assert.InDelta(t, 2.2944813, entry.Longitude, 1e-7)
+	assert.Equal(t, "0x47e66e2964e34e2d:0x8ddca9ee380ef7e0", entry.DataID)
+	assert.Equal(t, "10222232094831998944", entry.Cid)
+
+	assert.Equal(t, exiter.Progress{PlacesPartial: 1, SeedCompleted: 1}, progress.Progress())
+
+	// Without a search result to fall back on the failure stands
+	bare := gmaps.NewPlaceJob("kw-1", "fr", entry.Link, false, false)
+	assert.False(t, bare.ProcessOnFetchError())
+	_, _, err = bare.Process(context.Background(), &scrapemate.Response{Error: errors.New("timeout")})
+	assert.Error(t, err)
+}
@@ -11,10 +11,21 @@ import (
 
 	"github.com/sadewadee/google-scraper/exiter"
 	"github.com/sadewadee/google-scraper/internal/emailvalidator"
+	"github.com/sadewadee/google-scraper/ratelimit"
 )
 
 type PlaceJobOptions func(*PlaceJob)
 
+// PageCache keeps the raw payloads of fetched place pages, so they can be
+// parsed again without fetching them. Put must not block.
+type PageCache interface {
+	Put(url string, payload []byte)
+}
+
+// DefaultMaxImages is how many photo URLs a place keeps when the job does
+// not set a limit
+const DefaultMaxImages = 5
+
 type PlaceJob struct {
 	scrapemate.Job
 
@@ -22,7 +33,17 @@ type PlaceJob struct {
 	ExtractEmail        bool
 	ExitMonitor         exiter.Exiter
 	ExtractExtraReviews bool
+	MaxReviews          int
+	ReviewsSort         ReviewSort
+	MaxImages           int
 	EmailValidator      emailvalidator.Validator
+	EmailPages          int // Contact pages searched for emails besides the website
+	RateLimiter         ratelimit.Limiter
+	PageCache           PageCache
+
+	// Partial is what the search results listed of the place, returned
+	// instead of nothing when its page fails
+	Partial *Entry
 }
 
 func NewPlaceJob(parentID, langCode, u string, extractEmail, extraExtraReviews bool, opts ...PlaceJobOptions) *PlaceJob {
@@ -66,21 +87,106 @@ func WithPlaceJobEmailValidator(validator emailvalidator.Validator) PlaceJobOpti
 	}
 }
 
-func (j *PlaceJob) Process(_ context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+func WithPlaceJobRateLimiter(l ratelimit.Limiter) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.RateLimiter = l
+	}
+}
+
+func WithPlaceJobReviewLimit(maxReviews int, sort ReviewSort) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.MaxReviews = maxReviews
+		j.ReviewsSort = sort
+	}
+}
+
+// WithPlaceJobMaxImages caps the photo URLs kept for the place
+func WithPlaceJobMaxImages(maxImages int) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.MaxImages = maxImages
+	}
+}
+
+// WithPlaceJobHeaders sets the request headers, like User-Agent
+func WithPlaceJobHeaders(headers map[string]string) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.Headers = headers
+	}
+}
+
+// WithPlaceJobEmailPages makes the email job also search up to pages
+// contact, imprint and about pages of the website
+func WithPlaceJobEmailPages(pages int) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.EmailPages = pages
+	}
+}
+
+// WithPlaceJobPageCache stores the raw payload of the page in cache
+func WithPlaceJobPageCache(cache PageCache) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.PageCache = cache
+	}
+}
+
+// WithPlaceJobPartial keeps partial, read from the search results, should
+// the page of the place fail
+func WithPlaceJobPartial(partial *Entry) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.Partial = partial
+	}
+}
+
+// ProcessOnFetchError lets Process return the partial entry once
+// scrapemate has given up retrying the page
+func (j *PlaceJob) ProcessOnFetchError() bool {
+	return j.Partial != nil
+}
+
+// partialResult returns the partial entry in place of the failed page, or
+// err without one
+func (j *PlaceJob) partialResult(ctx context.Context, err error) (any, []scrapemate.IJob, error) {
+	if j.Partial == nil {
+		return nil, nil, err
+	}
+
+	scrapemate.GetLoggerFromContext(ctx).Info(fmt.Sprintf("keeping the search result of %s: %v", j.GetURL(), err))
+
+	entry := *j.Partial
+	entry.ID = j.ParentID
+	entry.Lang = j.URLParams["hl"]
+
+	if j.ExitMonitor != nil {
+		j.ExitMonitor.IncrPlacesPartial(1)
+	}
+
+	return &entry, nil, nil
+}
+
+func (j *PlaceJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
 		resp.Body = nil
 		resp.Meta = nil
 	}()
 
+	if resp.Error != nil {
+		return j.partialResult(ctx, resp.Error)
+	}
+
 	raw, ok := resp.Meta["json"].([]byte)
 	if !ok {
-		return nil, nil, fmt.Errorf("could not convert to []byte")
+		return j.partialResult(ctx, fmt.Errorf("could not convert to []byte"))
+	}
+
+	// Cached before parsing: a page the parser fails on is worth keeping
+	if j.PageCache != nil {
+		j.PageCache.Put(j.GetURL(), raw)
 	}
 
 	entry, err := EntryFromJSON(raw)
 	if err != nil {
-		return nil, nil, err
+		return j.partialResult(ctx, err)
 	}
 
 	entry.ID = j.ParentID
@@ -89,6 +195,9 @@ func (j *PlaceJob) Process(_ context.Context, resp *scrapemate.Response) (any, [
 		entry.Link = j.GetURL()
 	}
 
+	entry.Lang = j.URLParams["hl"]
+	entry.ParseOpenHours(entry.Lang)
+
 	// Handle RPC-based reviews
 	allReviewsRaw, ok := resp.Meta["reviews_raw"].(FetchReviewsResponse)
 	if ok && len(allReviewsRaw.pages) > 0 {
@@ -102,14 +211,30 @@ func (j *PlaceJob) Process(_ context.Context, resp *scrapemate.Response) (any, [
 		entry.UserReviewsExtended = append(entry.UserReviewsExtended, convertedReviews...)
 	}
 
+	if j.MaxReviews > 0 && len(entry.UserReviewsExtended) > j.MaxReviews {
+		entry.UserReviewsExtended = entry.UserReviewsExtended[:j.MaxReviews]
+	}
+
+	maxImages := j.MaxImages
+	if maxImages <= 0 {
+		maxImages = DefaultMaxImages
+	}
+
+	if len(entry.ImageURLs) > maxImages {
+		entry.ImageURLs = entry.ImageURLs[:maxImages]
+	}
+
 	if j.ExtractEmail && entry.IsWebsiteValidForEmail() {
-		opts := []EmailExtractJobOptions{}
+		opts := []EmailExtractJobOptions{WithEmailJobPlaceURL(j.GetURL())}
 		if j.ExitMonitor != nil {
 			opts = append(opts, WithEmailJobExitMonitor(j.ExitMonitor))
 		}
 		if j.EmailValidator != nil {
 			opts = append(opts, WithEmailValidatorOption(j.EmailValidator))
 		}
+		if j.EmailPages > 0 {
+			opts = append(opts, WithEmailJobPages(j.EmailPages))
+		}
 
 		emailJob := NewEmailJob(j.ID, &entry, opts...)
 
@@ -126,6 +251,18 @@ func (j *PlaceJob) Process(_ context.Context, resp *scrapemate.Response) (any, [
 func (j *PlaceJob) BrowserActions(ctx context.Context, page scrapemate.BrowserPage) scrapemate.Response {
 	var resp scrapemate.Response
 
+	if err := waitForSlot(ctx, j.RateLimiter); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
+	if err := setPageHeaders(page, j.Headers); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
 	pageResponse, err := page.Goto(j.GetURL(), scrapemate.WaitUntilDOMContentLoaded)
 	if err != nil {
 		resp.Error = err
@@ -135,6 +272,12 @@ func (j *PlaceJob) BrowserActions(ctx context.Context, page scrapemate.BrowserPa
 
 	clickRejectCookiesIfRequired(page)
 
+	if blocked := detectBlock(page.URL(), pageResponse.StatusCode, ""); blocked != nil {
+		resp.Error = reportBlocked(j.RateLimiter, j.ExitMonitor, blocked)
+
+		return resp
+	}
+
 	const defaultTimeout = 5 * time.Second
 
 	err = page.WaitForURL(page.URL(), defaultTimeout)
@@ -150,11 +293,20 @@ func (j *PlaceJob) BrowserActions(ctx context.Context, page scrapemate.BrowserPa
 
 	raw, err := j.extractJSON(page)
 	if err != nil {
+		// a missing APP_INITIALIZATION_STATE usually means we were served a block page
+		if blocked := detectBlockOnPage(page, pageResponse.StatusCode); blocked != nil {
+			resp.Error = reportBlocked(j.RateLimiter, j.ExitMonitor, blocked)
+
+			return resp
+		}
+
 		resp.Error = err
 
 		return resp
 	}
 
+	reportSuccess(j.RateLimiter)
+
 	if resp.Meta == nil {
 		resp.Meta = make(map[string]any)
 	}
@@ -168,6 +320,8 @@ func (j *PlaceJob) BrowserActions(ctx context.Context, page scrapemate.BrowserPa
 				page:        page,
 				mapURL:      page.URL(),
 				reviewCount: reviewCount,
+				maxReviews:  j.MaxReviews,
+				sort:        j.ReviewsSort,
 			}
 
 			// Use the new fallback mechanism that tries RPC first, then DOM
@@ -0,0 +1,77 @@
+package gmaps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// maxProbeBody bounds the search response read by CountSearchResults
+	maxProbeBody = 8 << 20
+
+	probeUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"
+)
+
+// CountSearchResults runs a single search over plain HTTP, without a
+// browser, and returns how many results lie within params.Location.Radius.
+// It is meant for cheap probes such as checking whether a grid cell has
+// any places at all; scraping goes through SearchJob. A nil client uses a
+// client with a 15 second timeout.
+func CountSearchResults(ctx context.Context, client *http.Client, params *MapSearchParams) (int, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+
+	query := url.Values{}
+	for k, v := range buildGoogleMapsParams(params) {
+		query.Set(k, v)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL+"?"+query.Encode(), http.NoBody)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", probeUserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBody))
+	if err != nil {
+		return 0, fmt.Errorf("read search response: %w", err)
+	}
+
+	if blocked := detectBlock(resp.Request.URL.String(), resp.StatusCode, string(body)); blocked != nil {
+		return 0, blocked
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("search returned status %d", resp.StatusCode)
+	}
+
+	body = removeFirstLine(body)
+	if !json.Valid(body) {
+		return 0, fmt.Errorf("unexpected search response")
+	}
+
+	entries, err := ParseSearchResults(body)
+	if err != nil {
+		// A well-formed answer without a business list has no places
+		return 0, nil
+	}
+
+	entries = filterAndSortEntriesWithinRadius(entries,
+		params.Location.Lat,
+		params.Location.Lon,
+		params.Location.Radius,
+	)
+
+	return len(entries), nil
+}
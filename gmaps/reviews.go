@@ -18,10 +18,37 @@ import (
 	"github.com/gosom/scrapemate/adapters/fetchers/stealth"
 )
 
+// ReviewSort is the order extra reviews are fetched in
+type ReviewSort string
+
+const (
+	ReviewSortRelevant ReviewSort = "relevant"
+	ReviewSortNewest   ReviewSort = "newest"
+)
+
+// IsValid reports whether s is a known sort order; empty means the default
+func (s ReviewSort) IsValid() bool {
+	return s == "" || s == ReviewSortRelevant || s == ReviewSortNewest
+}
+
+// rpcValue is the sort code used by the listugcposts endpoint
+func (s ReviewSort) rpcValue() int {
+	if s == ReviewSortNewest {
+		return 2
+	}
+
+	return 1
+}
+
+// reviewPageSize is the number of reviews requested per RPC page
+const reviewPageSize = 20
+
 type fetchReviewsParams struct {
 	page        scrapemate.BrowserPage
 	mapURL      string
 	reviewCount int
+	maxReviews  int // 0 fetches every page
+	sort        ReviewSort
 }
 
 type FetchReviewsResponse struct {
@@ -49,7 +76,7 @@ func (f *fetcher) fetch(ctx context.Context) (FetchReviewsResponse, error) {
 		return FetchReviewsResponse{}, fmt.Errorf("failed to generate session request ID: %v", err)
 	}
 
-	reviewURL, err := f.generateURL(f.params.mapURL, "", 20, requestIDForSession)
+	reviewURL, err := f.generateURL(f.params.mapURL, "", reviewPageSize, requestIDForSession)
 	if err != nil {
 		return FetchReviewsResponse{}, fmt.Errorf("failed to generate initial URL: %v", err)
 	}
@@ -76,8 +103,8 @@ func (f *fetcher) fetch(ctx context.Context) (FetchReviewsResponse, error) {
 
 	nextPageToken := extractNextPageToken(currentPageBody)
 
-	for nextPageToken != "" {
-		reviewURL, err = f.generateURL(f.params.mapURL, nextPageToken, 20, requestIDForSession)
+	for nextPageToken != "" && !f.enough(len(ans.pages)) {
+		reviewURL, err = f.generateURL(f.params.mapURL, nextPageToken, reviewPageSize, requestIDForSession)
 		if err != nil {
 			log.Printf("Error generating URL for token %s: %v", nextPageToken, err)
 			break
@@ -145,8 +172,8 @@ func (f *fetcher) fetchWithBrowser(_ context.Context, initialURL, requestID stri
 
 	// Get additional pages
 	nextPageToken := extractNextPageToken([]byte(data))
-	for nextPageToken != "" && len(ans.pages) < 50 { // Limit to 50 pages
-		nextURL, err := f.generateURL(f.params.mapURL, nextPageToken, 20, requestID)
+	for nextPageToken != "" && len(ans.pages) < 50 && !f.enough(len(ans.pages)) { // Limit to 50 pages
+		nextURL, err := f.generateURL(f.params.mapURL, nextPageToken, reviewPageSize, requestID)
 		if err != nil {
 			break
 		}
@@ -188,6 +215,11 @@ func (f *fetcher) fetchWithBrowser(_ context.Context, initialURL, requestID stri
 	return ans, nil
 }
 
+// enough reports whether pages already cover the requested review count
+func (f *fetcher) enough(pages int) bool {
+	return f.params.maxReviews > 0 && pages*reviewPageSize >= f.params.maxReviews
+}
+
 var (
 	patternsOnce sync.Once
 	patterns     map[string]*regexp.Regexp
@@ -246,13 +278,13 @@ func (f *fetcher) generateURL(mapURL, pageToken string, pageSize int, requestID
 		fmt.Sprintf("!2m2!1i%d!2s%s", pageSize, encodedPageToken),
 		fmt.Sprintf("!5m2!1s%s!7e81", requestID),
 		"!8m9!2b1!3b1!5b1!7b1",
-		"!12m4!1b1!2b1!4m1!1e1!11m0!13m1!1e1",
+		fmt.Sprintf("!12m4!1b1!2b1!4m1!1e1!11m0!13m1!1e%d", f.params.sort.rpcValue()),
 	}
 
-	// Use English language for consistent parsing
+	// Use English language for consistent parsing, on the domain of the place
 	fullURL := fmt.Sprintf(
-		"https://www.google.com/maps/rpc/listugcposts?authuser=0&hl=en&pb=%s",
-		strings.Join(pbComponents, ""),
+		"https://www.%s/maps/rpc/listugcposts?authuser=0&hl=en&pb=%s",
+		URLDomain(mapURL), strings.Join(pbComponents, ""),
 	)
 
 	return fullURL, nil
@@ -329,6 +361,7 @@ type DOMReview struct {
 	Rating                  int
 	RelativeTimeDescription string
 	Text                    string
+	OwnerReply              string
 	Images                  []string
 }
 
@@ -343,6 +376,7 @@ func ConvertDOMReviewsToReviews(domReviews []DOMReview) []Review {
 			Rating:         dr.Rating,
 			Description:    dr.Text,
 			When:           dr.RelativeTimeDescription,
+			OwnerReply:     dr.OwnerReply,
 			Images:         dr.Images,
 		}
 		if review.Name != "" {
@@ -355,7 +389,7 @@ func ConvertDOMReviewsToReviews(domReviews []DOMReview) []Review {
 
 // extractReviewsFromPage extracts reviews directly from the page DOM
 // This is a fallback when the RPC API fails
-func extractReviewsFromPage(ctx context.Context, page scrapemate.BrowserPage) ([]DOMReview, error) {
+func extractReviewsFromPage(ctx context.Context, page scrapemate.BrowserPage, maxReviews int) ([]DOMReview, error) {
 	log.Printf("Attempting DOM-based review extraction")
 
 	// First, try to click the reviews section to open the reviews panel
@@ -573,6 +607,10 @@ func extractReviewsFromPage(ctx context.Context, page scrapemate.BrowserPage) ([
 							}
 						}
 
+						// Response from the owner
+						const replyEl = element.querySelector('.CDe7pd .wiI7pd');
+						const ownerReply = replyEl ? (replyEl.textContent?.trim() || '') : '';
+
 						// Images
 						const imageElements = element.querySelectorAll('.KtCyie img, .Tya61d img, .review-photos img, img[src*="lh3"]');
 						const images = [];
@@ -591,6 +629,7 @@ func extractReviewsFromPage(ctx context.Context, page scrapemate.BrowserPage) ([
 								rating: rating,
 								relative_time_description: relativeTime,
 								text: text,
+								owner_reply: ownerReply,
 								images: images
 							});
 						}
@@ -642,6 +681,10 @@ func extractReviewsFromPage(ctx context.Context, page scrapemate.BrowserPage) ([
 						review.Text = v
 					}
 
+					if v, ok := reviewMap["owner_reply"].(string); ok {
+						review.OwnerReply = v
+					}
+
 					if v, ok := reviewMap["images"].([]interface{}); ok {
 						for _, img := range v {
 							if imgStr, ok := img.(string); ok {
@@ -676,6 +719,10 @@ func extractReviewsFromPage(ctx context.Context, page scrapemate.BrowserPage) ([
 		}
 
 		currentCount := len(reviews)
+		if maxReviews > 0 && currentCount >= maxReviews {
+			break
+		}
+
 		if currentCount == lastCount {
 			stuckCount++
 			if stuckCount > 5 {
@@ -749,7 +796,7 @@ func FetchReviewsWithFallback(ctx context.Context, params fetchReviewsParams) (F
 
 	// Fallback to DOM-based extraction
 	if params.page != nil {
-		domReviews, domErr := extractReviewsFromPage(ctx, params.page)
+		domReviews, domErr := extractReviewsFromPage(ctx, params.page, params.maxReviews)
 		if domErr == nil && len(domReviews) > 0 {
 			log.Printf("DOM extraction successful: %d reviews", len(domReviews))
 			return FetchReviewsResponse{}, domReviews, nil
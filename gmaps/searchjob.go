@@ -5,12 +5,16 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sadewadee/google-scraper/exiter"
+	"github.com/sadewadee/google-scraper/ratelimit"
 	"github.com/gosom/scrapemate"
 )
 
+const searchURL = "https://maps.google.com/search"
+
 type SearchJobOptions func(*SearchJob)
 
 type MapLocation struct {
@@ -33,20 +37,22 @@ type SearchJob struct {
 
 	params      *MapSearchParams
 	ExitMonitor exiter.Exiter
+	RateLimiter ratelimit.Limiter
+
+	ctx context.Context // Bounds the backoff after a block page, nil for none
 }
 
 func NewSearchJob(params *MapSearchParams, opts ...SearchJobOptions) *SearchJob {
 	const (
 		defaultPrio       = scrapemate.PriorityMedium
 		defaultMaxRetries = 3
-		baseURL           = "https://maps.google.com/search"
 	)
 
 	job := SearchJob{
 		Job: scrapemate.Job{
 			ID:         uuid.New().String(),
 			Method:     http.MethodGet,
-			URL:        baseURL,
+			URL:        searchURL,
 			URLParams:  buildGoogleMapsParams(params),
 			MaxRetries: defaultMaxRetries,
 			Priority:   defaultPrio,
@@ -68,6 +74,64 @@ func WithSearchJobExitMonitor(exitMonitor exiter.Exiter) SearchJobOptions {
 	}
 }
 
+func WithSearchJobRateLimiter(l ratelimit.Limiter) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.RateLimiter = l
+	}
+}
+
+// WithSearchJobHeaders sets the request headers, like User-Agent. The
+// stealth fetcher ignores them and sends its own.
+func WithSearchJobHeaders(headers map[string]string) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.Headers = headers
+	}
+}
+
+// SetContext makes the backoff after a block page end when ctx is done.
+// Scrapemate does not hand the context to DoCheckResponse.
+func (j *SearchJob) SetContext(ctx context.Context) {
+	j.ctx = ctx
+}
+
+// DoCheckResponse rejects block pages so scrapemate retries them, and
+// backs off for the limiter's current delay before it does.
+func (j *SearchJob) DoCheckResponse(resp *scrapemate.Response) bool {
+	if blocked := detectBlock(resp.URL, resp.StatusCode, string(resp.Body)); blocked != nil {
+		resp.Error = reportBlocked(j.RateLimiter, j.ExitMonitor, blocked)
+
+		if j.RateLimiter != nil {
+			j.backoff(j.RateLimiter.Delay())
+		}
+
+		return false
+	}
+
+	if !j.Job.DoCheckResponse(resp) {
+		return false
+	}
+
+	reportSuccess(j.RateLimiter)
+
+	return true
+}
+
+// backoff waits for d, or until the job's context is done
+func (j *SearchJob) backoff(d time.Duration) {
+	ctx := j.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
 func (j *SearchJob) Process(_ context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
@@ -85,6 +149,10 @@ func (j *SearchJob) Process(_ context.Context, resp *scrapemate.Response) (any,
 		return nil, nil, fmt.Errorf("failed to parse search results: %w", err)
 	}
 
+	for _, entry := range entries {
+		entry.ParseOpenHours(j.params.Hl)
+	}
+
 	entries = filterAndSortEntriesWithinRadius(entries,
 		j.params.Location.Lat,
 		j.params.Location.Lon,
@@ -0,0 +1,238 @@
+package gmaps
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Social networks recognised in website links. The keys are the ones used
+// in Entry.SocialLinks.
+const (
+	SocialFacebook  = "facebook"
+	SocialInstagram = "instagram"
+	SocialLinkedIn  = "linkedin"
+	SocialWhatsApp  = "whatsapp"
+	SocialTwitter   = "twitter"
+	SocialYouTube   = "youtube"
+	SocialTikTok    = "tiktok"
+)
+
+var socialDomains = []struct {
+	domain  string
+	network string
+}{
+	{"facebook.com", SocialFacebook},
+	{"fb.com", SocialFacebook},
+	{"fb.me", SocialFacebook},
+	{"instagram.com", SocialInstagram},
+	{"linkedin.com", SocialLinkedIn},
+	{"wa.me", SocialWhatsApp},
+	{"whatsapp.com", SocialWhatsApp},
+	{"twitter.com", SocialTwitter},
+	{"x.com", SocialTwitter},
+	{"youtube.com", SocialYouTube},
+	{"youtu.be", SocialYouTube},
+	{"tiktok.com", SocialTikTok},
+}
+
+// socialSkipPaths are path prefixes of share buttons, login walls and
+// similar links that do not point at the business profile. A prefix not
+// ending in a slash matches a whole path segment, with or without an
+// extension: /tr matches /tr and /tr.php, not /trattoria.
+var socialSkipPaths = map[string][]string{
+	SocialFacebook:  {"/sharer", "/share", "/dialog", "/plugins", "/tr", "/login", "/policies", "/help"},
+	SocialInstagram: {"/p/", "/reel/", "/explore", "/accounts", "/about"},
+	SocialLinkedIn:  {"/sharearticle", "/sharing", "/share", "/login", "/feed"},
+	SocialTwitter:   {"/intent", "/share", "/home", "/login", "/hashtag", "/search"},
+	SocialYouTube:   {"/watch", "/embed", "/results", "/redirect"},
+	SocialTikTok:    {"/embed", "/share", "/video"},
+}
+
+// extractSocialLinks returns one profile URL per network found in the
+// anchors of doc. When a network is linked several times the shortest
+// canonical URL wins, which drops most deep links and tracking variants.
+func extractSocialLinks(doc *goquery.Document) map[string]string {
+	var links map[string]string
+
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+
+		network, canonical := canonicalSocialURL(href)
+		if network == "" {
+			return
+		}
+
+		if current, ok := links[network]; ok && len(current) <= len(canonical) {
+			return
+		}
+
+		if links == nil {
+			links = make(map[string]string)
+		}
+
+		links[network] = canonical
+	})
+
+	return links
+}
+
+// canonicalSocialURL maps href to its network and a canonical https URL
+// without query string or trailing slash. Protocol-relative links are
+// taken as https. It returns empty strings for links that are not
+// profiles.
+func canonicalSocialURL(href string) (network, canonical string) {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return "", ""
+	}
+
+	if u.Scheme == "" && u.Host != "" {
+		u.Scheme = "https"
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, prefix := range []string{"www.", "m.", "mobile.", "web.", "api."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+
+	for _, d := range socialDomains {
+		if host == d.domain || strings.HasSuffix(host, "."+d.domain) {
+			network = d.network
+			break
+		}
+	}
+
+	if network == "" {
+		return "", ""
+	}
+
+	path := strings.TrimRight(u.EscapedPath(), "/")
+
+	if network == SocialWhatsApp {
+		return canonicalWhatsApp(host, path, u.Query())
+	}
+
+	if path == "" {
+		return "", ""
+	}
+
+	lower := strings.ToLower(path)
+	for _, skip := range socialSkipPaths[network] {
+		if skipsPath(lower, skip) {
+			return "", ""
+		}
+	}
+
+	// Facebook pages without a vanity name are only addressable by id
+	if network == SocialFacebook && lower == "/profile.php" {
+		id := u.Query().Get("id")
+		if id == "" {
+			return "", ""
+		}
+
+		return network, "https://www.facebook.com/profile.php?id=" + url.QueryEscape(id)
+	}
+
+	return network, "https://" + host + path
+}
+
+// skipsPath tells whether path starts with the socialSkipPaths prefix skip
+func skipsPath(path, skip string) bool {
+	if !strings.HasPrefix(path, skip) {
+		return false
+	}
+
+	if len(path) == len(skip) || strings.HasSuffix(skip, "/") {
+		return true
+	}
+
+	next := path[len(skip)]
+	return next == '/' || next == '.'
+}
+
+// canonicalWhatsApp turns click-to-chat links into https://wa.me/<number>.
+// Links without a phone number are share buttons and are dropped.
+func canonicalWhatsApp(host, path string, query url.Values) (network, canonical string) {
+	var phone string
+
+	switch {
+	case host == "wa.me":
+		phone, _ = url.PathUnescape(strings.TrimPrefix(path, "/"))
+	case path == "/send":
+		phone = query.Get("phone")
+	}
+
+	phone = digitsOnly(phone)
+	if len(phone) < 7 {
+		return "", ""
+	}
+
+	return SocialWhatsApp, "https://wa.me/" + phone
+}
+
+// extractTelPhone returns the first plausible number in a tel: link,
+// keeping a leading + and digits only. The scheme is matched in any case,
+// as in TEL: or Tel:.
+func extractTelPhone(doc *goquery.Document) string {
+	var phone string
+
+	doc.Find("a[href]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		href, _ := s.Attr("href")
+
+		href = strings.TrimSpace(href)
+		if len(href) < len("tel:") || !strings.EqualFold(href[:len("tel:")], "tel:") {
+			return true
+		}
+
+		raw, err := url.PathUnescape(href[len("tel:"):])
+		if err != nil {
+			return true
+		}
+
+		digits := digitsOnly(raw)
+		if len(digits) < 7 {
+			return true
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(raw), "+") {
+			digits = "+" + digits
+		}
+
+		phone = digits
+
+		return false
+	})
+
+	return phone
+}
+
+// extractMetaDescription returns the page's meta description, falling back
+// to og:description.
+func extractMetaDescription(doc *goquery.Document) string {
+	for _, sel := range []string{`meta[name="description"]`, `meta[property="og:description"]`} {
+		content, _ := doc.Find(sel).First().Attr("content")
+		if content = strings.Join(strings.Fields(content), " "); content != "" {
+			return content
+		}
+	}
+
+	return ""
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
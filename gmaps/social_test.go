@@ -0,0 +1,133 @@
+package gmaps
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseHTML(t *testing.T, html string) *goquery.Document {
+	t.Helper()
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	require.NoError(t, err)
+
+	return doc
+}
+
+func TestCanonicalSocialURL(t *testing.T) {
+	tests := []struct {
+		href      string
+		network   string
+		canonical string
+	}{
+		{"https://www.facebook.com/TrattoriaNapoli/", SocialFacebook, "https://facebook.com/TrattoriaNapoli"},
+		{"http://m.facebook.com/TrattoriaNapoli?ref=bookmarks", SocialFacebook, "https://facebook.com/TrattoriaNapoli"},
+		{"//facebook.com/TrattoriaNapoli", SocialFacebook, "https://facebook.com/TrattoriaNapoli"},
+		{"//www.instagram.com/trattoria.napoli/", SocialInstagram, "https://instagram.com/trattoria.napoli"},
+		{"https://www.facebook.com/profile.php?id=100064&ref=x", SocialFacebook, "https://www.facebook.com/profile.php?id=100064"},
+		{"https://x.com/trattoria", SocialTwitter, "https://x.com/trattoria"},
+		{"https://www.linkedin.com/company/trattoria-napoli", SocialLinkedIn, "https://linkedin.com/company/trattoria-napoli"},
+		{"https://youtu.be/channel/UC123", SocialYouTube, "https://youtu.be/channel/UC123"},
+		{"https://wa.me/39%20081%20555%201234", SocialWhatsApp, "https://wa.me/390815551234"},
+		{"https://api.whatsapp.com/send?phone=+390815551234&text=hi", SocialWhatsApp, "https://wa.me/390815551234"},
+
+		// Share buttons, posts and links that are no profiles
+		{"https://www.facebook.com/sharer/sharer.php?u=x", "", ""},
+		{"https://www.facebook.com/sharer.php?u=x", "", ""},
+		{"https://www.facebook.com/tr?id=1&ev=PageView", "", ""},
+		{"https://www.facebook.com/profile.php", "", ""},
+		{"https://www.instagram.com/p/Cx123/", "", ""},
+		{"https://twitter.com/intent/tweet?text=x", "", ""},
+		{"https://www.youtube.com/watch?v=abc", "", ""},
+		{"https://wa.me/", "", ""},
+		{"https://facebook.com/", "", ""},
+		{"https://notfacebook.com/TrattoriaNapoli", "", ""},
+		{"/facebook.com/TrattoriaNapoli", "", ""},
+		{"mailto:hello@facebook.com", "", ""},
+		{"ftp://facebook.com/TrattoriaNapoli", "", ""},
+	}
+
+	for _, tt := range tests {
+		network, canonical := canonicalSocialURL(tt.href)
+		assert.Equal(t, tt.network, network, tt.href)
+		assert.Equal(t, tt.canonical, canonical, tt.href)
+	}
+}
+
+func TestExtractSocialLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want map[string]string
+	}{
+		{"none", `<a href="/contact">Contact</a><a href="https://example.com">Partner</a>`, nil},
+		{
+			"one per network, shortest wins",
+			`<a href="https://www.facebook.com/TrattoriaNapoli/photos">Photos</a>
+			<a href="https://facebook.com/TrattoriaNapoli">Facebook</a>
+			<a href="https://www.facebook.com/sharer/sharer.php?u=x">Share</a>
+			<a href="//instagram.com/trattoria.napoli">Instagram</a>`,
+			map[string]string{
+				SocialFacebook:  "https://facebook.com/TrattoriaNapoli",
+				SocialInstagram: "https://instagram.com/trattoria.napoli",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, extractSocialLinks(parseHTML(t, tt.html)))
+		})
+	}
+}
+
+func TestExtractTelPhone(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"none", `<a href="/contact">Contact</a>`, ""},
+		{"international", `<a href="tel:+39%20081%20555%201234">Call</a>`, "+390815551234"},
+		{"national", `<a href="tel:081-555-1234">Call</a>`, "0815551234"},
+		{"upper case scheme", `<a href="TEL:+39 081 555 1234">Call</a>`, "+390815551234"},
+		{"title case scheme", `<a href=" Tel:0815551234">Call</a>`, "0815551234"},
+		{"too short is skipped", `<a href="tel:112">Emergency</a><a href="tel:0815551234">Call</a>`, "0815551234"},
+		{"other schemes", `<a href="telegram:trattoria">Telegram</a><a href="mailto:tel@x.it">Mail</a>`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, extractTelPhone(parseHTML(t, tt.html)))
+		})
+	}
+}
+
+func TestExtractMetaDescription(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"none", `<head><title>Trattoria</title></head>`, ""},
+		{"description", `<meta name="description" content="  Pizza   and pasta
+			in Naples ">`, "Pizza and pasta in Naples"},
+		{"og fallback", `<meta property="og:description" content="Wood-fired pizza">`, "Wood-fired pizza"},
+		{
+			"description first",
+			`<meta property="og:description" content="Open graph"><meta name="description" content="Plain">`,
+			"Plain",
+		},
+		{"blank description falls back", `<meta name="description" content=" "><meta property="og:description" content="Open graph">`, "Open graph"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, extractMetaDescription(parseHTML(t, tt.html)))
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// compressMinSize is the smallest JSON body worth compressing: below it
+// the encoding overhead eats the saving
+const compressMinSize = 1024
+
+// brotliLevel trades ratio for speed, as suits bodies built per request
+const brotliLevel = 5
+
+var (
+	gzipWriters = sync.Pool{New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	}}
+	brotliWriters = sync.Pool{New: func() any {
+		return brotli.NewWriterLevel(io.Discard, brotliLevel)
+	}}
+)
+
+// Compress encodes JSON responses of compressMinSize bytes or more with br
+// or gzip, whichever the client accepts, br first. Event streams,
+// downloads and exports pass through untouched, as does whatever a
+// handler flushes before reaching the threshold.
+func Compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead || isStreamRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, status: http.StatusOK}
+		defer cw.close()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// isStreamRequest tells the requests answered with event streams or files
+// written as they are read from the database
+func isStreamRequest(r *http.Request) bool {
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return true
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	for _, suffix := range []string{"/download", "/export", "/archive"} {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return path == "/api/v2/workers/events"
+}
+
+// negotiateEncoding picks br or gzip from an Accept-Encoding header, ""
+// when the client takes neither
+func negotiateEncoding(header string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		accepted[name] = q > 0
+	}
+
+	for _, encoding := range []string{"br", "gzip"} {
+		if ok, listed := accepted[encoding]; ok || (!listed && accepted["*"]) {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compressWriter holds back the body until it reaches compressMinSize,
+// then sends it through the encoder, or as is if it ended first
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	status   int
+
+	wroteHeader bool
+	passthrough bool // Sending the body as the handler wrote it
+	buf         []byte
+	enc         io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = code
+
+	h := cw.Header()
+	if !isJSON(h.Get("Content-Type")) {
+		cw.passthrough = true
+	} else {
+		h.Add("Vary", "Accept-Encoding")
+		if h.Get("Content-Encoding") != "" || h.Get("Content-Disposition") != "" ||
+			code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+			cw.passthrough = true
+		}
+	}
+
+	if cw.passthrough {
+		cw.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	switch {
+	case cw.passthrough:
+		return cw.ResponseWriter.Write(p)
+	case cw.enc != nil:
+		return cw.enc.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= compressMinSize {
+		if err := cw.startEncoding(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// startEncoding sends the headers of the encoded response and what the
+// handler wrote so far through the encoder
+func (cw *compressWriter) startEncoding() error {
+	h := cw.Header()
+	h.Set("Content-Encoding", cw.encoding)
+	h.Del("Content-Length")
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	switch cw.encoding {
+	case "br":
+		bw := brotliWriters.Get().(*brotli.Writer)
+		bw.Reset(cw.ResponseWriter)
+		cw.enc = bw
+	default:
+		gw := gzipWriters.Get().(*gzip.Writer)
+		gw.Reset(cw.ResponseWriter)
+		cw.enc = gw
+	}
+
+	buf := cw.buf
+	cw.buf = nil
+	_, err := cw.enc.Write(buf)
+	return err
+}
+
+// sendBuffered sends what is held back as is, for bodies too short to
+// compress or flushed before reaching compressMinSize
+func (cw *compressWriter) sendBuffered() error {
+	cw.passthrough = true
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := cw.ResponseWriter.Write(buf)
+	return err
+}
+
+// close ends the response once the handler returned
+func (cw *compressWriter) close() {
+	switch {
+	case !cw.wroteHeader, cw.passthrough:
+		return
+	case cw.enc == nil:
+		_ = cw.sendBuffered()
+		return
+	}
+
+	_ = cw.enc.Close()
+	switch enc := cw.enc.(type) {
+	case *brotli.Writer:
+		enc.Reset(io.Discard)
+		brotliWriters.Put(enc)
+	case *gzip.Writer:
+		enc.Reset(io.Discard)
+		gzipWriters.Put(enc)
+	}
+	cw.enc = nil
+}
+
+// FlushError sends what the handler wrote so far, for
+// http.ResponseController
+func (cw *compressWriter) FlushError() error {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	switch {
+	case cw.enc != nil:
+		if f, ok := cw.enc.(interface{ Flush() error }); ok {
+			if err := f.Flush(); err != nil {
+				return err
+			}
+		}
+	case !cw.passthrough:
+		if err := cw.sendBuffered(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Flush is FlushError for handlers asserting http.Flusher
+func (cw *compressWriter) Flush() {
+	_ = cw.FlushError()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// isJSON tells JSON content types, application/json and the +json ones
+func isJSON(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	large := `{"data":"` + strings.Repeat("coffee ", 400) + `"}`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/results", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, large)
+	})
+	mux.HandleFunc("/api/v2/small", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{}`)
+	})
+	mux.HandleFunc("/api/v2/results/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, large)
+	})
+	mux.HandleFunc("/api/v2/jobs/{id}/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "event: progress\n\n")
+		_ = http.NewResponseController(w).Flush()
+		_, _ = io.WriteString(w, strings.Repeat(": heartbeat\n\n", 200))
+	})
+	handler := Chain(mux, Compress, ETag)
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/api/v2/results", "gzip, deflate, br")
+	require.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+	assert.NotEmpty(t, rec.Header().Get("ETag"))
+	body, err := io.ReadAll(brotli.NewReader(rec.Body))
+	require.NoError(t, err)
+	assert.Equal(t, large, string(body))
+
+	rec = get("/api/v2/results", "gzip, br;q=0")
+	require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	gz, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err = io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, large, string(body))
+
+	rec = get("/api/v2/results", "identity")
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, large, rec.Body.String())
+
+	rec = get("/api/v2/small", "gzip")
+	assert.Empty(t, rec.Header().Get("Content-Encoding"), "below the threshold")
+	assert.Equal(t, `{}`, rec.Body.String())
+
+	rec = get("/api/v2/results/download", "gzip")
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Empty(t, rec.Header().Get("ETag"))
+
+	rec = get("/api/v2/jobs/x/events", "gzip")
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Empty(t, rec.Header().Get("ETag"))
+	assert.True(t, rec.Flushed)
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "event: progress"))
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	assert.Equal(t, "br", negotiateEncoding("gzip, br"))
+	assert.Equal(t, "gzip", negotiateEncoding("gzip;q=0.5, br;q=0"))
+	assert.Equal(t, "br", negotiateEncoding("*"))
+	assert.Equal(t, "gzip", negotiateEncoding("*, br;q=0"))
+	assert.Equal(t, "", negotiateEncoding("identity"))
+	assert.Equal(t, "", negotiateEncoding(""))
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// etagMaxSize caps the body held back to take its ETag; larger ones are
+// sent as they are written, without one
+const etagMaxSize = 8 << 20
+
+// ETag gives successful JSON answers to GET requests a weak ETag, the hash
+// of their body, and answers 304 Not Modified when If-None-Match has it.
+// The body hashed leaves out the trailing newline json.Encoder writes, so
+// the cached handlers give the same ETag whether they hit or miss the
+// cache. Event streams and downloads pass through untouched, as do bodies
+// a handler flushes.
+func ETag(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || isStreamRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ew := &etagWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(ew, r)
+		ew.finish(r.Header.Get("If-None-Match"))
+	})
+}
+
+// etagOf is the weak ETag of a JSON body
+func etagOf(body []byte) string {
+	sum := sha256.Sum256(bytes.TrimRight(body, "\n"))
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches tells whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 asks for
+func etagMatches(header, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter holds back the body of a 200 JSON answer until the handler
+// returned
+type etagWriter struct {
+	http.ResponseWriter
+	status int
+
+	wroteHeader bool
+	passthrough bool // Sending the body as the handler wrote it
+	buf         bytes.Buffer
+}
+
+func (ew *etagWriter) WriteHeader(code int) {
+	if ew.wroteHeader {
+		return
+	}
+	ew.wroteHeader = true
+	ew.status = code
+
+	h := ew.Header()
+	if code != http.StatusOK || !isJSON(h.Get("Content-Type")) ||
+		h.Get("ETag") != "" || h.Get("Content-Disposition") != "" {
+		ew.passthrough = true
+		ew.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (ew *etagWriter) Write(p []byte) (int, error) {
+	if !ew.wroteHeader {
+		ew.WriteHeader(http.StatusOK)
+	}
+	if ew.passthrough {
+		return ew.ResponseWriter.Write(p)
+	}
+
+	if ew.buf.Len()+len(p) > etagMaxSize {
+		if err := ew.sendBuffered(); err != nil {
+			return 0, err
+		}
+		return ew.ResponseWriter.Write(p)
+	}
+	return ew.buf.Write(p)
+}
+
+// sendBuffered gives up on the ETag and sends what is held back
+func (ew *etagWriter) sendBuffered() error {
+	ew.passthrough = true
+	ew.ResponseWriter.WriteHeader(ew.status)
+
+	if ew.buf.Len() == 0 {
+		return nil
+	}
+	_, err := ew.ResponseWriter.Write(ew.buf.Bytes())
+	ew.buf.Reset()
+	return err
+}
+
+// finish sends the held back body with its ETag, or 304 without it when
+// ifNoneMatch has the ETag
+func (ew *etagWriter) finish(ifNoneMatch string) {
+	if !ew.wroteHeader || ew.passthrough {
+		return
+	}
+
+	etag := etagOf(ew.buf.Bytes())
+	h := ew.Header()
+	h.Set("ETag", etag)
+	if h.Get("Cache-Control") == "" {
+		// Cached by the browser, but checked with the ETag every time
+		h.Set("Cache-Control", "private, no-cache")
+	}
+
+	if ifNoneMatch != "" && etagMatches(ifNoneMatch, etag) {
+		h.Del("Content-Type")
+		h.Del("Content-Length")
+		ew.ResponseWriter.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	ew.ResponseWriter.WriteHeader(http.StatusOK)
+	_, _ = ew.ResponseWriter.Write(ew.buf.Bytes())
+}
+
+// FlushError sends what the handler wrote so far without an ETag, for
+// http.ResponseController
+func (ew *etagWriter) FlushError() error {
+	if !ew.wroteHeader {
+		ew.WriteHeader(http.StatusOK)
+	}
+	if !ew.passthrough {
+		if err := ew.sendBuffered(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(ew.ResponseWriter).Flush()
+}
+
+// Flush is FlushError for handlers asserting http.Flusher
+func (ew *etagWriter) Flush() {
+	_ = ew.FlushError()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (ew *etagWriter) Unwrap() http.ResponseWriter {
+	return ew.ResponseWriter
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/api/handlers"
+	"github.com/sadewadee/google-scraper/internal/cache"
+)
+
+func TestETagStableAcrossCacheHits(t *testing.T) {
+	jobs := handlers.NewCachedJobHandler(fakeJobService{}, fakeResultService{}, cache.NewMemoryCache())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/jobs/{id}", jobs.GetByID)
+	handler := Chain(mux, Compress, ETag)
+
+	get := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/jobs/"+testJob.ID.String(), nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	miss := get("", "")
+	require.Equal(t, http.StatusOK, miss.Code)
+	assert.Equal(t, "MISS", miss.Header().Get("X-Cache"))
+	etag := miss.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(etag, `W/"`), etag)
+	assert.Equal(t, "private, no-cache", miss.Header().Get("Cache-Control"))
+
+	hit := get("", "")
+	require.Equal(t, http.StatusOK, hit.Code)
+	assert.Equal(t, "HIT", hit.Header().Get("X-Cache"))
+	assert.Equal(t, etag, hit.Header().Get("ETag"), "a cache hit keeps the ETag of the miss")
+	assert.Equal(t, strings.TrimSpace(miss.Body.String()), hit.Body.String())
+
+	notModified := get("If-None-Match", `"stale", `+etag)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Equal(t, "HIT", notModified.Header().Get("X-Cache"))
+	assert.Equal(t, etag, notModified.Header().Get("ETag"))
+	assert.Empty(t, notModified.Body.String())
+
+	assert.Equal(t, http.StatusOK, get("If-None-Match", `W/"stale"`).Code)
+	assert.Equal(t, etag, get("Accept-Encoding", "gzip").Header().Get("ETag"), "the same whatever the encoding")
+}
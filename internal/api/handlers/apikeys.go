@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// APIKeyServiceInterface defines the API key service methods
+type APIKeyServiceInterface interface {
+	Create(ctx context.Context, req *domain.CreateAPIKeyRequest) (*domain.APIKey, string, error)
+	List(ctx context.Context) ([]*domain.APIKey, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// APIKeyHandler handles API key management requests
+type APIKeyHandler struct {
+	keys APIKeyServiceInterface
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler
+func NewAPIKeyHandler(keys APIKeyServiceInterface) *APIKeyHandler {
+	return &APIKeyHandler{
+		keys: keys,
+	}
+}
+
+// CreateAPIKeyResponse is returned once on key creation and is the only
+// response that contains the cleartext key
+type CreateAPIKeyResponse struct {
+	*domain.APIKey
+	Key string `json:"key"`
+}
+
+// Create handles POST /api/v2/apikeys
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req domain.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	key, secret, err := h.keys.Create(r.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAPIKeyNameRequired),
+			errors.Is(err, service.ErrAPIKeyNoScopes),
+			errors.Is(err, service.ErrAPIKeyBadScope),
+			errors.Is(err, service.ErrAPIKeyBadQuota),
+			errors.Is(err, service.ErrAPIKeyBadProfile):
+			RenderError(w, http.StatusBadRequest, err.Error())
+		default:
+			RenderError(w, http.StatusInternalServerError, "Failed to create API key: "+err.Error())
+		}
+		return
+	}
+
+	RenderJSON(w, http.StatusCreated, CreateAPIKeyResponse{APIKey: key, Key: secret})
+}
+
+// List handles GET /api/v2/apikeys
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	keys, err := h.keys.List(r.Context())
+	if err != nil {
+		RenderError(w, http.StatusInternalServerError, "Failed to list API keys: "+err.Error())
+		return
+	}
+
+	if keys == nil {
+		keys = []*domain.APIKey{}
+	}
+
+	RenderJSON(w, http.StatusOK, map[string]interface{}{
+		"data": keys,
+	})
+}
+
+// Delete handles DELETE /api/v2/apikeys/{id}
+func (h *APIKeyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid API key ID")
+		return
+	}
+
+	if err := h.keys.Delete(r.Context(), id); err != nil {
+		RenderError(w, http.StatusInternalServerError, "Failed to delete API key: "+err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
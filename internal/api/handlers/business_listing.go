@@ -1,20 +1,40 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
 	"github.com/sadewadee/google-scraper/internal/service"
 )
 
+// maxExportJobs caps the jobs combined into one export
+const maxExportJobs = 100
+
+// JobLookup finds jobs by ID; *service.JobService satisfies it
+type JobLookup interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Job, error)
+}
+
+// ExportProfileResolver picks the export profile a download goes through;
+// *service.ExportProfileService satisfies it
+type ExportProfileResolver interface {
+	Resolve(ctx context.Context, requested string) (*domain.ExportProfile, error)
+}
+
 // BusinessListingHandler handles business listing endpoints
 type BusinessListingHandler struct {
-	svc *service.BusinessListingService
+	svc      *service.BusinessListingService
+	jobs     JobLookup             // Optional: needed by Export
+	profiles ExportProfileResolver // Optional: needed by export_profile
 }
 
 // NewBusinessListingHandler creates a new handler
@@ -22,11 +42,68 @@ func NewBusinessListingHandler(svc *service.BusinessListingService) *BusinessLis
 	return &BusinessListingHandler{svc: svc}
 }
 
+// SetJobs enables exports across several jobs
+func (h *BusinessListingHandler) SetJobs(jobs JobLookup) {
+	h.jobs = jobs
+}
+
+// SetExportProfiles enables export profiles on downloads
+func (h *BusinessListingHandler) SetExportProfiles(profiles ExportProfileResolver) {
+	h.profiles = profiles
+}
+
 // List handles GET /api/v2/results (global business listings)
 func (h *BusinessListingHandler) List(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// Parse query parameters
+	filter, err := parseListingFilter(r)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	listings, total, err := h.svc.List(ctx, filter)
+	if err != nil {
+		logging.Logger(r.Context(), "BusinessListingHandler").Error("List failed", "error", err)
+		h.jsonError(w, "Failed to fetch listings", http.StatusInternalServerError)
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"data": listings,
+		"meta": listingPageMeta(filter, listings, total),
+	})
+}
+
+// listingPageMeta describes a page of listings. Cursor pages have no page
+// number or total; next_cursor is set whenever a full page sorted by
+// created_at was returned, so offset pages can be continued by cursor.
+func listingPageMeta(filter domain.BusinessListingFilter, listings []*domain.BusinessListing, total int) map[string]interface{} {
+	meta := map[string]interface{}{
+		"per_page":    filter.PerPage,
+		"next_cursor": nil,
+	}
+
+	if filter.Cursor != nil {
+		meta["total"] = nil
+	} else {
+		meta["page"] = filter.Page
+		meta["total"] = total
+		meta["total_pages"] = (total + filter.PerPage - 1) / filter.PerPage
+	}
+
+	if filter.SortBy == "created_at" && len(listings) > 0 && len(listings) == filter.PerPage {
+		if c, err := domain.ListingCursorAfter(listings[len(listings)-1]); err == nil {
+			meta["next_cursor"] = c.String()
+		}
+	}
+
+	return meta
+}
+
+// parseListingFilter reads the filters, sorting and pagination of a listing
+// request from the query string
+func parseListingFilter(r *http.Request) (domain.BusinessListingFilter, error) {
 	filter := domain.BusinessListingFilter{
 		Page:      1,
 		PerPage:   25,
@@ -46,6 +123,10 @@ func (h *BusinessListingHandler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if tag := r.URL.Query().Get("job_tag"); tag != "" {
+		filter.JobTag = strings.TrimSpace(tag)
+	}
+
 	if s := r.URL.Query().Get("search"); s != "" {
 		filter.Search = s
 	}
@@ -54,6 +135,10 @@ func (h *BusinessListingHandler) List(w http.ResponseWriter, r *http.Request) {
 		filter.Category = c
 	}
 
+	if c := r.URL.Query().Get("canonical_category"); c != "" {
+		filter.CanonicalCategory = c
+	}
+
 	if city := r.URL.Query().Get("city"); city != "" {
 		filter.City = city
 	}
@@ -62,6 +147,14 @@ func (h *BusinessListingHandler) List(w http.ResponseWriter, r *http.Request) {
 		filter.Country = country
 	}
 
+	if state := r.URL.Query().Get("state"); state != "" {
+		filter.State = state
+	}
+
+	if postcode := r.URL.Query().Get("postcode"); postcode != "" {
+		filter.Postcode = postcode
+	}
+
 	if rating := r.URL.Query().Get("min_rating"); rating != "" {
 		if r, err := strconv.ParseFloat(rating, 64); err == nil {
 			filter.MinRating = &r
@@ -82,59 +175,100 @@ func (h *BusinessListingHandler) List(w http.ResponseWriter, r *http.Request) {
 		filter.HasEmail = &val
 	}
 
+	if hasValidPhone := r.URL.Query().Get("has_valid_phone"); hasValidPhone != "" {
+		val := strings.ToLower(hasValidPhone) == "true" || hasValidPhone == "1"
+		filter.HasValidPhone = &val
+	}
+
 	if emailStatus := r.URL.Query().Get("email_status"); emailStatus != "" {
 		filter.EmailStatus = strings.ToLower(emailStatus)
 	}
 
-	listings, total, err := h.svc.List(ctx, filter)
-	if err != nil {
-		log.Printf("[BusinessListingHandler] List error: %v", err)
-		h.jsonError(w, "Failed to fetch listings", http.StatusInternalServerError)
-		return
+	if websiteStatus := r.URL.Query().Get("website_status"); websiteStatus != "" {
+		filter.WebsiteStatus = strings.ToLower(websiteStatus)
 	}
 
-	totalPages := (total + filter.PerPage - 1) / filter.PerPage
+	if businessStatus := r.URL.Query().Get("business_status"); businessStatus != "" {
+		filter.BusinessStatus = strings.ToLower(businessStatus)
+	}
 
-	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
-		"data": listings,
-		"meta": map[string]interface{}{
-			"page":        filter.Page,
-			"per_page":    filter.PerPage,
-			"total":       total,
-			"total_pages": totalPages,
-		},
-	})
+	if completeness := r.URL.Query().Get("completeness"); completeness != "" {
+		filter.Completeness = strings.ToLower(completeness)
+	}
+
+	// IANA names are case-sensitive
+	if tz := r.URL.Query().Get("timezone"); tz != "" {
+		filter.Timezone = tz
+	}
+
+	if attribute := r.URL.Query().Get("attribute"); attribute != "" {
+		filter.Attribute = attribute
+	}
+
+	if onlyNew := r.URL.Query().Get("only_new"); onlyNew != "" {
+		filter.OnlyNew = strings.ToLower(onlyNew) == "true" || onlyNew == "1"
+	}
+
+	if bbox := r.URL.Query().Get("bbox"); bbox != "" {
+		box, err := domain.ParseBoundingBox(bbox)
+		if err != nil {
+			return filter, fmt.Errorf("invalid bbox: %w", err)
+		}
+		filter.BBox = box
+	}
+
+	if openOn := r.URL.Query().Get("open_on"); openOn != "" {
+		day, err := domain.ParseWeekday(openOn)
+		if err != nil {
+			return filter, fmt.Errorf("invalid open_on: %w", err)
+		}
+		filter.OpenOn = day
+	}
+
+	if r.URL.Query().Has("cursor") {
+		cursor, err := domain.ParseListingCursor(r.URL.Query().Get("cursor"))
+		if err != nil {
+			return filter, fmt.Errorf("invalid cursor: %w", err)
+		}
+		if filter.SortBy != "created_at" {
+			return filter, fmt.Errorf("cursor requires sort_by=created_at")
+		}
+		filter.Cursor = cursor
+	}
+
+	return filter, nil
 }
 
 // Download handles GET /api/v2/results/download (export global listings)
 func (h *BusinessListingHandler) Download(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
 	format := r.URL.Query().Get("format")
 	if format == "" {
 		format = "csv"
 	}
 
+	// An export profile overrides columns
+	profile, ok := h.exportProfile(w, r, r.URL.Query().Get("export_profile"))
+	if !ok {
+		return
+	}
+
 	// Parse columns
 	var columns []string
-	if cols := r.URL.Query().Get("columns"); cols != "" {
+	if cols := r.URL.Query().Get("columns"); cols != "" && profile == nil {
 		columns = strings.Split(cols, ",")
-		// Validate columns
-		validCols := make(map[string]bool)
-		for _, c := range h.svc.AvailableColumns() {
-			validCols[c] = true
-		}
-		for _, c := range columns {
-			if !validCols[c] {
-				h.jsonError(w, "Invalid column: "+c, http.StatusBadRequest)
-				return
-			}
+		if c := h.invalidColumn(columns); c != "" {
+			h.jsonError(w, "Invalid column: "+c, http.StatusBadRequest)
+			return
 		}
 	}
 
 	// Parse filter
 	filter := domain.BusinessListingFilter{}
 
+	if tag := r.URL.Query().Get("job_tag"); tag != "" {
+		filter.JobTag = strings.TrimSpace(tag)
+	}
+
 	if s := r.URL.Query().Get("search"); s != "" {
 		filter.Search = s
 	}
@@ -143,6 +277,10 @@ func (h *BusinessListingHandler) Download(w http.ResponseWriter, r *http.Request
 		filter.Category = c
 	}
 
+	if c := r.URL.Query().Get("canonical_category"); c != "" {
+		filter.CanonicalCategory = c
+	}
+
 	if city := r.URL.Query().Get("city"); city != "" {
 		filter.City = city
 	}
@@ -151,6 +289,14 @@ func (h *BusinessListingHandler) Download(w http.ResponseWriter, r *http.Request
 		filter.Country = country
 	}
 
+	if state := r.URL.Query().Get("state"); state != "" {
+		filter.State = state
+	}
+
+	if postcode := r.URL.Query().Get("postcode"); postcode != "" {
+		filter.Postcode = postcode
+	}
+
 	if rating := r.URL.Query().Get("min_rating"); rating != "" {
 		if r, err := strconv.ParseFloat(rating, 64); err == nil {
 			filter.MinRating = &r
@@ -163,35 +309,330 @@ func (h *BusinessListingHandler) Download(w http.ResponseWriter, r *http.Request
 		filter.HasEmail = &val
 	}
 
+	if hasValidPhone := r.URL.Query().Get("has_valid_phone"); hasValidPhone != "" {
+		val := strings.ToLower(hasValidPhone) == "true" || hasValidPhone == "1"
+		filter.HasValidPhone = &val
+	}
+
 	if emailStatus := r.URL.Query().Get("email_status"); emailStatus != "" {
 		filter.EmailStatus = strings.ToLower(emailStatus)
 	}
 
+	if websiteStatus := r.URL.Query().Get("website_status"); websiteStatus != "" {
+		filter.WebsiteStatus = strings.ToLower(websiteStatus)
+	}
+
+	if businessStatus := r.URL.Query().Get("business_status"); businessStatus != "" {
+		filter.BusinessStatus = strings.ToLower(businessStatus)
+	}
+
+	if completeness := r.URL.Query().Get("completeness"); completeness != "" {
+		filter.Completeness = strings.ToLower(completeness)
+	}
+
+	// IANA names are case-sensitive
+	if tz := r.URL.Query().Get("timezone"); tz != "" {
+		filter.Timezone = tz
+	}
+
+	if attribute := r.URL.Query().Get("attribute"); attribute != "" {
+		filter.Attribute = attribute
+	}
+
+	if onlyNew := r.URL.Query().Get("only_new"); onlyNew != "" {
+		filter.OnlyNew = strings.ToLower(onlyNew) == "true" || onlyNew == "1"
+	}
+
+	if bbox := r.URL.Query().Get("bbox"); bbox != "" {
+		box, err := domain.ParseBoundingBox(bbox)
+		if err != nil {
+			h.jsonError(w, "Invalid bbox: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.BBox = box
+	}
+
+	if openOn := r.URL.Query().Get("open_on"); openOn != "" {
+		day, err := domain.ParseWeekday(openOn)
+		if err != nil {
+			h.jsonError(w, "Invalid open_on: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.OpenOn = day
+	}
+
+	h.download(w, r, format, filter, columns, profile, "business_listings")
+}
+
+// download writes the listings matching filter in format. CSV and JSON are
+// streamed and end with the X-Export-Complete trailer, CSV also with its
+// marker line; XLSX is built first, so a failure is still a 500.
+func (h *BusinessListingHandler) download(w http.ResponseWriter, r *http.Request, format string, filter domain.BusinessListingFilter, columns []string, profile *domain.ExportProfile, filename string) {
+	ctx := r.Context()
+	logger := logging.Logger(ctx, "BusinessListingHandler")
+	if filter.JobID != nil {
+		logger = logger.With("job_id", *filter.JobID)
+	}
+
 	switch format {
 	case "csv":
 		w.Header().Set("Content-Type", "text/csv")
-		w.Header().Set("Content-Disposition", "attachment; filename=business_listings.csv")
-		if err := h.svc.ExportCSV(ctx, w, filter, columns); err != nil {
-			log.Printf("[BusinessListingHandler] ExportCSV error: %v", err)
-			return
+		w.Header().Set("Content-Disposition", "attachment; filename="+filename+".csv")
+		announceExportComplete(w)
+		rows, err := h.svc.ExportCSV(ctx, w, filter, columns, profile)
+		if err == nil {
+			err = writeExportMarker(w, rows)
 		}
+		if err != nil {
+			logger.Error("ExportCSV failed", "rows", rows, "error", err)
+		}
+		endExport(w, err == nil)
 	case "json":
 		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Content-Disposition", "attachment; filename=business_listings.json")
-		if err := h.svc.ExportJSON(ctx, w, filter); err != nil {
-			log.Printf("[BusinessListingHandler] ExportJSON error: %v", err)
-			return
+		w.Header().Set("Content-Disposition", "attachment; filename="+filename+".json")
+		announceExportComplete(w)
+		rows, err := h.svc.ExportJSON(ctx, w, filter, profile)
+		if err != nil {
+			logger.Error("ExportJSON failed", "rows", rows, "error", err)
 		}
+		endExport(w, err == nil)
 	case "xlsx":
-		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-		w.Header().Set("Content-Disposition", "attachment; filename=business_listings.xlsx")
-		if err := h.svc.ExportXLSX(ctx, w, filter, columns); err != nil {
-			log.Printf("[BusinessListingHandler] ExportXLSX error: %v", err)
+		var rows int
+		sent, err := sendWorkbook(w, filename+".xlsx", func(out io.Writer) error {
+			var err error
+			rows, err = h.svc.ExportXLSX(ctx, out, filter, columns, profile)
+			return err
+		})
+		if err != nil {
+			logger.Error("ExportXLSX failed", "rows", rows, "error", err)
+			if !sent {
+				h.jsonError(w, "Failed to export listings", http.StatusInternalServerError)
+			}
+		}
+	case "geojson":
+		h.downloadGeoJSON(w, r, filter, columns, profile, filename)
+	default:
+		h.jsonError(w, "Invalid format. Supported: csv, json, xlsx, geojson", http.StatusBadRequest)
+	}
+}
+
+// exportRequest is the body of POST /api/v2/results/export. Filters and
+// columns take the same values as the query parameters of
+// /api/v2/results/download.
+type exportRequest struct {
+	JobIDs         []string `json:"job_ids"`
+	Format         string   `json:"format"` // csv (default), json, xlsx or ndjson
+	Columns        []string `json:"columns"`
+	ExportProfile  string   `json:"export_profile"` // Overrides columns
+	Search         string   `json:"search"`
+	Category       string   `json:"category"`
+	City           string   `json:"city"`
+	Country        string   `json:"country"`
+	State          string   `json:"state"`
+	Postcode       string   `json:"postcode"`
+	MinRating      *float64 `json:"min_rating"`
+	HasEmail       *bool    `json:"has_email"`
+	HasValidPhone  *bool    `json:"has_valid_phone"`
+	EmailStatus    string   `json:"email_status"`
+	WebsiteStatus  string   `json:"website_status"`
+	BusinessStatus string   `json:"business_status"`
+	Completeness   string   `json:"completeness"`
+	Timezone       string   `json:"timezone"`
+	Attribute      string   `json:"attribute"`
+	OnlyNew        bool     `json:"only_new"`
+	OpenOn         string   `json:"open_on"`
+}
+
+func (req *exportRequest) filter() domain.BusinessListingFilter {
+	return domain.BusinessListingFilter{
+		Search:         req.Search,
+		Category:       req.Category,
+		City:           req.City,
+		Country:        req.Country,
+		State:          req.State,
+		Postcode:       req.Postcode,
+		MinRating:      req.MinRating,
+		HasEmail:       req.HasEmail,
+		HasValidPhone:  req.HasValidPhone,
+		EmailStatus:    strings.ToLower(req.EmailStatus),
+		WebsiteStatus:  strings.ToLower(req.WebsiteStatus),
+		BusinessStatus: strings.ToLower(req.BusinessStatus),
+		Completeness:   strings.ToLower(req.Completeness),
+		Timezone:       req.Timezone,
+		Attribute:      req.Attribute,
+		OnlyNew:        req.OnlyNew,
+	}
+}
+
+// Export handles POST /api/v2/results/export: the listings of several jobs
+// in one file, in the order the jobs are given, each place once. Every job
+// is checked before anything is written, so an unknown job is a 400 rather
+// than a truncated file. The row count is sent in the X-Total-Rows trailer,
+// next to X-Export-Complete; an XLSX is built first and sends them as
+// headers instead.
+func (h *BusinessListingHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.jobs == nil {
+		h.jsonError(w, "Job lookup not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx := r.Context()
+	logger := logging.Logger(ctx, "BusinessListingHandler")
+
+	var req exportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.JobIDs) == 0 {
+		h.jsonError(w, "job_ids is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.JobIDs) > maxExportJobs {
+		h.jsonError(w, fmt.Sprintf("At most %d jobs can be exported together", maxExportJobs), http.StatusBadRequest)
+		return
+	}
+
+	profile, ok := h.exportProfile(w, r, req.ExportProfile)
+	if !ok {
+		return
+	}
+	if profile != nil {
+		req.Columns = nil
+	} else if c := h.invalidColumn(req.Columns); c != "" {
+		h.jsonError(w, "Invalid column: "+c, http.StatusBadRequest)
+		return
+	}
+
+	filter := req.filter()
+	if req.OpenOn != "" {
+		day, err := domain.ParseWeekday(req.OpenOn)
+		if err != nil {
+			h.jsonError(w, "Invalid open_on: "+err.Error(), http.StatusBadRequest)
 			return
 		}
+		filter.OpenOn = day
+	}
+
+	format := req.Format
+	if format == "" {
+		format = "csv"
+	}
+
+	var contentType string
+	switch format {
+	case "csv":
+		contentType = "text/csv"
+	case "json":
+		contentType = "application/json"
+	case "xlsx":
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case "ndjson":
+		contentType = "application/x-ndjson"
 	default:
-		h.jsonError(w, "Invalid format. Supported: csv, json, xlsx", http.StatusBadRequest)
+		h.jsonError(w, "Invalid format. Supported: csv, json, xlsx, ndjson", http.StatusBadRequest)
+		return
+	}
+
+	jobIDs := make([]uuid.UUID, 0, len(req.JobIDs))
+	listed := make(map[uuid.UUID]bool, len(req.JobIDs))
+	var missing []string
+	for _, raw := range req.JobIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			h.jsonError(w, "Invalid job ID: "+raw, http.StatusBadRequest)
+			return
+		}
+		if listed[id] {
+			continue
+		}
+		listed[id] = true
+
+		if _, err := h.jobs.GetByID(ctx, id); err != nil {
+			if errors.Is(err, service.ErrJobNotFound) {
+				missing = append(missing, raw)
+				continue
+			}
+			logger.Error("failed to look up job", "job_id", id, "error", err)
+			h.jsonError(w, "Failed to look up jobs", http.StatusInternalServerError)
+			return
+		}
+		jobIDs = append(jobIDs, id)
+	}
+
+	if len(missing) > 0 {
+		h.jsonError(w, "Unknown jobs: "+strings.Join(missing, ", "), http.StatusBadRequest)
+		return
 	}
+
+	if format == "xlsx" {
+		var res *service.MultiJobExport
+		sent, err := sendWorkbook(w, "business_listings_export.xlsx", func(out io.Writer) error {
+			var err error
+			res, err = h.svc.ExportXLSXByJobIDs(ctx, out, jobIDs, filter, req.Columns, profile)
+			if err == nil {
+				w.Header().Set("X-Total-Rows", strconv.Itoa(res.Rows))
+				w.Header().Set("X-Duplicate-Rows", strconv.Itoa(res.Duplicates))
+			}
+			return err
+		})
+		if err != nil {
+			logger.Error("multi-job export failed", "jobs", len(jobIDs), "format", format, "rows", exportedRows(res), "error", err)
+			if !sent {
+				h.jsonError(w, "Failed to export listings", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		logger.Info("multi-job export finished", "jobs", len(jobIDs), "format", format, "rows", res.Rows, "duplicates", res.Duplicates)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=business_listings_export."+format)
+	w.Header().Set("Trailer", "X-Total-Rows, X-Duplicate-Rows")
+	announceExportComplete(w)
+
+	var (
+		res *service.MultiJobExport
+		err error
+	)
+	switch format {
+	case "csv":
+		res, err = h.svc.ExportCSVByJobIDs(ctx, w, jobIDs, filter, req.Columns, profile)
+	case "json":
+		res, err = h.svc.ExportJSONByJobIDs(ctx, w, jobIDs, filter, profile)
+	case "ndjson":
+		res, err = h.svc.ExportNDJSONByJobIDs(ctx, w, jobIDs, filter, profile)
+	}
+	if err == nil && format != "json" {
+		err = writeExportMarker(w, res.Rows)
+	}
+	if err != nil {
+		logger.Error("multi-job export failed", "jobs", len(jobIDs), "format", format, "rows", exportedRows(res), "error", err)
+		endExport(w, false)
+		return
+	}
+
+	w.Header().Set("X-Total-Rows", strconv.Itoa(res.Rows))
+	w.Header().Set("X-Duplicate-Rows", strconv.Itoa(res.Duplicates))
+	endExport(w, true)
+
+	logger.Info("multi-job export finished", "jobs", len(jobIDs), "format", format, "rows", res.Rows, "duplicates", res.Duplicates)
+}
+
+// exportedRows is how many rows an export got to, for logging a failure
+func exportedRows(res *service.MultiJobExport) int {
+	if res == nil {
+		return 0
+	}
+	return res.Rows
 }
 
 // ListByJobID handles GET /api/v2/jobs/{id}/results
@@ -206,53 +647,43 @@ func (h *BusinessListingHandler) ListByJobID(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Validate UUID
-	if _, err := uuid.Parse(jobID); err != nil {
+	id, err := uuid.Parse(jobID)
+	if err != nil {
 		h.jsonError(w, "Invalid job ID format", http.StatusBadRequest)
 		return
 	}
 
-	// Parse pagination
-	page := 1
-	limit := 25
-
-	if p := r.URL.Query().Get("page"); p != "" {
-		if val, err := strconv.Atoi(p); err == nil && val > 0 {
-			page = val
-		}
-	}
-
-	if l := r.URL.Query().Get("limit"); l != "" {
-		if val, err := strconv.Atoi(l); err == nil && val > 0 && val <= 100 {
-			limit = val
-		}
+	filter, err := parseListingFilter(r)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	offset := (page - 1) * limit
-
-	listings, total, err := h.svc.ListByJobID(ctx, jobID, limit, offset)
+	listings, total, err := h.svc.ListByJobID(ctx, jobID, filter)
 	if err != nil {
-		log.Printf("[BusinessListingHandler] ListByJobID error: %v", err)
+		logging.Logger(r.Context(), "BusinessListingHandler").Error("ListByJobID failed", "error", err)
 		h.jsonError(w, "Failed to fetch listings", http.StatusInternalServerError)
 		return
 	}
 
-	totalPages := (total + limit - 1) / limit
-
-	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+	resp := map[string]interface{}{
 		"data": listings,
-		"meta": map[string]interface{}{
-			"page":        page,
-			"per_page":    limit,
-			"total":       total,
-			"total_pages": totalPages,
-		},
-	})
+		"meta": listingPageMeta(filter, listings, total),
+	}
+	// is_partial stays true while the job runs and adds to its results
+	if h.jobs != nil {
+		partial, err := resultsPartial(ctx, h.jobs, id)
+		if err != nil {
+			logging.Logger(ctx, "BusinessListingHandler").Warn("failed to get job of listings", "job_id", jobID, "error", err)
+		}
+		resp["is_partial"] = partial
+	}
+
+	h.jsonResponse(w, http.StatusOK, resp)
 }
 
 // DownloadByJobID handles GET /api/v2/jobs/{id}/download
 func (h *BusinessListingHandler) DownloadByJobID(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
 	// Extract job ID from path
 	jobID := extractJobIDFromPath(r.URL.Path)
 	if jobID == "" {
@@ -261,7 +692,8 @@ func (h *BusinessListingHandler) DownloadByJobID(w http.ResponseWriter, r *http.
 	}
 
 	// Validate UUID
-	if _, err := uuid.Parse(jobID); err != nil {
+	id, err := uuid.Parse(jobID)
+	if err != nil {
 		h.jsonError(w, "Invalid job ID format", http.StatusBadRequest)
 		return
 	}
@@ -271,53 +703,98 @@ func (h *BusinessListingHandler) DownloadByJobID(w http.ResponseWriter, r *http.
 		format = "csv"
 	}
 
+	// An export profile overrides columns
+	profile, ok := h.exportProfile(w, r, r.URL.Query().Get("export_profile"))
+	if !ok {
+		return
+	}
+
 	// Parse columns
 	var columns []string
-	if cols := r.URL.Query().Get("columns"); cols != "" {
+	if cols := r.URL.Query().Get("columns"); cols != "" && profile == nil {
 		columns = strings.Split(cols, ",")
-		// Validate columns
-		validCols := make(map[string]bool)
-		for _, c := range h.svc.AvailableColumns() {
-			validCols[c] = true
-		}
-		for _, c := range columns {
-			if !validCols[c] {
-				h.jsonError(w, "Invalid column: "+c, http.StatusBadRequest)
-				return
-			}
+		if c := h.invalidColumn(columns); c != "" {
+			h.jsonError(w, "Invalid column: "+c, http.StatusBadRequest)
+			return
 		}
 	}
 
-	filename := "job_" + jobID[:8]
-
-	switch format {
-	case "csv":
-		w.Header().Set("Content-Type", "text/csv")
-		w.Header().Set("Content-Disposition", "attachment; filename="+filename+".csv")
-		if err := h.svc.ExportCSVByJobID(ctx, w, jobID, columns); err != nil {
-			log.Printf("[BusinessListingHandler] ExportCSVByJobID error: %v", err)
+	// only_new keeps the places an incremental job found first
+	filter := domain.BusinessListingFilter{JobID: &id}
+	if onlyNew := r.URL.Query().Get("only_new"); onlyNew != "" {
+		filter.OnlyNew = strings.ToLower(onlyNew) == "true" || onlyNew == "1"
+	}
+	if hasValidPhone := r.URL.Query().Get("has_valid_phone"); hasValidPhone != "" {
+		val := strings.ToLower(hasValidPhone) == "true" || hasValidPhone == "1"
+		filter.HasValidPhone = &val
+	}
+	if websiteStatus := r.URL.Query().Get("website_status"); websiteStatus != "" {
+		filter.WebsiteStatus = strings.ToLower(websiteStatus)
+	}
+	if businessStatus := r.URL.Query().Get("business_status"); businessStatus != "" {
+		filter.BusinessStatus = strings.ToLower(businessStatus)
+	}
+	if completeness := r.URL.Query().Get("completeness"); completeness != "" {
+		filter.Completeness = strings.ToLower(completeness)
+	}
+	if tz := r.URL.Query().Get("timezone"); tz != "" {
+		filter.Timezone = tz
+	}
+	if openOn := r.URL.Query().Get("open_on"); openOn != "" {
+		day, err := domain.ParseWeekday(openOn)
+		if err != nil {
+			h.jsonError(w, "Invalid open_on: "+err.Error(), http.StatusBadRequest)
 			return
 		}
-	case "json":
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Content-Disposition", "attachment; filename="+filename+".json")
-		if err := h.svc.ExportJSONByJobID(ctx, w, jobID); err != nil {
-			log.Printf("[BusinessListingHandler] ExportJSONByJobID error: %v", err)
+		filter.OpenOn = day
+	}
+
+	if h.jobs != nil {
+		if err := checkPartialDownload(w, r, h.jobs, id); err != nil {
+			h.jsonError(w, err.Error(), partialDownloadStatus(err))
 			return
 		}
-	case "xlsx":
-		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-		w.Header().Set("Content-Disposition", "attachment; filename="+filename+".xlsx")
-		if err := h.svc.ExportXLSXByJobID(ctx, w, jobID, columns); err != nil {
-			log.Printf("[BusinessListingHandler] ExportXLSXByJobID error: %v", err)
-			return
+	}
+
+	h.download(w, r, format, filter, columns, profile, "job_"+jobID[:8])
+}
+
+// downloadGeoJSON streams the listings matching filter as a GeoJSON
+// FeatureCollection. How many were skipped for lacking coordinates is only
+// known once all are written, so it goes in the X-Skipped-No-Coords trailer.
+func (h *BusinessListingHandler) downloadGeoJSON(w http.ResponseWriter, r *http.Request, filter domain.BusinessListingFilter, columns []string, profile *domain.ExportProfile, filename string) {
+	logger := logging.Logger(r.Context(), "BusinessListingHandler")
+
+	if profile != nil && (!profile.HasColumn("latitude") || !profile.HasColumn("longitude")) {
+		h.jsonError(w, "Export profile "+profile.Name+" has no coordinates for GeoJSON", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.Header().Set("Content-Disposition", "attachment; filename="+filename+".geojson")
+	w.Header().Set("Trailer", "X-Skipped-No-Coords")
+	announceExportComplete(w)
+
+	res, err := h.svc.ExportGeoJSON(r.Context(), w, filter, columns, profile)
+	if err != nil {
+		// Headers are gone; the trailer tells the client the file is incomplete
+		features := 0
+		if res != nil {
+			features = res.Features
 		}
-	default:
-		h.jsonError(w, "Invalid format. Supported: csv, json, xlsx", http.StatusBadRequest)
+		logger.Error("ExportGeoJSON failed", "features", features, "error", err)
+		endExport(w, false)
+		return
 	}
+
+	w.Header().Set("X-Skipped-No-Coords", strconv.Itoa(res.NoCoords))
+	endExport(w, true)
+
+	logger.Info("GeoJSON export finished", "features", res.Features, "skipped_no_coords", res.NoCoords)
 }
 
-// GetCategories handles GET /api/v2/results/categories
+// GetCategories handles GET /api/v2/results/categories. With canonical=true
+// it returns canonical categories and their counts instead of raw names.
 func (h *BusinessListingHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -328,9 +805,23 @@ func (h *BusinessListingHandler) GetCategories(w http.ResponseWriter, r *http.Re
 		}
 	}
 
+	if r.URL.Query().Get("canonical") == "true" {
+		categories, err := h.svc.GetCanonicalCategories(ctx, limit)
+		if err != nil {
+			logging.Logger(r.Context(), "BusinessListingHandler").Error("GetCanonicalCategories failed", "error", err)
+			h.jsonError(w, "Failed to fetch categories", http.StatusInternalServerError)
+			return
+		}
+
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"data": categories,
+		})
+		return
+	}
+
 	categories, err := h.svc.GetCategories(ctx, limit)
 	if err != nil {
-		log.Printf("[BusinessListingHandler] GetCategories error: %v", err)
+		logging.Logger(r.Context(), "BusinessListingHandler").Error("GetCategories failed", "error", err)
 		h.jsonError(w, "Failed to fetch categories", http.StatusInternalServerError)
 		return
 	}
@@ -353,7 +844,7 @@ func (h *BusinessListingHandler) GetCities(w http.ResponseWriter, r *http.Reques
 
 	cities, err := h.svc.GetCities(ctx, limit)
 	if err != nil {
-		log.Printf("[BusinessListingHandler] GetCities error: %v", err)
+		logging.Logger(r.Context(), "BusinessListingHandler").Error("GetCities failed", "error", err)
 		h.jsonError(w, "Failed to fetch cities", http.StatusInternalServerError)
 		return
 	}
@@ -369,7 +860,7 @@ func (h *BusinessListingHandler) GetStats(w http.ResponseWriter, r *http.Request
 
 	stats, err := h.svc.Stats(ctx)
 	if err != nil {
-		log.Printf("[BusinessListingHandler] GetStats error: %v", err)
+		logging.Logger(r.Context(), "BusinessListingHandler").Error("GetStats failed", "error", err)
 		h.jsonError(w, "Failed to fetch stats", http.StatusInternalServerError)
 		return
 	}
@@ -379,6 +870,68 @@ func (h *BusinessListingHandler) GetStats(w http.ResponseWriter, r *http.Request
 	})
 }
 
+// Sample handles GET /api/v2/results/sample
+func (h *BusinessListingHandler) Sample(w http.ResponseWriter, r *http.Request) {
+	h.sample(w, r, "")
+}
+
+// SampleByJobID handles GET /api/v2/jobs/{id}/results/sample
+func (h *BusinessListingHandler) SampleByJobID(w http.ResponseWriter, r *http.Request) {
+	jobID := extractJobIDFromPath(r.URL.Path)
+	if _, err := uuid.Parse(jobID); err != nil {
+		h.jsonError(w, "Invalid job ID format", http.StatusBadRequest)
+		return
+	}
+
+	h.sample(w, r, jobID)
+}
+
+// sample renders n random listings of the job, or of all jobs for an
+// empty jobID, with their quality. Samples are cached for a few minutes,
+// and clients may cache them as long.
+func (h *BusinessListingHandler) sample(w http.ResponseWriter, r *http.Request, jobID string) {
+	n := domain.DefaultSampleSize
+	if v := r.URL.Query().Get("n"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil || n < 1 || n > domain.MaxSampleSize {
+			h.jsonError(w, fmt.Sprintf("n must be between 1 and %d", domain.MaxSampleSize), http.StatusBadRequest)
+			return
+		}
+	}
+
+	sample, err := h.svc.Sample(r.Context(), jobID, n)
+	if err != nil {
+		logging.Logger(r.Context(), "BusinessListingHandler").Error("Sample failed", "job_id", jobID, "error", err)
+		h.jsonError(w, "Failed to sample listings", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, max-age=180")
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"data": sample,
+	})
+}
+
+// FieldStatsByJobID handles GET /api/v2/jobs/{id}/results/field-stats
+func (h *BusinessListingHandler) FieldStatsByJobID(w http.ResponseWriter, r *http.Request) {
+	jobID := extractJobIDFromPath(r.URL.Path)
+	if _, err := uuid.Parse(jobID); err != nil {
+		h.jsonError(w, "Invalid job ID format", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.svc.FieldStats(r.Context(), jobID)
+	if err != nil {
+		logging.Logger(r.Context(), "BusinessListingHandler").Error("Field stats failed", "job_id", jobID, "error", err)
+		h.jsonError(w, "Failed to compute field stats", http.StatusInternalServerError)
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"data": stats,
+	})
+}
+
 // GetAvailableColumns handles GET /api/v2/results/columns
 func (h *BusinessListingHandler) GetAvailableColumns(w http.ResponseWriter, r *http.Request) {
 	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
@@ -386,6 +939,49 @@ func (h *BusinessListingHandler) GetAvailableColumns(w http.ResponseWriter, r *h
 	})
 }
 
+// exportProfile resolves the export profile a download goes through, nil
+// for none. It renders the error and reports false when the download
+// must not start.
+func (h *BusinessListingHandler) exportProfile(w http.ResponseWriter, r *http.Request, requested string) (*domain.ExportProfile, bool) {
+	if h.profiles == nil {
+		if requested != "" {
+			h.jsonError(w, "Export profiles are not enabled", http.StatusBadRequest)
+			return nil, false
+		}
+		return nil, true
+	}
+
+	profile, err := h.profiles.Resolve(r.Context(), requested)
+	switch {
+	case errors.Is(err, domain.ErrExportProfileForbidden):
+		h.jsonError(w, err.Error(), http.StatusForbidden)
+		return nil, false
+	case errors.Is(err, domain.ErrExportProfileNotFound):
+		h.jsonError(w, "Unknown export profile: "+requested, http.StatusBadRequest)
+		return nil, false
+	case err != nil:
+		logging.Logger(r.Context(), "BusinessListingHandler").Error("failed to resolve export profile", "export_profile", requested, "error", err)
+		h.jsonError(w, "Failed to resolve export profile", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return profile, true
+}
+
+// invalidColumn returns the first of columns that cannot be exported, or ""
+func (h *BusinessListingHandler) invalidColumn(columns []string) string {
+	validCols := make(map[string]bool)
+	for _, c := range h.svc.AvailableColumns() {
+		validCols[c] = true
+	}
+	for _, c := range columns {
+		if !validCols[c] {
+			return c
+		}
+	}
+	return ""
+}
+
 // extractJobIDFromPath extracts job ID from paths like /api/v2/jobs/{id}/results
 func extractJobIDFromPath(path string) string {
 	parts := strings.Split(path, "/")
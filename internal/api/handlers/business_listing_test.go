@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// sampledListings samples listings titled after the job sampled
+type sampledListings struct {
+	domain.BusinessListingRepository
+}
+
+func (s *sampledListings) Sample(ctx context.Context, jobID string, n int) (*domain.ListingSample, error) {
+	sample := &domain.ListingSample{Quality: domain.ListingQuality{Listings: 100}}
+	for range n {
+		sample.Listings = append(sample.Listings, &domain.BusinessListing{Title: jobID})
+	}
+	return sample, nil
+}
+
+func TestSampleListings(t *testing.T) {
+	h := NewBusinessListingHandler(service.NewBusinessListingService(&sampledListings{}))
+	jobID := uuid.NewString()
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		size   int
+		title  string
+	}{
+		{"default size", "/api/v2/results/sample", http.StatusOK, domain.DefaultSampleSize, ""},
+		{"job", "/api/v2/jobs/" + jobID + "/results/sample?n=3", http.StatusOK, 3, jobID},
+		{"largest size", "/api/v2/results/sample?n=500", http.StatusOK, 500, ""},
+		{"too large", "/api/v2/results/sample?n=501", http.StatusBadRequest, 0, ""},
+		{"not a number", "/api/v2/results/sample?n=all", http.StatusBadRequest, 0, ""},
+		{"invalid job", "/api/v2/jobs/nope/results/sample", http.StatusBadRequest, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if r.URL.Path == "/api/v2/results/sample" {
+				h.Sample(w, r)
+			} else {
+				h.SampleByJobID(w, r)
+			}
+
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data domain.ListingSample `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Len(t, resp.Data.Listings, tt.size)
+			assert.Equal(t, tt.title, resp.Data.Listings[0].Title)
+			assert.Equal(t, 100, resp.Data.Quality.Listings)
+			assert.Equal(t, "private, max-age=180", w.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+// statsListings has field stats for every job, with one listing
+type statsListings struct {
+	domain.BusinessListingRepository
+}
+
+func (s *statsListings) FieldStats(ctx context.Context, jobID string) (*domain.ListingFieldStats, error) {
+	return &domain.ListingFieldStats{
+		JobID:    uuid.MustParse(jobID),
+		Listings: 1,
+		Columns:  []domain.ColumnStats{{Column: "website", Filled: 1, FillRate: 100, Distinct: 1}},
+	}, nil
+}
+
+func TestFieldStatsByJobID(t *testing.T) {
+	h := NewBusinessListingHandler(service.NewBusinessListingService(&statsListings{}))
+	jobID := uuid.NewString()
+
+	w := httptest.NewRecorder()
+	h.FieldStatsByJobID(w, httptest.NewRequest(http.MethodGet, "/api/v2/jobs/"+jobID+"/results/field-stats", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp struct {
+		Data domain.ListingFieldStats `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, jobID, resp.Data.JobID.String())
+	assert.Equal(t, []domain.ColumnStats{{Column: "website", Filled: 1, FillRate: 100, Distinct: 1}}, resp.Data.Columns)
+
+	w = httptest.NewRecorder()
+	h.FieldStatsByJobID(w, httptest.NewRequest(http.MethodGet, "/api/v2/jobs/nope/results/field-stats", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
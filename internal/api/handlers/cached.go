@@ -5,14 +5,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 
 	"github.com/sadewadee/google-scraper/internal/cache"
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
 )
 
 // CachedStatsHandler wraps StatsHandler with Redis cache
@@ -41,7 +42,7 @@ func (h *CachedStatsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Re
 
 	// Try cache first
 	if cached, err := h.cache.Get(ctx, cacheKey); err == nil && cached != nil {
-		log.Printf("[CachedStats] Cache HIT for stats")
+		logging.Logger(ctx, "CachedStats").Debug("cache hit", "entry", "stats")
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("X-Cache", "HIT")
 		w.WriteHeader(http.StatusOK)
@@ -50,7 +51,7 @@ func (h *CachedStatsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Re
 	}
 
 	// Cache miss - fetch from service
-	log.Printf("[CachedStats] Cache MISS for stats")
+	logging.Logger(ctx, "CachedStats").Debug("cache miss", "entry", "stats")
 	stats, err := h.stats.GetStats(ctx)
 	if err != nil {
 		RenderError(w, http.StatusInternalServerError, "Failed to get stats: "+err.Error())
@@ -61,7 +62,7 @@ func (h *CachedStatsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Re
 	data, err := json.Marshal(stats)
 	if err == nil {
 		if cacheErr := h.cache.Set(ctx, cacheKey, data, cache.TTLStats); cacheErr != nil {
-			log.Printf("[CachedStats] Failed to cache stats: %v", cacheErr)
+			logging.Logger(ctx, "CachedStats").Warn("failed to cache stats", "error", cacheErr)
 		}
 	}
 
@@ -106,14 +107,26 @@ func (h *CachedJobHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	status := r.URL.Query().Get("status")
+	if status != "" && !domain.JobStatus(status).IsValid() {
+		RenderError(w, http.StatusBadRequest, "Invalid status: "+status)
+		return
+	}
+	includeDeleted := r.URL.Query().Get("include_deleted") == "true"
+	tags := domain.NormalizeTags(r.URL.Query()["tag"])
+
+	errorCode := r.URL.Query().Get("error_code")
+	if errorCode != "" && !domain.JobErrorCode(errorCode).IsValid() {
+		RenderError(w, http.StatusBadRequest, "Invalid error_code: "+errorCode)
+		return
+	}
 
 	// Build cache key
-	cacheKey := fmt.Sprintf("%s:list:page=%d:perPage=%d:status=%s",
-		cache.KeyPrefixDashboardJobs, page, perPage, status)
+	cacheKey := fmt.Sprintf("%s:list:page=%d:perPage=%d:status=%s:deleted=%t:tags=%s:error_code=%s",
+		cache.KeyPrefixDashboardJobs, page, perPage, status, includeDeleted, strings.Join(tags, ","), errorCode)
 
 	// Try cache first
 	if cached, err := h.cache.Get(ctx, cacheKey); err == nil && cached != nil {
-		log.Printf("[CachedJobs] Cache HIT for jobs list")
+		logging.Logger(ctx, "CachedJobs").Debug("cache hit", "entry", "jobs list")
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("X-Cache", "HIT")
 		w.WriteHeader(http.StatusOK)
@@ -122,16 +135,22 @@ func (h *CachedJobHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Cache miss - fetch from service
-	log.Printf("[CachedJobs] Cache MISS for jobs list")
+	logging.Logger(ctx, "CachedJobs").Debug("cache miss", "entry", "jobs list")
 	params := domain.JobListParams{
-		Limit:  perPage,
-		Offset: (page - 1) * perPage,
+		Limit:          perPage,
+		Offset:         (page - 1) * perPage,
+		IncludeDeleted: includeDeleted,
+		Tags:           tags,
 	}
 
 	if status != "" {
 		s := domain.JobStatus(status)
 		params.Status = &s
 	}
+	if errorCode != "" {
+		c := domain.JobErrorCode(errorCode)
+		params.ErrorCode = &c
+	}
 
 	jobs, total, err := h.jobs.List(ctx, params)
 	if err != nil {
@@ -139,13 +158,13 @@ func (h *CachedJobHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := NewPaginatedResponse(jobs, total, page, perPage)
+	response := NewPaginatedResponse(maskJobs(jobs), total, page, perPage)
 
 	// Serialize and cache
 	data, err := json.Marshal(response)
 	if err == nil {
 		if cacheErr := h.cache.Set(ctx, cacheKey, data, cache.TTLJobsList); cacheErr != nil {
-			log.Printf("[CachedJobs] Failed to cache jobs list: %v", cacheErr)
+			logging.Logger(ctx, "CachedJobs").Warn("failed to cache jobs list", "error", cacheErr)
 		}
 	}
 
@@ -171,7 +190,7 @@ func (h *CachedJobHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	// Try cache first
 	if cached, err := h.cache.Get(ctx, cacheKey); err == nil && cached != nil {
-		log.Printf("[CachedJobs] Cache HIT for job %s", id)
+		logging.Logger(ctx, "CachedJobs").Debug("cache hit", "entry", "job", "job_id", id)
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("X-Cache", "HIT")
 		w.WriteHeader(http.StatusOK)
@@ -180,7 +199,7 @@ func (h *CachedJobHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Cache miss - fetch from service
-	log.Printf("[CachedJobs] Cache MISS for job %s", id)
+	logging.Logger(ctx, "CachedJobs").Debug("cache miss", "entry", "job", "job_id", id)
 	job, err := h.jobs.GetByID(ctx, id)
 	if err != nil {
 		RenderError(w, http.StatusInternalServerError, "Failed to retrieve job: "+err.Error())
@@ -193,15 +212,15 @@ func (h *CachedJobHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Serialize and cache
-	data, err := json.Marshal(job)
+	data, err := json.Marshal(maskJob(job))
 	if err == nil {
 		if cacheErr := h.cache.Set(ctx, cacheKey, data, cache.TTLJobDetail); cacheErr != nil {
-			log.Printf("[CachedJobs] Failed to cache job: %v", cacheErr)
+			logging.Logger(ctx, "CachedJobs").Warn("failed to cache job", "error", cacheErr)
 		}
 	}
 
 	w.Header().Set("X-Cache", "MISS")
-	RenderJSON(w, http.StatusOK, job)
+	RenderJSON(w, http.StatusOK, maskJob(job))
 }
 
 // GetResults handles GET /api/v2/jobs/{id}/results with caching
@@ -236,7 +255,7 @@ func (h *CachedJobHandler) GetResults(w http.ResponseWriter, r *http.Request) {
 
 	// Try cache first
 	if cached, err := h.cache.Get(ctx, cacheKey); err == nil && cached != nil {
-		log.Printf("[CachedJobs] Cache HIT for results job %s", id)
+		logging.Logger(ctx, "CachedJobs").Debug("cache hit", "entry", "job results", "job_id", id)
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("X-Cache", "HIT")
 		w.WriteHeader(http.StatusOK)
@@ -245,7 +264,7 @@ func (h *CachedJobHandler) GetResults(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Cache miss - fetch from service
-	log.Printf("[CachedJobs] Cache MISS for results job %s", id)
+	logging.Logger(ctx, "CachedJobs").Debug("cache miss", "entry", "job results", "job_id", id)
 	offset := (page - 1) * perPage
 
 	results, total, err := h.results.ListByJobID(ctx, id, perPage, offset)
@@ -254,19 +273,13 @@ func (h *CachedJobHandler) GetResults(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse JSON data
-	var parsedResults []json.RawMessage
-	for _, data := range results {
-		parsedResults = append(parsedResults, json.RawMessage(data))
-	}
-
-	response := NewPaginatedResponse(parsedResults, total, page, perPage)
+	response := newJobResultsPage(ctx, h.jobs, id, results, total, page, perPage)
 
 	// Serialize and cache
 	data, err := json.Marshal(response)
 	if err == nil {
 		if cacheErr := h.cache.Set(ctx, cacheKey, data, cache.TTLResults); cacheErr != nil {
-			log.Printf("[CachedJobs] Failed to cache results: %v", cacheErr)
+			logging.Logger(ctx, "CachedJobs").Warn("failed to cache results", "error", cacheErr)
 		}
 	}
 
@@ -286,7 +299,7 @@ func (h *CachedJobHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 
 	// Try cache first
 	if cached, err := h.cache.Get(ctx, cacheKey); err == nil && cached != nil {
-		log.Printf("[CachedJobs] Cache HIT for job stats")
+		logging.Logger(ctx, "CachedJobs").Debug("cache hit", "entry", "job stats")
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("X-Cache", "HIT")
 		w.WriteHeader(http.StatusOK)
@@ -295,7 +308,7 @@ func (h *CachedJobHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Cache miss - fetch from service
-	log.Printf("[CachedJobs] Cache MISS for job stats")
+	logging.Logger(ctx, "CachedJobs").Debug("cache miss", "entry", "job stats")
 	stats, err := h.jobs.GetStats(ctx)
 	if err != nil {
 		RenderError(w, http.StatusInternalServerError, "Failed to get stats: "+err.Error())
@@ -306,7 +319,7 @@ func (h *CachedJobHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	data, err := json.Marshal(stats)
 	if err == nil {
 		if cacheErr := h.cache.Set(ctx, cacheKey, data, cache.TTLStats); cacheErr != nil {
-			log.Printf("[CachedJobs] Failed to cache job stats: %v", cacheErr)
+			logging.Logger(ctx, "CachedJobs").Warn("failed to cache job stats", "error", cacheErr)
 		}
 	}
 
@@ -318,26 +331,26 @@ func (h *CachedJobHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 func (h *CachedJobHandler) InvalidateJobCache(ctx context.Context, jobID uuid.UUID) error {
 	// Invalidate job detail
 	if err := h.cache.Delete(ctx, fmt.Sprintf("%s:%s", cache.KeyPrefixDashboardJobs, jobID.String())); err != nil {
-		log.Printf("[CachedJobs] Failed to invalidate job cache: %v", err)
+		logging.Logger(ctx, "CachedJobs").Warn("failed to invalidate job cache", "error", err)
 	}
 
 	// Invalidate job list (all pages)
 	if err := h.cache.DeleteByPattern(ctx, cache.KeyPrefixDashboardJobs+":list:*"); err != nil {
-		log.Printf("[CachedJobs] Failed to invalidate job list cache: %v", err)
+		logging.Logger(ctx, "CachedJobs").Warn("failed to invalidate job list cache", "error", err)
 	}
 
 	// Invalidate results for this job
 	if err := h.cache.DeleteByPattern(ctx, fmt.Sprintf("%s:%s:*", cache.KeyPrefixDashboardResults, jobID.String())); err != nil {
-		log.Printf("[CachedJobs] Failed to invalidate results cache: %v", err)
+		logging.Logger(ctx, "CachedJobs").Warn("failed to invalidate results cache", "error", err)
 	}
 
 	// Invalidate stats
 	if err := h.cache.Delete(ctx, cache.KeyPrefixDashboardStats); err != nil {
-		log.Printf("[CachedJobs] Failed to invalidate stats cache: %v", err)
+		logging.Logger(ctx, "CachedJobs").Warn("failed to invalidate stats cache", "error", err)
 	}
 
 	if err := h.cache.Delete(ctx, cache.KeyPrefixDashboardJobs+":stats"); err != nil {
-		log.Printf("[CachedJobs] Failed to invalidate job stats cache: %v", err)
+		logging.Logger(ctx, "CachedJobs").Warn("failed to invalidate job stats cache", "error", err)
 	}
 
 	return nil
@@ -383,7 +396,7 @@ func (h *CachedResultHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	// Try cache first
 	if cached, err := h.cache.Get(ctx, cacheKey); err == nil && cached != nil {
-		log.Printf("[CachedResults] Cache HIT for results list")
+		logging.Logger(ctx, "CachedResults").Debug("cache hit", "entry", "results list")
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("X-Cache", "HIT")
 		w.WriteHeader(http.StatusOK)
@@ -392,7 +405,7 @@ func (h *CachedResultHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Cache miss - fetch from service
-	log.Printf("[CachedResults] Cache MISS for results list")
+	logging.Logger(ctx, "CachedResults").Debug("cache miss", "entry", "results list")
 	offset := (page - 1) * perPage
 
 	results, total, err := h.results.ListAll(ctx, perPage, offset)
@@ -413,7 +426,7 @@ func (h *CachedResultHandler) List(w http.ResponseWriter, r *http.Request) {
 	data, err := json.Marshal(response)
 	if err == nil {
 		if cacheErr := h.cache.Set(ctx, cacheKey, data, cache.TTLResults); cacheErr != nil {
-			log.Printf("[CachedResults] Failed to cache results: %v", cacheErr)
+			logging.Logger(ctx, "CachedResults").Warn("failed to cache results", "error", cacheErr)
 		}
 	}
 
@@ -443,22 +456,22 @@ func NewCacheInvalidator(c cache.Cache) *CacheInvalidator {
 func (ci *CacheInvalidator) InvalidateOnNewResults(ctx context.Context, jobID uuid.UUID) error {
 	// Invalidate results for this job
 	if err := ci.cache.DeleteByPattern(ctx, fmt.Sprintf("%s:%s:*", cache.KeyPrefixDashboardResults, jobID.String())); err != nil {
-		log.Printf("[CacheInvalidator] Failed to invalidate job results: %v", err)
+		logging.Logger(ctx, "CacheInvalidator").Warn("failed to invalidate job results", "error", err)
 	}
 
 	// Invalidate global results
 	if err := ci.cache.DeleteByPattern(ctx, cache.KeyPrefixDashboardResults+":all:*"); err != nil {
-		log.Printf("[CacheInvalidator] Failed to invalidate all results: %v", err)
+		logging.Logger(ctx, "CacheInvalidator").Warn("failed to invalidate all results", "error", err)
 	}
 
 	// Invalidate search cache
 	if err := ci.cache.DeleteByPattern(ctx, cache.KeyPrefixDashboardSearch+":*"); err != nil {
-		log.Printf("[CacheInvalidator] Failed to invalidate search cache: %v", err)
+		logging.Logger(ctx, "CacheInvalidator").Warn("failed to invalidate search cache", "error", err)
 	}
 
 	// Invalidate stats
 	if err := ci.cache.Delete(ctx, cache.KeyPrefixDashboardStats); err != nil {
-		log.Printf("[CacheInvalidator] Failed to invalidate stats: %v", err)
+		logging.Logger(ctx, "CacheInvalidator").Warn("failed to invalidate stats", "error", err)
 	}
 
 	return nil
@@ -468,17 +481,17 @@ func (ci *CacheInvalidator) InvalidateOnNewResults(ctx context.Context, jobID uu
 func (ci *CacheInvalidator) InvalidateOnJobStatusChange(ctx context.Context, jobID uuid.UUID) error {
 	// Invalidate job detail
 	if err := ci.cache.Delete(ctx, fmt.Sprintf("%s:%s", cache.KeyPrefixDashboardJobs, jobID.String())); err != nil {
-		log.Printf("[CacheInvalidator] Failed to invalidate job: %v", err)
+		logging.Logger(ctx, "CacheInvalidator").Warn("failed to invalidate job", "error", err)
 	}
 
 	// Invalidate job list
 	if err := ci.cache.DeleteByPattern(ctx, cache.KeyPrefixDashboardJobs+":list:*"); err != nil {
-		log.Printf("[CacheInvalidator] Failed to invalidate job list: %v", err)
+		logging.Logger(ctx, "CacheInvalidator").Warn("failed to invalidate job list", "error", err)
 	}
 
 	// Invalidate job stats
 	if err := ci.cache.Delete(ctx, cache.KeyPrefixDashboardJobs+":stats"); err != nil {
-		log.Printf("[CacheInvalidator] Failed to invalidate job stats: %v", err)
+		logging.Logger(ctx, "CacheInvalidator").Warn("failed to invalidate job stats", "error", err)
 	}
 
 	return nil
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// CategoryServiceInterface defines the category taxonomy service methods
+type CategoryServiceInterface interface {
+	AddMappings(ctx context.Context, mappings []domain.CategoryMapping) (int64, error)
+	ListMappings(ctx context.Context) ([]*domain.CategoryMapping, error)
+	Unmapped(ctx context.Context, limit int) ([]domain.CategoryCount, error)
+}
+
+// CategoryHandler maintains the mappings of localized categories to the
+// canonical taxonomy
+type CategoryHandler struct {
+	categories CategoryServiceInterface
+}
+
+// NewCategoryHandler creates a new CategoryHandler
+func NewCategoryHandler(categories CategoryServiceInterface) *CategoryHandler {
+	return &CategoryHandler{
+		categories: categories,
+	}
+}
+
+// AddMappingsRequest is the body of POST /api/v2/admin/categories/mappings
+type AddMappingsRequest struct {
+	Mappings []domain.CategoryMapping `json:"mappings"`
+}
+
+// Mappings handles /api/v2/admin/categories/mappings. GET lists the
+// mappings and POST creates or replaces the ones in the body, relabeling
+// the listings of their aliases.
+func (h *CategoryHandler) Mappings(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context(), "CategoryHandler")
+
+	switch r.Method {
+	case http.MethodGet:
+		mappings, err := h.categories.ListMappings(r.Context())
+		if err != nil {
+			logger.Error("ListMappings failed", "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to list category mappings")
+			return
+		}
+
+		RenderJSON(w, http.StatusOK, map[string]interface{}{
+			"data": mappings,
+		})
+	case http.MethodPost:
+		var req AddMappingsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+
+		relabeled, err := h.categories.AddMappings(r.Context(), req.Mappings)
+		if err != nil {
+			if errors.Is(err, domain.ErrInvalidCategoryMapping) {
+				RenderError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			logger.Error("AddMappings failed", "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to add category mappings")
+			return
+		}
+
+		RenderJSON(w, http.StatusOK, map[string]interface{}{
+			"relabeled": relabeled,
+		})
+	default:
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Unmapped handles GET /api/v2/admin/categories/unmapped?limit= with the
+// categories no alias matches, most listed first, so the taxonomy grows
+// where it matters
+func (h *CategoryHandler) Unmapped(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	limit := 100
+	if l := r.URL.Query().Get("limit"); l != "" {
+		val, err := strconv.Atoi(l)
+		if err != nil || val <= 0 || val > 500 {
+			RenderError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+		limit = val
+	}
+
+	categories, err := h.categories.Unmapped(r.Context(), limit)
+	if err != nil {
+		logging.Logger(r.Context(), "CategoryHandler").Error("Unmapped failed", "error", err)
+		RenderError(w, http.StatusInternalServerError, "Failed to list unmapped categories")
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, map[string]interface{}{
+		"data": categories,
+	})
+}
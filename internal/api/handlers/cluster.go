@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// ClusterStatusProvider reports the leader election state of the replica
+type ClusterStatusProvider interface {
+	Status(ctx context.Context) *domain.ClusterStatus
+}
+
+// ClusterHandler handles manager cluster HTTP requests
+type ClusterHandler struct {
+	elector ClusterStatusProvider
+}
+
+// NewClusterHandler creates a new ClusterHandler
+func NewClusterHandler(elector ClusterStatusProvider) *ClusterHandler {
+	return &ClusterHandler{
+		elector: elector,
+	}
+}
+
+// Status handles GET /api/v2/cluster/status. Each replica answers for
+// itself, so behind a load balancer replica_id tells which one answered.
+func (h *ClusterHandler) Status(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, h.elector.Status(r.Context()))
+}
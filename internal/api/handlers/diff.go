@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// DiffServiceInterface defines the listing diff service methods
+type DiffServiceInterface interface {
+	Diff(ctx context.Context, filter domain.ListingDiffFilter) ([]*domain.ListingDiff, int, *domain.ListingDiffSummary, error)
+	Check(ctx context.Context, filter *domain.ListingDiffFilter) error
+	ExportCSV(ctx context.Context, w io.Writer, filter domain.ListingDiffFilter) error
+}
+
+// DiffHandler compares the results of two jobs
+type DiffHandler struct {
+	diffs DiffServiceInterface
+}
+
+// NewDiffHandler creates a new DiffHandler
+func NewDiffHandler(diffs DiffServiceInterface) *DiffHandler {
+	return &DiffHandler{
+		diffs: diffs,
+	}
+}
+
+// Diff handles GET /api/v2/jobs/{id}/diff?against=&change=&include_unchanged=&format=
+func (h *DiffHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	q := r.URL.Query()
+
+	against, err := uuid.Parse(q.Get("against"))
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "against must be the ID of the job to compare against")
+		return
+	}
+
+	filter := domain.ListingDiffFilter{
+		JobID:     id,
+		AgainstID: against,
+		Page:      1,
+		PerPage:   50,
+	}
+
+	if c := q.Get("change"); c != "" {
+		filter.Changes = strings.Split(c, ",")
+	} else if v := q.Get("include_unchanged"); strings.ToLower(v) == "true" || v == "1" {
+		filter.Changes = domain.DiffChanges
+	}
+
+	if p := q.Get("page"); p != "" {
+		if val, err := strconv.Atoi(p); err == nil && val > 0 {
+			filter.Page = val
+		}
+	}
+
+	if l := q.Get("limit"); l != "" {
+		if val, err := strconv.Atoi(l); err == nil && val > 0 && val <= 500 {
+			filter.PerPage = val
+		}
+	}
+
+	logger := logging.Logger(r.Context(), "DiffHandler")
+
+	switch format := q.Get("format"); format {
+	case "", "json":
+		diffs, total, summary, err := h.diffs.Diff(r.Context(), filter)
+		if err != nil {
+			h.renderError(w, r, err)
+			return
+		}
+
+		RenderJSON(w, http.StatusOK, map[string]interface{}{
+			"data":    diffs,
+			"summary": summary,
+			"meta": map[string]interface{}{
+				"page":        filter.Page,
+				"per_page":    filter.PerPage,
+				"total":       total,
+				"total_pages": (total + filter.PerPage - 1) / filter.PerPage,
+			},
+		})
+	case "csv":
+		if err := h.diffs.Check(r.Context(), &filter); err != nil {
+			h.renderError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=diff_"+id.String()[:8]+"_"+against.String()[:8]+".csv")
+		if err := h.diffs.ExportCSV(r.Context(), w, filter); err != nil {
+			logger.Error("diff export failed", "job_id", id, "against", against, "error", err)
+		}
+	default:
+		RenderError(w, http.StatusBadRequest, "Invalid format. Supported: json, csv")
+	}
+}
+
+func (h *DiffHandler) renderError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrJobNotFound):
+		RenderError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrDiffSameJob), errors.Is(err, service.ErrInvalidDiffChange):
+		RenderError(w, http.StatusBadRequest, err.Error())
+	default:
+		logging.Logger(r.Context(), "DiffHandler").Error("diff failed", "error", err)
+		RenderError(w, http.StatusInternalServerError, "Failed to compare jobs")
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// exportCompleteTrailer is the trailer streamed downloads end with: true
+// once every row was written, false when the stream broke off. The status
+// is sent before the rows, so it cannot tell a truncated file.
+const exportCompleteTrailer = "X-Export-Complete"
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// partialResultsHeader is set to true on downloads of a job still running
+const partialResultsHeader = "X-Results-Partial"
+
+// errPartialResults refuses a download of a job still running without
+// ?partial=true
+var errPartialResults = errors.New("job is still running, its results are incomplete; add partial=true to download them anyway")
+
+// resultsPartial returns true if results of job id may still be added
+func resultsPartial(ctx context.Context, jobs JobLookup, id uuid.UUID) (bool, error) {
+	job, err := jobs.GetByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if job == nil {
+		return false, service.ErrJobNotFound
+	}
+	return job.Status.ResultsPartial(), nil
+}
+
+// checkPartialDownload returns errPartialResults for a download of a job
+// still running, unless the request accepts it with ?partial=true; the
+// download is then marked with X-Results-Partial
+func checkPartialDownload(w http.ResponseWriter, r *http.Request, jobs JobLookup, id uuid.UUID) error {
+	partial, err := resultsPartial(r.Context(), jobs, id)
+	if err != nil || !partial {
+		return err
+	}
+	if ok, _ := strconv.ParseBool(r.URL.Query().Get("partial")); !ok {
+		return errPartialResults
+	}
+	w.Header().Set(partialResultsHeader, "true")
+	return nil
+}
+
+// partialDownloadStatus is the status answering an error of
+// checkPartialDownload
+func partialDownloadStatus(err error) int {
+	switch {
+	case errors.Is(err, errPartialResults):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrJobNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// announceExportComplete declares the X-Export-Complete trailer; it must be
+// called before the body is written
+func announceExportComplete(w http.ResponseWriter) {
+	w.Header().Add("Trailer", exportCompleteTrailer)
+}
+
+// endExport sets the X-Export-Complete trailer
+func endExport(w http.ResponseWriter, complete bool) {
+	w.Header().Set(exportCompleteTrailer, strconv.FormatBool(complete))
+}
+
+// writeExportMarker ends a complete CSV or NDJSON download with the line
+// clients without trailer support check for. A file without it is
+// truncated.
+func writeExportMarker(w io.Writer, rows int) error {
+	_, err := fmt.Fprintf(w, "# export_complete rows=%d\n", rows)
+	return err
+}
+
+// sendWorkbook builds a workbook in a temp file and sends it with its
+// Content-Length. Nothing is sent when build fails, so the caller can
+// still answer with an error status.
+func sendWorkbook(w http.ResponseWriter, filename string, build func(w io.Writer) error) (sent bool, err error) {
+	tmp, err := os.CreateTemp("", "export-*.xlsx")
+	if err != nil {
+		return false, fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := build(tmp); err != nil {
+		return false, err
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return false, fmt.Errorf("size workbook: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return false, fmt.Errorf("rewind workbook: %w", err)
+	}
+
+	w.Header().Set("Content-Type", xlsxContentType)
+	w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, tmp); err != nil {
+		return true, fmt.Errorf("send workbook: %w", err)
+	}
+
+	return true, nil
+}
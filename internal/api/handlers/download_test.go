@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// brokenResults streams its results, then fails when failAfter is set
+type brokenResults struct {
+	ResultServiceInterface
+
+	results   []string
+	failAfter bool
+}
+
+func (b *brokenResults) ListByJobID(ctx context.Context, jobID uuid.UUID, limit, offset int) ([][]byte, int, error) {
+	var data [][]byte
+	for _, r := range b.results {
+		data = append(data, []byte(r))
+	}
+	return data, len(data), nil
+}
+
+func (b *brokenResults) StreamByJobID(ctx context.Context, jobID uuid.UUID, fn func(data []byte) error) error {
+	for _, r := range b.results {
+		if err := fn([]byte(r)); err != nil {
+			return err
+		}
+	}
+	if b.failAfter {
+		return errors.New("connection reset by peer")
+	}
+	return nil
+}
+
+// jobStatus is a job service whose jobs all have status
+type jobStatus struct {
+	JobServiceInterface
+
+	status domain.JobStatus
+}
+
+func (j *jobStatus) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	return &domain.Job{ID: id, Status: j.status}, nil
+}
+
+func TestDownloadResultsTruncation(t *testing.T) {
+	jobID := uuid.New()
+	results := []string{`{"title":"Cafe"}`, `{"title":"Bakery"}`}
+
+	download := func(t *testing.T, failAfter bool, format string) (*http.Response, string) {
+		h := NewJobHandler(&jobStatus{status: domain.JobStatusCompleted}, &brokenResults{results: results, failAfter: failAfter})
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/v2/jobs/{id}/download", h.DownloadResults)
+		srv := httptest.NewServer(mux)
+		t.Cleanup(srv.Close)
+
+		resp, err := http.Get(srv.URL + "/api/v2/jobs/" + jobID.String() + "/download?format=" + format + "&columns=title")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	t.Run("complete csv", func(t *testing.T) {
+		resp, body := download(t, false, "csv")
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "true", resp.Trailer.Get(exportCompleteTrailer))
+		assert.True(t, strings.HasSuffix(body, "# export_complete rows=2\n"), body)
+	})
+
+	t.Run("truncated csv", func(t *testing.T) {
+		resp, body := download(t, true, "csv")
+
+		assert.Equal(t, "false", resp.Trailer.Get(exportCompleteTrailer))
+		assert.Contains(t, body, "Bakery")
+		assert.NotContains(t, body, "# export_complete")
+	})
+
+	t.Run("complete json", func(t *testing.T) {
+		resp, body := download(t, false, "json")
+
+		assert.Equal(t, "true", resp.Trailer.Get(exportCompleteTrailer))
+		var parsed []map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(body), &parsed))
+		assert.Len(t, parsed, 2)
+	})
+
+	t.Run("truncated json", func(t *testing.T) {
+		resp, body := download(t, true, "json")
+
+		assert.Equal(t, "false", resp.Trailer.Get(exportCompleteTrailer))
+		var parsed []map[string]any
+		assert.Error(t, json.Unmarshal([]byte(body), &parsed))
+	})
+
+	t.Run("complete xlsx", func(t *testing.T) {
+		resp, body := download(t, false, "xlsx")
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int64(len(body)), resp.ContentLength)
+		assert.Equal(t, "attachment; filename=results-"+jobID.String()+".xlsx", resp.Header.Get("Content-Disposition"))
+	})
+
+	t.Run("failed xlsx", func(t *testing.T) {
+		resp, _ := download(t, true, "xlsx")
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Content-Disposition"))
+	})
+}
+
+func TestDownloadResultsPartial(t *testing.T) {
+	jobID := uuid.New()
+	h := NewJobHandler(&jobStatus{status: domain.JobStatusRunning}, &brokenResults{results: []string{`{"title":"Cafe"}`}})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/jobs/{id}/download", h.DownloadResults)
+	mux.HandleFunc("/api/v2/jobs/{id}/results", h.GetResults)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2/jobs/"+jobID.String()+path, nil))
+		return w
+	}
+
+	w := get("/download?format=json")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "partial=true")
+
+	w = get("/download?format=json&partial=true")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get(partialResultsHeader))
+	assert.Contains(t, w.Body.String(), "Cafe")
+
+	w = get("/results")
+	require.Equal(t, http.StatusOK, w.Code)
+	var page JobResultsPage
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+	assert.True(t, page.IsPartial)
+	assert.Equal(t, 1, page.Total)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// DuplicateServiceInterface defines the duplicate service methods
+type DuplicateServiceInterface interface {
+	FindClusters(ctx context.Context, filter domain.DuplicateFilter) ([]*domain.DuplicateCluster, int, error)
+	Merge(ctx context.Context, req *domain.MergeDuplicatesRequest) (*domain.ListingMerge, error)
+}
+
+// DuplicateHandler handles the duplicate listings report and merges
+type DuplicateHandler struct {
+	duplicates DuplicateServiceInterface
+}
+
+// NewDuplicateHandler creates a new DuplicateHandler
+func NewDuplicateHandler(duplicates DuplicateServiceInterface) *DuplicateHandler {
+	return &DuplicateHandler{
+		duplicates: duplicates,
+	}
+}
+
+// List handles GET /api/v2/results/duplicates?criterion=&min_cluster_size=
+func (h *DuplicateHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	filter := domain.DuplicateFilter{
+		Criterion:      r.URL.Query().Get("criterion"),
+		MinClusterSize: 2,
+		Page:           1,
+		PerPage:        25,
+	}
+
+	if p := r.URL.Query().Get("page"); p != "" {
+		if val, err := strconv.Atoi(p); err == nil && val > 0 {
+			filter.Page = val
+		}
+	}
+
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if val, err := strconv.Atoi(l); err == nil && val > 0 && val <= 100 {
+			filter.PerPage = val
+		}
+	}
+
+	if m := r.URL.Query().Get("min_cluster_size"); m != "" {
+		val, err := strconv.Atoi(m)
+		if err != nil || val < 2 {
+			RenderError(w, http.StatusBadRequest, "min_cluster_size must be an integer of at least 2")
+			return
+		}
+		filter.MinClusterSize = val
+	}
+
+	clusters, total, err := h.duplicates.FindClusters(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCriterion) {
+			RenderError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		logging.Logger(r.Context(), "DuplicateHandler").Error("FindClusters failed", "error", err)
+		RenderError(w, http.StatusInternalServerError, "Failed to find duplicates")
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, map[string]interface{}{
+		"data": clusters,
+		"meta": map[string]interface{}{
+			"page":        filter.Page,
+			"per_page":    filter.PerPage,
+			"total":       total,
+			"total_pages": (total + filter.PerPage - 1) / filter.PerPage,
+		},
+	})
+}
+
+// Merge handles POST /api/v2/results/duplicates/merge
+func (h *DuplicateHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req domain.MergeDuplicatesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	merge, err := h.duplicates.Merge(r.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMergeNoSurvivor),
+			errors.Is(err, service.ErrMergeNothingToMerge),
+			errors.Is(err, service.ErrInvalidCriterion):
+			RenderError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrListingNotFound):
+			RenderError(w, http.StatusNotFound, "One or more listings not found")
+		default:
+			logging.Logger(r.Context(), "DuplicateHandler").Error("Merge failed", "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to merge listings")
+		}
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, merge)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// EmailValidationServiceInterface defines the email validation service methods
+type EmailValidationServiceInterface interface {
+	Stats(ctx context.Context) (*domain.EmailValidationStats, error)
+}
+
+// EmailValidationHandler reports the progress of email validation
+type EmailValidationHandler struct {
+	validation EmailValidationServiceInterface
+}
+
+// NewEmailValidationHandler creates a new EmailValidationHandler
+func NewEmailValidationHandler(validation EmailValidationServiceInterface) *EmailValidationHandler {
+	return &EmailValidationHandler{
+		validation: validation,
+	}
+}
+
+// Stats handles GET /api/v2/emails/validation-stats
+func (h *EmailValidationHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	stats, err := h.validation.Stats(r.Context())
+	if err != nil {
+		logging.Logger(r.Context(), "EmailValidation").Error("stats failed", "error", err)
+		RenderError(w, http.StatusInternalServerError, "Failed to get email validation stats")
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/events"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// heartbeatInterval keeps idle streams alive through proxies and load
+// balancers that close silent connections
+const heartbeatInterval = 15 * time.Second
+
+// EventHandler streams live job updates as Server-Sent Events
+type EventHandler struct {
+	jobs   JobServiceInterface
+	events events.Subscriber
+}
+
+// NewEventHandler creates a new EventHandler
+func NewEventHandler(jobs JobServiceInterface, sub events.Subscriber) *EventHandler {
+	return &EventHandler{
+		jobs:   jobs,
+		events: sub,
+	}
+}
+
+// Stream handles GET /api/v2/jobs/{id}/events
+//
+// The first event is a status snapshot of the job, followed by progress and
+// status events as they happen. The stream ends after a complete event.
+func (h *EventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	// Subscribe before reading the snapshot so no change in between is lost
+	ch, unsubscribe := h.events.Subscribe(id)
+	defer unsubscribe()
+
+	job, err := h.jobs.GetByID(r.Context(), id)
+	if err != nil {
+		logging.Logger(r.Context(), "EventHandler").Error("GetByID failed", "error", err)
+		RenderError(w, http.StatusInternalServerError, "Failed to fetch job")
+		return
+	}
+	if job == nil {
+		RenderError(w, http.StatusNotFound, "Job not found")
+		return
+	}
+
+	rc := openStream(w, r, "EventHandler")
+
+	snapshot := events.StatusEvent(job.ID, job.Status, "")
+	progress := job.Progress
+	snapshot.Progress = &progress
+	if job.ErrorMessage != nil {
+		snapshot.Error = *job.ErrorMessage
+	}
+
+	if err := writeEvent(w, rc, snapshot); err != nil || snapshot.Type == events.TypeComplete {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if err := writeKeepAlive(w, rc); err != nil {
+				return
+			}
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, rc, ev); err != nil || ev.Type == events.TypeComplete {
+				return
+			}
+		}
+	}
+}
+
+// openStream sends the headers of an event stream
+func openStream(w http.ResponseWriter, r *http.Request, component string) *http.ResponseController {
+	rc := http.NewResponseController(w)
+
+	// The server's WriteTimeout is sized for downloads, not open-ended streams
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		logging.Logger(r.Context(), component).Warn("could not clear write deadline", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	return rc
+}
+
+func writeKeepAlive(w http.ResponseWriter, rc *http.ResponseController) error {
+	if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+		return err
+	}
+
+	return rc.Flush()
+}
+
+func writeEvent(w http.ResponseWriter, rc *http.ResponseController, ev events.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data); err != nil {
+		return err
+	}
+
+	return rc.Flush()
+}
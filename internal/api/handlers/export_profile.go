@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// ExportProfileServiceInterface defines the export profile service methods
+type ExportProfileServiceInterface interface {
+	Save(ctx context.Context, profile *domain.ExportProfile) error
+	Get(ctx context.Context, name string) (*domain.ExportProfile, error)
+	List(ctx context.Context) ([]*domain.ExportProfile, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// ExportProfileHandler manages the export profiles downloads are limited
+// by
+type ExportProfileHandler struct {
+	profiles ExportProfileServiceInterface
+}
+
+// NewExportProfileHandler creates a new ExportProfileHandler
+func NewExportProfileHandler(profiles ExportProfileServiceInterface) *ExportProfileHandler {
+	return &ExportProfileHandler{
+		profiles: profiles,
+	}
+}
+
+// Profiles handles /api/v2/export-profiles. GET lists the profiles and
+// POST creates the profile in the body or replaces the one of its name.
+func (h *ExportProfileHandler) Profiles(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context(), "ExportProfileHandler")
+
+	switch r.Method {
+	case http.MethodGet:
+		profiles, err := h.profiles.List(r.Context())
+		if err != nil {
+			logger.Error("List failed", "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to list export profiles")
+			return
+		}
+
+		if profiles == nil {
+			profiles = []*domain.ExportProfile{}
+		}
+
+		RenderJSON(w, http.StatusOK, map[string]interface{}{
+			"data": profiles,
+		})
+	case http.MethodPost:
+		var profile domain.ExportProfile
+		if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+			RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+
+		if err := h.profiles.Save(r.Context(), &profile); err != nil {
+			if errors.Is(err, domain.ErrInvalidExportProfile) {
+				RenderError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			logger.Error("Save failed", "profile", profile.Name, "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to save export profile")
+			return
+		}
+
+		RenderJSON(w, http.StatusOK, profile)
+	default:
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Profile handles /api/v2/export-profiles/{name}. GET returns the profile
+// and DELETE deletes it, refused while API keys are restricted to it.
+func (h *ExportProfileHandler) Profile(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context(), "ExportProfileHandler")
+	name := r.PathValue("name")
+
+	switch r.Method {
+	case http.MethodGet:
+		profile, err := h.profiles.Get(r.Context(), name)
+		if err != nil {
+			if errors.Is(err, domain.ErrExportProfileNotFound) {
+				RenderError(w, http.StatusNotFound, "Export profile not found")
+				return
+			}
+			logger.Error("Get failed", "profile", name, "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to get export profile")
+			return
+		}
+
+		RenderJSON(w, http.StatusOK, profile)
+	case http.MethodDelete:
+		if err := h.profiles.Delete(r.Context(), name); err != nil {
+			switch {
+			case errors.Is(err, domain.ErrExportProfileNotFound):
+				RenderError(w, http.StatusNotFound, "Export profile not found")
+			case errors.Is(err, domain.ErrExportProfileInUse):
+				RenderError(w, http.StatusConflict, "Export profile is assigned to API keys; delete those keys first")
+			default:
+				logger.Error("Delete failed", "profile", name, "error", err)
+				RenderError(w, http.StatusInternalServerError, "Failed to delete export profile")
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
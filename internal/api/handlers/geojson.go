@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/sadewadee/google-scraper/gmaps"
+)
+
+// geoJSONWriter streams raw results as a GeoJSON FeatureCollection of
+// points. Results without coordinates are skipped and counted.
+type geoJSONWriter struct {
+	w         io.Writer
+	columns   []string
+	available map[string]func(e *gmaps.Entry) string
+
+	features int
+	skipped  int
+}
+
+// startGeoJSON sets the download headers, announces the
+// X-Skipped-No-Coords trailer and opens the collection
+func startGeoJSON(w http.ResponseWriter, filename string, columns []string, available map[string]func(e *gmaps.Entry) string) (*geoJSONWriter, error) {
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.Header().Set("Content-Disposition", "attachment; filename="+filename+".geojson")
+	w.Header().Set("Trailer", "X-Skipped-No-Coords")
+
+	if _, err := io.WriteString(w, `{"type":"FeatureCollection","features":[`+"\n"); err != nil {
+		return nil, err
+	}
+
+	return &geoJSONWriter{w: w, columns: columns, available: available}, nil
+}
+
+// write adds the result in data as a feature
+func (g *geoJSONWriter) write(data []byte) error {
+	var entry gmaps.Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+
+	// 0,0 is what a result without coordinates decodes to
+	if entry.Latitude == 0 && entry.Longitude == 0 {
+		g.skipped++
+		return nil
+	}
+
+	properties := make(map[string]string, len(g.columns))
+	for _, col := range g.columns {
+		properties[col] = g.available[col](&entry)
+	}
+
+	feature, err := json.Marshal(map[string]any{
+		"type": "Feature",
+		"geometry": map[string]any{
+			"type":        "Point",
+			"coordinates": [2]float64{entry.Longitude, entry.Latitude},
+		},
+		"properties": properties,
+	})
+	if err != nil {
+		return err
+	}
+
+	if g.features > 0 {
+		if _, err := io.WriteString(g.w, ",\n"); err != nil {
+			return err
+		}
+	}
+	g.features++
+	_, err = g.w.Write(feature)
+	return err
+}
+
+// finish closes the collection and sets the trailer. Not calling it leaves
+// the trailer out, which tells the client the file is incomplete.
+func (g *geoJSONWriter) finish(w http.ResponseWriter) error {
+	if _, err := io.WriteString(g.w, "\n]}\n"); err != nil {
+		return err
+	}
+
+	w.Header().Set("X-Skipped-No-Coords", strconv.Itoa(g.skipped))
+	return nil
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeoJSONWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	columns := []string{"Title"}
+
+	g, err := startGeoJSON(rec, "results", columns, getAvailableColumns())
+	require.NoError(t, err)
+
+	for _, data := range []string{
+		`{"title":"Cafe","latitude":52.52,"longitude":13.40}`,
+		`{"title":"No coordinates"}`,
+		`{"title":"Bakery","latitude":-33.86,"longitude":151.21}`,
+	} {
+		require.NoError(t, g.write([]byte(data)))
+	}
+	require.NoError(t, g.finish(rec))
+
+	assert.Equal(t, "application/geo+json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "1", rec.Header().Get("X-Skipped-No-Coords"))
+
+	var fc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type        string    `json:"type"`
+				Coordinates []float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]string `json:"properties"`
+		} `json:"features"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &fc))
+
+	assert.Equal(t, "FeatureCollection", fc.Type)
+	require.Len(t, fc.Features, 2)
+	assert.Equal(t, "Point", fc.Features[0].Geometry.Type)
+	assert.Equal(t, []float64{13.40, 52.52}, fc.Features[0].Geometry.Coordinates)
+	assert.Equal(t, map[string]string{"Title": "Bakery"}, fc.Features[1].Properties)
+}
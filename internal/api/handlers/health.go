@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// healthCheckTimeout bounds each dependency check
+const healthCheckTimeout = time.Second
+
+// HealthCheckFunc probes a dependency. details are reported as they are,
+// also when err is not nil.
+type HealthCheckFunc func(ctx context.Context) (details map[string]interface{}, err error)
+
+// HealthCheck is a dependency reported by /health and /ready
+type HealthCheck struct {
+	Name string
+
+	// Critical dependencies make /health answer 503 and the manager not ready
+	Critical bool
+
+	// ReadyOnly checks only run for /ready, e.g. pending migrations
+	ReadyOnly bool
+
+	Check HealthCheckFunc
+}
+
+// ComponentHealth is the state of one dependency
+type ComponentHealth struct {
+	Name        string                 `json:"name"`
+	Status      string                 `json:"status"` // up or down
+	Critical    bool                   `json:"critical"`
+	LatencyMS   float64                `json:"latency_ms"`
+	Error       string                 `json:"error,omitempty"`
+	LastError   string                 `json:"last_error,omitempty"`
+	LastErrorAt *time.Time             `json:"last_error_at,omitempty"`
+	Details     map[string]interface{} `json:"details,omitempty"`
+}
+
+// HealthResponse is the body of /health and /ready
+type HealthResponse struct {
+	Status     string             `json:"status"`
+	Components []*ComponentHealth `json:"components"`
+}
+
+type lastHealthError struct {
+	message string
+	at      time.Time
+}
+
+// HealthHandler checks the manager's dependencies
+type HealthHandler struct {
+	checks []HealthCheck
+
+	mu         sync.Mutex
+	lastErrors map[string]lastHealthError
+}
+
+// NewHealthHandler creates a new HealthHandler
+func NewHealthHandler() *HealthHandler {
+	return &HealthHandler{
+		lastErrors: make(map[string]lastHealthError),
+	}
+}
+
+// AddCheck registers a dependency; call it before serving requests
+func (h *HealthHandler) AddCheck(check HealthCheck) {
+	h.checks = append(h.checks, check)
+}
+
+// Health handles GET /health
+//
+// status is ok when every dependency is up, degraded when only optional
+// ones are down and down (503) when a critical one is.
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	components := h.run(r.Context(), false)
+
+	resp := HealthResponse{Status: "ok", Components: components}
+	code := http.StatusOK
+	for _, c := range components {
+		if c.Status == "up" {
+			continue
+		}
+		if c.Critical {
+			resp.Status = "down"
+			code = http.StatusServiceUnavailable
+			break
+		}
+		resp.Status = "degraded"
+	}
+
+	RenderJSON(w, code, resp)
+}
+
+// Ready handles GET /ready for readiness probes: 200 once every critical
+// dependency is up and the ready-only checks pass, 503 otherwise
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	components := h.run(r.Context(), true)
+
+	resp := HealthResponse{Status: "ready", Components: components}
+	code := http.StatusOK
+	for _, c := range components {
+		if c.Status != "up" {
+			resp.Status = "not_ready"
+			code = http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	RenderJSON(w, code, resp)
+}
+
+// run checks the dependencies concurrently. ready selects the critical and
+// ready-only checks, otherwise all but the ready-only ones run.
+func (h *HealthHandler) run(ctx context.Context, ready bool) []*ComponentHealth {
+	var (
+		wg         sync.WaitGroup
+		components []*ComponentHealth
+	)
+
+	for _, check := range h.checks {
+		if (ready && !check.Critical && !check.ReadyOnly) || (!ready && check.ReadyOnly) {
+			continue
+		}
+
+		c := &ComponentHealth{Name: check.Name, Critical: check.Critical}
+		components = append(components, c)
+
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+			h.probe(ctx, check, c)
+		}(check)
+	}
+
+	wg.Wait()
+
+	return components
+}
+
+func (h *HealthHandler) probe(ctx context.Context, check HealthCheck, c *ComponentHealth) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	type result struct {
+		details map[string]interface{}
+		err     error
+	}
+
+	// Some clients cannot be cancelled; stop waiting for them at the timeout
+	done := make(chan result, 1)
+	start := time.Now()
+	go func() {
+		details, err := check.Check(ctx)
+		done <- result{details, err}
+	}()
+
+	var res result
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		res.err = ctx.Err()
+	}
+
+	c.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	c.Details = res.details
+	c.Status = "up"
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if res.err != nil {
+		if errors.Is(res.err, context.DeadlineExceeded) {
+			res.err = errors.New("timed out after " + healthCheckTimeout.String())
+		}
+		c.Status = "down"
+		c.Error = res.err.Error()
+		h.lastErrors[check.Name] = lastHealthError{message: c.Error, at: time.Now()}
+	}
+
+	if last, ok := h.lastErrors[check.Name]; ok {
+		at := last.at
+		c.LastError = last.message
+		c.LastErrorAt = &at
+	}
+}
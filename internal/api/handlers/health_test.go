@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler(t *testing.T) {
+	up := func(context.Context) (map[string]interface{}, error) { return nil, nil }
+	down := func(context.Context) (map[string]interface{}, error) { return nil, errors.New("connection refused") }
+	hang := func(ctx context.Context) (map[string]interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	tests := []struct {
+		name        string
+		checks      []HealthCheck
+		path        string
+		wantCode    int
+		wantStatus  string
+		wantChecked int
+	}{
+		{
+			name:        "all up",
+			checks:      []HealthCheck{{Name: "database", Critical: true, Check: up}, {Name: "redis", Check: up}},
+			path:        "/health",
+			wantCode:    http.StatusOK,
+			wantStatus:  "ok",
+			wantChecked: 2,
+		},
+		{
+			name:        "optional dependency down",
+			checks:      []HealthCheck{{Name: "database", Critical: true, Check: up}, {Name: "redis", Check: down}},
+			path:        "/health",
+			wantCode:    http.StatusOK,
+			wantStatus:  "degraded",
+			wantChecked: 2,
+		},
+		{
+			name:        "database times out",
+			checks:      []HealthCheck{{Name: "database", Critical: true, Check: hang}, {Name: "redis", Check: up}},
+			path:        "/health",
+			wantCode:    http.StatusServiceUnavailable,
+			wantStatus:  "down",
+			wantChecked: 2,
+		},
+		{
+			name:        "health skips ready-only checks",
+			checks:      []HealthCheck{{Name: "database", Critical: true, Check: up}, {Name: "migrations", ReadyOnly: true, Check: down}},
+			path:        "/health",
+			wantCode:    http.StatusOK,
+			wantStatus:  "ok",
+			wantChecked: 1,
+		},
+		{
+			name:        "not ready with pending migrations",
+			checks:      []HealthCheck{{Name: "database", Critical: true, Check: up}, {Name: "migrations", ReadyOnly: true, Check: down}},
+			path:        "/ready",
+			wantCode:    http.StatusServiceUnavailable,
+			wantStatus:  "not_ready",
+			wantChecked: 2,
+		},
+		{
+			name:        "ready ignores optional dependencies",
+			checks:      []HealthCheck{{Name: "database", Critical: true, Check: up}, {Name: "redis", Check: down}},
+			path:        "/ready",
+			wantCode:    http.StatusOK,
+			wantStatus:  "ready",
+			wantChecked: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthHandler()
+			for _, c := range tt.checks {
+				h.AddCheck(c)
+			}
+
+			handle := h.Health
+			if tt.path == "/ready" {
+				handle = h.Ready
+			}
+
+			w := httptest.NewRecorder()
+			handle(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantCode, w.Code)
+
+			var resp HealthResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+			assert.Equal(t, tt.wantStatus, resp.Status)
+			assert.Len(t, resp.Components, tt.wantChecked)
+
+			for _, c := range resp.Components {
+				if c.Status == "down" {
+					assert.NotEmpty(t, c.Error)
+					assert.Equal(t, c.Error, c.LastError)
+					assert.NotNil(t, c.LastErrorAt)
+				}
+			}
+		})
+	}
+}
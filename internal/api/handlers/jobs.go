@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -18,6 +20,12 @@ import (
 	"github.com/sadewadee/google-scraper/gmaps"
 	"github.com/sadewadee/google-scraper/internal/cache"
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/exportschema"
+	"github.com/sadewadee/google-scraper/internal/jobarchive"
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/internal/proxygate"
+	"github.com/sadewadee/google-scraper/internal/querycsv"
+	"github.com/sadewadee/google-scraper/internal/secrets"
 	"github.com/sadewadee/google-scraper/internal/service"
 )
 
@@ -25,18 +33,35 @@ import (
 type JobServiceInterface interface {
 	Create(ctx context.Context, req *domain.CreateJobRequest) (*domain.Job, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Job, error)
+	AcceptResults(ctx context.Context, id uuid.UUID) error
+	ParseReport(ctx context.Context, id uuid.UUID) (*domain.JobParseReport, error)
 	List(ctx context.Context, params domain.JobListParams) ([]*domain.Job, int, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) (*domain.Job, error)
+	Patch(ctx context.Context, id uuid.UUID, req *domain.PatchJobRequest) (*domain.Job, error)
+	Purge(ctx context.Context, id uuid.UUID) error
 	Pause(ctx context.Context, id uuid.UUID) (*domain.Job, error)
 	Resume(ctx context.Context, id uuid.UUID) (*domain.Job, error)
 	Cancel(ctx context.Context, id uuid.UUID) (*domain.Job, error)
+	RetryFailed(ctx context.Context, id uuid.UUID, maxAttempts int) (*domain.RetryResult, error)
+	Backfill(ctx context.Context, id uuid.UUID) (*domain.Job, error)
+	Shard(ctx context.Context, id uuid.UUID, count int) (*domain.Job, error)
+	UpdateConfig(ctx context.Context, id uuid.UUID, req *domain.CreateJobRequest) (*domain.Job, error)
+	Requeue(ctx context.Context, id uuid.UUID) (*domain.Job, error)
+	QueueStatus(ctx context.Context) (*domain.QueueStatus, error)
+	Events(ctx context.Context, id uuid.UUID, limit, offset int) ([]*domain.JobEvent, int, error)
 	UpdateProgress(ctx context.Context, id uuid.UUID, progress domain.JobProgress) error
+	SyncProgress(ctx context.Context, id uuid.UUID) error
 	GetStats(ctx context.Context) (*domain.JobStats, error)
+	ExpandKeywords(req *domain.ExpandKeywordsRequest) (*domain.KeywordExpansion, error)
+	Preview(ctx context.Context, req *domain.CreateJobRequest) (*domain.JobPreview, error)
+	Archive(ctx context.Context, id uuid.UUID, w io.Writer) error
+	Import(ctx context.Context, r io.Reader, tenant string) (*domain.JobImport, error)
 }
 
 // ResultServiceInterface defines the result service methods
 type ResultServiceInterface interface {
-	CreateBatch(ctx context.Context, jobID uuid.UUID, data [][]byte) error
+	CreateBatch(ctx context.Context, jobID, batchID uuid.UUID, data [][]byte) error
 	ListByJobID(ctx context.Context, jobID uuid.UUID, limit, offset int) ([][]byte, int, error)
 	StreamByJobID(ctx context.Context, jobID uuid.UUID, fn func(data []byte) error) error
 	CountByJobID(ctx context.Context, jobID uuid.UUID) (int, error)
@@ -44,9 +69,11 @@ type ResultServiceInterface interface {
 
 // JobHandler handles job-related HTTP requests
 type JobHandler struct {
-	jobs    JobServiceInterface
-	results ResultServiceInterface
-	cache   cache.Cache
+	jobs      JobServiceInterface
+	results   ResultServiceInterface
+	cache     cache.Cache
+	templates JobTemplateServiceInterface
+	settings  SettingsServiceInterface // House job defaults (optional)
 }
 
 // MaxResultBatchSize is the maximum size of a result batch (10MB)
@@ -55,6 +82,10 @@ const MaxResultBatchSize = 10 << 20
 // downloadTimeout is the timeout for large download operations (5 minutes)
 const downloadTimeout = 5 * time.Minute
 
+// archiveTimeout bounds streaming a job archive in or out, past the
+// server's read and write timeouts
+const archiveTimeout = 30 * time.Minute
+
 // SubmitResults handles POST /api/v2/jobs/{id}/results
 func (h *JobHandler) SubmitResults(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -62,60 +93,89 @@ func (h *JobHandler) SubmitResults(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	logger := logging.Logger(r.Context(), "SubmitResults")
+
 	id, err := parseJobID(r)
 	if err != nil {
-		log.Printf("[SubmitResults] Invalid job ID: %v", err)
+		logger.Warn("invalid job ID", "error", err)
 		RenderError(w, http.StatusBadRequest, "Invalid job ID")
 		return
 	}
 
-	log.Printf("[SubmitResults] Receiving results for job %s", id)
+	logger = logger.With("job_id", id)
+	logger.Debug("receiving results")
 
 	// Limit request body size to prevent memory exhaustion
 	r.Body = http.MaxBytesReader(w, r.Body, MaxResultBatchSize)
 
 	var batch domain.ResultBatch
 	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
-		log.Printf("[SubmitResults] Failed to decode request body: %v", err)
+		logger.Warn("failed to decode request body", "error", err)
 		RenderError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	log.Printf("[SubmitResults] Job %s: Received batch with %d results", id, len(batch.Data))
+	logger.Debug("received batch", "results", len(batch.Data))
 
 	if batch.JobID != uuid.Nil && batch.JobID != id {
-		log.Printf("[SubmitResults] Job ID mismatch: URL=%s, Body=%s", id, batch.JobID)
+		logger.Warn("job ID mismatch", "body_job_id", batch.JobID)
 		RenderError(w, http.StatusBadRequest, "Job ID mismatch")
 		return
 	}
 
 	if len(batch.Data) == 0 {
-		log.Printf("[SubmitResults] Job %s: Empty batch, returning 204", id)
+		logger.Debug("empty batch")
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	if err := h.results.CreateBatch(r.Context(), id, batch.Data); err != nil {
-		log.Printf("[SubmitResults] Job %s: CreateBatch FAILED: %v", id, err)
-		RenderError(w, http.StatusInternalServerError, "Failed to save results")
+	if err := h.jobs.AcceptResults(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, service.ErrJobNotFound):
+			RenderError(w, http.StatusNotFound, "Job not found")
+		case errors.Is(err, service.ErrJobFinished):
+			logger.Warn("rejected results of a finished job", "batch_id", batch.BatchID, "results", len(batch.Data))
+			RenderError(w, http.StatusConflict, "Job already finished")
+		default:
+			logger.Error("failed to get job", "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to save results")
+		}
 		return
 	}
 
-	log.Printf("[SubmitResults] Job %s: Successfully saved %d results to database", id, len(batch.Data))
+	// A batch crossing the tenant's quota is stored up to the cap; the
+	// progress below still has to reflect that part
+	err = h.results.CreateBatch(r.Context(), id, batch.BatchID, batch.Data)
+	if errors.Is(err, domain.ErrBatchAlreadyStored) {
+		// A retry of a batch whose response got lost
+		logger.Info("result batch already stored", "batch_id", batch.BatchID)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	quotaExceeded := errors.Is(err, domain.ErrQuotaExceeded)
+	if err != nil && !quotaExceeded {
+		logger.Error("failed to save results", "error", err)
+		RenderError(w, http.StatusInternalServerError, "Failed to save results")
+		return
+	}
 
-	// Update scraped_places counter from actual database count
-	totalResults, countErr := h.results.CountByJobID(r.Context(), id)
-	if countErr != nil {
-		log.Printf("[SubmitResults] Job %s: WARNING - failed to count results: %v", id, countErr)
+	if quotaExceeded {
+		logger.Warn("results stored up to the quota", "error", err)
 	} else {
-		progress := domain.JobProgress{
-			ScrapedPlaces: totalResults,
-		}
-		if progressErr := h.jobs.UpdateProgress(r.Context(), id, progress); progressErr != nil {
-			log.Printf("[SubmitResults] Job %s: WARNING - failed to update progress: %v", id, progressErr)
-		} else {
-			log.Printf("[SubmitResults] Job %s: Updated scraped_places to %d", id, totalResults)
-		}
+		logger.Info("results saved", "results", len(batch.Data))
+	}
+
+	// Update the scraped and partial places from the stored results
+	if progressErr := h.jobs.SyncProgress(r.Context(), id); progressErr != nil {
+		logger.Warn("failed to update progress", "error", progressErr)
+	}
+
+	// Keep results_available, last_result_at and the result pages fresh
+	h.invalidateJobCache(r.Context(), &id)
+
+	if quotaExceeded {
+		renderQuotaExceeded(w, err)
+		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
@@ -138,6 +198,17 @@ func NewJobHandlerWithCache(jobs JobServiceInterface, results ResultServiceInter
 	}
 }
 
+// SetTemplates enables template_id support on job creation
+func (h *JobHandler) SetTemplates(templates JobTemplateServiceInterface) {
+	h.templates = templates
+}
+
+// SetSettings fills the fields a create request and its template leave
+// unset from the job defaults saved in the settings
+func (h *JobHandler) SetSettings(settings SettingsServiceInterface) {
+	h.settings = settings
+}
+
 // invalidateJobCache invalidates all job-related cache entries
 func (h *JobHandler) invalidateJobCache(ctx context.Context, jobID *uuid.UUID) {
 	if h.cache == nil {
@@ -146,49 +217,189 @@ func (h *JobHandler) invalidateJobCache(ctx context.Context, jobID *uuid.UUID) {
 
 	// Invalidate job list cache
 	if err := h.cache.DeleteByPattern(ctx, cache.KeyPrefixDashboardJobs+":*"); err != nil {
-		log.Printf("[JobHandler] Warning: failed to invalidate job list cache: %v", err)
+		logging.Logger(ctx, "JobHandler").Warn("failed to invalidate job list cache", "error", err)
 	}
 
 	// Invalidate stats cache
 	if err := h.cache.Delete(ctx, cache.KeyPrefixDashboardStats); err != nil {
-		log.Printf("[JobHandler] Warning: failed to invalidate stats cache: %v", err)
+		logging.Logger(ctx, "JobHandler").Warn("failed to invalidate stats cache", "error", err)
 	}
 
-	// Invalidate specific job detail cache if jobID provided
+	// Invalidate specific job detail and result pages if jobID provided
 	if jobID != nil {
-		detailKey := fmt.Sprintf("%s:detail:%s", cache.KeyPrefixDashboardJobs, jobID.String())
+		detailKey := fmt.Sprintf("%s:%s", cache.KeyPrefixDashboardJobs, jobID.String())
 		if err := h.cache.Delete(ctx, detailKey); err != nil {
-			log.Printf("[JobHandler] Warning: failed to invalidate job detail cache: %v", err)
+			logging.Logger(ctx, "JobHandler").Warn("failed to invalidate job detail cache", "job_id", jobID, "error", err)
+		}
+		resultsPattern := fmt.Sprintf("%s:%s:*", cache.KeyPrefixDashboardResults, jobID.String())
+		if err := h.cache.DeleteByPattern(ctx, resultsPattern); err != nil {
+			logging.Logger(ctx, "JobHandler").Warn("failed to invalidate job results cache", "job_id", jobID, "error", err)
 		}
 	}
 }
 
 // CreateJobRequest represents the request body for creating a job
 type CreateJobRequest struct {
-	Name         string   `json:"name"`
-	Keywords     []string `json:"keywords"`
+	Name     string   `json:"name"`
+	Keywords []string `json:"keywords"`
+
+	// InputType place_urls scrapes PlaceURLs, Google Maps place URLs or
+	// place IDs, instead of searching for keywords
+	InputType string   `json:"input_type,omitempty"`
+	PlaceURLs []string `json:"place_urls,omitempty"`
+
 	Lang         string   `json:"lang"`
+	LangFallback []string `json:"lang_fallback,omitempty"`
 	Lat          *float64 `json:"lat,omitempty"`
 	Lon          *float64 `json:"lon,omitempty"`
 	Zoom         int      `json:"zoom"`
 	Radius       int      `json:"radius"`
 	Depth        int      `json:"depth"`
-	FastMode     bool     `json:"fast_mode"`
-	ExtractEmail bool     `json:"extract_email"`
+	FastMode     *bool    `json:"fast_mode"`
+	ExtractEmail *bool    `json:"extract_email"`
 	MaxTime      int      `json:"max_time"` // seconds
+	MaxResults   int      `json:"max_results,omitempty"`
 	Proxies      []string `json:"proxies,omitempty"`
+	ProxyCountry string   `json:"proxy_country,omitempty"`
+	GoogleDomain string   `json:"google_domain,omitempty"` // e.g. google.de, or auto
+	MaxReviews   int      `json:"max_reviews,omitempty"`
+	ReviewsSort  string   `json:"reviews_sort,omitempty"`
+	MaxImages    int      `json:"max_images,omitempty"`
 	Priority     int      `json:"priority"`
 
 	// Geo coverage settings for area-wide scraping
 	LocationName string              `json:"location_name,omitempty"`
 	BoundingBox  *domain.BoundingBox `json:"boundingbox,omitempty"`
 	CoverageMode domain.CoverageMode `json:"coverage_mode,omitempty"`
+
+	// Full coverage grid cap and the optional empty-cell pre-check
+	MaxGridPoints int  `json:"max_grid_points,omitempty"`
+	DensityCheck  bool `json:"density_check,omitempty"`
+
+	// Headers sent to Google Maps: a named preset, optionally overridden
+	BrowserProfile string `json:"browser_profile,omitempty"`
+	UserAgent      string `json:"user_agent,omitempty"`
+	AcceptLanguage string `json:"accept_language,omitempty"`
+
+	// Incremental flags places already known from earlier jobs
+	Incremental *bool `json:"incremental,omitempty"`
+
+	// GlobalDedupe skips places other jobs scraped recently
+	GlobalDedupe *bool `json:"global_dedupe,omitempty"`
+
+	// Outputs the worker writes the results to besides the manager
+	Outputs []domain.JobOutput `json:"outputs,omitempty"`
+
+	// NotifyEmails receive the summary report once the job completes
+	NotifyEmails []string `json:"notify_emails,omitempty"`
+
+	// RetryOnTimeout puts the job back to pending once when the manager
+	// times it out, instead of failing it
+	RetryOnTimeout *bool `json:"retry_on_timeout,omitempty"`
+
+	// CheckWebsite flags the dead and parked websites of the listings
+	CheckWebsite *bool `json:"check_website,omitempty"`
+
+	// Keyword × location expansion, performed when the job is created
+	BaseKeywords []string                 `json:"base_keywords,omitempty"`
+	Locations    []domain.KeywordLocation `json:"locations,omitempty"`
+
+	// TemplateID fills fields not set in the request from a job template
+	TemplateID *uuid.UUID `json:"template_id,omitempty"`
+
+	// Tags and notes to organize jobs; they do not affect the run
+	Tags  []string `json:"tags,omitempty"`
+	Notes string   `json:"notes,omitempty"`
+
+	// DependsOn keeps the job waiting until that job finished; RunIf is
+	// success (the default) to run it only if that job completed, or always
+	DependsOn *uuid.UUID `json:"depends_on,omitempty"`
+	RunIf     string     `json:"run_if,omitempty"`
+
+	// Shards splits the keywords over this many jobs that workers claim
+	// separately, with the job created as their aggregate
+	Shards int `json:"shards,omitempty"`
+}
+
+// applyTemplate fills fields left unset in the request from the template.
+// Explicit request fields always win.
+func (req *CreateJobRequest) applyTemplate(cfg domain.JobTemplateConfig) {
+	if len(req.Keywords) == 0 && len(req.BaseKeywords) == 0 && req.InputType != domain.InputTypePlaceURLs {
+		req.Keywords = cfg.Keywords
+	}
+	if req.Lang == "" && cfg.Lang != nil {
+		req.Lang = *cfg.Lang
+	}
+	if len(req.LangFallback) == 0 {
+		req.LangFallback = cfg.LangFallback
+	}
+	if req.Zoom == 0 && cfg.Zoom != nil {
+		req.Zoom = *cfg.Zoom
+	}
+	if req.Radius == 0 && cfg.Radius != nil {
+		req.Radius = *cfg.Radius
+	}
+	if req.Depth == 0 && cfg.Depth != nil {
+		req.Depth = *cfg.Depth
+	}
+	if req.FastMode == nil {
+		req.FastMode = cfg.FastMode
+	}
+	if req.ExtractEmail == nil {
+		req.ExtractEmail = cfg.ExtractEmail
+	}
+	if req.MaxTime == 0 && cfg.MaxTime != nil {
+		req.MaxTime = *cfg.MaxTime
+	}
+	if req.MaxResults == 0 && cfg.MaxResults != nil {
+		req.MaxResults = *cfg.MaxResults
+	}
+	if len(req.Proxies) == 0 {
+		req.Proxies = cfg.Proxies
+	}
+	if req.ProxyCountry == "" {
+		req.ProxyCountry = cfg.ProxyCountry
+	}
+	if req.GoogleDomain == "" {
+		req.GoogleDomain = cfg.GoogleDomain
+	}
+	if req.MaxReviews == 0 && cfg.MaxReviews != nil {
+		req.MaxReviews = *cfg.MaxReviews
+	}
+	if req.ReviewsSort == "" {
+		req.ReviewsSort = cfg.ReviewsSort
+	}
+	if req.MaxImages == 0 && cfg.MaxImages != nil {
+		req.MaxImages = *cfg.MaxImages
+	}
+	if req.Priority == 0 && cfg.Priority != nil {
+		req.Priority = *cfg.Priority
+	}
+	if req.CoverageMode == "" {
+		req.CoverageMode = cfg.CoverageMode
+	}
+	if req.BrowserProfile == "" {
+		req.BrowserProfile = cfg.BrowserProfile
+	}
+	if req.UserAgent == "" {
+		req.UserAgent = cfg.UserAgent
+	}
+	if req.AcceptLanguage == "" {
+		req.AcceptLanguage = cfg.AcceptLanguage
+	}
+	if req.Incremental == nil {
+		req.Incremental = cfg.Incremental
+	}
+	if req.GlobalDedupe == nil {
+		req.GlobalDedupe = cfg.GlobalDedupe
+	}
 }
 
 // Create handles POST /api/v2/jobs
 func (h *JobHandler) Create(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
-	log.Printf("[JobHandler] Create request received")
+	logger := logging.Logger(r.Context(), "JobHandler")
+	logger.Debug("create request received")
 
 	if r.Method != http.MethodPost {
 		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -201,18 +412,232 @@ func (h *JobHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("[JobHandler] Request decoded in %v: name=%s, keywords=%d", time.Since(start), req.Name, len(req.Keywords))
+	logger.Debug("create request decoded", "name", req.Name, "keywords", len(req.Keywords), "duration_ms", logging.SinceMS(start))
 
-	// Validate required fields
-	if req.Name == "" {
-		RenderError(w, http.StatusBadRequest, "Name is required")
+	h.create(w, r, &req, nil)
+}
+
+// create validates req and creates the job through JobService.Create.
+// clonedFrom is recorded on the job when it copies another one.
+func (h *JobHandler) create(w http.ResponseWriter, r *http.Request, req *CreateJobRequest, clonedFrom *uuid.UUID) {
+	start := time.Now()
+	logger := logging.Logger(r.Context(), "JobHandler")
+
+	domainReq, normalized, apiErr := h.domainRequest(r, req)
+	if apiErr != nil {
+		RenderJSON(w, apiErr.Code, apiErr)
 		return
 	}
-	if len(req.Keywords) == 0 {
-		RenderError(w, http.StatusBadRequest, "At least one keyword is required")
+	domainReq.ClonedFrom = clonedFrom
+
+	serviceStart := time.Now()
+	job, err := h.jobs.Create(r.Context(), domainReq)
+	if err != nil {
+		logger.Error("create failed", "duration_ms", logging.SinceMS(start), "service_ms", logging.SinceMS(serviceStart), "error", err)
+		if renderQuotaExceeded(w, err) || RenderValidationError(w, err) {
+			return
+		}
+		if isInvalidJobError(err) {
+			RenderError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		RenderError(w, http.StatusInternalServerError, "Failed to create job")
+		return
+	}
+
+	if req.TemplateID != nil {
+		if err := h.templates.RecordUsage(r.Context(), *req.TemplateID); err != nil {
+			logger.Warn("failed to record template usage", "template_id", req.TemplateID, "error", err)
+		}
+	}
+
+	// Invalidate cache after successful create
+	h.invalidateJobCache(r.Context(), &job.ID)
+
+	normalized.apply(job)
+
+	logger.Info("job created", "job_id", job.ID, "duration_ms", logging.SinceMS(start), "service_ms", logging.SinceMS(serviceStart),
+		"keywords_dropped", normalized.keywords.Dropped)
+	RenderJSON(w, http.StatusCreated, maskJob(job))
+}
+
+// domainRequest merges the template of req, validates it and converts it
+// to the domain request of the caller's tenant
+func (h *JobHandler) domainRequest(r *http.Request, req *CreateJobRequest) (*domain.CreateJobRequest, inputNormalization, *APIError) {
+	// Merge template defaults before validation so the merged config is checked
+	if req.TemplateID != nil {
+		tmpl, apiErr := h.template(r.Context(), *req.TemplateID)
+		if apiErr != nil {
+			return nil, inputNormalization{}, apiErr
+		}
+		req.applyTemplate(tmpl.Config)
+	}
+
+	// House defaults come after the template and before the built-in ones
+	defaults, apiErr := h.jobDefaults(r.Context())
+	if apiErr != nil {
+		return nil, inputNormalization{}, apiErr
+	}
+	if defaults != nil {
+		req.applyTemplate(*defaults)
+	}
+
+	domainReq, normalized, err := validateCreateRequest(req)
+	if err != nil {
+		return nil, normalized, validationAPIError(err)
+	}
+	domainReq.Tenant = requestTenant(r)
+
+	return domainReq, normalized, nil
+}
+
+// Preview handles POST /api/v2/jobs/preview: the body of a create request
+// goes through the same checks, and the seed jobs, grid points, places and
+// runtime the job would have are returned without storing or enqueuing it
+func (h *JobHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req CreateJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	domainReq, normalized, apiErr := h.domainRequest(r, &req)
+	if apiErr != nil {
+		RenderJSON(w, apiErr.Code, apiErr)
+		return
+	}
+
+	preview, err := h.jobs.Preview(r.Context(), domainReq)
+	if err != nil {
+		if RenderValidationError(w, err) {
+			return
+		}
+		if isInvalidJobError(err) {
+			RenderError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		RenderError(w, http.StatusInternalServerError, "Failed to preview job: "+err.Error())
 		return
 	}
 
+	preview.NormalizedKeywords = &normalized.keywords
+	preview.NormalizedPlaceURLs = normalized.placeURLs
+	if n := normalized.keywords; n.Dropped > 0 {
+		preview.Warn("%d of %d submitted keywords were dropped as blank or repeated", n.Dropped, n.Submitted)
+	}
+	if n := normalized.placeURLs; n != nil && len(n.Invalid) > 0 {
+		preview.Warn("%d of %d submitted place URLs are invalid and will be skipped", len(n.Invalid), n.Submitted)
+	}
+
+	RenderJSON(w, http.StatusOK, preview)
+}
+
+// jobDefaults loads the job defaults of the settings, nil without settings
+func (h *JobHandler) jobDefaults(ctx context.Context) (*domain.JobTemplateConfig, *APIError) {
+	if h.settings == nil {
+		return nil, nil
+	}
+
+	defaults, err := h.settings.JobDefaults(ctx)
+	if err != nil {
+		return nil, &APIError{Code: http.StatusInternalServerError, Message: "Failed to load job defaults: " + err.Error()}
+	}
+
+	return &defaults.Config, nil
+}
+
+// validateJobDefaults checks job defaults with the rules of a create
+// request, so invalid defaults are refused when saved rather than failing
+// every job later
+func validateJobDefaults(cfg domain.JobTemplateConfig) error {
+	req := &CreateJobRequest{Name: "job defaults"}
+	req.applyTemplate(cfg)
+	if len(req.Keywords) == 0 {
+		req.Keywords = []string{"job defaults"}
+	}
+
+	_, _, err := validateCreateRequest(req)
+	return err
+}
+
+// template loads a job template to create a job from
+func (h *JobHandler) template(ctx context.Context, id uuid.UUID) (*domain.JobTemplate, *APIError) {
+	if h.templates == nil {
+		return nil, &APIError{Code: http.StatusBadRequest, Message: "Job templates are not available"}
+	}
+
+	tmpl, err := h.templates.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, service.ErrTemplateNotFound) {
+			return nil, &APIError{Code: http.StatusBadRequest, Message: "Template not found"}
+		}
+		return nil, &APIError{Code: http.StatusInternalServerError, Message: "Failed to load template: " + err.Error()}
+	}
+
+	return tmpl, nil
+}
+
+// requestFields are the JSON names of the API request for the fields of
+// domain.CreateJobRequest named differently
+var requestFields = map[string]string{
+	"geo_lat": "lat",
+	"geo_lon": "lon",
+}
+
+// inputNormalization sums up the cleanup of the keywords and place URLs
+// of a create request
+type inputNormalization struct {
+	keywords  domain.KeywordNormalization
+	placeURLs *domain.PlaceURLNormalization // Only for input_type place_urls
+}
+
+// apply sets the summaries on the job created from the request
+func (n inputNormalization) apply(job *domain.Job) {
+	job.NormalizedKeywords = &n.keywords
+	job.NormalizedPlaceURLs = n.placeURLs
+}
+
+// validateCreateRequest checks req, fills in the defaults and converts it
+// to the domain request. Every error is the fault of the request; the
+// fields that fail are listed in a *domain.ValidationError.
+func validateCreateRequest(req *CreateJobRequest) (*domain.CreateJobRequest, inputNormalization, error) {
+	verr := &domain.ValidationError{}
+	var normalized inputNormalization
+
+	// Pasted lists carry blank lines and repeats, each of which would cost
+	// a full search
+	keywords, keywordsNormalized := domain.NormalizeKeywords(req.Keywords)
+	baseKeywords, baseNormalized := domain.NormalizeKeywords(req.BaseKeywords)
+	keywordsNormalized.Add(baseNormalized)
+	req.Keywords, req.BaseKeywords = keywords, baseKeywords
+	normalized.keywords = keywordsNormalized
+
+	switch {
+	case req.InputType == domain.InputTypePlaceURLs:
+		// Invalid entries are left out and listed in the response; the job
+		// needs one that is not
+		placeURLs, placeNormalized := domain.NormalizePlaceURLs(req.PlaceURLs)
+		req.PlaceURLs = placeURLs
+		normalized.placeURLs = &placeNormalized
+		if len(req.PlaceURLs) == 0 {
+			for _, invalid := range placeNormalized.Invalid {
+				verr.Add(fmt.Sprintf("place_urls[%d]", invalid.Index), "place_url", "Invalid place URL %q: %s", invalid.Input, invalid.Reason)
+			}
+			verr.Add("place_urls", "required", "At least one valid place URL or place ID is required")
+		}
+	case len(req.Keywords) == 0 && len(req.BaseKeywords) == 0:
+		if keywordsNormalized.Submitted > 0 {
+			verr.Add("keywords", "required", "At least one keyword is required; the %d submitted are blank", keywordsNormalized.Submitted)
+		} else {
+			verr.Add("keywords", "required", "At least one keyword is required")
+		}
+	}
+
 	// Set defaults
 	if req.Lang == "" {
 		req.Lang = "en"
@@ -230,15 +655,35 @@ func (h *JobHandler) Create(w http.ResponseWriter, r *http.Request) {
 		req.MaxTime = 600 // 10 minutes default
 	}
 
-	// Validate bounding box if full coverage mode is requested
-	if req.CoverageMode == domain.CoverageModeFull {
-		if req.BoundingBox == nil {
-			RenderError(w, http.StatusBadRequest, "Bounding box is required for full coverage mode")
-			return
+	if langFallback, err := domain.NormalizeLangFallback(req.Lang, req.LangFallback); err != nil {
+		verr.Add("lang_fallback", "lang", "%v", err)
+	} else {
+		req.LangFallback = langFallback
+	}
+
+	if notifyEmails, err := domain.NormalizeNotifyEmails(req.NotifyEmails); err != nil {
+		verr.Add("notify_emails", "email", "%v", err)
+	} else {
+		req.NotifyEmails = notifyEmails
+	}
+
+	if req.ProxyCountry != "" {
+		if country := proxygate.NormalizeCountry(req.ProxyCountry); country != "" {
+			req.ProxyCountry = country
+		} else {
+			verr.Add("proxy_country", "country", "proxy_country must be a two-letter ISO country code")
 		}
-		if !req.BoundingBox.IsValid() {
-			RenderError(w, http.StatusBadRequest, "Invalid bounding box coordinates")
-			return
+	}
+
+	if _, err := domain.ResolveBrowserProfile(req.BrowserProfile, req.UserAgent, req.AcceptLanguage); err != nil {
+		verr.Add("browser_profile", "browser_profile", "%v", err)
+	}
+	if err := domain.ValidateOutputs(req.Outputs); err != nil {
+		verr.Add("outputs", "output", "%v", err)
+	}
+	for i, p := range req.Proxies {
+		if _, err := proxygate.ParseProxyURL(p, proxygate.ProtocolSOCKS5); err != nil {
+			verr.Add(fmt.Sprintf("proxies[%d]", i), "proxy", "Invalid proxy %q: %v", p, err)
 		}
 	}
 
@@ -246,109 +691,1077 @@ func (h *JobHandler) Create(w http.ResponseWriter, r *http.Request) {
 	domainReq := &domain.CreateJobRequest{
 		Name:         req.Name,
 		Keywords:     req.Keywords,
+		InputType:    req.InputType,
+		PlaceURLs:    req.PlaceURLs,
 		Lang:         req.Lang,
+		LangFallback: req.LangFallback,
 		GeoLat:       req.Lat,
 		GeoLon:       req.Lon,
 		Zoom:         req.Zoom,
 		Radius:       req.Radius,
 		Depth:        req.Depth,
-		FastMode:     req.FastMode,
-		ExtractEmail: req.ExtractEmail,
+		FastMode:     req.FastMode != nil && *req.FastMode,
+		ExtractEmail: req.ExtractEmail != nil && *req.ExtractEmail,
 		MaxTime:      req.MaxTime,
+		MaxResults:   req.MaxResults,
 		Proxies:      req.Proxies,
+		ProxyCountry: req.ProxyCountry,
+		GoogleDomain: req.GoogleDomain,
+		MaxReviews:   req.MaxReviews,
+		ReviewsSort:  req.ReviewsSort,
+		MaxImages:    req.MaxImages,
 		Priority:     req.Priority,
 		// Geo coverage settings for area-wide scraping
-		LocationName: req.LocationName,
-		BoundingBox:  req.BoundingBox,
-		CoverageMode: req.CoverageMode,
+		LocationName:  req.LocationName,
+		BoundingBox:   req.BoundingBox,
+		CoverageMode:  req.CoverageMode,
+		MaxGridPoints: req.MaxGridPoints,
+		DensityCheck:  req.DensityCheck,
+		// Headers sent to Google Maps
+		BrowserProfile: req.BrowserProfile,
+		UserAgent:      req.UserAgent,
+		AcceptLanguage: req.AcceptLanguage,
+		Incremental:    req.Incremental != nil && *req.Incremental,
+		GlobalDedupe:   req.GlobalDedupe != nil && *req.GlobalDedupe,
+		Outputs:        req.Outputs,
+		NotifyEmails:   req.NotifyEmails,
+		RetryOnTimeout: req.RetryOnTimeout != nil && *req.RetryOnTimeout,
+		CheckWebsite:   req.CheckWebsite != nil && *req.CheckWebsite,
+		BaseKeywords:   req.BaseKeywords,
+		Locations:      req.Locations,
+		TemplateID:     req.TemplateID,
+		Tags:           req.Tags,
+		Notes:          req.Notes,
+		DependsOn:      req.DependsOn,
+		RunIf:          req.RunIf,
+		Shards:         req.Shards,
+	}
+
+	// Ranges and the rules spanning fields, such as the bounding box of
+	// full coverage mode
+	checked := len(verr.Fields)
+	domainReq.Check(verr)
+	for i := checked; i < len(verr.Fields); i++ {
+		f := &verr.Fields[i]
+		for domainName, name := range requestFields {
+			f.Field = strings.ReplaceAll(f.Field, domainName, name)
+			f.Message = strings.ReplaceAll(f.Message, domainName, name)
+		}
 	}
 
-	log.Printf("[JobHandler] Calling service.Create")
-	serviceStart := time.Now()
-	job, err := h.jobs.Create(r.Context(), domainReq)
+	return domainReq, normalized, verr.Err()
+}
+
+// isInvalidJobError tells whether JobService.Create rejected the job itself
+// rather than failed to store it
+func isInvalidJobError(err error) bool {
+	_, invalid := domain.AsValidationError(err)
+	return invalid || errors.Is(err, service.ErrNoProxiesForCountry) || isKeywordExpansionError(err) || isGridError(err) || domain.IsBrowserProfileError(err) ||
+		errors.Is(err, domain.ErrInvalidOutput) || errors.Is(err, domain.ErrInvalidLangFallback) || errors.Is(err, domain.ErrNoKeywords) || errors.Is(err, domain.ErrNoPlaceURLs) ||
+		errors.Is(err, domain.ErrInvalidNotifyEmail) || errors.Is(err, domain.ErrInvalidRunIf) ||
+		errors.Is(err, service.ErrDependencyNotFound) || errors.Is(err, service.ErrDependencyCycle) ||
+		errors.Is(err, service.ErrDependencyTooDeep) || errors.Is(err, domain.ErrInvalidShardCount) ||
+		errors.Is(err, domain.ErrJobNotShardable)
+}
+
+// applySourceJob fills fields left unset in the request from the config of
+// the job being cloned. Explicit request fields always win.
+func (req *CreateJobRequest) applySourceJob(job *domain.Job) {
+	cfg := job.Config
+
+	if req.Name == "" {
+		req.Name = job.Name + " (copy)"
+	}
+	// A job scraping a list of places stays one unless the request brings
+	// keywords
+	if req.InputType == "" && len(cfg.PlaceURLs) > 0 && len(req.Keywords) == 0 && len(req.BaseKeywords) == 0 {
+		req.InputType = domain.InputTypePlaceURLs
+	}
+	switch {
+	case req.InputType == domain.InputTypePlaceURLs:
+		if len(req.PlaceURLs) == 0 {
+			req.PlaceURLs = cfg.PlaceURLs
+		}
+	case len(req.Keywords) == 0 && len(req.BaseKeywords) == 0:
+		req.Keywords = cfg.Keywords
+	}
+	if req.Lang == "" {
+		req.Lang = cfg.Lang
+	}
+	if len(req.LangFallback) == 0 {
+		req.LangFallback = cfg.LangFallback
+	}
+	if req.Lat == nil && req.Lon == nil {
+		req.Lat, req.Lon = cfg.GeoLat, cfg.GeoLon
+	}
+	if req.Zoom == 0 {
+		req.Zoom = cfg.Zoom
+	}
+	if req.Radius == 0 {
+		req.Radius = cfg.Radius
+	}
+	if req.Depth == 0 {
+		req.Depth = cfg.Depth
+	}
+	if req.FastMode == nil {
+		req.FastMode = &cfg.FastMode
+	}
+	if req.ExtractEmail == nil {
+		req.ExtractEmail = &cfg.ExtractEmail
+	}
+	if req.MaxTime == 0 {
+		req.MaxTime = int(cfg.MaxTime / time.Second)
+	}
+	if req.MaxResults == 0 {
+		req.MaxResults = cfg.MaxResults
+	}
+	// Proxies attached for a country were picked from the pool at the
+	// time; the clone picks healthy ones again
+	if len(req.Proxies) == 0 && req.ProxyCountry == "" {
+		if cfg.ProxyCountry != "" {
+			req.ProxyCountry = cfg.ProxyCountry
+		} else {
+			req.Proxies = cfg.Proxies
+		}
+	}
+	if req.GoogleDomain == "" {
+		req.GoogleDomain = cfg.GoogleDomain
+	}
+	if req.MaxReviews == 0 {
+		req.MaxReviews = cfg.MaxReviews
+	}
+	if req.ReviewsSort == "" {
+		req.ReviewsSort = cfg.ReviewsSort
+	}
+	if req.MaxImages == 0 {
+		req.MaxImages = cfg.MaxImages
+	}
+	if req.Priority == 0 {
+		req.Priority = job.Priority
+	}
+	if req.LocationName == "" {
+		req.LocationName = cfg.LocationName
+	}
+	if req.BoundingBox == nil {
+		req.BoundingBox = cfg.BoundingBox
+	}
+	if req.CoverageMode == "" {
+		req.CoverageMode = cfg.CoverageMode
+	}
+	// The source grid fit under its cap, so the same grid must fit again
+	if req.MaxGridPoints == 0 && cfg.GridPoints > domain.DefaultMaxGridPoints {
+		req.MaxGridPoints = cfg.GridPoints
+	}
+	if !req.DensityCheck {
+		req.DensityCheck = cfg.DensityCheck
+	}
+	if req.BrowserProfile == "" && req.UserAgent == "" && req.AcceptLanguage == "" {
+		req.BrowserProfile = cfg.BrowserProfile
+		req.UserAgent = cfg.UserAgent
+		req.AcceptLanguage = cfg.AcceptLanguage
+	}
+	if req.Incremental == nil {
+		req.Incremental = &cfg.Incremental
+	}
+	if req.GlobalDedupe == nil {
+		req.GlobalDedupe = &cfg.GlobalDedupe
+	}
+	if len(req.Outputs) == 0 {
+		req.Outputs = cfg.Outputs
+	}
+	if len(req.NotifyEmails) == 0 {
+		req.NotifyEmails = cfg.NotifyEmails
+	}
+	if req.RetryOnTimeout == nil {
+		req.RetryOnTimeout = &cfg.RetryOnTimeout
+	}
+	if req.CheckWebsite == nil {
+		req.CheckWebsite = &cfg.CheckWebsite
+	}
+	// A copy usually belongs to the same client and campaign; notes are
+	// about the source job and stay with it
+	if req.Tags == nil {
+		req.Tags = job.Tags
+	}
+}
+
+// Clone handles POST /api/v2/jobs/{id}/clone
+//
+// The body has the shape of a create request with every field optional;
+// fields it leaves out are copied from the source job. The new job goes
+// through the same validation and creation path as POST /api/v2/jobs.
+func (h *JobHandler) Clone(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := parseJobID(r)
 	if err != nil {
-		log.Printf("[JobHandler] Create FAILED after %v (service: %v): %v", time.Since(start), time.Since(serviceStart), err)
-		RenderError(w, http.StatusInternalServerError, "Failed to create job")
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	var req CreateJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	source, err := h.jobs.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			RenderError(w, http.StatusNotFound, "Job not found")
+		} else {
+			RenderError(w, http.StatusInternalServerError, "Failed to retrieve job: "+err.Error())
+		}
+		return
+	}
+
+	req.applySourceJob(source)
+
+	h.create(w, r, &req, &source.ID)
+}
+
+// GetConfig handles GET /api/v2/jobs/{id}/config: the config of the job,
+// proxies masked
+func (h *JobHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	job, err := h.jobs.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			RenderError(w, http.StatusNotFound, "Job not found")
+		} else {
+			RenderError(w, http.StatusInternalServerError, "Failed to retrieve job: "+err.Error())
+		}
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, maskJob(job).Config)
+}
+
+// PatchConfig handles PATCH /api/v2/jobs/{id}/config
+//
+// The body has the shape of a create request with every field optional;
+// fields it leaves out keep the value of the job. The merged config goes
+// through the checks of a create request and replaces that of the pending
+// or paused job, which keeps its place in the queue. Fields of the job
+// rather than of its config are refused.
+func (h *JobHandler) PatchConfig(w http.ResponseWriter, r *http.Request) {
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	var req CreateJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	verr := &domain.ValidationError{}
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"name", req.Name != ""},
+		{"priority", req.Priority != 0},
+		{"tags", req.Tags != nil},
+		{"notes", req.Notes != ""},
+		{"depends_on", req.DependsOn != nil},
+		{"run_if", req.RunIf != ""},
+		{"shards", req.Shards != 0},
+		{"template_id", req.TemplateID != nil},
+	} {
+		if f.set {
+			verr.Add(f.name, "config", "%s is not part of the job config", f.name)
+		}
+	}
+	if RenderValidationError(w, verr.Err()) {
+		return
+	}
+
+	job, err := h.jobs.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			RenderError(w, http.StatusNotFound, "Job not found")
+		} else {
+			RenderError(w, http.StatusInternalServerError, "Failed to retrieve job: "+err.Error())
+		}
+		return
+	}
+
+	// GetConfig shows the proxies masked, so one sent back as shown keeps
+	// the proxy stored
+	if RenderValidationError(w, unmaskProxies(req.Proxies, job.Config.Proxies)) {
+		return
+	}
+
+	req.Name = job.Name
+	req.applySourceJob(job)
+
+	domainReq, normalized, err := validateCreateRequest(&req)
+	if err != nil {
+		apiErr := validationAPIError(err)
+		RenderJSON(w, apiErr.Code, apiErr)
+		return
+	}
+
+	job, err = h.jobs.UpdateConfig(r.Context(), id, domainReq)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrJobNotFound):
+			RenderError(w, http.StatusNotFound, "Job not found")
+		case errors.Is(err, service.ErrJobNotEditable):
+			RenderError(w, http.StatusConflict, err.Error())
+		case RenderValidationError(w, err):
+		case isInvalidJobError(err):
+			RenderError(w, http.StatusBadRequest, err.Error())
+		default:
+			logging.Logger(r.Context(), "JobHandler").Error("config edit failed", "job_id", id, "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to edit job config")
+		}
+		return
+	}
+
+	h.invalidateJobCache(r.Context(), &id)
+
+	normalized.apply(job)
+	RenderJSON(w, http.StatusOK, maskJob(job))
+}
+
+// Archive handles GET /api/v2/jobs/{id}/archive: the job with its raw
+// results as a tar.gz for POST /api/v2/jobs/import on another manager
+func (h *JobHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	if _, err := h.jobs.GetByID(r.Context(), id); err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			RenderError(w, http.StatusNotFound, "Job not found")
+		} else {
+			RenderError(w, http.StatusInternalServerError, "Failed to retrieve job: "+err.Error())
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), archiveTimeout)
+	defer cancel()
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(archiveTimeout))
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", "attachment; filename=job-"+id.String()+".tar.gz")
+
+	// Headers are sent with the first bytes; a failure later can only cut
+	// the archive short, which the import detects
+	if err := h.jobs.Archive(ctx, id, w); err != nil {
+		logging.Logger(r.Context(), "JobHandler").Error("archive failed", "job_id", id, "error", err)
+	}
+}
+
+// Import handles POST /api/v2/jobs/import. The body is an archive of GET
+// /api/v2/jobs/{id}/archive; the job is recreated as a new completed job.
+func (h *JobHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), archiveTimeout)
+	defer cancel()
+	_ = http.NewResponseController(w).SetReadDeadline(time.Now().Add(archiveTimeout))
+
+	imported, err := h.jobs.Import(ctx, r.Body, requestTenant(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, jobarchive.ErrSchemaVersion), errors.Is(err, jobarchive.ErrMalformed):
+			RenderError(w, http.StatusBadRequest, err.Error())
+		default:
+			RenderError(w, http.StatusInternalServerError, "Failed to import job: "+err.Error())
+		}
+		return
+	}
+
+	h.invalidateJobCache(r.Context(), nil)
+
+	imported.Job = maskJob(imported.Job)
+	RenderJSON(w, http.StatusCreated, imported)
+}
+
+// Limits of a bulk job upload
+const (
+	maxBulkJobs      = 1000
+	maxBulkBodyBytes = 5 << 20
+)
+
+// BulkRowError is a row of a bulk upload no job was created for
+type BulkRowError struct {
+	Line   int                 `json:"line"`
+	Error  string              `json:"error"`
+	Fields []domain.FieldError `json:"errors,omitempty"`
+}
+
+// bulkRowError describes why the row at line was skipped
+func bulkRowError(line int, err error) BulkRowError {
+	rowErr := BulkRowError{Line: line, Error: err.Error()}
+	if verr, ok := domain.AsValidationError(err); ok {
+		rowErr.Fields = verr.Fields
+	}
+	return rowErr
+}
+
+// BulkCreateResponse lists the jobs a bulk upload created and the rows it
+// skipped
+type BulkCreateResponse struct {
+	Jobs    []*domain.Job  `json:"jobs"`
+	Skipped []BulkRowError `json:"skipped"`
+}
+
+type bulkRejectedResponse struct {
+	APIError
+	Rows []BulkRowError `json:"rows"`
+}
+
+// BulkCreate handles POST /api/v2/jobs/bulk. The body is a query CSV, the
+// format of -input in file mode, and each row becomes a job named after the
+// name parameter and its keyword; the place_url rows of each language make
+// one place_urls job. Settings a row leaves blank come from the
+// template_id template or the job defaults. Invalid rows are skipped, or
+// reject the whole upload with strict=true.
+func (h *JobHandler) BulkCreate(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	logger := logging.Logger(r.Context(), "JobHandler")
+
+	if r.Method != http.MethodPost {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+
+	name := strings.TrimSpace(query.Get("name"))
+	if name == "" {
+		RenderError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	strict := false
+	if s := query.Get("strict"); s != "" {
+		var err error
+		if strict, err = strconv.ParseBool(s); err != nil {
+			RenderError(w, http.StatusBadRequest, "strict must be true or false")
+			return
+		}
+	}
+
+	var tmpl *domain.JobTemplate
+	if s := query.Get("template_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			RenderError(w, http.StatusBadRequest, "Invalid template_id")
+			return
+		}
+		var apiErr *APIError
+		if tmpl, apiErr = h.template(r.Context(), id); apiErr != nil {
+			RenderError(w, apiErr.Code, apiErr.Message)
+			return
+		}
+	}
+
+	defaults, apiErr := h.jobDefaults(r.Context())
+	if apiErr != nil {
+		RenderError(w, apiErr.Code, apiErr.Message)
+		return
+	}
+
+	rows, invalid, err := querycsv.Parse(http.MaxBytesReader(w, r.Body, maxBulkBodyBytes))
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(rows) > maxBulkJobs {
+		RenderError(w, http.StatusBadRequest, fmt.Sprintf("at most %d rows are allowed", maxBulkJobs))
+		return
+	}
+
+	resp := BulkCreateResponse{
+		Jobs:    make([]*domain.Job, 0, len(rows)),
+		Skipped: make([]BulkRowError, 0, len(invalid)),
+	}
+	for _, rowErr := range invalid {
+		resp.Skipped = append(resp.Skipped, BulkRowError{Line: rowErr.Line, Error: rowErr.Err.Error()})
+	}
+
+	// Every row is checked before the first job is created, so a strict
+	// upload creates all jobs or none
+	type bulkJob struct {
+		line int
+		req  *domain.CreateJobRequest
+	}
+	jobs := make([]bulkJob, 0, len(rows))
+
+	add := func(line int, req *CreateJobRequest) {
+		if tmpl != nil {
+			req.TemplateID = &tmpl.ID
+			req.applyTemplate(tmpl.Config)
+		}
+		if defaults != nil {
+			req.applyTemplate(*defaults)
+		}
+
+		domainReq, _, err := validateCreateRequest(req)
+		if err != nil {
+			resp.Skipped = append(resp.Skipped, bulkRowError(line, err))
+			return
+		}
+		domainReq.Tenant = requestTenant(r)
+
+		jobs = append(jobs, bulkJob{line: line, req: domainReq})
+	}
+
+	// The place_url rows of a language make one job scraping their places,
+	// reported at the line of the first
+	type placeRows struct {
+		line int
+		lang string
+		urls []string
+	}
+	var places []*placeRows
+
+	for _, row := range rows {
+		if row.PlaceURL != "" {
+			i := slices.IndexFunc(places, func(p *placeRows) bool { return p.lang == row.Lang })
+			if i < 0 {
+				places = append(places, &placeRows{line: row.Line, lang: row.Lang})
+				i = len(places) - 1
+			}
+			places[i].urls = append(places[i].urls, row.PlaceURL)
+			continue
+		}
+
+		add(row.Line, &CreateJobRequest{
+			Name:     name + ": " + row.Keyword,
+			Keywords: []string{row.Keyword},
+			Lang:     row.Lang,
+			Lat:      row.Lat,
+			Lon:      row.Lon,
+			Zoom:     row.Zoom,
+			Depth:    row.Depth,
+			Radius:   row.Radius,
+		})
+	}
+
+	for _, p := range places {
+		jobName := fmt.Sprintf("%s: %d places", name, len(p.urls))
+		if p.lang != "" {
+			jobName += " (" + p.lang + ")"
+		}
+		add(p.line, &CreateJobRequest{
+			Name:      jobName,
+			InputType: domain.InputTypePlaceURLs,
+			PlaceURLs: p.urls,
+			Lang:      p.lang,
+		})
+	}
+
+	slices.SortFunc(resp.Skipped, func(a, b BulkRowError) int { return a.Line - b.Line })
+
+	if strict && len(resp.Skipped) > 0 {
+		RenderJSON(w, http.StatusBadRequest, bulkRejectedResponse{
+			APIError: APIError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("%d invalid rows", len(resp.Skipped)),
+			},
+			Rows: resp.Skipped,
+		})
+		return
+	}
+	if len(jobs) == 0 {
+		RenderError(w, http.StatusBadRequest, "No valid rows")
+		return
+	}
+
+	for _, j := range jobs {
+		job, err := h.jobs.Create(r.Context(), j.req)
+		if err != nil {
+			if !isInvalidJobError(err) && !errors.As(err, new(*domain.QuotaExceededError)) {
+				logger.Error("bulk create failed", "line", j.line, "error", err)
+				err = errors.New("Failed to create job")
+			}
+			resp.Skipped = append(resp.Skipped, bulkRowError(j.line, err))
+			continue
+		}
+		resp.Jobs = append(resp.Jobs, maskJob(job))
+	}
+
+	if tmpl != nil && len(resp.Jobs) > 0 {
+		if err := h.templates.RecordUsage(r.Context(), tmpl.ID); err != nil {
+			logger.Warn("failed to record template usage", "template_id", tmpl.ID, "error", err)
+		}
+	}
+
+	h.invalidateJobCache(r.Context(), nil)
+
+	logger.Info("bulk jobs created", "jobs", len(resp.Jobs), "skipped", len(resp.Skipped), "duration_ms", logging.SinceMS(start))
+	RenderJSON(w, http.StatusCreated, resp)
+}
+
+// ExpandKeywords handles POST /api/v2/jobs/expand-keywords
+func (h *JobHandler) ExpandKeywords(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req domain.ExpandKeywordsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if req.CoverageMode == domain.CoverageModeFull && !req.BoundingBox.IsValid() {
+		RenderError(w, http.StatusBadRequest, "Invalid bounding box coordinates")
+		return
+	}
+
+	expansion, err := h.jobs.ExpandKeywords(&req)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoBaseKeywords) || isKeywordExpansionError(err) {
+			RenderError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		RenderError(w, http.StatusInternalServerError, "Failed to expand keywords: "+err.Error())
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, expansion)
+}
+
+func isKeywordExpansionError(err error) bool {
+	return errors.Is(err, domain.ErrTooManyKeywords) ||
+		errors.Is(err, domain.ErrInvalidLocation) ||
+		errors.Is(err, domain.ErrInvalidSubdivision)
+}
+
+func isGridError(err error) bool {
+	return errors.Is(err, domain.ErrTooManyGridPoints) ||
+		errors.Is(err, domain.ErrInvalidMaxGridPoints)
+}
+
+// List handles GET /api/v2/jobs
+func (h *JobHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	ctx := r.Context()
+
+	// Parse query parameters
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" && !domain.JobStatus(status).IsValid() {
+		RenderError(w, http.StatusBadRequest, "Invalid status: "+status)
+		return
+	}
+	includeDeleted := r.URL.Query().Get("include_deleted") == "true"
+	tags := domain.NormalizeTags(r.URL.Query()["tag"])
+
+	errorCode := r.URL.Query().Get("error_code")
+	if errorCode != "" && !domain.JobErrorCode(errorCode).IsValid() {
+		RenderError(w, http.StatusBadRequest, "Invalid error_code: "+errorCode)
+		return
+	}
+
+	// Try cache if available
+	if h.cache != nil {
+		cacheKey := fmt.Sprintf("%s:list:page=%d:perPage=%d:status=%s:deleted=%t:tags=%s:error_code=%s",
+			cache.KeyPrefixDashboardJobs, page, perPage, status, includeDeleted, strings.Join(tags, ","), errorCode)
+		if cached, err := h.cache.Get(ctx, cacheKey); err == nil && cached != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Cache", "HIT")
+			w.WriteHeader(http.StatusOK)
+			w.Write(cached)
+			return
+		}
+	}
+
+	params := domain.JobListParams{
+		Limit:          perPage,
+		Offset:         (page - 1) * perPage,
+		IncludeDeleted: includeDeleted,
+		Tags:           tags,
+	}
+
+	// Parse status filter
+	if status != "" {
+		s := domain.JobStatus(status)
+		params.Status = &s
+	}
+	if errorCode != "" {
+		c := domain.JobErrorCode(errorCode)
+		params.ErrorCode = &c
+	}
+
+	jobs, total, err := h.jobs.List(ctx, params)
+	if err != nil {
+		RenderError(w, http.StatusInternalServerError, "Failed to list jobs: "+err.Error())
+		return
+	}
+
+	response := NewPaginatedResponse(maskJobs(jobs), total, page, perPage)
+
+	// Cache the response if cache available
+	if h.cache != nil {
+		cacheKey := fmt.Sprintf("%s:list:page=%d:perPage=%d:status=%s:deleted=%t:tags=%s:error_code=%s",
+			cache.KeyPrefixDashboardJobs, page, perPage, status, includeDeleted, strings.Join(tags, ","), errorCode)
+		if data, err := json.Marshal(response); err == nil {
+			h.cache.Set(ctx, cacheKey, data, cache.TTLJobsList)
+		}
+	}
+
+	w.Header().Set("X-Cache", "MISS")
+	RenderJSON(w, http.StatusOK, response)
+}
+
+// GetByID handles GET /api/v2/jobs/{id}
+func (h *JobHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	ctx := r.Context()
+
+	// Try cache if available
+	if h.cache != nil {
+		cacheKey := fmt.Sprintf("%s:%s", cache.KeyPrefixDashboardJobs, id.String())
+		if cached, err := h.cache.Get(ctx, cacheKey); err == nil && cached != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Cache", "HIT")
+			w.WriteHeader(http.StatusOK)
+			w.Write(cached)
+			return
+		}
+	}
+
+	job, err := h.jobs.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			RenderError(w, http.StatusNotFound, "Job not found")
+		} else {
+			RenderError(w, http.StatusInternalServerError, "Failed to retrieve job: "+err.Error())
+		}
+		return
+	}
+
+	// Cache the response if cache available
+	if h.cache != nil {
+		cacheKey := fmt.Sprintf("%s:%s", cache.KeyPrefixDashboardJobs, id.String())
+		if data, err := json.Marshal(maskJob(job)); err == nil {
+			h.cache.Set(ctx, cacheKey, data, cache.TTLJobDetail)
+		}
+	}
+
+	w.Header().Set("X-Cache", "MISS")
+	RenderJSON(w, http.StatusOK, maskJob(job))
+}
+
+// GetForWorker handles GET /api/v2/workers/{id}/jobs/{job_id}: the job a
+// worker got from the queue, with its proxies in full. It bypasses the
+// response cache, which only holds masked jobs.
+func (h *JobHandler) GetForWorker(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("job_id"))
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	job, err := h.jobs.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			RenderError(w, http.StatusNotFound, "Job not found")
+		} else {
+			RenderError(w, http.StatusInternalServerError, "Failed to retrieve job: "+err.Error())
+		}
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, job)
+}
+
+// Patch handles PATCH /api/v2/jobs/{id}: edits the tags and notes of a
+// job. Fields left out of the body are kept; status changes go through
+// the pause, resume and cancel endpoints.
+func (h *JobHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	var req domain.PatchJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	verr := &domain.ValidationError{}
+	verr.Check("", &req)
+	if RenderValidationError(w, verr.Err()) {
+		return
+	}
+
+	job, err := h.jobs.Patch(r.Context(), id, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			RenderError(w, http.StatusNotFound, "Job not found")
+		} else {
+			RenderError(w, http.StatusInternalServerError, "Failed to update job: "+err.Error())
+		}
+		return
+	}
+
+	h.invalidateJobCache(r.Context(), &id)
+
+	RenderJSON(w, http.StatusOK, maskJob(job))
+}
+
+// ParseReport handles GET /api/v2/jobs/{id}/parse-report: per field, how
+// many places of the last completed run it could not be read for
+func (h *JobHandler) ParseReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	report, err := h.jobs.ParseReport(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			RenderError(w, http.StatusNotFound, "Job not found")
+		} else {
+			RenderError(w, http.StatusInternalServerError, "Failed to retrieve parse report: "+err.Error())
+		}
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, report)
+}
+
+// Delete handles DELETE /api/v2/jobs/{id}. The job is only marked as
+// deleted, unless ?hard=true purges it with its results.
+func (h *JobHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	if r.URL.Query().Get("hard") == "true" {
+		err = h.jobs.Purge(r.Context(), id)
+	} else {
+		err = h.jobs.Delete(r.Context(), id)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrJobNotFound):
+			RenderError(w, http.StatusNotFound, "Job not found")
+		case errors.Is(err, service.ErrJobRunning):
+			RenderError(w, http.StatusConflict, err.Error())
+		default:
+			RenderError(w, http.StatusInternalServerError, "Failed to delete job: "+err.Error())
+		}
+		return
+	}
+
+	// Invalidate cache after successful delete
+	h.invalidateJobCache(r.Context(), &id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Restore handles POST /api/v2/jobs/{id}/restore
+func (h *JobHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	job, err := h.jobs.Restore(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrJobNotFound):
+			RenderError(w, http.StatusNotFound, "Job not found")
+		case errors.Is(err, service.ErrJobNotDeleted):
+			RenderError(w, http.StatusConflict, "Job is not deleted")
+		default:
+			RenderError(w, http.StatusInternalServerError, "Failed to restore job: "+err.Error())
+		}
+		return
+	}
+
+	h.invalidateJobCache(r.Context(), &id)
+
+	RenderJSON(w, http.StatusOK, maskJob(job))
+}
+
+// InvalidateJobs drops the cached job list, stats and details after jobs
+// changed outside of a request, like the purge of old deleted jobs
+func (h *JobHandler) InvalidateJobs(ctx context.Context, ids []uuid.UUID) {
+	for i := range ids {
+		h.invalidateJobCache(ctx, &ids[i])
+	}
+}
+
+// Pause handles POST /api/v2/jobs/{id}/pause
+func (h *JobHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	job, err := h.jobs.Pause(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			RenderError(w, http.StatusNotFound, "Job not found")
+		} else {
+			RenderError(w, http.StatusBadRequest, "Failed to pause job: "+err.Error())
+		}
 		return
 	}
 
-	// Invalidate cache after successful create
-	h.invalidateJobCache(r.Context(), &job.ID)
+	// Invalidate cache after successful pause
+	h.invalidateJobCache(r.Context(), &id)
 
-	log.Printf("[JobHandler] Create completed in %v (service: %v)", time.Since(start), time.Since(serviceStart))
-	RenderJSON(w, http.StatusCreated, job)
+	RenderJSON(w, http.StatusOK, maskJob(job))
 }
 
-// List handles GET /api/v2/jobs
-func (h *JobHandler) List(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+// Resume handles POST /api/v2/jobs/{id}/resume
+func (h *JobHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
 		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	ctx := r.Context()
-
-	// Parse query parameters
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
-	if perPage < 1 || perPage > 100 {
-		perPage = 20
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
 	}
 
-	status := r.URL.Query().Get("status")
-
-	// Try cache if available
-	if h.cache != nil {
-		cacheKey := fmt.Sprintf("%s:list:page=%d:perPage=%d:status=%s",
-			cache.KeyPrefixDashboardJobs, page, perPage, status)
-		if cached, err := h.cache.Get(ctx, cacheKey); err == nil && cached != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.Header().Set("X-Cache", "HIT")
-			w.WriteHeader(http.StatusOK)
-			w.Write(cached)
-			return
+	job, err := h.jobs.Resume(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			RenderError(w, http.StatusNotFound, "Job not found")
+		} else {
+			RenderError(w, http.StatusBadRequest, "Failed to resume job: "+err.Error())
 		}
+		return
 	}
 
-	params := domain.JobListParams{
-		Limit:  perPage,
-		Offset: (page - 1) * perPage,
-	}
+	// Invalidate cache after successful resume
+	h.invalidateJobCache(r.Context(), &id)
 
-	// Parse status filter
-	if status != "" {
-		s := domain.JobStatus(status)
-		params.Status = &s
+	RenderJSON(w, http.StatusOK, maskJob(job))
+}
+
+// Cancel handles POST /api/v2/jobs/{id}/cancel
+func (h *JobHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
 	}
 
-	jobs, total, err := h.jobs.List(ctx, params)
+	id, err := parseJobID(r)
 	if err != nil {
-		RenderError(w, http.StatusInternalServerError, "Failed to list jobs: "+err.Error())
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
 		return
 	}
 
-	response := NewPaginatedResponse(jobs, total, page, perPage)
-
-	// Cache the response if cache available
-	if h.cache != nil {
-		cacheKey := fmt.Sprintf("%s:list:page=%d:perPage=%d:status=%s",
-			cache.KeyPrefixDashboardJobs, page, perPage, status)
-		if data, err := json.Marshal(response); err == nil {
-			h.cache.Set(ctx, cacheKey, data, cache.TTLJobsList)
+	job, err := h.jobs.Cancel(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			RenderError(w, http.StatusNotFound, "Job not found")
+		} else {
+			RenderError(w, http.StatusBadRequest, "Failed to cancel job: "+err.Error())
 		}
+		return
 	}
 
-	w.Header().Set("X-Cache", "MISS")
-	RenderJSON(w, http.StatusOK, response)
+	// Invalidate cache after successful cancel
+	h.invalidateJobCache(r.Context(), &id)
+
+	RenderJSON(w, http.StatusOK, maskJob(job))
 }
 
-// GetByID handles GET /api/v2/jobs/{id}
-func (h *JobHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+// RetryFailed handles POST /api/v2/jobs/{id}/retry-failed?max_attempts=
+//
+// Searches that already ran max_attempts times are counted as exhausted
+// and left failed.
+func (h *JobHandler) RetryFailed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
 		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
@@ -359,45 +1772,42 @@ func (h *JobHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := r.Context()
-
-	// Try cache if available
-	if h.cache != nil {
-		cacheKey := fmt.Sprintf("%s:%s", cache.KeyPrefixDashboardJobs, id.String())
-		if cached, err := h.cache.Get(ctx, cacheKey); err == nil && cached != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.Header().Set("X-Cache", "HIT")
-			w.WriteHeader(http.StatusOK)
-			w.Write(cached)
+	maxAttempts := domain.DefaultMaxAttempts
+	if v := r.URL.Query().Get("max_attempts"); v != "" {
+		maxAttempts, err = strconv.Atoi(v)
+		if err != nil || maxAttempts < 1 || maxAttempts > domain.MaxAttemptsLimit {
+			RenderError(w, http.StatusBadRequest, fmt.Sprintf("max_attempts must be between 1 and %d", domain.MaxAttemptsLimit))
 			return
 		}
 	}
 
-	job, err := h.jobs.GetByID(ctx, id)
+	result, err := h.jobs.RetryFailed(r.Context(), id, maxAttempts)
 	if err != nil {
-		if errors.Is(err, service.ErrJobNotFound) {
+		switch {
+		case errors.Is(err, service.ErrJobNotFound):
 			RenderError(w, http.StatusNotFound, "Job not found")
-		} else {
-			RenderError(w, http.StatusInternalServerError, "Failed to retrieve job: "+err.Error())
+		case errors.Is(err, service.ErrJobNotRetryable), errors.Is(err, service.ErrFailureNotRetryable):
+			RenderError(w, http.StatusConflict, err.Error())
+		default:
+			logging.Logger(r.Context(), "JobHandler").Error("RetryFailed failed", "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to retry job")
 		}
 		return
 	}
 
-	// Cache the response if cache available
-	if h.cache != nil {
-		cacheKey := fmt.Sprintf("%s:%s", cache.KeyPrefixDashboardJobs, id.String())
-		if data, err := json.Marshal(job); err == nil {
-			h.cache.Set(ctx, cacheKey, data, cache.TTLJobDetail)
-		}
+	if result.Requeued > 0 {
+		h.invalidateJobCache(r.Context(), &id)
 	}
 
-	w.Header().Set("X-Cache", "MISS")
-	RenderJSON(w, http.StatusOK, job)
+	RenderJSON(w, http.StatusOK, result)
 }
 
-// Delete handles DELETE /api/v2/jobs/{id}
-func (h *JobHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
+// Backfill handles POST /api/v2/jobs/{id}/backfill
+//
+// A pending job is created that scrapes the pages of the places the
+// finished job kept partial; its results upgrade them in place.
+func (h *JobHandler) Backfill(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
 		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
@@ -408,23 +1818,32 @@ func (h *JobHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.jobs.Delete(r.Context(), id); err != nil {
-		if errors.Is(err, service.ErrJobNotFound) {
+	job, err := h.jobs.Backfill(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrJobNotFound):
 			RenderError(w, http.StatusNotFound, "Job not found")
-		} else {
-			RenderError(w, http.StatusInternalServerError, "Failed to delete job: "+err.Error())
+		case errors.Is(err, service.ErrJobNotFinished), errors.Is(err, service.ErrNoPartialResults):
+			RenderError(w, http.StatusConflict, err.Error())
+		case renderQuotaExceeded(w, err):
+		default:
+			logging.Logger(r.Context(), "JobHandler").Error("Backfill failed", "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to create backfill job")
 		}
 		return
 	}
 
-	// Invalidate cache after successful delete
-	h.invalidateJobCache(r.Context(), &id)
+	h.invalidateJobCache(r.Context(), &job.ID)
 
-	w.WriteHeader(http.StatusNoContent)
+	RenderJSON(w, http.StatusCreated, maskJob(job))
 }
 
-// Pause handles POST /api/v2/jobs/{id}/pause
-func (h *JobHandler) Pause(w http.ResponseWriter, r *http.Request) {
+// Shard handles POST /api/v2/jobs/{id}/shard?count=
+//
+// The keywords of the pending or waiting job are split over count jobs
+// that workers claim separately. The job returned is their parent, whose
+// progress, results and downloads are theirs.
+func (h *JobHandler) Shard(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
@@ -436,24 +1855,38 @@ func (h *JobHandler) Pause(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	job, err := h.jobs.Pause(r.Context(), id)
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
 	if err != nil {
-		if errors.Is(err, service.ErrJobNotFound) {
+		RenderError(w, http.StatusBadRequest, domain.ErrInvalidShardCount.Error())
+		return
+	}
+
+	job, err := h.jobs.Shard(r.Context(), id, count)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrJobNotFound):
 			RenderError(w, http.StatusNotFound, "Job not found")
-		} else {
-			RenderError(w, http.StatusBadRequest, "Failed to pause job: "+err.Error())
+		case errors.Is(err, domain.ErrInvalidShardCount):
+			RenderError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrJobNotShardable), errors.Is(err, service.ErrJobBridged):
+			RenderError(w, http.StatusConflict, err.Error())
+		default:
+			logging.Logger(r.Context(), "JobHandler").Error("Shard failed", "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to shard job")
 		}
 		return
 	}
 
-	// Invalidate cache after successful pause
-	h.invalidateJobCache(r.Context(), &id)
+	h.invalidateJobCache(r.Context(), &job.ID)
 
-	RenderJSON(w, http.StatusOK, job)
+	RenderJSON(w, http.StatusOK, maskJob(job))
 }
 
-// Resume handles POST /api/v2/jobs/{id}/resume
-func (h *JobHandler) Resume(w http.ResponseWriter, r *http.Request) {
+// Requeue handles POST /api/v2/jobs/{id}/requeue
+//
+// The message that enqueued the pending job is published again, for jobs
+// whose message was lost or is stuck in the queue.
+func (h *JobHandler) Requeue(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
@@ -465,25 +1898,44 @@ func (h *JobHandler) Resume(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	job, err := h.jobs.Resume(r.Context(), id)
+	job, err := h.jobs.Requeue(r.Context(), id)
 	if err != nil {
-		if errors.Is(err, service.ErrJobNotFound) {
+		switch {
+		case errors.Is(err, service.ErrJobNotFound):
 			RenderError(w, http.StatusNotFound, "Job not found")
-		} else {
-			RenderError(w, http.StatusBadRequest, "Failed to resume job: "+err.Error())
+		case errors.Is(err, service.ErrJobNotPending), errors.Is(err, service.ErrNoJobQueue):
+			RenderError(w, http.StatusConflict, err.Error())
+		default:
+			logging.Logger(r.Context(), "JobHandler").Error("Requeue failed", "error", err)
+			RenderError(w, http.StatusServiceUnavailable, "Failed to requeue job")
 		}
 		return
 	}
 
-	// Invalidate cache after successful resume
-	h.invalidateJobCache(r.Context(), &id)
+	RenderJSON(w, http.StatusOK, maskJob(job))
+}
 
-	RenderJSON(w, http.StatusOK, job)
+// QueueStatus handles GET /api/v2/queue/status
+func (h *JobHandler) QueueStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	status, err := h.jobs.QueueStatus(r.Context())
+	if err != nil {
+		logging.Logger(r.Context(), "JobHandler").Error("QueueStatus failed", "error", err)
+		RenderError(w, http.StatusServiceUnavailable, "Failed to inspect the job queue")
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, status)
 }
 
-// Cancel handles POST /api/v2/jobs/{id}/cancel
-func (h *JobHandler) Cancel(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+// Events handles GET /api/v2/jobs/{id}/events: the audit trail of a job,
+// oldest first
+func (h *JobHandler) Events(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
 		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
@@ -494,20 +1946,71 @@ func (h *JobHandler) Cancel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	job, err := h.jobs.Cancel(r.Context(), id)
+	page := 1
+	limit := 50
+
+	if p := r.URL.Query().Get("page"); p != "" {
+		if val, err := strconv.Atoi(p); err == nil && val > 0 {
+			page = val
+		}
+	}
+
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if val, err := strconv.Atoi(l); err == nil && val > 0 && val <= 500 {
+			limit = val
+		}
+	}
+
+	events, total, err := h.jobs.Events(r.Context(), id, limit, (page-1)*limit)
 	if err != nil {
-		if errors.Is(err, service.ErrJobNotFound) {
+		switch {
+		case errors.Is(err, service.ErrJobNotFound):
 			RenderError(w, http.StatusNotFound, "Job not found")
-		} else {
-			RenderError(w, http.StatusBadRequest, "Failed to cancel job: "+err.Error())
+		case errors.Is(err, service.ErrNoJobEvents):
+			RenderError(w, http.StatusNotImplemented, err.Error())
+		default:
+			logging.Logger(r.Context(), "JobHandler").Error("Events failed", "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to fetch job events")
 		}
 		return
 	}
 
-	// Invalidate cache after successful cancel
-	h.invalidateJobCache(r.Context(), &id)
+	RenderJSON(w, http.StatusOK, map[string]interface{}{
+		"data": events,
+		"meta": map[string]interface{}{
+			"page":        page,
+			"per_page":    limit,
+			"total":       total,
+			"total_pages": (total + limit - 1) / limit,
+		},
+	})
+}
 
-	RenderJSON(w, http.StatusOK, job)
+// JobResultsPage is a page of the results of a job. IsPartial is true
+// while the job is still running and adding to them.
+type JobResultsPage struct {
+	PaginatedResponse
+	IsPartial bool `json:"is_partial"`
+}
+
+// newJobResultsPage pages the results of job id, looking up whether they
+// are partial
+func newJobResultsPage(ctx context.Context, jobs JobLookup, id uuid.UUID, results [][]byte, total, page, perPage int) JobResultsPage {
+	// Parse JSON data
+	var parsedResults []json.RawMessage
+	for _, data := range results {
+		parsedResults = append(parsedResults, json.RawMessage(data))
+	}
+
+	resp := JobResultsPage{PaginatedResponse: NewPaginatedResponse(parsedResults, total, page, perPage)}
+	if jobs != nil {
+		partial, err := resultsPartial(ctx, jobs, id)
+		if err != nil {
+			logging.Logger(ctx, "JobHandler").Warn("failed to get job of results", "job_id", id, "error", err)
+		}
+		resp.IsPartial = partial
+	}
+	return resp
 }
 
 // GetResults handles GET /api/v2/jobs/{id}/results
@@ -542,14 +2045,7 @@ func (h *JobHandler) GetResults(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse JSON data
-	var parsedResults []json.RawMessage
-	for _, data := range results {
-		parsedResults = append(parsedResults, json.RawMessage(data))
-	}
-
-	response := NewPaginatedResponse(parsedResults, total, page, perPage)
-	RenderJSON(w, http.StatusOK, response)
+	RenderJSON(w, http.StatusOK, newJobResultsPage(r.Context(), h.jobs, id, results, total, page, perPage))
 }
 
 // DownloadResults handles GET /api/v2/jobs/{id}/download
@@ -570,6 +2066,11 @@ func (h *JobHandler) DownloadResults(w http.ResponseWriter, r *http.Request) {
 		format = "json"
 	}
 
+	if err := checkPartialDownload(w, r, h.jobs, id); err != nil {
+		RenderError(w, partialDownloadStatus(err), err.Error())
+		return
+	}
+
 	switch format {
 	case "json":
 		h.downloadJSON(w, r, id)
@@ -577,8 +2078,10 @@ func (h *JobHandler) DownloadResults(w http.ResponseWriter, r *http.Request) {
 		h.downloadCSV(w, r, id)
 	case "xlsx":
 		h.downloadXLSX(w, r, id)
+	case "geojson":
+		h.downloadGeoJSON(w, r, id)
 	default:
-		RenderError(w, http.StatusBadRequest, "Invalid format. Use 'json', 'csv', or 'xlsx'")
+		RenderError(w, http.StatusBadRequest, "Invalid format. Use 'json', 'csv', 'xlsx' or 'geojson'")
 	}
 }
 
@@ -589,6 +2092,7 @@ func (h *JobHandler) downloadJSON(w http.ResponseWriter, r *http.Request, jobID
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Content-Disposition", "attachment; filename=results-"+jobID.String()+".json")
+	announceExportComplete(w)
 
 	w.Write([]byte("["))
 	first := true
@@ -596,10 +2100,14 @@ func (h *JobHandler) downloadJSON(w http.ResponseWriter, r *http.Request, jobID
 
 	err := h.results.StreamByJobID(ctx, jobID, func(data []byte) error {
 		if !first {
-			w.Write([]byte(","))
+			if _, err := w.Write([]byte(",")); err != nil {
+				return err
+			}
 		}
 		first = false
-		w.Write(data)
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
 		count++
 
 		// Flush every 100 records to prevent buffering timeout
@@ -612,10 +2120,14 @@ func (h *JobHandler) downloadJSON(w http.ResponseWriter, r *http.Request, jobID
 	})
 
 	if err != nil {
-		log.Printf("error streaming JSON for job %s: %v", jobID, err)
+		// The array is left open, so the file does not parse either
+		logging.Logger(r.Context(), "JobHandler").Error("failed to stream JSON results", "job_id", jobID, "rows", count, "error", err)
+		endExport(w, false)
+		return
 	}
 
 	w.Write([]byte("]"))
+	endExport(w, true)
 }
 
 func (h *JobHandler) downloadCSV(w http.ResponseWriter, r *http.Request, jobID uuid.UUID) {
@@ -625,12 +2137,12 @@ func (h *JobHandler) downloadCSV(w http.ResponseWriter, r *http.Request, jobID u
 
 	w.Header().Set("Content-Type", "text/csv")
 	w.Header().Set("Content-Disposition", "attachment; filename=results-"+jobID.String()+".csv")
+	announceExportComplete(w)
 
 	availableColumns := getAvailableColumns()
 	selectedColumns := parseSelectedColumns(r.URL.Query().Get("columns"), availableColumns)
 
 	writer := csv.NewWriter(w)
-	defer writer.Flush()
 
 	// Write Header
 	if err := writer.Write(selectedColumns); err != nil {
@@ -664,19 +2176,30 @@ func (h *JobHandler) downloadCSV(w http.ResponseWriter, r *http.Request, jobID u
 		return nil
 	})
 
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	if err == nil {
+		err = writeExportMarker(w, count)
+	}
+
 	if err != nil {
-		log.Printf("error streaming CSV for job %s: %v", jobID, err)
+		logging.Logger(r.Context(), "JobHandler").Error("failed to stream CSV results", "job_id", jobID, "rows", count, "error", err)
+		endExport(w, false)
+		return
 	}
+
+	endExport(w, true)
 }
 
+// downloadXLSX builds the whole workbook before sending it, so a failed
+// read is a 500 rather than a truncated file
 func (h *JobHandler) downloadXLSX(w http.ResponseWriter, r *http.Request, jobID uuid.UUID) {
 	// Create download context with timeout
 	ctx, cancel := context.WithTimeout(r.Context(), downloadTimeout)
 	defer cancel()
 
-	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	w.Header().Set("Content-Disposition", "attachment; filename=results-"+jobID.String()+".xlsx")
-
 	availableColumns := getAvailableColumns()
 
 	// Parse requested columns
@@ -719,7 +2242,9 @@ func (h *JobHandler) downloadXLSX(w http.ResponseWriter, r *http.Request, jobID
 	})
 
 	if err != nil {
-		log.Printf("error streaming results for XLSX job %s: %v", jobID, err)
+		logging.Logger(r.Context(), "JobHandler").Error("failed to stream XLSX results", "job_id", jobID, "rows", rowNum-2, "error", err)
+		RenderError(w, http.StatusInternalServerError, "Failed to read results")
+		return
 	}
 
 	// Auto-fit column widths (approximate)
@@ -728,61 +2253,75 @@ func (h *JobHandler) downloadXLSX(w http.ResponseWriter, r *http.Request, jobID
 		f.SetColWidth(sheetName, colName, colName, 15)
 	}
 
-	// Write to response
-	if err := f.Write(w); err != nil {
-		log.Printf("error writing XLSX to response: %v", err)
+	sent, err := sendWorkbook(w, "results-"+jobID.String()+".xlsx", func(out io.Writer) error {
+		return f.Write(out)
+	})
+	if err != nil {
+		logging.Logger(r.Context(), "JobHandler").Error("failed to write XLSX response", "job_id", jobID, "rows", rowNum-2, "error", err)
+		if !sent {
+			RenderError(w, http.StatusInternalServerError, "Failed to build workbook")
+		}
 	}
 }
 
-// getAvailableColumns returns the map of available export columns
-func getAvailableColumns() map[string]func(e *gmaps.Entry) string {
-	return map[string]func(e *gmaps.Entry) string{
-		"Title":           func(e *gmaps.Entry) string { return e.Title },
-		"Address":         func(e *gmaps.Entry) string { return e.Address },
-		"Phone":           func(e *gmaps.Entry) string { return e.Phone },
-		"Website":         func(e *gmaps.Entry) string { return e.WebSite },
-		"Category":        func(e *gmaps.Entry) string { return e.Category },
-		"Rating":          func(e *gmaps.Entry) string { return fmt.Sprintf("%.1f", e.ReviewRating) },
-		"Reviews":         func(e *gmaps.Entry) string { return fmt.Sprintf("%d", e.ReviewCount) },
-		"Latitude":        func(e *gmaps.Entry) string { return fmt.Sprintf("%f", e.Latitude) },
-		"Longitude":       func(e *gmaps.Entry) string { return fmt.Sprintf("%f", e.Longitude) },
-		"Place ID":        func(e *gmaps.Entry) string { return e.PlaceID },
-		"Google Maps URL": func(e *gmaps.Entry) string { return e.Link },
-		"Description":     func(e *gmaps.Entry) string { return e.Description },
-		"Status":          func(e *gmaps.Entry) string { return e.Status },
-		"Timezone":        func(e *gmaps.Entry) string { return e.Timezone },
-		"Price Range":     func(e *gmaps.Entry) string { return e.PriceRange },
-		"Data ID":         func(e *gmaps.Entry) string { return e.DataID },
-		"Email": func(e *gmaps.Entry) string { return strings.Join(e.Emails, ", ") },
-		"Opening Hours": func(e *gmaps.Entry) string {
-			var parts []string
-			for day, hours := range e.OpenHours {
-				parts = append(parts, fmt.Sprintf("%s: %s", day, strings.Join(hours, ", ")))
+func (h *JobHandler) downloadGeoJSON(w http.ResponseWriter, r *http.Request, jobID uuid.UUID) {
+	// Create download context with timeout
+	ctx, cancel := context.WithTimeout(r.Context(), downloadTimeout)
+	defer cancel()
+
+	availableColumns := getAvailableColumns()
+	selectedColumns := parseSelectedColumns(r.URL.Query().Get("columns"), availableColumns)
+
+	g, err := startGeoJSON(w, "results-"+jobID.String(), selectedColumns, availableColumns)
+	if err != nil {
+		return
+	}
+
+	err = h.results.StreamByJobID(ctx, jobID, func(data []byte) error {
+		if err := g.write(data); err != nil {
+			return err
+		}
+
+		// Flush every 100 features to prevent buffering timeout
+		if g.features%100 == 0 {
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
 			}
-			return strings.Join(parts, "; ")
-		},
+		}
+		return nil
+	})
+	if err == nil {
+		err = g.finish(w)
 	}
+
+	if err != nil {
+		logging.Logger(r.Context(), "JobHandler").Error("failed to stream GeoJSON results", "job_id", jobID, "features", g.features, "error", err)
+	}
+}
+
+// getAvailableColumns maps the label of every export column of raw results
+// to its formatter
+func getAvailableColumns() map[string]func(e *gmaps.Entry) string {
+	return exportschema.EntryFormatters()
 }
 
-// parseSelectedColumns parses and validates requested columns
+// parseSelectedColumns parses the requested columns, by label or key, into
+// labels. Unknown columns are dropped; without any the defaults are used.
 func parseSelectedColumns(colsParam string, availableColumns map[string]func(e *gmaps.Entry) string) []string {
 	var selectedColumns []string
 	if colsParam != "" {
 		requested := strings.Split(colsParam, ",")
 		for _, col := range requested {
-			col = strings.TrimSpace(col)
-			if _, ok := availableColumns[col]; ok {
-				selectedColumns = append(selectedColumns, col)
+			c, ok := exportschema.Lookup(strings.TrimSpace(col))
+			if _, available := availableColumns[c.Label]; ok && available {
+				selectedColumns = append(selectedColumns, c.Label)
 			}
 		}
 	}
 
 	// Default columns if none selected or invalid
 	if len(selectedColumns) == 0 {
-		selectedColumns = []string{
-			"Title", "Address", "Phone", "Website", "Category", "Rating", "Reviews",
-			"Latitude", "Longitude", "Place ID", "Google Maps URL",
-		}
+		selectedColumns = exportschema.DefaultLabels()
 	}
 	return selectedColumns
 }
@@ -803,6 +2342,71 @@ func (h *JobHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	RenderJSON(w, http.StatusOK, stats)
 }
 
+// maskJob returns a copy of job with the credentials of its proxies masked,
+// for responses to users. Workers claim jobs with them unmasked.
+func maskJob(job *domain.Job) *domain.Job {
+	if job == nil || len(job.Config.Proxies) == 0 {
+		return job
+	}
+
+	masked := *job
+	masked.Config.Proxies = secrets.MaskURLs(job.Config.Proxies)
+	return &masked
+}
+
+// unmaskProxies replaces each of proxies that is the masked form of a proxy
+// of stored, the one at its index first, by that proxy. Any other proxy
+// with a masked credential is refused, as it would be stored as ***.
+func unmaskProxies(proxies, stored []string) error {
+	verr := &domain.ValidationError{}
+	for i, p := range proxies {
+		if !isMaskedProxy(p) {
+			continue
+		}
+
+		if i < len(stored) && secrets.MaskURL(stored[i]) == p {
+			proxies[i] = stored[i]
+			continue
+		}
+		var matches []string
+		for _, s := range stored {
+			if secrets.MaskURL(s) == p && !slices.Contains(matches, s) {
+				matches = append(matches, s)
+			}
+		}
+		if len(matches) != 1 {
+			verr.Add(fmt.Sprintf("proxies[%d]", i), "masked", "Proxy %q has a masked credential that matches no single proxy of the job", p)
+			continue
+		}
+		proxies[i] = matches[0]
+	}
+	return verr.Err()
+}
+
+// isMaskedProxy reports whether p carries a credential masked by
+// secrets.MaskURL
+func isMaskedProxy(p string) bool {
+	if p == "***" {
+		return true
+	}
+
+	u, err := url.Parse(p)
+	if err != nil || u.User == nil {
+		return false
+	}
+	password, _ := u.User.Password()
+	return u.User.Username() == "***" || password == "***"
+}
+
+// maskJobs applies maskJob to each job
+func maskJobs(jobs []*domain.Job) []*domain.Job {
+	masked := make([]*domain.Job, len(jobs))
+	for i, job := range jobs {
+		masked[i] = maskJob(job)
+	}
+	return masked
+}
+
 func parseJobID(r *http.Request) (uuid.UUID, error) {
 	idStr := r.PathValue("id")
 	if idStr == "" {
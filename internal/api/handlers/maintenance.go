@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// MaintenanceServiceInterface defines the database maintenance service
+// methods
+type MaintenanceServiceInterface interface {
+	Start(ctx context.Context, trigger string, opts domain.MaintenanceOptions) (*domain.MaintenanceRun, error)
+	Latest(ctx context.Context) (*domain.MaintenanceRun, error)
+}
+
+// MaintenanceHandler starts database maintenance runs and reports their
+// progress
+type MaintenanceHandler struct {
+	maintenance MaintenanceServiceInterface
+}
+
+// NewMaintenanceHandler creates a new MaintenanceHandler
+func NewMaintenanceHandler(maintenance MaintenanceServiceInterface) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenance: maintenance,
+	}
+}
+
+// Maintenance handles /api/v2/admin/maintenance. POST starts a run with
+// the options in the body, which may be empty, and GET returns the latest
+// one.
+func (h *MaintenanceHandler) Maintenance(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context(), "MaintenanceHandler")
+
+	switch r.Method {
+	case http.MethodGet:
+		run, err := h.maintenance.Latest(r.Context())
+		if err != nil {
+			logger.Error("Latest failed", "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to get maintenance run")
+			return
+		}
+		if run == nil {
+			RenderError(w, http.StatusNotFound, "No maintenance run")
+			return
+		}
+
+		RenderJSON(w, http.StatusOK, run)
+	case http.MethodPost:
+		var opts domain.MaintenanceOptions
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil && !errors.Is(err, io.EOF) {
+			RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+
+		run, err := h.maintenance.Start(r.Context(), domain.MaintenanceTriggerAPI, opts)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrInvalidMaintenance):
+				RenderError(w, http.StatusBadRequest, err.Error())
+			case errors.Is(err, service.ErrMaintenanceRunning):
+				RenderError(w, http.StatusConflict, err.Error())
+			default:
+				logger.Error("Start failed", "error", err)
+				RenderError(w, http.StatusInternalServerError, "Failed to start maintenance")
+			}
+			return
+		}
+
+		RenderJSON(w, http.StatusAccepted, run)
+	default:
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
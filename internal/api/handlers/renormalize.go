@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// RenormalizeServiceInterface defines the re-normalization service methods
+type RenormalizeServiceInterface interface {
+	Start(ctx context.Context, jobID *uuid.UUID) (*domain.RenormalizeRun, error)
+	Latest(ctx context.Context, jobID *uuid.UUID) (*domain.RenormalizeRun, error)
+}
+
+// RenormalizeHandler starts re-normalization runs and reports their progress
+type RenormalizeHandler struct {
+	renormalize RenormalizeServiceInterface
+}
+
+// NewRenormalizeHandler creates a new RenormalizeHandler
+func NewRenormalizeHandler(renormalize RenormalizeServiceInterface) *RenormalizeHandler {
+	return &RenormalizeHandler{
+		renormalize: renormalize,
+	}
+}
+
+// Renormalize handles /api/v2/admin/renormalize?job_id=. POST starts or
+// resumes the run of the job, or of every job without job_id, and GET
+// returns the latest one.
+func (h *RenormalizeHandler) Renormalize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var jobID *uuid.UUID
+	if v := r.URL.Query().Get("job_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			RenderError(w, http.StatusBadRequest, "Invalid job_id")
+			return
+		}
+		jobID = &id
+	}
+
+	logger := logging.Logger(r.Context(), "RenormalizeHandler")
+
+	if r.Method == http.MethodGet {
+		run, err := h.renormalize.Latest(r.Context(), jobID)
+		if err != nil {
+			logger.Error("Latest failed", "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to get re-normalization run")
+			return
+		}
+		if run == nil {
+			RenderError(w, http.StatusNotFound, "No re-normalization run")
+			return
+		}
+
+		RenderJSON(w, http.StatusOK, run)
+		return
+	}
+
+	run, err := h.renormalize.Start(r.Context(), jobID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrJobNotFound):
+			RenderError(w, http.StatusNotFound, "Job not found")
+		case errors.Is(err, service.ErrRenormalizeRunning):
+			RenderError(w, http.StatusConflict, err.Error())
+		default:
+			logger.Error("Start failed", "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to start re-normalization")
+		}
+		return
+	}
+
+	RenderJSON(w, http.StatusAccepted, run)
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/internal/report"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// ReportServiceInterface defines the job report service methods
+type ReportServiceInterface interface {
+	Render(ctx context.Context, jobID uuid.UUID, mode report.MapMode) (*report.Report, error)
+}
+
+// ReportHandler serves the summary reports of jobs
+type ReportHandler struct {
+	reports ReportServiceInterface
+}
+
+// NewReportHandler creates a new ReportHandler
+func NewReportHandler(reports ReportServiceInterface) *ReportHandler {
+	return &ReportHandler{
+		reports: reports,
+	}
+}
+
+// Report handles GET /api/v2/jobs/{id}/report with the HTML of the report
+// emailed to the job's notify_emails, its map inlined
+func (h *ReportHandler) Report(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	rep, err := h.reports.Render(r.Context(), id, report.MapInline)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			RenderError(w, http.StatusNotFound, "Job not found")
+			return
+		}
+		logging.Logger(r.Context(), "ReportHandler").Error("render report failed", "job_id", id, "error", err)
+		RenderError(w, http.StatusInternalServerError, "Failed to render report")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(rep.HTML))
+}
@@ -3,12 +3,17 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
 )
 
 // APIError represents an error response
 type APIError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+
+	// Errors lists the fields of a request that failed validation
+	Errors []domain.FieldError `json:"errors,omitempty"`
 }
 
 // PaginatedResponse wraps paginated results
@@ -35,6 +40,30 @@ func RenderError(w http.ResponseWriter, code int, message string) {
 	})
 }
 
+// validationAPIError is the response to a request err rejected: 422 with
+// the failing fields for a *domain.ValidationError, 400 otherwise
+func validationAPIError(err error) *APIError {
+	if verr, ok := domain.AsValidationError(err); ok {
+		return &APIError{Code: http.StatusUnprocessableEntity, Message: verr.Error(), Errors: verr.Fields}
+	}
+	return &APIError{Code: http.StatusBadRequest, Message: err.Error()}
+}
+
+// RenderValidationError renders the *domain.ValidationError in the chain
+// of err as 422 with the failing fields, and reports whether there was one
+func RenderValidationError(w http.ResponseWriter, err error) bool {
+	verr, ok := domain.AsValidationError(err)
+	if !ok {
+		return false
+	}
+	RenderJSON(w, http.StatusUnprocessableEntity, APIError{
+		Code:    http.StatusUnprocessableEntity,
+		Message: verr.Error(),
+		Errors:  verr.Fields,
+	})
+	return true
+}
+
 // NewPaginatedResponse creates a paginated response
 func NewPaginatedResponse(data interface{}, total, page, perPage int) PaginatedResponse {
 	totalPages := total / perPage
@@ -4,15 +4,14 @@ import (
 	"context"
 	"encoding/csv"
 	"encoding/json"
-	"fmt"
-	"log"
+	"io"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/xuri/excelize/v2"
 
 	"github.com/sadewadee/google-scraper/gmaps"
+	"github.com/sadewadee/google-scraper/internal/logging"
 )
 
 // Note: downloadTimeout constant is defined in jobs.go (5 minutes)
@@ -89,8 +88,10 @@ func (h *ResultHandler) Download(w http.ResponseWriter, r *http.Request) {
 		h.downloadCSV(w, r)
 	case "xlsx":
 		h.downloadXLSX(w, r)
+	case "geojson":
+		h.downloadGeoJSON(w, r)
 	default:
-		RenderError(w, http.StatusBadRequest, "Invalid format. Use 'json', 'csv', or 'xlsx'")
+		RenderError(w, http.StatusBadRequest, "Invalid format. Use 'json', 'csv', 'xlsx' or 'geojson'")
 	}
 }
 
@@ -101,9 +102,11 @@ func (h *ResultHandler) downloadJSON(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Content-Disposition", "attachment; filename=all-results.json")
+	announceExportComplete(w)
 
 	w.Write([]byte("["))
 	first := true
+	count := 0
 
 	// Stream in batches
 	offset := 0
@@ -113,15 +116,18 @@ func (h *ResultHandler) downloadJSON(w http.ResponseWriter, r *http.Request) {
 		// Check context before each batch
 		select {
 		case <-ctx.Done():
-			log.Printf("download JSON cancelled: %v", ctx.Err())
+			logging.Logger(ctx, "ResultHandler").Warn("JSON download cancelled", "rows", count, "error", ctx.Err())
+			endExport(w, false)
 			return
 		default:
 		}
 
 		results, _, err := h.results.ListAll(ctx, batchSize, offset)
 		if err != nil {
-			log.Printf("error fetching results for JSON download: %v", err)
-			break
+			// The array is left open, so the file does not parse either
+			logging.Logger(ctx, "ResultHandler").Error("failed to fetch results for JSON download", "rows", count, "error", err)
+			endExport(w, false)
+			return
 		}
 
 		if len(results) == 0 {
@@ -134,6 +140,7 @@ func (h *ResultHandler) downloadJSON(w http.ResponseWriter, r *http.Request) {
 			}
 			first = false
 			w.Write(data)
+			count++
 		}
 
 		// Flush response to prevent buffering timeout
@@ -145,6 +152,7 @@ func (h *ResultHandler) downloadJSON(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Write([]byte("]"))
+	endExport(w, true)
 }
 
 func (h *ResultHandler) downloadCSV(w http.ResponseWriter, r *http.Request) {
@@ -154,9 +162,10 @@ func (h *ResultHandler) downloadCSV(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "text/csv")
 	w.Header().Set("Content-Disposition", "attachment; filename=all-results.csv")
+	announceExportComplete(w)
 
-	availableColumns := getGlobalAvailableColumns()
-	selectedColumns := parseGlobalSelectedColumns(r.URL.Query().Get("columns"), availableColumns)
+	availableColumns := getAvailableColumns()
+	selectedColumns := parseSelectedColumns(r.URL.Query().Get("columns"), availableColumns)
 
 	writer := csv.NewWriter(w)
 	defer writer.Flush()
@@ -169,20 +178,23 @@ func (h *ResultHandler) downloadCSV(w http.ResponseWriter, r *http.Request) {
 	// Stream in batches
 	offset := 0
 	batchSize := 1000
+	count := 0
 
 	for {
 		// Check context before each batch
 		select {
 		case <-ctx.Done():
-			log.Printf("download CSV cancelled: %v", ctx.Err())
+			logging.Logger(ctx, "ResultHandler").Warn("CSV download cancelled", "rows", count, "error", ctx.Err())
+			endExport(w, false)
 			return
 		default:
 		}
 
 		results, _, err := h.results.ListAll(ctx, batchSize, offset)
 		if err != nil {
-			log.Printf("error fetching results for CSV download: %v", err)
-			break
+			logging.Logger(ctx, "ResultHandler").Error("failed to fetch results for CSV download", "rows", count, "error", err)
+			endExport(w, false)
+			return
 		}
 
 		if len(results) == 0 {
@@ -201,6 +213,7 @@ func (h *ResultHandler) downloadCSV(w http.ResponseWriter, r *http.Request) {
 			}
 
 			writer.Write(record)
+			count++
 		}
 
 		offset += batchSize
@@ -211,18 +224,29 @@ func (h *ResultHandler) downloadCSV(w http.ResponseWriter, r *http.Request) {
 			flusher.Flush()
 		}
 	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		logging.Logger(ctx, "ResultHandler").Error("failed to write CSV download", "rows", count, "error", err)
+		endExport(w, false)
+		return
+	}
+	if err := writeExportMarker(w, count); err != nil {
+		endExport(w, false)
+		return
+	}
+	endExport(w, true)
 }
 
+// downloadXLSX builds the whole workbook before sending it, so a failed
+// read is a 500 rather than a truncated file
 func (h *ResultHandler) downloadXLSX(w http.ResponseWriter, r *http.Request) {
 	// Create download context with timeout
 	ctx, cancel := context.WithTimeout(r.Context(), downloadTimeout)
 	defer cancel()
 
-	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	w.Header().Set("Content-Disposition", "attachment; filename=all-results.xlsx")
-
-	availableColumns := getGlobalAvailableColumns()
-	selectedColumns := parseGlobalSelectedColumns(r.URL.Query().Get("columns"), availableColumns)
+	availableColumns := getAvailableColumns()
+	selectedColumns := parseSelectedColumns(r.URL.Query().Get("columns"), availableColumns)
 
 	f := excelize.NewFile()
 	defer f.Close()
@@ -252,15 +276,17 @@ func (h *ResultHandler) downloadXLSX(w http.ResponseWriter, r *http.Request) {
 		// Check context before each batch
 		select {
 		case <-ctx.Done():
-			log.Printf("download XLSX cancelled: %v", ctx.Err())
+			logging.Logger(ctx, "ResultHandler").Warn("XLSX download cancelled", "rows", rowNum-2, "error", ctx.Err())
+			RenderError(w, http.StatusServiceUnavailable, "Download timed out")
 			return
 		default:
 		}
 
 		results, _, err := h.results.ListAll(ctx, batchSize, offset)
 		if err != nil {
-			log.Printf("error fetching results for XLSX download: %v", err)
-			break
+			logging.Logger(ctx, "ResultHandler").Error("failed to fetch results for XLSX download", "rows", rowNum-2, "error", err)
+			RenderError(w, http.StatusInternalServerError, "Failed to read results")
+			return
 		}
 
 		if len(results) == 0 {
@@ -289,60 +315,72 @@ func (h *ResultHandler) downloadXLSX(w http.ResponseWriter, r *http.Request) {
 		f.SetColWidth(sheetName, colName, colName, 15)
 	}
 
-	if err := f.Write(w); err != nil {
-		log.Printf("error writing XLSX to response: %v", err)
+	sent, err := sendWorkbook(w, "all-results.xlsx", func(out io.Writer) error {
+		return f.Write(out)
+	})
+	if err != nil {
+		logging.Logger(ctx, "ResultHandler").Error("failed to write XLSX response", "rows", rowNum-2, "error", err)
+		if !sent {
+			RenderError(w, http.StatusInternalServerError, "Failed to build workbook")
+		}
 	}
 }
 
-// getGlobalAvailableColumns returns the map of available export columns
-func getGlobalAvailableColumns() map[string]func(e *gmaps.Entry) string {
-	return map[string]func(e *gmaps.Entry) string{
-		"Title":           func(e *gmaps.Entry) string { return e.Title },
-		"Address":         func(e *gmaps.Entry) string { return e.Address },
-		"Phone":           func(e *gmaps.Entry) string { return e.Phone },
-		"Website":         func(e *gmaps.Entry) string { return e.WebSite },
-		"Category":        func(e *gmaps.Entry) string { return e.Category },
-		"Rating":          func(e *gmaps.Entry) string { return fmt.Sprintf("%.1f", e.ReviewRating) },
-		"Reviews":         func(e *gmaps.Entry) string { return fmt.Sprintf("%d", e.ReviewCount) },
-		"Latitude":        func(e *gmaps.Entry) string { return fmt.Sprintf("%f", e.Latitude) },
-		"Longitude":       func(e *gmaps.Entry) string { return fmt.Sprintf("%f", e.Longitude) },
-		"Place ID":        func(e *gmaps.Entry) string { return e.PlaceID },
-		"Google Maps URL": func(e *gmaps.Entry) string { return e.Link },
-		"Description":     func(e *gmaps.Entry) string { return e.Description },
-		"Status":          func(e *gmaps.Entry) string { return e.Status },
-		"Timezone":        func(e *gmaps.Entry) string { return e.Timezone },
-		"Price Range":     func(e *gmaps.Entry) string { return e.PriceRange },
-		"Data ID":         func(e *gmaps.Entry) string { return e.DataID },
-		"Email":           func(e *gmaps.Entry) string { return strings.Join(e.Emails, ", ") },
-		"Opening Hours": func(e *gmaps.Entry) string {
-			var parts []string
-			for day, hours := range e.OpenHours {
-				parts = append(parts, fmt.Sprintf("%s: %s", day, strings.Join(hours, ", ")))
-			}
-			return strings.Join(parts, "; ")
-		},
+func (h *ResultHandler) downloadGeoJSON(w http.ResponseWriter, r *http.Request) {
+	// Create download context with timeout
+	ctx, cancel := context.WithTimeout(r.Context(), downloadTimeout)
+	defer cancel()
+
+	availableColumns := getAvailableColumns()
+	selectedColumns := parseSelectedColumns(r.URL.Query().Get("columns"), availableColumns)
+
+	g, err := startGeoJSON(w, "all-results", selectedColumns, availableColumns)
+	if err != nil {
+		return
 	}
-}
 
-// parseGlobalSelectedColumns parses and validates requested columns
-func parseGlobalSelectedColumns(colsParam string, availableColumns map[string]func(e *gmaps.Entry) string) []string {
-	var selectedColumns []string
-	if colsParam != "" {
-		requested := strings.Split(colsParam, ",")
-		for _, col := range requested {
-			col = strings.TrimSpace(col)
-			if _, ok := availableColumns[col]; ok {
-				selectedColumns = append(selectedColumns, col)
+	// Stream in batches
+	offset := 0
+	batchSize := 1000
+
+	for {
+		// Check context before each batch
+		select {
+		case <-ctx.Done():
+			logging.Logger(ctx, "ResultHandler").Warn("GeoJSON download cancelled", "error", ctx.Err())
+			return
+		default:
+		}
+
+		results, _, err := h.results.ListAll(ctx, batchSize, offset)
+		if err != nil {
+			logging.Logger(ctx, "ResultHandler").Error("failed to fetch results for GeoJSON download", "error", err)
+			return
+		}
+
+		if len(results) == 0 {
+			break
+		}
+
+		for _, data := range results {
+			if err := g.write(data); err != nil {
+				if _, ok := err.(*json.SyntaxError); ok {
+					continue
+				}
+				logging.Logger(ctx, "ResultHandler").Error("failed to write GeoJSON download", "error", err)
+				return
 			}
 		}
-	}
 
-	// Default columns if none selected or invalid
-	if len(selectedColumns) == 0 {
-		selectedColumns = []string{
-			"Title", "Address", "Phone", "Website", "Category", "Rating", "Reviews",
-			"Latitude", "Longitude", "Place ID", "Google Maps URL",
+		offset += batchSize
+
+		// Flush HTTP response to prevent buffering timeout
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
 		}
 	}
-	return selectedColumns
+
+	if err := g.finish(w); err != nil {
+		logging.Logger(ctx, "ResultHandler").Error("failed to write GeoJSON download", "error", err)
+	}
 }
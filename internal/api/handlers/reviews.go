@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// ReviewServiceInterface defines the review service methods
+type ReviewServiceInterface interface {
+	ListByJobID(ctx context.Context, jobID string, limit, offset int) ([]*domain.BusinessReview, int, error)
+	ExportCSVByJobID(ctx context.Context, w io.Writer, jobID string) error
+	ExportNDJSONByJobID(ctx context.Context, w io.Writer, jobID string) error
+}
+
+// ReviewHandler handles review HTTP requests
+type ReviewHandler struct {
+	reviews ReviewServiceInterface
+}
+
+// NewReviewHandler creates a new ReviewHandler
+func NewReviewHandler(reviews ReviewServiceInterface) *ReviewHandler {
+	return &ReviewHandler{
+		reviews: reviews,
+	}
+}
+
+// ListByJobID handles GET /api/v2/jobs/{id}/reviews
+func (h *ReviewHandler) ListByJobID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	page := 1
+	limit := 50
+
+	if p := r.URL.Query().Get("page"); p != "" {
+		if val, err := strconv.Atoi(p); err == nil && val > 0 {
+			page = val
+		}
+	}
+
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if val, err := strconv.Atoi(l); err == nil && val > 0 && val <= 500 {
+			limit = val
+		}
+	}
+
+	reviews, total, err := h.reviews.ListByJobID(r.Context(), id.String(), limit, (page-1)*limit)
+	if err != nil {
+		logging.Logger(r.Context(), "ReviewHandler").Error("ListByJobID failed", "error", err)
+		RenderError(w, http.StatusInternalServerError, "Failed to fetch reviews")
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, map[string]interface{}{
+		"data": reviews,
+		"meta": map[string]interface{}{
+			"page":        page,
+			"per_page":    limit,
+			"total":       total,
+			"total_pages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// DownloadByJobID handles GET /api/v2/jobs/{id}/reviews/download?format=csv|ndjson
+func (h *ReviewHandler) DownloadByJobID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	filename := "job_" + id.String()[:8] + "_reviews"
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename="+filename+".csv")
+		if err := h.reviews.ExportCSVByJobID(r.Context(), w, id.String()); err != nil {
+			logging.Logger(r.Context(), "ReviewHandler").Error("ExportCSVByJobID failed", "error", err)
+		}
+	case "ndjson":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", "attachment; filename="+filename+".ndjson")
+		if err := h.reviews.ExportNDJSONByJobID(r.Context(), w, id.String()); err != nil {
+			logging.Logger(r.Context(), "ReviewHandler").Error("ExportNDJSONByJobID failed", "error", err)
+		}
+	default:
+		RenderError(w, http.StatusBadRequest, "Invalid format. Supported: csv, ndjson")
+	}
+}
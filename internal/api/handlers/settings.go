@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// SettingsServiceInterface defines the settings service methods
+type SettingsServiceInterface interface {
+	Get(ctx context.Context) (*domain.Settings, error)
+	JobDefaults(ctx context.Context) (*domain.JobDefaults, error)
+	SetJobDefaults(ctx context.Context, cfg domain.JobTemplateConfig) (*domain.JobDefaults, error)
+	RatingPrior(ctx context.Context) (*domain.RatingPrior, error)
+	SetRatingPrior(ctx context.Context, mean float64, weight int) (*domain.RatingPrior, error)
+}
+
+// SettingsHandler handles the server-side settings HTTP requests
+type SettingsHandler struct {
+	settings SettingsServiceInterface
+}
+
+// NewSettingsHandler creates a new SettingsHandler
+func NewSettingsHandler(settings SettingsServiceInterface) *SettingsHandler {
+	return &SettingsHandler{
+		settings: settings,
+	}
+}
+
+// Get handles GET /api/v2/settings
+func (h *SettingsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	settings, err := h.settings.Get(r.Context())
+	if err != nil {
+		RenderError(w, http.StatusInternalServerError, "Failed to get settings: "+err.Error())
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, settings)
+}
+
+// GetJobDefaults handles GET /api/v2/settings/job-defaults
+func (h *SettingsHandler) GetJobDefaults(w http.ResponseWriter, r *http.Request) {
+	defaults, err := h.settings.JobDefaults(r.Context())
+	if err != nil {
+		RenderError(w, http.StatusInternalServerError, "Failed to get job defaults: "+err.Error())
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, defaults)
+}
+
+// PutJobDefaults handles PUT /api/v2/settings/job-defaults. The body is the
+// partial job config, checked with the rules of a create request.
+func (h *SettingsHandler) PutJobDefaults(w http.ResponseWriter, r *http.Request) {
+	var cfg domain.JobTemplateConfig
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := validateJobDefaults(cfg); err != nil {
+		apiErr := validationAPIError(err)
+		RenderJSON(w, apiErr.Code, apiErr)
+		return
+	}
+
+	defaults, err := h.settings.SetJobDefaults(r.Context(), cfg)
+	if err != nil {
+		RenderError(w, http.StatusInternalServerError, "Failed to save job defaults: "+err.Error())
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, defaults)
+}
+
+// GetRatingPrior handles GET /api/v2/settings/rating-prior
+func (h *SettingsHandler) GetRatingPrior(w http.ResponseWriter, r *http.Request) {
+	prior, err := h.settings.RatingPrior(r.Context())
+	if err != nil {
+		RenderError(w, http.StatusInternalServerError, "Failed to get rating prior: "+err.Error())
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, prior)
+}
+
+// PutRatingPrior handles PUT /api/v2/settings/rating-prior
+func (h *SettingsHandler) PutRatingPrior(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Mean   float64 `json:"mean" validate:"gte=1,lte=5"`
+		Weight int     `json:"weight" validate:"gte=0,lte=100000"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	verr := &domain.ValidationError{}
+	verr.Check("", &req)
+	if RenderValidationError(w, verr.Err()) {
+		return
+	}
+
+	prior, err := h.settings.SetRatingPrior(r.Context(), req.Mean, req.Weight)
+	if err != nil {
+		RenderError(w, http.StatusInternalServerError, "Failed to save rating prior: "+err.Error())
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, prior)
+}
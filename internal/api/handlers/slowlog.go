@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/slowlog"
+)
+
+// SlowLogHandler exposes the slow request and query log
+type SlowLogHandler struct {
+	log *slowlog.Log
+}
+
+// NewSlowLogHandler creates a new SlowLogHandler
+func NewSlowLogHandler(log *slowlog.Log) *SlowLogHandler {
+	return &SlowLogHandler{log: log}
+}
+
+// slowLogThresholds is how the thresholds are rendered and changed, as
+// durations like "2s" or "500ms"; "0" turns a kind off
+type slowLogThresholds struct {
+	HTTP string `json:"http_threshold"`
+	DB   string `json:"db_threshold"`
+}
+
+// slowLogResponse is the body of GET /api/v2/admin/slowlog
+type slowLogResponse struct {
+	slowLogThresholds
+	Entries []slowlog.Entry `json:"entries"`
+}
+
+// SlowLog handles /api/v2/admin/slowlog. GET returns the thresholds and
+// the latest slow operations, newest first; PATCH changes the thresholds
+// given in the body.
+func (h *SlowLogHandler) SlowLog(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		RenderJSON(w, http.StatusOK, slowLogResponse{
+			slowLogThresholds: h.thresholds(),
+			Entries:           h.log.Entries(),
+		})
+	case http.MethodPatch:
+		var req slowLogThresholds
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+
+		t := h.log.Thresholds()
+		for _, field := range []struct {
+			name  string
+			value string
+			into  *time.Duration
+		}{
+			{"http_threshold", req.HTTP, &t.HTTP},
+			{"db_threshold", req.DB, &t.DB},
+		} {
+			if field.value == "" {
+				continue
+			}
+			d, err := time.ParseDuration(field.value)
+			if err != nil || d < 0 {
+				RenderError(w, http.StatusBadRequest, "Invalid "+field.name+": "+field.value)
+				return
+			}
+			*field.into = d
+		}
+		h.log.SetThresholds(t)
+
+		RenderJSON(w, http.StatusOK, h.thresholds())
+	default:
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (h *SlowLogHandler) thresholds() slowLogThresholds {
+	t := h.log.Thresholds()
+	return slowLogThresholds{HTTP: t.HTTP.String(), DB: t.DB.String()}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// SpawnerStatusProvider reports the worker auto-scaler state
+type SpawnerStatusProvider interface {
+	Status() *domain.SpawnerStatus
+}
+
+// SpawnerHandler handles worker auto-scaling HTTP requests
+type SpawnerHandler struct {
+	scaler SpawnerStatusProvider
+}
+
+// NewSpawnerHandler creates a new SpawnerHandler
+func NewSpawnerHandler(scaler SpawnerStatusProvider) *SpawnerHandler {
+	return &SpawnerHandler{
+		scaler: scaler,
+	}
+}
+
+// Status handles GET /api/v2/spawner/status
+func (h *SpawnerHandler) Status(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, h.scaler.Status())
+}
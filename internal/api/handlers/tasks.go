@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// SeedTaskServiceInterface defines the seed task service methods
+type SeedTaskServiceInterface interface {
+	List(ctx context.Context, jobID uuid.UUID, params domain.SeedTaskListParams) (*domain.SeedTaskCounts, []*domain.SeedTask, int, error)
+}
+
+// SeedTaskHandler exposes the seed tasks of jobs bridged to DSN workers
+type SeedTaskHandler struct {
+	tasks SeedTaskServiceInterface
+}
+
+// NewSeedTaskHandler creates a new SeedTaskHandler
+func NewSeedTaskHandler(tasks SeedTaskServiceInterface) *SeedTaskHandler {
+	return &SeedTaskHandler{
+		tasks: tasks,
+	}
+}
+
+// ListByJobID handles GET /api/v2/jobs/{id}/tasks?status=&page=&limit=
+//
+// counts always covers every task of the job; status only filters data.
+func (h *SeedTaskHandler) ListByJobID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := parseJobID(r)
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	status := domain.SeedTaskStatus(r.URL.Query().Get("status"))
+	if status != "" && !status.IsValid() {
+		RenderError(w, http.StatusBadRequest, "status must be one of new, queued, ok, failed")
+		return
+	}
+
+	page := 1
+	limit := 50
+
+	if p := r.URL.Query().Get("page"); p != "" {
+		if val, err := strconv.Atoi(p); err == nil && val > 0 {
+			page = val
+		}
+	}
+
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if val, err := strconv.Atoi(l); err == nil && val > 0 && val <= 500 {
+			limit = val
+		}
+	}
+
+	counts, tasks, total, err := h.tasks.List(r.Context(), id, domain.SeedTaskListParams{
+		Status: status,
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			RenderError(w, http.StatusNotFound, "Job not found")
+			return
+		}
+		logging.Logger(r.Context(), "SeedTaskHandler").Error("ListByJobID failed", "error", err)
+		RenderError(w, http.StatusInternalServerError, "Failed to fetch seed tasks")
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, map[string]interface{}{
+		"counts": counts,
+		"data":   tasks,
+		"meta": map[string]interface{}{
+			"page":        page,
+			"per_page":    limit,
+			"total":       total,
+			"total_pages": (total + limit - 1) / limit,
+		},
+	})
+}
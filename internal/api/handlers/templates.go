@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// JobTemplateServiceInterface defines the job template service methods
+type JobTemplateServiceInterface interface {
+	Create(ctx context.Context, req *domain.JobTemplateRequest) (*domain.JobTemplate, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.JobTemplate, error)
+	List(ctx context.Context) ([]*domain.JobTemplate, error)
+	Update(ctx context.Context, id uuid.UUID, req *domain.JobTemplateRequest) (*domain.JobTemplate, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	RecordUsage(ctx context.Context, id uuid.UUID) error
+}
+
+// TemplateHandler handles job template HTTP requests
+type TemplateHandler struct {
+	templates JobTemplateServiceInterface
+}
+
+// NewTemplateHandler creates a new TemplateHandler
+func NewTemplateHandler(templates JobTemplateServiceInterface) *TemplateHandler {
+	return &TemplateHandler{
+		templates: templates,
+	}
+}
+
+// Create handles POST /api/v2/templates
+func (h *TemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req domain.JobTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	tmpl, err := h.templates.Create(r.Context(), &req)
+	if err != nil {
+		renderTemplateError(w, err, "Failed to create template")
+		return
+	}
+
+	RenderJSON(w, http.StatusCreated, tmpl)
+}
+
+// List handles GET /api/v2/templates
+func (h *TemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	templates, err := h.templates.List(r.Context())
+	if err != nil {
+		RenderError(w, http.StatusInternalServerError, "Failed to list templates: "+err.Error())
+		return
+	}
+
+	if templates == nil {
+		templates = []*domain.JobTemplate{}
+	}
+
+	RenderJSON(w, http.StatusOK, map[string]interface{}{
+		"data": templates,
+	})
+}
+
+// GetByID handles GET /api/v2/templates/{id}
+func (h *TemplateHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	tmpl, err := h.templates.GetByID(r.Context(), id)
+	if err != nil {
+		renderTemplateError(w, err, "Failed to retrieve template")
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, tmpl)
+}
+
+// Update handles PUT /api/v2/templates/{id}
+func (h *TemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	var req domain.JobTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	tmpl, err := h.templates.Update(r.Context(), id, &req)
+	if err != nil {
+		renderTemplateError(w, err, "Failed to update template")
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, tmpl)
+}
+
+// Delete handles DELETE /api/v2/templates/{id}
+func (h *TemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		RenderError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	if err := h.templates.Delete(r.Context(), id); err != nil {
+		renderTemplateError(w, err, "Failed to delete template")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// renderTemplateError maps template service errors to HTTP responses
+func renderTemplateError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrTemplateNotFound):
+		RenderError(w, http.StatusNotFound, "Template not found")
+	case errors.Is(err, service.ErrTemplateNameRequired):
+		RenderError(w, http.StatusBadRequest, err.Error())
+	default:
+		RenderError(w, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/cache"
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// defaultTimeSeriesRange is the range of a time series without from
+const defaultTimeSeriesRange = 30 * 24 * time.Hour
+
+// TimeSeriesServiceInterface defines the time series service methods
+type TimeSeriesServiceInterface interface {
+	TimeSeries(ctx context.Context, q domain.TimeSeriesQuery) (*domain.TimeSeries, error)
+}
+
+// TimeSeriesHandler serves the dashboard trend charts
+type TimeSeriesHandler struct {
+	series TimeSeriesServiceInterface
+	cache  cache.Cache
+}
+
+// NewTimeSeriesHandler creates a new TimeSeriesHandler. Answers are cached
+// in c per query for cache.TTLTimeSeries.
+func NewTimeSeriesHandler(series TimeSeriesServiceInterface, c cache.Cache) *TimeSeriesHandler {
+	if c == nil {
+		c = cache.NewNoOpCache()
+	}
+
+	return &TimeSeriesHandler{
+		series: series,
+		cache:  c,
+	}
+}
+
+// TimeSeries handles GET /api/v2/stats/timeseries?metric=&interval=&from=&to=&group_by=&max_series=
+//
+// from and to are YYYY-MM-DD or RFC 3339 in UTC; a date to includes the
+// whole day. The range defaults to the 30 days up to the end of the
+// current interval.
+func (h *TimeSeriesHandler) TimeSeries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+
+	q := domain.TimeSeriesQuery{
+		Metric:   query.Get("metric"),
+		Interval: query.Get("interval"),
+		GroupBy:  query.Get("group_by"),
+	}
+	if q.Interval == "" {
+		q.Interval = domain.IntervalDay
+	}
+
+	if v := query.Get("max_series"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			RenderError(w, http.StatusBadRequest, "max_series must be a positive number")
+			return
+		}
+		q.MaxSeries = n
+	}
+
+	var err error
+	if q.To, err = parseTimeSeriesBound(query.Get("to"), true); err != nil {
+		RenderError(w, http.StatusBadRequest, "to "+err.Error())
+		return
+	}
+	if q.To.IsZero() {
+		// The end of the current interval keeps the cache key stable
+		q.To = domain.NextInterval(domain.TruncateInterval(time.Now(), q.Interval), q.Interval)
+	}
+
+	if q.From, err = parseTimeSeriesBound(query.Get("from"), false); err != nil {
+		RenderError(w, http.StatusBadRequest, "from "+err.Error())
+		return
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-defaultTimeSeriesRange)
+	}
+
+	ctx := r.Context()
+	logger := logging.Logger(ctx, "TimeSeriesHandler")
+
+	cacheKey := fmt.Sprintf("%s:%s:%s:%d:%d:%s:%d", cache.KeyPrefixDashboardTimeSeries,
+		q.Metric, q.Interval, q.From.Unix(), q.To.Unix(), q.GroupBy, q.MaxSeries)
+
+	if cached, err := h.cache.Get(ctx, cacheKey); err == nil && cached != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Cache", "HIT")
+		w.WriteHeader(http.StatusOK)
+		w.Write(cached)
+		return
+	}
+
+	ts, err := h.series.TimeSeries(ctx, q)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTimeSeries) {
+			RenderError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		logger.Error("time series failed", "metric", q.Metric, "error", err)
+		RenderError(w, http.StatusInternalServerError, "Failed to get time series")
+		return
+	}
+
+	if data, err := json.Marshal(ts); err == nil {
+		if cacheErr := h.cache.Set(ctx, cacheKey, data, cache.TTLTimeSeries); cacheErr != nil {
+			logger.Warn("failed to cache time series", "error", cacheErr)
+		}
+	}
+
+	w.Header().Set("X-Cache", "MISS")
+	RenderJSON(w, http.StatusOK, ts)
+}
+
+// parseTimeSeriesBound parses a date or an RFC 3339 time. A date ending a
+// range is moved to the next midnight so that the day is included.
+func parseTimeSeriesBound(v string, end bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, errors.New("must be a date in YYYY-MM-DD format or an RFC 3339 time")
+	}
+
+	return t.UTC(), nil
+}
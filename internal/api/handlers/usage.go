@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// tenantHeader attributes jobs created with the legacy API token
+const tenantHeader = "X-Tenant"
+
+// UsageServiceInterface defines the usage service methods
+type UsageServiceInterface interface {
+	Summary(ctx context.Context, filter domain.UsageFilter) ([]*domain.TenantUsage, error)
+}
+
+// UsageHandler reports usage per API key or tenant
+type UsageHandler struct {
+	usage UsageServiceInterface
+}
+
+// NewUsageHandler creates a new UsageHandler
+func NewUsageHandler(usage UsageServiceInterface) *UsageHandler {
+	return &UsageHandler{
+		usage: usage,
+	}
+}
+
+// Summary handles GET /api/v2/usage?from=&to=
+//
+// Dates are YYYY-MM-DD in UTC and default to the current month. An API key
+// only sees its own usage; the admin token sees every tenant unless it
+// passes tenant=.
+func (h *UsageHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	now := time.Now().UTC()
+	filter := domain.UsageFilter{
+		Tenant: r.URL.Query().Get("tenant"),
+		From:   domain.QuotaPeriodStart(now),
+		To:     now.Truncate(24 * time.Hour),
+	}
+
+	if key := domain.APIKeyFromContext(r.Context()); key != nil {
+		filter.Tenant = key.ID.String()
+	}
+
+	for param, dst := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		v := r.URL.Query().Get(param)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			RenderError(w, http.StatusBadRequest, param+" must be a date in YYYY-MM-DD format")
+			return
+		}
+		*dst = t
+	}
+
+	usage, err := h.usage.Summary(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidUsageRange) {
+			RenderError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		logging.Logger(r.Context(), "UsageHandler").Error("Summary failed", "error", err)
+		RenderError(w, http.StatusInternalServerError, "Failed to fetch usage")
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, map[string]interface{}{
+		"from": filter.From.Format(time.DateOnly),
+		"to":   filter.To.Format(time.DateOnly),
+		"data": usage,
+	})
+}
+
+// requestTenant returns the tenant a request is accounted to: the API key
+// that authenticated it, or the X-Tenant header
+func requestTenant(r *http.Request) string {
+	if key := domain.APIKeyFromContext(r.Context()); key != nil {
+		return key.ID.String()
+	}
+	return strings.TrimSpace(r.Header.Get(tenantHeader))
+}
+
+// quotaExceededResponse is the 429 body for a used-up quota
+type quotaExceededResponse struct {
+	APIError
+	Quota *domain.QuotaExceededError `json:"quota"`
+}
+
+// renderQuotaExceeded renders a 429 with the quota detail if err is a
+// *domain.QuotaExceededError and reports whether it did
+func renderQuotaExceeded(w http.ResponseWriter, err error) bool {
+	var quotaErr *domain.QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		return false
+	}
+
+	w.Header().Set("Retry-After", quotaErr.ResetsAt.UTC().Format(http.TimeFormat))
+	RenderJSON(w, http.StatusTooManyRequests, quotaExceededResponse{
+		APIError: APIError{
+			Code:    http.StatusTooManyRequests,
+			Message: err.Error(),
+		},
+		Quota: quotaErr,
+	})
+
+	return true
+}
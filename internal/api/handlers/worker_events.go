@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/events"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// WorkerEventHandler streams the state of the worker fleet as Server-Sent
+// Events
+type WorkerEventHandler struct {
+	workers WorkerServiceInterface
+	events  events.WorkerSubscriber
+}
+
+// NewWorkerEventHandler creates a new WorkerEventHandler
+func NewWorkerEventHandler(workers WorkerServiceInterface, sub events.WorkerSubscriber) *WorkerEventHandler {
+	return &WorkerEventHandler{
+		workers: workers,
+		events:  sub,
+	}
+}
+
+// Stream handles GET /api/v2/workers/events
+//
+// Every known worker is sent first: a worker_update with all its fields, or
+// a worker_offline. Heartbeats then produce worker_update events holding
+// only the fields that changed, and workers the heartbeat monitor marks
+// offline a worker_offline.
+func (h *WorkerEventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Subscribe before listing so no heartbeat in between is lost
+	ch, unsubscribe := h.events.SubscribeWorkers()
+	defer unsubscribe()
+
+	workers, err := h.workers.List(r.Context(), domain.WorkerListParams{})
+	if err != nil {
+		logging.Logger(r.Context(), "WorkerEventHandler").Error("List failed", "error", err)
+		RenderError(w, http.StatusInternalServerError, "Failed to list workers")
+		return
+	}
+
+	rc := openStream(w, r, "WorkerEventHandler")
+	deltas := events.NewWorkerDeltas()
+
+	for _, worker := range workers {
+		ev := events.WorkerEvent{Type: events.TypeWorkerOffline, WorkerID: worker.ID}
+		if worker.Status != domain.WorkerStatusOffline {
+			ev = events.WorkerEvent{Type: events.TypeWorkerUpdate, WorkerID: worker.ID, State: workerState(worker)}
+		}
+
+		if err := writeWorkerEvent(w, deltas, ev); err != nil {
+			return
+		}
+	}
+
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if err := writeKeepAlive(w, rc); err != nil {
+				return
+			}
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := writeWorkerEvent(w, deltas, ev); err != nil {
+				return
+			}
+
+			// A large fleet beats continuously: write what is already
+			// queued and flush once
+			if !drainWorkerEvents(w, deltas, ch) {
+				_ = rc.Flush()
+				return
+			}
+
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// drainWorkerEvents writes the events waiting in ch. It returns false once
+// ch is closed or a write fails.
+func drainWorkerEvents(w http.ResponseWriter, deltas *events.WorkerDeltas, ch <-chan events.WorkerEvent) bool {
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return false
+			}
+			if err := writeWorkerEvent(w, deltas, ev); err != nil {
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
+
+// writeWorkerEvent writes what changed for the worker, if anything,
+// without flushing
+func writeWorkerEvent(w http.ResponseWriter, deltas *events.WorkerDeltas, ev events.WorkerEvent) error {
+	delta, err := deltas.Delta(ev)
+	if err != nil || delta == nil {
+		return err
+	}
+
+	data, err := json.Marshal(delta)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+	return err
+}
+
+// workerState is the heartbeat last stored for worker
+func workerState(worker *domain.Worker) *domain.WorkerHeartbeat {
+	state := &domain.WorkerHeartbeat{
+		WorkerID:     worker.ID,
+		Hostname:     worker.Hostname,
+		Status:       worker.Status,
+		CurrentJobID: worker.CurrentJobID,
+
+		RequestDelayMs: worker.RequestDelayMs,
+		BlockCount:     worker.BlockCount,
+
+		SeedJobsCompleted: worker.SeedJobsCompleted,
+		SeedJobsTotal:     worker.SeedJobsTotal,
+		JobPlacesScraped:  worker.JobPlacesScraped,
+		MemoryBytes:       worker.MemoryBytes,
+	}
+
+	if worker.CurrentJobName != nil {
+		state.CurrentJobName = *worker.CurrentJobName
+	}
+
+	return state
+}
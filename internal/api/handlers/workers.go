@@ -3,30 +3,36 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/events"
+	"github.com/sadewadee/google-scraper/internal/service"
 )
 
 // WorkerServiceInterface defines the worker service methods
 type WorkerServiceInterface interface {
 	Register(ctx context.Context, workerID string) (*domain.Worker, error)
-	Heartbeat(ctx context.Context, hb *domain.WorkerHeartbeat) error
+	Heartbeat(ctx context.Context, hb *domain.WorkerHeartbeat) (*domain.WorkerDirectives, error)
 	List(ctx context.Context, params domain.WorkerListParams) ([]*domain.Worker, error)
 	GetByID(ctx context.Context, id string) (*domain.Worker, error)
 	GetStats(ctx context.Context) (*domain.WorkerStats, error)
 	ClaimJob(ctx context.Context, workerID string) (*domain.Job, error)
 	ReleaseJob(ctx context.Context, jobID uuid.UUID, workerID string) error
-	CompleteJob(ctx context.Context, jobID uuid.UUID, workerID string, placesScraped int) error
-	FailJob(ctx context.Context, jobID uuid.UUID, workerID string, errMsg string) error
+	CompleteJob(ctx context.Context, jobID uuid.UUID, workerID string, placesScraped, dedupedPlaces int, failedKeywords []string, stoppedReason string, outputErrors []string, parseReport *domain.JobParseReport) error
+	FailJob(ctx context.Context, jobID uuid.UUID, workerID string, dedupedPlaces int, errMsg string, errorCode string, failedKeywords []string) error
 	Unregister(ctx context.Context, workerID string) error
+	Drain(ctx context.Context, workerID string, timeout time.Duration) (*domain.Worker, error)
 }
 
 // WorkerHandler handles worker-related HTTP requests
 type WorkerHandler struct {
-	workers WorkerServiceInterface
+	workers   WorkerServiceInterface
+	claimable events.ClaimableSubscriber // Wakes held claims (optional)
 }
 
 // NewWorkerHandler creates a new WorkerHandler
@@ -36,29 +42,61 @@ func NewWorkerHandler(workers WorkerServiceInterface) *WorkerHandler {
 	}
 }
 
+// SetClaimable lets claims with ?wait wait for a job to be announced
+// rather than checking again on a timer
+func (h *WorkerHandler) SetClaimable(sub events.ClaimableSubscriber) {
+	h.claimable = sub
+}
+
+// claimRecheckInterval is how often a held claim checks for a job without
+// an announcement, for jobs queued by another manager without Redis
+const claimRecheckInterval = 5 * time.Second
+
 // RegisterRequest represents the request body for worker registration
 type RegisterRequest struct {
-	WorkerID string `json:"worker_id"`
+	WorkerID string `json:"worker_id" validate:"required,max=255"`
 }
 
 // HeartbeatRequest represents the request body for worker heartbeat
 type HeartbeatRequest struct {
-	WorkerID     string              `json:"worker_id"`
-	Hostname     string              `json:"hostname,omitempty"`
-	Status       domain.WorkerStatus `json:"status"`
-	CurrentJobID *uuid.UUID          `json:"current_job_id,omitempty"`
+	WorkerID       string              `json:"worker_id"`
+	Hostname       string              `json:"hostname,omitempty"`
+	Status         domain.WorkerStatus `json:"status"`
+	CurrentJobID   *uuid.UUID          `json:"current_job_id,omitempty"`
+	CurrentJobName string              `json:"current_job_name,omitempty"`
+
+	RequestDelayMs int64 `json:"request_delay_ms"`
+	BlockCount     int64 `json:"block_count"`
+
+	SeedJobsCompleted int   `json:"seed_jobs_completed"`
+	SeedJobsTotal     int   `json:"seed_jobs_total"`
+	JobPlacesScraped  int   `json:"job_places_scraped"`
+	MemoryBytes       int64 `json:"memory_bytes"`
+
+	Jobs []domain.WorkerJob `json:"jobs,omitempty"` // Every job the worker runs
 }
 
 // CompleteJobRequest represents the request body for completing a job
 type CompleteJobRequest struct {
-	JobID         uuid.UUID `json:"job_id"`
-	PlacesScraped int       `json:"places_scraped"`
+	JobID          uuid.UUID `json:"job_id"`
+	PlacesScraped  int       `json:"places_scraped"`
+	DedupedPlaces  int       `json:"deduped_places,omitempty"` // Places skipped as already scraped
+	FailedKeywords []string  `json:"failed_keywords,omitempty"`
+	StoppedReason  string    `json:"stopped_reason,omitempty"`
+	OutputErrors   []string  `json:"output_errors,omitempty"` // Job outputs that could not be written
+
+	// ParseReport counts the fields the run could not read from Google's
+	// place data
+	ParseReport *domain.JobParseReport `json:"parse_report,omitempty"`
 }
 
 // FailJobRequest represents the request body for failing a job
 type FailJobRequest struct {
-	JobID   uuid.UUID `json:"job_id"`
-	Message string    `json:"message"`
+	JobID          uuid.UUID `json:"job_id"`
+	Message        string    `json:"message"`
+	ErrorCode      string    `json:"error_code,omitempty"` // One of the domain.JobError codes
+	FailedKeywords []string  `json:"failed_keywords,omitempty"`
+	DedupedPlaces  int       `json:"deduped_places,omitempty"`
 }
 
 // ReleaseJobRequest represents the request body for releasing a job
@@ -79,8 +117,9 @@ func (h *WorkerHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.WorkerID == "" {
-		RenderError(w, http.StatusBadRequest, "Worker ID is required")
+	verr := &domain.ValidationError{}
+	verr.Check("", &req)
+	if RenderValidationError(w, verr.Err()) {
 		return
 	}
 
@@ -112,21 +151,42 @@ func (h *WorkerHandler) Heartbeat(w http.ResponseWriter, r *http.Request) {
 	}
 
 	hb := &domain.WorkerHeartbeat{
-		WorkerID:     req.WorkerID,
-		Hostname:     req.Hostname,
-		Status:       req.Status,
-		CurrentJobID: req.CurrentJobID,
+		WorkerID:       req.WorkerID,
+		Hostname:       req.Hostname,
+		Status:         req.Status,
+		CurrentJobID:   req.CurrentJobID,
+		CurrentJobName: req.CurrentJobName,
+
+		RequestDelayMs: req.RequestDelayMs,
+		BlockCount:     req.BlockCount,
+
+		SeedJobsCompleted: req.SeedJobsCompleted,
+		SeedJobsTotal:     req.SeedJobsTotal,
+		JobPlacesScraped:  req.JobPlacesScraped,
+		MemoryBytes:       req.MemoryBytes,
+
+		Jobs: req.Jobs,
 	}
 
-	if err := h.workers.Heartbeat(r.Context(), hb); err != nil {
+	directives, err := h.workers.Heartbeat(r.Context(), hb)
+	if err != nil {
 		RenderError(w, http.StatusInternalServerError, "Failed to update heartbeat: "+err.Error())
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	// Workers that predate directives expect 204, and only get a body when
+	// asked to do something
+	if directives == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	RenderJSON(w, http.StatusOK, directives)
 }
 
-// ClaimJob handles POST /api/v2/workers/{id}/claim
+// ClaimJob handles POST /api/v2/workers/{id}/claim. With ?wait= (a
+// duration such as 30s, at most domain.MaxClaimWait) the request is held
+// until a job can be claimed or the wait runs out.
 func (h *WorkerHandler) ClaimJob(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -139,7 +199,18 @@ func (h *WorkerHandler) ClaimJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	job, err := h.workers.ClaimJob(r.Context(), workerID)
+	var wait time.Duration
+	if v := r.URL.Query().Get("wait"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			RenderError(w, http.StatusBadRequest, "Invalid wait, expected a duration such as 30s")
+			return
+		}
+		wait = min(d, domain.MaxClaimWait)
+	}
+	w.Header().Set(domain.ClaimWaitHeader, wait.String())
+
+	job, err := h.claimJob(r.Context(), workerID, wait)
 	if err != nil {
 		RenderError(w, http.StatusInternalServerError, "Failed to claim job: "+err.Error())
 		return
@@ -158,6 +229,49 @@ func (h *WorkerHandler) ClaimJob(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// claimJob claims a job for a worker, trying again whenever a job is
+// announced until wait runs out. Held claims woken together race in
+// ClaimJob, whose row lock lets one of them have each job; the others
+// keep waiting.
+func (h *WorkerHandler) claimJob(ctx context.Context, workerID string, wait time.Duration) (*domain.Job, error) {
+	if wait <= 0 {
+		return h.workers.ClaimJob(ctx, workerID)
+	}
+
+	// Subscribed before the first try, so a job announced in between is
+	// not missed
+	var announced <-chan struct{}
+	if h.claimable != nil {
+		ch, unsubscribe := h.claimable.SubscribeClaimable()
+		defer unsubscribe()
+		announced = ch
+	}
+
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	recheck := time.NewTicker(claimRecheckInterval)
+	defer recheck.Stop()
+
+	for {
+		job, err := h.workers.ClaimJob(ctx, workerID)
+		if err != nil || job != nil {
+			return job, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-deadline.C:
+			return nil, nil
+		case _, ok := <-announced:
+			if !ok {
+				announced = nil
+			}
+		case <-recheck.C:
+		}
+	}
+}
+
 // CompleteJob handles POST /api/v2/workers/{id}/complete
 func (h *WorkerHandler) CompleteJob(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -177,7 +291,7 @@ func (h *WorkerHandler) CompleteJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.workers.CompleteJob(r.Context(), req.JobID, workerID, req.PlacesScraped); err != nil {
+	if err := h.workers.CompleteJob(r.Context(), req.JobID, workerID, req.PlacesScraped, req.DedupedPlaces, req.FailedKeywords, req.StoppedReason, req.OutputErrors, req.ParseReport); err != nil {
 		RenderError(w, http.StatusInternalServerError, "Failed to complete job: "+err.Error())
 		return
 	}
@@ -204,7 +318,7 @@ func (h *WorkerHandler) FailJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.workers.FailJob(r.Context(), req.JobID, workerID, req.Message); err != nil {
+	if err := h.workers.FailJob(r.Context(), req.JobID, workerID, req.DedupedPlaces, req.Message, req.ErrorCode, req.FailedKeywords); err != nil {
 		RenderError(w, http.StatusInternalServerError, "Failed to fail job: "+err.Error())
 		return
 	}
@@ -326,3 +440,43 @@ func (h *WorkerHandler) Unregister(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Drain handles POST /api/v2/workers/{id}/drain
+//
+// The worker stops taking jobs, finishes its current one and exits. With
+// ?timeout= (a duration such as 30m) its job is released back to pending
+// if it is still running by then.
+func (h *WorkerHandler) Drain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	workerID := r.PathValue("id")
+	if workerID == "" {
+		RenderError(w, http.StatusBadRequest, "Worker ID is required")
+		return
+	}
+
+	var timeout time.Duration
+	if v := r.URL.Query().Get("timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			RenderError(w, http.StatusBadRequest, "Invalid timeout, expected a positive duration such as 30m")
+			return
+		}
+		timeout = d
+	}
+
+	worker, err := h.workers.Drain(r.Context(), workerID, timeout)
+	if err != nil {
+		if errors.Is(err, service.ErrWorkerNotFound) {
+			RenderError(w, http.StatusNotFound, "Worker not found")
+			return
+		}
+		RenderError(w, http.StatusInternalServerError, "Failed to drain worker: "+err.Error())
+		return
+	}
+
+	RenderJSON(w, http.StatusAccepted, worker)
+}
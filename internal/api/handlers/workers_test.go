@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/events"
+)
+
+// queueWorkers hands each pending job to one claim, like the row lock of
+// the repositories' ClaimJob
+type queueWorkers struct {
+	WorkerServiceInterface
+
+	mu      sync.Mutex
+	pending []*domain.Job
+}
+
+func (q *queueWorkers) ClaimJob(_ context.Context, _ string) (*domain.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.pending) == 0 {
+		return nil, nil
+	}
+	job := q.pending[0]
+	q.pending = q.pending[1:]
+	return job, nil
+}
+
+func (q *queueWorkers) add(job *domain.Job) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, job)
+}
+
+func claim(t *testing.T, h *WorkerHandler, query string) (*domain.Job, http.Header) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/workers/w1/claim"+query, nil)
+	req.SetPathValue("id", "w1")
+	rec := httptest.NewRecorder()
+	h.ClaimJob(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp struct {
+		Job *domain.Job `json:"job"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return resp.Job, rec.Header()
+}
+
+func TestClaimJobWait(t *testing.T) {
+	broker := events.NewMemoryBroker()
+	defer broker.Close()
+
+	workers := &queueWorkers{}
+	h := NewWorkerHandler(workers)
+	h.SetClaimable(broker)
+
+	t.Run("answers at once without wait", func(t *testing.T) {
+		job, header := claim(t, h, "")
+		assert.Nil(t, job)
+		assert.Equal(t, "0s", header.Get(domain.ClaimWaitHeader))
+	})
+
+	t.Run("times out with no job", func(t *testing.T) {
+		start := time.Now()
+		job, header := claim(t, h, "?wait=50ms")
+		assert.Nil(t, job)
+		assert.Equal(t, "50ms", header.Get(domain.ClaimWaitHeader))
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("held claims share an announced job", func(t *testing.T) {
+		const waiters = 5
+
+		var wg sync.WaitGroup
+		got := make(chan *domain.Job, waiters)
+		for range waiters {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				job, _ := claim(t, h, "?wait=300ms")
+				got <- job
+			}()
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		job := &domain.Job{ID: uuid.New()}
+		workers.add(job)
+		broker.PublishClaimable(context.Background())
+
+		wg.Wait()
+		close(got)
+
+		var claimed []uuid.UUID
+		for j := range got {
+			if j != nil {
+				claimed = append(claimed, j.ID)
+			}
+		}
+		assert.Equal(t, []uuid.UUID{job.ID}, claimed)
+	})
+
+	t.Run("rejects an invalid wait", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/workers/w1/claim?wait=soon", nil)
+		req.SetPathValue("id", "w1")
+		rec := httptest.NewRecorder()
+		h.ClaimJob(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
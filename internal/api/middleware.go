@@ -1,12 +1,22 @@
 package api
 
 import (
+	"context"
 	"crypto/subtle"
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/internal/slowlog"
 )
 
 // renderError renders an error response (local to this package)
@@ -19,32 +29,75 @@ func renderError(w http.ResponseWriter, code int, message string) {
 	})
 }
 
-// Logger logs HTTP requests
+// RequestIDHeader carries the ID that correlates the log lines of a request
+const RequestIDHeader = "X-Request-ID"
+
+// Logger gives every request an ID, taken from X-Request-ID when the client
+// sent a usable one, and a logger carrying it in the request context. The
+// ID is echoed in the response and the request is logged once it is served.
 func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		logger := logging.FromContext(r.Context()).With("request_id", requestID)
+		r = r.WithContext(logging.NewContext(r.Context(), logger))
+
 		// Wrap response writer to capture status
 		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
 
 		next.ServeHTTP(rw, r)
 
-		log.Printf(
-			"%s %s %d %s",
-			r.Method,
-			r.URL.Path,
-			rw.status,
-			time.Since(start),
+		// Probes hit these every few seconds
+		level := slog.LevelInfo
+		if isProbePath(r.URL.Path) {
+			level = slog.LevelDebug
+		}
+
+		logger.Log(r.Context(), level, "request served",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rw.status,
+			"duration_ms", logging.SinceMS(start),
 		)
 	})
 }
 
+// validRequestID accepts up to 128 printable ASCII characters without spaces
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func isProbePath(path string) bool {
+	switch path {
+	case "/health", "/api/v2/health", "/ready", "/api/v2/ready":
+		return true
+	}
+	return false
+}
+
 // Recovery recovers from panics
 func Recovery(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Printf("panic recovered: %v", err)
+				logging.FromContext(r.Context()).Error("panic recovered",
+					"panic", fmt.Sprint(err),
+					"stack", string(debug.Stack()),
+				)
 				renderError(w, http.StatusInternalServerError, "Internal server error")
 			}
 		}()
@@ -58,7 +111,8 @@ func CORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-Tenant, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		if r.Method == http.MethodOptions {
@@ -81,17 +135,39 @@ func SecurityHeaders(next http.Handler) http.Handler {
 	})
 }
 
+// APIKeyAuthenticator resolves a presented secret to a scoped API key
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, secret string) (*domain.APIKey, error)
+}
+
+// APIKeyFromContext returns the API key that authenticated the request.
+// It returns nil when the request used the legacy API_TOKEN or auth is disabled.
+func APIKeyFromContext(ctx context.Context) *domain.APIKey {
+	return domain.APIKeyFromContext(ctx)
+}
+
 // Auth middleware checks for API token
 // WARNING: If token is empty, authentication is DISABLED
 func Auth(token string) func(http.Handler) http.Handler {
+	return AuthWithKeys(token, nil)
+}
+
+// AuthWithKeys middleware accepts either the legacy API token, which has
+// full access, or a scoped API key resolved through keys (may be nil).
+// WARNING: If token is empty, authentication is DISABLED
+func AuthWithKeys(token string, keys APIKeyAuthenticator) func(http.Handler) http.Handler {
 	if token == "" {
-		log.Println("WARNING: API_TOKEN is not set - authentication is DISABLED. Set API_TOKEN environment variable for production use.")
+		slog.Warn("API_TOKEN is not set - authentication is DISABLED. Set API_TOKEN environment variable for production use.")
 	}
 
 	// Paths that don't require authentication
 	publicPaths := []string{
 		"/health",
 		"/api/v2/health",
+		"/ready",
+		"/api/v2/ready",
+		"/api/v2/openapi.json",
+		"/api/v2/docs",
 	}
 
 	return func(next http.Handler) http.Handler {
@@ -105,44 +181,229 @@ func Auth(token string) func(http.Handler) http.Handler {
 			}
 
 			if token == "" {
-				next.ServeHTTP(w, r)
+				next.ServeHTTP(w, r.WithContext(domain.ContextWithActor(r.Context(), domain.ActorAnonymous)))
 				return
 			}
 
-			// Check Authorization header
-			authHeader := r.Header.Get("Authorization")
-			if authHeader != "" {
-				parts := strings.Split(authHeader, " ")
-				if len(parts) == 2 && parts[0] == "Bearer" {
-					if subtle.ConstantTimeCompare([]byte(parts[1]), []byte(token)) == 1 {
-						next.ServeHTTP(w, r)
-						return
-					}
+			credentials := requestCredentials(r)
+
+			// Legacy token has full access
+			for _, c := range credentials {
+				if subtle.ConstantTimeCompare([]byte(c), []byte(token)) == 1 {
+					next.ServeHTTP(w, r.WithContext(domain.ContextWithActor(r.Context(), domain.ActorAPIToken)))
+					return
 				}
 			}
 
-			// Check X-API-Key
-			apiKey := r.Header.Get("X-API-Key")
-			if apiKey != "" {
-				if subtle.ConstantTimeCompare([]byte(apiKey), []byte(token)) == 1 {
-					next.ServeHTTP(w, r)
-					return
+			if keys == nil {
+				renderError(w, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
+
+			var key *domain.APIKey
+			for _, c := range credentials {
+				k, err := keys.Authenticate(r.Context(), c)
+				if err == nil {
+					key = k
+					break
 				}
 			}
+			if key == nil {
+				renderError(w, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
 
-			// Check query parameter
-			if qKey := r.URL.Query().Get("api_key"); qKey != "" {
-				if subtle.ConstantTimeCompare([]byte(qKey), []byte(token)) == 1 {
-					next.ServeHTTP(w, r)
+			scopes := requiredScopes(r)
+			for _, scope := range scopes {
+				if key.HasScope(scope) {
+					if key.ExportProfile != nil && !exportProfileAllows(r, scopes) {
+						renderError(w, http.StatusForbidden, "API key is restricted to export profile "+*key.ExportProfile+"; results are only served by the downloads")
+						return
+					}
+					ctx := domain.ContextWithAPIKey(r.Context(), key)
+					ctx = logging.With(ctx, "api_key_id", key.ID)
+					next.ServeHTTP(w, r.WithContext(ctx))
 					return
 				}
 			}
 
-			renderError(w, http.StatusUnauthorized, "Unauthorized")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"code":          http.StatusForbidden,
+				"message":       "Missing required scope: " + scopes[0],
+				"missing_scope": scopes[0],
+			})
+		})
+	}
+}
+
+// requestCredentials collects the secrets presented via Authorization
+// Bearer, X-API-Key and the api_key query parameter, in that order
+func requestCredentials(r *http.Request) []string {
+	var credentials []string
+
+	if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+		parts := strings.Split(authHeader, " ")
+		if len(parts) == 2 && parts[0] == "Bearer" && parts[1] != "" {
+			credentials = append(credentials, parts[1])
+		}
+	}
+
+	if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+		credentials = append(credentials, apiKey)
+	}
+
+	if qKey := r.URL.Query().Get("api_key"); qKey != "" {
+		credentials = append(credentials, qKey)
+	}
+
+	return credentials
+}
+
+// exportProfileAllows tells whether a key restricted to an export profile
+// may make a request. Places are only served by the downloads, which apply
+// the profile; every other read of them is refused.
+func exportProfileAllows(r *http.Request, scopes []string) bool {
+	path := r.URL.Path
+
+	switch {
+	case strings.HasSuffix(path, "/reviews/download"), strings.HasSuffix(path, "/results/sample"):
+		// Samples are listings in full, outside the profile
+		return false
+	case strings.HasSuffix(path, "/download"), path == "/api/v2/results/export", path == "/api/v2/results/columns":
+		return true
+	case strings.HasPrefix(path, "/api/v2/jobs/") && (strings.HasSuffix(path, "/diff") || strings.HasSuffix(path, "/report")):
+		// Changed values and the map of a job's places
+		return false
+	}
+
+	return !slices.Contains(scopes, domain.ScopeResultsRead)
+}
+
+// requiredScopes returns the scopes that grant access to a request.
+// Any one of them is sufficient; the first is reported when none match.
+func requiredScopes(r *http.Request) []string {
+	path := r.URL.Path
+	read := r.Method == http.MethodGet || r.Method == http.MethodHead
+
+	switch {
+	case path == "/api/v2/workers/events":
+		// The dashboard's fleet view
+		return []string{domain.ScopeJobsRead, domain.ScopeWorkers}
+	case strings.HasPrefix(path, "/api/v2/workers"):
+		return []string{domain.ScopeWorkers}
+	case strings.HasPrefix(path, "/api/v2/proxygate/lease"):
+		// Workers lease manager proxies and report those that fail
+		return []string{domain.ScopeWorkers}
+	case strings.HasPrefix(path, "/api/v2/jobs/"):
+		if r.Method == http.MethodDelete && r.URL.Query().Get("hard") == "true" {
+			// A purge cannot be undone
+			return []string{domain.ScopeAdmin}
+		}
+		if strings.HasSuffix(path, "/results") {
+			if read {
+				return []string{domain.ScopeResultsRead}
+			}
+			// Workers submit scraped results here
+			return []string{domain.ScopeWorkers}
+		}
+		if strings.HasSuffix(path, "/download") || strings.HasSuffix(path, "/reviews") || strings.HasSuffix(path, "/archive") ||
+			strings.HasSuffix(path, "/results/sample") || strings.HasSuffix(path, "/diff") {
+			return []string{domain.ScopeResultsRead}
+		}
+		if strings.HasSuffix(path, "/events") || strings.HasSuffix(path, "/tasks") || strings.HasSuffix(path, "/report") {
+			return []string{domain.ScopeJobsRead}
+		}
+		if read {
+			// Workers fetch job details when consuming from the queue
+			return []string{domain.ScopeJobsRead, domain.ScopeWorkers}
+		}
+		return []string{domain.ScopeJobsWrite}
+	case path == "/api/v2/jobs":
+		if r.URL.Query().Get("include_deleted") == "true" {
+			return []string{domain.ScopeAdmin}
+		}
+		if read {
+			return []string{domain.ScopeJobsRead}
+		}
+		return []string{domain.ScopeJobsWrite}
+	case strings.HasPrefix(path, "/api/v2/templates"):
+		if read {
+			return []string{domain.ScopeJobsRead}
+		}
+		return []string{domain.ScopeJobsWrite}
+	case strings.HasPrefix(path, "/api/v2/settings"):
+		// The job form pre-fills the job defaults; changing them is for
+		// admins
+		if read {
+			return []string{domain.ScopeJobsRead}
+		}
+		return []string{domain.ScopeAdmin}
+	case path == "/api/v2/stats", path == "/api/v2/stats/timeseries":
+		return []string{domain.ScopeJobsRead}
+	case path == "/api/v2/emails/validation-stats":
+		return []string{domain.ScopeJobsRead}
+	case path == "/api/v2/usage":
+		// Keys only see their own usage, see handlers.UsageHandler
+		return []string{domain.ScopeJobsRead}
+	case path == "/api/v2/results/duplicates/merge":
+		// Merging deletes listings across every job
+		return []string{domain.ScopeAdmin}
+	case strings.HasPrefix(path, "/api/v2/results"):
+		return []string{domain.ScopeResultsRead}
+	default:
+		// Key management, ProxyGate and anything unlisted
+		return []string{domain.ScopeAdmin}
+	}
+}
+
+// SlowLog records requests that take longer than the HTTP threshold of l.
+// It goes last in the chain, right around the mux, so the route pattern
+// the mux matched and the API key that authenticated are known.
+func SlowLog(l *slowlog.Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(rw, r)
+
+			elapsed := time.Since(start)
+			if !l.Slow(slowlog.KindHTTP, elapsed) {
+				return
+			}
+
+			name := r.Pattern
+			if name == "" {
+				name = r.URL.Path
+			}
+			caller := r.RemoteAddr
+			if key := APIKeyFromContext(r.Context()); key != nil {
+				caller = key.Name
+			}
+
+			l.Record(r.Context(), slowlog.Entry{
+				Kind:       slowlog.KindHTTP,
+				At:         start,
+				DurationMs: elapsed.Milliseconds(),
+				Name:       name,
+				Method:     r.Method,
+				Status:     rw.status,
+				Params:     slowLogParams(r),
+				Caller:     caller,
+			})
 		})
 	}
 }
 
+// slowLogParams returns the query string of r without credentials
+func slowLogParams(r *http.Request) string {
+	q := r.URL.Query()
+	q.Del("api_key")
+	return q.Encode()
+}
+
 // Chain chains multiple middlewares
 func Chain(h http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
 	for i := len(middlewares) - 1; i >= 0; i-- {
@@ -161,3 +422,9 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.status = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap exposes the underlying writer to http.ResponseController, which
+// streaming handlers use to flush
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -1,10 +1,15 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
 )
 
 func TestAuthentication(t *testing.T) {
@@ -93,3 +98,173 @@ func TestAuthentication(t *testing.T) {
 		})
 	}
 }
+
+type fakeAuthenticator map[string]*domain.APIKey
+
+func (f fakeAuthenticator) Authenticate(_ context.Context, secret string) (*domain.APIKey, error) {
+	if key, ok := f[secret]; ok {
+		return key, nil
+	}
+	return nil, errors.New("invalid api key")
+}
+
+func TestAuthWithKeysScopes(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	keys := fakeAuthenticator{
+		"reader": {Name: "reader", Scopes: []string{domain.ScopeJobsRead, domain.ScopeResultsRead}},
+		"worker": {Name: "worker", Scopes: []string{domain.ScopeWorkers}},
+		"writer": {Name: "writer", Scopes: []string{domain.ScopeJobsRead, domain.ScopeJobsWrite}},
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		key            string
+		expectedStatus int
+	}{
+		{"legacy token has full access", "POST", "/api/v2/apikeys", "secret123", http.StatusOK},
+		{"unknown key", "GET", "/api/v2/jobs", "nope", http.StatusUnauthorized},
+		{"reader can list jobs", "GET", "/api/v2/jobs", "reader", http.StatusOK},
+		{"reader cannot create jobs", "POST", "/api/v2/jobs", "reader", http.StatusForbidden},
+		{"reader cannot list deleted jobs", "GET", "/api/v2/jobs?include_deleted=true", "reader", http.StatusForbidden},
+		{"writer can delete jobs", "DELETE", "/api/v2/jobs/abc", "writer", http.StatusOK},
+		{"writer can restore jobs", "POST", "/api/v2/jobs/abc/restore", "writer", http.StatusOK},
+		{"writer cannot purge jobs", "DELETE", "/api/v2/jobs/abc?hard=true", "writer", http.StatusForbidden},
+		{"reader can download results", "GET", "/api/v2/jobs/abc/download", "reader", http.StatusOK},
+		{"reader can list reviews", "GET", "/api/v2/jobs/abc/reviews", "reader", http.StatusOK},
+		{"worker cannot list reviews", "GET", "/api/v2/jobs/abc/reviews", "worker", http.StatusForbidden},
+		{"reader can sample job results", "GET", "/api/v2/jobs/abc/results/sample", "reader", http.StatusOK},
+		{"worker cannot sample job results", "GET", "/api/v2/jobs/abc/results/sample", "worker", http.StatusForbidden},
+		{"writer cannot sample job results", "GET", "/api/v2/jobs/abc/results/sample", "writer", http.StatusForbidden},
+		{"reader can diff jobs", "GET", "/api/v2/jobs/abc/diff", "reader", http.StatusOK},
+		{"writer cannot diff jobs", "GET", "/api/v2/jobs/abc/diff", "writer", http.StatusForbidden},
+		{"worker cannot diff jobs", "GET", "/api/v2/jobs/abc/diff", "worker", http.StatusForbidden},
+		{"reader can stream job events", "GET", "/api/v2/jobs/abc/events", "reader", http.StatusOK},
+		{"worker cannot stream job events", "GET", "/api/v2/jobs/abc/events", "worker", http.StatusForbidden},
+		{"reader can stream worker events", "GET", "/api/v2/workers/events", "reader", http.StatusOK},
+		{"reader cannot list workers", "GET", "/api/v2/workers", "reader", http.StatusForbidden},
+		{"reader can list seed tasks", "GET", "/api/v2/jobs/abc/tasks", "reader", http.StatusOK},
+		{"worker cannot list seed tasks", "GET", "/api/v2/jobs/abc/tasks", "worker", http.StatusForbidden},
+		{"reader cannot retry failed searches", "POST", "/api/v2/jobs/abc/retry-failed", "reader", http.StatusForbidden},
+		{"reader cannot manage keys", "GET", "/api/v2/apikeys", "reader", http.StatusForbidden},
+		{"worker can claim", "POST", "/api/v2/workers/w1/claim", "worker", http.StatusOK},
+		{"worker can submit results", "POST", "/api/v2/jobs/abc/results", "worker", http.StatusOK},
+		{"worker can fetch job", "GET", "/api/v2/jobs/abc", "worker", http.StatusOK},
+		{"worker can fetch job to run", "GET", "/api/v2/workers/w1/jobs/abc", "worker", http.StatusOK},
+		{"reader cannot fetch job to run", "GET", "/api/v2/workers/w1/jobs/abc", "reader", http.StatusForbidden},
+		{"worker cannot read results", "GET", "/api/v2/results", "worker", http.StatusForbidden},
+		{"reader can export results across jobs", "POST", "/api/v2/results/export", "reader", http.StatusOK},
+		{"reader can list duplicates", "GET", "/api/v2/results/duplicates", "reader", http.StatusOK},
+		{"reader cannot merge duplicates", "POST", "/api/v2/results/duplicates/merge", "reader", http.StatusForbidden},
+		{"reader can read usage", "GET", "/api/v2/usage", "reader", http.StatusOK},
+		{"reader can read email validation stats", "GET", "/api/v2/emails/validation-stats", "reader", http.StatusOK},
+		{"worker cannot read usage", "GET", "/api/v2/usage", "worker", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-API-Key", tt.key)
+			w := httptest.NewRecorder()
+
+			AuthWithKeys("secret123", keys)(nextHandler).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if w.Code == http.StatusForbidden {
+				var body map[string]interface{}
+				if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+					t.Fatalf("failed to decode body: %v", err)
+				}
+				if body["missing_scope"] == "" || body["missing_scope"] == nil {
+					t.Errorf("expected missing_scope in body, got %v", body)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthWithKeysExportProfile(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	profile := "partners"
+	keys := fakeAuthenticator{
+		"partner": {Name: "partner", Scopes: []string{domain.ScopeJobsRead, domain.ScopeResultsRead}, ExportProfile: &profile},
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{"can download job results", "/api/v2/jobs/abc/download", http.StatusOK},
+		{"can download global results", "/api/v2/results/download", http.StatusOK},
+		{"can read export columns", "/api/v2/results/columns", http.StatusOK},
+		{"can list jobs", "/api/v2/jobs", http.StatusOK},
+		{"cannot list results", "/api/v2/results", http.StatusForbidden},
+		{"cannot list job results", "/api/v2/jobs/abc/results", http.StatusForbidden},
+		{"cannot download reviews", "/api/v2/jobs/abc/reviews/download", http.StatusForbidden},
+		{"cannot archive jobs", "/api/v2/jobs/abc/archive", http.StatusForbidden},
+		{"cannot diff jobs", "/api/v2/jobs/abc/diff", http.StatusForbidden},
+		{"cannot sample job results", "/api/v2/jobs/abc/results/sample", http.StatusForbidden},
+		{"cannot sample results", "/api/v2/results/sample", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("X-API-Key", "partner")
+			w := httptest.NewRecorder()
+
+			AuthWithKeys("secret123", keys)(nextHandler).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestLoggerRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "keeps incoming ID", incoming: "req-123", keep: true},
+		{name: "generates missing ID", incoming: ""},
+		{name: "replaces ID with control characters", incoming: "bad\nid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := Logger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = w.Header().Get(RequestIDHeader)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/jobs", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			got := rr.Header().Get(RequestIDHeader)
+			if got == "" || got != seen {
+				t.Fatalf("request ID %q, handler saw %q", got, seen)
+			}
+			if (got == tt.incoming) != tt.keep {
+				t.Errorf("request ID %q for incoming %q, keep %v", got, tt.incoming, tt.keep)
+			}
+		})
+	}
+}
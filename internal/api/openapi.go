@@ -0,0 +1,89 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sadewadee/google-scraper/internal/api/handlers"
+)
+
+// openAPISpec is the hand-maintained description of the routes set up by
+// Router.Setup. TestOpenAPISpec keeps the two in step.
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+var (
+	openAPIOnce sync.Once
+	openAPIJSON []byte
+	openAPIErr  error
+)
+
+// OpenAPIJSON returns the API description as an OpenAPI 3 JSON document
+func OpenAPIJSON() ([]byte, error) {
+	openAPIOnce.Do(func() {
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(openAPISpec, &doc); err != nil {
+			openAPIErr = fmt.Errorf("parse openapi.yaml: %w", err)
+			return
+		}
+		openAPIJSON, openAPIErr = json.Marshal(doc)
+	})
+
+	return openAPIJSON, openAPIErr
+}
+
+// serveOpenAPI handles GET /api/v2/openapi.json
+func serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handlers.RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	spec, err := OpenAPIJSON()
+	if err != nil {
+		handlers.RenderError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(spec)
+}
+
+// swaggerUIPage renders /api/v2/openapi.json with Swagger UI
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Google Maps Scraper API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({
+      url: "/api/v2/openapi.json",
+      dom_id: "#swagger-ui",
+      persistAuthorization: true
+    });
+  </script>
+</body>
+</html>
+`
+
+// serveDocs handles GET /api/v2/docs
+func serveDocs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handlers.RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(swaggerUIPage))
+}
@@ -0,0 +1,470 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/client"
+	"github.com/sadewadee/google-scraper/internal/api/handlers"
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/slowlog"
+)
+
+var testJob = &domain.Job{
+	ID:       uuid.MustParse("6f1c2d3e-4a5b-4c6d-8e7f-8091a2b3c4d5"),
+	Name:     "coffee",
+	Status:   domain.JobStatusRunning,
+	Priority: 1,
+	Config: domain.JobConfig{
+		Keywords: []string{"coffee"},
+		Lang:     "en",
+		Zoom:     15,
+		Depth:    10,
+		MaxTime:  10 * time.Minute,
+	},
+	CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	UpdatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+}
+
+var testWorker = &domain.Worker{
+	ID:            "worker-1",
+	Hostname:      "host",
+	Status:        domain.WorkerStatusBusy,
+	CurrentJobID:  &testJob.ID,
+	LastHeartbeat: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	CreatedAt:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+}
+
+type fakeJobService struct{}
+
+func (fakeJobService) Create(context.Context, *domain.CreateJobRequest) (*domain.Job, error) {
+	return testJob, nil
+}
+func (fakeJobService) GetByID(context.Context, uuid.UUID) (*domain.Job, error) { return testJob, nil }
+func (fakeJobService) AcceptResults(context.Context, uuid.UUID) error          { return nil }
+func (fakeJobService) ParseReport(context.Context, uuid.UUID) (*domain.JobParseReport, error) {
+	return &domain.JobParseReport{Places: 2, Fields: map[string]domain.FieldParseStats{
+		"phone": {Missing: 1, MissingRate: 0.5},
+	}}, nil
+}
+func (fakeJobService) List(context.Context, domain.JobListParams) ([]*domain.Job, int, error) {
+	return []*domain.Job{testJob}, 1, nil
+}
+func (fakeJobService) Delete(context.Context, uuid.UUID) error                 { return nil }
+func (fakeJobService) Restore(context.Context, uuid.UUID) (*domain.Job, error) { return testJob, nil }
+func (fakeJobService) Purge(context.Context, uuid.UUID) error                  { return nil }
+func (fakeJobService) Patch(context.Context, uuid.UUID, *domain.PatchJobRequest) (*domain.Job, error) {
+	return testJob, nil
+}
+func (fakeJobService) Pause(context.Context, uuid.UUID) (*domain.Job, error)  { return testJob, nil }
+func (fakeJobService) Resume(context.Context, uuid.UUID) (*domain.Job, error) { return testJob, nil }
+func (fakeJobService) Cancel(context.Context, uuid.UUID) (*domain.Job, error) { return testJob, nil }
+func (fakeJobService) RetryFailed(context.Context, uuid.UUID, int) (*domain.RetryResult, error) {
+	return &domain.RetryResult{Status: domain.JobStatusQueued, Requeued: 2}, nil
+}
+func (fakeJobService) Requeue(context.Context, uuid.UUID) (*domain.Job, error) { return testJob, nil }
+func (fakeJobService) QueueStatus(context.Context) (*domain.QueueStatus, error) {
+	age := 12.5
+	return &domain.QueueStatus{Backend: domain.QueueBackendRabbitMQ, Queues: []*domain.QueueDepth{
+		{Name: "gmaps.jobs.default", Depth: 3, InFlight: 1, Consumers: 2, OldestAgeSeconds: &age},
+	}}, nil
+}
+func (fakeJobService) Events(context.Context, uuid.UUID, int, int) ([]*domain.JobEvent, int, error) {
+	return []*domain.JobEvent{
+		{ID: 1, JobID: testJob.ID, Type: domain.JobEventCreated, Status: domain.JobStatusPending, Actor: "api_key:ci", CreatedAt: testJob.CreatedAt},
+	}, 1, nil
+}
+func (fakeJobService) UpdateProgress(context.Context, uuid.UUID, domain.JobProgress) error {
+	return nil
+}
+func (fakeJobService) SyncProgress(context.Context, uuid.UUID) error { return nil }
+func (fakeJobService) Backfill(context.Context, uuid.UUID) (*domain.Job, error) {
+	return testJob, nil
+}
+func (fakeJobService) Shard(context.Context, uuid.UUID, int) (*domain.Job, error) {
+	return testJob, nil
+}
+func (fakeJobService) UpdateConfig(context.Context, uuid.UUID, *domain.CreateJobRequest) (*domain.Job, error) {
+	return testJob, nil
+}
+func (fakeJobService) GetStats(context.Context) (*domain.JobStats, error) {
+	return &domain.JobStats{Total: 1, Running: 1}, nil
+}
+func (fakeJobService) ExpandKeywords(*domain.ExpandKeywordsRequest) (*domain.KeywordExpansion, error) {
+	return &domain.KeywordExpansion{}, nil
+}
+func (fakeJobService) Preview(context.Context, *domain.CreateJobRequest) (*domain.JobPreview, error) {
+	return &domain.JobPreview{Keywords: 1, GridPoints: 1, SeedJobs: 1, EstimatedPlaces: 200, Concurrency: 4, Warnings: []string{}}, nil
+}
+func (fakeJobService) Archive(context.Context, uuid.UUID, io.Writer) error { return nil }
+func (fakeJobService) Import(context.Context, io.Reader, string) (*domain.JobImport, error) {
+	return &domain.JobImport{
+		Job: testJob, SourceJobID: uuid.New(), SchemaVersion: 1,
+		Sections: []domain.ImportSection{{Name: "job", InArchive: 1, Imported: 1}, {Name: "results", InArchive: 2, Imported: 2}},
+	}, nil
+}
+
+type fakeResultService struct{}
+
+func (fakeResultService) CreateBatch(context.Context, uuid.UUID, uuid.UUID, [][]byte) error {
+	return nil
+}
+func (fakeResultService) ListByJobID(context.Context, uuid.UUID, int, int) ([][]byte, int, error) {
+	return nil, 0, nil
+}
+func (fakeResultService) StreamByJobID(context.Context, uuid.UUID, func([]byte) error) error {
+	return nil
+}
+func (fakeResultService) CountByJobID(context.Context, uuid.UUID) (int, error) { return 1, nil }
+
+type fakeWorkerService struct{}
+
+func (fakeWorkerService) Register(context.Context, string) (*domain.Worker, error) {
+	return testWorker, nil
+}
+func (fakeWorkerService) Heartbeat(context.Context, *domain.WorkerHeartbeat) (*domain.WorkerDirectives, error) {
+	timeout := 60
+	return &domain.WorkerDirectives{Drain: true, DrainTimeoutSeconds: &timeout}, nil
+}
+func (fakeWorkerService) List(context.Context, domain.WorkerListParams) ([]*domain.Worker, error) {
+	return []*domain.Worker{testWorker}, nil
+}
+func (fakeWorkerService) GetByID(context.Context, string) (*domain.Worker, error) {
+	return testWorker, nil
+}
+func (fakeWorkerService) GetStats(context.Context) (*domain.WorkerStats, error) {
+	return &domain.WorkerStats{TotalWorkers: 1, OnlineWorkers: 1, BusyWorkers: 1}, nil
+}
+func (fakeWorkerService) ClaimJob(context.Context, string) (*domain.Job, error) { return testJob, nil }
+func (fakeWorkerService) ReleaseJob(context.Context, uuid.UUID, string) error   { return nil }
+func (fakeWorkerService) CompleteJob(context.Context, uuid.UUID, string, int, int, []string, string, []string, *domain.JobParseReport) error {
+	return nil
+}
+func (fakeWorkerService) FailJob(context.Context, uuid.UUID, string, int, string, string, []string) error {
+	return nil
+}
+func (fakeWorkerService) Unregister(context.Context, string) error { return nil }
+func (fakeWorkerService) Drain(context.Context, string, time.Duration) (*domain.Worker, error) {
+	return testWorker, nil
+}
+
+// newTestRouter sets up every route, optional ones included. Only the job
+// and worker handlers are backed by services.
+func newTestRouter() (*Router, http.Handler) {
+	r := NewRouter(
+		handlers.NewJobHandler(fakeJobService{}, fakeResultService{}),
+		handlers.NewWorkerHandler(fakeWorkerService{}),
+		&handlers.StatsHandler{},
+		&handlers.ProxyHandler{},
+		&handlers.ResultHandler{},
+		&handlers.BusinessListingHandler{},
+	)
+	r.SetAPIKeys(&handlers.APIKeyHandler{}, nil)
+	r.SetTemplates(&handlers.TemplateHandler{})
+	r.SetSettings(&handlers.SettingsHandler{})
+	r.SetReviews(&handlers.ReviewHandler{})
+	r.SetEvents(&handlers.EventHandler{})
+	r.SetWorkerEvents(&handlers.WorkerEventHandler{})
+	r.SetDuplicates(&handlers.DuplicateHandler{})
+	r.SetDiffs(&handlers.DiffHandler{})
+	r.SetSpawner(&handlers.SpawnerHandler{})
+	r.SetCluster(&handlers.ClusterHandler{})
+	r.SetTimeSeries(&handlers.TimeSeriesHandler{})
+	r.SetUsage(&handlers.UsageHandler{})
+	r.SetSeedTasks(&handlers.SeedTaskHandler{})
+	r.SetEmailValidation(&handlers.EmailValidationHandler{})
+	r.SetRenormalize(&handlers.RenormalizeHandler{})
+	r.SetMaintenance(&handlers.MaintenanceHandler{})
+	r.SetReports(&handlers.ReportHandler{})
+	r.SetCategories(&handlers.CategoryHandler{})
+	r.SetExportProfiles(&handlers.ExportProfileHandler{})
+	r.SetHealth(handlers.NewHealthHandler())
+	r.SetSlowLog(&handlers.SlowLogHandler{}, slowlog.New(slowlog.Thresholds{}))
+
+	return r, r.Setup("")
+}
+
+func loadSpec(t *testing.T) map[string]interface{} {
+	t.Helper()
+
+	data, err := OpenAPIJSON()
+	require.NoError(t, err)
+
+	var spec map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &spec))
+
+	return spec
+}
+
+var httpMethods = []string{"get", "post", "put", "patch", "delete"}
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	spec := loadSpec(t)
+	paths := spec["paths"].(map[string]interface{})
+
+	router, _ := newTestRouter()
+
+	routes := make(map[string]bool)
+	for _, pattern := range router.Routes() {
+		routes[pattern] = true
+		assert.Contains(t, paths, pattern, "route %s is not documented", pattern)
+	}
+
+	documented := make([]string, 0, len(paths))
+	for path := range paths {
+		documented = append(documented, path)
+	}
+	sort.Strings(documented)
+
+	for _, path := range documented {
+		assert.True(t, routes[path], "documented path %s is not routed", path)
+
+		item := paths[path].(map[string]interface{})
+		operations := 0
+		for _, method := range httpMethods {
+			if _, ok := item[method]; ok {
+				operations++
+			}
+		}
+		assert.NotZero(t, operations, "path %s documents no operation", path)
+	}
+}
+
+func TestOpenAPIResponseShapes(t *testing.T) {
+	spec := loadSpec(t)
+	_, handler := newTestRouter()
+
+	jobPath := "/api/v2/jobs/" + testJob.ID.String()
+	workerPath := "/api/v2/workers/" + testWorker.ID
+
+	tests := []struct {
+		method string
+		path   string // as documented
+		url    string
+		body   interface{}
+	}{
+		{http.MethodGet, "/health", "/health", nil},
+		{http.MethodGet, "/api/v2/openapi.json", "/api/v2/openapi.json", nil},
+		{http.MethodGet, "/api/v2/jobs", "/api/v2/jobs?page=1&per_page=10", nil},
+		{http.MethodPost, "/api/v2/jobs", "/api/v2/jobs", client.CreateJobRequest{Name: "coffee", Keywords: []string{"coffee"}}},
+		{http.MethodGet, "/api/v2/jobs/stats", "/api/v2/jobs/stats", nil},
+		{http.MethodGet, "/api/v2/jobs/{id}", jobPath, nil},
+		{http.MethodPatch, "/api/v2/jobs/{id}", jobPath, client.PatchJobRequest{Notes: &testJob.Name}},
+		{http.MethodDelete, "/api/v2/jobs/{id}", jobPath, nil},
+		{http.MethodPost, "/api/v2/jobs/{id}/pause", jobPath + "/pause", nil},
+		{http.MethodPost, "/api/v2/jobs/{id}/resume", jobPath + "/resume", nil},
+		{http.MethodPost, "/api/v2/jobs/{id}/cancel", jobPath + "/cancel", nil},
+		{http.MethodPost, "/api/v2/jobs/{id}/retry-failed", jobPath + "/retry-failed", nil},
+		{http.MethodGet, "/api/v2/jobs/{id}/parse-report", jobPath + "/parse-report", nil},
+		{http.MethodPost, "/api/v2/jobs/{id}/shard", jobPath + "/shard?count=2", nil},
+		{http.MethodGet, "/api/v2/jobs/{id}/config", jobPath + "/config", nil},
+		{http.MethodPatch, "/api/v2/jobs/{id}/config", jobPath + "/config", client.CreateJobRequest{Keywords: []string{"tea"}, Radius: 3000}},
+		{http.MethodPost, "/api/v2/jobs/preview", "/api/v2/jobs/preview", client.CreateJobRequest{Name: "coffee", Keywords: []string{"coffee", "Coffee"}}},
+		{http.MethodGet, "/api/v2/jobs/{id}/events", jobPath + "/events", nil},
+		{http.MethodPost, "/api/v2/jobs/import", "/api/v2/jobs/import", nil},
+		{http.MethodPost, "/api/v2/jobs/bulk", "/api/v2/jobs/bulk?name=coffee", nil},
+		{http.MethodPost, "/api/v2/jobs/{id}/results", jobPath + "/results", domain.ResultBatch{JobID: testJob.ID, BatchID: uuid.New(), Data: [][]byte{[]byte(`{}`)}}},
+		{http.MethodGet, "/api/v2/workers", "/api/v2/workers", nil},
+		{http.MethodPost, "/api/v2/workers/register", "/api/v2/workers/register", client.RegisterWorkerRequest{WorkerID: testWorker.ID}},
+		{http.MethodPost, "/api/v2/workers/heartbeat", "/api/v2/workers/heartbeat", domain.WorkerHeartbeat{WorkerID: testWorker.ID, Status: domain.WorkerStatusBusy}},
+		{http.MethodGet, "/api/v2/workers/stats", "/api/v2/workers/stats", nil},
+		{http.MethodGet, "/api/v2/workers/{id}", workerPath, nil},
+		{http.MethodDelete, "/api/v2/workers/{id}", workerPath, nil},
+		{http.MethodPost, "/api/v2/workers/{id}/claim", workerPath + "/claim", nil},
+		{http.MethodPost, "/api/v2/workers/{id}/complete", workerPath + "/complete", client.CompleteJobRequest{JobID: testJob.ID, PlacesScraped: 3}},
+		{http.MethodPost, "/api/v2/workers/{id}/fail", workerPath + "/fail", client.FailJobRequest{JobID: testJob.ID, Message: "blocked", ErrorCode: "blocked_by_google"}},
+		{http.MethodPost, "/api/v2/workers/{id}/release", workerPath + "/release", client.ReleaseJobRequest{JobID: testJob.ID}},
+		{http.MethodPost, "/api/v2/workers/{id}/drain", workerPath + "/drain?timeout=30m", nil},
+		{http.MethodGet, "/api/v2/workers/{id}/jobs/{job_id}", workerPath + "/jobs/" + testJob.ID.String(), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			var body bytes.Buffer
+			if tt.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tt.body))
+			}
+
+			req := httptest.NewRequest(tt.method, tt.url, &body)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			operation := specOperation(t, spec, tt.path, tt.method)
+			responses := operation["responses"].(map[string]interface{})
+			response, ok := responses[strconv.Itoa(rec.Code)].(map[string]interface{})
+			require.True(t, ok, "status %d is not documented: %s", rec.Code, rec.Body.String())
+			response = resolveRef(t, spec, response)
+
+			schema := jsonSchema(response)
+			if schema == nil {
+				return
+			}
+
+			var got interface{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got), rec.Body.String())
+			for _, problem := range validateSchema(spec, schema, got, "$") {
+				t.Error(problem)
+			}
+		})
+	}
+}
+
+// TestClientMatchesHandlers keeps the request bodies of the client package
+// in step with what the handlers decode
+func TestClientMatchesHandlers(t *testing.T) {
+	pairs := []struct {
+		handler interface{}
+		client  interface{}
+	}{
+		{handlers.CreateJobRequest{}, client.CreateJobRequest{}},
+		{handlers.RegisterRequest{}, client.RegisterWorkerRequest{}},
+		{handlers.CompleteJobRequest{}, client.CompleteJobRequest{}},
+		{handlers.FailJobRequest{}, client.FailJobRequest{}},
+		{handlers.ReleaseJobRequest{}, client.ReleaseJobRequest{}},
+	}
+
+	for _, p := range pairs {
+		assert.Equal(t, jsonFields(reflect.TypeOf(p.handler)), jsonFields(reflect.TypeOf(p.client)), "%T", p.client)
+	}
+}
+
+func jsonFields(typ reflect.Type) map[string]string {
+	fields := make(map[string]string)
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		fields[name] = f.Type.String()
+	}
+	return fields
+}
+
+func specOperation(t *testing.T, spec map[string]interface{}, path, method string) map[string]interface{} {
+	t.Helper()
+
+	item, ok := spec["paths"].(map[string]interface{})[path].(map[string]interface{})
+	require.True(t, ok, "path %s is not documented", path)
+	operation, ok := item[strings.ToLower(method)].(map[string]interface{})
+	require.True(t, ok, "%s %s is not documented", method, path)
+
+	return operation
+}
+
+func resolveRef(t *testing.T, spec map[string]interface{}, node map[string]interface{}) map[string]interface{} {
+	ref, ok := node["$ref"].(string)
+	if !ok {
+		return node
+	}
+
+	var cur interface{} = spec
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, ok := cur.(map[string]interface{})
+		if t != nil {
+			require.True(t, ok, "bad $ref %s", ref)
+		} else if !ok {
+			return nil
+		}
+		cur = m[part]
+	}
+
+	resolved, _ := cur.(map[string]interface{})
+	return resolved
+}
+
+func jsonSchema(response map[string]interface{}) map[string]interface{} {
+	content, _ := response["content"].(map[string]interface{})
+	media, _ := content["application/json"].(map[string]interface{})
+	schema, _ := media["schema"].(map[string]interface{})
+	return schema
+}
+
+// validateSchema checks value against the subset of JSON Schema the spec
+// uses and returns what does not match
+func validateSchema(spec, schema map[string]interface{}, value interface{}, at string) []string {
+	schema = resolveRef(nil, spec, schema)
+	if schema == nil {
+		return []string{at + ": unresolved $ref"}
+	}
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable {
+			return nil
+		}
+		return []string{at + ": null is not allowed"}
+	}
+
+	var problems []string
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			problems = append(problems, validateSchema(spec, sub.(map[string]interface{}), value, at)...)
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: want object, got %T", at, value))
+		}
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := obj[name.(string)]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing %s", at, name))
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, v := range obj {
+			if prop, ok := properties[name].(map[string]interface{}); ok {
+				problems = append(problems, validateSchema(spec, prop, v, at+"."+name)...)
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: want array, got %T", at, value))
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, v := range arr {
+				problems = append(problems, validateSchema(spec, items, v, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: want string, got %T", at, value))
+		}
+		if enum, ok := schema["enum"].([]interface{}); ok {
+			found := false
+			for _, e := range enum {
+				found = found || e == s
+			}
+			if !found {
+				problems = append(problems, fmt.Sprintf("%s: %q is not one of %v", at, s, enum))
+			}
+		}
+	case "integer", "number":
+		if _, ok := value.(float64); !ok {
+			return append(problems, fmt.Sprintf("%s: want number, got %T", at, value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return append(problems, fmt.Sprintf("%s: want boolean, got %T", at, value))
+		}
+	}
+
+	return problems
+}
@@ -2,8 +2,10 @@ package api
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/sadewadee/google-scraper/internal/api/handlers"
+	"github.com/sadewadee/google-scraper/internal/slowlog"
 )
 
 // Router sets up all API routes
@@ -22,6 +24,75 @@ type Router struct {
 	cachedJobs    *handlers.CachedJobHandler
 	cachedStats   *handlers.CachedStatsHandler
 	cachedResults *handlers.CachedResultHandler
+
+	// Scoped API keys (optional, set via SetAPIKeys)
+	apiKeys    *handlers.APIKeyHandler
+	apiKeyAuth APIKeyAuthenticator
+
+	// Job templates (optional, set via SetTemplates)
+	templates *handlers.TemplateHandler
+
+	// Server-side settings (optional, set via SetSettings)
+	settings *handlers.SettingsHandler
+
+	// Normalized reviews (optional, set via SetReviews)
+	reviews *handlers.ReviewHandler
+
+	// Live job events over SSE (optional, set via SetEvents)
+	events *handlers.EventHandler
+
+	// Live worker fleet over SSE (optional, set via SetWorkerEvents)
+	workerEvents *handlers.WorkerEventHandler
+
+	// Duplicate listings report and merge (optional, set via SetDuplicates)
+	duplicates *handlers.DuplicateHandler
+
+	// Results diff between two jobs (optional, set via SetDiffs)
+	diffs *handlers.DiffHandler
+
+	// Worker auto-scaler state (optional, set via SetSpawner)
+	spawner *handlers.SpawnerHandler
+
+	// Stats time series for trend charts (optional, set via SetTimeSeries)
+	timeSeries *handlers.TimeSeriesHandler
+
+	// Leader election state of the replica (optional, set via SetCluster)
+	cluster *handlers.ClusterHandler
+
+	// Usage accounting per API key or tenant (optional, set via SetUsage)
+	usage *handlers.UsageHandler
+
+	// Seed tasks of jobs bridged to DSN workers (optional, set via SetSeedTasks)
+	seedTasks *handlers.SeedTaskHandler
+
+	// Email validation progress (optional, set via SetEmailValidation)
+	emailValidation *handlers.EmailValidationHandler
+
+	// Re-normalization of stored results (optional, set via SetRenormalize)
+	renormalize *handlers.RenormalizeHandler
+
+	// Database maintenance runs (optional, set via SetMaintenance)
+	maintenance *handlers.MaintenanceHandler
+
+	// Summary reports of jobs (optional, set via SetReports)
+	reports *handlers.ReportHandler
+
+	// Canonical category taxonomy (optional, set via SetCategories)
+	categories *handlers.CategoryHandler
+
+	// Export profiles (optional, set via SetExportProfiles)
+	exportProfiles *handlers.ExportProfileHandler
+
+	// Dependency checks (optional, set via SetHealth); without them /health
+	// always answers ok
+	health *handlers.HealthHandler
+
+	// Slow request and query log (optional, set via SetSlowLog)
+	slowLog        *handlers.SlowLogHandler
+	slowLogEntries *slowlog.Log
+
+	// routes lists the patterns registered by Setup
+	routes []string
 }
 
 // NewRouter creates a new Router
@@ -55,77 +126,320 @@ func (r *Router) SetCachedHandlers(
 	r.cachedResults = cachedResults
 }
 
+// SetAPIKeys enables scoped API key management and authentication
+func (r *Router) SetAPIKeys(apiKeys *handlers.APIKeyHandler, auth APIKeyAuthenticator) {
+	r.apiKeys = apiKeys
+	r.apiKeyAuth = auth
+}
+
+// SetTemplates enables the job templates endpoints
+func (r *Router) SetTemplates(templates *handlers.TemplateHandler) {
+	r.templates = templates
+}
+
+// SetSettings enables the server-side settings endpoints
+func (r *Router) SetSettings(settings *handlers.SettingsHandler) {
+	r.settings = settings
+}
+
+// SetReviews enables the per-job review endpoints
+func (r *Router) SetReviews(reviews *handlers.ReviewHandler) {
+	r.reviews = reviews
+}
+
+// SetEvents enables the live job events stream
+func (r *Router) SetEvents(events *handlers.EventHandler) {
+	r.events = events
+}
+
+// SetWorkerEvents enables the live worker fleet stream
+func (r *Router) SetWorkerEvents(workerEvents *handlers.WorkerEventHandler) {
+	r.workerEvents = workerEvents
+}
+
+// SetDuplicates enables the duplicate listings endpoints
+func (r *Router) SetDuplicates(duplicates *handlers.DuplicateHandler) {
+	r.duplicates = duplicates
+}
+
+// SetDiffs enables the job results diff endpoint
+func (r *Router) SetDiffs(diffs *handlers.DiffHandler) {
+	r.diffs = diffs
+}
+
+// SetSpawner enables the worker auto-scaler status endpoint
+func (r *Router) SetSpawner(spawner *handlers.SpawnerHandler) {
+	r.spawner = spawner
+}
+
+// SetTimeSeries enables the stats time series endpoint
+func (r *Router) SetTimeSeries(timeSeries *handlers.TimeSeriesHandler) {
+	r.timeSeries = timeSeries
+}
+
+// SetCluster enables the manager cluster status endpoint
+func (r *Router) SetCluster(cluster *handlers.ClusterHandler) {
+	r.cluster = cluster
+}
+
+// SetUsage enables the usage report endpoint
+func (r *Router) SetUsage(usage *handlers.UsageHandler) {
+	r.usage = usage
+}
+
+// SetSeedTasks enables the per-job seed task endpoint
+func (r *Router) SetSeedTasks(seedTasks *handlers.SeedTaskHandler) {
+	r.seedTasks = seedTasks
+}
+
+// SetEmailValidation enables the email validation stats endpoint
+func (r *Router) SetEmailValidation(emailValidation *handlers.EmailValidationHandler) {
+	r.emailValidation = emailValidation
+}
+
+// SetRenormalize enables the admin re-normalization endpoint
+func (r *Router) SetRenormalize(renormalize *handlers.RenormalizeHandler) {
+	r.renormalize = renormalize
+}
+
+// SetMaintenance enables the admin database maintenance endpoint
+func (r *Router) SetMaintenance(maintenance *handlers.MaintenanceHandler) {
+	r.maintenance = maintenance
+}
+
+// SetReports enables the job report endpoint
+func (r *Router) SetReports(reports *handlers.ReportHandler) {
+	r.reports = reports
+}
+
+// SetCategories enables the admin category mapping endpoints
+func (r *Router) SetCategories(categories *handlers.CategoryHandler) {
+	r.categories = categories
+}
+
+// SetExportProfiles enables the export profile management endpoints
+func (r *Router) SetExportProfiles(exportProfiles *handlers.ExportProfileHandler) {
+	r.exportProfiles = exportProfiles
+}
+
+// SetHealth enables dependency checks on /health and the /ready endpoint
+func (r *Router) SetHealth(health *handlers.HealthHandler) {
+	r.health = health
+}
+
+// SetSlowLog records slow requests into log and enables the admin
+// endpoint that shows them
+func (r *Router) SetSlowLog(slowLog *handlers.SlowLogHandler, log *slowlog.Log) {
+	r.slowLog = slowLog
+	r.slowLogEntries = log
+}
+
+// handle registers a route and records its pattern
+func (r *Router) handle(pattern string, handler http.HandlerFunc) {
+	r.mux.HandleFunc(pattern, handler)
+	r.routes = append(r.routes, pattern)
+}
+
+// Routes returns the patterns registered by Setup
+func (r *Router) Routes() []string {
+	return r.routes
+}
+
 // Setup configures all routes
 func (r *Router) Setup(token string) http.Handler {
 	// Health check endpoint (no auth required)
-	r.mux.HandleFunc("/health", r.healthCheck)
-	r.mux.HandleFunc("/api/v2/health", r.healthCheck)
+	if r.health != nil {
+		r.handle("/health", r.health.Health)
+		r.handle("/api/v2/health", r.health.Health)
+		r.handle("/ready", r.health.Ready)
+		r.handle("/api/v2/ready", r.health.Ready)
+	} else {
+		r.handle("/health", r.healthCheck)
+		r.handle("/api/v2/health", r.healthCheck)
+	}
+
+	// API description (no auth required)
+	r.handle("/api/v2/openapi.json", serveOpenAPI)
+	r.handle("/api/v2/docs", serveDocs)
 
 	// Stats endpoint - use cached handler if available
 	if r.cachedStats != nil {
-		r.mux.HandleFunc("/api/v2/stats", r.cachedStats.GetDashboardStats)
+		r.handle("/api/v2/stats", r.cachedStats.GetDashboardStats)
 	} else {
-		r.mux.HandleFunc("/api/v2/stats", r.stats.GetDashboardStats)
+		r.handle("/api/v2/stats", r.stats.GetDashboardStats)
+	}
+	if r.timeSeries != nil {
+		r.handle("/api/v2/stats/timeseries", r.timeSeries.TimeSeries)
 	}
 
 	// ProxyGate endpoints
-	r.mux.HandleFunc("/api/v2/proxygate/stats", r.proxy.GetProxyStats)
-	r.mux.HandleFunc("/api/v2/proxygate/sources", r.handleProxySources)
-	r.mux.HandleFunc("/api/v2/proxygate/sources/{id}", r.handleProxySource)
-	r.mux.HandleFunc("/api/v2/proxygate/refresh", r.proxy.Refresh)
-	r.mux.HandleFunc("/api/v2/proxygate/proxies", r.handleProxies)
-	r.mux.HandleFunc("/api/v2/proxygate/proxies/bulk", r.proxy.AddProxiesBulk)
-	r.mux.HandleFunc("/api/v2/proxygate/proxies/cleanup", r.proxy.DeleteDeadProxies)
-	r.mux.HandleFunc("/api/v2/proxygate/proxies/{id}", r.handleProxy)
+	r.handle("/api/v2/proxygate/stats", r.proxy.GetProxyStats)
+	r.handle("/api/v2/proxygate/sources", r.handleProxySources)
+	r.handle("/api/v2/proxygate/sources/{id}", r.handleProxySource)
+	r.handle("/api/v2/proxygate/refresh", r.proxy.Refresh)
+	r.handle("/api/v2/proxygate/proxies", r.handleProxies)
+	r.handle("/api/v2/proxygate/proxies/bulk", r.proxy.AddProxiesBulk)
+	r.handle("/api/v2/proxygate/proxies/cleanup", r.proxy.DeleteDeadProxies)
+	r.handle("/api/v2/proxygate/proxies/{id}", r.handleProxy)
+	r.handle("/api/v2/proxygate/proxies/{id}/stats", r.proxy.GetProxyScore)
+	r.handle("/api/v2/proxygate/proxies/{id}/events", r.proxy.ListProxyEvents)
+	r.handle("/api/v2/proxygate/events/summary", r.proxy.GetProxyEventSummary)
+	r.handle("/api/v2/proxygate/usage", r.proxy.GetProxyUsage)
+	r.handle("/api/v2/proxygate/lease", r.proxy.LeaseProxies)
+	r.handle("/api/v2/proxygate/lease/failures", r.proxy.ReportProxyFailures)
 
 	// Job endpoints
-	r.mux.HandleFunc("/api/v2/jobs", r.handleJobs)
-	r.mux.HandleFunc("/api/v2/jobs/stats", r.handleJobStats)
-	r.mux.HandleFunc("/api/v2/jobs/{id}", r.handleJob)
-	r.mux.HandleFunc("/api/v2/jobs/{id}/pause", r.jobs.Pause)
-	r.mux.HandleFunc("/api/v2/jobs/{id}/resume", r.jobs.Resume)
-	r.mux.HandleFunc("/api/v2/jobs/{id}/cancel", r.jobs.Cancel)
-	r.mux.HandleFunc("/api/v2/jobs/{id}/results", r.handleJobResults)
-	r.mux.HandleFunc("/api/v2/jobs/{id}/download", r.handleJobDownload)
+	r.handle("/api/v2/jobs", r.handleJobs)
+	r.handle("/api/v2/jobs/stats", r.handleJobStats)
+	r.handle("/api/v2/jobs/expand-keywords", r.jobs.ExpandKeywords)
+	r.handle("/api/v2/jobs/preview", r.jobs.Preview)
+	r.handle("/api/v2/jobs/import", r.jobs.Import)
+	r.handle("/api/v2/jobs/bulk", r.jobs.BulkCreate)
+	r.handle("/api/v2/jobs/{id}", r.handleJob)
+	r.handle("/api/v2/jobs/{id}/pause", r.jobs.Pause)
+	r.handle("/api/v2/jobs/{id}/resume", r.jobs.Resume)
+	r.handle("/api/v2/jobs/{id}/cancel", r.jobs.Cancel)
+	r.handle("/api/v2/jobs/{id}/retry-failed", r.jobs.RetryFailed)
+	r.handle("/api/v2/jobs/{id}/backfill", r.jobs.Backfill)
+	r.handle("/api/v2/jobs/{id}/shard", r.jobs.Shard)
+	r.handle("/api/v2/jobs/{id}/config", r.handleJobConfig)
+	r.handle("/api/v2/jobs/{id}/requeue", r.jobs.Requeue)
+	r.handle("/api/v2/jobs/{id}/clone", r.jobs.Clone)
+	r.handle("/api/v2/jobs/{id}/restore", r.jobs.Restore)
+	r.handle("/api/v2/jobs/{id}/parse-report", r.jobs.ParseReport)
+	r.handle("/api/v2/jobs/{id}/archive", r.jobs.Archive)
+	r.handle("/api/v2/jobs/{id}/results", r.handleJobResults)
+	r.handle("/api/v2/jobs/{id}/download", r.handleJobDownload)
+	if r.businessListings != nil {
+		r.handle("/api/v2/jobs/{id}/results/sample", r.businessListings.SampleByJobID)
+		r.handle("/api/v2/jobs/{id}/results/field-stats", r.businessListings.FieldStatsByJobID)
+	}
+	r.handle("/api/v2/queue/status", r.jobs.QueueStatus)
+	if r.reviews != nil {
+		r.handle("/api/v2/jobs/{id}/reviews", r.reviews.ListByJobID)
+		r.handle("/api/v2/jobs/{id}/reviews/download", r.reviews.DownloadByJobID)
+	}
+	r.handle("/api/v2/jobs/{id}/events", r.handleJobEvents)
+	if r.seedTasks != nil {
+		r.handle("/api/v2/jobs/{id}/tasks", r.seedTasks.ListByJobID)
+	}
+	if r.diffs != nil {
+		r.handle("/api/v2/jobs/{id}/diff", r.diffs.Diff)
+	}
+	if r.reports != nil {
+		r.handle("/api/v2/jobs/{id}/report", r.reports.Report)
+	}
+
+	// Job template endpoints
+	if r.templates != nil {
+		r.handle("/api/v2/templates", r.handleTemplates)
+		r.handle("/api/v2/templates/{id}", r.handleTemplate)
+	}
+
+	// Settings endpoints
+	if r.settings != nil {
+		r.handle("/api/v2/settings", r.settings.Get)
+		r.handle("/api/v2/settings/job-defaults", r.handleJobDefaults)
+		r.handle("/api/v2/settings/rating-prior", r.handleRatingPrior)
+	}
 
 	// Worker endpoints
-	r.mux.HandleFunc("/api/v2/workers", r.workers.List)
-	r.mux.HandleFunc("/api/v2/workers/register", r.workers.Register)
-	r.mux.HandleFunc("/api/v2/workers/heartbeat", r.workers.Heartbeat)
-	r.mux.HandleFunc("/api/v2/workers/stats", r.workers.GetStats)
-	r.mux.HandleFunc("/api/v2/workers/{id}", r.handleWorker)
-	r.mux.HandleFunc("/api/v2/workers/{id}/claim", r.workers.ClaimJob)
-	r.mux.HandleFunc("/api/v2/workers/{id}/complete", r.workers.CompleteJob)
-	r.mux.HandleFunc("/api/v2/workers/{id}/fail", r.workers.FailJob)
-	r.mux.HandleFunc("/api/v2/workers/{id}/release", r.workers.ReleaseJob)
+	r.handle("/api/v2/workers", r.workers.List)
+	r.handle("/api/v2/workers/register", r.workers.Register)
+	r.handle("/api/v2/workers/heartbeat", r.workers.Heartbeat)
+	r.handle("/api/v2/workers/stats", r.workers.GetStats)
+	if r.workerEvents != nil {
+		r.handle("/api/v2/workers/events", r.workerEvents.Stream)
+	}
+	r.handle("/api/v2/workers/{id}", r.handleWorker)
+	r.handle("/api/v2/workers/{id}/claim", r.workers.ClaimJob)
+	r.handle("/api/v2/workers/{id}/complete", r.workers.CompleteJob)
+	r.handle("/api/v2/workers/{id}/fail", r.workers.FailJob)
+	r.handle("/api/v2/workers/{id}/release", r.workers.ReleaseJob)
+	r.handle("/api/v2/workers/{id}/drain", r.workers.Drain)
+	r.handle("/api/v2/workers/{id}/jobs/{job_id}", r.jobs.GetForWorker)
+
+	// Worker auto-scaler endpoint
+	if r.spawner != nil {
+		r.handle("/api/v2/spawner/status", r.spawner.Status)
+	}
+
+	if r.cluster != nil {
+		r.handle("/api/v2/cluster/status", r.cluster.Status)
+	}
+
+	if r.usage != nil {
+		r.handle("/api/v2/usage", r.usage.Summary)
+	}
+
+	if r.emailValidation != nil {
+		r.handle("/api/v2/emails/validation-stats", r.emailValidation.Stats)
+	}
 
 	// Global results endpoints - use business_listings table via BusinessListingHandler
 	// (Normalized data with proper columns, filtering, and export formats)
 	if r.businessListings != nil {
-		r.mux.HandleFunc("/api/v2/results", r.businessListings.List)
-		r.mux.HandleFunc("/api/v2/results/download", r.businessListings.Download)
-		r.mux.HandleFunc("/api/v2/results/categories", r.businessListings.GetCategories)
-		r.mux.HandleFunc("/api/v2/results/cities", r.businessListings.GetCities)
-		r.mux.HandleFunc("/api/v2/results/stats", r.businessListings.GetStats)
-		r.mux.HandleFunc("/api/v2/results/columns", r.businessListings.GetAvailableColumns)
+		r.handle("/api/v2/results", r.businessListings.List)
+		r.handle("/api/v2/results/download", r.businessListings.Download)
+		r.handle("/api/v2/results/export", r.businessListings.Export)
+		r.handle("/api/v2/results/categories", r.businessListings.GetCategories)
+		r.handle("/api/v2/results/cities", r.businessListings.GetCities)
+		r.handle("/api/v2/results/stats", r.businessListings.GetStats)
+		r.handle("/api/v2/results/columns", r.businessListings.GetAvailableColumns)
+		r.handle("/api/v2/results/sample", r.businessListings.Sample)
+		if r.duplicates != nil {
+			r.handle("/api/v2/results/duplicates", r.duplicates.List)
+			r.handle("/api/v2/results/duplicates/merge", r.duplicates.Merge)
+		}
 	} else if r.cachedResults != nil {
 		// Fallback to cached raw results handler
-		r.mux.HandleFunc("/api/v2/results", r.cachedResults.List)
-		r.mux.HandleFunc("/api/v2/results/download", r.results.Download)
+		r.handle("/api/v2/results", r.cachedResults.List)
+		r.handle("/api/v2/results/download", r.results.Download)
 	} else {
 		// Fallback to raw results handler
-		r.mux.HandleFunc("/api/v2/results", r.results.List)
-		r.mux.HandleFunc("/api/v2/results/download", r.results.Download)
+		r.handle("/api/v2/results", r.results.List)
+		r.handle("/api/v2/results/download", r.results.Download)
+	}
+
+	// API key management endpoints (admin token only)
+	if r.apiKeys != nil {
+		r.handle("/api/v2/apikeys", r.handleAPIKeys)
+		r.handle("/api/v2/apikeys/{id}", r.apiKeys.Delete)
+	}
+	if r.exportProfiles != nil {
+		r.handle("/api/v2/export-profiles", r.exportProfiles.Profiles)
+		r.handle("/api/v2/export-profiles/{name}", r.exportProfiles.Profile)
+	}
+
+	// Maintenance endpoints (admin token only)
+	if r.renormalize != nil {
+		r.handle("/api/v2/admin/renormalize", r.renormalize.Renormalize)
+	}
+	if r.maintenance != nil {
+		r.handle("/api/v2/admin/maintenance", r.maintenance.Maintenance)
+	}
+	if r.categories != nil {
+		r.handle("/api/v2/admin/categories/mappings", r.categories.Mappings)
+		r.handle("/api/v2/admin/categories/unmapped", r.categories.Unmapped)
+	}
+	if r.slowLog != nil {
+		r.handle("/api/v2/admin/slowlog", r.slowLog.SlowLog)
 	}
 
 	// Apply middleware
-	return Chain(r.mux,
-		Recovery,
+	middlewares := []func(http.Handler) http.Handler{
 		Logger,
+		Recovery,
 		CORS,
 		SecurityHeaders,
-		Auth(token),
-	)
+		Compress,
+		ETag,
+		AuthWithKeys(token, r.apiKeyAuth),
+	}
+	if r.slowLogEntries != nil {
+		middlewares = append(middlewares, SlowLog(r.slowLogEntries))
+	}
+	return Chain(r.mux, middlewares...)
 }
 
 // handleJobs routes requests for /api/v2/jobs
@@ -155,6 +469,8 @@ func (r *Router) handleJob(w http.ResponseWriter, req *http.Request) {
 		} else {
 			r.jobs.GetByID(w, req)
 		}
+	case http.MethodPatch:
+		r.jobs.Patch(w, req)
 	case http.MethodDelete:
 		r.jobs.Delete(w, req)
 	default:
@@ -162,6 +478,18 @@ func (r *Router) handleJob(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// handleJobConfig routes requests for /api/v2/jobs/{id}/config
+func (r *Router) handleJobConfig(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		r.jobs.GetConfig(w, req)
+	case http.MethodPatch:
+		r.jobs.PatchConfig(w, req)
+	default:
+		handlers.RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
 // handleJobStats routes requests for /api/v2/jobs/stats
 func (r *Router) handleJobStats(w http.ResponseWriter, req *http.Request) {
 	// Use cached handler if available
@@ -172,6 +500,56 @@ func (r *Router) handleJobStats(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// handleTemplates routes requests for /api/v2/templates
+func (r *Router) handleTemplates(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		r.templates.List(w, req)
+	case http.MethodPost:
+		r.templates.Create(w, req)
+	default:
+		handlers.RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleTemplate routes requests for /api/v2/templates/{id}
+func (r *Router) handleTemplate(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		r.templates.GetByID(w, req)
+	case http.MethodPut:
+		r.templates.Update(w, req)
+	case http.MethodDelete:
+		r.templates.Delete(w, req)
+	default:
+		handlers.RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleJobDefaults routes requests for /api/v2/settings/job-defaults
+func (r *Router) handleJobDefaults(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		r.settings.GetJobDefaults(w, req)
+	case http.MethodPut:
+		r.settings.PutJobDefaults(w, req)
+	default:
+		handlers.RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleRatingPrior routes requests for /api/v2/settings/rating-prior
+func (r *Router) handleRatingPrior(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		r.settings.GetRatingPrior(w, req)
+	case http.MethodPut:
+		r.settings.PutRatingPrior(w, req)
+	default:
+		handlers.RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
 // handleWorker routes requests for /api/v2/workers/{id}
 func (r *Router) handleWorker(w http.ResponseWriter, req *http.Request) {
 	switch req.Method {
@@ -184,6 +562,18 @@ func (r *Router) handleWorker(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// handleAPIKeys routes requests for /api/v2/apikeys
+func (r *Router) handleAPIKeys(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		r.apiKeys.List(w, req)
+	case http.MethodPost:
+		r.apiKeys.Create(w, req)
+	default:
+		handlers.RenderError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
 // handleProxySources routes requests for /api/v2/proxygate/sources
 func (r *Router) handleProxySources(w http.ResponseWriter, req *http.Request) {
 	switch req.Method {
@@ -257,6 +647,16 @@ func (r *Router) handleJobResults(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// handleJobEvents routes requests for /api/v2/jobs/{id}/events: clients
+// asking for server-sent events get the live stream, others the audit trail
+func (r *Router) handleJobEvents(w http.ResponseWriter, req *http.Request) {
+	if r.events != nil && strings.Contains(req.Header.Get("Accept"), "text/event-stream") {
+		r.events.Stream(w, req)
+		return
+	}
+	r.jobs.Events(w, req)
+}
+
 // handleJobDownload routes requests for /api/v2/jobs/{id}/download
 func (r *Router) handleJobDownload(w http.ResponseWriter, req *http.Request) {
 	// Use business listings handler (normalized data from business_listings table)
@@ -0,0 +1,306 @@
+// Package autoscale sizes the pool of spawned workers to the job queue.
+// Workers are started when jobs wait and stopped after sitting idle, instead
+// of spawning one container per job.
+package autoscale
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/spawner"
+)
+
+// Policy defaults
+const (
+	DefaultInterval      = 15 * time.Second
+	DefaultIdleTimeout   = 5 * time.Minute
+	DefaultJobsPerWorker = 1
+)
+
+// startupGrace is how long a spawned worker has to register with the
+// manager before it is considered gone
+const startupGrace = 2 * time.Minute
+
+// JobStats provides the job counts the policy works from
+type JobStats interface {
+	GetStats(ctx context.Context) (*domain.JobStats, error)
+}
+
+// Workers lists the workers registered with the manager
+type Workers interface {
+	List(ctx context.Context, params domain.WorkerListParams) ([]*domain.Worker, error)
+}
+
+// Config is the scaling policy
+type Config struct {
+	Interval      time.Duration // How often the pool is evaluated
+	IdleTimeout   time.Duration // How long a spawned worker may idle before it is terminated
+	JobsPerWorker int           // Pending jobs per additional worker
+	MaxWorkers    int           // Max spawned workers (0 = unlimited)
+}
+
+// Scaler spawns and terminates workers from the pending job count and the
+// busy and idle workers. Only workers it spawned itself are terminated.
+type Scaler struct {
+	spawner spawner.Spawner
+	jobs    JobStats
+	workers Workers
+	cfg     Config
+
+	mu        sync.Mutex
+	spawned   map[string]time.Time // Worker ID -> spawn time
+	idleSince map[string]time.Time
+	status    domain.SpawnerStatus
+}
+
+// New creates a new Scaler
+func New(sp spawner.Spawner, jobs JobStats, workers Workers, cfg Config) *Scaler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = DefaultIdleTimeout
+	}
+	if cfg.JobsPerWorker <= 0 {
+		cfg.JobsPerWorker = DefaultJobsPerWorker
+	}
+
+	return &Scaler{
+		spawner:   sp,
+		jobs:      jobs,
+		workers:   workers,
+		cfg:       cfg,
+		spawned:   make(map[string]time.Time),
+		idleSince: make(map[string]time.Time),
+		status: domain.SpawnerStatus{
+			Spawner:            sp.Name(),
+			IntervalSeconds:    int(cfg.Interval / time.Second),
+			IdleTimeoutSeconds: int(cfg.IdleTimeout / time.Second),
+			JobsPerWorker:      cfg.JobsPerWorker,
+			MaxWorkers:         cfg.MaxWorkers,
+			Decision:           domain.ScaleHold,
+		},
+	}
+}
+
+// Run evaluates the pool every interval until ctx is done
+func (s *Scaler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	log.Printf("[Autoscaler] Started (spawner: %s, interval: %s, idle timeout: %s, jobs per worker: %d, max workers: %d)",
+		s.spawner.Name(), s.cfg.Interval, s.cfg.IdleTimeout, s.cfg.JobsPerWorker, s.cfg.MaxWorkers)
+
+	s.evaluate(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("[Autoscaler] Stopped")
+			return nil
+		case <-ticker.C:
+			s.evaluate(ctx)
+		}
+	}
+}
+
+// Status returns the policy and the last decision
+func (s *Scaler) Status() *domain.SpawnerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.status
+	status.SpawnedWorkers = make([]domain.SpawnedWorker, 0, len(s.spawned))
+
+	for id, at := range s.spawned {
+		w := domain.SpawnedWorker{ID: id, SpawnedAt: at}
+		if since, ok := s.idleSince[id]; ok {
+			w.IdleSince = &since
+		}
+		status.SpawnedWorkers = append(status.SpawnedWorkers, w)
+	}
+
+	sort.Slice(status.SpawnedWorkers, func(i, j int) bool {
+		return status.SpawnedWorkers[i].SpawnedAt.Before(status.SpawnedWorkers[j].SpawnedAt)
+	})
+
+	return &status
+}
+
+// evaluate compares the workers the queue needs with the workers there are
+// and spawns or terminates the difference
+func (s *Scaler) evaluate(ctx context.Context) {
+	now := time.Now()
+
+	stats, err := s.jobs.GetStats(ctx)
+	if err != nil {
+		s.recordError(now, "failed to get job stats: "+err.Error())
+		return
+	}
+
+	workers, err := s.workers.List(ctx, domain.WorkerListParams{})
+	if err != nil {
+		s.recordError(now, "failed to list workers: "+err.Error())
+		return
+	}
+
+	online := make(map[string]*domain.Worker, len(workers))
+	draining := make(map[string]bool)
+	busy, idle := 0, 0
+
+	for _, w := range workers {
+		if w.Status == domain.WorkerStatusOffline || !w.IsOnline(domain.HeartbeatTimeout) {
+			continue
+		}
+
+		// On its way out: takes no jobs, so it is no capacity, and exits on
+		// its own
+		if w.IsDraining() {
+			draining[w.ID] = true
+			continue
+		}
+
+		online[w.ID] = w
+		if w.Status == domain.WorkerStatusBusy {
+			busy++
+		} else {
+			idle++
+		}
+	}
+
+	pending := stats.Pending + stats.Queued
+	desired := busy + (pending+s.cfg.JobsPerWorker-1)/s.cfg.JobsPerWorker
+
+	s.mu.Lock()
+
+	starting := 0
+	var gone, expired []string
+
+	for id, at := range s.spawned {
+		w, ok := online[id]
+
+		switch {
+		case ok && w.Status == domain.WorkerStatusIdle:
+			since, seen := s.idleSince[id]
+			if !seen {
+				since = now
+				s.idleSince[id] = now
+			}
+			if now.Sub(since) >= s.cfg.IdleTimeout {
+				expired = append(expired, id)
+			}
+		case ok, draining[id]:
+			delete(s.idleSince, id)
+		case now.Sub(at) < startupGrace:
+			starting++
+		default:
+			gone = append(gone, id)
+			delete(s.spawned, id)
+			delete(s.idleSince, id)
+		}
+	}
+
+	current := len(online) + starting
+	decision, count := domain.ScaleHold, 0
+
+	switch {
+	case desired > current:
+		count = desired - current
+		if s.cfg.MaxWorkers > 0 {
+			count = min(count, s.cfg.MaxWorkers-len(s.spawned))
+		}
+		if count > 0 {
+			decision = domain.ScaleUp
+		}
+	case desired < current && len(expired) > 0:
+		// Longest idle first
+		sort.Slice(expired, func(i, j int) bool {
+			return s.idleSince[expired[i]].Before(s.idleSince[expired[j]])
+		})
+		count = min(current-desired, len(expired))
+		expired = expired[:count]
+		decision = domain.ScaleDown
+	}
+
+	s.mu.Unlock()
+
+	// Workers that exited or never registered; their container or service
+	// may already be gone, so errors are expected
+	for _, id := range gone {
+		log.Printf("[Autoscaler] Worker %s is not registered, dropping it", id)
+		_ = s.spawner.Terminate(ctx, id)
+	}
+
+	delta := 0
+	var errMsg string
+
+	switch decision {
+	case domain.ScaleUp:
+		log.Printf("[Autoscaler] %d pending jobs, %d busy and %d idle workers, %d starting: spawning %d",
+			pending, busy, idle, starting, count)
+
+		for range count {
+			result, err := s.spawner.Spawn(ctx, &spawner.SpawnRequest{})
+			if err != nil {
+				errMsg = "failed to spawn worker: " + err.Error()
+				break
+			}
+			if result.Error != "" {
+				errMsg = "spawner refused worker: " + result.Error
+				break
+			}
+
+			s.mu.Lock()
+			s.spawned[result.WorkerID] = time.Now()
+			s.mu.Unlock()
+			delta++
+		}
+	case domain.ScaleDown:
+		log.Printf("[Autoscaler] %d pending jobs, %d busy and %d idle workers: terminating %d idle for %s",
+			pending, busy, idle, count, s.cfg.IdleTimeout)
+
+		for _, id := range expired {
+			if err := s.spawner.Terminate(ctx, id); err != nil {
+				errMsg = "failed to terminate worker " + id + ": " + err.Error()
+				continue
+			}
+
+			s.mu.Lock()
+			delete(s.spawned, id)
+			delete(s.idleSince, id)
+			s.mu.Unlock()
+			delta--
+		}
+	}
+
+	if errMsg != "" {
+		log.Printf("[Autoscaler] WARNING: %s", errMsg)
+	}
+
+	s.mu.Lock()
+	s.status.PendingJobs = pending
+	s.status.BusyWorkers = busy
+	s.status.IdleWorkers = idle
+	s.status.StartingWorkers = starting
+	s.status.DesiredWorkers = desired
+	s.status.Decision = decision
+	s.status.Delta = delta
+	s.status.EvaluatedAt = &now
+	s.status.Error = errMsg
+	s.mu.Unlock()
+}
+
+func (s *Scaler) recordError(now time.Time, msg string) {
+	log.Printf("[Autoscaler] WARNING: %s", msg)
+
+	s.mu.Lock()
+	s.status.Decision = domain.ScaleHold
+	s.status.Delta = 0
+	s.status.EvaluatedAt = &now
+	s.status.Error = msg
+	s.mu.Unlock()
+}
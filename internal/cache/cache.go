@@ -36,6 +36,9 @@ const (
 
 	// KeyPrefixDashboardSearch is the prefix for search results
 	KeyPrefixDashboardSearch = "cache:dashboard:search"
+
+	// KeyPrefixDashboardTimeSeries is the prefix for stats time series
+	KeyPrefixDashboardTimeSeries = "cache:dashboard:timeseries"
 )
 
 // TTL configurations for different cache types
@@ -54,4 +57,7 @@ const (
 
 	// TTLSearch is the TTL for search results (30 seconds)
 	TTLSearch = 30 * time.Second
+
+	// TTLTimeSeries is the TTL for stats time series (5 minutes)
+	TTLTimeSeries = 5 * time.Minute
 )
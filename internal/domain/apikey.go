@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey scopes
+const (
+	ScopeJobsRead    = "jobs:read"
+	ScopeJobsWrite   = "jobs:write"
+	ScopeResultsRead = "results:read"
+	ScopeWorkers     = "workers:*"
+
+	// ScopeAdmin is only granted to the legacy API_TOKEN and guards
+	// key management and ProxyGate administration
+	ScopeAdmin = "admin"
+)
+
+// ValidScopes lists the scopes that can be assigned to an API key
+var ValidScopes = []string{
+	ScopeJobsRead,
+	ScopeJobsWrite,
+	ScopeResultsRead,
+	ScopeWorkers,
+}
+
+// IsValidScope checks if a scope can be assigned to an API key
+func IsValidScope(scope string) bool {
+	for _, s := range ValidScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey represents a scoped API key for accessing the manager API
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // First characters of the key, for identification
+	KeyHash    string     `json:"-"`      // SHA-256 of the full key, never exposed
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// MonthlyPlaceQuota caps the places scraped for jobs created with the
+	// key per calendar month. Nil means unlimited.
+	MonthlyPlaceQuota *int `json:"monthly_place_quota,omitempty"`
+
+	// ExportProfile names the profile every download with the key goes
+	// through. Nil means downloads are unrestricted.
+	ExportProfile *string `json:"export_profile,omitempty"`
+}
+
+// IsExpired returns true if the key has an expiry in the past
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt)
+}
+
+// HasScope checks if the key grants the required scope.
+// A scope ending in ":*" grants every scope in the same group.
+func (k *APIKey) HasScope(required string) bool {
+	group, _, _ := strings.Cut(required, ":")
+	for _, s := range k.Scopes {
+		if s == required || s == group+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateAPIKeyRequest represents a request to create an API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	MonthlyPlaceQuota *int    `json:"monthly_place_quota,omitempty"`
+	ExportProfile     *string `json:"export_profile,omitempty"`
+}
+
+type apiKeyContextKey struct{}
+
+// ContextWithAPIKey returns a copy of ctx carrying the API key that
+// authenticated the request
+func ContextWithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext returns the API key stored by ContextWithAPIKey, or nil
+// for requests authenticated with the legacy token
+func APIKeyFromContext(ctx context.Context) *APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*APIKey)
+	return key
+}
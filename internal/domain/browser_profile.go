@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Browser profile presets selectable with a job's browser_profile
+const (
+	BrowserProfileDesktopChrome = "desktop_chrome"
+	BrowserProfileMobileAndroid = "mobile_android"
+)
+
+// Header length limits; real browsers stay well below them
+const (
+	MaxUserAgentLength      = 512
+	MaxAcceptLanguageLength = 128
+)
+
+// Browser profile errors
+var (
+	ErrUnknownBrowserProfile = errors.New("unknown browser_profile")
+	ErrInvalidUserAgent      = fmt.Errorf("user_agent must be 1 to %d printable characters", MaxUserAgentLength)
+	ErrInvalidAcceptLanguage = fmt.Errorf("accept_language must be 1 to %d printable characters", MaxAcceptLanguageLength)
+)
+
+// BrowserProfile is the User-Agent and Accept-Language a job sends to
+// Google Maps
+type BrowserProfile struct {
+	UserAgent      string `json:"user_agent"`
+	AcceptLanguage string `json:"accept_language"`
+}
+
+// BrowserProfiles are the named presets a job can select instead of
+// spelling out its headers
+var BrowserProfiles = map[string]BrowserProfile{
+	BrowserProfileDesktopChrome: {
+		UserAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
+		AcceptLanguage: "en-US,en;q=0.9",
+	},
+	BrowserProfileMobileAndroid: {
+		UserAgent:      "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Mobile Safari/537.36",
+		AcceptLanguage: "en-US,en;q=0.9",
+	},
+}
+
+// ResolveBrowserProfile returns the headers a job sends: the preset named
+// profile, if any, overridden by userAgent and acceptLanguage when they are
+// set. Empty results leave the scraper's default headers in place.
+func ResolveBrowserProfile(profile, userAgent, acceptLanguage string) (BrowserProfile, error) {
+	var resolved BrowserProfile
+
+	if profile != "" {
+		preset, ok := BrowserProfiles[profile]
+		if !ok {
+			return resolved, fmt.Errorf("%w %q (%s)", ErrUnknownBrowserProfile, profile, strings.Join(BrowserProfileNames(), ", "))
+		}
+		resolved = preset
+	}
+
+	if userAgent != "" {
+		if !validHeaderValue(userAgent, MaxUserAgentLength) {
+			return resolved, ErrInvalidUserAgent
+		}
+		resolved.UserAgent = strings.TrimSpace(userAgent)
+	}
+	if acceptLanguage != "" {
+		if !validHeaderValue(acceptLanguage, MaxAcceptLanguageLength) {
+			return resolved, ErrInvalidAcceptLanguage
+		}
+		resolved.AcceptLanguage = strings.TrimSpace(acceptLanguage)
+	}
+
+	return resolved, nil
+}
+
+// BrowserProfileNames returns the preset names in a stable order
+func BrowserProfileNames() []string {
+	return []string{BrowserProfileDesktopChrome, BrowserProfileMobileAndroid}
+}
+
+// IsBrowserProfileError reports whether err comes from ResolveBrowserProfile
+func IsBrowserProfileError(err error) bool {
+	return errors.Is(err, ErrUnknownBrowserProfile) ||
+		errors.Is(err, ErrInvalidUserAgent) ||
+		errors.Is(err, ErrInvalidAcceptLanguage)
+}
+
+// Headers returns the profile as HTTP request headers, leaving out unset ones
+func (p BrowserProfile) Headers() map[string]string {
+	headers := make(map[string]string, 2)
+	if p.UserAgent != "" {
+		headers["User-Agent"] = p.UserAgent
+	}
+	if p.AcceptLanguage != "" {
+		headers["Accept-Language"] = p.AcceptLanguage
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// validHeaderValue rejects blank and overlong values and control characters,
+// which could smuggle extra headers into the request
+func validHeaderValue(value string, maxLen int) bool {
+	value = strings.TrimSpace(value)
+	if value == "" || len(value) > maxLen {
+		return false
+	}
+	for _, r := range value {
+		if r < 0x20 || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestHeaders returns the headers the job's requests to Google Maps
+// carry, or nil when it uses the scraper defaults
+func (c JobConfig) RequestHeaders() map[string]string {
+	return BrowserProfile{UserAgent: c.UserAgent, AcceptLanguage: c.AcceptLanguage}.Headers()
+}
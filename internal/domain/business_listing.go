@@ -1,34 +1,139 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // BusinessListing represents a normalized business listing
 type BusinessListing struct {
-	ID              int64       `json:"id"`
-	ResultID        int64       `json:"result_id"`
-	JobID           *string     `json:"job_id,omitempty"`
-	PlaceID         *string     `json:"place_id,omitempty"`
-	CID             *string     `json:"cid,omitempty"`
-	Title           string      `json:"title"`
-	Category        *string     `json:"category,omitempty"`
-	Categories      []string    `json:"categories,omitempty"`
-	Address         *string     `json:"address,omitempty"`
-	Phone           *string     `json:"phone,omitempty"`
-	Website         *string     `json:"website,omitempty"`
-	Latitude        *float64    `json:"latitude,omitempty"`
-	Longitude       *float64    `json:"longitude,omitempty"`
-	AddressCity     *string     `json:"address_city,omitempty"`
-	AddressCountry  *string     `json:"address_country,omitempty"`
-	ReviewCount     int         `json:"review_count"`
-	ReviewRating    *float64    `json:"review_rating,omitempty"`
-	Status          *string     `json:"status,omitempty"`
-	PriceRange      *string     `json:"price_range,omitempty"`
-	Link            *string     `json:"link,omitempty"`
-	CreatedAt       string      `json:"created_at"`
-	Emails          []string    `json:"emails,omitempty"`
-	EmailsWithInfo  []EmailInfo `json:"emails_with_info,omitempty"`
-	ValidEmailCount int         `json:"valid_email_count"`
-	TotalEmailCount int         `json:"total_email_count"`
+	ID              int64               `json:"id"`
+	ResultID        int64               `json:"result_id"`
+	JobID           *string             `json:"job_id,omitempty"`
+	PlaceID         *string             `json:"place_id,omitempty"`
+	CID             *string             `json:"cid,omitempty"`
+	DataID          *string             `json:"data_id,omitempty"`
+	Title           string              `json:"title"`
+	Category        *string             `json:"category,omitempty"`
+	Categories      []string            `json:"categories,omitempty"`
+	Address         *string             `json:"address,omitempty"`
+	Phone           *string             `json:"phone,omitempty"`
+	PhoneE164       *string             `json:"phone_e164,omitempty"` // NULL when unparseable
+	Website         *string             `json:"website,omitempty"`
+	Latitude        *float64            `json:"latitude,omitempty"`
+	Longitude       *float64            `json:"longitude,omitempty"`
+	AddressCity     *string             `json:"address_city,omitempty"`
+	AddressCountry  *string             `json:"address_country,omitempty"`
+	ReviewCount     int                 `json:"review_count"`
+	ReviewRating    *float64            `json:"review_rating,omitempty"`
+	Status          *string             `json:"status,omitempty"`
+	BusinessStatus  string              `json:"business_status"`   // Status normalized, see BusinessStatusOpen and the others
+	Completeness    string              `json:"data_completeness"` // CompletenessFull or CompletenessPartial
+	PriceRange      *string             `json:"price_range,omitempty"`
+	Link            *string             `json:"link,omitempty"`
+	ReviewsLink     *string             `json:"reviews_link,omitempty"`
+	PlusCode        *string             `json:"plus_code,omitempty"`
+	Timezone        *string             `json:"timezone,omitempty"`
+	Description     *string             `json:"description,omitempty"`
+	CreatedAt       string              `json:"created_at"`
+	ImageURLs       []string            `json:"image_urls,omitempty"`
+	Attributes      map[string][]string `json:"attributes,omitempty"`
+	SocialLinks     map[string]string   `json:"social_links,omitempty"`
+	WebsitePhone    *string             `json:"website_phone,omitempty"`
+	WebsiteDesc     *string             `json:"website_description,omitempty"`
+	Emails          []string            `json:"emails,omitempty"`
+	EmailsWithInfo  []EmailInfo         `json:"emails_with_info,omitempty"`
+	ValidEmailCount int                 `json:"valid_email_count"`
+	TotalEmailCount int                 `json:"total_email_count"`
+
+	// Category in the canonical taxonomy, the raw category when no
+	// category_mappings alias matches it
+	CanonicalCategory *string `json:"canonical_category,omitempty"`
+
+	// Set by incremental jobs: whether no earlier listing had the place,
+	// and the job that found it first
+	IsNew          *bool   `json:"is_new,omitempty"`
+	FirstSeenJobID *string `json:"first_seen_job_id,omitempty"`
+
+	// Parsed from the address where complete_address lacks them. Street
+	// includes the house number.
+	AddressStreet     *string `json:"address_street,omitempty"`
+	AddressNumber     *string `json:"address_number,omitempty"`
+	AddressPostalCode *string `json:"address_postal_code,omitempty"`
+	AddressState      *string `json:"address_state,omitempty"`
+
+	// Review count by star, 1 to 5, nil when the place data had no
+	// histogram. BayesianRating is the rating weighed with the rating
+	// prior at ingestion, see RatingPrior; nil without reviews.
+	ReviewsPerRating map[int]int `json:"reviews_per_rating,omitempty"`
+	BayesianRating   *float64    `json:"bayesian_rating,omitempty"`
+
+	// Parsed from the displayed opening hours, nil when the place lists
+	// none or they did not parse
+	OpeningHours *OpeningHours `json:"opening_hours,omitempty"`
+
+	// Set once the website of a listing whose job had CheckWebsite was
+	// checked, see WebsiteStatusOK and the other statuses
+	WebsiteStatus     *string    `json:"website_status,omitempty"`
+	WebsiteHTTPStatus *int       `json:"website_http_status,omitempty"`
+	WebsiteFinalURL   *string    `json:"website_final_url,omitempty"`
+	WebsiteCheckedAt  *time.Time `json:"website_checked_at,omitempty"`
+}
+
+// OpeningHours is the schedule of a listing as gmaps.ParsedHours stores it,
+// by lowercase English weekday. Days the place does not list are missing.
+type OpeningHours struct {
+	Days    map[string]OpeningDay `json:"days"`
+	Open24h bool                  `json:"open_24h"`
+}
+
+// Weekdays are the keys of OpeningHours.Days
+var Weekdays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// ParseWeekday reads an English weekday, as open_on takes it
+func ParseWeekday(s string) (string, error) {
+	day := strings.ToLower(strings.TrimSpace(s))
+	for _, d := range Weekdays {
+		if day == d {
+			return day, nil
+		}
+	}
+
+	return "", fmt.Errorf("%q is not a weekday, use monday to sunday", s)
+}
+
+// OpeningDay is closed, open around the clock or open in its intervals
+type OpeningDay struct {
+	Closed    bool              `json:"closed,omitempty"`
+	Open24h   bool              `json:"open_24h,omitempty"`
+	Intervals []OpeningInterval `json:"intervals,omitempty"`
+}
+
+// OpeningInterval is in minutes since midnight; Close is past 1440 after
+// midnight
+type OpeningInterval struct {
+	Open  int `json:"open"`
+	Close int `json:"close"`
+}
+
+// IsOpen reports whether the listing opens on day. known is false when the
+// day is not listed.
+func (h *OpeningHours) IsOpen(day string) (open, known bool) {
+	if h == nil {
+		return false, false
+	}
+
+	d, ok := h.Days[day]
+	if !ok {
+		return false, false
+	}
+
+	return !d.Closed, true
 }
 
 // EmailInfo contains email with validation status
@@ -41,27 +146,185 @@ type EmailInfo struct {
 
 // BusinessListingFilter contains filter parameters for queries
 type BusinessListingFilter struct {
-	JobID       *uuid.UUID
-	Search      string // Search in title, address, phone, category
-	Category    string
-	City        string
-	Country     string
-	MinRating   *float64
-	HasEmail    *bool
-	EmailStatus string // api_valid, api_invalid, pending, local_valid
-	Page        int
-	PerPage     int
-	SortBy      string // created_at, review_rating, review_count, title
-	SortOrder   string // asc, desc
+	JobID             *uuid.UUID
+	JobTag            string // Listings of the jobs carrying the tag
+	Search            string // Search in title, address, phone, category
+	Category          string
+	CanonicalCategory string // Category in the canonical taxonomy, see CategoryMapping
+	City              string
+	Country           string
+	State             string // Case-insensitive
+	Postcode          string // Prefix of the postal code
+	MinRating         *float64
+	HasEmail          *bool
+	HasValidPhone     *bool        // Phone parsed to E.164, or not
+	EmailStatus       string       // api_valid, api_invalid, pending, local_valid
+	WebsiteStatus     string       // ok, parked, http_error, unreachable
+	BusinessStatus    string       // open, temporarily_closed, permanently_closed, unknown
+	Completeness      string       // full, partial
+	Timezone          string       // IANA timezone, e.g. "America/Chicago"
+	Attribute         string       // Enabled attribute in any section, e.g. "Delivery"
+	OnlyNew           bool         // Only places an incremental job flagged as new
+	BBox              *BoundingBox // Listings with coordinates inside the box
+	OpenOn            string       // Weekday the listing opens on, e.g. "sunday"
+	Page              int
+	PerPage           int
+	SortBy            string         // created_at, review_rating, review_count, title
+	SortOrder         string         // asc, desc
+	Cursor            *ListingCursor // Keyset pagination instead of Page; the total is not counted
+}
+
+// ListingCursor is the (created_at, id) of the last listing of a page. A
+// page after it holds the listings that sort after that key, so rows
+// inserted meanwhile do not shift pages. The zero cursor starts at the
+// first listing.
+type ListingCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// IsZero reports whether the cursor starts at the first listing
+func (c *ListingCursor) IsZero() bool {
+	return c.ID == 0 && c.CreatedAt.IsZero()
+}
+
+// String encodes the cursor as an opaque token for next_cursor
+func (c *ListingCursor) String() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixMicro(), 10) + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ListingCursorAfter returns the cursor of a page ending with l
+func ListingCursorAfter(l *BusinessListing) (*ListingCursor, error) {
+	createdAt, err := time.Parse(time.RFC3339Nano, l.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid created_at %q: %w", l.CreatedAt, err)
+	}
+
+	return &ListingCursor{CreatedAt: createdAt, ID: l.ID}, nil
+}
+
+// ParseListingCursor decodes a token made by ListingCursor.String. An
+// empty token is the zero cursor.
+func ParseListingCursor(token string) (*ListingCursor, error) {
+	if token == "" {
+		return &ListingCursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor: %w", err)
+	}
+
+	micros, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+
+	us, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor: %w", err)
+	}
+
+	c := &ListingCursor{CreatedAt: time.UnixMicro(us).UTC()}
+	if c.ID, err = strconv.ParseInt(id, 10, 64); err != nil || c.ID <= 0 {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+
+	return c, nil
 }
 
 // BusinessListingStats contains aggregate statistics
 type BusinessListingStats struct {
-	TotalListings int      `json:"total_listings"`
-	TotalJobs     int      `json:"total_jobs"`
-	TotalEmails   int      `json:"total_emails"`
-	ValidEmails   int      `json:"valid_emails"`
-	WithPhone     int      `json:"with_phone"`
-	WithWebsite   int      `json:"with_website"`
-	AvgRating     *float64 `json:"avg_rating,omitempty"`
+	TotalListings      int      `json:"total_listings"`
+	TotalJobs          int      `json:"total_jobs"`
+	TotalEmails        int      `json:"total_emails"`
+	ValidEmails        int      `json:"valid_emails"`
+	WithPhone          int      `json:"with_phone"`
+	ValidPhones        int      `json:"valid_phones"`
+	PhoneParseFailures int      `json:"phone_parse_failures"` // Phones that are not valid E.164
+	HoursParseFailures int      `json:"hours_parse_failures"` // Opening hours kept only as displayed
+	WithWebsite        int      `json:"with_website"`
+	AvgRating          *float64 `json:"avg_rating,omitempty"`
+
+	// Listings by business status, every status included
+	ByBusinessStatus map[string]int `json:"by_business_status"`
+
+	// Normalization is how far listings are behind the ingested results
+	Normalization *NormalizationLag `json:"normalization,omitempty"`
+}
+
+// Result samples: n random listings, DefaultSampleSize unless asked for up
+// to MaxSampleSize
+const (
+	DefaultSampleSize = 50
+	MaxSampleSize     = 500
+)
+
+// ListingSample is a random sample of the listings of a job, or of all of
+// them, with the quality of those listings
+type ListingSample struct {
+	Listings []*BusinessListing `json:"listings"`
+	Quality  ListingQuality     `json:"quality"`
+}
+
+// ListingQuality measures how completely listings were scraped and parsed.
+// It covers every listing sampled from, not only the sample.
+type ListingQuality struct {
+	Listings int `json:"listings"`
+
+	// Percent of the listings with the column non-empty, by column: phone,
+	// website, email and city
+	FillRates map[string]float64 `json:"fill_rates"`
+
+	AvgRating *float64 `json:"avg_rating,omitempty"`
+
+	// Listings whose phone or opening hours did not parse, or whose
+	// address gave no city
+	ParseFailures int `json:"parse_failures"`
+}
+
+// FieldStatsDistinctCap caps the distinct values reported for a column; a
+// column with more is as good as unique
+const FieldStatsDistinctCap = 10000
+
+// ListingFieldStats describes how the listings of a job fill each column
+// they export
+type ListingFieldStats struct {
+	JobID    uuid.UUID `json:"job_id"`
+	Listings int       `json:"listings"`
+
+	// LatestResultAt is when the newest result of the job was normalized,
+	// nil while it has none
+	LatestResultAt *time.Time `json:"latest_result_at,omitempty"`
+
+	Coverage ListingCoverage `json:"coverage"`
+	Columns  []ColumnStats   `json:"columns"` // In export order
+}
+
+// ListingCoverage counts the listings that can be contacted
+type ListingCoverage struct {
+	WithEmail      int `json:"with_email"`
+	WithValidEmail int `json:"with_valid_email"` // At least one acceptable email
+	WithPhone      int `json:"with_phone"`
+	WithValidPhone int `json:"with_valid_phone"` // Phone parsed to E.164
+	WithWebsite    int `json:"with_website"`
+}
+
+// ColumnStats describes the values of one export column across listings.
+// A value is empty when the column exports as "".
+type ColumnStats struct {
+	Column   string  `json:"column"`
+	Filled   int     `json:"filled"`
+	FillRate float64 `json:"fill_rate"` // Percent of the listings, rounded to 0.1
+
+	// Distinct non-empty values, at most FieldStatsDistinctCap
+	Distinct       int  `json:"distinct"`
+	DistinctCapped bool `json:"distinct_capped,omitempty"`
+
+	// Numeric columns only (review_rating, review_count), over the
+	// non-empty values
+	Min *float64 `json:"min,omitempty"`
+	Avg *float64 `json:"avg,omitempty"`
+	Max *float64 `json:"max,omitempty"`
 }
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListingCursorRoundTrip(t *testing.T) {
+	c, err := ListingCursorAfter(&BusinessListing{ID: 42, CreatedAt: "2026-03-01T10:20:30.123456Z"})
+	require.NoError(t, err)
+
+	parsed, err := ParseListingCursor(c.String())
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), parsed.ID)
+	assert.True(t, parsed.CreatedAt.Equal(c.CreatedAt))
+
+	zero, err := ParseListingCursor("")
+	require.NoError(t, err)
+	assert.True(t, zero.IsZero())
+
+	for _, bad := range []string{"!!", "bm9jb2xvbg", "MTIzOmFiYw"} {
+		_, err := ParseListingCursor(bad)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,18 @@
+package domain
+
+// Business statuses of a listing, normalized on ingestion from the status
+// Google words in the language of the job
+const (
+	BusinessStatusOpen              = "open"               // Any status that is not a closure, such as "Open ⋅ Closes 5PM"
+	BusinessStatusTemporarilyClosed = "temporarily_closed" // Closed for now
+	BusinessStatusPermanentlyClosed = "permanently_closed" // Closed for good
+	BusinessStatusUnknown           = "unknown"            // The place showed no status
+)
+
+// ValidBusinessStatuses are the values of business_listings.business_status
+var ValidBusinessStatuses = map[string]bool{
+	BusinessStatusOpen:              true,
+	BusinessStatusTemporarilyClosed: true,
+	BusinessStatusPermanentlyClosed: true,
+	BusinessStatusUnknown:           true,
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxCategoryMappings caps the mappings added by one request
+const MaxCategoryMappings = 500
+
+// ErrInvalidCategoryMapping is returned for a mapping without an alias or a
+// canonical name
+var ErrInvalidCategoryMapping = errors.New("invalid category mapping")
+
+// CategoryMapping maps a category as Google lists it, in any language, to
+// a name of the canonical taxonomy. Aliases are stored lower-cased and
+// match categories regardless of case.
+type CategoryMapping struct {
+	Alias     string    `json:"alias"`
+	Canonical string    `json:"canonical"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NormalizeCategoryMappings trims the mappings and lower-cases their
+// aliases. A repeated alias keeps its last mapping.
+func NormalizeCategoryMappings(mappings []CategoryMapping) ([]CategoryMapping, error) {
+	if len(mappings) == 0 {
+		return nil, fmt.Errorf("%w: no mappings", ErrInvalidCategoryMapping)
+	}
+	if len(mappings) > MaxCategoryMappings {
+		return nil, fmt.Errorf("%w: at most %d mappings per request", ErrInvalidCategoryMapping, MaxCategoryMappings)
+	}
+
+	index := make(map[string]int, len(mappings))
+	out := make([]CategoryMapping, 0, len(mappings))
+	for _, m := range mappings {
+		alias := strings.ToLower(strings.TrimSpace(m.Alias))
+		canonical := strings.TrimSpace(m.Canonical)
+		if alias == "" || canonical == "" {
+			return nil, fmt.Errorf("%w: alias and canonical are required", ErrInvalidCategoryMapping)
+		}
+
+		m = CategoryMapping{Alias: alias, Canonical: canonical}
+		if i, ok := index[alias]; ok {
+			out[i] = m
+			continue
+		}
+		index[alias] = len(out)
+		out = append(out, m)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCategoryMappings(t *testing.T) {
+	mappings, err := NormalizeCategoryMappings([]CategoryMapping{
+		{Alias: " Restaurante ", Canonical: " Restaurant "},
+		{Alias: "Trattoria", Canonical: "Cafe"},
+		{Alias: "TRATTORIA", Canonical: "Restaurant"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []CategoryMapping{
+		{Alias: "restaurante", Canonical: "Restaurant"},
+		{Alias: "trattoria", Canonical: "Restaurant"},
+	}, mappings)
+
+	for _, invalid := range [][]CategoryMapping{
+		nil,
+		{{Alias: "", Canonical: "Restaurant"}},
+		{{Alias: "Restaurante", Canonical: "  "}},
+		make([]CategoryMapping, MaxCategoryMappings+1),
+	} {
+		_, err = NormalizeCategoryMappings(invalid)
+		assert.ErrorIs(t, err, ErrInvalidCategoryMapping)
+	}
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Lock backends of the manager leader lock
+const (
+	LockBackendRedis    = "redis"
+	LockBackendPostgres = "postgres"
+	LockBackendMemory   = "memory" // One replica only
+)
+
+// ClusterStatus is the leader election state of a manager replica, as
+// returned by GET /api/v2/cluster/status. Every replica serves the API; the
+// leader also runs the singleton background tasks.
+type ClusterStatus struct {
+	ReplicaID      string     `json:"replica_id"`
+	Leader         bool       `json:"leader"`                 // This replica holds the lock
+	LeaderID       string     `json:"leader_id,omitempty"`    // Empty while the lock is free
+	LeaderSince    *time.Time `json:"leader_since,omitempty"` // Set on the leader
+	LockBackend    string     `json:"lock_backend"`
+	LockTTLSeconds int        `json:"lock_ttl_seconds"`
+	Tasks          []string   `json:"tasks"` // Run by the leader only
+}
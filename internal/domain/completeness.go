@@ -0,0 +1,31 @@
+package domain
+
+// Data completeness of a result and its listing. A place whose page could
+// not be scraped is kept with what the search results listed of it, marked
+// partial, until a backfill job upgrades it.
+const (
+	CompletenessFull    = "full"    // Scraped from the page of the place
+	CompletenessPartial = "partial" // Title, category, rating and coordinates from the search results only
+)
+
+// ValidCompleteness are the values of business_listings.data_completeness
+var ValidCompleteness = map[string]bool{
+	CompletenessFull:    true,
+	CompletenessPartial: true,
+}
+
+// ResultCounts are the results stored for a job, for its progress
+type ResultCounts struct {
+	Stored     int // Results of the job
+	Partial    int // Of those, kept partial
+	Backfilled int // Partial results of another job this backfill job upgraded
+}
+
+// Progress returns the scraped and partial places of the counts. A
+// backfill job counts the places it upgraded as scraped.
+func (c ResultCounts) Progress() JobProgress {
+	return JobProgress{
+		ScrapedPlaces: c.Stored - c.Partial + c.Backfilled,
+		PartialPlaces: c.Partial,
+	}
+}
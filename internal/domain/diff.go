@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of change between the listings of two jobs
+const (
+	DiffAdded     = "added"     // Only in the newer job
+	DiffRemoved   = "removed"   // Only in the job compared against
+	DiffChanged   = "changed"   // In both, with at least one compared field different
+	DiffUnchanged = "unchanged" // In both, every compared field equal
+)
+
+// DiffChanges lists every kind of change in report order
+var DiffChanges = []string{DiffAdded, DiffRemoved, DiffChanged, DiffUnchanged}
+
+// ListingSnapshot holds the fields of a listing that a diff compares
+type ListingSnapshot struct {
+	ListingID    int64    `json:"listing_id"`
+	Title        string   `json:"title"`
+	Category     *string  `json:"category,omitempty"`
+	Address      *string  `json:"address,omitempty"`
+	Phone        *string  `json:"phone,omitempty"` // E.164 when it parsed
+	Website      *string  `json:"website,omitempty"`
+	Status       *string  `json:"status,omitempty"`
+	ReviewCount  int      `json:"review_count"`
+	ReviewRating *float64 `json:"review_rating,omitempty"`
+
+	// Status normalized, and when the listing was last seen turning
+	// permanently closed on being upserted again
+	BusinessStatus string     `json:"business_status"`
+	ClosedAt       *time.Time `json:"closed_at,omitempty"`
+}
+
+// FieldChange is one field of a place that differs between two jobs
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// ListingDiff is one place in a diff of two jobs. Places are matched by
+// place ID, falling back to CID. Before is the place in the job compared
+// against, After the place in the newer job.
+type ListingDiff struct {
+	Key     string           `json:"key"`
+	Change  string           `json:"change"`
+	Closed  bool             `json:"closed,omitempty"` // Newly permanently closed
+	Before  *ListingSnapshot `json:"before,omitempty"`
+	After   *ListingSnapshot `json:"after,omitempty"`
+	Changes []FieldChange    `json:"changes,omitempty"`
+}
+
+// ListingDiffSummary counts the places of a diff by change, and the
+// changed places by field
+type ListingDiffSummary struct {
+	Added              int `json:"added"`
+	Removed            int `json:"removed"`
+	Changed            int `json:"changed"`
+	Unchanged          int `json:"unchanged"`
+	Closed             int `json:"closed"`
+	RatingChanged      int `json:"rating_changed"`
+	ReviewCountChanged int `json:"review_count_changed"`
+	PhoneChanged       int `json:"phone_changed"`
+	WebsiteChanged     int `json:"website_changed"`
+}
+
+// ListingDiffFilter selects the places of a diff of JobID against
+// AgainstID
+type ListingDiffFilter struct {
+	JobID     uuid.UUID
+	AgainstID uuid.UUID
+	Changes   []string // Kinds of change to include
+	Page      int
+	PerPage   int
+}
+
+// NewlyClosed reports whether the place closed for good in the newer job:
+// it is permanently closed there but was not in the job compared against,
+// or its listing was recorded turning permanently closed
+func (d *ListingDiff) NewlyClosed() bool {
+	if d.After == nil || d.After.BusinessStatus != BusinessStatusPermanentlyClosed {
+		return false
+	}
+	if d.Before != nil {
+		return d.Before.BusinessStatus != BusinessStatusPermanentlyClosed
+	}
+	return d.After.ClosedAt != nil
+}
+
+// Compare fills in Changes and Closed from Before and After
+func (d *ListingDiff) Compare() {
+	d.Changes = nil
+	d.Closed = d.NewlyClosed()
+	if d.Before == nil || d.After == nil {
+		return
+	}
+
+	b, a := d.Before, d.After
+	add := func(field string, before, after any) {
+		d.Changes = append(d.Changes, FieldChange{Field: field, Before: before, After: after})
+	}
+
+	if b.Title != a.Title {
+		add("title", b.Title, a.Title)
+	}
+	if !equalPtr(b.Category, a.Category) {
+		add("category", b.Category, a.Category)
+	}
+	if !equalPtr(b.Address, a.Address) {
+		add("address", b.Address, a.Address)
+	}
+	if !equalPtr(b.Phone, a.Phone) {
+		add("phone", b.Phone, a.Phone)
+	}
+	if !equalPtr(b.Website, a.Website) {
+		add("website", b.Website, a.Website)
+	}
+	if !equalPtr(b.Status, a.Status) {
+		add("status", b.Status, a.Status)
+	}
+	if b.ReviewCount != a.ReviewCount {
+		add("review_count", b.ReviewCount, a.ReviewCount)
+	}
+	if !equalPtr(b.ReviewRating, a.ReviewRating) {
+		add("review_rating", b.ReviewRating, a.ReviewRating)
+	}
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
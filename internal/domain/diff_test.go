@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListingDiffCompare(t *testing.T) {
+	str := func(s string) *string { return &s }
+	rating := func(f float64) *float64 { return &f }
+
+	before := &ListingSnapshot{
+		Title:        "Cafe Luna",
+		Phone:        str("+4930123456"),
+		Status:       str("Open"),
+		ReviewCount:  10,
+		ReviewRating: rating(4.2),
+
+		BusinessStatus: BusinessStatusOpen,
+	}
+	after := &ListingSnapshot{
+		Title:        "Cafe Luna",
+		Phone:        str("+4930123456"),
+		Website:      str("https://cafeluna.de"),
+		Status:       str("Dauerhaft geschlossen"),
+		ReviewCount:  12,
+		ReviewRating: rating(4.2),
+
+		BusinessStatus: BusinessStatusPermanentlyClosed,
+	}
+
+	d := &ListingDiff{Change: DiffChanged, Before: before, After: after}
+	d.Compare()
+
+	assert.True(t, d.Closed)
+	assert.Equal(t, []FieldChange{
+		{Field: "website", Before: (*string)(nil), After: after.Website},
+		{Field: "status", Before: before.Status, After: after.Status},
+		{Field: "review_count", Before: 10, After: 12},
+	}, d.Changes)
+
+	added := &ListingDiff{Change: DiffAdded, After: after}
+	added.Compare()
+	assert.Empty(t, added.Changes)
+	assert.False(t, added.Closed)
+
+	// An added place counts when its listing was seen closing
+	closedAt := time.Now()
+	after.ClosedAt = &closedAt
+	added.Compare()
+	assert.True(t, added.Closed)
+
+	before.BusinessStatus = BusinessStatusPermanentlyClosed
+	d.Compare()
+	assert.False(t, d.Closed, "closed in both jobs")
+}
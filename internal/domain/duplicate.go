@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// Duplicate detection criteria
+const (
+	DuplicateByPhone     = "phone"
+	DuplicateByWebsite   = "website"
+	DuplicateByProximity = "title_proximity"
+)
+
+// DuplicateCriteria lists every criterion in report order
+var DuplicateCriteria = []string{DuplicateByPhone, DuplicateByWebsite, DuplicateByProximity}
+
+// DuplicateCluster is a group of business listings that look like the same
+// place. Key is the normalized phone, the website domain or, for
+// title_proximity, the title of the lowest listing ID.
+type DuplicateCluster struct {
+	Criterion  string  `json:"criterion"`
+	Key        string  `json:"key"`
+	ListingIDs []int64 `json:"listing_ids"`
+	Size       int     `json:"size"`
+}
+
+// DuplicateFilter selects clusters for the duplicates report
+type DuplicateFilter struct {
+	Criterion      string // Empty for every criterion
+	MinClusterSize int
+	Page           int
+	PerPage        int
+}
+
+// MergeDuplicatesRequest merges ListingIDs into SurvivorID. The survivor
+// may be listed in ListingIDs as well.
+type MergeDuplicatesRequest struct {
+	SurvivorID int64   `json:"survivor_id"`
+	ListingIDs []int64 `json:"listing_ids"`
+	Criterion  string  `json:"criterion,omitempty"`
+}
+
+// ListingMerge is the audit record of a merge
+type ListingMerge struct {
+	ID         int64     `json:"id"`
+	SurvivorID int64     `json:"survivor_id"`
+	MergedIDs  []int64   `json:"merged_ids"`
+	Criterion  string    `json:"criterion,omitempty"`
+	MergedAt   time.Time `json:"merged_at"`
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// Email validation defaults
+const (
+	// DefaultEmailValidationTTL is how long an API result is reused before
+	// an email seen again is validated anew
+	DefaultEmailValidationTTL = 30 * 24 * time.Hour
+
+	// DefaultEmailValidationWorkers is the number of validator workers the
+	// manager runs
+	DefaultEmailValidationWorkers = 4
+)
+
+// EmailValidationStats counts stored emails by validation state
+type EmailValidationStats struct {
+	Total   int `json:"total"`
+	Pending int `json:"pending"` // Not validated with the API yet
+	Queued  int `json:"queued"`  // Pending and handed to a validator worker
+	Valid   int `json:"valid"`
+	Invalid int `json:"invalid"`
+	Errors  int `json:"errors"` // The API call failed; retried later
+
+	// Emails validated with the API recently, from every manager
+	ValidatedLastMinute int     `json:"validated_last_minute"`
+	ValidatedLastHour   int     `json:"validated_last_hour"`
+	PerMinute           float64 `json:"per_minute"` // Average over the last hour
+
+	// Validator workers of this manager, when enabled
+	Queue *EmailValidationQueueStats `json:"queue,omitempty"`
+}
+
+// EmailValidationQueueStats describes the validator workers of a manager
+// since it started
+type EmailValidationQueueStats struct {
+	Depth     int64 `json:"depth"` // Emails waiting in the queue
+	Workers   int   `json:"workers"`
+	InFlight  int64 `json:"in_flight"`
+	Processed int64 `json:"processed"`
+	CacheHits int64 `json:"cache_hits"`
+	Failed    int64 `json:"failed"`
+}
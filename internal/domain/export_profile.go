@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Export profile errors
+var (
+	ErrInvalidExportProfile   = errors.New("invalid export profile")
+	ErrExportProfileNotFound  = errors.New("export profile not found")
+	ErrExportProfileInUse     = errors.New("export profile is assigned to api keys")
+	ErrExportProfileForbidden = errors.New("api key is restricted to another export profile")
+)
+
+// exportProfileName is what profiles can be named, as export_profile takes it
+var exportProfileName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ExportProfile limits what listing exports carry: only its columns, with
+// emails and coordinates optionally coarsened. An API key with a profile
+// downloads through it whatever it asks for.
+type ExportProfile struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"` // Listing export keys, in export order
+
+	// MaskEmails keeps only the domain of each email; TruncateCoords cuts
+	// coordinates to 3 decimals, about 100 m
+	MaskEmails     bool `json:"mask_emails"`
+	TruncateCoords bool `json:"truncate_coords"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Normalize trims and lower-cases the name and drops repeated columns. The
+// columns are checked against the export schema by the caller.
+func (p *ExportProfile) Normalize() error {
+	p.Name = strings.ToLower(strings.TrimSpace(p.Name))
+	if !exportProfileName.MatchString(p.Name) {
+		return fmt.Errorf("%w: name must be 1-64 lowercase letters, digits, - or _", ErrInvalidExportProfile)
+	}
+
+	columns := make([]string, 0, len(p.Columns))
+	for _, c := range p.Columns {
+		c = strings.TrimSpace(c)
+		if c != "" && !slices.Contains(columns, c) {
+			columns = append(columns, c)
+		}
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("%w: at least one column is required", ErrInvalidExportProfile)
+	}
+	p.Columns = columns
+
+	return nil
+}
+
+// HasColumn tells whether the profile exports a column
+func (p *ExportProfile) HasColumn(key string) bool {
+	return slices.Contains(p.Columns, key)
+}
+
+// Redact returns l with the profile's transforms applied, copied when any
+// applies; l itself is never changed. A nil profile returns l.
+func (p *ExportProfile) Redact(l *BusinessListing) *BusinessListing {
+	if p == nil || (!p.MaskEmails && !p.TruncateCoords) {
+		return l
+	}
+
+	out := *l
+	if p.MaskEmails {
+		out.Emails = emailDomains(l.Emails)
+		out.EmailsWithInfo = nil
+		if l.EmailsWithInfo != nil {
+			out.EmailsWithInfo = make([]EmailInfo, len(l.EmailsWithInfo))
+			for i, e := range l.EmailsWithInfo {
+				e.Email = emailDomain(e.Email)
+				out.EmailsWithInfo[i] = e
+			}
+		}
+	}
+	if p.TruncateCoords {
+		out.Latitude = truncateCoord(l.Latitude)
+		out.Longitude = truncateCoord(l.Longitude)
+	}
+
+	return &out
+}
+
+// emailDomains masks emails to their domains, each domain once
+func emailDomains(emails []string) []string {
+	if emails == nil {
+		return nil
+	}
+	out := make([]string, 0, len(emails))
+	for _, e := range emails {
+		if d := emailDomain(e); !slices.Contains(out, d) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// emailDomain masks an email to its domain, ******** when it has none
+func emailDomain(email string) string {
+	_, domain, ok := strings.Cut(email, "@")
+	if !ok || domain == "" {
+		return "********"
+	}
+	return strings.ToLower(domain)
+}
+
+func truncateCoord(c *float64) *float64 {
+	if c == nil {
+		return nil
+	}
+	v := math.Trunc(*c*1000) / 1000
+	return &v
+}
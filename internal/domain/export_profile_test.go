@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportProfileNormalize(t *testing.T) {
+	p := &ExportProfile{Name: " Partners ", Columns: []string{"title", " email", "title", ""}}
+	require.NoError(t, p.Normalize())
+	assert.Equal(t, "partners", p.Name)
+	assert.Equal(t, []string{"title", "email"}, p.Columns)
+
+	for _, bad := range []*ExportProfile{
+		{Name: "", Columns: []string{"title"}},
+		{Name: "has space", Columns: []string{"title"}},
+		{Name: "ok"},
+	} {
+		assert.ErrorIs(t, bad.Normalize(), ErrInvalidExportProfile, bad.Name)
+	}
+}
+
+func TestExportProfileRedact(t *testing.T) {
+	lat, lon := 52.520008, -13.404954
+	l := &BusinessListing{
+		Title:          "Cafe",
+		Emails:         []string{"jane@Example.com", "info@example.com", "broken"},
+		EmailsWithInfo: []EmailInfo{{Email: "jane@example.com", Status: "valid"}},
+		Latitude:       &lat,
+		Longitude:      &lon,
+	}
+
+	var none *ExportProfile
+	assert.Same(t, l, none.Redact(l))
+	assert.Same(t, l, (&ExportProfile{}).Redact(l))
+
+	out := (&ExportProfile{MaskEmails: true, TruncateCoords: true}).Redact(l)
+	assert.Equal(t, []string{"example.com", "********"}, out.Emails)
+	assert.Equal(t, "example.com", out.EmailsWithInfo[0].Email)
+	assert.Equal(t, "valid", out.EmailsWithInfo[0].Status)
+	assert.Equal(t, 52.52, *out.Latitude)
+	assert.Equal(t, -13.404, *out.Longitude)
+
+	// The listing itself is left alone
+	assert.Equal(t, "jane@Example.com", l.Emails[0])
+	assert.Equal(t, "jane@example.com", l.EmailsWithInfo[0].Email)
+	assert.Equal(t, 52.520008, *l.Latitude)
+}
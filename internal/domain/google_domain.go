@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"strings"
+)
+
+const (
+	// DefaultGoogleDomain is where searches go unless a job picks another.
+	// Jobs created before google_domain have it empty and search it too.
+	DefaultGoogleDomain = "google.com"
+
+	// GoogleDomainAuto picks the domain of the job's proxy country, or of
+	// its language without one
+	GoogleDomainAuto = "auto"
+)
+
+// countryGoogleDomains are the Google domains of countries, by ISO 3166-1
+// alpha-2 code. They are the domains a job may pick.
+var countryGoogleDomains = map[string]string{
+	"AE": "google.ae", "AR": "google.com.ar", "AT": "google.at", "AU": "google.com.au",
+	"BD": "google.com.bd", "BE": "google.be", "BG": "google.bg", "BR": "google.com.br",
+	"CA": "google.ca", "CH": "google.ch", "CL": "google.cl", "CO": "google.com.co",
+	"CZ": "google.cz", "DE": "google.de", "DK": "google.dk", "EG": "google.com.eg",
+	"ES": "google.es", "FI": "google.fi", "FR": "google.fr", "GB": "google.co.uk",
+	"GR": "google.gr", "HK": "google.com.hk", "HU": "google.hu", "ID": "google.co.id",
+	"IE": "google.ie", "IL": "google.co.il", "IN": "google.co.in", "IT": "google.it",
+	"JP": "google.co.jp", "KE": "google.co.ke", "KR": "google.co.kr", "MX": "google.com.mx",
+	"MY": "google.com.my", "NG": "google.com.ng", "NL": "google.nl", "NO": "google.no",
+	"NZ": "google.co.nz", "PE": "google.com.pe", "PH": "google.com.ph", "PK": "google.com.pk",
+	"PL": "google.pl", "PT": "google.pt", "RO": "google.ro", "SA": "google.com.sa",
+	"SE": "google.se", "SG": "google.com.sg", "TH": "google.co.th", "TR": "google.com.tr",
+	"TW": "google.com.tw", "UA": "google.com.ua", "US": "google.com", "VN": "google.com.vn",
+	"ZA": "google.co.za",
+}
+
+// langGoogleDomains are the domains of the market a language stands for,
+// used by auto without a proxy country. Languages spoken in many markets
+// alike, like English and Spanish, keep google.com.
+var langGoogleDomains = map[string]string{
+	"cs": "google.cz", "da": "google.dk", "de": "google.de", "el": "google.gr",
+	"fi": "google.fi", "fr": "google.fr", "he": "google.co.il", "hu": "google.hu",
+	"id": "google.co.id", "it": "google.it", "ja": "google.co.jp", "ko": "google.co.kr",
+	"ms": "google.com.my", "nb": "google.no", "nl": "google.nl", "no": "google.no",
+	"pl": "google.pl", "pt": "google.com.br", "ro": "google.ro", "sv": "google.se",
+	"th": "google.co.th", "tr": "google.com.tr", "uk": "google.com.ua", "vi": "google.com.vn",
+}
+
+// knownGoogleDomains is the set of the values of countryGoogleDomains
+var knownGoogleDomains = func() map[string]bool {
+	known := make(map[string]bool, len(countryGoogleDomains))
+	for _, d := range countryGoogleDomains {
+		known[d] = true
+	}
+	return known
+}()
+
+// normalizeGoogleDomain lowercases a requested domain and strips a scheme
+// and a www. or maps. host, so www.google.de names google.de
+func normalizeGoogleDomain(d string) string {
+	d = strings.ToLower(strings.TrimSpace(d))
+	d = strings.TrimPrefix(d, "https://")
+	d = strings.TrimPrefix(d, "http://")
+	d = strings.TrimSuffix(d, "/")
+	d = strings.TrimPrefix(d, "www.")
+	return strings.TrimPrefix(d, "maps.")
+}
+
+// IsGoogleDomain tells whether d is auto, empty or a known Google domain
+func IsGoogleDomain(d string) bool {
+	d = normalizeGoogleDomain(d)
+	return d == "" || d == GoogleDomainAuto || knownGoogleDomains[d]
+}
+
+// ResolveGoogleDomain returns the domain the searches of a job go to:
+// requested itself, or for auto the domain of country, else of lang, else
+// DefaultGoogleDomain. Empty means DefaultGoogleDomain.
+func ResolveGoogleDomain(requested, country, lang string) string {
+	d := normalizeGoogleDomain(requested)
+	switch {
+	case d == "":
+		return DefaultGoogleDomain
+	case d != GoogleDomainAuto:
+		return d
+	}
+
+	if cd, ok := countryGoogleDomains[strings.ToUpper(country)]; ok {
+		return cd
+	}
+	if ld, ok := langGoogleDomains[strings.ToLower(lang)]; ok {
+		return ld
+	}
+	return DefaultGoogleDomain
+}
+
+// SearchDomain returns the Google domain the job searches
+func (c *JobConfig) SearchDomain() string {
+	if c.GoogleDomain == "" {
+		return DefaultGoogleDomain
+	}
+	return c.GoogleDomain
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveGoogleDomain(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		country   string
+		lang      string
+		want      string
+	}{
+		{"empty", "", "DE", "de", "google.com"},
+		{"explicit", "google.co.id", "DE", "de", "google.co.id"},
+		{"explicit with host", "https://www.google.de/", "", "", "google.de"},
+		{"auto from country", "auto", "de", "en", "google.de"},
+		{"auto from lang", "auto", "", "ja", "google.co.jp"},
+		{"auto with unknown country", "auto", "XX", "id", "google.co.id"},
+		{"auto without a market", "auto", "", "en", "google.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ResolveGoogleDomain(tt.requested, tt.country, tt.lang))
+		})
+	}
+}
+
+func TestCreateJobRequestGoogleDomain(t *testing.T) {
+	req := &CreateJobRequest{Name: "bakeries", Keywords: []string{"bäckerei"}, Lang: "de", GoogleDomain: "auto"}
+
+	job, err := req.ToJob(0)
+	assert.NoError(t, err)
+	assert.Equal(t, "google.de", job.Config.GoogleDomain)
+
+	req.GoogleDomain = "google.example"
+	verr, ok := AsValidationError(req.Validate())
+	if assert.True(t, ok) {
+		assert.Equal(t, "google_domain", verr.Fields[0].Field)
+	}
+}
@@ -1,7 +1,12 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,13 +22,34 @@ const (
 	CoverageModeFull CoverageMode = "full"
 )
 
+// Full coverage grid caps. A job may lower or raise its cap with
+// max_grid_points up to MaxGridPointsLimit.
+const (
+	DefaultMaxGridPoints = 400
+	MaxGridPointsLimit   = 5000
+)
+
+// MinGridRadius is the smallest radius, in meters, a full coverage grid
+// may be sized for
+const MinGridRadius = 100
+
+// Grid errors
+var (
+	ErrTooManyGridPoints    = errors.New("too many grid points")
+	ErrInvalidMaxGridPoints = fmt.Errorf("max_grid_points must be between 1 and %d", MaxGridPointsLimit)
+)
+
+// metersPerDegree is the length of a degree of latitude, and of longitude
+// at the equator
+const metersPerDegree = 111320.0
+
 // BoundingBox represents a geographic bounding box for area coverage
 // Field names match frontend/Nominatim API naming convention
 type BoundingBox struct {
-	MinLat float64 `json:"min_lat"` // Southern boundary latitude
-	MaxLat float64 `json:"max_lat"` // Northern boundary latitude
-	MinLon float64 `json:"min_lon"` // Western boundary longitude
-	MaxLon float64 `json:"max_lon"` // Eastern boundary longitude
+	MinLat float64 `json:"min_lat" validate:"latitude"`                 // Southern boundary latitude
+	MaxLat float64 `json:"max_lat" validate:"latitude,gtfield=MinLat"`  // Northern boundary latitude
+	MinLon float64 `json:"min_lon" validate:"longitude"`                // Western boundary longitude
+	MaxLon float64 `json:"max_lon" validate:"longitude,gtfield=MinLon"` // Eastern boundary longitude
 }
 
 // IsValid returns true if the bounding box has valid coordinates
@@ -50,6 +76,39 @@ func (b *BoundingBox) IsValid() bool {
 	return true
 }
 
+// ParseBoundingBox parses a box in GeoJSON bbox order,
+// "min_lon,min_lat,max_lon,max_lat"
+func ParseBoundingBox(s string) (*BoundingBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, errors.New("bbox must be min_lon,min_lat,max_lon,max_lat")
+	}
+
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("bbox value %q is not a number", p)
+		}
+		v[i] = f
+	}
+
+	box := &BoundingBox{MinLon: v[0], MinLat: v[1], MaxLon: v[2], MaxLat: v[3]}
+	if !box.IsValid() {
+		return nil, errors.New("bbox is out of range or has min above max")
+	}
+
+	return box, nil
+}
+
+// String formats the box as ParseBoundingBox reads it
+func (b *BoundingBox) String() string {
+	if b == nil {
+		return ""
+	}
+	return fmt.Sprintf("%g,%g,%g,%g", b.MinLon, b.MinLat, b.MaxLon, b.MaxLat)
+}
+
 // Center returns the center point of the bounding box
 func (b *BoundingBox) Center() (lat, lon float64) {
 	lat = (b.MaxLat + b.MinLat) / 2
@@ -90,42 +149,127 @@ func (b *BoundingBox) GenerateGrid(gridSize int) []GridPoint {
 	return points
 }
 
-// GenerateGridByRadius creates a grid of search points within the bounding box
-// based on the radius in meters (distance between grid points)
-func (b *BoundingBox) GenerateGridByRadius(radiusMeters int) []GridPoint {
+// GridLayout is a search grid for full coverage mode. Rows are offset by
+// half a column (hexagonal packing) so that circles of the search radius
+// around the points cover the whole bounding box with as little overlap as
+// possible.
+type GridLayout struct {
+	Points []GridPoint `json:"points,omitempty"`
+	Rows   int         `json:"rows"`
+	Cols   int         `json:"cols"` // Points in the widest row
+
+	// SpacingMeters is the distance between neighbouring points of a row,
+	// RowSpacingMeters the distance between rows
+	SpacingMeters    float64 `json:"spacing_meters"`
+	RowSpacingMeters float64 `json:"row_spacing_meters"`
+
+	size int
+}
+
+// Size returns the number of points of the layout, whether or not the
+// points were generated
+func (l GridLayout) Size() int {
+	return l.size
+}
+
+// GridLayoutByRadius sizes the hexagonal grid for radiusMeters without
+// generating its points. Use it to check the size of large boxes.
+func (b *BoundingBox) GridLayoutByRadius(radiusMeters int) GridLayout {
+	return b.gridByRadius(radiusMeters, false)
+}
+
+// GenerateGridByRadius creates a hexagonal grid of search points within the
+// bounding box such that no place in the box is farther than radiusMeters
+// from a point. Check the size with GridLayoutByRadius first; the number of
+// points is not capped here.
+func (b *BoundingBox) GenerateGridByRadius(radiusMeters int) GridLayout {
+	return b.gridByRadius(radiusMeters, true)
+}
+
+func (b *BoundingBox) gridByRadius(radiusMeters int, withPoints bool) GridLayout {
 	if radiusMeters < 100 {
 		radiusMeters = 100
 	}
+	r := float64(radiusMeters)
+
+	// Circles of radius r tile the plane as hexagons of width r·√3 and
+	// rows 1.5·r apart
+	spacing := r * math.Sqrt(3)
+	rowSpacing := 1.5 * r
+
+	// A degree of longitude is widest closest to the equator; sizing the
+	// columns there keeps the spacing within bounds across the whole box
+	refLat := 0.0
+	if b.MinLat > 0 {
+		refLat = b.MinLat
+	} else if b.MaxLat < 0 {
+		refLat = -b.MaxLat
+	}
+	metersPerDegLon := metersPerDegree * math.Cos(refLat*math.Pi/180)
 
-	// Convert radius to degrees (approximate)
-	// 1 degree latitude ≈ 111320 meters
-	latStep := float64(radiusMeters) / 111320.0
+	height := (b.MaxLat - b.MinLat) * metersPerDegree
+	width := (b.MaxLon - b.MinLon) * metersPerDegLon
 
-	// Longitude varies with latitude, use center latitude for approximation
-	centerLat := (b.MaxLat + b.MinLat) / 2
-	lonStep := float64(radiusMeters) / (111320.0 * math.Cos(centerLat*math.Pi/180.0))
+	// The outer rows cover up to r/2 beyond themselves along their whole
+	// length, so the rows have to span the height minus r
+	rows := 1
+	if height > r {
+		rows = int(math.Ceil((height-r)/rowSpacing)) + 1
+	}
+	cols := max(int(math.Ceil(width/spacing)), 1)
 
-	points := make([]GridPoint, 0)
+	layout := GridLayout{
+		Rows:             rows,
+		Cols:             cols,
+		SpacingMeters:    spacing,
+		RowSpacingMeters: rowSpacing,
+		size:             rows * cols,
+	}
+	if rows > 1 {
+		// Odd rows are shifted by half a column and get an extra point
+		layout.Cols = cols + 1
+		layout.size += rows / 2
+	}
 
-	// Generate grid points
-	for lat := b.MinLat; lat <= b.MaxLat; lat += latStep {
-		for lon := b.MinLon; lon <= b.MaxLon; lon += lonStep {
-			points = append(points, GridPoint{Lat: lat, Lon: lon})
-		}
+	if !withPoints {
+		return layout
 	}
 
-	// Cap at 100 points max
-	if len(points) > 100 {
-		points = points[:100]
+	latStep := rowSpacing / metersPerDegree
+	lonStep := spacing / metersPerDegLon
+
+	// Center the lattice in the box; points of the shifted rows that fall
+	// outside are pulled onto the edge, which only brings them closer to
+	// every place inside
+	lat0 := b.MinLat + ((b.MaxLat-b.MinLat)-float64(rows-1)*latStep)/2
+	lon0 := b.MinLon + ((b.MaxLon-b.MinLon)-float64(cols-1)*lonStep)/2
+
+	layout.Points = make([]GridPoint, 0, layout.size)
+	for i := 0; i < rows; i++ {
+		lat := lat0 + float64(i)*latStep
+
+		start, n := lon0, cols
+		if i%2 == 1 {
+			start, n = lon0-lonStep/2, cols+1
+		}
+
+		for j := 0; j < n; j++ {
+			lon := start + float64(j)*lonStep
+			layout.Points = append(layout.Points, GridPoint{
+				Lat: math.Min(math.Max(lat, b.MinLat), b.MaxLat),
+				Lon: math.Min(math.Max(lon, b.MinLon), b.MaxLon),
+			})
+		}
 	}
 
-	return points
+	return layout
 }
 
 // JobStatus represents the status of a job
 type JobStatus string
 
 const (
+	JobStatusWaiting   JobStatus = "waiting" // Waits for the job it depends on
 	JobStatusPending   JobStatus = "pending"
 	JobStatusQueued    JobStatus = "queued"
 	JobStatusRunning   JobStatus = "running"
@@ -135,11 +279,34 @@ const (
 	JobStatusCancelled JobStatus = "cancelled"
 )
 
+// IsValid returns true if s is one of the JobStatus constants
+func (s JobStatus) IsValid() bool {
+	switch s {
+	case JobStatusWaiting, JobStatusPending, JobStatusQueued, JobStatusRunning, JobStatusPaused,
+		JobStatusCompleted, JobStatusFailed, JobStatusCancelled:
+		return true
+	}
+	return false
+}
+
 // IsTerminal returns true if the job is in a terminal state
 func (s JobStatus) IsTerminal() bool {
 	return s == JobStatusCompleted || s == JobStatusFailed || s == JobStatusCancelled
 }
 
+// ResultsPartial returns true if results of a job in this status may still
+// be added to, so a listing or export of them is incomplete
+func (s JobStatus) ResultsPartial() bool {
+	return !s.IsTerminal()
+}
+
+// StopsWorker returns true if a running job moved to this status has to
+// stop on its worker: it was paused or cancelled, or the manager timed it
+// out, failing it or putting it back to pending
+func (s JobStatus) StopsWorker() bool {
+	return s == JobStatusPaused || s == JobStatusCancelled || s == JobStatusFailed || s == JobStatusPending
+}
+
 // CanPause returns true if the job can be paused
 func (s JobStatus) CanPause() bool {
 	return s == JobStatusRunning || s == JobStatusQueued
@@ -150,9 +317,22 @@ func (s JobStatus) CanResume() bool {
 	return s == JobStatusPaused
 }
 
+// CanRetry returns true if failed searches of the job can be retried.
+// Paused and cancelled jobs were stopped on purpose; waiting jobs have not
+// run yet.
+func (s JobStatus) CanRetry() bool {
+	return s != JobStatusPaused && s != JobStatusCancelled && s != JobStatusWaiting
+}
+
+// CanEditConfig returns true if the config of the job can still change:
+// no worker holds it
+func (s JobStatus) CanEditConfig() bool {
+	return s == JobStatusPending || s == JobStatusPaused
+}
+
 // CanCancel returns true if the job can be cancelled
 func (s JobStatus) CanCancel() bool {
-	return s == JobStatusPending || s == JobStatusQueued || s == JobStatusRunning || s == JobStatusPaused
+	return s == JobStatusWaiting || s == JobStatusPending || s == JobStatusQueued || s == JobStatusRunning || s == JobStatusPaused
 }
 
 // Job represents a scraping job in the queue
@@ -171,20 +351,290 @@ type Job struct {
 	// Worker assignment
 	WorkerID *string `json:"worker_id,omitempty"`
 
+	// Tenant the job's usage is accounted to: the ID of the API key that
+	// created it, or the X-Tenant header of a legacy-token request
+	Tenant string `json:"tenant,omitempty"`
+
 	// Timestamps
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	StartedAt   *time.Time `json:"started_at,omitempty"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 
+	// DeletedAt is set once the job was deleted. Its results are kept until
+	// it is restored or purged.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
 	// Error info
 	ErrorMessage *string `json:"error_message,omitempty"`
+
+	// ErrorCode tells what kind of failure ErrorMessage is; it is set
+	// while the job is failed
+	ErrorCode JobErrorCode `json:"error_code,omitempty"`
+
+	// Checkpoint is set once the job has been paused
+	Checkpoint *JobCheckpoint `json:"checkpoint,omitempty"`
+
+	// Manager/Worker mode only: the keywords whose search failed or never
+	// ran in the last run, and the keywords a retry runs instead of
+	// Config.Keywords
+	FailedKeywords []string `json:"failed_keywords,omitempty"`
+	RetryKeywords  []string `json:"retry_keywords,omitempty"`
+
+	// Attempts counts the runs of the job, the first one included
+	Attempts int `json:"attempts,omitempty"`
+
+	// TimeoutRequeued is set once the manager's timeout check put the job
+	// back to pending for Config.RetryOnTimeout; the next timeout fails it
+	TimeoutRequeued bool `json:"timeout_requeued,omitempty"`
+
+	// Novelty is set for incremental jobs
+	Novelty *JobNovelty `json:"novelty,omitempty"`
+
+	// StoppedReason tells why the last completed run stopped, one of the
+	// JobStopped constants
+	StoppedReason string `json:"stopped_reason,omitempty"`
+
+	// ClonedFrom is the job this one was copied from, if any
+	ClonedFrom *uuid.UUID `json:"cloned_from,omitempty"`
+
+	// BackfillOf is the job whose partial places this backfill job scrapes
+	// again; the places it scrapes upgrade them in place
+	BackfillOf *uuid.UUID `json:"backfill_of,omitempty"`
+
+	// ParentJobID is the job this shard searches a part of the keywords
+	// of; ShardCount is set on that job, the number of shards it was split
+	// into. See Job.Shard.
+	ParentJobID *uuid.UUID `json:"parent_job_id,omitempty"`
+	ShardCount  int        `json:"shard_count,omitempty"`
+
+	// DependsOn is the job this one waits for in JobStatusWaiting. RunIf,
+	// one of the JobRunIf constants, tells which outcomes of that job let
+	// this one run; the others cancel it.
+	DependsOn *uuid.UUID `json:"depends_on,omitempty"`
+	RunIf     string     `json:"run_if,omitempty"`
+
+	// Tags and Notes organize jobs, e.g. by client and campaign; they can
+	// be edited at any time and do not affect the run
+	Tags  []string `json:"tags,omitempty"`
+	Notes string   `json:"notes,omitempty"`
+
+	// Bandwidth is the traffic ProxyGate relayed for the job; it is not
+	// stored with the job and only set by JobService.GetByID
+	Bandwidth *ProxyTraffic `json:"bandwidth,omitempty"`
+
+	// Dependencies are the jobs this one waits for, DependsOn first and
+	// then its own parents, and Dependents the jobs waiting for this one.
+	// Like Bandwidth they are only set by JobService.GetByID.
+	Dependencies []JobLink `json:"dependencies,omitempty"`
+	Dependents   []JobLink `json:"dependents,omitempty"`
+
+	// Shards are the shards of a sharded job, also only set by
+	// JobService.GetByID
+	Shards []JobLink `json:"shards,omitempty"`
+
+	// ResultsAvailable counts the results stored so far, which a running
+	// job keeps adding to, and LastResultAt is when the latest was stored.
+	// Like Bandwidth they are only set by JobService.GetByID.
+	ResultsAvailable int        `json:"results_available"`
+	LastResultAt     *time.Time `json:"last_result_at,omitempty"`
+
+	// NormalizedKeywords sums up the keyword cleanup of the create request;
+	// it is only set in the response creating the job
+	NormalizedKeywords *KeywordNormalization `json:"normalized_keywords,omitempty"`
+
+	// NormalizedPlaceURLs does the same for the place URLs of a place_urls
+	// job, listing those left out as invalid
+	NormalizedPlaceURLs *PlaceURLNormalization `json:"normalized_place_urls,omitempty"`
+}
+
+// Why a completed run stopped
+const (
+	// JobStoppedExhausted means every search and place of the run was done
+	JobStoppedExhausted = "exhausted"
+	// JobStoppedMaxResults means the run collected Config.MaxResults places
+	JobStoppedMaxResults = "max_results"
+	// JobStoppedMaxTime means the run hit Config.MaxTime
+	JobStoppedMaxTime = "max_time"
+)
+
+// IsJobStoppedReason returns true if reason is one of the JobStopped constants
+func IsJobStoppedReason(reason string) bool {
+	return reason == JobStoppedExhausted || reason == JobStoppedMaxResults || reason == JobStoppedMaxTime
+}
+
+// JobErrorCode classifies why a job failed, so failures can be counted and
+// filtered by kind rather than by their message
+type JobErrorCode string
+
+const (
+	// JobErrorProxyExhausted means no proxy was left to run the job with
+	JobErrorProxyExhausted JobErrorCode = "proxy_exhausted"
+	// JobErrorBlockedByGoogle means Google kept serving block pages
+	JobErrorBlockedByGoogle JobErrorCode = "blocked_by_google"
+	// JobErrorTimeout means the run or a request it depends on timed out
+	JobErrorTimeout JobErrorCode = "timeout"
+	// JobErrorParse means a page or response could not be parsed
+	JobErrorParse JobErrorCode = "parse_error"
+	// JobErrorManagerUnreachable means the worker could not hand the
+	// results to the manager
+	JobErrorManagerUnreachable JobErrorCode = "manager_unreachable"
+	// JobErrorNoResults means the job had nothing to search
+	JobErrorNoResults JobErrorCode = "no_results"
+	// JobErrorInternal is any other failure, and the failures of workers
+	// and jobs from before error codes
+	JobErrorInternal JobErrorCode = "internal"
+)
+
+// JobErrorCodes lists the error codes
+var JobErrorCodes = []JobErrorCode{
+	JobErrorProxyExhausted, JobErrorBlockedByGoogle, JobErrorTimeout, JobErrorParse,
+	JobErrorManagerUnreachable, JobErrorNoResults, JobErrorInternal,
+}
+
+// IsValid returns true if c is one of the error codes
+func (c JobErrorCode) IsValid() bool {
+	return slices.Contains(JobErrorCodes, c)
+}
+
+// ParseJobErrorCode returns the error code a worker reported, internal for
+// an empty or unknown one
+func ParseJobErrorCode(code string) JobErrorCode {
+	if c := JobErrorCode(code); c.IsValid() {
+		return c
+	}
+	return JobErrorInternal
+}
+
+// Retryable returns true if running a job that failed with c again may
+// succeed. A job with nothing to search, or whose pages cannot be parsed,
+// fails the same way again.
+func (c JobErrorCode) Retryable() bool {
+	return c != JobErrorNoResults && c != JobErrorParse
+}
+
+// DefaultJobTimeoutGrace multiplies the MaxTime of a job into how long the
+// manager lets a worker hold it before failing it with JobErrorTimeout.
+// The margin covers starting the browser and submitting the results.
+const DefaultJobTimeoutGrace = 2.0
+
+// TimeoutDeadline returns when a running job has overrun its MaxTime times
+// grace, and false for a job no worker runs. A MaxTime under 3 minutes
+// counts as 3 minutes, as workers run jobs at least that long.
+func (j *Job) TimeoutDeadline(grace float64) (time.Time, bool) {
+	if j.Status != JobStatusRunning || j.WorkerID == nil || j.StartedAt == nil {
+		return time.Time{}, false
+	}
+
+	maxTime := max(j.Config.MaxTime, 3*time.Minute)
+
+	return j.StartedAt.Add(time.Duration(float64(maxTime) * grace)), true
+}
+
+// HeldBy returns true if workerID is the worker running the job. The
+// manager takes a job back from a worker that timed out, went offline or
+// drained too long; what that worker reports on the job afterwards is
+// stale.
+func (j *Job) HeldBy(workerID string) bool {
+	return j.WorkerID != nil && *j.WorkerID == workerID
+}
+
+// AcceptsResults returns true if result batches submitted for the job are
+// still stored. Batches for a completed or failed run are stale, except
+// those a worker spooled because it could not reach the manager; a
+// cancelled job keeps the results its worker submits as it stops.
+func (j *Job) AcceptsResults() bool {
+	switch j.Status {
+	case JobStatusCompleted:
+		return false
+	case JobStatusFailed:
+		return j.ErrorCode == JobErrorManagerUnreachable
+	}
+
+	return true
+}
+
+// DefaultLateResultsWindow is how long after failing a job the manager
+// still takes the results and completion a worker kept in its outbox
+const DefaultLateResultsWindow = 24 * time.Hour
+
+// AwaitsLateResults returns true if the job failed less than window ago
+// because its worker's report did not arrive in time, timed out or
+// unable to reach the manager. The results a worker then delivers from its
+// outbox are stored, and its completion reconciles the job to completed.
+func (j *Job) AwaitsLateResults(now time.Time, window time.Duration) bool {
+	if j.Status != JobStatusFailed || j.CompletedAt == nil || window <= 0 {
+		return false
+	}
+	if j.ErrorCode != JobErrorTimeout && j.ErrorCode != JobErrorManagerUnreachable {
+		return false
+	}
+
+	return now.Sub(*j.CompletedAt) <= window
+}
+
+// RunKeywords returns the keywords a worker should search in this run
+func (j *Job) RunKeywords() []string {
+	if len(j.RetryKeywords) > 0 {
+		return j.RetryKeywords
+	}
+	return j.Config.Keywords
+}
+
+// DefaultMaxAttempts is how often a failed search is run at most unless a
+// retry asks for more
+const DefaultMaxAttempts = 2
+
+// MaxAttemptsLimit caps the max_attempts a retry may ask for
+const MaxAttemptsLimit = 10
+
+// RetryResult is the outcome of retrying the failed searches of a job
+type RetryResult struct {
+	Status JobStatus `json:"status"`
+
+	// Requeued searches: seed tasks in DSN mode, keywords otherwise
+	Requeued int `json:"requeued"`
+
+	// Exhausted failed searches that already ran max_attempts times
+	Exhausted int `json:"exhausted"`
+}
+
+// JobImport is the outcome of importing a job archive
+type JobImport struct {
+	Job           *Job            `json:"job"`
+	SourceJobID   uuid.UUID       `json:"source_job_id"` // ID on the manager it was exported from
+	SchemaVersion int             `json:"schema_version"`
+	Sections      []ImportSection `json:"sections"`
+}
+
+// ImportSection counts the records of one part of an imported archive
+type ImportSection struct {
+	Name      string `json:"name"`       // job or results
+	InArchive int    `json:"in_archive"` // Records the archive holds
+	Imported  int    `json:"imported"`
+}
+
+// JobCheckpoint records where a paused job stopped. ScrapedPlaces counts the
+// places the worker submitted before letting go of the job; a resumed run
+// skips them.
+type JobCheckpoint struct {
+	PausedAt      time.Time `json:"paused_at"`
+	ScrapedPlaces int       `json:"scraped_places"`
+}
+
+// JobNovelty counts the places an incremental job ingested that no earlier
+// listing had, and those that were already known
+type JobNovelty struct {
+	NewPlaces   int `json:"new_places"`
+	KnownPlaces int `json:"known_places"`
 }
 
 // JobConfig contains the scraping configuration
 type JobConfig struct {
 	Keywords     []string      `json:"keywords"`
+	PlaceURLs    []string      `json:"place_urls,omitempty"` // Place pages scraped directly instead of searching Keywords
 	Lang         string        `json:"lang"`
+	LangFallback []string      `json:"lang_fallback,omitempty"` // Tried in order by searches that find nothing in Lang
 	GeoLat       *float64      `json:"geo_lat,omitempty"`
 	GeoLon       *float64      `json:"geo_lon,omitempty"`
 	Zoom         int           `json:"zoom"`
@@ -195,11 +645,64 @@ type JobConfig struct {
 	MaxTime      time.Duration `json:"max_time"`
 	Proxies      []string      `json:"proxies,omitempty"`
 
+	// MaxResults stops the run once that many unique places were scraped
+	// (0 means no cap)
+	MaxResults int `json:"max_results,omitempty"`
+
+	// ProxyCountry restricts the job to exit IPs in this country (ISO 3166-1 alpha-2)
+	ProxyCountry string `json:"proxy_country,omitempty"`
+
+	// GoogleDomain is the Google domain searched, e.g. google.de, resolved
+	// when the job is created; empty for jobs from before it, which
+	// searched DefaultGoogleDomain
+	GoogleDomain string `json:"google_domain,omitempty"`
+
+	// Extra reviews: MaxReviews > 0 fetches up to that many reviews per place,
+	// ReviewsSort is "relevant" (default) or "newest"
+	MaxReviews  int    `json:"max_reviews,omitempty"`
+	ReviewsSort string `json:"reviews_sort,omitempty"`
+
+	// MaxImages caps the photo URLs kept per place (0 means the scraper default of 5)
+	MaxImages int `json:"max_images,omitempty"`
+
 	// Geo coverage settings for area-wide scraping
 	LocationName string       `json:"location_name,omitempty"` // Human-readable location name
 	BoundingBox  *BoundingBox `json:"boundingbox,omitempty"`
 	CoverageMode CoverageMode `json:"coverage_mode,omitempty"`
 	GridPoints   int          `json:"grid_points,omitempty"` // Number of grid points generated
+
+	// DensityCheck probes every grid cell with a cheap search before seeding
+	// and skips cells without results
+	DensityCheck bool `json:"density_check,omitempty"`
+
+	// Headers sent to Google Maps, resolved from BrowserProfile and the
+	// request when the job is created. Empty fields mean the scraper
+	// defaults were used, so this records which profile produced the data.
+	BrowserProfile string `json:"browser_profile,omitempty"`
+	UserAgent      string `json:"user_agent,omitempty"`
+	AcceptLanguage string `json:"accept_language,omitempty"`
+
+	// Incremental flags each ingested place as new or already known by
+	// its place ID, so recurring jobs can export only new businesses
+	Incremental bool `json:"incremental,omitempty"`
+
+	// GlobalDedupe skips the places any job scraped recently, instead of
+	// only those this job already scraped (Redis workers only)
+	GlobalDedupe bool `json:"global_dedupe,omitempty"`
+
+	// Outputs are written by the worker besides submitting the results
+	Outputs []JobOutput `json:"outputs,omitempty"`
+
+	// NotifyEmails receive the summary report once the job completes
+	NotifyEmails []string `json:"notify_emails,omitempty"`
+
+	// RetryOnTimeout puts the job back to pending, once, instead of failing
+	// it when the manager times it out
+	RetryOnTimeout bool `json:"retry_on_timeout,omitempty"`
+
+	// CheckWebsite has the manager fetch the website of every listing the
+	// job stores, to flag dead and parked ones (PostgreSQL only)
+	CheckWebsite bool `json:"check_website,omitempty"`
 }
 
 // JobProgress tracks the scraping progress
@@ -208,6 +711,14 @@ type JobProgress struct {
 	ScrapedPlaces int     `json:"scraped_places"`
 	FailedPlaces  int     `json:"failed_places"`
 	Percentage    float64 `json:"percentage"`
+
+	// DedupedPlaces are the places the last run skipped because they were
+	// already scraped, by the job or, with GlobalDedupe, by any job
+	DedupedPlaces int `json:"deduped_places"`
+
+	// PartialPlaces are the places kept from the search results as their
+	// page failed; ScrapedPlaces does not count them
+	PartialPlaces int `json:"partial_places"`
 }
 
 // CalculatePercentage updates the percentage based on scraped/total
@@ -221,29 +732,152 @@ func (p *JobProgress) CalculatePercentage() {
 
 // CreateJobRequest is the request to create a new job
 type CreateJobRequest struct {
-	Name         string   `json:"name" validate:"required,min=1,max=255"`
-	Keywords     []string `json:"keywords" validate:"required,min=1,dive,min=1"`
-	Lang         string   `json:"lang" validate:"required,len=2"`
+	Name     string   `json:"name" validate:"required,max=255"`
+	Keywords []string `json:"keywords"`
+
+	// InputType is InputTypePlaceURLs for a job scraping PlaceURLs, Google
+	// Maps place URLs or place IDs, instead of searching for Keywords
+	InputType string   `json:"input_type,omitempty" validate:"omitempty,oneof=keywords place_urls"`
+	PlaceURLs []string `json:"place_urls,omitempty"`
+
+	Lang         string   `json:"lang" validate:"omitempty,len=2"`
+	LangFallback []string `json:"lang_fallback,omitempty"`
 	GeoLat       *float64 `json:"geo_lat,omitempty" validate:"omitempty,latitude"`
 	GeoLon       *float64 `json:"geo_lon,omitempty" validate:"omitempty,longitude"`
-	Zoom         int      `json:"zoom" validate:"min=1,max=21"`
-	Radius       int      `json:"radius" validate:"min=0"`
-	Depth        int      `json:"depth" validate:"required,min=1,max=100"`
+	Zoom         int      `json:"zoom" validate:"min=0,max=21"`   // 0 for the default
+	Radius       int      `json:"radius" validate:"min=0"`        // meters
+	Depth        int      `json:"depth" validate:"min=0,max=100"` // 0 for the default
 	FastMode     bool     `json:"fast_mode"`
 	ExtractEmail bool     `json:"extract_email"`
-	MaxTime      int      `json:"max_time" validate:"required,min=180"` // seconds
+	MaxTime      int      `json:"max_time" validate:"omitempty,min=180"` // seconds
+	MaxResults   int      `json:"max_results,omitempty" validate:"min=0"`
 	Proxies      []string `json:"proxies,omitempty"`
 	Priority     int      `json:"priority" validate:"min=0,max=100"`
+	ProxyCountry string   `json:"proxy_country,omitempty" validate:"omitempty,len=2"`
+	GoogleDomain string   `json:"google_domain,omitempty"` // A known domain like google.de, or auto
+	MaxReviews   int      `json:"max_reviews,omitempty" validate:"min=0"`
+	ReviewsSort  string   `json:"reviews_sort,omitempty" validate:"omitempty,oneof=relevant newest"`
+	MaxImages    int      `json:"max_images,omitempty" validate:"min=0"`
 
 	// Geo coverage settings for area-wide scraping
 	LocationName string       `json:"location_name,omitempty"`
-	BoundingBox  *BoundingBox `json:"boundingbox,omitempty"`
-	CoverageMode CoverageMode `json:"coverage_mode,omitempty"`
+	BoundingBox  *BoundingBox `json:"boundingbox,omitempty" validate:"-"` // Checked in full coverage mode only
+	CoverageMode CoverageMode `json:"coverage_mode,omitempty" validate:"omitempty,oneof=single full"`
+
+	// MaxGridPoints caps the full coverage grid (0 = DefaultMaxGridPoints);
+	// a larger grid fails validation instead of being cut short
+	MaxGridPoints int  `json:"max_grid_points,omitempty"`
+	DensityCheck  bool `json:"density_check,omitempty"`
+
+	// BrowserProfile names a preset of BrowserProfiles; UserAgent and
+	// AcceptLanguage override its headers
+	BrowserProfile string `json:"browser_profile,omitempty"`
+	UserAgent      string `json:"user_agent,omitempty"` // Checked by ResolveBrowserProfile
+	AcceptLanguage string `json:"accept_language,omitempty"`
+
+	Incremental  bool        `json:"incremental,omitempty"`
+	GlobalDedupe bool        `json:"global_dedupe,omitempty"`
+	Outputs      []JobOutput `json:"outputs,omitempty"`
+	NotifyEmails []string    `json:"notify_emails,omitempty"`
+
+	RetryOnTimeout bool `json:"retry_on_timeout,omitempty"`
+	CheckWebsite   bool `json:"check_website,omitempty"`
+
+	// BaseKeywords are combined with every entry of Locations by ToJob and
+	// appended to Keywords
+	BaseKeywords []string          `json:"base_keywords,omitempty"`
+	Locations    []KeywordLocation `json:"locations,omitempty"`
+
+	// TemplateID is the job template the request was merged with, if any
+	TemplateID *uuid.UUID `json:"template_id,omitempty"`
+
+	Tags  []string `json:"tags,omitempty" validate:"max=50,dive,max=64"`
+	Notes string   `json:"notes,omitempty" validate:"max=10000"`
+
+	// Tenant is set by the API handler from the caller, never from the body
+	Tenant string `json:"-"`
+
+	// ClonedFrom is set by the API handler when the request copies a job
+	ClonedFrom *uuid.UUID `json:"-"`
+
+	// DependsOn makes the job wait for another one; RunIf is one of the
+	// JobRunIf constants, JobRunIfSuccess when empty
+	DependsOn *uuid.UUID `json:"depends_on,omitempty"`
+	RunIf     string     `json:"run_if,omitempty"`
+
+	// Shards splits the keywords of the job over this many shards, 0 or 1
+	// to run it as one job
+	Shards int `json:"shards,omitempty" validate:"min=0,max=100"`
+}
+
+// Check adds the fields of the request that fail validation to v: those
+// breaking their validate tags and the rules spanning several fields.
+// Keywords and place URLs are left to ToJob, which normalizes them first.
+func (r *CreateJobRequest) Check(v *ValidationError) {
+	v.Check("", r)
+
+	if r.PlacesInput() {
+		if len(r.Keywords) > 0 || len(r.BaseKeywords) > 0 {
+			v.Add("keywords", "excluded_with", "keywords cannot be combined with input_type place_urls")
+		}
+		if len(r.PlaceURLs) > MaxPlaceURLs {
+			v.Add("place_urls", "max", "place_urls must have at most %d entries", MaxPlaceURLs)
+		}
+		if r.Shards > 1 {
+			v.Add("shards", "excluded_with", "a place_urls job cannot be sharded")
+		}
+	} else if len(r.PlaceURLs) > 0 {
+		v.Add("place_urls", "excluded_unless", "place_urls requires input_type place_urls")
+	}
+
+	if (r.GeoLat == nil) != (r.GeoLon == nil) {
+		missing := "geo_lat"
+		if r.GeoLon == nil {
+			missing = "geo_lon"
+		}
+		v.Add(missing, "required_with", "geo_lat and geo_lon must be set together")
+	}
+
+	if !IsGoogleDomain(r.GoogleDomain) {
+		v.Add("google_domain", "google_domain", "google_domain must be auto or a known Google domain like google.de")
+	}
+
+	if r.CoverageMode == CoverageModeFull && !r.PlacesInput() {
+		if r.BoundingBox == nil {
+			v.Add("boundingbox", "required", "boundingbox is required in full coverage mode")
+		} else {
+			v.Check("boundingbox", r.BoundingBox)
+		}
+		if r.Radius != 0 && r.Radius < MinGridRadius {
+			v.Add("radius", "min", "radius must be at least %d meters in full coverage mode", MinGridRadius)
+		}
+	}
+}
+
+// Validate returns a *ValidationError listing the fields of the request
+// that fail validation, nil if none does
+func (r *CreateJobRequest) Validate() error {
+	v := &ValidationError{}
+	r.Check(v)
+	return v.Err()
+}
+
+// PlacesInput tells whether the job scrapes a list of places rather than
+// searching
+func (r *CreateJobRequest) PlacesInput() bool {
+	return r.InputType == InputTypePlaceURLs
 }
 
 // EstimateTotalPlaces estimates total places based on job config
-// For full coverage mode, multiplies by number of grid points
+// For full coverage mode, multiplies by number of grid points. A place_urls
+// job has one place per URL. MaxResults caps the estimate.
 func (r *CreateJobRequest) EstimateTotalPlaces() int {
+	if r.PlacesInput() {
+		if r.MaxResults > 0 {
+			return min(len(r.PlaceURLs), r.MaxResults)
+		}
+		return len(r.PlaceURLs)
+	}
 	if len(r.Keywords) == 0 {
 		return 0
 	}
@@ -258,18 +892,15 @@ func (r *CreateJobRequest) EstimateTotalPlaces() int {
 	// For full coverage mode, multiply by number of grid points
 	gridMultiplier := 1
 	if r.CoverageMode == CoverageModeFull && r.BoundingBox != nil && r.BoundingBox.IsValid() {
-		radius := r.Radius
-		if radius == 0 {
-			radius = 5000 // default 5km
-		}
-		gridPoints := r.BoundingBox.GenerateGridByRadius(radius)
-		gridMultiplier = len(gridPoints)
-		if gridMultiplier < 1 {
-			gridMultiplier = 1
-		}
+		gridMultiplier = max(r.CalculateGridPoints(), 1)
+	}
+
+	total := len(r.Keywords) * resultsPerKeyword * gridMultiplier
+	if r.MaxResults > 0 {
+		total = min(total, r.MaxResults)
 	}
 
-	return len(r.Keywords) * resultsPerKeyword * gridMultiplier
+	return total
 }
 
 // CalculateGridPoints returns the number of grid points for this request
@@ -277,32 +908,168 @@ func (r *CreateJobRequest) CalculateGridPoints() int {
 	if r.CoverageMode != CoverageModeFull || r.BoundingBox == nil || !r.BoundingBox.IsValid() {
 		return 1
 	}
-	radius := r.Radius
+	return r.GridLayout().Size()
+}
+
+// GridLayout sizes the full coverage grid of the request without generating
+// its points
+func (r *CreateJobRequest) GridLayout() GridLayout {
+	return r.BoundingBox.GridLayoutByRadius(gridRadius(r.Radius))
+}
+
+// gridRadius is the radius a full coverage grid is sized for, 5 km unless
+// set
+func gridRadius(radius int) int {
 	if radius == 0 {
-		radius = 5000
+		return 5000
 	}
-	return len(r.BoundingBox.GenerateGridByRadius(radius))
+	return radius
 }
 
-// ToJob converts a CreateJobRequest to a Job
-func (r *CreateJobRequest) ToJob() *Job {
+// GridRadius is the radius the full coverage grid of the job is sized for
+func (c JobConfig) GridRadius() int {
+	return gridRadius(c.Radius)
+}
+
+// Grid generates the full coverage grid of the job, sized as
+// CreateJobRequest.GridLayout sized it when the job was created. ok is
+// false unless the job is in full coverage mode with a valid bounding box.
+func (c JobConfig) Grid() (grid GridLayout, ok bool) {
+	if c.CoverageMode != CoverageModeFull || c.BoundingBox == nil || !c.BoundingBox.IsValid() {
+		return GridLayout{}, false
+	}
+	return c.BoundingBox.GenerateGridByRadius(c.GridRadius()), true
+}
+
+// validateGrid rejects grids larger than the request's cap
+func (r *CreateJobRequest) validateGrid() error {
+	limit := r.MaxGridPoints
+	if limit == 0 {
+		limit = DefaultMaxGridPoints
+	}
+	if limit < 1 || limit > MaxGridPointsLimit {
+		return ErrInvalidMaxGridPoints
+	}
+
+	if n := r.CalculateGridPoints(); n > limit {
+		layout := r.GridLayout()
+		return fmt.Errorf("%w: the bounding box needs %d points (%d rows × up to %d columns), the limit is %d; raise the radius or max_grid_points",
+			ErrTooManyGridPoints, n, layout.Rows, layout.Cols, limit)
+	}
+
+	return nil
+}
+
+// EstimateSeedJobs returns the number of search seed jobs: one per keyword
+// and grid point, none for a place_urls job
+func (r *CreateJobRequest) EstimateSeedJobs() int {
+	if r.PlacesInput() {
+		return 0
+	}
+	return len(r.Keywords) * r.CalculateGridPoints()
+}
+
+// EstimateRuntime roughly estimates the single-worker runtime from the same
+// figures as EstimateTotalPlaces: scrolling every search, then visiting each
+// place (skipped in fast mode) and its website when emails are extracted.
+func (r *CreateJobRequest) EstimateRuntime() time.Duration {
+	depth := r.Depth
+	if depth == 0 {
+		depth = 10
+	}
+
+	seconds := r.EstimateSeedJobs() * depth * secondsPerScroll
+
+	// Place jobs visit their place in fast mode too
+	if !r.FastMode || r.PlacesInput() {
+		perPlace := secondsPerPlace
+		if r.ExtractEmail {
+			perPlace += secondsPerEmailFetch
+		}
+		seconds += r.EstimateTotalPlaces() * perPlace
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// ToJob converts a CreateJobRequest to a Job. Keywords and BaseKeywords
+// are normalized with NormalizeKeywords, then BaseKeywords × Locations are
+// expanded into Keywords; maxKeywords caps the result (0 for no cap). The
+// PlaceURLs of a place_urls job are normalized with NormalizePlaceURLs,
+// invalid ones left out.
+func (r *CreateJobRequest) ToJob(maxKeywords int) (*Job, error) {
 	now := time.Now().UTC()
 
-	// Set default coverage mode
+	r.Keywords, _ = NormalizeKeywords(r.Keywords)
+	r.BaseKeywords, _ = NormalizeKeywords(r.BaseKeywords)
+	if r.PlacesInput() {
+		r.PlaceURLs, _ = NormalizePlaceURLs(r.PlaceURLs)
+		if len(r.PlaceURLs) == 0 {
+			return nil, ErrNoPlaceURLs
+		}
+	} else if len(r.Keywords) == 0 && len(r.BaseKeywords) == 0 {
+		return nil, ErrNoKeywords
+	}
+
+	// Every job passes here, whether created through the API or for DSN
+	// workers, so none skips the checks of the request
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(r.BaseKeywords) > 0 {
+		keywords, err := ExpandKeywords(r.Keywords, r.BaseKeywords, r.Locations, maxKeywords)
+		if err != nil {
+			return nil, err
+		}
+		r.Keywords = keywords
+	}
+
+	// Set default coverage mode; a place_urls job has no searches to spread
+	// over a grid
 	coverageMode := r.CoverageMode
-	if coverageMode == "" {
+	if coverageMode == "" || r.PlacesInput() {
 		coverageMode = CoverageModeSingle
 	}
 
 	// Calculate grid points for full coverage mode
 	gridPoints := 1
 	if coverageMode == CoverageModeFull && r.BoundingBox != nil && r.BoundingBox.IsValid() {
+		if err := r.validateGrid(); err != nil {
+			return nil, err
+		}
 		gridPoints = r.CalculateGridPoints()
 	}
 
+	profile, err := ResolveBrowserProfile(r.BrowserProfile, r.UserAgent, r.AcceptLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateOutputs(r.Outputs); err != nil {
+		return nil, err
+	}
+
+	status, runIf, err := r.dependency()
+	if err != nil {
+		return nil, err
+	}
+
+	langFallback, err := NormalizeLangFallback(r.Lang, r.LangFallback)
+	if err != nil {
+		return nil, err
+	}
+
+	notifyEmails, err := NormalizeNotifyEmails(r.NotifyEmails)
+	if err != nil {
+		return nil, err
+	}
+
 	config := JobConfig{
 		Keywords:     r.Keywords,
+		PlaceURLs:    r.PlaceURLs,
 		Lang:         r.Lang,
+		LangFallback: langFallback,
 		GeoLat:       r.GeoLat,
 		GeoLon:       r.GeoLon,
 		Zoom:         r.Zoom,
@@ -311,11 +1078,27 @@ func (r *CreateJobRequest) ToJob() *Job {
 		FastMode:     r.FastMode,
 		ExtractEmail: r.ExtractEmail,
 		MaxTime:      time.Duration(r.MaxTime) * time.Second,
+		MaxResults:   r.MaxResults,
 		Proxies:      r.Proxies,
+		ProxyCountry: r.ProxyCountry,
+		MaxReviews:   r.MaxReviews,
+		ReviewsSort:  r.ReviewsSort,
+		MaxImages:    r.MaxImages,
 		LocationName: r.LocationName,
 		BoundingBox:  r.BoundingBox,
 		CoverageMode: coverageMode,
 		GridPoints:   gridPoints,
+		DensityCheck: r.DensityCheck && coverageMode == CoverageModeFull,
+
+		BrowserProfile: r.BrowserProfile,
+		UserAgent:      profile.UserAgent,
+		AcceptLanguage: profile.AcceptLanguage,
+		Incremental:    r.Incremental,
+		GlobalDedupe:   r.GlobalDedupe,
+		Outputs:        r.Outputs,
+		NotifyEmails:   notifyEmails,
+		RetryOnTimeout: r.RetryOnTimeout,
+		CheckWebsite:   r.CheckWebsite,
 	}
 
 	// Set defaults
@@ -334,22 +1117,50 @@ func (r *CreateJobRequest) ToJob() *Job {
 	if config.MaxTime == 0 {
 		config.MaxTime = 10 * time.Minute
 	}
+	config.GoogleDomain = ResolveGoogleDomain(r.GoogleDomain, config.ProxyCountry, config.Lang)
+	if r.PlacesInput() {
+		config.Keywords = []string{}
+	}
 
 	return &Job{
 		ID:       uuid.New(),
 		Name:     r.Name,
-		Status:   JobStatusPending,
+		Status:   status,
 		Priority: r.Priority,
 		Config:   config,
+		Tenant:   r.Tenant,
 		Progress: JobProgress{
 			TotalPlaces:   r.EstimateTotalPlaces(),
 			ScrapedPlaces: 0,
 			FailedPlaces:  0,
 			Percentage:    0,
 		},
-		CreatedAt: now,
-		UpdatedAt: now,
+		Attempts:   1,
+		ClonedFrom: r.ClonedFrom,
+		DependsOn:  r.DependsOn,
+		RunIf:      runIf,
+		Tags:       NormalizeTags(r.Tags),
+		Notes:      r.Notes,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// NormalizeTags trims tags and drops empty and repeated ones. Tags are
+// compared as given, case included. The result is never nil.
+func NormalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
 	}
+
+	return out
 }
 
 // UpdateJobRequest is the request to update a job (pause/resume/cancel)
@@ -357,12 +1168,25 @@ type UpdateJobRequest struct {
 	Status *JobStatus `json:"status,omitempty"`
 }
 
+// PatchJobRequest edits a job's tags and notes; nil fields are left as
+// they are
+type PatchJobRequest struct {
+	Tags  *[]string `json:"tags,omitempty" validate:"omitempty,max=50,dive,max=64"`
+	Notes *string   `json:"notes,omitempty" validate:"omitempty,max=10000"`
+}
+
 // JobListParams are parameters for listing jobs
 type JobListParams struct {
-	Status   *JobStatus
-	WorkerID *string
-	Limit    int
-	Offset   int
-	OrderBy  string
-	OrderDir string
+	Status         *JobStatus
+	WorkerID       *string
+	IncludeDeleted bool     // Deleted jobs are left out otherwise
+	Tags           []string // Jobs carrying all of these tags
+	ErrorCode      *JobErrorCode
+	DependsOn      *uuid.UUID // Jobs depending on this one
+	ParentJobID    *uuid.UUID // Shards of this job
+	Sharded        bool       // Jobs split into shards only
+	Limit          int
+	Offset         int
+	OrderBy        string
+	OrderDir       string
 }
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// When a job with DependsOn runs once the job it depends on finished
+const (
+	JobRunIfSuccess = "success" // Only once it completed; failed or cancelled cancels the dependent
+	JobRunIfAlways  = "always"  // Whatever it ended with
+)
+
+// ErrInvalidRunIf is returned for a run_if other than the JobRunIf
+// constants, or one without depends_on
+var ErrInvalidRunIf = errors.New("run_if must be success or always, and needs depends_on")
+
+// JobLink names a job of a dependency chain
+type JobLink struct {
+	ID     uuid.UUID `json:"id"`
+	Name   string    `json:"name"`
+	Status JobStatus `json:"status"`
+	RunIf  string    `json:"run_if,omitempty"`
+}
+
+// LinkTo returns the link of a job
+func LinkTo(job *Job) JobLink {
+	return JobLink{ID: job.ID, Name: job.Name, Status: job.Status, RunIf: job.RunIf}
+}
+
+// dependency returns the status and run_if of the job the request creates
+func (r *CreateJobRequest) dependency() (JobStatus, string, error) {
+	if r.DependsOn == nil {
+		if r.RunIf != "" {
+			return "", "", ErrInvalidRunIf
+		}
+		return JobStatusPending, "", nil
+	}
+
+	switch r.RunIf {
+	case "":
+		return JobStatusWaiting, JobRunIfSuccess, nil
+	case JobRunIfSuccess, JobRunIfAlways:
+		return JobStatusWaiting, r.RunIf, nil
+	default:
+		return "", "", ErrInvalidRunIf
+	}
+}
+
+// DependencyOutcome tells what becomes of a waiting job once the job it
+// depends on is parent: JobStatusPending to run it, JobStatusCancelled to
+// cancel it, or JobStatusWaiting while parent has not finished
+func (j *Job) DependencyOutcome(parent *Job) JobStatus {
+	if !parent.Status.IsTerminal() {
+		return JobStatusWaiting
+	}
+	if parent.Status == JobStatusCompleted || j.RunIf == JobRunIfAlways {
+		return JobStatusPending
+	}
+	return JobStatusCancelled
+}
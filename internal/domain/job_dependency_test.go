@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateJobRequestDependsOn(t *testing.T) {
+	req := &CreateJobRequest{Name: "enrich", Keywords: []string{"cafe"}}
+
+	job, err := req.ToJob(0)
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusPending, job.Status)
+
+	req.RunIf = JobRunIfAlways
+	_, err = req.ToJob(0)
+	assert.ErrorIs(t, err, ErrInvalidRunIf, "run_if without depends_on")
+
+	parent := uuid.New()
+	req.DependsOn = &parent
+	req.RunIf = ""
+	job, err = req.ToJob(0)
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusWaiting, job.Status)
+	assert.Equal(t, JobRunIfSuccess, job.RunIf)
+
+	req.RunIf = "sometimes"
+	_, err = req.ToJob(0)
+	assert.ErrorIs(t, err, ErrInvalidRunIf)
+}
+
+func TestJobDependencyOutcome(t *testing.T) {
+	tests := []struct {
+		parent JobStatus
+		runIf  string
+		want   JobStatus
+	}{
+		{JobStatusRunning, JobRunIfSuccess, JobStatusWaiting},
+		{JobStatusWaiting, JobRunIfAlways, JobStatusWaiting},
+		{JobStatusCompleted, JobRunIfSuccess, JobStatusPending},
+		{JobStatusFailed, JobRunIfSuccess, JobStatusCancelled},
+		{JobStatusCancelled, JobRunIfSuccess, JobStatusCancelled},
+		{JobStatusFailed, JobRunIfAlways, JobStatusPending},
+		{JobStatusCancelled, JobRunIfAlways, JobStatusPending},
+	}
+
+	for _, tt := range tests {
+		job := &Job{Status: JobStatusWaiting, RunIf: tt.runIf}
+		assert.Equal(t, tt.want, job.DependencyOutcome(&Job{Status: tt.parent}), "parent %s, run_if %s", tt.parent, tt.runIf)
+	}
+
+	assert.True(t, JobStatusWaiting.CanCancel())
+	assert.False(t, JobStatusWaiting.CanRetry())
+	assert.True(t, JobStatusWaiting.IsValid())
+	assert.False(t, JobStatus("done").IsValid())
+}
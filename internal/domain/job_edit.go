@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// ChangedFields lists the JSON fields of c that differ in other, sorted,
+// for the audit trail of config edits
+func (c JobConfig) ChangedFields(other JobConfig) []string {
+	before, after := configFields(c), configFields(other)
+
+	var changed []string
+	for name, value := range before {
+		if !bytes.Equal(value, after[name]) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// configFields is c as JSON fields, the fields left out empty omitted
+func configFields(c JobConfig) map[string]json.RawMessage {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobConfigChangedFields(t *testing.T) {
+	lat := 1.5
+	before := JobConfig{Keywords: []string{"cafe"}, Lang: "en", Radius: 5000, MaxTime: 10 * time.Minute}
+
+	after := before
+	assert.Empty(t, before.ChangedFields(after))
+
+	after.Keywords = []string{"coffee"}
+	after.Radius = 3000
+	after.GeoLat = &lat
+	assert.Equal(t, []string{"geo_lat", "keywords", "radius"}, before.ChangedFields(after))
+	assert.Equal(t, []string{"geo_lat", "keywords", "radius"}, after.ChangedFields(before), "fields dropped count too")
+}
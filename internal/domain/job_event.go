@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of job events
+const (
+	JobEventCreated   = "created"
+	JobEventUnblocked = "unblocked" // The job it depends on finished, moved to pending
+	JobEventQueued    = "queued"    // Sent to RabbitMQ or Redis
+	JobEventRequeued  = "requeued"  // Its queue message published again by hand
+	JobEventClaimed   = "claimed"   // Taken by a worker
+	JobEventStarted   = "started"   // Running on DSN workers, which take its seed tasks
+	JobEventReleased  = "released"  // Given back to pending by or for a worker
+	JobEventProgress  = "progress"  // Crossed a progress milestone
+	JobEventPaused    = "paused"
+	JobEventResumed   = "resumed"
+	JobEventCancelled = "cancelled"
+	JobEventRetried   = "retried" // Failed searches requeued
+	JobEventTimedOut  = "timed_out"
+	JobEventCompleted = "completed"
+	JobEventFailed    = "failed"
+	JobEventDeleted   = "deleted"
+	JobEventRestored  = "restored"
+	JobEventSharded   = "sharded" // Split into shards, which it runs through
+	JobEventEdited    = "edited"  // Its config changed before it ran
+)
+
+// JobEventProgressStep is the progress percentage between the progress
+// milestones recorded
+const JobEventProgressStep = 25
+
+// Actors of job events other than API keys and workers
+const (
+	ActorSystem    = "system"    // The manager's background tasks
+	ActorAPIToken  = "api_token" // The legacy API token
+	ActorAnonymous = "anonymous" // A request while auth is disabled
+)
+
+// JobEvent is an entry of a job's audit trail
+type JobEvent struct {
+	ID    int64     `json:"id"`
+	JobID uuid.UUID `json:"job_id"`
+	Type  string    `json:"type"`
+
+	// Status is the job's status after the event, empty when it did not
+	// change
+	Status JobStatus `json:"status,omitempty"`
+
+	// Actor is who caused the event: api_key:<name>, worker:<id>, or one
+	// of the Actor constants
+	Actor string `json:"actor"`
+
+	ErrorCode JobErrorCode `json:"error_code,omitempty"`
+	Message   string       `json:"message,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// WorkerActor is the actor of events a worker causes
+func WorkerActor(workerID string) string {
+	return "worker:" + workerID
+}
+
+type actorContextKey struct{}
+
+// ContextWithActor returns a copy of ctx whose job events are recorded
+// as caused by actor
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by ContextWithActor, else the
+// API key that authenticated the request, else ActorSystem
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorContextKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	if key := APIKeyFromContext(ctx); key != nil {
+		return "api_key:" + key.Name
+	}
+	return ActorSystem
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActorFromContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ActorSystem, ActorFromContext(ctx))
+
+	ctx = ContextWithAPIKey(ctx, &APIKey{Name: "ci"})
+	assert.Equal(t, "api_key:ci", ActorFromContext(ctx))
+
+	ctx = ContextWithActor(ctx, WorkerActor("w1"))
+	assert.Equal(t, "worker:w1", ActorFromContext(ctx), "explicit actor wins")
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultWorkerConcurrency is the number of searches a worker runs at a
+// time unless the manager is configured otherwise
+const DefaultWorkerConcurrency = 4
+
+// JobPreview is what creating a job would amount to, for
+// POST /api/v2/jobs/preview. Nothing is stored or enqueued for it.
+type JobPreview struct {
+	Keywords        int `json:"keywords"`    // After normalization and expansion
+	GridPoints      int `json:"grid_points"` // 1 outside full coverage mode
+	SeedJobs        int `json:"seed_jobs"`
+	EstimatedPlaces int `json:"estimated_places"`
+
+	// EstimatedRuntimeSeconds spreads the runtime over Concurrency
+	// searches at a time
+	EstimatedRuntimeSeconds int `json:"estimated_runtime_seconds"`
+	Concurrency             int `json:"concurrency"`
+
+	// QuotaRemaining is how many places the tenant may still scrape this
+	// month, nil without a quota. ExceedsQuota is set when the estimate
+	// is over it.
+	QuotaRemaining *int `json:"quota_remaining,omitempty"`
+	ExceedsQuota   bool `json:"exceeds_quota"`
+
+	Warnings            []string               `json:"warnings"`
+	NormalizedKeywords  *KeywordNormalization  `json:"normalized_keywords,omitempty"`
+	NormalizedPlaceURLs *PlaceURLNormalization `json:"normalized_place_urls,omitempty"`
+}
+
+// Preview returns the estimates of the job ToJob built from the request,
+// from the same figures ToJob stores with it
+func (r *CreateJobRequest) Preview(job *Job, concurrency int) *JobPreview {
+	if concurrency < 1 {
+		concurrency = DefaultWorkerConcurrency
+	}
+
+	seconds := int(r.EstimateRuntime() / time.Second)
+	p := &JobPreview{
+		Keywords:                len(job.Config.Keywords),
+		GridPoints:              job.Config.GridPoints,
+		SeedJobs:                r.EstimateSeedJobs(),
+		EstimatedPlaces:         job.Progress.TotalPlaces,
+		EstimatedRuntimeSeconds: (seconds + concurrency - 1) / concurrency,
+		Concurrency:             concurrency,
+		Warnings:                []string{},
+	}
+
+	if r.MaxResults > 0 {
+		uncapped := *r
+		uncapped.MaxResults = 0
+		if n := uncapped.EstimateTotalPlaces(); n > r.MaxResults {
+			p.Warn("max_results caps the estimate of %d places at %d", n, r.MaxResults)
+		}
+	}
+	if job.Config.DensityCheck {
+		p.Warn("density_check drops the grid points without places when the job starts, so the %d seed jobs are an upper bound", p.SeedJobs)
+	}
+
+	return p
+}
+
+// Warn adds a warning to the preview
+func (p *JobPreview) Warn(format string, args ...any) {
+	p.Warnings = append(p.Warnings, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateJobRequestPreview(t *testing.T) {
+	req := &CreateJobRequest{
+		Name:         "cafes",
+		Keywords:     []string{"cafe", "bakery"},
+		CoverageMode: CoverageModeFull,
+		BoundingBox:  &BoundingBox{MinLat: 52.40, MaxLat: 52.60, MinLon: 13.20, MaxLon: 13.50},
+		Radius:       2000,
+		MaxResults:   1000,
+	}
+
+	job, err := req.ToJob(0)
+	require.NoError(t, err)
+
+	p := req.Preview(job, 8)
+	assert.Equal(t, 2, p.Keywords)
+	assert.Equal(t, job.Config.GridPoints, p.GridPoints)
+	assert.Equal(t, 2*p.GridPoints, p.SeedJobs)
+	assert.Equal(t, job.Progress.TotalPlaces, p.EstimatedPlaces)
+	assert.Equal(t, 1000, p.EstimatedPlaces)
+	assert.Equal(t, 8, p.Concurrency)
+	assert.Equal(t, (int(req.EstimateRuntime().Seconds())+7)/8, p.EstimatedRuntimeSeconds)
+	require.Len(t, p.Warnings, 1)
+	assert.Contains(t, p.Warnings[0], "max_results caps the estimate")
+
+	// The grid the bridge generates is the one the preview counted
+	grid, ok := job.Config.Grid()
+	require.True(t, ok)
+	assert.Len(t, grid.Points, p.GridPoints)
+
+	assert.Equal(t, DefaultWorkerConcurrency, req.Preview(job, 0).Concurrency)
+}
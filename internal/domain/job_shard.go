@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxJobShards caps the shards a job is split into
+const MaxJobShards = 100
+
+var (
+	// ErrInvalidShardCount is returned when splitting a job into fewer than
+	// two shards, more than MaxJobShards or more than it has keywords
+	ErrInvalidShardCount = fmt.Errorf("shard count must be between 2 and %d, and at most the number of keywords", MaxJobShards)
+
+	// ErrJobNotShardable is returned when splitting a job that is no
+	// pending or waiting keyword search, or that is or has shards already
+	ErrJobNotShardable = errors.New("only pending or waiting keyword searches can be sharded, once")
+)
+
+// SplitKeywords splits keywords into n runs of consecutive keywords whose
+// lengths differ by one at most
+func SplitKeywords(keywords []string, n int) [][]string {
+	parts := make([][]string, 0, n)
+	for i := range n {
+		parts = append(parts, keywords[i*len(keywords)/n:(i+1)*len(keywords)/n])
+	}
+	return parts
+}
+
+// Shard splits the keywords of a pending or waiting job over n shards:
+// jobs configured like it that search a part of them each. The job becomes
+// their parent, which runs through them without a worker of its own and
+// finishes once they all did. Its place estimate and max_results are
+// divided among them; its notify_emails stay with it.
+func (j *Job) Shard(n int, now time.Time) ([]*Job, error) {
+	if (j.Status != JobStatusPending && j.Status != JobStatusWaiting) ||
+		len(j.Config.PlaceURLs) > 0 || len(j.RetryKeywords) > 0 ||
+		j.ParentJobID != nil || j.ShardCount > 0 {
+		return nil, ErrJobNotShardable
+	}
+	if n < 2 || n > MaxJobShards || n > len(j.Config.Keywords) {
+		return nil, ErrInvalidShardCount
+	}
+
+	// Padded so that the shards sort by name
+	width := len(strconv.Itoa(n))
+
+	shards := make([]*Job, 0, n)
+	for i, keywords := range SplitKeywords(j.Config.Keywords, n) {
+		config := j.Config
+		config.Keywords = keywords
+		config.NotifyEmails = nil
+		if config.MaxResults > 0 {
+			config.MaxResults = (config.MaxResults + n - 1) / n
+		}
+
+		shards = append(shards, &Job{
+			ID:       uuid.New(),
+			Name:     fmt.Sprintf("%s (shard %0*d/%d)", j.Name, width, i+1, n),
+			Status:   j.Status,
+			Priority: j.Priority,
+			Config:   config,
+			Tenant:   j.Tenant,
+			Progress: JobProgress{
+				TotalPlaces: j.Progress.TotalPlaces * len(keywords) / len(j.Config.Keywords),
+			},
+			Attempts:    1,
+			DependsOn:   j.DependsOn,
+			RunIf:       j.RunIf,
+			ParentJobID: &j.ID,
+			Tags:        NormalizeTags(j.Tags),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+	}
+
+	j.ShardCount = n
+	j.Status = JobStatusRunning
+	j.StartedAt = &now
+	j.UpdatedAt = now
+
+	return shards, nil
+}
+
+// ShardsProgress sums the progress of the shards of a job
+func ShardsProgress(shards []*Job) JobProgress {
+	var p JobProgress
+	for _, shard := range shards {
+		p.TotalPlaces += shard.Progress.TotalPlaces
+		p.ScrapedPlaces += shard.Progress.ScrapedPlaces
+		p.FailedPlaces += shard.Progress.FailedPlaces
+		p.PartialPlaces += shard.Progress.PartialPlaces
+	}
+	p.CalculatePercentage()
+	return p
+}
+
+// ShardsOutcome tells how a sharded job ends once its shards all finished:
+// failed if one of them failed, completed if one completed and cancelled
+// otherwise. It returns the first failed shard, if any, and an empty
+// status while a shard has yet to finish.
+func ShardsOutcome(shards []*Job) (JobStatus, *Job) {
+	var failed *Job
+	completed := false
+	for _, shard := range shards {
+		switch {
+		case !shard.Status.IsTerminal():
+			return "", nil
+		case shard.Status == JobStatusFailed && failed == nil:
+			failed = shard
+		case shard.Status == JobStatusCompleted:
+			completed = true
+		}
+	}
+
+	switch {
+	case failed != nil:
+		return JobStatusFailed, failed
+	case completed:
+		return JobStatusCompleted, nil
+	default:
+		return JobStatusCancelled, nil
+	}
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitKeywords(t *testing.T) {
+	keywords := []string{"a", "b", "c", "d", "e", "f", "g"}
+
+	assert.Equal(t, [][]string{{"a", "b", "c"}, {"d", "e", "f", "g"}}, SplitKeywords(keywords, 2))
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e", "f", "g"}}, SplitKeywords(keywords, 3))
+	assert.Len(t, SplitKeywords(keywords, 7), 7)
+}
+
+func TestJobShard(t *testing.T) {
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	dependency := uuid.New()
+	job := &Job{
+		ID:       uuid.New(),
+		Name:     "cafes",
+		Status:   JobStatusWaiting,
+		Priority: 3,
+		Config: JobConfig{
+			Keywords:     []string{"a", "b", "c", "d", "e"},
+			MaxResults:   100,
+			NotifyEmails: []string{"ops@example.com"},
+		},
+		Progress:  JobProgress{TotalPlaces: 500},
+		DependsOn: &dependency,
+		RunIf:     JobRunIfAlways,
+		Tags:      []string{"leads"},
+	}
+
+	_, err := job.Shard(1, now)
+	assert.ErrorIs(t, err, ErrInvalidShardCount)
+	_, err = job.Shard(6, now)
+	assert.ErrorIs(t, err, ErrInvalidShardCount, "more shards than keywords")
+
+	shards, err := job.Shard(2, now)
+	require.NoError(t, err)
+	require.Len(t, shards, 2)
+
+	assert.Equal(t, "cafes (shard 1/2)", shards[0].Name)
+	assert.Equal(t, []string{"a", "b"}, shards[0].Config.Keywords)
+	assert.Equal(t, []string{"c", "d", "e"}, shards[1].Config.Keywords)
+	for _, shard := range shards {
+		assert.Equal(t, &job.ID, shard.ParentJobID)
+		assert.Equal(t, JobStatusWaiting, shard.Status)
+		assert.Equal(t, 3, shard.Priority)
+		assert.Equal(t, 50, shard.Config.MaxResults)
+		assert.Nil(t, shard.Config.NotifyEmails)
+		assert.Equal(t, &dependency, shard.DependsOn)
+		assert.Equal(t, []string{"leads"}, shard.Tags)
+	}
+	assert.Equal(t, 200, shards[0].Progress.TotalPlaces)
+	assert.Equal(t, 300, shards[1].Progress.TotalPlaces)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, job.Config.Keywords, "parent keeps its keywords")
+
+	assert.Equal(t, 2, job.ShardCount)
+	assert.Equal(t, JobStatusRunning, job.Status)
+	assert.Equal(t, &now, job.StartedAt)
+
+	_, err = job.Shard(2, now)
+	assert.ErrorIs(t, err, ErrJobNotShardable, "already sharded")
+	_, err = shards[0].Shard(2, now)
+	assert.ErrorIs(t, err, ErrJobNotShardable, "a shard")
+
+	places := &Job{Status: JobStatusPending, Config: JobConfig{PlaceURLs: []string{"x", "y"}}}
+	_, err = places.Shard(2, now)
+	assert.ErrorIs(t, err, ErrJobNotShardable)
+
+	many := &Job{Status: JobStatusPending, Name: "many", Config: JobConfig{Keywords: make([]string, 12)}}
+	shards, err = many.Shard(10, now)
+	require.NoError(t, err)
+	assert.Equal(t, "many (shard 01/10)", shards[0].Name, "padded to sort by name")
+}
+
+func TestShardsOutcome(t *testing.T) {
+	shard := func(status JobStatus, scraped int) *Job {
+		return &Job{Status: status, Progress: JobProgress{TotalPlaces: 10, ScrapedPlaces: scraped}}
+	}
+
+	status, _ := ShardsOutcome([]*Job{shard(JobStatusCompleted, 10), shard(JobStatusRunning, 5)})
+	assert.Empty(t, status)
+
+	failed := shard(JobStatusFailed, 2)
+	status, first := ShardsOutcome([]*Job{shard(JobStatusCompleted, 10), failed, shard(JobStatusFailed, 0)})
+	assert.Equal(t, JobStatusFailed, status)
+	assert.Same(t, failed, first)
+
+	status, _ = ShardsOutcome([]*Job{shard(JobStatusCancelled, 0), shard(JobStatusCompleted, 10)})
+	assert.Equal(t, JobStatusCompleted, status)
+
+	status, _ = ShardsOutcome([]*Job{shard(JobStatusCancelled, 0)})
+	assert.Equal(t, JobStatusCancelled, status)
+
+	progress := ShardsProgress([]*Job{shard(JobStatusCompleted, 10), shard(JobStatusRunning, 5)})
+	assert.Equal(t, 20, progress.TotalPlaces)
+	assert.Equal(t, 15, progress.ScrapedPlaces)
+	assert.InDelta(t, 75, progress.Percentage, 0.01)
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobTemplate is a reusable, partial job configuration.
+// Its values are copied into a job at creation time, so editing a
+// template never changes jobs that were already created from it.
+type JobTemplate struct {
+	ID          uuid.UUID         `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Config      JobTemplateConfig `json:"config"`
+	UsageCount  int               `json:"usage_count"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// JobTemplateConfig holds the template defaults. Nil/empty fields are
+// left for the job request to supply.
+type JobTemplateConfig struct {
+	Keywords     []string     `json:"keywords,omitempty"`
+	Lang         *string      `json:"lang,omitempty"`
+	LangFallback []string     `json:"lang_fallback,omitempty"`
+	Zoom         *int         `json:"zoom,omitempty"`
+	Radius       *int         `json:"radius,omitempty"`
+	Depth        *int         `json:"depth,omitempty"`
+	FastMode     *bool        `json:"fast_mode,omitempty"`
+	ExtractEmail *bool        `json:"extract_email,omitempty"`
+	MaxTime      *int         `json:"max_time,omitempty"` // seconds
+	MaxResults   *int         `json:"max_results,omitempty"`
+	Proxies      []string     `json:"proxies,omitempty"`
+	ProxyCountry string       `json:"proxy_country,omitempty"`
+	GoogleDomain string       `json:"google_domain,omitempty"`
+	MaxReviews   *int         `json:"max_reviews,omitempty"`
+	ReviewsSort  string       `json:"reviews_sort,omitempty"`
+	MaxImages    *int         `json:"max_images,omitempty"`
+	Priority     *int         `json:"priority,omitempty"`
+	CoverageMode CoverageMode `json:"coverage_mode,omitempty"`
+
+	BrowserProfile string `json:"browser_profile,omitempty"`
+	UserAgent      string `json:"user_agent,omitempty"`
+	AcceptLanguage string `json:"accept_language,omitempty"`
+	Incremental    *bool  `json:"incremental,omitempty"`
+	GlobalDedupe   *bool  `json:"global_dedupe,omitempty"`
+}
+
+// JobTemplateRequest is the request body for creating or updating a template
+type JobTemplateRequest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Config      JobTemplateConfig `json:"config"`
+}
@@ -0,0 +1,280 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func haversineMeters(a, b GridPoint) float64 {
+	const earthRadius = 6371008.8
+
+	dLat := (b.Lat - a.Lat) * math.Pi / 180
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	h := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(a.Lat*math.Pi/180)*math.Cos(b.Lat*math.Pi/180)*math.Pow(math.Sin(dLon/2), 2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+func TestGenerateGridByRadiusCoverage(t *testing.T) {
+	tests := []struct {
+		name   string
+		box    BoundingBox
+		radius int
+	}{
+		{"city south of the equator", BoundingBox{MinLat: -6.37, MaxLat: -6.08, MinLon: 106.68, MaxLon: 106.97}, 2000},
+		{"crossing the equator", BoundingBox{MinLat: -0.5, MaxLat: 0.5, MinLon: 36.5, MaxLon: 37.5}, 5000},
+		{"tall box crossing the equator", BoundingBox{MinLat: -3, MaxLat: 3, MinLon: 10, MaxLon: 11}, 20000},
+		{"high latitude", BoundingBox{MinLat: 59.8, MaxLat: 60.2, MinLon: 10.5, MaxLon: 11.2}, 3000},
+		{"narrow strip", BoundingBox{MinLat: 40.0, MaxLat: 40.6, MinLon: -3.705, MaxLon: -3.700}, 1000},
+		{"smaller than the radius", BoundingBox{MinLat: 51.50, MaxLat: 51.51, MinLon: -0.13, MaxLon: -0.12}, 5000},
+	}
+
+	const samples = 80
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := tt.box.GenerateGridByRadius(tt.radius)
+			require.NotEmpty(t, layout.Points)
+			assert.Equal(t, layout.Size(), len(layout.Points))
+
+			sized := tt.box.GridLayoutByRadius(tt.radius)
+			assert.Equal(t, layout.Size(), sized.Size())
+			assert.Equal(t, layout.Rows, sized.Rows)
+			assert.Equal(t, layout.Cols, sized.Cols)
+
+			for _, p := range layout.Points {
+				assert.True(t, p.Lat >= tt.box.MinLat && p.Lat <= tt.box.MaxLat, "point %v outside the box", p)
+				assert.True(t, p.Lon >= tt.box.MinLon && p.Lon <= tt.box.MaxLon, "point %v outside the box", p)
+			}
+
+			var worst float64
+			for i := 0; i <= samples; i++ {
+				for j := 0; j <= samples; j++ {
+					s := GridPoint{
+						Lat: tt.box.MinLat + (tt.box.MaxLat-tt.box.MinLat)*float64(i)/samples,
+						Lon: tt.box.MinLon + (tt.box.MaxLon-tt.box.MinLon)*float64(j)/samples,
+					}
+
+					nearest := math.Inf(1)
+					for _, p := range layout.Points {
+						nearest = math.Min(nearest, haversineMeters(s, p))
+					}
+					worst = math.Max(worst, nearest)
+				}
+			}
+
+			assert.LessOrEqual(t, worst, float64(tt.radius)*1.001, "gap of %.0fm with %d points", worst, len(layout.Points))
+		})
+	}
+}
+
+func TestGenerateGridByRadiusHexPacking(t *testing.T) {
+	box := BoundingBox{MinLat: -0.5, MaxLat: 0.5, MinLon: 36.5, MaxLon: 37.5}
+	layout := box.GenerateGridByRadius(5000)
+
+	require.Greater(t, layout.Rows, 1)
+
+	// The second row is shifted by half a column and has one more point
+	evenCols := layout.Cols - 1
+	assert.Equal(t, layout.Size(), (layout.Rows+1)/2*evenCols+layout.Rows/2*layout.Cols)
+	assert.Less(t, layout.Points[evenCols].Lon, layout.Points[0].Lon)
+
+	// A square lattice needs r·√2 spacing for the same coverage
+	side := 5000 * math.Sqrt2
+	square := math.Ceil(111320/side) * math.Ceil(111320/side)
+	assert.Less(t, float64(layout.Size()), square)
+}
+
+func TestCreateJobRequestGridCap(t *testing.T) {
+	newRequest := func(maxGridPoints int) *CreateJobRequest {
+		return &CreateJobRequest{
+			Name:          "grid",
+			Keywords:      []string{"cafe"},
+			Radius:        1000,
+			CoverageMode:  CoverageModeFull,
+			BoundingBox:   &BoundingBox{MinLat: -1, MaxLat: 1, MinLon: 36, MaxLon: 38},
+			MaxGridPoints: maxGridPoints,
+		}
+	}
+
+	points := newRequest(0).CalculateGridPoints()
+	require.Greater(t, points, DefaultMaxGridPoints)
+
+	_, err := newRequest(0).ToJob(0)
+	assert.ErrorIs(t, err, ErrTooManyGridPoints)
+
+	_, err = newRequest(MaxGridPointsLimit + 1).ToJob(0)
+	assert.ErrorIs(t, err, ErrInvalidMaxGridPoints)
+
+	if points <= MaxGridPointsLimit {
+		job, err := newRequest(points).ToJob(0)
+		require.NoError(t, err)
+		assert.Equal(t, points, job.Config.GridPoints)
+	}
+}
+
+func TestCreateJobRequestBrowserProfile(t *testing.T) {
+	preset := BrowserProfiles[BrowserProfileMobileAndroid]
+
+	tests := []struct {
+		name    string
+		req     CreateJobRequest
+		want    BrowserProfile
+		wantErr error
+	}{
+		{
+			name: "scraper defaults",
+		},
+		{
+			name: "preset",
+			req:  CreateJobRequest{BrowserProfile: BrowserProfileMobileAndroid},
+			want: preset,
+		},
+		{
+			name: "preset with its language overridden",
+			req:  CreateJobRequest{BrowserProfile: BrowserProfileMobileAndroid, AcceptLanguage: " de-DE,de;q=0.9 "},
+			want: BrowserProfile{UserAgent: preset.UserAgent, AcceptLanguage: "de-DE,de;q=0.9"},
+		},
+		{
+			name: "custom user agent only",
+			req:  CreateJobRequest{UserAgent: "Mozilla/5.0 (X11; Linux x86_64)"},
+			want: BrowserProfile{UserAgent: "Mozilla/5.0 (X11; Linux x86_64)"},
+		},
+		{
+			name:    "unknown preset",
+			req:     CreateJobRequest{BrowserProfile: "netscape"},
+			wantErr: ErrUnknownBrowserProfile,
+		},
+		{
+			name:    "blank user agent",
+			req:     CreateJobRequest{UserAgent: "   "},
+			wantErr: ErrInvalidUserAgent,
+		},
+		{
+			name:    "header injection",
+			req:     CreateJobRequest{UserAgent: "Mozilla/5.0\r\nCookie: x=1"},
+			wantErr: ErrInvalidUserAgent,
+		},
+		{
+			name:    "overlong accept language",
+			req:     CreateJobRequest{AcceptLanguage: strings.Repeat("en,", MaxAcceptLanguageLength)},
+			wantErr: ErrInvalidAcceptLanguage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Name = "profile"
+			tt.req.Keywords = []string{"cafe"}
+
+			job, err := tt.req.ToJob(0)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.True(t, IsBrowserProfileError(err))
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.req.BrowserProfile, job.Config.BrowserProfile)
+			assert.Equal(t, tt.want.UserAgent, job.Config.UserAgent)
+			assert.Equal(t, tt.want.AcceptLanguage, job.Config.AcceptLanguage)
+			assert.Equal(t, tt.want.Headers(), job.Config.RequestHeaders())
+		})
+	}
+}
+
+func TestCreateJobRequestMaxResults(t *testing.T) {
+	req := &CreateJobRequest{Name: "capped", Keywords: []string{"cafe", "bakery", "florist"}, Depth: 5}
+
+	// 3 keywords × 5 scrolls × 20 results
+	assert.Equal(t, 300, req.EstimateTotalPlaces())
+
+	req.MaxResults = 50
+	assert.Equal(t, 50, req.EstimateTotalPlaces())
+
+	job, err := req.ToJob(0)
+	require.NoError(t, err)
+	assert.Equal(t, 50, job.Config.MaxResults)
+	assert.Equal(t, 50, job.Progress.TotalPlaces)
+
+	req.MaxResults = 1000
+	assert.Equal(t, 300, req.EstimateTotalPlaces())
+}
+
+func TestParseBoundingBox(t *testing.T) {
+	box, err := ParseBoundingBox("13.08, 52.33,13.76,52.68")
+	require.NoError(t, err)
+	assert.Equal(t, &BoundingBox{MinLon: 13.08, MinLat: 52.33, MaxLon: 13.76, MaxLat: 52.68}, box)
+	assert.Equal(t, "13.08,52.33,13.76,52.68", box.String())
+
+	for _, s := range []string{"", "1,2,3", "a,2,3,4", "13.76,52.33,13.08,52.68", "0,-91,1,1"} {
+		_, err := ParseBoundingBox(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseJobErrorCode(t *testing.T) {
+	assert.Equal(t, JobErrorBlockedByGoogle, ParseJobErrorCode("blocked_by_google"))
+	assert.Equal(t, JobErrorInternal, ParseJobErrorCode(""))
+	assert.Equal(t, JobErrorInternal, ParseJobErrorCode("out_of_coffee"))
+
+	for _, code := range JobErrorCodes {
+		assert.Equal(t, code, ParseJobErrorCode(string(code)))
+	}
+
+	assert.True(t, JobErrorTimeout.Retryable())
+	assert.True(t, JobErrorInternal.Retryable())
+	assert.False(t, JobErrorNoResults.Retryable())
+	assert.False(t, JobErrorParse.Retryable())
+}
+
+func TestJobTimeoutDeadline(t *testing.T) {
+	started := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	worker := "w1"
+	job := &Job{Status: JobStatusRunning, WorkerID: &worker, StartedAt: &started, Config: JobConfig{MaxTime: 10 * time.Minute}}
+
+	deadline, ok := job.TimeoutDeadline(1.5)
+	require.True(t, ok)
+	assert.Equal(t, started.Add(15*time.Minute), deadline)
+
+	job.Config.MaxTime = time.Minute
+	deadline, _ = job.TimeoutDeadline(2)
+	assert.Equal(t, started.Add(6*time.Minute), deadline, "max_time under 3 minutes counts as 3")
+
+	job.WorkerID = nil
+	_, ok = job.TimeoutDeadline(2)
+	assert.False(t, ok, "no worker runs the job")
+}
+
+func TestJobAcceptsResults(t *testing.T) {
+	assert.True(t, (&Job{Status: JobStatusRunning}).AcceptsResults())
+	assert.True(t, (&Job{Status: JobStatusCancelled}).AcceptsResults())
+	assert.True(t, (&Job{Status: JobStatusFailed, ErrorCode: JobErrorManagerUnreachable}).AcceptsResults())
+	assert.False(t, (&Job{Status: JobStatusFailed, ErrorCode: JobErrorTimeout}).AcceptsResults())
+	assert.False(t, (&Job{Status: JobStatusCompleted}).AcceptsResults())
+}
+
+func TestJobAwaitsLateResults(t *testing.T) {
+	now := time.Now()
+	failed := now.Add(-time.Hour)
+
+	job := &Job{Status: JobStatusFailed, ErrorCode: JobErrorTimeout, CompletedAt: &failed}
+	assert.True(t, job.AwaitsLateResults(now, 2*time.Hour))
+	assert.False(t, job.AwaitsLateResults(now, 30*time.Minute), "outside the window")
+	assert.False(t, job.AwaitsLateResults(now, 0), "window off")
+
+	job.ErrorCode = JobErrorBlockedByGoogle
+	assert.False(t, job.AwaitsLateResults(now, 2*time.Hour), "failed for another reason")
+
+	job.ErrorCode = JobErrorManagerUnreachable
+	assert.True(t, job.AwaitsLateResults(now, 2*time.Hour))
+
+	job.Status = JobStatusCompleted
+	assert.False(t, job.AwaitsLateResults(now, 2*time.Hour))
+}
@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultMaxExpandedKeywords caps keyword × location expansion unless the
+// manager is configured otherwise
+const DefaultMaxExpandedKeywords = 500
+
+// Rough per-step timings used by EstimateRuntime
+const (
+	secondsPerScroll     = 2
+	secondsPerPlace      = 3
+	secondsPerEmailFetch = 2
+)
+
+// Keyword expansion errors
+var (
+	ErrTooManyKeywords    = errors.New("too many keywords")
+	ErrInvalidLocation    = errors.New("location needs a name or a valid bounding box")
+	ErrNoBaseKeywords     = errors.New("at least one base keyword is required")
+	ErrInvalidSubdivision = errors.New("subdivision must be between 1 and 10")
+)
+
+// KeywordLocation is a place base keywords are combined with. A named
+// location yields "<keyword> in <name>"; a bounding box is split into
+// Subdivision × Subdivision cells (like a Nominatim result subdivided into
+// districts) and yields "<keyword> near <lat>,<lon>" per cell center.
+type KeywordLocation struct {
+	Name        string       `json:"name,omitempty"`
+	BoundingBox *BoundingBox `json:"boundingbox,omitempty"`
+	Subdivision int          `json:"subdivision,omitempty"` // Cells per side, default 1
+}
+
+// ExpandKeywordsRequest is the request for POST /api/v2/jobs/expand-keywords.
+// The scraping fields only feed the estimates.
+type ExpandKeywordsRequest struct {
+	BaseKeywords []string          `json:"base_keywords"`
+	Locations    []KeywordLocation `json:"locations"`
+
+	Depth        int          `json:"depth,omitempty"`
+	Radius       int          `json:"radius,omitempty"`
+	FastMode     bool         `json:"fast_mode,omitempty"`
+	ExtractEmail bool         `json:"extract_email,omitempty"`
+	BoundingBox  *BoundingBox `json:"boundingbox,omitempty"`
+	CoverageMode CoverageMode `json:"coverage_mode,omitempty"`
+}
+
+// KeywordExpansion is the expanded keyword list with its estimates
+type KeywordExpansion struct {
+	Keywords                []string `json:"keywords"`
+	Count                   int      `json:"count"`
+	MaxKeywords             int      `json:"max_keywords"`
+	EstimatedSeedJobs       int      `json:"estimated_seed_jobs"`
+	EstimatedPlaces         int      `json:"estimated_places"`
+	EstimatedRuntimeSeconds int      `json:"estimated_runtime_seconds"`
+}
+
+// ExpandKeywords combines every base keyword with every location. Explicit
+// keywords come first and duplicates are dropped, ignoring case. Without
+// locations the base keywords are returned as they are. maxKeywords <= 0
+// disables the cap.
+func ExpandKeywords(keywords, base []string, locations []KeywordLocation, maxKeywords int) ([]string, error) {
+	var suffixes []string
+
+	for _, loc := range locations {
+		switch {
+		case loc.BoundingBox != nil:
+			if !loc.BoundingBox.IsValid() {
+				return nil, ErrInvalidLocation
+			}
+
+			n := loc.Subdivision
+			if n == 0 {
+				n = 1
+			}
+			if n < 1 || n > 10 {
+				return nil, ErrInvalidSubdivision
+			}
+
+			for _, p := range loc.BoundingBox.GenerateGrid(n) {
+				suffixes = append(suffixes, fmt.Sprintf(" near %.4f,%.4f", p.Lat, p.Lon))
+			}
+		case strings.TrimSpace(loc.Name) != "":
+			suffixes = append(suffixes, " in "+strings.TrimSpace(loc.Name))
+		default:
+			return nil, ErrInvalidLocation
+		}
+	}
+
+	seen := make(map[string]struct{})
+	expanded := make([]string, 0, len(keywords)+len(base)*max(len(suffixes), 1))
+
+	add := func(kw string) {
+		key := keywordKey(kw)
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		expanded = append(expanded, kw)
+	}
+
+	for _, kw := range keywords {
+		if kw = strings.TrimSpace(kw); kw != "" {
+			add(kw)
+		}
+	}
+
+	for _, kw := range base {
+		kw = strings.TrimSpace(kw)
+		if kw == "" {
+			continue
+		}
+
+		if len(suffixes) == 0 {
+			add(kw)
+			continue
+		}
+
+		for _, suffix := range suffixes {
+			add(kw + suffix)
+		}
+	}
+
+	if maxKeywords > 0 && len(expanded) > maxKeywords {
+		return nil, fmt.Errorf("%w: expansion produces %d keywords, the limit is %d", ErrTooManyKeywords, len(expanded), maxKeywords)
+	}
+
+	return expanded, nil
+}
+
+// Expand expands the request and fills in the estimates
+func (r *ExpandKeywordsRequest) Expand(maxKeywords int) (*KeywordExpansion, error) {
+	if len(r.BaseKeywords) == 0 {
+		return nil, ErrNoBaseKeywords
+	}
+
+	keywords, err := ExpandKeywords(nil, r.BaseKeywords, r.Locations, maxKeywords)
+	if err != nil {
+		return nil, err
+	}
+
+	job := CreateJobRequest{
+		Keywords:     keywords,
+		Depth:        r.Depth,
+		Radius:       r.Radius,
+		FastMode:     r.FastMode,
+		ExtractEmail: r.ExtractEmail,
+		BoundingBox:  r.BoundingBox,
+		CoverageMode: r.CoverageMode,
+	}
+
+	return &KeywordExpansion{
+		Keywords:                keywords,
+		Count:                   len(keywords),
+		MaxKeywords:             maxKeywords,
+		EstimatedSeedJobs:       job.EstimateSeedJobs(),
+		EstimatedPlaces:         job.EstimateTotalPlaces(),
+		EstimatedRuntimeSeconds: int(job.EstimateRuntime() / time.Second),
+	}, nil
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxListedDuplicates caps the duplicates a KeywordNormalization lists
+const maxListedDuplicates = 20
+
+// ErrNoKeywords is returned for a job whose keywords are all blank
+var ErrNoKeywords = errors.New("at least one keyword is required")
+
+// KeywordNormalization sums up what NormalizeKeywords did to a submitted
+// keyword list, so a pasted list with repeats and blank lines can be told
+// apart from what will be searched
+type KeywordNormalization struct {
+	Submitted  int `json:"submitted"`
+	Kept       int `json:"kept"`
+	Dropped    int `json:"dropped"`
+	Empty      int `json:"empty"`      // Blank or whitespace only
+	Duplicates int `json:"duplicates"` // Repeats of an earlier keyword, ignoring case and spacing
+	Cleaned    int `json:"cleaned"`    // Kept with whitespace trimmed or collapsed
+
+	// DuplicateKeywords lists the first dropped repeats as submitted
+	DuplicateKeywords []string `json:"duplicate_keywords,omitempty"`
+}
+
+// Add adds the counts of another list, such as the base keywords of the
+// same request
+func (n *KeywordNormalization) Add(o KeywordNormalization) {
+	n.Submitted += o.Submitted
+	n.Kept += o.Kept
+	n.Dropped += o.Dropped
+	n.Empty += o.Empty
+	n.Duplicates += o.Duplicates
+	n.Cleaned += o.Cleaned
+
+	for _, kw := range o.DuplicateKeywords {
+		if len(n.DuplicateKeywords) >= maxListedDuplicates {
+			break
+		}
+		n.DuplicateKeywords = append(n.DuplicateKeywords, kw)
+	}
+}
+
+// NormalizeKeywords trims keywords and collapses the whitespace inside
+// them, then drops blank ones and repeats. Keywords that differ only in
+// case, spacing or Unicode composition are repeats; the first occurrence is
+// kept as written. Nothing else about a keyword changes, so accented and
+// CJK keywords are searched exactly as submitted.
+func NormalizeKeywords(keywords []string) ([]string, KeywordNormalization) {
+	n := KeywordNormalization{Submitted: len(keywords)}
+	if len(keywords) == 0 {
+		return nil, n
+	}
+
+	seen := make(map[string]struct{}, len(keywords))
+	kept := make([]string, 0, len(keywords))
+
+	for _, submitted := range keywords {
+		kw := strings.Join(strings.Fields(submitted), " ")
+		if kw == "" {
+			n.Empty++
+			continue
+		}
+
+		key := keywordKey(kw)
+		if _, ok := seen[key]; ok {
+			n.Duplicates++
+			if len(n.DuplicateKeywords) < maxListedDuplicates {
+				n.DuplicateKeywords = append(n.DuplicateKeywords, submitted)
+			}
+			continue
+		}
+		seen[key] = struct{}{}
+
+		kept = append(kept, kw)
+		if kw != submitted {
+			n.Cleaned++
+		}
+	}
+
+	n.Kept = len(kept)
+	n.Dropped = n.Empty + n.Duplicates
+
+	return kept, n
+}
+
+// keywordKey is what two keywords are compared by: composed, lowercased
+// and with single spaces
+func keywordKey(kw string) string {
+	return strings.ToLower(norm.NFC.String(strings.Join(strings.Fields(kw), " ")))
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/quick"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeKeywords(t *testing.T) {
+	kept, n := NormalizeKeywords([]string{
+		"  Pizza  Napoli ",
+		"",
+		"pizza napoli",
+		"   ",
+		"Café",
+		"café", // "café" with a combining accent
+		"CAFÉ",
+		"東京　ラーメン", // Ideographic space
+		"東京 ラーメン",
+		"Straße",
+		"straße",
+		"ÉCOLE",
+	})
+
+	assert.Equal(t, []string{"Pizza Napoli", "Café", "東京 ラーメン", "Straße", "ÉCOLE"}, kept)
+	assert.Equal(t, KeywordNormalization{
+		Submitted:         12,
+		Kept:              5,
+		Dropped:           7,
+		Empty:             2,
+		Duplicates:        5,
+		Cleaned:           2,
+		DuplicateKeywords: []string{"pizza napoli", "café", "CAFÉ", "東京 ラーメン", "straße"},
+	}, n)
+
+	kept, n = NormalizeKeywords(nil)
+	assert.Nil(t, kept)
+	assert.Zero(t, n.Submitted)
+}
+
+func TestCreateJobRequestNormalizesKeywords(t *testing.T) {
+	req := &CreateJobRequest{Name: "Bakeries", Keywords: []string{" bakery", "Bakery", "\t"}, Lang: "en"}
+	job, err := req.ToJob(0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bakery"}, job.Config.Keywords)
+
+	req = &CreateJobRequest{Name: "Blank", Keywords: []string{"", " \n "}, Lang: "en"}
+	_, err = req.ToJob(0)
+	assert.ErrorIs(t, err, ErrNoKeywords)
+}
+
+// keywordList is a random keyword list mixing scripts, letter case,
+// composed and decomposed accents and Unicode spaces, with repeats
+type keywordList []string
+
+var keywordRunes = []rune("abcXYZ éÉèüÜñßḉ̈東京大阪ラーメン寿司서울카페مقهى 　\t\n")
+
+func (keywordList) Generate(r *rand.Rand, size int) reflect.Value {
+	list := make(keywordList, r.Intn(size+1))
+	for i := range list {
+		if i > 0 && r.Intn(4) == 0 {
+			// A repeat, respaced and recased
+			prev := list[r.Intn(i)]
+			if r.Intn(2) == 0 {
+				prev = strings.ToUpper(prev)
+			}
+			list[i] = " " + strings.ReplaceAll(prev, " ", "　 ") + "\t"
+			continue
+		}
+
+		kw := make([]rune, r.Intn(12))
+		for j := range kw {
+			kw[j] = keywordRunes[r.Intn(len(keywordRunes))]
+		}
+		list[i] = string(kw)
+	}
+	return reflect.ValueOf(list)
+}
+
+func TestNormalizeKeywordsProperties(t *testing.T) {
+	cfg := &quick.Config{MaxCount: 2000}
+
+	t.Run("counts add up", func(t *testing.T) {
+		require.NoError(t, quick.Check(func(list keywordList) bool {
+			kept, n := NormalizeKeywords(list)
+			return n.Submitted == len(list) && n.Kept == len(kept) &&
+				n.Kept+n.Dropped == n.Submitted && n.Dropped == n.Empty+n.Duplicates
+		}, cfg))
+	})
+
+	t.Run("idempotent", func(t *testing.T) {
+		require.NoError(t, quick.Check(func(list keywordList) bool {
+			kept, _ := NormalizeKeywords(list)
+			again, n := NormalizeKeywords(kept)
+			return slices.Equal(kept, again) && n.Dropped == 0 && n.Cleaned == 0
+		}, cfg))
+	})
+
+	t.Run("kept keywords are distinct and tidy", func(t *testing.T) {
+		require.NoError(t, quick.Check(func(list keywordList) bool {
+			kept, _ := NormalizeKeywords(list)
+			seen := make(map[string]bool)
+			for _, kw := range kept {
+				if kw == "" || kw != strings.TrimSpace(kw) || strings.Contains(kw, "  ") || seen[keywordKey(kw)] {
+					return false
+				}
+				seen[keywordKey(kw)] = true
+			}
+			return true
+		}, cfg))
+	})
+
+	t.Run("only whitespace changes", func(t *testing.T) {
+		nonSpace := func(s string) string {
+			return strings.Map(func(r rune) rune {
+				if unicode.IsSpace(r) {
+					return -1
+				}
+				return r
+			}, s)
+		}
+
+		require.NoError(t, quick.Check(func(list keywordList) bool {
+			kept, _ := NormalizeKeywords(list)
+			// Each kept keyword is a submitted one, letter for letter, in order
+			i := 0
+			for _, kw := range kept {
+				for i < len(list) && nonSpace(list[i]) != nonSpace(kw) {
+					i++
+				}
+				if i == len(list) {
+					return false
+				}
+				i++
+			}
+			return true
+		}, cfg))
+	})
+}
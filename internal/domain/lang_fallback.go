@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxLangFallback caps the languages a search is retried with after it
+// found nothing in the job's language
+const MaxLangFallback = 5
+
+// maxLangFallbackKeywords caps the keywords a LangFallbackReport lists
+const maxLangFallbackKeywords = 100
+
+// ErrInvalidLangFallback is returned for a lang_fallback list that cannot
+// be used
+var ErrInvalidLangFallback = errors.New("invalid lang_fallback")
+
+// NormalizeLangFallback lowercases the fallback languages of a job whose
+// language is lang and drops empty and repeated ones and lang itself.
+// Each must be a two-letter code; at most MaxLangFallback are kept.
+func NormalizeLangFallback(lang string, fallback []string) ([]string, error) {
+	if len(fallback) == 0 {
+		return nil, nil
+	}
+
+	seen := map[string]bool{strings.ToLower(lang): true}
+	var out []string
+	for _, l := range fallback {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if l == "" || seen[l] {
+			continue
+		}
+		if len(l) != 2 || l[0] < 'a' || l[0] > 'z' || l[1] < 'a' || l[1] > 'z' {
+			return nil, fmt.Errorf("%w: %q is not a two-letter language code", ErrInvalidLangFallback, l)
+		}
+		seen[l] = true
+		out = append(out, l)
+	}
+
+	if len(out) > MaxLangFallback {
+		return nil, fmt.Errorf("%w: at most %d languages", ErrInvalidLangFallback, MaxLangFallback)
+	}
+
+	return out, nil
+}
+
+// LangFallbackReport sums up the searches of a run with a lang_fallback
+// list: the language each found places in, and the keywords that needed a
+// fallback language or found nothing at all, so they can be fixed
+type LangFallbackReport struct {
+	Searches int            `json:"searches"`  // Searches that finished
+	FellBack int            `json:"fell_back"` // Found places in a fallback language only
+	NotFound int            `json:"not_found"` // Found nothing in any language
+	ByLang   map[string]int `json:"by_lang"`   // Searches that found places, per language
+
+	// Keywords lists the searches that fell back, with the language that
+	// found places, and those that found nothing, without one. Repeated
+	// keywords (one per grid point) are listed once.
+	Keywords []KeywordLang `json:"keywords,omitempty"`
+}
+
+// KeywordLang is a keyword and the language its search found places in,
+// empty when it found none
+type KeywordLang struct {
+	Keyword string `json:"keyword"`
+	Lang    string `json:"lang,omitempty"`
+}
+
+// Add counts a finished search of keyword in a job whose language is
+// jobLang; lang is the language it ended with
+func (r *LangFallbackReport) Add(keyword, jobLang, lang string, placesFound int) {
+	r.Searches++
+
+	if r.ByLang == nil {
+		r.ByLang = make(map[string]int)
+	}
+
+	switch {
+	case placesFound == 0:
+		r.NotFound++
+		lang = ""
+	case lang != jobLang:
+		r.FellBack++
+		r.ByLang[lang]++
+	default:
+		r.ByLang[lang]++
+		return
+	}
+
+	kl := KeywordLang{Keyword: keyword, Lang: lang}
+	if len(r.Keywords) >= maxLangFallbackKeywords {
+		return
+	}
+	for _, listed := range r.Keywords {
+		if listed == kl {
+			return
+		}
+	}
+	r.Keywords = append(r.Keywords, kl)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeLangFallback(t *testing.T) {
+	langs, err := NormalizeLangFallback("de", []string{" EN ", "", "de", "fr", "en"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"en", "fr"}, langs)
+
+	_, err = NormalizeLangFallback("de", []string{"eng"})
+	assert.ErrorIs(t, err, ErrInvalidLangFallback)
+
+	_, err = NormalizeLangFallback("de", []string{"en", "fr", "es", "it", "nl", "pl"})
+	assert.ErrorIs(t, err, ErrInvalidLangFallback)
+}
+
+func TestLangFallbackReportAdd(t *testing.T) {
+	var r LangFallbackReport
+
+	r.Add("bäckerei", "de", "de", 12)
+	r.Add("bakery", "de", "en", 8)
+	r.Add("bakery", "de", "en", 3) // Another grid point
+	r.Add("xyzzy", "de", "fr", 0)
+
+	assert.Equal(t, 4, r.Searches)
+	assert.Equal(t, 2, r.FellBack)
+	assert.Equal(t, 1, r.NotFound)
+	assert.Equal(t, map[string]int{"de": 1, "en": 2}, r.ByLang)
+	assert.Equal(t, []KeywordLang{{Keyword: "bakery", Lang: "en"}, {Keyword: "xyzzy"}}, r.Keywords)
+}
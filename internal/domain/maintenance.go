@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Maintenance run and step states
+const (
+	MaintenancePending   = "pending"
+	MaintenanceRunning   = "running"
+	MaintenanceCompleted = "completed"
+	MaintenanceFailed    = "failed"
+)
+
+// What started a maintenance run
+const (
+	MaintenanceTriggerAPI      = "api"
+	MaintenanceTriggerSchedule = "schedule"
+)
+
+// ErrInvalidMaintenance is returned for maintenance options the database
+// cannot carry out, such as an index that is not maintained
+var ErrInvalidMaintenance = errors.New("invalid maintenance options")
+
+// MaintenanceOptions selects what a maintenance run does. Every run
+// analyzes the tables the listing queries plan with. On SQLite, which has
+// no concurrent reindex, a run always analyzes and vacuums the database.
+type MaintenanceOptions struct {
+	// Vacuum runs VACUUM (ANALYZE) instead of ANALYZE on PostgreSQL
+	Vacuum bool `json:"vacuum,omitempty"`
+
+	// Reindex rebuilds the indexes of business_listings and emails with
+	// REINDEX CONCURRENTLY first (PostgreSQL only)
+	Reindex bool `json:"reindex,omitempty"`
+
+	// Indexes limits Reindex to these indexes, all of them when empty
+	Indexes []string `json:"indexes,omitempty"`
+}
+
+// MaintenanceStep is one statement of a maintenance run
+type MaintenanceStep struct {
+	Statement  string     `json:"statement"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// MaintenanceRun is a pass of ANALYZE, VACUUM or REINDEX statements over
+// the database that keeps the planner statistics, and the approximate
+// counts read from them, fresh. One runs at a time; its steps run in order
+// and a failed step ends the run.
+type MaintenanceRun struct {
+	ID         int64              `json:"id"`
+	Trigger    string             `json:"trigger"`
+	Options    MaintenanceOptions `json:"options"`
+	Status     string             `json:"status"`
+	Steps      []*MaintenanceStep `json:"steps"`
+	Done       int                `json:"done"` // Steps completed
+	Error      string             `json:"error,omitempty"`
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt *time.Time         `json:"finished_at,omitempty"`
+}
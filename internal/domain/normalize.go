@@ -0,0 +1,19 @@
+package domain
+
+// NormalizeBatch counts what one pass of the normalizer did
+type NormalizeBatch struct {
+	Claimed    int // Queued results taken by the pass
+	Normalized int
+	Retrying   int    // Failed, queued again for later
+	GivenUp    int    // Failed their last attempt
+	LastError  string // Error of the last result that failed
+}
+
+// NormalizationLag is how far business listings trail the stored results
+type NormalizationLag struct {
+	Pending int `json:"pending"` // Results waiting to be normalized
+	Failed  int `json:"failed"`  // Results given up on after their attempts
+
+	// Age of the oldest waiting result, 0 when none waits
+	OldestPendingSeconds float64 `json:"oldest_pending_seconds"`
+}
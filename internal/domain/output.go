@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Output types
+const (
+	OutputS3      = "s3"      // One NDJSON object per run, uploaded when it ends
+	OutputWebhook = "webhook" // NDJSON batches POSTed while the job runs
+)
+
+// Output limits
+const (
+	MaxJobOutputs         = 5
+	DefaultWebhookBatch   = 100
+	MaxWebhookBatch       = 1000
+	maxOutputPrefixLength = 512
+)
+
+// ErrInvalidOutput is returned for a job output that cannot be written to
+var ErrInvalidOutput = errors.New("invalid output")
+
+// JobOutput is an additional destination the worker writes a job's results
+// to, besides submitting them to the manager. Results are written as
+// NDJSON, one place per line, in the shape of the stored results.
+type JobOutput struct {
+	Type string `json:"type"`
+
+	// s3: every run writes <prefix><job id>-<run start>.ndjson; Region
+	// defaults to the worker's -aws-region
+	Bucket string `json:"bucket,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+	Region string `json:"region,omitempty"`
+
+	// webhook: each POST carries up to BatchSize places
+	URL       string `json:"url,omitempty"`
+	BatchSize int    `json:"batch_size,omitempty"`
+}
+
+// String names the output in error messages, without the webhook query
+// string that may carry a token
+func (o JobOutput) String() string {
+	switch o.Type {
+	case OutputS3:
+		return "s3://" + o.Bucket + "/" + o.Prefix
+	case OutputWebhook:
+		u, err := url.Parse(o.URL)
+		if err != nil {
+			return "webhook"
+		}
+		return "webhook " + u.Scheme + "://" + u.Host + u.Path
+	default:
+		return o.Type
+	}
+}
+
+// Validate checks the output and fills in defaults
+func (o *JobOutput) Validate() error {
+	switch o.Type {
+	case OutputS3:
+		if o.Bucket == "" || strings.ContainsAny(o.Bucket, "/ ") {
+			return fmt.Errorf("%w: s3 needs a bucket name", ErrInvalidOutput)
+		}
+		if len(o.Prefix) > maxOutputPrefixLength {
+			return fmt.Errorf("%w: s3 prefix is longer than %d characters", ErrInvalidOutput, maxOutputPrefixLength)
+		}
+		if o.URL != "" || o.BatchSize != 0 {
+			return fmt.Errorf("%w: url and batch_size are for webhooks", ErrInvalidOutput)
+		}
+	case OutputWebhook:
+		u, err := url.Parse(o.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: webhook needs an http or https url", ErrInvalidOutput)
+		}
+		if o.Bucket != "" || o.Prefix != "" || o.Region != "" {
+			return fmt.Errorf("%w: bucket, prefix and region are for s3", ErrInvalidOutput)
+		}
+		if o.BatchSize < 0 || o.BatchSize > MaxWebhookBatch {
+			return fmt.Errorf("%w: batch_size must be between 1 and %d", ErrInvalidOutput, MaxWebhookBatch)
+		}
+		if o.BatchSize == 0 {
+			o.BatchSize = DefaultWebhookBatch
+		}
+	default:
+		return fmt.Errorf("%w: type must be %s or %s", ErrInvalidOutput, OutputS3, OutputWebhook)
+	}
+
+	return nil
+}
+
+// ValidateOutputs checks every output of a job
+func ValidateOutputs(outputs []JobOutput) error {
+	if len(outputs) > MaxJobOutputs {
+		return fmt.Errorf("%w: at most %d outputs per job", ErrInvalidOutput, MaxJobOutputs)
+	}
+
+	for i := range outputs {
+		if err := outputs[i].Validate(); err != nil {
+			return fmt.Errorf("outputs[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package domain
+
+// JobParseReport counts, for the last completed run of a job, the places
+// whose fields could not be read from Google's place data, so a change of
+// Google's layout shows up as a jump in a field's rate. Runs of jobs with
+// a lang_fallback list also report how their searches fell back.
+type JobParseReport struct {
+	Places int                        `json:"places"`           // Places parsed in the run
+	Fields map[string]FieldParseStats `json:"fields,omitempty"` // By the field's JSON name in the results
+
+	LangFallback *LangFallbackReport `json:"lang_fallback,omitempty"`
+}
+
+// FieldParseStats counts the places of a run missing a field, which is
+// normal for optional fields, or holding it as another type than expected,
+// which is not. The rates are per place parsed.
+type FieldParseStats struct {
+	Missing     int     `json:"missing"`
+	Failed      int     `json:"failed"`
+	MissingRate float64 `json:"missing_rate"`
+	FailedRate  float64 `json:"failed_rate"`
+}
+
+// Add counts a parsed place with the fields it was missing and failed
+func (r *JobParseReport) Add(missing, failed []string) {
+	r.Places++
+
+	if r.Fields == nil {
+		r.Fields = make(map[string]FieldParseStats)
+	}
+
+	for _, field := range missing {
+		stats := r.Fields[field]
+		stats.Missing++
+		r.Fields[field] = stats
+	}
+
+	for _, field := range failed {
+		stats := r.Fields[field]
+		stats.Failed++
+		r.Fields[field] = stats
+	}
+
+	for field, stats := range r.Fields {
+		stats.MissingRate = float64(stats.Missing) / float64(r.Places)
+		stats.FailedRate = float64(stats.Failed) / float64(r.Places)
+		r.Fields[field] = stats
+	}
+}
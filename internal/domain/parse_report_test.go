@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobParseReportAdd(t *testing.T) {
+	var r JobParseReport
+
+	r.Add([]string{"menu"}, nil)
+	r.Add([]string{"menu", "web_site"}, []string{"rating"})
+	r.Add(nil, nil)
+	r.Add([]string{"menu"}, nil)
+
+	assert.Equal(t, 4, r.Places)
+	assert.Equal(t, FieldParseStats{Missing: 3, MissingRate: 0.75}, r.Fields["menu"])
+	assert.Equal(t, FieldParseStats{Missing: 1, MissingRate: 0.25}, r.Fields["web_site"])
+	assert.Equal(t, FieldParseStats{Failed: 1, FailedRate: 0.25}, r.Fields["rating"])
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Input types of a job: what it scrapes places from
+const (
+	InputTypeKeywords  = "keywords"   // Searches for its keywords, the default
+	InputTypePlaceURLs = "place_urls" // Scrapes the pages of listed places without searching
+)
+
+// MaxPlaceURLs caps the places of a job created from a list
+const MaxPlaceURLs = 10000
+
+// ErrNoPlaceURLs is returned for a place_urls job none of whose places can
+// be scraped
+var ErrNoPlaceURLs = errors.New("at least one valid place URL or place ID is required")
+
+var (
+	// placeIDPattern matches Google place IDs, such as
+	// ChIJN1t_tDeuEmsRUsoyG83frY4 for a business, or Ei... and GhIJ... for
+	// addresses and areas
+	placeIDPattern = regexp.MustCompile(`^(ChIJ|GhIJ|E[a-z])[A-Za-z0-9_-]{16,}$`)
+
+	// cidPattern matches the numeric customer IDs of ?cid= links
+	cidPattern = regexp.MustCompile(`^[0-9]{6,20}$`)
+)
+
+// InvalidPlaceURL is a submitted entry a place_urls job cannot scrape, at
+// its index in the submitted list
+type InvalidPlaceURL struct {
+	Index  int    `json:"index"`
+	Input  string `json:"input"`
+	Reason string `json:"reason"`
+}
+
+// PlaceURLNormalization sums up what NormalizePlaceURLs did to a submitted
+// list, listing every entry that was left out as invalid
+type PlaceURLNormalization struct {
+	Submitted  int               `json:"submitted"`
+	Kept       int               `json:"kept"`
+	Empty      int               `json:"empty"`
+	Duplicates int               `json:"duplicates"`
+	Invalid    []InvalidPlaceURL `json:"invalid"`
+}
+
+// NormalizePlaceURLs turns Google Maps place URLs and place IDs into the
+// URLs the place jobs open, see PlaceURL. Blank entries and repeats are
+// dropped; the first occurrence is kept.
+func NormalizePlaceURLs(inputs []string) ([]string, PlaceURLNormalization) {
+	n := PlaceURLNormalization{Submitted: len(inputs), Invalid: []InvalidPlaceURL{}}
+	if len(inputs) == 0 {
+		return nil, n
+	}
+
+	seen := make(map[string]struct{}, len(inputs))
+	kept := make([]string, 0, len(inputs))
+
+	for i, input := range inputs {
+		if strings.TrimSpace(input) == "" {
+			n.Empty++
+			continue
+		}
+
+		u, err := PlaceURL(input)
+		if err != nil {
+			n.Invalid = append(n.Invalid, InvalidPlaceURL{Index: i, Input: input, Reason: err.Error()})
+			continue
+		}
+		if _, ok := seen[u]; ok {
+			n.Duplicates++
+			continue
+		}
+		seen[u] = struct{}{}
+
+		kept = append(kept, u)
+	}
+
+	n.Kept = len(kept)
+
+	return kept, n
+}
+
+// PlaceURL returns the URL a place job opens for input, which is one of:
+//
+//   - a Google Maps place URL: google.<tld>/maps/place/..., or a URL with
+//     cid, place_id or query_place_id in its query
+//   - a maps.app.goo.gl short link
+//   - a place ID, optionally prefixed with place_id:
+//   - a numeric customer ID, as in ?cid= links
+//
+// URLs are kept as given, over https; IDs are turned into the URL of
+// their place.
+func PlaceURL(input string) (string, error) {
+	s := strings.TrimSpace(input)
+	if s == "" {
+		return "", errors.New("blank")
+	}
+
+	if cidPattern.MatchString(s) {
+		return "https://maps.google.com/?cid=" + s, nil
+	}
+	if id := strings.TrimPrefix(s, "place_id:"); placeIDPattern.MatchString(id) {
+		return "https://www.google.com/maps/place/?q=place_id:" + id, nil
+	}
+
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return "", errors.New("neither a URL nor a place ID")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", errors.New("not an http or https URL")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "maps.app.goo.gl":
+		if strings.Trim(u.Path, "/") == "" {
+			return "", errors.New("short link without a place")
+		}
+	case isGoogleHost(host):
+		q := u.Query()
+		if !strings.HasPrefix(u.Path, "/maps/place/") && q.Get("cid") == "" &&
+			!strings.HasPrefix(q.Get("q"), "place_id:") && q.Get("query_place_id") == "" {
+			return "", errors.New("not the URL of a place: expected /maps/place/, cid or place_id")
+		}
+	default:
+		return "", errors.New("not a Google Maps URL")
+	}
+
+	u.Scheme = "https"
+	u.Fragment = ""
+	return u.String(), nil
+}
+
+// isGoogleHost tells Google hosts: google.<tld>, with or without www. or
+// maps.
+func isGoogleHost(host string) bool {
+	host = strings.TrimPrefix(host, "www.")
+	host = strings.TrimPrefix(host, "maps.")
+	return strings.HasPrefix(host, "google.") && len(host) > len("google.")
+}
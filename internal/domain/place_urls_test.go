@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceURL(t *testing.T) {
+	valid := map[string]string{
+		"https://www.google.com/maps/place/Cafe+Einstein/@52.5,13.4,17z/data=!3m1#x": "https://www.google.com/maps/place/Cafe+Einstein/@52.5,13.4,17z/data=!3m1",
+		"http://maps.google.de/?cid=1234567890123":                                   "https://maps.google.de/?cid=1234567890123",
+		"google.co.uk/maps/place/Pub":                                                "https://google.co.uk/maps/place/Pub",
+		"https://www.google.com/maps/search/?api=1&query=cafe&query_place_id=ChIJx":  "https://www.google.com/maps/search/?api=1&query=cafe&query_place_id=ChIJx",
+		"https://maps.app.goo.gl/abc123":                                             "https://maps.app.goo.gl/abc123",
+		"ChIJN1t_tDeuEmsRUsoyG83frY4":                                                "https://www.google.com/maps/place/?q=place_id:ChIJN1t_tDeuEmsRUsoyG83frY4",
+		" place_id:ChIJN1t_tDeuEmsRUsoyG83frY4 ":                                     "https://www.google.com/maps/place/?q=place_id:ChIJN1t_tDeuEmsRUsoyG83frY4",
+		"12345678901234567890":                                                       "https://maps.google.com/?cid=12345678901234567890",
+	}
+	for input, want := range valid {
+		got, err := PlaceURL(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{
+		"",
+		"cafe berlin",
+		"https://example.com/maps/place/Cafe",
+		"https://www.google.com/maps/search/cafe",
+		"https://maps.app.goo.gl/",
+		"ftp://www.google.com/maps/place/Cafe",
+		"https://google./maps/place/Cafe",
+	} {
+		_, err := PlaceURL(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestNormalizePlaceURLs(t *testing.T) {
+	urls, n := NormalizePlaceURLs([]string{
+		"ChIJN1t_tDeuEmsRUsoyG83frY4",
+		"  ",
+		"https://example.com",
+		"place_id:ChIJN1t_tDeuEmsRUsoyG83frY4",
+		"https://www.google.com/maps/place/Cafe",
+	})
+
+	assert.Equal(t, []string{
+		"https://www.google.com/maps/place/?q=place_id:ChIJN1t_tDeuEmsRUsoyG83frY4",
+		"https://www.google.com/maps/place/Cafe",
+	}, urls)
+	assert.Equal(t, 5, n.Submitted)
+	assert.Equal(t, 2, n.Kept)
+	assert.Equal(t, 1, n.Empty)
+	assert.Equal(t, 1, n.Duplicates)
+	require.Len(t, n.Invalid, 1)
+	assert.Equal(t, 2, n.Invalid[0].Index)
+	assert.Equal(t, "not a Google Maps URL", n.Invalid[0].Reason)
+
+	_, n = NormalizePlaceURLs(nil)
+	assert.NotNil(t, n.Invalid, "listed as [] in responses")
+}
+
+func TestCreateJobRequestPlaceURLs(t *testing.T) {
+	req := &CreateJobRequest{
+		Name:         "places",
+		InputType:    InputTypePlaceURLs,
+		PlaceURLs:    []string{"ChIJN1t_tDeuEmsRUsoyG83frY4", "https://www.google.com/maps/place/Cafe", "nope"},
+		CoverageMode: CoverageModeFull,
+		FastMode:     true,
+	}
+
+	job, err := req.ToJob(0)
+	require.NoError(t, err)
+	assert.Len(t, job.Config.PlaceURLs, 2)
+	assert.Equal(t, []string{}, job.Config.Keywords)
+	assert.Equal(t, CoverageModeSingle, job.Config.CoverageMode, "no grid to search")
+	assert.Equal(t, 2, job.Progress.TotalPlaces)
+	assert.Zero(t, req.EstimateSeedJobs())
+	assert.Positive(t, req.EstimateRuntime(), "places are visited in fast mode too")
+
+	req = &CreateJobRequest{Name: "places", InputType: InputTypePlaceURLs, PlaceURLs: []string{"nope"}}
+	_, err = req.ToJob(0)
+	assert.ErrorIs(t, err, ErrNoPlaceURLs)
+
+	req = &CreateJobRequest{Name: "cafes", Keywords: []string{"cafe"}, PlaceURLs: []string{"ChIJN1t_tDeuEmsRUsoyG83frY4"}}
+	verr, ok := AsValidationError(req.Validate())
+	require.True(t, ok)
+	assert.Equal(t, "place_urls", verr.Fields[0].Field, "place URLs need input_type place_urls")
+
+	req = &CreateJobRequest{Name: "places", InputType: InputTypePlaceURLs, PlaceURLs: []string{"x"}, Shards: 2}
+	verr, ok = AsValidationError(req.Validate())
+	require.True(t, ok)
+	assert.Equal(t, "shards", verr.Fields[0].Field)
+}
@@ -0,0 +1,26 @@
+package domain
+
+// Job queue backends
+const (
+	QueueBackendRabbitMQ = "rabbitmq"
+	QueueBackendRedis    = "redis"
+	QueueBackendNone     = "none" // Workers poll the manager
+)
+
+// QueueStatus is the state of the job queue workers consume from
+type QueueStatus struct {
+	Backend string        `json:"backend"`
+	Queues  []*QueueDepth `json:"queues"`
+}
+
+// QueueDepth is the state of one priority queue
+type QueueDepth struct {
+	Name      string `json:"name"`
+	Depth     int    `json:"depth"`     // Messages waiting for a worker
+	InFlight  int    `json:"in_flight"` // Delivered, not yet acknowledged
+	Consumers int    `json:"consumers"`
+
+	// OldestAgeSeconds is how long the oldest waiting message has been
+	// queued; nil when the queue is empty or the backend does not tell
+	OldestAgeSeconds *float64 `json:"oldest_age_seconds,omitempty"`
+}
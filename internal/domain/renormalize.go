@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Re-normalization run states
+const (
+	RenormalizeRunning   = "running"
+	RenormalizeCompleted = "completed"
+	RenormalizeFailed    = "failed"
+)
+
+// DefaultRenormalizeBatchSize is how many results a re-normalization run
+// parses and stores per transaction
+const DefaultRenormalizeBatchSize = 500
+
+// RenormalizeRun is a pass over the stored results of a job, or of every
+// job, that parses them again with the current schema and updates their
+// business listings. Results up to MaxResultID are covered; later ones were
+// normalized when they were stored.
+type RenormalizeRun struct {
+	ID           int64      `json:"id"`
+	JobID        *uuid.UUID `json:"job_id,omitempty"` // nil for every job
+	Status       string     `json:"status"`
+	MaxResultID  int64      `json:"max_result_id"`
+	LastResultID int64      `json:"last_result_id"` // A resumed run continues after it
+	Total        int        `json:"total"`
+	Processed    int        `json:"processed"`
+	Failed       int        `json:"failed"` // Results written to renormalize_failures
+	Error        string     `json:"error,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+
+	// Active is set when this process is working on the run; a running run
+	// that is not active was interrupted and resumes when started again
+	Active bool `json:"active"`
+}
+
+// StoredResult is a raw result as stored by a worker
+type StoredResult struct {
+	ID    int64
+	JobID *uuid.UUID
+	Data  []byte
+}
+
+// RenormalizedResult is a stored result encoded again in the current
+// schema, ready to be written to business_listings
+type RenormalizedResult struct {
+	ResultID int64
+	JobID    *uuid.UUID
+	Data     []byte
+}
+
+// RenormalizeFailure is a result a run could not normalize
+type RenormalizeFailure struct {
+	ResultID int64
+	JobID    *uuid.UUID
+	Error    string
+}
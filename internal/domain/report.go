@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MaxNotifyEmails caps the addresses a job report is sent to
+const MaxNotifyEmails = 10
+
+// ErrInvalidNotifyEmail is returned for a notify_emails list that cannot be
+// sent to
+var ErrInvalidNotifyEmail = errors.New("invalid notify_emails")
+
+// NormalizeNotifyEmails trims the report addresses of a job and drops empty
+// and repeated ones. Each must be a bare address, without a display name;
+// at most MaxNotifyEmails are kept.
+func NormalizeNotifyEmails(emails []string) ([]string, error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(emails))
+	var out []string
+	for _, e := range emails {
+		e = strings.TrimSpace(e)
+		if e == "" || seen[strings.ToLower(e)] {
+			continue
+		}
+		addr, err := mail.ParseAddress(e)
+		if err != nil || addr.Name != "" || addr.Address != e {
+			return nil, fmt.Errorf("%w: %q is not an email address", ErrInvalidNotifyEmail, e)
+		}
+		seen[strings.ToLower(e)] = true
+		out = append(out, e)
+	}
+
+	if len(out) > MaxNotifyEmails {
+		return nil, fmt.Errorf("%w: at most %d addresses", ErrInvalidNotifyEmail, MaxNotifyEmails)
+	}
+
+	return out, nil
+}
+
+// JobReport sums up the business listings of a job for its summary report
+type JobReport struct {
+	JobID       uuid.UUID `json:"job_id"`
+	Places      int       `json:"places"`
+	WithEmail   int       `json:"with_email"`
+	WithPhone   int       `json:"with_phone"`
+	WithWebsite int       `json:"with_website"`
+
+	// TopCategories are the most frequent categories, most places first
+	TopCategories []CategoryCount `json:"top_categories"`
+
+	// Points are the coordinates of up to the requested number of places,
+	// drawn as the coverage map
+	Points []GeoPoint `json:"points"`
+}
+
+// CategoryCount is a category and the places listed under it
+type CategoryCount struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
+
+// GeoPoint is a place's location
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeNotifyEmails(t *testing.T) {
+	emails, err := NormalizeNotifyEmails([]string{" am@example.com ", "", "AM@example.com", "ops@example.org"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"am@example.com", "ops@example.org"}, emails)
+
+	for _, invalid := range []string{"not-an-email", "Account Manager <am@example.com>", "am@example.com, ops@example.org"} {
+		_, err = NormalizeNotifyEmails([]string{invalid})
+		assert.ErrorIs(t, err, ErrInvalidNotifyEmail, invalid)
+	}
+
+	many := make([]string, MaxNotifyEmails+1)
+	for i := range many {
+		many[i] = string(rune('a'+i)) + "@example.com"
+	}
+	_, err = NormalizeNotifyEmails(many)
+	assert.ErrorIs(t, err, ErrInvalidNotifyEmail)
+}
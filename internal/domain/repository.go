@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -23,25 +24,64 @@ type JobRepository interface {
 	// Delete deletes a job by ID
 	Delete(ctx context.Context, id uuid.UUID) error
 
+	// SoftDelete marks a job as deleted; a job already deleted keeps its
+	// deletion time
+	SoftDelete(ctx context.Context, id uuid.UUID) error
+
+	// Restore clears the deletion mark of a job
+	Restore(ctx context.Context, id uuid.UUID) error
+
+	// ListDeletedBefore returns up to limit jobs deleted before t, oldest
+	// first
+	ListDeletedBefore(ctx context.Context, t time.Time, limit int) ([]uuid.UUID, error)
+
 	// UpdateStatus updates only the status of a job
 	UpdateStatus(ctx context.Context, id uuid.UUID, status JobStatus) error
 
+	// UpdateLabels replaces the tags and notes of a job
+	UpdateLabels(ctx context.Context, id uuid.UUID, tags []string, notes string) error
+
+	// UpdateConfig replaces the config and place estimate of a job, if it
+	// is still pending or paused, not deleted and not split into shards,
+	// and reports whether it was
+	UpdateConfig(ctx context.Context, job *Job) (bool, error)
+
 	// UpdateProgress updates the progress of a job
 	UpdateProgress(ctx context.Context, id uuid.UUID, progress JobProgress) error
 
 	// ClaimJob claims a pending job for a worker (atomic operation)
 	ClaimJob(ctx context.Context, workerID string) (*Job, error)
 
-	// ReleaseJob releases a job back to pending status
+	// ReleaseJob detaches the worker from a job and returns it to pending,
+	// unless it was paused or cancelled in the meantime
 	ReleaseJob(ctx context.Context, id uuid.UUID) error
 
+	// ListRunning returns the running jobs held by workers
+	ListRunning(ctx context.Context) ([]*Job, error)
+
 	// GetStats retrieves job statistics
 	GetStats(ctx context.Context) (*JobStats, error)
+
+	// SetParseReport stores the parse report of a job's last run
+	SetParseReport(ctx context.Context, id uuid.UUID, report *JobParseReport) error
+
+	// GetParseReport returns the parse report of a job's last run, nil when
+	// none was stored
+	GetParseReport(ctx context.Context, id uuid.UUID) (*JobParseReport, error)
+
+	// SetQueueMessageID stores the ID of the message that last enqueued a
+	// job
+	SetQueueMessageID(ctx context.Context, id uuid.UUID, messageID string) error
+
+	// GetQueueMessageID returns the ID of the message that last enqueued a
+	// job, empty when none was stored
+	GetQueueMessageID(ctx context.Context, id uuid.UUID) (string, error)
 }
 
 // WorkerRepository defines the interface for worker persistence
 type WorkerRepository interface {
-	// Upsert creates or updates a worker (for heartbeat)
+	// Upsert creates or updates a worker (for heartbeat). The drain state
+	// is kept and read back into worker.
 	Upsert(ctx context.Context, worker *Worker) error
 
 	// GetByID retrieves a worker by ID
@@ -56,8 +96,17 @@ type WorkerRepository interface {
 	// UpdateStatus updates only the status of a worker
 	UpdateStatus(ctx context.Context, id string, status WorkerStatus) error
 
-	// MarkOfflineWorkers marks workers as offline if heartbeat is stale
-	MarkOfflineWorkers(ctx context.Context, timeout int) (int, error)
+	// MarkOfflineWorkers marks workers as offline if heartbeat is stale and
+	// returns their IDs
+	MarkOfflineWorkers(ctx context.Context, timeout int) ([]string, error)
+
+	// Drain marks a worker as draining until deadline (nil for none). A
+	// worker already draining keeps its start and gets the new deadline.
+	Drain(ctx context.Context, id string, deadline *time.Time) error
+
+	// ExpireDrains clears the current job of draining workers past their
+	// deadline and returns the jobs they held
+	ExpireDrains(ctx context.Context) ([]ExpiredDrain, error)
 
 	// GetStats retrieves worker statistics
 	GetStats(ctx context.Context) (*WorkerStats, error)
@@ -66,13 +115,16 @@ type WorkerRepository interface {
 	IncrementStats(ctx context.Context, id string, jobsCompleted, placesScraped int) error
 }
 
-// ResultRepository defines the interface for result persistence
+// ResultRepository defines the interface for result persistence. Reading
+// the results of a sharded job reads those of its shards.
 type ResultRepository interface {
 	// Create creates a new result
 	Create(ctx context.Context, jobID uuid.UUID, data []byte) error
 
-	// CreateBatch creates multiple results in a batch
-	CreateBatch(ctx context.Context, jobID uuid.UUID, data [][]byte) error
+	// CreateBatch creates multiple results in a batch. A non-nil batchID
+	// is recorded; a batch already recorded returns ErrBatchAlreadyStored
+	// and stores nothing.
+	CreateBatch(ctx context.Context, jobID, batchID uuid.UUID, data [][]byte) error
 
 	// ListAll retrieves all results with pagination (global view)
 	ListAll(ctx context.Context, limit, offset int) ([][]byte, int, error)
@@ -83,6 +135,17 @@ type ResultRepository interface {
 	// CountByJobID counts results for a job
 	CountByJobID(ctx context.Context, jobID uuid.UUID) (int, error)
 
+	// CountsByJobID counts the results of a job for its progress
+	CountsByJobID(ctx context.Context, jobID uuid.UUID) (*ResultCounts, error)
+
+	// ListPartialLinks returns the place links of the partial results of a
+	// job, which a backfill job scrapes again
+	ListPartialLinks(ctx context.Context, jobID uuid.UUID) ([]string, error)
+
+	// LastCreatedAt returns when the latest result of a job was stored,
+	// nil if it has none
+	LastCreatedAt(ctx context.Context, jobID uuid.UUID) (*time.Time, error)
+
 	// DeleteByJobID deletes all results for a job
 	DeleteByJobID(ctx context.Context, jobID uuid.UUID) error
 
@@ -93,6 +156,21 @@ type ResultRepository interface {
 	StreamByJobID(ctx context.Context, jobID uuid.UUID, fn func(data []byte) error) error
 }
 
+// NormalizeQueueRepository normalizes stored results into business
+// listings after they were stored
+type NormalizeQueueRepository interface {
+	// Normalize normalizes up to limit queued results in one transaction.
+	// A result that fails is queued again retryAfter later, doubling with
+	// each attempt, and given up on after maxAttempts; the others are kept.
+	Normalize(ctx context.Context, limit, maxAttempts int, retryAfter time.Duration) (*NormalizeBatch, error)
+
+	// Lag reports the results waiting to be normalized
+	Lag(ctx context.Context) (*NormalizationLag, error)
+
+	// PendingByJobID counts the results of a job waiting to be normalized
+	PendingByJobID(ctx context.Context, jobID uuid.UUID) (int, error)
+}
+
 // ProxyRepository defines the interface for proxy source persistence
 type ProxyRepository interface {
 	// Create creates a new proxy source
@@ -106,6 +184,10 @@ type ProxyRepository interface {
 
 	// GetByID retrieves a proxy source by ID
 	GetByID(ctx context.Context, id int64) (*ProxySource, error)
+
+	// UpdateStats saves the stats of the source with url, including
+	// whether it is active. Sources not stored are ignored.
+	UpdateStats(ctx context.Context, url string, stats *ProxySourceStats) error
 }
 
 // ProxyListRepository defines the interface for proxy list persistence
@@ -125,6 +207,10 @@ type ProxyListRepository interface {
 	// ListHealthy retrieves all healthy proxies (for Pool)
 	ListHealthy(ctx context.Context) ([]*Proxy, error)
 
+	// ListLeastRecentlyChecked retrieves up to limit proxies with status,
+	// never checked ones first (for revalidation)
+	ListLeastRecentlyChecked(ctx context.Context, status ProxyStatus, limit int) ([]*Proxy, error)
+
 	// UpdateStatus updates the status of a proxy
 	UpdateStatus(ctx context.Context, id int64, status ProxyStatus) error
 
@@ -137,6 +223,9 @@ type ProxyListRepository interface {
 	// MarkUsed updates the last_used timestamp
 	MarkUsed(ctx context.Context, id int64) error
 
+	// UpdateMetrics stores the live uptime (percent) and response time (seconds)
+	UpdateMetrics(ctx context.Context, id int64, uptime, responseTime float64) error
+
 	// DeleteDead removes all dead proxies
 	DeleteDead(ctx context.Context) (int, error)
 
@@ -144,13 +233,45 @@ type ProxyListRepository interface {
 	GetStats(ctx context.Context) (*ProxyStats, error)
 }
 
-// BusinessListingRepository defines the interface for business listing persistence
+// ProxyEventRepository stores the ProxyGate log of proxy failures and recoveries
+type ProxyEventRepository interface {
+	// CreateBatch inserts events
+	CreateBatch(ctx context.Context, events []*ProxyEvent) error
+
+	// ListByProxy retrieves the most recent events of a proxy, newest first
+	ListByProxy(ctx context.Context, proxyID int64, limit int) ([]*ProxyEvent, error)
+
+	// Summarize groups events since the given time by reason and by source
+	Summarize(ctx context.Context, since time.Time) (*ProxyEventSummary, error)
+
+	// Prune deletes all but the newest keep events
+	Prune(ctx context.Context, keep int) (int64, error)
+}
+
+// ProxyUsageRepository stores the traffic ProxyGate relayed per upstream
+// proxy and job
+type ProxyUsageRepository interface {
+	// Add adds the counts to those already stored for the same hour, proxy
+	// and job
+	Add(ctx context.Context, usage []*ProxyUsage) error
+
+	// Summarize sums the usage since the given time per proxy or per job
+	// (ProxyUsageByProxy, ProxyUsageByJob)
+	Summarize(ctx context.Context, since time.Time, groupBy string) (*ProxyUsageReport, error)
+
+	// JobTotal sums the usage of a job, nil when none was recorded
+	JobTotal(ctx context.Context, jobID uuid.UUID) (*ProxyTraffic, error)
+}
+
+// BusinessListingRepository defines the interface for business listing
+// persistence. The listings of a sharded job are those of its shards.
 type BusinessListingRepository interface {
 	// List retrieves business listings with filters and pagination
 	List(ctx context.Context, filter BusinessListingFilter) ([]*BusinessListing, int, error)
 
-	// ListByJobID retrieves business listings for a specific job
-	ListByJobID(ctx context.Context, jobID string, limit, offset int) ([]*BusinessListing, int, error)
+	// ListByJobID retrieves business listings for a specific job, filtered
+	// like List. The filter's JobID is replaced by jobID.
+	ListByJobID(ctx context.Context, jobID string, filter BusinessListingFilter) ([]*BusinessListing, int, error)
 
 	// GetByID retrieves a single business listing by ID
 	GetByID(ctx context.Context, id int64) (*BusinessListing, error)
@@ -158,6 +279,10 @@ type BusinessListingRepository interface {
 	// GetCategories returns distinct categories
 	GetCategories(ctx context.Context, limit int) ([]string, error)
 
+	// GetCanonicalCategories returns the canonical categories with the
+	// listings under them across languages, most listed first
+	GetCanonicalCategories(ctx context.Context, limit int) ([]CategoryCount, error)
+
 	// GetCities returns distinct cities
 	GetCities(ctx context.Context, limit int) ([]string, error)
 
@@ -172,4 +297,278 @@ type BusinessListingRepository interface {
 
 	// CountByJobID counts business listings for a job
 	CountByJobID(ctx context.Context, jobID string) (int, error)
+
+	// Sample returns n random listings of a job, or of all jobs when jobID
+	// is empty, with the quality of the listings sampled from
+	Sample(ctx context.Context, jobID string, n int) (*ListingSample, error)
+
+	// FieldStats describes how the listings of a job fill each export
+	// column
+	FieldStats(ctx context.Context, jobID string) (*ListingFieldStats, error)
+}
+
+// CategoryMappingRepository persists the canonical category taxonomy
+type CategoryMappingRepository interface {
+	// Add creates or replaces mappings and relabels the listings of their
+	// aliases, returning the number of listings relabeled
+	Add(ctx context.Context, mappings []CategoryMapping) (int64, error)
+
+	// List returns the mappings ordered by canonical name and alias
+	List(ctx context.Context) ([]*CategoryMapping, error)
+
+	// Unmapped returns the categories of listings no alias matches, most
+	// listed first
+	Unmapped(ctx context.Context, limit int) ([]CategoryCount, error)
+}
+
+// ExportProfileRepository persists the export profiles downloads are
+// limited by
+type ExportProfileRepository interface {
+	// Save creates a profile or replaces the one of the same name
+	Save(ctx context.Context, profile *ExportProfile) error
+
+	// Get retrieves a profile by name, nil when there is none
+	Get(ctx context.Context, name string) (*ExportProfile, error)
+
+	// List returns every profile ordered by name
+	List(ctx context.Context) ([]*ExportProfile, error)
+
+	// Delete deletes a profile, returning ErrExportProfileInUse while API
+	// keys are assigned to it and whether it existed
+	Delete(ctx context.Context, name string) (bool, error)
+}
+
+// ReviewRepository defines the interface for normalized review access.
+// A job's reviews are the reviews of the places it scraped.
+type ReviewRepository interface {
+	// ListByJobID retrieves reviews for a job with pagination
+	ListByJobID(ctx context.Context, jobID string, limit, offset int) ([]*BusinessReview, int, error)
+
+	// StreamByJobID streams all reviews for a job for export
+	StreamByJobID(ctx context.Context, jobID string, fn func(review *BusinessReview) error) error
+}
+
+// DuplicateRepository finds and merges duplicate business listings
+type DuplicateRepository interface {
+	// FindClusters returns duplicate clusters with pagination
+	FindClusters(ctx context.Context, filter DuplicateFilter) ([]*DuplicateCluster, int, error)
+
+	// Merge moves the emails of mergedIDs to survivorID, keeps the highest
+	// review count and deletes the merged rows. It returns nil if the
+	// survivor or one of the merged listings does not exist.
+	Merge(ctx context.Context, survivorID int64, mergedIDs []int64, criterion string) (*ListingMerge, error)
+}
+
+// ListingDiffRepository compares the business listings of two jobs
+type ListingDiffRepository interface {
+	// Diff returns the places of a diff with pagination, added first, then
+	// removed, changed and unchanged, by title
+	Diff(ctx context.Context, filter ListingDiffFilter) ([]*ListingDiff, int, error)
+
+	// Summarize counts every place of the diff of jobID against againstID
+	Summarize(ctx context.Context, jobID, againstID uuid.UUID) (*ListingDiffSummary, error)
+
+	// Stream calls fn for every place of a diff in Diff order
+	Stream(ctx context.Context, filter ListingDiffFilter, fn func(diff *ListingDiff) error) error
+}
+
+// JobReportRepository sums up the business listings of a job
+type JobReportRepository interface {
+	// JobReport counts the places of jobID, its topCategories most frequent
+	// categories and the coordinates of up to maxPoints places
+	JobReport(ctx context.Context, jobID uuid.UUID, topCategories, maxPoints int) (*JobReport, error)
+}
+
+// TimeSeriesRepository counts the metrics of the stats time series
+type TimeSeriesRepository interface {
+	// TimeSeries counts q.Metric per q.Interval, and per group with
+	// q.GroupBy. Buckets without anything counted have no row.
+	TimeSeries(ctx context.Context, q TimeSeriesQuery) ([]TimeSeriesRow, error)
+
+	// Intervals lists the intervals the database can count per
+	Intervals() []string
+}
+
+// APIKeyRepository defines the interface for API key persistence
+type APIKeyRepository interface {
+	// Create creates a new API key
+	Create(ctx context.Context, key *APIKey) error
+
+	// GetByHash retrieves an API key by the hash of its secret
+	GetByHash(ctx context.Context, hash string) (*APIKey, error)
+
+	// List retrieves all API keys
+	List(ctx context.Context) ([]*APIKey, error)
+
+	// Delete deletes an API key by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// TouchLastUsed updates the last_used_at timestamp
+	TouchLastUsed(ctx context.Context, id uuid.UUID) error
+}
+
+// UsageRepository reads usage accounting. Usage is written by the result
+// repository as batches are stored, so it cannot be skipped.
+type UsageRepository interface {
+	// Summary returns per-tenant usage for the filter's date range
+	Summary(ctx context.Context, filter UsageFilter) ([]*TenantUsage, error)
+
+	// MonthlyQuota returns the tenant's quota and its usage this month
+	MonthlyQuota(ctx context.Context, tenant string) (*QuotaStatus, error)
+}
+
+// SeedTaskRepository reads the seed jobs bridged to gmaps_jobs. DSN
+// workers claim the tasks and record how they finished.
+type SeedTaskRepository interface {
+	// ListByParent retrieves the seed tasks of a job, oldest first
+	ListByParent(ctx context.Context, parentID uuid.UUID, params SeedTaskListParams) ([]*SeedTask, int, error)
+
+	// CountsByParent counts the seed tasks of a job by status
+	CountsByParent(ctx context.Context, parentID uuid.UUID) (*SeedTaskCounts, error)
+
+	// FailStale marks tasks claimed longer than timeout ago as failed,
+	// e.g. because their worker died
+	FailStale(ctx context.Context, timeout time.Duration) (int, error)
+
+	// ActiveProgress returns the seed task state of every pending, queued
+	// or running job that has seed tasks
+	ActiveProgress(ctx context.Context) ([]*SeedTaskProgress, error)
+
+	// RetryFailed puts the failed tasks of a job that ran fewer than
+	// maxAttempts times back to new. exhausted counts the failed tasks
+	// left alone.
+	RetryFailed(ctx context.Context, parentID uuid.UUID, maxAttempts int) (requeued, exhausted int, err error)
+
+	// DeleteNew deletes the tasks of a job no DSN worker claimed yet and
+	// returns how many
+	DeleteNew(ctx context.Context, parentID uuid.UUID) (int, error)
+}
+
+// EmailValidationRepository tracks the API validation of stored emails
+type EmailValidationRepository interface {
+	// ClaimDue marks up to limit emails as queued and returns them: emails
+	// never validated with the API, seen again more than ttl after their
+	// validation, or whose validation failed. Claims older than
+	// claimTimeout are taken over.
+	ClaimDue(ctx context.Context, limit int, ttl, claimTimeout time.Duration) ([]string, error)
+
+	// MarkError records that validating email failed
+	MarkError(ctx context.Context, email, reason string) error
+
+	// Stats counts the emails by validation state
+	Stats(ctx context.Context) (*EmailValidationStats, error)
+}
+
+// WebsiteCheckRepository stores the website checks of listings whose job
+// asked for them, and the latest check of each domain
+type WebsiteCheckRepository interface {
+	// ClaimDue marks up to limit listings with an unchecked website as
+	// queued and returns them. Claims older than claimTimeout are taken
+	// over.
+	ClaimDue(ctx context.Context, limit int, claimTimeout time.Duration) ([]WebsiteCheckTask, error)
+
+	// Recent returns the check of domain if one was made within maxAge,
+	// nil otherwise
+	Recent(ctx context.Context, domain string, maxAge time.Duration) (*WebsiteCheck, error)
+
+	// Store records check on the listing and, unless it was reused, as the
+	// latest check of its domain
+	Store(ctx context.Context, listingID int64, check *WebsiteCheck, reused bool) error
+}
+
+// JobTemplateRepository defines the interface for job template persistence
+type JobTemplateRepository interface {
+	// Create creates a new template
+	Create(ctx context.Context, tmpl *JobTemplate) error
+
+	// GetByID retrieves a template by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*JobTemplate, error)
+
+	// List retrieves all templates
+	List(ctx context.Context) ([]*JobTemplate, error)
+
+	// Update updates a template's name, description and config
+	Update(ctx context.Context, tmpl *JobTemplate) error
+
+	// Delete deletes a template by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// IncrementUsage increments the number of jobs created from a template
+	IncrementUsage(ctx context.Context, id uuid.UUID) error
+}
+
+// RenormalizeRepository stores re-normalization runs and the listings they
+// rewrite
+type RenormalizeRepository interface {
+	// StartRun resumes the latest run for jobID (nil for every job) that
+	// did not complete, or creates a new one
+	StartRun(ctx context.Context, jobID *uuid.UUID) (*RenormalizeRun, error)
+
+	// LatestRun returns the latest run for jobID, or nil
+	LatestRun(ctx context.Context, jobID *uuid.UUID) (*RenormalizeRun, error)
+
+	// NextResults returns up to limit results of the run after its
+	// LastResultID, in ID order. Results whose listing was merged into
+	// another one are skipped.
+	NextResults(ctx context.Context, run *RenormalizeRun, limit int) ([]StoredResult, error)
+
+	// SaveBatch upserts the listings and emails of results, records
+	// failures and moves the run to lastResultID in one transaction,
+	// updating the counters of run. A result the database refuses is
+	// recorded as a failure too.
+	SaveBatch(ctx context.Context, run *RenormalizeRun, results []RenormalizedResult, failures []RenormalizeFailure, lastResultID int64) error
+
+	// FinishRun marks the run completed, or failed with errMsg
+	FinishRun(ctx context.Context, run *RenormalizeRun, status, errMsg string) error
+}
+
+// JobEventRepository stores the audit trail of jobs
+type JobEventRepository interface {
+	// Record runs fn with a JobRepository whose changes are committed
+	// together with ev, then records ev unless fn failed. fn may set
+	// ev.JobID, e.g. to the job it claimed; an event left without a job is
+	// not recorded. fn may be nil for events that change nothing else.
+	Record(ctx context.Context, ev *JobEvent, fn func(jobs JobRepository) error) error
+
+	// ListByJobID returns the events of a job, oldest first, and their
+	// total
+	ListByJobID(ctx context.Context, jobID uuid.UUID, limit, offset int) ([]*JobEvent, int, error)
+
+	// DeleteBefore removes events recorded before t and returns how many
+	DeleteBefore(ctx context.Context, t time.Time) (int64, error)
+}
+
+// MaintenanceRepository records maintenance runs and carries out their
+// statements
+type MaintenanceRepository interface {
+	// Lock takes the maintenance lock shared by every manager on the
+	// database and reports whether it got it. unlock releases it.
+	Lock(ctx context.Context) (unlock func(), ok bool, err error)
+
+	// Plan returns the steps of a run with opts, or ErrInvalidMaintenance
+	Plan(ctx context.Context, opts MaintenanceOptions) ([]*MaintenanceStep, error)
+
+	// Exec runs the statement of a step
+	Exec(ctx context.Context, step *MaintenanceStep) error
+
+	// CreateRun stores a new run and sets its ID. The caller holds the
+	// lock, so runs still marked running were left by a stopped manager;
+	// they are marked failed.
+	CreateRun(ctx context.Context, run *MaintenanceRun) error
+
+	// UpdateRun stores the status, steps and error of run
+	UpdateRun(ctx context.Context, run *MaintenanceRun) error
+
+	// LatestRun returns the latest run, or nil
+	LatestRun(ctx context.Context) (*MaintenanceRun, error)
+}
+
+// SettingsRepository stores server-side settings
+type SettingsRepository interface {
+	// Get returns the setting of key, or nil when it was never saved
+	Get(ctx context.Context, key string) (*Setting, error)
+
+	// Put stores s, setting its UpdatedAt, and records the change with
+	// s.UpdatedBy in the settings history
+	Put(ctx context.Context, s *Setting) error
 }
@@ -1,9 +1,20 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrBatchAlreadyStored is returned when a result batch with the same
+// BatchID was stored before, e.g. by a retried submission
+var ErrBatchAlreadyStored = errors.New("result batch already stored")
 
 // ResultBatch represents a batch of results for submission
 type ResultBatch struct {
 	JobID uuid.UUID `json:"job_id"`
-	Data  [][]byte  `json:"data"`
+	// BatchID makes a submission idempotent: a batch is stored once no
+	// matter how often it is sent. Batches without one are always stored.
+	BatchID uuid.UUID `json:"batch_id,omitempty"`
+	Data    [][]byte  `json:"data"`
 }
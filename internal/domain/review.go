@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// BusinessReview is a normalized Google Maps review. Reviews are shared
+// across jobs: re-scraping a place refreshes existing rows instead of
+// adding duplicates.
+type BusinessReview struct {
+	ID              int64     `json:"id"`
+	PlaceID         string    `json:"place_id"`
+	BusinessTitle   string    `json:"business_title"`
+	ReviewerName    string    `json:"reviewer_name"`
+	ReviewerPicture *string   `json:"reviewer_picture,omitempty"`
+	Rating          *int      `json:"rating,omitempty"`
+	Text            string    `json:"text"`
+	RelativeDate    *string   `json:"relative_date,omitempty"`
+	OwnerReply      *string   `json:"owner_reply,omitempty"`
+	Images          []string  `json:"images,omitempty"`
+	FirstSeenAt     time.Time `json:"first_seen_at"`
+	LastSeenAt      time.Time `json:"last_seen_at"`
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SeedTaskStatus is the state of a seed job bridged to gmaps_jobs
+type SeedTaskStatus string
+
+const (
+	SeedTaskStatusNew    SeedTaskStatus = "new"
+	SeedTaskStatusQueued SeedTaskStatus = "queued" // Claimed by a DSN worker
+	SeedTaskStatusOK     SeedTaskStatus = "ok"
+	SeedTaskStatusFailed SeedTaskStatus = "failed"
+)
+
+// IsValid reports whether s is a known seed task status
+func (s SeedTaskStatus) IsValid() bool {
+	switch s {
+	case SeedTaskStatusNew, SeedTaskStatusQueued, SeedTaskStatusOK, SeedTaskStatusFailed:
+		return true
+	}
+	return false
+}
+
+// SeedTask is one search of a Dashboard job handed to DSN workers through
+// gmaps_jobs. Place jobs found by the search run on the same worker and
+// are not tracked separately.
+type SeedTask struct {
+	ID            string         `json:"id"`
+	Keyword       string         `json:"keyword,omitempty"`
+	Geo           string         `json:"geo,omitempty"` // "lat,lon" for geo-targeted searches
+	Status        SeedTaskStatus `json:"status"`
+	PlacesFound   *int           `json:"places_found,omitempty"`
+	Lang          string         `json:"lang,omitempty"`           // Language the search last ran in
+	LangFallbacks int            `json:"lang_fallbacks,omitempty"` // Languages tried before it
+	Error         string         `json:"error,omitempty"`
+	Attempts      int            `json:"attempts"`
+	CreatedAt     time.Time      `json:"created_at"`
+	StartedAt     *time.Time     `json:"started_at,omitempty"`
+	FinishedAt    *time.Time     `json:"finished_at,omitempty"`
+}
+
+// SeedTaskCounts counts the seed tasks of a job by status
+type SeedTaskCounts struct {
+	Total  int `json:"total"`
+	New    int `json:"new"`
+	Queued int `json:"queued"`
+	OK     int `json:"ok"`
+	Failed int `json:"failed"`
+}
+
+// Finished returns the number of tasks that are done, successfully or not
+func (c SeedTaskCounts) Finished() int {
+	return c.OK + c.Failed
+}
+
+// SeedTaskListParams contains parameters for listing the seed tasks of a job
+type SeedTaskListParams struct {
+	Status SeedTaskStatus // Empty for every status
+	Limit  int
+	Offset int
+}
+
+// SeedTaskProgress is the seed task state of an unfinished parent job
+type SeedTaskProgress struct {
+	JobID    uuid.UUID
+	Status   JobStatus
+	Progress JobProgress // As currently stored on the job
+	Counts   SeedTaskCounts
+
+	// Places stored for the job so far, besides those kept partial
+	Places        int
+	PartialPlaces int
+
+	// LastActivity is when a task last finished or a result was stored
+	LastActivity *time.Time
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Settings keys
+const (
+	SettingJobDefaults = "job_defaults"
+	SettingRatingPrior = "rating_prior" // Read by the listing triggers too
+)
+
+// Setting is a server-side setting, a JSON value stored by key
+type Setting struct {
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	UpdatedBy string          `json:"updated_by,omitempty"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// JobDefaults are the house defaults of job creation: a partial job config
+// filling the fields a create request and its template leave unset.
+// UpdatedAt is nil while none were saved.
+type JobDefaults struct {
+	Config    JobTemplateConfig `json:"config"`
+	UpdatedBy string            `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time        `json:"updated_at,omitempty"`
+}
+
+// RatingPrior is the prior of the bayesian rating of listings: Weight
+// reviews of Mean stars counted with those of every place, so that the
+// rating of a place with few reviews stays near Mean. It applies to the
+// listings ingested or renormalized after it is saved. UpdatedAt is nil
+// while the defaults are in use.
+type RatingPrior struct {
+	Mean      float64    `json:"mean" validate:"gte=1,lte=5"`
+	Weight    int        `json:"weight" validate:"gte=0,lte=100000"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// DefaultRatingPrior is the rating prior while none was saved, the one
+// migration 0065 falls back to
+var DefaultRatingPrior = RatingPrior{Mean: 4, Weight: 10}
+
+// Settings are every server-side setting, for the dashboard to pre-fill
+// its forms
+type Settings struct {
+	JobDefaults *JobDefaults `json:"job_defaults"`
+	RatingPrior *RatingPrior `json:"rating_prior"`
+}
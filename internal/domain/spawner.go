@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// Scaling decisions
+const (
+	ScaleUp   = "scale_up"
+	ScaleDown = "scale_down"
+	ScaleHold = "hold"
+)
+
+// SpawnerStatus is the state of the worker auto-scaler, as returned by
+// GET /api/v2/spawner/status
+type SpawnerStatus struct {
+	Spawner string `json:"spawner"`
+
+	// Policy
+	IntervalSeconds    int `json:"interval_seconds"`
+	IdleTimeoutSeconds int `json:"idle_timeout_seconds"`
+	JobsPerWorker      int `json:"jobs_per_worker"`
+	MaxWorkers         int `json:"max_workers"` // 0 = unlimited
+
+	// Inputs of the last evaluation
+	PendingJobs     int `json:"pending_jobs"`
+	BusyWorkers     int `json:"busy_workers"`
+	IdleWorkers     int `json:"idle_workers"`
+	StartingWorkers int `json:"starting_workers"` // Spawned, not registered yet
+
+	// Outcome of the last evaluation
+	DesiredWorkers int        `json:"desired_workers"`
+	Decision       string     `json:"decision"`
+	Delta          int        `json:"delta"` // Workers spawned (> 0) or terminated (< 0)
+	EvaluatedAt    *time.Time `json:"evaluated_at,omitempty"`
+	Error          string     `json:"error,omitempty"`
+
+	SpawnedWorkers []SpawnedWorker `json:"spawned_workers"`
+}
+
+// SpawnedWorker is a worker the auto-scaler started
+type SpawnedWorker struct {
+	ID        string     `json:"id"`
+	SpawnedAt time.Time  `json:"spawned_at"`
+	IdleSince *time.Time `json:"idle_since,omitempty"`
+}
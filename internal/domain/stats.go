@@ -10,6 +10,7 @@ type Stats struct {
 // JobStats contains job-related statistics
 type JobStats struct {
 	Total     int `json:"total"`
+	Waiting   int `json:"waiting"`
 	Pending   int `json:"pending"`
 	Queued    int `json:"queued"`
 	Running   int `json:"running"`
@@ -17,6 +18,9 @@ type JobStats struct {
 	Completed int `json:"completed"`
 	Failed    int `json:"failed"`
 	Cancelled int `json:"cancelled"`
+
+	// FailedByCode breaks Failed down by error code
+	FailedByCode map[JobErrorCode]int `json:"failed_by_code,omitempty"`
 }
 
 // PlaceStats contains place-related statistics
@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Time series metrics
+const (
+	MetricPlaces        = "places"         // Business listings stored
+	MetricEmails        = "emails"         // Emails seen for the first time
+	MetricJobsCompleted = "jobs_completed" // Jobs that completed
+)
+
+// TimeSeriesMetrics lists every metric
+var TimeSeriesMetrics = []string{MetricPlaces, MetricEmails, MetricJobsCompleted}
+
+// Time series intervals, named after date_trunc's fields
+const (
+	IntervalHour  = "hour"
+	IntervalDay   = "day"
+	IntervalWeek  = "week" // Starting on Monday
+	IntervalMonth = "month"
+)
+
+// TimeSeriesIntervals lists every interval
+var TimeSeriesIntervals = []string{IntervalHour, IntervalDay, IntervalWeek, IntervalMonth}
+
+// Time series groupings
+const (
+	GroupByJob      = "job"
+	GroupByCategory = "category"
+)
+
+// Limits of a time series query
+const (
+	DefaultTimeSeriesSeries = 10
+	MaxTimeSeriesSeries     = 25
+	MaxTimeSeriesPoints     = 1000
+)
+
+// TimeSeriesQuery selects a metric counted per interval in [From, To), in
+// UTC. With GroupBy the MaxSeries largest groups get a series each and the
+// rest are counted together.
+type TimeSeriesQuery struct {
+	Metric    string
+	Interval  string
+	From      time.Time
+	To        time.Time
+	GroupBy   string
+	MaxSeries int
+}
+
+// TimeSeriesRow is the count of one bucket of one group. Other marks the
+// groups beyond MaxSeries, Group is empty for them and without GroupBy.
+type TimeSeriesRow struct {
+	Bucket time.Time
+	Group  string
+	Other  bool
+	Count  int64
+}
+
+// TimeSeriesPoint is the count of one interval, starting at Time
+type TimeSeriesPoint struct {
+	Time  time.Time `json:"time"`
+	Value int64     `json:"value"`
+}
+
+// Series is a time series with a point for every interval of the range,
+// zero where nothing was counted
+type Series struct {
+	Key    string            `json:"key"`             // Job ID or category, "" for listings without one
+	Label  string            `json:"label,omitempty"` // Job name
+	Total  int64             `json:"total"`
+	Points []TimeSeriesPoint `json:"points"`
+}
+
+// TimeSeries is the answer to a TimeSeriesQuery. Series are sorted by
+// total, largest first.
+type TimeSeries struct {
+	Metric   string    `json:"metric"`
+	Interval string    `json:"interval"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	GroupBy  string    `json:"group_by,omitempty"`
+	Series   []*Series `json:"series"`
+	Other    *Series   `json:"other,omitempty"` // Groups beyond max_series
+}
+
+// Validate checks q and fills in MaxSeries
+func (q *TimeSeriesQuery) Validate() error {
+	if !slices.Contains(TimeSeriesMetrics, q.Metric) {
+		return fmt.Errorf("metric must be one of %v", TimeSeriesMetrics)
+	}
+	if !slices.Contains(TimeSeriesIntervals, q.Interval) {
+		return fmt.Errorf("interval must be one of %v", TimeSeriesIntervals)
+	}
+	if !q.From.Before(q.To) {
+		return fmt.Errorf("from must be before to")
+	}
+
+	switch q.GroupBy {
+	case "":
+	case GroupByJob, GroupByCategory:
+		if q.Metric == MetricJobsCompleted {
+			return fmt.Errorf("%s cannot be grouped", q.Metric)
+		}
+	default:
+		return fmt.Errorf("group_by must be %s or %s", GroupByJob, GroupByCategory)
+	}
+
+	if q.MaxSeries <= 0 {
+		q.MaxSeries = DefaultTimeSeriesSeries
+	}
+	if q.MaxSeries > MaxTimeSeriesSeries {
+		return fmt.Errorf("max_series must not exceed %d", MaxTimeSeriesSeries)
+	}
+
+	if n := len(q.Buckets()); n > MaxTimeSeriesPoints {
+		return fmt.Errorf("range has %d %ss, at most %d are allowed", n, q.Interval, MaxTimeSeriesPoints)
+	}
+
+	return nil
+}
+
+// Buckets returns the start of every interval of the range, as date_trunc
+// would truncate them in UTC
+func (q *TimeSeriesQuery) Buckets() []time.Time {
+	var buckets []time.Time
+
+	for t := TruncateInterval(q.From, q.Interval); t.Before(q.To); t = NextInterval(t, q.Interval) {
+		buckets = append(buckets, t)
+		if len(buckets) > MaxTimeSeriesPoints {
+			break
+		}
+	}
+
+	return buckets
+}
+
+// TruncateInterval returns the start of the interval t is in, in UTC
+func TruncateInterval(t time.Time, interval string) time.Time {
+	t = t.UTC()
+
+	switch interval {
+	case IntervalHour:
+		return t.Truncate(time.Hour)
+	case IntervalWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// NextInterval returns the start of the interval after the one starting at t
+func NextInterval(t time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalHour:
+		return t.Add(time.Hour)
+	case IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	case IntervalMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
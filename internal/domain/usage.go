@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrQuotaExceeded is returned when a tenant has used up its monthly
+// place quota
+var ErrQuotaExceeded = errors.New("monthly place quota exceeded")
+
+// QuotaExceededError is the quota a job or result batch ran into
+type QuotaExceededError struct {
+	Tenant   string    `json:"tenant"`
+	Quota    int       `json:"quota"`
+	Used     int       `json:"used"`
+	ResetsAt time.Time `json:"resets_at"`
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s: %d of %d places used, resets %s",
+		ErrQuotaExceeded, e.Used, e.Quota, e.ResetsAt.Format(time.DateOnly))
+}
+
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// QuotaStatus is a tenant's place usage in the current month
+type QuotaStatus struct {
+	Tenant string
+	Quota  *int // nil = unlimited
+	Used   int
+}
+
+// Remaining returns how many more places the tenant may scrape this month,
+// or -1 without a quota
+func (q *QuotaStatus) Remaining() int {
+	if q.Quota == nil {
+		return -1
+	}
+	return max(*q.Quota-q.Used, 0)
+}
+
+// Check returns a *QuotaExceededError once the quota is used up
+func (q *QuotaStatus) Check() error {
+	if q.Quota == nil || q.Used < *q.Quota {
+		return nil
+	}
+
+	return &QuotaExceededError{
+		Tenant:   q.Tenant,
+		Quota:    *q.Quota,
+		Used:     q.Used,
+		ResetsAt: QuotaPeriodStart(time.Now()).AddDate(0, 1, 0),
+	}
+}
+
+// QuotaPeriodStart returns the start of the quota month t falls in. Quotas
+// run per calendar month in UTC.
+func QuotaPeriodStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// DailyUsage is one tenant's usage on one UTC day
+type DailyUsage struct {
+	Day              string `json:"day"` // YYYY-MM-DD
+	PlacesScraped    int64  `json:"places_scraped"`
+	EmailValidations int64  `json:"email_validations"`
+}
+
+// TenantUsage aggregates a tenant's usage over a date range
+type TenantUsage struct {
+	Tenant            string       `json:"tenant"`
+	APIKeyName        string       `json:"api_key_name,omitempty"`
+	MonthlyPlaceQuota *int         `json:"monthly_place_quota,omitempty"`
+	PlacesScraped     int64        `json:"places_scraped"`
+	EmailValidations  int64        `json:"email_validations"`
+	Days              []DailyUsage `json:"days"`
+}
+
+// UsageFilter selects the usage returned by GET /api/v2/usage. From and To
+// are inclusive UTC dates.
+type UsageFilter struct {
+	Tenant string
+	From   time.Time
+	To     time.Time
+}
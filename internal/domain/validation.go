@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is a field of a request that breaks a rule
+type FieldError struct {
+	Field   string `json:"field"` // JSON path, e.g. keywords[2] or boundingbox.max_lat
+	Rule    string `json:"rule"`  // The rule broken, e.g. required, max or latitude
+	Message string `json:"message"`
+}
+
+// ValidationError lists every field of a request that failed validation.
+// The API answers it with 422 and the fields.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Add records that field breaks rule
+func (e *ValidationError) Add(field, rule, format string, args ...any) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err returns e, or nil if no field failed
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// AsValidationError returns the ValidationError in the chain of err
+func AsValidationError(err error) (*ValidationError, bool) {
+	var verr *ValidationError
+	ok := errors.As(err, &verr)
+	return verr, ok
+}
+
+// tagValidator checks structs against their validate tags, naming fields
+// after their JSON keys
+var tagValidator = sync.OnceValue(func() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+	return v
+})
+
+// Check adds the fields of the struct s that break their validate tags.
+// prefix is the JSON path of s in the request, empty for the request
+// itself.
+func (e *ValidationError) Check(prefix string, s any) {
+	err := tagValidator().Struct(s)
+	if err == nil {
+		return
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		e.Add(prefix, "invalid", "%v", err)
+		return
+	}
+
+	for _, fe := range fieldErrs {
+		// The namespace starts with the struct type, e.g.
+		// CreateJobRequest.keywords[2]
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		if prefix != "" {
+			field = prefix + "." + field
+		}
+		e.Add(field, fe.Tag(), "%s", ruleMessage(field, fe))
+	}
+}
+
+// ruleMessage words the rule a field broke
+func ruleMessage(field string, fe validator.FieldError) string {
+	// Strings and lists are measured by length, numbers by value
+	var unit string
+	switch fe.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "min":
+		if unit == "" {
+			return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must have at least %s%s", field, fe.Param(), unit)
+	case "max":
+		if unit == "" {
+			return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must have at most %s%s", field, fe.Param(), unit)
+	case "len":
+		return fmt.Sprintf("%s must have exactly %s%s", field, fe.Param(), unit)
+	case "latitude":
+		return field + " must be a latitude between -90 and 90"
+	case "longitude":
+		return field + " must be a longitude between -180 and 180"
+	case "gtfield":
+		return fmt.Sprintf("%s must be greater than %s", field, snakeCase(fe.Param()))
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "url":
+		return field + " must be a URL"
+	default:
+		return fmt.Sprintf("%s breaks the %s rule", field, fe.Tag())
+	}
+}
+
+// snakeCase turns the Go name of a field compared against into its JSON
+// name, e.g. MinLat into min_lat
+func snakeCase(goName string) string {
+	var b strings.Builder
+	for i, r := range goName {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToLower(b.String())
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateJobRequestValidate(t *testing.T) {
+	lat := func(v float64) *float64 { return &v }
+	box := &BoundingBox{MinLat: -6.3, MaxLat: -6.1, MinLon: 106.7, MaxLon: 106.9}
+
+	tests := []struct {
+		name   string
+		modify func(r *CreateJobRequest)
+		fields []FieldError // Field and rule only; nil for a valid request
+	}{
+		{"valid", func(r *CreateJobRequest) {}, nil},
+		{"missing name", func(r *CreateJobRequest) { r.Name = "" }, []FieldError{{Field: "name", Rule: "required"}}},
+		{"latitude out of range", func(r *CreateJobRequest) { r.GeoLat, r.GeoLon = lat(91), lat(0) }, []FieldError{{Field: "geo_lat", Rule: "latitude"}}},
+		{"longitude out of range", func(r *CreateJobRequest) { r.GeoLat, r.GeoLon = lat(0), lat(-181) }, []FieldError{{Field: "geo_lon", Rule: "longitude"}}},
+		{"latitude without longitude", func(r *CreateJobRequest) { r.GeoLat = lat(10) }, []FieldError{{Field: "geo_lon", Rule: "required_with"}}},
+		{"zoom too high", func(r *CreateJobRequest) { r.Zoom = 22 }, []FieldError{{Field: "zoom", Rule: "max"}}},
+		{"depth too high", func(r *CreateJobRequest) { r.Depth = 101 }, []FieldError{{Field: "depth", Rule: "max"}}},
+		{"negative depth", func(r *CreateJobRequest) { r.Depth = -1 }, []FieldError{{Field: "depth", Rule: "min"}}},
+		{"max time too short", func(r *CreateJobRequest) { r.MaxTime = 60 }, []FieldError{{Field: "max_time", Rule: "min"}}},
+		{"unknown coverage mode", func(r *CreateJobRequest) { r.CoverageMode = "city" }, []FieldError{{Field: "coverage_mode", Rule: "oneof"}}},
+		{"full coverage", func(r *CreateJobRequest) {
+			r.CoverageMode, r.BoundingBox, r.Radius = CoverageModeFull, box, 5000
+		}, nil},
+		{"full coverage without bounding box", func(r *CreateJobRequest) {
+			r.CoverageMode, r.Radius = CoverageModeFull, 5000
+		}, []FieldError{{Field: "boundingbox", Rule: "required"}}},
+		{"full coverage with small radius", func(r *CreateJobRequest) {
+			r.CoverageMode, r.BoundingBox, r.Radius = CoverageModeFull, box, 50
+		}, []FieldError{{Field: "radius", Rule: "min"}}},
+		{"inverted bounding box", func(r *CreateJobRequest) {
+			r.CoverageMode, r.Radius = CoverageModeFull, 5000
+			r.BoundingBox = &BoundingBox{MinLat: 10, MaxLat: 5, MinLon: 200, MaxLon: 210}
+		}, []FieldError{
+			{Field: "boundingbox.max_lat", Rule: "gtfield"},
+			{Field: "boundingbox.min_lon", Rule: "longitude"},
+			{Field: "boundingbox.max_lon", Rule: "longitude"},
+		}},
+		{"bounding box ignored in single mode", func(r *CreateJobRequest) {
+			r.BoundingBox = &BoundingBox{MinLat: 10, MaxLat: 5}
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &CreateJobRequest{Name: "cafes", Keywords: []string{"cafe"}}
+			tt.modify(req)
+
+			err := req.Validate()
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			verr, ok := AsValidationError(err)
+			require.True(t, ok, "got %v", err)
+
+			var got []FieldError
+			for _, f := range verr.Fields {
+				assert.NotEmpty(t, f.Message)
+				got = append(got, FieldError{Field: f.Field, Rule: f.Rule})
+			}
+			assert.ElementsMatch(t, tt.fields, got)
+		})
+	}
+}
+
+func TestCreateJobRequestToJobValidates(t *testing.T) {
+	req := &CreateJobRequest{Name: "cafes", Keywords: []string{"cafe"}, Zoom: 30}
+
+	_, err := req.ToJob(0)
+	verr, ok := AsValidationError(err)
+	require.True(t, ok, "got %v", err)
+	assert.Equal(t, []FieldError{{Field: "zoom", Rule: "max", Message: "zoom must be at most 21"}}, verr.Fields)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// Website check defaults
+const (
+	// DefaultWebsiteCheckTTL is how long the check of a domain is reused
+	// for other listings with a website on it
+	DefaultWebsiteCheckTTL = 30 * 24 * time.Hour
+
+	// DefaultWebsiteCheckWorkers is the number of website checkers the
+	// manager runs
+	DefaultWebsiteCheckWorkers = 4
+)
+
+// Website statuses of a listing whose job had CheckWebsite
+const (
+	WebsiteStatusOK          = "ok"          // Answered 2xx and is not parked
+	WebsiteStatusParked      = "parked"      // A parked or for-sale domain
+	WebsiteStatusHTTPError   = "http_error"  // Answered 4xx/5xx, or redirected too often
+	WebsiteStatusUnreachable = "unreachable" // DNS, connection, TLS or timeout failure
+)
+
+// ValidWebsiteStatuses are the values of business_listings.website_status
+var ValidWebsiteStatuses = map[string]bool{
+	WebsiteStatusOK:          true,
+	WebsiteStatusParked:      true,
+	WebsiteStatusHTTPError:   true,
+	WebsiteStatusUnreachable: true,
+}
+
+// WebsiteCheck is the outcome of fetching a website
+type WebsiteCheck struct {
+	Domain     string    `json:"domain"` // Host without www., what checks are reused by
+	Status     string    `json:"status"`
+	HTTPStatus int       `json:"http_status,omitempty"` // Of the final response, 0 when there was none
+	FinalURL   string    `json:"final_url,omitempty"`   // After redirects
+	Error      string    `json:"error,omitempty"`       // Why the site was unreachable
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// WebsiteCheckTask is a listing whose website is due for a check
+type WebsiteCheckTask struct {
+	ListingID int64
+	Website   string
+}
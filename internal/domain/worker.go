@@ -27,6 +27,21 @@ type Worker struct {
 	JobsCompleted int `json:"jobs_completed"`
 	PlacesScraped int `json:"places_scraped"`
 
+	// Throttle reports the worker's adaptive rate limiter
+	RequestDelayMs int64 `json:"request_delay_ms"`
+	BlockCount     int64 `json:"block_count"`
+
+	// Progress of the current job and memory use, as of the last heartbeat
+	SeedJobsCompleted int   `json:"seed_jobs_completed"`
+	SeedJobsTotal     int   `json:"seed_jobs_total"`
+	JobPlacesScraped  int   `json:"job_places_scraped"`
+	MemoryBytes       int64 `json:"memory_bytes"`
+
+	// Drain takes the worker out of rotation: it finishes its current job,
+	// then exits. Past the deadline its job is released back to pending.
+	DrainingSince *time.Time `json:"draining_since,omitempty"`
+	DrainDeadline *time.Time `json:"drain_deadline,omitempty"`
+
 	// Heartbeat
 	LastHeartbeat time.Time `json:"last_heartbeat"`
 	CreatedAt     time.Time `json:"created_at"`
@@ -37,12 +52,90 @@ func (w *Worker) IsOnline(timeout time.Duration) bool {
 	return time.Since(w.LastHeartbeat) < timeout
 }
 
-// WorkerHeartbeat is the request from a worker to update its status
+// IsDraining returns true if the worker was asked to drain
+func (w *Worker) IsDraining() bool {
+	return w.DrainingSince != nil
+}
+
+// Directives returns what the manager asks of the worker in the response
+// to its heartbeat, or nil when there is nothing to ask
+func (w *Worker) Directives() *WorkerDirectives {
+	if !w.IsDraining() {
+		return nil
+	}
+
+	d := &WorkerDirectives{Drain: true}
+	if w.DrainDeadline != nil {
+		// Sent as a duration so the worker's clock doesn't matter
+		remaining := int(max(time.Until(*w.DrainDeadline), 0).Seconds())
+		d.DrainTimeoutSeconds = &remaining
+	}
+
+	return d
+}
+
+// WorkerHeartbeat is the request from a worker to update its status. It is
+// also the state streamed to the fleet view.
 type WorkerHeartbeat struct {
-	WorkerID     string       `json:"worker_id"`
-	Hostname     string       `json:"hostname"`
-	Status       WorkerStatus `json:"status"`
-	CurrentJobID *uuid.UUID   `json:"current_job_id,omitempty"`
+	WorkerID       string       `json:"worker_id"`
+	Hostname       string       `json:"hostname"`
+	Status         WorkerStatus `json:"status"`
+	CurrentJobID   *uuid.UUID   `json:"current_job_id,omitempty"`
+	CurrentJobName string       `json:"current_job_name,omitempty"`
+
+	RequestDelayMs int64 `json:"request_delay_ms"`
+	BlockCount     int64 `json:"block_count"`
+
+	// Progress of the current job, zero while idle. A worker running
+	// several jobs reports its oldest one as the current job and the sum
+	// of their progress.
+	SeedJobsCompleted int `json:"seed_jobs_completed"`
+	SeedJobsTotal     int `json:"seed_jobs_total"`
+	JobPlacesScraped  int `json:"job_places_scraped"`
+
+	MemoryBytes int64 `json:"memory_bytes"` // Memory the worker process holds from the OS
+
+	// Jobs lists every job the worker runs, oldest first
+	Jobs []WorkerJob `json:"jobs,omitempty"`
+}
+
+// WorkerJob is a job a worker runs and its progress
+type WorkerJob struct {
+	JobID             uuid.UUID `json:"job_id"`
+	JobName           string    `json:"job_name,omitempty"`
+	SeedJobsCompleted int       `json:"seed_jobs_completed"`
+	SeedJobsTotal     int       `json:"seed_jobs_total"`
+	PlacesScraped     int       `json:"places_scraped"`
+}
+
+// WorkerDirectives is the manager's response to a heartbeat
+type WorkerDirectives struct {
+	// Drain asks the worker to stop taking jobs and exit once its current
+	// job is done
+	Drain bool `json:"drain"`
+
+	// DrainTimeoutSeconds is how long the worker has left to finish its
+	// job; at zero the job was, or is about to be, released. Nil waits
+	// for the job however long it takes.
+	DrainTimeoutSeconds *int `json:"drain_timeout_seconds,omitempty"`
+
+	// StopJob names a job the worker runs that was paused, cancelled,
+	// deleted or timed out, so it stops right away. Only the gRPC heartbeat stream
+	// pushes it; HTTP workers find out by polling the job.
+	StopJob *JobStop `json:"stop_job,omitempty"`
+}
+
+// JobStop is a running job that has to stop, and the status it stops for
+type JobStop struct {
+	JobID  uuid.UUID `json:"job_id"`
+	Status JobStatus `json:"status"`
+}
+
+// ExpiredDrain is a job held by a worker that did not drain before its
+// deadline
+type ExpiredDrain struct {
+	WorkerID string
+	JobID    uuid.UUID
 }
 
 // WorkerStats contains aggregated worker statistics
@@ -65,3 +158,10 @@ const HeartbeatTimeout = 30 * time.Second
 
 // HeartbeatInterval is how often workers should send heartbeats
 const HeartbeatInterval = 10 * time.Second
+
+// MaxClaimWait caps how long a claim with ?wait is held waiting for a job
+const MaxClaimWait = 60 * time.Second
+
+// ClaimWaitHeader is set on claim responses by managers honouring ?wait,
+// to the wait they applied, so workers know to long-poll
+const ClaimWaitHeader = "X-Claim-Wait"
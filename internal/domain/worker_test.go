@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerDirectives(t *testing.T) {
+	w := &Worker{ID: "w1"}
+	assert.Nil(t, w.Directives(), "not draining")
+
+	since := time.Now()
+	w.DrainingSince = &since
+	assert.Equal(t, &WorkerDirectives{Drain: true}, w.Directives(), "no deadline")
+
+	deadline := time.Now().Add(90 * time.Second)
+	w.DrainDeadline = &deadline
+	d := w.Directives()
+	require.NotNil(t, d.DrainTimeoutSeconds)
+	assert.InDelta(t, 90, *d.DrainTimeoutSeconds, 1)
+
+	past := time.Now().Add(-time.Minute)
+	w.DrainDeadline = &past
+	d = w.Directives()
+	require.NotNil(t, d.DrainTimeoutSeconds)
+	assert.Equal(t, 0, *d.DrainTimeoutSeconds, "deadline passed")
+}
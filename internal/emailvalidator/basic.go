@@ -0,0 +1,149 @@
+package emailvalidator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Resolver looks up the mail servers of a domain; *net.Resolver satisfies it
+type Resolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// BasicValidator validates emails without an external API: syntax, mail
+// servers of the domain and known disposable domains. It cannot tell
+// whether the mailbox exists.
+type BasicValidator struct {
+	resolver Resolver
+	timeout  time.Duration
+}
+
+// BasicConfig for the basic validator
+type BasicConfig struct {
+	Timeout  time.Duration // DNS lookup timeout
+	Resolver Resolver      // Optional: defaults to net.DefaultResolver
+}
+
+// disposableDomains contains common throwaway email providers
+var disposableDomains = map[string]bool{
+	"mailinator.com": true, "guerrillamail.com": true, "guerrillamail.net": true,
+	"10minutemail.com": true, "tempmail.com": true, "temp-mail.org": true,
+	"yopmail.com": true, "maildrop.cc": true, "dispostable.com": true,
+	"fakeinbox.com": true, "trashmail.com": true, "getnada.com": true,
+	"sharklasers.com": true, "throwawaymail.com": true, "mailnesia.com": true,
+	"mintemail.com": true, "mohmal.com": true, "emailondeck.com": true,
+}
+
+// roleLocalParts contains local parts of shared mailboxes
+var roleLocalParts = map[string]bool{
+	"info": true, "contact": true, "sales": true, "support": true,
+	"admin": true, "office": true, "hello": true, "enquiries": true,
+	"inquiries": true, "help": true, "billing": true, "marketing": true,
+	"team": true, "mail": true, "webmaster": true, "postmaster": true,
+}
+
+// NewBasicValidator creates a new basic validator
+func NewBasicValidator(cfg BasicConfig) *BasicValidator {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	var resolver Resolver = net.DefaultResolver
+	if cfg.Resolver != nil {
+		resolver = cfg.Resolver
+	}
+
+	return &BasicValidator{
+		resolver: resolver,
+		timeout:  timeout,
+	}
+}
+
+// Validate checks the syntax of email and that its domain accepts mail.
+// Scores follow the Mordibouncer mapping: an address whose domain has mail
+// servers counts as valid (80), role accounts are capped at 50 and
+// disposable domains at 20.
+func (v *BasicValidator) Validate(ctx context.Context, email string) (*ValidationResult, error) {
+	email = Normalize(email)
+	res := &ValidationResult{Email: email, Status: "invalid"}
+
+	addr, err := mail.ParseAddress(email)
+	at := strings.LastIndex(email, "@")
+	if err != nil || addr.Address != email || at <= 0 || !strings.Contains(email[at+1:], ".") {
+		res.Reason = "invalid syntax"
+		return res, nil
+	}
+
+	local, domain := email[:at], email[at+1:]
+	res.FreeEmail = freeEmailDomains[domain]
+	res.RoleAccount = roleLocalParts[local]
+	res.Disposable = disposableDomains[domain]
+
+	ok, err := v.acceptsMail(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		res.Reason = "domain does not accept mail"
+		return res, nil
+	}
+
+	res.Status = "valid"
+	res.Score = 80
+	res.Deliverable = true
+
+	if res.RoleAccount {
+		res.Score = min(res.Score, 50)
+		res.Reason = "role account"
+	}
+	if res.Disposable {
+		res.Status = "risky"
+		res.Score = min(res.Score, 20)
+		res.Reason = "disposable email"
+	}
+
+	return res, nil
+}
+
+// acceptsMail reports whether domain has MX records or, lacking them, an
+// address mail can be delivered to (RFC 5321 implicit MX). Lookup failures
+// other than a missing domain are returned.
+func (v *BasicValidator) acceptsMail(ctx context.Context, domain string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	mxs, err := v.resolver.LookupMX(ctx, domain)
+	if err == nil {
+		for _, mx := range mxs {
+			// A single "." MX means the domain accepts no mail (RFC 7505)
+			if mx.Host != "." && mx.Host != "" {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	if !isNotFound(err) {
+		return false, err
+	}
+
+	addrs, err := v.resolver.LookupHost(ctx, domain)
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return len(addrs) > 0, nil
+}
+
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
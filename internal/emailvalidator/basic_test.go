@@ -0,0 +1,143 @@
+package emailvalidator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver answers from fixed records; domains it does not know do not
+// exist and "broken.io" fails to resolve
+type fakeResolver struct {
+	mx    map[string][]*net.MX
+	hosts map[string][]string
+}
+
+func (f fakeResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	if name == "broken.io" {
+		return nil, &net.DNSError{Err: "server misbehaving", Name: name, IsTemporary: true}
+	}
+	if mx, ok := f.mx[name]; ok {
+		return mx, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (f fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if addrs, ok := f.hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestBasicValidator(t *testing.T) {
+	resolver := fakeResolver{
+		mx: map[string][]*net.MX{
+			"acme.io":        {{Host: "mx1.acme.io.", Pref: 10}},
+			"gmail.com":      {{Host: "gmail-smtp-in.l.google.com.", Pref: 5}},
+			"mailinator.com": {{Host: "mail.mailinator.com.", Pref: 10}},
+			"nomail.io":      {{Host: ".", Pref: 0}},
+		},
+		hosts: map[string][]string{
+			"a-only.io": {"192.0.2.10"},
+		},
+	}
+	v := NewBasicValidator(BasicConfig{Resolver: resolver})
+
+	tests := []struct {
+		email   string
+		want    *ValidationResult
+		wantErr bool
+	}{
+		{
+			email: " Owner@Acme.io ",
+			want:  &ValidationResult{Email: "owner@acme.io", Status: "valid", Score: 80, Deliverable: true},
+		},
+		{
+			email: "owner@gmail.com",
+			want:  &ValidationResult{Email: "owner@gmail.com", Status: "valid", Score: 80, Deliverable: true, FreeEmail: true},
+		},
+		{
+			email: "info@acme.io",
+			want:  &ValidationResult{Email: "info@acme.io", Status: "valid", Score: 50, Deliverable: true, RoleAccount: true, Reason: "role account"},
+		},
+		{
+			email: "owner@mailinator.com",
+			want:  &ValidationResult{Email: "owner@mailinator.com", Status: "risky", Score: 20, Deliverable: true, Disposable: true, Reason: "disposable email"},
+		},
+		{
+			email: "owner@a-only.io",
+			want:  &ValidationResult{Email: "owner@a-only.io", Status: "valid", Score: 80, Deliverable: true},
+		},
+		{
+			email: "owner@nomail.io",
+			want:  &ValidationResult{Email: "owner@nomail.io", Status: "invalid", Reason: "domain does not accept mail"},
+		},
+		{
+			email: "owner@missing.io",
+			want:  &ValidationResult{Email: "owner@missing.io", Status: "invalid", Reason: "domain does not accept mail"},
+		},
+		{
+			email: "owner@@acme.io",
+			want:  &ValidationResult{Email: "owner@@acme.io", Status: "invalid", Reason: "invalid syntax"},
+		},
+		{
+			email: "owner@localhost",
+			want:  &ValidationResult{Email: "owner@localhost", Status: "invalid", Reason: "invalid syntax"},
+		},
+		{
+			email:   "owner@broken.io",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			got, err := v.Validate(context.Background(), tt.email)
+			if tt.wantErr {
+				var dnsErr *net.DNSError
+				require.True(t, errors.As(err, &dnsErr))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		wantType Validator
+		wantErr  bool
+	}{
+		{name: "nothing configured", opts: Options{}},
+		{name: "mordibouncer key alone", opts: Options{Mordibouncer: MordibouncerConfig{APIKey: "k"}}, wantType: &MordibouncerValidator{}},
+		{name: "none wins over a key", opts: Options{Provider: "none", Mordibouncer: MordibouncerConfig{APIKey: "k"}}},
+		{name: "zerobounce", opts: Options{Provider: "ZeroBounce", ZeroBounce: ZeroBounceConfig{APIKey: "k"}}, wantType: &ZeroBounceValidator{}},
+		{name: "zerobounce without key", opts: Options{Provider: "zerobounce"}, wantErr: true},
+		{name: "basic", opts: Options{Provider: "basic"}, wantType: &BasicValidator{}},
+		{name: "unknown provider", opts: Options{Provider: "neverbounce"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.opts)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantType == nil {
+				assert.Nil(t, got)
+				return
+			}
+			assert.IsType(t, tt.wantType, got)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package emailvalidator
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Store persists validation results so an email is not checked again
+// every time it shows up in a new listing
+type Store interface {
+	// Lookup returns the result stored for email if it was validated less
+	// than maxAge ago, or nil
+	Lookup(ctx context.Context, email string, maxAge time.Duration) (*ValidationResult, error)
+
+	// Save stores the result of a validation
+	Save(ctx context.Context, result *ValidationResult) error
+}
+
+// Normalize returns the form emails are stored and looked up in
+func Normalize(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// CachedValidator checks a Store before calling the wrapped validator and
+// stores what it returns. Failed validations are not stored.
+type CachedValidator struct {
+	validator Validator
+	store     Store
+	ttl       time.Duration
+}
+
+// NewCachedValidator creates a validator that reuses results younger than ttl
+func NewCachedValidator(validator Validator, store Store, ttl time.Duration) *CachedValidator {
+	return &CachedValidator{
+		validator: validator,
+		store:     store,
+		ttl:       ttl,
+	}
+}
+
+// Validate returns the stored result for email or validates it. A result
+// that could not be stored is still returned.
+func (v *CachedValidator) Validate(ctx context.Context, email string) (*ValidationResult, error) {
+	res, _, err := v.ValidateCached(ctx, email)
+	if res != nil {
+		return res, nil
+	}
+	return nil, err
+}
+
+// ValidateCached is Validate also reporting whether the result came from
+// the store. A store that cannot be read is skipped; one that cannot be
+// written to makes it return the result together with the error.
+func (v *CachedValidator) ValidateCached(ctx context.Context, email string) (*ValidationResult, bool, error) {
+	email = Normalize(email)
+
+	if res, err := v.store.Lookup(ctx, email, v.ttl); err == nil && res != nil {
+		return res, true, nil
+	}
+
+	res, err := v.validator.Validate(ctx, email)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// The API may report a normalized address; store under the one asked for
+	res.Email = email
+	if err := v.store.Save(ctx, res); err != nil {
+		return res, false, err
+	}
+
+	return res, false, nil
+}
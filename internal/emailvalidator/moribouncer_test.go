@@ -0,0 +1,80 @@
+package emailvalidator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMordibouncerValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    int
+		body    string
+		want    *ValidationResult
+		wantErr bool
+	}{
+		{
+			name: "safe address",
+			code: http.StatusOK,
+			body: `{"input": "owner@acme.io", "is_reachable": "safe",
+				"mx": {"accepts_mail": true}, "smtp": {"can_connect_smtp": true, "is_deliverable": true},
+				"syntax": {"is_valid_syntax": true, "domain": "acme.io", "normalized_email": "owner@acme.io"}}`,
+			want: &ValidationResult{Email: "owner@acme.io", Status: "valid", Score: 100, Deliverable: true},
+		},
+		{
+			name: "role account on a catch-all domain",
+			code: http.StatusOK,
+			body: `{"input": "info@acme.io", "is_reachable": "risky", "misc": {"is_role_account": true},
+				"mx": {"accepts_mail": true}, "smtp": {"can_connect_smtp": true, "is_deliverable": true, "is_catch_all": true},
+				"syntax": {"is_valid_syntax": true, "domain": "acme.io"}}`,
+			want: &ValidationResult{Email: "info@acme.io", Status: "risky", Score: 50, RoleAccount: true, CatchAll: true, Reason: "role account"},
+		},
+		{
+			name: "domain without mail servers",
+			code: http.StatusOK,
+			body: `{"input": "owner@acme.io", "is_reachable": "invalid", "mx": {"accepts_mail": false},
+				"syntax": {"is_valid_syntax": true, "domain": "acme.io"}}`,
+			want: &ValidationResult{Email: "owner@acme.io", Status: "invalid", Reason: "domain does not accept mail"},
+		},
+		{
+			name:    "rejected key",
+			code:    http.StatusUnauthorized,
+			body:    `{"error": "invalid secret"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/v0/check_email", r.URL.Path)
+				assert.Equal(t, "secret", r.Header.Get("x-mordibouncer-secret"))
+
+				var req mordibouncerRequest
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.NotEmpty(t, req.ToEmail)
+
+				w.WriteHeader(tt.code)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			v := NewMordibouncerValidator(MordibouncerConfig{APIURL: srv.URL + "/", APIKey: "secret"})
+
+			got, err := v.Validate(context.Background(), "owner@acme.io")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
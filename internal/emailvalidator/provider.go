@@ -0,0 +1,66 @@
+package emailvalidator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Providers selectable with -email-validator-provider
+const (
+	ProviderMordibouncer = "mordibouncer"
+	ProviderZeroBounce   = "zerobounce"
+	ProviderBasic        = "basic" // Syntax, MX and disposable domains, no API
+	ProviderNone         = "none"
+)
+
+// Options selects an email validator and configures every provider
+type Options struct {
+	// Provider is one of the Provider constants. Empty picks Mordibouncer
+	// when its key is set, keeping setups from before providers existed.
+	Provider string
+
+	Mordibouncer MordibouncerConfig
+	ZeroBounce   ZeroBounceConfig
+	Basic        BasicConfig
+}
+
+// ResolvedProvider returns the provider New creates, or ProviderNone
+func (o Options) ResolvedProvider() string {
+	provider := strings.ToLower(strings.TrimSpace(o.Provider))
+	if provider == "" {
+		if o.Mordibouncer.APIKey != "" {
+			return ProviderMordibouncer
+		}
+		return ProviderNone
+	}
+	return provider
+}
+
+// Enabled reports whether emails are validated at all
+func (o Options) Enabled() bool {
+	return o.ResolvedProvider() != ProviderNone
+}
+
+// New creates the validator selected by opts, or nil when validation is
+// disabled
+func New(opts Options) (Validator, error) {
+	switch provider := opts.ResolvedProvider(); provider {
+	case ProviderNone:
+		return nil, nil
+	case ProviderMordibouncer:
+		if opts.Mordibouncer.APIKey == "" {
+			return nil, errors.New("mordibouncer email validator needs -mordibouncer-key")
+		}
+		return NewMordibouncerValidator(opts.Mordibouncer), nil
+	case ProviderZeroBounce:
+		if opts.ZeroBounce.APIKey == "" {
+			return nil, errors.New("zerobounce email validator needs -zerobounce-key")
+		}
+		return NewZeroBounceValidator(opts.ZeroBounce), nil
+	case ProviderBasic:
+		return NewBasicValidator(opts.Basic), nil
+	default:
+		return nil, fmt.Errorf("unknown email validator provider %q (mordibouncer, zerobounce, basic, none)", provider)
+	}
+}
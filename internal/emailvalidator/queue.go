@@ -0,0 +1,118 @@
+package emailvalidator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultQueueKey is the Redis list emails wait in for validation
+const DefaultQueueKey = "gmaps:email_validation:queue"
+
+// Queue hands emails from the scraping side to validator workers
+type Queue interface {
+	// Push appends emails to the queue
+	Push(ctx context.Context, emails ...string) error
+
+	// Pop waits up to timeout for an email; "" when none arrived
+	Pop(ctx context.Context, timeout time.Duration) (string, error)
+
+	// Len returns the number of emails waiting
+	Len(ctx context.Context) (int64, error)
+}
+
+// RedisQueue is a Queue on a Redis list, shared by every manager replica
+type RedisQueue struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisQueue creates a queue on the list key
+func NewRedisQueue(client *redis.Client, key string) *RedisQueue {
+	if key == "" {
+		key = DefaultQueueKey
+	}
+	return &RedisQueue{client: client, key: key}
+}
+
+// Push appends emails to the list
+func (q *RedisQueue) Push(ctx context.Context, emails ...string) error {
+	if len(emails) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, len(emails))
+	for i, email := range emails {
+		values[i] = email
+	}
+
+	if err := q.client.LPush(ctx, q.key, values...).Err(); err != nil {
+		return fmt.Errorf("push emails: %w", err)
+	}
+	return nil
+}
+
+// Pop takes the oldest email off the list
+func (q *RedisQueue) Pop(ctx context.Context, timeout time.Duration) (string, error) {
+	res, err := q.client.BRPop(ctx, timeout, q.key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("pop email: %w", err)
+	}
+
+	// BRPop returns the key followed by the value
+	return res[1], nil
+}
+
+// Len returns the length of the list
+func (q *RedisQueue) Len(ctx context.Context) (int64, error) {
+	return q.client.LLen(ctx, q.key).Result()
+}
+
+// MemoryQueue is a Queue for a single manager without Redis. Emails still
+// in it are lost on restart and queued again once their claim expires.
+type MemoryQueue struct {
+	emails chan string
+}
+
+// NewMemoryQueue creates a queue holding up to size emails
+func NewMemoryQueue(size int) *MemoryQueue {
+	return &MemoryQueue{emails: make(chan string, size)}
+}
+
+// Push appends emails, waiting while the queue is full
+func (q *MemoryQueue) Push(ctx context.Context, emails ...string) error {
+	for _, email := range emails {
+		select {
+		case q.emails <- email:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Pop takes the oldest email off the queue
+func (q *MemoryQueue) Pop(ctx context.Context, timeout time.Duration) (string, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case email := <-q.emails:
+		return email, nil
+	case <-timer.C:
+		return "", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Len returns the number of emails waiting
+func (q *MemoryQueue) Len(_ context.Context) (int64, error) {
+	return int64(len(q.emails)), nil
+}
@@ -2,7 +2,10 @@ package emailvalidator
 
 import "context"
 
-// Validator interface for email validation
+// Validator checks whether an email address can receive mail. Validate
+// returns a result for every address it could check, undeliverable ones
+// included, and an error only when the check itself failed (API down,
+// credits exhausted, DNS timeout).
 type Validator interface {
 	Validate(ctx context.Context, email string) (*ValidationResult, error)
 }
@@ -10,7 +13,7 @@ type Validator interface {
 // ValidationResult from email validation API
 type ValidationResult struct {
 	Email       string  `json:"email"`
-	Status      string  `json:"status"`       // valid, invalid, unknown, catch_all
+	Status      string  `json:"status"`       // valid, invalid, risky, unknown, catch_all
 	Score       float64 `json:"score"`        // 0-100
 	Deliverable bool    `json:"deliverable"`
 	Disposable  bool    `json:"disposable"`
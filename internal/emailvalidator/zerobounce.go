@@ -0,0 +1,169 @@
+package emailvalidator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ZeroBounceValidator validates emails using the ZeroBounce v2 API
+type ZeroBounceValidator struct {
+	apiURL     string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// ZeroBounceConfig for ZeroBounce validator
+type ZeroBounceConfig struct {
+	APIURL  string // e.g., "https://api.zerobounce.net"
+	APIKey  string
+	Timeout time.Duration
+}
+
+// NewZeroBounceValidator creates a new ZeroBounce validator
+func NewZeroBounceValidator(cfg ZeroBounceConfig) *ZeroBounceValidator {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		// ZeroBounce waits for slow mail servers up to a minute
+		timeout = 60 * time.Second
+	}
+
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		apiURL = "https://api.zerobounce.net"
+	}
+	apiURL = strings.TrimSuffix(apiURL, "/")
+
+	return &ZeroBounceValidator{
+		apiURL: apiURL,
+		apiKey: cfg.APIKey,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// zeroBounceResponse is the response format of GET /v2/validate
+type zeroBounceResponse struct {
+	Address    string `json:"address"`
+	Status     string `json:"status"`     // valid, invalid, catch-all, unknown, spamtrap, abuse, do_not_mail
+	SubStatus  string `json:"sub_status"` // e.g. role_based, disposable, mailbox_not_found
+	FreeEmail  bool   `json:"free_email"`
+	Domain     string `json:"domain"`
+	MXFound    string `json:"mx_found"` // "true" or "false"
+	DidYouMean string `json:"did_you_mean"`
+
+	// Set instead of the fields above when the request was rejected
+	Error string `json:"error"`
+}
+
+// Validate validates a single email using the ZeroBounce API
+func (v *ZeroBounceValidator) Validate(ctx context.Context, email string) (*ValidationResult, error) {
+	query := url.Values{}
+	query.Set("api_key", v.apiKey)
+	query.Set("email", email)
+	query.Set("ip_address", "")
+
+	reqURL := fmt.Sprintf("%s/v2/validate?%s", v.apiURL, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		// The URL carries the API key; keep it out of the error
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return nil, fmt.Errorf("failed to call ZeroBounce API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if len(errBody) > 0 {
+			return nil, fmt.Errorf("ZeroBounce API returned status %d: %s", resp.StatusCode, string(errBody))
+		}
+		return nil, fmt.Errorf("ZeroBounce API returned status %d", resp.StatusCode)
+	}
+
+	var apiResp zeroBounceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Invalid keys and exhausted credits are answered with 200
+	if apiResp.Error != "" {
+		return nil, fmt.Errorf("ZeroBounce API error: %s", apiResp.Error)
+	}
+
+	return v.mapResponse(email, &apiResp), nil
+}
+
+// mapResponse converts a ZeroBounce response to ValidationResult
+// Status mapping:
+// - valid=valid (100), catch-all=catch_all (60), unknown=unknown (30)
+// - invalid, spamtrap, abuse=invalid (0)
+// - do_not_mail=risky: role_based (50), disposable (20), otherwise invalid (0)
+func (v *ZeroBounceValidator) mapResponse(inputEmail string, resp *zeroBounceResponse) *ValidationResult {
+	res := &ValidationResult{
+		Email:     resp.Address,
+		FreeEmail: resp.FreeEmail || freeEmailDomains[strings.ToLower(resp.Domain)],
+		Reason:    strings.ReplaceAll(resp.SubStatus, "_", " "),
+	}
+	if res.Email == "" {
+		res.Email = inputEmail
+	}
+
+	switch resp.Status {
+	case "valid":
+		res.Status = "valid"
+		res.Score = 100
+		res.Deliverable = true
+	case "catch-all":
+		res.Status = "catch_all"
+		res.Score = 60
+		res.CatchAll = true
+	case "invalid":
+		res.Status = "invalid"
+	case "spamtrap", "abuse":
+		res.Status = "invalid"
+		if res.Reason == "" {
+			res.Reason = resp.Status
+		}
+	case "do_not_mail":
+		switch {
+		case strings.Contains(resp.SubStatus, "role_based"):
+			res.Status = "risky"
+			res.Score = 50
+			res.RoleAccount = true
+			res.CatchAll = resp.SubStatus == "role_based_catch_all"
+		case resp.SubStatus == "disposable":
+			res.Status = "risky"
+			res.Score = 20
+			res.Disposable = true
+		default:
+			// toxic, global_suppression, possible_trap, mx_forward
+			res.Status = "invalid"
+		}
+	default:
+		res.Status = "unknown"
+		res.Score = 30
+	}
+
+	if res.Reason == "" && resp.MXFound == "false" {
+		res.Reason = "domain does not accept mail"
+	}
+
+	return res
+}
@@ -0,0 +1,115 @@
+package emailvalidator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZeroBounceValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    int
+		body    string
+		want    *ValidationResult
+		wantErr string
+	}{
+		{
+			name: "valid address",
+			code: http.StatusOK,
+			body: `{"address": "owner@acme.io", "status": "valid", "sub_status": "", "free_email": false,
+				"domain": "acme.io", "mx_found": "true"}`,
+			want: &ValidationResult{Email: "owner@acme.io", Status: "valid", Score: 100, Deliverable: true},
+		},
+		{
+			name: "catch-all domain",
+			code: http.StatusOK,
+			body: `{"address": "owner@acme.io", "status": "catch-all", "sub_status": "", "mx_found": "true"}`,
+			want: &ValidationResult{Email: "owner@acme.io", Status: "catch_all", Score: 60, CatchAll: true},
+		},
+		{
+			name: "role account",
+			code: http.StatusOK,
+			body: `{"address": "owner@acme.io", "status": "do_not_mail", "sub_status": "role_based", "mx_found": "true"}`,
+			want: &ValidationResult{Email: "owner@acme.io", Status: "risky", Score: 50, RoleAccount: true, Reason: "role based"},
+		},
+		{
+			name: "disposable address",
+			code: http.StatusOK,
+			body: `{"address": "owner@acme.io", "status": "do_not_mail", "sub_status": "disposable", "mx_found": "true"}`,
+			want: &ValidationResult{Email: "owner@acme.io", Status: "risky", Score: 20, Disposable: true, Reason: "disposable"},
+		},
+		{
+			name: "unknown mailbox on a free provider",
+			code: http.StatusOK,
+			body: `{"address": "owner@gmail.com", "status": "invalid", "sub_status": "mailbox_not_found",
+				"free_email": true, "domain": "gmail.com", "mx_found": "true"}`,
+			want: &ValidationResult{Email: "owner@gmail.com", Status: "invalid", FreeEmail: true, Reason: "mailbox not found"},
+		},
+		{
+			name: "spamtrap",
+			code: http.StatusOK,
+			body: `{"address": "owner@acme.io", "status": "spamtrap", "sub_status": ""}`,
+			want: &ValidationResult{Email: "owner@acme.io", Status: "invalid", Reason: "spamtrap"},
+		},
+		{
+			name: "unknown",
+			code: http.StatusOK,
+			body: `{"address": "owner@acme.io", "status": "unknown", "sub_status": "timeout_exceeded"}`,
+			want: &ValidationResult{Email: "owner@acme.io", Status: "unknown", Score: 30, Reason: "timeout exceeded"},
+		},
+		{
+			name:    "invalid key answered with 200",
+			code:    http.StatusOK,
+			body:    `{"error": "Invalid API Key or your account ran out of credits"}`,
+			wantErr: "Invalid API Key",
+		},
+		{
+			name:    "server error",
+			code:    http.StatusInternalServerError,
+			body:    `oops`,
+			wantErr: "status 500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodGet, r.Method)
+				assert.Equal(t, "/v2/validate", r.URL.Path)
+				assert.Equal(t, "secret", r.URL.Query().Get("api_key"))
+				assert.Equal(t, "owner@acme.io", r.URL.Query().Get("email"))
+				assert.True(t, r.URL.Query().Has("ip_address"))
+
+				w.WriteHeader(tt.code)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			v := NewZeroBounceValidator(ZeroBounceConfig{APIURL: srv.URL, APIKey: "secret"})
+
+			got, err := v.Validate(context.Background(), "owner@acme.io")
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestZeroBounceValidatorKeepsKeyOutOfErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	srv.Close()
+
+	v := NewZeroBounceValidator(ZeroBounceConfig{APIURL: srv.URL, APIKey: "secret"})
+
+	_, err := v.Validate(context.Background(), "owner@acme.io")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+}
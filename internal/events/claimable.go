@@ -0,0 +1,85 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// ClaimablePublisher announces that a pending job may be claimed, so
+// workers long-polling the claim endpoint try again
+type ClaimablePublisher interface {
+	PublishClaimable(ctx context.Context)
+}
+
+// ClaimableSubscriber delivers the announcements of ClaimablePublisher.
+// The channel holds at most one, as a waiter only needs to know that it
+// should try claiming again. The returned function unsubscribes and must
+// be called once the caller is done.
+type ClaimableSubscriber interface {
+	SubscribeClaimable() (<-chan struct{}, func())
+}
+
+// claimSubs are the claimable subscriptions of a MemoryBroker
+type claimSubs struct {
+	mu     sync.Mutex
+	subs   map[chan struct{}]struct{}
+	closed bool
+}
+
+func (c *claimSubs) publish() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for ch := range c.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (c *claimSubs) subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if c.subs == nil {
+		c.subs = make(map[chan struct{}]struct{})
+	}
+
+	c.subs[ch] = struct{}{}
+
+	var once sync.Once
+
+	return ch, func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			if _, ok := c.subs[ch]; !ok {
+				return
+			}
+
+			delete(c.subs, ch)
+			close(ch)
+		})
+	}
+}
+
+func (c *claimSubs) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+
+	for ch := range c.subs {
+		close(ch)
+		delete(c.subs, ch)
+	}
+}
@@ -0,0 +1,197 @@
+// Package events fans job progress and status changes, and worker
+// heartbeats, out to live subscribers such as the SSE endpoints of the
+// dashboard
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// Event types
+const (
+	TypeProgress = "progress"
+	TypeStatus   = "status"
+	TypeComplete = "complete"
+	TypeRequeued = "requeued" // The job's queue message was published again
+)
+
+// subscriberBuffer is how many events a slow subscriber may lag behind
+// before further events are dropped for it
+const subscriberBuffer = 32
+
+// Event is a change to a job
+type Event struct {
+	Type     string              `json:"type"`
+	JobID    uuid.UUID           `json:"job_id"`
+	Status   domain.JobStatus    `json:"status,omitempty"`
+	Progress *domain.JobProgress `json:"progress,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Time     time.Time           `json:"time"`
+}
+
+// Publisher publishes job events
+type Publisher interface {
+	Publish(ctx context.Context, ev Event)
+}
+
+// Subscriber delivers the events of one job. The returned function
+// unsubscribes and must be called once the caller is done.
+type Subscriber interface {
+	Subscribe(jobID uuid.UUID) (<-chan Event, func())
+}
+
+// Broker is both ends of the pub/sub, for jobs and workers
+type Broker interface {
+	Publisher
+	Subscriber
+	WorkerPublisher
+	WorkerSubscriber
+	ClaimablePublisher
+	ClaimableSubscriber
+	Close() error
+}
+
+// StatusEvent builds the event for a job entering status. Terminal
+// statuses produce a complete event.
+func StatusEvent(jobID uuid.UUID, status domain.JobStatus, errMsg string) Event {
+	typ := TypeStatus
+	if status.IsTerminal() {
+		typ = TypeComplete
+	}
+
+	return Event{
+		Type:   typ,
+		JobID:  jobID,
+		Status: status,
+		Error:  errMsg,
+		Time:   time.Now().UTC(),
+	}
+}
+
+// MemoryBroker is an in-process Broker. Publishing never blocks: events
+// for a subscriber whose buffer is full are dropped.
+type MemoryBroker struct {
+	mu     sync.RWMutex
+	subs   map[uuid.UUID]map[chan Event]struct{}
+	closed bool
+
+	fleet     fleetSubs
+	claimable claimSubs
+}
+
+// NewMemoryBroker creates a new MemoryBroker
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		subs: make(map[uuid.UUID]map[chan Event]struct{}),
+	}
+}
+
+func (b *MemoryBroker) Publish(_ context.Context, ev Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subs[ev.JobID] {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+func (b *MemoryBroker) Subscribe(jobID uuid.UUID) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if b.subs[jobID] == nil {
+		b.subs[jobID] = make(map[chan Event]struct{})
+	}
+
+	b.subs[jobID][ch] = struct{}{}
+
+	var once sync.Once
+
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			if _, ok := b.subs[jobID][ch]; !ok {
+				return
+			}
+
+			delete(b.subs[jobID], ch)
+			if len(b.subs[jobID]) == 0 {
+				delete(b.subs, jobID)
+			}
+
+			close(ch)
+		})
+	}
+}
+
+func (b *MemoryBroker) PublishWorker(_ context.Context, ev WorkerEvent) {
+	b.fleet.publish(ev)
+}
+
+func (b *MemoryBroker) SubscribeWorkers() (<-chan WorkerEvent, func()) {
+	return b.fleet.subscribe()
+}
+
+func (b *MemoryBroker) PublishClaimable(_ context.Context) {
+	b.claimable.publish()
+}
+
+func (b *MemoryBroker) SubscribeClaimable() (<-chan struct{}, func()) {
+	return b.claimable.subscribe()
+}
+
+// SubscriberCount returns the number of open subscriptions
+func (b *MemoryBroker) SubscriberCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	n := 0
+	for _, subs := range b.subs {
+		n += len(subs)
+	}
+
+	return n
+}
+
+// Close closes every subscription
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+
+	b.closed = true
+	b.fleet.close()
+	b.claimable.close()
+
+	for jobID, subs := range b.subs {
+		for ch := range subs {
+			close(ch)
+		}
+		delete(b.subs, jobID)
+	}
+
+	return nil
+}
+
+var _ Broker = (*MemoryBroker)(nil)
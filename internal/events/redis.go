@@ -0,0 +1,203 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisChannel carries the events of every job; one channel keeps the
+// number of Redis subscriptions per manager constant
+const redisChannel = "events:jobs"
+
+// redisWorkersChannel carries worker heartbeats
+const redisWorkersChannel = "events:workers"
+
+// redisClaimableChannel carries the announcements that a pending job may
+// be claimed, so workers long-polling any replica wake up
+const redisClaimableChannel = "events:claimable"
+
+// RedisConfig holds the Redis connection for RedisBroker
+type RedisConfig struct {
+	RedisURL  string
+	RedisAddr string
+	Password  string
+	DB        int
+
+	// WorkerEvents also delivers worker events, and claimable job
+	// announcements, to local subscribers. Only managers stream them;
+	// workers would receive the whole fleet's heartbeats for nothing.
+	WorkerEvents bool
+}
+
+// RedisBroker relays events through Redis pub/sub so that a client
+// connected to one manager replica sees updates handled by another.
+// Events are published to Redis only and delivered to local subscribers
+// when they come back from the channel.
+type RedisBroker struct {
+	client *redis.Client
+	pubsub *redis.PubSub
+	local  *MemoryBroker
+	done   chan struct{}
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewRedisBroker connects to Redis and starts relaying events
+func NewRedisBroker(cfg RedisConfig) (*RedisBroker, error) {
+	var opts *redis.Options
+
+	switch {
+	case cfg.RedisURL != "":
+		o, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse redis URL: %w", err)
+		}
+		opts = o
+	case cfg.RedisAddr != "":
+		opts = &redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+		}
+	default:
+		return nil, fmt.Errorf("redis URL or address is required")
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("redis connection failed: %w", err)
+	}
+
+	channels := []string{redisChannel}
+	if cfg.WorkerEvents {
+		channels = append(channels, redisWorkersChannel, redisClaimableChannel)
+	}
+
+	pubsub := client.Subscribe(context.Background(), channels...)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		_ = client.Close()
+		return nil, fmt.Errorf("redis subscribe failed: %w", err)
+	}
+
+	b := &RedisBroker{
+		client: client,
+		pubsub: pubsub,
+		local:  NewMemoryBroker(),
+		done:   make(chan struct{}),
+	}
+
+	go b.relay()
+
+	return b, nil
+}
+
+func (b *RedisBroker) relay() {
+	defer close(b.done)
+
+	for msg := range b.pubsub.Channel() {
+		if msg.Channel == redisClaimableChannel {
+			b.local.PublishClaimable(context.Background())
+			continue
+		}
+
+		if msg.Channel == redisWorkersChannel {
+			var ev WorkerEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+				log.Printf("[Events] Ignoring malformed worker event: %v", err)
+				continue
+			}
+
+			b.local.PublishWorker(context.Background(), ev)
+			continue
+		}
+
+		var ev Event
+		if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+			log.Printf("[Events] Ignoring malformed event: %v", err)
+			continue
+		}
+
+		b.local.Publish(context.Background(), ev)
+	}
+}
+
+func (b *RedisBroker) Publish(ctx context.Context, ev Event) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("[Events] Failed to encode event for job %s: %v", ev.JobID, err)
+		return
+	}
+
+	if err := b.client.Publish(ctx, redisChannel, payload).Err(); err != nil {
+		log.Printf("[Events] Failed to publish event for job %s: %v", ev.JobID, err)
+	}
+}
+
+func (b *RedisBroker) Subscribe(jobID uuid.UUID) (<-chan Event, func()) {
+	return b.local.Subscribe(jobID)
+}
+
+func (b *RedisBroker) PublishWorker(ctx context.Context, ev WorkerEvent) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("[Events] Failed to encode event for worker %s: %v", ev.WorkerID, err)
+		return
+	}
+
+	if err := b.client.Publish(ctx, redisWorkersChannel, payload).Err(); err != nil {
+		log.Printf("[Events] Failed to publish event for worker %s: %v", ev.WorkerID, err)
+	}
+}
+
+// SubscribeWorkers only delivers events when the broker was created with
+// RedisConfig.WorkerEvents
+func (b *RedisBroker) SubscribeWorkers() (<-chan WorkerEvent, func()) {
+	return b.local.SubscribeWorkers()
+}
+
+func (b *RedisBroker) PublishClaimable(ctx context.Context) {
+	if err := b.client.Publish(ctx, redisClaimableChannel, "").Err(); err != nil {
+		log.Printf("[Events] Failed to publish claimable job: %v", err)
+	}
+}
+
+// SubscribeClaimable only delivers announcements when the broker was
+// created with RedisConfig.WorkerEvents
+func (b *RedisBroker) SubscribeClaimable() (<-chan struct{}, func()) {
+	return b.local.SubscribeClaimable()
+}
+
+// Close stops relaying and closes every subscription. It is safe to call
+// more than once.
+func (b *RedisBroker) Close() error {
+	b.closeOnce.Do(func() {
+		err := b.pubsub.Close()
+		<-b.done
+
+		_ = b.local.Close()
+
+		if cerr := b.client.Close(); err == nil {
+			err = cerr
+		}
+
+		b.closeErr = err
+	})
+
+	return b.closeErr
+}
+
+var _ Broker = (*RedisBroker)(nil)
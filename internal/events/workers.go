@@ -0,0 +1,165 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// Worker event types
+const (
+	TypeWorkerUpdate  = "worker_update"
+	TypeWorkerOffline = "worker_offline"
+)
+
+// fleetBuffer is how many worker events a slow subscriber may lag behind.
+// Every worker beats once per domain.HeartbeatInterval, so this covers a
+// fleet of several hundred workers.
+const fleetBuffer = 1024
+
+// WorkerEvent is a heartbeat of a worker, or the heartbeat monitor giving
+// up on it. State is nil for offline events.
+type WorkerEvent struct {
+	Type     string                  `json:"type"`
+	WorkerID string                  `json:"worker_id"`
+	State    *domain.WorkerHeartbeat `json:"state,omitempty"`
+}
+
+// WorkerPublisher publishes worker events
+type WorkerPublisher interface {
+	PublishWorker(ctx context.Context, ev WorkerEvent)
+}
+
+// WorkerSubscriber delivers the events of every worker. The returned
+// function unsubscribes and must be called once the caller is done.
+type WorkerSubscriber interface {
+	SubscribeWorkers() (<-chan WorkerEvent, func())
+}
+
+// fleetSubs are the worker event subscriptions of a MemoryBroker
+type fleetSubs struct {
+	mu     sync.RWMutex
+	subs   map[chan WorkerEvent]struct{}
+	closed bool
+}
+
+func (f *fleetSubs) publish(ev WorkerEvent) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for ch := range f.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+func (f *fleetSubs) subscribe() (<-chan WorkerEvent, func()) {
+	ch := make(chan WorkerEvent, fleetBuffer)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if f.subs == nil {
+		f.subs = make(map[chan WorkerEvent]struct{})
+	}
+
+	f.subs[ch] = struct{}{}
+
+	var once sync.Once
+
+	return ch, func() {
+		once.Do(func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			if _, ok := f.subs[ch]; !ok {
+				return
+			}
+
+			delete(f.subs, ch)
+			close(ch)
+		})
+	}
+}
+
+func (f *fleetSubs) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+
+	for ch := range f.subs {
+		close(ch)
+		delete(f.subs, ch)
+	}
+}
+
+// WorkerDeltas remembers the state last sent for each worker, so a stream
+// only carries the fields that changed. It is not safe for concurrent use.
+type WorkerDeltas struct {
+	last map[string]map[string]json.RawMessage
+}
+
+// NewWorkerDeltas creates a new WorkerDeltas
+func NewWorkerDeltas() *WorkerDeltas {
+	return &WorkerDeltas{last: make(map[string]map[string]json.RawMessage)}
+}
+
+// Delta returns the fields of an update that differ from the last state of
+// the worker, with worker_id always set and fields the worker no longer
+// reports set to null. The first update of a worker has every field. It
+// returns nil when nothing changed. Offline events forget the worker and
+// only carry its ID.
+func (d *WorkerDeltas) Delta(ev WorkerEvent) (map[string]json.RawMessage, error) {
+	id, err := json.Marshal(ev.WorkerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ev.Type == TypeWorkerOffline || ev.State == nil {
+		delete(d.last, ev.WorkerID)
+		return map[string]json.RawMessage{"worker_id": id}, nil
+	}
+
+	data, err := json.Marshal(ev.State)
+	if err != nil {
+		return nil, err
+	}
+
+	var cur map[string]json.RawMessage
+	if err := json.Unmarshal(data, &cur); err != nil {
+		return nil, err
+	}
+
+	prev := d.last[ev.WorkerID]
+	d.last[ev.WorkerID] = cur
+
+	delta := make(map[string]json.RawMessage)
+	for k, v := range cur {
+		if old, ok := prev[k]; !ok || string(old) != string(v) {
+			delta[k] = v
+		}
+	}
+	for k := range prev {
+		if _, ok := cur[k]; !ok {
+			delta[k] = json.RawMessage("null")
+		}
+	}
+
+	if len(delta) == 0 {
+		return nil, nil
+	}
+
+	delta["worker_id"] = id
+
+	return delta, nil
+}
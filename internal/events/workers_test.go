@@ -0,0 +1,108 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+func update(state domain.WorkerHeartbeat) WorkerEvent {
+	return WorkerEvent{Type: TypeWorkerUpdate, WorkerID: state.WorkerID, State: &state}
+}
+
+func fields(t *testing.T, delta map[string]json.RawMessage) map[string]any {
+	t.Helper()
+
+	data, err := json.Marshal(delta)
+	require.NoError(t, err)
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(data, &m))
+
+	return m
+}
+
+func TestWorkerDeltas(t *testing.T) {
+	d := NewWorkerDeltas()
+
+	busy := domain.WorkerHeartbeat{
+		WorkerID:         "w1",
+		Hostname:         "host-a",
+		Status:           domain.WorkerStatusBusy,
+		CurrentJobName:   "Cafes in Austin",
+		SeedJobsTotal:    4,
+		JobPlacesScraped: 10,
+		MemoryBytes:      1 << 20,
+	}
+
+	delta, err := d.Delta(update(busy))
+	require.NoError(t, err)
+	assert.Equal(t, "Cafes in Austin", fields(t, delta)["current_job_name"], "first update has every field")
+	assert.Contains(t, delta, "memory_bytes")
+
+	busy.JobPlacesScraped = 25
+	busy.SeedJobsCompleted = 1
+	delta, err = d.Delta(update(busy))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"worker_id":           "w1",
+		"job_places_scraped":  float64(25),
+		"seed_jobs_completed": float64(1),
+	}, fields(t, delta))
+
+	delta, err = d.Delta(update(busy))
+	require.NoError(t, err)
+	assert.Nil(t, delta, "unchanged heartbeat")
+
+	idle := busy
+	idle.Status = domain.WorkerStatusIdle
+	idle.CurrentJobName = ""
+	delta, err = d.Delta(update(idle))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"worker_id":        "w1",
+		"status":           "idle",
+		"current_job_name": nil,
+	}, fields(t, delta))
+}
+
+func TestWorkerDeltasOffline(t *testing.T) {
+	d := NewWorkerDeltas()
+
+	state := domain.WorkerHeartbeat{WorkerID: "w1", Status: domain.WorkerStatusIdle, MemoryBytes: 512}
+	_, err := d.Delta(update(state))
+	require.NoError(t, err)
+
+	delta, err := d.Delta(WorkerEvent{Type: TypeWorkerOffline, WorkerID: "w1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"worker_id": "w1"}, fields(t, delta))
+
+	// A worker coming back is sent in full
+	delta, err = d.Delta(update(state))
+	require.NoError(t, err)
+	assert.Contains(t, delta, "memory_bytes")
+	assert.Contains(t, delta, "status")
+}
+
+func TestMemoryBrokerWorkers(t *testing.T) {
+	b := NewMemoryBroker()
+
+	ch, unsubscribe := b.SubscribeWorkers()
+	b.PublishWorker(t.Context(), WorkerEvent{Type: TypeWorkerOffline, WorkerID: "w1"})
+
+	ev := <-ch
+	assert.Equal(t, "w1", ev.WorkerID)
+
+	unsubscribe()
+	_, ok := <-ch
+	assert.False(t, ok)
+
+	ch, _ = b.SubscribeWorkers()
+	require.NoError(t, b.Close())
+	_, ok = <-ch
+	assert.False(t, ok)
+}
@@ -0,0 +1,438 @@
+// Package exportschema declares every column a place can be exported with,
+// once. Job downloads read the columns from raw results (gmaps.Entry) and
+// name them by Label; listing exports read them from business_listings
+// (domain.BusinessListing) and name them by Key. Job downloads also accept
+// keys in their columns parameter.
+package exportschema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sadewadee/google-scraper/gmaps"
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// Column is an exportable field of a place
+type Column struct {
+	Key   string // snake_case name, used by listing exports
+	Label string // Header of job downloads
+
+	// Fields are the gmaps.Entry JSON fields the column is made of
+	Fields []string
+
+	// Entry formats the column from a raw result, Listing from a stored
+	// listing; nil when the source lacks the field
+	Entry   func(e *gmaps.Entry) string
+	Listing func(l *domain.BusinessListing) string
+
+	// Default columns make up a job download without columns
+	Default bool
+}
+
+// Unexported are the gmaps.Entry JSON fields no column is made of, and why
+var Unexported = map[string]string{
+	"input_id":               "internal to the scrape",
+	"open_hours_parse_error": "diagnostic, the open day columns are empty instead",
+	"popular_times":          "nested per day and hour",
+	"reservations":           "list of links with sources",
+	"order_online":           "list of links with sources",
+	"menu":                   "link with source",
+	"owner":                  "nested object",
+	"about":                  "nested sections, attributes carry the same options",
+	"user_reviews":           "served by the reviews endpoints",
+	"user_reviews_extended":  "served by the reviews endpoints",
+	"email_validations":      "per email, served with the listing",
+	"email_sources":          "per email, the page it was found on",
+}
+
+var columns = buildColumns()
+
+var (
+	byKey   = make(map[string]*Column, len(columns))
+	byLabel = make(map[string]*Column, len(columns))
+)
+
+func init() {
+	for i := range columns {
+		c := &columns[i]
+		if byKey[c.Key] != nil || byLabel[c.Label] != nil {
+			panic("exportschema: duplicate column " + c.Key)
+		}
+		byKey[c.Key] = c
+		byLabel[c.Label] = c
+	}
+}
+
+// All returns every column in export order
+func All() []Column {
+	return columns
+}
+
+// Lookup finds a column by key or label
+func Lookup(name string) (Column, bool) {
+	if c, ok := byKey[name]; ok {
+		return *c, true
+	}
+	if c, ok := byLabel[name]; ok {
+		return *c, true
+	}
+	return Column{}, false
+}
+
+// EntryFormatters maps the label of every column of raw results to its
+// formatter
+func EntryFormatters() map[string]func(e *gmaps.Entry) string {
+	formatters := make(map[string]func(e *gmaps.Entry) string)
+	for _, c := range columns {
+		if c.Entry != nil {
+			formatters[c.Label] = c.Entry
+		}
+	}
+	return formatters
+}
+
+// DefaultLabels returns the labels of the default job download columns
+func DefaultLabels() []string {
+	var labels []string
+	for _, c := range columns {
+		if c.Default {
+			labels = append(labels, c.Label)
+		}
+	}
+	return labels
+}
+
+// ListingKeys returns the keys of every column of stored listings
+func ListingKeys() []string {
+	var keys []string
+	for _, c := range columns {
+		if c.Listing != nil {
+			keys = append(keys, c.Key)
+		}
+	}
+	return keys
+}
+
+// ListingValue formats the column named key or label from a listing, ""
+// for an unknown column
+func ListingValue(l *domain.BusinessListing, name string) string {
+	c, ok := Lookup(name)
+	if !ok || c.Listing == nil {
+		return ""
+	}
+	return c.Listing(l)
+}
+
+func buildColumns() []Column {
+	cols := []Column{
+		{
+			Key: "title", Label: "Title", Fields: []string{"title"}, Default: true,
+			Entry:   func(e *gmaps.Entry) string { return e.Title },
+			Listing: func(l *domain.BusinessListing) string { return l.Title },
+		},
+		{
+			Key: "category", Label: "Category", Fields: []string{"category"}, Default: true,
+			Entry:   func(e *gmaps.Entry) string { return e.Category },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.Category) },
+		},
+		{
+			Key: "categories", Label: "Categories", Fields: []string{"categories"},
+			Entry:   func(e *gmaps.Entry) string { return strings.Join(e.Categories, ", ") },
+			Listing: func(l *domain.BusinessListing) string { return strings.Join(l.Categories, ", ") },
+		},
+		{
+			Key: "address", Label: "Address", Fields: []string{"address"}, Default: true,
+			Entry:   func(e *gmaps.Entry) string { return e.Address },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.Address) },
+		},
+		{
+			Key: "phone", Label: "Phone", Fields: []string{"phone"}, Default: true,
+			Entry:   func(e *gmaps.Entry) string { return e.Phone },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.Phone) },
+		},
+		{
+			Key: "phone_e164", Label: "Phone (E.164)",
+			Listing: func(l *domain.BusinessListing) string { return deref(l.PhoneE164) },
+		},
+		{
+			Key: "website", Label: "Website", Fields: []string{"web_site"}, Default: true,
+			Entry:   func(e *gmaps.Entry) string { return e.WebSite },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.Website) },
+		},
+		{
+			Key: "email", Label: "Email", Fields: []string{"emails"},
+			Entry:   func(e *gmaps.Entry) string { return strings.Join(e.Emails, ", ") },
+			Listing: func(l *domain.BusinessListing) string { return strings.Join(l.Emails, ", ") },
+		},
+		{
+			Key: "latitude", Label: "Latitude", Fields: []string{"latitude"}, Default: true,
+			Entry:   func(e *gmaps.Entry) string { return fmt.Sprintf("%f", e.Latitude) },
+			Listing: func(l *domain.BusinessListing) string { return formatFloat("%f", l.Latitude) },
+		},
+		{
+			Key: "longitude", Label: "Longitude", Fields: []string{"longitude"}, Default: true,
+			Entry:   func(e *gmaps.Entry) string { return fmt.Sprintf("%f", e.Longitude) },
+			Listing: func(l *domain.BusinessListing) string { return formatFloat("%f", l.Longitude) },
+		},
+		{
+			Key: "street", Label: "Street", Fields: []string{"complete_address"},
+			Entry:   func(e *gmaps.Entry) string { return e.CompleteAddress.Street },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.AddressStreet) },
+		},
+		{
+			Key: "house_number", Label: "House Number",
+			Listing: func(l *domain.BusinessListing) string { return deref(l.AddressNumber) },
+		},
+		{
+			Key: "postcode", Label: "Postcode", Fields: []string{"complete_address"},
+			Entry:   func(e *gmaps.Entry) string { return e.CompleteAddress.PostalCode },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.AddressPostalCode) },
+		},
+		{
+			Key: "city", Label: "City", Fields: []string{"complete_address"},
+			Entry:   func(e *gmaps.Entry) string { return e.CompleteAddress.City },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.AddressCity) },
+		},
+		{
+			Key: "state", Label: "State", Fields: []string{"complete_address"},
+			Entry:   func(e *gmaps.Entry) string { return e.CompleteAddress.State },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.AddressState) },
+		},
+		{
+			Key: "country", Label: "Country", Fields: []string{"complete_address"},
+			Entry:   func(e *gmaps.Entry) string { return e.CompleteAddress.Country },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.AddressCountry) },
+		},
+		{
+			Key: "plus_code", Label: "Plus Code", Fields: []string{"plus_code"},
+			Entry:   func(e *gmaps.Entry) string { return e.PlusCode },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.PlusCode) },
+		},
+		{
+			Key: "timezone", Label: "Timezone", Fields: []string{"timezone"},
+			Entry:   func(e *gmaps.Entry) string { return e.Timezone },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.Timezone) },
+		},
+		{
+			Key: "review_count", Label: "Reviews", Fields: []string{"review_count"}, Default: true,
+			Entry:   func(e *gmaps.Entry) string { return strconv.Itoa(e.ReviewCount) },
+			Listing: func(l *domain.BusinessListing) string { return strconv.Itoa(l.ReviewCount) },
+		},
+		{
+			Key: "review_rating", Label: "Rating", Fields: []string{"review_rating"}, Default: true,
+			Entry:   func(e *gmaps.Entry) string { return fmt.Sprintf("%.1f", e.ReviewRating) },
+			Listing: func(l *domain.BusinessListing) string { return formatFloat("%.1f", l.ReviewRating) },
+		},
+		{
+			Key: "reviews_per_rating", Label: "Reviews per Rating", Fields: []string{"reviews_per_rating"},
+			Entry:   func(e *gmaps.Entry) string { return formatHistogram(e.ReviewsPerRating) },
+			Listing: func(l *domain.BusinessListing) string { return formatHistogram(l.ReviewsPerRating) },
+		},
+		{
+			Key: "bayesian_rating", Label: "Bayesian Rating",
+			Listing: func(l *domain.BusinessListing) string { return formatFloat("%.2f", l.BayesianRating) },
+		},
+		{
+			Key: "status", Label: "Status", Fields: []string{"status"},
+			Entry:   func(e *gmaps.Entry) string { return e.Status },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.Status) },
+		},
+		{
+			Key: "price_range", Label: "Price Range", Fields: []string{"price_range"},
+			Entry:   func(e *gmaps.Entry) string { return e.PriceRange },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.PriceRange) },
+		},
+		{
+			Key: "description", Label: "Description", Fields: []string{"description"},
+			Entry:   func(e *gmaps.Entry) string { return e.Description },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.Description) },
+		},
+		{
+			Key: "link", Label: "Google Maps URL", Fields: []string{"link"}, Default: true,
+			Entry:   func(e *gmaps.Entry) string { return e.Link },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.Link) },
+		},
+		{
+			Key: "reviews_link", Label: "Reviews URL", Fields: []string{"reviews_link"},
+			Entry:   func(e *gmaps.Entry) string { return e.ReviewsLink },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.ReviewsLink) },
+		},
+		{
+			Key: "place_id", Label: "Place ID", Fields: []string{"place_id"}, Default: true,
+			Entry:   func(e *gmaps.Entry) string { return e.PlaceID },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.PlaceID) },
+		},
+		{
+			Key: "cid", Label: "CID", Fields: []string{"cid"},
+			Entry:   func(e *gmaps.Entry) string { return e.Cid },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.CID) },
+		},
+		{
+			Key: "data_id", Label: "Data ID", Fields: []string{"data_id"},
+			Entry:   func(e *gmaps.Entry) string { return e.DataID },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.DataID) },
+		},
+		{
+			Key: "thumbnail", Label: "Thumbnail", Fields: []string{"thumbnail"},
+			Entry: func(e *gmaps.Entry) string { return e.Thumbnail },
+		},
+		{
+			// image_urls is the capped list of the images' URLs
+			Key: "image_urls", Label: "Image URLs", Fields: []string{"image_urls", "images"},
+			Entry:   func(e *gmaps.Entry) string { return strings.Join(e.ImageURLs, ", ") },
+			Listing: func(l *domain.BusinessListing) string { return strings.Join(l.ImageURLs, ", ") },
+		},
+		{
+			Key: "attributes", Label: "Attributes", Fields: []string{"attributes"},
+			Entry:   func(e *gmaps.Entry) string { return gmaps.FlattenAttributes(e.Attributes) },
+			Listing: func(l *domain.BusinessListing) string { return gmaps.FlattenAttributes(l.Attributes) },
+		},
+	}
+
+	for _, network := range []struct{ key, label string }{
+		{gmaps.SocialFacebook, "Facebook"},
+		{gmaps.SocialInstagram, "Instagram"},
+		{gmaps.SocialLinkedIn, "LinkedIn"},
+		{gmaps.SocialWhatsApp, "WhatsApp"},
+		{gmaps.SocialTwitter, "Twitter"},
+		{gmaps.SocialYouTube, "YouTube"},
+		{gmaps.SocialTikTok, "TikTok"},
+	} {
+		cols = append(cols, Column{
+			Key: network.key, Label: network.label, Fields: []string{"social_links"},
+			Entry:   func(e *gmaps.Entry) string { return e.SocialLinks[network.key] },
+			Listing: func(l *domain.BusinessListing) string { return l.SocialLinks[network.key] },
+		})
+	}
+
+	cols = append(cols,
+		Column{
+			Key: "website_phone", Label: "Website Phone", Fields: []string{"website_phone"},
+			Entry:   func(e *gmaps.Entry) string { return e.WebsitePhone },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.WebsitePhone) },
+		},
+		Column{
+			Key: "website_description", Label: "Website Description", Fields: []string{"website_description"},
+			Entry:   func(e *gmaps.Entry) string { return e.WebsiteDescription },
+			Listing: func(l *domain.BusinessListing) string { return deref(l.WebsiteDesc) },
+		},
+		Column{
+			Key: "lang", Label: "Language", Fields: []string{"lang"},
+			Entry: func(e *gmaps.Entry) string { return e.Lang },
+		},
+		Column{
+			Key: "opening_hours", Label: "Opening Hours", Fields: []string{"open_hours"},
+			Entry: func(e *gmaps.Entry) string { return formatOpenHours(e.OpenHours) },
+		},
+		Column{
+			Key: "is_new", Label: "Is New",
+			Listing: func(l *domain.BusinessListing) string {
+				if l.IsNew == nil {
+					return ""
+				}
+				return strconv.FormatBool(*l.IsNew)
+			},
+		},
+		Column{
+			Key: "first_seen_job_id", Label: "First Seen Job ID",
+			Listing: func(l *domain.BusinessListing) string { return deref(l.FirstSeenJobID) },
+		},
+		Column{
+			Key: "business_status", Label: "Business Status",
+			Listing: func(l *domain.BusinessListing) string { return l.BusinessStatus },
+		},
+		Column{
+			Key: "website_status", Label: "Website Status",
+			Listing: func(l *domain.BusinessListing) string { return deref(l.WebsiteStatus) },
+		},
+		Column{
+			Key: "website_final_url", Label: "Website Final URL",
+			Listing: func(l *domain.BusinessListing) string { return deref(l.WebsiteFinalURL) },
+		},
+		Column{
+			Key: "data_completeness", Label: "Data Completeness",
+			Fields: []string{"data_completeness"},
+			Entry: func(e *gmaps.Entry) string {
+				if e.DataCompleteness == "" {
+					return domain.CompletenessFull
+				}
+				return e.DataCompleteness
+			},
+			Listing: func(l *domain.BusinessListing) string { return l.Completeness },
+		},
+	)
+
+	// y or n, empty when the day is not listed or the hours did not parse
+	for _, day := range gmaps.Weekdays {
+		cols = append(cols, Column{
+			Key:    "open_" + day,
+			Label:  fmt.Sprintf("Open %s (y/n)", strings.ToUpper(day[:1])+day[1:]),
+			Fields: []string{"open_hours_parsed"},
+			Entry:  func(e *gmaps.Entry) string { return yesNo(e.OpenHoursParsed.IsOpen(day)) },
+			Listing: func(l *domain.BusinessListing) string {
+				return yesNo(l.OpeningHours.IsOpen(day))
+			},
+		})
+	}
+
+	return cols
+}
+
+// formatOpenHours writes the displayed hours as "Day: hours; ...", in the
+// order of the day names
+func formatOpenHours(hours map[string][]string) string {
+	days := make([]string, 0, len(hours))
+	for day := range hours {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	parts := make([]string, len(days))
+	for i, day := range days {
+		parts[i] = fmt.Sprintf("%s: %s", day, strings.Join(hours[day], ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// yesNo formats a known flag as "y" or "n", and an unknown one as ""
+func yesNo(v, known bool) string {
+	switch {
+	case !known:
+		return ""
+	case v:
+		return "y"
+	default:
+		return "n"
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// formatHistogram renders review counts by star as "5:120;4:40;...", five
+// stars first
+func formatHistogram(perRating map[int]int) string {
+	if len(perRating) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, 5)
+	for star := 5; star >= 1; star-- {
+		parts = append(parts, fmt.Sprintf("%d:%d", star, perRating[star]))
+	}
+	return strings.Join(parts, ";")
+}
+
+func formatFloat(format string, f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return fmt.Sprintf(format, *f)
+}
@@ -0,0 +1,204 @@
+package exportschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/gmaps"
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+func str(s string) *string { return &s }
+
+// fullEntry has every field set. A field added to gmaps.Entry must be set
+// here too, and then exported by a column or listed in Unexported.
+func fullEntry(t *testing.T) gmaps.Entry {
+	hours := map[string][]string{"Monday": {"9 AM–5 PM"}, "Sunday": {"Closed"}}
+	parsed, err := gmaps.ParseHours(hours, "en")
+	require.NoError(t, err)
+
+	return gmaps.Entry{
+		ID:                  "input-1",
+		Link:                "https://www.google.com/maps/place/x",
+		Cid:                 "123",
+		Title:               "Cafe",
+		Categories:          []string{"Cafe", "Bakery"},
+		Category:            "Cafe",
+		Address:             "Main St 1, Springfield",
+		OpenHours:           hours,
+		OpenHoursParsed:     parsed,
+		OpenHoursParseError: "unknown day",
+		PopularTimes:        map[string]map[int]int{"Monday": {9: 50}},
+		WebSite:             "https://cafe.example",
+		Phone:               "+1 555 0100",
+		PlusCode:            "849VCWC8+R9",
+		ReviewCount:         12,
+		ReviewRating:        4.5,
+		ReviewsPerRating:    map[int]int{5: 10},
+		Latitude:            1.5,
+		Longitude:           2.5,
+		Status:              "Open",
+		Description:         "Coffee",
+		ReviewsLink:         "https://www.google.com/maps/reviews/x",
+		Thumbnail:           "https://img.example/t.jpg",
+		Timezone:            "America/New_York",
+		PriceRange:          "$$",
+		DataID:              "0x1:0x2",
+		PlaceID:             "ChIJ",
+		Images:              []gmaps.Image{{Title: "Front", Image: "https://img.example/1.jpg"}},
+		ImageURLs:           []string{"https://img.example/1.jpg"},
+		Reservations:        []gmaps.LinkSource{{Link: "https://book.example", Source: "book"}},
+		OrderOnline:         []gmaps.LinkSource{{Link: "https://order.example", Source: "order"}},
+		Menu:                gmaps.LinkSource{Link: "https://menu.example", Source: "menu"},
+		Owner:               gmaps.Owner{ID: "1", Name: "Owner", Link: "https://owner.example"},
+		CompleteAddress: gmaps.Address{
+			Borough: "Downtown", Street: "Main St 1", City: "Springfield",
+			PostalCode: "12345", State: "IL", Country: "US",
+		},
+		About:               []gmaps.About{{ID: "a", Name: "Service options"}},
+		Attributes:          map[string][]string{"Service options": {"Takeout"}},
+		UserReviews:         []gmaps.Review{{Name: "A", Rating: 5}},
+		UserReviewsExtended: []gmaps.Review{{Name: "B", Rating: 4}},
+		Emails:              []string{"info@cafe.example"},
+		EmailValidations:    []gmaps.EmailValidation{{Email: "info@cafe.example"}},
+		EmailSources:        []gmaps.EmailSource{{Email: "info@cafe.example", URL: "https://cafe.example/contact"}},
+		SocialLinks: map[string]string{
+			gmaps.SocialFacebook: "https://facebook.com/cafe", gmaps.SocialInstagram: "https://instagram.com/cafe",
+			gmaps.SocialLinkedIn: "https://linkedin.com/cafe", gmaps.SocialWhatsApp: "https://wa.me/1",
+			gmaps.SocialTwitter: "https://x.com/cafe", gmaps.SocialYouTube: "https://youtube.com/cafe",
+			gmaps.SocialTikTok: "https://tiktok.com/@cafe",
+		},
+		WebsitePhone:       "+1 555 0101",
+		WebsiteDescription: "Best coffee",
+		Lang:               "de",
+		DataCompleteness:   gmaps.CompletenessPartial,
+		ParseReport:        &gmaps.ParseReport{Missing: []string{"menu"}},
+	}
+}
+
+// entryJSONFields returns the JSON names of the fields of gmaps.Entry
+func entryJSONFields() []string {
+	var names []string
+
+	t := reflect.TypeOf(gmaps.Entry{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func TestEveryEntryFieldIsExported(t *testing.T) {
+	entry := fullEntry(t)
+
+	v := reflect.ValueOf(entry)
+	for i := 0; i < v.NumField(); i++ {
+		require.False(t, v.Field(i).IsZero(), "fullEntry leaves %s unset", v.Type().Field(i).Name)
+	}
+
+	exported := make(map[string]bool)
+	for _, c := range All() {
+		for _, f := range c.Fields {
+			exported[f] = true
+		}
+	}
+
+	fields := make(map[string]bool)
+	for _, name := range entryJSONFields() {
+		fields[name] = true
+
+		_, unexported := Unexported[name]
+		switch {
+		case exported[name] && unexported:
+			t.Errorf("%s is exported and listed in Unexported", name)
+		case !exported[name] && !unexported:
+			t.Errorf("gmaps.Entry field %s has no export column; add one or list it in Unexported", name)
+		}
+	}
+
+	for name := range exported {
+		assert.True(t, fields[name], "a column is made of %s, which gmaps.Entry lacks", name)
+	}
+	for name := range Unexported {
+		assert.True(t, fields[name], "Unexported lists %s, which gmaps.Entry lacks", name)
+	}
+}
+
+func TestEntryColumns(t *testing.T) {
+	// Downloads format results decoded from their stored JSON
+	full := fullEntry(t)
+	data, err := json.Marshal(&full)
+	require.NoError(t, err)
+
+	var entry gmaps.Entry
+	require.NoError(t, json.Unmarshal(data, &entry))
+
+	for _, c := range All() {
+		if c.Entry == nil {
+			assert.Empty(t, c.Fields, "%s is made of entry fields but cannot be read from one", c.Key)
+			continue
+		}
+		if strings.HasPrefix(c.Key, "open_") && c.Key != "open_monday" && c.Key != "open_sunday" {
+			continue // Days Google did not list are left empty
+		}
+		assert.NotEmpty(t, c.Entry(&entry), "%s is empty for a full entry", c.Label)
+	}
+
+	assert.Equal(t, "y", EntryFormatters()["Open Monday (y/n)"](&entry))
+	assert.Equal(t, "n", EntryFormatters()["Open Sunday (y/n)"](&entry))
+	assert.Equal(t, "", EntryFormatters()["Open Tuesday (y/n)"](&entry))
+	assert.Equal(t, "Monday: 9 AM–5 PM; Sunday: Closed", EntryFormatters()["Opening Hours"](&entry))
+}
+
+func TestListingColumns(t *testing.T) {
+	lat, lon, rating, bayesian, isNew := 1.5, 2.5, 4.5, 4.31, true
+	listing := &domain.BusinessListing{
+		Title: "Cafe", Category: str("Cafe"), Categories: []string{"Cafe"},
+		Address: str("Main St 1"), Phone: str("+1 555 0100"), PhoneE164: str("+15550100"),
+		Website: str("https://cafe.example"), Emails: []string{"info@cafe.example"},
+		Latitude: &lat, Longitude: &lon,
+		AddressStreet: str("Main St 1"), AddressNumber: str("1"), AddressPostalCode: str("12345"),
+		AddressCity: str("Springfield"), AddressState: str("IL"), AddressCountry: str("US"),
+		PlusCode: str("849VCWC8+R9"), Timezone: str("America/New_York"),
+		ReviewCount: 12, ReviewRating: &rating, BayesianRating: &bayesian,
+		ReviewsPerRating: map[int]int{5: 10, 4: 2}, Status: str("Open"), PriceRange: str("$$"),
+		Description: str("Coffee"), Link: str("https://maps"), ReviewsLink: str("https://maps/reviews"),
+		PlaceID: str("ChIJ"), CID: str("123"), DataID: str("0x1:0x2"),
+		ImageURLs:  []string{"https://img.example/1.jpg"},
+		Attributes: map[string][]string{"Service options": {"Takeout"}},
+		SocialLinks: map[string]string{
+			gmaps.SocialFacebook: "f", gmaps.SocialInstagram: "i", gmaps.SocialLinkedIn: "l",
+			gmaps.SocialWhatsApp: "w", gmaps.SocialTwitter: "t", gmaps.SocialYouTube: "y", gmaps.SocialTikTok: "k",
+		},
+		WebsitePhone: str("+1 555 0101"), WebsiteDesc: str("Best coffee"),
+		IsNew: &isNew, FirstSeenJobID: str("job"), BusinessStatus: domain.BusinessStatusOpen,
+		WebsiteStatus: str(domain.WebsiteStatusOK), WebsiteFinalURL: str("https://www.cafe.example/"),
+		OpeningHours: &domain.OpeningHours{Days: map[string]domain.OpeningDay{}},
+		Completeness: domain.CompletenessFull,
+	}
+	for _, day := range domain.Weekdays {
+		listing.OpeningHours.Days[day] = domain.OpeningDay{Closed: day == "sunday"}
+	}
+
+	for _, c := range All() {
+		require.True(t, c.Entry != nil || c.Listing != nil, "%s has no source", c.Key)
+		if c.Listing != nil {
+			assert.NotEmpty(t, c.Listing(listing), "%s is empty for a full listing", c.Key)
+		}
+	}
+
+	assert.Equal(t, "n", ListingValue(listing, "open_sunday"))
+	assert.Equal(t, "5:10;4:2;3:0;2:0;1:0", ListingValue(listing, "reviews_per_rating"))
+	assert.Equal(t, "4.31", ListingValue(listing, "bayesian_rating"))
+	assert.Equal(t, "Springfield", ListingValue(listing, "City"), "labels name columns too")
+	assert.Equal(t, "", ListingValue(listing, "no_such_column"))
+	assert.Equal(t, "", ListingValue(listing, "thumbnail"), "listings do not store thumbnails")
+}
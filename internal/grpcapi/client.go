@@ -0,0 +1,297 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/grpcapi/workerpb"
+)
+
+// Client is the worker's end of the protocol. Its methods mirror the HTTP
+// client's and return the gRPC status errors of the manager unchanged.
+type Client struct {
+	conn  *grpc.ClientConn
+	api   workerpb.WorkerServiceClient
+	token string
+
+	// The heartbeat stream, opened by the first Heartbeat and again after
+	// it breaks; directives received on it go to onDirectives
+	mu           sync.Mutex
+	stream       workerpb.WorkerService_HeartbeatClient
+	cancel       context.CancelFunc
+	streamErr    error // Why the last stream ended, reported by the next Heartbeat
+	onDirectives func(*domain.WorkerDirectives)
+}
+
+// NewClient creates a client for the manager at target (host:port),
+// authenticating with token unless it is empty. No connection is made
+// until the first call.
+func NewClient(target, token string) (*Client, error) {
+	conn, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(MaxMessageBytes),
+			grpc.MaxCallSendMsgSize(MaxMessageBytes),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+
+	return &Client{conn: conn, api: workerpb.NewWorkerServiceClient(conn), token: token}, nil
+}
+
+// OnDirectives sets the function the directives of the heartbeat stream
+// are handed to, both answers to heartbeats and pushed ones. It is called
+// from the stream's goroutine.
+func (c *Client) OnDirectives(fn func(*domain.WorkerDirectives)) {
+	c.mu.Lock()
+	c.onDirectives = fn
+	c.mu.Unlock()
+}
+
+// Close ends the heartbeat stream and the connection
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.mu.Unlock()
+
+	return c.conn.Close()
+}
+
+func (c *Client) outgoing(ctx context.Context) context.Context {
+	if c.token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.token)
+}
+
+// Register registers the worker
+func (c *Client) Register(ctx context.Context, workerID string) (*domain.Worker, error) {
+	resp, err := c.api.Register(c.outgoing(ctx), &workerpb.RegisterRequest{WorkerId: workerID})
+	if err != nil {
+		return nil, err
+	}
+
+	var worker domain.Worker
+	if err := json.Unmarshal(resp.GetWorkerJson(), &worker); err != nil {
+		return nil, fmt.Errorf("failed to decode worker: %w", err)
+	}
+
+	return &worker, nil
+}
+
+// Heartbeat sends hb on the heartbeat stream, opening it first when there
+// is none. The answer arrives through OnDirectives. A stream the manager
+// ended has its error returned once, without sending hb, so the caller
+// learns e.g. that the manager does not serve the protocol.
+func (c *Client) Heartbeat(_ context.Context, hb domain.WorkerHeartbeat) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stream == nil {
+		if err := c.streamErr; err != nil {
+			c.streamErr = nil
+			return err
+		}
+
+		// The stream outlives the call that opens it
+		ctx, cancel := context.WithCancel(c.outgoing(context.Background()))
+		stream, err := c.api.Heartbeat(ctx)
+		if err != nil {
+			cancel()
+			return err
+		}
+		c.stream, c.cancel = stream, cancel
+		go c.receive(stream)
+	}
+
+	if err := c.stream.Send(heartbeatToProto(hb)); err != nil {
+		// The next heartbeat opens a new stream; Send only reports the
+		// stream ended, RecvMsg tells why
+		c.cancel()
+		c.stream, c.cancel = nil, nil
+		return streamError(err)
+	}
+
+	return nil
+}
+
+// receive hands the directives of stream on until it ends
+func (c *Client) receive(stream workerpb.WorkerService_HeartbeatClient) {
+	for {
+		d, err := stream.Recv()
+		if err != nil {
+			c.mu.Lock()
+			if c.stream == stream {
+				c.cancel()
+				c.stream, c.cancel = nil, nil
+				if status.Code(err) != codes.Canceled {
+					c.streamErr = streamError(err)
+				}
+			}
+			c.mu.Unlock()
+			return
+		}
+
+		c.mu.Lock()
+		fn := c.onDirectives
+		c.mu.Unlock()
+
+		if directives := directivesFromProto(d); directives != nil && fn != nil {
+			fn(directives)
+		}
+	}
+}
+
+// streamError keeps the status of a failed Send, Unavailable otherwise
+func streamError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+// GetJob returns a job, nil if it does not exist
+func (c *Client) GetJob(ctx context.Context, jobID uuid.UUID) (*domain.Job, error) {
+	resp, err := c.api.GetJob(c.outgoing(ctx), &workerpb.GetJobRequest{JobId: jobID.String()})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeJob(resp)
+}
+
+// ClaimJob claims a pending job, nil when there is none
+func (c *Client) ClaimJob(ctx context.Context, workerID string) (*domain.Job, error) {
+	resp, err := c.api.ClaimJob(c.outgoing(ctx), &workerpb.ClaimJobRequest{WorkerId: workerID})
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeJob(resp)
+}
+
+func decodeJob(resp *workerpb.JobResponse) (*domain.Job, error) {
+	if len(resp.GetJobJson()) == 0 {
+		return nil, nil
+	}
+
+	var job domain.Job
+	if err := json.Unmarshal(resp.GetJobJson(), &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// SubmitResults sends a result batch. ResourceExhausted means the batch was
+// stored up to the tenant's quota.
+func (c *Client) SubmitResults(ctx context.Context, batch domain.ResultBatch) error {
+	var batchID string
+	if batch.BatchID != uuid.Nil {
+		batchID = batch.BatchID.String()
+	}
+
+	_, err := c.api.SubmitResults(c.outgoing(ctx), &workerpb.ResultBatch{
+		JobId:   batch.JobID.String(),
+		BatchId: batchID,
+		Results: batch.Data,
+	})
+
+	return err
+}
+
+// CompleteJob marks a job completed
+func (c *Client) CompleteJob(ctx context.Context, workerID string, jobID uuid.UUID, placesScraped, dedupedPlaces int, failedKeywords []string, stoppedReason string, outputErrors []string, parseReport *domain.JobParseReport) error {
+	req := &workerpb.CompleteJobRequest{
+		WorkerId:       workerID,
+		JobId:          jobID.String(),
+		PlacesScraped:  int32(placesScraped),
+		DedupedPlaces:  int32(dedupedPlaces),
+		FailedKeywords: failedKeywords,
+		StoppedReason:  stoppedReason,
+		OutputErrors:   outputErrors,
+	}
+	if parseReport != nil {
+		data, err := json.Marshal(parseReport)
+		if err != nil {
+			return fmt.Errorf("failed to encode parse report: %w", err)
+		}
+		req.ParseReportJson = data
+	}
+
+	_, err := c.api.CompleteJob(c.outgoing(ctx), req)
+	return err
+}
+
+// FailJob marks a job failed
+func (c *Client) FailJob(ctx context.Context, workerID string, jobID uuid.UUID, dedupedPlaces int, errMsg string, errorCode domain.JobErrorCode, failedKeywords []string) error {
+	_, err := c.api.FailJob(c.outgoing(ctx), &workerpb.FailJobRequest{
+		WorkerId:       workerID,
+		JobId:          jobID.String(),
+		Message:        errMsg,
+		ErrorCode:      string(errorCode),
+		FailedKeywords: failedKeywords,
+		DedupedPlaces:  int32(dedupedPlaces),
+	})
+	return err
+}
+
+// ReleaseJob hands a job back to pending
+func (c *Client) ReleaseJob(ctx context.Context, workerID string, jobID uuid.UUID) error {
+	_, err := c.api.ReleaseJob(c.outgoing(ctx), &workerpb.ReleaseJobRequest{WorkerId: workerID, JobId: jobID.String()})
+	return err
+}
+
+// Unregister removes the worker
+func (c *Client) Unregister(ctx context.Context, workerID string) error {
+	_, err := c.api.Unregister(c.outgoing(ctx), &workerpb.UnregisterRequest{WorkerId: workerID})
+	return err
+}
+
+// IsUnreachable reports whether err means no manager serving the protocol
+// was reached, so the call is to be made over HTTP instead: the gRPC port
+// is down or closed, or the manager serves an older protocol. A result
+// batch sent again that way is recognized by its batch ID.
+func IsUnreachable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Unimplemented:
+		return true
+	}
+
+	return false
+}
+
+// IsUnimplemented reports whether err means the manager does not serve the
+// protocol, so there is no point in trying again
+func IsUnimplemented(err error) bool {
+	return status.Code(err) == codes.Unimplemented
+}
+
+// IsRejected reports whether the manager refused a call for good, so
+// making it again, over either transport, does not help
+func IsRejected(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.NotFound, codes.PermissionDenied, codes.Unauthenticated,
+		codes.ResourceExhausted, codes.FailedPrecondition, codes.AlreadyExists:
+		return true
+	}
+
+	return false
+}
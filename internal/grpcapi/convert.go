@@ -0,0 +1,119 @@
+package grpcapi
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/grpcapi/workerpb"
+)
+
+// heartbeatToProto converts a heartbeat for the wire
+func heartbeatToProto(hb domain.WorkerHeartbeat) *workerpb.HeartbeatRequest {
+	req := &workerpb.HeartbeatRequest{
+		WorkerId:          hb.WorkerID,
+		Hostname:          hb.Hostname,
+		Status:            string(hb.Status),
+		CurrentJobName:    hb.CurrentJobName,
+		RequestDelayMs:    hb.RequestDelayMs,
+		BlockCount:        hb.BlockCount,
+		SeedJobsCompleted: int32(hb.SeedJobsCompleted),
+		SeedJobsTotal:     int32(hb.SeedJobsTotal),
+		JobPlacesScraped:  int32(hb.JobPlacesScraped),
+		MemoryBytes:       hb.MemoryBytes,
+	}
+	if hb.CurrentJobID != nil {
+		req.CurrentJobId = hb.CurrentJobID.String()
+	}
+	for _, job := range hb.Jobs {
+		req.Jobs = append(req.Jobs, &workerpb.WorkerJob{
+			JobId:             job.JobID.String(),
+			JobName:           job.JobName,
+			SeedJobsCompleted: int32(job.SeedJobsCompleted),
+			SeedJobsTotal:     int32(job.SeedJobsTotal),
+			PlacesScraped:     int32(job.PlacesScraped),
+		})
+	}
+
+	return req
+}
+
+// heartbeatFromProto converts a heartbeat from the wire
+func heartbeatFromProto(req *workerpb.HeartbeatRequest) (*domain.WorkerHeartbeat, error) {
+	hb := &domain.WorkerHeartbeat{
+		WorkerID:          req.GetWorkerId(),
+		Hostname:          req.GetHostname(),
+		Status:            domain.WorkerStatus(req.GetStatus()),
+		CurrentJobName:    req.GetCurrentJobName(),
+		RequestDelayMs:    req.GetRequestDelayMs(),
+		BlockCount:        req.GetBlockCount(),
+		SeedJobsCompleted: int(req.GetSeedJobsCompleted()),
+		SeedJobsTotal:     int(req.GetSeedJobsTotal()),
+		JobPlacesScraped:  int(req.GetJobPlacesScraped()),
+		MemoryBytes:       req.GetMemoryBytes(),
+	}
+
+	if id := req.GetCurrentJobId(); id != "" {
+		jobID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid current job ID: %w", err)
+		}
+		hb.CurrentJobID = &jobID
+	}
+
+	for _, job := range req.GetJobs() {
+		jobID, err := uuid.Parse(job.GetJobId())
+		if err != nil {
+			return nil, fmt.Errorf("invalid job ID: %w", err)
+		}
+		hb.Jobs = append(hb.Jobs, domain.WorkerJob{
+			JobID:             jobID,
+			JobName:           job.GetJobName(),
+			SeedJobsCompleted: int(job.GetSeedJobsCompleted()),
+			SeedJobsTotal:     int(job.GetSeedJobsTotal()),
+			PlacesScraped:     int(job.GetPlacesScraped()),
+		})
+	}
+
+	return hb, nil
+}
+
+// directivesToProto converts directives for the wire, nil for none
+func directivesToProto(d *domain.WorkerDirectives) *workerpb.Directives {
+	out := &workerpb.Directives{}
+	if d == nil {
+		return out
+	}
+
+	out.Drain = d.Drain
+	if d.DrainTimeoutSeconds != nil {
+		seconds := int32(*d.DrainTimeoutSeconds)
+		out.DrainTimeoutSeconds = &seconds
+	}
+	if d.StopJob != nil {
+		out.StopJobId = d.StopJob.JobID.String()
+		out.StopJobStatus = string(d.StopJob.Status)
+	}
+
+	return out
+}
+
+// directivesFromProto converts directives from the wire, nil when they ask
+// nothing of the worker
+func directivesFromProto(d *workerpb.Directives) *domain.WorkerDirectives {
+	out := &domain.WorkerDirectives{Drain: d.GetDrain()}
+	if d.DrainTimeoutSeconds != nil {
+		seconds := int(d.GetDrainTimeoutSeconds())
+		out.DrainTimeoutSeconds = &seconds
+	}
+	if jobID, err := uuid.Parse(d.GetStopJobId()); err == nil {
+		out.StopJob = &domain.JobStop{JobID: jobID, Status: domain.JobStatus(d.GetStopJobStatus())}
+	}
+
+	if !out.Drain && out.StopJob == nil {
+		return nil
+	}
+
+	return out
+}
@@ -0,0 +1,543 @@
+// Package grpcapi serves the worker protocol over gRPC next to the HTTP
+// API, and is the worker's client for it. Workers that hold a heartbeat
+// stream open learn of a paused or cancelled job as it happens instead of
+// at their next poll, and submit results without the JSON envelope.
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/events"
+	"github.com/sadewadee/google-scraper/internal/grpcapi/workerpb"
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// MaxMessageBytes caps a message either way, as MaxResultBatchSize caps an
+// HTTP result batch
+const MaxMessageBytes = 10 << 20
+
+// shutdownTimeout is how long Serve waits for calls in flight once its
+// context is done
+const shutdownTimeout = 10 * time.Second
+
+// WorkerService is what the server needs of the worker service
+type WorkerService interface {
+	Register(ctx context.Context, workerID string) (*domain.Worker, error)
+	Heartbeat(ctx context.Context, hb *domain.WorkerHeartbeat) (*domain.WorkerDirectives, error)
+	ClaimJob(ctx context.Context, workerID string) (*domain.Job, error)
+	ReleaseJob(ctx context.Context, jobID uuid.UUID, workerID string) error
+	CompleteJob(ctx context.Context, jobID uuid.UUID, workerID string, placesScraped, dedupedPlaces int, failedKeywords []string, stoppedReason string, outputErrors []string, parseReport *domain.JobParseReport) error
+	FailJob(ctx context.Context, jobID uuid.UUID, workerID string, dedupedPlaces int, errMsg string, errorCode string, failedKeywords []string) error
+	Unregister(ctx context.Context, workerID string) error
+}
+
+// JobService is what the server needs of the job service
+type JobService interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Job, error)
+	AcceptResults(ctx context.Context, id uuid.UUID) error
+	SyncProgress(ctx context.Context, id uuid.UUID) error
+}
+
+// ResultService is what the server needs of the result service
+type ResultService interface {
+	CreateBatch(ctx context.Context, jobID, batchID uuid.UUID, data [][]byte) error
+}
+
+// APIKeyAuthenticator resolves a presented secret to a scoped API key
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, secret string) (*domain.APIKey, error)
+}
+
+// Config configures a Server
+type Config struct {
+	// Token is the legacy API token, which is always accepted. Empty
+	// disables authentication, as it does for the HTTP API.
+	Token string
+
+	// Keys resolves scoped API keys, which need the workers scope; nil
+	// accepts the token only
+	Keys APIKeyAuthenticator
+}
+
+// Server serves workerpb.WorkerService on top of the manager's services
+type Server struct {
+	workerpb.UnimplementedWorkerServiceServer
+
+	cfg     Config
+	workers WorkerService
+	jobs    JobService
+	results ResultService
+	events  events.Subscriber // Job status changes pushed down heartbeat streams, may be nil
+
+	grpc    *grpc.Server
+	closing chan struct{} // Closed on shutdown to end heartbeat streams
+	once    sync.Once
+}
+
+// NewServer creates a Server
+func NewServer(cfg Config, workers WorkerService, jobs JobService, results ResultService) *Server {
+	s := &Server{
+		cfg:     cfg,
+		workers: workers,
+		jobs:    jobs,
+		results: results,
+		closing: make(chan struct{}),
+	}
+
+	s.grpc = grpc.NewServer(
+		grpc.MaxRecvMsgSize(MaxMessageBytes),
+		grpc.MaxSendMsgSize(MaxMessageBytes),
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, err := s.authenticate(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if _, err := s.authenticate(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	workerpb.RegisterWorkerServiceServer(s.grpc, s)
+
+	return s
+}
+
+// SetEvents enables pushing pauses and cancels of running jobs to the
+// workers as they are published
+func (s *Server) SetEvents(sub events.Subscriber) {
+	s.events = sub
+}
+
+// Serve serves on lis until ctx is done, then ends the heartbeat streams
+// and waits up to shutdownTimeout for the other calls
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		s.once.Do(func() { close(s.closing) })
+
+		stopped := make(chan struct{})
+		go func() {
+			s.grpc.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-time.After(shutdownTimeout):
+			s.grpc.Stop()
+		}
+	}()
+
+	if err := s.grpc.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+
+	return nil
+}
+
+// authenticate checks the credentials in the call's metadata the way the
+// HTTP API checks its headers: the token, or an API key with the workers
+// scope
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	if s.cfg.Token == "" {
+		return ctx, nil
+	}
+
+	credentials := metadataCredentials(ctx)
+	for _, c := range credentials {
+		if subtle.ConstantTimeCompare([]byte(c), []byte(s.cfg.Token)) == 1 {
+			return ctx, nil
+		}
+	}
+
+	if s.cfg.Keys != nil {
+		for _, c := range credentials {
+			key, err := s.cfg.Keys.Authenticate(ctx, c)
+			if err != nil {
+				continue
+			}
+			if !key.HasScope(domain.ScopeWorkers) {
+				return nil, status.Errorf(codes.PermissionDenied, "API key lacks the %s scope", domain.ScopeWorkers)
+			}
+			ctx = domain.ContextWithAPIKey(ctx, key)
+			return logging.With(ctx, "api_key_id", key.ID), nil
+		}
+	}
+
+	return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+}
+
+// metadataCredentials returns the secrets presented as "authorization:
+// Bearer <secret>" or "x-api-key: <secret>"
+func metadataCredentials(ctx context.Context) []string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	var credentials []string
+	for _, v := range md.Get("authorization") {
+		if secret, found := strings.CutPrefix(v, "Bearer "); found && secret != "" {
+			credentials = append(credentials, secret)
+		}
+	}
+	credentials = append(credentials, md.Get("x-api-key")...)
+
+	return credentials
+}
+
+// Register registers a worker
+func (s *Server) Register(ctx context.Context, req *workerpb.RegisterRequest) (*workerpb.RegisterResponse, error) {
+	if req.GetWorkerId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Worker ID is required")
+	}
+
+	worker, err := s.workers.Register(ctx, req.GetWorkerId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to register worker: %v", err)
+	}
+
+	data, err := json.Marshal(worker)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to encode worker: %v", err)
+	}
+
+	return &workerpb.RegisterResponse{WorkerJson: data}, nil
+}
+
+// Heartbeat records every heartbeat of the stream and answers it with the
+// worker's directives. In between it pushes a stop for a job of the worker as
+// soon as the job is paused, cancelled or deleted.
+func (s *Server) Heartbeat(stream workerpb.WorkerService_HeartbeatServer) error {
+	ctx := stream.Context()
+
+	beats := make(chan *workerpb.HeartbeatRequest)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case beats <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	watch := newJobWatch(s.events)
+	defer watch.close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.closing:
+			return status.Error(codes.Unavailable, "manager shutting down")
+		case err := <-recvErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case req := <-beats:
+			hb, err := heartbeatFromProto(req)
+			if err != nil {
+				return status.Error(codes.InvalidArgument, err.Error())
+			}
+			if hb.WorkerID == "" {
+				return status.Error(codes.InvalidArgument, "Worker ID is required")
+			}
+
+			directives, err := s.workers.Heartbeat(ctx, hb)
+			if err != nil {
+				return status.Errorf(codes.Internal, "Failed to update heartbeat: %v", err)
+			}
+			if err := stream.Send(directivesToProto(directives)); err != nil {
+				return err
+			}
+
+			// Follow the jobs the worker runs
+			running := make([]uuid.UUID, 0, len(hb.Jobs)+1)
+			if hb.CurrentJobID != nil {
+				running = append(running, *hb.CurrentJobID)
+			}
+			for _, job := range hb.Jobs {
+				running = append(running, job.JobID)
+			}
+			watch.follow(running)
+		case ev := <-watch.pushed:
+			if !ev.Status.StopsWorker() {
+				continue
+			}
+
+			stop := &domain.WorkerDirectives{StopJob: &domain.JobStop{JobID: ev.JobID, Status: ev.Status}}
+			if err := stream.Send(directivesToProto(stop)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// jobWatch follows the status events of the jobs a worker runs, merged
+// into one channel
+type jobWatch struct {
+	sub    events.Subscriber // May be nil, then nothing is pushed
+	pushed chan events.Event
+	done   chan struct{}
+	subs   map[uuid.UUID]func()
+}
+
+func newJobWatch(sub events.Subscriber) *jobWatch {
+	return &jobWatch{
+		sub:    sub,
+		pushed: make(chan events.Event),
+		done:   make(chan struct{}),
+		subs:   make(map[uuid.UUID]func()),
+	}
+}
+
+// follow subscribes to the jobs not followed yet and drops the jobs that
+// are no longer running
+func (w *jobWatch) follow(jobs []uuid.UUID) {
+	if w.sub == nil {
+		return
+	}
+
+	running := make(map[uuid.UUID]bool, len(jobs))
+	for _, id := range jobs {
+		running[id] = true
+		if _, ok := w.subs[id]; ok {
+			continue
+		}
+
+		ch, unsubscribe := w.sub.Subscribe(id)
+		w.subs[id] = unsubscribe
+		go func() {
+			// Ends when unsubscribed, which closes ch
+			for ev := range ch {
+				select {
+				case w.pushed <- ev:
+				case <-w.done:
+					return
+				}
+			}
+		}()
+	}
+
+	for id, unsubscribe := range w.subs {
+		if !running[id] {
+			unsubscribe()
+			delete(w.subs, id)
+		}
+	}
+}
+
+func (w *jobWatch) close() {
+	close(w.done)
+	for _, unsubscribe := range w.subs {
+		unsubscribe()
+	}
+}
+
+// ClaimJob claims a pending job for a worker
+func (s *Server) ClaimJob(ctx context.Context, req *workerpb.ClaimJobRequest) (*workerpb.JobResponse, error) {
+	if req.GetWorkerId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Worker ID is required")
+	}
+
+	job, err := s.workers.ClaimJob(ctx, req.GetWorkerId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to claim job: %v", err)
+	}
+
+	return jobResponse(job)
+}
+
+// GetJob returns a job, NotFound when it does not exist
+func (s *Server) GetJob(ctx context.Context, req *workerpb.GetJobRequest) (*workerpb.JobResponse, error) {
+	id, err := parseID(req.GetJobId())
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.jobs.GetByID(ctx, id)
+	if errors.Is(err, service.ErrJobNotFound) {
+		return nil, status.Error(codes.NotFound, "Job not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to get job: %v", err)
+	}
+
+	return jobResponse(job)
+}
+
+// SubmitResults stores a result batch and updates the job's progress, as
+// POST /api/v2/jobs/{id}/results does
+func (s *Server) SubmitResults(ctx context.Context, batch *workerpb.ResultBatch) (*workerpb.SubmitResultsResponse, error) {
+	id, err := parseID(batch.GetJobId())
+	if err != nil {
+		return nil, err
+	}
+
+	var batchID uuid.UUID
+	if v := batch.GetBatchId(); v != "" {
+		if batchID, err = uuid.Parse(v); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "Invalid batch ID")
+		}
+	}
+
+	if len(batch.GetResults()) == 0 {
+		return &workerpb.SubmitResultsResponse{}, nil
+	}
+
+	logger := logging.Logger(ctx, "SubmitResults").With("job_id", id, "batch_id", batchID)
+
+	if err := s.jobs.AcceptResults(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, service.ErrJobNotFound):
+			return nil, status.Error(codes.NotFound, "Job not found")
+		case errors.Is(err, service.ErrJobFinished):
+			logger.Warn("rejected results of a finished job", "results", len(batch.GetResults()))
+			return nil, status.Error(codes.FailedPrecondition, "Job already finished")
+		default:
+			logger.Error("failed to get job", "error", err)
+			return nil, status.Error(codes.Internal, "Failed to save results")
+		}
+	}
+
+	// A batch crossing the tenant's quota is stored up to the cap; the
+	// progress below still has to reflect that part
+	err = s.results.CreateBatch(ctx, id, batchID, batch.GetResults())
+	if errors.Is(err, domain.ErrBatchAlreadyStored) {
+		logger.Info("result batch already stored")
+		return &workerpb.SubmitResultsResponse{AlreadyStored: true}, nil
+	}
+	quotaExceeded := errors.Is(err, domain.ErrQuotaExceeded)
+	if err != nil && !quotaExceeded {
+		logger.Error("failed to save results", "error", err)
+		return nil, status.Error(codes.Internal, "Failed to save results")
+	}
+
+	if progressErr := s.jobs.SyncProgress(ctx, id); progressErr != nil {
+		logger.Warn("failed to update progress", "error", progressErr)
+	}
+
+	if quotaExceeded {
+		logger.Warn("results stored up to the quota", "error", err)
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	logger.Info("results saved", "results", len(batch.GetResults()))
+
+	return &workerpb.SubmitResultsResponse{}, nil
+}
+
+// CompleteJob marks a job completed
+func (s *Server) CompleteJob(ctx context.Context, req *workerpb.CompleteJobRequest) (*workerpb.Empty, error) {
+	id, err := parseID(req.GetJobId())
+	if err != nil {
+		return nil, err
+	}
+
+	var report *domain.JobParseReport
+	if data := req.GetParseReportJson(); len(data) > 0 {
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid parse report: %v", err)
+		}
+	}
+
+	err = s.workers.CompleteJob(ctx, id, req.GetWorkerId(), int(req.GetPlacesScraped()), int(req.GetDedupedPlaces()),
+		req.GetFailedKeywords(), req.GetStoppedReason(), req.GetOutputErrors(), report)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to complete job: %v", err)
+	}
+
+	return &workerpb.Empty{}, nil
+}
+
+// FailJob marks a job failed
+func (s *Server) FailJob(ctx context.Context, req *workerpb.FailJobRequest) (*workerpb.Empty, error) {
+	id, err := parseID(req.GetJobId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.workers.FailJob(ctx, id, req.GetWorkerId(), int(req.GetDedupedPlaces()), req.GetMessage(), req.GetErrorCode(), req.GetFailedKeywords()); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to fail job: %v", err)
+	}
+
+	return &workerpb.Empty{}, nil
+}
+
+// ReleaseJob hands a job back to pending
+func (s *Server) ReleaseJob(ctx context.Context, req *workerpb.ReleaseJobRequest) (*workerpb.Empty, error) {
+	id, err := parseID(req.GetJobId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.workers.ReleaseJob(ctx, id, req.GetWorkerId()); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to release job: %v", err)
+	}
+
+	return &workerpb.Empty{}, nil
+}
+
+// Unregister removes a worker
+func (s *Server) Unregister(ctx context.Context, req *workerpb.UnregisterRequest) (*workerpb.Empty, error) {
+	if req.GetWorkerId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Worker ID is required")
+	}
+
+	if err := s.workers.Unregister(ctx, req.GetWorkerId()); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to unregister worker: %v", err)
+	}
+
+	return &workerpb.Empty{}, nil
+}
+
+func parseID(v string) (uuid.UUID, error) {
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "Invalid job ID")
+	}
+
+	return id, nil
+}
+
+// jobResponse encodes job, which may be nil
+func jobResponse(job *domain.Job) (*workerpb.JobResponse, error) {
+	if job == nil {
+		return &workerpb.JobResponse{}, nil
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to encode job: %v", err)
+	}
+
+	return &workerpb.JobResponse{JobJson: data}, nil
+}
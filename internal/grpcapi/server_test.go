@@ -0,0 +1,243 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/events"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// fakeManager is the manager's services for one pending job
+type fakeManager struct {
+	mu        sync.Mutex
+	job       *domain.Job
+	draining  bool
+	beats     []domain.WorkerHeartbeat
+	results   [][]byte
+	batches   map[uuid.UUID]bool
+	completed *domain.JobParseReport
+	progress  int
+}
+
+func (f *fakeManager) Register(_ context.Context, workerID string) (*domain.Worker, error) {
+	return &domain.Worker{ID: workerID, Status: domain.WorkerStatusIdle}, nil
+}
+
+func (f *fakeManager) Heartbeat(_ context.Context, hb *domain.WorkerHeartbeat) (*domain.WorkerDirectives, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.beats = append(f.beats, *hb)
+	if f.draining {
+		return &domain.WorkerDirectives{Drain: true}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeManager) ClaimJob(_ context.Context, workerID string) (*domain.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.job.Status != domain.JobStatusPending {
+		return nil, nil
+	}
+	f.job.Status = domain.JobStatusRunning
+	f.job.WorkerID = &workerID
+	job := *f.job
+	return &job, nil
+}
+
+func (f *fakeManager) ReleaseJob(context.Context, uuid.UUID, string) error { return nil }
+
+func (f *fakeManager) CompleteJob(_ context.Context, _ uuid.UUID, _ string, _, _ int, _ []string, _ string, _ []string, report *domain.JobParseReport) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.job.Status = domain.JobStatusCompleted
+	f.completed = report
+	return nil
+}
+
+func (f *fakeManager) FailJob(context.Context, uuid.UUID, string, int, string, string, []string) error {
+	return nil
+}
+
+func (f *fakeManager) Unregister(context.Context, string) error { return nil }
+
+func (f *fakeManager) GetByID(_ context.Context, _ uuid.UUID) (*domain.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	job := *f.job
+	return &job, nil
+}
+
+func (f *fakeManager) AcceptResults(context.Context, uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.job.AcceptsResults() {
+		return service.ErrJobFinished
+	}
+	return nil
+}
+
+func (f *fakeManager) SyncProgress(context.Context, uuid.UUID) error {
+	f.mu.Lock()
+	f.progress = len(f.results)
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeManager) CreateBatch(_ context.Context, _, batchID uuid.UUID, data [][]byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.batches[batchID] {
+		return domain.ErrBatchAlreadyStored
+	}
+	f.batches[batchID] = true
+	f.results = append(f.results, data...)
+	return nil
+}
+
+// serve starts a Server for f on a free port and returns its address
+func serve(t *testing.T, f *fakeManager, broker *events.MemoryBroker) string {
+	t.Helper()
+
+	srv := NewServer(Config{Token: "secret"}, f, f, f)
+	srv.SetEvents(broker)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, lis) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	return lis.Addr().String()
+}
+
+func TestJobLifecycle(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeManager{
+		job:     &domain.Job{ID: uuid.New(), Name: "cafes", Status: domain.JobStatusPending},
+		batches: make(map[uuid.UUID]bool),
+	}
+	broker := events.NewMemoryBroker()
+	addr := serve(t, f, broker)
+
+	c, err := NewClient(addr, "secret")
+	require.NoError(t, err)
+	defer c.Close()
+
+	directives := make(chan *domain.WorkerDirectives, 4)
+	c.OnDirectives(func(d *domain.WorkerDirectives) { directives <- d })
+
+	worker, err := c.Register(ctx, "w1")
+	require.NoError(t, err)
+	assert.Equal(t, "w1", worker.ID)
+
+	job, err := c.ClaimJob(ctx, "w1")
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, f.job.ID, job.ID)
+
+	again, err := c.ClaimJob(ctx, "w1")
+	require.NoError(t, err)
+	assert.Nil(t, again, "no pending job left")
+
+	require.NoError(t, c.Heartbeat(ctx, domain.WorkerHeartbeat{WorkerID: "w1", Status: domain.WorkerStatusBusy, CurrentJobID: &job.ID}))
+	require.Eventually(t, func() bool {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return len(f.beats) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A pause is pushed between heartbeats
+	broker.Publish(ctx, events.StatusEvent(job.ID, domain.JobStatusPaused, ""))
+	select {
+	case d := <-directives:
+		require.NotNil(t, d.StopJob)
+		assert.Equal(t, job.ID, d.StopJob.JobID)
+		assert.Equal(t, domain.JobStatusPaused, d.StopJob.Status)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no stop pushed")
+	}
+
+	// A drain answers the next heartbeat
+	f.mu.Lock()
+	f.draining = true
+	f.mu.Unlock()
+	require.NoError(t, c.Heartbeat(ctx, domain.WorkerHeartbeat{WorkerID: "w1", CurrentJobID: &job.ID}))
+	select {
+	case d := <-directives:
+		assert.True(t, d.Drain)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no drain directive")
+	}
+
+	batch := domain.ResultBatch{JobID: job.ID, BatchID: uuid.New(), Data: [][]byte{[]byte(`{"title":"a"}`), []byte(`{"title":"b"}`)}}
+	require.NoError(t, c.SubmitResults(ctx, batch))
+	require.NoError(t, c.SubmitResults(ctx, batch), "a retried batch is accepted")
+	assert.Len(t, f.results, 2)
+	assert.Equal(t, 2, f.progress)
+
+	fetched, err := c.GetJob(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobStatusRunning, fetched.Status)
+
+	report := &domain.JobParseReport{Places: 2}
+	require.NoError(t, c.CompleteJob(ctx, "w1", job.ID, 2, 0, nil, "", nil, report))
+	assert.Equal(t, domain.JobStatusCompleted, f.job.Status)
+	assert.Equal(t, report, f.completed)
+
+	late := domain.ResultBatch{JobID: job.ID, BatchID: uuid.New(), Data: [][]byte{[]byte(`{"title":"c"}`)}}
+	assert.True(t, IsRejected(c.SubmitResults(ctx, late)), "results of a finished job are rejected")
+	assert.Len(t, f.results, 2)
+
+	require.NoError(t, c.Unregister(ctx, "w1"))
+}
+
+func TestAuthentication(t *testing.T) {
+	f := &fakeManager{job: &domain.Job{ID: uuid.New()}, batches: make(map[uuid.UUID]bool)}
+	addr := serve(t, f, events.NewMemoryBroker())
+
+	c, err := NewClient(addr, "wrong")
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.Register(context.Background(), "w1")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.True(t, IsRejected(err))
+	assert.False(t, IsUnreachable(err))
+}
+
+func TestUnreachable(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	c, err := NewClient(addr, "")
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.ClaimJob(context.Background(), "w1")
+	assert.True(t, IsUnreachable(err), "got %v", err)
+}
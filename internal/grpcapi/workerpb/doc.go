@@ -0,0 +1,5 @@
+// Package workerpb is the gRPC protocol between workers and the manager,
+// generated from worker.proto
+package workerpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative worker.proto
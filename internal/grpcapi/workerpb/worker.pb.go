@@ -0,0 +1,1113 @@
+// Worker ↔ manager protocol over gRPC, the counterpart of the
+// /api/v2/workers and /api/v2/jobs/{id}/results HTTP endpoints. Jobs and
+// reports travel as the JSON documents of the HTTP API, so both transports
+// share one schema; result rows are the raw JSON the manager stores.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: worker.proto
+
+package workerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_worker_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{0}
+}
+
+type RegisterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkerId      string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterRequest) Reset() {
+	*x = RegisterRequest{}
+	mi := &file_worker_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRequest) ProtoMessage() {}
+
+func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRequest) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RegisterRequest) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+type RegisterResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkerJson    []byte                 `protobuf:"bytes,1,opt,name=worker_json,json=workerJson,proto3" json:"worker_json,omitempty"` // domain.Worker
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterResponse) Reset() {
+	*x = RegisterResponse{}
+	mi := &file_worker_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterResponse) ProtoMessage() {}
+
+func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
+func (*RegisterResponse) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RegisterResponse) GetWorkerJson() []byte {
+	if x != nil {
+		return x.WorkerJson
+	}
+	return nil
+}
+
+type HeartbeatRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	WorkerId          string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	Hostname          string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Status            string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	CurrentJobId      string                 `protobuf:"bytes,4,opt,name=current_job_id,json=currentJobId,proto3" json:"current_job_id,omitempty"`
+	CurrentJobName    string                 `protobuf:"bytes,5,opt,name=current_job_name,json=currentJobName,proto3" json:"current_job_name,omitempty"`
+	RequestDelayMs    int64                  `protobuf:"varint,6,opt,name=request_delay_ms,json=requestDelayMs,proto3" json:"request_delay_ms,omitempty"`
+	BlockCount        int64                  `protobuf:"varint,7,opt,name=block_count,json=blockCount,proto3" json:"block_count,omitempty"`
+	SeedJobsCompleted int32                  `protobuf:"varint,8,opt,name=seed_jobs_completed,json=seedJobsCompleted,proto3" json:"seed_jobs_completed,omitempty"`
+	SeedJobsTotal     int32                  `protobuf:"varint,9,opt,name=seed_jobs_total,json=seedJobsTotal,proto3" json:"seed_jobs_total,omitempty"`
+	JobPlacesScraped  int32                  `protobuf:"varint,10,opt,name=job_places_scraped,json=jobPlacesScraped,proto3" json:"job_places_scraped,omitempty"`
+	MemoryBytes       int64                  `protobuf:"varint,11,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
+	// Every job the worker runs, oldest first
+	Jobs          []*WorkerJob `protobuf:"bytes,12,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_worker_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HeartbeatRequest) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetCurrentJobId() string {
+	if x != nil {
+		return x.CurrentJobId
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetCurrentJobName() string {
+	if x != nil {
+		return x.CurrentJobName
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetRequestDelayMs() int64 {
+	if x != nil {
+		return x.RequestDelayMs
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetBlockCount() int64 {
+	if x != nil {
+		return x.BlockCount
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetSeedJobsCompleted() int32 {
+	if x != nil {
+		return x.SeedJobsCompleted
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetSeedJobsTotal() int32 {
+	if x != nil {
+		return x.SeedJobsTotal
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetJobPlacesScraped() int32 {
+	if x != nil {
+		return x.JobPlacesScraped
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetMemoryBytes() int64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetJobs() []*WorkerJob {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+type WorkerJob struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	JobId             string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	JobName           string                 `protobuf:"bytes,2,opt,name=job_name,json=jobName,proto3" json:"job_name,omitempty"`
+	SeedJobsCompleted int32                  `protobuf:"varint,3,opt,name=seed_jobs_completed,json=seedJobsCompleted,proto3" json:"seed_jobs_completed,omitempty"`
+	SeedJobsTotal     int32                  `protobuf:"varint,4,opt,name=seed_jobs_total,json=seedJobsTotal,proto3" json:"seed_jobs_total,omitempty"`
+	PlacesScraped     int32                  `protobuf:"varint,5,opt,name=places_scraped,json=placesScraped,proto3" json:"places_scraped,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *WorkerJob) Reset() {
+	*x = WorkerJob{}
+	mi := &file_worker_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerJob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerJob) ProtoMessage() {}
+
+func (x *WorkerJob) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerJob.ProtoReflect.Descriptor instead.
+func (*WorkerJob) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WorkerJob) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *WorkerJob) GetJobName() string {
+	if x != nil {
+		return x.JobName
+	}
+	return ""
+}
+
+func (x *WorkerJob) GetSeedJobsCompleted() int32 {
+	if x != nil {
+		return x.SeedJobsCompleted
+	}
+	return 0
+}
+
+func (x *WorkerJob) GetSeedJobsTotal() int32 {
+	if x != nil {
+		return x.SeedJobsTotal
+	}
+	return 0
+}
+
+func (x *WorkerJob) GetPlacesScraped() int32 {
+	if x != nil {
+		return x.PlacesScraped
+	}
+	return 0
+}
+
+type Directives struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Drain               bool                   `protobuf:"varint,1,opt,name=drain,proto3" json:"drain,omitempty"`
+	DrainTimeoutSeconds *int32                 `protobuf:"varint,2,opt,name=drain_timeout_seconds,json=drainTimeoutSeconds,proto3,oneof" json:"drain_timeout_seconds,omitempty"`
+	// Set when the job the worker runs was paused, cancelled or deleted
+	StopJobId     string `protobuf:"bytes,3,opt,name=stop_job_id,json=stopJobId,proto3" json:"stop_job_id,omitempty"`
+	StopJobStatus string `protobuf:"bytes,4,opt,name=stop_job_status,json=stopJobStatus,proto3" json:"stop_job_status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Directives) Reset() {
+	*x = Directives{}
+	mi := &file_worker_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Directives) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Directives) ProtoMessage() {}
+
+func (x *Directives) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Directives.ProtoReflect.Descriptor instead.
+func (*Directives) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Directives) GetDrain() bool {
+	if x != nil {
+		return x.Drain
+	}
+	return false
+}
+
+func (x *Directives) GetDrainTimeoutSeconds() int32 {
+	if x != nil && x.DrainTimeoutSeconds != nil {
+		return *x.DrainTimeoutSeconds
+	}
+	return 0
+}
+
+func (x *Directives) GetStopJobId() string {
+	if x != nil {
+		return x.StopJobId
+	}
+	return ""
+}
+
+func (x *Directives) GetStopJobStatus() string {
+	if x != nil {
+		return x.StopJobStatus
+	}
+	return ""
+}
+
+type ClaimJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkerId      string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClaimJobRequest) Reset() {
+	*x = ClaimJobRequest{}
+	mi := &file_worker_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClaimJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimJobRequest) ProtoMessage() {}
+
+func (x *ClaimJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimJobRequest.ProtoReflect.Descriptor instead.
+func (*ClaimJobRequest) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ClaimJobRequest) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+type GetJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_worker_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type JobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobJson       []byte                 `protobuf:"bytes,1,opt,name=job_json,json=jobJson,proto3" json:"job_json,omitempty"` // domain.Job, empty for none
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobResponse) Reset() {
+	*x = JobResponse{}
+	mi := &file_worker_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobResponse) ProtoMessage() {}
+
+func (x *JobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobResponse.ProtoReflect.Descriptor instead.
+func (*JobResponse) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *JobResponse) GetJobJson() []byte {
+	if x != nil {
+		return x.JobJson
+	}
+	return nil
+}
+
+type ResultBatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	BatchId       string                 `protobuf:"bytes,2,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	Results       [][]byte               `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResultBatch) Reset() {
+	*x = ResultBatch{}
+	mi := &file_worker_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResultBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultBatch) ProtoMessage() {}
+
+func (x *ResultBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultBatch.ProtoReflect.Descriptor instead.
+func (*ResultBatch) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ResultBatch) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *ResultBatch) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *ResultBatch) GetResults() [][]byte {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type SubmitResultsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AlreadyStored bool                   `protobuf:"varint,1,opt,name=already_stored,json=alreadyStored,proto3" json:"already_stored,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitResultsResponse) Reset() {
+	*x = SubmitResultsResponse{}
+	mi := &file_worker_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitResultsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitResultsResponse) ProtoMessage() {}
+
+func (x *SubmitResultsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitResultsResponse.ProtoReflect.Descriptor instead.
+func (*SubmitResultsResponse) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SubmitResultsResponse) GetAlreadyStored() bool {
+	if x != nil {
+		return x.AlreadyStored
+	}
+	return false
+}
+
+type CompleteJobRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	WorkerId        string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	JobId           string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	PlacesScraped   int32                  `protobuf:"varint,3,opt,name=places_scraped,json=placesScraped,proto3" json:"places_scraped,omitempty"`
+	DedupedPlaces   int32                  `protobuf:"varint,4,opt,name=deduped_places,json=dedupedPlaces,proto3" json:"deduped_places,omitempty"`
+	FailedKeywords  []string               `protobuf:"bytes,5,rep,name=failed_keywords,json=failedKeywords,proto3" json:"failed_keywords,omitempty"`
+	StoppedReason   string                 `protobuf:"bytes,6,opt,name=stopped_reason,json=stoppedReason,proto3" json:"stopped_reason,omitempty"`
+	OutputErrors    []string               `protobuf:"bytes,7,rep,name=output_errors,json=outputErrors,proto3" json:"output_errors,omitempty"`
+	ParseReportJson []byte                 `protobuf:"bytes,8,opt,name=parse_report_json,json=parseReportJson,proto3" json:"parse_report_json,omitempty"` // domain.JobParseReport, empty for none
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CompleteJobRequest) Reset() {
+	*x = CompleteJobRequest{}
+	mi := &file_worker_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteJobRequest) ProtoMessage() {}
+
+func (x *CompleteJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteJobRequest.ProtoReflect.Descriptor instead.
+func (*CompleteJobRequest) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CompleteJobRequest) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+func (x *CompleteJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *CompleteJobRequest) GetPlacesScraped() int32 {
+	if x != nil {
+		return x.PlacesScraped
+	}
+	return 0
+}
+
+func (x *CompleteJobRequest) GetDedupedPlaces() int32 {
+	if x != nil {
+		return x.DedupedPlaces
+	}
+	return 0
+}
+
+func (x *CompleteJobRequest) GetFailedKeywords() []string {
+	if x != nil {
+		return x.FailedKeywords
+	}
+	return nil
+}
+
+func (x *CompleteJobRequest) GetStoppedReason() string {
+	if x != nil {
+		return x.StoppedReason
+	}
+	return ""
+}
+
+func (x *CompleteJobRequest) GetOutputErrors() []string {
+	if x != nil {
+		return x.OutputErrors
+	}
+	return nil
+}
+
+func (x *CompleteJobRequest) GetParseReportJson() []byte {
+	if x != nil {
+		return x.ParseReportJson
+	}
+	return nil
+}
+
+type FailJobRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	WorkerId       string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	JobId          string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Message        string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	FailedKeywords []string               `protobuf:"bytes,4,rep,name=failed_keywords,json=failedKeywords,proto3" json:"failed_keywords,omitempty"`
+	DedupedPlaces  int32                  `protobuf:"varint,5,opt,name=deduped_places,json=dedupedPlaces,proto3" json:"deduped_places,omitempty"`
+	ErrorCode      string                 `protobuf:"bytes,6,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"` // domain.JobErrorCode, internal when empty
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *FailJobRequest) Reset() {
+	*x = FailJobRequest{}
+	mi := &file_worker_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FailJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailJobRequest) ProtoMessage() {}
+
+func (x *FailJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailJobRequest.ProtoReflect.Descriptor instead.
+func (*FailJobRequest) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *FailJobRequest) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+func (x *FailJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *FailJobRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *FailJobRequest) GetFailedKeywords() []string {
+	if x != nil {
+		return x.FailedKeywords
+	}
+	return nil
+}
+
+func (x *FailJobRequest) GetDedupedPlaces() int32 {
+	if x != nil {
+		return x.DedupedPlaces
+	}
+	return 0
+}
+
+func (x *FailJobRequest) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+type ReleaseJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkerId      string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseJobRequest) Reset() {
+	*x = ReleaseJobRequest{}
+	mi := &file_worker_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseJobRequest) ProtoMessage() {}
+
+func (x *ReleaseJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseJobRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseJobRequest) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ReleaseJobRequest) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+func (x *ReleaseJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type UnregisterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkerId      string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnregisterRequest) Reset() {
+	*x = UnregisterRequest{}
+	mi := &file_worker_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnregisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnregisterRequest) ProtoMessage() {}
+
+func (x *UnregisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnregisterRequest.ProtoReflect.Descriptor instead.
+func (*UnregisterRequest) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UnregisterRequest) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+var File_worker_proto protoreflect.FileDescriptor
+
+const file_worker_proto_rawDesc = "" +
+	"\n" +
+	"\fworker.proto\x12\x11scraper.worker.v1\"\a\n" +
+	"\x05Empty\".\n" +
+	"\x0fRegisterRequest\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\"3\n" +
+	"\x10RegisterResponse\x12\x1f\n" +
+	"\vworker_json\x18\x01 \x01(\fR\n" +
+	"workerJson\"\xd9\x03\n" +
+	"\x10HeartbeatRequest\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x1a\n" +
+	"\bhostname\x18\x02 \x01(\tR\bhostname\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12$\n" +
+	"\x0ecurrent_job_id\x18\x04 \x01(\tR\fcurrentJobId\x12(\n" +
+	"\x10current_job_name\x18\x05 \x01(\tR\x0ecurrentJobName\x12(\n" +
+	"\x10request_delay_ms\x18\x06 \x01(\x03R\x0erequestDelayMs\x12\x1f\n" +
+	"\vblock_count\x18\a \x01(\x03R\n" +
+	"blockCount\x12.\n" +
+	"\x13seed_jobs_completed\x18\b \x01(\x05R\x11seedJobsCompleted\x12&\n" +
+	"\x0fseed_jobs_total\x18\t \x01(\x05R\rseedJobsTotal\x12,\n" +
+	"\x12job_places_scraped\x18\n" +
+	" \x01(\x05R\x10jobPlacesScraped\x12!\n" +
+	"\fmemory_bytes\x18\v \x01(\x03R\vmemoryBytes\x120\n" +
+	"\x04jobs\x18\f \x03(\v2\x1c.scraper.worker.v1.WorkerJobR\x04jobs\"\xbc\x01\n" +
+	"\tWorkerJob\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
+	"\bjob_name\x18\x02 \x01(\tR\ajobName\x12.\n" +
+	"\x13seed_jobs_completed\x18\x03 \x01(\x05R\x11seedJobsCompleted\x12&\n" +
+	"\x0fseed_jobs_total\x18\x04 \x01(\x05R\rseedJobsTotal\x12%\n" +
+	"\x0eplaces_scraped\x18\x05 \x01(\x05R\rplacesScraped\"\xbd\x01\n" +
+	"\n" +
+	"Directives\x12\x14\n" +
+	"\x05drain\x18\x01 \x01(\bR\x05drain\x127\n" +
+	"\x15drain_timeout_seconds\x18\x02 \x01(\x05H\x00R\x13drainTimeoutSeconds\x88\x01\x01\x12\x1e\n" +
+	"\vstop_job_id\x18\x03 \x01(\tR\tstopJobId\x12&\n" +
+	"\x0fstop_job_status\x18\x04 \x01(\tR\rstopJobStatusB\x18\n" +
+	"\x16_drain_timeout_seconds\".\n" +
+	"\x0fClaimJobRequest\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\"&\n" +
+	"\rGetJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"(\n" +
+	"\vJobResponse\x12\x19\n" +
+	"\bjob_json\x18\x01 \x01(\fR\ajobJson\"Y\n" +
+	"\vResultBatch\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
+	"\bbatch_id\x18\x02 \x01(\tR\abatchId\x12\x18\n" +
+	"\aresults\x18\x03 \x03(\fR\aresults\">\n" +
+	"\x15SubmitResultsResponse\x12%\n" +
+	"\x0ealready_stored\x18\x01 \x01(\bR\ralreadyStored\"\xb7\x02\n" +
+	"\x12CompleteJobRequest\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\x12%\n" +
+	"\x0eplaces_scraped\x18\x03 \x01(\x05R\rplacesScraped\x12%\n" +
+	"\x0ededuped_places\x18\x04 \x01(\x05R\rdedupedPlaces\x12'\n" +
+	"\x0ffailed_keywords\x18\x05 \x03(\tR\x0efailedKeywords\x12%\n" +
+	"\x0estopped_reason\x18\x06 \x01(\tR\rstoppedReason\x12#\n" +
+	"\routput_errors\x18\a \x03(\tR\foutputErrors\x12*\n" +
+	"\x11parse_report_json\x18\b \x01(\fR\x0fparseReportJson\"\xcd\x01\n" +
+	"\x0eFailJobRequest\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12'\n" +
+	"\x0ffailed_keywords\x18\x04 \x03(\tR\x0efailedKeywords\x12%\n" +
+	"\x0ededuped_places\x18\x05 \x01(\x05R\rdedupedPlaces\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x06 \x01(\tR\terrorCode\"G\n" +
+	"\x11ReleaseJobRequest\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\"0\n" +
+	"\x11UnregisterRequest\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId2\xe4\x05\n" +
+	"\rWorkerService\x12S\n" +
+	"\bRegister\x12\".scraper.worker.v1.RegisterRequest\x1a#.scraper.worker.v1.RegisterResponse\x12S\n" +
+	"\tHeartbeat\x12#.scraper.worker.v1.HeartbeatRequest\x1a\x1d.scraper.worker.v1.Directives(\x010\x01\x12N\n" +
+	"\bClaimJob\x12\".scraper.worker.v1.ClaimJobRequest\x1a\x1e.scraper.worker.v1.JobResponse\x12J\n" +
+	"\x06GetJob\x12 .scraper.worker.v1.GetJobRequest\x1a\x1e.scraper.worker.v1.JobResponse\x12Y\n" +
+	"\rSubmitResults\x12\x1e.scraper.worker.v1.ResultBatch\x1a(.scraper.worker.v1.SubmitResultsResponse\x12N\n" +
+	"\vCompleteJob\x12%.scraper.worker.v1.CompleteJobRequest\x1a\x18.scraper.worker.v1.Empty\x12F\n" +
+	"\aFailJob\x12!.scraper.worker.v1.FailJobRequest\x1a\x18.scraper.worker.v1.Empty\x12L\n" +
+	"\n" +
+	"ReleaseJob\x12$.scraper.worker.v1.ReleaseJobRequest\x1a\x18.scraper.worker.v1.Empty\x12L\n" +
+	"\n" +
+	"Unregister\x12$.scraper.worker.v1.UnregisterRequest\x1a\x18.scraper.worker.v1.EmptyB?Z=github.com/sadewadee/google-scraper/internal/grpcapi/workerpbb\x06proto3"
+
+var (
+	file_worker_proto_rawDescOnce sync.Once
+	file_worker_proto_rawDescData []byte
+)
+
+func file_worker_proto_rawDescGZIP() []byte {
+	file_worker_proto_rawDescOnce.Do(func() {
+		file_worker_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_worker_proto_rawDesc), len(file_worker_proto_rawDesc)))
+	})
+	return file_worker_proto_rawDescData
+}
+
+var file_worker_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_worker_proto_goTypes = []any{
+	(*Empty)(nil),                 // 0: scraper.worker.v1.Empty
+	(*RegisterRequest)(nil),       // 1: scraper.worker.v1.RegisterRequest
+	(*RegisterResponse)(nil),      // 2: scraper.worker.v1.RegisterResponse
+	(*HeartbeatRequest)(nil),      // 3: scraper.worker.v1.HeartbeatRequest
+	(*WorkerJob)(nil),             // 4: scraper.worker.v1.WorkerJob
+	(*Directives)(nil),            // 5: scraper.worker.v1.Directives
+	(*ClaimJobRequest)(nil),       // 6: scraper.worker.v1.ClaimJobRequest
+	(*GetJobRequest)(nil),         // 7: scraper.worker.v1.GetJobRequest
+	(*JobResponse)(nil),           // 8: scraper.worker.v1.JobResponse
+	(*ResultBatch)(nil),           // 9: scraper.worker.v1.ResultBatch
+	(*SubmitResultsResponse)(nil), // 10: scraper.worker.v1.SubmitResultsResponse
+	(*CompleteJobRequest)(nil),    // 11: scraper.worker.v1.CompleteJobRequest
+	(*FailJobRequest)(nil),        // 12: scraper.worker.v1.FailJobRequest
+	(*ReleaseJobRequest)(nil),     // 13: scraper.worker.v1.ReleaseJobRequest
+	(*UnregisterRequest)(nil),     // 14: scraper.worker.v1.UnregisterRequest
+}
+var file_worker_proto_depIdxs = []int32{
+	4,  // 0: scraper.worker.v1.HeartbeatRequest.jobs:type_name -> scraper.worker.v1.WorkerJob
+	1,  // 1: scraper.worker.v1.WorkerService.Register:input_type -> scraper.worker.v1.RegisterRequest
+	3,  // 2: scraper.worker.v1.WorkerService.Heartbeat:input_type -> scraper.worker.v1.HeartbeatRequest
+	6,  // 3: scraper.worker.v1.WorkerService.ClaimJob:input_type -> scraper.worker.v1.ClaimJobRequest
+	7,  // 4: scraper.worker.v1.WorkerService.GetJob:input_type -> scraper.worker.v1.GetJobRequest
+	9,  // 5: scraper.worker.v1.WorkerService.SubmitResults:input_type -> scraper.worker.v1.ResultBatch
+	11, // 6: scraper.worker.v1.WorkerService.CompleteJob:input_type -> scraper.worker.v1.CompleteJobRequest
+	12, // 7: scraper.worker.v1.WorkerService.FailJob:input_type -> scraper.worker.v1.FailJobRequest
+	13, // 8: scraper.worker.v1.WorkerService.ReleaseJob:input_type -> scraper.worker.v1.ReleaseJobRequest
+	14, // 9: scraper.worker.v1.WorkerService.Unregister:input_type -> scraper.worker.v1.UnregisterRequest
+	2,  // 10: scraper.worker.v1.WorkerService.Register:output_type -> scraper.worker.v1.RegisterResponse
+	5,  // 11: scraper.worker.v1.WorkerService.Heartbeat:output_type -> scraper.worker.v1.Directives
+	8,  // 12: scraper.worker.v1.WorkerService.ClaimJob:output_type -> scraper.worker.v1.JobResponse
+	8,  // 13: scraper.worker.v1.WorkerService.GetJob:output_type -> scraper.worker.v1.JobResponse
+	10, // 14: scraper.worker.v1.WorkerService.SubmitResults:output_type -> scraper.worker.v1.SubmitResultsResponse
+	0,  // 15: scraper.worker.v1.WorkerService.CompleteJob:output_type -> scraper.worker.v1.Empty
+	0,  // 16: scraper.worker.v1.WorkerService.FailJob:output_type -> scraper.worker.v1.Empty
+	0,  // 17: scraper.worker.v1.WorkerService.ReleaseJob:output_type -> scraper.worker.v1.Empty
+	0,  // 18: scraper.worker.v1.WorkerService.Unregister:output_type -> scraper.worker.v1.Empty
+	10, // [10:19] is the sub-list for method output_type
+	1,  // [1:10] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_worker_proto_init() }
+func file_worker_proto_init() {
+	if File_worker_proto != nil {
+		return
+	}
+	file_worker_proto_msgTypes[5].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_worker_proto_rawDesc), len(file_worker_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_worker_proto_goTypes,
+		DependencyIndexes: file_worker_proto_depIdxs,
+		MessageInfos:      file_worker_proto_msgTypes,
+	}.Build()
+	File_worker_proto = out.File
+	file_worker_proto_goTypes = nil
+	file_worker_proto_depIdxs = nil
+}
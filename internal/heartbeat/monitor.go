@@ -6,17 +6,21 @@ import (
 	"time"
 
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/events"
 )
 
 // WorkerService defines methods needed for heartbeat monitoring
 type WorkerService interface {
-	MarkOfflineWorkers(ctx context.Context) (int, error)
+	MarkOfflineWorkers(ctx context.Context) ([]string, error)
+	ReleaseExpiredDrains(ctx context.Context) error
 }
 
-// Monitor monitors worker heartbeats and marks stale workers as offline
+// Monitor monitors worker heartbeats and marks stale workers as offline. It
+// also releases the jobs of draining workers past their deadline.
 type Monitor struct {
 	workers  WorkerService
 	interval time.Duration
+	events   events.WorkerPublisher // Announces workers marked offline (optional)
 }
 
 // NewMonitor creates a new heartbeat monitor
@@ -31,6 +35,12 @@ func NewMonitor(workers WorkerService, interval time.Duration) *Monitor {
 	}
 }
 
+// SetEvents publishes a worker_offline event for every worker the monitor
+// marks offline
+func (m *Monitor) SetEvents(p events.WorkerPublisher) {
+	m.events = p
+}
+
 // Run starts the heartbeat monitor
 func (m *Monitor) Run(ctx context.Context) error {
 	ticker := time.NewTicker(m.interval)
@@ -45,14 +55,24 @@ func (m *Monitor) Run(ctx context.Context) error {
 			log.Println("heartbeat monitor stopped")
 			return nil
 		case <-ticker.C:
-			count, err := m.workers.MarkOfflineWorkers(ctx)
+			ids, err := m.workers.MarkOfflineWorkers(ctx)
 			if err != nil {
 				log.Printf("error marking offline workers: %v", err)
 				continue
 			}
 
-			if count > 0 {
-				log.Printf("marked %d workers as offline", count)
+			if len(ids) > 0 {
+				log.Printf("marked %d workers as offline", len(ids))
+			}
+
+			if m.events != nil {
+				for _, id := range ids {
+					m.events.PublishWorker(ctx, events.WorkerEvent{Type: events.TypeWorkerOffline, WorkerID: id})
+				}
+			}
+
+			if err := m.workers.ReleaseExpiredDrains(ctx); err != nil {
+				log.Printf("error releasing jobs of expired drains: %v", err)
 			}
 		}
 	}
@@ -0,0 +1,283 @@
+// Package jobarchive reads and writes portable job archives, to move a job
+// with its results between managers. An archive is a tar.gz of
+//
+//	manifest.json           schema version and what the archive holds
+//	job.json                the job definition (domain.Job)
+//	results/000001.ndjson   raw result rows, one JSON document per line
+//
+// in that order. Results are split into files of at most ChunkRows rows so
+// neither side holds more than one file in memory.
+package jobarchive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// SchemaVersion is the version of the archive layout and of the job and
+// result JSON it holds. Archives of another version are rejected.
+const SchemaVersion = 1
+
+// ChunkRows caps the results of one results file
+const ChunkRows = 1000
+
+const (
+	manifestName = "manifest.json"
+	jobName      = "job.json"
+	resultsDir   = "results/"
+)
+
+var (
+	// ErrSchemaVersion is returned for an archive of another SchemaVersion
+	ErrSchemaVersion = errors.New("unsupported job archive schema version")
+
+	// ErrMalformed is returned for input that is not a job archive or is
+	// truncated
+	ErrMalformed = errors.New("malformed job archive")
+)
+
+// Manifest describes an archive
+type Manifest struct {
+	SchemaVersion int       `json:"schema_version"`
+	ExportedAt    time.Time `json:"exported_at"`
+	JobID         uuid.UUID `json:"job_id"`  // ID of the job on the manager it was exported from
+	Results       int       `json:"results"` // Result rows in the archive
+}
+
+// Writer streams an archive
+type Writer struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+
+	modTime time.Time
+	chunk   bytes.Buffer
+	rows    int // Rows in chunk
+	chunks  int // Chunks written
+	results int
+}
+
+// NewWriter starts an archive of job on w with m, whose SchemaVersion and
+// ExportedAt are filled in. m.Results must be the number of results that
+// will be written.
+func NewWriter(w io.Writer, m Manifest, job *domain.Job) (*Writer, error) {
+	m.SchemaVersion = SchemaVersion
+	if m.ExportedAt.IsZero() {
+		m.ExportedAt = time.Now().UTC()
+	}
+	m.JobID = job.ID
+
+	gz := gzip.NewWriter(w)
+	aw := &Writer{gz: gz, tw: tar.NewWriter(gz), modTime: m.ExportedAt}
+
+	for _, entry := range []struct {
+		name string
+		v    any
+	}{{manifestName, m}, {jobName, job}} {
+		data, err := json.MarshalIndent(entry.v, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", entry.name, err)
+		}
+		if err := aw.writeFile(entry.name, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return aw, nil
+}
+
+// WriteResult adds a raw result row
+func (w *Writer) WriteResult(data []byte) error {
+	if err := json.Compact(&w.chunk, data); err != nil {
+		return fmt.Errorf("invalid result %d: %w", w.results+1, err)
+	}
+	w.chunk.WriteByte('\n')
+	w.rows++
+	w.results++
+
+	if w.rows >= ChunkRows {
+		return w.flush()
+	}
+
+	return nil
+}
+
+// Results returns the number of results written so far
+func (w *Writer) Results() int {
+	return w.results
+}
+
+// Close writes the last results file and ends the archive. It does not
+// close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if err := w.tw.Close(); err != nil {
+		return fmt.Errorf("failed to close tar: %w", err)
+	}
+	if err := w.gz.Close(); err != nil {
+		return fmt.Errorf("failed to close gzip: %w", err)
+	}
+
+	return nil
+}
+
+func (w *Writer) flush() error {
+	if w.rows == 0 {
+		return nil
+	}
+
+	w.chunks++
+	if err := w.writeFile(fmt.Sprintf("%s%06d.ndjson", resultsDir, w.chunks), w.chunk.Bytes()); err != nil {
+		return err
+	}
+
+	w.chunk.Reset()
+	w.rows = 0
+
+	return nil
+}
+
+func (w *Writer) writeFile(name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: w.modTime,
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := w.tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Reader reads an archive. The manifest and job are read by NewReader, the
+// results by Results.
+type Reader struct {
+	Manifest Manifest
+	Job      *domain.Job
+
+	tr *tar.Reader
+}
+
+// NewReader reads the manifest and job of the archive in r. An archive of
+// another schema version returns ErrSchemaVersion before anything else is
+// read.
+func NewReader(r io.Reader) (*Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	ar := &Reader{tr: tar.NewReader(gz)}
+
+	if err := ar.readJSON(manifestName, &ar.Manifest); err != nil {
+		return nil, err
+	}
+	if ar.Manifest.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("%w: archive has version %d, this manager reads %d",
+			ErrSchemaVersion, ar.Manifest.SchemaVersion, SchemaVersion)
+	}
+
+	if err := ar.readJSON(jobName, &ar.Job); err != nil {
+		return nil, err
+	}
+
+	return ar, nil
+}
+
+// Results calls fn with the rows of each results file in turn and returns
+// the number of rows read. An error returned by fn stops reading. Fewer
+// rows than the manifest lists mean a truncated archive and return
+// ErrMalformed.
+func (r *Reader) Results(fn func(rows [][]byte) error) (int, error) {
+	read := 0
+
+	for {
+		hdr, err := r.tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return read, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		if !strings.HasPrefix(hdr.Name, resultsDir) {
+			return read, fmt.Errorf("%w: unexpected file %s", ErrMalformed, hdr.Name)
+		}
+
+		rows, err := readRows(r.tr)
+		if err != nil {
+			return read, fmt.Errorf("%w: %s: %v", ErrMalformed, hdr.Name, err)
+		}
+		if read+len(rows) > r.Manifest.Results {
+			return read, fmt.Errorf("%w: more results than the %d of the manifest", ErrMalformed, r.Manifest.Results)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		if err := fn(rows); err != nil {
+			return read, err
+		}
+		read += len(rows)
+	}
+
+	if read != r.Manifest.Results {
+		return read, fmt.Errorf("%w: %d of the %d results of the manifest", ErrMalformed, read, r.Manifest.Results)
+	}
+
+	return read, nil
+}
+
+func (r *Reader) readJSON(name string, v any) error {
+	hdr, err := r.tr.Next()
+	if err != nil {
+		return fmt.Errorf("%w: missing %s: %v", ErrMalformed, name, err)
+	}
+	if hdr.Name != name {
+		return fmt.Errorf("%w: expected %s, found %s", ErrMalformed, name, hdr.Name)
+	}
+	if err := json.NewDecoder(r.tr).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrMalformed, name, err)
+	}
+
+	return nil
+}
+
+// readRows splits an NDJSON file into its rows, checking each is JSON
+func readRows(r io.Reader) ([][]byte, error) {
+	var rows [][]byte
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if !json.Valid(line) {
+				return nil, fmt.Errorf("row %d is not JSON", len(rows)+1)
+			}
+			rows = append(rows, line)
+		}
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
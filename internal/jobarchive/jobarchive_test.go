@@ -0,0 +1,85 @@
+package jobarchive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+func writeArchive(t *testing.T, job *domain.Job, results int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Manifest{Results: results}, job)
+	require.NoError(t, err)
+	for i := 0; i < results; i++ {
+		require.NoError(t, w.WriteResult([]byte(fmt.Sprintf("{\n  \"title\": \"place %d\"\n}", i))))
+	}
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	job := &domain.Job{ID: uuid.New(), Name: "coffee", Status: domain.JobStatusCompleted}
+	archive := writeArchive(t, job, ChunkRows+5)
+
+	r, err := NewReader(bytes.NewReader(archive))
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, r.Manifest.SchemaVersion)
+	assert.Equal(t, job.ID, r.Manifest.JobID)
+	assert.Equal(t, "coffee", r.Job.Name)
+
+	var chunks []int
+	var last []byte
+	n, err := r.Results(func(rows [][]byte) error {
+		chunks = append(chunks, len(rows))
+		last = rows[len(rows)-1]
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ChunkRows+5, n)
+	assert.Equal(t, []int{ChunkRows, 5}, chunks)
+	assert.JSONEq(t, fmt.Sprintf(`{"title": "place %d"}`, ChunkRows+4), string(last))
+}
+
+func TestReaderRejectsOtherVersions(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	data, _ := json.Marshal(Manifest{SchemaVersion: SchemaVersion + 1})
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: manifestName, Mode: 0o644, Size: int64(len(data))}))
+	_, _ = tw.Write(data)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	_, err := NewReader(&buf)
+	assert.ErrorIs(t, err, ErrSchemaVersion)
+
+	_, err = NewReader(bytes.NewReader([]byte("not an archive")))
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestReaderDetectsMissingResults(t *testing.T) {
+	job := &domain.Job{ID: uuid.New(), Name: "coffee"}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Manifest{Results: 3}, job)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteResult([]byte(`{}`)))
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(&buf)
+	require.NoError(t, err)
+	_, err = r.Results(func([][]byte) error { return nil })
+	assert.ErrorIs(t, err, ErrMalformed)
+}
@@ -0,0 +1,321 @@
+// Package leader elects one manager replica to run the background tasks
+// that must not run twice, such as the heartbeat monitor and the worker
+// auto-scaler. The leader holds a lease lock and renews it; the other
+// replicas keep trying to take it and only serve API traffic meanwhile.
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// DefaultTTL is how long the lock outlives its last renewal
+const DefaultTTL = 15 * time.Second
+
+// Locker is a named lease lock. A lock that is not renewed within its TTL
+// is free again, so a replica that dies loses it on its own.
+type Locker interface {
+	// Acquire takes the lock for holder, or extends it when holder has it
+	// already, and reports whether holder has it
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+
+	// Release frees the lock if holder has it
+	Release(ctx context.Context, name, holder string) error
+
+	// Holder returns the holder of the lock, "" when it is free
+	Holder(ctx context.Context, name string) (string, error)
+}
+
+// Config holds the settings of an Elector
+type Config struct {
+	Name    string        // Lock name, shared by the replicas
+	ID      string        // This replica, defaults to NewReplicaID()
+	TTL     time.Duration // Defaults to DefaultTTL
+	Backend string        // Reported by Status, e.g. domain.LockBackendRedis
+}
+
+type task struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// Elector runs its tasks while this replica holds the lock. The lock is
+// renewed every TTL/3. When it is lost, or cannot be renewed before it
+// would expire, the tasks' context is cancelled and Elector waits for them
+// to return before it tries to take the lock again.
+type Elector struct {
+	locker Locker
+	cfg    Config
+	renew  time.Duration
+	now    func() time.Time
+
+	tasks  []task
+	failed chan error
+
+	// Owned by Run
+	expires time.Time
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	mu    sync.RWMutex
+	since time.Time // Zero when not leading
+}
+
+// New creates an Elector. Tasks are added with Go before Run.
+func New(locker Locker, cfg Config) *Elector {
+	if cfg.ID == "" {
+		cfg.ID = NewReplicaID()
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultTTL
+	}
+
+	return &Elector{
+		locker: locker,
+		cfg:    cfg,
+		renew:  cfg.TTL / 3,
+		now:    time.Now,
+		failed: make(chan error, 1),
+	}
+}
+
+// NewReplicaID names a replica after its host, with a random suffix so
+// that restarts and containers sharing a hostname stay apart
+func NewReplicaID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "manager"
+	}
+
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+
+	return hostname + "-" + hex.EncodeToString(b)
+}
+
+// ID returns the ID of this replica
+func (e *Elector) ID() string {
+	return e.cfg.ID
+}
+
+// Go adds a task run only by the leader. It is started every time this
+// replica becomes leader and must return promptly once its context is
+// cancelled. An error other than the cancellation stops Run.
+func (e *Elector) Go(name string, run func(ctx context.Context) error) {
+	e.tasks = append(e.tasks, task{name: name, run: run})
+}
+
+// IsLeader reports whether this replica holds the lock
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return !e.since.IsZero()
+}
+
+// Run competes for the lock until ctx is cancelled, then stops the tasks
+// and releases the lock so another replica takes over at once
+func (e *Elector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.renew)
+	defer ticker.Stop()
+
+	defer func() {
+		if !e.IsLeader() {
+			return
+		}
+
+		e.stepDown("shutting down")
+
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := e.locker.Release(releaseCtx, e.cfg.Name, e.cfg.ID); err != nil {
+			log.Printf("leader: failed to release lock %s: %v", e.cfg.Name, err)
+		}
+	}()
+
+	log.Printf("leader: replica %s competing for lock %s (ttl: %s)", e.cfg.ID, e.cfg.Name, e.cfg.TTL)
+
+	for {
+		e.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-e.failed:
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Elector) tick(ctx context.Context) {
+	start := e.now()
+
+	acquireCtx, cancel := context.WithTimeout(ctx, e.renew)
+	ok, err := e.locker.Acquire(acquireCtx, e.cfg.Name, e.cfg.ID, e.cfg.TTL)
+	cancel()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	switch {
+	case err != nil:
+		log.Printf("leader: failed to renew lock %s: %v", e.cfg.Name, err)
+
+		// Another replica may take the lock once it expires; stop while
+		// the next renewal, give or take some tick jitter, could come too
+		// late
+		if e.IsLeader() && !e.now().Add(e.renew).Before(e.expires.Add(-e.renew/2)) {
+			e.stepDown("lock could not be renewed")
+		}
+	case ok:
+		// Measured from before the call, the lock may have been set then
+		e.expires = start.Add(e.cfg.TTL)
+		if !e.IsLeader() {
+			e.stepUp(ctx)
+		}
+	default:
+		if e.IsLeader() {
+			e.stepDown("lock taken by another replica")
+		}
+	}
+}
+
+func (e *Elector) stepUp(ctx context.Context) {
+	log.Printf("leader: replica %s is leader, starting %d tasks", e.cfg.ID, len(e.tasks))
+
+	e.mu.Lock()
+	e.since = e.now().UTC()
+	e.mu.Unlock()
+
+	taskCtx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	for _, t := range e.tasks {
+		e.wg.Add(1)
+
+		go func() {
+			defer e.wg.Done()
+
+			err := t.run(taskCtx)
+			if err == nil || taskCtx.Err() != nil {
+				return
+			}
+
+			select {
+			case e.failed <- fmt.Errorf("%s: %w", t.name, err):
+			default:
+			}
+		}()
+	}
+}
+
+func (e *Elector) stepDown(reason string) {
+	log.Printf("leader: replica %s stops leading: %s", e.cfg.ID, reason)
+
+	e.cancel()
+	e.wg.Wait()
+
+	e.mu.Lock()
+	e.since = time.Time{}
+	e.mu.Unlock()
+}
+
+// Status reports the election state of this replica
+func (e *Elector) Status(ctx context.Context) *domain.ClusterStatus {
+	status := &domain.ClusterStatus{
+		ReplicaID:      e.cfg.ID,
+		LockBackend:    e.cfg.Backend,
+		LockTTLSeconds: int(e.cfg.TTL / time.Second),
+		Tasks:          make([]string, 0, len(e.tasks)),
+	}
+
+	for _, t := range e.tasks {
+		status.Tasks = append(status.Tasks, t.name)
+	}
+
+	e.mu.RLock()
+	if !e.since.IsZero() {
+		since := e.since
+		status.Leader = true
+		status.LeaderSince = &since
+	}
+	e.mu.RUnlock()
+
+	holder, err := e.locker.Holder(ctx, e.cfg.Name)
+	if err != nil {
+		log.Printf("leader: failed to read holder of lock %s: %v", e.cfg.Name, err)
+	}
+	status.LeaderID = holder
+
+	return status
+}
+
+// MemoryLocker keeps locks in process. It only coordinates a single
+// replica, and stands in for Redis and PostgreSQL in tests.
+type MemoryLocker struct {
+	mu    sync.Mutex
+	locks map[string]memoryLock
+	now   func() time.Time
+}
+
+type memoryLock struct {
+	holder  string
+	expires time.Time
+}
+
+// NewMemoryLocker creates an in-process Locker
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{locks: make(map[string]memoryLock), now: time.Now}
+}
+
+// Acquire implements Locker
+func (m *MemoryLocker) Acquire(_ context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+
+	if l, ok := m.locks[name]; ok && l.holder != holder && now.Before(l.expires) {
+		return false, nil
+	}
+
+	m.locks[name] = memoryLock{holder: holder, expires: now.Add(ttl)}
+
+	return true, nil
+}
+
+// Release implements Locker
+func (m *MemoryLocker) Release(_ context.Context, name, holder string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if l, ok := m.locks[name]; ok && l.holder == holder {
+		delete(m.locks, name)
+	}
+
+	return nil
+}
+
+// Holder implements Locker
+func (m *MemoryLocker) Holder(_ context.Context, name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.locks[name]
+	if !ok || !m.now().Before(l.expires) {
+		return "", nil
+	}
+
+	return l.holder, nil
+}
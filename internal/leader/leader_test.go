@@ -0,0 +1,158 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTTL = 300 * time.Millisecond
+
+// flakyLocker fails every call while fail is set, like an unreachable Redis
+type flakyLocker struct {
+	Locker
+	fail atomic.Bool
+}
+
+func (f *flakyLocker) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	if f.fail.Load() {
+		return false, errors.New("connection refused")
+	}
+	return f.Locker.Acquire(ctx, name, holder, ttl)
+}
+
+// singleton records the replicas running a task, which must never overlap
+type singleton struct {
+	mu      sync.Mutex
+	running map[string]bool
+	overlap bool
+	starts  []string
+}
+
+func (s *singleton) task(id string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		s.mu.Lock()
+		if len(s.running) > 0 {
+			s.overlap = true
+		}
+		s.running[id] = true
+		s.starts = append(s.starts, id)
+		s.mu.Unlock()
+
+		<-ctx.Done()
+
+		s.mu.Lock()
+		delete(s.running, id)
+		s.mu.Unlock()
+
+		return nil
+	}
+}
+
+func (s *singleton) startedBy() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.starts...)
+}
+
+// startElector runs an elector until the test ends or stop is called
+func startElector(t *testing.T, locker Locker, id string, s *singleton) (e *Elector, stop func() error) {
+	t.Helper()
+
+	e = New(locker, Config{Name: "test", ID: id, TTL: testTTL})
+	e.Go("task", s.task(id))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- e.Run(ctx) }()
+
+	stop = sync.OnceValue(func() error {
+		cancel()
+		return <-done
+	})
+	t.Cleanup(func() { _ = stop() })
+
+	return e, stop
+}
+
+func TestElectorFailover(t *testing.T) {
+	locker := NewMemoryLocker()
+	flaky := &flakyLocker{Locker: locker}
+	s := &singleton{running: map[string]bool{}}
+
+	a, _ := startElector(t, flaky, "a", s)
+	require.Eventually(t, a.IsLeader, time.Second, 5*time.Millisecond)
+
+	b, _ := startElector(t, locker, "b", s)
+	time.Sleep(2 * testTTL)
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+
+	// a cannot renew: it stops before its lock expires, b takes over after
+	flaky.fail.Store(true)
+	require.Eventually(t, b.IsLeader, 3*testTTL, 5*time.Millisecond)
+	assert.False(t, a.IsLeader())
+
+	assert.Equal(t, []string{"a", "b"}, s.startedBy())
+	assert.False(t, s.overlap, "the task ran on both replicas at once")
+
+	status := b.Status(context.Background())
+	assert.Equal(t, "b", status.LeaderID)
+	assert.True(t, status.Leader)
+	assert.Equal(t, []string{"task"}, status.Tasks)
+}
+
+func TestElectorStopsWhenLockIsTaken(t *testing.T) {
+	locker := NewMemoryLocker()
+	s := &singleton{running: map[string]bool{}}
+
+	a, _ := startElector(t, locker, "a", s)
+	require.Eventually(t, a.IsLeader, time.Second, 5*time.Millisecond)
+
+	// The lock expired while a was paused and another replica took it
+	locker.mu.Lock()
+	locker.locks["test"] = memoryLock{holder: "other", expires: time.Now().Add(time.Hour)}
+	locker.mu.Unlock()
+
+	// Stopped at the next renewal, within the TTL
+	require.Eventually(t, func() bool { return !a.IsLeader() }, testTTL, time.Millisecond)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assert.Empty(t, s.running)
+}
+
+func TestElectorReleasesOnShutdown(t *testing.T) {
+	locker := NewMemoryLocker()
+	s := &singleton{running: map[string]bool{}}
+
+	a, stopA := startElector(t, locker, "a", s)
+	require.Eventually(t, a.IsLeader, time.Second, 5*time.Millisecond)
+
+	b, _ := startElector(t, locker, "b", s)
+
+	require.NoError(t, stopA())
+
+	holder, err := locker.Holder(context.Background(), "test")
+	require.NoError(t, err)
+	assert.NotEqual(t, "a", holder)
+
+	// b does not wait for the TTL
+	require.Eventually(t, b.IsLeader, testTTL/2, time.Millisecond)
+}
+
+func TestElectorTaskError(t *testing.T) {
+	e := New(NewMemoryLocker(), Config{Name: "test", ID: "a", TTL: testTTL})
+	e.Go("broken", func(context.Context) error { return errors.New("boom") })
+
+	err := e.Run(context.Background())
+	require.EqualError(t, err, "broken: boom")
+	assert.False(t, e.IsLeader())
+}
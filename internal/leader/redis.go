@@ -0,0 +1,110 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix namespaces the lock keys
+const redisKeyPrefix = "leader:"
+
+// acquireScript extends the lock when the holder has it, else takes it if
+// it is free (SET NX PX)
+var acquireScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 1
+end
+return 0
+`)
+
+// releaseScript deletes the lock only if the holder has it
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisConfig holds the Redis connection for RedisLocker
+type RedisConfig struct {
+	RedisURL  string
+	RedisAddr string
+	Password  string
+	DB        int
+}
+
+// RedisLocker keeps locks as Redis keys with a TTL
+type RedisLocker struct {
+	client *redis.Client
+}
+
+// NewRedisLocker connects to Redis
+func NewRedisLocker(cfg RedisConfig) (*RedisLocker, error) {
+	var opts *redis.Options
+
+	switch {
+	case cfg.RedisURL != "":
+		o, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse redis URL: %w", err)
+		}
+		opts = o
+	case cfg.RedisAddr != "":
+		opts = &redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+		}
+	default:
+		return nil, fmt.Errorf("redis URL or address is required")
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("redis connection failed: %w", err)
+	}
+
+	return &RedisLocker{client: client}, nil
+}
+
+// Acquire implements Locker
+func (l *RedisLocker) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	n, err := acquireScript.Run(ctx, l.client, []string{redisKeyPrefix + name}, holder, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// Release implements Locker
+func (l *RedisLocker) Release(ctx context.Context, name, holder string) error {
+	return releaseScript.Run(ctx, l.client, []string{redisKeyPrefix + name}, holder).Err()
+}
+
+// Holder implements Locker
+func (l *RedisLocker) Holder(ctx context.Context, name string) (string, error) {
+	holder, err := l.client.Get(ctx, redisKeyPrefix+name).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+
+	return holder, err
+}
+
+// Close closes the Redis connection
+func (l *RedisLocker) Close() error {
+	return l.client.Close()
+}
@@ -3,29 +3,38 @@ package mq
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// ErrRequeue is returned by a handler to put its message straight back on
+// the queue for another worker, without counting a retry
+var ErrRequeue = errors.New("requeue job")
+
 // RabbitMQConsumer implements Consumer interface
 type RabbitMQConsumer struct {
-	conn       *amqp.Connection
-	channel    *amqp.Channel
-	queues     []string
-	prefetch   int
-	consumerID string
+	conn        *amqp.Connection
+	channel     *amqp.Channel
+	queues      []string
+	prefetch    int
+	concurrency int
+	consumerID  string
+	paused      atomic.Bool
 }
 
 // ConsumerConfig holds consumer configuration
 type ConsumerConfig struct {
-	URL        string
-	Prefetch   int    // Number of messages to prefetch (default: 10)
-	ConsumerID string // Unique consumer identifier
-	Queues     []string // Queues to consume from (default: all priority queues)
+	URL         string
+	Prefetch    int      // Number of messages to prefetch (default: 10)
+	Concurrency int      // Messages handled at once (default: 1)
+	ConsumerID  string   // Unique consumer identifier
+	Queues      []string // Queues to consume from (default: all priority queues)
 }
 
 // NewConsumer creates a new RabbitMQ consumer
@@ -80,11 +89,12 @@ func NewConsumer(cfg ConsumerConfig) (*RabbitMQConsumer, error) {
 	}
 
 	return &RabbitMQConsumer{
-		conn:       conn,
-		channel:    ch,
-		queues:     queues,
-		prefetch:   prefetch,
-		consumerID: consumerID,
+		conn:        conn,
+		channel:     ch,
+		queues:      queues,
+		prefetch:    prefetch,
+		concurrency: max(cfg.Concurrency, 1),
+		consumerID:  consumerID,
 	}, nil
 }
 
@@ -96,7 +106,7 @@ func (c *RabbitMQConsumer) Consume(ctx context.Context, handler func(context.Con
 	for _, qName := range c.queues {
 		deliveries, err := c.channel.Consume(
 			qName,
-			fmt.Sprintf("%s-%s", c.consumerID, qName),
+			c.consumerTag(qName),
 			false, // auto-ack (we manually ack)
 			false, // exclusive
 			false, // no-local
@@ -112,12 +122,13 @@ func (c *RabbitMQConsumer) Consume(ctx context.Context, handler func(context.Con
 	// Merge all delivery channels with context for clean shutdown
 	merged := mergeChannelsWithContext(ctx, deliveryChannels...)
 
-	// Retry backoff configuration
-	const (
-		initialBackoff = 1 * time.Second
-		maxBackoff     = 30 * time.Second
-		maxRetries     = 5
+	// Up to concurrency handlers run at once; Consume returns once they
+	// all have
+	var (
+		wg    sync.WaitGroup
+		slots = make(chan struct{}, c.concurrency)
 	)
+	defer wg.Wait()
 
 	for {
 		select {
@@ -126,90 +137,153 @@ func (c *RabbitMQConsumer) Consume(ctx context.Context, handler func(context.Con
 
 		case d, ok := <-merged:
 			if !ok {
+				if c.paused.Load() {
+					return nil
+				}
 				return fmt.Errorf("delivery channel closed")
 			}
 
-			var msg JobMessage
-			if err := json.Unmarshal(d.Body, &msg); err != nil {
-				log.Printf("[Consumer] Failed to unmarshal message: %v", err)
-				// Reject and don't requeue malformed messages
-				d.Reject(false)
+			// Delivered before the broker saw the pause
+			if c.paused.Load() {
+				d.Nack(false, true)
 				continue
 			}
 
-			// Track retry count from message header
-			retryCount := 0
-			if d.Headers != nil {
-				if count, ok := d.Headers["x-retry-count"].(int64); ok {
-					retryCount = int(count)
-				} else if count, ok := d.Headers["x-retry-count"].(int32); ok {
-					retryCount = int(count)
-				}
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				d.Nack(false, true)
+				return ctx.Err()
 			}
 
-			// Process the message
-			if err := handler(ctx, &msg); err != nil {
-				log.Printf("[Consumer] Handler failed for job %s (retry %d/%d): %v", msg.JobID, retryCount, maxRetries, err)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+				c.deliver(ctx, d, handler)
+			}()
+		}
+	}
+}
 
-				if retryCount >= maxRetries {
-					// Max retries exceeded, dead-letter the message
-					log.Printf("[Consumer] Max retries exceeded for job %s, rejecting without requeue", msg.JobID)
-					d.Reject(false)
-					continue
-				}
+// deliver hands a message to handler, then acknowledges it, requeues it or
+// republishes it for a retry
+func (c *RabbitMQConsumer) deliver(ctx context.Context, d amqp.Delivery, handler func(context.Context, *JobMessage) error) {
+	// Retry backoff configuration
+	const (
+		initialBackoff = 1 * time.Second
+		maxBackoff     = 30 * time.Second
+		maxRetries     = 5
+	)
 
-				// Calculate backoff with exponential increase
-				backoff := initialBackoff * time.Duration(1<<uint(retryCount))
-				if backoff > maxBackoff {
-					backoff = maxBackoff
-				}
+	var msg JobMessage
+	if err := json.Unmarshal(d.Body, &msg); err != nil {
+		log.Printf("[Consumer] Failed to unmarshal message: %v", err)
+		// Reject and don't requeue malformed messages
+		d.Reject(false)
+		return
+	}
 
-				log.Printf("[Consumer] Waiting %v before republishing job %s (retry %d)", backoff, msg.JobID, retryCount+1)
+	// Track retry count from message header
+	retryCount := 0
+	if d.Headers != nil {
+		if count, ok := d.Headers["x-retry-count"].(int64); ok {
+			retryCount = int(count)
+		} else if count, ok := d.Headers["x-retry-count"].(int32); ok {
+			retryCount = int(count)
+		}
+	}
 
-				// Wait before republishing (prevents tight retry loop)
-				select {
-				case <-ctx.Done():
-					// Context cancelled during backoff, reject without requeue
-					d.Reject(false)
-					return ctx.Err()
-				case <-time.After(backoff):
-					// Republish with incremented retry count header
-					// Native requeue doesn't preserve headers, so we republish manually
-					headers := amqp.Table{
-						"x-retry-count": int64(retryCount + 1),
-					}
+	// Process the message
+	err := handler(ctx, &msg)
+	if err == nil {
+		// Acknowledge successful processing
+		if err := d.Ack(false); err != nil {
+			log.Printf("[Consumer] Ack failed for job %s: %v", msg.JobID, err)
+		}
+		return
+	}
 
-					err := c.channel.PublishWithContext(ctx,
-						"",           // exchange (use default)
-						d.RoutingKey, // routing key = queue name
-						false,        // mandatory
-						false,        // immediate
-						amqp.Publishing{
-							ContentType:  "application/json",
-							DeliveryMode: amqp.Persistent,
-							Headers:      headers,
-							Body:         d.Body,
-						},
-					)
-					if err != nil {
-						log.Printf("[Consumer] Failed to republish job %s: %v, rejecting without requeue", msg.JobID, err)
-						d.Reject(false)
-					} else {
-						// Acknowledge original message since we've republished
-						d.Ack(false)
-					}
-				}
-				continue
-			}
+	if errors.Is(err, ErrRequeue) {
+		log.Printf("[Consumer] Requeueing job %s: %v", msg.JobID, err)
+		d.Nack(false, true)
+		return
+	}
 
-			// Acknowledge successful processing
-			if err := d.Ack(false); err != nil {
-				log.Printf("[Consumer] Ack failed for job %s: %v", msg.JobID, err)
-			}
+	log.Printf("[Consumer] Handler failed for job %s (retry %d/%d): %v", msg.JobID, retryCount, maxRetries, err)
+
+	if retryCount >= maxRetries {
+		// Max retries exceeded, dead-letter the message
+		log.Printf("[Consumer] Max retries exceeded for job %s, rejecting without requeue", msg.JobID)
+		d.Reject(false)
+		return
+	}
+
+	// Calculate backoff with exponential increase
+	backoff := initialBackoff * time.Duration(1<<uint(retryCount))
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	log.Printf("[Consumer] Waiting %v before republishing job %s (retry %d)", backoff, msg.JobID, retryCount+1)
+
+	// Wait before republishing (prevents tight retry loop)
+	select {
+	case <-ctx.Done():
+		// Context cancelled during backoff, reject without requeue
+		d.Reject(false)
+	case <-time.After(backoff):
+		// Republish with incremented retry count header
+		// Native requeue doesn't preserve headers, so we republish manually
+		headers := amqp.Table{
+			"x-retry-count": int64(retryCount + 1),
+		}
+
+		err := c.channel.PublishWithContext(ctx,
+			"",           // exchange (use default)
+			d.RoutingKey, // routing key = queue name
+			false,        // mandatory
+			false,        // immediate
+			amqp.Publishing{
+				ContentType:  "application/json",
+				DeliveryMode: amqp.Persistent,
+				Headers:      headers,
+				MessageId:    d.MessageId,
+				Timestamp:    d.Timestamp,
+				Body:         d.Body,
+			},
+		)
+		if err != nil {
+			log.Printf("[Consumer] Failed to republish job %s: %v, rejecting without requeue", msg.JobID, err)
+			d.Reject(false)
+		} else {
+			// Acknowledge original message since we've republished
+			d.Ack(false)
 		}
 	}
 }
 
+// Pause stops the delivery of new messages. Consume returns nil once the
+// broker has cancelled the consumers; a message being handled is still
+// acknowledged when its handler returns.
+func (c *RabbitMQConsumer) Pause() error {
+	if !c.paused.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	for _, qName := range c.queues {
+		if err := c.channel.Cancel(c.consumerTag(qName), false); err != nil {
+			return fmt.Errorf("cancel consumer on %s failed: %w", qName, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *RabbitMQConsumer) consumerTag(queue string) string {
+	return fmt.Sprintf("%s-%s", c.consumerID, queue)
+}
+
 // Close closes the consumer connection
 func (c *RabbitMQConsumer) Close() error {
 	if c.channel != nil {
@@ -0,0 +1,159 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// defaultManagementPort is where the RabbitMQ management plugin listens
+const defaultManagementPort = "15672"
+
+// managementAPI reads queue details AMQP does not tell, such as the age of
+// the oldest message, from the RabbitMQ management plugin
+type managementAPI struct {
+	base     *url.URL
+	vhost    string
+	username string
+	password string
+	client   *http.Client
+}
+
+// managementQueue is the part of GET /api/queues/{vhost}/{name} used
+type managementQueue struct {
+	Ready     int `json:"messages_ready"`
+	Unacked   int `json:"messages_unacknowledged"`
+	Consumers int `json:"consumers"`
+
+	// HeadMessageTimestamp is the Timestamp property of the oldest
+	// message, in seconds; absent for an empty queue
+	HeadMessageTimestamp *int64 `json:"head_message_timestamp"`
+}
+
+// newManagementAPI builds the management API client of cfg
+func newManagementAPI(cfg Config) (*managementAPI, error) {
+	uri, err := amqp.ParseURI(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse rabbitmq url: %w", err)
+	}
+
+	raw := cfg.ManagementURL
+	if raw == "" {
+		raw = "http://" + net.JoinHostPort(uri.Host, defaultManagementPort)
+	}
+	base, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse management url: %w", err)
+	}
+
+	api := &managementAPI{
+		base:     base,
+		vhost:    uri.Vhost,
+		username: uri.Username,
+		password: uri.Password,
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}
+	if base.User != nil {
+		api.username = base.User.Username()
+		api.password, _ = base.User.Password()
+		base.User = nil
+	}
+
+	return api, nil
+}
+
+// queue fetches the details of queue name
+func (m *managementAPI) queue(ctx context.Context, name string) (*managementQueue, error) {
+	// The default vhost "/" is escaped into a single path segment
+	u := strings.TrimSuffix(m.base.String(), "/") + "/api/queues/" + url.PathEscape(m.vhost) + "/" + url.PathEscape(name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(m.username, m.password)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("management api returned %s", resp.Status)
+	}
+
+	var q managementQueue
+	if err := json.NewDecoder(resp.Body).Decode(&q); err != nil {
+		return nil, fmt.Errorf("decode queue %s: %w", name, err)
+	}
+
+	return &q, nil
+}
+
+// QueueStatus returns the depth and consumers of the priority queues. The
+// age of the oldest message comes from the management API; without it the
+// counts come from a passive declare, which knows no message ages.
+func (p *RabbitMQPublisher) QueueStatus(ctx context.Context) (*domain.QueueStatus, error) {
+	status := &domain.QueueStatus{Backend: domain.QueueBackendRabbitMQ}
+	queues := []string{QueueCritical, QueueHigh, QueueDefault, QueueLow}
+
+	if p.management != nil {
+		now := time.Now()
+		for _, name := range queues {
+			q, err := p.management.queue(ctx, name)
+			if err != nil {
+				log.Printf("[Publisher] Management API failed for %s, counting by passive declare: %v", name, err)
+				status.Queues = nil
+				break
+			}
+
+			depth := &domain.QueueDepth{
+				Name:      name,
+				Depth:     q.Ready,
+				InFlight:  q.Unacked,
+				Consumers: q.Consumers,
+			}
+			if q.HeadMessageTimestamp != nil && q.Ready > 0 {
+				age := max(now.Sub(time.Unix(*q.HeadMessageTimestamp, 0)).Seconds(), 0)
+				depth.OldestAgeSeconds = &age
+			}
+			status.Queues = append(status.Queues, depth)
+		}
+		if status.Queues != nil {
+			return status, nil
+		}
+	}
+
+	// A passive declare of a missing queue closes its channel, so it does
+	// not get the one messages are published on
+	ch, err := p.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("rabbitmq channel failed: %w", err)
+	}
+	defer ch.Close()
+
+	for _, name := range queues {
+		q, err := ch.QueueDeclarePassive(name, true, false, false, false, nil)
+		if err != nil {
+			return nil, fmt.Errorf("inspect queue %s: %w", name, err)
+		}
+		status.Queues = append(status.Queues, &domain.QueueDepth{
+			Name:      name,
+			Depth:     q.Messages,
+			Consumers: q.Consumers,
+		})
+	}
+
+	return status, nil
+}
@@ -0,0 +1,207 @@
+// Package phonenumber normalizes the phone numbers Google Maps displays to
+// E.164. It knows the calling code and trunk prefix of every country but
+// not their numbering plans, so it only rejects numbers that cannot be
+// E.164 at all; North American numbers are checked more strictly.
+package phonenumber
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrInvalid is returned for text that is not a phone number
+	ErrInvalid = errors.New("invalid phone number")
+
+	// ErrUnknownCountry is returned for a national number whose country
+	// is unknown, so its calling code cannot be added
+	ErrUnknownCountry = errors.New("unknown country for national phone number")
+)
+
+// E.164 allows at most 15 digits including the calling code
+const maxDigits = 15
+
+// minNationalDigits is the shortest subscriber number accepted; a few
+// Pacific islands have 4 digit numbers
+const minNationalDigits = 4
+
+// extension matches an extension suffix such as "ext. 12" or "x12"
+var extension = regexp.MustCompile(`(?i)\s*(?:;\s*ext=|ext\.?|extension|x|#)\s*\d+\s*$`)
+
+// Normalize returns raw in E.164 format, e.g. "+15125550147". Numbers
+// written without a calling code are read as national numbers of country,
+// an ISO 3166-1 alpha-2 code. Extensions are dropped.
+func Normalize(raw, country string) (string, error) {
+	s := extension.ReplaceAllString(strings.TrimSpace(raw), "")
+
+	international := false
+	digits := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits = append(digits, c)
+		case c == '+' && len(digits) == 0 && !international:
+			international = true
+		case c == ' ' || c == '-' || c == '.' || c == '(' || c == ')' || c == '/':
+		default:
+			// Letters (vanity numbers) and anything else
+			return "", ErrInvalid
+		}
+	}
+
+	country = strings.ToUpper(strings.TrimSpace(country))
+	number := string(digits)
+
+	if !international {
+		switch {
+		case nanp[country] && strings.HasPrefix(number, "011"):
+			international, number = true, number[3:]
+		case !nanp[country] && strings.HasPrefix(number, "00"):
+			international, number = true, number[2:]
+		}
+	}
+
+	if international {
+		cc := callingCodeOf(number)
+		if cc == "" {
+			return "", ErrInvalid
+		}
+		return format(cc, number[len(cc):])
+	}
+
+	cc, ok := callingCodes[country]
+	if !ok {
+		return "", ErrUnknownCountry
+	}
+
+	if nanp[country] {
+		// 1 is the NANP trunk prefix
+		if len(number) == 11 && number[0] == '1' {
+			number = number[1:]
+		}
+		return format(cc, number)
+	}
+
+	if prefix, ok := trunkPrefixes[country]; ok {
+		number = strings.TrimPrefix(number, prefix)
+	} else if !keepLeadingZero[country] {
+		number = strings.TrimPrefix(number, "0")
+	}
+
+	return format(cc, number)
+}
+
+// format joins the calling code and the national number after checking
+// their length
+func format(cc, national string) (string, error) {
+	if len(national) < minNationalDigits || len(cc)+len(national) > maxDigits {
+		return "", ErrInvalid
+	}
+
+	// NANP: area code and exchange never start with 0 or 1
+	if cc == "1" && (len(national) != 10 || national[0] < '2' || national[3] < '2') {
+		return "", ErrInvalid
+	}
+
+	return "+" + cc + national, nil
+}
+
+// callingCodeOf returns the calling code number starts with, if any.
+// Calling codes are prefix free, so at most one of 1 to 3 digits matches.
+func callingCodeOf(number string) string {
+	for n := 1; n <= 3 && n <= len(number); n++ {
+		if knownCallingCodes[number[:n]] {
+			return number[:n]
+		}
+	}
+	return ""
+}
+
+var knownCallingCodes = func() map[string]bool {
+	codes := make(map[string]bool, len(callingCodes))
+	for _, cc := range callingCodes {
+		codes[cc] = true
+	}
+	return codes
+}()
+
+// nanp are the countries of the North American Numbering Plan
+var nanp = map[string]bool{
+	"US": true, "CA": true, "AG": true, "AI": true, "AS": true, "BB": true,
+	"BM": true, "BS": true, "DM": true, "DO": true, "GD": true, "GU": true,
+	"JM": true, "KN": true, "KY": true, "LC": true, "MP": true, "MS": true,
+	"PR": true, "SX": true, "TC": true, "TT": true, "VC": true, "VG": true,
+	"VI": true,
+}
+
+// keepLeadingZero are the countries whose national numbers start with a 0
+// that is part of the number rather than a trunk prefix
+var keepLeadingZero = map[string]bool{
+	"IT": true, "SM": true, "VA": true, "CI": true,
+}
+
+// trunkPrefixes are trunk prefixes other than the usual 0
+var trunkPrefixes = map[string]string{
+	"RU": "8", "KZ": "8", "BY": "8", "LT": "8", "HU": "06",
+}
+
+// callingCodes maps ISO 3166-1 alpha-2 codes to country calling codes
+var callingCodes = map[string]string{
+	// North American Numbering Plan
+	"US": "1", "CA": "1", "AG": "1", "AI": "1", "AS": "1", "BB": "1",
+	"BM": "1", "BS": "1", "DM": "1", "DO": "1", "GD": "1", "GU": "1",
+	"JM": "1", "KN": "1", "KY": "1", "LC": "1", "MP": "1", "MS": "1",
+	"PR": "1", "SX": "1", "TC": "1", "TT": "1", "VC": "1", "VG": "1",
+	"VI": "1",
+
+	"RU": "7", "KZ": "7",
+	"EG": "20", "ZA": "27", "GR": "30", "NL": "31", "BE": "32", "FR": "33",
+	"ES": "34", "HU": "36", "IT": "39", "VA": "39", "RO": "40", "CH": "41",
+	"AT": "43", "GB": "44", "GG": "44", "IM": "44", "JE": "44", "DK": "45",
+	"SE": "46", "NO": "47", "SJ": "47", "PL": "48", "DE": "49",
+	"PE": "51", "MX": "52", "CU": "53", "AR": "54", "BR": "55", "CL": "56",
+	"CO": "57", "VE": "58",
+	"MY": "60", "AU": "61", "CC": "61", "CX": "61", "ID": "62", "PH": "63",
+	"NZ": "64", "SG": "65", "TH": "66",
+	"JP": "81", "KR": "82", "VN": "84", "CN": "86",
+	"TR": "90", "IN": "91", "PK": "92", "AF": "93", "LK": "94", "MM": "95",
+	"IR": "98",
+
+	"SS": "211", "MA": "212", "EH": "212", "DZ": "213", "TN": "216", "LY": "218",
+	"GM": "220", "SN": "221", "MR": "222", "ML": "223", "GN": "224", "CI": "225",
+	"BF": "226", "NE": "227", "TG": "228", "BJ": "229", "MU": "230", "LR": "231",
+	"SL": "232", "GH": "233", "NG": "234", "TD": "235", "CF": "236", "CM": "237",
+	"CV": "238", "ST": "239", "GQ": "240", "GA": "241", "CG": "242", "CD": "243",
+	"AO": "244", "GW": "245", "IO": "246", "SC": "248", "SD": "249", "RW": "250",
+	"ET": "251", "SO": "252", "DJ": "253", "KE": "254", "TZ": "255", "UG": "256",
+	"BI": "257", "MZ": "258", "ZM": "260", "MG": "261", "RE": "262", "YT": "262",
+	"ZW": "263", "NA": "264", "MW": "265", "LS": "266", "BW": "267", "SZ": "268",
+	"KM": "269", "SH": "290", "ER": "291", "AW": "297", "FO": "298", "GL": "299",
+
+	"GI": "350", "PT": "351", "LU": "352", "IE": "353", "IS": "354", "AL": "355",
+	"MT": "356", "CY": "357", "FI": "358", "AX": "358", "BG": "359", "LT": "370",
+	"LV": "371", "EE": "372", "MD": "373", "AM": "374", "BY": "375", "AD": "376",
+	"MC": "377", "SM": "378", "UA": "380", "RS": "381", "ME": "382", "XK": "383",
+	"HR": "385", "SI": "386", "BA": "387", "MK": "389",
+	"CZ": "420", "SK": "421", "LI": "423",
+
+	"FK": "500", "BZ": "501", "GT": "502", "SV": "503", "HN": "504", "NI": "505",
+	"CR": "506", "PA": "507", "PM": "508", "HT": "509", "GP": "590", "BL": "590",
+	"MF": "590", "BO": "591", "GY": "592", "EC": "593", "GF": "594", "PY": "595",
+	"MQ": "596", "SR": "597", "UY": "598", "CW": "599", "BQ": "599",
+
+	"TL": "670", "NF": "672", "BN": "673", "NR": "674", "PG": "675", "TO": "676",
+	"SB": "677", "VU": "678", "FJ": "679", "PW": "680", "WF": "681", "CK": "682",
+	"NU": "683", "WS": "685", "KI": "686", "NC": "687", "TV": "688", "PF": "689",
+	"TK": "690", "FM": "691", "MH": "692",
+
+	"KP": "850", "HK": "852", "MO": "853", "KH": "855", "LA": "856", "BD": "880",
+	"TW": "886",
+
+	"MV": "960", "LB": "961", "JO": "962", "SY": "963", "IQ": "964", "KW": "965",
+	"SA": "966", "YE": "967", "OM": "968", "PS": "970", "AE": "971", "IL": "972",
+	"BH": "973", "QA": "974", "BT": "975", "MN": "976", "NP": "977", "TJ": "992",
+	"TM": "993", "AZ": "994", "GE": "995", "KG": "996", "UZ": "998",
+}
@@ -0,0 +1,63 @@
+package phonenumber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		country string
+		want    string
+	}{
+		{name: "US national", raw: "(512) 555-0147", country: "US", want: "+15125550147"},
+		{name: "US with trunk prefix", raw: "1-512-555-0147", country: "us", want: "+15125550147"},
+		{name: "US extension dropped", raw: "(512) 555-0147 ext. 12", country: "US", want: "+15125550147"},
+		{name: "international", raw: "+61 2 9374 4000", country: "", want: "+61293744000"},
+		{name: "international ignores country", raw: "+44 20 7946 0958", country: "US", want: "+442079460958"},
+		{name: "AU national", raw: "(02) 9374 4000", country: "AU", want: "+61293744000"},
+		{name: "DE national", raw: "030 901820", country: "DE", want: "+4930901820"},
+		{name: "IT keeps leading zero", raw: "06 6988 4857", country: "IT", want: "+390669884857"},
+		{name: "RU trunk 8", raw: "8 (495) 123-45-67", country: "RU", want: "+74951234567"},
+		{name: "00 international prefix", raw: "0049 30 901820", country: "FR", want: "+4930901820"},
+		{name: "011 from NANP", raw: "011 49 30 901820", country: "CA", want: "+4930901820"},
+		{name: "three digit calling code", raw: "+357 22 123456", country: "", want: "+35722123456"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.raw, tt.country)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizeInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		country string
+		wantErr error
+	}{
+		{name: "empty", raw: "", country: "US", wantErr: ErrInvalid},
+		{name: "vanity number", raw: "1-800-FLOWERS", country: "US", wantErr: ErrInvalid},
+		{name: "too short", raw: "123", country: "DE", wantErr: ErrInvalid},
+		{name: "too long", raw: "+49 1234 5678 9012 345", country: "", wantErr: ErrInvalid},
+		{name: "NANP area code starts with 1", raw: "(112) 555-0147", country: "US", wantErr: ErrInvalid},
+		{name: "NANP wrong length", raw: "555-0147", country: "US", wantErr: ErrInvalid},
+		{name: "unassigned calling code", raw: "+999 123 4567", country: "", wantErr: ErrInvalid},
+		{name: "national without country", raw: "030 901820", country: "", wantErr: ErrUnknownCountry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Normalize(tt.raw, tt.country)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
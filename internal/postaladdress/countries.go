@@ -0,0 +1,199 @@
+package postaladdress
+
+import (
+	"regexp"
+	"strings"
+)
+
+// format is how the addresses of a country are written
+type format struct {
+	postalCode *regexp.Regexp
+
+	numberFirst bool // "221B Baker St" rather than "Marienplatz 8"
+	noNumber    bool // Block and lot numbers, not house numbers (Japan)
+
+	// cityFirst countries write the postal code after the city ("London
+	// SW1A 2AA"), the others before it ("10117 Berlin")
+	cityFirst bool
+
+	// stateBeforePostal: the postal code follows the state, and the city
+	// has a segment of its own ("Chicago, IL 60606")
+	stateBeforePostal bool
+
+	// states are the abbreviations written between city and postal code
+	// ("Sydney NSW 2000")
+	states map[string]bool
+
+	provinceAfterCity bool // "00184 Roma RM"
+}
+
+var (
+	fiveDigits = regexp.MustCompile(`\b\d{5}\b`)
+	fourDigits = regexp.MustCompile(`\b\d{4}\b`)
+)
+
+// formats by ISO 3166-1 alpha-2 code
+var formats = map[string]*format{
+	"US": {postalCode: regexp.MustCompile(`\b\d{5}(?:-\d{4})?\b`), numberFirst: true, cityFirst: true, stateBeforePostal: true},
+	"CA": {postalCode: regexp.MustCompile(`\b[A-Z]\d[A-Z] ?\d[A-Z]\d\b`), numberFirst: true, cityFirst: true, stateBeforePostal: true},
+	"GB": {postalCode: regexp.MustCompile(`\b[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}\b`), numberFirst: true, cityFirst: true},
+	"AU": {postalCode: fourDigits, numberFirst: true, cityFirst: true, states: setOf("NSW", "VIC", "QLD", "WA", "SA", "TAS", "ACT", "NT")},
+	"IN": {postalCode: regexp.MustCompile(`\b\d{3} ?\d{3}\b`), numberFirst: true, cityFirst: true, stateBeforePostal: true},
+	"JP": {postalCode: regexp.MustCompile(`\b\d{3}-\d{4}\b`), noNumber: true, cityFirst: true, stateBeforePostal: true},
+	"ID": {postalCode: fiveDigits, cityFirst: true, stateBeforePostal: true},
+	"FR": {postalCode: fiveDigits, numberFirst: true},
+	"DE": {postalCode: fiveDigits},
+	"AT": {postalCode: fourDigits},
+	"CH": {postalCode: fourDigits},
+	"BE": {postalCode: fourDigits},
+	"NL": {postalCode: regexp.MustCompile(`\b\d{4} ?[A-Z]{2}\b`)},
+	"PL": {postalCode: regexp.MustCompile(`\b\d{2}-\d{3}\b`)},
+	"IT": {postalCode: fiveDigits, provinceAfterCity: true},
+	"ES": {postalCode: fiveDigits},
+	"MX": {postalCode: fiveDigits},
+	"BR": {postalCode: regexp.MustCompile(`\b\d{5}-\d{3}\b`)},
+}
+
+// genericFormat reads addresses of other countries: a 4 to 6 digit postal
+// code with the city on either side, and the house number at either end of
+// the street
+var genericFormat = &format{postalCode: regexp.MustCompile(`\b\d{4,6}\b`)}
+
+// countryNames maps lower-cased country names, in English and the
+// languages Google Maps displays them in for the countries of formats,
+// to their code
+var countryNames = map[string]string{
+	"united states": "US", "united states of america": "US", "usa": "US", "estados unidos": "US",
+	"états-unis": "US", "vereinigte staaten": "US", "stati uniti": "US", "verenigde staten": "US",
+	"canada": "CA", "kanada": "CA", "canadá": "CA",
+	"united kingdom": "GB", "uk": "GB", "reino unido": "GB", "royaume-uni": "GB",
+	"vereinigtes königreich": "GB", "regno unito": "GB", "verenigd koninkrijk": "GB",
+	"australia": "AU", "australien": "AU", "australie": "AU", "austrália": "AU",
+	"india": "IN", "indien": "IN", "inde": "IN", "índia": "IN",
+	"japan": "JP", "日本": "JP", "japon": "JP", "japón": "JP", "giappone": "JP", "japão": "JP",
+	"indonesia": "ID", "indonesien": "ID", "indonésie": "ID", "indonésia": "ID",
+	"france": "FR", "frankreich": "FR", "francia": "FR", "frankrijk": "FR", "francja": "FR", "frança": "FR",
+	"germany": "DE", "deutschland": "DE", "allemagne": "DE", "alemania": "DE", "germania": "DE",
+	"duitsland": "DE", "niemcy": "DE", "alemanha": "DE",
+	"austria": "AT", "österreich": "AT", "autriche": "AT", "oostenrijk": "AT",
+	"switzerland": "CH", "schweiz": "CH", "suisse": "CH", "svizzera": "CH", "suiza": "CH", "zwitserland": "CH",
+	"belgium": "BE", "belgië": "BE", "belgique": "BE", "belgien": "BE", "bélgica": "BE", "belgio": "BE",
+	"netherlands": "NL", "the netherlands": "NL", "nederland": "NL", "niederlande": "NL",
+	"pays-bas": "NL", "países bajos": "NL", "paesi bassi": "NL", "holandia": "NL", "países baixos": "NL",
+	"poland": "PL", "polska": "PL", "polen": "PL", "pologne": "PL", "polonia": "PL",
+	"italy": "IT", "italia": "IT", "italien": "IT", "italie": "IT", "italië": "IT", "włochy": "IT", "itália": "IT",
+	"spain": "ES", "españa": "ES", "spanien": "ES", "espagne": "ES", "spagna": "ES", "spanje": "ES",
+	"hiszpania": "ES", "espanha": "ES",
+	"mexico": "MX", "méxico": "MX", "mexiko": "MX", "mexique": "MX", "messico": "MX",
+	"brazil": "BR", "brasil": "BR", "brasilien": "BR", "brésil": "BR", "brasile": "BR",
+}
+
+// langCountries are the countries of languages the formats only know in
+// one country
+var langCountries = map[string]string{
+	"ja": "JP",
+	"id": "ID",
+	"pl": "PL",
+}
+
+// CountryCode returns the code of a country given as an ISO 3166-1 alpha-2
+// code or by name, or "" if it is neither
+func CountryCode(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) == 2 && isLetters(s) {
+		return strings.ToUpper(s)
+	}
+	return countryNames[strings.ToLower(s)]
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// box is a rough bounding box of a country
+type box struct {
+	country                        string
+	minLat, maxLat, minLon, maxLon float64
+	langs                          []string
+}
+
+func (b box) contains(lat, lon float64) bool {
+	return lat >= b.minLat && lat <= b.maxLat && lon >= b.minLon && lon <= b.maxLon
+}
+
+func (b box) area() float64 {
+	return (b.maxLat - b.minLat) * (b.maxLon - b.minLon)
+}
+
+// boxes of the countries of formats. Neighbours overlap, see CountryAt.
+var boxes = []box{
+	{"US", 24.4, 49.4, -124.8, -66.9, []string{"en", "es"}},
+	{"US", 51.2, 71.5, -179.2, -129.9, []string{"en"}}, // Alaska
+	{"US", 18.9, 22.3, -160.3, -154.8, []string{"en"}}, // Hawaii
+	{"CA", 41.7, 83.1, -141.0, -52.6, []string{"en", "fr"}},
+	{"MX", 14.5, 32.7, -118.4, -86.7, []string{"es"}},
+	{"BR", -33.8, 5.3, -74.0, -34.8, []string{"pt"}},
+	{"GB", 49.9, 60.9, -8.2, 1.8, []string{"en"}},
+	{"FR", 41.3, 51.1, -5.2, 9.6, []string{"fr"}},
+	{"ES", 36.0, 43.8, -9.3, 3.3, []string{"es", "ca"}},
+	{"ES", 27.6, 29.5, -18.2, -13.4, []string{"es"}}, // Canary Islands
+	{"IT", 36.6, 47.1, 6.6, 18.5, []string{"it"}},
+	{"DE", 47.3, 55.1, 5.9, 15.0, []string{"de"}},
+	{"NL", 50.75, 53.6, 3.3, 7.2, []string{"nl"}},
+	{"BE", 49.5, 51.5, 2.5, 6.4, []string{"nl", "fr", "de"}},
+	{"AT", 46.4, 49.0, 9.5, 17.2, []string{"de"}},
+	{"CH", 45.8, 47.8, 5.9, 10.5, []string{"de", "fr", "it"}},
+	{"PL", 49.0, 54.9, 14.1, 24.2, []string{"pl"}},
+	{"IN", 6.7, 35.5, 68.1, 97.4, []string{"en", "hi"}},
+	{"JP", 24.0, 45.6, 122.9, 145.8, []string{"ja"}},
+	{"ID", -11.0, 6.1, 95.0, 141.0, []string{"id"}},
+	{"AU", -43.7, -10.7, 113.3, 153.6, []string{"en"}},
+}
+
+// CountryAt returns the country of the coordinates, or "" outside the
+// countries of formats. The boxes are coarse: where they overlap, a
+// country speaking lang wins, then the smallest box.
+func CountryAt(lat, lon float64, lang string) string {
+	lang = strings.ToLower(lang)
+
+	var (
+		best      *box
+		bestSpeak bool
+	)
+	for i := range boxes {
+		b := &boxes[i]
+		if !b.contains(lat, lon) {
+			continue
+		}
+
+		speaks := false
+		for _, l := range b.langs {
+			if l == lang {
+				speaks = true
+				break
+			}
+		}
+
+		if best == nil || (speaks && !bestSpeak) || (speaks == bestSpeak && b.area() < best.area()) {
+			best, bestSpeak = b, speaks
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+	return best.country
+}
+
+func setOf(values ...string) map[string]bool {
+	m := make(map[string]bool, len(values))
+	for _, v := range values {
+		m[v] = true
+	}
+	return m
+}
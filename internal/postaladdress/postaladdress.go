@@ -0,0 +1,325 @@
+// Package postaladdress splits the one-line addresses Google Maps displays
+// into street, house number, postal code, city, state and country. The
+// countries in formats each have their postal code pattern and the order
+// their addresses are written in; other countries get a generic reading
+// that only relies on a numeric postal code.
+package postaladdress
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Components are the parts of an address. Parts the address does not show
+// are empty.
+type Components struct {
+	Street     string `json:"street,omitempty"` // Street line as shown, house number included
+	Number     string `json:"number,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	City       string `json:"city,omitempty"`
+	State      string `json:"state,omitempty"`
+	Country    string `json:"country,omitempty"` // ISO 3166-1 alpha-2
+}
+
+// Located returns true if the address was placed in a city or postal code
+func (c Components) Located() bool {
+	return c.City != "" || c.PostalCode != ""
+}
+
+// Hint is what is known about the place besides its address
+type Hint struct {
+	Country string // Country code or name, when known
+	Lang    string // Language the address was displayed in
+
+	// Coordinates of the place, used when HasCoords is set
+	Lat, Lon  float64
+	HasCoords bool
+}
+
+var (
+	// japanese matches addresses written in Japanese: postal code,
+	// prefecture, municipality and the rest
+	japanese = regexp.MustCompile(`^〒?\s*(\d{3}-\d{4})?\s*(東京都|北海道|(?:京都|大阪)府|\p{Han}{2,3}県)(.+?[市区町村])(.*)$`)
+
+	// unit matches a leading segment naming a unit in a building rather
+	// than the street, e.g. "Shop No. 5" or "Suite 200"
+	unit = regexp.MustCompile(`(?i)^(?:shop|suite|ste\.?|unit|flat|apt\.?|apartment|floor|level|room|office|no\.?|#)\s*(?:no\.?\s*)?\d`)
+
+	// bareNumber matches a segment holding only the house number of the
+	// previous one, e.g. "1" in "Piazza del Colosseo, 1" or "1578 - Bela
+	// Vista" in "Av. Paulista, 1578 - Bela Vista"
+	bareNumber = regexp.MustCompile(`^(\d+[A-Za-z]?(?:[-/]\d+[A-Za-z]?)?)(?:\s+-\s+.*)?$`)
+
+	leadingNumber  = regexp.MustCompile(`^(\d+[A-Za-z]?(?:[-/]\d+[A-Za-z]?)?)\s`)
+	trailingNumber = regexp.MustCompile(`(?i)(?:^|\s)(?:no\.?\s*)?(\d+[A-Za-z]?(?:[-/]\d+[A-Za-z]?)?)$`)
+
+	// provinceCode matches the two letter province after an Italian city,
+	// "Roma RM"
+	provinceCode = regexp.MustCompile(`^(.+)\s([A-Z]{2})$`)
+)
+
+// Parse splits raw into its components. The country is the one of hint,
+// else the country name ending the address, else the country at the
+// coordinates of hint, else the country of its language if it is spoken
+// in only one.
+func Parse(raw string, hint Hint) Components {
+	s := normalizeText(raw)
+	if s == "" {
+		return Components{}
+	}
+
+	c := Components{Country: CountryCode(hint.Country)}
+
+	if m := japanese.FindStringSubmatch(s); m != nil {
+		if c.Country == "" {
+			c.Country = "JP"
+		}
+		c.PostalCode, c.State, c.City, c.Street = m[1], m[2], m[3], strings.TrimSpace(m[4])
+		return c
+	}
+
+	segs := splitSegments(s)
+
+	// A single segment is never read as the country
+	if n := len(segs); n > 1 {
+		if code, ok := countryNames[strings.ToLower(segs[n-1])]; ok {
+			segs = segs[:n-1]
+			if c.Country == "" {
+				c.Country = code
+			}
+		}
+	}
+
+	if c.Country == "" && hint.HasCoords {
+		c.Country = CountryAt(hint.Lat, hint.Lon, hint.Lang)
+	}
+	if c.Country == "" {
+		c.Country = langCountries[strings.ToLower(hint.Lang)]
+	}
+
+	f, ok := formats[c.Country]
+	if !ok {
+		f = genericFormat
+	}
+
+	start := f.locality(segs, &c)
+	f.street(segs[:start], &c)
+
+	return c
+}
+
+// locality fills the postal code, city and state from segs and returns the
+// index of the first segment they use
+func (f *format) locality(segs []string, c *Components) int {
+	// The first segment is the street unless it is the only one
+	for i := len(segs) - 1; i >= 0 && (i > 0 || len(segs) == 1); i-- {
+		matches := f.postalCode.FindAllStringIndex(segs[i], -1)
+		if matches == nil {
+			continue
+		}
+
+		m := matches[len(matches)-1]
+		c.PostalCode = normalizePostalCode(segs[i][m[0]:m[1]])
+		rest := trimSeparators(segs[i][:m[0]] + " " + segs[i][m[1]:])
+
+		return f.aroundPostalCode(segs, i, rest, c)
+	}
+
+	return f.withoutPostalCode(segs, c)
+}
+
+// aroundPostalCode reads the city and state next to the postal code in
+// segment i; rest is what that segment holds besides the postal code
+func (f *format) aroundPostalCode(segs []string, i int, rest string, c *Components) int {
+	start := i
+
+	switch {
+	case f.stateBeforePostal:
+		// "Springfield, IL 62704", "Bengaluru, Karnataka 560001"
+		c.State = rest
+	case f.states != nil:
+		// "Sydney NSW 2000", or "Sydney, NSW 2000"
+		if f.states[rest] {
+			c.State = rest
+		} else if j := strings.LastIndexByte(rest, ' '); j > 0 && f.states[rest[j+1:]] {
+			c.City, c.State = rest[:j], rest[j+1:]
+		} else {
+			c.City = rest
+		}
+	case f.provinceAfterCity:
+		// "00184 Roma RM"
+		if m := provinceCode.FindStringSubmatch(rest); m != nil {
+			c.City, c.State = m[1], m[2]
+		} else {
+			c.City = rest
+		}
+	default:
+		// "10117 Berlin", "London SW1A 2AA"
+		c.City = rest
+	}
+
+	// "Chicago, IL 60606" or "São Paulo - SP, 01310-200"
+	if c.City == "" && i > 1 {
+		start = i - 1
+		c.City = segs[start]
+	}
+
+	// "06050 Ciudad de México, CDMX"
+	if c.State == "" && !f.cityFirst && i+1 < len(segs) {
+		c.State = segs[i+1]
+	}
+
+	if c.State == "" {
+		c.City, c.State = splitCityState(c.City)
+	}
+
+	return start
+}
+
+// withoutPostalCode reads the city, and maybe the state, from the last
+// segments: "Rio de Janeiro - RJ", "Springfield, IL" or just "Berlin"
+func (f *format) withoutPostalCode(segs []string, c *Components) int {
+	n := len(segs)
+
+	switch {
+	case n == 0:
+		return 0
+	case n == 1:
+		// A lone segment with a number is more likely a street
+		if hasDigit(segs[0]) {
+			return 1
+		}
+		c.City, c.State = splitCityState(segs[0])
+		return 0
+	case n > 2 && f.isState(segs[n-1]):
+		c.City, c.State = segs[n-2], segs[n-1]
+		return n - 2
+	default:
+		c.City, c.State = splitCityState(segs[n-1])
+		return n - 1
+	}
+}
+
+// isState returns true if s is a state abbreviation written alone after
+// the city
+func (f *format) isState(s string) bool {
+	if f.states != nil {
+		return f.states[s]
+	}
+	return f.stateBeforePostal && stateAbbrev.MatchString(s)
+}
+
+var stateAbbrev = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// street fills the street and house number from the segments before the
+// locality
+func (f *format) street(segs []string, c *Components) {
+	// The street is the first of the first two segments with a house
+	// number, skipping units and landmark names ("Champ de Mars, 5 Av.
+	// Anatole France"); later segments are neighbourhoods
+	idx := -1
+	for i, candidates := 0, 0; i < len(segs) && candidates < 2; i++ {
+		if unit.MatchString(segs[i]) || bareNumber.MatchString(segs[i]) {
+			continue
+		}
+		candidates++
+		if idx < 0 {
+			idx = i
+		}
+		if f.houseNumber(segs[i]) != "" {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return
+	}
+
+	// "Parque Nacional da Tijuca - Alto da Boa Vista"
+	street, _, _ := strings.Cut(segs[idx], " - ")
+	c.Street = street
+	c.Number = f.houseNumber(street)
+
+	if c.Number == "" && !f.numberFirst && !f.noNumber && idx+1 < len(segs) {
+		if m := bareNumber.FindStringSubmatch(segs[idx+1]); m != nil {
+			c.Number = m[1]
+			c.Street = street + ", " + m[1]
+		}
+	}
+}
+
+// houseNumber returns the house number of a street line
+func (f *format) houseNumber(street string) string {
+	if f.noNumber {
+		return ""
+	}
+
+	if f.numberFirst || f == genericFormat {
+		if m := leadingNumber.FindStringSubmatch(street); m != nil {
+			return m[1]
+		}
+		if f.numberFirst {
+			return ""
+		}
+	}
+
+	if m := trailingNumber.FindStringSubmatch(street); m != nil {
+		return m[1]
+	}
+
+	return ""
+}
+
+// splitCityState splits "São Paulo - SP" into city and state
+func splitCityState(s string) (string, string) {
+	city, state, ok := strings.Cut(s, " - ")
+	if !ok {
+		return s, ""
+	}
+	return strings.TrimSpace(city), strings.TrimSpace(state)
+}
+
+// splitSegments splits an address on commas, dropping empty segments
+func splitSegments(s string) []string {
+	parts := strings.Split(s, ",")
+	segs := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			segs = append(segs, p)
+		}
+	}
+	return segs
+}
+
+// normalizeText turns full-width digits and punctuation into ASCII and
+// collapses whitespace
+func normalizeText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= '０' && r <= '９':
+			return '0' + (r - '０')
+		case r == '，':
+			return ','
+		case r == '－':
+			return '-'
+		}
+		return r
+	}, s)
+
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// trimSeparators trims the spaces, dashes and commas left around a removed
+// postal code
+func trimSeparators(s string) string {
+	return strings.Trim(strings.Join(strings.Fields(s), " "), " -,")
+}
+
+// normalizePostalCode upper-cases a postal code and collapses its spaces
+func normalizePostalCode(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), " "))
+}
+
+func hasDigit(s string) bool {
+	return strings.ContainsAny(s, "0123456789")
+}
@@ -0,0 +1,96 @@
+package postaladdress
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// corpusCase is an address as Google Maps displays it and its expected
+// parse. Lat and Lon are optional.
+type corpusCase struct {
+	Address string `json:"address"`
+	Hint    struct {
+		Country string   `json:"country"`
+		Lang    string   `json:"lang"`
+		Lat     *float64 `json:"lat"`
+		Lon     *float64 `json:"lon"`
+	} `json:"hint"`
+	Want Components `json:"want"`
+}
+
+// minCorpusCountries keeps the corpus from shrinking to a few formats
+const minCorpusCountries = 10
+
+func TestParseCorpus(t *testing.T) {
+	data, err := os.ReadFile("testdata/corpus.json")
+	require.NoError(t, err)
+
+	var corpus []corpusCase
+	require.NoError(t, json.Unmarshal(data, &corpus))
+
+	countries := make(map[string]bool)
+	for _, tc := range corpus {
+		t.Run(tc.Address, func(t *testing.T) {
+			hint := Hint{Country: tc.Hint.Country, Lang: tc.Hint.Lang}
+			if tc.Hint.Lat != nil && tc.Hint.Lon != nil {
+				hint.Lat, hint.Lon, hint.HasCoords = *tc.Hint.Lat, *tc.Hint.Lon, true
+			}
+
+			assert.Equal(t, tc.Want, Parse(tc.Address, hint))
+		})
+		countries[tc.Want.Country] = true
+	}
+
+	assert.GreaterOrEqual(t, len(countries), minCorpusCountries)
+}
+
+func TestParseEmpty(t *testing.T) {
+	assert.Equal(t, Components{}, Parse("  ", Hint{}))
+	assert.False(t, Parse("", Hint{}).Located())
+}
+
+func TestParseStateIsNotCountry(t *testing.T) {
+	// "CA" and "IN" are state abbreviations here, not Canada and India
+	c := Parse("Hollywood Bowl, Los Angeles, CA", Hint{Lang: "en", Lat: 34.1122, Lon: -118.3391, HasCoords: true})
+	assert.Equal(t, "US", c.Country)
+	assert.Equal(t, "CA", c.State)
+
+	c = Parse("Monument Circle, Indianapolis, IN", Hint{Country: "us"})
+	assert.Equal(t, "US", c.Country)
+	assert.Equal(t, "Indianapolis", c.City)
+	assert.Equal(t, "IN", c.State)
+}
+
+func TestCountryAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		lang     string
+		want     string
+	}{
+		{name: "inside one box", lat: 52.52, lon: 13.405, lang: "en", want: "DE"},
+		{name: "smallest box wins", lat: 50.8467, lon: 4.3525, lang: "en", want: "BE"},
+		{name: "language wins over size", lat: 48.5734, lon: 7.7521, lang: "fr", want: "FR"},
+		{name: "language of a neighbour", lat: 50.9413, lon: 6.9583, lang: "de", want: "DE"},
+		{name: "Alaska", lat: 61.2181, lon: -149.9003, lang: "en", want: "US"},
+		{name: "outside every box", lat: -1.2921, lon: 36.8219, lang: "en", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CountryAt(tt.lat, tt.lon, tt.lang))
+		})
+	}
+}
+
+func TestCountryCode(t *testing.T) {
+	assert.Equal(t, "DE", CountryCode("de"))
+	assert.Equal(t, "DE", CountryCode("Deutschland"))
+	assert.Equal(t, "GB", CountryCode(" United Kingdom "))
+	assert.Equal(t, "JP", CountryCode("日本"))
+	assert.Equal(t, "", CountryCode("Atlantis"))
+}
@@ -10,6 +10,27 @@ type Config struct {
 	SourceURLs           []string // Default GitHub raw URLs
 	RefreshInterval      time.Duration
 	ValidatorConcurrency int
+	ExplorationRatio     float64       // Share of connections routed to unproven proxies (0-1)
+	SessionTTL           time.Duration // Idle time after which a sticky session is dropped
+	MaxSessions          int           // Cap on concurrently pinned sessions
+	GeoIPURL             string        // GeoIP lookup URL template with {ip}; empty disables country tagging
+	LeaseTTL             time.Duration // How long proxies leased to a worker stay its own
+
+	// Revalidation of proxies already in the pool or waiting in the database
+	RevalidateInterval time.Duration // How often a slice of the pool is probed; 0 disables
+	RevalidateBatch    int           // Proxies probed per revalidation cycle
+	ProbeConcurrency   int           // Concurrent revalidation probes
+	ProbeTimeout       time.Duration // Timeout of a single probe request
+	ProbeURL           string        // Google Maps endpoint a proxy must reach
+	ConnectivityURL    string        // Generic target checked before ProbeURL
+
+	EventRetention     int           // Newest proxy events kept in the database; 0 keeps all
+	UsageFlushInterval time.Duration // How often relayed traffic counts are added to the database
+
+	// Fetching sources, and disabling those whose proxies keep failing
+	SourceTimeout      time.Duration // Bound on fetching one source
+	SourceMinPassRate  float64       // Share of a source's proxies that must pass (0-1)
+	SourceDisableAfter int           // Refreshes below SourceMinPassRate before a source is disabled; 0 never disables
 }
 
 func DefaultConfig() *Config {
@@ -24,5 +45,20 @@ func DefaultConfig() *Config {
 		},
 		RefreshInterval:      10 * time.Minute,
 		ValidatorConcurrency: 50,
+		ExplorationRatio:     DefaultExplorationRatio,
+		SessionTTL:           DefaultSessionTTL,
+		MaxSessions:          DefaultMaxSessions,
+		LeaseTTL:             DefaultLeaseTTL,
+		RevalidateInterval:   DefaultRevalidateInterval,
+		RevalidateBatch:      DefaultRevalidateBatch,
+		ProbeConcurrency:     DefaultProbeConcurrency,
+		ProbeTimeout:         DefaultProbeTimeout,
+		ProbeURL:             DefaultProbeURL,
+		ConnectivityURL:      DefaultConnectivityURL,
+		EventRetention:       DefaultEventRetention,
+		UsageFlushInterval:   DefaultUsageFlushInterval,
+		SourceTimeout:        DefaultSourceTimeout,
+		SourceMinPassRate:    DefaultSourceMinPassRate,
+		SourceDisableAfter:   DefaultSourceDisableAfter,
 	}
 }
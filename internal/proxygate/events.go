@@ -0,0 +1,221 @@
+package proxygate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+const (
+	// DefaultEventRetention is the number of proxy events kept in the database
+	DefaultEventRetention = 100000
+
+	// eventBufferSize bounds the events waiting to be written. Past it new
+	// events are dropped rather than holding up connection handling.
+	eventBufferSize = 4096
+
+	// eventBatchSize is the most events written in one transaction
+	eventBatchSize = 200
+
+	// eventFlushInterval is how long a partial batch waits to be written
+	eventFlushInterval = 2 * time.Second
+
+	// eventPruneInterval is how often the table is cut back to the retention
+	eventPruneInterval = 10 * time.Minute
+)
+
+// errCaptcha is returned by a probe that Google sent to its /sorry/ page
+var errCaptcha = errors.New("google captcha")
+
+// statusError is an HTTP error status from an upstream proxy or a probe target
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e *statusError) Error() string {
+	return "unexpected status: " + e.status
+}
+
+// classifyFailure maps a dial or probe error to one of the
+// domain.ProxyFail reasons
+func classifyFailure(err error) string {
+	if errors.Is(err, errCaptcha) {
+		return domain.ProxyFailCaptcha
+	}
+
+	var se *statusError
+	if errors.As(err, &se) {
+		switch se.code {
+		case http.StatusProxyAuthRequired:
+			return domain.ProxyFailAuth
+		case http.StatusTooManyRequests:
+			return domain.ProxyFailRateLimited
+		default:
+			return domain.ProxyFailBlocked
+		}
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return domain.ProxyFailConnectTimeout
+	}
+
+	// SOCKS5 replies and auth failures only come back as text
+	msg := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED), strings.Contains(msg, "connection refused"):
+		return domain.ProxyFailConnectRefused
+	case strings.Contains(msg, "authentication failed"), strings.Contains(msg, "username/password"):
+		return domain.ProxyFailAuth
+	case strings.Contains(msg, "tls"), strings.Contains(msg, "x509"):
+		return domain.ProxyFailTLS
+	}
+
+	return domain.ProxyFailOther
+}
+
+// eventRecorder writes proxy events from a background goroutine so that
+// recording never blocks connection handling. Events are dropped while no
+// repository is set or the buffer is full.
+type eventRecorder struct {
+	events    chan *domain.ProxyEvent
+	retention int
+	dropped   atomic.Int64
+
+	mu   sync.RWMutex
+	repo domain.ProxyEventRepository
+}
+
+func newEventRecorder(retention int) *eventRecorder {
+	return &eventRecorder{
+		events:    make(chan *domain.ProxyEvent, eventBufferSize),
+		retention: retention,
+	}
+}
+
+func (r *eventRecorder) setRepo(repo domain.ProxyEventRepository) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.repo = repo
+}
+
+func (r *eventRecorder) repository() domain.ProxyEventRepository {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.repo
+}
+
+// record queues an event for proxy. err, if any, gives the reason and
+// detail. Proxies that were never stored have nothing to attach events to.
+func (r *eventRecorder) record(proxy *domain.Proxy, eventType domain.ProxyEventType, err error, jobID *uuid.UUID) {
+	if r == nil || proxy.ID == 0 || r.repository() == nil {
+		return
+	}
+
+	event := &domain.ProxyEvent{
+		ProxyID:   proxy.ID,
+		EventType: eventType,
+		SourceURL: proxy.SourceURL,
+		JobID:     jobID,
+		CreatedAt: time.Now(),
+	}
+	if err != nil {
+		event.Reason = classifyFailure(err)
+		event.Detail = err.Error()
+	}
+
+	select {
+	case r.events <- event:
+	default:
+		if n := r.dropped.Add(1); n%1000 == 1 {
+			log.Printf("[ProxyGate] Event buffer full, %d proxy events dropped so far", n)
+		}
+	}
+}
+
+// Run writes queued events in batches and prunes old ones until ctx is done
+func (r *eventRecorder) Run(ctx context.Context) error {
+	flushTicker := time.NewTicker(eventFlushInterval)
+	defer flushTicker.Stop()
+
+	pruneTicker := time.NewTicker(eventPruneInterval)
+	defer pruneTicker.Stop()
+
+	batch := make([]*domain.ProxyEvent, 0, eventBatchSize)
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Write what is already queued before stopping
+		drain:
+			for {
+				select {
+				case event := <-r.events:
+					batch = append(batch, event)
+				default:
+					break drain
+				}
+			}
+
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			r.flush(flushCtx, batch)
+			cancel()
+
+			return nil
+		case event := <-r.events:
+			batch = append(batch, event)
+			if len(batch) >= eventBatchSize {
+				r.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		case <-flushTicker.C:
+			r.flush(ctx, batch)
+			batch = batch[:0]
+		case <-pruneTicker.C:
+			if err := r.prune(ctx); err != nil {
+				log.Printf("[ProxyGate] Proxy event pruning failed: %v", err)
+			}
+		}
+	}
+}
+
+func (r *eventRecorder) flush(ctx context.Context, batch []*domain.ProxyEvent) {
+	repo := r.repository()
+	if len(batch) == 0 || repo == nil {
+		return
+	}
+
+	if err := repo.CreateBatch(ctx, batch); err != nil {
+		log.Printf("[ProxyGate] Failed to write %d proxy events: %v", len(batch), err)
+	}
+}
+
+func (r *eventRecorder) prune(ctx context.Context) error {
+	repo := r.repository()
+	if repo == nil || r.retention <= 0 {
+		return nil
+	}
+
+	deleted, err := repo.Prune(ctx, r.retention)
+	if err != nil {
+		return fmt.Errorf("prune proxy events: %w", err)
+	}
+	if deleted > 0 {
+		log.Printf("[ProxyGate] Pruned %d proxy events", deleted)
+	}
+
+	return nil
+}
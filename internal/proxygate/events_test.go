@@ -0,0 +1,42 @@
+package proxygate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"dial timeout", &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}, domain.ProxyFailConnectTimeout},
+		{"probe timeout", &url.Error{Op: "Head", Err: context.DeadlineExceeded}, domain.ProxyFailConnectTimeout},
+		{"refused", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, domain.ProxyFailConnectRefused},
+		{"socks5 reply", errors.New("socks connect tcp 1.2.3.4:1080->maps.google.com:443: unknown error connection refused"), domain.ProxyFailConnectRefused},
+		{"socks5 auth", errors.New("socks connect tcp 1.2.3.4:1080->maps.google.com:443: username/password authentication failed"), domain.ProxyFailAuth},
+		{"connect 407", fmt.Errorf("upstream CONNECT failed: %w", &statusError{code: 407, status: "407 Proxy Authentication Required"}), domain.ProxyFailAuth},
+		{"google 429", &statusError{code: 429, status: "429 Too Many Requests"}, domain.ProxyFailRateLimited},
+		{"captcha", fmt.Errorf("%w at www.google.com", errCaptcha), domain.ProxyFailCaptcha},
+		{"forbidden", &statusError{code: 403, status: "403 Forbidden"}, domain.ProxyFailBlocked},
+		{"tls", errors.New("tls handshake with upstream: EOF"), domain.ProxyFailTLS},
+		{"other", errors.New("EOF"), domain.ProxyFailOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyFailure(tt.err))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package proxygate
 import (
 	"bufio"
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -16,6 +17,10 @@ type Fetcher struct {
 	client      *http.Client
 	mu          sync.RWMutex
 	lastUpdated time.Time
+
+	// Per-source stats and the auto-disable policy
+	tracker *sourceTracker
+	timeout time.Duration // Bounds fetching one source
 }
 
 func NewFetcher(sources []string, pool *Pool) *Fetcher {
@@ -25,6 +30,8 @@ func NewFetcher(sources []string, pool *Pool) *Fetcher {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		tracker: newSourceTracker(DefaultSourceMinPassRate, DefaultSourceDisableAfter),
+		timeout: DefaultSourceTimeout,
 	}
 }
 
@@ -55,20 +62,17 @@ func (f *Fetcher) fetchAll(ctx context.Context) error {
 	copy(sources, f.sources)
 	f.mu.RUnlock()
 
+	// Sources are fetched at once, each within its own timeout, so a dead
+	// host doesn't stall the others
+	var wg sync.WaitGroup
 	for _, url := range sources {
-		var err error
-		if IsProxyDBURL(url) {
-			// Use HTML scraper for proxydb.net
-			err = f.fetchProxyDB(ctx)
-		} else {
-			// Use plain text parser for other sources
-			err = f.fetchOne(ctx, url)
-		}
-		if err != nil {
-			log.Printf("[ProxyGate] Fetch from %s failed: %v", url, err)
-			continue
-		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.refreshSource(ctx, url)
+		}()
 	}
+	wg.Wait()
 
 	f.mu.Lock()
 	f.lastUpdated = time.Now()
@@ -81,33 +85,73 @@ func (f *Fetcher) ForceRefresh(ctx context.Context) error {
 	return f.fetchAll(ctx)
 }
 
-func (f *Fetcher) fetchOne(ctx context.Context, url string) error {
+// refreshSource judges the previous refresh of url, then fetches it again
+// and queues its lines for validation unless it is disabled
+func (f *Fetcher) refreshSource(ctx context.Context, url string) {
+	stats, disabled := f.tracker.begin(url, time.Now())
+	if disabled {
+		log.Printf("[ProxyGate] Source %s disabled: %s", url, stats.DisabledReason)
+	}
+	f.tracker.save(ctx, url, stats)
+	if !stats.Active {
+		return
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, f.timeout)
+	start := time.Now()
+	var lines []string
+	var err error
+	if IsProxyDBURL(url) {
+		// Use HTML scraper for proxydb.net
+		lines, err = f.fetchProxyDB(fetchCtx)
+	} else {
+		// Use plain text parser for other sources
+		lines, err = f.fetchOne(fetchCtx, url)
+	}
+	cancel()
+
+	f.tracker.fetched(url, len(lines), time.Since(start), err, time.Now())
+	if err != nil {
+		log.Printf("[ProxyGate] Fetch from %s failed: %v", url, err)
+		return
+	}
+
+	for _, line := range lines {
+		select {
+		case f.pool.raw <- rawProxy{line: line, source: url}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (f *Fetcher) fetchOne(ctx context.Context, url string) ([]string, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	resp, err := f.client.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var lines []string
 	scanner := bufio.NewScanner(resp.Body)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
-
-		select {
-		case f.pool.raw <- line:
-		case <-ctx.Done():
-			return nil
-		}
+		lines = append(lines, line)
 	}
 
-	return scanner.Err()
+	return lines, scanner.Err()
 }
 
 func (f *Fetcher) LastUpdated() time.Time {
@@ -128,6 +172,8 @@ func (f *Fetcher) AddSource(url string) {
 }
 
 func (f *Fetcher) RemoveSource(url string) {
+	f.tracker.remove(url)
+
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	for i, s := range f.sources {
@@ -147,24 +147,11 @@ func extractCountry(row string) string {
 	return ""
 }
 
-// AddProxyDBSource adds proxydb.net as a special source that uses HTML scraping
-// This is called by the fetcher when it detects a proxydb.net URL
-func (f *Fetcher) fetchProxyDB(ctx context.Context) error {
+// fetchProxyDB scrapes proxydb.net, which needs HTML parsing rather than a
+// plain list. This is called by the fetcher when it detects a proxydb.net URL
+func (f *Fetcher) fetchProxyDB(ctx context.Context) ([]string, error) {
 	// Quality filter: uptime >= 70%, response <= 5 seconds
-	proxies, err := FetchProxyDB(ctx, 70.0, 5.0)
-	if err != nil {
-		return err
-	}
-
-	for _, proxy := range proxies {
-		select {
-		case f.pool.raw <- proxy:
-		case <-ctx.Done():
-			return nil
-		}
-	}
-
-	return nil
+	return FetchProxyDB(ctx, 70.0, 5.0)
 }
 
 // IsProxyDBURL checks if a URL is proxydb.net
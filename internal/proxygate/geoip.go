@@ -0,0 +1,117 @@
+package proxygate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GeoResolver maps a proxy host to an ISO 3166-1 alpha-2 country code.
+// The validator uses it to tag proxies so jobs can ask for exits in a
+// given country.
+type GeoResolver interface {
+	Country(ctx context.Context, host string) (string, error)
+}
+
+// NormalizeCountry returns the upper-case two letter code, or "" when
+// code does not look like an ISO country code.
+func NormalizeCountry(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+		return ""
+	}
+
+	return code
+}
+
+// HTTPGeoResolver looks countries up in a GeoIP web service. The URL
+// template must contain {ip}, e.g. "https://ipinfo.io/{ip}/country" or
+// "http://ip-api.com/json/{ip}?fields=countryCode". Plain-text bodies and
+// JSON objects with a country_code, countryCode or country field are
+// understood. Results are cached for the life of the resolver.
+type HTTPGeoResolver struct {
+	urlTemplate string
+	client      *http.Client
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// NewHTTPGeoResolver creates a resolver for the given URL template
+func NewHTTPGeoResolver(urlTemplate string) *HTTPGeoResolver {
+	return &HTTPGeoResolver{
+		urlTemplate: urlTemplate,
+		client:      &http.Client{Timeout: 5 * time.Second},
+		cache:       make(map[string]string),
+	}
+}
+
+func (r *HTTPGeoResolver) Country(ctx context.Context, host string) (string, error) {
+	r.mu.Lock()
+	country, ok := r.cache[host]
+	r.mu.Unlock()
+
+	if ok {
+		return country, nil
+	}
+
+	lookupURL := strings.ReplaceAll(r.urlTemplate, "{ip}", url.PathEscape(host))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, lookupURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("geoip request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("geoip lookup %s: %w", host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("geoip lookup %s: status %d", host, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", fmt.Errorf("geoip lookup %s: %w", host, err)
+	}
+
+	country = parseGeoResponse(body)
+	if country == "" {
+		return "", fmt.Errorf("geoip lookup %s: no country in response", host)
+	}
+
+	r.mu.Lock()
+	r.cache[host] = country
+	r.mu.Unlock()
+
+	return country, nil
+}
+
+func parseGeoResponse(body []byte) string {
+	if country := NormalizeCountry(string(body)); country != "" {
+		return country
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
+	}
+
+	for _, key := range []string{"country_code", "countryCode", "country"} {
+		if s, ok := fields[key].(string); ok {
+			if country := NormalizeCountry(s); country != "" {
+				return country
+			}
+		}
+	}
+
+	return ""
+}
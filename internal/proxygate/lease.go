@@ -0,0 +1,171 @@
+package proxygate
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+const (
+	// DefaultLeaseTTL is how long leased proxies stay a worker's
+	DefaultLeaseTTL = 30 * time.Minute
+
+	// MaxLeaseCount caps the proxies of one lease
+	MaxLeaseCount = 100
+)
+
+// Lease picks up to count healthy proxies for holder, by score like
+// GetNextInCountry does, and holds them for holder until ttl passes.
+// Proxies other holders lease are only handed out when there are not
+// enough free ones, those held by the fewest holders first. A new lease
+// of holder replaces its previous one.
+func (p *Pool) Lease(holder, country string, count int, ttl time.Duration) ([]*domain.Proxy, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	p.sweepLeasesLocked(now)
+	for _, holders := range p.leases {
+		delete(holders, holder)
+	}
+
+	var free, held []*domain.Proxy
+	for _, proxy := range p.proxies {
+		if country != "" && !strings.EqualFold(proxy.Country, country) {
+			continue
+		}
+		if len(p.leases[proxyKey(proxy)]) == 0 {
+			free = append(free, proxy)
+		} else {
+			held = append(held, proxy)
+		}
+	}
+
+	if len(free)+len(held) == 0 {
+		if country != "" {
+			return nil, fmt.Errorf("no healthy proxies for country %s", country)
+		}
+		return nil, errors.New("no healthy proxies available")
+	}
+
+	leased := make([]*domain.Proxy, 0, min(count, len(free)+len(held)))
+	for len(leased) < count && len(free) > 0 {
+		pick := p.selectLocked(free)
+		leased = append(leased, pick)
+		free = slices.DeleteFunc(free, func(proxy *domain.Proxy) bool { return proxy == pick })
+	}
+
+	if len(leased) < count {
+		slices.SortStableFunc(held, func(a, b *domain.Proxy) int {
+			return len(p.leases[proxyKey(a)]) - len(p.leases[proxyKey(b)])
+		})
+		leased = append(leased, held[:min(count-len(leased), len(held))]...)
+	}
+
+	until := now.Add(ttl)
+	for _, proxy := range leased {
+		key := proxyKey(proxy)
+		if p.leases[key] == nil {
+			p.leases[key] = make(map[string]time.Time)
+		}
+		p.leases[key][holder] = until
+	}
+
+	return leased, nil
+}
+
+// sweepLeasesLocked drops expired leases. Caller holds p.mu.
+func (p *Pool) sweepLeasesLocked(now time.Time) {
+	for key, holders := range p.leases {
+		for holder, until := range holders {
+			if !until.After(now) {
+				delete(holders, holder)
+			}
+		}
+		if len(holders) == 0 {
+			delete(p.leases, key)
+		}
+	}
+}
+
+// ReportLeaseFailure counts a failure a worker saw through a proxy of its
+// lease towards the proxy's score, which quarantines it after repeated
+// failures, and ends the holder's lease of it. It reports false for
+// proxies that are not in rotation.
+func (p *Pool) ReportLeaseFailure(holder, proxyAddr string, cause error, jobID *uuid.UUID) bool {
+	ip, port, err := parseProxyAddress(proxyAddr)
+	if err != nil {
+		return false
+	}
+
+	p.mu.Lock()
+	var proxy *domain.Proxy
+	for _, existing := range p.proxies {
+		if existing.IP == ip && existing.Port == port {
+			proxy = existing
+			break
+		}
+	}
+	if proxy != nil {
+		delete(p.leases[proxyKey(proxy)], holder)
+	}
+	p.mu.Unlock()
+
+	if proxy == nil {
+		return false
+	}
+
+	p.RecordResult(proxy, 0, cause, jobID)
+
+	return true
+}
+
+// LeaseProxies leases up to count healthy proxies, in country when one
+// is given, to the worker holder for the configured lease TTL
+func (pg *ProxyGate) LeaseProxies(holder, country string, count int) (*domain.ProxyLease, error) {
+	ttl := pg.cfg.LeaseTTL
+	if ttl <= 0 {
+		ttl = DefaultLeaseTTL
+	}
+
+	proxies, err := pg.pool.Lease(holder, NormalizeCountry(country), count, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	lease := &domain.ProxyLease{
+		Proxies:   make([]*domain.LeasedProxy, 0, len(proxies)),
+		ExpiresAt: time.Now().Add(ttl).UTC(),
+	}
+	for _, proxy := range proxies {
+		lease.Proxies = append(lease.Proxies, &domain.LeasedProxy{
+			ID:      proxy.ID,
+			URL:     proxyURL(proxy),
+			Country: proxy.Country,
+		})
+	}
+
+	return lease, nil
+}
+
+// ReportProxyFailures demotes the leased proxies a worker reports as
+// failing and returns how many were still in rotation
+func (pg *ProxyGate) ReportProxyFailures(report *domain.ProxyFailureReport) int {
+	known := 0
+	for _, f := range report.Failures {
+		cause := errors.New(f.Error)
+		if f.Error == "" {
+			cause = errors.New("reported by worker")
+		}
+		if pg.pool.ReportLeaseFailure(report.WorkerID, f.URL, cause, report.JobID) {
+			known++
+		}
+	}
+	return known
+}
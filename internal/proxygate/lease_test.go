@@ -0,0 +1,90 @@
+package proxygate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+func leaseTestPool(t *testing.T, addrs ...string) *Pool {
+	t.Helper()
+
+	p := NewPool()
+	for _, addr := range addrs {
+		proxy, err := ParseProxyURL(addr, ProtocolSOCKS5)
+		require.NoError(t, err)
+		proxy.Country = "DE"
+		p.AddValidatedProxy(proxy)
+	}
+	return p
+}
+
+func leasedKeys(proxies []*domain.Proxy) []string {
+	keys := make([]string, len(proxies))
+	for i, proxy := range proxies {
+		keys[i] = proxyKey(proxy)
+	}
+	return keys
+}
+
+func TestPoolLease(t *testing.T) {
+	t.Run("prefers proxies other workers do not hold", func(t *testing.T) {
+		p := leaseTestPool(t, "10.0.0.1:1080", "10.0.0.2:1080", "10.0.0.3:1080", "10.0.0.4:1080")
+
+		first, err := p.Lease("worker-a", "", 2, time.Minute)
+		require.NoError(t, err)
+		second, err := p.Lease("worker-b", "", 2, time.Minute)
+		require.NoError(t, err)
+
+		assert.Len(t, first, 2)
+		assert.Len(t, second, 2)
+		assert.NotContains(t, leasedKeys(second), leasedKeys(first)[0])
+		assert.NotContains(t, leasedKeys(second), leasedKeys(first)[1])
+
+		// Only held proxies are left, so they are shared
+		third, err := p.Lease("worker-c", "", 3, time.Minute)
+		require.NoError(t, err)
+		assert.Len(t, third, 3)
+	})
+
+	t.Run("expired leases free their proxies", func(t *testing.T) {
+		p := leaseTestPool(t, "10.0.0.1:1080", "10.0.0.2:1080")
+
+		_, err := p.Lease("worker-a", "", 1, -time.Second)
+		require.NoError(t, err)
+		leased, err := p.Lease("worker-b", "", 2, time.Minute)
+		require.NoError(t, err)
+		assert.Len(t, leased, 2)
+	})
+
+	t.Run("filters by country", func(t *testing.T) {
+		p := leaseTestPool(t, "10.0.0.1:1080")
+
+		_, err := p.Lease("worker-a", "FR", 1, time.Minute)
+		assert.Error(t, err)
+
+		leased, err := p.Lease("worker-a", "DE", 5, time.Minute)
+		require.NoError(t, err)
+		assert.Len(t, leased, 1)
+	})
+}
+
+func TestPoolReportLeaseFailure(t *testing.T) {
+	p := leaseTestPool(t, "10.0.0.1:1080", "10.0.0.2:1080")
+
+	leased, err := p.Lease("worker-a", "", 1, time.Minute)
+	require.NoError(t, err)
+	addr := proxyKey(leased[0])
+
+	for range maxConsecutiveFails {
+		assert.True(t, p.ReportLeaseFailure("worker-a", "socks5://"+addr, errors.New("blocked"), nil))
+	}
+
+	assert.Equal(t, 1, p.Size(), "repeatedly failing proxy is quarantined")
+	assert.False(t, p.ReportLeaseFailure("worker-a", addr, errors.New("blocked"), nil), "quarantined proxy is no longer in rotation")
+}
@@ -5,42 +5,92 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strconv"
+	"math/rand/v2"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/sadewadee/google-scraper/internal/domain"
 )
 
+const (
+	// quarantineBackoff is the wait before the first revalidation of a
+	// quarantined proxy; it doubles after every failed revalidation.
+	quarantineBackoff = time.Minute
+
+	// maxRevalidations drops a quarantined proxy for good after this many
+	// failed revalidations.
+	maxRevalidations = 5
+)
+
+// rawProxy is a fetched proxy line waiting for validation
+type rawProxy struct {
+	line   string
+	source string // URL of the list it came from
+}
+
+// quarantineEntry is a proxy taken out of rotation until it revalidates
+type quarantineEntry struct {
+	proxy     *domain.Proxy
+	since     time.Time
+	nextCheck time.Time
+	attempts  int
+}
+
 // Pool manages a pool of proxies with optional database persistence
 type Pool struct {
 	mu      sync.RWMutex
 	proxies []*domain.Proxy // In-memory cache of healthy proxies
-	index   int
-	raw     chan string // Channel for raw fetched proxies
-	valid   chan string // Channel for validated proxies
+	raw     chan rawProxy   // Channel for raw fetched proxies
+	valid   chan string     // Channel for validated proxies
+
+	// Live scoring, keyed by IP:port
+	scores      map[string]*proxyScore
+	quarantine  map[string]*quarantineEntry
+	exploration float64 // Share of picks given to unproven proxies
+
+	// Position of the next revalidation slice in proxies
+	revalidateCursor int
+
+	// Workers holding each proxy, keyed by IP:port, with their lease expiry
+	leases map[string]map[string]time.Time
 
 	// Database persistence (optional)
 	repo domain.ProxyListRepository
+
+	// Log of failures and recoveries (optional)
+	events *eventRecorder
 }
 
 // NewPool creates a new proxy pool (in-memory only)
 func NewPool() *Pool {
 	return &Pool{
-		proxies: make([]*domain.Proxy, 0),
-		raw:     make(chan string, 10000),
-		valid:   make(chan string, 1000),
+		proxies:     make([]*domain.Proxy, 0),
+		raw:         make(chan rawProxy, 10000),
+		valid:       make(chan string, 1000),
+		scores:      make(map[string]*proxyScore),
+		quarantine:  make(map[string]*quarantineEntry),
+		leases:      make(map[string]map[string]time.Time),
+		exploration: DefaultExplorationRatio,
 	}
 }
 
 // NewPoolWithRepo creates a new proxy pool with database persistence
 func NewPoolWithRepo(repo domain.ProxyListRepository) *Pool {
-	return &Pool{
-		proxies: make([]*domain.Proxy, 0),
-		raw:     make(chan string, 10000),
-		valid:   make(chan string, 1000),
-		repo:    repo,
-	}
+	pool := NewPool()
+	pool.repo = repo
+
+	return pool
+}
+
+// SetExplorationRatio sets the share of picks (0-1) that go to proxies
+// without enough samples to be scored
+func (p *Pool) SetExplorationRatio(ratio float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.exploration = min(max(ratio, 0), 1)
 }
 
 // SetRepo sets the database repository for persistence (can be called after construction)
@@ -69,15 +119,21 @@ func (p *Pool) LoadFromDatabase(ctx context.Context) error {
 	}
 
 	p.mu.Lock()
-	p.proxies = proxies
-	p.index = 0
+	// Quarantined proxies only come back through revalidation
+	healthy := make([]*domain.Proxy, 0, len(proxies))
+	for _, proxy := range proxies {
+		if _, ok := p.quarantine[proxyKey(proxy)]; !ok {
+			healthy = append(healthy, proxy)
+		}
+	}
+	p.proxies = healthy
 	p.mu.Unlock()
 
 	log.Printf("[ProxyGate] Loaded %d healthy proxies from database", len(proxies))
 	return nil
 }
 
-// GetNext returns the next proxy in round-robin fashion
+// GetNext returns the next proxy, weighted by its live score
 func (p *Pool) GetNext() (string, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -86,8 +142,7 @@ func (p *Pool) GetNext() (string, error) {
 		return "", errors.New("no healthy proxies available")
 	}
 
-	proxy := p.proxies[p.index]
-	p.index = (p.index + 1) % len(p.proxies)
+	proxy := p.selectLocked(p.proxies)
 
 	// Mark as used (async, don't block)
 	if p.repo != nil {
@@ -99,16 +154,18 @@ func (p *Pool) GetNext() (string, error) {
 		}(proxy.ID)
 	}
 
-	// Return full URL with protocol scheme (e.g., socks5://192.168.1.1:1080)
-	protocol := proxy.Protocol
-	if protocol == "" {
-		protocol = "socks5"
-	}
-	return fmt.Sprintf("%s://%s:%d", protocol, proxy.IP, proxy.Port), nil
+	return proxyURL(proxy), nil
 }
 
 // GetNextWithID returns the next proxy with its database ID
 func (p *Pool) GetNextWithID() (*domain.Proxy, error) {
+	return p.GetNextInCountry("")
+}
+
+// GetNextInCountry returns the next proxy whose country matches. An empty
+// country selects from the whole pool. Proxies with an unknown country
+// never match a country filter.
+func (p *Pool) GetNextInCountry(country string) (*domain.Proxy, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -116,32 +173,333 @@ func (p *Pool) GetNextWithID() (*domain.Proxy, error) {
 		return nil, errors.New("no healthy proxies available")
 	}
 
-	proxy := p.proxies[p.index]
-	p.index = (p.index + 1) % len(p.proxies)
+	if country == "" {
+		return p.selectLocked(p.proxies), nil
+	}
+
+	var candidates []*domain.Proxy
+	for _, proxy := range p.proxies {
+		if strings.EqualFold(proxy.Country, country) {
+			candidates = append(candidates, proxy)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no healthy proxies for country %s", country)
+	}
 
-	return proxy, nil
+	return p.selectLocked(candidates), nil
+}
+
+// selectLocked picks one of candidates: usually weighted by score among
+// proven proxies, and with probability p.exploration uniformly among
+// unproven ones so new proxies get a chance to build a score. Caller
+// holds p.mu.
+func (p *Pool) selectLocked(candidates []*domain.Proxy) *domain.Proxy {
+	var proven, unproven []*domain.Proxy
+
+	for _, proxy := range candidates {
+		if p.scoreLocked(proxy).proven() {
+			proven = append(proven, proxy)
+		} else {
+			unproven = append(unproven, proxy)
+		}
+	}
+
+	if len(unproven) > 0 && (len(proven) == 0 || rand.Float64() < p.exploration) {
+		return unproven[rand.IntN(len(unproven))]
+	}
+
+	weights := make([]float64, len(proven))
+	for i, proxy := range proven {
+		weights[i] = p.scoreLocked(proxy).weight()
+	}
+
+	return proven[pickWeighted(weights)]
+}
+
+// InRotation reports whether the proxy is currently in the healthy pool
+func (p *Pool) InRotation(proxy *domain.Proxy) bool {
+	key := proxyKey(proxy)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, existing := range p.proxies {
+		if proxyKey(existing) == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scoreLocked returns the score for a proxy, creating it if needed. Caller holds p.mu.
+func (p *Pool) scoreLocked(proxy *domain.Proxy) *proxyScore {
+	key := proxyKey(proxy)
+
+	score, ok := p.scores[key]
+	if !ok {
+		score = newProxyScore()
+		p.scores[key] = score
+	}
+
+	return score
+}
+
+// RecordResult feeds the outcome of a dial through proxy into its score;
+// a nil err is a success. After maxConsecutiveFails failures in a row the
+// proxy is quarantined, which is reported by the return value. jobID, if
+// known, is logged with the failure.
+func (p *Pool) RecordResult(proxy *domain.Proxy, latency time.Duration, err error, jobID *uuid.UUID) bool {
+	success := err == nil
+
+	p.mu.Lock()
+	score := p.scoreLocked(proxy)
+	score.observe(success, latency)
+
+	quarantined := !success && score.consecutiveFails >= maxConsecutiveFails
+	if quarantined {
+		p.quarantineLocked(proxy)
+	}
+	p.mu.Unlock()
+
+	if !success {
+		p.events.record(proxy, domain.ProxyEventFailed, err, jobID)
+	}
+
+	if quarantined {
+		log.Printf("[ProxyGate] Quarantined proxy %s after %d consecutive failures", proxyKey(proxy), maxConsecutiveFails)
+		p.events.record(proxy, domain.ProxyEventQuarantined, err, jobID)
+
+		// Pending keeps it out of ListHealthy until it revalidates
+		if p.repo != nil && proxy.ID != 0 {
+			go func(id int64) {
+				if err := p.repo.UpdateStatus(context.Background(), id, domain.ProxyStatusPending); err != nil {
+					log.Printf("[ProxyGate] Failed to mark proxy %d as pending: %v", id, err)
+				}
+			}(proxy.ID)
+		}
+	}
+
+	return quarantined
+}
+
+// quarantineLocked moves a proxy from rotation into quarantine. Caller holds p.mu.
+func (p *Pool) quarantineLocked(proxy *domain.Proxy) {
+	key := proxyKey(proxy)
+
+	for i, existing := range p.proxies {
+		if proxyKey(existing) == key {
+			p.proxies = append(p.proxies[:i], p.proxies[i+1:]...)
+			break
+		}
+	}
+
+	now := time.Now()
+	p.quarantine[key] = &quarantineEntry{
+		proxy:     proxy,
+		since:     now,
+		nextCheck: now.Add(quarantineBackoff),
+	}
+}
+
+// Quarantined reports whether the proxy is waiting for revalidation
+func (p *Pool) Quarantined(proxy *domain.Proxy) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	_, ok := p.quarantine[proxyKey(proxy)]
+	return ok
+}
+
+// NextRevalidationBatch returns up to n proxies in rotation, continuing
+// where the previous batch ended so that successive calls cycle through
+// the whole pool
+func (p *Pool) NextRevalidationBatch(n int) []*domain.Proxy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n = min(n, len(p.proxies))
+	if n <= 0 {
+		return nil
+	}
+
+	// The pool shrinks on reloads and quarantines
+	if p.revalidateCursor >= len(p.proxies) {
+		p.revalidateCursor = 0
+	}
+
+	batch := make([]*domain.Proxy, 0, n)
+	for i := 0; i < n; i++ {
+		batch = append(batch, p.proxies[(p.revalidateCursor+i)%len(p.proxies)])
+	}
+	p.revalidateCursor = (p.revalidateCursor + n) % len(p.proxies)
+
+	return batch
+}
+
+// QuarantineDue returns quarantined proxies whose revalidation is due
+func (p *Pool) QuarantineDue(now time.Time) []*domain.Proxy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var due []*domain.Proxy
+	for _, entry := range p.quarantine {
+		if !entry.nextCheck.After(now) {
+			due = append(due, entry.proxy)
+		}
+	}
+
+	return due
+}
+
+// Release returns a quarantined proxy to rotation after a successful
+// revalidation. Its score is reset so it starts out as unproven.
+func (p *Pool) Release(proxy *domain.Proxy) {
+	key := proxyKey(proxy)
+
+	p.mu.Lock()
+	if _, ok := p.quarantine[key]; !ok {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.quarantine, key)
+	p.scores[key] = newProxyScore()
+	p.proxies = append(p.proxies, proxy)
+	p.mu.Unlock()
+
+	log.Printf("[ProxyGate] Proxy %s passed revalidation, back in rotation", key)
+	p.events.record(proxy, domain.ProxyEventRecovered, nil, nil)
+
+	if p.repo != nil && proxy.ID != 0 {
+		if err := p.repo.IncrementSuccessCount(context.Background(), proxy.ID); err != nil {
+			log.Printf("[ProxyGate] Failed to mark proxy %d as healthy: %v", proxy.ID, err)
+		}
+	}
+}
+
+// RevalidationFailed backs off the next revalidation of a quarantined
+// proxy, and drops it as dead after maxRevalidations attempts. err is the
+// reason the last probe failed.
+func (p *Pool) RevalidationFailed(proxy *domain.Proxy, err error) {
+	key := proxyKey(proxy)
+
+	p.mu.Lock()
+	entry, ok := p.quarantine[key]
+	if !ok {
+		p.mu.Unlock()
+		return
+	}
+
+	entry.attempts++
+	dead := entry.attempts >= maxRevalidations
+	if dead {
+		delete(p.quarantine, key)
+		delete(p.scores, key)
+	} else {
+		entry.nextCheck = time.Now().Add(quarantineBackoff << entry.attempts)
+	}
+	p.mu.Unlock()
+
+	if dead {
+		p.events.record(proxy, domain.ProxyEventDead, err, nil)
+	}
+
+	if dead && p.repo != nil && proxy.ID != 0 {
+		if err := p.repo.UpdateStatus(context.Background(), proxy.ID, domain.ProxyStatusDead); err != nil {
+			log.Printf("[ProxyGate] Failed to mark proxy %d as dead: %v", proxy.ID, err)
+		}
+	}
+}
+
+// Score returns the live score of a proxy by database ID, whether it is
+// in rotation or quarantined
+func (p *Pool) Score(id int64) (*ProxyScore, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, proxy := range p.proxies {
+		if proxy.ID == id {
+			snap := p.scoreLocked(proxy).snapshot()
+			snap.ProxyID = id
+			snap.Address = proxyKey(proxy)
+			return &snap, true
+		}
+	}
+
+	for key, entry := range p.quarantine {
+		if entry.proxy.ID == id {
+			snap := p.scoreLocked(entry.proxy).snapshot()
+			snap.ProxyID = id
+			snap.Address = key
+			snap.Quarantined = true
+			since := entry.since
+			snap.QuarantinedAt = &since
+			return &snap, true
+		}
+	}
+
+	return nil, false
+}
+
+// FlushScores persists changed scores to the proxies table as uptime
+// (success rate in percent) and response_time (seconds)
+func (p *Pool) FlushScores(ctx context.Context) error {
+	if p.repo == nil {
+		return nil
+	}
+
+	type metrics struct {
+		id           int64
+		uptime       float64
+		responseTime float64
+	}
+
+	p.mu.Lock()
+	var pending []metrics
+	for _, proxy := range p.proxies {
+		score, ok := p.scores[proxyKey(proxy)]
+		if !ok || !score.dirty || proxy.ID == 0 {
+			continue
+		}
+		score.dirty = false
+		pending = append(pending, metrics{
+			id:           proxy.ID,
+			uptime:       score.successRate * 100,
+			responseTime: score.latencyMs / 1000,
+		})
+	}
+	p.mu.Unlock()
+
+	for _, m := range pending {
+		if err := p.repo.UpdateMetrics(ctx, m.id, m.uptime, m.responseTime); err != nil {
+			return fmt.Errorf("update metrics for proxy %d: %w", m.id, err)
+		}
+	}
+
+	return nil
 }
 
 // AddValidated adds a validated proxy to the pool
 func (p *Pool) AddValidated(proxyAddr string) {
-	ip, port, err := parseProxyAddress(proxyAddr)
+	proxy, err := ParseProxyURL(proxyAddr, ProtocolSOCKS5)
 	if err != nil {
 		log.Printf("[ProxyGate] Invalid proxy address %s: %v", proxyAddr, err)
 		return
 	}
 
-	proxy := &domain.Proxy{
-		IP:       ip,
-		Port:     port,
-		Protocol: "socks5",
-		Status:   domain.ProxyStatusHealthy,
-	}
+	proxy.Status = domain.ProxyStatusHealthy
 
 	p.AddValidatedProxy(proxy)
 }
 
 // AddValidatedProxy adds a validated proxy object to the pool
 func (p *Pool) AddValidatedProxy(proxy *domain.Proxy) {
+	p.mu.RLock()
+	_, quarantined := p.quarantine[proxyKey(proxy)]
+	p.mu.RUnlock()
+
 	// Save to database first
 	if p.repo != nil {
 		ctx := context.Background()
@@ -151,6 +509,12 @@ func (p *Pool) AddValidatedProxy(proxy *domain.Proxy) {
 		}
 	}
 
+	// A fresh validation counts as revalidation for a quarantined proxy
+	if quarantined {
+		p.Release(proxy)
+		return
+	}
+
 	// Add to memory cache
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -277,33 +641,51 @@ func (p *Pool) GetStats(ctx context.Context) (*domain.ProxyStats, error) {
 		p.mu.RLock()
 		defer p.mu.RUnlock()
 		return &domain.ProxyStats{
-			Total:   len(p.proxies),
-			Healthy: len(p.proxies),
+			Total:      len(p.proxies),
+			Healthy:    len(p.proxies),
+			ByProtocol: p.protocolCountsLocked(),
 		}, nil
 	}
 
 	return p.repo.GetStats(ctx)
 }
 
-// CleanupDead removes dead proxies from database
-func (p *Pool) CleanupDead(ctx context.Context) (int, error) {
-	if p.repo == nil {
-		return 0, nil
-	}
-	return p.repo.DeleteDead(ctx)
+// ProtocolCounts returns the number of in-rotation proxies per protocol
+func (p *Pool) ProtocolCounts() map[string]int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.protocolCountsLocked()
 }
 
-// parseProxyAddress parses "IP:port" string
-func parseProxyAddress(addr string) (string, int, error) {
-	parts := strings.Split(addr, ":")
-	if len(parts) != 2 {
-		return "", 0, fmt.Errorf("invalid proxy address: %s", addr)
+func (p *Pool) protocolCountsLocked() map[string]int {
+	counts := make(map[string]int)
+	for _, proxy := range p.proxies {
+		protocol := proxy.Protocol
+		if protocol == "" {
+			protocol = ProtocolSOCKS5
+		}
+		counts[protocol]++
 	}
+	return counts
+}
 
-	port, err := strconv.Atoi(parts[1])
-	if err != nil {
-		return "", 0, fmt.Errorf("invalid port: %s", parts[1])
+// CountryCounts returns the number of in-memory proxies per country.
+// Proxies without a country are counted under "".
+func (p *Pool) CountryCounts() map[string]int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, proxy := range p.proxies {
+		counts[strings.ToUpper(proxy.Country)]++
 	}
+	return counts
+}
 
-	return parts[0], port, nil
+// CleanupDead removes dead proxies from database
+func (p *Pool) CleanupDead(ctx context.Context) (int, error) {
+	if p.repo == nil {
+		return 0, nil
+	}
+	return p.repo.DeleteDead(ctx)
 }
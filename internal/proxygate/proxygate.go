@@ -12,40 +12,93 @@ import (
 // DefaultPoolRefreshInterval is the default interval for refreshing pool from database
 const DefaultPoolRefreshInterval = 2 * time.Minute
 
+// quarantineCheckInterval is how often quarantined proxies are checked for revalidation
+const quarantineCheckInterval = 30 * time.Second
+
 type ProxyGate struct {
-	cfg       *Config
-	pool      *Pool
-	fetcher   *Fetcher
-	validator *Validator
-	server    *Server
+	cfg         *Config
+	pool        *Pool
+	fetcher     *Fetcher
+	validator   *Validator
+	server      *Server
+	sessions    *sessionStore
+	revalidator *Revalidator
+	events      *eventRecorder
+	usage       *usageMeter
+
+	// Set by SetSharedMaintenance: Run leaves RunMaintenance to the caller
+	sharedMaintenance bool
 }
 
 func New(cfg *Config) *ProxyGate {
 	pool := NewPool()
+	if cfg.ExplorationRatio > 0 {
+		pool.SetExplorationRatio(cfg.ExplorationRatio)
+	}
+	pool.events = newEventRecorder(cfg.EventRetention)
 	fetcher := NewFetcher(cfg.SourceURLs, pool)
+	fetcher.tracker = newSourceTracker(cfg.SourceMinPassRate, cfg.SourceDisableAfter)
+	if cfg.SourceTimeout > 0 {
+		fetcher.timeout = cfg.SourceTimeout
+	}
 	validator := NewValidator(cfg.ValidatorConcurrency, pool)
-	server := NewServer(cfg.ListenAddr, pool)
+	validator.sources = fetcher.tracker
+	if cfg.GeoIPURL != "" {
+		validator.SetGeoResolver(NewHTTPGeoResolver(cfg.GeoIPURL))
+	}
+	validator.SetProbe(cfg.ConnectivityURL, cfg.ProbeURL, cfg.ProbeTimeout)
+	sessions := newSessionStore(cfg.SessionTTL, cfg.MaxSessions)
+	server := NewServer(cfg.ListenAddr, pool, sessions)
+	server.usage = newUsageMeter(cfg.UsageFlushInterval)
+	revalidator := NewRevalidator(pool, validator, cfg.RevalidateInterval, cfg.RevalidateBatch, cfg.ProbeConcurrency)
 
 	return &ProxyGate{
-		cfg:       cfg,
-		pool:      pool,
-		fetcher:   fetcher,
-		validator: validator,
-		server:    server,
+		cfg:         cfg,
+		pool:        pool,
+		fetcher:     fetcher,
+		validator:   validator,
+		server:      server,
+		sessions:    sessions,
+		revalidator: revalidator,
+		events:      pool.events,
+		usage:       server.usage,
 	}
 }
 
 func (pg *ProxyGate) Run(ctx context.Context) error {
 	egroup, ctx := errgroup.WithContext(ctx)
 
-	egroup.Go(func() error { return pg.fetcher.Run(ctx) })
+	if !pg.sharedMaintenance {
+		egroup.Go(func() error { return pg.RunMaintenance(ctx) })
+	}
 	egroup.Go(func() error { return pg.validator.Run(ctx) })
 	egroup.Go(func() error { return pg.server.Run(ctx) })
 	egroup.Go(func() error { return pg.runPoolRefresher(ctx) })
+	egroup.Go(func() error { return pg.runQuarantineChecker(ctx) })
+	egroup.Go(func() error { return pg.events.Run(ctx) })
+	egroup.Go(func() error { return pg.usage.Run(ctx) })
+
+	return egroup.Wait()
+}
+
+// RunMaintenance fetches the sources and revalidates the pool on their
+// schedules. Both write to the shared proxy list, so with several manager
+// replicas only one of them should run it; see SetSharedMaintenance.
+func (pg *ProxyGate) RunMaintenance(ctx context.Context) error {
+	egroup, ctx := errgroup.WithContext(ctx)
+
+	egroup.Go(func() error { return pg.fetcher.Run(ctx) })
+	egroup.Go(func() error { return pg.revalidator.Run(ctx) })
 
 	return egroup.Wait()
 }
 
+// SetSharedMaintenance makes Run skip RunMaintenance, which the caller
+// runs instead. Must be called before Run.
+func (pg *ProxyGate) SetSharedMaintenance() {
+	pg.sharedMaintenance = true
+}
+
 // runPoolRefresher periodically reloads proxies from database
 // This ensures any proxies added directly to DB are picked up
 func (pg *ProxyGate) runPoolRefresher(ctx context.Context) error {
@@ -60,6 +113,9 @@ func (pg *ProxyGate) runPoolRefresher(ctx context.Context) error {
 			if !pg.pool.HasRepo() {
 				continue // No database configured
 			}
+			if err := pg.pool.FlushScores(ctx); err != nil {
+				log.Printf("[ProxyGate] Score flush failed: %v", err)
+			}
 			if err := pg.pool.LoadFromDatabase(ctx); err != nil {
 				log.Printf("[ProxyGate] Pool refresh failed: %v", err)
 			}
@@ -67,6 +123,28 @@ func (pg *ProxyGate) runPoolRefresher(ctx context.Context) error {
 	}
 }
 
+// runQuarantineChecker revalidates quarantined proxies whose backoff has
+// expired. Only proxies that pass go back into rotation.
+func (pg *ProxyGate) runQuarantineChecker(ctx context.Context) error {
+	ticker := time.NewTicker(quarantineCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			for _, proxy := range pg.pool.QuarantineDue(now) {
+				if _, err := pg.validator.probe(ctx, proxyURL(proxy)); err == nil {
+					pg.pool.Release(proxy)
+				} else {
+					pg.pool.RevalidationFailed(proxy, err)
+				}
+			}
+		}
+	}
+}
+
 func (pg *ProxyGate) Refresh(ctx context.Context) error {
 	return pg.fetcher.ForceRefresh(ctx)
 }
@@ -77,6 +155,22 @@ func (pg *ProxyGate) GetStats() (int, int, time.Time) {
 	return pg.pool.Size(), pg.pool.Size(), pg.fetcher.LastUpdated()
 }
 
+// LastRevalidation returns the summary of the last revalidation cycle, or
+// nil if none has completed
+func (pg *ProxyGate) LastRevalidation() *RevalidationRun {
+	return pg.revalidator.LastRun()
+}
+
+// ProtocolCounts returns the number of in-rotation proxies per protocol
+func (pg *ProxyGate) ProtocolCounts() map[string]int {
+	return pg.pool.ProtocolCounts()
+}
+
+// ListenAddr returns the address the gateway serves proxies on
+func (pg *ProxyGate) ListenAddr() string {
+	return pg.cfg.ListenAddr
+}
+
 func (pg *ProxyGate) GetSources() []string {
 	return pg.fetcher.GetSources()
 }
@@ -89,12 +183,51 @@ func (pg *ProxyGate) RemoveSource(url string) {
 	pg.fetcher.RemoveSource(url)
 }
 
+// RestoreSource adds a source stored in the database along with its
+// stats, so a disabled source stays disabled
+func (pg *ProxyGate) RestoreSource(source *domain.ProxySource) {
+	pg.fetcher.tracker.restore(source.URL, source.ProxySourceStats)
+	pg.fetcher.AddSource(source.URL)
+}
+
+// SourceStats returns the live stats of a source, false if ProxyGate has
+// none for it yet
+func (pg *ProxyGate) SourceStats(url string) (domain.ProxySourceStats, bool) {
+	return pg.fetcher.tracker.snapshot(url)
+}
+
+// SetSourceActive enables or disables a source and returns its stats. A
+// disabled source is skipped by refreshes until enabled again.
+func (pg *ProxyGate) SetSourceActive(url string, active bool) domain.ProxySourceStats {
+	stats := pg.fetcher.tracker.setActive(url, active, time.Now())
+	log.Printf("[ProxyGate] Source %s set active=%t", url, active)
+	return stats
+}
+
+// SetSourceRepo enables saving the stats of the sources stored in the
+// database. Like SetPoolRepo it can be called after construction.
+func (pg *ProxyGate) SetSourceRepo(repo domain.ProxyRepository) {
+	pg.fetcher.tracker.setRepo(repo)
+}
+
 // SetPoolRepo sets the database repository for proxy persistence
 // This can be called after construction when database becomes available
 func (pg *ProxyGate) SetPoolRepo(repo domain.ProxyListRepository) {
 	pg.pool.SetRepo(repo)
 }
 
+// SetEventRepo enables logging why proxies fail, get quarantined and
+// recover. Like SetPoolRepo it can be called after construction.
+func (pg *ProxyGate) SetEventRepo(repo domain.ProxyEventRepository) {
+	pg.events.setRepo(repo)
+}
+
+// SetUsageRepo enables counting the traffic relayed through each upstream
+// proxy per job. Like SetPoolRepo it can be called after construction.
+func (pg *ProxyGate) SetUsageRepo(repo domain.ProxyUsageRepository) {
+	pg.usage.setRepo(repo)
+}
+
 // LoadFromDatabase loads healthy proxies from database into the in-memory pool
 // This should be called after SetPoolRepo to initialize the pool with existing proxies
 func (pg *ProxyGate) LoadFromDatabase(ctx context.Context) error {
@@ -113,6 +246,27 @@ func (pg *ProxyGate) ReloadFromDatabase(ctx context.Context) error {
 	return pg.pool.LoadFromDatabase(ctx)
 }
 
+// ProxyScore returns the live score of a proxy by database ID
+func (pg *ProxyGate) ProxyScore(id int64) (*ProxyScore, bool) {
+	return pg.pool.Score(id)
+}
+
+// SetGeoResolver replaces the resolver used to tag validated proxies with
+// their country, e.g. with a local GeoIP database lookup
+func (pg *ProxyGate) SetGeoResolver(geo GeoResolver) {
+	pg.validator.SetGeoResolver(geo)
+}
+
+// CountryCounts returns the number of in-memory proxies per country
+func (pg *ProxyGate) CountryCounts() map[string]int {
+	return pg.pool.CountryCounts()
+}
+
+// ActiveSessions returns the number of sticky sessions pinned to an upstream
+func (pg *ProxyGate) ActiveSessions() int {
+	return pg.sessions.active()
+}
+
 // PoolSize returns the current number of proxies in memory
 func (pg *ProxyGate) PoolSize() int {
 	return pg.pool.Size()
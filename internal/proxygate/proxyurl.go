@@ -0,0 +1,114 @@
+package proxygate
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// Upstream protocols the gateway can dial through
+const (
+	ProtocolSOCKS5 = "socks5"
+	ProtocolHTTP   = "http"
+	ProtocolHTTPS  = "https"
+)
+
+// IsSupportedProtocol reports whether ProxyGate can dial through protocol
+func IsSupportedProtocol(protocol string) bool {
+	switch protocol {
+	case ProtocolSOCKS5, ProtocolHTTP, ProtocolHTTPS:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseProxyURL parses an upstream proxy in any of the formats found in
+// proxy lists and provider dashboards:
+//
+//	host:port
+//	host:port:user:pass
+//	user:pass@host:port
+//	scheme://[user:pass@]host:port
+//
+// defaultProtocol is used when the input has no scheme.
+func ParseProxyURL(raw, defaultProtocol string) (*domain.Proxy, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("empty proxy address")
+	}
+
+	if !strings.Contains(raw, "://") {
+		// host:port:user:pass has no '@' and exactly three colons
+		if parts := strings.Split(raw, ":"); len(parts) == 4 && !strings.Contains(raw, "@") {
+			raw = fmt.Sprintf("%s:%s@%s:%s", parts[2], parts[3], parts[0], parts[1])
+		}
+
+		if defaultProtocol == "" {
+			defaultProtocol = ProtocolSOCKS5
+		}
+
+		raw = defaultProtocol + "://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy address: %w", err)
+	}
+
+	protocol := strings.ToLower(u.Scheme)
+	if protocol == "socks5h" {
+		protocol = ProtocolSOCKS5
+	}
+
+	if !IsSupportedProtocol(protocol) {
+		return nil, fmt.Errorf("unsupported proxy protocol: %s", u.Scheme)
+	}
+
+	host, portStr, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy address %s: %w", u.Host, err)
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return nil, fmt.Errorf("invalid port: %s", portStr)
+	}
+
+	proxy := &domain.Proxy{
+		IP:       host,
+		Port:     port,
+		Protocol: protocol,
+	}
+
+	if u.User != nil {
+		proxy.Username = u.User.Username()
+		proxy.Password, _ = u.User.Password()
+	}
+
+	return proxy, nil
+}
+
+// proxyKey identifies a proxy by address, independent of its database ID
+func proxyKey(proxy *domain.Proxy) string {
+	return net.JoinHostPort(proxy.IP, strconv.Itoa(proxy.Port))
+}
+
+// proxyURL returns the full URL with protocol scheme and credentials
+func proxyURL(proxy *domain.Proxy) string {
+	return proxy.URL()
+}
+
+// parseProxyAddress parses "IP:port" or a full proxy URL into host and port
+func parseProxyAddress(addr string) (string, int, error) {
+	proxy, err := ParseProxyURL(addr, "")
+	if err != nil {
+		return "", 0, err
+	}
+
+	return proxy.IP, proxy.Port, nil
+}
@@ -0,0 +1,221 @@
+package proxygate
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// DefaultRevalidateInterval is how often a slice of the pool is probed
+	DefaultRevalidateInterval = 5 * time.Minute
+
+	// DefaultRevalidateBatch is how many proxies one cycle probes
+	DefaultRevalidateBatch = 200
+
+	// DefaultProbeConcurrency is how many probes run at once
+	DefaultProbeConcurrency = 20
+
+	// maxPendingProbeFails marks a pending proxy dead after this many
+	// failed probes
+	maxPendingProbeFails = 3
+)
+
+// RevalidationRun summarises one revalidation cycle
+type RevalidationRun struct {
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Checked    int       `json:"checked"`
+	Promoted   int       `json:"promoted"` // Pending proxies that passed and joined the pool
+	Demoted    int       `json:"demoted"`  // Proxies quarantined or marked dead
+	Failed     int       `json:"failed"`   // Failed probes, including demotions
+}
+
+// Revalidator probes proxies in the background so dead ones leave the pool
+// before a job dials them. Each cycle takes the next slice of the pool plus
+// the pending proxies checked longest ago: pool proxies feed their probe
+// into the live score and are quarantined like after failed dials, pending
+// ones join the pool when they pass.
+type Revalidator struct {
+	pool        *Pool
+	validator   *Validator
+	interval    time.Duration
+	batch       int
+	concurrency int
+
+	mu   sync.RWMutex
+	last *RevalidationRun
+}
+
+// NewRevalidator creates a revalidator probing batch proxies every interval
+func NewRevalidator(pool *Pool, validator *Validator, interval time.Duration, batch, concurrency int) *Revalidator {
+	if batch <= 0 {
+		batch = DefaultRevalidateBatch
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultProbeConcurrency
+	}
+
+	return &Revalidator{
+		pool:        pool,
+		validator:   validator,
+		interval:    interval,
+		batch:       batch,
+		concurrency: concurrency,
+	}
+}
+
+// Run revalidates a batch every interval until ctx is done. A zero
+// interval disables revalidation.
+func (r *Revalidator) Run(ctx context.Context) error {
+	if r.interval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			run := r.revalidate(ctx)
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			r.mu.Lock()
+			r.last = run
+			r.mu.Unlock()
+
+			log.Printf("[ProxyGate] Revalidation checked %d proxies in %dms: %d promoted, %d demoted, %d failed",
+				run.Checked, run.DurationMs, run.Promoted, run.Demoted, run.Failed)
+		}
+	}
+}
+
+// LastRun returns the summary of the last completed cycle, or nil
+func (r *Revalidator) LastRun() *RevalidationRun {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.last == nil {
+		return nil
+	}
+
+	run := *r.last
+	return &run
+}
+
+func (r *Revalidator) revalidate(ctx context.Context) *RevalidationRun {
+	run := &RevalidationRun{StartedAt: time.Now()}
+
+	// Pending proxies get up to half of the batch, the pool the rest
+	pending := r.pendingBatch(ctx, r.batch/2)
+	inPool := r.pool.NextRevalidationBatch(r.batch - len(pending))
+
+	var promoted, demoted, failed atomic.Int64
+
+	var egroup errgroup.Group
+	egroup.SetLimit(r.concurrency)
+
+	for _, proxy := range pending {
+		egroup.Go(func() error {
+			latency, err := r.validator.probe(ctx, proxyURL(proxy))
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if err == nil {
+				r.promote(ctx, proxy, latency)
+				promoted.Add(1)
+				return nil
+			}
+
+			failed.Add(1)
+			if err := r.pool.repo.IncrementFailCount(ctx, proxy.ID, maxPendingProbeFails); err != nil {
+				log.Printf("[ProxyGate] Failed to record probe failure of proxy %d: %v", proxy.ID, err)
+				return nil
+			}
+			if proxy.FailCount+1 >= maxPendingProbeFails {
+				demoted.Add(1)
+			}
+			return nil
+		})
+	}
+
+	for _, proxy := range inPool {
+		egroup.Go(func() error {
+			latency, err := r.validator.probe(ctx, proxyURL(proxy))
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if err != nil {
+				failed.Add(1)
+			}
+			if r.pool.RecordResult(proxy, latency, err, nil) {
+				demoted.Add(1)
+			}
+			return nil
+		})
+	}
+
+	_ = egroup.Wait()
+
+	// Persist the new response_time and uptime of the pool proxies
+	if err := r.pool.FlushScores(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("[ProxyGate] Score flush failed: %v", err)
+	}
+
+	run.Checked = len(pending) + len(inPool)
+	run.Promoted = int(promoted.Load())
+	run.Demoted = int(demoted.Load())
+	run.Failed = int(failed.Load())
+	run.DurationMs = time.Since(run.StartedAt).Milliseconds()
+
+	return run
+}
+
+// pendingBatch returns up to limit pending proxies from the database,
+// skipping those quarantined here since the quarantine checker owns them
+func (r *Revalidator) pendingBatch(ctx context.Context, limit int) []*domain.Proxy {
+	if limit <= 0 || !r.pool.HasRepo() {
+		return nil
+	}
+
+	proxies, err := r.pool.repo.ListLeastRecentlyChecked(ctx, domain.ProxyStatusPending, limit)
+	if err != nil {
+		log.Printf("[ProxyGate] Failed to list pending proxies: %v", err)
+		return nil
+	}
+
+	batch := proxies[:0]
+	for _, proxy := range proxies {
+		if !r.pool.Quarantined(proxy) {
+			batch = append(batch, proxy)
+		}
+	}
+
+	return batch
+}
+
+// promote marks a pending proxy that passed its probe healthy and adds it
+// to the pool, seeding its score with the probe latency
+func (r *Revalidator) promote(ctx context.Context, proxy *domain.Proxy, latency time.Duration) {
+	r.validator.enrichCountry(ctx, proxy)
+	r.pool.AddValidatedProxy(proxy)
+
+	// Upsert keeps the stored status; this is what makes it healthy
+	if err := r.pool.repo.IncrementSuccessCount(ctx, proxy.ID); err != nil {
+		log.Printf("[ProxyGate] Failed to mark proxy %d as healthy: %v", proxy.ID, err)
+	}
+
+	r.pool.RecordResult(proxy, latency, nil, nil)
+}
@@ -0,0 +1,132 @@
+package proxygate
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// scoreAlpha is the EWMA smoothing factor for latency and success rate
+	scoreAlpha = 0.3
+
+	// minSamples is the number of dials after which a proxy counts as proven
+	minSamples = 5
+
+	// maxConsecutiveFails quarantines a proxy after this many failed dials in a row
+	maxConsecutiveFails = 3
+
+	// DefaultExplorationRatio is the share of picks that go to unproven proxies
+	DefaultExplorationRatio = 0.1
+)
+
+// ProxyScore is a snapshot of the live score of one upstream proxy
+type ProxyScore struct {
+	ProxyID          int64      `json:"proxy_id"`
+	Address          string     `json:"address"`
+	LatencyMs        float64    `json:"latency_ms"`
+	SuccessRate      float64    `json:"success_rate"`
+	Samples          int64      `json:"samples"`
+	ConsecutiveFails int        `json:"consecutive_fails"`
+	Weight           float64    `json:"weight"`
+	Proven           bool       `json:"proven"`
+	Quarantined      bool       `json:"quarantined"`
+	QuarantinedAt    *time.Time `json:"quarantined_at,omitempty"`
+	LastUsed         *time.Time `json:"last_used,omitempty"`
+}
+
+// proxyScore tracks EWMA latency and success rate for one upstream.
+// Scores are keyed by address and survive pool reloads.
+type proxyScore struct {
+	latencyMs        float64
+	successRate      float64
+	samples          int64
+	consecutiveFails int
+	lastUsed         time.Time
+	dirty            bool // changed since the last flush to the database
+}
+
+func newProxyScore() *proxyScore {
+	return &proxyScore{successRate: 1}
+}
+
+func (s *proxyScore) observe(success bool, latency time.Duration) {
+	s.samples++
+	s.lastUsed = time.Now()
+	s.dirty = true
+
+	outcome := 0.0
+	if success {
+		outcome = 1
+		s.consecutiveFails = 0
+
+		ms := float64(latency) / float64(time.Millisecond)
+		if s.latencyMs == 0 {
+			s.latencyMs = ms
+		} else {
+			s.latencyMs = scoreAlpha*ms + (1-scoreAlpha)*s.latencyMs
+		}
+	} else {
+		s.consecutiveFails++
+	}
+
+	if s.samples == 1 {
+		s.successRate = outcome
+	} else {
+		s.successRate = scoreAlpha*outcome + (1-scoreAlpha)*s.successRate
+	}
+}
+
+func (s *proxyScore) proven() bool {
+	return s.samples >= minSamples
+}
+
+// weight favours fast, reliable proxies: a proxy at 200ms gets roughly
+// ten times the traffic of one at 5s with the same success rate.
+func (s *proxyScore) weight() float64 {
+	const latencyFloorMs = 100
+
+	return s.successRate * s.successRate * 1000 / (math.Max(s.latencyMs, 0) + latencyFloorMs)
+}
+
+func (s *proxyScore) snapshot() ProxyScore {
+	snap := ProxyScore{
+		LatencyMs:        s.latencyMs,
+		SuccessRate:      s.successRate,
+		Samples:          s.samples,
+		ConsecutiveFails: s.consecutiveFails,
+		Weight:           s.weight(),
+		Proven:           s.proven(),
+	}
+
+	if !s.lastUsed.IsZero() {
+		lastUsed := s.lastUsed
+		snap.LastUsed = &lastUsed
+	}
+
+	return snap
+}
+
+// pickWeighted returns an index into candidates chosen with probability
+// proportional to weights. It falls back to a uniform pick when every
+// weight is zero.
+func pickWeighted(weights []float64) int {
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+
+	if total <= 0 {
+		return rand.IntN(len(weights))
+	}
+
+	r := rand.Float64() * total
+	for i, w := range weights {
+		r -= w
+		if r < 0 {
+			return i
+		}
+	}
+
+	return len(weights) - 1
+}
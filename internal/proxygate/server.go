@@ -1,31 +1,46 @@
 package proxygate
 
 import (
+	"bufio"
 	"context"
+	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"log"
 	"net"
+	"net/http"
 	"net/url"
+	"time"
 
+	"github.com/sadewadee/google-scraper/internal/domain"
 	"github.com/txthinking/socks5"
 	"golang.org/x/net/proxy"
 )
 
 // Define constants that might be missing in the version of socks5 library used
 const (
-	socks5Ver5       = 0x05
-	socks5MethodNone = 0x00
-	socks5CmdConnect = 0x01
+	socks5Ver5            = 0x05
+	socks5MethodNone      = 0x00
+	socks5MethodUserPass  = 0x02
+	socks5MethodNoAccept  = 0xFF
+	socks5CmdConnect      = 0x01
+	socks5UserPassVer     = 0x01
+	socks5UserPassSuccess = 0x00
 )
 
+// upstreamDialTimeout bounds the CONNECT handshake with HTTP(S) upstreams
+const upstreamDialTimeout = 15 * time.Second
+
 type Server struct {
-	addr string
-	pool *Pool
+	addr     string
+	pool     *Pool
+	sessions *sessionStore
+	usage    *usageMeter // Counts the relayed traffic; nil counts nothing
 }
 
-func NewServer(addr string, pool *Pool) *Server {
-	return &Server{addr: addr, pool: pool}
+func NewServer(addr string, pool *Pool, sessions *sessionStore) *Server {
+	return &Server{addr: addr, pool: pool, sessions: sessions}
 }
 
 func (s *Server) Run(ctx context.Context) error {
@@ -76,22 +91,52 @@ func (s *Server) handleConnection(conn net.Conn) error {
 	// | 1  |    1     | 1 to 255 |
 	// +----+----------+----------+
 	buf := make([]byte, 257)
-	if _, err := io.ReadAtLeast(conn, buf, 2); err != nil {
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
 		return err
 	}
 	if buf[0] != socks5Ver5 {
 		return fmt.Errorf("unsupported version: %d", buf[0])
 	}
 	nMethods := int(buf[1])
-	if _, err := io.ReadAtLeast(conn, buf, nMethods); err != nil {
+	if _, err := io.ReadFull(conn, buf[:nMethods]); err != nil {
 		return err
 	}
 
-	// We only support NoAuth (0x00) for now
-	if _, err := conn.Write([]byte{socks5Ver5, socks5MethodNone}); err != nil {
+	// Username/password auth is only used to carry a session key and an
+	// optional country (e.g. "job-<uuid>-country-us"); any password is
+	// accepted. Clients without credentials get plain rotation.
+	method := byte(socks5MethodNoAccept)
+	for _, m := range buf[:nMethods] {
+		if m == socks5MethodUserPass {
+			method = socks5MethodUserPass
+			break
+		}
+		if m == socks5MethodNone {
+			method = socks5MethodNone
+		}
+	}
+
+	if _, err := conn.Write([]byte{socks5Ver5, method}); err != nil {
 		return err
 	}
 
+	var sessionKey, country string
+
+	switch method {
+	case socks5MethodNoAccept:
+		return fmt.Errorf("no supported auth method offered")
+	case socks5MethodUserPass:
+		username, err := readUserPass(conn)
+		if err != nil {
+			return err
+		}
+		sessionKey, country = parseSessionUsername(username)
+
+		if _, err := conn.Write([]byte{socks5UserPassVer, socks5UserPassSuccess}); err != nil {
+			return err
+		}
+	}
+
 	// 2. Request
 	// +----+-----+-------+------+----------+----------+
 	// |VER | CMD |  RSV  | ATYP | DST.ADDR | DST.PORT |
@@ -170,19 +215,31 @@ func (s *Server) handleConnection(conn net.Conn) error {
 	// Retry mechanism: Try up to 3 different proxies if dialing fails
 	var targetConn net.Conn
 	var dialErr error
+	var upstream *domain.Proxy
 
 	for i := 0; i < 3; i++ {
-		upstreamStr, err := s.pool.GetNext()
+		var err error
+		upstream, err = s.pickUpstream(sessionKey, country)
 		if err != nil {
 			log.Printf("[ProxyGate] No proxies available: %v", err)
 			conn.Write([]byte{socks5Ver5, socks5.RepServerFailure, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
 			return nil
 		}
 
-		targetConn, dialErr = s.dialUpstream(upstreamStr, address)
+		start := time.Now()
+		targetConn, dialErr = s.dialUpstream(proxyURL(upstream), address)
+		s.pool.RecordResult(upstream, time.Since(start), dialErr, sessionJobID(sessionKey))
 		if dialErr == nil {
+			if sessionKey != "" && !s.sessions.pin(sessionKey, upstream) {
+				log.Printf("[ProxyGate] Session limit reached, serving %s without affinity", sessionKey)
+			}
 			break
 		}
+
+		// The pinned upstream is gone for this session; the next attempt repins
+		if sessionKey != "" {
+			s.sessions.unpin(sessionKey)
+		}
 	}
 
 	if dialErr != nil {
@@ -197,22 +254,76 @@ func (s *Server) handleConnection(conn net.Conn) error {
 	conn.Write([]byte{socks5Ver5, socks5.RepSuccess, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
 
 	// Bi-directional copy
-	go func() {
-		io.Copy(targetConn, conn)
-	}()
-	io.Copy(conn, targetConn)
+	counter := s.usage.open(upstream, sessionJobID(sessionKey))
+	defer s.usage.close(counter)
+	relay(conn, targetConn, counter)
 
 	return nil
 }
 
+// pickUpstream returns the upstream pinned to the session while it is
+// still in rotation, or the next proxy from the pool, restricted to
+// country when the client asked for one
+func (s *Server) pickUpstream(sessionKey, country string) (*domain.Proxy, error) {
+	if sessionKey != "" {
+		if pinned := s.sessions.get(sessionKey); pinned != nil && s.pool.InRotation(pinned) {
+			return pinned, nil
+		}
+	}
 
+	return s.pool.GetNextInCountry(country)
+}
+
+// readUserPass reads an RFC 1929 username/password request and returns the username
+func readUserPass(conn net.Conn) (string, error) {
+	// +----+------+----------+------+----------+
+	// |VER | ULEN |  UNAME   | PLEN |  PASSWD  |
+	// +----+------+----------+------+----------+
+	// | 1  |  1   | 1 to 255 |  1   | 1 to 255 |
+	// +----+------+----------+------+----------+
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", err
+	}
+	if header[0] != socks5UserPassVer {
+		return "", fmt.Errorf("unsupported auth version: %d", header[0])
+	}
+
+	username := make([]byte, int(header[1]))
+	if _, err := io.ReadFull(conn, username); err != nil {
+		return "", err
+	}
+
+	plen := make([]byte, 1)
+	if _, err := io.ReadFull(conn, plen); err != nil {
+		return "", err
+	}
+
+	password := make([]byte, int(plen[0]))
+	if _, err := io.ReadFull(conn, password); err != nil {
+		return "", err
+	}
+
+	return string(username), nil
+}
 
-func (s *Server) dialUpstream(proxyURL, targetAddr string) (net.Conn, error) {
-	u, err := url.Parse(proxyURL)
+func (s *Server) dialUpstream(upstreamURL, targetAddr string) (net.Conn, error) {
+	u, err := url.Parse(upstreamURL)
 	if err != nil {
 		return nil, err
 	}
 
+	switch u.Scheme {
+	case ProtocolHTTP, ProtocolHTTPS:
+		return dialHTTPConnect(u, targetAddr)
+	case ProtocolSOCKS5, "":
+		return dialSOCKS5(u, targetAddr)
+	default:
+		return nil, fmt.Errorf("unsupported upstream protocol: %s", u.Scheme)
+	}
+}
+
+func dialSOCKS5(u *url.URL, targetAddr string) (net.Conn, error) {
 	var auth *proxy.Auth
 	if u.User != nil {
 		auth = &proxy.Auth{
@@ -231,3 +342,75 @@ func (s *Server) dialUpstream(proxyURL, targetAddr string) (net.Conn, error) {
 
 	return dialer.Dial("tcp", targetAddr)
 }
+
+// dialHTTPConnect opens a tunnel to targetAddr through an HTTP(S) proxy
+// using the CONNECT method, authenticating with the URL's credentials.
+func dialHTTPConnect(u *url.URL, targetAddr string) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", u.Host, upstreamDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == ProtocolHTTPS {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("tls handshake with upstream: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: targetAddr},
+		Host:   targetAddr,
+		Header: make(http.Header),
+	}
+
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(upstreamDialTimeout))
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("write CONNECT: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("read CONNECT response: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("upstream CONNECT failed: %w", &statusError{code: resp.StatusCode, status: resp.Status})
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	// The proxy may already have sent tunnel bytes after its response
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+
+	return conn, nil
+}
+
+// bufferedConn drains bytes read ahead while parsing the CONNECT response
+// before reading from the connection itself
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
@@ -0,0 +1,174 @@
+package proxygate
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+const (
+	// DefaultSessionTTL is how long an idle session keeps its upstream
+	DefaultSessionTTL = 10 * time.Minute
+
+	// DefaultMaxSessions caps the number of concurrently pinned sessions
+	DefaultMaxSessions = 1000
+)
+
+// countryTag marks the country part of a SOCKS5 username
+const countryTag = "country-"
+
+// SessionUsername builds the SOCKS5 username for a session, restricted to
+// country when it is not empty: "job-<uuid>", "job-<uuid>-country-us" or,
+// for geo-targeting without affinity, "country-us".
+func SessionUsername(session, country string) string {
+	if country == "" {
+		return session
+	}
+
+	tag := countryTag + strings.ToLower(country)
+	if session == "" {
+		return tag
+	}
+
+	return session + "-" + tag
+}
+
+// parseSessionUsername splits a SOCKS5 username into the session key and
+// the requested country. A bare "country-xx" carries no session.
+func parseSessionUsername(username string) (key, country string) {
+	if strings.HasPrefix(username, countryTag) {
+		return "", NormalizeCountry(strings.TrimPrefix(username, countryTag))
+	}
+
+	if i := strings.LastIndex(username, "-"+countryTag); i >= 0 {
+		if cc := NormalizeCountry(username[i+1+len(countryTag):]); cc != "" {
+			return username, cc
+		}
+	}
+
+	return username, ""
+}
+
+// session pins a client session (the SOCKS5 username, e.g. "job-<uuid>")
+// to one upstream proxy
+type session struct {
+	proxy    *domain.Proxy
+	lastSeen time.Time
+}
+
+// sessionStore keeps session affinity for the gateway. Sessions expire
+// after ttl without traffic.
+type sessionStore struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	max      int
+	sessions map[string]*session
+}
+
+func newSessionStore(ttl time.Duration, max int) *sessionStore {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+
+	if max <= 0 {
+		max = DefaultMaxSessions
+	}
+
+	return &sessionStore{
+		ttl:      ttl,
+		max:      max,
+		sessions: make(map[string]*session),
+	}
+}
+
+// get returns the upstream pinned to key, or nil if there is none or it expired
+func (s *sessionStore) get(key string) *domain.Proxy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[key]
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	if now.Sub(sess.lastSeen) > s.ttl {
+		delete(s.sessions, key)
+		return nil
+	}
+
+	sess.lastSeen = now
+
+	return sess.proxy
+}
+
+// pin binds key to proxy. It returns false when the store is full, in
+// which case the connection is served without affinity.
+func (s *sessionStore) pin(key string, proxy *domain.Proxy) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if sess, ok := s.sessions[key]; ok {
+		sess.proxy = proxy
+		sess.lastSeen = now
+		return true
+	}
+
+	if len(s.sessions) >= s.max {
+		s.sweepLocked(now)
+		if len(s.sessions) >= s.max {
+			return false
+		}
+	}
+
+	s.sessions[key] = &session{proxy: proxy, lastSeen: now}
+
+	return true
+}
+
+// unpin drops the session so the next connection picks a new upstream
+func (s *sessionStore) unpin(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, key)
+}
+
+// active returns the number of unexpired sessions
+func (s *sessionStore) active() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(time.Now())
+
+	return len(s.sessions)
+}
+
+func (s *sessionStore) sweepLocked(now time.Time) {
+	for key, sess := range s.sessions {
+		if now.Sub(sess.lastSeen) > s.ttl {
+			delete(s.sessions, key)
+		}
+	}
+}
+
+// sessionJobID returns the job a session key ("job-<uuid>...") belongs
+// to, or nil for other sessions
+func sessionJobID(key string) *uuid.UUID {
+	rest, ok := strings.CutPrefix(key, "job-")
+	if !ok || len(rest) < 36 {
+		return nil
+	}
+
+	id, err := uuid.Parse(rest[:36])
+	if err != nil {
+		return nil
+	}
+
+	return &id
+}
@@ -0,0 +1,139 @@
+package proxygate
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+func TestParseSessionUsername(t *testing.T) {
+	job := "job-" + uuid.NewString()
+
+	tests := []struct {
+		username string
+		key      string
+		country  string
+	}{
+		{job, job, ""},
+		{job + "-country-us", job + "-country-us", "US"},
+		{"country-de", "", "DE"},
+		{"country-deu", "", ""},
+		{job + "-country-usa", job + "-country-usa", ""},
+		{"worker-1", "worker-1", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			key, country := parseSessionUsername(tt.username)
+			assert.Equal(t, tt.key, key)
+			assert.Equal(t, tt.country, country)
+		})
+	}
+}
+
+func TestSessionUsername(t *testing.T) {
+	job := "job-" + uuid.NewString()
+
+	assert.Equal(t, job, SessionUsername(job, ""))
+	assert.Equal(t, job+"-country-us", SessionUsername(job, "US"))
+	assert.Equal(t, "country-de", SessionUsername("", "DE"))
+
+	// The session key keeps the country, so each country pins its own upstream
+	key, country := parseSessionUsername(SessionUsername(job, "FR"))
+	assert.Equal(t, job+"-country-fr", key)
+	assert.Equal(t, "FR", country)
+}
+
+func TestSessionJobID(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		key  string
+		want *uuid.UUID
+	}{
+		{"job-" + id.String(), &id},
+		{"job-" + id.String() + "-country-us", &id},
+		{"job-not-a-uuid", nil},
+		{"worker-" + id.String(), nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.want, sessionJobID(tt.key))
+		})
+	}
+}
+
+func TestSessionStore(t *testing.T) {
+	upstream := func(i int) *domain.Proxy {
+		return &domain.Proxy{IP: fmt.Sprintf("10.0.0.%d", i), Port: 1080}
+	}
+
+	t.Run("same key returns the same upstream", func(t *testing.T) {
+		s := newSessionStore(time.Minute, 10)
+		a, b := upstream(1), upstream(2)
+
+		require.True(t, s.pin("job-a", a))
+		require.True(t, s.pin("job-b", b))
+
+		assert.Same(t, a, s.get("job-a"))
+		assert.Same(t, a, s.get("job-a"))
+		assert.Same(t, b, s.get("job-b"))
+		assert.Nil(t, s.get("job-c"))
+
+		// Pinning again moves the session to the new upstream
+		require.True(t, s.pin("job-a", b))
+		assert.Same(t, b, s.get("job-a"))
+
+		s.unpin("job-a")
+		assert.Nil(t, s.get("job-a"))
+		assert.Equal(t, 1, s.active())
+	})
+
+	t.Run("sessions expire after the ttl without traffic", func(t *testing.T) {
+		s := newSessionStore(time.Minute, 10)
+		require.True(t, s.pin("job-idle", upstream(1)))
+		require.True(t, s.pin("job-busy", upstream(2)))
+
+		s.sessions["job-idle"].lastSeen = time.Now().Add(-2 * time.Minute)
+		s.sessions["job-busy"].lastSeen = time.Now().Add(-30 * time.Second)
+
+		assert.Nil(t, s.get("job-idle"))
+		assert.NotNil(t, s.get("job-busy"))
+		assert.Equal(t, 1, s.active())
+
+		// A get renews the session
+		assert.WithinDuration(t, time.Now(), s.sessions["job-busy"].lastSeen, time.Second)
+	})
+
+	t.Run("a full store evicts expired sessions only", func(t *testing.T) {
+		s := newSessionStore(time.Minute, 2)
+		require.True(t, s.pin("job-a", upstream(1)))
+		require.True(t, s.pin("job-b", upstream(2)))
+
+		assert.False(t, s.pin("job-c", upstream(3)), "no session expired")
+		assert.Nil(t, s.get("job-c"))
+
+		// Sessions already pinned are still moved when the store is full
+		assert.True(t, s.pin("job-a", upstream(4)))
+
+		s.sessions["job-b"].lastSeen = time.Now().Add(-2 * time.Minute)
+		assert.True(t, s.pin("job-c", upstream(3)))
+		assert.Equal(t, 2, s.active())
+		assert.Nil(t, s.get("job-b"))
+	})
+
+	t.Run("zero values fall back to the defaults", func(t *testing.T) {
+		s := newSessionStore(0, 0)
+		assert.Equal(t, DefaultSessionTTL, s.ttl)
+		assert.Equal(t, DefaultMaxSessions, s.max)
+	})
+}
@@ -0,0 +1,201 @@
+package proxygate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+const (
+	// DefaultSourceTimeout bounds fetching one source, so a dead host does
+	// not hold up the rest of the refresh
+	DefaultSourceTimeout = 45 * time.Second
+
+	// DefaultSourceMinPassRate is the share of a source's proxies that must
+	// pass validation for a refresh of it to count as useful
+	DefaultSourceMinPassRate = 0.01
+
+	// DefaultSourceDisableAfter disables a source after this many
+	// consecutive refreshes below the minimum pass rate
+	DefaultSourceDisableAfter = 3
+)
+
+// sourceTracker counts what each source fetched and how its proxies fared
+// in validation. A refresh of a source is judged when the next one starts,
+// which gives its proxies the refresh interval to get validated.
+type sourceTracker struct {
+	mu    sync.Mutex
+	stats map[string]*domain.ProxySourceStats // Keyed by source URL
+	repo  domain.ProxyRepository              // Optional persistence
+
+	minPassRate  float64
+	disableAfter int // 0 never disables
+}
+
+func newSourceTracker(minPassRate float64, disableAfter int) *sourceTracker {
+	return &sourceTracker{
+		stats:        make(map[string]*domain.ProxySourceStats),
+		minPassRate:  minPassRate,
+		disableAfter: disableAfter,
+	}
+}
+
+// get returns the stats of url, adding an active source. Callers hold mu.
+func (t *sourceTracker) get(url string) *domain.ProxySourceStats {
+	s, ok := t.stats[url]
+	if !ok {
+		s = &domain.ProxySourceStats{Active: true}
+		t.stats[url] = s
+	}
+	return s
+}
+
+func (t *sourceTracker) setRepo(repo domain.ProxyRepository) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.repo = repo
+}
+
+// restore seeds the stats of url from the database
+func (t *sourceTracker) restore(url string, stats domain.ProxySourceStats) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats[url] = &stats
+}
+
+func (t *sourceTracker) remove(url string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.stats, url)
+}
+
+// snapshot returns a copy of the stats of url, those of a new active
+// source and false if it has none
+func (t *sourceTracker) snapshot(url string) (domain.ProxySourceStats, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.stats[url]
+	if !ok {
+		return domain.ProxySourceStats{Active: true}, false
+	}
+	return *s, true
+}
+
+// parseError counts a line of url that is not a proxy
+func (t *sourceTracker) parseError(url string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.get(url).ParseErrors++
+}
+
+// validated counts a proxy of url that was probed
+func (t *sourceTracker) validated(url string, passed bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.get(url)
+	s.Validated++
+	if passed {
+		s.Passed++
+	}
+}
+
+// begin judges the previous refresh of url before a new one and returns
+// the stats it left, and whether that disabled the source just now
+func (t *sourceTracker) begin(url string, now time.Time) (domain.ProxySourceStats, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.get(url)
+	if !s.Active || s.LastFetchAt == nil {
+		return *s, false
+	}
+
+	var reason string
+	switch {
+	case s.LastError != "":
+		reason = "fetch failed: " + s.LastError
+	case s.FetchedCount == 0:
+		reason = "no proxies fetched"
+	case s.Checked() == 0:
+		// Nothing validated yet, so nothing to judge
+		return *s, false
+	case s.PassRate() < t.minPassRate:
+		reason = fmt.Sprintf("pass rate %.2f%% below %.2f%%", s.PassRate()*100, t.minPassRate*100)
+	default:
+		s.LowRefreshes = 0
+		return *s, false
+	}
+
+	s.LowRefreshes++
+	if t.disableAfter <= 0 || s.LowRefreshes < t.disableAfter {
+		return *s, false
+	}
+
+	s.Active = false
+	s.DisabledAt = &now
+	s.DisabledReason = fmt.Sprintf("%s for %d consecutive refreshes", reason, s.LowRefreshes)
+	return *s, true
+}
+
+// fetched starts a new refresh of url with what its fetch returned
+func (t *sourceTracker) fetched(url string, count int, latency time.Duration, err error, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.get(url)
+	s.FetchedCount = count
+	s.ParseErrors = 0
+	s.Validated = 0
+	s.Passed = 0
+	s.FetchLatencyMs = latency.Milliseconds()
+	s.LastFetchAt = &at
+	s.LastError = ""
+	if err != nil {
+		s.LastError = err.Error()
+	}
+}
+
+// setActive enables or disables url by hand. Enabling clears the streak of
+// low refreshes and leaves the last fetch unjudged, so the source gets
+// disableAfter new refreshes to recover.
+func (t *sourceTracker) setActive(url string, active bool, now time.Time) domain.ProxySourceStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.get(url)
+	s.Active = active
+	if active {
+		s.LowRefreshes = 0
+		s.LastFetchAt = nil
+		s.DisabledAt = nil
+		s.DisabledReason = ""
+	} else if s.DisabledAt == nil {
+		s.DisabledAt = &now
+		s.DisabledReason = "disabled by hand"
+	}
+	return *s
+}
+
+// save persists the stats of url, if a repository is set
+func (t *sourceTracker) save(ctx context.Context, url string, stats domain.ProxySourceStats) {
+	t.mu.Lock()
+	repo := t.repo
+	t.mu.Unlock()
+
+	if repo == nil {
+		return
+	}
+	if err := repo.UpdateStats(ctx, url, &stats); err != nil {
+		log.Printf("[ProxyGate] Failed to save stats of source %s: %v", url, err)
+	}
+}
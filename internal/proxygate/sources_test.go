@@ -0,0 +1,100 @@
+package proxygate
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceTrackerDisablesLowPassRate(t *testing.T) {
+	const url = "https://lists.example/socks5.txt"
+	tracker := newSourceTracker(0.1, 2)
+	now := time.Now()
+
+	// A refresh where 1 of 20 proxies passed, one line not being a proxy
+	refresh := func() {
+		tracker.fetched(url, 20, time.Second, nil, now)
+		tracker.parseError(url)
+		for i := 0; i < 19; i++ {
+			tracker.validated(url, i == 0)
+		}
+	}
+
+	stats, disabled := tracker.begin(url, now)
+	assert.False(t, disabled, "nothing fetched yet")
+	assert.True(t, stats.Active)
+
+	refresh()
+	stats, _ = tracker.snapshot(url)
+	assert.Equal(t, 20, stats.Checked())
+	assert.InDelta(t, 0.05, stats.PassRate(), 1e-9)
+
+	stats, disabled = tracker.begin(url, now)
+	assert.False(t, disabled)
+	assert.Equal(t, 1, stats.LowRefreshes)
+
+	tracker.fetched(url, 0, time.Second, errors.New("connection refused"), now)
+	stats, disabled = tracker.begin(url, now)
+	require.True(t, disabled)
+	assert.False(t, stats.Active)
+	assert.Contains(t, stats.DisabledReason, "connection refused for 2 consecutive refreshes")
+
+	_, disabled = tracker.begin(url, now)
+	assert.False(t, disabled, "already disabled")
+
+	stats = tracker.setActive(url, true, now)
+	assert.True(t, stats.Active)
+	assert.Zero(t, stats.LowRefreshes)
+	assert.Empty(t, stats.DisabledReason)
+
+	// A good refresh clears the streak
+	refresh()
+	tracker.begin(url, now)
+	tracker.fetched(url, 10, time.Second, nil, now)
+	for i := 0; i < 10; i++ {
+		tracker.validated(url, i < 5)
+	}
+	stats, _ = tracker.begin(url, now)
+	assert.Zero(t, stats.LowRefreshes)
+}
+
+func TestFetcherTimesOutSlowSources(t *testing.T) {
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("# comment\n1.2.3.4:1080\n\n5.6.7.8:1080\n"))
+	}))
+	defer fast.Close()
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	pool := NewPool()
+	f := NewFetcher([]string{slow.URL, fast.URL}, pool)
+	f.timeout = 200 * time.Millisecond
+
+	start := time.Now()
+	require.NoError(t, f.fetchAll(context.Background()))
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Len(t, pool.raw, 2)
+
+	stats, ok := f.tracker.snapshot(fast.URL)
+	require.True(t, ok)
+	assert.Equal(t, 2, stats.FetchedCount)
+	assert.Empty(t, stats.LastError)
+
+	stats, _ = f.tracker.snapshot(slow.URL)
+	assert.Zero(t, stats.FetchedCount)
+	assert.NotEmpty(t, stats.LastError)
+}
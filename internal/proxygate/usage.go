@@ -0,0 +1,216 @@
+package proxygate
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// DefaultUsageFlushInterval is how often the traffic counters are added to
+// the database. A manager that dies loses at most this much of them.
+const DefaultUsageFlushInterval = time.Minute
+
+// usageKey identifies the counters of one upstream proxy and job; the zero
+// job ID stands for connections without a job session
+type usageKey struct {
+	proxyID int64
+	jobID   uuid.UUID
+}
+
+// usageCounter counts the traffic of one key. The relay adds to it with
+// atomic adds only; the flush swaps the counts out.
+type usageCounter struct {
+	bytesUp   atomic.Int64
+	bytesDown atomic.Int64
+	requests  atomic.Int64
+
+	// Connections still relaying through the counter, guarded by
+	// usageMeter.mu; an idle counter is dropped once flushed
+	open int
+}
+
+// take returns the counts and resets them
+func (c *usageCounter) take() domain.ProxyTraffic {
+	return domain.ProxyTraffic{
+		BytesUp:   c.bytesUp.Swap(0),
+		BytesDown: c.bytesDown.Swap(0),
+		Requests:  c.requests.Swap(0),
+	}
+}
+
+// give adds counts that could not be stored back for the next flush
+func (c *usageCounter) give(t domain.ProxyTraffic) {
+	c.bytesUp.Add(t.BytesUp)
+	c.bytesDown.Add(t.BytesDown)
+	c.requests.Add(t.Requests)
+}
+
+// usageMeter counts the traffic the gateway relays per upstream proxy and
+// job and adds it to the database periodically. Nothing is counted while
+// no repository is set.
+type usageMeter struct {
+	interval time.Duration
+
+	mu       sync.Mutex
+	repo     domain.ProxyUsageRepository
+	counters map[usageKey]*usageCounter
+}
+
+func newUsageMeter(interval time.Duration) *usageMeter {
+	if interval <= 0 {
+		interval = DefaultUsageFlushInterval
+	}
+
+	return &usageMeter{interval: interval, counters: make(map[usageKey]*usageCounter)}
+}
+
+func (m *usageMeter) setRepo(repo domain.ProxyUsageRepository) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.repo = repo
+}
+
+// open returns the counter of a connection through proxy for jobID (nil
+// without a job session) with the connection counted, nil when usage is
+// not recorded. The caller calls close when the connection ends.
+func (m *usageMeter) open(proxy *domain.Proxy, jobID *uuid.UUID) *usageCounter {
+	if m == nil || proxy.ID == 0 {
+		return nil
+	}
+
+	key := usageKey{proxyID: proxy.ID}
+	if jobID != nil {
+		key.jobID = *jobID
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.repo == nil {
+		return nil
+	}
+
+	c, ok := m.counters[key]
+	if !ok {
+		c = &usageCounter{}
+		m.counters[key] = c
+	}
+	c.open++
+	c.requests.Add(1)
+
+	return c
+}
+
+// close ends a connection opened with open
+func (m *usageMeter) close(c *usageCounter) {
+	if c == nil {
+		return
+	}
+
+	m.mu.Lock()
+	c.open--
+	m.mu.Unlock()
+}
+
+// Run adds the counts to the database every interval until ctx is done,
+// then once more
+func (m *usageMeter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			m.flush(flushCtx, time.Now())
+			cancel()
+
+			return nil
+		case now := <-ticker.C:
+			m.flush(ctx, now)
+		}
+	}
+}
+
+// flush adds the counts since the last flush to the hour of now. Counts
+// that could not be stored are kept for the next one.
+func (m *usageMeter) flush(ctx context.Context, now time.Time) {
+	m.mu.Lock()
+	repo := m.repo
+	keys := make([]usageKey, 0, len(m.counters))
+	counters := make([]*usageCounter, 0, len(m.counters))
+	usage := make([]*domain.ProxyUsage, 0, len(m.counters))
+	for key, c := range m.counters {
+		traffic := c.take()
+		if traffic.IsZero() {
+			if c.open == 0 {
+				delete(m.counters, key)
+			}
+			continue
+		}
+
+		u := &domain.ProxyUsage{Bucket: now, ProxyID: key.proxyID, ProxyTraffic: traffic}
+		if key.jobID != uuid.Nil {
+			jobID := key.jobID
+			u.JobID = &jobID
+		}
+		keys = append(keys, key)
+		counters = append(counters, c)
+		usage = append(usage, u)
+	}
+	m.mu.Unlock()
+
+	if len(usage) == 0 || repo == nil {
+		return
+	}
+
+	if err := repo.Add(ctx, usage); err != nil {
+		log.Printf("[ProxyGate] Failed to store the traffic of %d proxies and jobs, retrying with the next flush: %v", len(usage), err)
+
+		m.mu.Lock()
+		for i, c := range counters {
+			// The counter may have been dropped as idle in the meantime
+			if current, ok := m.counters[keys[i]]; ok {
+				c = current
+			} else {
+				m.counters[keys[i]] = c
+			}
+			c.give(usage[i].ProxyTraffic)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// countingWriter adds the bytes written through it to n
+type countingWriter struct {
+	w io.Writer
+	n *atomic.Int64
+}
+
+func (cw countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n.Add(int64(n))
+	return n, err
+}
+
+// relay copies between the client and the upstream in both directions
+// until the upstream side ends, counting the bytes into c (nil for none)
+func relay(client, upstream io.ReadWriter, c *usageCounter) {
+	var up, down io.Writer = upstream, client
+	if c != nil {
+		up = countingWriter{w: upstream, n: &c.bytesUp}
+		down = countingWriter{w: client, n: &c.bytesDown}
+	}
+
+	go func() {
+		io.Copy(up, client)
+	}()
+	io.Copy(down, upstream)
+}
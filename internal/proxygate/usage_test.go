@@ -0,0 +1,105 @@
+package proxygate
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// fakeUsageRepo sums what is added to it per proxy and job
+type fakeUsageRepo struct {
+	fail  bool
+	added map[usageKey]domain.ProxyTraffic
+}
+
+func (f *fakeUsageRepo) Add(_ context.Context, usage []*domain.ProxyUsage) error {
+	if f.fail {
+		return errors.New("database down")
+	}
+	for _, u := range usage {
+		key := usageKey{proxyID: u.ProxyID}
+		if u.JobID != nil {
+			key.jobID = *u.JobID
+		}
+		t := f.added[key]
+		t.Add(u.ProxyTraffic)
+		f.added[key] = t
+	}
+	return nil
+}
+
+func (f *fakeUsageRepo) Summarize(context.Context, time.Time, string) (*domain.ProxyUsageReport, error) {
+	return nil, nil
+}
+
+func (f *fakeUsageRepo) JobTotal(context.Context, uuid.UUID) (*domain.ProxyTraffic, error) {
+	return nil, nil
+}
+
+func TestUsageMeter(t *testing.T) {
+	ctx := context.Background()
+	proxy := &domain.Proxy{ID: 7}
+	jobID := uuid.New()
+
+	m := newUsageMeter(time.Minute)
+	assert.Nil(t, m.open(proxy, &jobID), "nothing is counted without a repository")
+
+	repo := &fakeUsageRepo{added: make(map[usageKey]domain.ProxyTraffic)}
+	m.setRepo(repo)
+	assert.Nil(t, m.open(&domain.Proxy{}, &jobID), "proxies not in the database are not counted")
+
+	// The upstream echoes what the client sends
+	client, clientEnd := net.Pipe()
+	upstream, upstreamEnd := net.Pipe()
+	go func() {
+		buf := make([]byte, 5)
+		n, _ := upstreamEnd.Read(buf)
+		upstreamEnd.Write(buf[:n])
+		upstreamEnd.Write([]byte("!"))
+		upstreamEnd.Close()
+	}()
+
+	c := m.open(proxy, &jobID)
+	require.NotNil(t, c)
+	done := make(chan struct{})
+	go func() {
+		relay(clientEnd, upstream, c)
+		m.close(c)
+		close(done)
+	}()
+
+	_, err := client.Write([]byte("hello"))
+	require.NoError(t, err)
+	buf := make([]byte, 6)
+	_, err = client.Read(buf[:5])
+	require.NoError(t, err)
+	_, err = client.Read(buf[5:])
+	require.NoError(t, err)
+	assert.Equal(t, "hello!", string(buf))
+	<-done
+	client.Close()
+
+	m.open(proxy, nil)
+
+	// A failed flush keeps its counts for the next one
+	repo.fail = true
+	m.flush(ctx, time.Now())
+	assert.Empty(t, repo.added)
+
+	repo.fail = false
+	m.flush(ctx, time.Now())
+	assert.Equal(t, domain.ProxyTraffic{BytesUp: 5, BytesDown: 6, Requests: 1}, repo.added[usageKey{proxyID: 7, jobID: jobID}])
+	assert.Equal(t, domain.ProxyTraffic{Requests: 1}, repo.added[usageKey{proxyID: 7}])
+
+	// The closed connection's counter is dropped once idle, the open one kept
+	m.flush(ctx, time.Now())
+	assert.Len(t, m.counters, 1)
+}
@@ -2,26 +2,69 @@ package proxygate
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
+	"github.com/sadewadee/google-scraper/internal/domain"
 	"golang.org/x/sync/errgroup"
 )
 
+const (
+	// DefaultProbeURL is the Google Maps endpoint a proxy has to reach
+	DefaultProbeURL = "https://www.google.com/maps"
+
+	// DefaultConnectivityURL tells a dead proxy from one Google blocks
+	DefaultConnectivityURL = "https://www.gstatic.com/generate_204"
+
+	// DefaultProbeTimeout bounds each probe request
+	DefaultProbeTimeout = 10 * time.Second
+)
+
 type Validator struct {
 	pool        *Pool
 	concurrency int
+	geo         GeoResolver    // Optional country enrichment
+	sources     *sourceTracker // Optional per-source pass counts
+
+	// Probe targets, checked in order
+	connectivityURL string
+	probeURL        string
+	timeout         time.Duration
 }
 
 func NewValidator(concurrency int, pool *Pool) *Validator {
 	return &Validator{
-		pool:        pool,
-		concurrency: concurrency,
+		pool:            pool,
+		concurrency:     concurrency,
+		connectivityURL: DefaultConnectivityURL,
+		probeURL:        DefaultProbeURL,
+		timeout:         DefaultProbeTimeout,
 	}
 }
 
+// SetProbe replaces the probe targets and timeout. Empty values keep the
+// current ones.
+func (v *Validator) SetProbe(connectivityURL, probeURL string, timeout time.Duration) {
+	if connectivityURL != "" {
+		v.connectivityURL = connectivityURL
+	}
+	if probeURL != "" {
+		v.probeURL = probeURL
+	}
+	if timeout > 0 {
+		v.timeout = timeout
+	}
+}
+
+// SetGeoResolver enables tagging validated proxies with their country
+func (v *Validator) SetGeoResolver(geo GeoResolver) {
+	v.geo = geo
+}
+
 func (v *Validator) Run(ctx context.Context) error {
 	egroup, ctx := errgroup.WithContext(ctx)
 
@@ -39,51 +82,105 @@ func (v *Validator) worker(ctx context.Context) error {
 		select {
 		case <-ctx.Done():
 			return nil
-		case rawProxy := <-v.pool.raw:
-			// Construct proper URL if no scheme present (raw format: IP:PORT)
-			proxyURL := rawProxy
-			if !strings.Contains(rawProxy, "://") {
-				proxyURL = "socks5://" + rawProxy
+		case raw := <-v.pool.raw:
+			// Raw lines are IP:PORT (SOCKS5) or full proxy URLs with credentials
+			proxy, err := ParseProxyURL(raw.line, ProtocolSOCKS5)
+			if err != nil {
+				v.sources.parseError(raw.source)
+				continue
 			}
-			if v.validate(ctx, proxyURL) {
-				v.pool.AddValidated(rawProxy) // Store original format
+			proxy.SourceURL = raw.source
+			passed := v.validate(ctx, proxyURL(proxy))
+			if ctx.Err() != nil {
+				return nil
+			}
+			v.sources.validated(raw.source, passed)
+			if passed {
+				proxy.Status = domain.ProxyStatusHealthy
+				v.enrichCountry(ctx, proxy)
+				v.pool.AddValidatedProxy(proxy)
 			}
 		}
 	}
 }
 
+// enrichCountry fills in proxy.Country from the GeoIP resolver, if one is
+// configured. A failed lookup leaves the proxy untagged but still usable
+// for jobs that don't ask for a country.
+func (v *Validator) enrichCountry(ctx context.Context, proxy *domain.Proxy) {
+	if v.geo == nil || proxy.Country != "" {
+		return
+	}
+
+	country, err := v.geo.Country(ctx, proxy.IP)
+	if err != nil {
+		log.Printf("[ProxyGate] GeoIP lookup failed for %s: %v", proxyKey(proxy), err)
+		return
+	}
+
+	proxy.Country = country
+}
+
+// validate checks a proxy URL against Google. net/http dials SOCKS5 and
+// HTTP/HTTPS proxies alike and sends credentials from the URL, so the same
+// check covers every supported protocol.
 func (v *Validator) validate(ctx context.Context, proxyURL string) bool {
-	// Step 1: Ping Google
-	if !v.checkURL(ctx, proxyURL, "https://www.google.com") {
-		return false
+	_, err := v.probe(ctx, proxyURL)
+	return err == nil
+}
+
+// probe checks the connectivity target and then Google Maps through the
+// proxy, returning how long the Google Maps request took or why the proxy
+// failed
+func (v *Validator) probe(ctx context.Context, proxyURL string) (time.Duration, error) {
+	// Step 1: Reach anything at all
+	if err := v.checkURL(ctx, proxyURL, v.connectivityURL); err != nil {
+		return 0, err
 	}
 
 	// Step 2: Verify Google Maps
-	return v.checkURL(ctx, proxyURL, "https://www.google.com/maps")
+	start := time.Now()
+	if err := v.checkURL(ctx, proxyURL, v.probeURL); err != nil {
+		return 0, err
+	}
+
+	return time.Since(start), nil
 }
 
-func (v *Validator) checkURL(ctx context.Context, proxyURL, testURL string) bool {
+func (v *Validator) checkURL(ctx context.Context, proxyURL, testURL string) error {
 	proxyFunc := http.ProxyURL(mustParseURL(proxyURL))
 
 	client := &http.Client{
-		Timeout: 10 * time.Second,
+		Timeout: v.timeout,
 		Transport: &http.Transport{
 			Proxy: proxyFunc,
 		},
 	}
 
+	// Each check dials its own proxy; don't leave the connection idle
+	defer client.CloseIdleConnections()
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodHead, testURL, nil)
 	if err != nil {
-		return false
+		return err
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return false
+		return err
 	}
 	defer resp.Body.Close()
 
-	return resp.StatusCode < 400
+	// Google answers a flagged IP with a redirect to its captcha page
+	if strings.HasPrefix(resp.Request.URL.Path, "/sorry/") {
+		return fmt.Errorf("%w at %s", errCaptcha, resp.Request.URL.Host)
+	}
+
+	if resp.StatusCode >= 400 {
+		return &statusError{code: resp.StatusCode, status: resp.Status}
+	}
+
+	return nil
 }
 
 func mustParseURL(s string) *url.URL {
@@ -0,0 +1,258 @@
+// Package querycsv reads search queries from CSV, one per row with its own
+// location, zoom, depth, radius and language, or places to scrape without
+// searching. File mode reads its -input with it and the manager its bulk
+// job uploads, so both accept and reject the same rows.
+package querycsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// Columns are the columns of a query CSV, in the order of a file without a
+// header row. Only keyword is required; a header row may name the columns
+// in any order and leave any but keyword out.
+var Columns = []string{"keyword", "lat", "lon", "zoom", "depth", "radius", "lang"}
+
+// PlaceURLColumn may be named by a header row besides Columns. A row with
+// a place_url instead of a keyword scrapes that place without searching;
+// of its other cells only lang applies.
+const PlaceURLColumn = "place_url"
+
+// Limits of the per-row settings, the ones a job accepts
+const (
+	MinZoom  = 1
+	MaxZoom  = 21
+	MaxDepth = 100
+)
+
+// Row is one search. Blank cells are zero, and nil for the coordinates;
+// they fall back to the settings of the file or upload.
+type Row struct {
+	Line    int // Line of the row in the file, from 1
+	Keyword string
+	Lat     *float64
+	Lon     *float64
+	Zoom    int
+	Depth   int
+	Radius  int // Meters
+	Lang    string
+
+	// PlaceURL is the URL of the place a row without Keyword scrapes, as
+	// domain.PlaceURL normalizes it
+	PlaceURL string
+}
+
+// RowError is a row that was left out
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Parse reads the rows of r. Rows that are not valid are left out and
+// returned as RowErrors, in order; an error is only returned when r cannot
+// be read or its header names an unknown column.
+func Parse(r io.Reader) ([]Row, []*RowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var (
+		rows    []Row
+		invalid []*RowError
+		columns []string
+	)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			// A malformed line is one row; the reader carries on after it
+			invalid = append(invalid, &RowError{Line: parseErr.Line, Err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		if blank(record) {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		if columns == nil {
+			if header, ok := parseHeader(record); ok {
+				if err := checkHeader(header); err != nil {
+					return nil, nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				columns = header
+				continue
+			}
+			columns = Columns
+		}
+
+		row, err := parseRow(columns, record)
+		if err != nil {
+			invalid = append(invalid, &RowError{Line: line, Err: err})
+			continue
+		}
+
+		row.Line = line
+		rows = append(rows, row)
+	}
+
+	return rows, invalid, nil
+}
+
+// parseHeader returns the column names of record if it is a header row,
+// which names the keyword or place_url column
+func parseHeader(record []string) ([]string, bool) {
+	header := make([]string, len(record))
+	isHeader := false
+
+	for i, cell := range record {
+		header[i] = strings.ToLower(strings.TrimSpace(cell))
+		if header[i] == "keyword" || header[i] == PlaceURLColumn {
+			isHeader = true
+		}
+	}
+
+	return header, isHeader
+}
+
+func checkHeader(header []string) error {
+	seen := make(map[string]bool, len(header))
+
+	for _, name := range header {
+		if !slices.Contains(Columns, name) && name != PlaceURLColumn {
+			return fmt.Errorf("unknown column %q, expected %s or %s", name, strings.Join(Columns, ","), PlaceURLColumn)
+		}
+		if seen[name] {
+			return fmt.Errorf("column %q appears twice", name)
+		}
+		seen[name] = true
+	}
+
+	return nil
+}
+
+func parseRow(columns, record []string) (Row, error) {
+	var row Row
+
+	if len(record) > len(columns) {
+		return row, fmt.Errorf("%d cells, expected at most %d", len(record), len(columns))
+	}
+
+	for i, cell := range record {
+		cell = strings.TrimSpace(cell)
+		if cell == "" {
+			continue
+		}
+
+		var err error
+		switch columns[i] {
+		case "keyword":
+			row.Keyword = strings.Join(strings.Fields(cell), " ")
+		case "lat":
+			row.Lat, err = parseCoordinate(cell, 90)
+		case "lon":
+			row.Lon, err = parseCoordinate(cell, 180)
+		case "zoom":
+			row.Zoom, err = parseInt(cell, MinZoom, MaxZoom)
+		case "depth":
+			row.Depth, err = parseInt(cell, 1, MaxDepth)
+		case "radius":
+			row.Radius, err = parseInt(cell, 0, -1)
+		case "lang":
+			row.Lang, err = parseLang(cell)
+		case PlaceURLColumn:
+			row.PlaceURL, err = domain.PlaceURL(cell)
+		}
+		if err != nil {
+			return row, fmt.Errorf("%s: %w", columns[i], err)
+		}
+	}
+
+	switch {
+	case row.Keyword != "" && row.PlaceURL != "":
+		return row, errors.New("keyword and place_url cannot both be set")
+	case row.PlaceURL != "":
+		return row, nil
+	case row.Keyword == "" && slices.Contains(columns, PlaceURLColumn):
+		return row, errors.New("keyword or place_url is required")
+	case row.Keyword == "":
+		return row, errors.New("keyword is required")
+	}
+	if (row.Lat == nil) != (row.Lon == nil) {
+		return row, errors.New("lat and lon must be given together")
+	}
+
+	return row, nil
+}
+
+// parseCoordinate parses a latitude or longitude within ±limit
+func parseCoordinate(s string, limit float64) (*float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a number", s)
+	}
+	if v < -limit || v > limit {
+		return nil, fmt.Errorf("%s is out of range, expected -%g to %g", s, limit, limit)
+	}
+
+	return &v, nil
+}
+
+// parseInt parses an integer of at least lo and at most hi, unbounded for
+// a negative hi
+func parseInt(s string, lo, hi int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a whole number", s)
+	}
+	if v < lo || (hi >= 0 && v > hi) {
+		if hi < 0 {
+			return 0, fmt.Errorf("%d is out of range, expected at least %d", v, lo)
+		}
+		return 0, fmt.Errorf("%d is out of range, expected %d to %d", v, lo, hi)
+	}
+
+	return v, nil
+}
+
+func parseLang(s string) (string, error) {
+	lang := strings.ToLower(s)
+	if len(lang) != 2 || lang[0] < 'a' || lang[0] > 'z' || lang[1] < 'a' || lang[1] > 'z' {
+		return "", fmt.Errorf("%q is not a two-letter language code", s)
+	}
+
+	return lang, nil
+}
+
+func blank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package querycsv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func TestParse(t *testing.T) {
+	input := `keyword,lat,lon,zoom,depth,radius,lang
+pizza,52.52,13.405,14,5,2000,de
+  coffee  shops ,,,,,,
+
+bakery,48.85,,,,,
+dentist,91,2.35,,,,
+florist,,,25,,,
+"bad "quote",,,,,,
+museum,,,,0,,
+library,,,,,,english
+,,,,,,fr
+"ramen, tokyo",35.68,139.69,,,,JA
+`
+
+	rows, invalid, err := Parse(strings.NewReader(input))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Row{
+		{Line: 2, Keyword: "pizza", Lat: ptr(52.52), Lon: ptr(13.405), Zoom: 14, Depth: 5, Radius: 2000, Lang: "de"},
+		{Line: 3, Keyword: "coffee shops"},
+		{Line: 12, Keyword: "ramen, tokyo", Lat: ptr(35.68), Lon: ptr(139.69), Lang: "ja"},
+	}, rows)
+
+	var lines []int
+	var messages []string
+	for _, e := range invalid {
+		lines = append(lines, e.Line)
+		messages = append(messages, e.Error())
+	}
+	assert.Equal(t, []int{5, 6, 7, 8, 9, 10, 11}, lines)
+	assert.Equal(t, "line 5: lat and lon must be given together", messages[0])
+	assert.Equal(t, "line 6: lat: 91 is out of range, expected -90 to 90", messages[1])
+	assert.Equal(t, "line 7: zoom: 25 is out of range, expected 1 to 21", messages[2])
+	assert.Equal(t, `line 10: lang: "english" is not a two-letter language code`, messages[5])
+	assert.Equal(t, "line 11: keyword is required", messages[6])
+}
+
+func TestParseWithoutHeader(t *testing.T) {
+	rows, invalid, err := Parse(strings.NewReader("pizza,40.71,-74.00,,3\ncafe\ncafe,1,2,3,4,5,en,extra\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []Row{
+		{Line: 1, Keyword: "pizza", Lat: ptr(40.71), Lon: ptr(-74.00), Depth: 3},
+		{Line: 2, Keyword: "cafe"},
+	}, rows)
+	require.Len(t, invalid, 1)
+	assert.Equal(t, "line 3: 8 cells, expected at most 7", invalid[0].Error())
+}
+
+func TestParseHeaderInAnyOrder(t *testing.T) {
+	rows, invalid, err := Parse(strings.NewReader("Lang,Keyword\nfr,boulangerie\n"))
+	require.NoError(t, err)
+	assert.Empty(t, invalid)
+	assert.Equal(t, []Row{{Line: 2, Keyword: "boulangerie", Lang: "fr"}}, rows)
+
+	_, _, err = Parse(strings.NewReader("keyword,city\npizza,Rome\n"))
+	assert.EqualError(t, err, `line 1: unknown column "city", expected keyword,lat,lon,zoom,depth,radius,lang or place_url`)
+}
+
+func TestParsePlaceURLs(t *testing.T) {
+	input := `keyword,place_url,lang
+,"https://www.google.com/maps/place/Cafe/@52.5,13.4,17z",de
+,ChIJN1t_tDeuEmsRUsoyG83frY4,
+pizza,,
+pizza,ChIJN1t_tDeuEmsRUsoyG83frY4,
+,https://example.com/cafe,
+,,fr
+`
+
+	rows, invalid, err := Parse(strings.NewReader(input))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Row{
+		{Line: 2, PlaceURL: "https://www.google.com/maps/place/Cafe/@52.5,13.4,17z", Lang: "de"},
+		{Line: 3, PlaceURL: "https://www.google.com/maps/place/?q=place_id:ChIJN1t_tDeuEmsRUsoyG83frY4"},
+		{Line: 4, Keyword: "pizza"},
+	}, rows)
+
+	require.Len(t, invalid, 3)
+	assert.Equal(t, "line 5: keyword and place_url cannot both be set", invalid[0].Error())
+	assert.Equal(t, "line 6: place_url: not a Google Maps URL", invalid[1].Error())
+	assert.Equal(t, "line 7: keyword or place_url is required", invalid[2].Error())
+}
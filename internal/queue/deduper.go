@@ -13,6 +13,7 @@ type Deduper struct {
 	client *redis.Client
 	prefix string
 	ttl    time.Duration
+	scoped bool // Shares the client of the deduper it was scoped from
 }
 
 // DedupeConfig holds deduper configuration
@@ -83,6 +84,18 @@ func NewDeduper(cfg *DedupeConfig) (*Deduper, error) {
 	}, nil
 }
 
+// Scope returns a deduper whose keys are under prefix:scope, so that they
+// do not collide with those of other scopes, e.g. other jobs. It shares
+// the connection; closing it does not close the connection.
+func (d *Deduper) Scope(scope string) *Deduper {
+	return &Deduper{
+		client: d.client,
+		prefix: d.prefix + ":" + scope,
+		ttl:    d.ttl,
+		scoped: true,
+	}
+}
+
 // IsDuplicate checks if a place has already been scraped
 // Returns true if duplicate (already seen), false if new
 func (d *Deduper) IsDuplicate(ctx context.Context, placeID string) (bool, error) {
@@ -117,6 +130,56 @@ func (d *Deduper) MarkAsSeen(ctx context.Context, placeID string) error {
 	return d.client.Set(ctx, key, 1, d.ttl).Err()
 }
 
+// Forget removes a URL marked by IsDuplicateURL, so it is processed again
+// the next time it shows up
+func (d *Deduper) Forget(ctx context.Context, url string) error {
+	key := fmt.Sprintf("%s:url:%s", d.prefix, url)
+
+	if err := d.client.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("failed to forget URL: %w", err)
+	}
+
+	return nil
+}
+
+// releaseScript deletes a lease only while holder still has it
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Lease marks key as held by holder for ttl. It returns false when
+// another holder has it.
+func (d *Deduper) Lease(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	ok, err := d.client.SetNX(ctx, d.leaseKey(key), holder, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to lease %s: %w", key, err)
+	}
+	return ok, nil
+}
+
+// RenewLease holds key for another ttl
+func (d *Deduper) RenewLease(ctx context.Context, key string, ttl time.Duration) error {
+	if err := d.client.Expire(ctx, d.leaseKey(key), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to renew lease of %s: %w", key, err)
+	}
+	return nil
+}
+
+// ReleaseLease ends the lease of key, unless it expired and another
+// holder took it since
+func (d *Deduper) ReleaseLease(ctx context.Context, key, holder string) error {
+	if err := releaseScript.Run(ctx, d.client, []string{d.leaseKey(key)}, holder).Err(); err != nil {
+		return fmt.Errorf("failed to release lease of %s: %w", key, err)
+	}
+	return nil
+}
+
+func (d *Deduper) leaseKey(key string) string {
+	return fmt.Sprintf("%s:lease:%s", d.prefix, key)
+}
+
 // Clear removes all dedup keys (use with caution)
 func (d *Deduper) Clear(ctx context.Context) error {
 	pattern := fmt.Sprintf("%s:*", d.prefix)
@@ -146,7 +209,7 @@ func (d *Deduper) Stats(ctx context.Context) (int64, error) {
 
 // Close closes the Redis connection
 func (d *Deduper) Close() error {
-	if d.client != nil {
+	if d.client != nil && !d.scoped {
 		return d.client.Close()
 	}
 	return nil
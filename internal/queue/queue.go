@@ -10,6 +10,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
 )
 
 const (
@@ -28,6 +30,10 @@ type JobPayload struct {
 	JobID     uuid.UUID `json:"job_id"`
 	Priority  int       `json:"priority"`
 	CreatedAt time.Time `json:"created_at"`
+
+	// MessageID identifies one enqueue of the job; a manual requeue
+	// enqueues the same ID again so workers handle it once
+	MessageID string `json:"message_id,omitempty"`
 }
 
 // Config holds Redis queue configuration
@@ -79,12 +85,13 @@ func New(cfg *Config) (*Queue, error) {
 	}, nil
 }
 
-// Enqueue adds a job to the queue
-func (q *Queue) Enqueue(ctx context.Context, jobID uuid.UUID, priority int) error {
+// Enqueue adds a job to the queue under messageID
+func (q *Queue) Enqueue(ctx context.Context, jobID uuid.UUID, priority int, messageID string) error {
 	payload := JobPayload{
 		JobID:     jobID,
 		Priority:  priority,
 		CreatedAt: time.Now(),
+		MessageID: messageID,
 	}
 
 	data, err := json.Marshal(payload)
@@ -142,6 +149,46 @@ func (q *Queue) GetQueueStats(ctx context.Context) (map[string]*asynq.QueueInfo,
 	return stats, nil
 }
 
+// QueueStatus returns the pending and active tasks of the priority queues,
+// the age of their oldest pending task, and how many worker servers
+// consume each
+func (q *Queue) QueueStatus(ctx context.Context) (*domain.QueueStatus, error) {
+	servers, err := q.inspector.Servers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queue servers: %w", err)
+	}
+
+	status := &domain.QueueStatus{Backend: domain.QueueBackendRedis}
+	for _, name := range []string{QueueCritical, QueueHigh, QueueDefault, QueueLow} {
+		depth := &domain.QueueDepth{Name: name}
+		for _, s := range servers {
+			if _, ok := s.Queues[name]; ok {
+				depth.Consumers++
+			}
+		}
+
+		// A queue nothing was enqueued to yet does not exist
+		info, err := q.inspector.GetQueueInfo(name)
+		if err == nil {
+			depth.Depth = info.Pending
+			depth.InFlight = info.Active
+			if info.Pending > 0 {
+				age := info.Latency.Seconds()
+				depth.OldestAgeSeconds = &age
+			}
+		}
+
+		status.Queues = append(status.Queues, depth)
+	}
+
+	return status, nil
+}
+
+// Ping checks the connection to Redis
+func (q *Queue) Ping() error {
+	return q.client.Ping()
+}
+
 // Close closes the queue client
 func (q *Queue) Close() error {
 	if q.client != nil {
@@ -130,6 +130,14 @@ func (w *Worker) Run(ctx context.Context) error {
 	}
 }
 
+// Pause stops pulling new tasks off the queues. The task being processed
+// runs to completion.
+func (w *Worker) Pause() {
+	if w.server != nil {
+		w.server.Stop()
+	}
+}
+
 // Shutdown gracefully shuts down the worker
 func (w *Worker) Shutdown() {
 	if w.server != nil {
@@ -0,0 +1,204 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSMTPPort is the submission port, upgraded with STARTTLS
+const DefaultSMTPPort = 587
+
+// smtpTimeout bounds a whole delivery when the context has no deadline
+const smtpTimeout = time.Minute
+
+// SMTPConfig is the server reports are sent through
+type SMTPConfig struct {
+	Host     string
+	Port     int // 465 for implicit TLS, else STARTTLS when offered
+	Username string
+	Password string
+	From     string
+}
+
+// Enabled tells whether a server is configured
+func (c SMTPConfig) Enabled() bool {
+	return c.Host != ""
+}
+
+// Mailer sends reports by email
+type Mailer struct {
+	cfg SMTPConfig
+}
+
+// NewMailer creates a Mailer for cfg, which needs a host and a sender
+func NewMailer(cfg SMTPConfig) (*Mailer, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("smtp host is required")
+	}
+	if cfg.From == "" {
+		return nil, errors.New("smtp sender address is required")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = DefaultSMTPPort
+	}
+
+	return &Mailer{cfg: cfg}, nil
+}
+
+// Send emails r to the addresses in to
+func (m *Mailer) Send(ctx context.Context, to []string, r *Report) error {
+	msg, err := m.message(to, r)
+	if err != nil {
+		return err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: m.cfg.Host}
+	dialer := &net.Dialer{Deadline: deadline}
+
+	var conn net.Conn
+	if m.cfg.Port == 465 {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("connect to smtp server: %w", err)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake: %w", err)
+	}
+	defer c.Close()
+
+	if m.cfg.Port != 465 {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("smtp starttls: %w", err)
+			}
+		}
+	}
+
+	if m.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(m.cfg.From); err != nil {
+		return fmt.Errorf("smtp sender: %w", err)
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp recipient %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+
+	return c.Quit()
+}
+
+// message builds the MIME message of r: the text and HTML versions as
+// alternatives, related to the map attached inline
+func (m *Mailer) message(to []string, r *Report) ([]byte, error) {
+	var buf bytes.Buffer
+
+	related := multipart.NewWriter(&buf)
+	altBoundary := multipart.NewWriter(nil).Boundary()
+
+	fmt.Fprintf(&buf, "From: %s\r\n", m.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", r.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/related; boundary=%q; type=\"multipart/alternative\"\r\n\r\n", related.Boundary())
+
+	altPart, err := related.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {fmt.Sprintf("multipart/alternative; boundary=%q", altBoundary)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	alternative := multipart.NewWriter(altPart)
+	if err := alternative.SetBoundary(altBoundary); err != nil {
+		return nil, err
+	}
+
+	for _, body := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", r.Text},
+		{"text/html; charset=utf-8", r.HTML},
+	} {
+		part, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {body.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(body.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := alternative.Close(); err != nil {
+		return nil, err
+	}
+
+	if r.Map != nil {
+		part, err := related.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {"image/png"},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-ID":                {"<" + MapContentID + ">"},
+			"Content-Disposition":       {`inline; filename="coverage.png"`},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(r.Map)
+		for len(encoded) > 76 {
+			fmt.Fprintf(part, "%s\r\n", encoded[:76])
+			encoded = encoded[76:]
+		}
+		fmt.Fprintf(part, "%s\r\n", encoded)
+	}
+
+	if err := related.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// Size of the coverage map in pixels
+const (
+	mapWidth  = 480
+	mapHeight = 280
+	mapMargin = 12
+)
+
+// minMapSpan keeps a map of places on one spot from zooming in on a single
+// pixel, in degrees of latitude
+const minMapSpan = 0.01
+
+var (
+	mapBackground = color.RGBA{R: 0xf4, G: 0xf5, B: 0xf7, A: 0xff}
+	mapPlace      = color.RGBA{R: 0x3e, G: 0x7b, B: 0xfa, A: 0xff}
+)
+
+// drawMap draws points as dots on a plain map of their bounding box, in
+// equirectangular projection scaled by the latitude so distances keep
+// their proportions
+func drawMap(points []domain.GeoPoint) ([]byte, error) {
+	minLat, maxLat := points[0].Lat, points[0].Lat
+	minLon, maxLon := points[0].Lon, points[0].Lon
+	for _, p := range points[1:] {
+		minLat, maxLat = math.Min(minLat, p.Lat), math.Max(maxLat, p.Lat)
+		minLon, maxLon = math.Min(minLon, p.Lon), math.Max(maxLon, p.Lon)
+	}
+
+	midLat, midLon := (minLat+maxLat)/2, (minLon+maxLon)/2
+	lonScale := math.Max(math.Cos(midLat*math.Pi/180), 0.01)
+
+	// Degrees of latitude per pixel, fitting the box on both axes
+	spanLat := math.Max(maxLat-minLat, minMapSpan)
+	spanLon := (maxLon - minLon) * lonScale
+	scale := math.Max(spanLat/float64(mapHeight-2*mapMargin), spanLon/float64(mapWidth-2*mapMargin))
+
+	img := image.NewRGBA(image.Rect(0, 0, mapWidth, mapHeight))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = mapBackground.R, mapBackground.G, mapBackground.B, mapBackground.A
+	}
+
+	for _, p := range points {
+		x := mapWidth/2 + int(math.Round((p.Lon-midLon)*lonScale/scale))
+		y := mapHeight/2 - int(math.Round((p.Lat-midLat)/scale))
+		drawDot(img, x, y)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// drawDot draws a dot of 5 pixels across centered on x, y
+func drawDot(img *image.RGBA, x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			if dx*dx+dy*dy > 5 {
+				continue
+			}
+			img.SetRGBA(x+dx, y+dy, mapPlace)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Package report renders the summary of a finished job, sent by email to
+// the job's notify_emails and served by GET /api/v2/jobs/{id}/report.
+package report
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// Limits of the summary
+const (
+	TopCategories = 10   // Categories listed
+	MaxMapPoints  = 2000 // Places drawn on the coverage map
+)
+
+//go:embed templates/*
+var templatesFS embed.FS
+
+var (
+	htmlTemplate = htmltemplate.Must(htmltemplate.ParseFS(templatesFS, "templates/report.html"))
+	textTemplate = texttemplate.Must(texttemplate.ParseFS(templatesFS, "templates/report.txt"))
+)
+
+// MapMode is how the HTML of a report refers to its coverage map
+type MapMode int
+
+const (
+	// MapInline embeds the map as a data URI, for the report endpoint
+	MapInline MapMode = iota
+
+	// MapAttached refers to the map as cid:MapContentID, for emails that
+	// carry it as an inline attachment
+	MapAttached
+)
+
+// MapContentID is the Content-ID of the map attached to a report email
+const MapContentID = "coverage-map"
+
+// Report is the summary of a job as HTML and plain text
+type Report struct {
+	Subject string
+	HTML    string
+	Text    string
+
+	// Map is the coverage map as PNG, nil when no place has coordinates
+	Map []byte
+}
+
+// templateData is what the templates render
+type templateData struct {
+	Subject    string
+	Job        *domain.Job
+	Summary    *domain.JobReport
+	Status     string
+	Finished   string
+	Keywords   int
+	Coverage   []coverageRow
+	Categories []categoryRow
+	MapSrc     htmltemplate.URL
+	MapWidth   int
+	MapHeight  int
+}
+
+type coverageRow struct {
+	Label   string
+	Count   int
+	Percent int
+}
+
+type categoryRow struct {
+	Category string
+	Count    int
+	Percent  int // Of the largest category, for the bar width
+}
+
+// Render renders the report of job from its summary
+func Render(job *domain.Job, summary *domain.JobReport, mode MapMode) (*Report, error) {
+	data := templateData{
+		Subject:   fmt.Sprintf("Job %q %s: %d places", job.Name, job.Status, summary.Places),
+		Job:       job,
+		Summary:   summary,
+		Status:    string(job.Status),
+		Keywords:  len(job.Config.Keywords),
+		MapWidth:  mapWidth,
+		MapHeight: mapHeight,
+		Coverage: []coverageRow{
+			{Label: "email", Count: summary.WithEmail, Percent: percent(summary.WithEmail, summary.Places)},
+			{Label: "phone", Count: summary.WithPhone, Percent: percent(summary.WithPhone, summary.Places)},
+			{Label: "website", Count: summary.WithWebsite, Percent: percent(summary.WithWebsite, summary.Places)},
+		},
+	}
+
+	if job.CompletedAt != nil {
+		data.Finished = job.CompletedAt.UTC().Format(time.RFC1123)
+	}
+
+	for _, c := range summary.TopCategories {
+		data.Categories = append(data.Categories, categoryRow{
+			Category: c.Category,
+			Count:    c.Count,
+			Percent:  percent(c.Count, summary.TopCategories[0].Count),
+		})
+	}
+
+	r := &Report{Subject: data.Subject}
+
+	if len(summary.Points) > 0 {
+		png, err := drawMap(summary.Points)
+		if err != nil {
+			return nil, fmt.Errorf("draw coverage map: %w", err)
+		}
+		r.Map = png
+
+		switch mode {
+		case MapAttached:
+			data.MapSrc = htmltemplate.URL("cid:" + MapContentID)
+		default:
+			data.MapSrc = htmltemplate.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render html report: %w", err)
+	}
+	r.HTML = buf.String()
+
+	buf.Reset()
+	if err := textTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render text report: %w", err)
+	}
+	r.Text = buf.String()
+
+	return r, nil
+}
+
+func percent(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return n * 100 / total
+}
@@ -0,0 +1,128 @@
+package report
+
+import (
+	"bytes"
+	"image/png"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+func testReport(t *testing.T, mode MapMode) *Report {
+	t.Helper()
+
+	completed := time.Date(2026, 3, 2, 15, 4, 0, 0, time.UTC)
+	job := &domain.Job{
+		ID:          uuid.MustParse("6f1c6c3e-93c1-4f55-9a35-0d1e2a7b8c90"),
+		Name:        "Berlin <cafés>",
+		Status:      domain.JobStatusCompleted,
+		Config:      domain.JobConfig{Keywords: []string{"cafe", "bakery"}, LocationName: "Berlin"},
+		CompletedAt: &completed,
+	}
+	summary := &domain.JobReport{
+		JobID:       job.ID,
+		Places:      200,
+		WithEmail:   50,
+		WithPhone:   180,
+		WithWebsite: 120,
+		TopCategories: []domain.CategoryCount{
+			{Category: "Cafe", Count: 120},
+			{Category: "Bakery", Count: 60},
+		},
+		Points: []domain.GeoPoint{{Lat: 52.52, Lon: 13.40}, {Lat: 52.50, Lon: 13.45}},
+	}
+
+	r, err := Render(job, summary, mode)
+	require.NoError(t, err)
+
+	return r
+}
+
+func TestRender(t *testing.T) {
+	r := testReport(t, MapInline)
+
+	assert.Equal(t, `Job "Berlin <cafés>" completed: 200 places`, r.Subject)
+	assert.Contains(t, r.HTML, "Berlin &lt;cafés&gt;")
+	assert.Contains(t, r.HTML, "with email (25%)")
+	assert.Contains(t, r.HTML, `src="data:image/png;base64,`)
+	assert.Contains(t, r.HTML, "width:50%") // Bakery against the top category
+
+	assert.Contains(t, r.Text, "Places: 200\nWith email: 50 (25%)\nWith phone: 180 (90%)\nWith website: 120 (60%)\n")
+	assert.Contains(t, r.Text, "  Cafe: 120\n  Bakery: 60\n")
+
+	img, err := png.Decode(bytes.NewReader(r.Map))
+	require.NoError(t, err)
+	assert.Equal(t, mapWidth, img.Bounds().Dx())
+
+	attached := testReport(t, MapAttached)
+	assert.Contains(t, attached.HTML, `src="cid:`+MapContentID+`"`)
+}
+
+func TestRenderWithoutPlaces(t *testing.T) {
+	job := &domain.Job{ID: uuid.New(), Name: "empty", Status: domain.JobStatusCompleted}
+
+	r, err := Render(job, &domain.JobReport{JobID: job.ID}, MapInline)
+	require.NoError(t, err)
+
+	assert.Nil(t, r.Map)
+	assert.NotContains(t, r.HTML, "<img")
+	assert.Contains(t, r.Text, "With email: 0 (0%)")
+}
+
+func TestMailerMessage(t *testing.T) {
+	m, err := NewMailer(SMTPConfig{Host: "smtp.example.com", From: "reports@example.com"})
+	require.NoError(t, err)
+
+	r := testReport(t, MapAttached)
+	raw, err := m.message([]string{"am@example.com", "ops@example.com"}, r)
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "am@example.com, ops@example.com", msg.Header.Get("To"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, r.Subject, subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/related", mediaType)
+
+	related := multipart.NewReader(msg.Body, params["boundary"])
+
+	alt, err := related.NextPart()
+	require.NoError(t, err)
+	_, altParams, err := mime.ParseMediaType(alt.Header.Get("Content-Type"))
+	require.NoError(t, err)
+
+	alternative := multipart.NewReader(alt, altParams["boundary"])
+	var bodies []string
+	for {
+		part, err := alternative.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(part) // Decodes quoted-printable
+		require.NoError(t, err)
+		bodies = append(bodies, part.Header.Get("Content-Type")+"|"+strings.ReplaceAll(string(body), "\r\n", "\n"))
+	}
+	require.Len(t, bodies, 2)
+	assert.Equal(t, "text/plain; charset=utf-8|"+r.Text, bodies[0])
+	assert.Equal(t, "text/html; charset=utf-8|"+r.HTML, bodies[1])
+
+	image, err := related.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "<"+MapContentID+">", image.Header.Get("Content-ID"))
+}
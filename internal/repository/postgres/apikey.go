@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// APIKeyRepository implements domain.APIKeyRepository for PostgreSQL
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository creates a new APIKeyRepository
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create creates a new API key
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scopes: %w", err)
+	}
+
+	query := `
+		INSERT INTO api_keys (id, name, prefix, key_hash, scopes, expires_at, monthly_place_quota, export_profile, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		key.ID, key.Name, key.Prefix, key.KeyHash, scopes, nullTime(key.ExpiresAt), key.MonthlyPlaceQuota, key.ExportProfile,
+	).Scan(&key.CreatedAt)
+}
+
+// GetByHash retrieves an API key by the hash of its secret
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	query := `
+		SELECT id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at, monthly_place_quota, export_profile
+		FROM api_keys
+		WHERE key_hash = $1
+	`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return key, err
+}
+
+// List retrieves all API keys
+func (r *APIKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	query := `
+		SELECT id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at, monthly_place_quota, export_profile
+		FROM api_keys
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Delete deletes an API key by ID
+func (r *APIKeyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM api_keys WHERE id = $1`, id)
+	return err
+}
+
+// TouchLastUsed updates the last_used_at timestamp
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	key := &domain.APIKey{}
+	var scopes []byte
+	var expiresAt, lastUsedAt sql.NullTime
+	var quota sql.NullInt32
+	var profile sql.NullString
+
+	if err := row.Scan(
+		&key.ID, &key.Name, &key.Prefix, &key.KeyHash, &scopes,
+		&expiresAt, &lastUsedAt, &key.CreatedAt, &quota, &profile,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(scopes, &key.Scopes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal scopes: %w", err)
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if quota.Valid {
+		q := int(quota.Int32)
+		key.MonthlyPlaceQuota = &q
+	}
+	if profile.Valid {
+		key.ExportProfile = &profile.String
+	}
+
+	return key, nil
+}
+
+// Verify interface compliance at compile time
+var _ domain.APIKeyRepository = (*APIKeyRepository)(nil)
@@ -6,18 +6,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"strings"
 
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
 	"github.com/sadewadee/google-scraper/internal/domain"
 )
 
 // BusinessListingRepository provides access to business_listings
 type BusinessListingRepository struct {
-	db *sql.DB
+	db querier
 }
 
 // NewBusinessListingRepository creates a new repository
-func NewBusinessListingRepository(db *sql.DB) *BusinessListingRepository {
+func NewBusinessListingRepository(db querier) *BusinessListingRepository {
 	return &BusinessListingRepository{db: db}
 }
 
@@ -55,11 +59,21 @@ func buildFilterClauses(filter domain.BusinessListingFilter, startArgNum int) fi
 	argNum := startArgNum
 
 	if filter.JobID != nil {
-		conditions = append(conditions, fmt.Sprintf("bl.job_id = $%d", argNum))
+		conditions = append(conditions, jobScope("bl.job_id", fmt.Sprintf("$%d", argNum)))
 		args = append(args, filter.JobID.String())
 		argNum++
 	}
 
+	// Listings of every job carrying the tag
+	if filter.JobTag != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			"bl.job_id IN (SELECT id FROM jobs_queue WHERE tags @> ARRAY[$%d]::text[])",
+			argNum,
+		))
+		args = append(args, filter.JobTag)
+		argNum++
+	}
+
 	if filter.Search != "" {
 		searchPattern := "%" + escapeLikePattern(filter.Search) + "%"
 		conditions = append(conditions, fmt.Sprintf(
@@ -76,6 +90,13 @@ func buildFilterClauses(filter domain.BusinessListingFilter, startArgNum int) fi
 		argNum++
 	}
 
+	// Canonical names are matched case-insensitively, like aliases
+	if filter.CanonicalCategory != "" {
+		conditions = append(conditions, fmt.Sprintf("lower(bl.canonical_category) = lower($%d)", argNum))
+		args = append(args, filter.CanonicalCategory)
+		argNum++
+	}
+
 	if filter.City != "" {
 		conditions = append(conditions, fmt.Sprintf("bl.address_city = $%d", argNum))
 		args = append(args, filter.City)
@@ -88,12 +109,99 @@ func buildFilterClauses(filter domain.BusinessListingFilter, startArgNum int) fi
 		argNum++
 	}
 
+	if filter.State != "" {
+		conditions = append(conditions, fmt.Sprintf("lower(bl.address_state) = lower($%d)", argNum))
+		args = append(args, filter.State)
+		argNum++
+	}
+
+	// Postal codes are stored upper-cased
+	if filter.Postcode != "" {
+		conditions = append(conditions, fmt.Sprintf("bl.address_postal_code LIKE $%d", argNum))
+		args = append(args, escapeLikePattern(strings.ToUpper(filter.Postcode))+"%")
+		argNum++
+	}
+
 	if filter.MinRating != nil {
 		conditions = append(conditions, fmt.Sprintf("bl.review_rating >= $%d", argNum))
 		args = append(args, *filter.MinRating)
 		argNum++
 	}
 
+	if filter.Attribute != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM jsonb_each(bl.attributes) s, jsonb_array_elements_text(s.value) v WHERE lower(v) = lower($%d))",
+			argNum,
+		))
+		args = append(args, filter.Attribute)
+		argNum++
+	}
+
+	// Listed days only: a listing without parsed hours matches no day
+	if filter.OpenOn != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			"(bl.opening_hours -> 'days' ? $%d AND NOT bl.opening_hours -> 'days' @> jsonb_build_object($%d::text, '{\"closed\": true}'::jsonb))",
+			argNum, argNum,
+		))
+		args = append(args, filter.OpenOn)
+		argNum++
+	}
+
+	if filter.OnlyNew {
+		conditions = append(conditions, "bl.is_new")
+	}
+
+	if filter.WebsiteStatus != "" && domain.ValidWebsiteStatuses[filter.WebsiteStatus] {
+		conditions = append(conditions, fmt.Sprintf("bl.website_status = $%d", argNum))
+		args = append(args, filter.WebsiteStatus)
+		argNum++
+	}
+
+	if filter.BusinessStatus != "" && domain.ValidBusinessStatuses[filter.BusinessStatus] {
+		conditions = append(conditions, fmt.Sprintf("bl.business_status = $%d", argNum))
+		args = append(args, filter.BusinessStatus)
+		argNum++
+	}
+
+	if filter.Completeness != "" && domain.ValidCompleteness[filter.Completeness] {
+		conditions = append(conditions, fmt.Sprintf("bl.data_completeness = $%d", argNum))
+		args = append(args, filter.Completeness)
+		argNum++
+	}
+
+	if filter.Timezone != "" {
+		conditions = append(conditions, fmt.Sprintf("bl.timezone = $%d", argNum))
+		args = append(args, filter.Timezone)
+		argNum++
+	}
+
+	if filter.HasValidPhone != nil {
+		if *filter.HasValidPhone {
+			conditions = append(conditions, "bl.phone_e164 IS NOT NULL")
+		} else {
+			conditions = append(conditions, "bl.phone_e164 IS NULL")
+		}
+	}
+
+	if box := filter.BBox; box != nil {
+		conditions = append(conditions,
+			fmt.Sprintf("bl.latitude BETWEEN $%d AND $%d", argNum, argNum+1),
+			fmt.Sprintf("bl.longitude BETWEEN $%d AND $%d", argNum+2, argNum+3),
+		)
+		args = append(args, box.MinLat, box.MaxLat, box.MinLon, box.MaxLon)
+		argNum += 4
+	}
+
+	if c := filter.Cursor; c != nil && !c.IsZero() {
+		op := "<"
+		if strings.ToLower(filter.SortOrder) == "asc" {
+			op = ">"
+		}
+		conditions = append(conditions, fmt.Sprintf("(bl.created_at, bl.id) %s ($%d, $%d)", op, argNum, argNum+1))
+		args = append(args, c.CreatedAt, c.ID)
+		argNum += 2
+	}
+
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
@@ -136,19 +244,36 @@ func (r *BusinessListingRepository) scanListing(rows *sql.Rows) (*domain.Busines
 	var bl domain.BusinessListing
 	var jobID, placeID, cid, category, address, phone, website sql.NullString
 	var addressCity, addressCountry, status, priceRange, link sql.NullString
-	var latitude, longitude, reviewRating sql.NullFloat64
+	var addressStreet, addressNumber, addressPostalCode, addressState sql.NullString
+	var websitePhone, websiteDesc sql.NullString
+	var dataID, reviewsLink, plusCode, timezone, description sql.NullString
+	var canonicalCategory sql.NullString
+	var websiteStatus, websiteFinalURL sql.NullString
+	var websiteHTTPStatus sql.NullInt64
+	var websiteCheckedAt sql.NullTime
+	var isNew sql.NullBool
+	var firstSeenJobID, phoneE164 sql.NullString
+	var latitude, longitude, reviewRating, bayesianRating sql.NullFloat64
 	var categories []byte
 	var emailsInfoJSON []byte
 	var emailsArray []byte
+	var imageURLs, attributes, socialLinks, openingHours, reviewsPerRating []byte
 
 	err := rows.Scan(
 		&bl.ID, &bl.ResultID, &jobID, &placeID, &cid,
 		&bl.Title, &category, &categories, &address, &phone,
 		&website, &latitude, &longitude, &addressCity, &addressCountry,
-		&bl.ReviewCount, &reviewRating, &status, &priceRange, &link,
-		&bl.CreatedAt,
+		&addressStreet, &addressNumber, &addressPostalCode, &addressState,
+		&bl.ReviewCount, &reviewRating, &status, &bl.BusinessStatus, &priceRange, &link,
+		&bl.CreatedAt, &imageURLs, &attributes,
+		&socialLinks, &websitePhone, &websiteDesc,
 		&emailsInfoJSON, &emailsArray,
 		&bl.ValidEmailCount, &bl.TotalEmailCount,
+		&isNew, &firstSeenJobID, &phoneE164, &openingHours,
+		&dataID, &reviewsLink, &plusCode, &timezone, &description,
+		&canonicalCategory,
+		&websiteStatus, &websiteHTTPStatus, &websiteFinalURL, &websiteCheckedAt,
+		&bl.Completeness, &reviewsPerRating, &bayesianRating,
 	)
 	if err != nil {
 		return nil, err
@@ -188,9 +313,24 @@ func (r *BusinessListingRepository) scanListing(rows *sql.Rows) (*domain.Busines
 	if addressCountry.Valid {
 		bl.AddressCountry = &addressCountry.String
 	}
+	if addressStreet.Valid {
+		bl.AddressStreet = &addressStreet.String
+	}
+	if addressNumber.Valid {
+		bl.AddressNumber = &addressNumber.String
+	}
+	if addressPostalCode.Valid {
+		bl.AddressPostalCode = &addressPostalCode.String
+	}
+	if addressState.Valid {
+		bl.AddressState = &addressState.String
+	}
 	if reviewRating.Valid {
 		bl.ReviewRating = &reviewRating.Float64
 	}
+	if bayesianRating.Valid {
+		bl.BayesianRating = &bayesianRating.Float64
+	}
 	if status.Valid {
 		bl.Status = &status.String
 	}
@@ -200,6 +340,36 @@ func (r *BusinessListingRepository) scanListing(rows *sql.Rows) (*domain.Busines
 	if link.Valid {
 		bl.Link = &link.String
 	}
+	if websitePhone.Valid {
+		bl.WebsitePhone = &websitePhone.String
+	}
+	if websiteDesc.Valid {
+		bl.WebsiteDesc = &websiteDesc.String
+	}
+	if isNew.Valid {
+		bl.IsNew = &isNew.Bool
+	}
+	if firstSeenJobID.Valid {
+		bl.FirstSeenJobID = &firstSeenJobID.String
+	}
+	if phoneE164.Valid {
+		bl.PhoneE164 = &phoneE164.String
+	}
+	bl.DataID = nullStringPtr(dataID)
+	bl.ReviewsLink = nullStringPtr(reviewsLink)
+	bl.PlusCode = nullStringPtr(plusCode)
+	bl.Timezone = nullStringPtr(timezone)
+	bl.Description = nullStringPtr(description)
+	bl.CanonicalCategory = nullStringPtr(canonicalCategory)
+	bl.WebsiteStatus = nullStringPtr(websiteStatus)
+	bl.WebsiteFinalURL = nullStringPtr(websiteFinalURL)
+	if websiteHTTPStatus.Valid {
+		code := int(websiteHTTPStatus.Int64)
+		bl.WebsiteHTTPStatus = &code
+	}
+	if websiteCheckedAt.Valid {
+		bl.WebsiteCheckedAt = &websiteCheckedAt.Time
+	}
 
 	// Parse categories array
 	if len(categories) > 0 {
@@ -222,6 +392,41 @@ func (r *BusinessListingRepository) scanListing(rows *sql.Rows) (*domain.Busines
 		}
 	}
 
+	// Parse image URLs
+	if len(imageURLs) > 0 {
+		if err := json.Unmarshal(imageURLs, &bl.ImageURLs); err != nil {
+			log.Printf("[BusinessListingRepository] Warning: failed to unmarshal image_urls for listing %d: %v", bl.ID, err)
+		}
+	}
+
+	// Parse attributes
+	if len(attributes) > 0 {
+		if err := json.Unmarshal(attributes, &bl.Attributes); err != nil {
+			log.Printf("[BusinessListingRepository] Warning: failed to unmarshal attributes for listing %d: %v", bl.ID, err)
+		}
+	}
+
+	// Parse social links
+	if len(socialLinks) > 0 {
+		if err := json.Unmarshal(socialLinks, &bl.SocialLinks); err != nil {
+			log.Printf("[BusinessListingRepository] Warning: failed to unmarshal social_links for listing %d: %v", bl.ID, err)
+		}
+	}
+
+	// Parse opening hours
+	if len(openingHours) > 0 {
+		if err := json.Unmarshal(openingHours, &bl.OpeningHours); err != nil {
+			log.Printf("[BusinessListingRepository] Warning: failed to unmarshal opening_hours for listing %d: %v", bl.ID, err)
+		}
+	}
+
+	// Parse the review histogram
+	if len(reviewsPerRating) > 0 {
+		if err := json.Unmarshal(reviewsPerRating, &bl.ReviewsPerRating); err != nil {
+			log.Printf("[BusinessListingRepository] Warning: failed to unmarshal reviews_per_rating for listing %d: %v", bl.ID, err)
+		}
+	}
+
 	return &bl, nil
 }
 
@@ -232,8 +437,10 @@ func baseSelectQuery() string {
 			bl.id, bl.result_id, bl.job_id, bl.place_id, bl.cid,
 			bl.title, bl.category, COALESCE(array_to_json(bl.categories), '[]'::json) AS categories, bl.address, bl.phone,
 			bl.website, bl.latitude, bl.longitude, bl.address_city, bl.address_country,
-			bl.review_count, bl.review_rating, bl.status, bl.price_range, bl.link,
-			bl.created_at,
+			bl.address_street, bl.address_number, bl.address_postal_code, bl.address_state,
+			bl.review_count, bl.review_rating, bl.status, bl.business_status, bl.price_range, bl.link,
+			bl.created_at, COALESCE(bl.image_urls, '[]'::jsonb) AS image_urls, bl.attributes,
+			bl.social_links, bl.website_phone, bl.website_description,
 			COALESCE(
 				jsonb_agg(
 					DISTINCT jsonb_build_object(
@@ -247,7 +454,12 @@ func baseSelectQuery() string {
 			) AS emails_info,
 			COALESCE(array_to_json(array_agg(DISTINCT e.email) FILTER (WHERE e.id IS NOT NULL)), '[]'::json) AS emails,
 			COUNT(DISTINCT e.id) FILTER (WHERE e.is_acceptable = true) AS valid_email_count,
-			COUNT(DISTINCT e.id) AS total_email_count
+			COUNT(DISTINCT e.id) AS total_email_count,
+			bl.is_new, bl.first_seen_job_id, bl.phone_e164, bl.opening_hours,
+			bl.data_id, bl.reviews_link, bl.plus_code, bl.timezone, bl.description,
+			bl.canonical_category,
+			bl.website_status, bl.website_http_status, bl.website_final_url, bl.website_checked_at,
+			bl.data_completeness, bl.reviews_per_rating, bl.bayesian_rating
 		FROM business_listings bl
 		LEFT JOIN business_emails be ON be.business_listing_id = bl.id
 		LEFT JOIN emails e ON e.id = be.email_id
@@ -279,10 +491,11 @@ func (r *BusinessListingRepository) List(ctx context.Context, filter domain.Busi
 
 	// Validate sort column
 	validSortColumns := map[string]string{
-		"created_at":    "bl.created_at",
-		"review_rating": "bl.review_rating",
-		"review_count":  "bl.review_count",
-		"title":         "bl.title",
+		"created_at":      "bl.created_at",
+		"review_rating":   "bl.review_rating",
+		"review_count":    "bl.review_count",
+		"bayesian_rating": "bl.bayesian_rating",
+		"title":           "bl.title",
 	}
 	sortColumn, ok := validSortColumns[filter.SortBy]
 	if !ok {
@@ -294,6 +507,13 @@ func (r *BusinessListingRepository) List(ctx context.Context, filter domain.Busi
 		sortOrder = "ASC"
 	}
 
+	if filter.Cursor != nil {
+		if sortColumn != "bl.created_at" {
+			return nil, 0, fmt.Errorf("cursor pagination sorts by created_at, not %s", filter.SortBy)
+		}
+		return r.listAfter(ctx, filter, fr, sortOrder)
+	}
+
 	// Count total - need to use subquery when HAVING is present
 	var countQuery string
 	if havingClause != "" {
@@ -327,9 +547,9 @@ func (r *BusinessListingRepository) List(ctx context.Context, filter domain.Busi
 	query := fmt.Sprintf(`%s %s
 		GROUP BY bl.id
 		%s
-		ORDER BY %s %s NULLS LAST
+		ORDER BY %s %s NULLS LAST, bl.id %s
 		LIMIT $%d OFFSET $%d
-	`, baseSelectQuery(), whereClause, havingClause, sortColumn, sortOrder, argNum, argNum+1)
+	`, baseSelectQuery(), whereClause, havingClause, sortColumn, sortOrder, sortOrder, argNum, argNum+1)
 
 	args = append(args, filter.PerPage, offset)
 
@@ -355,32 +575,19 @@ func (r *BusinessListingRepository) List(ctx context.Context, filter domain.Busi
 	return listings, total, nil
 }
 
-// ListByJobID retrieves business listings for a specific job with pagination
-func (r *BusinessListingRepository) ListByJobID(ctx context.Context, jobID string, limit, offset int) ([]*domain.BusinessListing, int, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 25
-	}
-	if offset < 0 {
-		offset = 0
-	}
-
-	// Count total
-	countQuery := `SELECT COUNT(*) FROM business_listings WHERE job_id = $1`
-	var total int
-	if err := r.db.QueryRowContext(ctx, countQuery, jobID).Scan(&total); err != nil {
-		return nil, 0, fmt.Errorf("count query failed: %w", err)
-	}
-
-	// Main query
-	query := fmt.Sprintf(`%s WHERE bl.job_id = $1
+// listAfter returns the page of listings after filter.Cursor in
+// (created_at, id) order. It does not count the listings; the total is 0.
+func (r *BusinessListingRepository) listAfter(ctx context.Context, filter domain.BusinessListingFilter, fr filterResult, sortOrder string) ([]*domain.BusinessListing, int, error) {
+	query := fmt.Sprintf(`%s %s
 		GROUP BY bl.id
-		ORDER BY bl.created_at DESC
-		LIMIT $2 OFFSET $3
-	`, baseSelectQuery())
+		%s
+		ORDER BY bl.created_at %s, bl.id %s
+		LIMIT $%d
+	`, baseSelectQuery(), fr.whereClause, fr.havingClause, sortOrder, sortOrder, fr.nextArgNum)
 
-	rows, err := r.db.QueryContext(ctx, query, jobID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, append(fr.args, filter.PerPage)...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("list by job id query failed: %w", err)
+		return nil, 0, fmt.Errorf("list query failed: %w", err)
 	}
 	defer rows.Close()
 
@@ -397,7 +604,19 @@ func (r *BusinessListingRepository) ListByJobID(ctx context.Context, jobID strin
 		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
 	}
 
-	return listings, total, nil
+	return listings, 0, nil
+}
+
+// ListByJobID retrieves business listings for a specific job, with the
+// filters, sorting and pagination of List
+func (r *BusinessListingRepository) ListByJobID(ctx context.Context, jobID string, filter domain.BusinessListingFilter) ([]*domain.BusinessListing, int, error) {
+	id, err := uuid.Parse(jobID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid job id %q: %w", jobID, err)
+	}
+	filter.JobID = &id
+
+	return r.List(ctx, filter)
 }
 
 // GetByID retrieves a single business listing by ID
@@ -455,6 +674,49 @@ func (r *BusinessListingRepository) GetCategories(ctx context.Context, limit int
 	return categories, nil
 }
 
+// GetCanonicalCategories returns the canonical categories with the
+// listings under them, most listed first
+func (r *BusinessListingRepository) GetCanonicalCategories(ctx context.Context, limit int) ([]domain.CategoryCount, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	query := `
+		SELECT canonical_category, COUNT(*) as cnt
+		FROM business_listings
+		WHERE canonical_category IS NOT NULL AND canonical_category != ''
+		GROUP BY canonical_category
+		ORDER BY cnt DESC, canonical_category
+		LIMIT $1
+	`
+
+	return queryCategoryCounts(ctx, r.db, query, limit)
+}
+
+// queryCategoryCounts runs a query selecting categories and their counts
+func queryCategoryCounts(ctx context.Context, db querier, query string, args ...any) ([]domain.CategoryCount, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get categories failed: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []domain.CategoryCount{}
+	for rows.Next() {
+		var c domain.CategoryCount
+		if err := rows.Scan(&c.Category, &c.Count); err != nil {
+			return nil, fmt.Errorf("scan category failed: %w", err)
+		}
+		categories = append(categories, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return categories, nil
+}
+
 // GetCities returns distinct cities
 func (r *BusinessListingRepository) GetCities(ctx context.Context, limit int) ([]string, error) {
 	if limit <= 0 || limit > 100 {
@@ -503,6 +765,9 @@ func (r *BusinessListingRepository) Stats(ctx context.Context) (*domain.Business
 			COUNT(DISTINCT e.id) FILTER (WHERE e.is_acceptable = true) as valid_emails,
 			AVG(bl.review_rating) FILTER (WHERE bl.review_rating IS NOT NULL) as avg_rating,
 			COUNT(*) FILTER (WHERE bl.phone IS NOT NULL AND bl.phone != '') as with_phone,
+			COUNT(*) FILTER (WHERE bl.phone_e164 IS NOT NULL) as valid_phones,
+			COUNT(*) FILTER (WHERE bl.phone_valid = false) as phone_parse_failures,
+			COUNT(*) FILTER (WHERE bl.hours_parsed = false) as hours_parse_failures,
 			COUNT(*) FILTER (WHERE bl.website IS NOT NULL AND bl.website != '') as with_website
 		FROM business_listings bl
 		LEFT JOIN business_emails be ON be.business_listing_id = bl.id
@@ -514,7 +779,7 @@ func (r *BusinessListingRepository) Stats(ctx context.Context) (*domain.Business
 
 	err := r.db.QueryRowContext(ctx, query).Scan(
 		&stats.TotalListings, &stats.TotalJobs, &stats.TotalEmails, &stats.ValidEmails,
-		&avgRating, &stats.WithPhone, &stats.WithWebsite,
+		&avgRating, &stats.WithPhone, &stats.ValidPhones, &stats.PhoneParseFailures, &stats.HoursParseFailures, &stats.WithWebsite,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("stats query failed: %w", err)
@@ -524,9 +789,38 @@ func (r *BusinessListingRepository) Stats(ctx context.Context) (*domain.Business
 		stats.AvgRating = &avgRating.Float64
 	}
 
+	if stats.ByBusinessStatus, err = r.countByBusinessStatus(ctx); err != nil {
+		return nil, err
+	}
+
 	return &stats, nil
 }
 
+// countByBusinessStatus counts the listings of every business status
+func (r *BusinessListingRepository) countByBusinessStatus(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int, len(domain.ValidBusinessStatuses))
+	for status := range domain.ValidBusinessStatuses {
+		counts[status] = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT business_status, COUNT(*) FROM business_listings GROUP BY business_status`)
+	if err != nil {
+		return nil, fmt.Errorf("business status stats query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("scan business status stats failed: %w", err)
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
 // Stream streams business listings for export (memory efficient)
 func (r *BusinessListingRepository) Stream(ctx context.Context, filter domain.BusinessListingFilter, fn func(listing *domain.BusinessListing) error) error {
 	// Build filter clauses
@@ -556,7 +850,7 @@ func (r *BusinessListingRepository) Stream(ctx context.Context, filter domain.Bu
 
 // StreamByJobID streams business listings for a specific job (memory efficient)
 func (r *BusinessListingRepository) StreamByJobID(ctx context.Context, jobID string, fn func(listing *domain.BusinessListing) error) error {
-	query := fmt.Sprintf(`%s WHERE bl.job_id = $1 GROUP BY bl.id ORDER BY bl.created_at DESC`, baseSelectQuery())
+	query := fmt.Sprintf(`%s WHERE %s GROUP BY bl.id ORDER BY bl.created_at DESC`, baseSelectQuery(), jobScope("bl.job_id", "$1"))
 
 	rows, err := r.db.QueryContext(ctx, query, jobID)
 	if err != nil {
@@ -577,9 +871,145 @@ func (r *BusinessListingRepository) StreamByJobID(ctx context.Context, jobID str
 	return rows.Err()
 }
 
+// sampleOversample is how many times n listings a block sample of the
+// whole table aims for, so that shuffling it still leaves n
+const sampleOversample = 4
+
+// Sample returns n random listings of a job, or of all jobs when jobID is
+// empty, with the quality of the listings sampled from. The listings of a
+// job are shuffled whole; across jobs a TABLESAMPLE of the table is
+// shuffled instead, as ORDER BY random() would read every listing.
+func (r *BusinessListingRepository) Sample(ctx context.Context, jobID string, n int) (*domain.ListingSample, error) {
+	var where string
+	var args []any
+	if jobID != "" {
+		id, err := uuid.Parse(jobID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid job id %q: %w", jobID, err)
+		}
+		where = "WHERE " + jobScope("bl.job_id", "$1")
+		args = append(args, id)
+	}
+
+	quality, err := r.quality(ctx, where, args)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := &domain.ListingSample{Listings: []*domain.BusinessListing{}, Quality: *quality}
+	if quality.Listings == 0 {
+		return sample, nil
+	}
+
+	ids, err := r.sampleIDs(ctx, where, args, n, quality.Listings)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`%s WHERE bl.id = ANY($1) GROUP BY bl.id`, baseSelectQuery())
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("sample query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		bl, err := r.scanListing(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		sample.Listings = append(sample.Listings, bl)
+	}
+
+	return sample, rows.Err()
+}
+
+// sampleIDs picks the IDs of n random listings among total ones matching
+// where
+func (r *BusinessListingRepository) sampleIDs(ctx context.Context, where string, args []any, n, total int) ([]int64, error) {
+	from := "business_listings bl"
+	if where == "" && total > n*sampleOversample {
+		percent := float64(n*sampleOversample) * 100 / float64(total)
+		from = fmt.Sprintf("business_listings bl TABLESAMPLE SYSTEM (%f)", percent)
+	}
+
+	query := fmt.Sprintf(`SELECT bl.id FROM %s %s ORDER BY random() LIMIT $%d`, from, where, len(args)+1)
+	rows, err := r.db.QueryContext(ctx, query, append(args, n)...)
+	if err != nil {
+		return nil, fmt.Errorf("sample ids query failed: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0, n)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan sample id failed: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Blocks are sampled whole, so a table of few, full pages may fall short
+	if len(ids) < n && from != "business_listings bl" {
+		return r.sampleIDs(ctx, where, args, n, 0)
+	}
+
+	return ids, nil
+}
+
+// quality measures the listings matching where in one pass
+func (r *BusinessListingRepository) quality(ctx context.Context, where string, args []any) (*domain.ListingQuality, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE bl.phone IS NOT NULL AND bl.phone != ''),
+			COUNT(*) FILTER (WHERE bl.website IS NOT NULL AND bl.website != ''),
+			COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM business_emails be WHERE be.business_listing_id = bl.id)),
+			COUNT(*) FILTER (WHERE bl.address_city IS NOT NULL AND bl.address_city != ''),
+			AVG(bl.review_rating),
+			COUNT(*) FILTER (WHERE bl.phone_valid = false OR bl.hours_parsed = false
+				OR (bl.address IS NOT NULL AND bl.address != '' AND COALESCE(bl.address_city, '') = ''))
+		FROM business_listings bl
+		%s
+	`, where)
+
+	var q domain.ListingQuality
+	var phone, website, email, city int
+	var avgRating sql.NullFloat64
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&q.Listings, &phone, &website, &email, &city, &avgRating, &q.ParseFailures,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("quality query failed: %w", err)
+	}
+
+	q.FillRates = map[string]float64{
+		"phone":   fillRate(phone, q.Listings),
+		"website": fillRate(website, q.Listings),
+		"email":   fillRate(email, q.Listings),
+		"city":    fillRate(city, q.Listings),
+	}
+	if avgRating.Valid {
+		q.AvgRating = &avgRating.Float64
+	}
+
+	return &q, nil
+}
+
+// fillRate is the percent of total that filled is, rounded to 0.1
+func fillRate(filled, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(filled)*1000/float64(total)) / 10
+}
+
 // CountByJobID counts business listings for a job
 func (r *BusinessListingRepository) CountByJobID(ctx context.Context, jobID string) (int, error) {
-	query := `SELECT COUNT(*) FROM business_listings WHERE job_id = $1`
+	query := `SELECT COUNT(*) FROM business_listings WHERE ` + jobScope("job_id", "$1")
 	var count int
 	if err := r.db.QueryRowContext(ctx, query, jobID).Scan(&count); err != nil {
 		return 0, fmt.Errorf("count by job id failed: %w", err)
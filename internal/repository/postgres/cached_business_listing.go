@@ -3,13 +3,14 @@ package postgres
 import (
 	"context"
 	"crypto/sha256"
-	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/sadewadee/google-scraper/internal/cache"
 	"github.com/sadewadee/google-scraper/internal/domain"
 )
@@ -19,7 +20,7 @@ import (
 type CachedBusinessListingRepository struct {
 	repo      *BusinessListingRepository
 	cache     cache.Cache
-	db        *sql.DB
+	db        querier
 	hasCache  bool // true if cache is a real implementation (not NoOpCache)
 }
 
@@ -29,6 +30,11 @@ const (
 	statsCacheTTL    = 120 * time.Second // Stats cache (more expensive, longer TTL)
 	listCacheTTL     = 30 * time.Second  // List results cache (shortest TTL)
 	categoryCacheTTL = 300 * time.Second // Categories/cities cache (rarely changes)
+	sampleCacheTTL   = 180 * time.Second // Samples and their quality (changes slowly)
+
+	// Field stats are keyed on the latest result, so the TTL only bounds
+	// how long enrichment (emails, website checks) goes unseen
+	fieldStatsCacheTTL = 600 * time.Second
 )
 
 // Cache key prefixes
@@ -37,13 +43,16 @@ const (
 	keyPrefixStats      = "bl:stats"
 	keyPrefixList       = "bl:list:"
 	keyPrefixCategories = "bl:categories:"
+	keyPrefixCanonical  = "bl:canonical:"
 	keyPrefixCities     = "bl:cities:"
 	keyPrefixJobCount   = "bl:jobcount:"
+	keyPrefixSample     = "bl:sample:"
+	keyPrefixFieldStats = "bl:fieldstats:"
 	keyTotalApprox      = "bl:total:approx"
 )
 
 // NewCachedBusinessListingRepository creates a new cached repository
-func NewCachedBusinessListingRepository(db *sql.DB, c cache.Cache) *CachedBusinessListingRepository {
+func NewCachedBusinessListingRepository(db querier, c cache.Cache) *CachedBusinessListingRepository {
 	// Check if this is a real cache implementation or a NoOpCache
 	_, isNoOp := c.(*cache.NoOpCache)
 	return &CachedBusinessListingRepository{
@@ -57,9 +66,11 @@ func NewCachedBusinessListingRepository(db *sql.DB, c cache.Cache) *CachedBusine
 // filterCacheKey generates a unique cache key based on filter parameters
 func filterCacheKey(filter domain.BusinessListingFilter) string {
 	// Create a deterministic representation of the filter
-	data := fmt.Sprintf("%v|%s|%s|%s|%s|%v|%v|%s",
+	data := fmt.Sprintf("%v|%s|%s|%s|%s|%v|%v|%s|%s|%t|%v|%s|%s|%s|%s|%s|%s|%s|%s|%s",
 		filter.JobID, filter.Search, filter.Category, filter.City, filter.Country,
-		filter.MinRating, filter.HasEmail, filter.EmailStatus)
+		filter.MinRating, filter.HasEmail, filter.EmailStatus, filter.Attribute, filter.OnlyNew,
+		filter.HasValidPhone, filter.State, filter.Postcode, filter.BBox.String(), filter.OpenOn, filter.JobTag, filter.WebsiteStatus,
+		filter.BusinessStatus, filter.Completeness, filter.Timezone)
 	hash := sha256.Sum256([]byte(data))
 	return hex.EncodeToString(hash[:8]) // Use first 8 bytes for shorter key
 }
@@ -80,6 +91,11 @@ func (r *CachedBusinessListingRepository) List(ctx context.Context, filter domai
 		filter.SortOrder = "desc"
 	}
 
+	// Cursor pages are not counted
+	if filter.Cursor != nil {
+		return r.repo.List(ctx, filter)
+	}
+
 	// Generate cache key for count
 	countKey := keyPrefixCount + filterCacheKey(filter)
 
@@ -136,13 +152,25 @@ func (r *CachedBusinessListingRepository) List(ctx context.Context, filter domai
 // isSimpleQuery checks if the filter has no search/filter conditions
 func (r *CachedBusinessListingRepository) isSimpleQuery(filter domain.BusinessListingFilter) bool {
 	return filter.JobID == nil &&
+		filter.JobTag == "" &&
 		filter.Search == "" &&
 		filter.Category == "" &&
 		filter.City == "" &&
 		filter.Country == "" &&
+		filter.State == "" &&
+		filter.Postcode == "" &&
 		filter.MinRating == nil &&
 		filter.HasEmail == nil &&
-		filter.EmailStatus == ""
+		filter.EmailStatus == "" &&
+		filter.Attribute == "" &&
+		!filter.OnlyNew &&
+		filter.HasValidPhone == nil &&
+		filter.BBox == nil &&
+		filter.OpenOn == "" &&
+		filter.WebsiteStatus == "" &&
+		filter.BusinessStatus == "" &&
+		filter.Completeness == "" &&
+		filter.Timezone == ""
 }
 
 // getApproximateCount uses PostgreSQL's pg_class.reltuples for fast count estimation
@@ -178,10 +206,20 @@ func (r *CachedBusinessListingRepository) getApproximateCount(ctx context.Contex
 	return count, nil
 }
 
-// ListByJobID retrieves business listings for a specific job with caching
-func (r *CachedBusinessListingRepository) ListByJobID(ctx context.Context, jobID string, limit, offset int) ([]*domain.BusinessListing, int, error) {
+// ListByJobID retrieves business listings for a specific job with caching.
+// The unfiltered count is shared with CountByJobID; a filtered one is kept
+// under the hash of the filter.
+func (r *CachedBusinessListingRepository) ListByJobID(ctx context.Context, jobID string, filter domain.BusinessListingFilter) ([]*domain.BusinessListing, int, error) {
+	if filter.Cursor != nil {
+		return r.repo.ListByJobID(ctx, jobID, filter)
+	}
+
 	// Try to get count from cache
+	filter.JobID = nil
 	countKey := keyPrefixJobCount + jobID
+	if !r.isSimpleQuery(filter) {
+		countKey += ":" + filterCacheKey(filter)
+	}
 	var total int
 	var countCached bool
 
@@ -193,7 +231,7 @@ func (r *CachedBusinessListingRepository) ListByJobID(ctx context.Context, jobID
 
 	if !countCached {
 		// Get exact count from repo
-		listings, exactTotal, err := r.repo.ListByJobID(ctx, jobID, limit, offset)
+		listings, exactTotal, err := r.repo.ListByJobID(ctx, jobID, filter)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -208,7 +246,7 @@ func (r *CachedBusinessListingRepository) ListByJobID(ctx context.Context, jobID
 	}
 
 	// Get listings with cached count
-	listings, _, err := r.repo.ListByJobID(ctx, jobID, limit, offset)
+	listings, _, err := r.repo.ListByJobID(ctx, jobID, filter)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -266,6 +304,29 @@ func (r *CachedBusinessListingRepository) GetCategories(ctx context.Context, lim
 	return categories, nil
 }
 
+// GetCanonicalCategories returns the canonical categories with caching
+func (r *CachedBusinessListingRepository) GetCanonicalCategories(ctx context.Context, limit int) ([]domain.CategoryCount, error) {
+	cacheKey := fmt.Sprintf("%s%d", keyPrefixCanonical, limit)
+
+	if cached, err := r.cache.Get(ctx, cacheKey); err == nil {
+		var categories []domain.CategoryCount
+		if err := json.Unmarshal(cached, &categories); err == nil {
+			return categories, nil
+		}
+	}
+
+	categories, err := r.repo.GetCanonicalCategories(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(categories); err == nil {
+		_ = r.cache.Set(ctx, cacheKey, data, categoryCacheTTL)
+	}
+
+	return categories, nil
+}
+
 // GetCities returns distinct cities with caching
 func (r *CachedBusinessListingRepository) GetCities(ctx context.Context, limit int) ([]string, error) {
 	cacheKey := fmt.Sprintf("%s%d", keyPrefixCities, limit)
@@ -333,6 +394,68 @@ func (r *CachedBusinessListingRepository) CountByJobID(ctx context.Context, jobI
 	return count, nil
 }
 
+// Sample returns a sample of listings with caching. A sample stays the same
+// for sampleCacheTTL, which also spares repeated quality aggregates.
+func (r *CachedBusinessListingRepository) Sample(ctx context.Context, jobID string, n int) (*domain.ListingSample, error) {
+	cacheKey := fmt.Sprintf("%s%s:%d", keyPrefixSample, jobID, n)
+
+	if cached, err := r.cache.Get(ctx, cacheKey); err == nil {
+		var sample domain.ListingSample
+		if err := json.Unmarshal(cached, &sample); err == nil {
+			return &sample, nil
+		}
+	}
+
+	sample, err := r.repo.Sample(ctx, jobID, n)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(sample); err == nil {
+		_ = r.cache.Set(ctx, cacheKey, data, sampleCacheTTL)
+	}
+
+	return sample, nil
+}
+
+// FieldStats returns the field stats of a job with caching, keyed on the
+// latest result of the job so that new results miss the cache
+func (r *CachedBusinessListingRepository) FieldStats(ctx context.Context, jobID string) (*domain.ListingFieldStats, error) {
+	id, err := uuid.Parse(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job id %q: %w", jobID, err)
+	}
+
+	latest, err := r.repo.latestResultAt(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var version int64
+	if latest != nil {
+		version = latest.UnixNano()
+	}
+	cacheKey := fmt.Sprintf("%s%s:%d", keyPrefixFieldStats, id, version)
+
+	if cached, err := r.cache.Get(ctx, cacheKey); err == nil {
+		var stats domain.ListingFieldStats
+		if err := json.Unmarshal(cached, &stats); err == nil {
+			return &stats, nil
+		}
+	}
+
+	stats, err := r.repo.FieldStats(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(stats); err == nil {
+		_ = r.cache.Set(ctx, cacheKey, data, fieldStatsCacheTTL)
+	}
+
+	return stats, nil
+}
+
 // InvalidateJobCache invalidates cache for a specific job
 // Call this when job results are updated
 func (r *CachedBusinessListingRepository) InvalidateJobCache(ctx context.Context, jobID string) error {
@@ -340,6 +463,10 @@ func (r *CachedBusinessListingRepository) InvalidateJobCache(ctx context.Context
 	if err := r.cache.Delete(ctx, countKey); err != nil {
 		log.Printf("[CachedBusinessListingRepo] Failed to invalidate job cache %s: %v", jobID, err)
 	}
+	// Filtered counts
+	if err := r.cache.DeleteByPattern(ctx, countKey+":*"); err != nil {
+		log.Printf("[CachedBusinessListingRepo] Failed to invalidate filtered job cache %s: %v", jobID, err)
+	}
 	return nil
 }
 
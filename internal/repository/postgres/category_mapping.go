@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// CategoryMappingRepository persists the canonical category taxonomy. The
+// canonical category of a listing is looked up by a trigger on ingestion.
+type CategoryMappingRepository struct {
+	db *sql.DB
+}
+
+// NewCategoryMappingRepository creates a new repository
+func NewCategoryMappingRepository(db *sql.DB) *CategoryMappingRepository {
+	return &CategoryMappingRepository{db: db}
+}
+
+// Add creates or replaces mappings and relabels the listings of their
+// aliases, returning the number of listings relabeled
+func (r *CategoryMappingRepository) Add(ctx context.Context, mappings []domain.CategoryMapping) (int64, error) {
+	aliases := make([]string, len(mappings))
+	canonicals := make([]string, len(mappings))
+	for i, m := range mappings {
+		aliases[i] = m.Alias
+		canonicals[i] = m.Canonical
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO category_mappings (alias, canonical)
+		SELECT * FROM unnest($1::text[], $2::text[])
+		ON CONFLICT (alias) DO UPDATE SET canonical = EXCLUDED.canonical, created_at = NOW()
+	`, pq.Array(aliases), pq.Array(canonicals))
+	if err != nil {
+		return 0, fmt.Errorf("add category mappings failed: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE business_listings bl
+		SET canonical_category = m.canonical
+		FROM category_mappings m
+		WHERE m.alias = ANY($1::text[])
+		  AND lower(bl.category) = m.alias
+		  AND bl.canonical_category IS DISTINCT FROM m.canonical
+	`, pq.Array(aliases))
+	if err != nil {
+		return 0, fmt.Errorf("relabel listings failed: %w", err)
+	}
+	relabeled, _ := res.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit category mappings: %w", err)
+	}
+
+	return relabeled, nil
+}
+
+// List returns the mappings ordered by canonical name and alias
+func (r *CategoryMappingRepository) List(ctx context.Context) ([]*domain.CategoryMapping, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT alias, canonical, created_at
+		FROM category_mappings
+		ORDER BY canonical, alias
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list category mappings failed: %w", err)
+	}
+	defer rows.Close()
+
+	mappings := []*domain.CategoryMapping{}
+	for rows.Next() {
+		var m domain.CategoryMapping
+		if err := rows.Scan(&m.Alias, &m.Canonical, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan category mapping failed: %w", err)
+		}
+		mappings = append(mappings, &m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return mappings, nil
+}
+
+// Unmapped returns the categories of listings no alias matches, most
+// listed first
+func (r *CategoryMappingRepository) Unmapped(ctx context.Context, limit int) ([]domain.CategoryCount, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	query := `
+		SELECT bl.category, COUNT(*) as cnt
+		FROM business_listings bl
+		WHERE bl.category IS NOT NULL AND bl.category != ''
+		  AND NOT EXISTS (SELECT 1 FROM category_mappings m WHERE m.alias = lower(bl.category))
+		GROUP BY bl.category
+		ORDER BY cnt DESC, bl.category
+		LIMIT $1
+	`
+
+	return queryCategoryCounts(ctx, r.db, query, limit)
+}
@@ -104,18 +104,22 @@ func sanitizeDSN(dsn string) (string, error) {
 
 // Repositories holds all repository instances
 type Repositories struct {
-	Jobs    *JobRepository
-	Workers *WorkerRepository
-	Results *ResultRepository
-	Proxies *ProxyRepository
+	Jobs      *JobRepository
+	Workers   *WorkerRepository
+	Results   *ResultRepository
+	Proxies   *ProxyRepository
+	APIKeys   *APIKeyRepository
+	Templates *JobTemplateRepository
 }
 
 // NewRepositories creates all repositories
 func NewRepositories(db *sql.DB) *Repositories {
 	return &Repositories{
-		Jobs:    NewJobRepository(db),
-		Workers: NewWorkerRepository(db),
-		Results: NewResultRepository(db),
-		Proxies: NewProxyRepository(db),
+		Jobs:      NewJobRepository(db),
+		Workers:   NewWorkerRepository(db),
+		Results:   NewResultRepository(db),
+		Proxies:   NewProxyRepository(db),
+		APIKeys:   NewAPIKeyRepository(db),
+		Templates: NewJobTemplateRepository(db),
 	}
 }
@@ -0,0 +1,254 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// diffCTE pairs the listings of job $1 (n, newer) with those of job $2
+// (o, compared against) by place ID, falling back to CID. A job holding a
+// place twice contributes its latest listing. The compared fields must
+// match domain.ListingDiff.Compare.
+const diffCTE = `
+	WITH o AS (
+		SELECT DISTINCT ON (key) * FROM (
+			SELECT COALESCE(place_id, 'cid:' || cid) AS key, id, title, category, address,
+			       COALESCE(phone_e164, phone) AS phone, website, status, review_count, review_rating,
+			       business_status, (
+			           SELECT MAX(c.changed_at) FROM listing_status_changes c
+			           WHERE c.listing_id = business_listings.id AND c.to_status = 'permanently_closed'
+			       ) AS closed_at
+			FROM business_listings
+			WHERE job_id IN (SELECT id FROM jobs_queue WHERE id = $2 OR parent_job_id = $2)
+		) s
+		WHERE key IS NOT NULL
+		ORDER BY key, id DESC
+	), n AS (
+		SELECT DISTINCT ON (key) * FROM (
+			SELECT COALESCE(place_id, 'cid:' || cid) AS key, id, title, category, address,
+			       COALESCE(phone_e164, phone) AS phone, website, status, review_count, review_rating,
+			       business_status, (
+			           SELECT MAX(c.changed_at) FROM listing_status_changes c
+			           WHERE c.listing_id = business_listings.id AND c.to_status = 'permanently_closed'
+			       ) AS closed_at
+			FROM business_listings
+			WHERE job_id IN (SELECT id FROM jobs_queue WHERE id = $1 OR parent_job_id = $1)
+		) s
+		WHERE key IS NOT NULL
+		ORDER BY key, id DESC
+	), d AS (
+		SELECT
+			COALESCE(n.key, o.key) AS key,
+			CASE
+				WHEN o.key IS NULL THEN 'added'
+				WHEN n.key IS NULL THEN 'removed'
+				WHEN (o.title, o.category, o.address, o.phone, o.website, o.status, COALESCE(o.review_count, 0), o.review_rating)
+				     IS DISTINCT FROM
+				     (n.title, n.category, n.address, n.phone, n.website, n.status, COALESCE(n.review_count, 0), n.review_rating)
+				THEN 'changed'
+				ELSE 'unchanged'
+			END AS change,
+			o.id AS o_id, o.title AS o_title, o.category AS o_category, o.address AS o_address,
+			o.phone AS o_phone, o.website AS o_website, o.status AS o_status,
+			o.review_count AS o_review_count, o.review_rating AS o_review_rating,
+			o.business_status AS o_business_status, o.closed_at AS o_closed_at,
+			n.id AS n_id, n.title AS n_title, n.category AS n_category, n.address AS n_address,
+			n.phone AS n_phone, n.website AS n_website, n.status AS n_status,
+			n.review_count AS n_review_count, n.review_rating AS n_review_rating,
+			n.business_status AS n_business_status, n.closed_at AS n_closed_at
+		FROM o FULL JOIN n ON o.key = n.key
+	)
+`
+
+// diffColumns are the columns of d scanned by scanDiff
+const diffColumns = `
+	key, change,
+	o_id, o_title, o_category, o_address, o_phone, o_website, o_status, o_review_count, o_review_rating,
+	o_business_status, o_closed_at,
+	n_id, n_title, n_category, n_address, n_phone, n_website, n_status, n_review_count, n_review_rating,
+	n_business_status, n_closed_at
+`
+
+// diffOrder sorts added places first, then removed, changed and unchanged
+const diffOrder = `
+	ORDER BY array_position(ARRAY['added', 'removed', 'changed', 'unchanged'], change),
+	         COALESCE(n_title, o_title), key
+`
+
+// ListingDiffRepository compares the business listings of two jobs
+type ListingDiffRepository struct {
+	db *sql.DB
+}
+
+// NewListingDiffRepository creates a new repository
+func NewListingDiffRepository(db *sql.DB) *ListingDiffRepository {
+	return &ListingDiffRepository{db: db}
+}
+
+// Diff returns the places of a diff with pagination
+func (r *ListingDiffRepository) Diff(ctx context.Context, filter domain.ListingDiffFilter) ([]*domain.ListingDiff, int, error) {
+	query := diffCTE + `SELECT ` + diffColumns + `, COUNT(*) OVER () FROM d WHERE change = ANY($3)` + diffOrder + `LIMIT $4 OFFSET $5`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.JobID, filter.AgainstID, pq.Array(filter.Changes),
+		filter.PerPage, (filter.Page-1)*filter.PerPage)
+	if err != nil {
+		return nil, 0, fmt.Errorf("diff listings: %w", err)
+	}
+	defer rows.Close()
+
+	diffs := make([]*domain.ListingDiff, 0)
+	total := 0
+	for rows.Next() {
+		diff, err := scanDiff(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, total, rows.Err()
+}
+
+// Stream calls fn for every place of a diff
+func (r *ListingDiffRepository) Stream(ctx context.Context, filter domain.ListingDiffFilter, fn func(diff *domain.ListingDiff) error) error {
+	query := diffCTE + `SELECT ` + diffColumns + ` FROM d WHERE change = ANY($3)` + diffOrder
+
+	rows, err := r.db.QueryContext(ctx, query, filter.JobID, filter.AgainstID, pq.Array(filter.Changes))
+	if err != nil {
+		return fmt.Errorf("diff listings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		diff, err := scanDiff(rows, nil)
+		if err != nil {
+			return err
+		}
+		if err := fn(diff); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Summarize counts every place of the diff of jobID against againstID
+func (r *ListingDiffRepository) Summarize(ctx context.Context, jobID, againstID uuid.UUID) (*domain.ListingDiffSummary, error) {
+	// The closed count follows domain.ListingDiff.NewlyClosed
+	query := diffCTE + `
+		SELECT
+			COUNT(*) FILTER (WHERE change = 'added'),
+			COUNT(*) FILTER (WHERE change = 'removed'),
+			COUNT(*) FILTER (WHERE change = 'changed'),
+			COUNT(*) FILTER (WHERE change = 'unchanged'),
+			COUNT(*) FILTER (WHERE n_business_status = 'permanently_closed' AND CASE
+				WHEN o_id IS NOT NULL THEN o_business_status <> 'permanently_closed'
+				ELSE n_closed_at IS NOT NULL
+			END),
+			COUNT(*) FILTER (WHERE change = 'changed' AND o_review_rating IS DISTINCT FROM n_review_rating),
+			COUNT(*) FILTER (WHERE change = 'changed' AND COALESCE(o_review_count, 0) <> COALESCE(n_review_count, 0)),
+			COUNT(*) FILTER (WHERE change = 'changed' AND o_phone IS DISTINCT FROM n_phone),
+			COUNT(*) FILTER (WHERE change = 'changed' AND o_website IS DISTINCT FROM n_website)
+		FROM d
+	`
+
+	var s domain.ListingDiffSummary
+	err := r.db.QueryRowContext(ctx, query, jobID, againstID).Scan(
+		&s.Added, &s.Removed, &s.Changed, &s.Unchanged, &s.Closed,
+		&s.RatingChanged, &s.ReviewCountChanged, &s.PhoneChanged, &s.WebsiteChanged,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("summarize listing diff: %w", err)
+	}
+
+	return &s, nil
+}
+
+// diffSide holds the nullable columns of one side of a diff row
+type diffSide struct {
+	id           sql.NullInt64
+	title        sql.NullString
+	category     sql.NullString
+	address      sql.NullString
+	phone        sql.NullString
+	website      sql.NullString
+	status       sql.NullString
+	reviewCount  sql.NullInt64
+	reviewRating sql.NullFloat64
+
+	businessStatus sql.NullString
+	closedAt       sql.NullTime
+}
+
+func (s *diffSide) dest() []any {
+	return []any{&s.id, &s.title, &s.category, &s.address, &s.phone, &s.website,
+		&s.status, &s.reviewCount, &s.reviewRating, &s.businessStatus, &s.closedAt}
+}
+
+// snapshot returns nil for the missing side of an added or removed place
+func (s *diffSide) snapshot() *domain.ListingSnapshot {
+	if !s.id.Valid {
+		return nil
+	}
+
+	snap := &domain.ListingSnapshot{
+		ListingID:   s.id.Int64,
+		Title:       s.title.String,
+		Category:    nullStringPtr(s.category),
+		Address:     nullStringPtr(s.address),
+		Phone:       nullStringPtr(s.phone),
+		Website:     nullStringPtr(s.website),
+		Status:      nullStringPtr(s.status),
+		ReviewCount: int(s.reviewCount.Int64),
+
+		BusinessStatus: s.businessStatus.String,
+	}
+	if s.reviewRating.Valid {
+		snap.ReviewRating = &s.reviewRating.Float64
+	}
+	if s.closedAt.Valid {
+		snap.ClosedAt = &s.closedAt.Time
+	}
+
+	return snap
+}
+
+// scanDiff scans a row of diffColumns, followed by the total count when
+// total is not nil
+func scanDiff(rows *sql.Rows, total *int) (*domain.ListingDiff, error) {
+	var (
+		diff   domain.ListingDiff
+		before diffSide
+		after  diffSide
+	)
+
+	dest := []any{&diff.Key, &diff.Change}
+	dest = append(dest, before.dest()...)
+	dest = append(dest, after.dest()...)
+	if total != nil {
+		dest = append(dest, total)
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return nil, fmt.Errorf("scan listing diff: %w", err)
+	}
+
+	diff.Before = before.snapshot()
+	diff.After = after.snapshot()
+	diff.Compare()
+
+	return &diff, nil
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
@@ -0,0 +1,265 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+const (
+	// proximityMaxMeters is how close two listings must be to count as
+	// the same place
+	proximityMaxMeters = 100
+
+	// proximityMinSimilarity is the pg_trgm similarity two titles need
+	proximityMinSimilarity = 0.6
+
+	// metersPerDegreeLat converts proximityMaxMeters to a latitude window
+	metersPerDegreeLat = 111320
+)
+
+// sharedWebsiteHosts are hosts many unrelated businesses use as their
+// website. Grouping by them would only produce noise.
+var sharedWebsiteHosts = []string{
+	"facebook.com", "instagram.com", "linktr.ee", "wa.me", "api.whatsapp.com",
+	"twitter.com", "x.com", "tiktok.com", "youtube.com", "linkedin.com",
+	"sites.google.com", "google.com", "goo.gl", "bit.ly",
+}
+
+// DuplicateRepository finds and merges duplicate business listings
+type DuplicateRepository struct {
+	db *sql.DB
+}
+
+// NewDuplicateRepository creates a new repository
+func NewDuplicateRepository(db *sql.DB) *DuplicateRepository {
+	return &DuplicateRepository{db: db}
+}
+
+// clusterQueries builds one SELECT per criterion, each returning
+// (criterion, match_key, ids). Proximity clusters are anchored on the lowest
+// listing ID of a chain of matching pairs, so a listing close to two
+// anchors can show up in two clusters.
+func clusterQueries(criteria []string, minSize int) (with string, selects []string, args []interface{}) {
+	args = append(args, minSize)
+
+	for _, criterion := range criteria {
+		switch criterion {
+		case domain.DuplicateByPhone:
+			selects = append(selects, `
+				SELECT 'phone' AS criterion, digits AS match_key, array_agg(id ORDER BY id) AS ids
+				FROM (
+					SELECT id, regexp_replace(phone, '\D', '', 'g') AS digits
+					FROM business_listings
+					WHERE phone IS NOT NULL AND phone <> ''
+				) p
+				WHERE length(digits) >= 7
+				GROUP BY digits
+				HAVING COUNT(*) >= $1`)
+
+		case domain.DuplicateByWebsite:
+			args = append(args, pq.Array(sharedWebsiteHosts))
+			selects = append(selects, fmt.Sprintf(`
+				SELECT 'website' AS criterion, host AS match_key, array_agg(id ORDER BY id) AS ids
+				FROM (
+					SELECT id, regexp_replace(
+						regexp_replace(lower(website), '^[a-z][a-z0-9+.-]*://', ''),
+						'^www\.|[/:?#].*$', '', 'g'
+					) AS host
+					FROM business_listings
+					WHERE website IS NOT NULL AND website <> ''
+				) w
+				WHERE host <> '' AND host <> ALL($%d)
+				GROUP BY host
+				HAVING COUNT(*) >= $1`, len(args)))
+
+		case domain.DuplicateByProximity:
+			args = append(args, float64(proximityMaxMeters)/metersPerDegreeLat, proximityMinSimilarity, proximityMaxMeters)
+			latDelta, similarity, meters := len(args)-2, len(args)-1, len(args)
+
+			// The bounding box lets the location index narrow the self join
+			// before the distance and similarity checks
+			with = fmt.Sprintf(`
+				pairs AS (
+					SELECT a.id AS a_id, b.id AS b_id
+					FROM business_listings a
+					JOIN business_listings b ON b.id > a.id
+						AND b.latitude BETWEEN a.latitude - $%[1]d AND a.latitude + $%[1]d
+						AND b.longitude BETWEEN a.longitude - $%[1]d / GREATEST(cos(radians(a.latitude)), 0.01)
+							AND a.longitude + $%[1]d / GREATEST(cos(radians(a.latitude)), 0.01)
+					WHERE a.latitude IS NOT NULL AND a.longitude IS NOT NULL
+						AND similarity(a.title, b.title) >= $%[2]d
+						AND 6371000 * sqrt(
+							power(radians(b.latitude - a.latitude), 2) +
+							power(radians(b.longitude - a.longitude) * cos(radians((a.latitude + b.latitude) / 2)), 2)
+						) < $%[3]d
+				),`, latDelta, similarity, meters)
+
+			selects = append(selects, `
+				SELECT 'title_proximity' AS criterion, bl.title AS match_key, ARRAY[p.a_id] || array_agg(p.b_id ORDER BY p.b_id) AS ids
+				FROM pairs p
+				JOIN business_listings bl ON bl.id = p.a_id
+				WHERE NOT EXISTS (SELECT 1 FROM pairs q WHERE q.b_id = p.a_id)
+				GROUP BY p.a_id, bl.title
+				HAVING COUNT(*) + 1 >= $1`)
+		}
+	}
+
+	return with, selects, args
+}
+
+// FindClusters returns duplicate clusters, largest first
+func (r *DuplicateRepository) FindClusters(ctx context.Context, filter domain.DuplicateFilter) ([]*domain.DuplicateCluster, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PerPage < 1 || filter.PerPage > 100 {
+		filter.PerPage = 25
+	}
+	if filter.MinClusterSize < 2 {
+		filter.MinClusterSize = 2
+	}
+
+	criteria := domain.DuplicateCriteria
+	if filter.Criterion != "" {
+		criteria = []string{filter.Criterion}
+	}
+
+	with, selects, args := clusterQueries(criteria, filter.MinClusterSize)
+	if len(selects) == 0 {
+		return nil, 0, fmt.Errorf("unknown duplicate criterion: %s", filter.Criterion)
+	}
+
+	query := fmt.Sprintf(`
+		WITH %s
+		clusters AS (%s
+		)
+		SELECT criterion, match_key, array_to_json(ids), COUNT(*) OVER () AS total
+		FROM clusters
+		ORDER BY cardinality(ids) DESC, criterion, match_key
+		LIMIT $%d OFFSET $%d
+	`, with, strings.Join(selects, "\n\t\t\t\tUNION ALL"), len(args)+1, len(args)+2)
+
+	args = append(args, filter.PerPage, (filter.Page-1)*filter.PerPage)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("find duplicates failed: %w", err)
+	}
+	defer rows.Close()
+
+	var total int
+	clusters := make([]*domain.DuplicateCluster, 0, filter.PerPage)
+	for rows.Next() {
+		var c domain.DuplicateCluster
+		var ids []byte
+
+		if err := rows.Scan(&c.Criterion, &c.Key, &ids, &total); err != nil {
+			return nil, 0, fmt.Errorf("scan failed: %w", err)
+		}
+		if err := json.Unmarshal(ids, &c.ListingIDs); err != nil {
+			return nil, 0, fmt.Errorf("unmarshal listing ids: %w", err)
+		}
+
+		c.Size = len(c.ListingIDs)
+		clusters = append(clusters, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	// COUNT(*) OVER () is only known when the page has rows
+	if len(clusters) == 0 && filter.Page > 1 {
+		countQuery := fmt.Sprintf(`WITH %s clusters AS (%s) SELECT COUNT(*) FROM clusters`,
+			with, strings.Join(selects, " UNION ALL"))
+		if err := r.db.QueryRowContext(ctx, countQuery, args[:len(args)-2]...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("count duplicates failed: %w", err)
+		}
+	}
+
+	return clusters, total, nil
+}
+
+// Merge folds mergedIDs into survivorID in a single transaction and records
+// the merge, including a snapshot of the deleted rows, in
+// business_listing_merges.
+func (r *DuplicateRepository) Merge(ctx context.Context, survivorID int64, mergedIDs []int64, criterion string) (*domain.ListingMerge, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	allIDs := append([]int64{survivorID}, mergedIDs...)
+
+	var found int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT id FROM business_listings WHERE id = ANY($1) ORDER BY id FOR UPDATE
+		) locked
+	`, pq.Array(allIDs)).Scan(&found)
+	if err != nil {
+		return nil, fmt.Errorf("lock listings: %w", err)
+	}
+	if found != len(allIDs) {
+		return nil, nil
+	}
+
+	// Union of emails: copy before the cascade on delete removes them
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO business_emails (business_listing_id, email_id, source, position)
+		SELECT $1, email_id, source, position
+		FROM business_emails
+		WHERE business_listing_id = ANY($2)
+		ON CONFLICT (business_listing_id, email_id) DO NOTHING
+	`, survivorID, pq.Array(mergedIDs))
+	if err != nil {
+		return nil, fmt.Errorf("merge emails: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE business_listings
+		SET review_count = (
+				SELECT MAX(COALESCE(review_count, 0)) FROM business_listings WHERE id = ANY($2)
+			),
+			updated_at = NOW()
+		WHERE id = $1
+	`, survivorID, pq.Array(allIDs))
+	if err != nil {
+		return nil, fmt.Errorf("update survivor: %w", err)
+	}
+
+	merge := &domain.ListingMerge{
+		SurvivorID: survivorID,
+		MergedIDs:  mergedIDs,
+		Criterion:  criterion,
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		WITH removed AS (
+			DELETE FROM business_listings WHERE id = ANY($2) RETURNING *
+		)
+		INSERT INTO business_listing_merges (survivor_id, merged_ids, criterion, merged_listings)
+		SELECT $1, $2, NULLIF($3, ''), COALESCE(jsonb_agg(to_jsonb(removed)), '[]'::jsonb)
+		FROM removed
+		RETURNING id, merged_at
+	`, survivorID, pq.Array(mergedIDs), criterion).Scan(&merge.ID, &merge.MergedAt)
+	if err != nil {
+		return nil, fmt.Errorf("delete merged listings: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit merge: %w", err)
+	}
+
+	return merge, nil
+}
+
+var _ domain.DuplicateRepository = (*DuplicateRepository)(nil)
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/emailvalidator"
+)
+
+// EmailValidationRepository stores API validation results on the emails
+// table and serves them as the validation cache
+type EmailValidationRepository struct {
+	db *sql.DB
+}
+
+// NewEmailValidationRepository creates a new EmailValidationRepository
+func NewEmailValidationRepository(db *sql.DB) *EmailValidationRepository {
+	return &EmailValidationRepository{db: db}
+}
+
+// ClaimDue marks up to limit due emails as queued and returns them, the
+// most frequent first
+func (r *EmailValidationRepository) ClaimDue(ctx context.Context, limit int, ttl, claimTimeout time.Duration) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE emails SET validation_queued_at = NOW()
+		WHERE id IN (
+			SELECT id FROM emails
+			WHERE (validation_queued_at IS NULL OR validation_queued_at < NOW() - $3 * INTERVAL '1 second')
+				AND (
+					validation_status IN ('pending', 'local_valid', 'api_error')
+					OR (validation_status IN ('api_valid', 'api_invalid')
+						AND api_validated_at < NOW() - $2 * INTERVAL '1 second'
+						AND last_seen_at > api_validated_at)
+				)
+			ORDER BY occurrence_count DESC, first_seen_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING email
+	`, limit, ttl.Seconds(), claimTimeout.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim emails for validation: %w", err)
+	}
+	defer rows.Close()
+
+	var emails []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		emails = append(emails, email)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return emails, nil
+}
+
+// MarkError records that validating email failed. The claim is kept, so
+// the email is retried once it expires.
+func (r *EmailValidationRepository) MarkError(ctx context.Context, email, reason string) error {
+	if _, err := r.db.ExecContext(ctx, `SELECT mark_email_validation_error($1, $2)`, email, reason); err != nil {
+		return fmt.Errorf("mark email validation error: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the API result stored for email if it is younger than maxAge
+func (r *EmailValidationRepository) Lookup(ctx context.Context, email string, maxAge time.Duration) (*emailvalidator.ValidationResult, error) {
+	var (
+		res         = emailvalidator.ValidationResult{Email: email}
+		score       sql.NullFloat64
+		deliverable sql.NullBool
+		disposable  sql.NullBool
+		roleAccount sql.NullBool
+		freeEmail   sql.NullBool
+		catchAll    sql.NullBool
+	)
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(api_status, ''), api_score, api_deliverable, api_disposable,
+			api_role_account, api_free_email, api_catch_all, COALESCE(api_reason, '')
+		FROM emails
+		WHERE email = $1
+			AND validation_status IN ('api_valid', 'api_invalid')
+			AND api_validated_at > NOW() - $2 * INTERVAL '1 second'
+	`, email, maxAge.Seconds()).Scan(&res.Status, &score, &deliverable, &disposable,
+		&roleAccount, &freeEmail, &catchAll, &res.Reason)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lookup email validation: %w", err)
+	}
+
+	res.Score = score.Float64
+	res.Deliverable = deliverable.Bool
+	res.Disposable = disposable.Bool
+	res.RoleAccount = roleAccount.Bool
+	res.FreeEmail = freeEmail.Bool
+	res.CatchAll = catchAll.Bool
+
+	return &res, nil
+}
+
+// Save stores an API result and ends the email's claim
+func (r *EmailValidationRepository) Save(ctx context.Context, res *emailvalidator.ValidationResult) error {
+	_, err := r.db.ExecContext(ctx, `SELECT update_email_validation($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		res.Email, res.Status, res.Score, res.Deliverable, res.Disposable,
+		res.RoleAccount, res.FreeEmail, res.CatchAll, res.Reason)
+	if err != nil {
+		return fmt.Errorf("save email validation: %w", err)
+	}
+	return nil
+}
+
+// Stats counts the emails by validation state
+func (r *EmailValidationRepository) Stats(ctx context.Context) (*domain.EmailValidationStats, error) {
+	var s domain.EmailValidationStats
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE validation_status IN ('pending', 'local_valid')),
+			COUNT(*) FILTER (WHERE validation_status IN ('pending', 'local_valid') AND validation_queued_at IS NOT NULL),
+			COUNT(*) FILTER (WHERE validation_status = 'api_valid'),
+			COUNT(*) FILTER (WHERE validation_status = 'api_invalid'),
+			COUNT(*) FILTER (WHERE validation_status = 'api_error')
+		FROM emails
+	`).Scan(&s.Total, &s.Pending, &s.Queued, &s.Valid, &s.Invalid, &s.Errors)
+	if err != nil {
+		return nil, fmt.Errorf("count emails failed: %w", err)
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE api_validated_at > NOW() - INTERVAL '1 minute'),
+			COUNT(*)
+		FROM emails
+		WHERE api_validated_at > NOW() - INTERVAL '1 hour'
+			AND validation_status IN ('api_valid', 'api_invalid')
+	`).Scan(&s.ValidatedLastMinute, &s.ValidatedLastHour)
+	if err != nil {
+		return nil, fmt.Errorf("count validated emails failed: %w", err)
+	}
+
+	s.PerMinute = float64(s.ValidatedLastHour) / 60
+
+	return &s, nil
+}
+
+var (
+	_ domain.EmailValidationRepository = (*EmailValidationRepository)(nil)
+	_ emailvalidator.Store             = (*EmailValidationRepository)(nil)
+)
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// ExportProfileRepository implements domain.ExportProfileRepository for
+// PostgreSQL
+type ExportProfileRepository struct {
+	db *sql.DB
+}
+
+// NewExportProfileRepository creates a new ExportProfileRepository
+func NewExportProfileRepository(db *sql.DB) *ExportProfileRepository {
+	return &ExportProfileRepository{db: db}
+}
+
+// Save creates a profile or replaces the one of the same name
+func (r *ExportProfileRepository) Save(ctx context.Context, profile *domain.ExportProfile) error {
+	columns, err := json.Marshal(profile.Columns)
+	if err != nil {
+		return fmt.Errorf("failed to marshal columns: %w", err)
+	}
+
+	query := `
+		INSERT INTO export_profiles (name, columns, mask_emails, truncate_coords)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO UPDATE SET
+			columns = EXCLUDED.columns,
+			mask_emails = EXCLUDED.mask_emails,
+			truncate_coords = EXCLUDED.truncate_coords,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		profile.Name, columns, profile.MaskEmails, profile.TruncateCoords,
+	).Scan(&profile.CreatedAt, &profile.UpdatedAt)
+}
+
+// Get retrieves a profile by name, nil when there is none
+func (r *ExportProfileRepository) Get(ctx context.Context, name string) (*domain.ExportProfile, error) {
+	query := `
+		SELECT name, columns, mask_emails, truncate_coords, created_at, updated_at
+		FROM export_profiles
+		WHERE name = $1
+	`
+
+	profile, err := scanExportProfile(r.db.QueryRowContext(ctx, query, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return profile, err
+}
+
+// List returns every profile ordered by name
+func (r *ExportProfileRepository) List(ctx context.Context) ([]*domain.ExportProfile, error) {
+	query := `
+		SELECT name, columns, mask_emails, truncate_coords, created_at, updated_at
+		FROM export_profiles
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []*domain.ExportProfile
+	for rows.Next() {
+		profile, err := scanExportProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+// Delete deletes a profile unless API keys are assigned to it, reporting
+// whether it existed
+func (r *ExportProfileRepository) Delete(ctx context.Context, name string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM export_profiles p
+		WHERE p.name = $1
+		  AND NOT EXISTS (SELECT 1 FROM api_keys k WHERE k.export_profile = p.name)
+	`, name)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return true, nil
+	}
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM export_profiles WHERE name = $1)`, name).Scan(&exists); err != nil {
+		return false, err
+	}
+	if exists {
+		return true, domain.ErrExportProfileInUse
+	}
+
+	return false, nil
+}
+
+func scanExportProfile(row rowScanner) (*domain.ExportProfile, error) {
+	profile := &domain.ExportProfile{}
+	var columns []byte
+
+	if err := row.Scan(
+		&profile.Name, &columns, &profile.MaskEmails, &profile.TruncateCoords,
+		&profile.CreatedAt, &profile.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(columns, &profile.Columns); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal columns: %w", err)
+	}
+
+	return profile, nil
+}
+
+// Verify interface compliance at compile time
+var _ domain.ExportProfileRepository = (*ExportProfileRepository)(nil)
@@ -0,0 +1,202 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// fieldStatsColumn is an export column as text, NULL or empty when the
+// column exports empty
+type fieldStatsColumn struct {
+	key  string
+	expr string
+}
+
+// openDayExpr is y or n for a day of the parsed opening hours, as exported
+func openDayExpr(day string) string {
+	return fmt.Sprintf(`CASE
+		WHEN bl.opening_hours -> 'days' -> '%[1]s' IS NULL THEN NULL
+		WHEN COALESCE((bl.opening_hours -> 'days' -> '%[1]s' ->> 'closed')::boolean, false) THEN 'n'
+		ELSE 'y' END`, day)
+}
+
+// fieldStatsColumns are the listing export columns, in export order. A test
+// keeps them in step with exportschema.
+var fieldStatsColumns = []fieldStatsColumn{
+	{"title", "bl.title"},
+	{"category", "bl.category"},
+	{"categories", "array_to_string(bl.categories, ', ')"},
+	{"address", "bl.address"},
+	{"phone", "bl.phone"},
+	{"phone_e164", "bl.phone_e164"},
+	{"website", "bl.website"},
+	{"email", "em.emails"},
+	{"latitude", "bl.latitude::text"},
+	{"longitude", "bl.longitude::text"},
+	{"street", "bl.address_street"},
+	{"house_number", "bl.address_number"},
+	{"postcode", "bl.address_postal_code"},
+	{"city", "bl.address_city"},
+	{"state", "bl.address_state"},
+	{"country", "bl.address_country"},
+	{"plus_code", "bl.plus_code"},
+	{"timezone", "bl.timezone"},
+	{"review_count", "bl.review_count::text"},
+	{"review_rating", "bl.review_rating::text"},
+	{"reviews_per_rating", "CASE WHEN jsonb_typeof(bl.reviews_per_rating) = 'object' THEN bl.reviews_per_rating::text END"},
+	{"bayesian_rating", "bl.bayesian_rating::text"},
+	{"status", "bl.status"},
+	{"price_range", "bl.price_range"},
+	{"description", "bl.description"},
+	{"link", "bl.link"},
+	{"reviews_link", "bl.reviews_link"},
+	{"place_id", "bl.place_id"},
+	{"cid", "bl.cid"},
+	{"data_id", "bl.data_id"},
+	{"image_urls", "CASE WHEN jsonb_typeof(bl.image_urls) = 'array' AND bl.image_urls != '[]' THEN bl.image_urls::text END"},
+	{"attributes", "CASE WHEN jsonb_typeof(bl.attributes) = 'object' AND bl.attributes != '{}' THEN bl.attributes::text END"},
+	{"facebook", "bl.social_links ->> 'facebook'"},
+	{"instagram", "bl.social_links ->> 'instagram'"},
+	{"linkedin", "bl.social_links ->> 'linkedin'"},
+	{"whatsapp", "bl.social_links ->> 'whatsapp'"},
+	{"twitter", "bl.social_links ->> 'twitter'"},
+	{"youtube", "bl.social_links ->> 'youtube'"},
+	{"tiktok", "bl.social_links ->> 'tiktok'"},
+	{"website_phone", "bl.website_phone"},
+	{"website_description", "bl.website_description"},
+	{"is_new", "bl.is_new::text"},
+	{"first_seen_job_id", "bl.first_seen_job_id::text"},
+	{"business_status", "bl.business_status"},
+	{"website_status", "bl.website_status"},
+	{"website_final_url", "bl.website_final_url"},
+	{"data_completeness", "bl.data_completeness"},
+	{"open_monday", openDayExpr("monday")},
+	{"open_tuesday", openDayExpr("tuesday")},
+	{"open_wednesday", openDayExpr("wednesday")},
+	{"open_thursday", openDayExpr("thursday")},
+	{"open_friday", openDayExpr("friday")},
+	{"open_saturday", openDayExpr("saturday")},
+	{"open_sunday", openDayExpr("sunday")},
+}
+
+// fieldStatsNumeric are the columns with min, avg and max, by key
+var fieldStatsNumeric = map[string]string{
+	"review_rating":   "bl.review_rating",
+	"review_count":    "bl.review_count",
+	"bayesian_rating": "bl.bayesian_rating",
+}
+
+// fieldStatsQuery aggregates every column of the listings of a job in one
+// pass: the listing count, the coverage, then filled and distinct counts
+// per column and the min, avg and max of the numeric ones
+func fieldStatsQuery() string {
+	var sb strings.Builder
+	sb.WriteString(`
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE em.emails IS NOT NULL),
+			COUNT(*) FILTER (WHERE em.valid),
+			COUNT(*) FILTER (WHERE NULLIF(bl.phone, '') IS NOT NULL),
+			COUNT(*) FILTER (WHERE NULLIF(bl.phone_e164, '') IS NOT NULL),
+			COUNT(*) FILTER (WHERE NULLIF(bl.website, '') IS NOT NULL)`)
+
+	for _, c := range fieldStatsColumns {
+		fmt.Fprintf(&sb, ",\n\t\t\tCOUNT(*) FILTER (WHERE NULLIF(%[1]s, '') IS NOT NULL), COUNT(DISTINCT NULLIF(%[1]s, ''))", c.expr)
+		if col, ok := fieldStatsNumeric[c.key]; ok {
+			fmt.Fprintf(&sb, ",\n\t\t\tMIN(%[1]s)::float8, AVG(%[1]s)::float8, MAX(%[1]s)::float8", col)
+		}
+	}
+
+	sb.WriteString(`
+		FROM business_listings bl
+		LEFT JOIN LATERAL (
+			SELECT string_agg(e.email, ', ' ORDER BY e.email) AS emails, bool_or(e.is_acceptable) AS valid
+			FROM business_emails be
+			JOIN emails e ON e.id = be.email_id
+			WHERE be.business_listing_id = bl.id
+		) em ON true
+		WHERE ` + jobScope("bl.job_id", "$1"))
+
+	return sb.String()
+}
+
+// latestResultAt is when the newest result of a job was normalized, nil
+// while it has none
+func (r *BusinessListingRepository) latestResultAt(ctx context.Context, jobID uuid.UUID) (*time.Time, error) {
+	var latest sql.NullTime
+	err := r.db.QueryRowContext(ctx,
+		`SELECT MAX(normalized_at) FROM results WHERE `+jobScope("job_id", "$1"), jobID,
+	).Scan(&latest)
+	if err != nil {
+		return nil, fmt.Errorf("latest result query failed: %w", err)
+	}
+	if !latest.Valid {
+		return nil, nil
+	}
+	return &latest.Time, nil
+}
+
+// FieldStats describes how the listings of a job fill each export column
+func (r *BusinessListingRepository) FieldStats(ctx context.Context, jobID string) (*domain.ListingFieldStats, error) {
+	id, err := uuid.Parse(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job id %q: %w", jobID, err)
+	}
+
+	latest, err := r.latestResultAt(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.ListingFieldStats{
+		JobID:          id,
+		LatestResultAt: latest,
+		Columns:        make([]domain.ColumnStats, len(fieldStatsColumns)),
+	}
+
+	cov := &stats.Coverage
+	dest := []any{&stats.Listings, &cov.WithEmail, &cov.WithValidEmail, &cov.WithPhone, &cov.WithValidPhone, &cov.WithWebsite}
+	numeric := make(map[int][3]*sql.NullFloat64)
+	for i, c := range fieldStatsColumns {
+		stats.Columns[i].Column = c.key
+		dest = append(dest, &stats.Columns[i].Filled, &stats.Columns[i].Distinct)
+		if _, ok := fieldStatsNumeric[c.key]; ok {
+			agg := [3]*sql.NullFloat64{{}, {}, {}}
+			numeric[i] = agg
+			dest = append(dest, agg[0], agg[1], agg[2])
+		}
+	}
+
+	if err := r.db.QueryRowContext(ctx, fieldStatsQuery(), id).Scan(dest...); err != nil {
+		return nil, fmt.Errorf("field stats query failed: %w", err)
+	}
+
+	for i := range stats.Columns {
+		c := &stats.Columns[i]
+		c.FillRate = fillRate(c.Filled, stats.Listings)
+		if c.Distinct > domain.FieldStatsDistinctCap {
+			c.Distinct = domain.FieldStatsDistinctCap
+			c.DistinctCapped = true
+		}
+		if agg, ok := numeric[i]; ok {
+			c.Min, c.Avg, c.Max = floatOrNil(agg[0]), floatOrNil(agg[1]), floatOrNil(agg[2])
+		}
+	}
+
+	return stats, nil
+}
+
+// floatOrNil is the value of f, nil when it is NULL
+func floatOrNil(f *sql.NullFloat64) *float64 {
+	if !f.Valid {
+		return nil
+	}
+	return &f.Float64
+}
@@ -0,0 +1,21 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sadewadee/google-scraper/internal/exportschema"
+)
+
+func TestFieldStatsCoverExportColumns(t *testing.T) {
+	keys := make([]string, len(fieldStatsColumns))
+	for i, c := range fieldStatsColumns {
+		keys[i] = c.key
+	}
+	assert.Equal(t, exportschema.ListingKeys(), keys)
+
+	for key := range fieldStatsNumeric {
+		assert.Contains(t, keys, key)
+	}
+}
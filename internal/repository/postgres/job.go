@@ -15,6 +15,8 @@ import (
 	"github.com/lib/pq"
 
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/secrets"
+	gmapspostgres "github.com/sadewadee/google-scraper/postgres"
 )
 
 // IntervalDuration is a custom type that can scan PostgreSQL INTERVAL into time.Duration
@@ -96,14 +98,27 @@ func (d IntervalDuration) Value() (driver.Value, error) {
 
 // JobRepository implements domain.JobRepository for PostgreSQL
 type JobRepository struct {
-	db *sql.DB
+	db   querier
+	keys *secrets.Keyring
 }
 
 // NewJobRepository creates a new JobRepository
-func NewJobRepository(db *sql.DB) *JobRepository {
+func NewJobRepository(db querier) *JobRepository {
 	return &JobRepository{db: db}
 }
 
+// SeedTasks returns the seed tasks of bridged jobs and their pusher on the
+// connection of r, so that in a transaction they change with the jobs
+func (r *JobRepository) SeedTasks() (domain.SeedTaskRepository, gmapspostgres.GmapsJobPusher) {
+	return NewSeedTaskRepository(r.db), gmapspostgres.NewGmapsJobPusher(r.db)
+}
+
+// SetKeyring encrypts the proxies of jobs with credentials before they are
+// stored. Without a keyring they are stored in plaintext.
+func (r *JobRepository) SetKeyring(keys *secrets.Keyring) {
+	r.keys = keys
+}
+
 // Create creates a new job
 func (r *JobRepository) Create(ctx context.Context, job *domain.Job) error {
 	start := time.Now()
@@ -123,6 +138,16 @@ func (r *JobRepository) Create(ctx context.Context, job *domain.Job) error {
 		}
 	}
 
+	outputsJSON, err := marshalOutputs(job.Config.Outputs)
+	if err != nil {
+		return err
+	}
+
+	proxies, err := r.keys.SealURLs(job.Config.Proxies)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt job proxies: %w", err)
+	}
+
 	query := `
 		INSERT INTO jobs_queue (
 			id, name, status, priority,
@@ -130,26 +155,53 @@ func (r *JobRepository) Create(ctx context.Context, job *domain.Job) error {
 			fast_mode, extract_email, max_time, proxies,
 			location_name, boundingbox, coverage_mode, grid_points,
 			total_places, scraped_places, failed_places,
-			created_at, updated_at
+			created_at, updated_at,
+			proxy_country, max_reviews, reviews_sort, max_images,
+			tenant, density_check,
+			browser_profile, user_agent, accept_language,
+			incremental, max_results, cloned_from,
+			outputs, global_dedupe, tags, notes,
+			lang_fallback, notify_emails, retry_on_timeout,
+			check_website, depends_on, run_if,
+			google_domain, place_urls, backfill_of,
+			parent_job_id, shard_count
 		) VALUES (
 			$1, $2, $3, $4,
 			$5, $6, $7, $8, $9, $10, $11,
 			$12, $13, $14, $15,
 			$16, $17, $18, $19,
 			$20, $21, $22,
-			$23, $24
+			$23, $24,
+			$25, $26, $27, $28,
+			$29, $30,
+			$31, $32, $33,
+			$34, $35, $36,
+			$37, $38, $39, $40,
+			$41, $42, $43,
+			$44, $45, $46,
+			$47, $48, $49,
+			$50, $51
 		)
 	`
 
 	execStart := time.Now()
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		job.ID, job.Name, job.Status, job.Priority,
 		pq.Array(job.Config.Keywords), job.Config.Lang, job.Config.GeoLat, job.Config.GeoLon,
 		job.Config.Zoom, job.Config.Radius, job.Config.Depth,
-		job.Config.FastMode, job.Config.ExtractEmail, IntervalDuration(job.Config.MaxTime), pq.Array(job.Config.Proxies),
+		job.Config.FastMode, job.Config.ExtractEmail, IntervalDuration(job.Config.MaxTime), pq.Array(proxies),
 		job.Config.LocationName, boundingboxJSON, job.Config.CoverageMode, job.Config.GridPoints,
 		job.Progress.TotalPlaces, job.Progress.ScrapedPlaces, job.Progress.FailedPlaces,
 		job.CreatedAt, job.UpdatedAt,
+		nullString(job.Config.ProxyCountry), job.Config.MaxReviews, nullString(job.Config.ReviewsSort), job.Config.MaxImages,
+		nullString(job.Tenant), job.Config.DensityCheck,
+		nullString(job.Config.BrowserProfile), nullString(job.Config.UserAgent), nullString(job.Config.AcceptLanguage),
+		job.Config.Incremental, job.Config.MaxResults, job.ClonedFrom,
+		outputsJSON, job.Config.GlobalDedupe, pq.Array(domain.NormalizeTags(job.Tags)), job.Notes,
+		pq.Array(job.Config.LangFallback), pq.Array(job.Config.NotifyEmails), job.Config.RetryOnTimeout,
+		job.Config.CheckWebsite, job.DependsOn, nullString(job.RunIf),
+		nullString(job.Config.GoogleDomain), pq.Array(job.Config.PlaceURLs), job.BackfillOf,
+		job.ParentJobID, job.ShardCount,
 	)
 
 	if err != nil {
@@ -175,7 +227,20 @@ func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job,
 			location_name, boundingbox, coverage_mode, grid_points,
 			total_places, scraped_places, failed_places,
 			worker_id, created_at, updated_at, started_at, completed_at,
-			error_message
+			error_message,
+			proxy_country, max_reviews, reviews_sort, max_images,
+			paused_at, checkpoint_places, tenant, density_check,
+			attempts, failed_keywords, retry_keywords,
+			browser_profile, user_agent, accept_language,
+			incremental, new_places, known_places,
+			max_results, stopped_reason, cloned_from,
+			outputs, deleted_at,
+			global_dedupe, deduped_places,
+			tags, notes, lang_fallback, error_code,
+			notify_emails, retry_on_timeout, timeout_requeued,
+			check_website, depends_on, run_if,
+			google_domain, place_urls, backfill_of, partial_places,
+			parent_job_id, shard_count
 		FROM jobs_queue
 		WHERE id = $1
 	`
@@ -187,6 +252,18 @@ func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job,
 	var boundingboxJSON []byte
 	var coverageMode sql.NullString
 	var gridPoints sql.NullInt32
+	var proxyCountry, reviewsSort sql.NullString
+	var pausedAt sql.NullTime
+	var checkpointPlaces sql.NullInt32
+	var tenant sql.NullString
+	var failedKeywords, retryKeywords pq.StringArray
+	var browserProfile, userAgent, acceptLanguage sql.NullString
+	var novelty domain.JobNovelty
+	var stoppedReason, errorCode sql.NullString
+	var clonedFrom, dependsOn, backfillOf, parentJobID uuid.NullUUID
+	var runIf, googleDomain sql.NullString
+	var outputsJSON []byte
+	var tags, langFallback, notifyEmails, placeURLs pq.StringArray
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&job.ID, &job.Name, &job.Status, &job.Priority,
@@ -197,6 +274,19 @@ func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job,
 		&job.Progress.TotalPlaces, &job.Progress.ScrapedPlaces, &job.Progress.FailedPlaces,
 		&job.WorkerID, &job.CreatedAt, &job.UpdatedAt, &job.StartedAt, &job.CompletedAt,
 		&job.ErrorMessage,
+		&proxyCountry, &job.Config.MaxReviews, &reviewsSort, &job.Config.MaxImages,
+		&pausedAt, &checkpointPlaces, &tenant, &job.Config.DensityCheck,
+		&job.Attempts, &failedKeywords, &retryKeywords,
+		&browserProfile, &userAgent, &acceptLanguage,
+		&job.Config.Incremental, &novelty.NewPlaces, &novelty.KnownPlaces,
+		&job.Config.MaxResults, &stoppedReason, &clonedFrom,
+		&outputsJSON, &job.DeletedAt,
+		&job.Config.GlobalDedupe, &job.Progress.DedupedPlaces,
+		&tags, &job.Notes, &langFallback, &errorCode,
+		&notifyEmails, &job.Config.RetryOnTimeout, &job.TimeoutRequeued,
+		&job.Config.CheckWebsite, &dependsOn, &runIf,
+		&googleDomain, &placeURLs, &backfillOf, &job.Progress.PartialPlaces,
+		&parentJobID, &job.ShardCount,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -207,7 +297,10 @@ func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job,
 	}
 
 	job.Config.Keywords = keywords
-	job.Config.Proxies = proxies
+	job.Config.Proxies, err = r.keys.OpenAll(proxies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt proxies of job %s: %w", job.ID, err)
+	}
 	job.Config.MaxTime = time.Duration(maxTime)
 
 	// Parse location fields
@@ -226,6 +319,39 @@ func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job,
 	if gridPoints.Valid {
 		job.Config.GridPoints = int(gridPoints.Int32)
 	}
+	job.Config.ProxyCountry = proxyCountry.String
+	job.Config.GoogleDomain = googleDomain.String
+	job.Config.ReviewsSort = reviewsSort.String
+	job.Checkpoint = scanCheckpoint(pausedAt, checkpointPlaces)
+	job.Tenant = tenant.String
+	job.FailedKeywords = failedKeywords
+	job.RetryKeywords = retryKeywords
+	job.Config.BrowserProfile = browserProfile.String
+	job.Config.UserAgent = userAgent.String
+	job.Config.AcceptLanguage = acceptLanguage.String
+	if job.Config.Incremental {
+		job.Novelty = &novelty
+	}
+	job.StoppedReason = stoppedReason.String
+	job.ErrorCode = domain.JobErrorCode(errorCode.String)
+	if clonedFrom.Valid {
+		job.ClonedFrom = &clonedFrom.UUID
+	}
+	if dependsOn.Valid {
+		job.DependsOn = &dependsOn.UUID
+	}
+	job.RunIf = runIf.String
+	job.Config.Outputs = unmarshalOutputs(outputsJSON)
+	job.Tags = tags
+	job.Config.LangFallback = langFallback
+	job.Config.NotifyEmails = notifyEmails
+	job.Config.PlaceURLs = placeURLs
+	if backfillOf.Valid {
+		job.BackfillOf = &backfillOf.UUID
+	}
+	if parentJobID.Valid {
+		job.ParentJobID = &parentJobID.UUID
+	}
 
 	job.Progress.CalculatePercentage()
 
@@ -254,6 +380,41 @@ func (r *JobRepository) List(ctx context.Context, params domain.JobListParams) (
 		argIdx++
 	}
 
+	if len(params.Tags) > 0 {
+		conditions = append(conditions, fmt.Sprintf("tags @> $%d", argIdx))
+		args = append(args, pq.Array(params.Tags))
+		argIdx++
+	}
+
+	if params.ErrorCode != nil {
+		conditions = append(conditions, fmt.Sprintf("error_code = $%d", argIdx))
+		args = append(args, *params.ErrorCode)
+		argIdx++
+	}
+
+	if params.DependsOn != nil {
+		conditions = append(conditions, fmt.Sprintf("depends_on = $%d", argIdx))
+		args = append(args, *params.DependsOn)
+		argIdx++
+	}
+
+	if params.ParentJobID != nil {
+		conditions = append(conditions, fmt.Sprintf("parent_job_id = $%d", argIdx))
+		args = append(args, *params.ParentJobID)
+		argIdx++
+	}
+
+	if params.Sharded {
+		conditions = append(conditions, "shard_count > 0")
+	}
+
+	// The estimate below counts deleted jobs too, which is close enough
+	filtered := len(conditions) > 0
+
+	if !params.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
@@ -264,7 +425,7 @@ func (r *JobRepository) List(ctx context.Context, params domain.JobListParams) (
 
 	// Optimization: Use approximate row count if no filters are applied
 	// This avoids slow COUNT(*) on large tables
-	if len(conditions) == 0 {
+	if !filtered {
 		countQuery = "SELECT reltuples::bigint FROM pg_class WHERE relname = 'jobs_queue'"
 	} else {
 		countQuery = fmt.Sprintf("SELECT COUNT(*) FROM jobs_queue %s", whereClause)
@@ -274,9 +435,9 @@ func (r *JobRepository) List(ctx context.Context, params domain.JobListParams) (
 	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		// Fallback to standard count if estimation fails (e.g., table not analyzed yet)
-		if len(conditions) == 0 {
-			countQuery = "SELECT COUNT(*) FROM jobs_queue"
-			if err := r.db.QueryRowContext(ctx, countQuery).Scan(&total); err != nil {
+		if !filtered {
+			countQuery = fmt.Sprintf("SELECT COUNT(*) FROM jobs_queue %s", whereClause)
+			if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
 				return nil, 0, err
 			}
 		} else {
@@ -310,7 +471,20 @@ func (r *JobRepository) List(ctx context.Context, params domain.JobListParams) (
 			location_name, boundingbox, coverage_mode, grid_points,
 			total_places, scraped_places, failed_places,
 			worker_id, created_at, updated_at, started_at, completed_at,
-			error_message
+			error_message,
+			proxy_country, max_reviews, reviews_sort, max_images,
+			paused_at, checkpoint_places, tenant, density_check,
+			attempts, failed_keywords, retry_keywords,
+			browser_profile, user_agent, accept_language,
+			incremental, new_places, known_places,
+			max_results, stopped_reason, cloned_from,
+			outputs, deleted_at,
+			global_dedupe, deduped_places,
+			tags, notes, lang_fallback, error_code,
+			notify_emails, retry_on_timeout, timeout_requeued,
+			check_website, depends_on, run_if,
+			google_domain, place_urls, backfill_of, partial_places,
+			parent_job_id, shard_count
 		FROM jobs_queue
 		%s
 		ORDER BY %s %s
@@ -334,6 +508,18 @@ func (r *JobRepository) List(ctx context.Context, params domain.JobListParams) (
 		var boundingboxJSON []byte
 		var coverageMode sql.NullString
 		var gridPoints sql.NullInt32
+		var proxyCountry, reviewsSort sql.NullString
+		var pausedAt sql.NullTime
+		var checkpointPlaces sql.NullInt32
+		var tenant sql.NullString
+		var failedKeywords, retryKeywords pq.StringArray
+		var browserProfile, userAgent, acceptLanguage sql.NullString
+		var novelty domain.JobNovelty
+		var stoppedReason, errorCode sql.NullString
+		var clonedFrom, dependsOn, backfillOf, parentJobID uuid.NullUUID
+		var runIf, googleDomain sql.NullString
+		var outputsJSON []byte
+		var tags, langFallback, notifyEmails, placeURLs pq.StringArray
 
 		err := rows.Scan(
 			&job.ID, &job.Name, &job.Status, &job.Priority,
@@ -344,13 +530,29 @@ func (r *JobRepository) List(ctx context.Context, params domain.JobListParams) (
 			&job.Progress.TotalPlaces, &job.Progress.ScrapedPlaces, &job.Progress.FailedPlaces,
 			&job.WorkerID, &job.CreatedAt, &job.UpdatedAt, &job.StartedAt, &job.CompletedAt,
 			&job.ErrorMessage,
+			&proxyCountry, &job.Config.MaxReviews, &reviewsSort, &job.Config.MaxImages,
+			&pausedAt, &checkpointPlaces, &tenant, &job.Config.DensityCheck,
+			&job.Attempts, &failedKeywords, &retryKeywords,
+			&browserProfile, &userAgent, &acceptLanguage,
+			&job.Config.Incremental, &novelty.NewPlaces, &novelty.KnownPlaces,
+			&job.Config.MaxResults, &stoppedReason, &clonedFrom,
+			&outputsJSON, &job.DeletedAt,
+			&job.Config.GlobalDedupe, &job.Progress.DedupedPlaces,
+			&tags, &job.Notes, &langFallback, &errorCode,
+			&notifyEmails, &job.Config.RetryOnTimeout, &job.TimeoutRequeued,
+			&job.Config.CheckWebsite, &dependsOn, &runIf,
+			&googleDomain, &placeURLs, &backfillOf, &job.Progress.PartialPlaces,
+			&parentJobID, &job.ShardCount,
 		)
 		if err != nil {
 			return nil, 0, err
 		}
 
 		job.Config.Keywords = keywords
-		job.Config.Proxies = proxies
+		job.Config.Proxies, err = r.keys.OpenAll(proxies)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to decrypt proxies of job %s: %w", job.ID, err)
+		}
 		job.Config.MaxTime = time.Duration(maxTime)
 
 		// Parse location fields
@@ -369,6 +571,39 @@ func (r *JobRepository) List(ctx context.Context, params domain.JobListParams) (
 		if gridPoints.Valid {
 			job.Config.GridPoints = int(gridPoints.Int32)
 		}
+		job.Config.ProxyCountry = proxyCountry.String
+		job.Config.GoogleDomain = googleDomain.String
+		job.Config.ReviewsSort = reviewsSort.String
+		job.Checkpoint = scanCheckpoint(pausedAt, checkpointPlaces)
+		job.Tenant = tenant.String
+		job.FailedKeywords = failedKeywords
+		job.RetryKeywords = retryKeywords
+		job.Config.BrowserProfile = browserProfile.String
+		job.Config.UserAgent = userAgent.String
+		job.Config.AcceptLanguage = acceptLanguage.String
+		if job.Config.Incremental {
+			job.Novelty = &novelty
+		}
+		job.StoppedReason = stoppedReason.String
+		job.ErrorCode = domain.JobErrorCode(errorCode.String)
+		if clonedFrom.Valid {
+			job.ClonedFrom = &clonedFrom.UUID
+		}
+		if dependsOn.Valid {
+			job.DependsOn = &dependsOn.UUID
+		}
+		job.RunIf = runIf.String
+		job.Config.Outputs = unmarshalOutputs(outputsJSON)
+		job.Tags = tags
+		job.Config.LangFallback = langFallback
+		job.Config.NotifyEmails = notifyEmails
+		job.Config.PlaceURLs = placeURLs
+		if backfillOf.Valid {
+			job.BackfillOf = &backfillOf.UUID
+		}
+		if parentJobID.Valid {
+			job.ParentJobID = &parentJobID.UUID
+		}
 
 		job.Progress.CalculatePercentage()
 
@@ -390,6 +625,16 @@ func (r *JobRepository) Update(ctx context.Context, job *domain.Job) error {
 		}
 	}
 
+	outputsJSON, err := marshalOutputs(job.Config.Outputs)
+	if err != nil {
+		return err
+	}
+
+	proxies, err := r.keys.SealURLs(job.Config.Proxies)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt job proxies: %w", err)
+	}
+
 	query := `
 		UPDATE jobs_queue SET
 			name = $2, status = $3, priority = $4,
@@ -399,19 +644,43 @@ func (r *JobRepository) Update(ctx context.Context, job *domain.Job) error {
 			location_name = $16, boundingbox = $17, coverage_mode = $18, grid_points = $19,
 			total_places = $20, scraped_places = $21, failed_places = $22,
 			worker_id = $23, started_at = $24, completed_at = $25,
-			error_message = $26
+			error_message = $26,
+			proxy_country = $27, max_reviews = $28, reviews_sort = $29, max_images = $30,
+			density_check = $31,
+			attempts = $32, failed_keywords = $33, retry_keywords = $34,
+			browser_profile = $35, user_agent = $36, accept_language = $37,
+			incremental = $38, max_results = $39, stopped_reason = $40,
+			outputs = $41, global_dedupe = $42, deduped_places = $43,
+			tags = $44, notes = $45, lang_fallback = $46,
+			error_code = $47, notify_emails = $48,
+			retry_on_timeout = $49, timeout_requeued = $50,
+			check_website = $51, google_domain = $52,
+			place_urls = $53, partial_places = $54,
+			shard_count = $55
 		WHERE id = $1
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		job.ID, job.Name, job.Status, job.Priority,
 		pq.Array(job.Config.Keywords), job.Config.Lang, job.Config.GeoLat, job.Config.GeoLon,
 		job.Config.Zoom, job.Config.Radius, job.Config.Depth,
-		job.Config.FastMode, job.Config.ExtractEmail, IntervalDuration(job.Config.MaxTime), pq.Array(job.Config.Proxies),
+		job.Config.FastMode, job.Config.ExtractEmail, IntervalDuration(job.Config.MaxTime), pq.Array(proxies),
 		job.Config.LocationName, boundingboxJSON, job.Config.CoverageMode, job.Config.GridPoints,
 		job.Progress.TotalPlaces, job.Progress.ScrapedPlaces, job.Progress.FailedPlaces,
 		job.WorkerID, job.StartedAt, job.CompletedAt,
 		job.ErrorMessage,
+		nullString(job.Config.ProxyCountry), job.Config.MaxReviews, nullString(job.Config.ReviewsSort), job.Config.MaxImages,
+		job.Config.DensityCheck,
+		max(job.Attempts, 1), pq.Array(job.FailedKeywords), pq.Array(job.RetryKeywords),
+		nullString(job.Config.BrowserProfile), nullString(job.Config.UserAgent), nullString(job.Config.AcceptLanguage),
+		job.Config.Incremental, job.Config.MaxResults, nullString(job.StoppedReason),
+		outputsJSON, job.Config.GlobalDedupe, job.Progress.DedupedPlaces,
+		pq.Array(domain.NormalizeTags(job.Tags)), job.Notes, pq.Array(job.Config.LangFallback),
+		nullString(string(job.ErrorCode)), pq.Array(job.Config.NotifyEmails),
+		job.Config.RetryOnTimeout, job.TimeoutRequeued,
+		job.Config.CheckWebsite, nullString(job.Config.GoogleDomain),
+		pq.Array(job.Config.PlaceURLs), job.Progress.PartialPlaces,
+		job.ShardCount,
 	)
 
 	return err
@@ -436,6 +705,110 @@ func (r *JobRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// SoftDelete marks a job as deleted
+func (r *JobRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE jobs_queue SET deleted_at = COALESCE(deleted_at, NOW()) WHERE id = $1`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Restore clears the deletion mark of a job
+func (r *JobRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE jobs_queue SET deleted_at = NULL WHERE id = $1`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ListDeletedBefore returns up to limit jobs deleted before t, oldest first
+func (r *JobRepository) ListDeletedBefore(ctx context.Context, t time.Time, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM jobs_queue
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+		ORDER BY deleted_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, t, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ListRunning returns the running jobs held by workers
+func (r *JobRepository) ListRunning(ctx context.Context) ([]*domain.Job, error) {
+	query := `
+		SELECT id FROM jobs_queue
+		WHERE status = 'running' AND worker_id IS NOT NULL AND deleted_at IS NULL
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*domain.Job, 0, len(ids))
+	for _, id := range ids {
+		job, err := r.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+
+	return jobs, nil
+}
+
 // UpdateStatus updates only the status of a job
 func (r *JobRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.JobStatus) error {
 	var query string
@@ -443,6 +816,8 @@ func (r *JobRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status d
 	switch status {
 	case domain.JobStatusRunning:
 		query = `UPDATE jobs_queue SET status = $2, started_at = NOW() WHERE id = $1`
+	case domain.JobStatusPaused:
+		query = `UPDATE jobs_queue SET status = $2, paused_at = NOW(), checkpoint_places = scraped_places WHERE id = $1`
 	case domain.JobStatusCompleted, domain.JobStatusFailed, domain.JobStatusCancelled:
 		query = `UPDATE jobs_queue SET status = $2, completed_at = NOW(), worker_id = NULL WHERE id = $1`
 	default:
@@ -453,18 +828,97 @@ func (r *JobRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status d
 	return err
 }
 
+// UpdateLabels replaces the tags and notes of a job
+func (r *JobRepository) UpdateLabels(ctx context.Context, id uuid.UUID, tags []string, notes string) error {
+	query := `UPDATE jobs_queue SET tags = $2, notes = $3, updated_at = NOW() WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id, pq.Array(domain.NormalizeTags(tags)), notes)
+	return err
+}
+
+// UpdateConfig replaces the config and place estimate of a job, if it is
+// still pending or paused, not deleted and not split into shards
+func (r *JobRepository) UpdateConfig(ctx context.Context, job *domain.Job) (bool, error) {
+	var boundingboxJSON []byte
+	if job.Config.BoundingBox != nil {
+		var err error
+		boundingboxJSON, err = json.Marshal(job.Config.BoundingBox)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal boundingbox: %w", err)
+		}
+	}
+
+	outputsJSON, err := marshalOutputs(job.Config.Outputs)
+	if err != nil {
+		return false, err
+	}
+
+	proxies, err := r.keys.SealURLs(job.Config.Proxies)
+	if err != nil {
+		return false, fmt.Errorf("failed to encrypt job proxies: %w", err)
+	}
+
+	query := `
+		UPDATE jobs_queue SET
+			keywords = $2, lang = $3, geo_lat = $4, geo_lon = $5,
+			zoom = $6, radius = $7, depth = $8,
+			fast_mode = $9, extract_email = $10, max_time = $11, proxies = $12,
+			location_name = $13, boundingbox = $14, coverage_mode = $15, grid_points = $16,
+			total_places = $17,
+			proxy_country = $18, max_reviews = $19, reviews_sort = $20, max_images = $21,
+			density_check = $22,
+			browser_profile = $23, user_agent = $24, accept_language = $25,
+			incremental = $26, max_results = $27,
+			outputs = $28, global_dedupe = $29, lang_fallback = $30,
+			notify_emails = $31, retry_on_timeout = $32,
+			check_website = $33, google_domain = $34,
+			place_urls = $35,
+			updated_at = NOW()
+		WHERE id = $1 AND status IN ('pending', 'paused')
+			AND deleted_at IS NULL AND shard_count = 0
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		job.ID,
+		pq.Array(job.Config.Keywords), job.Config.Lang, job.Config.GeoLat, job.Config.GeoLon,
+		job.Config.Zoom, job.Config.Radius, job.Config.Depth,
+		job.Config.FastMode, job.Config.ExtractEmail, IntervalDuration(job.Config.MaxTime), pq.Array(proxies),
+		job.Config.LocationName, boundingboxJSON, job.Config.CoverageMode, job.Config.GridPoints,
+		job.Progress.TotalPlaces,
+		nullString(job.Config.ProxyCountry), job.Config.MaxReviews, nullString(job.Config.ReviewsSort), job.Config.MaxImages,
+		job.Config.DensityCheck,
+		nullString(job.Config.BrowserProfile), nullString(job.Config.UserAgent), nullString(job.Config.AcceptLanguage),
+		job.Config.Incremental, job.Config.MaxResults,
+		outputsJSON, job.Config.GlobalDedupe, pq.Array(job.Config.LangFallback),
+		pq.Array(job.Config.NotifyEmails), job.Config.RetryOnTimeout,
+		job.Config.CheckWebsite, nullString(job.Config.GoogleDomain),
+		pq.Array(job.Config.PlaceURLs),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
 // UpdateProgress updates the progress of a job
 func (r *JobRepository) UpdateProgress(ctx context.Context, id uuid.UUID, progress domain.JobProgress) error {
 	query := `
 		UPDATE jobs_queue SET
 			total_places = $2,
 			scraped_places = $3,
-			failed_places = $4
+			failed_places = $4,
+			partial_places = $5
 		WHERE id = $1
 	`
 
 	_, err := r.db.ExecContext(ctx, query, id,
-		progress.TotalPlaces, progress.ScrapedPlaces, progress.FailedPlaces)
+		progress.TotalPlaces, progress.ScrapedPlaces, progress.FailedPlaces, progress.PartialPlaces)
 	return err
 }
 
@@ -477,7 +931,7 @@ func (r *JobRepository) ClaimJob(ctx context.Context, workerID string) (*domain.
 			started_at = NOW()
 		WHERE id = (
 			SELECT id FROM jobs_queue
-			WHERE status = 'pending'
+			WHERE status = 'pending' AND deleted_at IS NULL AND shard_count = 0
 			ORDER BY priority DESC, created_at ASC
 			FOR UPDATE SKIP LOCKED
 			LIMIT 1
@@ -497,11 +951,14 @@ func (r *JobRepository) ClaimJob(ctx context.Context, workerID string) (*domain.
 	return r.GetByID(ctx, jobID)
 }
 
-// ReleaseJob releases a job back to pending status
+// ReleaseJob releases a job back to pending status. A job paused or
+// cancelled while it ran keeps its status; a paused job's checkpoint is
+// refreshed with the places the worker submitted before stopping.
 func (r *JobRepository) ReleaseJob(ctx context.Context, id uuid.UUID) error {
 	query := `
 		UPDATE jobs_queue SET
-			status = 'pending',
+			status = CASE WHEN status IN ('paused', 'cancelled') THEN status ELSE 'pending' END,
+			checkpoint_places = CASE WHEN status = 'paused' THEN scraped_places ELSE checkpoint_places END,
 			worker_id = NULL,
 			started_at = NULL
 		WHERE id = $1
@@ -511,6 +968,44 @@ func (r *JobRepository) ReleaseJob(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+func scanCheckpoint(pausedAt sql.NullTime, places sql.NullInt32) *domain.JobCheckpoint {
+	if !pausedAt.Valid {
+		return nil
+	}
+
+	return &domain.JobCheckpoint{
+		PausedAt:      pausedAt.Time,
+		ScrapedPlaces: int(places.Int32),
+	}
+}
+
+// marshalOutputs stores a job without outputs as NULL
+func marshalOutputs(outputs []domain.JobOutput) ([]byte, error) {
+	if len(outputs) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(outputs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal outputs: %w", err)
+	}
+
+	return data, nil
+}
+
+func unmarshalOutputs(data []byte) []domain.JobOutput {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var outputs []domain.JobOutput
+	if err := json.Unmarshal(data, &outputs); err != nil {
+		return nil
+	}
+
+	return outputs
+}
+
 // GetStats retrieves job statistics
 func (r *JobRepository) GetStats(ctx context.Context) (*domain.JobStats, error) {
 	// Add timeout to prevent hanging on stats query
@@ -520,6 +1015,7 @@ func (r *JobRepository) GetStats(ctx context.Context) (*domain.JobStats, error)
 	query := `
 		SELECT
 			COUNT(*) as total,
+			COUNT(*) FILTER (WHERE status = 'waiting') as waiting,
 			COUNT(*) FILTER (WHERE status = 'pending') as pending,
 			COUNT(*) FILTER (WHERE status = 'queued') as queued,
 			COUNT(*) FILTER (WHERE status = 'running') as running,
@@ -528,13 +1024,104 @@ func (r *JobRepository) GetStats(ctx context.Context) (*domain.JobStats, error)
 			COUNT(*) FILTER (WHERE status = 'failed') as failed,
 			COUNT(*) FILTER (WHERE status = 'cancelled') as cancelled
 		FROM jobs_queue
+		WHERE deleted_at IS NULL
 	`
 
 	stats := &domain.JobStats{}
 	err := r.db.QueryRowContext(ctx, query).Scan(
-		&stats.Total, &stats.Pending, &stats.Queued, &stats.Running,
+		&stats.Total, &stats.Waiting, &stats.Pending, &stats.Queued, &stats.Running,
 		&stats.Paused, &stats.Completed, &stats.Failed, &stats.Cancelled,
 	)
+	if err != nil || stats.Failed == 0 {
+		return stats, err
+	}
 
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT COALESCE(error_code, 'internal'), COUNT(*)
+		FROM jobs_queue
+		WHERE status = 'failed' AND deleted_at IS NULL
+		GROUP BY 1
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats.FailedByCode, err = scanFailedByCode(rows)
 	return stats, err
 }
+
+// scanFailedByCode reads rows of error codes and job counts. Codes no
+// longer known count as internal.
+func scanFailedByCode(rows *sql.Rows) (map[domain.JobErrorCode]int, error) {
+	byCode := make(map[domain.JobErrorCode]int)
+	for rows.Next() {
+		var (
+			code  string
+			count int
+		)
+		if err := rows.Scan(&code, &count); err != nil {
+			return nil, err
+		}
+		byCode[domain.ParseJobErrorCode(code)] += count
+	}
+
+	return byCode, rows.Err()
+}
+
+// SetParseReport stores the parse report of a job's last run
+func (r *JobRepository) SetParseReport(ctx context.Context, id uuid.UUID, report *domain.JobParseReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal parse report: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `UPDATE jobs_queue SET parse_report = $2 WHERE id = $1`, id, data)
+	return err
+}
+
+// GetParseReport returns the parse report of a job's last run, nil when
+// none was stored
+func (r *JobRepository) GetParseReport(ctx context.Context, id uuid.UUID) (*domain.JobParseReport, error) {
+	var data []byte
+	err := r.db.QueryRowContext(ctx, `SELECT parse_report FROM jobs_queue WHERE id = $1`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var report domain.JobParseReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal parse report: %w", err)
+	}
+
+	return &report, nil
+}
+
+// SetQueueMessageID stores the ID of the message that last enqueued a job
+func (r *JobRepository) SetQueueMessageID(ctx context.Context, id uuid.UUID, messageID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE jobs_queue SET queue_message_id = $2 WHERE id = $1`, id, messageID)
+	return err
+}
+
+// GetQueueMessageID returns the ID of the message that last enqueued a
+// job, empty when none was stored
+func (r *JobRepository) GetQueueMessageID(ctx context.Context, id uuid.UUID) (string, error) {
+	var messageID sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT queue_message_id FROM jobs_queue WHERE id = $1`, id).Scan(&messageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return messageID.String, err
+}
+
+// jobScope is the condition matching column to the job arg and, for a
+// sharded job, to its shards, whose results it shows as its own
+func jobScope(column, arg string) string {
+	return fmt.Sprintf("%s IN (SELECT id FROM jobs_queue WHERE id = %s OR parent_job_id = %s)", column, arg, arg)
+}
@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/secrets"
+)
+
+// JobEventRepository implements domain.JobEventRepository for PostgreSQL
+type JobEventRepository struct {
+	db   *sql.DB
+	keys *secrets.Keyring
+}
+
+// NewJobEventRepository creates a new JobEventRepository
+func NewJobEventRepository(db *sql.DB) *JobEventRepository {
+	return &JobEventRepository{db: db}
+}
+
+// SetKeyring sets the keyring of the JobRepository Record hands to fn
+func (r *JobEventRepository) SetKeyring(keys *secrets.Keyring) {
+	r.keys = keys
+}
+
+// Record runs fn on a JobRepository in the transaction ev is inserted in,
+// so the trail holds exactly the changes that were committed
+func (r *JobEventRepository) Record(ctx context.Context, ev *domain.JobEvent, fn func(jobs domain.JobRepository) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if fn != nil {
+		jobs := NewJobRepository(tx)
+		jobs.SetKeyring(r.keys)
+		if err := fn(jobs); err != nil {
+			return err
+		}
+	}
+
+	if ev.JobID != uuid.Nil {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO job_events (job_id, type, status, actor, error_code, message)
+			VALUES ($1, $2, NULLIF($3, ''), $4, NULLIF($5, ''), NULLIF($6, ''))
+			RETURNING id, created_at
+		`, ev.JobID, ev.Type, string(ev.Status), ev.Actor, string(ev.ErrorCode), ev.Message).Scan(&ev.ID, &ev.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to record job event: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit job event: %w", err)
+	}
+
+	return nil
+}
+
+// ListByJobID returns the events of a job, oldest first, and their total
+func (r *JobEventRepository) ListByJobID(ctx context.Context, jobID uuid.UUID, limit, offset int) ([]*domain.JobEvent, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM job_events WHERE job_id = $1`, jobID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count job events: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, job_id, type, COALESCE(status, ''), actor, COALESCE(error_code, ''), COALESCE(message, ''), created_at
+		FROM job_events
+		WHERE job_id = $1
+		ORDER BY id
+		LIMIT $2 OFFSET $3
+	`, jobID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list job events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*domain.JobEvent, 0)
+	for rows.Next() {
+		var (
+			ev     domain.JobEvent
+			status string
+			code   string
+		)
+		if err := rows.Scan(&ev.ID, &ev.JobID, &ev.Type, &status, &ev.Actor, &code, &ev.Message, &ev.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan job event: %w", err)
+		}
+		ev.Status = domain.JobStatus(status)
+		ev.ErrorCode = domain.JobErrorCode(code)
+		events = append(events, &ev)
+	}
+
+	return events, total, rows.Err()
+}
+
+// DeleteBefore removes events recorded before t
+func (r *JobEventRepository) DeleteBefore(ctx context.Context, t time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM job_events WHERE created_at < $1`, t)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune job events: %w", err)
+	}
+	return res.RowsAffected()
+}
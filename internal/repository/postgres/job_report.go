@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// JobReportRepository sums up the business listings of a job
+type JobReportRepository struct {
+	db *sql.DB
+}
+
+// NewJobReportRepository creates a new JobReportRepository
+func NewJobReportRepository(db *sql.DB) *JobReportRepository {
+	return &JobReportRepository{db: db}
+}
+
+// JobReport counts the places of a job, its most frequent categories and
+// the coordinates of up to maxPoints places, in the order they were stored
+func (r *JobReportRepository) JobReport(ctx context.Context, jobID uuid.UUID, topCategories, maxPoints int) (*domain.JobReport, error) {
+	report := &domain.JobReport{
+		JobID:         jobID,
+		TopCategories: []domain.CategoryCount{},
+		Points:        []domain.GeoPoint{},
+	}
+
+	const countsQuery = `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM business_emails be WHERE be.business_listing_id = bl.id)),
+			COUNT(*) FILTER (WHERE bl.phone IS NOT NULL AND bl.phone != ''),
+			COUNT(*) FILTER (WHERE bl.website IS NOT NULL AND bl.website != '')
+		FROM business_listings bl
+		WHERE bl.job_id IN (SELECT id FROM jobs_queue WHERE id = $1 OR parent_job_id = $1)
+	`
+	if err := r.db.QueryRowContext(ctx, countsQuery, jobID).Scan(
+		&report.Places, &report.WithEmail, &report.WithPhone, &report.WithWebsite,
+	); err != nil {
+		return nil, fmt.Errorf("count job listings: %w", err)
+	}
+
+	if report.Places == 0 {
+		return report, nil
+	}
+
+	const categoriesQuery = `
+		SELECT category, COUNT(*) AS cnt
+		FROM business_listings
+		WHERE job_id IN (SELECT id FROM jobs_queue WHERE id = $1 OR parent_job_id = $1) AND category IS NOT NULL AND category != ''
+		GROUP BY category
+		ORDER BY cnt DESC, category
+		LIMIT $2
+	`
+	rows, err := r.db.QueryContext(ctx, categoriesQuery, jobID, topCategories)
+	if err != nil {
+		return nil, fmt.Errorf("count job categories: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c domain.CategoryCount
+		if err := rows.Scan(&c.Category, &c.Count); err != nil {
+			return nil, fmt.Errorf("scan job category: %w", err)
+		}
+		report.TopCategories = append(report.TopCategories, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("count job categories: %w", err)
+	}
+
+	const pointsQuery = `
+		SELECT latitude, longitude
+		FROM business_listings
+		WHERE job_id IN (SELECT id FROM jobs_queue WHERE id = $1 OR parent_job_id = $1) AND latitude IS NOT NULL AND longitude IS NOT NULL
+		ORDER BY id
+		LIMIT $2
+	`
+	points, err := r.db.QueryContext(ctx, pointsQuery, jobID, maxPoints)
+	if err != nil {
+		return nil, fmt.Errorf("list job coordinates: %w", err)
+	}
+	defer points.Close()
+
+	for points.Next() {
+		var p domain.GeoPoint
+		if err := points.Scan(&p.Lat, &p.Lon); err != nil {
+			return nil, fmt.Errorf("scan job coordinates: %w", err)
+		}
+		report.Points = append(report.Points, p)
+	}
+	if err := points.Err(); err != nil {
+		return nil, fmt.Errorf("list job coordinates: %w", err)
+	}
+
+	return report, nil
+}
+
+// Verify interface compliance at compile time
+var _ domain.JobReportRepository = (*JobReportRepository)(nil)
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// JobTemplateRepository implements domain.JobTemplateRepository for PostgreSQL
+type JobTemplateRepository struct {
+	db *sql.DB
+}
+
+// NewJobTemplateRepository creates a new JobTemplateRepository
+func NewJobTemplateRepository(db *sql.DB) *JobTemplateRepository {
+	return &JobTemplateRepository{db: db}
+}
+
+// Create creates a new template
+func (r *JobTemplateRepository) Create(ctx context.Context, tmpl *domain.JobTemplate) error {
+	config, err := json.Marshal(tmpl.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal template config: %w", err)
+	}
+
+	query := `
+		INSERT INTO job_templates (id, name, description, config, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		tmpl.ID, tmpl.Name, nullString(tmpl.Description), config,
+	).Scan(&tmpl.CreatedAt, &tmpl.UpdatedAt)
+}
+
+// GetByID retrieves a template by ID
+func (r *JobTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.JobTemplate, error) {
+	query := `
+		SELECT id, name, description, config, usage_count, created_at, updated_at
+		FROM job_templates
+		WHERE id = $1
+	`
+
+	tmpl, err := scanJobTemplate(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return tmpl, err
+}
+
+// List retrieves all templates
+func (r *JobTemplateRepository) List(ctx context.Context) ([]*domain.JobTemplate, error) {
+	query := `
+		SELECT id, name, description, config, usage_count, created_at, updated_at
+		FROM job_templates
+		ORDER BY name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []*domain.JobTemplate
+	for rows.Next() {
+		tmpl, err := scanJobTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, tmpl)
+	}
+
+	return templates, rows.Err()
+}
+
+// Update updates a template's name, description and config
+func (r *JobTemplateRepository) Update(ctx context.Context, tmpl *domain.JobTemplate) error {
+	config, err := json.Marshal(tmpl.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal template config: %w", err)
+	}
+
+	query := `
+		UPDATE job_templates
+		SET name = $2, description = $3, config = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		tmpl.ID, tmpl.Name, nullString(tmpl.Description), config,
+	).Scan(&tmpl.UpdatedAt)
+}
+
+// Delete deletes a template by ID
+func (r *JobTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM job_templates WHERE id = $1`, id)
+	return err
+}
+
+// IncrementUsage increments the number of jobs created from a template
+func (r *JobTemplateRepository) IncrementUsage(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE job_templates SET usage_count = usage_count + 1 WHERE id = $1`, id)
+	return err
+}
+
+func scanJobTemplate(row rowScanner) (*domain.JobTemplate, error) {
+	tmpl := &domain.JobTemplate{}
+	var description sql.NullString
+	var config []byte
+
+	if err := row.Scan(
+		&tmpl.ID, &tmpl.Name, &description, &config,
+		&tmpl.UsageCount, &tmpl.CreatedAt, &tmpl.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if description.Valid {
+		tmpl.Description = description.String
+	}
+	if err := json.Unmarshal(config, &tmpl.Config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal template config: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// Verify interface compliance at compile time
+var _ domain.JobTemplateRepository = (*JobTemplateRepository)(nil)
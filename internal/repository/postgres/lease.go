@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LeaseRepository keeps the manager leader lock in manager_leases. It
+// implements leader.Locker; expiry is decided by the database clock, so the
+// replicas' clocks do not matter.
+type LeaseRepository struct {
+	db *sql.DB
+}
+
+// NewLeaseRepository creates a new LeaseRepository
+func NewLeaseRepository(db *sql.DB) *LeaseRepository {
+	return &LeaseRepository{db: db}
+}
+
+// Acquire takes the lease if it is free or expired, or extends it when
+// holder has it
+func (r *LeaseRepository) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	var got string
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO manager_leases (name, holder, acquired_at, expires_at)
+		VALUES ($1, $2, NOW(), NOW() + $3 * INTERVAL '1 millisecond')
+		ON CONFLICT (name) DO UPDATE SET
+			holder = EXCLUDED.holder,
+			acquired_at = CASE WHEN manager_leases.holder = EXCLUDED.holder
+				THEN manager_leases.acquired_at ELSE NOW() END,
+			expires_at = EXCLUDED.expires_at
+		WHERE manager_leases.holder = EXCLUDED.holder OR manager_leases.expires_at <= NOW()
+		RETURNING holder
+	`, name, holder, ttl.Milliseconds()).Scan(&got)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("acquire lease %s: %w", name, err)
+	}
+
+	return got == holder, nil
+}
+
+// Release drops the lease if holder has it
+func (r *LeaseRepository) Release(ctx context.Context, name, holder string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM manager_leases WHERE name = $1 AND holder = $2`, name, holder); err != nil {
+		return fmt.Errorf("release lease %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Holder returns the holder of an unexpired lease, "" when there is none
+func (r *LeaseRepository) Holder(ctx context.Context, name string) (string, error) {
+	var holder string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT holder FROM manager_leases WHERE name = $1 AND expires_at > NOW()
+	`, name).Scan(&holder)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read lease %s: %w", name, err)
+	}
+
+	return holder, nil
+}
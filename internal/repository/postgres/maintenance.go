@@ -0,0 +1,243 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/lib/pq"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// maintenanceTables are analyzed by every run: the listing queries and the
+// approximate counts read from pg_class.reltuples plan with their statistics
+var maintenanceTables = []string{"business_listings", "emails", "jobs_queue"}
+
+// reindexTables hold the heavy indexes a run may rebuild
+var reindexTables = []string{"business_listings", "emails"}
+
+// MaintenanceRepository implements domain.MaintenanceRepository for
+// PostgreSQL
+type MaintenanceRepository struct {
+	db *sql.DB
+}
+
+// NewMaintenanceRepository creates a new MaintenanceRepository
+func NewMaintenanceRepository(db *sql.DB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+// Lock takes a session advisory lock on a connection of its own, so the
+// lock is freed with the connection when the manager dies mid-run
+func (r *MaintenanceRepository) Lock(ctx context.Context) (func(), bool, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("get connection: %w", err)
+	}
+
+	var ok bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext('maintenance'))`).Scan(&ok); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("take maintenance lock: %w", err)
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext('maintenance'))`); err != nil {
+			log.Printf("maintenance: failed to release lock: %v", err)
+		}
+		conn.Close()
+	}
+
+	return unlock, true, nil
+}
+
+// Plan rebuilds the requested indexes, then analyzes, or vacuums and
+// analyzes, the maintained tables, so the statistics cover the new indexes
+func (r *MaintenanceRepository) Plan(ctx context.Context, opts domain.MaintenanceOptions) ([]*domain.MaintenanceStep, error) {
+	if len(opts.Indexes) > 0 && !opts.Reindex {
+		return nil, fmt.Errorf("%w: indexes need reindex", domain.ErrInvalidMaintenance)
+	}
+
+	var steps []*domain.MaintenanceStep
+
+	if opts.Reindex {
+		indexes, err := r.indexes(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(opts.Indexes) > 0 {
+			known := make(map[string]bool, len(indexes))
+			for _, name := range indexes {
+				known[name] = true
+			}
+			for _, name := range opts.Indexes {
+				if !known[name] {
+					return nil, fmt.Errorf("%w: %q is not an index of %v", domain.ErrInvalidMaintenance, name, reindexTables)
+				}
+			}
+			indexes = opts.Indexes
+		}
+
+		for _, name := range indexes {
+			steps = append(steps, maintenanceStep("REINDEX INDEX CONCURRENTLY "+pq.QuoteIdentifier(name)))
+		}
+	}
+
+	for _, table := range maintenanceTables {
+		stmt := "ANALYZE " + table
+		if opts.Vacuum {
+			stmt = "VACUUM (ANALYZE) " + table
+		}
+		steps = append(steps, maintenanceStep(stmt))
+	}
+
+	return steps, nil
+}
+
+// indexes returns the indexes of reindexTables
+func (r *MaintenanceRepository) indexes(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT indexname FROM pg_indexes
+		WHERE schemaname = current_schema() AND tablename = ANY($1)
+		ORDER BY tablename, indexname
+	`, pq.Array(reindexTables))
+	if err != nil {
+		return nil, fmt.Errorf("list indexes: %w", err)
+	}
+	defer rows.Close()
+
+	var indexes []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan index: %w", err)
+		}
+		indexes = append(indexes, name)
+	}
+
+	return indexes, rows.Err()
+}
+
+func maintenanceStep(stmt string) *domain.MaintenanceStep {
+	return &domain.MaintenanceStep{Statement: stmt, Status: domain.MaintenancePending}
+}
+
+// Exec runs the statement outside a transaction, which VACUUM and REINDEX
+// CONCURRENTLY require
+func (r *MaintenanceRepository) Exec(ctx context.Context, step *domain.MaintenanceStep) error {
+	_, err := r.db.ExecContext(ctx, step.Statement)
+	return err
+}
+
+// CreateRun marks interrupted runs failed and stores run
+func (r *MaintenanceRepository) CreateRun(ctx context.Context, run *domain.MaintenanceRun) error {
+	options, steps, err := encodeMaintenanceRun(run)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE maintenance_runs
+		SET status = 'failed', error = 'interrupted', finished_at = NOW()
+		WHERE status = 'running'
+	`)
+	if err != nil {
+		return fmt.Errorf("fail interrupted runs: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO maintenance_runs (trigger, options, status, steps, done, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, run.Trigger, options, run.Status, steps, run.Done, run.StartedAt).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("create run: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// UpdateRun stores the progress of run
+func (r *MaintenanceRepository) UpdateRun(ctx context.Context, run *domain.MaintenanceRun) error {
+	_, steps, err := encodeMaintenanceRun(run)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE maintenance_runs
+		SET status = $2, steps = $3, done = $4, error = NULLIF($5, ''), finished_at = $6
+		WHERE id = $1
+	`, run.ID, run.Status, steps, run.Done, run.Error, run.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("update run: %w", err)
+	}
+
+	return nil
+}
+
+// LatestRun returns the latest run, or nil
+func (r *MaintenanceRepository) LatestRun(ctx context.Context) (*domain.MaintenanceRun, error) {
+	var (
+		run            domain.MaintenanceRun
+		options, steps []byte
+		finishedAt     sql.NullTime
+	)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, trigger, options, status, steps, done, COALESCE(error, ''), started_at, finished_at
+		FROM maintenance_runs
+		ORDER BY id DESC
+		LIMIT 1
+	`).Scan(&run.ID, &run.Trigger, &options, &run.Status, &steps, &run.Done, &run.Error, &run.StartedAt, &finishedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get latest run: %w", err)
+	}
+
+	if err := decodeMaintenanceRun(&run, options, steps); err != nil {
+		return nil, err
+	}
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+
+	return &run, nil
+}
+
+func encodeMaintenanceRun(run *domain.MaintenanceRun) (options, steps []byte, err error) {
+	if options, err = json.Marshal(run.Options); err != nil {
+		return nil, nil, fmt.Errorf("encode options: %w", err)
+	}
+	if steps, err = json.Marshal(run.Steps); err != nil {
+		return nil, nil, fmt.Errorf("encode steps: %w", err)
+	}
+	return options, steps, nil
+}
+
+func decodeMaintenanceRun(run *domain.MaintenanceRun, options, steps []byte) error {
+	if err := json.Unmarshal(options, &run.Options); err != nil {
+		return fmt.Errorf("decode options: %w", err)
+	}
+	if err := json.Unmarshal(steps, &run.Steps); err != nil {
+		return fmt.Errorf("decode steps: %w", err)
+	}
+	return nil
+}
+
+var _ domain.MaintenanceRepository = (*MaintenanceRepository)(nil)
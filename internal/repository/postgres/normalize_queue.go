@@ -0,0 +1,207 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// NormalizeQueueRepository implements domain.NormalizeQueueRepository for
+// PostgreSQL
+type NormalizeQueueRepository struct {
+	db *sql.DB
+}
+
+// NewNormalizeQueueRepository creates a new NormalizeQueueRepository
+func NewNormalizeQueueRepository(db *sql.DB) *NormalizeQueueRepository {
+	return &NormalizeQueueRepository{db: db}
+}
+
+// queuedResult is a claimed normalize_queue row
+type queuedResult struct {
+	resultID int64
+	jobID    uuid.UUID
+	attempts int
+}
+
+// Normalize claims up to limit due results and normalizes them: marking a
+// result normalized fires the triggers that write its listing and reviews,
+// after which new places are flagged and addresses and phones normalized
+// for each job. A result whose triggers fail is retried after retryAfter,
+// doubled on each attempt, and given up on after maxAttempts. Results
+// claimed by another manager are skipped.
+func (r *NormalizeQueueRepository) Normalize(ctx context.Context, limit, maxAttempts int, retryAfter time.Duration) (*domain.NormalizeBatch, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	claimed, err := claimQueuedResults(ctx, tx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &domain.NormalizeBatch{Claimed: len(claimed)}
+	if len(claimed) == 0 {
+		return batch, nil
+	}
+
+	done, failed, err := markNormalized(ctx, tx, claimed)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make(map[uuid.UUID]bool)
+	ids := make([]int64, 0, len(done))
+	for _, q := range done {
+		jobs[q.jobID] = true
+		ids = append(ids, q.resultID)
+	}
+	for jobID := range jobs {
+		if err := flagNewPlaces(ctx, tx, jobID); err != nil {
+			return nil, err
+		}
+		if err := normalizeAddresses(ctx, tx, jobID); err != nil {
+			return nil, err
+		}
+		if err := normalizePhones(ctx, tx, jobID); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(ids) > 0 {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM normalize_queue WHERE result_id = ANY($1)`, pq.Array(ids)); err != nil {
+			return nil, fmt.Errorf("failed to dequeue normalized results: %w", err)
+		}
+	}
+	batch.Normalized = len(done)
+
+	for q, cause := range failed {
+		givenUp := q.attempts+1 >= maxAttempts
+		_, err := tx.ExecContext(ctx, `
+			UPDATE normalize_queue
+			SET attempts = attempts + 1,
+				last_error = $2,
+				next_attempt_at = NOW() + make_interval(secs => $3),
+				failed_at = CASE WHEN $4 THEN NOW() END
+			WHERE result_id = $1
+		`, q.resultID, cause, retryAfter.Seconds()*float64(int(1)<<q.attempts), givenUp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reschedule result %d: %w", q.resultID, err)
+		}
+		if givenUp {
+			batch.GivenUp++
+		} else {
+			batch.Retrying++
+		}
+		batch.LastError = cause
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+func claimQueuedResults(ctx context.Context, tx *sql.Tx, limit int) ([]queuedResult, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT result_id, job_id, attempts
+		FROM normalize_queue
+		WHERE failed_at IS NULL AND next_attempt_at <= NOW()
+		ORDER BY result_id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim queued results: %w", err)
+	}
+	defer rows.Close()
+
+	var claimed []queuedResult
+	for rows.Next() {
+		var q queuedResult
+		if err := rows.Scan(&q.resultID, &q.jobID, &q.attempts); err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, q)
+	}
+
+	return claimed, rows.Err()
+}
+
+// markNormalized marks the claimed results normalized, all at once and,
+// when a trigger fails, one by one so one bad result does not hold back
+// the rest. It returns the results marked and why the others failed.
+func markNormalized(ctx context.Context, tx *sql.Tx, claimed []queuedResult) ([]queuedResult, map[queuedResult]string, error) {
+	ids := make([]int64, len(claimed))
+	for i, q := range claimed {
+		ids[i] = q.resultID
+	}
+
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT normalize_batch`); err != nil {
+		return nil, nil, err
+	}
+	_, err := tx.ExecContext(ctx, `UPDATE results SET normalized_at = NOW() WHERE id = ANY($1) AND normalized_at IS NULL`, pq.Array(ids))
+	if err == nil {
+		return claimed, nil, nil
+	}
+	if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT normalize_batch`); err != nil {
+		return nil, nil, err
+	}
+
+	var done []queuedResult
+	failed := make(map[queuedResult]string)
+	for _, q := range claimed {
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT normalize_result`); err != nil {
+			return nil, nil, err
+		}
+		_, err := tx.ExecContext(ctx, `UPDATE results SET normalized_at = NOW() WHERE id = $1 AND normalized_at IS NULL`, q.resultID)
+		if err != nil {
+			if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT normalize_result`); rbErr != nil {
+				return nil, nil, rbErr
+			}
+			failed[q] = err.Error()
+			continue
+		}
+		done = append(done, q)
+	}
+
+	return done, failed, nil
+}
+
+// Lag reports how far normalization is behind ingestion
+func (r *NormalizeQueueRepository) Lag(ctx context.Context) (*domain.NormalizationLag, error) {
+	lag := &domain.NormalizationLag{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE failed_at IS NULL),
+			COUNT(*) FILTER (WHERE failed_at IS NOT NULL),
+			COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(enqueued_at) FILTER (WHERE failed_at IS NULL)), 0)
+		FROM normalize_queue
+	`).Scan(&lag.Pending, &lag.Failed, &lag.OldestPendingSeconds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read normalization lag: %w", err)
+	}
+	return lag, nil
+}
+
+// PendingByJobID counts the results of a job waiting to be normalized,
+// leaving out those given up on
+func (r *NormalizeQueueRepository) PendingByJobID(ctx context.Context, jobID uuid.UUID) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM normalize_queue WHERE job_id = $1 AND failed_at IS NULL
+	`, jobID).Scan(&n)
+	return n, err
+}
+
+// Verify interface compliance at compile time
+var _ domain.NormalizeQueueRepository = (*NormalizeQueueRepository)(nil)
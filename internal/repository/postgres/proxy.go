@@ -3,18 +3,48 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/secrets"
 )
 
+// proxySourceColumns are the columns scanned by scanProxySource
+const proxySourceColumns = `id, url, created_at, updated_at, active, fetched_count, parse_errors,
+	validated, passed, fetch_latency_ms, last_error, last_fetch_at, low_refreshes, disabled_at,
+	disabled_reason`
+
 type ProxyRepository struct {
-	db *sql.DB
+	db   *sql.DB
+	keys *secrets.Keyring
 }
 
 func NewProxyRepository(db *sql.DB) *ProxyRepository {
 	return &ProxyRepository{db: db}
 }
 
+// SetKeyring encrypts source URLs with credentials before they are stored.
+// Without a keyring they are stored in plaintext.
+func (r *ProxyRepository) SetKeyring(keys *secrets.Keyring) {
+	r.keys = keys
+}
+
+// idByURL finds the source stored for url. Encrypted URLs differ on every
+// write, so they are matched by opening each source.
+func (r *ProxyRepository) idByURL(ctx context.Context, url string) (int64, bool, error) {
+	sources, err := r.List(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+
+	for _, s := range sources {
+		if s.URL == url {
+			return s.ID, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
 func (r *ProxyRepository) Create(ctx context.Context, url string) (*domain.ProxySource, error) {
 	query := `
 		INSERT INTO proxy_sources (url, created_at, updated_at)
@@ -23,10 +53,25 @@ func (r *ProxyRepository) Create(ctx context.Context, url string) (*domain.Proxy
 	`
 
 	source := &domain.ProxySource{
-		URL: url,
+		URL:              url,
+		ProxySourceStats: domain.ProxySourceStats{Active: true},
 	}
 
-	err := r.db.QueryRowContext(ctx, query, url).Scan(
+	stored, err := r.keys.SealURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt proxy source url: %w", err)
+	}
+	if stored != url {
+		_, exists, err := r.idByURL(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, fmt.Errorf("proxy source %s already exists", secrets.MaskURL(url))
+		}
+	}
+
+	err = r.db.QueryRowContext(ctx, query, stored).Scan(
 		&source.ID, &source.CreatedAt, &source.UpdatedAt,
 	)
 	if err != nil {
@@ -43,7 +88,7 @@ func (r *ProxyRepository) Delete(ctx context.Context, id int64) error {
 }
 
 func (r *ProxyRepository) List(ctx context.Context) ([]*domain.ProxySource, error) {
-	query := `SELECT id, url, created_at, updated_at FROM proxy_sources ORDER BY created_at DESC`
+	query := `SELECT ` + proxySourceColumns + ` FROM proxy_sources ORDER BY created_at DESC`
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -52,8 +97,8 @@ func (r *ProxyRepository) List(ctx context.Context) ([]*domain.ProxySource, erro
 
 	var sources []*domain.ProxySource
 	for rows.Next() {
-		s := &domain.ProxySource{}
-		if err := rows.Scan(&s.ID, &s.URL, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		s, err := scanProxySource(rows, r.keys)
+		if err != nil {
 			return nil, err
 		}
 		sources = append(sources, s)
@@ -62,11 +107,64 @@ func (r *ProxyRepository) List(ctx context.Context) ([]*domain.ProxySource, erro
 }
 
 func (r *ProxyRepository) GetByID(ctx context.Context, id int64) (*domain.ProxySource, error) {
-	query := `SELECT id, url, created_at, updated_at FROM proxy_sources WHERE id = $1`
+	query := `SELECT ` + proxySourceColumns + ` FROM proxy_sources WHERE id = $1`
+	return scanProxySource(r.db.QueryRowContext(ctx, query, id), r.keys)
+}
+
+func (r *ProxyRepository) UpdateStats(ctx context.Context, url string, stats *domain.ProxySourceStats) error {
+	query := `
+		UPDATE proxy_sources SET
+			active = $2, fetched_count = $3, parse_errors = $4, validated = $5, passed = $6,
+			fetch_latency_ms = $7, last_error = $8, last_fetch_at = $9, low_refreshes = $10,
+			disabled_at = $11, disabled_reason = $12, updated_at = NOW()
+		WHERE url = $1 OR id = $13
+	`
+
+	// A source stored encrypted is updated by id
+	var id int64
+	if r.keys != nil && secrets.HasCredentials(url) {
+		found, ok, err := r.idByURL(ctx, url)
+		if err != nil || !ok {
+			return err
+		}
+		id = found
+	}
+
+	_, err := r.db.ExecContext(ctx, query, url,
+		stats.Active, stats.FetchedCount, stats.ParseErrors, stats.Validated, stats.Passed,
+		stats.FetchLatencyMs, nullString(stats.LastError), stats.LastFetchAt, stats.LowRefreshes,
+		stats.DisabledAt, nullString(stats.DisabledReason), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update proxy source stats: %w", err)
+	}
+	return nil
+}
+
+func scanProxySource(row interface{ Scan(...any) error }, keys *secrets.Keyring) (*domain.ProxySource, error) {
 	s := &domain.ProxySource{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&s.ID, &s.URL, &s.CreatedAt, &s.UpdatedAt)
+	var lastError, disabledReason sql.NullString
+	var lastFetchAt, disabledAt sql.NullTime
+
+	err := row.Scan(&s.ID, &s.URL, &s.CreatedAt, &s.UpdatedAt, &s.Active, &s.FetchedCount,
+		&s.ParseErrors, &s.Validated, &s.Passed, &s.FetchLatencyMs, &lastError, &lastFetchAt,
+		&s.LowRefreshes, &disabledAt, &disabledReason)
 	if err != nil {
 		return nil, err
 	}
+
+	if s.URL, err = keys.Open(s.URL); err != nil {
+		return nil, fmt.Errorf("failed to decrypt url of proxy source %d: %w", s.ID, err)
+	}
+
+	s.LastError = lastError.String
+	s.DisabledReason = disabledReason.String
+	if lastFetchAt.Valid {
+		s.LastFetchAt = &lastFetchAt.Time
+	}
+	if disabledAt.Valid {
+		s.DisabledAt = &disabledAt.Time
+	}
+
 	return s, nil
 }
@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/secrets"
+)
+
+// ProxyEventRepository implements domain.ProxyEventRepository for PostgreSQL
+type ProxyEventRepository struct {
+	db *sql.DB
+}
+
+// NewProxyEventRepository creates a new ProxyEventRepository
+func NewProxyEventRepository(db *sql.DB) *ProxyEventRepository {
+	return &ProxyEventRepository{db: db}
+}
+
+// CreateBatch inserts events in one transaction
+func (r *ProxyEventRepository) CreateBatch(ctx context.Context, events []*domain.ProxyEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO proxy_events (proxy_id, event_type, reason, detail, source_url, job_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`)
+	if err != nil {
+		return fmt.Errorf("prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, event := range events {
+		_, err := stmt.ExecContext(ctx,
+			event.ProxyID,
+			event.EventType,
+			nullString(event.Reason),
+			nullString(event.Detail),
+			nullString(secrets.MaskURL(event.SourceURL)),
+			event.JobID,
+			event.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("insert event of proxy %d: %w", event.ProxyID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListByProxy retrieves the most recent events of a proxy, newest first
+func (r *ProxyEventRepository) ListByProxy(ctx context.Context, proxyID int64, limit int) ([]*domain.ProxyEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, proxy_id, event_type, COALESCE(reason, ''), COALESCE(detail, ''),
+		       COALESCE(source_url, ''), job_id, created_at
+		FROM proxy_events
+		WHERE proxy_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`, proxyID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list proxy events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*domain.ProxyEvent, 0)
+	for rows.Next() {
+		var (
+			event domain.ProxyEvent
+			jobID uuid.NullUUID
+		)
+		err := rows.Scan(&event.ID, &event.ProxyID, &event.EventType, &event.Reason,
+			&event.Detail, &event.SourceURL, &jobID, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan proxy event: %w", err)
+		}
+		if jobID.Valid {
+			event.JobID = &jobID.UUID
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+// Summarize groups events since the given time by type and reason, and
+// by the source the proxy came from
+func (r *ProxyEventRepository) Summarize(ctx context.Context, since time.Time) (*domain.ProxyEventSummary, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT COALESCE(source_url, ''), event_type, COALESCE(reason, ''), COUNT(*)
+		FROM proxy_events
+		WHERE created_at >= $1
+		GROUP BY 1, 2, 3
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("summarize proxy events: %w", err)
+	}
+	defer rows.Close()
+
+	summary := &domain.ProxyEventSummary{
+		Since:    since,
+		ByReason: make([]domain.ProxyEventCount, 0),
+		BySource: make([]*domain.ProxySourceEvents, 0),
+	}
+	byReason := make(map[domain.ProxyEventCount]int)
+	bySource := make(map[string]*domain.ProxySourceEvents)
+
+	for rows.Next() {
+		var (
+			sourceURL, reason string
+			eventType         domain.ProxyEventType
+			count             int
+		)
+		if err := rows.Scan(&sourceURL, &eventType, &reason, &count); err != nil {
+			return nil, fmt.Errorf("scan proxy event summary: %w", err)
+		}
+
+		summary.Total += count
+		byReason[domain.ProxyEventCount{EventType: eventType, Reason: reason}] += count
+
+		source, ok := bySource[sourceURL]
+		if !ok {
+			source = &domain.ProxySourceEvents{SourceURL: sourceURL, ByReason: make(map[string]int)}
+			bySource[sourceURL] = source
+		}
+
+		switch eventType {
+		case domain.ProxyEventFailed:
+			source.Failed += count
+			source.ByReason[reason] += count
+		case domain.ProxyEventQuarantined:
+			source.Quarantined += count
+		case domain.ProxyEventRecovered:
+			source.Recovered += count
+		case domain.ProxyEventDead:
+			source.Dead += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("summarize proxy events: %w", err)
+	}
+
+	for key, count := range byReason {
+		key.Count = count
+		summary.ByReason = append(summary.ByReason, key)
+	}
+	sort.Slice(summary.ByReason, func(i, j int) bool {
+		a, b := summary.ByReason[i], summary.ByReason[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.EventType != b.EventType {
+			return a.EventType < b.EventType
+		}
+		return a.Reason < b.Reason
+	})
+
+	for _, source := range bySource {
+		summary.BySource = append(summary.BySource, source)
+	}
+	sort.Slice(summary.BySource, func(i, j int) bool {
+		a, b := summary.BySource[i], summary.BySource[j]
+		if a.Failed != b.Failed {
+			return a.Failed > b.Failed
+		}
+		return a.SourceURL < b.SourceURL
+	})
+
+	return summary, nil
+}
+
+// Prune deletes all but the newest keep events
+func (r *ProxyEventRepository) Prune(ctx context.Context, keep int) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM proxy_events
+		WHERE id <= (SELECT id FROM proxy_events ORDER BY id DESC OFFSET $1 LIMIT 1)
+	`, keep)
+	if err != nil {
+		return 0, fmt.Errorf("prune proxy events: %w", err)
+	}
+
+	return res.RowsAffected()
+}
@@ -8,11 +8,13 @@ import (
 	"time"
 
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/secrets"
 )
 
 // ProxyListRepository implements domain.ProxyListRepository for PostgreSQL
 type ProxyListRepository struct {
-	db *sql.DB
+	db   *sql.DB
+	keys *secrets.Keyring
 }
 
 // NewProxyListRepository creates a new ProxyListRepository
@@ -20,13 +22,21 @@ func NewProxyListRepository(db *sql.DB) *ProxyListRepository {
 	return &ProxyListRepository{db: db}
 }
 
+// SetKeyring encrypts proxy passwords before they are stored. Without a
+// keyring they are stored in plaintext.
+func (r *ProxyListRepository) SetKeyring(keys *secrets.Keyring) {
+	r.keys = keys
+}
+
 // Upsert creates or updates a proxy (based on IP:port unique constraint)
 func (r *ProxyListRepository) Upsert(ctx context.Context, proxy *domain.Proxy) error {
 	query := `
-		INSERT INTO proxies (ip, port, protocol, country, uptime, response_time, status, source_id, source_url, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		INSERT INTO proxies (ip, port, protocol, username, password, country, uptime, response_time, status, source_id, source_url, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
 		ON CONFLICT (ip, port) DO UPDATE SET
 			protocol = EXCLUDED.protocol,
+			username = COALESCE(EXCLUDED.username, proxies.username),
+			password = COALESCE(EXCLUDED.password, proxies.password),
 			country = COALESCE(EXCLUDED.country, proxies.country),
 			uptime = COALESCE(EXCLUDED.uptime, proxies.uptime),
 			response_time = COALESCE(EXCLUDED.response_time, proxies.response_time),
@@ -35,16 +45,23 @@ func (r *ProxyListRepository) Upsert(ctx context.Context, proxy *domain.Proxy) e
 		RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
+	password, err := r.keys.Seal(proxy.Password)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt proxy password: %w", err)
+	}
+
+	err = r.db.QueryRowContext(ctx, query,
 		proxy.IP,
 		proxy.Port,
 		proxy.Protocol,
+		nullString(proxy.Username),
+		nullString(password),
 		nullString(proxy.Country),
 		nullFloat64(proxy.Uptime),
 		nullFloat64(proxy.ResponseTime),
 		proxy.Status,
 		nullInt64(proxy.SourceID),
-		nullString(proxy.SourceURL),
+		nullString(secrets.MaskURL(proxy.SourceURL)),
 	).Scan(&proxy.ID, &proxy.CreatedAt, &proxy.UpdatedAt)
 
 	return err
@@ -64,9 +81,12 @@ func (r *ProxyListRepository) UpsertBatch(ctx context.Context, proxies []*domain
 	defer tx.Rollback()
 
 	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO proxies (ip, port, protocol, country, uptime, response_time, status, source_url, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		INSERT INTO proxies (ip, port, protocol, username, password, country, uptime, response_time, status, source_url, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
 		ON CONFLICT (ip, port) DO UPDATE SET
+			protocol = EXCLUDED.protocol,
+			username = COALESCE(EXCLUDED.username, proxies.username),
+			password = COALESCE(EXCLUDED.password, proxies.password),
 			country = COALESCE(EXCLUDED.country, proxies.country),
 			uptime = COALESCE(EXCLUDED.uptime, proxies.uptime),
 			response_time = COALESCE(EXCLUDED.response_time, proxies.response_time),
@@ -79,15 +99,22 @@ func (r *ProxyListRepository) UpsertBatch(ctx context.Context, proxies []*domain
 	defer stmt.Close()
 
 	for _, proxy := range proxies {
-		_, err := stmt.ExecContext(ctx,
+		password, err := r.keys.Seal(proxy.Password)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt proxy password: %w", err)
+		}
+
+		_, err = stmt.ExecContext(ctx,
 			proxy.IP,
 			proxy.Port,
 			proxy.Protocol,
+			nullString(proxy.Username),
+			nullString(password),
 			nullString(proxy.Country),
 			nullFloat64(proxy.Uptime),
 			nullFloat64(proxy.ResponseTime),
 			proxy.Status,
-			nullString(proxy.SourceURL),
+			nullString(secrets.MaskURL(proxy.SourceURL)),
 		)
 		if err != nil {
 			return err
@@ -100,7 +127,7 @@ func (r *ProxyListRepository) UpsertBatch(ctx context.Context, proxies []*domain
 // GetByAddress retrieves a proxy by IP:port
 func (r *ProxyListRepository) GetByAddress(ctx context.Context, ip string, port int) (*domain.Proxy, error) {
 	query := `
-		SELECT id, ip, port, protocol, country, uptime, response_time, status,
+		SELECT id, ip, port, protocol, username, password, country, uptime, response_time, status,
 		       last_checked, last_used, fail_count, success_count, source_id, source_url,
 		       created_at, updated_at
 		FROM proxies
@@ -108,13 +135,13 @@ func (r *ProxyListRepository) GetByAddress(ctx context.Context, ip string, port
 	`
 
 	proxy := &domain.Proxy{}
-	var country, sourceURL sql.NullString
+	var username, password, country, sourceURL sql.NullString
 	var uptime, responseTime sql.NullFloat64
 	var lastChecked, lastUsed sql.NullTime
 	var sourceID sql.NullInt64
 
 	err := r.db.QueryRowContext(ctx, query, ip, port).Scan(
-		&proxy.ID, &proxy.IP, &proxy.Port, &proxy.Protocol,
+		&proxy.ID, &proxy.IP, &proxy.Port, &proxy.Protocol, &username, &password,
 		&country, &uptime, &responseTime, &proxy.Status,
 		&lastChecked, &lastUsed, &proxy.FailCount, &proxy.SuccessCount,
 		&sourceID, &sourceURL, &proxy.CreatedAt, &proxy.UpdatedAt,
@@ -123,6 +150,10 @@ func (r *ProxyListRepository) GetByAddress(ctx context.Context, ip string, port
 		return nil, err
 	}
 
+	proxy.Username = username.String
+	if proxy.Password, err = r.keys.Open(password.String); err != nil {
+		return nil, fmt.Errorf("failed to decrypt password of proxy %d: %w", proxy.ID, err)
+	}
 	if country.Valid {
 		proxy.Country = country.String
 	}
@@ -165,7 +196,7 @@ func (r *ProxyListRepository) List(ctx context.Context, params domain.ProxyListP
 		argNum++
 	}
 	if params.Country != "" {
-		conditions = append(conditions, fmt.Sprintf("country = $%d", argNum))
+		conditions = append(conditions, fmt.Sprintf("UPPER(country) = UPPER($%d)", argNum))
 		args = append(args, params.Country)
 		argNum++
 	}
@@ -184,7 +215,7 @@ func (r *ProxyListRepository) List(ctx context.Context, params domain.ProxyListP
 
 	// List query
 	query := fmt.Sprintf(`
-		SELECT id, ip, port, protocol, country, uptime, response_time, status,
+		SELECT id, ip, port, protocol, username, password, country, uptime, response_time, status,
 		       last_checked, last_used, fail_count, success_count, source_id, source_url,
 		       created_at, updated_at
 		FROM proxies
@@ -201,7 +232,7 @@ func (r *ProxyListRepository) List(ctx context.Context, params domain.ProxyListP
 	}
 	defer rows.Close()
 
-	proxies, err := scanProxies(rows)
+	proxies, err := scanProxies(rows, r.keys)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -212,7 +243,7 @@ func (r *ProxyListRepository) List(ctx context.Context, params domain.ProxyListP
 // ListHealthy retrieves all healthy proxies (for Pool)
 func (r *ProxyListRepository) ListHealthy(ctx context.Context) ([]*domain.Proxy, error) {
 	query := `
-		SELECT id, ip, port, protocol, country, uptime, response_time, status,
+		SELECT id, ip, port, protocol, username, password, country, uptime, response_time, status,
 		       last_checked, last_used, fail_count, success_count, source_id, source_url,
 		       created_at, updated_at
 		FROM proxies
@@ -226,7 +257,28 @@ func (r *ProxyListRepository) ListHealthy(ctx context.Context) ([]*domain.Proxy,
 	}
 	defer rows.Close()
 
-	return scanProxies(rows)
+	return scanProxies(rows, r.keys)
+}
+
+// ListLeastRecentlyChecked retrieves proxies with status, never checked ones first
+func (r *ProxyListRepository) ListLeastRecentlyChecked(ctx context.Context, status domain.ProxyStatus, limit int) ([]*domain.Proxy, error) {
+	query := `
+		SELECT id, ip, port, protocol, username, password, country, uptime, response_time, status,
+		       last_checked, last_used, fail_count, success_count, source_id, source_url,
+		       created_at, updated_at
+		FROM proxies
+		WHERE status = $1
+		ORDER BY last_checked ASC NULLS FIRST, id ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanProxies(rows, r.keys)
 }
 
 // UpdateStatus updates the status of a proxy
@@ -276,6 +328,17 @@ func (r *ProxyListRepository) MarkUsed(ctx context.Context, id int64) error {
 	return err
 }
 
+// UpdateMetrics stores the live uptime (percent) and response time (seconds)
+func (r *ProxyListRepository) UpdateMetrics(ctx context.Context, id int64, uptime, responseTime float64) error {
+	query := `
+		UPDATE proxies
+		SET uptime = $2, response_time = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, uptime, responseTime)
+	return err
+}
+
 // DeleteDead removes all dead proxies
 func (r *ProxyListRepository) DeleteDead(ctx context.Context) (int, error) {
 	result, err := r.db.ExecContext(ctx, `DELETE FROM proxies WHERE status = 'dead'`)
@@ -307,22 +370,41 @@ func (r *ProxyListRepository) GetStats(ctx context.Context) (*domain.ProxyStats,
 		return nil, err
 	}
 
+	rows, err := r.db.QueryContext(ctx, `SELECT protocol, COUNT(*) FROM proxies GROUP BY protocol`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats.ByProtocol = make(map[string]int)
+	for rows.Next() {
+		var protocol string
+		var count int
+		if err := rows.Scan(&protocol, &count); err != nil {
+			return nil, err
+		}
+		stats.ByProtocol[protocol] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
 	return stats, nil
 }
 
-// Helper function to scan proxies from rows
-func scanProxies(rows *sql.Rows) ([]*domain.Proxy, error) {
+// Helper function to scan proxies from rows, opening passwords with keys
+func scanProxies(rows *sql.Rows, keys *secrets.Keyring) ([]*domain.Proxy, error) {
 	var proxies []*domain.Proxy
 
 	for rows.Next() {
 		proxy := &domain.Proxy{}
-		var country, sourceURL sql.NullString
+		var username, password, country, sourceURL sql.NullString
 		var uptime, responseTime sql.NullFloat64
 		var lastChecked, lastUsed sql.NullTime
 		var sourceID sql.NullInt64
 
 		err := rows.Scan(
-			&proxy.ID, &proxy.IP, &proxy.Port, &proxy.Protocol,
+			&proxy.ID, &proxy.IP, &proxy.Port, &proxy.Protocol, &username, &password,
 			&country, &uptime, &responseTime, &proxy.Status,
 			&lastChecked, &lastUsed, &proxy.FailCount, &proxy.SuccessCount,
 			&sourceID, &sourceURL, &proxy.CreatedAt, &proxy.UpdatedAt,
@@ -331,6 +413,10 @@ func scanProxies(rows *sql.Rows) ([]*domain.Proxy, error) {
 			return nil, err
 		}
 
+		proxy.Username = username.String
+		if proxy.Password, err = keys.Open(password.String); err != nil {
+			return nil, fmt.Errorf("failed to decrypt password of proxy %d: %w", proxy.ID, err)
+		}
 		if country.Valid {
 			proxy.Country = country.String
 		}
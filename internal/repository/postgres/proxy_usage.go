@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// ProxyUsageRepository implements domain.ProxyUsageRepository for PostgreSQL
+type ProxyUsageRepository struct {
+	db *sql.DB
+}
+
+// NewProxyUsageRepository creates a new ProxyUsageRepository
+func NewProxyUsageRepository(db *sql.DB) *ProxyUsageRepository {
+	return &ProxyUsageRepository{db: db}
+}
+
+// Add adds the counts to the rows of their hour in one transaction
+func (r *ProxyUsageRepository) Add(ctx context.Context, usage []*domain.ProxyUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The conflict target matches idx_proxy_usage_key
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO proxy_usage (bucket, proxy_id, job_id, bytes_up, bytes_down, requests)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (bucket, proxy_id, COALESCE(job_id, '00000000-0000-0000-0000-000000000000'::uuid))
+		DO UPDATE SET
+			bytes_up = proxy_usage.bytes_up + EXCLUDED.bytes_up,
+			bytes_down = proxy_usage.bytes_down + EXCLUDED.bytes_down,
+			requests = proxy_usage.requests + EXCLUDED.requests,
+			updated_at = NOW()
+	`)
+	if err != nil {
+		return fmt.Errorf("prepare upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, u := range usage {
+		_, err := stmt.ExecContext(ctx,
+			u.Bucket.UTC().Truncate(time.Hour), u.ProxyID, u.JobID,
+			u.BytesUp, u.BytesDown, u.Requests,
+		)
+		if err != nil {
+			return fmt.Errorf("add usage of proxy %d: %w", u.ProxyID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Summarize sums the usage since the given time, rounded down to the hour,
+// per proxy or per job, most bytes first
+func (r *ProxyUsageRepository) Summarize(ctx context.Context, since time.Time, groupBy string) (*domain.ProxyUsageReport, error) {
+	var query string
+	switch groupBy {
+	case domain.ProxyUsageByProxy:
+		query = `
+			SELECT u.proxy_id, COALESCE(p.ip || ':' || p.port, ''),
+			       SUM(u.bytes_up), SUM(u.bytes_down), SUM(u.requests)
+			FROM proxy_usage u
+			LEFT JOIN proxies p ON p.id = u.proxy_id
+			WHERE u.bucket >= $1
+			GROUP BY 1, 2
+			ORDER BY SUM(u.bytes_up + u.bytes_down) DESC, 1
+		`
+	case domain.ProxyUsageByJob:
+		query = `
+			SELECT u.job_id, COALESCE(j.name, ''),
+			       SUM(u.bytes_up), SUM(u.bytes_down), SUM(u.requests)
+			FROM proxy_usage u
+			LEFT JOIN jobs_queue j ON j.id = u.job_id
+			WHERE u.bucket >= $1
+			GROUP BY 1, 2
+			ORDER BY SUM(u.bytes_up + u.bytes_down) DESC, 1
+		`
+	default:
+		return nil, fmt.Errorf("unknown proxy usage grouping %q", groupBy)
+	}
+
+	since = since.UTC().Truncate(time.Hour)
+
+	rows, err := r.db.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("summarize proxy usage: %w", err)
+	}
+	defer rows.Close()
+
+	report := &domain.ProxyUsageReport{
+		GroupBy: groupBy,
+		Since:   since,
+		Groups:  make([]*domain.ProxyUsageGroup, 0),
+	}
+
+	for rows.Next() {
+		var (
+			group   domain.ProxyUsageGroup
+			proxyID int64
+			jobID   uuid.NullUUID
+			name    string
+		)
+		key := any(&jobID)
+		if groupBy == domain.ProxyUsageByProxy {
+			key = &proxyID
+		}
+		if err := rows.Scan(key, &name, &group.BytesUp, &group.BytesDown, &group.Requests); err != nil {
+			return nil, fmt.Errorf("scan proxy usage: %w", err)
+		}
+
+		if groupBy == domain.ProxyUsageByProxy {
+			group.ProxyID, group.Proxy = &proxyID, name
+		} else {
+			if jobID.Valid {
+				group.JobID = &jobID.UUID
+			}
+			group.JobName = name
+		}
+
+		report.Total.Add(group.ProxyTraffic)
+		report.Groups = append(report.Groups, &group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("summarize proxy usage: %w", err)
+	}
+
+	return report, nil
+}
+
+// JobTotal sums the usage of a job, nil when none was recorded
+func (r *ProxyUsageRepository) JobTotal(ctx context.Context, jobID uuid.UUID) (*domain.ProxyTraffic, error) {
+	var (
+		rows    int
+		traffic domain.ProxyTraffic
+	)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(bytes_up), 0), COALESCE(SUM(bytes_down), 0), COALESCE(SUM(requests), 0)
+		FROM proxy_usage
+		WHERE job_id IN (SELECT id FROM jobs_queue WHERE id = $1 OR parent_job_id = $1)
+	`, jobID).Scan(&rows, &traffic.BytesUp, &traffic.BytesDown, &traffic.Requests)
+	if err != nil {
+		return nil, fmt.Errorf("sum proxy usage of job: %w", err)
+	}
+	if rows == 0 {
+		return nil, nil
+	}
+
+	return &traffic, nil
+}
+
+var _ domain.ProxyUsageRepository = (*ProxyUsageRepository)(nil)
@@ -0,0 +1,347 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// RenormalizeRepository rewrites business listings from their stored
+// results for re-normalization runs
+type RenormalizeRepository struct {
+	db *sql.DB
+}
+
+// NewRenormalizeRepository creates a new repository
+func NewRenormalizeRepository(db *sql.DB) *RenormalizeRepository {
+	return &RenormalizeRepository{db: db}
+}
+
+const renormalizeRunColumns = `
+	id, job_id, status, max_result_id, last_result_id, total, processed, failed,
+	COALESCE(error, ''), started_at, updated_at, finished_at`
+
+func scanRenormalizeRun(row interface{ Scan(...any) error }) (*domain.RenormalizeRun, error) {
+	var (
+		run        domain.RenormalizeRun
+		jobID      uuid.NullUUID
+		finishedAt sql.NullTime
+	)
+
+	err := row.Scan(&run.ID, &jobID, &run.Status, &run.MaxResultID, &run.LastResultID,
+		&run.Total, &run.Processed, &run.Failed, &run.Error, &run.StartedAt, &run.UpdatedAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if jobID.Valid {
+		run.JobID = &jobID.UUID
+	}
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+
+	return &run, nil
+}
+
+// StartRun resumes the latest unfinished run of the scope or creates one
+func (r *RenormalizeRepository) StartRun(ctx context.Context, jobID *uuid.UUID) (*domain.RenormalizeRun, error) {
+	run, err := scanRenormalizeRun(r.db.QueryRowContext(ctx, `
+		UPDATE renormalize_runs
+		SET status = 'running', error = NULL, updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM renormalize_runs
+			WHERE job_id IS NOT DISTINCT FROM $1 AND status <> 'completed'
+			ORDER BY id DESC
+			LIMIT 1
+		)
+		RETURNING `+renormalizeRunColumns, jobID))
+	if err == nil {
+		return run, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("resume run: %w", err)
+	}
+
+	run, err = scanRenormalizeRun(r.db.QueryRowContext(ctx, `
+		INSERT INTO renormalize_runs (job_id, max_result_id, total)
+		SELECT $1::uuid, COALESCE(MAX(id), 0), COUNT(*)
+		FROM results
+		WHERE $1::uuid IS NULL OR job_id = $1
+		RETURNING `+renormalizeRunColumns, jobID))
+	if err != nil {
+		return nil, fmt.Errorf("create run: %w", err)
+	}
+
+	return run, nil
+}
+
+// LatestRun returns the latest run of the scope, or nil
+func (r *RenormalizeRepository) LatestRun(ctx context.Context, jobID *uuid.UUID) (*domain.RenormalizeRun, error) {
+	run, err := scanRenormalizeRun(r.db.QueryRowContext(ctx, `
+		SELECT `+renormalizeRunColumns+`
+		FROM renormalize_runs
+		WHERE job_id IS NOT DISTINCT FROM $1
+		ORDER BY id DESC
+		LIMIT 1
+	`, jobID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get run: %w", err)
+	}
+
+	return run, nil
+}
+
+// NextResults returns the next results of the run. Listings merged into
+// another one are not brought back.
+func (r *RenormalizeRepository) NextResults(ctx context.Context, run *domain.RenormalizeRun, limit int) ([]domain.StoredResult, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.id, r.job_id, r.data
+		FROM results r
+		WHERE r.id > $2 AND r.id <= $3
+		  AND ($1::uuid IS NULL OR r.job_id = $1)
+		  AND NOT EXISTS (
+			SELECT 1 FROM business_listing_merges m
+			WHERE m.merged_listings @> jsonb_build_array(jsonb_build_object('result_id', r.id))
+		  )
+		ORDER BY r.id
+		LIMIT $4
+	`, run.JobID, run.LastResultID, run.MaxResultID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []domain.StoredResult
+	for rows.Next() {
+		var (
+			res   domain.StoredResult
+			jobID uuid.NullUUID
+		)
+		if err := rows.Scan(&res.ID, &jobID, &res.Data); err != nil {
+			return nil, fmt.Errorf("scan result: %w", err)
+		}
+		if jobID.Valid {
+			res.JobID = &jobID.UUID
+		}
+		results = append(results, res)
+	}
+
+	return results, rows.Err()
+}
+
+// upsertRenormalizedListing writes every column populate_normalized_listings()
+// fills, overwriting the existing listing of the result. The address
+// components come back from complete_address only, so the listing is
+// queued for address parsing again. Setting result_id has
+// populate_listing_result_fields() copy the other fields again.
+const upsertRenormalizedListing = `
+	INSERT INTO business_listings (
+		result_id, job_id, place_id, cid, data_id, title, category, categories,
+		address, phone, website, latitude, longitude, plus_code, timezone,
+		address_street, address_city, address_state, address_postal_code, address_country,
+		review_count, review_rating, status, price_range, description, link, reviews_link,
+		opening_hours, hours_parsed
+	)
+	SELECT $1, $2, d ->> 'place_id', d ->> 'cid', d ->> 'data_id',
+		COALESCE(NULLIF(d ->> 'title', ''), 'Unknown'), d ->> 'category',
+		CASE WHEN jsonb_typeof(d -> 'categories') = 'array'
+		THEN ARRAY(SELECT jsonb_array_elements_text(d -> 'categories')) END,
+		d ->> 'address', d ->> 'phone', d ->> 'web_site',
+		(d ->> 'latitude')::DOUBLE PRECISION, (d ->> 'longitude')::DOUBLE PRECISION,
+		d ->> 'plus_code', d ->> 'timezone',
+		d -> 'complete_address' ->> 'street', d -> 'complete_address' ->> 'city',
+		d -> 'complete_address' ->> 'state', d -> 'complete_address' ->> 'postal_code',
+		d -> 'complete_address' ->> 'country',
+		COALESCE((d ->> 'review_count')::INTEGER, 0), (d ->> 'review_rating')::NUMERIC(3,1),
+		d ->> 'status', d ->> 'price_range', d ->> 'description',
+		d ->> 'link', d ->> 'reviews_link',
+		CASE WHEN jsonb_typeof(d -> 'open_hours_parsed') = 'object' THEN d -> 'open_hours_parsed' END,
+		CASE WHEN jsonb_typeof(d -> 'open_hours_parsed') = 'object' THEN true
+		WHEN d ? 'open_hours_parse_error' THEN false END
+	FROM (SELECT $3::jsonb AS d) src
+	ON CONFLICT (result_id) DO UPDATE SET
+		result_id = EXCLUDED.result_id,
+		job_id = EXCLUDED.job_id, place_id = EXCLUDED.place_id, cid = EXCLUDED.cid,
+		data_id = EXCLUDED.data_id, title = EXCLUDED.title, category = EXCLUDED.category,
+		categories = EXCLUDED.categories, address = EXCLUDED.address, phone = EXCLUDED.phone,
+		website = EXCLUDED.website, latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude,
+		plus_code = EXCLUDED.plus_code, timezone = EXCLUDED.timezone,
+		address_street = EXCLUDED.address_street, address_city = EXCLUDED.address_city,
+		address_state = EXCLUDED.address_state, address_postal_code = EXCLUDED.address_postal_code,
+		address_country = EXCLUDED.address_country, address_number = NULL, address_parsed = NULL,
+		review_count = EXCLUDED.review_count,
+		review_rating = EXCLUDED.review_rating, status = EXCLUDED.status,
+		price_range = EXCLUDED.price_range, description = EXCLUDED.description,
+		link = EXCLUDED.link, reviews_link = EXCLUDED.reviews_link,
+		opening_hours = EXCLUDED.opening_hours, hours_parsed = EXCLUDED.hours_parsed,
+		data_completeness = EXCLUDED.data_completeness, updated_at = NOW()
+	RETURNING id`
+
+// upsertRenormalizedEmails links the emails of a result to its listing.
+// New emails take the validation stored with the result the way
+// populate_normalized_listings() does; known emails keep their state and
+// are not counted as seen again.
+const upsertRenormalizedEmails = `
+	WITH src AS (
+		SELECT $2::jsonb AS d
+	), validations AS (
+		SELECT DISTINCT ON (lower(trim(v ->> 'email'))) lower(trim(v ->> 'email')) AS email, v
+		FROM src, jsonb_array_elements(CASE WHEN jsonb_typeof(d -> 'email_validations') = 'array'
+			THEN d -> 'email_validations' ELSE '[]'::jsonb END) v
+	), addresses AS (
+		SELECT DISTINCT ON (email) email, pos
+		FROM (
+			SELECT lower(trim(e)) AS email, (n - 1)::INTEGER AS pos
+			FROM src, jsonb_array_elements_text(CASE WHEN jsonb_typeof(d -> 'emails') = 'array'
+				THEN d -> 'emails' ELSE '[]'::jsonb END) WITH ORDINALITY AS t(e, n)
+		) a
+		WHERE email <> ''
+		ORDER BY email, pos
+	), upserted AS (
+		INSERT INTO emails (email, validation_status, local_validation_passed, local_validated_at,
+			api_status, api_score, api_deliverable, api_disposable, api_role_account,
+			api_free_email, api_catch_all, api_reason, api_validated_at)
+		SELECT a.email,
+			CASE
+				WHEN v.v IS NULL THEN 'local_valid'
+				WHEN (v.v ->> 'status') = 'api_error' THEN 'api_error'
+				WHEN (v.v ->> 'status') = 'valid'
+					AND (v.v ->> 'deliverable')::BOOLEAN = true
+					AND (v.v ->> 'disposable')::BOOLEAN = false
+					AND (v.v ->> 'role_account')::BOOLEAN = false
+					AND COALESCE((v.v ->> 'score')::NUMERIC, 0) >= 70
+				THEN 'api_valid'
+				ELSE 'api_invalid'
+			END,
+			true, NOW(),
+			v.v ->> 'status', (v.v ->> 'score')::NUMERIC,
+			(v.v ->> 'deliverable')::BOOLEAN, (v.v ->> 'disposable')::BOOLEAN,
+			(v.v ->> 'role_account')::BOOLEAN, (v.v ->> 'free_email')::BOOLEAN,
+			(v.v ->> 'catch_all')::BOOLEAN, v.v ->> 'reason',
+			CASE WHEN v.v IS NOT NULL THEN NOW() END
+		FROM addresses a
+		LEFT JOIN validations v ON v.email = a.email
+		ON CONFLICT (email) DO UPDATE SET email = EXCLUDED.email
+		RETURNING id, email
+	)
+	INSERT INTO business_emails (business_listing_id, email_id, position, source)
+	SELECT $1, u.id, a.pos, 'website'
+	FROM upserted u
+	JOIN addresses a ON a.email = u.email
+	ON CONFLICT (business_listing_id, email_id) DO NOTHING`
+
+// SaveBatch writes a batch of a run. Each result gets a savepoint, so one
+// the database refuses becomes a failure instead of failing the batch.
+func (r *RenormalizeRepository) SaveBatch(ctx context.Context, run *domain.RenormalizeRun, results []domain.RenormalizedResult, failures []domain.RenormalizeFailure, lastResultID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	processed := 0
+	jobs := make(map[uuid.UUID]bool)
+
+	for _, res := range results {
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT renormalize_result`); err != nil {
+			return fmt.Errorf("savepoint: %w", err)
+		}
+
+		err := saveRenormalizedResult(ctx, tx, res)
+		if err != nil {
+			if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT renormalize_result`); rbErr != nil {
+				return fmt.Errorf("rollback to savepoint: %w", rbErr)
+			}
+			failures = append(failures, domain.RenormalizeFailure{ResultID: res.ResultID, JobID: res.JobID, Error: err.Error()})
+			continue
+		}
+
+		processed++
+		if res.JobID != nil {
+			jobs[*res.JobID] = true
+		}
+	}
+
+	for _, f := range failures {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO renormalize_failures (run_id, result_id, job_id, error)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (run_id, result_id) DO UPDATE SET error = EXCLUDED.error, created_at = NOW()
+		`, run.ID, f.ResultID, f.JobID, f.Error)
+		if err != nil {
+			return fmt.Errorf("record failure: %w", err)
+		}
+	}
+
+	// Rewritten phones were reset by trg_reset_listing_phone_e164; this
+	// also parses listings stored before phone normalization and address
+	// parsing existed
+	for jobID := range jobs {
+		if err := normalizeAddresses(ctx, tx, jobID); err != nil {
+			return err
+		}
+		if err := normalizePhones(ctx, tx, jobID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE renormalize_runs
+		SET last_result_id = $2, processed = processed + $3, failed = failed + $4, updated_at = NOW()
+		WHERE id = $1
+	`, run.ID, lastResultID, processed, len(failures))
+	if err != nil {
+		return fmt.Errorf("update run: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit batch: %w", err)
+	}
+
+	run.LastResultID = lastResultID
+	run.Processed += processed
+	run.Failed += len(failures)
+
+	return nil
+}
+
+func saveRenormalizedResult(ctx context.Context, tx *sql.Tx, res domain.RenormalizedResult) error {
+	var listingID int64
+	if err := tx.QueryRowContext(ctx, upsertRenormalizedListing, res.ResultID, res.JobID, string(res.Data)).Scan(&listingID); err != nil {
+		return fmt.Errorf("upsert listing: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, upsertRenormalizedEmails, listingID, string(res.Data)); err != nil {
+		return fmt.Errorf("upsert emails: %w", err)
+	}
+
+	return nil
+}
+
+// FinishRun ends the run
+func (r *RenormalizeRepository) FinishRun(ctx context.Context, run *domain.RenormalizeRun, status, errMsg string) error {
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE renormalize_runs
+		SET status = $2, error = NULLIF($3, ''), updated_at = NOW(), finished_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at, finished_at
+	`, run.ID, status, errMsg).Scan(&run.UpdatedAt, &run.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("finish run: %w", err)
+	}
+
+	run.Status = status
+	run.Error = errMsg
+
+	return nil
+}
+
+var _ domain.RenormalizeRepository = (*RenormalizeRepository)(nil)
@@ -3,13 +3,18 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/phonenumber"
+	"github.com/sadewadee/google-scraper/internal/postaladdress"
 )
 
 const (
@@ -30,36 +35,415 @@ func NewResultRepository(db *sql.DB) *ResultRepository {
 	return &ResultRepository{db: db}
 }
 
-// Create creates a new result
+// Create creates a new result. It goes through CreateBatch so the result
+// is accounted like any other.
 func (r *ResultRepository) Create(ctx context.Context, jobID uuid.UUID, data []byte) error {
-	query := `INSERT INTO results (job_id, data) VALUES ($1, $2)`
-	_, err := r.db.ExecContext(ctx, query, jobID, data)
-	return err
+	return r.CreateBatch(ctx, jobID, uuid.Nil, [][]byte{data})
+}
+
+// CreateBatch creates multiple results in a batch and adds them to the usage
+// of the job's tenant in the same transaction. Once the tenant's monthly
+// place quota is used up, the rest of the batch is dropped and a
+// *domain.QuotaExceededError is returned. The batch ID is recorded in the
+// same transaction, so a batch is stored at most once.
+//
+// The results are copied in with COPY and queued for the normalizer, so
+// the batch returns once they are durable rather than once their listings
+// are written; see NormalizeQueueRepository.
+func (r *ResultRepository) CreateBatch(ctx context.Context, jobID, batchID uuid.UUID, data [][]byte) error {
+	return r.createBatch(ctx, jobID, batchID, data, nil)
 }
 
-// CreateBatch creates multiple results in a batch
-func (r *ResultRepository) CreateBatch(ctx context.Context, jobID uuid.UUID, data [][]byte) error {
+// ImportBatch is CreateBatch for results moved from another database,
+// which keep when they were stored: createdAt holds the time of each
+// result.
+func (r *ResultRepository) ImportBatch(ctx context.Context, jobID, batchID uuid.UUID, data [][]byte, createdAt []time.Time) error {
+	if len(createdAt) != len(data) {
+		return fmt.Errorf("import batch: %d results but %d timestamps", len(data), len(createdAt))
+	}
+	return r.createBatch(ctx, jobID, batchID, data, createdAt)
+}
+
+func (r *ResultRepository) createBatch(ctx context.Context, jobID, batchID uuid.UUID, data [][]byte, createdAt []time.Time) error {
 	if len(data) == 0 {
 		return nil
 	}
 
-	// Build batch insert query
-	values := make([]string, 0, len(data))
-	args := make([]interface{}, 0, len(data)+1)
-	args = append(args, jobID)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if batchID != uuid.Nil {
+		// A concurrent retry of the batch waits here until this one commits
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO result_batches (batch_id, job_id, results) VALUES ($1, $2, $3)
+			ON CONFLICT (batch_id) DO NOTHING
+		`, batchID, jobID, len(data))
+		if err != nil {
+			return fmt.Errorf("record result batch: %w", err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return domain.ErrBatchAlreadyStored
+		}
+	}
+
+	usage, err := lockJobUsage(ctx, tx, jobID)
+	if err != nil {
+		return err
+	}
+
+	var quotaErr error
+	if usage != nil {
+		if quotaErr = usage.Check(); quotaErr != nil {
+			return quotaErr
+		}
+
+		if remaining := usage.Remaining(); remaining >= 0 && len(data) > remaining {
+			log.Printf("[ResultRepo] Job %s: tenant %s reached its quota, dropping %d of %d results",
+				jobID, usage.Tenant, len(data)-remaining, len(data))
+
+			data = data[:remaining]
+			if createdAt != nil {
+				createdAt = createdAt[:remaining]
+			}
+			usage.Used += remaining
+			quotaErr = usage.Check()
+		}
+	}
+
+	// Usage counts the places a backfill upgrades too
+	stored := data
+	if createdAt == nil {
+		if data, err = upgradePartialResults(ctx, tx, jobID, data); err != nil {
+			return err
+		}
+	}
+
+	if err := copyResults(ctx, tx, jobID, data, createdAt); err != nil {
+		return err
+	}
+
+	// Results of the job still waiting from an earlier batch are queued already
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO normalize_queue (result_id, job_id)
+		SELECT id, job_id FROM results
+		WHERE job_id = $1 AND normalized_at IS NULL
+		ON CONFLICT (result_id) DO NOTHING
+	`, jobID)
+	if err != nil {
+		return fmt.Errorf("queue results for normalization: %w", err)
+	}
+
+	if usage != nil {
+		if err := recordUsage(ctx, tx, usage.Tenant, int64(len(stored)), countEmailValidations(stored)); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit results: %w", err)
+	}
+
+	return quotaErr
+}
+
+// copyResults copies results in unnormalized. Their data goes as text,
+// which COPY reads into jsonb; []byte would be sent as bytea. Without
+// createdAt the results are stamped with the current time.
+func copyResults(ctx context.Context, tx *sql.Tx, jobID uuid.UUID, data [][]byte, createdAt []time.Time) error {
+	columns := []string{"job_id", "data", "normalized_at"}
+	if createdAt != nil {
+		columns = append(columns, "created_at")
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("results", columns...))
+	if err != nil {
+		return fmt.Errorf("prepare copy: %w", err)
+	}
+	defer stmt.Close()
 
 	for i, d := range data {
-		values = append(values, fmt.Sprintf("($1, $%d)", i+2))
-		args = append(args, d)
+		args := []any{jobID, string(d), nil}
+		if createdAt != nil {
+			args = append(args, createdAt[i])
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return fmt.Errorf("copy result: %w", err)
+		}
 	}
 
-	query := fmt.Sprintf(`
-		INSERT INTO results (job_id, data) VALUES %s
-		ON CONFLICT DO NOTHING
-	`, strings.Join(values, ", "))
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("copy results: %w", err)
+	}
 
-	_, err := r.db.ExecContext(ctx, query, args...)
-	return err
+	return nil
+}
+
+// upgradePartialResults stores the results of a backfill job over the
+// partial results of the job it backfills, matched by data ID, CID or
+// link. The upgraded listings are written at once and the results queued
+// for the normalizer, which adds their reviews and parses their addresses
+// and phones. It returns the results that matched none, stored as the
+// backfill job's own; for other jobs that is all of data.
+func upgradePartialResults(ctx context.Context, tx *sql.Tx, jobID uuid.UUID, data [][]byte) ([][]byte, error) {
+	var backfillOf uuid.NullUUID
+	err := tx.QueryRowContext(ctx, `SELECT backfill_of FROM jobs_queue WHERE id = $1`, jobID).Scan(&backfillOf)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !backfillOf.Valid) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get job backfill_of: %w", err)
+	}
+
+	var rest [][]byte
+	for _, d := range data {
+		var place struct {
+			DataID       string `json:"data_id"`
+			Cid          string `json:"cid"`
+			Link         string `json:"link"`
+			Completeness string `json:"data_completeness"`
+		}
+		if err := json.Unmarshal(d, &place); err != nil || place.Completeness == domain.CompletenessPartial {
+			rest = append(rest, d)
+			continue
+		}
+
+		var resultID int64
+		err := tx.QueryRowContext(ctx, `
+			UPDATE results SET data = $5::jsonb, normalized_at = NULL, backfilled_by = $6
+			WHERE id = (
+				SELECT id FROM results
+				WHERE `+jobScope("job_id", "$1")+` AND data ->> 'data_completeness' = 'partial'
+					AND ((data ->> 'data_id' = $2 AND $2 <> '') OR (data ->> 'cid' = $3 AND $3 <> '') OR data ->> 'link' = $4)
+				ORDER BY id
+				LIMIT 1
+				FOR UPDATE
+			)
+			RETURNING id
+		`, backfillOf.UUID, place.DataID, place.Cid, place.Link, string(d), jobID).Scan(&resultID)
+		if errors.Is(err, sql.ErrNoRows) {
+			rest = append(rest, d)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("upgrade partial result: %w", err)
+		}
+
+		if err := saveRenormalizedResult(ctx, tx, domain.RenormalizedResult{ResultID: resultID, JobID: &backfillOf.UUID, Data: d}); err != nil {
+			return nil, err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO normalize_queue (result_id, job_id) VALUES ($1, $2)
+			ON CONFLICT (result_id) DO NOTHING
+		`, resultID, backfillOf.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("queue upgraded result for normalization: %w", err)
+		}
+	}
+
+	return rest, nil
+}
+
+// flagNewPlaces marks the listings an incremental job just ingested as new
+// or already known, with one statement for the whole batch. A place is known
+// when an earlier listing of any job has its place ID; first_seen_job_id
+// names the job of the earliest one. Incremental batches are flagged one at
+// a time, so two jobs ingesting the same new place concurrently do not both
+// count it as new.
+func flagNewPlaces(ctx context.Context, tx *sql.Tx, jobID uuid.UUID) error {
+	var incremental bool
+	err := tx.QueryRowContext(ctx, `SELECT incremental FROM jobs_queue WHERE id = $1`, jobID).Scan(&incremental)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !incremental) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get job incremental: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('incremental_ingest'))`); err != nil {
+		return fmt.Errorf("lock incremental ingest: %w", err)
+	}
+
+	// Unflagged listings of the job are the ones just inserted. Listings of
+	// the same batch count as earlier when their ID is lower, so a place
+	// submitted twice is new once.
+	_, err = tx.ExecContext(ctx, `
+		WITH flagged AS (
+			UPDATE business_listings bl
+			SET is_new = prior.id IS NULL,
+				first_seen_job_id = CASE
+					WHEN prior.id IS NULL THEN $1::uuid
+					ELSE COALESCE(prior.first_seen_job_id, prior.job_id)
+				END
+			FROM business_listings f
+			LEFT JOIN LATERAL (
+				SELECT o.id, o.job_id, o.first_seen_job_id
+				FROM business_listings o
+				WHERE o.place_id = f.place_id
+				  AND o.id <> f.id
+				  AND (o.is_new IS NOT NULL OR o.job_id IS DISTINCT FROM $1::uuid OR o.id < f.id)
+				ORDER BY o.id
+				LIMIT 1
+			) prior ON TRUE
+			WHERE bl.id = f.id AND f.job_id = $1::uuid AND f.is_new IS NULL
+			RETURNING bl.is_new
+		)
+		UPDATE jobs_queue SET
+			new_places = new_places + (SELECT COUNT(*) FROM flagged WHERE is_new),
+			known_places = known_places + (SELECT COUNT(*) FROM flagged WHERE NOT is_new)
+		WHERE id = $1
+	`, jobID)
+	if err != nil {
+		return fmt.Errorf("flag new places: %w", err)
+	}
+
+	return nil
+}
+
+// normalizeAddresses splits the addresses of the job's listings that were
+// not parsed yet into their components. Those complete_address already
+// provided are kept; the parse fills the others. The country of an address
+// that doesn't name one is read from the listing's coordinates and the
+// job's language.
+func normalizeAddresses(ctx context.Context, tx *sql.Tx, jobID uuid.UUID) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT bl.id, bl.address, COALESCE(bl.address_country, ''), bl.latitude, bl.longitude, COALESCE(j.lang, '')
+		FROM business_listings bl
+		LEFT JOIN jobs_queue j ON j.id = bl.job_id
+		WHERE bl.job_id = $1 AND bl.address_parsed IS NULL AND bl.address <> ''
+	`, jobID)
+	if err != nil {
+		return fmt.Errorf("list unparsed addresses: %w", err)
+	}
+
+	var (
+		ids                                             []int64
+		streets, numbers, postcodes, cities, states, cc []string
+		located                                         []bool
+	)
+	for rows.Next() {
+		var (
+			id                     int64
+			address, country, lang string
+			lat, lon               sql.NullFloat64
+		)
+		if err := rows.Scan(&id, &address, &country, &lat, &lon, &lang); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan unparsed address: %w", err)
+		}
+
+		c := postaladdress.Parse(address, postaladdress.Hint{
+			Country:   country,
+			Lang:      lang,
+			Lat:       lat.Float64,
+			Lon:       lon.Float64,
+			HasCoords: lat.Valid && lon.Valid,
+		})
+		ids = append(ids, id)
+		streets = append(streets, c.Street)
+		numbers = append(numbers, c.Number)
+		postcodes = append(postcodes, c.PostalCode)
+		cities = append(cities, c.City)
+		states = append(states, c.State)
+		cc = append(cc, c.Country)
+		located = append(located, c.Located())
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("list unparsed addresses: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE business_listings bl SET
+			address_street = COALESCE(NULLIF(bl.address_street, ''), NULLIF(u.street, '')),
+			address_number = NULLIF(u.number, ''),
+			address_postal_code = COALESCE(NULLIF(bl.address_postal_code, ''), NULLIF(u.postcode, '')),
+			address_city = COALESCE(NULLIF(bl.address_city, ''), NULLIF(u.city, '')),
+			address_state = COALESCE(NULLIF(bl.address_state, ''), NULLIF(u.state, '')),
+			address_country = COALESCE(NULLIF(bl.address_country, ''), NULLIF(u.country, '')),
+			address_parsed = u.located
+		FROM unnest($1::bigint[], $2::text[], $3::text[], $4::text[], $5::text[], $6::text[], $7::text[], $8::boolean[])
+			AS u(id, street, number, postcode, city, state, country, located)
+		WHERE bl.id = u.id
+	`, pq.Array(ids), pq.Array(streets), pq.Array(numbers), pq.Array(postcodes),
+		pq.Array(cities), pq.Array(states), pq.Array(cc), pq.Array(located))
+	if err != nil {
+		return fmt.Errorf("store address components: %w", err)
+	}
+
+	return nil
+}
+
+// normalizePhones stores the E.164 form of the phone numbers of the job's
+// listings that were not parsed yet. A national number is read in the
+// listing's address country, or else in the country most of the job's
+// places are in, which is where its geo points.
+func normalizePhones(ctx context.Context, tx *sql.Tx, jobID uuid.UUID) error {
+	rows, err := tx.QueryContext(ctx, `
+		WITH job_country AS (
+			SELECT address_country
+			FROM business_listings
+			WHERE job_id = $1 AND address_country <> ''
+			GROUP BY address_country
+			ORDER BY COUNT(*) DESC
+			LIMIT 1
+		)
+		SELECT bl.id, bl.phone, COALESCE(NULLIF(bl.address_country, ''), (SELECT address_country FROM job_country), '')
+		FROM business_listings bl
+		WHERE bl.job_id = $1 AND bl.phone_valid IS NULL AND bl.phone <> ''
+	`, jobID)
+	if err != nil {
+		return fmt.Errorf("list unparsed phones: %w", err)
+	}
+
+	var (
+		ids   []int64
+		e164s []sql.NullString
+		valid []bool
+	)
+	for rows.Next() {
+		var (
+			id             int64
+			phone, country string
+		)
+		if err := rows.Scan(&id, &phone, &country); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan unparsed phone: %w", err)
+		}
+
+		normalized, err := phonenumber.Normalize(phone, country)
+		ids = append(ids, id)
+		e164s = append(e164s, sql.NullString{String: normalized, Valid: err == nil})
+		valid = append(valid, err == nil)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("list unparsed phones: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// Invalid numbers keep phone_e164 NULL and count as parse failures
+	_, err = tx.ExecContext(ctx, `
+		UPDATE business_listings bl
+		SET phone_e164 = u.e164, phone_valid = u.valid
+		FROM unnest($1::bigint[], $2::text[], $3::boolean[]) AS u(id, e164, valid)
+		WHERE bl.id = u.id
+	`, pq.Array(ids), pq.Array(e164s), pq.Array(valid))
+	if err != nil {
+		return fmt.Errorf("store normalized phones: %w", err)
+	}
+
+	return nil
 }
 
 // ListAll retrieves all results with pagination (global view)
@@ -115,7 +499,7 @@ func (r *ResultRepository) ListByJobID(ctx context.Context, jobID uuid.UUID, lim
 	countCtx, countCancel := context.WithTimeout(ctx, resultCountTimeout)
 	defer countCancel()
 
-	countQuery := `SELECT COUNT(*) FROM results WHERE job_id = $1`
+	countQuery := `SELECT COUNT(*) FROM results WHERE ` + jobScope("job_id", "$1")
 	var total int
 	err := r.db.QueryRowContext(countCtx, countQuery, jobID).Scan(&total)
 	if err != nil {
@@ -128,7 +512,7 @@ func (r *ResultRepository) ListByJobID(ctx context.Context, jobID uuid.UUID, lim
 
 	query := `
 		SELECT data FROM results
-		WHERE job_id = $1
+		WHERE ` + jobScope("job_id", "$1") + `
 		ORDER BY id ASC
 		LIMIT $2 OFFSET $3
 	`
@@ -156,7 +540,7 @@ func (r *ResultRepository) CountByJobID(ctx context.Context, jobID uuid.UUID) (i
 	countCtx, cancel := context.WithTimeout(ctx, resultCountTimeout)
 	defer cancel()
 
-	query := `SELECT COUNT(*) FROM results WHERE job_id = $1`
+	query := `SELECT COUNT(*) FROM results WHERE ` + jobScope("job_id", "$1")
 	var count int
 	err := r.db.QueryRowContext(countCtx, query, jobID).Scan(&count)
 	if err != nil {
@@ -165,6 +549,58 @@ func (r *ResultRepository) CountByJobID(ctx context.Context, jobID uuid.UUID) (i
 	return count, nil
 }
 
+// CountsByJobID counts the results of a job for its progress
+func (r *ResultRepository) CountsByJobID(ctx context.Context, jobID uuid.UUID) (*domain.ResultCounts, error) {
+	countCtx, cancel := context.WithTimeout(ctx, resultCountTimeout)
+	defer cancel()
+
+	var counts domain.ResultCounts
+	err := r.db.QueryRowContext(countCtx, `
+		SELECT
+			(SELECT COUNT(*) FROM results WHERE `+jobScope("job_id", "$1")+`),
+			(SELECT COUNT(*) FROM results WHERE `+jobScope("job_id", "$1")+` AND data ->> 'data_completeness' = 'partial'),
+			(SELECT COUNT(*) FROM results WHERE backfilled_by = $1)
+	`, jobID).Scan(&counts.Stored, &counts.Partial, &counts.Backfilled)
+	if err != nil {
+		return nil, fmt.Errorf("count query failed: %w", err)
+	}
+	return &counts, nil
+}
+
+// ListPartialLinks returns the place links of the partial results of a job
+func (r *ResultRepository) ListPartialLinks(ctx context.Context, jobID uuid.UUID) ([]string, error) {
+	var links pq.StringArray
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(array_agg(link ORDER BY id), '{}')
+		FROM (
+			SELECT DISTINCT ON (data ->> 'link') id, data ->> 'link' AS link
+			FROM results
+			WHERE `+jobScope("job_id", "$1")+` AND data ->> 'data_completeness' = 'partial' AND data ->> 'link' <> ''
+			ORDER BY data ->> 'link', id
+		) partial
+	`, jobID).Scan(&links)
+	if err != nil {
+		return nil, fmt.Errorf("list partial links: %w", err)
+	}
+	return links, nil
+}
+
+// LastCreatedAt returns when the latest result of a job was stored
+func (r *ResultRepository) LastCreatedAt(ctx context.Context, jobID uuid.UUID) (*time.Time, error) {
+	countCtx, cancel := context.WithTimeout(ctx, resultCountTimeout)
+	defer cancel()
+
+	query := `SELECT MAX(created_at) FROM results WHERE ` + jobScope("job_id", "$1")
+	var last sql.NullTime
+	if err := r.db.QueryRowContext(countCtx, query, jobID).Scan(&last); err != nil {
+		return nil, fmt.Errorf("last result query failed: %w", err)
+	}
+	if !last.Valid {
+		return nil, nil
+	}
+	return &last.Time, nil
+}
+
 // DeleteByJobID deletes all results for a job
 func (r *ResultRepository) DeleteByJobID(ctx context.Context, jobID uuid.UUID) error {
 	query := `DELETE FROM results WHERE job_id = $1`
@@ -221,7 +657,7 @@ func (r *ResultRepository) StreamByJobID(ctx context.Context, jobID uuid.UUID, f
 	streamCtx, cancel := context.WithTimeout(ctx, resultStreamTimeout)
 	defer cancel()
 
-	query := `SELECT data FROM results WHERE job_id = $1 ORDER BY id ASC`
+	query := `SELECT data FROM results WHERE ` + jobScope("job_id", "$1") + ` ORDER BY id ASC`
 
 	rows, err := r.db.QueryContext(streamCtx, query, jobID)
 	if err != nil {
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// ReviewRepository provides access to business_reviews
+type ReviewRepository struct {
+	db *sql.DB
+}
+
+// NewReviewRepository creates a new repository
+func NewReviewRepository(db *sql.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// jobReviewsQuery selects the reviews of every place a job scraped. A place
+// can appear in several results of the same job, hence DISTINCT ON.
+const jobReviewsQuery = `
+	WITH places AS (
+		SELECT DISTINCT ON (place_id) place_id, title
+		FROM business_listings
+		WHERE job_id IN (SELECT id FROM jobs_queue WHERE id = $1 OR parent_job_id = $1) AND place_id IS NOT NULL
+		ORDER BY place_id, created_at DESC
+	)
+	SELECT
+		r.id, r.place_id, p.title, r.reviewer_name, r.reviewer_picture, r.rating,
+		r.review_text, r.relative_date, r.owner_reply,
+		COALESCE(array_to_json(r.images), '[]'::json) AS images,
+		r.first_seen_at, r.last_seen_at
+	FROM business_reviews r
+	JOIN places p ON p.place_id = r.place_id
+	ORDER BY p.title, r.id
+`
+
+// ListByJobID retrieves reviews for a job with pagination
+func (r *ReviewRepository) ListByJobID(ctx context.Context, jobID string, limit, offset int) ([]*domain.BusinessReview, int, error) {
+	countQuery := `
+		SELECT COUNT(*)
+		FROM business_reviews r
+		WHERE r.place_id IN (
+			SELECT place_id FROM business_listings WHERE job_id IN (SELECT id FROM jobs_queue WHERE id = $1 OR parent_job_id = $1) AND place_id IS NOT NULL
+		)
+	`
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countQuery, jobID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count reviews failed: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, jobReviewsQuery+" LIMIT $2 OFFSET $3", jobID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list reviews failed: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := make([]*domain.BusinessReview, 0, limit)
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan failed: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, total, rows.Err()
+}
+
+// StreamByJobID streams all reviews for a job for export
+func (r *ReviewRepository) StreamByJobID(ctx context.Context, jobID string, fn func(review *domain.BusinessReview) error) error {
+	rows, err := r.db.QueryContext(ctx, jobReviewsQuery, jobID)
+	if err != nil {
+		return fmt.Errorf("stream reviews query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
+		if err := fn(review); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func scanReview(row rowScanner) (*domain.BusinessReview, error) {
+	var review domain.BusinessReview
+	var picture, relativeDate, ownerReply sql.NullString
+	var rating sql.NullInt64
+	var images []byte
+
+	err := row.Scan(
+		&review.ID, &review.PlaceID, &review.BusinessTitle, &review.ReviewerName, &picture, &rating,
+		&review.Text, &relativeDate, &ownerReply,
+		&images,
+		&review.FirstSeenAt, &review.LastSeenAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if picture.Valid {
+		review.ReviewerPicture = &picture.String
+	}
+	if rating.Valid {
+		v := int(rating.Int64)
+		review.Rating = &v
+	}
+	if relativeDate.Valid {
+		review.RelativeDate = &relativeDate.String
+	}
+	if ownerReply.Valid {
+		review.OwnerReply = &ownerReply.String
+	}
+	if len(images) > 0 {
+		if err := json.Unmarshal(images, &review.Images); err != nil {
+			return nil, fmt.Errorf("unmarshal images: %w", err)
+		}
+	}
+
+	return &review, nil
+}
+
+var _ domain.ReviewRepository = (*ReviewRepository)(nil)
@@ -0,0 +1,207 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/sadewadee/google-scraper/internal/secrets"
+)
+
+// SecretsRepository re-encrypts the credentials the job, proxy and proxy
+// source repositories store, batch by batch so rows stay locked briefly
+type SecretsRepository struct {
+	db   *sql.DB
+	keys *secrets.Keyring
+}
+
+// NewSecretsRepository creates a repository resealing with keys
+func NewSecretsRepository(db *sql.DB, keys *secrets.Keyring) *SecretsRepository {
+	return &SecretsRepository{db: db, keys: keys}
+}
+
+// ResealJobProxies re-encrypts the proxies of the jobs after id `after`, up
+// to limit jobs. It returns the last job read, nil past the last one, and
+// how many jobs changed.
+func (r *SecretsRepository) ResealJobProxies(ctx context.Context, after uuid.UUID, limit int) (*uuid.UUID, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, proxies FROM jobs_queue
+		WHERE id > $1 AND cardinality(proxies) > 0
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE
+	`, after, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("select jobs: %w", err)
+	}
+
+	type jobProxies struct {
+		id      uuid.UUID
+		proxies pq.StringArray
+	}
+	var batch []jobProxies
+	for rows.Next() {
+		var j jobProxies
+		if err := rows.Scan(&j.id, &j.proxies); err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		batch = append(batch, j)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	if len(batch) == 0 {
+		return nil, 0, nil
+	}
+
+	changed := 0
+	for _, j := range batch {
+		dirty := false
+		for i, p := range j.proxies {
+			sealed, ok, err := r.keys.ResealURL(p)
+			if err != nil {
+				return nil, 0, fmt.Errorf("job %s: %w", j.id, err)
+			}
+			j.proxies[i] = sealed
+			dirty = dirty || ok
+		}
+		if !dirty {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE jobs_queue SET proxies = $2 WHERE id = $1`, j.id, j.proxies); err != nil {
+			return nil, 0, fmt.Errorf("update job %s: %w", j.id, err)
+		}
+		changed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("commit: %w", err)
+	}
+
+	return &batch[len(batch)-1].id, changed, nil
+}
+
+// ResealProxyPasswords re-encrypts the passwords of the proxies after id
+// `after`, up to limit proxies, and masks the credentials of their source
+// URLs. It returns the last proxy read, 0 past the last one, and how many
+// proxies changed.
+func (r *SecretsRepository) ResealProxyPasswords(ctx context.Context, after int64, limit int) (int64, int, error) {
+	return r.resealColumns(ctx, "proxies", "COALESCE(password, ''), COALESCE(source_url, '')", after, limit,
+		func(values []string) (bool, error) {
+			password, ok, err := r.keys.Reseal(values[0])
+			if err != nil {
+				return false, err
+			}
+			sourceURL := secrets.MaskURL(values[1])
+
+			changed := ok || sourceURL != values[1]
+			values[0], values[1] = password, sourceURL
+			return changed, nil
+		},
+		`UPDATE proxies SET password = NULLIF($2, ''), source_url = NULLIF($3, '') WHERE id = $1`)
+}
+
+// ResealProxySources re-encrypts the URLs with credentials of the proxy
+// sources after id `after`, up to limit sources. It returns the last
+// source read, 0 past the last one, and how many sources changed.
+func (r *SecretsRepository) ResealProxySources(ctx context.Context, after int64, limit int) (int64, int, error) {
+	return r.resealColumns(ctx, "proxy_sources", "url", after, limit,
+		func(values []string) (bool, error) {
+			url, ok, err := r.keys.ResealURL(values[0])
+			values[0] = url
+			return ok, err
+		},
+		`UPDATE proxy_sources SET url = $2 WHERE id = $1`)
+}
+
+// resealColumns runs reseal over the text columns of a batch of rows of a
+// table with bigint ids, and update on the rows it changed, with the id
+// followed by the columns as arguments
+func (r *SecretsRepository) resealColumns(ctx context.Context, table, columns string, after int64, limit int,
+	reseal func(values []string) (bool, error), update string) (int64, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, `+columns+` FROM `+table+`
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE
+	`, after, limit)
+	if err != nil {
+		return 0, 0, fmt.Errorf("select %s: %w", table, err)
+	}
+
+	cols, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return 0, 0, err
+	}
+
+	type row struct {
+		id     int64
+		values []string
+	}
+	var batch []row
+	for rows.Next() {
+		rw := row{values: make([]string, len(cols)-1)}
+		dest := []any{&rw.id}
+		for i := range rw.values {
+			dest = append(dest, &rw.values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		batch = append(batch, rw)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+	if len(batch) == 0 {
+		return 0, 0, nil
+	}
+
+	changed := 0
+	for _, rw := range batch {
+		ok, err := reseal(rw.values)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%s %d: %w", table, rw.id, err)
+		}
+		if !ok {
+			continue
+		}
+
+		args := []any{rw.id}
+		for _, v := range rw.values {
+			args = append(args, v)
+		}
+		if _, err := tx.ExecContext(ctx, update, args...); err != nil {
+			return 0, 0, fmt.Errorf("update %s %d: %w", table, rw.id, err)
+		}
+		changed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("commit: %w", err)
+	}
+
+	return batch[len(batch)-1].id, changed, nil
+}
@@ -0,0 +1,237 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// SeedTaskRepository implements domain.SeedTaskRepository on gmaps_jobs
+type SeedTaskRepository struct {
+	db querier
+}
+
+// NewSeedTaskRepository creates a new SeedTaskRepository
+func NewSeedTaskRepository(db querier) *SeedTaskRepository {
+	return &SeedTaskRepository{db: db}
+}
+
+// ListByParent retrieves the seed tasks of a job, oldest first
+func (r *SeedTaskRepository) ListByParent(ctx context.Context, parentID uuid.UUID, params domain.SeedTaskListParams) ([]*domain.SeedTask, int, error) {
+	var total int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM gmaps_jobs
+		WHERE parent_job_id = $1 AND ($2 = '' OR status = $2)
+	`, parentID, string(params.Status)).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count seed tasks failed: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, COALESCE(keyword, ''), COALESCE(geo, ''), status, places_found,
+			COALESCE(lang, ''), lang_fallbacks,
+			COALESCE(error, ''), attempts, created_at, started_at, finished_at
+		FROM gmaps_jobs
+		WHERE parent_job_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at, id
+		LIMIT $3 OFFSET $4
+	`, parentID, string(params.Status), params.Limit, params.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list seed tasks failed: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]*domain.SeedTask, 0, params.Limit)
+	for rows.Next() {
+		var (
+			task        domain.SeedTask
+			placesFound sql.NullInt32
+			startedAt   sql.NullTime
+			finishedAt  sql.NullTime
+		)
+
+		if err := rows.Scan(&task.ID, &task.Keyword, &task.Geo, &task.Status, &placesFound,
+			&task.Lang, &task.LangFallbacks,
+			&task.Error, &task.Attempts, &task.CreatedAt, &startedAt, &finishedAt); err != nil {
+			return nil, 0, fmt.Errorf("scan failed: %w", err)
+		}
+
+		if placesFound.Valid {
+			n := int(placesFound.Int32)
+			task.PlacesFound = &n
+		}
+		if startedAt.Valid {
+			task.StartedAt = &startedAt.Time
+		}
+		if finishedAt.Valid {
+			task.FinishedAt = &finishedAt.Time
+		}
+
+		tasks = append(tasks, &task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return tasks, total, nil
+}
+
+// CountsByParent counts the seed tasks of a job by status
+func (r *SeedTaskRepository) CountsByParent(ctx context.Context, parentID uuid.UUID) (*domain.SeedTaskCounts, error) {
+	var c domain.SeedTaskCounts
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'new'),
+			COUNT(*) FILTER (WHERE status = 'queued'),
+			COUNT(*) FILTER (WHERE status = 'ok'),
+			COUNT(*) FILTER (WHERE status = 'failed')
+		FROM gmaps_jobs
+		WHERE parent_job_id = $1
+	`, parentID).Scan(&c.Total, &c.New, &c.Queued, &c.OK, &c.Failed)
+	if err != nil {
+		return nil, fmt.Errorf("count seed tasks failed: %w", err)
+	}
+
+	return &c, nil
+}
+
+// FailStale marks tasks claimed longer than timeout ago as failed
+func (r *SeedTaskRepository) FailStale(ctx context.Context, timeout time.Duration) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE gmaps_jobs
+		SET status = 'failed', finished_at = NOW(), error = $2
+		WHERE parent_job_id IS NOT NULL
+			AND status = 'queued'
+			AND started_at < NOW() - $1 * INTERVAL '1 second'
+	`, timeout.Seconds(), fmt.Sprintf("no result reported within %s", timeout))
+	if err != nil {
+		return 0, fmt.Errorf("fail stale seed tasks: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n), nil
+}
+
+// ActiveProgress returns the seed task state of every unfinished job that
+// has seed tasks
+func (r *SeedTaskRepository) ActiveProgress(ctx context.Context) ([]*domain.SeedTaskProgress, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			j.id, j.status, COALESCE(j.total_places, 0), COALESCE(j.scraped_places, 0), COALESCE(j.failed_places, 0),
+			j.partial_places,
+			t.total, t.new, t.queued, t.ok, t.failed,
+			res.places, res.partial, GREATEST(t.last_finished, res.last_stored)
+		FROM jobs_queue j
+		JOIN (
+			SELECT
+				parent_job_id,
+				COUNT(*) AS total,
+				COUNT(*) FILTER (WHERE status = 'new') AS new,
+				COUNT(*) FILTER (WHERE status = 'queued') AS queued,
+				COUNT(*) FILTER (WHERE status = 'ok') AS ok,
+				COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+				MAX(finished_at) AS last_finished
+			FROM gmaps_jobs
+			WHERE parent_job_id IS NOT NULL
+			GROUP BY parent_job_id
+		) t ON t.parent_job_id = j.id
+		CROSS JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE data ->> 'data_completeness' IS DISTINCT FROM 'partial') AS places,
+				COUNT(*) FILTER (WHERE data ->> 'data_completeness' = 'partial') AS partial,
+				MAX(created_at) AS last_stored
+			FROM results
+			WHERE job_id = j.id
+		) res
+		WHERE j.status IN ('pending', 'queued', 'running')
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("seed task progress failed: %w", err)
+	}
+	defer rows.Close()
+
+	var active []*domain.SeedTaskProgress
+	for rows.Next() {
+		var (
+			p            domain.SeedTaskProgress
+			lastActivity sql.NullTime
+		)
+
+		if err := rows.Scan(&p.JobID, &p.Status,
+			&p.Progress.TotalPlaces, &p.Progress.ScrapedPlaces, &p.Progress.FailedPlaces,
+			&p.Progress.PartialPlaces,
+			&p.Counts.Total, &p.Counts.New, &p.Counts.Queued, &p.Counts.OK, &p.Counts.Failed,
+			&p.Places, &p.PartialPlaces, &lastActivity); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+
+		if lastActivity.Valid {
+			p.LastActivity = &lastActivity.Time
+		}
+
+		active = append(active, &p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return active, nil
+}
+
+// RetryFailed puts the failed tasks of a job that ran fewer than
+// maxAttempts times back to new. A single statement does both, so
+// concurrent calls cannot requeue a task twice.
+func (r *SeedTaskRepository) RetryFailed(ctx context.Context, parentID uuid.UUID, maxAttempts int) (requeued, exhausted int, err error) {
+	err = r.db.QueryRowContext(ctx, `
+		WITH requeued AS (
+			UPDATE gmaps_jobs
+			SET status = 'new', attempts = attempts + 1,
+				started_at = NULL, finished_at = NULL, places_found = NULL, error = NULL
+			WHERE parent_job_id = $1 AND status = 'failed' AND attempts < $2
+			RETURNING id
+		)
+		SELECT
+			(SELECT COUNT(*) FROM requeued),
+			(SELECT COUNT(*) FROM gmaps_jobs
+				WHERE parent_job_id = $1 AND status = 'failed' AND attempts >= $2)
+	`, parentID, maxAttempts).Scan(&requeued, &exhausted)
+	if err != nil {
+		return 0, 0, fmt.Errorf("retry failed seed tasks: %w", err)
+	}
+
+	return requeued, exhausted, nil
+}
+
+// DeleteNew deletes the tasks of a job no DSN worker claimed yet. Workers
+// claim with FOR UPDATE SKIP LOCKED, so a task is either claimed or
+// deleted, never both.
+func (r *SeedTaskRepository) DeleteNew(ctx context.Context, parentID uuid.UUID) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM gmaps_jobs
+		WHERE parent_job_id = $1 AND status = 'new'
+	`, parentID)
+	if err != nil {
+		return 0, fmt.Errorf("delete new seed tasks: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("delete new seed tasks: %w", err)
+	}
+	return int(n), nil
+}
+
+var _ domain.SeedTaskRepository = (*SeedTaskRepository)(nil)
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// SettingsRepository implements domain.SettingsRepository for PostgreSQL
+type SettingsRepository struct {
+	db *sql.DB
+}
+
+// NewSettingsRepository creates a new SettingsRepository
+func NewSettingsRepository(db *sql.DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+// Get returns the setting of key, or nil when it was never saved
+func (r *SettingsRepository) Get(ctx context.Context, key string) (*domain.Setting, error) {
+	s := &domain.Setting{Key: key}
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT value, updated_by, updated_at FROM settings WHERE key = $1
+	`, key).Scan(&s.Value, &s.UpdatedBy, &s.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get setting %s: %w", key, err)
+	}
+
+	return s, nil
+}
+
+// Put stores s and records the change in the settings history, in one
+// transaction
+func (r *SettingsRepository) Put(ctx context.Context, s *domain.Setting) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO settings (key, value, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`, s.Key, []byte(s.Value), s.UpdatedBy).Scan(&s.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to store setting %s: %w", s.Key, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO settings_history (key, value, actor, created_at) VALUES ($1, $2, $3, $4)
+	`, s.Key, []byte(s.Value), s.UpdatedBy, s.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to record setting %s: %w", s.Key, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// timeSeriesSource is where a metric is counted. Ranges are filtered on at,
+// which has an index in every table (migration 0038).
+type timeSeriesSource struct {
+	from      string            // FROM clause without grouping
+	groupFrom string            // FROM clause with grouping
+	at        string            // Timestamp the metric is bucketed by
+	where     string            // Additional condition
+	id        string            // Counted row
+	distinct  bool              // A row may be joined more than once per group
+	groups    map[string]string // group_by -> expression
+}
+
+var timeSeriesSources = map[string]timeSeriesSource{
+	domain.MetricPlaces: {
+		from:      "business_listings bl",
+		groupFrom: "business_listings bl",
+		at:        "bl.created_at",
+		id:        "bl.id",
+		groups: map[string]string{
+			domain.GroupByJob:      "COALESCE(bl.job_id::text, '')",
+			domain.GroupByCategory: "COALESCE(bl.category, '')",
+		},
+	},
+	// An email found by several jobs or categories counts in each of them
+	domain.MetricEmails: {
+		from: "emails e",
+		groupFrom: `emails e
+			JOIN business_emails be ON be.email_id = e.id
+			JOIN business_listings bl ON bl.id = be.business_listing_id`,
+		at:       "e.first_seen_at",
+		id:       "e.id",
+		distinct: true,
+		groups: map[string]string{
+			domain.GroupByJob:      "COALESCE(bl.job_id::text, '')",
+			domain.GroupByCategory: "COALESCE(bl.category, '')",
+		},
+	},
+	domain.MetricJobsCompleted: {
+		from:  "jobs_queue j",
+		at:    "j.completed_at",
+		where: "j.status = 'completed'",
+		id:    "j.id",
+	},
+}
+
+// TimeSeriesRepository counts the metrics of the stats time series with
+// date_trunc
+type TimeSeriesRepository struct {
+	db *sql.DB
+}
+
+// NewTimeSeriesRepository creates a new repository
+func NewTimeSeriesRepository(db *sql.DB) *TimeSeriesRepository {
+	return &TimeSeriesRepository{db: db}
+}
+
+// Intervals implements domain.TimeSeriesRepository
+func (r *TimeSeriesRepository) Intervals() []string {
+	return domain.TimeSeriesIntervals
+}
+
+// TimeSeries implements domain.TimeSeriesRepository
+func (r *TimeSeriesRepository) TimeSeries(ctx context.Context, q domain.TimeSeriesQuery) ([]domain.TimeSeriesRow, error) {
+	src, ok := timeSeriesSources[q.Metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", q.Metric)
+	}
+
+	where := fmt.Sprintf("%s >= $2 AND %s < $3", src.at, src.at)
+	if src.where != "" {
+		where += " AND " + src.where
+	}
+
+	bucket := fmt.Sprintf("date_trunc($1, %s AT TIME ZONE 'UTC')", src.at)
+
+	if q.GroupBy == "" {
+		query := fmt.Sprintf(`
+			SELECT %s AS bucket, '', false, COUNT(*)
+			FROM %s
+			WHERE %s
+			GROUP BY 1`, bucket, src.from, where)
+
+		return r.query(ctx, query, q.Interval, q.From, q.To)
+	}
+
+	group, ok := src.groups[q.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("%s cannot be grouped by %s", q.Metric, q.GroupBy)
+	}
+
+	count := "COUNT(*)"
+	if src.distinct {
+		count = "COUNT(DISTINCT id)"
+	}
+
+	// The largest groups by total over the range keep their own series
+	query := fmt.Sprintf(`
+		WITH f AS (
+			SELECT %s AS bucket, %s AS grp, %s AS id
+			FROM %s
+			WHERE %s
+		), top AS (
+			SELECT grp FROM f GROUP BY grp ORDER BY %s DESC, grp LIMIT $4
+		)
+		SELECT f.bucket, CASE WHEN top.grp IS NULL THEN '' ELSE f.grp END, top.grp IS NULL, %s
+		FROM f
+		LEFT JOIN top ON top.grp = f.grp
+		GROUP BY 1, 2, 3`, bucket, group, src.id, src.groupFrom, where, count, count)
+
+	return r.query(ctx, query, q.Interval, q.From, q.To, q.MaxSeries)
+}
+
+func (r *TimeSeriesRepository) query(ctx context.Context, query string, args ...any) ([]domain.TimeSeriesRow, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count time series: %w", err)
+	}
+	defer rows.Close()
+
+	var result []domain.TimeSeriesRow
+	for rows.Next() {
+		var row domain.TimeSeriesRow
+		if err := rows.Scan(&row.Bucket, &row.Group, &row.Other, &row.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan time series: %w", err)
+		}
+		row.Bucket = row.Bucket.UTC()
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
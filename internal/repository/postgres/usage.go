@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// querier is satisfied by *sql.DB, *sql.Tx and wrappers of them, such as
+// the one that logs slow queries
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// UsageRepository implements domain.UsageRepository for PostgreSQL
+type UsageRepository struct {
+	db *sql.DB
+}
+
+// NewUsageRepository creates a new UsageRepository
+func NewUsageRepository(db *sql.DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+// Summary returns per-tenant usage for the filter's date range, busiest
+// tenant first
+func (r *UsageRepository) Summary(ctx context.Context, filter domain.UsageFilter) ([]*domain.TenantUsage, error) {
+	query := `
+		SELECT u.tenant, COALESCE(k.name, ''), k.monthly_place_quota,
+			u.day, u.places_scraped, u.email_validations
+		FROM usage_daily u
+		LEFT JOIN api_keys k ON k.id::text = u.tenant
+		WHERE u.day BETWEEN $1 AND $2
+			AND ($3 = '' OR u.tenant = $3)
+		ORDER BY u.tenant, u.day
+	`
+
+	rows, err := r.db.QueryContext(ctx, query,
+		filter.From.Format(time.DateOnly), filter.To.Format(time.DateOnly), filter.Tenant)
+	if err != nil {
+		return nil, fmt.Errorf("usage summary failed: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []*domain.TenantUsage
+	var current *domain.TenantUsage
+
+	for rows.Next() {
+		var tenant, keyName string
+		var quota sql.NullInt32
+		var day time.Time
+		var d domain.DailyUsage
+
+		if err := rows.Scan(&tenant, &keyName, &quota, &day, &d.PlacesScraped, &d.EmailValidations); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		d.Day = day.Format(time.DateOnly)
+
+		if current == nil || current.Tenant != tenant {
+			current = &domain.TenantUsage{Tenant: tenant, APIKeyName: keyName}
+			if quota.Valid {
+				q := int(quota.Int32)
+				current.MonthlyPlaceQuota = &q
+			}
+			usage = append(usage, current)
+		}
+
+		current.PlacesScraped += d.PlacesScraped
+		current.EmailValidations += d.EmailValidations
+		current.Days = append(current.Days, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return usage, nil
+}
+
+// MonthlyQuota returns the tenant's quota and its usage this month
+func (r *UsageRepository) MonthlyQuota(ctx context.Context, tenant string) (*domain.QuotaStatus, error) {
+	status := &domain.QuotaStatus{Tenant: tenant}
+
+	var quota sql.NullInt32
+	err := r.db.QueryRowContext(ctx,
+		`SELECT monthly_place_quota FROM api_keys WHERE id::text = $1`, tenant,
+	).Scan(&quota)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("get quota: %w", err)
+	}
+	if quota.Valid {
+		q := int(quota.Int32)
+		status.Quota = &q
+	}
+
+	if status.Used, err = monthlyPlaces(ctx, r.db, tenant); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// monthlyPlaces sums the places accounted to tenant in the current quota
+// month
+func monthlyPlaces(ctx context.Context, q querier, tenant string) (int, error) {
+	var used int
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(places_scraped), 0)
+		FROM usage_daily
+		WHERE tenant = $1 AND day >= $2
+	`, tenant, domain.QuotaPeriodStart(time.Now()).Format(time.DateOnly)).Scan(&used)
+	if err != nil {
+		return 0, fmt.Errorf("get monthly usage: %w", err)
+	}
+
+	return used, nil
+}
+
+// lockJobUsage returns the quota status of the job's tenant, or nil when
+// the job is not attributed to one. It holds a transaction-scoped lock on
+// the tenant so concurrent batches cannot overshoot the quota together.
+func lockJobUsage(ctx context.Context, tx *sql.Tx, jobID uuid.UUID) (*domain.QuotaStatus, error) {
+	var tenant sql.NullString
+	var quota sql.NullInt32
+
+	err := tx.QueryRowContext(ctx, `
+		SELECT j.tenant, k.monthly_place_quota
+		FROM jobs_queue j
+		LEFT JOIN api_keys k ON k.id::text = j.tenant
+		WHERE j.id = $1
+	`, jobID).Scan(&tenant, &quota)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !tenant.Valid) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get job tenant: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, tenant.String); err != nil {
+		return nil, fmt.Errorf("lock tenant usage: %w", err)
+	}
+
+	status := &domain.QuotaStatus{Tenant: tenant.String}
+	if quota.Valid {
+		q := int(quota.Int32)
+		status.Quota = &q
+	}
+
+	if status.Used, err = monthlyPlaces(ctx, tx, tenant.String); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// recordUsage adds to the tenant's usage for the current UTC day
+func recordUsage(ctx context.Context, q querier, tenant string, places, emailValidations int64) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO usage_daily (tenant, day, places_scraped, email_validations)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant, day) DO UPDATE SET
+			places_scraped = usage_daily.places_scraped + EXCLUDED.places_scraped,
+			email_validations = usage_daily.email_validations + EXCLUDED.email_validations
+	`, tenant, time.Now().UTC().Format(time.DateOnly), places, emailValidations)
+	if err != nil {
+		return fmt.Errorf("record usage: %w", err)
+	}
+
+	return nil
+}
+
+// countEmailValidations counts the email validation calls recorded in a
+// batch of result entries
+func countEmailValidations(data [][]byte) int64 {
+	var n int64
+	for _, d := range data {
+		var entry struct {
+			EmailValidations []json.RawMessage `json:"email_validations"`
+		}
+		if err := json.Unmarshal(d, &entry); err == nil {
+			n += int64(len(entry.EmailValidations))
+		}
+	}
+
+	return n
+}
+
+var _ domain.UsageRepository = (*UsageRepository)(nil)
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// WebsiteCheckRepository implements domain.WebsiteCheckRepository for
+// PostgreSQL
+type WebsiteCheckRepository struct {
+	db *sql.DB
+}
+
+// NewWebsiteCheckRepository creates a new WebsiteCheckRepository
+func NewWebsiteCheckRepository(db *sql.DB) *WebsiteCheckRepository {
+	return &WebsiteCheckRepository{db: db}
+}
+
+// ClaimDue marks up to limit unchecked listings of jobs with check_website
+// as queued and returns them, the oldest first
+func (r *WebsiteCheckRepository) ClaimDue(ctx context.Context, limit int, claimTimeout time.Duration) ([]domain.WebsiteCheckTask, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE business_listings SET website_check_queued_at = NOW()
+		WHERE id IN (
+			SELECT bl.id FROM business_listings bl
+			JOIN jobs_queue j ON j.id = bl.job_id AND j.check_website
+			WHERE bl.website_checked_at IS NULL AND bl.website IS NOT NULL AND bl.website <> ''
+				AND (bl.website_check_queued_at IS NULL OR bl.website_check_queued_at < NOW() - $2 * INTERVAL '1 second')
+			ORDER BY bl.id
+			LIMIT $1
+			FOR UPDATE OF bl SKIP LOCKED
+		)
+		RETURNING id, website
+	`, limit, claimTimeout.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim websites for checking: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []domain.WebsiteCheckTask
+	for rows.Next() {
+		var t domain.WebsiteCheckTask
+		if err := rows.Scan(&t.ListingID, &t.Website); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// Recent returns the latest check of domain if it is younger than maxAge
+func (r *WebsiteCheckRepository) Recent(ctx context.Context, domainName string, maxAge time.Duration) (*domain.WebsiteCheck, error) {
+	var (
+		check      = domain.WebsiteCheck{Domain: domainName}
+		httpStatus sql.NullInt64
+		finalURL   sql.NullString
+		checkErr   sql.NullString
+	)
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT status, http_status, final_url, error, checked_at
+		FROM website_checks
+		WHERE domain = $1 AND checked_at > NOW() - $2 * INTERVAL '1 second'
+	`, domainName, maxAge.Seconds()).Scan(&check.Status, &httpStatus, &finalURL, &checkErr, &check.CheckedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get website check of %s: %w", domainName, err)
+	}
+
+	check.HTTPStatus = int(httpStatus.Int64)
+	check.FinalURL = finalURL.String
+	check.Error = checkErr.String
+
+	return &check, nil
+}
+
+// Store records check on the listing, clearing its claim, and unless it
+// was reused as the latest check of its domain
+func (r *WebsiteCheckRepository) Store(ctx context.Context, listingID int64, check *domain.WebsiteCheck, reused bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	httpStatus := sql.NullInt64{Int64: int64(check.HTTPStatus), Valid: check.HTTPStatus != 0}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE business_listings SET
+			website_status = $2,
+			website_http_status = $3,
+			website_final_url = $4,
+			website_checked_at = $5,
+			website_check_queued_at = NULL
+		WHERE id = $1
+	`, listingID, check.Status, httpStatus, nullString(check.FinalURL), check.CheckedAt)
+	if err != nil {
+		return fmt.Errorf("store website check of listing %d: %w", listingID, err)
+	}
+
+	if !reused && check.Domain != "" {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO website_checks (domain, status, http_status, final_url, error, checked_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (domain) DO UPDATE SET
+				status = EXCLUDED.status,
+				http_status = EXCLUDED.http_status,
+				final_url = EXCLUDED.final_url,
+				error = EXCLUDED.error,
+				checked_at = EXCLUDED.checked_at
+		`, check.Domain, check.Status, httpStatus, nullString(check.FinalURL), nullString(check.Error), check.CheckedAt)
+		if err != nil {
+			return fmt.Errorf("store website check of %s: %w", check.Domain, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -24,18 +24,31 @@ func NewWorkerRepository(db *sql.DB) *WorkerRepository {
 // Upsert creates or updates a worker (for heartbeat)
 func (r *WorkerRepository) Upsert(ctx context.Context, worker *domain.Worker) error {
 	query := `
-		INSERT INTO workers (id, hostname, status, current_job_id, last_heartbeat, created_at)
-		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		INSERT INTO workers (
+			id, hostname, status, current_job_id, request_delay_ms, block_count,
+			seed_jobs_completed, seed_jobs_total, job_places_scraped, memory_bytes,
+			last_heartbeat, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
 		ON CONFLICT (id) DO UPDATE SET
 			hostname = EXCLUDED.hostname,
 			status = EXCLUDED.status,
 			current_job_id = EXCLUDED.current_job_id,
+			request_delay_ms = EXCLUDED.request_delay_ms,
+			block_count = EXCLUDED.block_count,
+			seed_jobs_completed = EXCLUDED.seed_jobs_completed,
+			seed_jobs_total = EXCLUDED.seed_jobs_total,
+			job_places_scraped = EXCLUDED.job_places_scraped,
+			memory_bytes = EXCLUDED.memory_bytes,
 			last_heartbeat = NOW()
+		RETURNING draining_since, drain_deadline
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
-		worker.ID, worker.Hostname, worker.Status, worker.CurrentJobID)
-	return err
+	return r.db.QueryRowContext(ctx, query,
+		worker.ID, worker.Hostname, worker.Status, worker.CurrentJobID,
+		worker.RequestDelayMs, worker.BlockCount,
+		worker.SeedJobsCompleted, worker.SeedJobsTotal, worker.JobPlacesScraped, worker.MemoryBytes,
+	).Scan(&worker.DrainingSince, &worker.DrainDeadline)
 }
 
 // GetByID retrieves a worker by ID
@@ -43,7 +56,10 @@ func (r *WorkerRepository) GetByID(ctx context.Context, id string) (*domain.Work
 	query := `
 		SELECT
 			w.id, w.hostname, w.status, w.current_job_id,
-			w.jobs_completed, w.places_scraped, w.last_heartbeat, w.created_at,
+			w.jobs_completed, w.places_scraped, w.request_delay_ms, w.block_count,
+			w.seed_jobs_completed, w.seed_jobs_total, w.job_places_scraped, w.memory_bytes,
+			w.draining_since, w.drain_deadline,
+			w.last_heartbeat, w.created_at,
 			j.name as job_name
 		FROM workers w
 		LEFT JOIN jobs_queue j ON w.current_job_id = j.id
@@ -55,7 +71,10 @@ func (r *WorkerRepository) GetByID(ctx context.Context, id string) (*domain.Work
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&worker.ID, &worker.Hostname, &worker.Status, &currentJobID,
-		&worker.JobsCompleted, &worker.PlacesScraped, &worker.LastHeartbeat, &worker.CreatedAt,
+		&worker.JobsCompleted, &worker.PlacesScraped, &worker.RequestDelayMs, &worker.BlockCount,
+		&worker.SeedJobsCompleted, &worker.SeedJobsTotal, &worker.JobPlacesScraped, &worker.MemoryBytes,
+		&worker.DrainingSince, &worker.DrainDeadline,
+		&worker.LastHeartbeat, &worker.CreatedAt,
 		&worker.CurrentJobName,
 	)
 
@@ -79,7 +98,10 @@ func (r *WorkerRepository) List(ctx context.Context, params domain.WorkerListPar
 	query := `
 		SELECT
 			w.id, w.hostname, w.status, w.current_job_id,
-			w.jobs_completed, w.places_scraped, w.last_heartbeat, w.created_at,
+			w.jobs_completed, w.places_scraped, w.request_delay_ms, w.block_count,
+			w.seed_jobs_completed, w.seed_jobs_total, w.job_places_scraped, w.memory_bytes,
+			w.draining_since, w.drain_deadline,
+			w.last_heartbeat, w.created_at,
 			j.name as job_name
 		FROM workers w
 		LEFT JOIN jobs_queue j ON w.current_job_id = j.id
@@ -112,7 +134,10 @@ func (r *WorkerRepository) List(ctx context.Context, params domain.WorkerListPar
 
 		err := rows.Scan(
 			&worker.ID, &worker.Hostname, &worker.Status, &currentJobID,
-			&worker.JobsCompleted, &worker.PlacesScraped, &worker.LastHeartbeat, &worker.CreatedAt,
+			&worker.JobsCompleted, &worker.PlacesScraped, &worker.RequestDelayMs, &worker.BlockCount,
+			&worker.SeedJobsCompleted, &worker.SeedJobsTotal, &worker.JobPlacesScraped, &worker.MemoryBytes,
+			&worker.DrainingSince, &worker.DrainDeadline,
+			&worker.LastHeartbeat, &worker.CreatedAt,
 			&worker.CurrentJobName,
 		)
 		if err != nil {
@@ -146,23 +171,83 @@ func (r *WorkerRepository) UpdateStatus(ctx context.Context, id string, status d
 	return err
 }
 
-// MarkOfflineWorkers marks workers as offline if heartbeat is stale
-func (r *WorkerRepository) MarkOfflineWorkers(ctx context.Context, timeoutSeconds int) (int, error) {
+// MarkOfflineWorkers marks workers as offline if heartbeat is stale and
+// returns their IDs
+func (r *WorkerRepository) MarkOfflineWorkers(ctx context.Context, timeoutSeconds int) ([]string, error) {
 	query := `
 		UPDATE workers SET
 			status = 'offline',
-			current_job_id = NULL
+			current_job_id = NULL,
+			seed_jobs_completed = 0,
+			seed_jobs_total = 0,
+			job_places_scraped = 0,
+			draining_since = NULL,
+			drain_deadline = NULL
 		WHERE last_heartbeat < NOW() - INTERVAL '1 second' * $1
 		AND status != 'offline'
+		RETURNING id
 	`
 
-	result, err := r.db.ExecContext(ctx, query, timeoutSeconds)
+	rows, err := r.db.QueryContext(ctx, query, timeoutSeconds)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
+	defer rows.Close()
 
-	rows, err := result.RowsAffected()
-	return int(rows), err
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Drain marks a worker as draining until deadline (nil for none)
+func (r *WorkerRepository) Drain(ctx context.Context, id string, deadline *time.Time) error {
+	query := `
+		UPDATE workers SET
+			draining_since = COALESCE(draining_since, NOW()),
+			drain_deadline = $2
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, deadline)
+	return err
+}
+
+// ExpireDrains clears the current job of draining workers past their
+// deadline and returns the jobs they held
+func (r *WorkerRepository) ExpireDrains(ctx context.Context) ([]domain.ExpiredDrain, error) {
+	// old is read before the update, so it still holds the job
+	query := `
+		UPDATE workers w SET current_job_id = NULL
+		FROM workers old
+		WHERE w.id = old.id
+		AND w.drain_deadline < NOW()
+		AND w.current_job_id IS NOT NULL
+		RETURNING w.id, old.current_job_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expired []domain.ExpiredDrain
+	for rows.Next() {
+		var d domain.ExpiredDrain
+		if err := rows.Scan(&d.WorkerID, &d.JobID); err != nil {
+			return nil, err
+		}
+		expired = append(expired, d)
+	}
+
+	return expired, rows.Err()
 }
 
 // GetStats retrieves worker statistics
@@ -1,41 +1,138 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
 	"log"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
-	_ "modernc.org/sqlite"
+	sqlitedrv "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 //go:embed migrations/*.sql
 var migrationsFS embed.FS
 
+// busyTimeout is how long SQLite waits on another connection's lock before
+// returning SQLITE_BUSY
+const busyTimeout = 5 * time.Second
+
+// connectionParams are added to every DSN so that they apply to each pooled
+// connection rather than only the one a PRAGMA happened to run on.
+// Transactions begin IMMEDIATE so they take the write lock up front instead
+// of failing when a read upgrades to a write.
+var connectionParams = []string{
+	"_pragma=journal_mode(WAL)",
+	fmt.Sprintf("_pragma=busy_timeout(%d)", busyTimeout.Milliseconds()),
+	"_pragma=foreign_keys(1)",
+	"_txlock=immediate",
+}
+
+// DB is a SQLite connection pool whose writes are serialized. SQLite allows a
+// single writer at a time; queueing writers here keeps them from racing each
+// other into "database is locked" while WAL lets readers carry on.
+type DB struct {
+	*sql.DB
+
+	writeMu sync.Mutex
+}
+
 // OpenConnection opens a SQLite connection
-func OpenConnection(dsn string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", dsn)
+func OpenConnection(dsn string) (*DB, error) {
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+
+	db, err := sql.Open("sqlite", dsn+sep+strings.Join(connectionParams, "&"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	// Enable WAL mode for better concurrency
-	if _, err := db.Exec("PRAGMA journal_mode=WAL;"); err != nil {
-		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
+	return &DB{DB: db}, nil
+}
+
+// write runs fn holding the write lock, retrying while another process
+// keeps the database busy past the busy timeout
+func (d *DB) write(ctx context.Context, fn func() error) error {
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+
+	return retryBusy(ctx, fn)
+}
+
+// exec runs a write statement through write
+func (d *DB) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	var res sql.Result
+	err := d.write(ctx, func() error {
+		var err error
+		res, err = d.ExecContext(ctx, query, args...)
+		return err
+	})
+	return res, err
+}
+
+// writeTx runs fn in a transaction through write. The transaction is
+// committed if fn returns nil and rolled back otherwise.
+func (d *DB) writeTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return d.write(ctx, func() error {
+		tx, err := d.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("begin transaction: %w", err)
+		}
+		defer func() { _ = tx.Rollback() }()
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// retryBusy runs fn until it succeeds, fails with an error other than
+// SQLITE_BUSY or SQLITE_LOCKED, or runs out of attempts
+func retryBusy(ctx context.Context, fn func() error) error {
+	const attempts = 5
+	backoff := 50 * time.Millisecond
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); !isBusy(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
 	}
 
-    // Enable foreign keys
-    if _, err := db.Exec("PRAGMA foreign_keys=ON;"); err != nil {
-        return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
-    }
+	return fmt.Errorf("database still busy after %d attempts: %w", attempts, err)
+}
+
+// isBusy reports whether err is SQLite's SQLITE_BUSY or SQLITE_LOCKED
+func isBusy(err error) bool {
+	var se *sqlitedrv.Error
+	if !errors.As(err, &se) {
+		return false
+	}
 
-	return db, nil
+	// Extended result codes carry the primary code in the low byte
+	code := se.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
 }
 
 // RunMigrations runs embedded migrations
@@ -108,18 +205,22 @@ func RunMigrations(db *sql.DB) error {
 
 // Repositories holds all repository instances
 type Repositories struct {
-	Jobs    *JobRepository
-	Workers *WorkerRepository
-	Results *ResultRepository
-	Proxies *ProxyRepository
+	Jobs        *JobRepository
+	Workers     *WorkerRepository
+	Results     *ResultRepository
+	Proxies     *ProxyRepository
+	TimeSeries  *TimeSeriesRepository
+	Maintenance *MaintenanceRepository
 }
 
 // NewRepositories creates all repositories
-func NewRepositories(db *sql.DB) *Repositories {
+func NewRepositories(db *DB) *Repositories {
 	return &Repositories{
-		Jobs:    NewJobRepository(db),
-		Workers: NewWorkerRepository(db),
-		Results: NewResultRepository(db),
-		Proxies: NewProxyRepository(db),
+		Jobs:        NewJobRepository(db),
+		Workers:     NewWorkerRepository(db),
+		Results:     NewResultRepository(db),
+		Proxies:     NewProxyRepository(db),
+		TimeSeries:  NewTimeSeriesRepository(db),
+		Maintenance: NewMaintenanceRepository(db),
 	}
 }
@@ -0,0 +1,260 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+func openTestDB(t *testing.T) *Repositories {
+	t.Helper()
+
+	db, err := OpenConnection(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, RunMigrations(db.DB))
+
+	return NewRepositories(db)
+}
+
+func createTestJob(t *testing.T, repos *Repositories, priority int) *domain.Job {
+	t.Helper()
+
+	now := time.Now().UTC()
+	job := &domain.Job{
+		ID:        uuid.New(),
+		Name:      "test",
+		Status:    domain.JobStatusPending,
+		Priority:  priority,
+		Config:    domain.JobConfig{Keywords: []string{"cafe"}, Lang: "en", MaxTime: time.Minute},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, repos.Jobs.Create(context.Background(), job))
+
+	return job
+}
+
+func TestConcurrentWritesAndReads(t *testing.T) {
+	repos := openTestDB(t)
+	ctx := context.Background()
+	job := createTestJob(t, repos, 0)
+
+	const (
+		writers   = 8
+		batches   = 25
+		batchSize = 40
+		readers   = 4
+	)
+
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, writers*batches+readers*batches)
+	)
+
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for b := 0; b < batches; b++ {
+				data := make([][]byte, batchSize)
+				for i := range data {
+					data[i] = []byte(fmt.Sprintf(`{"title":"place %d-%d-%d"}`, w, b, i))
+				}
+				if err := repos.Results.CreateBatch(ctx, job.ID, uuid.New(), data); err != nil {
+					errs <- fmt.Errorf("create batch: %w", err)
+				}
+			}
+		}(w)
+	}
+
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := 0; b < batches; b++ {
+				if _, _, err := repos.Jobs.List(ctx, domain.JobListParams{Limit: 20}); err != nil {
+					errs <- fmt.Errorf("list jobs: %w", err)
+				}
+				if _, _, err := repos.Results.ListByJobID(ctx, job.ID, 50, 0); err != nil {
+					errs <- fmt.Errorf("list results: %w", err)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	count, err := repos.Results.CountByJobID(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, writers*batches*batchSize, count)
+}
+
+func TestClaimJobConcurrent(t *testing.T) {
+	repos := openTestDB(t)
+	ctx := context.Background()
+
+	const jobs = 20
+	for i := 0; i < jobs; i++ {
+		createTestJob(t, repos, i%3)
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		claimed = make(map[uuid.UUID]string)
+	)
+
+	for w := 0; w < 10; w++ {
+		wg.Add(1)
+		go func(workerID string) {
+			defer wg.Done()
+			for {
+				job, err := repos.Jobs.ClaimJob(ctx, workerID)
+				if !assert.NoError(t, err) || job == nil {
+					return
+				}
+
+				mu.Lock()
+				prev, dup := claimed[job.ID]
+				claimed[job.ID] = workerID
+				mu.Unlock()
+
+				assert.False(t, dup, "job %s claimed by %s and %s", job.ID, prev, workerID)
+				assert.Equal(t, domain.JobStatusRunning, job.Status)
+			}
+		}(fmt.Sprintf("worker-%d", w))
+	}
+
+	wg.Wait()
+	assert.Len(t, claimed, jobs)
+}
+
+func TestSoftDeleteJob(t *testing.T) {
+	repos := openTestDB(t)
+	ctx := context.Background()
+	deleted := createTestJob(t, repos, 10)
+	kept := createTestJob(t, repos, 0)
+
+	require.NoError(t, repos.Jobs.SoftDelete(ctx, deleted.ID))
+	assert.ErrorIs(t, repos.Jobs.SoftDelete(ctx, uuid.New()), sql.ErrNoRows)
+
+	job, err := repos.Jobs.GetByID(ctx, deleted.ID)
+	require.NoError(t, err)
+	require.NotNil(t, job.DeletedAt, "deleted jobs can still be fetched")
+
+	jobs, total, err := repos.Jobs.List(ctx, domain.JobListParams{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, kept.ID, jobs[0].ID)
+
+	_, total, err = repos.Jobs.List(ctx, domain.JobListParams{IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+
+	stats, err := repos.Jobs.GetStats(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, stats.Pending)
+
+	ids, err := repos.Jobs.ListDeletedBefore(ctx, time.Now().Add(time.Minute), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{deleted.ID}, ids)
+	ids, err = repos.Jobs.ListDeletedBefore(ctx, time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	// The deleted job has the higher priority but is not claimed
+	claimed, err := repos.Jobs.ClaimJob(ctx, "worker-1")
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, kept.ID, claimed.ID)
+
+	require.NoError(t, repos.Jobs.Restore(ctx, deleted.ID))
+	claimed, err = repos.Jobs.ClaimJob(ctx, "worker-2")
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, deleted.ID, claimed.ID)
+	assert.Nil(t, claimed.DeletedAt)
+}
+
+func TestJobTags(t *testing.T) {
+	repos := openTestDB(t)
+	ctx := context.Background()
+	acme := createTestJob(t, repos, 0)
+	untagged := createTestJob(t, repos, 0)
+
+	require.NoError(t, repos.Jobs.UpdateLabels(ctx, acme.ID, []string{" ACME ", "plumbers", "ACME", ""}, "march campaign"))
+
+	job, err := repos.Jobs.GetByID(ctx, acme.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ACME", "plumbers"}, job.Tags)
+	assert.Equal(t, "march campaign", job.Notes)
+
+	job, err = repos.Jobs.GetByID(ctx, untagged.ID)
+	require.NoError(t, err)
+	assert.Empty(t, job.Tags)
+
+	jobs, total, err := repos.Jobs.List(ctx, domain.JobListParams{Tags: []string{"ACME"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, acme.ID, jobs[0].ID)
+
+	// Every tag must match, case included
+	_, total, err = repos.Jobs.List(ctx, domain.JobListParams{Tags: []string{"ACME", "bakers"}})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	_, total, err = repos.Jobs.List(ctx, domain.JobListParams{Tags: []string{"acme"}})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
+
+func TestUpdateJobConfig(t *testing.T) {
+	repos := openTestDB(t)
+	ctx := context.Background()
+	job := createTestJob(t, repos, 0)
+
+	job.Config.Keywords = []string{"tea"}
+	job.Progress.TotalPlaces = 40
+	ok, err := repos.Jobs.UpdateConfig(ctx, job)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	stored, err := repos.Jobs.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tea"}, stored.Config.Keywords)
+	assert.Equal(t, 40, stored.Progress.TotalPlaces)
+
+	// Claimed since it was read: the edit loses
+	claimed, err := repos.Jobs.ClaimJob(ctx, "worker-1")
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+
+	job.Config.Keywords = []string{"coffee"}
+	ok, err = repos.Jobs.UpdateConfig(ctx, job)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	stored, err = repos.Jobs.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tea"}, stored.Config.Keywords)
+	assert.Equal(t, domain.JobStatusRunning, stored.Status)
+	assert.Equal(t, "worker-1", *stored.WorkerID)
+}
@@ -11,18 +11,49 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/secrets"
 )
 
 // JobRepository implements domain.JobRepository for SQLite
 type JobRepository struct {
-	db *sql.DB
+	db   *DB
+	keys *secrets.Keyring
 }
 
 // NewJobRepository creates a new JobRepository
-func NewJobRepository(db *sql.DB) *JobRepository {
+func NewJobRepository(db *DB) *JobRepository {
 	return &JobRepository{db: db}
 }
 
+// SetKeyring encrypts the proxies of jobs with credentials before they are
+// stored. Without a keyring they are stored in plaintext.
+func (r *JobRepository) SetKeyring(keys *secrets.Keyring) {
+	r.keys = keys
+}
+
+// marshalProxies encodes the proxies of a job as stored, encrypted if they
+// carry credentials
+func (r *JobRepository) marshalProxies(proxies []string) ([]byte, error) {
+	sealed, err := r.keys.SealURLs(proxies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt proxies: %w", err)
+	}
+	return json.Marshal(sealed)
+}
+
+// unmarshalProxies decodes the proxies of a job as stored
+func (r *JobRepository) unmarshalProxies(data string) ([]string, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	var proxies []string
+	if err := json.Unmarshal([]byte(data), &proxies); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal proxies: %w", err)
+	}
+	return r.keys.OpenAll(proxies)
+}
+
 // Create creates a new job
 func (r *JobRepository) Create(ctx context.Context, job *domain.Job) error {
 	query := `
@@ -31,13 +62,17 @@ func (r *JobRepository) Create(ctx context.Context, job *domain.Job) error {
 			keywords, lang, geo_lat, geo_lon, zoom, radius, depth,
 			fast_mode, extract_email, max_time, proxies,
 			total_places, scraped_places, failed_places,
-			created_at, updated_at
+			created_at, updated_at, tags, notes,
+			retry_on_timeout, depends_on, run_if, google_domain,
+			place_urls, backfill_of, parent_job_id, shard_count
 		) VALUES (
 			?, ?, ?, ?,
 			?, ?, ?, ?, ?, ?, ?,
 			?, ?, ?, ?,
 			?, ?, ?,
-			?, ?
+			?, ?, ?, ?,
+			?, ?, ?, ?,
+			?, ?, ?, ?
 		)
 	`
 
@@ -46,18 +81,36 @@ func (r *JobRepository) Create(ctx context.Context, job *domain.Job) error {
 		return fmt.Errorf("failed to marshal keywords: %w", err)
 	}
 
-	proxiesJSON, err := json.Marshal(job.Config.Proxies)
+	proxiesJSON, err := r.marshalProxies(job.Config.Proxies)
 	if err != nil {
-		return fmt.Errorf("failed to marshal proxies: %w", err)
+		return err
+	}
+
+	tagsJSON, err := json.Marshal(domain.NormalizeTags(job.Tags))
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	var placeURLs sql.NullString
+	if len(job.Config.PlaceURLs) > 0 {
+		raw, err := json.Marshal(job.Config.PlaceURLs)
+		if err != nil {
+			return fmt.Errorf("failed to marshal place URLs: %w", err)
+		}
+		placeURLs = sql.NullString{String: string(raw), Valid: true}
 	}
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = r.db.exec(ctx, query,
 		job.ID.String(), job.Name, job.Status, job.Priority,
 		string(keywordsJSON), job.Config.Lang, job.Config.GeoLat, job.Config.GeoLon,
 		job.Config.Zoom, job.Config.Radius, job.Config.Depth,
 		job.Config.FastMode, job.Config.ExtractEmail, job.Config.MaxTime.String(), string(proxiesJSON),
 		job.Progress.TotalPlaces, job.Progress.ScrapedPlaces, job.Progress.FailedPlaces,
 		job.CreatedAt.Format(time.RFC3339), job.UpdatedAt.Format(time.RFC3339),
+		string(tagsJSON), job.Notes,
+		job.Config.RetryOnTimeout, nullUUID(job.DependsOn), sql.NullString{String: job.RunIf, Valid: job.RunIf != ""},
+		sql.NullString{String: job.Config.GoogleDomain, Valid: job.Config.GoogleDomain != ""},
+		placeURLs, nullUUID(job.BackfillOf), nullUUID(job.ParentJobID), job.ShardCount,
 	)
 
 	return err
@@ -72,7 +125,10 @@ func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job,
 			fast_mode, extract_email, max_time, proxies,
 			total_places, scraped_places, failed_places,
 			worker_id, created_at, updated_at, started_at, completed_at,
-			error_message
+			error_message, deleted_at, tags, notes, error_code,
+			retry_on_timeout, timeout_requeued, depends_on, run_if,
+			google_domain, place_urls, backfill_of, partial_places,
+			parent_job_id, shard_count
 		FROM jobs_queue
 		WHERE id = ?
 	`
@@ -84,7 +140,10 @@ func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job,
 	var workerID sql.NullString
 	var createdAtStr, updatedAtStr string
 	var startedAtStr, completedAtStr sql.NullString
-	var errorMessage sql.NullString
+	var errorMessage, errorCode sql.NullString
+	var deletedAtStr sql.NullString
+	var tagsJSON string
+	var dependsOn, runIf, googleDomain, placeURLs, backfillOf, parentJobID sql.NullString
 
 	err := r.db.QueryRowContext(ctx, query, id.String()).Scan(
 		&idStr, &job.Name, &statusStr, &job.Priority,
@@ -93,7 +152,10 @@ func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job,
 		&job.Config.FastMode, &job.Config.ExtractEmail, &maxTimeStr, &proxiesJSON,
 		&job.Progress.TotalPlaces, &job.Progress.ScrapedPlaces, &job.Progress.FailedPlaces,
 		&workerID, &createdAtStr, &updatedAtStr, &startedAtStr, &completedAtStr,
-		&errorMessage,
+		&errorMessage, &deletedAtStr, &tagsJSON, &job.Notes, &errorCode,
+		&job.Config.RetryOnTimeout, &job.TimeoutRequeued, &dependsOn, &runIf,
+		&googleDomain, &placeURLs, &backfillOf, &job.Progress.PartialPlaces,
+		&parentJobID, &job.ShardCount,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -116,10 +178,12 @@ func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job,
 		return nil, fmt.Errorf("failed to unmarshal keywords: %w", err)
 	}
 
-	if proxiesJSON != "" {
-		if err := json.Unmarshal([]byte(proxiesJSON), &job.Config.Proxies); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal proxies: %w", err)
-		}
+	if job.Config.Proxies, err = r.unmarshalProxies(proxiesJSON); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(tagsJSON), &job.Tags); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
 	}
 
 	// Parse Duration
@@ -135,22 +199,39 @@ func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job,
 	}
 
 	// Parse Timestamps
-	job.CreatedAt, _ = time.Parse(time.RFC3339, createdAtStr)
-	job.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAtStr)
+	job.CreatedAt = parseTime(createdAtStr)
+	job.UpdatedAt = parseTime(updatedAtStr)
 
 	if startedAtStr.Valid {
-		t, _ := time.Parse(time.RFC3339, startedAtStr.String)
+		t := parseTime(startedAtStr.String)
 		job.StartedAt = &t
 	}
 
 	if completedAtStr.Valid {
-		t, _ := time.Parse(time.RFC3339, completedAtStr.String)
+		t := parseTime(completedAtStr.String)
 		job.CompletedAt = &t
 	}
 
 	if errorMessage.Valid {
 		job.ErrorMessage = &errorMessage.String
 	}
+	job.ErrorCode = domain.JobErrorCode(errorCode.String)
+
+	if deletedAtStr.Valid {
+		t := parseTime(deletedAtStr.String)
+		job.DeletedAt = &t
+	}
+
+	job.DependsOn = parseNullUUID(dependsOn)
+	job.RunIf = runIf.String
+	job.Config.GoogleDomain = googleDomain.String
+	job.BackfillOf = parseNullUUID(backfillOf)
+	job.ParentJobID = parseNullUUID(parentJobID)
+	if placeURLs.Valid {
+		if err := json.Unmarshal([]byte(placeURLs.String), &job.Config.PlaceURLs); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal place URLs: %w", err)
+		}
+	}
 
 	job.Progress.CalculatePercentage()
 
@@ -172,6 +253,34 @@ func (r *JobRepository) List(ctx context.Context, params domain.JobListParams) (
 		args = append(args, *params.WorkerID)
 	}
 
+	for _, tag := range params.Tags {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM json_each(jobs_queue.tags) WHERE value = ?)")
+		args = append(args, tag)
+	}
+
+	if params.ErrorCode != nil {
+		conditions = append(conditions, "error_code = ?")
+		args = append(args, *params.ErrorCode)
+	}
+
+	if params.DependsOn != nil {
+		conditions = append(conditions, "depends_on = ?")
+		args = append(args, params.DependsOn.String())
+	}
+
+	if params.ParentJobID != nil {
+		conditions = append(conditions, "parent_job_id = ?")
+		args = append(args, params.ParentJobID.String())
+	}
+
+	if params.Sharded {
+		conditions = append(conditions, "shard_count > 0")
+	}
+
+	if !params.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
@@ -210,7 +319,10 @@ func (r *JobRepository) List(ctx context.Context, params domain.JobListParams) (
 			fast_mode, extract_email, max_time, proxies,
 			total_places, scraped_places, failed_places,
 			worker_id, created_at, updated_at, started_at, completed_at,
-			error_message
+			error_message, deleted_at, tags, notes, error_code,
+			retry_on_timeout, timeout_requeued, depends_on, run_if,
+			google_domain, place_urls, backfill_of, partial_places,
+			parent_job_id, shard_count
 		FROM jobs_queue
 		%s
 		ORDER BY %s %s
@@ -234,7 +346,10 @@ func (r *JobRepository) List(ctx context.Context, params domain.JobListParams) (
 		var workerID sql.NullString
 		var createdAtStr, updatedAtStr string
 		var startedAtStr, completedAtStr sql.NullString
-		var errorMessage sql.NullString
+		var errorMessage, errorCode sql.NullString
+		var deletedAtStr sql.NullString
+		var tagsJSON string
+		var dependsOn, runIf, googleDomain, placeURLs, backfillOf, parentJobID sql.NullString
 
 		err := rows.Scan(
 			&idStr, &job.Name, &statusStr, &job.Priority,
@@ -243,7 +358,10 @@ func (r *JobRepository) List(ctx context.Context, params domain.JobListParams) (
 			&job.Config.FastMode, &job.Config.ExtractEmail, &maxTimeStr, &proxiesJSON,
 			&job.Progress.TotalPlaces, &job.Progress.ScrapedPlaces, &job.Progress.FailedPlaces,
 			&workerID, &createdAtStr, &updatedAtStr, &startedAtStr, &completedAtStr,
-			&errorMessage,
+			&errorMessage, &deletedAtStr, &tagsJSON, &job.Notes, &errorCode,
+			&job.Config.RetryOnTimeout, &job.TimeoutRequeued, &dependsOn, &runIf,
+			&googleDomain, &placeURLs, &backfillOf, &job.Progress.PartialPlaces,
+			&parentJobID, &job.ShardCount,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -252,27 +370,41 @@ func (r *JobRepository) List(ctx context.Context, params domain.JobListParams) (
 		job.ID, _ = uuid.Parse(idStr)
 		job.Status = domain.JobStatus(statusStr)
 		_ = json.Unmarshal([]byte(keywordsJSON), &job.Config.Keywords)
-		if proxiesJSON != "" {
-			_ = json.Unmarshal([]byte(proxiesJSON), &job.Config.Proxies)
+		if job.Config.Proxies, err = r.unmarshalProxies(proxiesJSON); err != nil {
+			return nil, 0, err
 		}
+		_ = json.Unmarshal([]byte(tagsJSON), &job.Tags)
 		job.Config.MaxTime, _ = time.ParseDuration(maxTimeStr)
 
 		if workerID.Valid {
 			job.WorkerID = &workerID.String
 		}
-		job.CreatedAt, _ = time.Parse(time.RFC3339, createdAtStr)
-		job.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAtStr)
+		job.CreatedAt = parseTime(createdAtStr)
+		job.UpdatedAt = parseTime(updatedAtStr)
 		if startedAtStr.Valid {
-			t, _ := time.Parse(time.RFC3339, startedAtStr.String)
+			t := parseTime(startedAtStr.String)
 			job.StartedAt = &t
 		}
 		if completedAtStr.Valid {
-			t, _ := time.Parse(time.RFC3339, completedAtStr.String)
+			t := parseTime(completedAtStr.String)
 			job.CompletedAt = &t
 		}
 		if errorMessage.Valid {
 			job.ErrorMessage = &errorMessage.String
 		}
+		job.ErrorCode = domain.JobErrorCode(errorCode.String)
+		if deletedAtStr.Valid {
+			t := parseTime(deletedAtStr.String)
+			job.DeletedAt = &t
+		}
+		job.DependsOn = parseNullUUID(dependsOn)
+		job.RunIf = runIf.String
+		job.Config.GoogleDomain = googleDomain.String
+		job.BackfillOf = parseNullUUID(backfillOf)
+		job.ParentJobID = parseNullUUID(parentJobID)
+		if placeURLs.Valid {
+			_ = json.Unmarshal([]byte(placeURLs.String), &job.Config.PlaceURLs)
+		}
 
 		job.Progress.CalculatePercentage()
 		jobs = append(jobs, job)
@@ -291,12 +423,18 @@ func (r *JobRepository) Update(ctx context.Context, job *domain.Job) error {
 			fast_mode = ?, extract_email = ?, max_time = ?, proxies = ?,
 			total_places = ?, scraped_places = ?, failed_places = ?,
 			worker_id = ?, started_at = ?, completed_at = ?,
-			error_message = ?, updated_at = ?
+			error_message = ?, updated_at = ?,
+			tags = ?, notes = ?, error_code = ?,
+			retry_on_timeout = ?, timeout_requeued = ?, shard_count = ?
 		WHERE id = ?
 	`
 
 	keywordsJSON, _ := json.Marshal(job.Config.Keywords)
-	proxiesJSON, _ := json.Marshal(job.Config.Proxies)
+	proxiesJSON, err := r.marshalProxies(job.Config.Proxies)
+	if err != nil {
+		return err
+	}
+	tagsJSON, _ := json.Marshal(domain.NormalizeTags(job.Tags))
 
 	var startedAtStr, completedAtStr interface{}
 	if job.StartedAt != nil {
@@ -306,7 +444,7 @@ func (r *JobRepository) Update(ctx context.Context, job *domain.Job) error {
 		completedAtStr = job.CompletedAt.Format(time.RFC3339)
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.exec(ctx, query,
 		job.Name, job.Status, job.Priority,
 		string(keywordsJSON), job.Config.Lang, job.Config.GeoLat, job.Config.GeoLon,
 		job.Config.Zoom, job.Config.Radius, job.Config.Depth,
@@ -314,6 +452,8 @@ func (r *JobRepository) Update(ctx context.Context, job *domain.Job) error {
 		job.Progress.TotalPlaces, job.Progress.ScrapedPlaces, job.Progress.FailedPlaces,
 		job.WorkerID, startedAtStr, completedAtStr,
 		job.ErrorMessage, time.Now().UTC().Format(time.RFC3339),
+		string(tagsJSON), job.Notes, sql.NullString{String: string(job.ErrorCode), Valid: job.ErrorCode != ""},
+		job.Config.RetryOnTimeout, job.TimeoutRequeued, job.ShardCount,
 		job.ID.String(),
 	)
 
@@ -323,7 +463,45 @@ func (r *JobRepository) Update(ctx context.Context, job *domain.Job) error {
 // Delete deletes a job by ID
 func (r *JobRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM jobs_queue WHERE id = ?`
-	result, err := r.db.ExecContext(ctx, query, id.String())
+	result, err := r.db.exec(ctx, query, id.String())
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// SoftDelete marks a job as deleted
+func (r *JobRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE jobs_queue SET deleted_at = COALESCE(deleted_at, ?) WHERE id = ?`
+	result, err := r.db.exec(ctx, query, time.Now().UTC().Format(time.RFC3339), id.String())
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Restore clears the deletion mark of a job
+func (r *JobRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE jobs_queue SET deleted_at = NULL WHERE id = ?`
+	result, err := r.db.exec(ctx, query, id.String())
 	if err != nil {
 		return err
 	}
@@ -339,6 +517,37 @@ func (r *JobRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// ListDeletedBefore returns up to limit jobs deleted before t, oldest first
+func (r *JobRepository) ListDeletedBefore(ctx context.Context, t time.Time, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM jobs_queue
+		WHERE deleted_at IS NOT NULL AND datetime(deleted_at) < datetime(?)
+		ORDER BY deleted_at
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, t.UTC().Format(time.RFC3339), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var idStr string
+		if err := rows.Scan(&idStr); err != nil {
+			return nil, err
+		}
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse job ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
 // UpdateStatus updates only the status of a job
 func (r *JobRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.JobStatus) error {
 	var query string
@@ -347,19 +556,71 @@ func (r *JobRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status d
 	switch status {
 	case domain.JobStatusRunning:
 		query = `UPDATE jobs_queue SET status = ?, started_at = ?, updated_at = ? WHERE id = ?`
-		_, err := r.db.ExecContext(ctx, query, status, now, now, id.String())
+		_, err := r.db.exec(ctx, query, status, now, now, id.String())
 		return err
 	case domain.JobStatusCompleted, domain.JobStatusFailed, domain.JobStatusCancelled:
 		query = `UPDATE jobs_queue SET status = ?, completed_at = ?, worker_id = NULL, updated_at = ? WHERE id = ?`
-		_, err := r.db.ExecContext(ctx, query, status, now, now, id.String())
+		_, err := r.db.exec(ctx, query, status, now, now, id.String())
 		return err
 	default:
 		query = `UPDATE jobs_queue SET status = ?, updated_at = ? WHERE id = ?`
-		_, err := r.db.ExecContext(ctx, query, status, now, id.String())
+		_, err := r.db.exec(ctx, query, status, now, id.String())
 		return err
 	}
 }
 
+// UpdateLabels replaces the tags and notes of a job
+func (r *JobRepository) UpdateLabels(ctx context.Context, id uuid.UUID, tags []string, notes string) error {
+	tagsJSON, err := json.Marshal(domain.NormalizeTags(tags))
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	query := `UPDATE jobs_queue SET tags = ?, notes = ?, updated_at = ? WHERE id = ?`
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	_, err = r.db.exec(ctx, query, string(tagsJSON), notes, now, id.String())
+	return err
+}
+
+// UpdateConfig replaces the config and place estimate of a job, if it is
+// still pending or paused, not deleted and not split into shards
+func (r *JobRepository) UpdateConfig(ctx context.Context, job *domain.Job) (bool, error) {
+	query := `
+		UPDATE jobs_queue SET
+			keywords = ?, lang = ?, geo_lat = ?, geo_lon = ?,
+			zoom = ?, radius = ?, depth = ?,
+			fast_mode = ?, extract_email = ?, max_time = ?, proxies = ?,
+			total_places = ?, retry_on_timeout = ?, updated_at = ?
+		WHERE id = ? AND status IN ('pending', 'paused')
+			AND deleted_at IS NULL AND shard_count = 0
+	`
+
+	keywordsJSON, _ := json.Marshal(job.Config.Keywords)
+	proxiesJSON, err := r.marshalProxies(job.Config.Proxies)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := r.db.exec(ctx, query,
+		string(keywordsJSON), job.Config.Lang, job.Config.GeoLat, job.Config.GeoLon,
+		job.Config.Zoom, job.Config.Radius, job.Config.Depth,
+		job.Config.FastMode, job.Config.ExtractEmail, job.Config.MaxTime.String(), string(proxiesJSON),
+		job.Progress.TotalPlaces, job.Config.RetryOnTimeout, time.Now().UTC().Format(time.RFC3339),
+		job.ID.String(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
 // UpdateProgress updates the progress of a job
 func (r *JobRepository) UpdateProgress(ctx context.Context, id uuid.UUID, progress domain.JobProgress) error {
 	query := `
@@ -367,70 +628,46 @@ func (r *JobRepository) UpdateProgress(ctx context.Context, id uuid.UUID, progre
 			total_places = ?,
 			scraped_places = ?,
 			failed_places = ?,
+			partial_places = ?,
 			updated_at = ?
 		WHERE id = ?
 	`
 	now := time.Now().UTC().Format(time.RFC3339)
 
-	_, err := r.db.ExecContext(ctx, query,
-		progress.TotalPlaces, progress.ScrapedPlaces, progress.FailedPlaces, now, id.String())
+	_, err := r.db.exec(ctx, query,
+		progress.TotalPlaces, progress.ScrapedPlaces, progress.FailedPlaces, progress.PartialPlaces, now, id.String())
 	return err
 }
 
-// ClaimJob claims a pending job for a worker (atomic operation using transaction)
+// ClaimJob claims a pending job for a worker. The pick and the update are a
+// single statement so two claimers can never take the same job.
 func (r *JobRepository) ClaimJob(ctx context.Context, workerID string) (*domain.Job, error) {
-	// Start transaction
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, err
-	}
-	defer tx.Rollback()
-
-	// Find the first pending job
-	selectQuery := `
-		SELECT id FROM jobs_queue
-		WHERE status = 'pending'
-		ORDER BY priority DESC, created_at ASC
-		LIMIT 1
-	`
-
-	var jobIDStr string
-	err = tx.QueryRowContext(ctx, selectQuery).Scan(&jobIDStr)
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, nil // No pending jobs
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	// Update the job
-	updateQuery := `
+	query := `
 		UPDATE jobs_queue SET
 			status = 'running',
 			worker_id = ?,
 			started_at = ?,
 			updated_at = ?
-		WHERE id = ? AND status = 'pending'
+		WHERE id = (
+			SELECT id FROM jobs_queue
+			WHERE status = 'pending' AND deleted_at IS NULL AND shard_count = 0
+			ORDER BY priority DESC, created_at ASC
+			LIMIT 1
+		) AND status = 'pending'
+		RETURNING id
 	`
 	now := time.Now().UTC().Format(time.RFC3339)
 
-	res, err := tx.ExecContext(ctx, updateQuery, workerID, now, now, jobIDStr)
-	if err != nil {
-		return nil, err
+	var jobIDStr string
+	err := r.db.write(ctx, func() error {
+		return r.db.QueryRowContext(ctx, query, workerID, now, now).Scan(&jobIDStr)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil // No pending jobs
 	}
-
-	rows, err := res.RowsAffected()
 	if err != nil {
 		return nil, err
 	}
-	if rows == 0 {
-		// Job was claimed by another worker between select and update
-		return nil, nil // Or return specific error
-	}
-
-	if err := tx.Commit(); err != nil {
-		return nil, err
-	}
 
 	jobID, _ := uuid.Parse(jobIDStr)
 	return r.GetByID(ctx, jobID)
@@ -440,7 +677,7 @@ func (r *JobRepository) ClaimJob(ctx context.Context, workerID string) (*domain.
 func (r *JobRepository) ReleaseJob(ctx context.Context, id uuid.UUID) error {
 	query := `
 		UPDATE jobs_queue SET
-			status = 'pending',
+			status = CASE WHEN status IN ('paused', 'cancelled') THEN status ELSE 'pending' END,
 			worker_id = NULL,
 			started_at = NULL,
 			updated_at = ?
@@ -448,15 +685,59 @@ func (r *JobRepository) ReleaseJob(ctx context.Context, id uuid.UUID) error {
 	`
 	now := time.Now().UTC().Format(time.RFC3339)
 
-	_, err := r.db.ExecContext(ctx, query, now, id.String())
+	_, err := r.db.exec(ctx, query, now, id.String())
 	return err
 }
 
+// ListRunning returns the running jobs held by workers
+func (r *JobRepository) ListRunning(ctx context.Context) ([]*domain.Job, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id FROM jobs_queue
+		WHERE status = 'running' AND worker_id IS NOT NULL AND deleted_at IS NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var idStr string
+		if err := rows.Scan(&idStr); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to parse job ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*domain.Job, 0, len(ids))
+	for _, id := range ids {
+		job, err := r.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+
+	return jobs, nil
+}
+
 // GetStats retrieves job statistics
 func (r *JobRepository) GetStats(ctx context.Context) (*domain.JobStats, error) {
 	query := `
 		SELECT
 			COUNT(*) as total,
+			SUM(CASE WHEN status = 'waiting' THEN 1 ELSE 0 END) as waiting,
 			SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END) as pending,
 			SUM(CASE WHEN status = 'queued' THEN 1 ELSE 0 END) as queued,
 			SUM(CASE WHEN status = 'running' THEN 1 ELSE 0 END) as running,
@@ -465,13 +746,109 @@ func (r *JobRepository) GetStats(ctx context.Context) (*domain.JobStats, error)
 			SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END) as failed,
 			SUM(CASE WHEN status = 'cancelled' THEN 1 ELSE 0 END) as cancelled
 		FROM jobs_queue
+		WHERE deleted_at IS NULL
 	`
 
 	stats := &domain.JobStats{}
 	err := r.db.QueryRowContext(ctx, query).Scan(
-		&stats.Total, &stats.Pending, &stats.Queued, &stats.Running,
+		&stats.Total, &stats.Waiting, &stats.Pending, &stats.Queued, &stats.Running,
 		&stats.Paused, &stats.Completed, &stats.Failed, &stats.Cancelled,
 	)
+	if err != nil || stats.Failed == 0 {
+		return stats, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT COALESCE(error_code, 'internal'), COUNT(*)
+		FROM jobs_queue
+		WHERE status = 'failed' AND deleted_at IS NULL
+		GROUP BY 1
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats.FailedByCode = make(map[domain.JobErrorCode]int)
+	for rows.Next() {
+		var (
+			code  string
+			count int
+		)
+		if err := rows.Scan(&code, &count); err != nil {
+			return nil, err
+		}
+		stats.FailedByCode[domain.ParseJobErrorCode(code)] += count
+	}
+
+	return stats, rows.Err()
+}
+
+// SetParseReport stores the parse report of a job's last run
+func (r *JobRepository) SetParseReport(ctx context.Context, id uuid.UUID, report *domain.JobParseReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal parse report: %w", err)
+	}
 
-	return stats, err
+	_, err = r.db.exec(ctx, `UPDATE jobs_queue SET parse_report = ? WHERE id = ?`, string(data), id.String())
+	return err
+}
+
+// GetParseReport returns the parse report of a job's last run, nil when
+// none was stored
+func (r *JobRepository) GetParseReport(ctx context.Context, id uuid.UUID) (*domain.JobParseReport, error) {
+	var data sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT parse_report FROM jobs_queue WHERE id = ?`, id.String()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !data.Valid) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var report domain.JobParseReport
+	if err := json.Unmarshal([]byte(data.String), &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal parse report: %w", err)
+	}
+
+	return &report, nil
+}
+
+// SetQueueMessageID stores the ID of the message that last enqueued a job
+func (r *JobRepository) SetQueueMessageID(ctx context.Context, id uuid.UUID, messageID string) error {
+	_, err := r.db.exec(ctx, `UPDATE jobs_queue SET queue_message_id = ? WHERE id = ?`, messageID, id.String())
+	return err
+}
+
+// GetQueueMessageID returns the ID of the message that last enqueued a
+// job, empty when none was stored
+func (r *JobRepository) GetQueueMessageID(ctx context.Context, id uuid.UUID) (string, error) {
+	var messageID sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT queue_message_id FROM jobs_queue WHERE id = ?`, id.String()).Scan(&messageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return messageID.String, err
+}
+
+// nullUUID is the value of an optional UUID column
+func nullUUID(id *uuid.UUID) sql.NullString {
+	if id == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: id.String(), Valid: true}
+}
+
+// parseNullUUID parses an optional UUID column, nil when unset or invalid
+func parseNullUUID(s sql.NullString) *uuid.UUID {
+	if !s.Valid {
+		return nil
+	}
+
+	id, err := uuid.Parse(s.String)
+	if err != nil {
+		return nil
+	}
+	return &id
 }
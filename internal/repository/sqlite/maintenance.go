@@ -0,0 +1,142 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// MaintenanceRepository implements domain.MaintenanceRepository for SQLite
+type MaintenanceRepository struct {
+	db *DB
+}
+
+// NewMaintenanceRepository creates a new MaintenanceRepository
+func NewMaintenanceRepository(db *DB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+// Lock always succeeds: a SQLite database has a single manager, whose
+// MaintenanceService runs one maintenance at a time already
+func (r *MaintenanceRepository) Lock(context.Context) (func(), bool, error) {
+	return func() {}, true, nil
+}
+
+// Plan analyzes and vacuums the whole database. SQLite cannot rebuild
+// indexes concurrently, so reindexing is refused.
+func (r *MaintenanceRepository) Plan(_ context.Context, opts domain.MaintenanceOptions) ([]*domain.MaintenanceStep, error) {
+	if opts.Reindex || len(opts.Indexes) > 0 {
+		return nil, fmt.Errorf("%w: reindex needs PostgreSQL", domain.ErrInvalidMaintenance)
+	}
+
+	return []*domain.MaintenanceStep{
+		{Statement: "ANALYZE", Status: domain.MaintenancePending},
+		{Statement: "VACUUM", Status: domain.MaintenancePending},
+	}, nil
+}
+
+// Exec runs the statement through the write lock: VACUUM needs the
+// database to itself
+func (r *MaintenanceRepository) Exec(ctx context.Context, step *domain.MaintenanceStep) error {
+	_, err := r.db.exec(ctx, step.Statement)
+	return err
+}
+
+// CreateRun marks interrupted runs failed and stores run
+func (r *MaintenanceRepository) CreateRun(ctx context.Context, run *domain.MaintenanceRun) error {
+	options, err := json.Marshal(run.Options)
+	if err != nil {
+		return fmt.Errorf("encode options: %w", err)
+	}
+	steps, err := json.Marshal(run.Steps)
+	if err != nil {
+		return fmt.Errorf("encode steps: %w", err)
+	}
+
+	return r.db.writeTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE maintenance_runs
+			SET status = 'failed', error = 'interrupted', finished_at = ?
+			WHERE status = 'running'
+		`, time.Now().UTC().Format(time.RFC3339))
+		if err != nil {
+			return fmt.Errorf("fail interrupted runs: %w", err)
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO maintenance_runs (trigger, options, status, steps, done, started_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, run.Trigger, string(options), run.Status, string(steps), run.Done, run.StartedAt.UTC().Format(time.RFC3339))
+		if err != nil {
+			return fmt.Errorf("create run: %w", err)
+		}
+
+		run.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+// UpdateRun stores the progress of run
+func (r *MaintenanceRepository) UpdateRun(ctx context.Context, run *domain.MaintenanceRun) error {
+	steps, err := json.Marshal(run.Steps)
+	if err != nil {
+		return fmt.Errorf("encode steps: %w", err)
+	}
+
+	var finishedAt sql.NullString
+	if run.FinishedAt != nil {
+		finishedAt = sql.NullString{String: run.FinishedAt.UTC().Format(time.RFC3339), Valid: true}
+	}
+
+	_, err = r.db.exec(ctx, `
+		UPDATE maintenance_runs
+		SET status = ?, steps = ?, done = ?, error = NULLIF(?, ''), finished_at = ?
+		WHERE id = ?
+	`, run.Status, string(steps), run.Done, run.Error, finishedAt, run.ID)
+	if err != nil {
+		return fmt.Errorf("update run: %w", err)
+	}
+
+	return nil
+}
+
+// LatestRun returns the latest run, or nil
+func (r *MaintenanceRepository) LatestRun(ctx context.Context) (*domain.MaintenanceRun, error) {
+	var (
+		run                     domain.MaintenanceRun
+		options, steps, started string
+		finishedAt              sql.NullString
+	)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, trigger, options, status, steps, done, COALESCE(error, ''), started_at, finished_at
+		FROM maintenance_runs
+		ORDER BY id DESC
+		LIMIT 1
+	`).Scan(&run.ID, &run.Trigger, &options, &run.Status, &steps, &run.Done, &run.Error, &started, &finishedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get latest run: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(options), &run.Options); err != nil {
+		return nil, fmt.Errorf("decode options: %w", err)
+	}
+	if err := json.Unmarshal([]byte(steps), &run.Steps); err != nil {
+		return nil, fmt.Errorf("decode steps: %w", err)
+	}
+	run.StartedAt, _ = time.Parse(time.RFC3339, started)
+	if finishedAt.Valid {
+		t, _ := time.Parse(time.RFC3339, finishedAt.String)
+		run.FinishedAt = &t
+	}
+
+	return &run, nil
+}
+
+var _ domain.MaintenanceRepository = (*MaintenanceRepository)(nil)
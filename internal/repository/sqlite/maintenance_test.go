@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+func TestMaintenance(t *testing.T) {
+	repos := openTestDB(t)
+	ctx := context.Background()
+	svc := service.NewMaintenanceService(repos.Maintenance)
+
+	latest, err := svc.Latest(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	_, err = svc.Run(ctx, domain.MaintenanceTriggerAPI, domain.MaintenanceOptions{Reindex: true})
+	assert.ErrorIs(t, err, domain.ErrInvalidMaintenance)
+
+	run, err := svc.Run(ctx, domain.MaintenanceTriggerSchedule, domain.MaintenanceOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, run.Done)
+
+	latest, err = svc.Latest(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, run.ID, latest.ID)
+	assert.Equal(t, domain.MaintenanceTriggerSchedule, latest.Trigger)
+	assert.Equal(t, domain.MaintenanceCompleted, latest.Status)
+	assert.NotNil(t, latest.FinishedAt)
+	require.Len(t, latest.Steps, 2)
+	for _, step := range latest.Steps {
+		assert.Equal(t, domain.MaintenanceCompleted, step.Status, step.Statement)
+	}
+
+	// A run left running by a stopped manager is failed by the next one
+	_, err = repos.Maintenance.db.ExecContext(ctx, `UPDATE maintenance_runs SET status = 'running', finished_at = NULL`)
+	require.NoError(t, err)
+	_, err = svc.Run(ctx, domain.MaintenanceTriggerAPI, domain.MaintenanceOptions{})
+	require.NoError(t, err)
+
+	var status, errMsg string
+	require.NoError(t, repos.Maintenance.db.QueryRowContext(ctx,
+		`SELECT status, error FROM maintenance_runs WHERE id = ?`, run.ID).Scan(&status, &errMsg))
+	assert.Equal(t, domain.MaintenanceFailed, status)
+	assert.Equal(t, "interrupted", errMsg)
+}
@@ -0,0 +1,4 @@
+-- Migration 0004: Rollback result batches
+
+DROP INDEX IF EXISTS idx_result_batches_job_id;
+DROP TABLE IF EXISTS result_batches;
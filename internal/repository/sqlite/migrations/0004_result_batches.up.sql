@@ -0,0 +1,12 @@
+-- Migration 0004: Result batches
+-- SQLite version for Dashboard/Web UI
+
+-- IDs of the result batches stored, so a retried submission is not stored twice
+CREATE TABLE IF NOT EXISTS result_batches (
+    batch_id TEXT PRIMARY KEY,
+    job_id TEXT NOT NULL REFERENCES jobs_queue(id) ON DELETE CASCADE,
+    results INTEGER NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_result_batches_job_id ON result_batches(job_id);
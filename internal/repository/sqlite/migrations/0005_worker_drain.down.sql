@@ -0,0 +1,4 @@
+-- Migration 0005: Rollback worker drain
+
+ALTER TABLE workers DROP COLUMN drain_deadline;
+ALTER TABLE workers DROP COLUMN draining_since;
@@ -0,0 +1,7 @@
+-- Migration 0005: Worker drain
+-- SQLite version for Dashboard/Web UI
+
+-- A draining worker takes no new jobs and exits once its current job is
+-- done. Past the deadline the manager releases the job back to pending.
+ALTER TABLE workers ADD COLUMN draining_since TEXT;
+ALTER TABLE workers ADD COLUMN drain_deadline TEXT;
@@ -0,0 +1,4 @@
+-- Migration 0006: Rollback time series indexes
+
+DROP INDEX IF EXISTS idx_jobs_queue_completed_datetime;
+DROP INDEX IF EXISTS idx_results_created_datetime;
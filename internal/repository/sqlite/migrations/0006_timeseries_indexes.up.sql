@@ -0,0 +1,9 @@
+-- Migration 0006: Time series indexes
+-- SQLite version for Dashboard/Web UI
+
+-- Range scans for /api/v2/stats/timeseries. Timestamps are stored both as
+-- RFC 3339 and as datetime('now'), datetime() compares them alike.
+CREATE INDEX IF NOT EXISTS idx_results_created_datetime ON results(datetime(created_at));
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_completed_datetime
+    ON jobs_queue(datetime(completed_at))
+    WHERE status = 'completed';
@@ -0,0 +1,4 @@
+-- Migration 0007: Rollback job soft delete
+
+DROP INDEX IF EXISTS idx_jobs_queue_deleted_at;
+ALTER TABLE jobs_queue DROP COLUMN deleted_at;
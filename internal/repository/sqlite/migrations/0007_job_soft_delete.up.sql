@@ -0,0 +1,9 @@
+-- Migration 0007: Job soft delete
+-- SQLite version for Dashboard/Web UI
+
+-- Deleting a job marks it instead of removing it; its results stay until
+-- the job is restored, purged with ?hard=true, or swept by the retention.
+ALTER TABLE jobs_queue ADD COLUMN deleted_at TEXT;
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_deleted_at
+    ON jobs_queue(deleted_at)
+    WHERE deleted_at IS NOT NULL;
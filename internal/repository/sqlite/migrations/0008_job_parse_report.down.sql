@@ -0,0 +1,3 @@
+-- Migration 0008: Rollback job parse report
+
+ALTER TABLE jobs_queue DROP COLUMN parse_report;
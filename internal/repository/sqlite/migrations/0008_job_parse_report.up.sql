@@ -0,0 +1,6 @@
+-- Migration 0008: Job parse report
+-- SQLite version for Dashboard/Web UI
+
+-- Fields the workers could not read from Google's place data in a job's
+-- last run, as JSON
+ALTER TABLE jobs_queue ADD COLUMN parse_report TEXT;
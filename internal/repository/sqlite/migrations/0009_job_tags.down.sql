@@ -0,0 +1,4 @@
+-- Migration 0009: Rollback job tags
+
+ALTER TABLE jobs_queue DROP COLUMN notes;
+ALTER TABLE jobs_queue DROP COLUMN tags;
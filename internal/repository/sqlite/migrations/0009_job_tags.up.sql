@@ -0,0 +1,6 @@
+-- Migration 0009: Job tags
+-- SQLite version for Dashboard/Web UI
+
+-- Tags as a JSON array, and free-form notes
+ALTER TABLE jobs_queue ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE jobs_queue ADD COLUMN notes TEXT NOT NULL DEFAULT '';
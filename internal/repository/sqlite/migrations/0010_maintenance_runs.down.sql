@@ -0,0 +1,3 @@
+-- Migration 0010: Rollback maintenance runs
+
+DROP TABLE IF EXISTS maintenance_runs;
@@ -0,0 +1,16 @@
+-- Migration 0010: Maintenance runs
+-- SQLite version for Dashboard/Web UI
+
+-- ANALYZE and VACUUM passes started from the admin API or the
+-- -maintenance-schedule; options and steps are JSON
+CREATE TABLE IF NOT EXISTS maintenance_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    trigger TEXT NOT NULL,
+    options TEXT NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL DEFAULT 'running',
+    steps TEXT NOT NULL DEFAULT '[]',
+    done INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TEXT NOT NULL DEFAULT (datetime('now')),
+    finished_at TEXT
+);
@@ -0,0 +1,3 @@
+-- Migration 0011: Rollback job error codes
+
+ALTER TABLE jobs_queue DROP COLUMN error_code;
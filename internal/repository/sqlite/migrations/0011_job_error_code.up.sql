@@ -0,0 +1,7 @@
+-- Migration 0011: Job error codes
+-- SQLite version for Dashboard/Web UI
+
+-- The kind of failure of a failed job; jobs that failed before are internal
+ALTER TABLE jobs_queue ADD COLUMN error_code TEXT;
+
+UPDATE jobs_queue SET error_code = 'internal' WHERE status = 'failed' AND error_code IS NULL;
@@ -0,0 +1,4 @@
+-- Migration 0012: Rollback job timeouts
+
+ALTER TABLE jobs_queue DROP COLUMN timeout_requeued;
+ALTER TABLE jobs_queue DROP COLUMN retry_on_timeout;
@@ -0,0 +1,7 @@
+-- Migration 0012: Job timeouts
+-- SQLite version for Dashboard/Web UI
+
+-- Jobs the manager times out go back to pending once when retry_on_timeout
+-- is set; timeout_requeued records that they did
+ALTER TABLE jobs_queue ADD COLUMN retry_on_timeout INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE jobs_queue ADD COLUMN timeout_requeued INTEGER NOT NULL DEFAULT 0;
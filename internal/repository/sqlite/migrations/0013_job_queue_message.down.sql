@@ -0,0 +1,3 @@
+-- Migration 0013: Rollback job queue message
+
+ALTER TABLE jobs_queue DROP COLUMN queue_message_id;
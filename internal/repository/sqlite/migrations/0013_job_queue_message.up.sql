@@ -0,0 +1,6 @@
+-- Migration 0013: Job queue message
+-- SQLite version for Dashboard/Web UI
+
+-- The ID of the last message that enqueued a job, published again by a
+-- manual requeue
+ALTER TABLE jobs_queue ADD COLUMN queue_message_id TEXT;
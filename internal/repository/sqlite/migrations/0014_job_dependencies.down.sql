@@ -0,0 +1,4 @@
+-- Migration 0014: Rollback job dependencies
+
+ALTER TABLE jobs_queue DROP COLUMN run_if;
+ALTER TABLE jobs_queue DROP COLUMN depends_on;
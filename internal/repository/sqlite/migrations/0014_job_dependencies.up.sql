@@ -0,0 +1,7 @@
+-- Migration 0014: Job dependencies
+-- SQLite version for Dashboard/Web UI
+
+-- The job a job waits for, and whether it runs once that job completed
+-- only or whatever it ended with
+ALTER TABLE jobs_queue ADD COLUMN depends_on TEXT;
+ALTER TABLE jobs_queue ADD COLUMN run_if TEXT;
@@ -0,0 +1,13 @@
+-- Migration 0015: Rollback proxy source stats
+
+ALTER TABLE proxy_sources DROP COLUMN disabled_reason;
+ALTER TABLE proxy_sources DROP COLUMN disabled_at;
+ALTER TABLE proxy_sources DROP COLUMN low_refreshes;
+ALTER TABLE proxy_sources DROP COLUMN last_fetch_at;
+ALTER TABLE proxy_sources DROP COLUMN last_error;
+ALTER TABLE proxy_sources DROP COLUMN fetch_latency_ms;
+ALTER TABLE proxy_sources DROP COLUMN passed;
+ALTER TABLE proxy_sources DROP COLUMN validated;
+ALTER TABLE proxy_sources DROP COLUMN parse_errors;
+ALTER TABLE proxy_sources DROP COLUMN fetched_count;
+ALTER TABLE proxy_sources DROP COLUMN active;
@@ -0,0 +1,16 @@
+-- Migration 0015: Proxy source stats
+-- SQLite version for Dashboard/Web UI
+
+-- What the last finished refresh of each source fetched and how many of
+-- its proxies passed validation, and whether it was disabled for it
+ALTER TABLE proxy_sources ADD COLUMN active INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE proxy_sources ADD COLUMN fetched_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE proxy_sources ADD COLUMN parse_errors INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE proxy_sources ADD COLUMN validated INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE proxy_sources ADD COLUMN passed INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE proxy_sources ADD COLUMN fetch_latency_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE proxy_sources ADD COLUMN last_error TEXT;
+ALTER TABLE proxy_sources ADD COLUMN last_fetch_at TEXT;
+ALTER TABLE proxy_sources ADD COLUMN low_refreshes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE proxy_sources ADD COLUMN disabled_at TEXT;
+ALTER TABLE proxy_sources ADD COLUMN disabled_reason TEXT;
@@ -0,0 +1,3 @@
+-- Migration 0016: Rollback Google domain
+
+ALTER TABLE jobs_queue DROP COLUMN google_domain;
@@ -0,0 +1,6 @@
+-- Migration 0016: Google domain
+-- SQLite version for Dashboard/Web UI
+
+-- The Google domain a job searches, e.g. google.de; NULL for older jobs,
+-- which searched google.com
+ALTER TABLE jobs_queue ADD COLUMN google_domain TEXT;
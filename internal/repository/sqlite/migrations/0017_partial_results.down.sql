@@ -0,0 +1,6 @@
+-- Migration 0017: Rollback partial results
+
+ALTER TABLE results DROP COLUMN backfilled_by;
+ALTER TABLE jobs_queue DROP COLUMN partial_places;
+ALTER TABLE jobs_queue DROP COLUMN backfill_of;
+ALTER TABLE jobs_queue DROP COLUMN place_urls;
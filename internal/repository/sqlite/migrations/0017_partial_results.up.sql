@@ -0,0 +1,11 @@
+-- Migration 0017: Partial results
+-- SQLite version for Dashboard/Web UI
+
+-- Place pages a job scrapes instead of searching, as a JSON array, and
+-- the job whose partial places a backfill job scrapes again
+ALTER TABLE jobs_queue ADD COLUMN place_urls TEXT;
+ALTER TABLE jobs_queue ADD COLUMN backfill_of TEXT;
+ALTER TABLE jobs_queue ADD COLUMN partial_places INTEGER NOT NULL DEFAULT 0;
+
+-- The backfill job whose scrape upgraded a partial result
+ALTER TABLE results ADD COLUMN backfilled_by TEXT;
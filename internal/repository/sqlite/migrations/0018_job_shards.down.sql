@@ -0,0 +1,5 @@
+-- Migration 0018: Rollback job shards
+
+DROP INDEX IF EXISTS idx_jobs_queue_parent_job_id;
+ALTER TABLE jobs_queue DROP COLUMN shard_count;
+ALTER TABLE jobs_queue DROP COLUMN parent_job_id;
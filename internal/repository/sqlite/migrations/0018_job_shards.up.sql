@@ -0,0 +1,9 @@
+-- Migration 0018: Job shards
+-- SQLite version for Dashboard/Web UI
+
+-- The job a shard searches a part of the keywords of, and the number of
+-- shards a job was split into
+ALTER TABLE jobs_queue ADD COLUMN parent_job_id TEXT;
+ALTER TABLE jobs_queue ADD COLUMN shard_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_parent_job_id ON jobs_queue(parent_job_id);
@@ -9,11 +9,16 @@ import (
 	"github.com/sadewadee/google-scraper/internal/domain"
 )
 
+// proxySourceColumns are the columns scanned by scanProxySource
+const proxySourceColumns = `id, url, created_at, updated_at, active, fetched_count, parse_errors,
+	validated, passed, fetch_latency_ms, last_error, last_fetch_at, low_refreshes, disabled_at,
+	disabled_reason`
+
 type ProxyRepository struct {
-	db *sql.DB
+	db *DB
 }
 
-func NewProxyRepository(db *sql.DB) *ProxyRepository {
+func NewProxyRepository(db *DB) *ProxyRepository {
 	return &ProxyRepository{db: db}
 }
 
@@ -56,14 +61,17 @@ func (r *ProxyRepository) Create(ctx context.Context, url string) (*domain.Proxy
 	`
 
 	source := &domain.ProxySource{
-		URL: url,
+		URL:              url,
+		ProxySourceStats: domain.ProxySourceStats{Active: true},
 	}
 
 	var createdAt, updatedAt string
 	// Scan into strings first, then parse
-	err := r.db.QueryRowContext(ctx, query, url).Scan(
-		&source.ID, &createdAt, &updatedAt,
-	)
+	err := r.db.write(ctx, func() error {
+		return r.db.QueryRowContext(ctx, query, url).Scan(
+			&source.ID, &createdAt, &updatedAt,
+		)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create proxy source: %w", err)
 	}
@@ -81,12 +89,12 @@ func (r *ProxyRepository) Create(ctx context.Context, url string) (*domain.Proxy
 
 func (r *ProxyRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM proxy_sources WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := r.db.exec(ctx, query, id)
 	return err
 }
 
 func (r *ProxyRepository) List(ctx context.Context) ([]*domain.ProxySource, error) {
-	query := `SELECT id, url, created_at, updated_at FROM proxy_sources ORDER BY created_at DESC`
+	query := `SELECT ` + proxySourceColumns + ` FROM proxy_sources ORDER BY created_at DESC`
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -95,15 +103,8 @@ func (r *ProxyRepository) List(ctx context.Context) ([]*domain.ProxySource, erro
 
 	var sources []*domain.ProxySource
 	for rows.Next() {
-		s := &domain.ProxySource{}
-		var createdAt, updatedAt string
-		if err := rows.Scan(&s.ID, &s.URL, &createdAt, &updatedAt); err != nil {
-			return nil, err
-		}
-		if err := scanTime(&s.CreatedAt, createdAt); err != nil {
-			return nil, err
-		}
-		if err := scanTime(&s.UpdatedAt, updatedAt); err != nil {
+		s, err := scanProxySource(rows)
+		if err != nil {
 			return nil, err
 		}
 		sources = append(sources, s)
@@ -112,21 +113,71 @@ func (r *ProxyRepository) List(ctx context.Context) ([]*domain.ProxySource, erro
 }
 
 func (r *ProxyRepository) GetByID(ctx context.Context, id int64) (*domain.ProxySource, error) {
-	query := `SELECT id, url, created_at, updated_at FROM proxy_sources WHERE id = ?`
-	s := &domain.ProxySource{}
-	var createdAt, updatedAt string
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&s.ID, &s.URL, &createdAt, &updatedAt)
+	query := `SELECT ` + proxySourceColumns + ` FROM proxy_sources WHERE id = ?`
+	s, err := scanProxySource(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("proxy source not found: %w", err)
 		}
 		return nil, err
 	}
+	return s, nil
+}
+
+func (r *ProxyRepository) UpdateStats(ctx context.Context, url string, stats *domain.ProxySourceStats) error {
+	query := `
+		UPDATE proxy_sources SET
+			active = ?, fetched_count = ?, parse_errors = ?, validated = ?, passed = ?,
+			fetch_latency_ms = ?, last_error = ?, last_fetch_at = ?, low_refreshes = ?,
+			disabled_at = ?, disabled_reason = ?, updated_at = datetime('now')
+		WHERE url = ?
+	`
+	_, err := r.db.exec(ctx, query,
+		stats.Active, stats.FetchedCount, stats.ParseErrors, stats.Validated, stats.Passed,
+		stats.FetchLatencyMs, nullText(stats.LastError), formatNullTime(stats.LastFetchAt), stats.LowRefreshes,
+		formatNullTime(stats.DisabledAt), nullText(stats.DisabledReason), url,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update proxy source stats: %w", err)
+	}
+	return nil
+}
+
+func scanProxySource(row interface{ Scan(...any) error }) (*domain.ProxySource, error) {
+	s := &domain.ProxySource{}
+	var createdAt, updatedAt string
+	var lastError, lastFetchAt, disabledAt, disabledReason sql.NullString
+
+	err := row.Scan(&s.ID, &s.URL, &createdAt, &updatedAt, &s.Active, &s.FetchedCount,
+		&s.ParseErrors, &s.Validated, &s.Passed, &s.FetchLatencyMs, &lastError, &lastFetchAt,
+		&s.LowRefreshes, &disabledAt, &disabledReason)
+	if err != nil {
+		return nil, err
+	}
 	if err := scanTime(&s.CreatedAt, createdAt); err != nil {
 		return nil, err
 	}
 	if err := scanTime(&s.UpdatedAt, updatedAt); err != nil {
 		return nil, err
 	}
+
+	s.LastError = lastError.String
+	s.DisabledReason = disabledReason.String
+	s.LastFetchAt = parseNullTime(lastFetchAt)
+	s.DisabledAt = parseNullTime(disabledAt)
+
 	return s, nil
 }
+
+// nullText stores an empty string as NULL
+func nullText(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// formatNullTime stores an optional timestamp as RFC 3339 text
+func formatNullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
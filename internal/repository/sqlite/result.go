@@ -3,6 +3,8 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -11,13 +13,17 @@ import (
 	"github.com/sadewadee/google-scraper/internal/domain"
 )
 
+// jobScope matches the results of a job and, for a sharded job, those of
+// its shards. It takes the ID of the job twice.
+const jobScope = `job_id IN (SELECT id FROM jobs_queue WHERE id = ? OR parent_job_id = ?)`
+
 // ResultRepository implements domain.ResultRepository for SQLite
 type ResultRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 // NewResultRepository creates a new ResultRepository
-func NewResultRepository(db *sql.DB) *ResultRepository {
+func NewResultRepository(db *DB) *ResultRepository {
 	return &ResultRepository{db: db}
 }
 
@@ -26,46 +32,113 @@ func (r *ResultRepository) Create(ctx context.Context, jobID uuid.UUID, data []b
 	query := `INSERT INTO results (job_id, data, created_at) VALUES (?, ?, ?)`
 	now := time.Now().UTC().Format(time.RFC3339)
 
-	_, err := r.db.ExecContext(ctx, query, jobID.String(), string(data), now)
+	_, err := r.db.exec(ctx, query, jobID.String(), string(data), now)
 	return err
 }
 
-// CreateBatch creates multiple results in a batch
-func (r *ResultRepository) CreateBatch(ctx context.Context, jobID uuid.UUID, data [][]byte) error {
+// CreateBatch creates multiple results in a batch, recording batchID in
+// the same transaction so a batch is stored at most once
+func (r *ResultRepository) CreateBatch(ctx context.Context, jobID, batchID uuid.UUID, data [][]byte) error {
 	if len(data) == 0 {
 		return nil
 	}
 
-	// SQLite has limit on number of variables. Split into chunks if necessary.
-	// Safe batch size: 100
-	batchSize := 100
-	for i := 0; i < len(data); i += batchSize {
-		end := i + batchSize
-		if end > len(data) {
-			end = len(data)
+	return r.db.writeTx(ctx, func(tx *sql.Tx) error {
+		if batchID != uuid.Nil {
+			res, err := tx.ExecContext(ctx,
+				`INSERT OR IGNORE INTO result_batches (batch_id, job_id, results, created_at) VALUES (?, ?, ?, ?)`,
+				batchID.String(), jobID.String(), len(data), time.Now().UTC().Format(time.RFC3339))
+			if err != nil {
+				return fmt.Errorf("record result batch: %w", err)
+			}
+			if n, err := res.RowsAffected(); err == nil && n == 0 {
+				return domain.ErrBatchAlreadyStored
+			}
 		}
 
-		batch := data[i:end]
-		valueStrings := make([]string, 0, len(batch))
-		valueArgs := make([]interface{}, 0, len(batch)*3)
-		now := time.Now().UTC().Format(time.RFC3339)
-		jobIDStr := jobID.String()
+		data, err := upgradePartialResults(ctx, tx, jobID, data)
+		if err != nil {
+			return err
+		}
+
+		// SQLite has limit on number of variables. Split into chunks if necessary.
+		// Safe batch size: 100
+		batchSize := 100
+		for i := 0; i < len(data); i += batchSize {
+			end := i + batchSize
+			if end > len(data) {
+				end = len(data)
+			}
+
+			batch := data[i:end]
+			valueStrings := make([]string, 0, len(batch))
+			valueArgs := make([]interface{}, 0, len(batch)*3)
+			now := time.Now().UTC().Format(time.RFC3339)
+			jobIDStr := jobID.String()
+
+			for _, d := range batch {
+				valueStrings = append(valueStrings, "(?, ?, ?)")
+				valueArgs = append(valueArgs, jobIDStr, string(d), now)
+			}
 
-		for _, d := range batch {
-			valueStrings = append(valueStrings, "(?, ?, ?)")
-			valueArgs = append(valueArgs, jobIDStr, string(d), now)
+			query := fmt.Sprintf("INSERT INTO results (job_id, data, created_at) VALUES %s",
+				strings.Join(valueStrings, ","))
+
+			if _, err := tx.ExecContext(ctx, query, valueArgs...); err != nil {
+				return err
+			}
 		}
 
-		query := fmt.Sprintf("INSERT INTO results (job_id, data, created_at) VALUES %s",
-			strings.Join(valueStrings, ","))
+		return nil
+	})
+}
+
+// upgradePartialResults stores the results of a backfill job over the
+// partial results of the job it backfills, matched by data ID, CID or
+// link, and returns those that matched none
+func upgradePartialResults(ctx context.Context, tx *sql.Tx, jobID uuid.UUID, data [][]byte) ([][]byte, error) {
+	var backfillOf sql.NullString
+	err := tx.QueryRowContext(ctx, `SELECT backfill_of FROM jobs_queue WHERE id = ?`, jobID.String()).Scan(&backfillOf)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !backfillOf.Valid) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get job backfill_of: %w", err)
+	}
 
-		_, err := r.db.ExecContext(ctx, query, valueArgs...)
+	var rest [][]byte
+	for _, d := range data {
+		var place struct {
+			DataID       string `json:"data_id"`
+			Cid          string `json:"cid"`
+			Link         string `json:"link"`
+			Completeness string `json:"data_completeness"`
+		}
+		if err := json.Unmarshal(d, &place); err != nil || place.Completeness == domain.CompletenessPartial {
+			rest = append(rest, d)
+			continue
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			UPDATE results SET data = ?, backfilled_by = ?
+			WHERE id = (
+				SELECT id FROM results
+				WHERE `+jobScope+` AND json_extract(data, '$.data_completeness') = 'partial'
+					AND ((json_extract(data, '$.data_id') = ? AND ? <> '') OR (json_extract(data, '$.cid') = ? AND ? <> '')
+						OR json_extract(data, '$.link') = ?)
+				ORDER BY id
+				LIMIT 1
+			)
+		`, string(d), jobID.String(), backfillOf.String, backfillOf.String, place.DataID, place.DataID, place.Cid, place.Cid, place.Link)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("upgrade partial result: %w", err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			rest = append(rest, d)
 		}
 	}
 
-	return nil
+	return rest, nil
 }
 
 // ListAll retrieves all results with pagination (global view)
@@ -101,16 +174,16 @@ func (r *ResultRepository) ListAll(ctx context.Context, limit, offset int) ([][]
 // ListByJobID retrieves results for a job with pagination
 func (r *ResultRepository) ListByJobID(ctx context.Context, jobID uuid.UUID, limit, offset int) ([][]byte, int, error) {
 	// First get total count
-	countQuery := `SELECT COUNT(*) FROM results WHERE job_id = ?`
+	countQuery := `SELECT COUNT(*) FROM results WHERE ` + jobScope
 	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, jobID.String()).Scan(&total)
+	err := r.db.QueryRowContext(ctx, countQuery, jobID.String(), jobID.String()).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// Get results
-	query := `SELECT data FROM results WHERE job_id = ? ORDER BY id ASC LIMIT ? OFFSET ?`
-	rows, err := r.db.QueryContext(ctx, query, jobID.String(), limit, offset)
+	query := `SELECT data FROM results WHERE ` + jobScope + ` ORDER BY id ASC LIMIT ? OFFSET ?`
+	rows, err := r.db.QueryContext(ctx, query, jobID.String(), jobID.String(), limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -130,16 +203,68 @@ func (r *ResultRepository) ListByJobID(ctx context.Context, jobID uuid.UUID, lim
 
 // CountByJobID counts results for a job
 func (r *ResultRepository) CountByJobID(ctx context.Context, jobID uuid.UUID) (int, error) {
-	query := `SELECT COUNT(*) FROM results WHERE job_id = ?`
+	query := `SELECT COUNT(*) FROM results WHERE ` + jobScope
 	var count int
-	err := r.db.QueryRowContext(ctx, query, jobID.String()).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, jobID.String(), jobID.String()).Scan(&count)
 	return count, err
 }
 
+// CountsByJobID counts the results of a job for its progress
+func (r *ResultRepository) CountsByJobID(ctx context.Context, jobID uuid.UUID) (*domain.ResultCounts, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM results WHERE ` + jobScope + `),
+			(SELECT COUNT(*) FROM results WHERE ` + jobScope + ` AND json_extract(data, '$.data_completeness') = 'partial'),
+			(SELECT COUNT(*) FROM results WHERE backfilled_by = ?)
+	`
+	var counts domain.ResultCounts
+	id := jobID.String()
+	if err := r.db.QueryRowContext(ctx, query, id, id, id, id, id).Scan(&counts.Stored, &counts.Partial, &counts.Backfilled); err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
+
+// ListPartialLinks returns the place links of the partial results of a job
+func (r *ResultRepository) ListPartialLinks(ctx context.Context, jobID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT json_extract(data, '$.link') AS link
+		FROM results
+		WHERE ` + jobScope + ` AND json_extract(data, '$.data_completeness') = 'partial' AND link <> ''
+		GROUP BY link
+		ORDER BY MIN(id)
+	`
+	rows, err := r.db.QueryContext(ctx, query, jobID.String(), jobID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []string
+	for rows.Next() {
+		var link string
+		if err := rows.Scan(&link); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// LastCreatedAt returns when the latest result of a job was stored
+func (r *ResultRepository) LastCreatedAt(ctx context.Context, jobID uuid.UUID) (*time.Time, error) {
+	query := `SELECT MAX(created_at) FROM results WHERE ` + jobScope
+	var last sql.NullString
+	if err := r.db.QueryRowContext(ctx, query, jobID.String(), jobID.String()).Scan(&last); err != nil {
+		return nil, err
+	}
+	return parseNullTime(last), nil
+}
+
 // DeleteByJobID deletes all results for a job
 func (r *ResultRepository) DeleteByJobID(ctx context.Context, jobID uuid.UUID) error {
 	query := `DELETE FROM results WHERE job_id = ?`
-	_, err := r.db.ExecContext(ctx, query, jobID.String())
+	_, err := r.db.exec(ctx, query, jobID.String())
 	return err
 }
 
@@ -159,8 +284,8 @@ func (r *ResultRepository) GetPlaceStats(ctx context.Context) (*domain.PlaceStat
 
 // StreamByJobID streams results for a job
 func (r *ResultRepository) StreamByJobID(ctx context.Context, jobID uuid.UUID, fn func(data []byte) error) error {
-	query := `SELECT data FROM results WHERE job_id = ? ORDER BY id ASC`
-	rows, err := r.db.QueryContext(ctx, query, jobID.String())
+	query := `SELECT data FROM results WHERE ` + jobScope + ` ORDER BY id ASC`
+	rows, err := r.db.QueryContext(ctx, query, jobID.String(), jobID.String())
 	if err != nil {
 		return err
 	}
@@ -178,3 +303,41 @@ func (r *ResultRepository) StreamByJobID(ctx context.Context, jobID uuid.UUID, f
 
 	return rows.Err()
 }
+
+// StoredResult is a result row as stored, for moving results to another
+// database
+type StoredResult struct {
+	ID        int64
+	JobID     *uuid.UUID // nil for results stored without a valid job
+	Data      []byte
+	CreatedAt time.Time
+}
+
+// ListAfter returns up to limit results with an ID above afterID, in ID
+// order, so all results can be read in batches
+func (r *ResultRepository) ListAfter(ctx context.Context, afterID int64, limit int) ([]*StoredResult, error) {
+	query := `SELECT id, job_id, data, created_at FROM results WHERE id > ? ORDER BY id LIMIT ?`
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*StoredResult
+	for rows.Next() {
+		var res StoredResult
+		var jobID sql.NullString
+		var data, createdAt string
+		if err := rows.Scan(&res.ID, &jobID, &data, &createdAt); err != nil {
+			return nil, err
+		}
+		if id, err := uuid.Parse(jobID.String); err == nil {
+			res.JobID = &id
+		}
+		res.Data = []byte(data)
+		res.CreatedAt = parseTime(createdAt)
+		results = append(results, &res)
+	}
+
+	return results, rows.Err()
+}
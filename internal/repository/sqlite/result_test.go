@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+func TestResultListAfter(t *testing.T) {
+	repos := openTestDB(t)
+	ctx := context.Background()
+	job := createTestJob(t, repos, 0)
+
+	require.NoError(t, repos.Results.CreateBatch(ctx, job.ID, uuid.New(), [][]byte{[]byte(`{"title":"a"}`), []byte(`{"title":"b"}`)}))
+	// Rows written by hand or by older versions carry datetime('now')
+	_, err := repos.Results.db.ExecContext(ctx, `INSERT INTO results (job_id, data) VALUES (NULL, '{"title":"c"}')`)
+	require.NoError(t, err)
+
+	first, err := repos.Results.ListAfter(ctx, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.Equal(t, job.ID, *first[0].JobID)
+	assert.JSONEq(t, `{"title":"a"}`, string(first[0].Data))
+
+	rest, err := repos.Results.ListAfter(ctx, first[1].ID, 2)
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.Nil(t, rest[0].JobID)
+	assert.WithinDuration(t, time.Now(), rest[0].CreatedAt, time.Minute)
+}
+
+func TestResultLastCreatedAt(t *testing.T) {
+	repos := openTestDB(t)
+	ctx := context.Background()
+	job := createTestJob(t, repos, 0)
+
+	last, err := repos.Results.LastCreatedAt(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Nil(t, last)
+
+	require.NoError(t, repos.Results.CreateBatch(ctx, job.ID, uuid.New(), [][]byte{[]byte(`{"title":"a"}`)}))
+	last, err = repos.Results.LastCreatedAt(ctx, job.ID)
+	require.NoError(t, err)
+	require.NotNil(t, last)
+	assert.WithinDuration(t, time.Now(), *last, time.Minute)
+}
+
+func TestResultBackfillUpgradesPartial(t *testing.T) {
+	repos := openTestDB(t)
+	ctx := context.Background()
+	source := createTestJob(t, repos, 0)
+
+	require.NoError(t, repos.Results.CreateBatch(ctx, source.ID, uuid.New(), [][]byte{
+		[]byte(`{"title":"a","data_id":"0x1:0x2","link":"https://maps/a"}`),
+		[]byte(`{"title":"b","data_id":"0x3:0x4","link":"https://maps/b","data_completeness":"partial"}`),
+		[]byte(`{"title":"c","data_id":"0x5:0x6","link":"https://maps/c","data_completeness":"partial"}`),
+	}))
+
+	links, err := repos.Results.ListPartialLinks(ctx, source.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://maps/b", "https://maps/c"}, links)
+
+	backfill := &domain.Job{
+		ID: uuid.New(), Name: "test (backfill)", Status: domain.JobStatusPending, BackfillOf: &source.ID,
+		Config:    domain.JobConfig{PlaceURLs: links, Lang: "en", MaxTime: time.Minute},
+		CreatedAt: time.Now().UTC(), UpdatedAt: time.Now().UTC(),
+	}
+	require.NoError(t, repos.Jobs.Create(ctx, backfill))
+
+	stored, err := repos.Jobs.GetByID(ctx, backfill.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &source.ID, stored.BackfillOf)
+	assert.Equal(t, links, stored.Config.PlaceURLs)
+
+	// b is upgraded in place, d matches no partial result of the source
+	require.NoError(t, repos.Results.CreateBatch(ctx, backfill.ID, uuid.New(), [][]byte{
+		[]byte(`{"title":"b","data_id":"0x3:0x4","link":"https://maps/b?full","phone":"1"}`),
+		[]byte(`{"title":"d","data_id":"0x7:0x8","link":"https://maps/d"}`),
+	}))
+
+	counts, err := repos.Results.CountsByJobID(ctx, source.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ResultCounts{Stored: 3, Partial: 1}, *counts)
+	assert.Equal(t, domain.JobProgress{ScrapedPlaces: 2, PartialPlaces: 1}, counts.Progress())
+
+	counts, err = repos.Results.CountsByJobID(ctx, backfill.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ResultCounts{Stored: 1, Backfilled: 1}, *counts)
+	assert.Equal(t, 2, counts.Progress().ScrapedPlaces)
+}
@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// timeSeriesSource is where a metric is counted. SQLite has no normalized
+// listings, places and emails are counted from the raw results.
+type timeSeriesSource struct {
+	table  string
+	at     string            // Timestamp column, indexed as datetime(at)
+	where  string            // Additional condition
+	amount string            // Counted per row
+	groups map[string]string // group_by -> expression
+}
+
+var timeSeriesSources = map[string]timeSeriesSource{
+	domain.MetricPlaces: {
+		table:  "results",
+		at:     "created_at",
+		amount: "1",
+		groups: map[string]string{
+			domain.GroupByJob:      "COALESCE(job_id, '')",
+			domain.GroupByCategory: "COALESCE(json_extract(data, '$.category'), '')",
+		},
+	},
+	// Emails are not deduplicated across results in SQLite
+	domain.MetricEmails: {
+		table:  "results",
+		at:     "created_at",
+		amount: "COALESCE(json_array_length(data, '$.emails'), 0)",
+		groups: map[string]string{
+			domain.GroupByJob:      "COALESCE(job_id, '')",
+			domain.GroupByCategory: "COALESCE(json_extract(data, '$.category'), '')",
+		},
+	},
+	domain.MetricJobsCompleted: {
+		table:  "jobs_queue",
+		at:     "completed_at",
+		where:  "status = 'completed'",
+		amount: "1",
+	},
+}
+
+// TimeSeriesRepository counts the metrics of the stats time series per day
+type TimeSeriesRepository struct {
+	db *DB
+}
+
+// NewTimeSeriesRepository creates a new TimeSeriesRepository
+func NewTimeSeriesRepository(db *DB) *TimeSeriesRepository {
+	return &TimeSeriesRepository{db: db}
+}
+
+// Intervals implements domain.TimeSeriesRepository; SQLite only counts per
+// day
+func (r *TimeSeriesRepository) Intervals() []string {
+	return []string{domain.IntervalDay}
+}
+
+// TimeSeries implements domain.TimeSeriesRepository
+func (r *TimeSeriesRepository) TimeSeries(ctx context.Context, q domain.TimeSeriesQuery) ([]domain.TimeSeriesRow, error) {
+	src, ok := timeSeriesSources[q.Metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", q.Metric)
+	}
+	if q.Interval != domain.IntervalDay {
+		return nil, fmt.Errorf("interval %s is not supported by SQLite", q.Interval)
+	}
+
+	where := fmt.Sprintf("datetime(%s) >= ? AND datetime(%s) < ?", src.at, src.at)
+	if src.where != "" {
+		where += " AND " + src.where
+	}
+
+	from := q.From.UTC().Format(time.DateTime)
+	to := q.To.UTC().Format(time.DateTime)
+
+	if q.GroupBy == "" {
+		query := fmt.Sprintf(`
+			SELECT date(%s), '', 0, SUM(%s)
+			FROM %s
+			WHERE %s
+			GROUP BY 1`, src.at, src.amount, src.table, where)
+
+		return r.query(ctx, query, from, to)
+	}
+
+	group, ok := src.groups[q.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("%s cannot be grouped by %s", q.Metric, q.GroupBy)
+	}
+
+	query := fmt.Sprintf(`
+		WITH f AS (
+			SELECT date(%s) AS bucket, %s AS grp, %s AS n
+			FROM %s
+			WHERE %s
+		), top AS (
+			SELECT grp FROM f GROUP BY grp ORDER BY SUM(n) DESC, grp LIMIT ?
+		)
+		SELECT f.bucket, CASE WHEN top.grp IS NULL THEN '' ELSE f.grp END, top.grp IS NULL, SUM(f.n)
+		FROM f
+		LEFT JOIN top ON top.grp = f.grp
+		GROUP BY 1, 2, 3`, src.at, group, src.amount, src.table, where)
+
+	return r.query(ctx, query, from, to, q.MaxSeries)
+}
+
+func (r *TimeSeriesRepository) query(ctx context.Context, query string, args ...any) ([]domain.TimeSeriesRow, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count time series: %w", err)
+	}
+	defer rows.Close()
+
+	var result []domain.TimeSeriesRow
+	for rows.Next() {
+		var (
+			row    domain.TimeSeriesRow
+			bucket string
+		)
+		if err := rows.Scan(&bucket, &row.Group, &row.Other, &row.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan time series: %w", err)
+		}
+
+		row.Bucket, err = time.Parse(time.DateOnly, bucket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse time series bucket %q: %w", bucket, err)
+		}
+
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
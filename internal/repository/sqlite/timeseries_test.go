@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+func TestTimeSeries(t *testing.T) {
+	repos := openTestDB(t)
+	ctx := context.Background()
+
+	cafes := createTestJob(t, repos, 0)
+	bars := createTestJob(t, repos, 0)
+
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	// Results stored at hour h of day d, in both timestamp formats
+	store := func(job *domain.Job, d, h int, data string) {
+		require.NoError(t, repos.Results.CreateBatch(ctx, job.ID, uuid.Nil, [][]byte{[]byte(data)}))
+		at := day.AddDate(0, 0, d).Add(time.Duration(h) * time.Hour)
+		layout := time.RFC3339
+		if h%2 == 1 {
+			layout = time.DateTime
+		}
+		_, err := repos.Results.db.ExecContext(ctx,
+			`UPDATE results SET created_at = ? WHERE id = (SELECT MAX(id) FROM results)`, at.Format(layout))
+		require.NoError(t, err)
+	}
+
+	store(cafes, 0, 9, `{"category":"Cafe","emails":["a@x.com","b@x.com"]}`)
+	store(cafes, 0, 23, `{"category":"Cafe","emails":[]}`)
+	store(cafes, 2, 1, `{"category":"Bakery"}`)
+	store(bars, 2, 12, `{"category":"Bar","emails":["c@x.com"]}`)
+	store(bars, 3, 12, `{"category":"Bar"}`) // Outside the range
+
+	svc := service.NewTimeSeriesService(repos.TimeSeries, repos.Jobs)
+
+	points := func(s *domain.Series) []int64 {
+		values := make([]int64, len(s.Points))
+		for i, p := range s.Points {
+			values[i] = p.Value
+		}
+		return values
+	}
+
+	q := domain.TimeSeriesQuery{
+		Metric:   domain.MetricPlaces,
+		Interval: domain.IntervalDay,
+		From:     day,
+		To:       day.AddDate(0, 0, 3),
+	}
+
+	ts, err := svc.TimeSeries(ctx, q)
+	require.NoError(t, err)
+	require.Len(t, ts.Series, 1)
+	assert.Equal(t, []int64{2, 0, 2}, points(ts.Series[0]))
+	assert.Equal(t, day.AddDate(0, 0, 1), ts.Series[0].Points[1].Time)
+	assert.Nil(t, ts.Other)
+
+	q.Metric = domain.MetricEmails
+	ts, err = svc.TimeSeries(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 0, 1}, points(ts.Series[0]))
+
+	q.Metric = domain.MetricPlaces
+	q.GroupBy = domain.GroupByCategory
+	q.MaxSeries = 2
+	ts, err = svc.TimeSeries(ctx, q)
+	require.NoError(t, err)
+	require.Len(t, ts.Series, 2)
+	assert.Equal(t, "Cafe", ts.Series[0].Key)
+	assert.Equal(t, int64(2), ts.Series[0].Total)
+	assert.Equal(t, "Bakery", ts.Series[1].Key, "ties are broken by key")
+	require.NotNil(t, ts.Other)
+	assert.Equal(t, []int64{0, 0, 1}, points(ts.Other))
+
+	q.GroupBy = domain.GroupByJob
+	ts, err = svc.TimeSeries(ctx, q)
+	require.NoError(t, err)
+	require.Len(t, ts.Series, 2)
+	assert.Equal(t, cafes.ID.String(), ts.Series[0].Key)
+	assert.Equal(t, "test", ts.Series[0].Label)
+	assert.Equal(t, []int64{0, 0, 1}, points(ts.Series[1]))
+
+	q.Interval = domain.IntervalWeek
+	_, err = svc.TimeSeries(ctx, q)
+	assert.ErrorIs(t, err, service.ErrInvalidTimeSeries)
+}
@@ -13,11 +13,11 @@ import (
 
 // WorkerRepository implements domain.WorkerRepository for SQLite
 type WorkerRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 // NewWorkerRepository creates a new WorkerRepository
-func NewWorkerRepository(db *sql.DB) *WorkerRepository {
+func NewWorkerRepository(db *DB) *WorkerRepository {
 	return &WorkerRepository{db: db}
 }
 
@@ -33,6 +33,7 @@ func (r *WorkerRepository) Upsert(ctx context.Context, worker *domain.Worker) er
 			status = excluded.status,
 			current_job_id = excluded.current_job_id,
 			last_heartbeat = excluded.last_heartbeat
+		RETURNING draining_since, drain_deadline
 	`
 
 	jobID := sql.NullString{}
@@ -43,12 +44,21 @@ func (r *WorkerRepository) Upsert(ctx context.Context, worker *domain.Worker) er
 
 	now := time.Now().UTC().Format(time.RFC3339)
 
-	_, err := r.db.ExecContext(ctx, query,
-		worker.ID, worker.Hostname, worker.Status, jobID,
-		now, now,
-	)
+	var drainingSince, drainDeadline sql.NullString
+	err := r.db.write(ctx, func() error {
+		return r.db.QueryRowContext(ctx, query,
+			worker.ID, worker.Hostname, worker.Status, jobID,
+			now, now,
+		).Scan(&drainingSince, &drainDeadline)
+	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	worker.DrainingSince = parseNullTime(drainingSince)
+	worker.DrainDeadline = parseNullTime(drainDeadline)
+
+	return nil
 }
 
 // GetByID retrieves a worker by ID
@@ -57,6 +67,7 @@ func (r *WorkerRepository) GetByID(ctx context.Context, id string) (*domain.Work
 		SELECT
 			w.id, w.hostname, w.status, w.current_job_id,
 			w.jobs_completed, w.places_scraped, w.last_heartbeat, w.created_at,
+			w.draining_since, w.drain_deadline,
 			j.name
 		FROM workers w
 		LEFT JOIN jobs_queue j ON w.current_job_id = j.id
@@ -67,10 +78,12 @@ func (r *WorkerRepository) GetByID(ctx context.Context, id string) (*domain.Work
 	var currentJobID sql.NullString
 	var currentJobName sql.NullString
 	var lastHeartbeatStr, createdAtStr string
+	var drainingSince, drainDeadline sql.NullString
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&worker.ID, &worker.Hostname, &worker.Status, &currentJobID,
 		&worker.JobsCompleted, &worker.PlacesScraped, &lastHeartbeatStr, &createdAtStr,
+		&drainingSince, &drainDeadline,
 		&currentJobName,
 	)
 
@@ -91,8 +104,10 @@ func (r *WorkerRepository) GetByID(ctx context.Context, id string) (*domain.Work
 		worker.CurrentJobName = &currentJobName.String
 	}
 
-	worker.LastHeartbeat, _ = time.Parse(time.RFC3339, lastHeartbeatStr)
-	worker.CreatedAt, _ = time.Parse(time.RFC3339, createdAtStr)
+	worker.LastHeartbeat = parseTime(lastHeartbeatStr)
+	worker.CreatedAt = parseTime(createdAtStr)
+	worker.DrainingSince = parseNullTime(drainingSince)
+	worker.DrainDeadline = parseNullTime(drainDeadline)
 
 	return worker, nil
 }
@@ -103,6 +118,7 @@ func (r *WorkerRepository) List(ctx context.Context, params domain.WorkerListPar
 		SELECT
 			w.id, w.hostname, w.status, w.current_job_id,
 			w.jobs_completed, w.places_scraped, w.last_heartbeat, w.created_at,
+			w.draining_since, w.drain_deadline,
 			j.name
 		FROM workers w
 		LEFT JOIN jobs_queue j ON w.current_job_id = j.id
@@ -128,10 +144,12 @@ func (r *WorkerRepository) List(ctx context.Context, params domain.WorkerListPar
 		var currentJobID sql.NullString
 		var currentJobName sql.NullString
 		var lastHeartbeatStr, createdAtStr string
+		var drainingSince, drainDeadline sql.NullString
 
 		err := rows.Scan(
 			&worker.ID, &worker.Hostname, &worker.Status, &currentJobID,
 			&worker.JobsCompleted, &worker.PlacesScraped, &lastHeartbeatStr, &createdAtStr,
+			&drainingSince, &drainDeadline,
 			&currentJobName,
 		)
 		if err != nil {
@@ -148,8 +166,10 @@ func (r *WorkerRepository) List(ctx context.Context, params domain.WorkerListPar
 			worker.CurrentJobName = &currentJobName.String
 		}
 
-		worker.LastHeartbeat, _ = time.Parse(time.RFC3339, lastHeartbeatStr)
-		worker.CreatedAt, _ = time.Parse(time.RFC3339, createdAtStr)
+		worker.LastHeartbeat = parseTime(lastHeartbeatStr)
+		worker.CreatedAt = parseTime(createdAtStr)
+		worker.DrainingSince = parseNullTime(drainingSince)
+		worker.DrainDeadline = parseNullTime(drainDeadline)
 
 		workers = append(workers, worker)
 	}
@@ -160,7 +180,7 @@ func (r *WorkerRepository) List(ctx context.Context, params domain.WorkerListPar
 // Delete deletes a worker by ID
 func (r *WorkerRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM workers WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := r.db.exec(ctx, query, id)
 	return err
 }
 
@@ -168,28 +188,115 @@ func (r *WorkerRepository) Delete(ctx context.Context, id string) error {
 func (r *WorkerRepository) UpdateStatus(ctx context.Context, id string, status domain.WorkerStatus) error {
 	query := `UPDATE workers SET status = ?, last_heartbeat = ? WHERE id = ?`
 	now := time.Now().UTC().Format(time.RFC3339)
-	_, err := r.db.ExecContext(ctx, query, status, now, id)
+	_, err := r.db.exec(ctx, query, status, now, id)
 	return err
 }
 
-// MarkOfflineWorkers marks workers as offline if heartbeat is stale
-func (r *WorkerRepository) MarkOfflineWorkers(ctx context.Context, timeout int) (int, error) {
+// MarkOfflineWorkers marks workers as offline if heartbeat is stale and
+// returns their IDs
+func (r *WorkerRepository) MarkOfflineWorkers(ctx context.Context, timeout int) ([]string, error) {
 	query := `
 		UPDATE workers
-		SET status = 'offline'
+		SET status = 'offline', draining_since = NULL, drain_deadline = NULL
 		WHERE status != 'offline'
 		AND datetime(last_heartbeat) < datetime('now', '-' || ? || ' seconds')
+		RETURNING id
 	`
 	// Note: timeout is int seconds.
 	// SQLite datetime modifiers: '-30 seconds'
 
-	result, err := r.db.ExecContext(ctx, query, fmt.Sprintf("%d", timeout))
+	var ids []string
+	err := r.db.write(ctx, func() error {
+		ids = nil
+
+		rows, err := r.db.QueryContext(ctx, query, fmt.Sprintf("%d", timeout))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+
+		return rows.Err()
+	})
+
+	return ids, err
+}
+
+// Drain marks a worker as draining until deadline (nil for none)
+func (r *WorkerRepository) Drain(ctx context.Context, id string, deadline *time.Time) error {
+	query := `
+		UPDATE workers SET
+			draining_since = COALESCE(draining_since, ?),
+			drain_deadline = ?
+		WHERE id = ?
+	`
+
+	deadlineStr := sql.NullString{}
+	if deadline != nil {
+		deadlineStr.String = deadline.UTC().Format(time.RFC3339)
+		deadlineStr.Valid = true
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	_, err := r.db.exec(ctx, query, now, deadlineStr, id)
+	return err
+}
+
+// ExpireDrains clears the current job of draining workers past their
+// deadline and returns the jobs they held
+func (r *WorkerRepository) ExpireDrains(ctx context.Context) ([]domain.ExpiredDrain, error) {
+	var expired []domain.ExpiredDrain
+	err := r.db.writeTx(ctx, func(tx *sql.Tx) error {
+		expired = nil
+
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id, current_job_id FROM workers
+			WHERE current_job_id IS NOT NULL
+			AND datetime(drain_deadline) < datetime('now')
+		`)
+		if err != nil {
+			return err
+		}
+
+		for rows.Next() {
+			var workerID, jobID string
+			if err := rows.Scan(&workerID, &jobID); err != nil {
+				rows.Close()
+				return err
+			}
+
+			uid, err := uuid.Parse(jobID)
+			if err != nil {
+				continue
+			}
+			expired = append(expired, domain.ExpiredDrain{WorkerID: workerID, JobID: uid})
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, d := range expired {
+			if _, err := tx.ExecContext(ctx, `UPDATE workers SET current_job_id = NULL WHERE id = ?`, d.WorkerID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	rows, err := result.RowsAffected()
-	return int(rows), err
+	return expired, nil
 }
 
 // GetStats retrieves worker statistics
@@ -219,6 +326,31 @@ func (r *WorkerRepository) IncrementStats(ctx context.Context, id string, jobsCo
 			places_scraped = places_scraped + ?
 		WHERE id = ?
 	`
-	_, err := r.db.ExecContext(ctx, query, jobsCompleted, placesScraped, id)
+	_, err := r.db.exec(ctx, query, jobsCompleted, placesScraped, id)
 	return err
 }
+
+// parseTime parses a timestamp column. The repositories write RFC 3339,
+// column defaults datetime('now'); the zero time is returned for anything
+// else.
+func parseTime(s string) time.Time {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	t, _ := time.Parse(time.DateTime, s)
+	return t
+}
+
+// parseNullTime parses an optional timestamp column
+func parseNullTime(s sql.NullString) *time.Time {
+	if !s.Valid {
+		return nil
+	}
+
+	t := parseTime(s.String)
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
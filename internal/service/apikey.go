@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// API key errors
+var (
+	ErrAPIKeyInvalid      = errors.New("invalid api key")
+	ErrAPIKeyExpired      = errors.New("api key expired")
+	ErrAPIKeyNameRequired = errors.New("api key name is required")
+	ErrAPIKeyNoScopes     = errors.New("at least one scope is required")
+	ErrAPIKeyBadScope     = errors.New("invalid scope")
+	ErrAPIKeyBadQuota     = errors.New("monthly place quota must be positive")
+	ErrAPIKeyBadProfile   = errors.New("unknown export profile")
+)
+
+// apiKeyPrefix marks keys issued by this service so they can be told apart
+// from the legacy API_TOKEN at a glance
+const apiKeyPrefix = "gms_"
+
+// lastUsedInterval is how old a key's last_used_at gets before a request
+// updates it, so polling workers do not write on every request
+const lastUsedInterval = time.Minute
+
+// APIKeyService handles API key business logic
+type APIKeyService struct {
+	keys     domain.APIKeyRepository
+	profiles domain.ExportProfileRepository // Optional: needed to restrict keys to export profiles
+
+	touchMu sync.Mutex
+	touched map[uuid.UUID]time.Time // When last_used_at was last updated, per key
+}
+
+// NewAPIKeyService creates a new APIKeyService
+func NewAPIKeyService(keys domain.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		keys:    keys,
+		touched: make(map[uuid.UUID]time.Time),
+	}
+}
+
+// SetExportProfiles enables restricting keys to export profiles
+func (s *APIKeyService) SetExportProfiles(profiles domain.ExportProfileRepository) {
+	s.profiles = profiles
+}
+
+// Create creates a new API key and returns it together with the cleartext
+// secret. The secret is not stored and cannot be retrieved again.
+func (s *APIKeyService) Create(ctx context.Context, req *domain.CreateAPIKeyRequest) (*domain.APIKey, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, "", ErrAPIKeyNameRequired
+	}
+	if len(req.Scopes) == 0 {
+		return nil, "", ErrAPIKeyNoScopes
+	}
+	for _, scope := range req.Scopes {
+		if !domain.IsValidScope(scope) {
+			return nil, "", fmt.Errorf("%w: %s", ErrAPIKeyBadScope, scope)
+		}
+	}
+	if req.MonthlyPlaceQuota != nil && *req.MonthlyPlaceQuota <= 0 {
+		return nil, "", ErrAPIKeyBadQuota
+	}
+	if req.ExportProfile != nil {
+		if s.profiles == nil {
+			return nil, "", fmt.Errorf("%w: export profiles are not enabled", ErrAPIKeyBadProfile)
+		}
+		name := strings.ToLower(strings.TrimSpace(*req.ExportProfile))
+		profile, err := s.profiles.Get(ctx, name)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get export profile: %w", err)
+		}
+		if profile == nil {
+			return nil, "", fmt.Errorf("%w: %s", ErrAPIKeyBadProfile, name)
+		}
+		req.ExportProfile = &name
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	secret := apiKeyPrefix + hex.EncodeToString(buf)
+
+	key := &domain.APIKey{
+		ID:        uuid.New(),
+		Name:      name,
+		Prefix:    secret[:len(apiKeyPrefix)+8],
+		KeyHash:   hashAPIKey(secret),
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+
+		MonthlyPlaceQuota: req.MonthlyPlaceQuota,
+		ExportProfile:     req.ExportProfile,
+	}
+
+	if err := s.keys.Create(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return key, secret, nil
+}
+
+// List retrieves all API keys
+func (s *APIKeyService) List(ctx context.Context) ([]*domain.APIKey, error) {
+	return s.keys.List(ctx)
+}
+
+// Delete revokes an API key
+func (s *APIKeyService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.keys.Delete(ctx, id)
+}
+
+// Authenticate resolves a cleartext secret to its API key.
+// On success the key's last_used_at is updated in the background, at most
+// once per lastUsedInterval.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (*domain.APIKey, error) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	key, err := s.keys.GetByHash(ctx, hashAPIKey(secret))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up api key: %w", err)
+	}
+	if key == nil {
+		return nil, ErrAPIKeyInvalid
+	}
+	if key.IsExpired() {
+		return nil, ErrAPIKeyExpired
+	}
+
+	if !s.claimTouch(key, time.Now()) {
+		return key, nil
+	}
+
+	logger := logging.Logger(ctx, "APIKeyService")
+	go func(id uuid.UUID) {
+		touchCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.keys.TouchLastUsed(touchCtx, id); err != nil {
+			logger.Warn("failed to update last_used_at", "api_key_id", id, "error", err)
+		}
+	}(key.ID)
+
+	return key, nil
+}
+
+// claimTouch reports whether the last_used_at of key is due an update at
+// now. The update is claimed in memory too, as requests arriving together
+// all read the stored value before it changes.
+func (s *APIKeyService) claimTouch(key *domain.APIKey, now time.Time) bool {
+	if key.LastUsedAt != nil && now.Sub(*key.LastUsedAt) < lastUsedInterval {
+		return false
+	}
+
+	s.touchMu.Lock()
+	defer s.touchMu.Unlock()
+
+	if last, ok := s.touched[key.ID]; ok && now.Sub(last) < lastUsedInterval {
+		return false
+	}
+
+	// Entries past the interval no longer hold anything back
+	for id, last := range s.touched {
+		if now.Sub(last) >= lastUsedInterval {
+			delete(s.touched, id)
+		}
+	}
+	s.touched[key.ID] = now
+
+	return true
+}
+
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
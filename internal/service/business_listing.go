@@ -1,20 +1,24 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
-	"strings"
 
+	"github.com/google/uuid"
+	"github.com/sadewadee/google-scraper/deduper"
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/exportschema"
 	"github.com/tealeg/xlsx/v3"
 )
 
 // BusinessListingService provides business logic for business listings
 type BusinessListingService struct {
-	repo domain.BusinessListingRepository
+	repo      domain.BusinessListingRepository
+	normalize domain.NormalizeQueueRepository // Optional
 }
 
 // NewBusinessListingService creates a new service
@@ -27,9 +31,10 @@ func (s *BusinessListingService) List(ctx context.Context, filter domain.Busines
 	return s.repo.List(ctx, filter)
 }
 
-// ListByJobID retrieves business listings for a specific job
-func (s *BusinessListingService) ListByJobID(ctx context.Context, jobID string, limit, offset int) ([]*domain.BusinessListing, int, error) {
-	return s.repo.ListByJobID(ctx, jobID, limit, offset)
+// ListByJobID retrieves business listings for a specific job with filters
+// and pagination
+func (s *BusinessListingService) ListByJobID(ctx context.Context, jobID string, filter domain.BusinessListingFilter) ([]*domain.BusinessListing, int, error) {
+	return s.repo.ListByJobID(ctx, jobID, filter)
 }
 
 // GetByID retrieves a single business listing by ID
@@ -42,14 +47,49 @@ func (s *BusinessListingService) GetCategories(ctx context.Context, limit int) (
 	return s.repo.GetCategories(ctx, limit)
 }
 
+// GetCanonicalCategories returns the canonical categories with the
+// listings under them across languages
+func (s *BusinessListingService) GetCanonicalCategories(ctx context.Context, limit int) ([]domain.CategoryCount, error) {
+	return s.repo.GetCanonicalCategories(ctx, limit)
+}
+
 // GetCities returns distinct cities
 func (s *BusinessListingService) GetCities(ctx context.Context, limit int) ([]string, error) {
 	return s.repo.GetCities(ctx, limit)
 }
 
-// Stats returns aggregate statistics
+// SetNormalizeQueue makes Stats report the normalization lag
+func (s *BusinessListingService) SetNormalizeQueue(queue domain.NormalizeQueueRepository) {
+	s.normalize = queue
+}
+
+// Stats returns aggregate statistics, with the normalization lag when the
+// normalize queue is set
 func (s *BusinessListingService) Stats(ctx context.Context) (*domain.BusinessListingStats, error) {
-	return s.repo.Stats(ctx)
+	stats, err := s.repo.Stats(ctx)
+	if err != nil || s.normalize == nil {
+		return stats, err
+	}
+
+	lag, err := s.normalize.Lag(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := *stats
+	out.Normalization = lag
+	return &out, nil
+}
+
+// Sample returns n random listings of a job, or of all jobs when jobID is
+// empty, with their quality
+func (s *BusinessListingService) Sample(ctx context.Context, jobID string, n int) (*domain.ListingSample, error) {
+	return s.repo.Sample(ctx, jobID, n)
+}
+
+// FieldStats describes how the listings of a job fill each export column
+func (s *BusinessListingService) FieldStats(ctx context.Context, jobID string) (*domain.ListingFieldStats, error) {
+	return s.repo.FieldStats(ctx, jobID)
 }
 
 // CountByJobID counts business listings for a job
@@ -57,76 +97,52 @@ func (s *BusinessListingService) CountByJobID(ctx context.Context, jobID string)
 	return s.repo.CountByJobID(ctx, jobID)
 }
 
-// AvailableColumns returns the list of available columns for export
+// AvailableColumns returns the keys of the columns listings can be
+// exported with, in export order
 func (s *BusinessListingService) AvailableColumns() []string {
-	return []string{
-		"title",
-		"category",
-		"address",
-		"phone",
-		"website",
-		"email",
-		"latitude",
-		"longitude",
-		"city",
-		"country",
-		"review_count",
-		"review_rating",
-		"status",
-		"price_range",
-		"link",
-		"place_id",
-		"cid",
-	}
+	return exportschema.ListingKeys()
 }
 
-// ExportCSV exports business listings to CSV format
-func (s *BusinessListingService) ExportCSV(ctx context.Context, w io.Writer, filter domain.BusinessListingFilter, columns []string) error {
-	if len(columns) == 0 {
-		columns = s.AvailableColumns()
-	}
+// ExportCSV exports business listings to CSV format, returning the rows
+// written also when it fails. A profile overrides columns and redacts each
+// listing, here and in every other export.
+func (s *BusinessListingService) ExportCSV(ctx context.Context, w io.Writer, filter domain.BusinessListingFilter, columns []string, profile *domain.ExportProfile) (int, error) {
+	columns = s.exportColumns(columns, profile)
 
 	csvWriter := csv.NewWriter(w)
-	defer csvWriter.Flush()
 
 	// Write header
 	if err := csvWriter.Write(columns); err != nil {
-		return fmt.Errorf("write csv header: %w", err)
+		return 0, fmt.Errorf("write csv header: %w", err)
 	}
 
-	return s.repo.Stream(ctx, filter, func(listing *domain.BusinessListing) error {
-		row := s.listingToRow(listing, columns)
-		return csvWriter.Write(row)
+	rows := 0
+	err := s.repo.Stream(ctx, filter, func(listing *domain.BusinessListing) error {
+		row := s.listingToRow(profile.Redact(listing), columns)
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+		rows++
+		return nil
 	})
-}
 
-// ExportCSVByJobID exports business listings for a job to CSV format
-func (s *BusinessListingService) ExportCSVByJobID(ctx context.Context, w io.Writer, jobID string, columns []string) error {
-	if len(columns) == 0 {
-		columns = s.AvailableColumns()
+	csvWriter.Flush()
+	if err == nil {
+		err = csvWriter.Error()
 	}
-
-	csvWriter := csv.NewWriter(w)
-	defer csvWriter.Flush()
-
-	// Write header
-	if err := csvWriter.Write(columns); err != nil {
-		return fmt.Errorf("write csv header: %w", err)
-	}
-
-	return s.repo.StreamByJobID(ctx, jobID, func(listing *domain.BusinessListing) error {
-		row := s.listingToRow(listing, columns)
-		return csvWriter.Write(row)
-	})
+	return rows, err
 }
 
-// ExportJSON exports business listings to JSON format
-func (s *BusinessListingService) ExportJSON(ctx context.Context, w io.Writer, filter domain.BusinessListingFilter) error {
+// ExportJSON exports business listings to JSON format, returning the rows
+// written also when it fails. A failed export is left without its closing
+// bracket.
+func (s *BusinessListingService) ExportJSON(ctx context.Context, w io.Writer, filter domain.BusinessListingFilter, profile *domain.ExportProfile) (int, error) {
 	// Write opening bracket
 	if _, err := w.Write([]byte("[\n")); err != nil {
-		return err
+		return 0, err
 	}
 
+	rows := 0
 	first := true
 	err := s.repo.Stream(ctx, filter, func(listing *domain.BusinessListing) error {
 		if !first {
@@ -135,38 +151,252 @@ func (s *BusinessListingService) ExportJSON(ctx context.Context, w io.Writer, fi
 			}
 		}
 		first = false
-		data, err := json.MarshalIndent(listing, "", "  ")
+		data, err := s.listingJSON(listing, profile, true)
 		if err != nil {
 			return err
 		}
-		_, err = w.Write(data)
-		return err
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		rows++
+		return nil
 	})
 	if err != nil {
-		return err
+		return rows, err
 	}
 
 	// Write closing bracket
 	_, err = w.Write([]byte("\n]"))
-	return err
+	return rows, err
 }
 
-// ExportJSONByJobID exports business listings for a job to JSON format
-func (s *BusinessListingService) ExportJSONByJobID(ctx context.Context, w io.Writer, jobID string) error {
-	// Write opening bracket
-	if _, err := w.Write([]byte("[\n")); err != nil {
+// ExportXLSX exports business listings to XLSX format, returning the rows
+// read also when it fails. Nothing is written to w unless every listing
+// was read.
+func (s *BusinessListingService) ExportXLSX(ctx context.Context, w io.Writer, filter domain.BusinessListingFilter, columns []string, profile *domain.ExportProfile) (int, error) {
+	columns = s.exportColumns(columns, profile)
+
+	wb := xlsx.NewFile()
+	sheet, err := wb.AddSheet("Business Listings")
+	if err != nil {
+		return 0, fmt.Errorf("create xlsx sheet: %w", err)
+	}
+
+	// Write header
+	headerRow := sheet.AddRow()
+	for _, col := range columns {
+		cell := headerRow.AddCell()
+		cell.SetString(col)
+	}
+
+	// Stream data
+	rows := 0
+	err = s.repo.Stream(ctx, filter, func(listing *domain.BusinessListing) error {
+		row := sheet.AddRow()
+		values := s.listingToRow(profile.Redact(listing), columns)
+		for _, val := range values {
+			cell := row.AddCell()
+			cell.SetString(val)
+		}
+		rows++
+		return nil
+	})
+	if err != nil {
+		return rows, err
+	}
+
+	return rows, wb.Write(w)
+}
+
+// GeoJSONExport counts what a GeoJSON export wrote
+type GeoJSONExport struct {
+	Features int
+	NoCoords int // Listings skipped for missing or zero coordinates
+}
+
+// geoJSONFeature is a listing as a GeoJSON Point feature
+type geoJSONFeature struct {
+	Type     string `json:"type"`
+	Geometry struct {
+		Type        string     `json:"type"`
+		Coordinates [2]float64 `json:"coordinates"`
+	} `json:"geometry"`
+	Properties map[string]string `json:"properties"`
+}
+
+// ExportGeoJSON exports business listings as a GeoJSON FeatureCollection
+// of points, one feature per listing with the selected columns as
+// properties. Features are written as they are read. Listings without
+// coordinates cannot be placed on a map and are skipped. A profile must
+// include both coordinates, which make the geometry.
+func (s *BusinessListingService) ExportGeoJSON(ctx context.Context, w io.Writer, filter domain.BusinessListingFilter, columns []string, profile *domain.ExportProfile) (*GeoJSONExport, error) {
+	if profile != nil {
+		if !profile.HasColumn("latitude") || !profile.HasColumn("longitude") {
+			return nil, fmt.Errorf("%w: %s has no coordinates for GeoJSON", domain.ErrInvalidExportProfile, profile.Name)
+		}
+		columns = nil
+		for _, col := range profile.Columns {
+			if col != "latitude" && col != "longitude" {
+				columns = append(columns, col)
+			}
+		}
+	} else if len(columns) == 0 {
+		// The coordinates are already the geometry
+		for _, col := range s.AvailableColumns() {
+			if col != "latitude" && col != "longitude" {
+				columns = append(columns, col)
+			}
+		}
+	}
+
+	if _, err := io.WriteString(w, `{"type":"FeatureCollection","features":[`+"\n"); err != nil {
+		return nil, err
+	}
+
+	res := &GeoJSONExport{}
+	err := s.repo.Stream(ctx, filter, func(listing *domain.BusinessListing) error {
+		feature, ok := s.listingToFeature(profile.Redact(listing), columns)
+		if !ok {
+			res.NoCoords++
+			return nil
+		}
+
+		data, err := json.Marshal(feature)
+		if err != nil {
+			return err
+		}
+		if res.Features > 0 {
+			if _, err := io.WriteString(w, ",\n"); err != nil {
+				return err
+			}
+		}
+		res.Features++
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil {
+		return res, err
+	}
+
+	_, err = io.WriteString(w, "\n]}\n")
+	return res, err
+}
+
+// listingToFeature converts a listing to a GeoJSON feature. It reports
+// false for listings without coordinates; 0,0 is taken as missing too.
+func (s *BusinessListingService) listingToFeature(listing *domain.BusinessListing, columns []string) (*geoJSONFeature, bool) {
+	if listing.Latitude == nil || listing.Longitude == nil {
+		return nil, false
+	}
+	lat, lon := *listing.Latitude, *listing.Longitude
+	if lat == 0 && lon == 0 {
+		return nil, false
+	}
+
+	feature := &geoJSONFeature{
+		Type:       "Feature",
+		Properties: make(map[string]string, len(columns)),
+	}
+	feature.Geometry.Type = "Point"
+	// GeoJSON positions are longitude first
+	feature.Geometry.Coordinates = [2]float64{lon, lat}
+
+	for _, col := range columns {
+		feature.Properties[col] = s.getColumnValue(listing, col)
+	}
+
+	return feature, true
+}
+
+// MultiJobExport counts what an export across several jobs wrote
+type MultiJobExport struct {
+	Rows       int
+	Duplicates int // Listings skipped because an earlier one had the same place
+}
+
+// streamJobs streams the listings of each job matching filter, one job
+// after the other, calling fn once per place. Places are told apart by
+// place ID, falling back to CID; listings with neither are all kept. The
+// seen set holds 64-bit hashes, not the IDs themselves.
+func (s *BusinessListingService) streamJobs(ctx context.Context, jobIDs []uuid.UUID, filter domain.BusinessListingFilter, fn func(listing *domain.BusinessListing) error) (*MultiJobExport, error) {
+	seen := deduper.New()
+	res := &MultiJobExport{}
+
+	for _, jobID := range jobIDs {
+		filter.JobID = &jobID
+
+		err := s.repo.Stream(ctx, filter, func(listing *domain.BusinessListing) error {
+			key := ""
+			switch {
+			case listing.PlaceID != nil && *listing.PlaceID != "":
+				key = "place:" + *listing.PlaceID
+			case listing.CID != nil && *listing.CID != "":
+				key = "cid:" + *listing.CID
+			}
+
+			if key != "" && !seen.AddIfNotExists(ctx, key) {
+				res.Duplicates++
+				return nil
+			}
+
+			res.Rows++
+			return fn(listing)
+		})
+		if err != nil {
+			return res, fmt.Errorf("stream job %s: %w", jobID, err)
+		}
+	}
+
+	return res, nil
+}
+
+// ExportCSVByJobIDs exports the listings of several jobs to one CSV,
+// without duplicate places
+func (s *BusinessListingService) ExportCSVByJobIDs(ctx context.Context, w io.Writer, jobIDs []uuid.UUID, filter domain.BusinessListingFilter, columns []string, profile *domain.ExportProfile) (*MultiJobExport, error) {
+	columns = s.exportColumns(columns, profile)
+
+	csvWriter := csv.NewWriter(w)
+	defer csvWriter.Flush()
+
+	// Write header
+	if err := csvWriter.Write(columns); err != nil {
+		return nil, fmt.Errorf("write csv header: %w", err)
+	}
+
+	return s.streamJobs(ctx, jobIDs, filter, func(listing *domain.BusinessListing) error {
+		return csvWriter.Write(s.listingToRow(profile.Redact(listing), columns))
+	})
+}
+
+// ExportNDJSONByJobIDs exports the listings of several jobs as one JSON
+// object per line, without duplicate places
+func (s *BusinessListingService) ExportNDJSONByJobIDs(ctx context.Context, w io.Writer, jobIDs []uuid.UUID, filter domain.BusinessListingFilter, profile *domain.ExportProfile) (*MultiJobExport, error) {
+	return s.streamJobs(ctx, jobIDs, filter, func(listing *domain.BusinessListing) error {
+		data, err := s.listingJSON(listing, profile, false)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
 		return err
+	})
+}
+
+// ExportJSONByJobIDs exports the listings of several jobs as one JSON
+// array, without duplicate places
+func (s *BusinessListingService) ExportJSONByJobIDs(ctx context.Context, w io.Writer, jobIDs []uuid.UUID, filter domain.BusinessListingFilter, profile *domain.ExportProfile) (*MultiJobExport, error) {
+	if _, err := w.Write([]byte("[\n")); err != nil {
+		return nil, err
 	}
 
 	first := true
-	err := s.repo.StreamByJobID(ctx, jobID, func(listing *domain.BusinessListing) error {
+	res, err := s.streamJobs(ctx, jobIDs, filter, func(listing *domain.BusinessListing) error {
 		if !first {
 			if _, err := w.Write([]byte(",\n")); err != nil {
 				return err
 			}
 		}
 		first = false
-		data, err := json.MarshalIndent(listing, "", "  ")
+		data, err := s.listingJSON(listing, profile, true)
 		if err != nil {
 			return err
 		}
@@ -174,24 +404,22 @@ func (s *BusinessListingService) ExportJSONByJobID(ctx context.Context, w io.Wri
 		return err
 	})
 	if err != nil {
-		return err
+		return res, err
 	}
 
-	// Write closing bracket
 	_, err = w.Write([]byte("\n]"))
-	return err
+	return res, err
 }
 
-// ExportXLSX exports business listings to XLSX format
-func (s *BusinessListingService) ExportXLSX(ctx context.Context, w io.Writer, filter domain.BusinessListingFilter, columns []string) error {
-	if len(columns) == 0 {
-		columns = s.AvailableColumns()
-	}
+// ExportXLSXByJobIDs exports the listings of several jobs to one XLSX
+// sheet, without duplicate places
+func (s *BusinessListingService) ExportXLSXByJobIDs(ctx context.Context, w io.Writer, jobIDs []uuid.UUID, filter domain.BusinessListingFilter, columns []string, profile *domain.ExportProfile) (*MultiJobExport, error) {
+	columns = s.exportColumns(columns, profile)
 
 	wb := xlsx.NewFile()
 	sheet, err := wb.AddSheet("Business Listings")
 	if err != nil {
-		return fmt.Errorf("create xlsx sheet: %w", err)
+		return nil, fmt.Errorf("create xlsx sheet: %w", err)
 	}
 
 	// Write header
@@ -201,57 +429,66 @@ func (s *BusinessListingService) ExportXLSX(ctx context.Context, w io.Writer, fi
 		cell.SetString(col)
 	}
 
-	// Stream data
-	err = s.repo.Stream(ctx, filter, func(listing *domain.BusinessListing) error {
+	res, err := s.streamJobs(ctx, jobIDs, filter, func(listing *domain.BusinessListing) error {
 		row := sheet.AddRow()
-		values := s.listingToRow(listing, columns)
-		for _, val := range values {
+		for _, val := range s.listingToRow(profile.Redact(listing), columns) {
 			cell := row.AddCell()
 			cell.SetString(val)
 		}
 		return nil
 	})
 	if err != nil {
-		return err
+		return res, err
 	}
 
-	return wb.Write(w)
+	return res, wb.Write(w)
 }
 
-// ExportXLSXByJobID exports business listings for a job to XLSX format
-func (s *BusinessListingService) ExportXLSXByJobID(ctx context.Context, w io.Writer, jobID string, columns []string) error {
-	if len(columns) == 0 {
-		columns = s.AvailableColumns()
+// exportColumns returns the columns an export writes: the profile's when
+// there is one, else the selected columns or all of them
+func (s *BusinessListingService) exportColumns(columns []string, profile *domain.ExportProfile) []string {
+	if profile != nil {
+		return profile.Columns
 	}
-
-	wb := xlsx.NewFile()
-	sheet, err := wb.AddSheet("Business Listings")
-	if err != nil {
-		return fmt.Errorf("create xlsx sheet: %w", err)
+	if len(columns) == 0 {
+		return s.AvailableColumns()
 	}
+	return columns
+}
 
-	// Write header
-	headerRow := sheet.AddRow()
-	for _, col := range columns {
-		cell := headerRow.AddCell()
-		cell.SetString(col)
+// listingJSON encodes a listing for the JSON exports. Through a profile
+// the listing becomes an object of the profile's columns, in order, as
+// the other formats write them.
+func (s *BusinessListingService) listingJSON(listing *domain.BusinessListing, profile *domain.ExportProfile, indent bool) ([]byte, error) {
+	if profile == nil {
+		if indent {
+			return json.MarshalIndent(listing, "", "  ")
+		}
+		return json.Marshal(listing)
 	}
 
-	// Stream data
-	err = s.repo.StreamByJobID(ctx, jobID, func(listing *domain.BusinessListing) error {
-		row := sheet.AddRow()
-		values := s.listingToRow(listing, columns)
-		for _, val := range values {
-			cell := row.AddCell()
-			cell.SetString(val)
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, val := range s.listingToRow(profile.Redact(listing), profile.Columns) {
+		if i > 0 {
+			buf.WriteByte(',')
 		}
-		return nil
-	})
-	if err != nil {
-		return err
+		key, _ := json.Marshal(profile.Columns[i])
+		value, _ := json.Marshal(val)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
 	}
+	buf.WriteByte('}')
 
-	return wb.Write(w)
+	if !indent {
+		return buf.Bytes(), nil
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
 }
 
 // listingToRow converts a business listing to a row based on selected columns
@@ -263,73 +500,7 @@ func (s *BusinessListingService) listingToRow(listing *domain.BusinessListing, c
 	return row
 }
 
-// getColumnValue extracts a column value from a business listing
+// getColumnValue formats a column of a business listing
 func (s *BusinessListingService) getColumnValue(listing *domain.BusinessListing, column string) string {
-	switch column {
-	case "title":
-		return listing.Title
-	case "category":
-		if listing.Category != nil {
-			return *listing.Category
-		}
-	case "address":
-		if listing.Address != nil {
-			return *listing.Address
-		}
-	case "phone":
-		if listing.Phone != nil {
-			return *listing.Phone
-		}
-	case "website":
-		if listing.Website != nil {
-			return *listing.Website
-		}
-	case "email":
-		if len(listing.Emails) > 0 {
-			return strings.Join(listing.Emails, ", ")
-		}
-	case "latitude":
-		if listing.Latitude != nil {
-			return fmt.Sprintf("%f", *listing.Latitude)
-		}
-	case "longitude":
-		if listing.Longitude != nil {
-			return fmt.Sprintf("%f", *listing.Longitude)
-		}
-	case "city":
-		if listing.AddressCity != nil {
-			return *listing.AddressCity
-		}
-	case "country":
-		if listing.AddressCountry != nil {
-			return *listing.AddressCountry
-		}
-	case "review_count":
-		return fmt.Sprintf("%d", listing.ReviewCount)
-	case "review_rating":
-		if listing.ReviewRating != nil {
-			return fmt.Sprintf("%.1f", *listing.ReviewRating)
-		}
-	case "status":
-		if listing.Status != nil {
-			return *listing.Status
-		}
-	case "price_range":
-		if listing.PriceRange != nil {
-			return *listing.PriceRange
-		}
-	case "link":
-		if listing.Link != nil {
-			return *listing.Link
-		}
-	case "place_id":
-		if listing.PlaceID != nil {
-			return *listing.PlaceID
-		}
-	case "cid":
-		if listing.CID != nil {
-			return *listing.CID
-		}
-	}
-	return ""
+	return exportschema.ListingValue(listing, column)
 }
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// CategoryService maintains the canonical category taxonomy listings are
+// filtered by across languages
+type CategoryService struct {
+	repo domain.CategoryMappingRepository
+}
+
+// NewCategoryService creates a new CategoryService
+func NewCategoryService(repo domain.CategoryMappingRepository) *CategoryService {
+	return &CategoryService{repo: repo}
+}
+
+// AddMappings creates or replaces mappings and relabels the listings of
+// their aliases, returning the number of listings relabeled
+func (s *CategoryService) AddMappings(ctx context.Context, mappings []domain.CategoryMapping) (int64, error) {
+	mappings, err := domain.NormalizeCategoryMappings(mappings)
+	if err != nil {
+		return 0, err
+	}
+
+	relabeled, err := s.repo.Add(ctx, mappings)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add category mappings: %w", err)
+	}
+
+	logging.Logger(ctx, "CategoryService").Info("category mappings added", "mappings", len(mappings), "relabeled", relabeled)
+
+	return relabeled, nil
+}
+
+// ListMappings returns the mappings ordered by canonical name and alias
+func (s *CategoryService) ListMappings(ctx context.Context) ([]*domain.CategoryMapping, error) {
+	return s.repo.List(ctx)
+}
+
+// Unmapped returns the categories no alias matches, most listed first
+func (s *CategoryService) Unmapped(ctx context.Context, limit int) ([]domain.CategoryCount, error) {
+	return s.repo.Unmapped(ctx, limit)
+}
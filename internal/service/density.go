@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sadewadee/google-scraper/gmaps"
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+const (
+	// densityProbeWorkers bounds the concurrent probe searches of one job
+	densityProbeWorkers = 6
+
+	// densityProbeTimeout bounds a single probe search
+	densityProbeTimeout = 15 * time.Second
+)
+
+// probeZoom returns the zoom level at which the default search viewport
+// spans a circle of radiusMeters, one level further out to be safe
+func probeZoom(lat float64, radiusMeters int) float64 {
+	const viewportWidth = 600 // pixels, see gmaps.MapSearchParams
+
+	metersPerPixel := 2 * float64(radiusMeters) / viewportWidth
+	zoom := math.Floor(math.Log2(156543.03392*math.Cos(lat*math.Pi/180)/metersPerPixel)) - 1
+
+	return math.Min(math.Max(zoom, 3), 21)
+}
+
+// dropEmptyCells runs a cheap zoomed-out search around every grid point and
+// keeps the points where any keyword has results. Keywords are tried in
+// order until one finds something, so a populated cell usually costs a
+// single request. Cells whose probe fails are kept, and once Google blocks
+// the probes the remaining cells are kept without probing.
+func dropEmptyCells(ctx context.Context, job *domain.Job, points []domain.GridPoint, radiusMeters int) []domain.GridPoint {
+	keep := make([]bool, len(points))
+	var blocked atomic.Bool
+
+	work := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < densityProbeWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range work {
+				keep[i] = blocked.Load() || cellHasPlaces(ctx, job, points[i], radiusMeters, &blocked)
+			}
+		}()
+	}
+
+	for i := range points {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	kept := make([]domain.GridPoint, 0, len(points))
+	for i, p := range points {
+		if keep[i] {
+			kept = append(kept, p)
+		}
+	}
+
+	return kept
+}
+
+func cellHasPlaces(ctx context.Context, job *domain.Job, point domain.GridPoint, radiusMeters int, blocked *atomic.Bool) bool {
+	for _, keyword := range job.Config.Keywords {
+		probeCtx, cancel := context.WithTimeout(ctx, densityProbeTimeout)
+		n, err := gmaps.CountSearchResults(probeCtx, nil, &gmaps.MapSearchParams{
+			Location: gmaps.MapLocation{
+				Lat:     point.Lat,
+				Lon:     point.Lon,
+				ZoomLvl: probeZoom(point.Lat, radiusMeters),
+				Radius:  float64(radiusMeters),
+			},
+			Query: keyword,
+			Hl:    job.Config.Lang,
+		})
+		cancel()
+
+		if err != nil {
+			if !gmaps.IsBlocked(err) {
+				logging.Logger(ctx, "JobService").Warn("density probe failed, keeping the cell",
+					"job_id", job.ID, "lat", point.Lat, "lon", point.Lon, "error", err)
+			} else if !blocked.Swap(true) {
+				logging.Logger(ctx, "JobService").Warn("density check blocked, keeping the remaining cells",
+					"job_id", job.ID, "error", err)
+			}
+			return true
+		}
+
+		if n > 0 {
+			return true
+		}
+	}
+
+	return false
+}
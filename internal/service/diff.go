@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// Listing diff errors
+var (
+	ErrDiffSameJob       = errors.New("a job cannot be compared against itself")
+	ErrInvalidDiffChange = errors.New("invalid change, use added, removed, changed or unchanged")
+)
+
+// diffCSVHeader is the header of a diff exported as CSV. Each changed field
+// is a row of its own; added and removed places have a single row.
+var diffCSVHeader = []string{"change", "key", "title", "field", "before", "after", "closed"}
+
+// DiffService compares the business listings of two jobs
+type DiffService struct {
+	repo domain.ListingDiffRepository
+	jobs domain.JobRepository
+}
+
+// NewDiffService creates a new DiffService
+func NewDiffService(repo domain.ListingDiffRepository, jobs domain.JobRepository) *DiffService {
+	return &DiffService{repo: repo, jobs: jobs}
+}
+
+// Diff returns a page of the diff and the summary of the whole diff
+func (s *DiffService) Diff(ctx context.Context, filter domain.ListingDiffFilter) ([]*domain.ListingDiff, int, *domain.ListingDiffSummary, error) {
+	if err := s.Check(ctx, &filter); err != nil {
+		return nil, 0, nil, err
+	}
+
+	diffs, total, err := s.repo.Diff(ctx, filter)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to diff listings: %w", err)
+	}
+
+	summary, err := s.repo.Summarize(ctx, filter.JobID, filter.AgainstID)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to summarize diff: %w", err)
+	}
+
+	return diffs, total, summary, nil
+}
+
+// ExportCSV writes the diff as CSV, one row per changed field
+func (s *DiffService) ExportCSV(ctx context.Context, w io.Writer, filter domain.ListingDiffFilter) error {
+	csvWriter := csv.NewWriter(w)
+	defer csvWriter.Flush()
+
+	if err := csvWriter.Write(diffCSVHeader); err != nil {
+		return fmt.Errorf("write csv header: %w", err)
+	}
+
+	return s.repo.Stream(ctx, filter, func(diff *domain.ListingDiff) error {
+		for _, row := range diffRows(diff) {
+			if err := csvWriter.Write(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Check validates filter and that both jobs exist. Without Changes a diff
+// leaves out unchanged places. Exports call it before writing anything, so
+// that errors can still be answered with a status code.
+func (s *DiffService) Check(ctx context.Context, filter *domain.ListingDiffFilter) error {
+	if filter.JobID == filter.AgainstID {
+		return ErrDiffSameJob
+	}
+
+	for _, change := range filter.Changes {
+		if !slices.Contains(domain.DiffChanges, change) {
+			return ErrInvalidDiffChange
+		}
+	}
+	if len(filter.Changes) == 0 {
+		filter.Changes = []string{domain.DiffAdded, domain.DiffRemoved, domain.DiffChanged}
+	}
+
+	for _, id := range []uuid.UUID{filter.JobID, filter.AgainstID} {
+		job, err := s.jobs.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get job: %w", err)
+		}
+		if job == nil {
+			return fmt.Errorf("%w: %s", ErrJobNotFound, id)
+		}
+	}
+
+	return nil
+}
+
+// diffRows flattens a place of a diff to CSV rows
+func diffRows(diff *domain.ListingDiff) [][]string {
+	title := ""
+	switch {
+	case diff.After != nil:
+		title = diff.After.Title
+	case diff.Before != nil:
+		title = diff.Before.Title
+	}
+	closed := strconv.FormatBool(diff.Closed)
+
+	if len(diff.Changes) == 0 {
+		return [][]string{{diff.Change, diff.Key, title, "", "", "", closed}}
+	}
+
+	rows := make([][]string, 0, len(diff.Changes))
+	for _, c := range diff.Changes {
+		rows = append(rows, []string{diff.Change, diff.Key, title, c.Field, csvValue(c.Before), csvValue(c.After), closed})
+	}
+	return rows
+}
+
+// csvValue formats a FieldChange value, which may be a nil pointer
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case *string:
+		if v != nil {
+			return *v
+		}
+	case *float64:
+		if v != nil {
+			return strconv.FormatFloat(*v, 'f', -1, 64)
+		}
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// Duplicate report and merge errors
+var (
+	ErrMergeNoSurvivor     = errors.New("survivor_id is required")
+	ErrMergeNothingToMerge = errors.New("listing_ids must contain at least one listing besides the survivor")
+	ErrInvalidCriterion    = errors.New("invalid duplicate criterion")
+	ErrListingNotFound     = errors.New("listing not found")
+)
+
+// DuplicateService reports and merges duplicate business listings
+type DuplicateService struct {
+	repo domain.DuplicateRepository
+}
+
+// NewDuplicateService creates a new DuplicateService
+func NewDuplicateService(repo domain.DuplicateRepository) *DuplicateService {
+	return &DuplicateService{repo: repo}
+}
+
+// FindClusters returns duplicate clusters with pagination
+func (s *DuplicateService) FindClusters(ctx context.Context, filter domain.DuplicateFilter) ([]*domain.DuplicateCluster, int, error) {
+	if filter.Criterion != "" && !slices.Contains(domain.DuplicateCriteria, filter.Criterion) {
+		return nil, 0, ErrInvalidCriterion
+	}
+
+	return s.repo.FindClusters(ctx, filter)
+}
+
+// Merge merges a cluster into its survivor
+func (s *DuplicateService) Merge(ctx context.Context, req *domain.MergeDuplicatesRequest) (*domain.ListingMerge, error) {
+	if req.SurvivorID <= 0 {
+		return nil, ErrMergeNoSurvivor
+	}
+	if req.Criterion != "" && !slices.Contains(domain.DuplicateCriteria, req.Criterion) {
+		return nil, ErrInvalidCriterion
+	}
+
+	merged := make([]int64, 0, len(req.ListingIDs))
+	for _, id := range req.ListingIDs {
+		if id != req.SurvivorID && !slices.Contains(merged, id) {
+			merged = append(merged, id)
+		}
+	}
+	if len(merged) == 0 {
+		return nil, ErrMergeNothingToMerge
+	}
+
+	merge, err := s.repo.Merge(ctx, req.SurvivorID, merged, req.Criterion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge listings: %w", err)
+	}
+	if merge == nil {
+		return nil, ErrListingNotFound
+	}
+
+	return merge, nil
+}
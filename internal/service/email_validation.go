@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/emailvalidator"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+const (
+	// emailFeedInterval is how often due emails are moved to the queue
+	emailFeedInterval = 5 * time.Second
+
+	// emailQueuePerWorker is how many emails are kept queued per worker
+	emailQueuePerWorker = 10
+
+	// emailClaimTimeout is when a queued email that was never validated,
+	// e.g. because its manager stopped, or whose validation failed is
+	// queued again
+	emailClaimTimeout = time.Hour
+
+	// emailPopTimeout bounds how long a worker waits for an email before
+	// checking whether it should stop
+	emailPopTimeout = 5 * time.Second
+
+	// emailValidateTimeout bounds a single validation; SMTP checks alone
+	// can take a minute
+	emailValidateTimeout = 2 * time.Minute
+)
+
+// EmailValidationService validates stored emails in the background, so
+// email jobs do not wait for the validation API. Emails due for validation
+// are claimed from the database onto a queue that a pool of workers
+// drains, updating each email's validation status.
+type EmailValidationService struct {
+	repo      domain.EmailValidationRepository
+	validator *emailvalidator.CachedValidator // nil when validation is disabled
+	queue     emailvalidator.Queue
+	workers   int
+	ttl       time.Duration
+
+	inFlight  atomic.Int64
+	processed atomic.Int64
+	cacheHits atomic.Int64
+	failed    atomic.Int64
+}
+
+// NewEmailValidationService creates a service running workers validators.
+// Without a validator it only reports stats.
+func NewEmailValidationService(repo domain.EmailValidationRepository, validator *emailvalidator.CachedValidator, queue emailvalidator.Queue, workers int, ttl time.Duration) *EmailValidationService {
+	if workers <= 0 {
+		workers = domain.DefaultEmailValidationWorkers
+	}
+	if ttl <= 0 {
+		ttl = domain.DefaultEmailValidationTTL
+	}
+
+	return &EmailValidationService{
+		repo:      repo,
+		validator: validator,
+		queue:     queue,
+		workers:   workers,
+		ttl:       ttl,
+	}
+}
+
+// Stats counts the stored emails by validation state, together with the
+// state of this manager's workers when they run
+func (s *EmailValidationService) Stats(ctx context.Context) (*domain.EmailValidationStats, error) {
+	stats, err := s.repo.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.validator == nil {
+		return stats, nil
+	}
+
+	depth, err := s.queue.Len(ctx)
+	if err != nil {
+		logging.Logger(ctx, "EmailValidation").Warn("failed to read queue depth", "error", err)
+	}
+
+	stats.Queue = &domain.EmailValidationQueueStats{
+		Depth:     depth,
+		Workers:   s.workers,
+		InFlight:  s.inFlight.Load(),
+		Processed: s.processed.Load(),
+		CacheHits: s.cacheHits.Load(),
+		Failed:    s.failed.Load(),
+	}
+
+	return stats, nil
+}
+
+// Run feeds the queue and runs the workers until ctx is done
+func (s *EmailValidationService) Run(ctx context.Context) error {
+	if s.validator == nil {
+		return nil
+	}
+
+	logger := logging.Logger(ctx, "EmailValidation")
+	logger.Info("email validation started", "workers", s.workers, "ttl", s.ttl.String())
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx)
+		}()
+	}
+
+	s.feed(ctx)
+	wg.Wait()
+
+	logger.Info("email validation stopped")
+	return nil
+}
+
+// feed claims due emails while the queue runs low
+func (s *EmailValidationService) feed(ctx context.Context) {
+	ticker := time.NewTicker(emailFeedInterval)
+	defer ticker.Stop()
+
+	logger := logging.Logger(ctx, "EmailValidation")
+
+	for {
+		if err := s.fill(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("failed to queue emails", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *EmailValidationService) fill(ctx context.Context) error {
+	depth, err := s.queue.Len(ctx)
+	if err != nil {
+		return err
+	}
+
+	want := s.workers*emailQueuePerWorker - int(depth)
+	if want <= 0 {
+		return nil
+	}
+
+	emails, err := s.repo.ClaimDue(ctx, want, s.ttl, emailClaimTimeout)
+	if err != nil {
+		return err
+	}
+	if len(emails) == 0 {
+		return nil
+	}
+
+	logging.Logger(ctx, "EmailValidation").Debug("queued emails", "emails", len(emails))
+	return s.queue.Push(ctx, emails...)
+}
+
+func (s *EmailValidationService) work(ctx context.Context) {
+	logger := logging.Logger(ctx, "EmailValidation")
+
+	for ctx.Err() == nil {
+		email, err := s.queue.Pop(ctx, emailPopTimeout)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Warn("failed to read queue", "error", err)
+				time.Sleep(emailPopTimeout)
+			}
+			continue
+		}
+		if email == "" {
+			continue
+		}
+
+		s.validate(ctx, email)
+	}
+}
+
+func (s *EmailValidationService) validate(ctx context.Context, email string) {
+	s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+
+	vctx, cancel := context.WithTimeout(ctx, emailValidateTimeout)
+	defer cancel()
+
+	start := time.Now()
+	res, cached, err := s.validator.ValidateCached(vctx, email)
+	if ctx.Err() != nil {
+		// Stopping; the claim expires and another manager picks it up
+		return
+	}
+
+	s.processed.Add(1)
+	logger := logging.Logger(ctx, "EmailValidation").With("email", email)
+
+	switch {
+	case res == nil:
+		s.failed.Add(1)
+		logger.Warn("email validation failed", "error", err, "duration_ms", logging.SinceMS(start))
+		if err := s.repo.MarkError(ctx, email, err.Error()); err != nil {
+			logger.Error("failed to record validation error", "error", err)
+		}
+	case err != nil:
+		s.failed.Add(1)
+		logger.Error("failed to store validation result", "error", err)
+	case cached:
+		s.cacheHits.Add(1)
+		logger.Debug("email validation cached", "status", res.Status)
+	default:
+		logger.Debug("email validated", "status", res.Status, "score", res.Score, "duration_ms", logging.SinceMS(start))
+	}
+}
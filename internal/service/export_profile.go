@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/exportschema"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// ExportProfileService manages export profiles and decides which one a
+// download goes through
+type ExportProfileService struct {
+	repo domain.ExportProfileRepository
+}
+
+// NewExportProfileService creates a new ExportProfileService
+func NewExportProfileService(repo domain.ExportProfileRepository) *ExportProfileService {
+	return &ExportProfileService{repo: repo}
+}
+
+// Save creates a profile or replaces the one of the same name. Keys
+// restricted to the profile download through the new columns right away.
+func (s *ExportProfileService) Save(ctx context.Context, profile *domain.ExportProfile) error {
+	if err := profile.Normalize(); err != nil {
+		return err
+	}
+	keys := exportschema.ListingKeys()
+	for _, c := range profile.Columns {
+		if !slices.Contains(keys, c) {
+			return fmt.Errorf("%w: unknown column %s", domain.ErrInvalidExportProfile, c)
+		}
+	}
+
+	if err := s.repo.Save(ctx, profile); err != nil {
+		return fmt.Errorf("failed to save export profile: %w", err)
+	}
+
+	logging.Logger(ctx, "ExportProfileService").Info("export profile saved", "profile", profile.Name, "columns", len(profile.Columns), "mask_emails", profile.MaskEmails, "truncate_coords", profile.TruncateCoords)
+
+	return nil
+}
+
+// Get retrieves a profile by name
+func (s *ExportProfileService) Get(ctx context.Context, name string) (*domain.ExportProfile, error) {
+	profile, err := s.repo.Get(ctx, strings.ToLower(strings.TrimSpace(name)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export profile: %w", err)
+	}
+	if profile == nil {
+		return nil, domain.ErrExportProfileNotFound
+	}
+	return profile, nil
+}
+
+// List returns every profile ordered by name
+func (s *ExportProfileService) List(ctx context.Context) ([]*domain.ExportProfile, error) {
+	return s.repo.List(ctx)
+}
+
+// Delete deletes a profile no API key is restricted to
+func (s *ExportProfileService) Delete(ctx context.Context, name string) error {
+	found, err := s.repo.Delete(ctx, strings.ToLower(strings.TrimSpace(name)))
+	if err != nil {
+		return err
+	}
+	if !found {
+		return domain.ErrExportProfileNotFound
+	}
+	return nil
+}
+
+// Resolve returns the profile a download goes through, nil for none. A key
+// restricted to a profile always gets it, and asking for another one is
+// refused; otherwise the requested profile, if any, is used.
+func (s *ExportProfileService) Resolve(ctx context.Context, requested string) (*domain.ExportProfile, error) {
+	requested = strings.ToLower(strings.TrimSpace(requested))
+
+	if key := domain.APIKeyFromContext(ctx); key != nil && key.ExportProfile != nil {
+		if requested != "" && requested != *key.ExportProfile {
+			return nil, fmt.Errorf("%w: %s", domain.ErrExportProfileForbidden, *key.ExportProfile)
+		}
+		requested = *key.ExportProfile
+	}
+
+	if requested == "" {
+		return nil, nil
+	}
+
+	return s.Get(ctx, requested)
+}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gosom/scrapemate"
 
+	"github.com/sadewadee/google-scraper/gmaps"
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/events"
+	"github.com/sadewadee/google-scraper/internal/logging"
 	"github.com/sadewadee/google-scraper/internal/mq"
 	"github.com/sadewadee/google-scraper/internal/queue"
 	"github.com/sadewadee/google-scraper/internal/spawner"
@@ -25,16 +30,64 @@ var (
 	ErrJobNotPausable    = errors.New("job cannot be paused")
 	ErrJobNotResumable   = errors.New("job cannot be resumed")
 	ErrJobNotCancellable = errors.New("job cannot be cancelled")
+	ErrJobNotRetryable   = errors.New("paused or cancelled jobs cannot be retried")
+	ErrJobRunning        = errors.New("cannot delete a running job, cancel it first")
+	ErrJobNotDeleted     = errors.New("job is not deleted")
+	ErrJobNotPending     = errors.New("only pending jobs can be requeued")
+
+	// ErrNoJobQueue is returned when requeueing a job while neither
+	// RabbitMQ nor Redis is configured; workers poll for pending jobs
+	ErrNoJobQueue = errors.New("no job queue configured, workers poll for pending jobs")
+
+	// ErrNoJobEvents is returned when listing the events of a job while
+	// no audit trail is kept, which needs PostgreSQL
+	ErrNoJobEvents = errors.New("job events are not recorded, they need PostgreSQL")
+
+	// ErrFailureNotRetryable is returned when retrying a job whose error
+	// code says it would fail the same way again
+	ErrFailureNotRetryable = errors.New("retrying does not fix this failure")
+
+	// ErrJobFinished is returned for results submitted to a job whose run
+	// already completed or failed
+	ErrJobFinished = errors.New("job already finished, results are stale")
+
+	// ErrNoProxiesForCountry is returned when a job asks for a proxy
+	// country that has no healthy proxies
+	ErrNoProxiesForCountry = errors.New("no healthy proxies for requested country")
+)
+
+// maxCountryProxies caps the proxies attached to a geo-targeted job
+const maxCountryProxies = 50
+
+// Deleted jobs past their retention are purged this often, this many per
+// query
+const (
+	deletedJobSweepInterval = time.Hour
+	deletedJobPurgeBatch    = 100
 )
 
 // JobService handles job business logic
 type JobService struct {
 	jobs      domain.JobRepository
 	results   domain.ResultRepository
-	queue     *queue.Queue       // Redis queue (legacy)
-	mqPub     mq.Publisher       // RabbitMQ publisher (preferred)
-	gmapsPush postgres.GmapsJobPusher // Bridge to gmaps_jobs for DSN workers
-	spawner   spawner.Spawner    // Auto-spawn workers on job creation
+	queue     *queue.Queue                // Redis queue (legacy)
+	mqPub     mq.Publisher                // RabbitMQ publisher (preferred)
+	gmapsPush postgres.GmapsJobPusher     // Bridge to gmaps_jobs for DSN workers
+	spawner   spawner.Spawner             // Auto-spawn workers on job creation
+	proxyList domain.ProxyListRepository  // Proxy pool for geo-targeted jobs (optional)
+	events    events.Publisher            // Live progress/status stream (optional)
+	claimable events.ClaimablePublisher   // Wakes workers long-polling for a job (optional)
+	usage     domain.UsageRepository      // Per-tenant monthly quotas (optional)
+	seedTasks domain.SeedTaskRepository   // Seed tasks of bridged jobs (optional)
+	bandwidth domain.ProxyUsageRepository // ProxyGate traffic per job (optional)
+	reports   *ReportService              // Report emails of completed jobs (optional)
+	audit     *jobAudit                   // Audit trail of job transitions (optional)
+
+	retryMu sync.Mutex // Serializes RetryFailed so repeated calls requeue once
+
+	maxExpandedKeywords int           // Cap for base_keywords × locations expansion (0 = default)
+	lateResultsWindow   time.Duration // How long failed jobs still take outbox results (0 = not at all)
+	workerConcurrency   int           // Searches the workers run at a time, for previews (0 = default)
 }
 
 // NewJobService creates a new JobService
@@ -81,164 +134,360 @@ func NewJobServiceWithSpawner(jobs domain.JobRepository, results domain.ResultRe
 	}
 }
 
-// SetSpawner sets the worker spawner (can be called after construction)
+// SetSpawner sets the worker spawner (can be called after construction).
+// Every created job then gets its own worker, which suits spawners like
+// Lambda that run one job per invocation; docker and swarm workers are
+// sized by the autoscale package instead.
 func (s *JobService) SetSpawner(sp spawner.Spawner) {
 	s.spawner = sp
 }
 
+// SetProxyList enables geo-targeted jobs by giving the service access to
+// the proxy pool
+func (s *JobService) SetProxyList(repo domain.ProxyListRepository) {
+	s.proxyList = repo
+}
+
+// SetEvents publishes progress and status changes to live subscribers
+func (s *JobService) SetEvents(p events.Publisher) {
+	s.events = p
+}
+
+// SetClaimable announces jobs entering the queue to the workers
+// long-polling the claim endpoint
+func (s *JobService) SetClaimable(p events.ClaimablePublisher) {
+	s.claimable = p
+}
+
+// SetMaxExpandedKeywords caps how many keywords base_keywords × locations
+// may expand to
+func (s *JobService) SetMaxExpandedKeywords(n int) {
+	s.maxExpandedKeywords = n
+}
+
+// SetLateResultsWindow takes the results workers deliver from their outbox
+// for jobs that timed out or failed as unreachable up to d after they failed
+func (s *JobService) SetLateResultsWindow(d time.Duration) {
+	s.lateResultsWindow = d
+}
+
+// SetUsage enforces monthly place quotas when jobs are created
+func (s *JobService) SetUsage(repo domain.UsageRepository) {
+	s.usage = repo
+}
+
+// SetSeedTasks lets RetryFailed requeue the failed seed tasks of jobs
+// bridged to DSN workers
+func (s *JobService) SetSeedTasks(repo domain.SeedTaskRepository) {
+	s.seedTasks = repo
+}
+
+// SetProxyUsage adds the ProxyGate traffic of a job to GetByID
+func (s *JobService) SetProxyUsage(repo domain.ProxyUsageRepository) {
+	s.bandwidth = repo
+}
+
+// SetReports emails the report of jobs Complete completes
+func (s *JobService) SetReports(r *ReportService) {
+	s.reports = r
+}
+
+// SetAudit records every transition of a job, and who caused it, in
+// repo; RunJobEventRetention prunes events older than retention (0 keeps
+// them)
+func (s *JobService) SetAudit(repo domain.JobEventRepository, retention time.Duration) {
+	s.audit = newJobAudit(repo, retention)
+}
+
+func (s *JobService) keywordLimit() int {
+	if s.maxExpandedKeywords > 0 {
+		return s.maxExpandedKeywords
+	}
+	return domain.DefaultMaxExpandedKeywords
+}
+
+func (s *JobService) publishStatus(ctx context.Context, id uuid.UUID, status domain.JobStatus, errMsg string) {
+	if s.events != nil {
+		s.events.Publish(ctx, events.StatusEvent(id, status, errMsg))
+	}
+}
+
 // Create creates a new job
 func (s *JobService) Create(ctx context.Context, req *domain.CreateJobRequest) (*domain.Job, error) {
 	start := time.Now()
-	log.Printf("[JobService] Create started for job: %s", req.Name)
+	logger := logging.Logger(ctx, "JobService")
+	logger.Debug("create started", "name", req.Name)
+
+	job, err := req.ToJob(s.keywordLimit())
+	if err != nil {
+		return nil, err
+	}
+	logger = logger.With("job_id", job.ID)
+	logger.Debug("job config built", "duration_ms", logging.SinceMS(start))
+
+	quota, err := s.quota(ctx, job.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	if quota != nil {
+		if err := quota.Check(); err != nil {
+			return nil, err
+		}
+	}
+
+	parent, err := s.prepare(ctx, job)
+	if err != nil {
+		return nil, err
+	}
 
-	job := req.ToJob()
-	log.Printf("[JobService] ToJob completed in %v", time.Since(start))
+	// A sharded job is dispatched as its shards
+	if req.Shards > 1 {
+		return s.createSharded(ctx, logger, job, req.Shards, parent)
+	}
 
 	dbStart := time.Now()
-	if err := s.jobs.Create(ctx, job); err != nil {
-		log.Printf("[JobService] Create FAILED after %v: %v", time.Since(start), err)
+	created := &domain.JobEvent{JobID: job.ID, Type: domain.JobEventCreated, Status: job.Status}
+	if err := s.audit.apply(ctx, s.jobs, created, func(jobs domain.JobRepository) error {
+		return jobs.Create(ctx, job)
+	}); err != nil {
+		logger.Error("create failed", "duration_ms", logging.SinceMS(start), "error", err)
 		return nil, fmt.Errorf("failed to create job: %w", err)
 	}
 
-	log.Printf("[JobService] Create completed in %v (db: %v)", time.Since(start), time.Since(dbStart))
+	logger.Info("job stored", "duration_ms", logging.SinceMS(start), "db_ms", logging.SinceMS(dbStart))
+
+	// A waiting job is dispatched once the job it depends on finished,
+	// right away if it already has
+	if job.Status == domain.JobStatusWaiting {
+		if job.DependencyOutcome(parent) != domain.JobStatusWaiting {
+			if err := s.resolveDependency(ctx, logger, job, parent); err != nil {
+				logger.Warn("failed to resolve dependency", "depends_on", parent.ID, "error", err)
+			}
+		}
+		return job, nil
+	}
+
+	s.dispatch(ctx, logger, job)
 
+	return job, nil
+}
+
+// quota returns the monthly quota of tenant, nil without usage tracking or
+// a tenant
+func (s *JobService) quota(ctx context.Context, tenant string) (*domain.QuotaStatus, error) {
+	if s.usage == nil || tenant == "" {
+		return nil, nil
+	}
+
+	quota, err := s.usage.MonthlyQuota(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check quota: %w", err)
+	}
+	return quota, nil
+}
+
+// prepare completes a job built from a create request before it is stored:
+// it attaches the proxies of its country and returns the job it depends
+// on, if any
+func (s *JobService) prepare(ctx context.Context, job *domain.Job) (*domain.Job, error) {
+	// Explicit proxies win; otherwise attach healthy proxies from the
+	// requested country so the job never silently runs elsewhere
+	if job.Config.ProxyCountry != "" && len(job.Config.Proxies) == 0 {
+		proxies, err := s.countryProxies(ctx, job.Config.ProxyCountry)
+		if err != nil {
+			return nil, err
+		}
+		job.Config.Proxies = proxies
+	}
+
+	if job.DependsOn == nil {
+		return nil, nil
+	}
+	return s.dependencyParent(ctx, job)
+}
+
+// dispatch hands a stored pending job to the workers: DSN workers get its
+// seed tasks, queue workers a message, and a worker is spawned for it
+func (s *JobService) dispatch(ctx context.Context, logger *slog.Logger, job *domain.Job) {
 	// Bridge to gmaps_jobs for DSN workers (if configured)
 	if s.gmapsPush != nil {
 		bridgeStart := time.Now()
-		if err := s.bridgeToGmapsJobs(ctx, job); err != nil {
-			log.Printf("[JobService] WARNING: bridge to gmaps_jobs failed for job %s: %v", job.ID, err)
+		if _, err := s.bridgeToGmapsJobs(ctx, s.gmapsPush, job, nil); err != nil {
+			logger.Warn("bridge to gmaps_jobs failed", "error", err)
 			// Don't fail job creation - just log the error
 			// The Redis queue fallback can still work
 		} else {
-			log.Printf("[JobService] Job %s bridged to gmaps_jobs (%d tasks) in %v",
-				job.ID, job.Progress.TotalPlaces, time.Since(bridgeStart))
+			logger.Info("job bridged to gmaps_jobs",
+				"tasks", job.Progress.TotalPlaces, "duration_ms", logging.SinceMS(bridgeStart))
 			// Update job with total tasks count from bridge
 			if err := s.jobs.Update(ctx, job); err != nil {
-				log.Printf("[JobService] WARNING: failed to update total_tasks for job %s: %v", job.ID, err)
+				logger.Warn("failed to update total_tasks", "error", err)
 			}
 		}
 	}
 
-	// Enqueue to RabbitMQ if available (preferred over Redis)
-	if s.mqPub != nil {
-		msg := &mq.JobMessage{
-			JobID:    job.ID,
-			Priority: job.Priority,
-			Type:     "job:process",
-		}
-		if err := s.mqPub.Publish(ctx, msg); err != nil {
-			log.Printf("[JobService] WARNING: failed to publish job %s to RabbitMQ: %v", job.ID, err)
-		} else {
-			log.Printf("[JobService] Job %s published to RabbitMQ queue", job.ID)
-		}
-	} else if s.queue != nil {
-		// Fallback to Redis queue if RabbitMQ not available
-		if err := s.queue.Enqueue(ctx, job.ID, job.Priority); err != nil {
-			// Log error but don't fail job creation - worker can still poll
-			log.Printf("[JobService] WARNING: failed to enqueue job %s to Redis: %v", job.ID, err)
-		} else {
-			log.Printf("[JobService] Job %s enqueued to Redis queue", job.ID)
-		}
+	// Log error but don't fail job creation - worker can still poll
+	if backend, err := s.enqueue(ctx, job); err != nil {
+		logger.Warn("failed to enqueue job", "queue", backend, "error", err)
+	} else if backend != domain.QueueBackendNone {
+		logger.Info("job enqueued", "queue", backend)
 	}
 
 	// Auto-spawn worker if spawner is configured
 	if s.spawner != nil {
-		go s.spawnWorkerForJob(job)
+		go s.spawnWorkerForJob(logger, job)
 	}
+}
 
-	return job, nil
+// ExpandKeywords previews the keywords base_keywords × locations expand to
+// together with seed job, place and runtime estimates
+func (s *JobService) ExpandKeywords(req *domain.ExpandKeywordsRequest) (*domain.KeywordExpansion, error) {
+	return req.Expand(s.keywordLimit())
+}
+
+// countryProxies returns URLs of the best healthy proxies in country
+func (s *JobService) countryProxies(ctx context.Context, country string) ([]string, error) {
+	if s.proxyList == nil {
+		return nil, fmt.Errorf("%w: %s (proxy list not available)", ErrNoProxiesForCountry, country)
+	}
+
+	proxies, _, err := s.proxyList.List(ctx, domain.ProxyListParams{
+		Status:  domain.ProxyStatusHealthy,
+		Country: country,
+		Limit:   maxCountryProxies,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list proxies for country %s: %w", country, err)
+	}
+
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoProxiesForCountry, country)
+	}
+
+	urls := make([]string, 0, len(proxies))
+	for _, proxy := range proxies {
+		urls = append(urls, proxy.URL())
+	}
+
+	return urls, nil
 }
 
 // bridgeToGmapsJobs creates seed jobs and inserts them into gmaps_jobs table.
 // This bridges the Dashboard job (jobs_queue) to DSN workers (gmaps_jobs).
-func (s *JobService) bridgeToGmapsJobs(ctx context.Context, job *domain.Job) error {
-	var allSeedJobs []scrapemate.IJob
+// Searches in claimed, by seedTaskKey, are on gmaps_jobs already and are
+// not pushed again. It returns the number of seed jobs pushed with push.
+func (s *JobService) bridgeToGmapsJobs(ctx context.Context, push postgres.GmapsJobPusher, job *domain.Job, claimed map[string]bool) (int, error) {
 	parentID := job.ID.String()
 
-	// Check if full coverage mode is enabled with valid bounding box
-	if job.Config.CoverageMode == domain.CoverageModeFull &&
-		job.Config.BoundingBox != nil &&
-		job.Config.BoundingBox.IsValid() {
+	// Full coverage mode searches every point of a grid over the bounding box
+	var gridPoints []domain.GridPoint
+	if grid, ok := job.Config.Grid(); ok {
+		radius := job.Config.GridRadius()
+		gridPoints = grid.Points
 
-		// Generate grid points from bounding box based on radius
-		radius := job.Config.Radius
-		if radius < 100 {
-			radius = 5000 // default 5km
+		logger := logging.Logger(ctx, "JobService").With("job_id", job.ID)
+		logger.Info("full coverage mode: generating grid points",
+			"grid_points", len(gridPoints), "rows", grid.Rows, "cols", grid.Cols,
+			"spacing_m", math.Round(grid.SpacingMeters), "radius_m", radius)
+
+		if job.Config.DensityCheck {
+			checkStart := time.Now()
+			gridPoints = dropEmptyCells(ctx, job, gridPoints, radius)
+			job.Config.GridPoints = len(gridPoints)
+
+			logger.Info("density check done",
+				"kept", len(gridPoints), "grid_points", grid.Size(), "duration_ms", logging.SinceMS(checkStart))
 		}
-		gridPoints := job.Config.BoundingBox.GenerateGridByRadius(radius)
-
-		log.Printf("[JobService] Full coverage mode enabled: generating %d grid points for job %s (radius: %dm)",
-			len(gridPoints), job.ID, radius)
-
-		// Create seed jobs for each grid point
-		for i, point := range gridPoints {
-			geoCoords := runner.FormatGeoCoordinates(point.Lat, point.Lon)
-
-			seedJobs, err := runner.CreateSeedJobsFromKeywords(runner.SeedJobConfig{
-				Keywords:       job.Config.Keywords,
-				FastMode:       job.Config.FastMode,
-				LangCode:       job.Config.Lang,
-				Depth:          job.Config.Depth,
-				Email:          job.Config.ExtractEmail,
-				GeoCoordinates: geoCoords,
-				Zoom:           job.Config.Zoom,
-				Radius:         float64(job.Config.Radius),
-				ExtraReviews:   false, // Not exposed in Dashboard yet
-				Dedup:          nil,   // Deduplication handled by workers
-				ExitMonitor:    nil,   // Not needed for bridge
-			})
-			if err != nil {
-				return fmt.Errorf("failed to create seed jobs for grid point %d (%.4f, %.4f): %w",
-					i, point.Lat, point.Lon, err)
-			}
+	}
+
+	allSeedJobs, err := seedJobs(job, gridPoints)
+	if err != nil {
+		return 0, err
+	}
+	if gridPoints != nil {
+		logging.Logger(ctx, "JobService").Info("full coverage mode: seed jobs created", "job_id", job.ID, "seed_jobs", len(allSeedJobs))
+	}
 
-			allSeedJobs = append(allSeedJobs, seedJobs...)
+	// Push each seed job to gmaps_jobs with parent reference
+	pushed := 0
+	for _, seedJob := range allSeedJobs {
+		if claimed[seedTaskKey(seedJob)] {
+			continue
+		}
+		if err := push.PushWithParent(ctx, seedJob, parentID); err != nil {
+			return pushed, fmt.Errorf("failed to push seed job %s: %w", seedJob.GetID(), err)
 		}
+		pushed++
+	}
 
-		log.Printf("[JobService] Full coverage mode: created %d total seed jobs for job %s",
-			len(allSeedJobs), job.ID)
-	} else {
+	// Update job with total tasks count
+	job.Progress.TotalPlaces = len(allSeedJobs)
+
+	return pushed, nil
+}
+
+// seedJobs builds the seed jobs of a job, one per keyword and grid point.
+// Without grid points the keywords are searched around the job's
+// coordinates, if any. A job with place URLs scrapes just those places.
+func seedJobs(job *domain.Job, gridPoints []domain.GridPoint) ([]scrapemate.IJob, error) {
+	cfg := runner.SeedJobConfig{
+		Keywords:     job.Config.Keywords,
+		FastMode:     job.Config.FastMode,
+		LangCode:     job.Config.Lang,
+		LangFallback: job.Config.LangFallback,
+		GoogleDomain: job.Config.GoogleDomain,
+		Depth:        job.Config.Depth,
+		Email:        job.Config.ExtractEmail,
+		Zoom:         job.Config.Zoom,
+		Radius:       float64(job.Config.Radius),
+		ExtraReviews: false, // Enabled per job through MaxReviews
+		MaxReviews:   job.Config.MaxReviews,
+		ReviewsSort:  gmaps.ReviewSort(job.Config.ReviewsSort),
+		MaxImages:    job.Config.MaxImages,
+		Headers:      job.Config.RequestHeaders(),
+		Dedup:        nil, // Deduplication handled by workers
+		ExitMonitor:  nil, // Not needed for bridge
+	}
+
+	if len(job.Config.PlaceURLs) > 0 {
+		seeds, err := runner.CreatePlaceJobs(cfg, job.Config.PlaceURLs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create place jobs: %w", err)
+		}
+		return seeds, nil
+	}
+
+	if gridPoints == nil {
 		// Single point mode (default/legacy behavior)
-		geoCoords := ""
 		if job.Config.GeoLat != nil && job.Config.GeoLon != nil {
-			geoCoords = runner.FormatGeoCoordinates(*job.Config.GeoLat, *job.Config.GeoLon)
+			cfg.GeoCoordinates = runner.FormatGeoCoordinates(*job.Config.GeoLat, *job.Config.GeoLon)
 		}
 
-		seedJobs, err := runner.CreateSeedJobsFromKeywords(runner.SeedJobConfig{
-			Keywords:       job.Config.Keywords,
-			FastMode:       job.Config.FastMode,
-			LangCode:       job.Config.Lang,
-			Depth:          job.Config.Depth,
-			Email:          job.Config.ExtractEmail,
-			GeoCoordinates: geoCoords,
-			Zoom:           job.Config.Zoom,
-			Radius:         float64(job.Config.Radius),
-			ExtraReviews:   false, // Not exposed in Dashboard yet
-			Dedup:          nil,   // Deduplication handled by workers
-			ExitMonitor:    nil,   // Not needed for bridge
-		})
+		seeds, err := runner.CreateSeedJobsFromKeywords(cfg)
 		if err != nil {
-			return fmt.Errorf("failed to create seed jobs: %w", err)
+			return nil, fmt.Errorf("failed to create seed jobs: %w", err)
 		}
-
-		allSeedJobs = seedJobs
+		return seeds, nil
 	}
 
-	// Push each seed job to gmaps_jobs with parent reference
-	for _, seedJob := range allSeedJobs {
-		if err := s.gmapsPush.PushWithParent(ctx, seedJob, parentID); err != nil {
-			return fmt.Errorf("failed to push seed job %s: %w", seedJob.GetID(), err)
+	var all []scrapemate.IJob
+	for i, point := range gridPoints {
+		cfg.GeoCoordinates = runner.FormatGeoCoordinates(point.Lat, point.Lon)
+
+		seeds, err := runner.CreateSeedJobsFromKeywords(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create seed jobs for grid point %d (%.4f, %.4f): %w",
+				i, point.Lat, point.Lon, err)
 		}
-	}
 
-	// Update job with total tasks count
-	job.Progress.TotalPlaces = len(allSeedJobs)
+		all = append(all, seeds...)
+	}
 
-	return nil
+	return all, nil
 }
 
 // spawnWorkerForJob spawns a worker container/function to process the job
-func (s *JobService) spawnWorkerForJob(job *domain.Job) {
+func (s *JobService) spawnWorkerForJob(logger *slog.Logger, job *domain.Job) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -250,17 +499,17 @@ func (s *JobService) spawnWorkerForJob(job *domain.Job) {
 
 	result, err := s.spawner.Spawn(ctx, req)
 	if err != nil {
-		log.Printf("[JobService] WARNING: failed to spawn worker for job %s: %v", job.ID, err)
+		logger.Warn("failed to spawn worker", "error", err)
 		return
 	}
 
 	if result.Error != "" {
-		log.Printf("[JobService] WARNING: spawner returned error for job %s: %s", job.ID, result.Error)
+		logger.Warn("spawner returned an error", "error", result.Error)
 		return
 	}
 
-	log.Printf("[JobService] Spawned worker for job %s (worker: %s, status: %s, spawner: %s)",
-		job.ID, result.WorkerID, result.Status, s.spawner.Name())
+	logger.Info("spawned worker",
+		"worker_id", result.WorkerID, "status", result.Status, "spawner", s.spawner.Name())
 }
 
 // GetByID retrieves a job by ID
@@ -273,9 +522,83 @@ func (s *JobService) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job, er
 		return nil, ErrJobNotFound
 	}
 
+	// Jobs from before google_domain searched the default one
+	job.Config.GoogleDomain = job.Config.SearchDomain()
+
+	if s.bandwidth != nil {
+		// The job is still worth returning without its traffic
+		traffic, err := s.bandwidth.JobTotal(ctx, id)
+		if err != nil {
+			logging.Logger(ctx, "JobService").Warn("failed to sum job bandwidth", "job_id", id, "error", err)
+		}
+		job.Bandwidth = traffic
+	}
+
+	// Likewise without its dependency chain
+	if err := s.dependencyChain(ctx, job); err != nil {
+		logging.Logger(ctx, "JobService").Warn("failed to get job dependencies", "job_id", id, "error", err)
+	}
+
+	// Or its shards
+	if job.ShardCount > 0 {
+		shards, err := s.shards(ctx, id, false)
+		if err != nil {
+			logging.Logger(ctx, "JobService").Warn("failed to get job shards", "job_id", id, "error", err)
+		}
+		for _, shard := range shards {
+			job.Shards = append(job.Shards, domain.LinkTo(shard))
+		}
+	}
+
+	// And without the results stored so far
+	if s.results != nil {
+		if job.ResultsAvailable, err = s.results.CountByJobID(ctx, id); err != nil {
+			logging.Logger(ctx, "JobService").Warn("failed to count job results", "job_id", id, "error", err)
+		}
+		if job.LastResultAt, err = s.results.LastCreatedAt(ctx, id); err != nil {
+			logging.Logger(ctx, "JobService").Warn("failed to get last job result", "job_id", id, "error", err)
+		}
+	}
+
 	return job, nil
 }
 
+// AcceptResults returns nil if result batches for the job are to be
+// stored, ErrJobFinished if they are stale and ErrJobNotFound if there is
+// no such job
+func (s *JobService) AcceptResults(ctx context.Context, id uuid.UUID) error {
+	job, err := s.jobs.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get job: %w", err)
+	}
+	if job == nil {
+		return ErrJobNotFound
+	}
+	if !job.AcceptsResults() && !job.AwaitsLateResults(time.Now(), s.lateResultsWindow) {
+		return ErrJobFinished
+	}
+
+	return nil
+}
+
+// ParseReport returns the parse report of a job's last completed run, with
+// no places when none was reported
+func (s *JobService) ParseReport(ctx context.Context, id uuid.UUID) (*domain.JobParseReport, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	report, err := s.jobs.GetParseReport(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parse report: %w", err)
+	}
+	if report == nil {
+		report = &domain.JobParseReport{}
+	}
+
+	return report, nil
+}
+
 // List retrieves jobs with optional filtering
 func (s *JobService) List(ctx context.Context, params domain.JobListParams) ([]*domain.Job, int, error) {
 	jobs, total, err := s.jobs.List(ctx, params)
@@ -286,7 +609,31 @@ func (s *JobService) List(ctx context.Context, params domain.JobListParams) ([]*
 	return jobs, total, nil
 }
 
-// Delete deletes a job and its results
+// Patch edits the tags and notes of a job, whatever its status
+func (s *JobService) Patch(ctx context.Context, id uuid.UUID, req *domain.PatchJobRequest) (*domain.Job, error) {
+	job, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Tags != nil {
+		job.Tags = domain.NormalizeTags(*req.Tags)
+	}
+	if req.Notes != nil {
+		job.Notes = *req.Notes
+	}
+
+	if err := s.jobs.UpdateLabels(ctx, id, job.Tags, job.Notes); err != nil {
+		return nil, fmt.Errorf("failed to update job: %w", err)
+	}
+	job.UpdatedAt = time.Now().UTC()
+
+	return job, nil
+}
+
+// Delete marks a job as deleted. It disappears from the job list, the
+// stats and the claims; its results are kept until it is restored or
+// purged.
 func (s *JobService) Delete(ctx context.Context, id uuid.UUID) error {
 	job, err := s.jobs.GetByID(ctx, id)
 	if err != nil {
@@ -296,9 +643,88 @@ func (s *JobService) Delete(ctx context.Context, id uuid.UUID) error {
 		return ErrJobNotFound
 	}
 
-	// Don't allow deleting running jobs
 	if job.Status == domain.JobStatusRunning {
-		return errors.New("cannot delete a running job, cancel it first")
+		return ErrJobRunning
+	}
+
+	deleted := &domain.JobEvent{JobID: id, Type: domain.JobEventDeleted}
+	if err := s.audit.apply(ctx, s.jobs, deleted, func(jobs domain.JobRepository) error {
+		return jobs.SoftDelete(ctx, id)
+	}); err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+
+	if job.ShardCount > 0 {
+		s.eachShard(ctx, id, "delete", false, func(shard *domain.Job) error {
+			return s.Delete(ctx, shard.ID)
+		})
+	}
+
+	return nil
+}
+
+// Restore undoes the deletion of a job. A pending job is enqueued again.
+func (s *JobService) Restore(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	job, err := s.jobs.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	if job == nil {
+		return nil, ErrJobNotFound
+	}
+	if job.DeletedAt == nil {
+		return nil, ErrJobNotDeleted
+	}
+
+	restored := &domain.JobEvent{JobID: id, Type: domain.JobEventRestored}
+	if err := s.audit.apply(ctx, s.jobs, restored, func(jobs domain.JobRepository) error {
+		return jobs.Restore(ctx, id)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to restore job: %w", err)
+	}
+	job.DeletedAt = nil
+
+	if job.Status == domain.JobStatusPending {
+		s.requeue(ctx, job, "restored")
+	}
+
+	if job.ShardCount > 0 {
+		s.eachShard(ctx, id, "restore", true, func(shard *domain.Job) error {
+			if shard.DeletedAt == nil {
+				return nil
+			}
+			_, err := s.Restore(ctx, shard.ID)
+			return err
+		})
+	}
+
+	return job, nil
+}
+
+// Purge deletes a job and its results for good
+func (s *JobService) Purge(ctx context.Context, id uuid.UUID) error {
+	job, err := s.jobs.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get job: %w", err)
+	}
+	if job == nil {
+		return ErrJobNotFound
+	}
+
+	if job.Status == domain.JobStatusRunning {
+		return ErrJobRunning
+	}
+
+	if job.ShardCount > 0 {
+		shards, err := s.shards(ctx, id, true)
+		if err != nil {
+			return err
+		}
+		for _, shard := range shards {
+			if err := s.Purge(ctx, shard.ID); err != nil {
+				return fmt.Errorf("failed to purge shard %s: %w", shard.ID, err)
+			}
+		}
 	}
 
 	// Delete results first (cascade should handle this, but be explicit)
@@ -313,6 +739,59 @@ func (s *JobService) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// PurgeDeleted purges the jobs deleted before t and returns their IDs
+func (s *JobService) PurgeDeleted(ctx context.Context, t time.Time) ([]uuid.UUID, error) {
+	var purged []uuid.UUID
+
+	for {
+		ids, err := s.jobs.ListDeletedBefore(ctx, t, deletedJobPurgeBatch)
+		if err != nil {
+			return purged, fmt.Errorf("failed to list deleted jobs: %w", err)
+		}
+
+		for _, id := range ids {
+			if err := s.Purge(ctx, id); err != nil && !errors.Is(err, ErrJobNotFound) {
+				return purged, fmt.Errorf("failed to purge job %s: %w", id, err)
+			}
+			purged = append(purged, id)
+		}
+
+		if len(ids) < deletedJobPurgeBatch {
+			return purged, nil
+		}
+	}
+}
+
+// RunDeletedJobRetention purges jobs deleted longer than retention ago,
+// every hour until ctx is done. onPurge is called with the purged jobs.
+func (s *JobService) RunDeletedJobRetention(ctx context.Context, retention time.Duration, onPurge func(ids []uuid.UUID)) error {
+	logger := logging.Logger(ctx, "JobService")
+	logger.Info("deleted job retention started", "retention", retention.String())
+
+	ticker := time.NewTicker(deletedJobSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		ids, err := s.PurgeDeleted(ctx, time.Now().Add(-retention))
+		if err != nil {
+			logger.Warn("deleted job retention failed", "error", err)
+		}
+		if len(ids) > 0 {
+			logger.Info("purged deleted jobs", "count", len(ids))
+			if onPurge != nil {
+				onPurge(ids)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info("deleted job retention stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
 // Pause pauses a running or queued job
 func (s *JobService) Pause(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
 	job, err := s.jobs.GetByID(ctx, id)
@@ -327,10 +806,16 @@ func (s *JobService) Pause(ctx context.Context, id uuid.UUID) (*domain.Job, erro
 		return nil, ErrJobNotPausable
 	}
 
-	if err := s.jobs.UpdateStatus(ctx, id, domain.JobStatusPaused); err != nil {
+	if err := s.setStatus(ctx, id, domain.JobStatusPaused, domain.JobEventPaused); err != nil {
 		return nil, fmt.Errorf("failed to pause job: %w", err)
 	}
 
+	s.publishStatus(ctx, id, domain.JobStatusPaused, "")
+
+	if job.ShardCount > 0 {
+		s.pauseShards(ctx, id)
+	}
+
 	job.Status = domain.JobStatusPaused
 	return job, nil
 }
@@ -349,34 +834,298 @@ func (s *JobService) Resume(ctx context.Context, id uuid.UUID) (*domain.Job, err
 		return nil, ErrJobNotResumable
 	}
 
-	// Resume to pending so a worker can pick it up
-	if err := s.jobs.UpdateStatus(ctx, id, domain.JobStatusPending); err != nil {
+	// Resume to pending so a worker can pick it up; a sharded job runs
+	// again through its shards
+	status := domain.JobStatusPending
+	if job.ShardCount > 0 {
+		status = domain.JobStatusRunning
+	}
+
+	if err := s.setStatus(ctx, id, status, domain.JobEventResumed); err != nil {
 		return nil, fmt.Errorf("failed to resume job: %w", err)
 	}
 
-	// Re-enqueue to RabbitMQ if available (preferred over Redis)
-	if s.mqPub != nil {
+	s.publishStatus(ctx, id, status, "")
+
+	if job.ShardCount > 0 {
+		s.eachShard(ctx, id, "resume", false, func(shard *domain.Job) error {
+			if !shard.Status.CanResume() {
+				return nil
+			}
+			_, err := s.Resume(ctx, shard.ID)
+			return err
+		})
+	} else {
+		s.requeue(ctx, job, "resumed")
+	}
+
+	job.Status = status
+	return job, nil
+}
+
+// requeue enqueues a job again so a worker picks it up; reason is how the
+// job got back to pending, for the logs
+func (s *JobService) requeue(ctx context.Context, job *domain.Job, reason string) {
+	logger := logging.Logger(ctx, "JobService").With("job_id", job.ID, "reason", reason)
+
+	if backend, err := s.enqueue(ctx, job); err != nil {
+		logger.Warn("failed to re-enqueue job", "queue", backend, "error", err)
+	} else if backend != domain.QueueBackendNone {
+		logger.Info("job re-enqueued", "queue", backend)
+	}
+}
+
+// enqueue sends a job to the queue under a new message ID, stored with the
+// job first so a manual requeue can publish the message again
+func (s *JobService) enqueue(ctx context.Context, job *domain.Job) (string, error) {
+	if s.claimable != nil {
+		s.claimable.PublishClaimable(ctx)
+	}
+
+	if s.mqPub == nil && s.queue == nil {
+		return domain.QueueBackendNone, nil
+	}
+
+	messageID := uuid.NewString()
+	if err := s.jobs.SetQueueMessageID(ctx, job.ID, messageID); err != nil {
+		logging.Logger(ctx, "JobService").Warn("failed to store queue message ID", "job_id", job.ID, "error", err)
+	}
+
+	backend, err := s.publish(ctx, job, messageID)
+	if err == nil {
+		s.audit.record(ctx, &domain.JobEvent{JobID: job.ID, Type: domain.JobEventQueued, Message: backend})
+	}
+	return backend, err
+}
+
+// setStatus changes the status of a job, recording typ in the audit trail
+func (s *JobService) setStatus(ctx context.Context, id uuid.UUID, status domain.JobStatus, typ string) error {
+	ev := &domain.JobEvent{JobID: id, Type: typ, Status: status}
+	return s.audit.apply(ctx, s.jobs, ev, func(jobs domain.JobRepository) error {
+		return jobs.UpdateStatus(ctx, id, status)
+	})
+}
+
+// update stores a job, recording ev in the audit trail
+func (s *JobService) update(ctx context.Context, job *domain.Job, ev *domain.JobEvent) error {
+	return s.audit.apply(ctx, s.jobs, ev, func(jobs domain.JobRepository) error {
+		return jobs.Update(ctx, job)
+	})
+}
+
+// publish sends a job to RabbitMQ, or to the Redis queue without it, and
+// returns the backend it went to
+func (s *JobService) publish(ctx context.Context, job *domain.Job, messageID string) (string, error) {
+	switch {
+	case s.mqPub != nil:
 		msg := &mq.JobMessage{
-			JobID:    job.ID,
-			Priority: job.Priority,
-			Type:     "job:process",
+			JobID:     job.ID,
+			Priority:  job.Priority,
+			Type:      "job:process",
+			MessageID: messageID,
 		}
-		if err := s.mqPub.Publish(ctx, msg); err != nil {
-			log.Printf("[JobService] WARNING: failed to re-publish resumed job %s to RabbitMQ: %v", job.ID, err)
-		} else {
-			log.Printf("[JobService] Resumed job %s re-published to RabbitMQ queue", job.ID)
+		return domain.QueueBackendRabbitMQ, s.mqPub.Publish(ctx, msg)
+	case s.queue != nil:
+		return domain.QueueBackendRedis, s.queue.Enqueue(ctx, job.ID, job.Priority, messageID)
+	default:
+		return domain.QueueBackendNone, nil
+	}
+}
+
+// Requeue publishes the message that enqueued a pending job again, for a
+// message lost or stuck in the queue. Workers drop message IDs they
+// already handled, so the job runs once should the first message turn up
+// after all.
+func (s *JobService) Requeue(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	job, err := s.jobs.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrJobNotFound
+	}
+
+	if job.Status != domain.JobStatusPending || job.DeletedAt != nil {
+		return nil, ErrJobNotPending
+	}
+	if s.mqPub == nil && s.queue == nil {
+		return nil, ErrNoJobQueue
+	}
+
+	messageID, err := s.jobs.GetQueueMessageID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue message ID: %w", err)
+	}
+	// Enqueued before message IDs were stored
+	if messageID == "" {
+		messageID = uuid.NewString()
+		if err := s.jobs.SetQueueMessageID(ctx, id, messageID); err != nil {
+			return nil, fmt.Errorf("failed to store queue message ID: %w", err)
 		}
-	} else if s.queue != nil {
-		// Fallback to Redis queue
-		if err := s.queue.Enqueue(ctx, job.ID, job.Priority); err != nil {
-			log.Printf("[JobService] WARNING: failed to re-enqueue resumed job %s to Redis: %v", job.ID, err)
-		} else {
-			log.Printf("[JobService] Resumed job %s re-enqueued to Redis queue", job.ID)
+	}
+
+	backend, err := s.publish(ctx, job, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to requeue job: %w", err)
+	}
+
+	logging.Logger(ctx, "JobService").Info("job requeued manually",
+		"job_id", id, "queue", backend, "message_id", messageID)
+	s.audit.record(ctx, &domain.JobEvent{JobID: id, Type: domain.JobEventRequeued, Message: backend})
+	if s.events != nil {
+		s.events.Publish(ctx, events.Event{
+			Type:   events.TypeRequeued,
+			JobID:  id,
+			Status: job.Status,
+			Time:   time.Now().UTC(),
+		})
+	}
+
+	return job, nil
+}
+
+// QueueStatus returns the depth, consumers and oldest message age of the
+// job queues of the active backend
+func (s *JobService) QueueStatus(ctx context.Context) (*domain.QueueStatus, error) {
+	switch {
+	case s.mqPub != nil:
+		return s.mqPub.QueueStatus(ctx)
+	case s.queue != nil:
+		return s.queue.QueueStatus(ctx)
+	default:
+		return &domain.QueueStatus{Backend: domain.QueueBackendNone, Queues: []*domain.QueueDepth{}}, nil
+	}
+}
+
+// Events returns a page of the audit trail of a job, oldest first, and the
+// number of its events
+func (s *JobService) Events(ctx context.Context, id uuid.UUID, limit, offset int) ([]*domain.JobEvent, int, error) {
+	if s.audit == nil {
+		return nil, 0, ErrNoJobEvents
+	}
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, 0, err
+	}
+
+	events, total, err := s.audit.repo.ListByJobID(ctx, id, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list job events: %w", err)
+	}
+
+	return events, total, nil
+}
+
+// RetryFailed requeues the failed searches of a job that ran fewer than
+// maxAttempts times. A job bridged to DSN workers retries its failed seed
+// tasks and is running again right away; otherwise the keywords the last
+// run failed are run again once a worker claims the job. A second call
+// before the retry has run requeues nothing. A failed job is only retried
+// if its error code is retryable.
+func (s *JobService) RetryFailed(ctx context.Context, id uuid.UUID, maxAttempts int) (*domain.RetryResult, error) {
+	s.retryMu.Lock()
+	defer s.retryMu.Unlock()
+
+	job, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !job.Status.CanRetry() {
+		return nil, ErrJobNotRetryable
+	}
+	if job.Status == domain.JobStatusFailed && !job.ErrorCode.Retryable() {
+		return nil, fmt.Errorf("%w: %s", ErrFailureNotRetryable, job.ErrorCode)
+	}
+
+	if job.ShardCount > 0 {
+		return s.retryShards(ctx, job, maxAttempts)
+	}
+
+	return s.retry(ctx, job, maxAttempts)
+}
+
+// retry requeues the failed searches of a job that is no sharded one
+func (s *JobService) retry(ctx context.Context, job *domain.Job, maxAttempts int) (*domain.RetryResult, error) {
+	if s.seedTasks != nil {
+		counts, err := s.seedTasks.CountsByParent(ctx, job.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count seed tasks: %w", err)
 		}
+		if counts.Total > 0 {
+			return s.retrySeedTasks(ctx, job, maxAttempts)
+		}
+	}
+
+	return s.retryKeywords(ctx, job, maxAttempts)
+}
+
+func (s *JobService) retrySeedTasks(ctx context.Context, job *domain.Job, maxAttempts int) (*domain.RetryResult, error) {
+	requeued, exhausted, err := s.seedTasks.RetryFailed(ctx, job.ID, maxAttempts)
+	if err != nil {
+		return nil, err
 	}
 
+	result := &domain.RetryResult{Status: job.Status, Requeued: requeued, Exhausted: exhausted}
+
+	if requeued > 0 && job.Status.IsTerminal() {
+		job.Status = domain.JobStatusRunning
+		job.CompletedAt = nil
+		job.ErrorMessage = nil
+		job.ErrorCode = ""
+
+		retried := &domain.JobEvent{JobID: job.ID, Type: domain.JobEventRetried, Status: job.Status,
+			Message: fmt.Sprintf("%d failed seed tasks requeued", requeued)}
+		if err := s.update(ctx, job, retried); err != nil {
+			return nil, fmt.Errorf("failed to reopen job: %w", err)
+		}
+
+		s.publishStatus(ctx, job.ID, domain.JobStatusRunning, "")
+		result.Status = domain.JobStatusRunning
+	}
+
+	logging.Logger(ctx, "JobService").Info("failed seed tasks requeued",
+		"job_id", job.ID, "requeued", requeued, "exhausted", exhausted)
+	return result, nil
+}
+
+func (s *JobService) retryKeywords(ctx context.Context, job *domain.Job, maxAttempts int) (*domain.RetryResult, error) {
+	result := &domain.RetryResult{Status: job.Status}
+
+	// A run in progress reports its own failed keywords when it ends
+	if !job.Status.IsTerminal() || len(job.FailedKeywords) == 0 {
+		return result, nil
+	}
+
+	if job.Attempts >= maxAttempts {
+		result.Exhausted = len(job.FailedKeywords)
+		return result, nil
+	}
+
+	job.RetryKeywords = job.FailedKeywords
+	job.FailedKeywords = nil
+	job.Attempts++
 	job.Status = domain.JobStatusPending
-	return job, nil
+	job.WorkerID = nil
+	job.StartedAt = nil
+	job.CompletedAt = nil
+	job.ErrorMessage = nil
+	job.ErrorCode = ""
+
+	retried := &domain.JobEvent{JobID: job.ID, Type: domain.JobEventRetried, Status: job.Status,
+		Message: fmt.Sprintf("%d failed keywords requeued, attempt %d", len(job.RetryKeywords), job.Attempts)}
+	if err := s.update(ctx, job, retried); err != nil {
+		return nil, fmt.Errorf("failed to reopen job: %w", err)
+	}
+
+	s.publishStatus(ctx, job.ID, domain.JobStatusPending, "")
+	s.requeue(ctx, job, "retried")
+
+	logging.Logger(ctx, "JobService").Info("failed keywords requeued",
+		"job_id", job.ID, "requeued", len(job.RetryKeywords), "attempt", job.Attempts)
+
+	result.Status = domain.JobStatusPending
+	result.Requeued = len(job.RetryKeywords)
+	return result, nil
 }
 
 // Cancel cancels a job
@@ -393,10 +1142,22 @@ func (s *JobService) Cancel(ctx context.Context, id uuid.UUID) (*domain.Job, err
 		return nil, ErrJobNotCancellable
 	}
 
-	if err := s.jobs.UpdateStatus(ctx, id, domain.JobStatusCancelled); err != nil {
+	if err := s.setStatus(ctx, id, domain.JobStatusCancelled, domain.JobEventCancelled); err != nil {
 		return nil, fmt.Errorf("failed to cancel job: %w", err)
 	}
 
+	s.publishStatus(ctx, id, domain.JobStatusCancelled, "")
+
+	if job.ShardCount > 0 {
+		s.eachShard(ctx, id, "cancel", false, func(shard *domain.Job) error {
+			if !shard.Status.CanCancel() {
+				return nil
+			}
+			_, err := s.Cancel(ctx, shard.ID)
+			return err
+		})
+	}
+
 	job.Status = domain.JobStatusCancelled
 	return job, nil
 }
@@ -404,16 +1165,77 @@ func (s *JobService) Cancel(ctx context.Context, id uuid.UUID) (*domain.Job, err
 // UpdateProgress updates job progress
 func (s *JobService) UpdateProgress(ctx context.Context, id uuid.UUID, progress domain.JobProgress) error {
 	progress.CalculatePercentage()
-	return s.jobs.UpdateProgress(ctx, id, progress)
+	if err := s.jobs.UpdateProgress(ctx, id, progress); err != nil {
+		return err
+	}
+
+	if s.events != nil {
+		s.events.Publish(ctx, events.Event{
+			Type:     events.TypeProgress,
+			JobID:    id,
+			Progress: &progress,
+			Time:     time.Now().UTC(),
+		})
+	}
+
+	if m := s.audit.milestone(id, progress.Percentage); m > 0 {
+		s.audit.record(ctx, &domain.JobEvent{JobID: id, Type: domain.JobEventProgress,
+			Message: fmt.Sprintf("%d%% (%d of %d places)", m, progress.ScrapedPlaces, progress.TotalPlaces)})
+	}
+
+	return nil
+}
+
+// SyncProgress sets the progress of a job from the results stored for it,
+// the partial ones counted apart. A backfill also updates the job it
+// backfills, whose partial results it upgraded, and a shard the job it is
+// a shard of.
+func (s *JobService) SyncProgress(ctx context.Context, id uuid.UUID) error {
+	job, err := s.jobs.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return ErrJobNotFound
+	}
+
+	ids := []uuid.UUID{id}
+	if job.BackfillOf != nil {
+		ids = append(ids, *job.BackfillOf)
+	}
+
+	for _, jobID := range ids {
+		counts, err := s.results.CountsByJobID(ctx, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to count results: %w", err)
+		}
+		if err := s.UpdateProgress(ctx, jobID, counts.Progress()); err != nil {
+			return err
+		}
+	}
+
+	if job.ParentJobID != nil {
+		return s.syncShards(ctx, *job.ParentJobID)
+	}
+
+	return nil
 }
 
 // Complete marks a job as completed
 func (s *JobService) Complete(ctx context.Context, id uuid.UUID) error {
-	return s.jobs.UpdateStatus(ctx, id, domain.JobStatusCompleted)
+	if err := s.setStatus(ctx, id, domain.JobStatusCompleted, domain.JobEventCompleted); err != nil {
+		return err
+	}
+
+	s.publishStatus(ctx, id, domain.JobStatusCompleted, "")
+	if s.reports != nil {
+		s.reports.JobCompleted(ctx, id)
+	}
+	return nil
 }
 
-// Fail marks a job as failed with an error message
-func (s *JobService) Fail(ctx context.Context, id uuid.UUID, errMsg string) error {
+// Fail marks a job as failed with an error code and message
+func (s *JobService) Fail(ctx context.Context, id uuid.UUID, code domain.JobErrorCode, errMsg string) error {
 	job, err := s.jobs.GetByID(ctx, id)
 	if err != nil {
 		return err
@@ -424,8 +1246,15 @@ func (s *JobService) Fail(ctx context.Context, id uuid.UUID, errMsg string) erro
 
 	job.Status = domain.JobStatusFailed
 	job.ErrorMessage = &errMsg
+	job.ErrorCode = code
 
-	return s.jobs.Update(ctx, job)
+	failed := &domain.JobEvent{JobID: id, Type: domain.JobEventFailed, Status: job.Status, ErrorCode: code, Message: errMsg}
+	if err := s.update(ctx, job, failed); err != nil {
+		return err
+	}
+
+	s.publishStatus(ctx, id, domain.JobStatusFailed, errMsg)
+	return nil
 }
 
 // GetStats retrieves job statistics
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/jobarchive"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// errArchiveComplete stops the result stream once the rows counted for the
+// manifest are written
+var errArchiveComplete = errors.New("archive complete")
+
+// Archive writes job id with its raw results to w as a job archive. The
+// results counted when it starts are written; results stored meanwhile are
+// left out, so the manifest stays true for running jobs.
+func (s *JobService) Archive(ctx context.Context, id uuid.UUID, w io.Writer) error {
+	job, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.results.CountByJobID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to count results: %w", err)
+	}
+
+	aw, err := jobarchive.NewWriter(w, jobarchive.Manifest{Results: count}, job)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		err = s.results.StreamByJobID(ctx, id, func(data []byte) error {
+			if err := aw.WriteResult(data); err != nil {
+				return err
+			}
+			if aw.Results() == count {
+				return errArchiveComplete
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errArchiveComplete) {
+			return fmt.Errorf("failed to stream results: %w", err)
+		}
+		if aw.Results() != count {
+			return fmt.Errorf("%d of %d results found, results were deleted while archiving", aw.Results(), count)
+		}
+	}
+
+	return aw.Close()
+}
+
+// Import recreates the job of the archive in r as a new completed job of
+// tenant and stores its results through CreateBatch, so they are
+// normalized like submitted ones. An archive that fails half way is
+// removed again.
+func (s *JobService) Import(ctx context.Context, r io.Reader, tenant string) (*domain.JobImport, error) {
+	ar, err := jobarchive.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	source := ar.Job
+	if source == nil {
+		return nil, fmt.Errorf("%w: empty job definition", jobarchive.ErrMalformed)
+	}
+
+	now := time.Now().UTC()
+	job := *source
+	job.ID = uuid.New()
+	job.Status = domain.JobStatusCompleted
+	job.WorkerID = nil
+	job.Tenant = tenant
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	job.DeletedAt = nil
+	job.Checkpoint = nil
+	job.RetryKeywords = nil
+	job.ClonedFrom = nil
+	job.BackfillOf = nil
+	job.DependsOn, job.RunIf = nil, ""
+	job.Dependencies, job.Dependents = nil, nil
+	if job.CompletedAt == nil {
+		job.CompletedAt = &now
+	}
+
+	if err := s.jobs.Create(ctx, &job); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	logger := logging.Logger(ctx, "JobService").With("job_id", job.ID, "source_job_id", ar.Manifest.JobID)
+
+	// Create leaves out the run state; Update stores it
+	err = s.jobs.Update(ctx, &job)
+	if err == nil {
+		_, err = ar.Results(func(rows [][]byte) error {
+			return s.results.CreateBatch(ctx, job.ID, uuid.Nil, rows)
+		})
+	}
+	if err != nil {
+		if perr := s.Purge(context.WithoutCancel(ctx), job.ID); perr != nil {
+			logger.Error("failed to remove partly imported job", "error", perr)
+		}
+		return nil, fmt.Errorf("failed to import job: %w", err)
+	}
+
+	logger.Info("job imported", "results", ar.Manifest.Results)
+
+	return &domain.JobImport{
+		Job:           &job,
+		SourceJobID:   ar.Manifest.JobID,
+		SchemaVersion: ar.Manifest.SchemaVersion,
+		Sections: []domain.ImportSection{
+			{Name: "job", InArchive: 1, Imported: 1},
+			{Name: "results", InArchive: ar.Manifest.Results, Imported: ar.Manifest.Results},
+		},
+	}, nil
+}
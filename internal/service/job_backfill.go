@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+var (
+	// ErrJobNotFinished is returned when backfilling a job that still runs
+	ErrJobNotFinished = errors.New("job has not finished yet")
+
+	// ErrNoPartialResults is returned when backfilling a job that kept no
+	// partial results
+	ErrNoPartialResults = errors.New("job has no partial results")
+)
+
+// Backfill creates a job that scrapes the pages of the places job id kept
+// partial. Its results replace the partial ones where they are stored, so
+// the listings keep their IDs and the source job its results.
+func (s *JobService) Backfill(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	source, err := s.jobs.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, ErrJobNotFound
+	}
+	if !source.Status.IsTerminal() {
+		return nil, ErrJobNotFinished
+	}
+
+	links, err := s.results.ListPartialLinks(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partial results: %w", err)
+	}
+	if len(links) == 0 {
+		return nil, ErrNoPartialResults
+	}
+
+	quota, err := s.quota(ctx, source.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	if quota != nil {
+		if err := quota.Check(); err != nil {
+			return nil, err
+		}
+	}
+
+	// The places are known, so nothing of the search carries over
+	config := source.Config
+	config.Keywords = []string{}
+	config.PlaceURLs = links
+	config.MaxResults = 0
+	config.Incremental = false
+	config.CoverageMode = domain.CoverageModeSingle
+	config.BoundingBox = nil
+	config.GridPoints = 1
+	config.DensityCheck = false
+
+	now := time.Now().UTC()
+	job := &domain.Job{
+		ID:         uuid.New(),
+		Name:       source.Name + " (backfill)",
+		Status:     domain.JobStatusPending,
+		Priority:   source.Priority,
+		Config:     config,
+		Tenant:     source.Tenant,
+		Progress:   domain.JobProgress{TotalPlaces: len(links)},
+		Attempts:   1,
+		BackfillOf: &source.ID,
+		Tags:       domain.NormalizeTags(source.Tags),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	logger := logging.Logger(ctx, "JobService").With("job_id", job.ID, "backfill_of", source.ID)
+
+	created := &domain.JobEvent{JobID: job.ID, Type: domain.JobEventCreated, Status: job.Status,
+		Message: fmt.Sprintf("backfill of %d partial places of job %s", len(links), source.ID)}
+	if err := s.audit.apply(ctx, s.jobs, created, func(jobs domain.JobRepository) error {
+		return jobs.Create(ctx, job)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	logger.Info("backfill job stored", "places", len(links))
+
+	s.dispatch(ctx, logger, job)
+
+	return job, nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gosom/scrapemate"
+
+	"github.com/sadewadee/google-scraper/gmaps"
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/postgres"
+)
+
+// ErrJobNotEditable is returned when editing the config of a job a worker
+// may hold, or of a job split into shards, whose shards carry the config
+var ErrJobNotEditable = errors.New("only the config of pending or paused jobs can be edited")
+
+// UpdateConfig replaces the config of a pending or paused job with the one
+// req builds, keeping the job's place and queue position. The place
+// estimate follows the new config and, for a job bridged to DSN workers,
+// its unclaimed seed tasks are replaced by those of the new config.
+func (s *JobService) UpdateConfig(ctx context.Context, id uuid.UUID, req *domain.CreateJobRequest) (*domain.Job, error) {
+	job, err := s.jobs.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	if job == nil || job.DeletedAt != nil {
+		return nil, ErrJobNotFound
+	}
+	if !job.Status.CanEditConfig() || job.ShardCount > 0 {
+		return nil, fmt.Errorf("%w: job is %s", ErrJobNotEditable, job.Status)
+	}
+
+	built, err := req.ToJob(s.keywordLimit())
+	if err != nil {
+		return nil, err
+	}
+	config := built.Config
+	if job.BackfillOf != nil {
+		// A backfill scrapes the places its source kept partial, whatever
+		// the edit
+		config.Keywords, config.PlaceURLs = job.Config.Keywords, job.Config.PlaceURLs
+		built.Progress.TotalPlaces = len(config.PlaceURLs)
+	}
+
+	// Proxies attached for a country are picked again, as on creation
+	if config.ProxyCountry != "" && len(config.Proxies) == 0 {
+		if config.Proxies, err = s.countryProxies(ctx, config.ProxyCountry); err != nil {
+			return nil, err
+		}
+	}
+
+	changed := job.Config.ChangedFields(config)
+	if len(changed) == 0 {
+		return job, nil
+	}
+
+	logger := logging.Logger(ctx, "JobService").With("job_id", job.ID)
+
+	job.Config = config
+	job.Progress.TotalPlaces = built.Progress.TotalPlaces
+	job.UpdatedAt = time.Now().UTC()
+
+	// The config is only written while the job is still editable, and its
+	// seed tasks only replaced once it was, in the same transaction: a
+	// failed push or commit keeps the old config and seed tasks
+	edited := &domain.JobEvent{JobID: job.ID, Type: domain.JobEventEdited, Message: "changed " + strings.Join(changed, ", ")}
+	err = s.audit.apply(ctx, s.jobs, edited, func(jobs domain.JobRepository) error {
+		ok, err := jobs.UpdateConfig(ctx, job)
+		if err != nil {
+			return fmt.Errorf("failed to update job: %w", err)
+		}
+		if !ok {
+			return ErrJobNotEditable
+		}
+
+		bridged, err := s.rebridge(ctx, jobs, job)
+		if err != nil {
+			return fmt.Errorf("failed to replace seed tasks: %w", err)
+		}
+		if bridged == "" {
+			return nil
+		}
+		edited.Message += "; " + bridged
+
+		// The seed tasks set the grid points and place estimate
+		if _, err := jobs.UpdateConfig(ctx, job); err != nil {
+			return fmt.Errorf("failed to update job: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("job config edited", "fields", changed, "total_places", job.Progress.TotalPlaces)
+	return job, nil
+}
+
+// rebridge replaces the seed tasks of a job bridged to DSN workers that no
+// worker claimed yet with those of its config. Searches already claimed are
+// not pushed again; those the config dropped run to their end. It returns
+// what it did for the audit trail, empty for a job that was not bridged.
+// The seed tasks are replaced on the connection of jobs when it has them.
+func (s *JobService) rebridge(ctx context.Context, jobs domain.JobRepository, job *domain.Job) (string, error) {
+	if s.gmapsPush == nil || s.seedTasks == nil {
+		return "", nil
+	}
+
+	seedTasks, push := s.seedTasks, s.gmapsPush
+	if tx, ok := jobs.(seedTaskScope); ok {
+		seedTasks, push = tx.SeedTasks()
+	}
+
+	counts, err := seedTasks.CountsByParent(ctx, job.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to count seed tasks: %w", err)
+	}
+	if counts.Total == 0 {
+		return "", nil
+	}
+
+	deleted, err := seedTasks.DeleteNew(ctx, job.ID)
+	if err != nil {
+		return "", err
+	}
+
+	tasks, _, err := seedTasks.ListByParent(ctx, job.ID, domain.SeedTaskListParams{Limit: counts.Total})
+	if err != nil {
+		return "", fmt.Errorf("failed to list claimed seed tasks: %w", err)
+	}
+	claimed := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		if task.Keyword != "" {
+			claimed[task.Keyword+"\x00"+task.Geo] = true
+		}
+	}
+
+	pushed, err := s.bridgeToGmapsJobs(ctx, push, job, claimed)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d unclaimed seed tasks replaced by %d", deleted, pushed), nil
+}
+
+// seedTaskScope is implemented by job repositories that reach the seed
+// tasks of bridged jobs on their own connection, e.g. a transaction
+type seedTaskScope interface {
+	SeedTasks() (domain.SeedTaskRepository, postgres.GmapsJobPusher)
+}
+
+// seedTaskKey identifies the search of a seed job as gmaps_jobs stores it,
+// empty for place jobs
+func seedTaskKey(job scrapemate.IJob) string {
+	search, ok := job.(*gmaps.GmapJob)
+	if !ok {
+		return ""
+	}
+
+	query, geo := search.SearchQuery()
+	if query == "" {
+		return ""
+	}
+	return query + "\x00" + geo
+}
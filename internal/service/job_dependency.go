@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+var (
+	// ErrDependencyNotFound is returned when creating a job that depends on
+	// a job that does not exist or was deleted
+	ErrDependencyNotFound = errors.New("depends_on job not found")
+
+	// ErrDependencyCycle is returned when creating a job whose dependency
+	// chain leads back to a job already in it
+	ErrDependencyCycle = errors.New("depends_on makes a dependency cycle")
+
+	// ErrDependencyTooDeep is returned when creating a job under a chain of
+	// maxDependencyDepth jobs
+	ErrDependencyTooDeep = fmt.Errorf("depends_on chains are limited to %d jobs", maxDependencyDepth)
+)
+
+const (
+	// maxDependencyDepth caps the jobs a dependency chain walks up
+	maxDependencyDepth = 32
+
+	// Waiting jobs are checked against their parent this often, this many
+	// per query
+	dependencySweepInterval = 15 * time.Second
+	dependencySweepBatch    = 200
+
+	// maxDependents caps the dependents listed in the job detail
+	maxDependents = 100
+)
+
+// dependencyParent returns the job job depends on after checking that its
+// chain neither loops back nor grows past maxDependencyDepth
+func (s *JobService) dependencyParent(ctx context.Context, job *domain.Job) (*domain.Job, error) {
+	parent, err := s.jobs.GetByID(ctx, *job.DependsOn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get depends_on job: %w", err)
+	}
+	if parent == nil || parent.DeletedAt != nil {
+		return nil, ErrDependencyNotFound
+	}
+
+	seen := map[uuid.UUID]bool{job.ID: true}
+	for cur, depth := parent, 1; ; depth++ {
+		if seen[cur.ID] {
+			return nil, ErrDependencyCycle
+		}
+		seen[cur.ID] = true
+
+		if cur.DependsOn == nil {
+			return parent, nil
+		}
+		if depth >= maxDependencyDepth {
+			return nil, ErrDependencyTooDeep
+		}
+
+		next, err := s.jobs.GetByID(ctx, *cur.DependsOn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get depends_on job: %w", err)
+		}
+		if next == nil {
+			return parent, nil
+		}
+		cur = next
+	}
+}
+
+// dependencyChain fills in the dependencies and dependents of a job for
+// its detail
+func (s *JobService) dependencyChain(ctx context.Context, job *domain.Job) error {
+	seen := map[uuid.UUID]bool{job.ID: true}
+	for id := job.DependsOn; id != nil && !seen[*id] && len(job.Dependencies) < maxDependencyDepth; {
+		seen[*id] = true
+
+		parent, err := s.jobs.GetByID(ctx, *id)
+		if err != nil {
+			return fmt.Errorf("failed to get depends_on job: %w", err)
+		}
+		if parent == nil {
+			break
+		}
+
+		job.Dependencies = append(job.Dependencies, domain.LinkTo(parent))
+		id = parent.DependsOn
+	}
+
+	dependents, _, err := s.jobs.List(ctx, domain.JobListParams{
+		DependsOn: &job.ID,
+		Limit:     maxDependents,
+		OrderBy:   "created_at",
+		OrderDir:  "ASC",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list dependent jobs: %w", err)
+	}
+	for _, dep := range dependents {
+		job.Dependents = append(job.Dependents, domain.LinkTo(dep))
+	}
+
+	return nil
+}
+
+// resolveDependency moves a waiting job on once the job it depends on
+// finished: to pending, dispatching it, or to cancelled with the reason.
+// parent is nil when that job was purged.
+func (s *JobService) resolveDependency(ctx context.Context, logger *slog.Logger, job *domain.Job, parent *domain.Job) error {
+	outcome := domain.JobStatusCancelled
+	reason := "the job it depended on was purged"
+	if parent != nil {
+		outcome = job.DependencyOutcome(parent)
+		reason = fmt.Sprintf("job %s it depended on %s", parent.ID, parent.Status)
+	}
+
+	switch outcome {
+	case domain.JobStatusPending:
+		job.Status = domain.JobStatusPending
+		ev := &domain.JobEvent{JobID: job.ID, Type: domain.JobEventUnblocked, Status: job.Status, Message: reason}
+		if err := s.update(ctx, job, ev); err != nil {
+			return fmt.Errorf("failed to release job: %w", err)
+		}
+
+		s.publishStatus(ctx, job.ID, job.Status, "")
+		logger.Info("dependency met, job released", "job_id", job.ID, "reason", reason)
+		s.dispatch(ctx, logger.With("job_id", job.ID), job)
+	case domain.JobStatusCancelled:
+		now := time.Now().UTC()
+		job.Status = domain.JobStatusCancelled
+		job.CompletedAt = &now
+		job.ErrorMessage = &reason
+		ev := &domain.JobEvent{JobID: job.ID, Type: domain.JobEventCancelled, Status: job.Status, Message: reason}
+		if err := s.update(ctx, job, ev); err != nil {
+			return fmt.Errorf("failed to cancel job: %w", err)
+		}
+
+		s.publishStatus(ctx, job.ID, job.Status, reason)
+		logger.Info("dependency failed, job cancelled", "job_id", job.ID, "reason", reason)
+	}
+
+	return nil
+}
+
+// ResolveDependencies moves on every waiting job whose parent finished and
+// returns the jobs it moved
+func (s *JobService) ResolveDependencies(ctx context.Context) ([]uuid.UUID, error) {
+	logger := logging.Logger(ctx, "JobService")
+	waiting := domain.JobStatusWaiting
+	parents := make(map[uuid.UUID]*domain.Job)
+
+	var moved []uuid.UUID
+	for offset := 0; ; {
+		jobs, _, err := s.jobs.List(ctx, domain.JobListParams{
+			Status:   &waiting,
+			Limit:    dependencySweepBatch,
+			Offset:   offset,
+			OrderBy:  "created_at",
+			OrderDir: "ASC",
+		})
+		if err != nil {
+			return moved, fmt.Errorf("failed to list waiting jobs: %w", err)
+		}
+
+		for _, job := range jobs {
+			var parent *domain.Job
+			if job.DependsOn != nil {
+				var ok bool
+				if parent, ok = parents[*job.DependsOn]; !ok {
+					if parent, err = s.jobs.GetByID(ctx, *job.DependsOn); err != nil {
+						return moved, fmt.Errorf("failed to get depends_on job: %w", err)
+					}
+					parents[*job.DependsOn] = parent
+				}
+			}
+
+			if parent != nil && job.DependencyOutcome(parent) == domain.JobStatusWaiting {
+				offset++
+				continue
+			}
+
+			if err := s.resolveDependency(ctx, logger, job, parent); err != nil {
+				logger.Warn("failed to resolve dependency", "job_id", job.ID, "error", err)
+				offset++
+				continue
+			}
+			moved = append(moved, job.ID)
+		}
+
+		if len(jobs) < dependencySweepBatch {
+			return moved, nil
+		}
+	}
+}
+
+// RunDependencies resolves waiting jobs every dependencySweepInterval
+// until ctx is done. onChange is called with the jobs moved on.
+func (s *JobService) RunDependencies(ctx context.Context, onChange func(ids []uuid.UUID)) error {
+	logger := logging.Logger(ctx, "JobService")
+	logger.Info("job dependencies started", "interval", dependencySweepInterval.String())
+
+	ticker := time.NewTicker(dependencySweepInterval)
+	defer ticker.Stop()
+
+	for {
+		ids, err := s.ResolveDependencies(ctx)
+		if err != nil {
+			logger.Warn("job dependencies failed", "error", err)
+		}
+		if len(ids) > 0 && onChange != nil {
+			onChange(ids)
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info("job dependencies stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// jobAudit writes the audit trail of jobs. Its methods do nothing but the
+// change itself on a nil jobAudit, for stores without a trail.
+type jobAudit struct {
+	repo      domain.JobEventRepository
+	retention time.Duration // 0 keeps events for good
+
+	mu         sync.Mutex
+	milestones map[uuid.UUID]int // Last progress milestone recorded per job
+}
+
+func newJobAudit(repo domain.JobEventRepository, retention time.Duration) *jobAudit {
+	if repo == nil {
+		return nil
+	}
+	return &jobAudit{
+		repo:       repo,
+		retention:  retention,
+		milestones: make(map[uuid.UUID]int),
+	}
+}
+
+// apply runs fn on jobs and records ev in the same transaction. ev's actor
+// is taken from ctx unless it has one.
+func (a *jobAudit) apply(ctx context.Context, jobs domain.JobRepository, ev *domain.JobEvent, fn func(jobs domain.JobRepository) error) error {
+	if a == nil {
+		return fn(jobs)
+	}
+
+	if ev.Actor == "" {
+		ev.Actor = domain.ActorFromContext(ctx)
+	}
+	if err := a.repo.Record(ctx, ev, fn); err != nil {
+		return err
+	}
+
+	// A new run counts its milestones again
+	if ev.Type == domain.JobEventClaimed || ev.Status.IsTerminal() {
+		a.mu.Lock()
+		delete(a.milestones, ev.JobID)
+		a.mu.Unlock()
+	}
+
+	return nil
+}
+
+// record records an event that goes with no change to the job. The event
+// already happened, so a failure is only logged.
+func (a *jobAudit) record(ctx context.Context, ev *domain.JobEvent) {
+	if a == nil {
+		return
+	}
+
+	if err := a.apply(ctx, nil, ev, nil); err != nil {
+		logging.Logger(ctx, "JobAudit").Warn("failed to record job event",
+			"job_id", ev.JobID, "type", ev.Type, "error", err)
+	}
+}
+
+// milestone returns the progress milestone a job reached at percentage,
+// when it was not recorded yet, else 0. Completion is recorded by its own
+// event.
+func (a *jobAudit) milestone(jobID uuid.UUID, percentage float64) int {
+	if a == nil {
+		return 0
+	}
+
+	m := int(percentage) / domain.JobEventProgressStep * domain.JobEventProgressStep
+	if m <= 0 || m >= 100 {
+		return 0
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if m <= a.milestones[jobID] {
+		return 0
+	}
+	a.milestones[jobID] = m
+	return m
+}
+
+// RunJobEventRetention prunes the events of the audit trail past their
+// retention every hour until ctx is done
+func (s *JobService) RunJobEventRetention(ctx context.Context) error {
+	if s.audit == nil || s.audit.retention <= 0 {
+		return nil
+	}
+
+	logger := logging.Logger(ctx, "JobService")
+	logger.Info("job event retention started", "retention", s.audit.retention.String())
+
+	ticker := time.NewTicker(deletedJobSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		s.audit.prune(ctx)
+
+		select {
+		case <-ctx.Done():
+			logger.Info("job event retention stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// prune removes the events past the retention
+func (a *jobAudit) prune(ctx context.Context) {
+	if a == nil || a.retention <= 0 {
+		return
+	}
+
+	n, err := a.repo.DeleteBefore(ctx, time.Now().Add(-a.retention))
+	if err != nil {
+		logging.Logger(ctx, "JobAudit").Warn("failed to prune job events", "error", err)
+		return
+	}
+	if n > 0 {
+		logging.Logger(ctx, "JobAudit").Info("pruned job events", "count", n)
+	}
+}
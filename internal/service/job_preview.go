@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// SetWorkerConcurrency sets the searches the workers run at a time, which
+// spreads the runtime estimate of a preview (0 = default)
+func (s *JobService) SetWorkerConcurrency(n int) {
+	s.workerConcurrency = n
+}
+
+// Preview runs the checks and estimates of Create for req without storing,
+// enqueuing or dispatching anything
+func (s *JobService) Preview(ctx context.Context, req *domain.CreateJobRequest) (*domain.JobPreview, error) {
+	job, err := req.ToJob(s.keywordLimit())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.prepare(ctx, job); err != nil {
+		return nil, err
+	}
+
+	preview := req.Preview(job, s.workerConcurrency)
+
+	// Seed jobs are built as the bridge builds them, short of the density
+	// check, which probes Google Maps
+	var gridPoints []domain.GridPoint
+	if grid, ok := job.Config.Grid(); ok {
+		gridPoints = grid.Points
+	}
+	seeds, err := seedJobs(job, gridPoints)
+	if err != nil {
+		return nil, err
+	}
+	preview.SeedJobs = len(seeds)
+
+	quota, err := s.quota(ctx, job.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	if quota != nil && quota.Quota != nil {
+		remaining := quota.Remaining()
+		preview.QuotaRemaining = &remaining
+		preview.ExceedsQuota = preview.EstimatedPlaces > remaining
+
+		if err := quota.Check(); err != nil {
+			preview.Warn("%v; creating the job fails", err)
+		} else if preview.ExceedsQuota {
+			preview.Warn("the estimate of %d places exceeds the %d left of the monthly quota", preview.EstimatedPlaces, remaining)
+		}
+	}
+
+	return preview, nil
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// ErrJobBridged is returned when sharding a job whose searches were
+// already handed to DSN workers as seed tasks
+var ErrJobBridged = errors.New("job was handed to DSN workers and can no longer be sharded")
+
+const (
+	// Sharded jobs are brought up to date with their shards this often,
+	// this many per query
+	shardSweepInterval = 15 * time.Second
+	shardSweepBatch    = 200
+)
+
+// Shard splits the keywords of a pending or waiting job over count shards
+// and dispatches them, see domain.Job.Shard. The job stays as their
+// parent: its progress, results and status are theirs.
+func (s *JobService) Shard(ctx context.Context, id uuid.UUID, count int) (*domain.Job, error) {
+	job, err := s.jobs.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	if job == nil || job.DeletedAt != nil {
+		return nil, ErrJobNotFound
+	}
+
+	if s.seedTasks != nil {
+		counts, err := s.seedTasks.CountsByParent(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count seed tasks: %w", err)
+		}
+		if counts.Total > 0 {
+			return nil, ErrJobBridged
+		}
+	}
+
+	shards, err := job.Shard(count, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	logger := logging.Logger(ctx, "JobService").With("job_id", job.ID)
+	if err := s.storeShards(ctx, logger, job, shards, nil, func(jobs domain.JobRepository) error {
+		return jobs.Update(ctx, job)
+	}); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// createSharded stores a new job split into count shards, then dispatches
+// the shards. dependency is the job it depends on, if any.
+func (s *JobService) createSharded(ctx context.Context, logger *slog.Logger, job *domain.Job, count int, dependency *domain.Job) (*domain.Job, error) {
+	shards, err := job.Shard(count, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storeShards(ctx, logger, job, shards, dependency, func(jobs domain.JobRepository) error {
+		return jobs.Create(ctx, job)
+	}); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// storeShards stores job with store and its shards in one transaction,
+// then dispatches the pending shards. A waiting shard is released right
+// away if dependency already finished.
+func (s *JobService) storeShards(ctx context.Context, logger *slog.Logger, job *domain.Job, shards []*domain.Job, dependency *domain.Job, store func(jobs domain.JobRepository) error) error {
+	sharded := &domain.JobEvent{JobID: job.ID, Type: domain.JobEventSharded, Status: job.Status,
+		Message: fmt.Sprintf("split into %d shards", len(shards))}
+	if err := s.audit.apply(ctx, s.jobs, sharded, func(jobs domain.JobRepository) error {
+		if err := store(jobs); err != nil {
+			return err
+		}
+		for _, shard := range shards {
+			if err := jobs.Create(ctx, shard); err != nil {
+				return fmt.Errorf("failed to create shard: %w", err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to shard job: %w", err)
+	}
+
+	logger.Info("job sharded", "shards", len(shards))
+	s.publishStatus(ctx, job.ID, job.Status, "")
+
+	for _, shard := range shards {
+		job.Shards = append(job.Shards, domain.LinkTo(shard))
+
+		s.audit.record(ctx, &domain.JobEvent{JobID: shard.ID, Type: domain.JobEventCreated, Status: shard.Status,
+			Message: fmt.Sprintf("shard of job %s", job.ID)})
+
+		shardLogger := logger.With("shard_id", shard.ID)
+		if shard.Status != domain.JobStatusWaiting {
+			s.dispatch(ctx, shardLogger, shard)
+			continue
+		}
+		if dependency != nil && shard.DependencyOutcome(dependency) != domain.JobStatusWaiting {
+			if err := s.resolveDependency(ctx, shardLogger, shard, dependency); err != nil {
+				shardLogger.Warn("failed to resolve dependency", "depends_on", dependency.ID, "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// shards returns the shards of a job in order, the deleted ones only with
+// includeDeleted
+func (s *JobService) shards(ctx context.Context, id uuid.UUID, includeDeleted bool) ([]*domain.Job, error) {
+	shards, _, err := s.jobs.List(ctx, domain.JobListParams{
+		ParentJobID:    &id,
+		IncludeDeleted: includeDeleted,
+		Limit:          domain.MaxJobShards,
+		OrderBy:        "name",
+		OrderDir:       "ASC",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shards: %w", err)
+	}
+	return shards, nil
+}
+
+// eachShard runs fn on the shards of a job the action changed. The job
+// has already changed, so failures are only logged.
+func (s *JobService) eachShard(ctx context.Context, id uuid.UUID, action string, includeDeleted bool, fn func(shard *domain.Job) error) {
+	logger := logging.Logger(ctx, "JobService").With("job_id", id, "action", action)
+
+	shards, err := s.shards(ctx, id, includeDeleted)
+	if err != nil {
+		logger.Warn("failed to cascade to shards", "error", err)
+		return
+	}
+
+	for _, shard := range shards {
+		if err := fn(shard); err != nil {
+			logger.Warn("failed to cascade to shard", "shard_id", shard.ID, "error", err)
+		}
+	}
+}
+
+// pauseShards pauses the shards of a paused job: those running or queued
+// as Pause does, the pending ones before a worker claims them
+func (s *JobService) pauseShards(ctx context.Context, id uuid.UUID) {
+	s.eachShard(ctx, id, "pause", false, func(shard *domain.Job) error {
+		switch {
+		case shard.Status.CanPause():
+			_, err := s.Pause(ctx, shard.ID)
+			return err
+		case shard.Status == domain.JobStatusPending:
+			if err := s.setStatus(ctx, shard.ID, domain.JobStatusPaused, domain.JobEventPaused); err != nil {
+				return err
+			}
+			s.publishStatus(ctx, shard.ID, domain.JobStatusPaused, "")
+		}
+		return nil
+	})
+}
+
+// retryShards requeues the failed searches of the shards of a job, which
+// is running again right away if one of them was requeued
+func (s *JobService) retryShards(ctx context.Context, job *domain.Job, maxAttempts int) (*domain.RetryResult, error) {
+	shards, err := s.shards(ctx, job.ID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.RetryResult{Status: job.Status}
+	for _, shard := range shards {
+		if !shard.Status.CanRetry() || (shard.Status == domain.JobStatusFailed && !shard.ErrorCode.Retryable()) {
+			continue
+		}
+
+		r, err := s.retry(ctx, shard, maxAttempts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retry shard %s: %w", shard.ID, err)
+		}
+		result.Requeued += r.Requeued
+		result.Exhausted += r.Exhausted
+	}
+
+	if result.Requeued > 0 && job.Status.IsTerminal() {
+		job.Status = domain.JobStatusRunning
+		job.CompletedAt = nil
+		job.ErrorMessage = nil
+		job.ErrorCode = ""
+
+		retried := &domain.JobEvent{JobID: job.ID, Type: domain.JobEventRetried, Status: job.Status,
+			Message: fmt.Sprintf("%d failed searches of its shards requeued", result.Requeued)}
+		if err := s.update(ctx, job, retried); err != nil {
+			return nil, fmt.Errorf("failed to reopen job: %w", err)
+		}
+
+		s.publishStatus(ctx, job.ID, domain.JobStatusRunning, "")
+		result.Status = domain.JobStatusRunning
+	}
+
+	return result, nil
+}
+
+// syncShards sets the progress of a sharded job to the sum of that of its
+// shards
+func (s *JobService) syncShards(ctx context.Context, id uuid.UUID) error {
+	shards, err := s.shards(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	return s.UpdateProgress(ctx, id, domain.ShardsProgress(shards))
+}
+
+// resolveShards brings a running sharded job up to date with its shards:
+// it takes the sum of their progress and, once they all finished, ends
+// as domain.ShardsOutcome tells. It returns whether the job ended.
+func (s *JobService) resolveShards(ctx context.Context, logger *slog.Logger, job *domain.Job) (bool, error) {
+	shards, err := s.shards(ctx, job.ID, false)
+	if err != nil || len(shards) == 0 {
+		return false, err
+	}
+
+	if progress := domain.ShardsProgress(shards); progress != job.Progress {
+		if err := s.UpdateProgress(ctx, job.ID, progress); err != nil {
+			return false, fmt.Errorf("failed to update progress: %w", err)
+		}
+	}
+
+	status, failed := domain.ShardsOutcome(shards)
+	switch status {
+	case domain.JobStatusCompleted:
+		err = s.Complete(ctx, job.ID)
+	case domain.JobStatusFailed:
+		msg := fmt.Sprintf("shard %s failed", failed.Name)
+		if failed.ErrorMessage != nil {
+			msg += ": " + *failed.ErrorMessage
+		}
+		err = s.Fail(ctx, job.ID, failed.ErrorCode, msg)
+	case domain.JobStatusCancelled:
+		if err = s.setStatus(ctx, job.ID, status, domain.JobEventCancelled); err == nil {
+			s.publishStatus(ctx, job.ID, status, "")
+		}
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to end sharded job: %w", err)
+	}
+
+	logger.Info("shards finished, job ended", "job_id", job.ID, "status", status)
+	return true, nil
+}
+
+// ResolveShards brings every running sharded job up to date with its
+// shards and returns the jobs that ended
+func (s *JobService) ResolveShards(ctx context.Context) ([]uuid.UUID, error) {
+	logger := logging.Logger(ctx, "JobService")
+	running := domain.JobStatusRunning
+
+	var ended []uuid.UUID
+	for offset := 0; ; {
+		jobs, _, err := s.jobs.List(ctx, domain.JobListParams{
+			Status:   &running,
+			Sharded:  true,
+			Limit:    shardSweepBatch,
+			Offset:   offset,
+			OrderBy:  "created_at",
+			OrderDir: "ASC",
+		})
+		if err != nil {
+			return ended, fmt.Errorf("failed to list sharded jobs: %w", err)
+		}
+
+		for _, job := range jobs {
+			done, err := s.resolveShards(ctx, logger, job)
+			if err != nil {
+				logger.Warn("failed to resolve shards", "job_id", job.ID, "error", err)
+			}
+			if !done {
+				offset++
+				continue
+			}
+			ended = append(ended, job.ID)
+		}
+
+		if len(jobs) < shardSweepBatch {
+			return ended, nil
+		}
+	}
+}
+
+// RunShards resolves sharded jobs every shardSweepInterval until ctx is
+// done. onChange is called with the jobs that ended.
+func (s *JobService) RunShards(ctx context.Context, onChange func(ids []uuid.UUID)) error {
+	logger := logging.Logger(ctx, "JobService")
+	logger.Info("job shards started", "interval", shardSweepInterval.String())
+
+	ticker := time.NewTicker(shardSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		ids, err := s.ResolveShards(ctx)
+		if err != nil {
+			logger.Warn("job shards failed", "error", err)
+		}
+		if len(ids) > 0 && onChange != nil {
+			onChange(ids)
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info("job shards stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// Job template errors
+var (
+	ErrTemplateNotFound     = errors.New("template not found")
+	ErrTemplateNameRequired = errors.New("template name is required")
+)
+
+// JobTemplateService handles job template business logic
+type JobTemplateService struct {
+	templates domain.JobTemplateRepository
+}
+
+// NewJobTemplateService creates a new JobTemplateService
+func NewJobTemplateService(templates domain.JobTemplateRepository) *JobTemplateService {
+	return &JobTemplateService{
+		templates: templates,
+	}
+}
+
+// Create creates a new template
+func (s *JobTemplateService) Create(ctx context.Context, req *domain.JobTemplateRequest) (*domain.JobTemplate, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrTemplateNameRequired
+	}
+
+	tmpl := &domain.JobTemplate{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: req.Description,
+		Config:      req.Config,
+	}
+
+	if err := s.templates.Create(ctx, tmpl); err != nil {
+		return nil, fmt.Errorf("failed to create template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// GetByID retrieves a template by ID
+func (s *JobTemplateService) GetByID(ctx context.Context, id uuid.UUID) (*domain.JobTemplate, error) {
+	tmpl, err := s.templates.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	if tmpl == nil {
+		return nil, ErrTemplateNotFound
+	}
+	return tmpl, nil
+}
+
+// List retrieves all templates with their usage counts
+func (s *JobTemplateService) List(ctx context.Context) ([]*domain.JobTemplate, error) {
+	return s.templates.List(ctx)
+}
+
+// Update replaces a template's name, description and config.
+// Jobs already created from the template keep their copied config.
+func (s *JobTemplateService) Update(ctx context.Context, id uuid.UUID, req *domain.JobTemplateRequest) (*domain.JobTemplate, error) {
+	tmpl, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrTemplateNameRequired
+	}
+
+	tmpl.Name = name
+	tmpl.Description = req.Description
+	tmpl.Config = req.Config
+
+	if err := s.templates.Update(ctx, tmpl); err != nil {
+		return nil, fmt.Errorf("failed to update template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// Delete deletes a template
+func (s *JobTemplateService) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return s.templates.Delete(ctx, id)
+}
+
+// RecordUsage counts a job created from the template
+func (s *JobTemplateService) RecordUsage(ctx context.Context, id uuid.UUID) error {
+	return s.templates.IncrementUsage(ctx, id)
+}
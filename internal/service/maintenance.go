@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// ErrMaintenanceRunning is returned when a maintenance run is already
+// running, on this manager or another one sharing the database
+var ErrMaintenanceRunning = errors.New("maintenance already running")
+
+// MaintenanceService runs ANALYZE, VACUUM and REINDEX passes over the
+// database, one at a time, and records their progress. Runs started from
+// the API and from the schedule take the same path.
+type MaintenanceService struct {
+	repo domain.MaintenanceRepository
+
+	mu      sync.Mutex
+	running bool
+}
+
+// NewMaintenanceService creates a new MaintenanceService
+func NewMaintenanceService(repo domain.MaintenanceRepository) *MaintenanceService {
+	return &MaintenanceService{repo: repo}
+}
+
+// ParseMaintenanceSchedule parses a -maintenance-schedule, a standard
+// five-field cron expression such as "0 3 * * *" or a descriptor such as
+// "@daily", in the manager's local time
+func ParseMaintenanceSchedule(spec string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance schedule %q: %w", spec, err)
+	}
+	return schedule, nil
+}
+
+// Start begins a run with opts in the background and returns it
+func (s *MaintenanceService) Start(ctx context.Context, trigger string, opts domain.MaintenanceOptions) (*domain.MaintenanceRun, error) {
+	run, unlock, err := s.begin(ctx, trigger, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// The run outlives the request
+	started := *run
+	started.Steps = copySteps(run.Steps)
+	go s.process(context.WithoutCancel(ctx), run, unlock)
+
+	return &started, nil
+}
+
+// Run carries out a run with opts and returns it once it ended
+func (s *MaintenanceService) Run(ctx context.Context, trigger string, opts domain.MaintenanceOptions) (*domain.MaintenanceRun, error) {
+	run, unlock, err := s.begin(ctx, trigger, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return run, s.process(ctx, run, unlock)
+}
+
+// Latest returns the latest run, or nil
+func (s *MaintenanceService) Latest(ctx context.Context) (*domain.MaintenanceRun, error) {
+	return s.repo.LatestRun(ctx)
+}
+
+// RunSchedule starts a run with opts at every time of schedule until ctx
+// is done. A time that finds a run still going is skipped.
+func (s *MaintenanceService) RunSchedule(ctx context.Context, schedule cron.Schedule, opts domain.MaintenanceOptions) error {
+	logger := logging.Logger(ctx, "MaintenanceService")
+
+	for {
+		next := schedule.Next(time.Now())
+		logger.Info("next scheduled maintenance", "at", next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		run, err := s.Run(ctx, domain.MaintenanceTriggerSchedule, opts)
+		switch {
+		case errors.Is(err, ErrMaintenanceRunning):
+			logger.Info("scheduled maintenance skipped, a run is still going")
+		case err != nil && ctx.Err() != nil:
+			return nil
+		case err != nil:
+			// Recorded with the run; the next time tries again
+			logger.Warn("scheduled maintenance failed", "error", err)
+		default:
+			logger.Info("scheduled maintenance completed", "run_id", run.ID, "steps", run.Done)
+		}
+	}
+}
+
+// begin takes the guards, plans the steps and records the run
+func (s *MaintenanceService) begin(ctx context.Context, trigger string, opts domain.MaintenanceOptions) (*domain.MaintenanceRun, func(), error) {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, nil, ErrMaintenanceRunning
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	unlock, ok, err := s.repo.Lock(ctx)
+	if err != nil || !ok {
+		s.release(nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrMaintenanceRunning
+	}
+
+	run, err := s.create(ctx, trigger, opts)
+	if err != nil {
+		s.release(unlock)
+		return nil, nil, err
+	}
+
+	return run, unlock, nil
+}
+
+func (s *MaintenanceService) create(ctx context.Context, trigger string, opts domain.MaintenanceOptions) (*domain.MaintenanceRun, error) {
+	steps, err := s.repo.Plan(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &domain.MaintenanceRun{
+		Trigger:   trigger,
+		Options:   opts,
+		Status:    domain.MaintenanceRunning,
+		Steps:     steps,
+		StartedAt: time.Now().UTC(),
+	}
+	if err := s.repo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+func (s *MaintenanceService) release(unlock func()) {
+	if unlock != nil {
+		unlock()
+	}
+
+	s.mu.Lock()
+	s.running = false
+	s.mu.Unlock()
+}
+
+// process runs the steps of run in order, storing the run after each, and
+// stops at the first that fails
+func (s *MaintenanceService) process(ctx context.Context, run *domain.MaintenanceRun, unlock func()) error {
+	defer s.release(unlock)
+
+	logger := logging.Logger(ctx, "MaintenanceService").With("run_id", run.ID, "trigger", run.Trigger)
+	logger.Info("maintenance started", "steps", len(run.Steps))
+
+	var runErr error
+	for _, step := range run.Steps {
+		now := time.Now().UTC()
+		step.Status, step.StartedAt = domain.MaintenanceRunning, &now
+		s.update(ctx, logger, run)
+
+		err := s.repo.Exec(ctx, step)
+
+		finished := time.Now().UTC()
+		step.FinishedAt = &finished
+		if err != nil {
+			step.Status, step.Error = domain.MaintenanceFailed, err.Error()
+			runErr = fmt.Errorf("%s: %w", step.Statement, err)
+			break
+		}
+		step.Status = domain.MaintenanceCompleted
+		run.Done++
+
+		logger.Info("maintenance step completed", "statement", step.Statement, "duration", finished.Sub(now).String())
+	}
+
+	finished := time.Now().UTC()
+	run.FinishedAt = &finished
+	run.Status = domain.MaintenanceCompleted
+	if runErr != nil {
+		run.Status, run.Error = domain.MaintenanceFailed, runErr.Error()
+	}
+
+	// Also recorded when ctx was cancelled
+	s.update(context.WithoutCancel(ctx), logger, run)
+
+	if runErr != nil {
+		logger.Error("maintenance failed", "error", runErr, "done", run.Done)
+		return runErr
+	}
+
+	logger.Info("maintenance completed", "done", run.Done)
+
+	return nil
+}
+
+// update stores the progress of run. The steps carry on when it cannot be
+// stored; the record catches up with the next update.
+func (s *MaintenanceService) update(ctx context.Context, logger *slog.Logger, run *domain.MaintenanceRun) {
+	if err := s.repo.UpdateRun(ctx, run); err != nil {
+		logger.Warn("failed to store maintenance progress", "error", err)
+	}
+}
+
+func copySteps(steps []*domain.MaintenanceStep) []*domain.MaintenanceStep {
+	out := make([]*domain.MaintenanceStep, len(steps))
+	for i, step := range steps {
+		c := *step
+		out[i] = &c
+	}
+	return out
+}
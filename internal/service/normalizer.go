@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+const (
+	normalizeBatchSize   = 500
+	normalizeMaxAttempts = 5
+	normalizeRetryAfter  = 30 * time.Second // Doubled on each attempt
+	normalizeIdle        = time.Second
+)
+
+// NormalizerService turns results ingested with COPY into listings and
+// reviews behind the ingest path, so a batch upload no longer waits on
+// the listing triggers and the per-job normalization
+type NormalizerService struct {
+	queue domain.NormalizeQueueRepository
+}
+
+// NewNormalizerService creates a new NormalizerService
+func NewNormalizerService(queue domain.NormalizeQueueRepository) *NormalizerService {
+	return &NormalizerService{queue: queue}
+}
+
+// Run normalizes queued results until ctx is done, going straight on to
+// the next batch while the queue is backed up
+func (s *NormalizerService) Run(ctx context.Context) error {
+	logger := logging.Logger(ctx, "Normalizer")
+	logger.Info("normalizer started", "batch_size", normalizeBatchSize, "max_attempts", normalizeMaxAttempts)
+
+	for {
+		batch, err := s.queue.Normalize(ctx, normalizeBatchSize, normalizeMaxAttempts, normalizeRetryAfter)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			logger.Warn("failed to normalize results", "error", err)
+		case err == nil && batch.Retrying+batch.GivenUp > 0:
+			logger.Warn("results failed to normalize", "retrying", batch.Retrying, "given_up", batch.GivenUp, "last_error", batch.LastError)
+		}
+
+		if err == nil && batch.Claimed >= normalizeBatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info("normalizer stopped")
+			return nil
+		case <-time.After(normalizeIdle):
+		}
+	}
+}
+
+// Lag reports how far normalization is behind ingestion
+func (s *NormalizerService) Lag(ctx context.Context) (*domain.NormalizationLag, error) {
+	return s.queue.Lag(ctx)
+}
+
+// WaitForJob waits up to timeout for the results of a job to be
+// normalized, reporting how many are still pending when it gives up
+func (s *NormalizerService) WaitForJob(ctx context.Context, jobID uuid.UUID, timeout time.Duration) (int, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		pending, err := s.queue.PendingByJobID(ctx, jobID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count pending results: %w", err)
+		}
+		if pending == 0 || !time.Now().Before(deadline) {
+			return pending, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return pending, ctx.Err()
+		case <-time.After(normalizeIdle):
+		}
+	}
+}
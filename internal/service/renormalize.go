@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/gmaps"
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// ErrRenormalizeRunning is returned when the results of a job, or of every
+// job, are already being re-normalized by this process
+var ErrRenormalizeRunning = errors.New("re-normalization already running")
+
+// RenormalizeService parses stored results again with the current
+// gmaps.Entry and rewrites their business listings and emails. Results
+// stored before a field was added to the schema only get it this way.
+type RenormalizeService struct {
+	repo      domain.RenormalizeRepository
+	jobs      domain.JobRepository
+	batchSize int
+
+	mu     sync.Mutex
+	active map[string]bool // Scopes being worked on, see renormalizeScope
+}
+
+// NewRenormalizeService creates a new RenormalizeService
+func NewRenormalizeService(repo domain.RenormalizeRepository, jobs domain.JobRepository) *RenormalizeService {
+	return &RenormalizeService{
+		repo:      repo,
+		jobs:      jobs,
+		batchSize: domain.DefaultRenormalizeBatchSize,
+		active:    make(map[string]bool),
+	}
+}
+
+// Start begins or resumes the run for jobID, nil for every job, in the
+// background and returns it
+func (s *RenormalizeService) Start(ctx context.Context, jobID *uuid.UUID) (*domain.RenormalizeRun, error) {
+	run, err := s.begin(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The run outlives the request; an interrupted run is resumed by the
+	// next start
+	started := *run
+	go s.process(context.WithoutCancel(ctx), run, nil)
+
+	return &started, nil
+}
+
+// Run begins or resumes the run for jobID and works on it until it ends,
+// calling progress after every batch
+func (s *RenormalizeService) Run(ctx context.Context, jobID *uuid.UUID, progress func(*domain.RenormalizeRun)) (*domain.RenormalizeRun, error) {
+	run, err := s.begin(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	return run, s.process(ctx, run, progress)
+}
+
+// Latest returns the latest run for jobID, or nil
+func (s *RenormalizeService) Latest(ctx context.Context, jobID *uuid.UUID) (*domain.RenormalizeRun, error) {
+	run, err := s.repo.LatestRun(ctx, jobID)
+	if err != nil || run == nil {
+		return run, err
+	}
+
+	s.mu.Lock()
+	run.Active = run.Status == domain.RenormalizeRunning && s.active[renormalizeScope(jobID)]
+	s.mu.Unlock()
+
+	return run, nil
+}
+
+func (s *RenormalizeService) begin(ctx context.Context, jobID *uuid.UUID) (*domain.RenormalizeRun, error) {
+	if jobID != nil {
+		job, err := s.jobs.GetByID(ctx, *jobID)
+		if err != nil {
+			return nil, err
+		}
+		if job == nil {
+			return nil, ErrJobNotFound
+		}
+	}
+
+	key := renormalizeScope(jobID)
+
+	s.mu.Lock()
+	if s.active[key] {
+		s.mu.Unlock()
+		return nil, ErrRenormalizeRunning
+	}
+	s.active[key] = true
+	s.mu.Unlock()
+
+	run, err := s.repo.StartRun(ctx, jobID)
+	if err != nil {
+		s.release(key)
+		return nil, err
+	}
+	run.Active = true
+
+	return run, nil
+}
+
+func (s *RenormalizeService) release(key string) {
+	s.mu.Lock()
+	delete(s.active, key)
+	s.mu.Unlock()
+}
+
+// process works through the results of run in batches, each committed with
+// the run's progress so a stopped run loses at most one batch
+func (s *RenormalizeService) process(ctx context.Context, run *domain.RenormalizeRun, progress func(*domain.RenormalizeRun)) error {
+	defer s.release(renormalizeScope(run.JobID))
+
+	logger := logging.Logger(ctx, "RenormalizeService").With("run_id", run.ID)
+	if run.JobID != nil {
+		logger = logger.With("job_id", *run.JobID)
+	}
+	logger.Info("re-normalization started", "last_result_id", run.LastResultID, "max_result_id", run.MaxResultID, "total", run.Total)
+
+	for {
+		results, err := s.repo.NextResults(ctx, run, s.batchSize)
+		if err != nil {
+			return s.fail(ctx, logger, run, err)
+		}
+		if len(results) == 0 {
+			break
+		}
+
+		var (
+			batch    []domain.RenormalizedResult
+			failures []domain.RenormalizeFailure
+		)
+		for _, res := range results {
+			data, err := renormalizeResult(res.Data)
+			if err != nil {
+				failures = append(failures, domain.RenormalizeFailure{ResultID: res.ID, JobID: res.JobID, Error: err.Error()})
+				continue
+			}
+			batch = append(batch, domain.RenormalizedResult{ResultID: res.ID, JobID: res.JobID, Data: data})
+		}
+
+		if err := s.repo.SaveBatch(ctx, run, batch, failures, results[len(results)-1].ID); err != nil {
+			return s.fail(ctx, logger, run, err)
+		}
+
+		logger.Info("re-normalization progress", "processed", run.Processed, "failed", run.Failed, "total", run.Total, "last_result_id", run.LastResultID)
+		if progress != nil {
+			progress(run)
+		}
+	}
+
+	if err := s.repo.FinishRun(context.WithoutCancel(ctx), run, domain.RenormalizeCompleted, ""); err != nil {
+		logger.Error("failed to finish re-normalization", "error", err)
+		return err
+	}
+	run.Active = false
+
+	logger.Info("re-normalization completed", "processed", run.Processed, "failed", run.Failed)
+
+	return nil
+}
+
+func (s *RenormalizeService) fail(ctx context.Context, logger *slog.Logger, run *domain.RenormalizeRun, err error) error {
+	logger.Error("re-normalization stopped", "error", err, "last_result_id", run.LastResultID)
+
+	// Also recorded when ctx was cancelled
+	if finishErr := s.repo.FinishRun(context.WithoutCancel(ctx), run, domain.RenormalizeFailed, err.Error()); finishErr != nil {
+		logger.Error("failed to record re-normalization failure", "error", finishErr)
+	}
+	run.Active = false
+
+	return err
+}
+
+// renormalizeResult parses a stored result with the current gmaps.Entry and
+// encodes it again, so fields of older results end up where the current
+// schema expects them
+func renormalizeResult(data []byte) ([]byte, error) {
+	var entry gmaps.Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+
+	if entry.Title == "" && entry.PlaceID == "" && entry.Cid == "" {
+		return nil, errors.New("not a place: no title, place_id or cid")
+	}
+
+	// The job language is not stored with the result, every language is tried
+	entry.ParseOpenHours("")
+	// Plus code and timezone of places stored before they were filled in
+	entry.FillLocation()
+
+	return json.Marshal(&entry)
+}
+
+// renormalizeScope identifies the results a run covers
+func renormalizeScope(jobID *uuid.UUID) string {
+	if jobID == nil {
+		return "all"
+	}
+	return jobID.String()
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/internal/report"
+)
+
+// Delays between the attempts to email a report; an attempt failing after
+// the last delay gives up
+var reportRetryDelays = []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute}
+
+// reportSendTimeout bounds one attempt to email a report
+const reportSendTimeout = 2 * time.Minute
+
+// reportNormalizeWait bounds how long an emailed report waits for the
+// job's last results to be normalized
+const reportNormalizeWait = 5 * time.Minute
+
+// ReportNormalizer tells when the results of a job are all normalized
+type ReportNormalizer interface {
+	WaitForJob(ctx context.Context, jobID uuid.UUID, timeout time.Duration) (int, error)
+}
+
+// ReportSender emails a report
+type ReportSender interface {
+	Send(ctx context.Context, to []string, r *report.Report) error
+}
+
+// ReportService renders the summary report of a job and emails it to the
+// job's notify_emails once the job completes
+type ReportService struct {
+	repo       domain.JobReportRepository
+	jobs       domain.JobRepository
+	sender     ReportSender     // nil when no SMTP server is configured
+	normalizer ReportNormalizer // Optional
+
+	retryDelays []time.Duration
+}
+
+// NewReportService creates a new ReportService. Without a sender reports
+// are only rendered on request.
+func NewReportService(repo domain.JobReportRepository, jobs domain.JobRepository, sender ReportSender) *ReportService {
+	return &ReportService{
+		repo:        repo,
+		jobs:        jobs,
+		sender:      sender,
+		retryDelays: reportRetryDelays,
+	}
+}
+
+// SetNormalizer makes emailed reports wait for the job's results to be
+// normalized, so they count every listing
+func (s *ReportService) SetNormalizer(normalizer ReportNormalizer) {
+	s.normalizer = normalizer
+}
+
+// Render renders the report of a job
+func (s *ReportService) Render(ctx context.Context, jobID uuid.UUID, mode report.MapMode) (*report.Report, error) {
+	job, err := s.jobs.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	if job == nil {
+		return nil, ErrJobNotFound
+	}
+
+	return s.render(ctx, job, mode)
+}
+
+func (s *ReportService) render(ctx context.Context, job *domain.Job, mode report.MapMode) (*report.Report, error) {
+	summary, err := s.repo.JobReport(ctx, job.ID, report.TopCategories, report.MaxMapPoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize job: %w", err)
+	}
+
+	return report.Render(job, summary, mode)
+}
+
+// JobCompleted emails the report of a completed job to its notify_emails in
+// the background. Failures are logged and retried, and never change the
+// job.
+func (s *ReportService) JobCompleted(ctx context.Context, jobID uuid.UUID) {
+	if s.sender == nil {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go s.notify(ctx, jobID)
+}
+
+func (s *ReportService) notify(ctx context.Context, jobID uuid.UUID) {
+	logger := logging.Logger(ctx, "ReportService")
+
+	job, err := s.jobs.GetByID(ctx, jobID)
+	if err != nil || job == nil {
+		logger.Warn("report not sent, job not loaded", "job_id", jobID, "error", err)
+		return
+	}
+	if len(job.Config.NotifyEmails) == 0 {
+		return
+	}
+
+	if s.normalizer != nil {
+		pending, err := s.normalizer.WaitForJob(ctx, jobID, reportNormalizeWait)
+		if err != nil {
+			logger.Warn("report sent without waiting for normalization", "job_id", jobID, "error", err)
+		} else if pending > 0 {
+			logger.Warn("report sent before normalization caught up", "job_id", jobID, "pending_results", pending)
+		}
+	}
+
+	r, err := s.render(ctx, job, report.MapAttached)
+	if err != nil {
+		logger.Error("report not sent, render failed", "job_id", jobID, "error", err)
+		return
+	}
+
+	for attempt := 0; ; attempt++ {
+		sendCtx, cancel := context.WithTimeout(ctx, reportSendTimeout)
+		err = s.sender.Send(sendCtx, job.Config.NotifyEmails, r)
+		cancel()
+		if err == nil {
+			logger.Info("report sent", "job_id", jobID, "recipients", len(job.Config.NotifyEmails), "attempts", attempt+1)
+			return
+		}
+
+		if attempt == len(s.retryDelays) {
+			logger.Error("report not sent, giving up", "job_id", jobID, "attempts", attempt+1, "error", err)
+			return
+		}
+
+		logger.Warn("report send failed, retrying", "job_id", jobID, "attempt", attempt+1, "retry_in", s.retryDelays[attempt], "error", err)
+		time.Sleep(s.retryDelays[attempt])
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -26,8 +27,8 @@ func (s *ResultService) Create(ctx context.Context, jobID uuid.UUID, data []byte
 }
 
 // CreateBatch creates multiple results
-func (s *ResultService) CreateBatch(ctx context.Context, jobID uuid.UUID, data [][]byte) error {
-	return s.results.CreateBatch(ctx, jobID, data)
+func (s *ResultService) CreateBatch(ctx context.Context, jobID, batchID uuid.UUID, data [][]byte) error {
+	return s.results.CreateBatch(ctx, jobID, batchID, data)
 }
 
 // ListAll retrieves all results with pagination (global view)
@@ -45,6 +46,11 @@ func (s *ResultService) CountByJobID(ctx context.Context, jobID uuid.UUID) (int,
 	return s.results.CountByJobID(ctx, jobID)
 }
 
+// LastCreatedAt returns when the latest result of a job was stored
+func (s *ResultService) LastCreatedAt(ctx context.Context, jobID uuid.UUID) (*time.Time, error) {
+	return s.results.LastCreatedAt(ctx, jobID)
+}
+
 // StreamByJobID streams results for a job
 func (s *ResultService) StreamByJobID(ctx context.Context, jobID uuid.UUID, fn func(data []byte) error) error {
 	return s.results.StreamByJobID(ctx, jobID, fn)
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// reviewColumns is the CSV header for review exports
+var reviewColumns = []string{
+	"place_id", "business_title", "reviewer_name", "rating", "text",
+	"relative_date", "owner_reply", "images", "first_seen_at", "last_seen_at",
+}
+
+// ReviewService provides access to normalized reviews
+type ReviewService struct {
+	repo domain.ReviewRepository
+}
+
+// NewReviewService creates a new ReviewService
+func NewReviewService(repo domain.ReviewRepository) *ReviewService {
+	return &ReviewService{repo: repo}
+}
+
+// ListByJobID retrieves reviews for a job with pagination
+func (s *ReviewService) ListByJobID(ctx context.Context, jobID string, limit, offset int) ([]*domain.BusinessReview, int, error) {
+	return s.repo.ListByJobID(ctx, jobID, limit, offset)
+}
+
+// ExportCSVByJobID writes every review of a job as CSV
+func (s *ReviewService) ExportCSVByJobID(ctx context.Context, w io.Writer, jobID string) error {
+	csvWriter := csv.NewWriter(w)
+	defer csvWriter.Flush()
+
+	if err := csvWriter.Write(reviewColumns); err != nil {
+		return fmt.Errorf("write csv header: %w", err)
+	}
+
+	return s.repo.StreamByJobID(ctx, jobID, func(review *domain.BusinessReview) error {
+		return csvWriter.Write(reviewToRow(review))
+	})
+}
+
+// ExportNDJSONByJobID writes every review of a job as newline-delimited JSON
+func (s *ReviewService) ExportNDJSONByJobID(ctx context.Context, w io.Writer, jobID string) error {
+	enc := json.NewEncoder(w)
+
+	return s.repo.StreamByJobID(ctx, jobID, func(review *domain.BusinessReview) error {
+		return enc.Encode(review)
+	})
+}
+
+func reviewToRow(review *domain.BusinessReview) []string {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+
+	rating := ""
+	if review.Rating != nil {
+		rating = strconv.Itoa(*review.Rating)
+	}
+
+	return []string{
+		review.PlaceID,
+		review.BusinessTitle,
+		review.ReviewerName,
+		rating,
+		review.Text,
+		deref(review.RelativeDate),
+		deref(review.OwnerReply),
+		strings.Join(review.Images, ", "),
+		review.FirstSeenAt.Format("2006-01-02 15:04:05"),
+		review.LastSeenAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+const (
+	// seedTaskSyncInterval is how often parent jobs are updated from their
+	// seed tasks
+	seedTaskSyncInterval = 30 * time.Second
+
+	// seedTaskTimeout fails seed tasks whose worker never reported back
+	seedTaskTimeout = time.Hour
+
+	// seedTaskGrace leaves place jobs of the last finished searches time to
+	// store their results before the parent job is completed
+	seedTaskGrace = 2 * time.Minute
+)
+
+// SeedTaskService exposes the seed tasks of jobs bridged to DSN workers
+// and keeps the parent jobs' progress and status in line with them
+type SeedTaskService struct {
+	tasks domain.SeedTaskRepository
+	jobs  *JobService
+}
+
+// NewSeedTaskService creates a new SeedTaskService
+func NewSeedTaskService(tasks domain.SeedTaskRepository, jobs *JobService) *SeedTaskService {
+	return &SeedTaskService{
+		tasks: tasks,
+		jobs:  jobs,
+	}
+}
+
+// List returns the task counts of a job together with a page of its tasks
+func (s *SeedTaskService) List(ctx context.Context, jobID uuid.UUID, params domain.SeedTaskListParams) (*domain.SeedTaskCounts, []*domain.SeedTask, int, error) {
+	if _, err := s.jobs.GetByID(ctx, jobID); err != nil {
+		return nil, nil, 0, err
+	}
+
+	counts, err := s.tasks.CountsByParent(ctx, jobID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	tasks, total, err := s.tasks.ListByParent(ctx, jobID, params)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return counts, tasks, total, nil
+}
+
+// Sync updates every unfinished parent job from its seed tasks. Scraped
+// places are the results stored for the job and failed places the failed
+// searches. A job runs once a worker claims its first task and completes
+// when all tasks have finished, or fails when none of them succeeded.
+func (s *SeedTaskService) Sync(ctx context.Context) error {
+	stale, err := s.tasks.FailStale(ctx, seedTaskTimeout)
+	if err != nil {
+		return err
+	}
+	if stale > 0 {
+		logging.Logger(ctx, "SeedTaskService").Warn("marked seed tasks without a result as failed",
+			"tasks", stale, "timeout", seedTaskTimeout.String())
+	}
+
+	active, err := s.tasks.ActiveProgress(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range active {
+		if err := s.syncJob(ctx, p); err != nil {
+			logging.Logger(ctx, "SeedTaskService").Warn("failed to sync job", "job_id", p.JobID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SeedTaskService) syncJob(ctx context.Context, p *domain.SeedTaskProgress) error {
+	progress := p.Progress
+	progress.ScrapedPlaces = p.Places
+	progress.PartialPlaces = p.PartialPlaces
+	progress.FailedPlaces = p.Counts.Failed
+
+	if progress.ScrapedPlaces != p.Progress.ScrapedPlaces || progress.FailedPlaces != p.Progress.FailedPlaces ||
+		progress.PartialPlaces != p.Progress.PartialPlaces {
+		if err := s.jobs.UpdateProgress(ctx, p.JobID, progress); err != nil {
+			return fmt.Errorf("update progress: %w", err)
+		}
+	}
+
+	pending := p.Counts.New + p.Counts.Queued
+
+	if pending > 0 {
+		if p.Status != domain.JobStatusRunning && p.Counts.New < p.Counts.Total {
+			if err := s.jobs.setStatus(ctx, p.JobID, domain.JobStatusRunning, domain.JobEventStarted); err != nil {
+				return fmt.Errorf("mark running: %w", err)
+			}
+			s.jobs.publishStatus(ctx, p.JobID, domain.JobStatusRunning, "")
+		}
+		return nil
+	}
+
+	if p.LastActivity != nil && time.Since(*p.LastActivity) < seedTaskGrace {
+		return nil
+	}
+
+	if p.Counts.OK == 0 {
+		logging.Logger(ctx, "SeedTaskService").Warn("all seed tasks failed", "job_id", p.JobID, "tasks", p.Counts.Total)
+		return s.jobs.Fail(ctx, p.JobID, domain.JobErrorInternal, fmt.Sprintf("all %d seed tasks failed", p.Counts.Total))
+	}
+
+	if err := s.reportLangFallback(ctx, p.JobID); err != nil {
+		logging.Logger(ctx, "SeedTaskService").Warn("failed to report language fallback", "job_id", p.JobID, "error", err)
+	}
+
+	logging.Logger(ctx, "SeedTaskService").Info("job completed",
+		"job_id", p.JobID, "succeeded", p.Counts.OK, "tasks", p.Counts.Total, "places", p.Places)
+	return s.jobs.Complete(ctx, p.JobID)
+}
+
+// reportLangFallback stores how the successful seed tasks of a job with a
+// lang_fallback list fell back, as workers do in the report of their run
+func (s *SeedTaskService) reportLangFallback(ctx context.Context, jobID uuid.UUID) error {
+	job, err := s.jobs.GetByID(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if len(job.Config.LangFallback) == 0 {
+		return nil
+	}
+
+	report := &domain.LangFallbackReport{}
+	params := domain.SeedTaskListParams{Status: domain.SeedTaskStatusOK, Limit: 1000}
+	for {
+		tasks, total, err := s.tasks.ListByParent(ctx, jobID, params)
+		if err != nil {
+			return err
+		}
+
+		for _, task := range tasks {
+			lang := task.Lang
+			if lang == "" {
+				lang = job.Config.Lang
+			}
+			placesFound := 0
+			if task.PlacesFound != nil {
+				placesFound = *task.PlacesFound
+			}
+			report.Add(task.Keyword, job.Config.Lang, lang, placesFound)
+		}
+
+		params.Offset += len(tasks)
+		if len(tasks) == 0 || params.Offset >= total {
+			break
+		}
+	}
+
+	return s.jobs.jobs.SetParseReport(ctx, jobID, &domain.JobParseReport{LangFallback: report})
+}
+
+// Run syncs parent jobs periodically until ctx is done
+func (s *SeedTaskService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(seedTaskSyncInterval)
+	defer ticker.Stop()
+
+	logger := logging.Logger(ctx, "SeedTaskService")
+	logger.Info("seed task sync started", "interval", seedTaskSyncInterval.String())
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("seed task sync stopped")
+			return nil
+		case <-ticker.C:
+			if err := s.Sync(ctx); err != nil {
+				logger.Warn("seed task sync failed", "error", err)
+			}
+		}
+	}
+}
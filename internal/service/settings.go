@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// SettingsService handles the server-side settings edited from the
+// dashboard
+type SettingsService struct {
+	settings domain.SettingsRepository
+}
+
+// NewSettingsService creates a new SettingsService
+func NewSettingsService(settings domain.SettingsRepository) *SettingsService {
+	return &SettingsService{settings: settings}
+}
+
+// Get returns every setting
+func (s *SettingsService) Get(ctx context.Context) (*domain.Settings, error) {
+	defaults, err := s.JobDefaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prior, err := s.RatingPrior(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Settings{JobDefaults: defaults, RatingPrior: prior}, nil
+}
+
+// JobDefaults returns the job defaults, empty while none were saved
+func (s *SettingsService) JobDefaults(ctx context.Context) (*domain.JobDefaults, error) {
+	setting, err := s.settings.Get(ctx, domain.SettingJobDefaults)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := &domain.JobDefaults{}
+	if setting == nil {
+		return defaults, nil
+	}
+
+	if err := json.Unmarshal(setting.Value, &defaults.Config); err != nil {
+		return nil, fmt.Errorf("failed to decode job defaults: %w", err)
+	}
+	defaults.UpdatedBy = setting.UpdatedBy
+	defaults.UpdatedAt = &setting.UpdatedAt
+
+	return defaults, nil
+}
+
+// SetJobDefaults replaces the job defaults, recording the caller as the
+// actor of the change. cfg is expected to be validated like a create
+// request.
+func (s *SettingsService) SetJobDefaults(ctx context.Context, cfg domain.JobTemplateConfig) (*domain.JobDefaults, error) {
+	value, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job defaults: %w", err)
+	}
+
+	setting := &domain.Setting{
+		Key:       domain.SettingJobDefaults,
+		Value:     value,
+		UpdatedBy: domain.ActorFromContext(ctx),
+	}
+	if err := s.settings.Put(ctx, setting); err != nil {
+		return nil, err
+	}
+
+	logging.Logger(ctx, "SettingsService").Info("job defaults updated", "actor", setting.UpdatedBy)
+
+	return &domain.JobDefaults{Config: cfg, UpdatedBy: setting.UpdatedBy, UpdatedAt: &setting.UpdatedAt}, nil
+}
+
+// RatingPrior returns the prior of bayesian ratings, the default while
+// none was saved
+func (s *SettingsService) RatingPrior(ctx context.Context) (*domain.RatingPrior, error) {
+	setting, err := s.settings.Get(ctx, domain.SettingRatingPrior)
+	if err != nil {
+		return nil, err
+	}
+
+	prior := domain.DefaultRatingPrior
+	if setting == nil {
+		return &prior, nil
+	}
+
+	if err := json.Unmarshal(setting.Value, &prior); err != nil {
+		return nil, fmt.Errorf("failed to decode rating prior: %w", err)
+	}
+	prior.UpdatedBy = setting.UpdatedBy
+	prior.UpdatedAt = &setting.UpdatedAt
+
+	return &prior, nil
+}
+
+// SetRatingPrior replaces the prior of bayesian ratings, recording the
+// caller as the actor of the change. Listings keep the bayesian rating
+// they were ingested with until they are renormalized.
+func (s *SettingsService) SetRatingPrior(ctx context.Context, mean float64, weight int) (*domain.RatingPrior, error) {
+	prior := domain.RatingPrior{Mean: mean, Weight: weight}
+	value, err := json.Marshal(prior)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rating prior: %w", err)
+	}
+
+	setting := &domain.Setting{
+		Key:       domain.SettingRatingPrior,
+		Value:     value,
+		UpdatedBy: domain.ActorFromContext(ctx),
+	}
+	if err := s.settings.Put(ctx, setting); err != nil {
+		return nil, err
+	}
+
+	logging.Logger(ctx, "SettingsService").Info("rating prior updated", "mean", mean, "weight", weight, "actor", setting.UpdatedBy)
+
+	prior.UpdatedBy = setting.UpdatedBy
+	prior.UpdatedAt = &setting.UpdatedAt
+	return &prior, nil
+}
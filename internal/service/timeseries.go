@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// ErrInvalidTimeSeries is returned for a time series query that cannot be
+// answered
+var ErrInvalidTimeSeries = errors.New("invalid time series query")
+
+// TimeSeriesService counts the dashboard metrics over time
+type TimeSeriesService struct {
+	repo domain.TimeSeriesRepository
+	jobs domain.JobRepository
+}
+
+// NewTimeSeriesService creates a new TimeSeriesService
+func NewTimeSeriesService(repo domain.TimeSeriesRepository, jobs domain.JobRepository) *TimeSeriesService {
+	return &TimeSeriesService{repo: repo, jobs: jobs}
+}
+
+// TimeSeries counts q.Metric per interval. Every series has a point for
+// every interval of the range; series grouped by job are labelled with the
+// job name.
+func (s *TimeSeriesService) TimeSeries(ctx context.Context, q domain.TimeSeriesQuery) (*domain.TimeSeries, error) {
+	if err := q.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTimeSeries, err)
+	}
+	if !slices.Contains(s.repo.Intervals(), q.Interval) {
+		return nil, fmt.Errorf("%w: interval must be one of %v with this database", ErrInvalidTimeSeries, s.repo.Intervals())
+	}
+
+	rows, err := s.repo.TimeSeries(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := q.Buckets()
+	index := make(map[time.Time]int, len(buckets))
+	for i, b := range buckets {
+		index[b] = i
+	}
+
+	newSeries := func(key string) *domain.Series {
+		points := make([]domain.TimeSeriesPoint, len(buckets))
+		for i, b := range buckets {
+			points[i].Time = b
+		}
+		return &domain.Series{Key: key, Points: points}
+	}
+
+	ts := &domain.TimeSeries{
+		Metric:   q.Metric,
+		Interval: q.Interval,
+		From:     q.From,
+		To:       q.To,
+		GroupBy:  q.GroupBy,
+		Series:   []*domain.Series{},
+	}
+
+	byKey := make(map[string]*domain.Series)
+	if q.GroupBy == "" {
+		// A single series, even when nothing was counted
+		byKey[""] = newSeries("")
+		ts.Series = append(ts.Series, byKey[""])
+	}
+
+	for _, row := range rows {
+		i, ok := index[row.Bucket]
+		if !ok {
+			continue
+		}
+
+		var series *domain.Series
+		switch {
+		case row.Other:
+			if ts.Other == nil {
+				ts.Other = newSeries("")
+			}
+			series = ts.Other
+		default:
+			series = byKey[row.Group]
+			if series == nil {
+				series = newSeries(row.Group)
+				byKey[row.Group] = series
+				ts.Series = append(ts.Series, series)
+			}
+		}
+
+		series.Points[i].Value += row.Count
+		series.Total += row.Count
+	}
+
+	sort.SliceStable(ts.Series, func(i, j int) bool {
+		if ts.Series[i].Total != ts.Series[j].Total {
+			return ts.Series[i].Total > ts.Series[j].Total
+		}
+		return ts.Series[i].Key < ts.Series[j].Key
+	})
+
+	if q.GroupBy == domain.GroupByJob {
+		s.labelJobs(ctx, ts.Series)
+	}
+
+	return ts, nil
+}
+
+// labelJobs sets the job name of series grouped by job. Deleted jobs keep
+// their ID only.
+func (s *TimeSeriesService) labelJobs(ctx context.Context, series []*domain.Series) {
+	for _, ser := range series {
+		id, err := uuid.Parse(ser.Key)
+		if err != nil {
+			continue
+		}
+
+		job, err := s.jobs.GetByID(ctx, id)
+		if err != nil || job == nil {
+			continue
+		}
+
+		ser.Label = job.Name
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// ErrInvalidUsageRange is returned when a usage range ends before it starts
+var ErrInvalidUsageRange = errors.New("from must not be after to")
+
+// UsageService reports per-tenant usage
+type UsageService struct {
+	usage domain.UsageRepository
+}
+
+// NewUsageService creates a new UsageService
+func NewUsageService(usage domain.UsageRepository) *UsageService {
+	return &UsageService{
+		usage: usage,
+	}
+}
+
+// Summary returns per-tenant usage between filter.From and filter.To
+func (s *UsageService) Summary(ctx context.Context, filter domain.UsageFilter) ([]*domain.TenantUsage, error) {
+	if filter.From.After(filter.To) {
+		return nil, ErrInvalidUsageRange
+	}
+
+	usage, err := s.usage.Summary(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if usage == nil {
+		usage = []*domain.TenantUsage{}
+	}
+
+	return usage, nil
+}
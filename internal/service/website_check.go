@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/internal/websitecheck"
+)
+
+const (
+	// websiteFeedInterval is how often due websites are moved to the queue
+	websiteFeedInterval = 5 * time.Second
+
+	// websiteQueuePerWorker is how many websites are kept queued per worker
+	websiteQueuePerWorker = 10
+
+	// websiteClaimTimeout is when a queued website that was never checked,
+	// e.g. because its manager stopped, is queued again
+	websiteClaimTimeout = time.Hour
+)
+
+// WebsiteCheckService checks the websites of listings whose job asked for
+// it, apart from ingestion so it never holds up a job. Listings due for a
+// check are claimed from the database onto a queue that a pool of workers
+// drains. A domain checked within the TTL is not fetched again; its check
+// is copied to the listing.
+type WebsiteCheckService struct {
+	repo    domain.WebsiteCheckRepository
+	checker *websitecheck.Checker
+	queue   chan domain.WebsiteCheckTask
+	workers int
+	ttl     time.Duration
+
+	checked atomic.Int64
+	reused  atomic.Int64
+	failed  atomic.Int64
+}
+
+// NewWebsiteCheckService creates a service running workers checkers.
+// Checks of a domain are reused for ttl; 0 fetches every website.
+func NewWebsiteCheckService(repo domain.WebsiteCheckRepository, checker *websitecheck.Checker, workers int, ttl time.Duration) *WebsiteCheckService {
+	if workers <= 0 {
+		workers = domain.DefaultWebsiteCheckWorkers
+	}
+
+	return &WebsiteCheckService{
+		repo:    repo,
+		checker: checker,
+		queue:   make(chan domain.WebsiteCheckTask, workers*websiteQueuePerWorker),
+		workers: workers,
+		ttl:     ttl,
+	}
+}
+
+// Run feeds the queue and runs the workers until ctx is done
+func (s *WebsiteCheckService) Run(ctx context.Context) error {
+	logger := logging.Logger(ctx, "WebsiteCheck")
+	logger.Info("website checks started", "workers", s.workers, "ttl", s.ttl.String())
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx)
+		}()
+	}
+
+	s.feed(ctx)
+	wg.Wait()
+
+	logger.Info("website checks stopped",
+		"checked", s.checked.Load(), "reused", s.reused.Load(), "failed", s.failed.Load())
+	return nil
+}
+
+// feed claims due websites while the queue runs low. It is the only
+// sender, so the claims always fit.
+func (s *WebsiteCheckService) feed(ctx context.Context) {
+	ticker := time.NewTicker(websiteFeedInterval)
+	defer ticker.Stop()
+
+	logger := logging.Logger(ctx, "WebsiteCheck")
+
+	for {
+		if want := cap(s.queue) - len(s.queue); want > 0 {
+			tasks, err := s.repo.ClaimDue(ctx, want, websiteClaimTimeout)
+			if err != nil && ctx.Err() == nil {
+				logger.Warn("failed to queue websites", "error", err)
+			}
+			for _, t := range tasks {
+				s.queue <- t
+			}
+			if len(tasks) > 0 {
+				logger.Debug("queued websites", "websites", len(tasks))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *WebsiteCheckService) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-s.queue:
+			s.check(ctx, t)
+		}
+	}
+}
+
+func (s *WebsiteCheckService) check(ctx context.Context, t domain.WebsiteCheckTask) {
+	logger := logging.Logger(ctx, "WebsiteCheck").With("listing_id", t.ListingID, "website", t.Website)
+
+	var check *domain.WebsiteCheck
+	if name := websitecheck.Domain(t.Website); name != "" && s.ttl > 0 {
+		recent, err := s.repo.Recent(ctx, name, s.ttl)
+		if err != nil {
+			logger.Warn("failed to look up recent check", "error", err)
+		}
+		check = recent
+	}
+	reused := check != nil
+
+	if !reused {
+		start := time.Now()
+		var err error
+		check, err = s.checker.Check(ctx, t.Website)
+		if err != nil {
+			// Stopping; the claim expires and another manager picks it up
+			return
+		}
+		logger.Debug("website checked", "status", check.Status, "http_status", check.HTTPStatus, "duration_ms", logging.SinceMS(start))
+	}
+
+	if err := s.repo.Store(ctx, t.ListingID, check, reused); err != nil {
+		s.failed.Add(1)
+		logger.Error("failed to store website check", "error", err)
+		return
+	}
+
+	s.checked.Add(1)
+	if reused {
+		s.reused.Add(1)
+	}
+}
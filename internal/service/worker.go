@@ -2,19 +2,37 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/events"
+	"github.com/sadewadee/google-scraper/internal/logging"
 )
 
+// ErrWorkerNotFound is returned when a worker is not registered
+var ErrWorkerNotFound = errors.New("worker not found")
+
+// jobTimeoutInterval is how often running jobs are checked against their
+// timeout deadline
+const jobTimeoutInterval = time.Minute
+
 // WorkerService handles worker business logic
 type WorkerService struct {
-	workers domain.WorkerRepository
-	jobs    domain.JobRepository
+	workers   domain.WorkerRepository
+	jobs      domain.JobRepository
+	events    events.Publisher          // Live job status stream (optional)
+	fleet     events.WorkerPublisher    // Live worker stream (optional)
+	claimable events.ClaimablePublisher // Wakes workers long-polling for a job (optional)
+	reports   *ReportService            // Report emails of completed jobs (optional)
+	audit     *jobAudit                 // Audit trail of job transitions (optional)
+
+	lateResultsWindow time.Duration // How long failed jobs still take outbox completions (0 = not at all)
 }
 
 // NewWorkerService creates a new WorkerService
@@ -25,6 +43,48 @@ func NewWorkerService(workers domain.WorkerRepository, jobs domain.JobRepository
 	}
 }
 
+// SetEvents publishes job status changes made by workers to live subscribers
+func (s *WorkerService) SetEvents(p events.Publisher) {
+	s.events = p
+}
+
+// SetWorkerEvents publishes heartbeats and departures to live subscribers
+func (s *WorkerService) SetWorkerEvents(p events.WorkerPublisher) {
+	s.fleet = p
+}
+
+// SetClaimable announces the jobs released back to pending to the
+// workers long-polling the claim endpoint
+func (s *WorkerService) SetClaimable(p events.ClaimablePublisher) {
+	s.claimable = p
+}
+
+// SetLateResultsWindow lets a worker complete a job from its outbox up to
+// d after the job timed out or failed as unreachable
+func (s *WorkerService) SetLateResultsWindow(d time.Duration) {
+	s.lateResultsWindow = d
+}
+
+// SetReports emails the report of jobs workers complete
+func (s *WorkerService) SetReports(r *ReportService) {
+	s.reports = r
+}
+
+// SetAudit records the job transitions workers cause in repo. Pruning is
+// left to the JobService sharing it.
+func (s *WorkerService) SetAudit(repo domain.JobEventRepository) {
+	s.audit = newJobAudit(repo, 0)
+}
+
+func (s *WorkerService) publishStatus(ctx context.Context, jobID uuid.UUID, status domain.JobStatus, errMsg string) {
+	if s.events != nil {
+		s.events.Publish(ctx, events.StatusEvent(jobID, status, errMsg))
+	}
+	if status == domain.JobStatusPending && s.claimable != nil {
+		s.claimable.PublishClaimable(ctx)
+	}
+}
+
 // Register registers a new worker or updates existing one
 func (s *WorkerService) Register(ctx context.Context, workerID string) (*domain.Worker, error) {
 	hostname, _ := os.Hostname()
@@ -44,8 +104,9 @@ func (s *WorkerService) Register(ctx context.Context, workerID string) (*domain.
 	return worker, nil
 }
 
-// Heartbeat updates worker heartbeat and status
-func (s *WorkerService) Heartbeat(ctx context.Context, hb *domain.WorkerHeartbeat) error {
+// Heartbeat updates worker heartbeat and status, and returns what the
+// manager asks of the worker, if anything
+func (s *WorkerService) Heartbeat(ctx context.Context, hb *domain.WorkerHeartbeat) (*domain.WorkerDirectives, error) {
 	hostname := hb.Hostname
 	if hostname == "" {
 		hostname, _ = os.Hostname()
@@ -57,9 +118,94 @@ func (s *WorkerService) Heartbeat(ctx context.Context, hb *domain.WorkerHeartbea
 		Status:        hb.Status,
 		CurrentJobID:  hb.CurrentJobID,
 		LastHeartbeat: time.Now().UTC(),
+
+		RequestDelayMs: hb.RequestDelayMs,
+		BlockCount:     hb.BlockCount,
+
+		SeedJobsCompleted: hb.SeedJobsCompleted,
+		SeedJobsTotal:     hb.SeedJobsTotal,
+		JobPlacesScraped:  hb.JobPlacesScraped,
+		MemoryBytes:       hb.MemoryBytes,
+	}
+
+	if err := s.workers.Upsert(ctx, worker); err != nil {
+		return nil, err
+	}
+
+	if s.fleet != nil {
+		state := *hb
+		state.Hostname = hostname
+		s.fleet.PublishWorker(ctx, events.WorkerEvent{Type: events.TypeWorkerUpdate, WorkerID: hb.WorkerID, State: &state})
+	}
+
+	return worker.Directives(), nil
+}
+
+// Drain asks a worker to stop taking jobs and exit once its current job is
+// done. With a timeout, the job is released back to pending if the worker
+// still holds it by then; zero waits however long the job takes.
+func (s *WorkerService) Drain(ctx context.Context, workerID string, timeout time.Duration) (*domain.Worker, error) {
+	worker, err := s.workers.GetByID(ctx, workerID)
+	if err != nil {
+		return nil, err
+	}
+	if worker == nil {
+		return nil, ErrWorkerNotFound
+	}
+
+	var deadline *time.Time
+	if timeout > 0 {
+		t := time.Now().UTC().Add(timeout)
+		deadline = &t
+	}
+
+	if err := s.workers.Drain(ctx, workerID, deadline); err != nil {
+		return nil, fmt.Errorf("failed to drain worker: %w", err)
+	}
+
+	logging.Logger(ctx, "WorkerService").Info("draining worker", "worker_id", workerID, "timeout", timeout)
+
+	return s.workers.GetByID(ctx, workerID)
+}
+
+// ReleaseExpiredDrains releases the jobs of draining workers past their
+// deadline back to pending. The worker learns from its next heartbeat that
+// its time is up and stops the job.
+func (s *WorkerService) ReleaseExpiredDrains(ctx context.Context) error {
+	expired, err := s.workers.ExpireDrains(ctx)
+	if err != nil {
+		return err
 	}
 
-	return s.workers.Upsert(ctx, worker)
+	logger := logging.Logger(ctx, "WorkerService")
+
+	for _, d := range expired {
+		job, err := s.jobs.GetByID(ctx, d.JobID)
+		if err != nil {
+			logger.Warn("get drained job failed", "worker_id", d.WorkerID, "job_id", d.JobID, "error", err)
+			continue
+		}
+
+		// The worker keeps reporting the job until it stops it; once released
+		// it may belong to another worker
+		if job == nil || job.Status != domain.JobStatusRunning || job.WorkerID == nil || *job.WorkerID != d.WorkerID {
+			continue
+		}
+
+		released := &domain.JobEvent{JobID: d.JobID, Type: domain.JobEventReleased, Status: domain.JobStatusPending,
+			Actor: domain.ActorSystem, Message: "drain of worker " + d.WorkerID + " timed out"}
+		if err := s.audit.apply(ctx, s.jobs, released, func(jobs domain.JobRepository) error {
+			return jobs.ReleaseJob(ctx, d.JobID)
+		}); err != nil {
+			logger.Warn("release drained job failed", "worker_id", d.WorkerID, "job_id", d.JobID, "error", err)
+			continue
+		}
+
+		logger.Info("drain timed out, released job", "worker_id", d.WorkerID, "job_id", d.JobID)
+		s.publishStatus(ctx, d.JobID, domain.JobStatusPending, "")
+	}
+
+	return nil
 }
 
 // List retrieves all workers
@@ -77,10 +223,23 @@ func (s *WorkerService) GetStats(ctx context.Context) (*domain.WorkerStats, erro
 	return s.workers.GetStats(ctx)
 }
 
-// ClaimJob claims a pending job for a worker
+// ClaimJob claims a pending job for a worker. A draining worker gets none.
 func (s *WorkerService) ClaimJob(ctx context.Context, workerID string) (*domain.Job, error) {
+	if worker, err := s.workers.GetByID(ctx, workerID); err == nil && worker != nil && worker.IsDraining() {
+		return nil, nil
+	}
+
 	// Update worker status to busy
-	job, err := s.jobs.ClaimJob(ctx, workerID)
+	var job *domain.Job
+	claimed := &domain.JobEvent{Type: domain.JobEventClaimed, Status: domain.JobStatusRunning, Actor: domain.WorkerActor(workerID)}
+	err := s.audit.apply(ctx, s.jobs, claimed, func(jobs domain.JobRepository) error {
+		var err error
+		job, err = jobs.ClaimJob(ctx, workerID)
+		if job != nil {
+			claimed.JobID = job.ID
+		}
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to claim job: %w", err)
 	}
@@ -89,50 +248,140 @@ func (s *WorkerService) ClaimJob(ctx context.Context, workerID string) (*domain.
 		return nil, nil // No pending jobs
 	}
 
+	s.publishStatus(ctx, job.ID, domain.JobStatusRunning, "")
+
 	// Update worker status
 	if err := s.workers.UpdateStatus(ctx, workerID, domain.WorkerStatusBusy); err != nil {
 		// Log but don't fail
-		fmt.Printf("warning: failed to update worker status: %v\n", err)
+		logging.Logger(ctx, "WorkerService").Warn("update worker status failed", "worker_id", workerID, "error", err)
 	}
 
 	return job, nil
 }
 
-// ReleaseJob releases a job back to pending (e.g., worker crashed)
+// ReleaseJob releases a job back to pending (e.g., worker crashed). Workers
+// also release jobs they stopped because they were paused or cancelled;
+// those keep their status.
 func (s *WorkerService) ReleaseJob(ctx context.Context, jobID uuid.UUID, workerID string) error {
-	if err := s.jobs.ReleaseJob(ctx, jobID); err != nil {
+	if stale, err := s.staleReport(ctx, jobID, workerID, "release"); err != nil || stale {
+		return err
+	}
+
+	// A paused or cancelled job keeps its status, so none is recorded
+	released := &domain.JobEvent{JobID: jobID, Type: domain.JobEventReleased, Actor: domain.WorkerActor(workerID)}
+	if err := s.audit.apply(ctx, s.jobs, released, func(jobs domain.JobRepository) error {
+		return jobs.ReleaseJob(ctx, jobID)
+	}); err != nil {
 		return fmt.Errorf("failed to release job: %w", err)
 	}
 
+	if job, err := s.jobs.GetByID(ctx, jobID); err == nil && job != nil && job.Status == domain.JobStatusPending {
+		s.publishStatus(ctx, jobID, domain.JobStatusPending, "")
+	}
+
 	// Update worker status to idle
 	if err := s.workers.UpdateStatus(ctx, workerID, domain.WorkerStatusIdle); err != nil {
-		fmt.Printf("warning: failed to update worker status: %v\n", err)
+		logging.Logger(ctx, "WorkerService").Warn("update worker status failed", "worker_id", workerID, "error", err)
 	}
 
 	return nil
 }
 
-// CompleteJob marks job as completed and updates worker stats
-func (s *WorkerService) CompleteJob(ctx context.Context, jobID uuid.UUID, workerID string, placesScraped int) error {
+// CompleteJob marks job as completed and updates worker stats.
+// failedKeywords are the searches of the run that failed or never ran, and
+// stoppedReason is why the run stopped (empty when the worker doesn't say).
+// outputErrors are the job outputs the worker could not write; they become
+// the job's error message without failing it. dedupedPlaces are the places
+// the run skipped as already scraped, and parseReport counts the fields it
+// could not read, nil when the worker parsed no place page.
+//
+// A job the worker no longer holds is completed only if it awaits late
+// results: the worker delivered the run from its outbox after the job timed
+// out or failed as unreachable.
+func (s *WorkerService) CompleteJob(ctx context.Context, jobID uuid.UUID, workerID string, placesScraped, dedupedPlaces int, failedKeywords []string, stoppedReason string, outputErrors []string, parseReport *domain.JobParseReport) error {
+	job, err := s.jobs.GetByID(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to get job: %w", err)
+	}
+
+	late := job != nil && !job.HeldBy(workerID)
+	if late && !job.AwaitsLateResults(time.Now(), s.lateResultsWindow) {
+		logging.Logger(ctx, "WorkerService").Warn("ignoring completion of a job the worker no longer holds", "job_id", jobID, "worker_id", workerID, "status", job.Status)
+		return nil
+	}
+
 	// Mark job as completed
-	if err := s.jobs.UpdateStatus(ctx, jobID, domain.JobStatusCompleted); err != nil {
-		return fmt.Errorf("failed to complete job: %w", err)
+	if late {
+		if err := s.completeLate(ctx, job, workerID, placesScraped); err != nil {
+			return fmt.Errorf("failed to complete job: %w", err)
+		}
+	} else {
+		completed := &domain.JobEvent{JobID: jobID, Type: domain.JobEventCompleted, Status: domain.JobStatusCompleted,
+			Actor: domain.WorkerActor(workerID), Message: fmt.Sprintf("%d places scraped", placesScraped)}
+		if err := s.audit.apply(ctx, s.jobs, completed, func(jobs domain.JobRepository) error {
+			return jobs.UpdateStatus(ctx, jobID, domain.JobStatusCompleted)
+		}); err != nil {
+			return fmt.Errorf("failed to complete job: %w", err)
+		}
+	}
+
+	if !domain.IsJobStoppedReason(stoppedReason) {
+		stoppedReason = ""
+	}
+
+	if err := s.recordRun(ctx, jobID, dedupedPlaces, failedKeywords, stoppedReason, outputErrors); err != nil {
+		logging.Logger(ctx, "WorkerService").Warn("record run failed", "job_id", jobID, "error", err)
+	}
+
+	if parseReport != nil {
+		if err := s.jobs.SetParseReport(ctx, jobID, parseReport); err != nil {
+			logging.Logger(ctx, "WorkerService").Warn("store parse report failed", "job_id", jobID, "error", err)
+		}
+	}
+
+	s.publishStatus(ctx, jobID, domain.JobStatusCompleted, "")
+	if s.reports != nil {
+		s.reports.JobCompleted(ctx, jobID)
 	}
 
 	// Update worker stats and status
 	if err := s.workers.IncrementStats(ctx, workerID, 1, placesScraped); err != nil {
-		fmt.Printf("warning: failed to update worker stats: %v\n", err)
+		logging.Logger(ctx, "WorkerService").Warn("update worker stats failed", "worker_id", workerID, "error", err)
+	}
+
+	// The worker went on with other jobs since
+	if late {
+		return nil
 	}
 
 	if err := s.workers.UpdateStatus(ctx, workerID, domain.WorkerStatusIdle); err != nil {
-		fmt.Printf("warning: failed to update worker status: %v\n", err)
+		logging.Logger(ctx, "WorkerService").Warn("update worker status failed", "worker_id", workerID, "error", err)
 	}
 
 	return nil
 }
 
-// FailJob marks job as failed and updates worker
-func (s *WorkerService) FailJob(ctx context.Context, jobID uuid.UUID, workerID string, errMsg string) error {
+// completeLate reconciles a job that awaits late results to completed,
+// dropping the failure the manager recorded
+func (s *WorkerService) completeLate(ctx context.Context, job *domain.Job, workerID string, placesScraped int) error {
+	logging.Logger(ctx, "WorkerService").Info("completing job from the worker's outbox", "job_id", job.ID, "worker_id", workerID, "failed_as", job.ErrorCode)
+
+	now := time.Now().UTC()
+	job.Status = domain.JobStatusCompleted
+	job.ErrorMessage = nil
+	job.ErrorCode = ""
+	job.WorkerID = nil
+	job.CompletedAt = &now
+
+	completed := &domain.JobEvent{JobID: job.ID, Type: domain.JobEventCompleted, Status: domain.JobStatusCompleted,
+		Actor: domain.WorkerActor(workerID), Message: fmt.Sprintf("%d places scraped, delivered late", placesScraped)}
+
+	return s.updateJob(ctx, job, completed)
+}
+
+// FailJob marks job as failed with the error code the worker classified
+// the failure as, internal for an empty or unknown one, and updates worker
+func (s *WorkerService) FailJob(ctx context.Context, jobID uuid.UUID, workerID string, dedupedPlaces int, errMsg string, errorCode string, failedKeywords []string) error {
 	// Get job to update with error message
 	job, err := s.jobs.GetByID(ctx, jobID)
 	if err != nil {
@@ -141,24 +390,178 @@ func (s *WorkerService) FailJob(ctx context.Context, jobID uuid.UUID, workerID s
 	if job == nil {
 		return fmt.Errorf("job not found")
 	}
+	if !job.HeldBy(workerID) {
+		logging.Logger(ctx, "WorkerService").Warn("ignoring failure of a job the worker no longer holds", "job_id", jobID, "worker_id", workerID, "status", job.Status)
+		return nil
+	}
 
 	job.Status = domain.JobStatusFailed
 	job.ErrorMessage = &errMsg
-
-	if err := s.jobs.Update(ctx, job); err != nil {
+	job.ErrorCode = domain.ParseJobErrorCode(errorCode)
+	job.FailedKeywords = failedKeywords
+	job.RetryKeywords = nil
+	job.StoppedReason = ""
+	job.Progress.DedupedPlaces = dedupedPlaces
+
+	failed := &domain.JobEvent{JobID: jobID, Type: domain.JobEventFailed, Status: domain.JobStatusFailed,
+		Actor: domain.WorkerActor(workerID), ErrorCode: job.ErrorCode, Message: errMsg}
+	if err := s.updateJob(ctx, job, failed); err != nil {
 		return fmt.Errorf("failed to update job: %w", err)
 	}
 
+	s.publishStatus(ctx, jobID, domain.JobStatusFailed, errMsg)
+
 	// Update worker status to idle
 	if err := s.workers.UpdateStatus(ctx, workerID, domain.WorkerStatusIdle); err != nil {
-		fmt.Printf("warning: failed to update worker status: %v\n", err)
+		logging.Logger(ctx, "WorkerService").Warn("update worker status failed", "worker_id", workerID, "error", err)
 	}
 
 	return nil
 }
 
-// MarkOfflineWorkers marks stale workers as offline and releases their jobs
-func (s *WorkerService) MarkOfflineWorkers(ctx context.Context) (int, error) {
+// staleReport returns true if workerID no longer holds the job it reports
+// on, e.g. because the job timed out, and the report is to be ignored
+func (s *WorkerService) staleReport(ctx context.Context, jobID uuid.UUID, workerID, report string) (bool, error) {
+	job, err := s.jobs.GetByID(ctx, jobID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get job: %w", err)
+	}
+	if job == nil || job.HeldBy(workerID) {
+		return false, nil
+	}
+
+	logging.Logger(ctx, "WorkerService").Warn("ignoring "+report+" of a job the worker no longer holds", "job_id", jobID, "worker_id", workerID, "status", job.Status)
+
+	return true, nil
+}
+
+// TimeOutJobs fails the running jobs a worker has held for longer than
+// their MaxTime times grace, e.g. because the worker hung before its own
+// deadline applied. A job with RetryOnTimeout goes back to pending instead
+// the first time. Either way the worker is detached; it finds out the next
+// time it checks the job and stops without submitting its results.
+func (s *WorkerService) TimeOutJobs(ctx context.Context, grace float64) error {
+	running, err := s.jobs.ListRunning(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list running jobs: %w", err)
+	}
+
+	now := time.Now().UTC()
+	for _, job := range running {
+		deadline, ok := job.TimeoutDeadline(grace)
+		if !ok || now.Before(deadline) {
+			continue
+		}
+
+		if err := s.timeOut(ctx, job, now); err != nil {
+			logging.Logger(ctx, "WorkerService").Warn("time out job failed", "job_id", job.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *WorkerService) timeOut(ctx context.Context, job *domain.Job, now time.Time) error {
+	logger := logging.Logger(ctx, "WorkerService").With("job_id", job.ID, "worker_id", *job.WorkerID)
+	ran := now.Sub(*job.StartedAt).Round(time.Second)
+
+	if job.Config.RetryOnTimeout && !job.TimeoutRequeued {
+		job.Status = domain.JobStatusPending
+		job.WorkerID = nil
+		job.StartedAt = nil
+		job.TimeoutRequeued = true
+
+		timedOut := &domain.JobEvent{JobID: job.ID, Type: domain.JobEventTimedOut, Status: domain.JobStatusPending,
+			Actor: domain.ActorSystem, Message: fmt.Sprintf("requeued after %s", ran)}
+		if err := s.updateJob(ctx, job, timedOut); err != nil {
+			return err
+		}
+
+		logger.Warn("job timed out, requeued", "ran", ran.String(), "max_time", job.Config.MaxTime.String())
+		s.publishStatus(ctx, job.ID, domain.JobStatusPending, "")
+
+		return nil
+	}
+
+	msg := fmt.Sprintf("timed out: worker %s held the job for %s, max_time is %s", *job.WorkerID, ran, job.Config.MaxTime)
+
+	job.Status = domain.JobStatusFailed
+	job.ErrorMessage = &msg
+	job.ErrorCode = domain.JobErrorTimeout
+	job.FailedKeywords = job.RunKeywords()
+	job.RetryKeywords = nil
+	job.StoppedReason = ""
+	job.WorkerID = nil
+	job.CompletedAt = &now
+
+	timedOut := &domain.JobEvent{JobID: job.ID, Type: domain.JobEventTimedOut, Status: domain.JobStatusFailed,
+		Actor: domain.ActorSystem, ErrorCode: domain.JobErrorTimeout, Message: msg}
+	if err := s.updateJob(ctx, job, timedOut); err != nil {
+		return err
+	}
+
+	logger.Warn("job timed out, failed", "ran", ran.String(), "max_time", job.Config.MaxTime.String())
+	s.publishStatus(ctx, job.ID, domain.JobStatusFailed, msg)
+
+	return nil
+}
+
+// updateJob stores a job, recording ev in the audit trail
+func (s *WorkerService) updateJob(ctx context.Context, job *domain.Job, ev *domain.JobEvent) error {
+	return s.audit.apply(ctx, s.jobs, ev, func(jobs domain.JobRepository) error {
+		return jobs.Update(ctx, job)
+	})
+}
+
+// RunJobTimeouts runs TimeOutJobs every minute until ctx is done
+func (s *WorkerService) RunJobTimeouts(ctx context.Context, grace float64) error {
+	logger := logging.Logger(ctx, "WorkerService")
+	logger.Info("job timeouts started", "grace", grace)
+
+	ticker := time.NewTicker(jobTimeoutInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("job timeouts stopped")
+			return nil
+		case <-ticker.C:
+			if err := s.TimeOutJobs(ctx, grace); err != nil {
+				logger.Warn("job timeouts failed", "error", err)
+			}
+		}
+	}
+}
+
+// recordRun stores the keywords a finished run failed, which a retry runs
+// again, why the run stopped and how many places it skipped as already
+// scraped, and ends the retry the run was. Output errors are recorded as the
+// job's error message.
+func (s *WorkerService) recordRun(ctx context.Context, jobID uuid.UUID, dedupedPlaces int, failedKeywords []string, stoppedReason string, outputErrors []string) error {
+	job, err := s.jobs.GetByID(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if job == nil || (len(failedKeywords) == 0 && len(job.FailedKeywords) == 0 && len(job.RetryKeywords) == 0 && job.StoppedReason == stoppedReason && job.Progress.DedupedPlaces == dedupedPlaces && len(outputErrors) == 0) {
+		return nil
+	}
+
+	job.FailedKeywords = failedKeywords
+	job.RetryKeywords = nil
+	job.StoppedReason = stoppedReason
+	job.Progress.DedupedPlaces = dedupedPlaces
+	if len(outputErrors) > 0 {
+		msg := "outputs failed: " + strings.Join(outputErrors, "; ")
+		job.ErrorMessage = &msg
+	}
+
+	return s.jobs.Update(ctx, job)
+}
+
+// MarkOfflineWorkers marks stale workers as offline and releases their
+// jobs. It returns the IDs of the workers it marked.
+func (s *WorkerService) MarkOfflineWorkers(ctx context.Context) ([]string, error) {
 	timeout := int(domain.HeartbeatTimeout.Seconds())
 	return s.workers.MarkOfflineWorkers(ctx, timeout)
 }
@@ -173,10 +576,22 @@ func (s *WorkerService) Unregister(ctx context.Context, workerID string) error {
 
 	if worker != nil && worker.CurrentJobID != nil {
 		// Release the job back to pending
-		if err := s.jobs.ReleaseJob(ctx, *worker.CurrentJobID); err != nil {
-			fmt.Printf("warning: failed to release job: %v\n", err)
+		released := &domain.JobEvent{JobID: *worker.CurrentJobID, Type: domain.JobEventReleased,
+			Actor: domain.WorkerActor(workerID), Message: "worker unregistered"}
+		if err := s.audit.apply(ctx, s.jobs, released, func(jobs domain.JobRepository) error {
+			return jobs.ReleaseJob(ctx, *worker.CurrentJobID)
+		}); err != nil {
+			logging.Logger(ctx, "WorkerService").Warn("release job failed", "worker_id", workerID, "job_id", *worker.CurrentJobID, "error", err)
 		}
 	}
 
-	return s.workers.Delete(ctx, workerID)
+	if err := s.workers.Delete(ctx, workerID); err != nil {
+		return err
+	}
+
+	if s.fleet != nil {
+		s.fleet.PublishWorker(ctx, events.WorkerEvent{Type: events.TypeWorkerOffline, WorkerID: workerID})
+	}
+
+	return nil
 }
@@ -0,0 +1,97 @@
+package slowlog
+
+import (
+	"context"
+	"database/sql"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// MaxSQL is how much of a slow query's SQL is kept
+const MaxSQL = 500
+
+// DB times the queries run through it and records those over the DB
+// threshold with the repository method that ran them. Rows of a query are
+// timed until the first one is ready, not while they are read.
+type DB struct {
+	*sql.DB
+	log *Log
+}
+
+// WrapDB returns db with its queries timed into log
+func WrapDB(db *sql.DB, log *Log) *DB {
+	return &DB{DB: db, log: log}
+}
+
+// ExecContext runs a statement, timing it
+func (d *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	res, err := d.DB.ExecContext(ctx, query, args...)
+	d.observe(ctx, start, query, len(args), err)
+	return res, err
+}
+
+// QueryContext runs a query, timing it
+func (d *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := d.DB.QueryContext(ctx, query, args...)
+	d.observe(ctx, start, query, len(args), err)
+	return rows, err
+}
+
+// QueryRowContext runs a single row query, timing it
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
+	row := d.DB.QueryRowContext(ctx, query, args...)
+	d.observe(ctx, start, query, len(args), row.Err())
+	return row
+}
+
+func (d *DB) observe(ctx context.Context, start time.Time, query string, args int, err error) {
+	elapsed := time.Since(start)
+	if !d.log.Slow(KindDB, elapsed) {
+		return
+	}
+
+	e := Entry{
+		Kind:       KindDB,
+		At:         start,
+		DurationMs: elapsed.Milliseconds(),
+		Name:       callerName(3),
+		SQL:        truncateSQL(query),
+		Args:       args,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	d.log.Record(ctx, e)
+}
+
+// callerName returns the function skip frames up, without its import path,
+// such as postgres.(*JobRepository).List
+func callerName(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return ""
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// truncateSQL collapses the whitespace of a query and cuts it at MaxSQL
+func truncateSQL(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > MaxSQL {
+		query = query[:MaxSQL] + "…"
+	}
+	return query
+}
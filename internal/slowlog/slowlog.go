@@ -0,0 +1,127 @@
+// Package slowlog keeps the latest HTTP requests and database queries that
+// took longer than their threshold, so slow endpoints can be inspected in
+// production without access to the logs.
+package slowlog
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+const (
+	// DefaultHTTPThreshold is how long a request may take before it is logged
+	DefaultHTTPThreshold = 2 * time.Second
+	// DefaultDBThreshold is how long a query may take before it is logged
+	DefaultDBThreshold = 500 * time.Millisecond
+
+	// Size is how many slow operations are kept
+	Size = 100
+)
+
+// Kinds of slow operations
+const (
+	KindHTTP = "http"
+	KindDB   = "db"
+)
+
+// Entry is a slow operation
+type Entry struct {
+	Kind       string    `json:"kind"`
+	At         time.Time `json:"at"` // When it started
+	DurationMs int64     `json:"duration_ms"`
+	Name       string    `json:"name"`             // Route pattern, or repository method of a query
+	Method     string    `json:"method,omitempty"` // HTTP method
+	Status     int       `json:"status,omitempty"` // HTTP status
+	Params     string    `json:"params,omitempty"` // Query string, credentials removed
+	Caller     string    `json:"caller,omitempty"` // API key name or client address
+	SQL        string    `json:"sql,omitempty"`    // Truncated to MaxSQL
+	Args       int       `json:"args,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Thresholds are the durations past which operations are logged; zero
+// turns logging of that kind off
+type Thresholds struct {
+	HTTP time.Duration
+	DB   time.Duration
+}
+
+// Log is a ring buffer of the latest Size slow operations
+type Log struct {
+	httpThreshold atomic.Int64
+	dbThreshold   atomic.Int64
+
+	mu      sync.Mutex
+	entries []Entry
+	next    int // Where the next entry goes once entries is full
+}
+
+// New creates a Log with the given thresholds
+func New(t Thresholds) *Log {
+	l := &Log{entries: make([]Entry, 0, Size)}
+	l.SetThresholds(t)
+	return l
+}
+
+// Thresholds returns the current thresholds
+func (l *Log) Thresholds() Thresholds {
+	return Thresholds{
+		HTTP: time.Duration(l.httpThreshold.Load()),
+		DB:   time.Duration(l.dbThreshold.Load()),
+	}
+}
+
+// SetThresholds changes the thresholds, for operations that end from now on
+func (l *Log) SetThresholds(t Thresholds) {
+	l.httpThreshold.Store(int64(t.HTTP))
+	l.dbThreshold.Store(int64(t.DB))
+}
+
+// Slow reports whether an operation of kind that took d is logged
+func (l *Log) Slow(kind string, d time.Duration) bool {
+	threshold := l.dbThreshold.Load()
+	if kind == KindHTTP {
+		threshold = l.httpThreshold.Load()
+	}
+	return threshold > 0 && int64(d) >= threshold
+}
+
+// Record adds a slow operation, dropping the oldest one when the buffer is
+// full, and logs it
+func (l *Log) Record(ctx context.Context, e Entry) {
+	l.mu.Lock()
+	if len(l.entries) < Size {
+		l.entries = append(l.entries, e)
+	} else {
+		l.entries[l.next] = e
+		l.next = (l.next + 1) % Size
+	}
+	l.mu.Unlock()
+
+	attrs := []any{"kind", e.Kind, "name", e.Name, "duration_ms", e.DurationMs}
+	if e.Kind == KindHTTP {
+		attrs = append(attrs, "method", e.Method, "status", e.Status, "params", e.Params, "caller", e.Caller)
+	} else {
+		attrs = append(attrs, "sql", e.SQL, "args", e.Args)
+	}
+	logging.Logger(ctx, "SlowLog").Warn("slow operation", attrs...)
+}
+
+// Entries returns the slow operations kept, newest first
+func (l *Log) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// The newest entry is the one before next, which stays 0 until the
+	// buffer is full
+	n := len(l.entries)
+	out := make([]Entry, 0, n)
+	for i := range n {
+		out = append(out, l.entries[(l.next-1-i+2*n)%n])
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package slowlog
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogEntries(t *testing.T) {
+	l := New(Thresholds{HTTP: time.Second, DB: time.Second})
+	assert.Empty(t, l.Entries())
+
+	for i := range Size + 5 {
+		l.Record(context.Background(), Entry{Kind: KindDB, Name: strconv.Itoa(i)})
+	}
+
+	entries := l.Entries()
+	assert.Len(t, entries, Size)
+	assert.Equal(t, strconv.Itoa(Size+4), entries[0].Name)
+	assert.Equal(t, "5", entries[Size-1].Name)
+}
+
+func TestLogSlow(t *testing.T) {
+	l := New(Thresholds{HTTP: 2 * time.Second, DB: 500 * time.Millisecond})
+
+	assert.True(t, l.Slow(KindHTTP, 2*time.Second))
+	assert.False(t, l.Slow(KindHTTP, time.Second))
+	assert.True(t, l.Slow(KindDB, time.Second))
+
+	l.SetThresholds(Thresholds{HTTP: 5 * time.Second})
+	assert.False(t, l.Slow(KindHTTP, 2*time.Second))
+	assert.False(t, l.Slow(KindDB, time.Hour), "a zero threshold turns logging off")
+}
+
+func TestTruncateSQL(t *testing.T) {
+	assert.Equal(t, "SELECT id FROM jobs WHERE id = $1", truncateSQL("\n\tSELECT id\n\tFROM jobs\n\tWHERE id = $1\n"))
+
+	long := truncateSQL("SELECT " + strings.Repeat("x, ", MaxSQL))
+	assert.Equal(t, MaxSQL+len("…"), len(long))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
+	"github.com/google/uuid"
 )
 
 // DockerSpawner spawns workers as local Docker containers
@@ -19,7 +20,7 @@ type DockerSpawner struct {
 	managerURL  string
 	rabbitmqURL string
 	redisAddr   string
-	proxies     string // Proxy URL for workers (e.g., "socks5://host:port")
+	secrets     func() map[string]string
 
 	// Track active containers
 	mu         sync.Mutex
@@ -27,7 +28,7 @@ type DockerSpawner struct {
 }
 
 // NewDockerSpawner creates a new Docker spawner
-func NewDockerSpawner(cfg *DockerConfig, managerURL, rabbitmqURL, redisAddr, proxies string) (*DockerSpawner, error) {
+func NewDockerSpawner(cfg *DockerConfig, managerURL, rabbitmqURL, redisAddr string, secrets func() map[string]string) (*DockerSpawner, error) {
 	// Set defaults
 	if cfg.Image == "" {
 		cfg.Image = "gmaps-scraper:latest"
@@ -62,20 +63,22 @@ func NewDockerSpawner(cfg *DockerConfig, managerURL, rabbitmqURL, redisAddr, pro
 		managerURL:  managerURL,
 		rabbitmqURL: rabbitmqURL,
 		redisAddr:   redisAddr,
-		proxies:     proxies,
+		secrets:     secrets,
 		containers:  make(map[string]time.Time),
 	}, nil
 }
 
 func (s *DockerSpawner) Spawn(ctx context.Context, req *SpawnRequest) (*SpawnResult, error) {
+	containerName := newWorkerName()
+
 	// Check max workers limit and reserve a slot atomically
-	reservationKey := "pending-" + req.JobID.String()
+	reservationKey := "pending-" + containerName
 	s.mu.Lock()
 	activeCount := len(s.containers)
 	if s.cfg.MaxWorkers > 0 && activeCount >= s.cfg.MaxWorkers {
 		s.mu.Unlock()
-		log.Printf("[DockerSpawner] Max workers reached (%d/%d), skipping spawn for job %s",
-			activeCount, s.cfg.MaxWorkers, req.JobID)
+		log.Printf("[DockerSpawner] Max workers reached (%d/%d), skipping spawn of %s",
+			activeCount, s.cfg.MaxWorkers, containerName)
 		return &SpawnResult{
 			Status: "skipped",
 			Error:  "max workers limit reached",
@@ -96,6 +99,7 @@ func (s *DockerSpawner) Spawn(ctx context.Context, req *SpawnRequest) (*SpawnRes
 	cmd := []string{
 		"-worker",
 		"-manager-url", s.managerURL,
+		"-worker-id", containerName,
 		"-c", fmt.Sprintf("%d", s.cfg.Concurrency),
 	}
 
@@ -105,35 +109,35 @@ func (s *DockerSpawner) Spawn(ctx context.Context, req *SpawnRequest) (*SpawnRes
 	if s.redisAddr != "" {
 		cmd = append(cmd, "-redis-addr", s.redisAddr)
 	}
-	if s.proxies != "" {
-		cmd = append(cmd, "-proxies", s.proxies)
-	}
 
 	// Add extra args
 	cmd = append(cmd, req.ExtraArgs...)
 
 	// Build environment variables
-	env := []string{
-		fmt.Sprintf("JOB_ID=%s", req.JobID),
-		fmt.Sprintf("JOB_PRIORITY=%d", req.Priority),
+	var env []string
+	labels := map[string]string{
+		"gmaps.worker":  "true",
+		"gmaps.spawner": "docker",
+	}
+	if req.JobID != uuid.Nil {
+		env = append(env,
+			fmt.Sprintf("JOB_ID=%s", req.JobID),
+			fmt.Sprintf("JOB_PRIORITY=%d", req.Priority),
+		)
+		labels["gmaps.job-id"] = req.JobID.String()
+		labels["gmaps.priority"] = fmt.Sprintf("%d", req.Priority)
 	}
 	for k, v := range s.cfg.Environment {
 		env = append(env, fmt.Sprintf("%s=%s", k, v))
 	}
+	env = append(env, secretEnv(s.secrets)...)
 
 	// Container configuration
-	containerName := fmt.Sprintf("gmaps-worker-%s", req.JobID.String()[:8])
-
 	containerCfg := &container.Config{
-		Image: s.cfg.Image,
-		Cmd:   cmd,
-		Env:   env,
-		Labels: map[string]string{
-			"gmaps.worker":   "true",
-			"gmaps.job-id":   req.JobID.String(),
-			"gmaps.priority": fmt.Sprintf("%d", req.Priority),
-			"gmaps.spawner":  "docker",
-		},
+		Image:  s.cfg.Image,
+		Cmd:    cmd,
+		Env:    env,
+		Labels: labels,
 	}
 
 	hostCfg := &container.HostConfig{
@@ -144,7 +148,7 @@ func (s *DockerSpawner) Spawn(ctx context.Context, req *SpawnRequest) (*SpawnRes
 	networkCfg := &network.NetworkingConfig{}
 
 	// Create container
-	log.Printf("[DockerSpawner] Creating container %s for job %s", containerName, req.JobID)
+	log.Printf("[DockerSpawner] Creating container %s", containerName)
 
 	resp, err := s.client.ContainerCreate(ctx, containerCfg, hostCfg, networkCfg, nil, containerName)
 	if err != nil {
@@ -158,16 +162,15 @@ func (s *DockerSpawner) Spawn(ctx context.Context, req *SpawnRequest) (*SpawnRes
 		return nil, fmt.Errorf("failed to start container: %w", err)
 	}
 
-	// Track container
+	// Track container by name, which is also the worker ID
 	s.mu.Lock()
-	s.containers[resp.ID] = time.Now()
+	s.containers[containerName] = time.Now()
 	s.mu.Unlock()
 
-	log.Printf("[DockerSpawner] Started container %s (ID: %s) for job %s",
-		containerName, resp.ID[:12], req.JobID)
+	log.Printf("[DockerSpawner] Started container %s (ID: %s)", containerName, resp.ID[:12])
 
 	return &SpawnResult{
-		WorkerID: resp.ID,
+		WorkerID: containerName,
 		Status:   "running",
 	}, nil
 }
@@ -215,6 +218,22 @@ func (s *DockerSpawner) Stop(ctx context.Context, workerID string) error {
 	return nil
 }
 
+// Terminate stops a worker's container and removes it. The worker receives
+// SIGTERM first, so it can release its job and unregister.
+func (s *DockerSpawner) Terminate(ctx context.Context, workerID string) error {
+	if err := s.Stop(ctx, workerID); err != nil {
+		return err
+	}
+
+	if !s.cfg.AutoRemove {
+		if err := s.client.ContainerRemove(ctx, workerID, container.RemoveOptions{}); err != nil {
+			return fmt.Errorf("failed to remove container: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (s *DockerSpawner) Close() error {
 	return s.client.Close()
 }
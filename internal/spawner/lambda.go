@@ -205,6 +205,11 @@ func (s *LambdaSpawner) Stop(ctx context.Context, workerID string) error {
 	return nil
 }
 
+// Terminate is not supported: a Lambda invocation runs until its job is done
+func (s *LambdaSpawner) Terminate(ctx context.Context, workerID string) error {
+	return ErrTerminateUnsupported
+}
+
 func (s *LambdaSpawner) Close() error {
 	// Nothing to close for Lambda client
 	return nil
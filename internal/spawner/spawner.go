@@ -9,14 +9,20 @@ package spawner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
 )
 
+// ErrTerminateUnsupported is returned by spawners whose workers cannot be
+// shut down from the outside
+var ErrTerminateUnsupported = errors.New("spawner cannot terminate workers")
+
 // SpawnRequest contains the information needed to spawn a worker
 type SpawnRequest struct {
-	// JobID is the UUID of the job to process
+	// JobID is the UUID of the job to process. Docker and Swarm workers
+	// take any queued job and leave it unset.
 	JobID uuid.UUID
 
 	// Priority of the job (higher = more urgent)
@@ -64,6 +70,10 @@ type Spawner interface {
 	// Stop terminates a spawned worker
 	Stop(ctx context.Context, workerID string) error
 
+	// Terminate shuts down the worker registered with the manager as
+	// workerID and removes whatever the spawner created for it
+	Terminate(ctx context.Context, workerID string) error
+
 	// Close cleans up spawner resources
 	Close() error
 
@@ -95,8 +105,11 @@ type Config struct {
 	// RedisAddr is the Redis address
 	RedisAddr string
 
-	// Proxies is the proxy URL for workers (e.g., "socks5://host:port")
-	Proxies string
+	// Secrets returns the credentials workers get as environment
+	// variables, e.g. PROXIES. It is called at each spawn so they are never
+	// kept in the spawner config nor passed as arguments, which anyone who
+	// can list processes sees.
+	Secrets func() map[string]string
 
 	// Docker-specific configuration
 	Docker DockerConfig
@@ -140,7 +153,8 @@ type SwarmConfig struct {
 	// Concurrency is the default concurrency per worker
 	Concurrency int
 
-	// Replicas is the number of replicas per service
+	// Replicas is the number of replicas per service. Replicas register
+	// under the service name, so keep it at 1 when auto-scaling.
 	Replicas int
 
 	// MaxServices is the maximum number of concurrent services
@@ -171,15 +185,30 @@ type LambdaConfig struct {
 	MaxConcurrent int
 }
 
+// secretEnv returns the variables of secrets as KEY=value pairs
+func secretEnv(secrets func() map[string]string) []string {
+	if secrets == nil {
+		return nil
+	}
+
+	var env []string
+	for k, v := range secrets() {
+		if v != "" {
+			env = append(env, k+"="+v)
+		}
+	}
+	return env
+}
+
 // New creates a new spawner based on configuration
 func New(cfg *Config) (Spawner, error) {
 	switch cfg.Type {
 	case SpawnerTypeNone, "":
 		return NewNoOpSpawner(), nil
 	case SpawnerTypeDocker:
-		return NewDockerSpawner(&cfg.Docker, cfg.ManagerURL, cfg.RabbitMQURL, cfg.RedisAddr, cfg.Proxies)
+		return NewDockerSpawner(&cfg.Docker, cfg.ManagerURL, cfg.RabbitMQURL, cfg.RedisAddr, cfg.Secrets)
 	case SpawnerTypeSwarm:
-		return NewSwarmSpawner(&cfg.Swarm, cfg.ManagerURL, cfg.RabbitMQURL, cfg.RedisAddr)
+		return NewSwarmSpawner(&cfg.Swarm, cfg.ManagerURL, cfg.RabbitMQURL, cfg.RedisAddr, cfg.Secrets)
 	case SpawnerTypeLambda:
 		return NewLambdaSpawner(&cfg.Lambda, cfg.ManagerURL, cfg.RabbitMQURL, cfg.RedisAddr)
 	default:
@@ -210,6 +239,10 @@ func (s *NoOpSpawner) Stop(ctx context.Context, workerID string) error {
 	return nil
 }
 
+func (s *NoOpSpawner) Terminate(ctx context.Context, workerID string) error {
+	return nil
+}
+
 func (s *NoOpSpawner) Close() error {
 	return nil
 }
@@ -217,3 +250,10 @@ func (s *NoOpSpawner) Close() error {
 func (s *NoOpSpawner) Name() string {
 	return "none"
 }
+
+// newWorkerName names a spawned container or service. The worker is started
+// with the name as its -worker-id, so the spawner and the manager know it by
+// the same ID.
+func newWorkerName() string {
+	return "gmaps-worker-" + uuid.New().String()[:8]
+}
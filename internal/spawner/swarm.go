@@ -11,6 +11,7 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/swarm"
 	"github.com/docker/docker/client"
+	"github.com/google/uuid"
 )
 
 // SwarmSpawner spawns workers as Docker Swarm services
@@ -21,6 +22,7 @@ type SwarmSpawner struct {
 	managerURL  string
 	rabbitmqURL string
 	redisAddr   string
+	secrets     func() map[string]string
 
 	// Track active services
 	mu       sync.Mutex
@@ -28,7 +30,7 @@ type SwarmSpawner struct {
 }
 
 // NewSwarmSpawner creates a new Docker Swarm spawner
-func NewSwarmSpawner(cfg *SwarmConfig, managerURL, rabbitmqURL, redisAddr string) (*SwarmSpawner, error) {
+func NewSwarmSpawner(cfg *SwarmConfig, managerURL, rabbitmqURL, redisAddr string, secrets func() map[string]string) (*SwarmSpawner, error) {
 	// Set defaults
 	if cfg.Image == "" {
 		cfg.Image = "gmaps-scraper:latest"
@@ -72,19 +74,22 @@ func NewSwarmSpawner(cfg *SwarmConfig, managerURL, rabbitmqURL, redisAddr string
 		managerURL:  managerURL,
 		rabbitmqURL: rabbitmqURL,
 		redisAddr:   redisAddr,
+		secrets:     secrets,
 		services:    make(map[string]time.Time),
 	}, nil
 }
 
 func (s *SwarmSpawner) Spawn(ctx context.Context, req *SpawnRequest) (*SpawnResult, error) {
+	serviceName := newWorkerName()
+
 	// Check max services limit and reserve a slot atomically
-	reservationKey := "pending-" + req.JobID.String()
+	reservationKey := "pending-" + serviceName
 	s.mu.Lock()
 	activeCount := len(s.services)
 	if s.cfg.MaxServices > 0 && activeCount >= s.cfg.MaxServices {
 		s.mu.Unlock()
-		log.Printf("[SwarmSpawner] Max services reached (%d/%d), skipping spawn for job %s",
-			activeCount, s.cfg.MaxServices, req.JobID)
+		log.Printf("[SwarmSpawner] Max services reached (%d/%d), skipping spawn of %s",
+			activeCount, s.cfg.MaxServices, serviceName)
 		return &SpawnResult{
 			Status: "skipped",
 			Error:  "max services limit reached",
@@ -105,6 +110,7 @@ func (s *SwarmSpawner) Spawn(ctx context.Context, req *SpawnRequest) (*SpawnResu
 	cmd := []string{
 		"-worker",
 		"-manager-url", s.managerURL,
+		"-worker-id", serviceName,
 		"-c", fmt.Sprintf("%d", s.cfg.Concurrency),
 	}
 
@@ -118,25 +124,24 @@ func (s *SwarmSpawner) Spawn(ctx context.Context, req *SpawnRequest) (*SpawnResu
 	// Add extra args
 	cmd = append(cmd, req.ExtraArgs...)
 
-	// Build environment variables as strings
-	envStrings := []string{
-		fmt.Sprintf("JOB_ID=%s", req.JobID.String()),
-		fmt.Sprintf("JOB_PRIORITY=%d", req.Priority),
+	// Build environment variables and labels
+	var envStrings []string
+	labels := map[string]string{
+		"gmaps.worker":  "true",
+		"gmaps.spawner": "swarm",
+	}
+	if req.JobID != uuid.Nil {
+		envStrings = append(envStrings,
+			fmt.Sprintf("JOB_ID=%s", req.JobID.String()),
+			fmt.Sprintf("JOB_PRIORITY=%d", req.Priority),
+		)
+		labels["gmaps.job-id"] = req.JobID.String()
+		labels["gmaps.priority"] = fmt.Sprintf("%d", req.Priority)
 	}
 	for k, v := range s.cfg.Environment {
 		envStrings = append(envStrings, fmt.Sprintf("%s=%s", k, v))
 	}
-
-	// Service name
-	serviceName := fmt.Sprintf("gmaps-worker-%s", req.JobID.String()[:8])
-
-	// Build labels
-	labels := map[string]string{
-		"gmaps.worker":   "true",
-		"gmaps.job-id":   req.JobID.String(),
-		"gmaps.priority": fmt.Sprintf("%d", req.Priority),
-		"gmaps.spawner":  "swarm",
-	}
+	envStrings = append(envStrings, secretEnv(s.secrets)...)
 	for k, v := range s.cfg.Labels {
 		labels[k] = v
 	}
@@ -183,23 +188,22 @@ func (s *SwarmSpawner) Spawn(ctx context.Context, req *SpawnRequest) (*SpawnResu
 	}
 
 	// Create service
-	log.Printf("[SwarmSpawner] Creating service %s for job %s", serviceName, req.JobID)
+	log.Printf("[SwarmSpawner] Creating service %s", serviceName)
 
 	resp, err := s.client.ServiceCreate(ctx, serviceSpec, types.ServiceCreateOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create service: %w", err)
 	}
 
-	// Track service
+	// Track service by name, which is also the worker ID
 	s.mu.Lock()
-	s.services[resp.ID] = time.Now()
+	s.services[serviceName] = time.Now()
 	s.mu.Unlock()
 
-	log.Printf("[SwarmSpawner] Created service %s (ID: %s) for job %s",
-		serviceName, resp.ID[:12], req.JobID)
+	log.Printf("[SwarmSpawner] Created service %s (ID: %s)", serviceName, resp.ID[:12])
 
 	return &SpawnResult{
-		WorkerID: resp.ID,
+		WorkerID: serviceName,
 		Status:   "running",
 	}, nil
 }
@@ -267,6 +271,12 @@ func (s *SwarmSpawner) Stop(ctx context.Context, workerID string) error {
 	return nil
 }
 
+// Terminate removes a worker's service. Swarm stops its task with SIGTERM,
+// so the worker can release its job and unregister.
+func (s *SwarmSpawner) Terminate(ctx context.Context, workerID string) error {
+	return s.Stop(ctx, workerID)
+}
+
 func (s *SwarmSpawner) Close() error {
 	return s.client.Close()
 }
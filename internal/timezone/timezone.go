@@ -0,0 +1,175 @@
+// Package timezone finds the IANA timezone of a place from its coordinates,
+// for places whose page leaves it out. It embeds the locations of the tz
+// database's zone.tab, refined by places.tab near the boundaries within
+// countries that have more than one timezone, and gives a place the
+// timezone of the nearest location in its country.
+package timezone
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // Valid must not depend on the zoneinfo of the host
+)
+
+//go:embed zone.tab
+var zoneTab []byte
+
+//go:embed places.tab
+var placesTab []byte
+
+// location is a place whose timezone is known
+type location struct {
+	country  string
+	lat, lon float64
+	name     string
+}
+
+type index struct {
+	all       []location
+	byCountry map[string][]location
+}
+
+var loadIndex = sync.OnceValue(func() *index {
+	idx := &index{byCountry: make(map[string][]location)}
+	for _, data := range [][]byte{zoneTab, placesTab} {
+		for _, loc := range parseTab(data) {
+			idx.all = append(idx.all, loc)
+			idx.byCountry[loc.country] = append(idx.byCountry[loc.country], loc)
+		}
+	}
+	return idx
+})
+
+// Lookup returns the timezone of the place at lat, lon in country, an ISO
+// 3166-1 alpha-2 code. The nearest location of any country is used when
+// country is empty or unknown. It returns "" for coordinates that are not
+// valid.
+func Lookup(lat, lon float64, country string) string {
+	if (lat == 0 && lon == 0) || math.Abs(lat) > 90 || math.Abs(lon) > 180 || math.IsNaN(lat) || math.IsNaN(lon) {
+		return ""
+	}
+
+	idx := loadIndex()
+
+	candidates := idx.byCountry[strings.ToUpper(strings.TrimSpace(country))]
+	if len(candidates) == 0 {
+		candidates = idx.all
+	}
+
+	best, bestDist := "", math.Inf(1)
+	for _, loc := range candidates {
+		if d := distance(lat, lon, loc.lat, loc.lon); d < bestDist {
+			best, bestDist = loc.name, d
+		}
+	}
+
+	return best
+}
+
+var valid sync.Map // name -> bool
+
+// Valid reports whether name is an IANA timezone such as
+// "America/Chicago". Backward-compatible names like "Asia/Calcutta" are
+// valid too.
+func Valid(name string) bool {
+	if name == "" || name == "Local" || strings.TrimSpace(name) != name {
+		return false
+	}
+
+	if ok, cached := valid.Load(name); cached {
+		return ok.(bool)
+	}
+
+	_, err := time.LoadLocation(name)
+	valid.Store(name, err == nil)
+
+	return err == nil
+}
+
+// distance returns the central angle between two points, which orders
+// them like the distance on the surface does
+func distance(lat1, lon1, lat2, lon2 float64) float64 {
+	const rad = math.Pi / 180
+
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * math.Asin(math.Sqrt(min(1, a)))
+}
+
+// parseTab reads the rows of a file in the format of zone.tab: a country
+// code, ISO 6709 coordinates and the timezone, separated by tabs
+func parseTab(data []byte) []location {
+	var locs []location
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 {
+			continue
+		}
+
+		lat, lon, ok := parseISO6709(fields[1])
+		if !ok {
+			continue
+		}
+
+		locs = append(locs, location{country: fields[0], lat: lat, lon: lon, name: fields[2]})
+	}
+
+	return locs
+}
+
+// parseISO6709 parses coordinates written ±DDMM±DDDMM or ±DDMMSS±DDDMMSS
+func parseISO6709(s string) (lat, lon float64, ok bool) {
+	split := strings.IndexAny(s[1:], "+-") + 1
+	if split == 0 {
+		return 0, 0, false
+	}
+
+	lat, okLat := parseDegrees(s[:split], 2)
+	lon, okLon := parseDegrees(s[split:], 3)
+
+	return lat, lon, okLat && okLon
+}
+
+// parseDegrees parses a signed coordinate whose degrees take width digits,
+// followed by minutes and optionally seconds
+func parseDegrees(s string, width int) (float64, bool) {
+	digits := s[1:]
+	if len(digits) != width+2 && len(digits) != width+4 {
+		return 0, false
+	}
+
+	var parts [3]float64
+	for i, p := range []string{digits[:width], digits[width : width+2], digits[width+2:]} {
+		if p == "" {
+			continue
+		}
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return 0, false
+		}
+		parts[i] = float64(v)
+	}
+
+	deg := parts[0] + parts[1]/60 + parts[2]/3600
+	if s[0] == '-' {
+		deg = -deg
+	}
+
+	return deg, true
+}
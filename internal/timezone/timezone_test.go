@@ -0,0 +1,54 @@
+package timezone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		country  string
+		want     string
+	}{
+		{"single timezone country", 34.6706, 33.0425, "CY", "Asia/Nicosia"},
+		{"lower-case country", 52.52, 13.405, "de", "Europe/Berlin"},
+		{"Chicago", 41.8781, -87.6298, "US", "America/Chicago"},
+		{"Florida panhandle", 30.4213, -87.2169, "US", "America/Chicago"},
+		{"Tallahassee", 30.4383, -84.2807, "US", "America/New_York"},
+		{"El Paso", 31.7619, -106.485, "US", "America/Denver"},
+		{"Phoenix", 33.4484, -112.074, "US", "America/Phoenix"},
+		{"Tijuana, not San Diego", 32.5149, -117.0382, "MX", "America/Tijuana"},
+		{"unknown country", 48.8566, 2.3522, "", "Europe/Paris"},
+		{"no coordinates", 0, 0, "US", ""},
+		{"invalid coordinates", 95, 10, "US", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Lookup(tt.lat, tt.lon, tt.country))
+		})
+	}
+}
+
+func TestEmbeddedLocationsAreValid(t *testing.T) {
+	idx := loadIndex()
+	assert.Greater(t, len(idx.all), 500)
+
+	for _, loc := range idx.all {
+		assert.True(t, Valid(loc.name), "%s of %s", loc.name, loc.country)
+		assert.LessOrEqual(t, len(loc.country), 2)
+	}
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid("America/Chicago"))
+	assert.True(t, Valid("Asia/Calcutta"))
+	assert.True(t, Valid("UTC"))
+	assert.False(t, Valid(""))
+	assert.False(t, Valid("Local"))
+	assert.False(t, Valid("Closed ⋅ Opens 9 AM"))
+	assert.False(t, Valid(" America/Chicago"))
+}
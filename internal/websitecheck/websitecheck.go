@@ -0,0 +1,221 @@
+// Package websitecheck tells live business websites from dead and parked
+// ones, so exports can leave out sites not worth reaching out to.
+package websitecheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+const (
+	// DefaultTimeout bounds each request of a check, redirects included
+	DefaultTimeout = 10 * time.Second
+
+	// MaxRedirects is how many redirects a check follows
+	MaxRedirects = 3
+
+	// bodyLimit is how much of a page is read for the parked heuristic
+	bodyLimit = 64 << 10
+
+	userAgent = "Mozilla/5.0 (compatible; gmaps-scraper website check)"
+)
+
+// parkingHosts serve the parked and for-sale pages domains redirect to
+var parkingHosts = []string{
+	"sedoparking.com", "sedo.com", "parkingcrew.net", "bodis.com",
+	"above.com", "dan.com", "afternic.com", "hugedomains.com",
+	"undeveloped.com", "parklogic.com", "domainmarket.com",
+	"buydomains.com", "namebright.com", "porkbun.com",
+}
+
+// parkedPhrases mark a page as parked when found in its first bodyLimit
+// bytes, lowercased
+var parkedPhrases = []string{
+	"this domain is for sale",
+	"this domain may be for sale",
+	"buy this domain",
+	"domain is parked",
+	"parked free, courtesy of",
+	"this domain has been registered",
+	"the domain has expired",
+	"domain parking",
+	"parkingcrew",
+	"sedoparking",
+	"window.park",
+}
+
+// Checker fetches websites, HEAD first and GET when the server rejects or
+// fails HEAD, following up to MaxRedirects redirects
+type Checker struct {
+	client *http.Client
+}
+
+// New creates a Checker whose requests take at most timeout (0 =
+// DefaultTimeout), through proxy when it is set, e.g. the address of
+// ProxyGate
+func New(timeout time.Duration, proxy *url.URL) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &Checker{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) > MaxRedirects {
+					return http.ErrUseLastResponse
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Check fetches website and classifies it. Failing to reach the site is a
+// result, not an error; the error is ctx's when it ended.
+func (c *Checker) Check(ctx context.Context, website string) (*domain.WebsiteCheck, error) {
+	target, err := Normalize(website)
+	if err != nil {
+		return &domain.WebsiteCheck{
+			Status:    domain.WebsiteStatusUnreachable,
+			Error:     err.Error(),
+			CheckedAt: time.Now().UTC(),
+		}, nil
+	}
+
+	check := &domain.WebsiteCheck{Domain: Domain(target)}
+
+	resp, err := c.fetch(ctx, http.MethodHead, target)
+	if err != nil || resp.StatusCode >= 400 {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		resp, err = c.fetch(ctx, http.MethodGet, target)
+	}
+	check.CheckedAt = time.Now().UTC()
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		check.Status = domain.WebsiteStatusUnreachable
+		check.Error = unwrapURLError(err).Error()
+		return check, nil
+	}
+	defer resp.Body.Close()
+
+	check.HTTPStatus = resp.StatusCode
+	check.FinalURL = resp.Request.URL.String()
+
+	var body []byte
+	if resp.Request.Method == http.MethodGet {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, bodyLimit))
+	}
+
+	switch {
+	case parked(resp.Request.URL, body):
+		check.Status = domain.WebsiteStatusParked
+	case resp.StatusCode >= 300:
+		check.Status = domain.WebsiteStatusHTTPError
+	default:
+		check.Status = domain.WebsiteStatusOK
+	}
+
+	return check, nil
+}
+
+func (c *Checker) fetch(ctx context.Context, method, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,*/*;q=0.8")
+
+	return c.client.Do(req)
+}
+
+// parked reports whether a page that ended at final with body (nil for
+// HEAD) is a parked domain
+func parked(final *url.URL, body []byte) bool {
+	host := strings.ToLower(final.Hostname())
+	for _, h := range parkingHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+
+	if len(body) == 0 {
+		return false
+	}
+	body = bytes.ToLower(body)
+	for _, phrase := range parkedPhrases {
+		if bytes.Contains(body, []byte(phrase)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Normalize turns a scraped website into a URL that can be fetched, adding
+// the http scheme Google Maps often leaves out
+func Normalize(website string) (string, error) {
+	website = strings.TrimSpace(website)
+	if website == "" {
+		return "", errors.New("empty website")
+	}
+	if !strings.Contains(website, "://") {
+		website = "http://" + website
+	}
+
+	u, err := url.Parse(website)
+	if err != nil {
+		return "", fmt.Errorf("invalid website: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid website scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return "", errors.New("invalid website: no host")
+	}
+
+	return u.String(), nil
+}
+
+// Domain returns the lowercase host of website without a leading www.,
+// which checks are reused by; "" when website does not parse
+func Domain(website string) string {
+	target, err := Normalize(website)
+	if err != nil {
+		return ""
+	}
+	u, _ := url.Parse(target)
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// unwrapURLError drops the method and URL url.Error prefixes errors with,
+// which the listing already shows
+func unwrapURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package websitecheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+func TestCheck(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html>Bakery</html>"))
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		_, _ = w.Write([]byte("<html>Bakery</html>"))
+	})
+	mux.HandleFunc("/parked", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte("<html><h1>This Domain Is For Sale</h1></html>"))
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c := New(0, nil)
+	check := func(t *testing.T, path string) *domain.WebsiteCheck {
+		res, err := c.Check(context.Background(), srv.URL+path)
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		res := check(t, "/ok")
+		assert.Equal(t, domain.WebsiteStatusOK, res.Status)
+		assert.Equal(t, http.StatusOK, res.HTTPStatus)
+		assert.Equal(t, "127.0.0.1", res.Domain)
+	})
+
+	t.Run("get fallback", func(t *testing.T) {
+		assert.Equal(t, domain.WebsiteStatusOK, check(t, "/no-head").Status)
+	})
+
+	t.Run("parked", func(t *testing.T) {
+		assert.Equal(t, domain.WebsiteStatusParked, check(t, "/parked").Status)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		res := check(t, "/gone")
+		assert.Equal(t, domain.WebsiteStatusHTTPError, res.Status)
+		assert.Equal(t, http.StatusNotFound, res.HTTPStatus)
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		res := check(t, "/moved")
+		assert.Equal(t, domain.WebsiteStatusOK, res.Status)
+		assert.Equal(t, srv.URL+"/ok", res.FinalURL)
+	})
+
+	t.Run("too many redirects", func(t *testing.T) {
+		res := check(t, "/loop")
+		assert.Equal(t, domain.WebsiteStatusHTTPError, res.Status)
+		assert.Equal(t, http.StatusFound, res.HTTPStatus)
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		res, err := c.Check(context.Background(), "http://127.0.0.1:1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.WebsiteStatusUnreachable, res.Status)
+		assert.NotEmpty(t, res.Error)
+	})
+}
+
+func TestDomain(t *testing.T) {
+	assert.Equal(t, "example.com", Domain("WWW.Example.com/contact"))
+	assert.Equal(t, "shop.example.com", Domain("https://shop.example.com"))
+	assert.Equal(t, "", Domain("ftp://example.com"))
+	assert.True(t, parked(mustURL(t, "https://www.sedoparking.com/x"), nil))
+}
+
+func mustURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return u
+}
@@ -0,0 +1,283 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	parser "github.com/gosom/scrapemate/adapters/parsers/goqueryparser"
+	memprovider "github.com/gosom/scrapemate/adapters/providers/memory"
+	"github.com/gosom/scrapemate/adapters/proxy"
+	"github.com/shirou/gopsutil/v4/mem"
+	"golang.org/x/sync/errgroup"
+)
+
+// memCheckTimeout bounds reading the system memory use
+const memCheckTimeout = 5 * time.Second
+
+// browserKey holds the settings browsers are launched with. A job whose
+// settings differ, such as another proxy list or a ProxyGate session of its
+// own, gets fresh browsers.
+type browserKey struct {
+	userAgent string
+	proxies   string // Sorted, one per line
+}
+
+func newBrowserKey(userAgent string, proxies []string) browserKey {
+	sorted := slices.Clone(proxies)
+	slices.Sort(sorted)
+
+	return browserKey{userAgent: userAgent, proxies: strings.Join(sorted, "\n")}
+}
+
+// proxyRotator rotates over the proxies of key, nil without any
+func proxyRotator(key browserKey) scrapemate.ProxyRotator {
+	if key.proxies == "" {
+		return nil
+	}
+	return proxy.New(strings.Split(key.proxies, "\n"))
+}
+
+// browserPoolConfig configures a browserPool
+type browserPoolConfig struct {
+	MaxIdle        int     // Fetchers kept warm between jobs
+	Contexts       int     // Browser contexts of each fetcher, a job's concurrency
+	PageReuseLimit int     // Navigations of a page before it is closed, 0 for none
+	Recycle        int     // Pages a browser context loads before it is replaced
+	MaxMemPercent  float64 // System memory use over which warm fetchers are closed
+}
+
+// browserPool keeps the browsers of finished jobs running for the next job
+// launched with the same settings, which then skips starting Playwright and
+// the browsers. Each fetcher replaces its browser contexts after Recycle
+// pages or when they disconnect; a fetcher whose browser crashed under a
+// job is closed instead of kept.
+type browserPool struct {
+	cfg        browserPoolConfig
+	newFetcher func(key browserKey) (scrapemate.HTTPFetcher, error)
+	memUsed    func(ctx context.Context) (float64, error) // Percent of system memory in use
+	logger     *slog.Logger
+
+	mu     sync.Mutex
+	idle   []*warmFetcher // Oldest first
+	closed bool
+}
+
+// warmFetcher is a fetcher and the settings its browsers run with
+type warmFetcher struct {
+	scrapemate.HTTPFetcher
+	key browserKey
+}
+
+func newBrowserPool(cfg browserPoolConfig, logger *slog.Logger) *browserPool {
+	p := &browserPool{
+		cfg:     cfg,
+		memUsed: systemMemUsed,
+		logger:  logger,
+	}
+	p.newFetcher = func(key browserKey) (scrapemate.HTTPFetcher, error) {
+		return newJSFetcher(key, p.cfg)
+	}
+
+	return p
+}
+
+func systemMemUsed(ctx context.Context) (float64, error) {
+	vm, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return vm.UsedPercent, nil
+}
+
+// get leases a fetcher launched with key, warm when one was idle
+func (p *browserPool) get(key browserKey) (*pooledFetcher, bool, error) {
+	p.mu.Lock()
+	for i := len(p.idle) - 1; i >= 0; i-- {
+		w := p.idle[i]
+		if w.key != key {
+			continue
+		}
+		p.idle = slices.Delete(p.idle, i, i+1)
+		p.mu.Unlock()
+		return &pooledFetcher{warm: w, pool: p}, true, nil
+	}
+	p.mu.Unlock()
+
+	fetcher, err := p.newFetcher(key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &pooledFetcher{warm: &warmFetcher{HTTPFetcher: fetcher, key: key}, pool: p}, false, nil
+}
+
+// put takes a fetcher back once its job is done, closing it when its
+// browser crashed, when the pool is full or when memory use is over the
+// watermark
+func (p *browserPool) put(w *warmFetcher, crashed bool) {
+	if crashed {
+		p.logger.Warn("browser crashed, closing it instead of keeping it warm")
+		_ = w.Close()
+		return
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		_ = w.Close()
+		return
+	}
+
+	p.idle = append(p.idle, w)
+	var evicted []*warmFetcher
+	for len(p.idle) > p.cfg.MaxIdle {
+		evicted = append(evicted, p.idle[0])
+		p.idle = p.idle[1:]
+	}
+	p.mu.Unlock()
+
+	for _, e := range evicted {
+		_ = e.Close()
+	}
+
+	p.shrink()
+}
+
+// shrink closes warm fetchers, oldest first, while the system memory use
+// is over the watermark
+func (p *browserPool) shrink() {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), memCheckTimeout)
+		used, err := p.memUsed(ctx)
+		cancel()
+		if err != nil {
+			p.logger.Warn("failed to read memory use, warm browsers are kept", "error", err)
+			return
+		}
+		if used < p.cfg.MaxMemPercent {
+			return
+		}
+
+		p.mu.Lock()
+		if len(p.idle) == 0 {
+			p.mu.Unlock()
+			return
+		}
+		w := p.idle[0]
+		p.idle = p.idle[1:]
+		left := len(p.idle)
+		p.mu.Unlock()
+
+		p.logger.Info("memory use over the watermark, closing warm browsers", "used_percent", used, "max_percent", p.cfg.MaxMemPercent, "warm_left", left)
+		_ = w.Close()
+	}
+}
+
+// Close closes the warm fetchers; fetchers still leased are closed when
+// their job hands them back
+func (p *browserPool) Close() {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+
+	for _, w := range idle {
+		_ = w.Close()
+	}
+}
+
+// pooledFetcher is a lease of a warm fetcher for one job
+type pooledFetcher struct {
+	warm *warmFetcher
+	pool *browserPool
+
+	crashed  atomic.Bool
+	released sync.Once
+}
+
+// Fetch implements scrapemate.HTTPFetcher, noting a crashed browser
+func (f *pooledFetcher) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	resp := f.warm.Fetch(ctx, job)
+	if resp.Error != nil && ctx.Err() == nil && isBrowserCrash(resp.Error) {
+		f.crashed.Store(true)
+	}
+	return resp
+}
+
+// Close hands the fetcher back to the pool. Scrapemate closes its fetcher
+// when a run ends; only the first Close of a lease counts.
+func (f *pooledFetcher) Close() error {
+	f.released.Do(func() {
+		f.pool.put(f.warm, f.crashed.Load())
+	})
+	return nil
+}
+
+// pooledMate runs a job's seed jobs on a leased fetcher the way
+// scrapemateapp does, which always launches browsers of its own
+type pooledMate struct {
+	fetcher          *pooledFetcher
+	writers          []scrapemate.ResultWriter
+	concurrency      int
+	exitOnInactivity time.Duration
+}
+
+// Start implements mateRunner
+func (m *pooledMate) Start(ctx context.Context, seedJobs ...scrapemate.IJob) error {
+	g, ctx := errgroup.WithContext(ctx)
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(errors.New("closing app"))
+
+	provider := memprovider.New()
+
+	mate, err := scrapemate.New(
+		scrapemate.WithContext(ctx, cancel),
+		scrapemate.WithJobProvider(provider),
+		scrapemate.WithHTTPFetcher(m.fetcher),
+		scrapemate.WithHTMLParser(parser.New()),
+		scrapemate.WithConcurrency(m.concurrency),
+		scrapemate.WithExitBecauseOfInactivity(m.exitOnInactivity),
+	)
+	if err != nil {
+		return err
+	}
+	defer mate.Close()
+
+	for _, w := range m.writers {
+		g.Go(func() error {
+			if err := w.Run(ctx, mate.Results()); err != nil {
+				cancel(err)
+				return err
+			}
+			return nil
+		})
+	}
+
+	g.Go(func() error {
+		return mate.Start()
+	})
+
+	g.Go(func() error {
+		for _, job := range seedJobs {
+			if err := provider.Push(ctx, job); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return g.Wait()
+}
+
+// Close implements mateRunner, handing the fetcher back if Start did not
+func (m *pooledMate) Close() error {
+	return m.fetcher.Close()
+}
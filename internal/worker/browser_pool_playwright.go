@@ -0,0 +1,31 @@
+//go:build !rod
+
+package worker
+
+import (
+	"errors"
+
+	"github.com/gosom/scrapemate"
+	jsfetcher "github.com/gosom/scrapemate/adapters/fetchers/jshttp"
+	"github.com/playwright-community/playwright-go"
+)
+
+// newJSFetcher launches Playwright and the browsers of a pooled fetcher,
+// set up like scrapemateapp's with images disabled
+func newJSFetcher(key browserKey, cfg browserPoolConfig) (scrapemate.HTTPFetcher, error) {
+	return jsfetcher.New(jsfetcher.JSFetcherOptions{
+		Headless:          true,
+		DisableImages:     true,
+		Rotator:           proxyRotator(key),
+		PoolSize:          cfg.Contexts,
+		PageReuseLimit:    cfg.PageReuseLimit,
+		BrowserReuseLimit: cfg.Recycle,
+		UserAgent:         key.userAgent,
+	})
+}
+
+// isBrowserCrash tells whether a fetch failed because the browser or the
+// Playwright driver went away
+func isBrowserCrash(err error) bool {
+	return errors.Is(err, playwright.ErrTargetClosed)
+}
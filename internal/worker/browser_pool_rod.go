@@ -0,0 +1,31 @@
+//go:build rod
+
+package worker
+
+import (
+	"errors"
+
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/gosom/scrapemate"
+	rodfetcher "github.com/gosom/scrapemate/adapters/fetchers/rodhttp"
+)
+
+// newJSFetcher launches the browsers of a pooled fetcher, set up like
+// scrapemateapp's with images disabled
+func newJSFetcher(key browserKey, cfg browserPoolConfig) (scrapemate.HTTPFetcher, error) {
+	return rodfetcher.New(rodfetcher.RodFetcherOptions{
+		Headless:          true,
+		DisableImages:     true,
+		Rotator:           proxyRotator(key),
+		PoolSize:          cfg.Contexts,
+		PageReuseLimit:    cfg.PageReuseLimit,
+		BrowserReuseLimit: cfg.Recycle,
+		UserAgent:         key.userAgent,
+	})
+}
+
+// isBrowserCrash tells whether a fetch failed because the browser went
+// away
+func isBrowserCrash(err error) bool {
+	return errors.Is(err, cdp.ErrSessionNotFound)
+}
@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFetcher struct {
+	closed int
+}
+
+func (f *fakeFetcher) Fetch(context.Context, scrapemate.IJob) scrapemate.Response {
+	return scrapemate.Response{}
+}
+
+func (f *fakeFetcher) Close() error {
+	f.closed++
+	return nil
+}
+
+func newTestBrowserPool(maxIdle int) (*browserPool, *[]*fakeFetcher, *float64) {
+	var launched []*fakeFetcher
+	memUsed := 10.0
+
+	p := newBrowserPool(browserPoolConfig{MaxIdle: maxIdle, Contexts: 1, MaxMemPercent: 85}, slog.Default())
+	p.newFetcher = func(browserKey) (scrapemate.HTTPFetcher, error) {
+		f := &fakeFetcher{}
+		launched = append(launched, f)
+		return f, nil
+	}
+	p.memUsed = func(context.Context) (float64, error) { return memUsed, nil }
+
+	return p, &launched, &memUsed
+}
+
+func TestBrowserPoolReusesMatchingBrowsers(t *testing.T) {
+	p, launched, _ := newTestBrowserPool(2)
+	key := newBrowserKey("", []string{"socks5://b:1080", "socks5://a:1080"})
+
+	f, warm, err := p.get(key)
+	require.NoError(t, err)
+	assert.False(t, warm)
+	require.NoError(t, f.Close())
+	require.NoError(t, f.Close(), "a second close of the lease is ignored")
+
+	f, warm, err = p.get(newBrowserKey("", []string{"socks5://a:1080", "socks5://b:1080"}))
+	require.NoError(t, err)
+	assert.True(t, warm, "same proxies in another order")
+	assert.Len(t, *launched, 1)
+
+	other, warm, err := p.get(newBrowserKey("", []string{"socks5://c:1080"}))
+	require.NoError(t, err)
+	assert.False(t, warm, "other proxies get fresh browsers")
+
+	require.NoError(t, f.Close())
+	require.NoError(t, other.Close())
+	assert.Len(t, p.idle, 2)
+
+	p.Close()
+	for _, l := range *launched {
+		assert.Equal(t, 1, l.closed)
+	}
+}
+
+func TestBrowserPoolClosesCrashedAndExtraBrowsers(t *testing.T) {
+	p, launched, memUsed := newTestBrowserPool(1)
+
+	crashed, _, err := p.get(newBrowserKey("crash", nil))
+	require.NoError(t, err)
+	crashed.crashed.Store(true)
+	require.NoError(t, crashed.Close())
+	assert.Equal(t, 1, (*launched)[0].closed)
+	assert.Empty(t, p.idle)
+
+	first, _, _ := p.get(newBrowserKey("a", nil))
+	second, _, _ := p.get(newBrowserKey("b", nil))
+	require.NoError(t, first.Close())
+	require.NoError(t, second.Close())
+	assert.Equal(t, 1, (*launched)[1].closed, "the oldest is closed over MaxIdle")
+	assert.Len(t, p.idle, 1)
+
+	*memUsed = 90
+	third, _, _ := p.get(newBrowserKey("c", nil))
+	require.NoError(t, third.Close())
+	assert.Empty(t, p.idle, "memory over the watermark closes warm browsers")
+	assert.Equal(t, 1, (*launched)[2].closed)
+	assert.Equal(t, 1, (*launched)[3].closed)
+}
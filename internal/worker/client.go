@@ -1,28 +1,34 @@
 package worker
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/sadewadee/google-scraper/client"
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/grpcapi"
+	"github.com/sadewadee/google-scraper/internal/logging"
 )
 
 // Client is a worker client that communicates with the manager API
+// through the shared API client, as the worker identified by workerID.
+// With SetGRPC it talks gRPC instead, and falls back to HTTP call by call
+// while the gRPC port cannot be reached.
 type Client struct {
-	baseURL    string
-	workerID   string
-	hostname   string
-	apiToken   string
-	httpClient *http.Client
+	api      *client.Client
+	workerID string
+	hostname string
+	apiToken string
+
+	grpc    *grpcapi.Client // nil for HTTP only
+	grpcOff atomic.Bool     // Set once the manager turned out not to serve gRPC
 }
 
 // NewClient creates a new worker client
@@ -44,281 +50,285 @@ func NewClient(baseURL, workerID string) *Client {
 		ExpectContinueTimeout: 1 * time.Second,
 	}
 
+	httpClient := &http.Client{
+		Timeout:   60 * time.Second, // Increased from 30s for large result batches
+		Transport: transport,
+	}
+
 	return &Client{
-		baseURL:  baseURL,
+		api:      client.NewWithHTTPClient(baseURL, apiToken, httpClient),
 		workerID: workerID,
 		hostname: hostname,
 		apiToken: apiToken,
-		httpClient: &http.Client{
-			Timeout:   60 * time.Second, // Increased from 30s for large result batches
-			Transport: transport,
-		},
 	}
 }
 
-// Register registers the worker with the manager
-func (c *Client) Register(ctx context.Context) (*domain.Worker, error) {
-	body := map[string]string{
-		"worker_id": c.workerID,
-	}
-
-	resp, err := c.post(ctx, "/api/v2/workers/register", body)
+// SetGRPC makes the client talk to the manager's gRPC server at target
+// (host:port), with the same API token. Directives the manager sends on
+// the heartbeat stream, answers and pushed ones alike, go to onDirectives.
+func (c *Client) SetGRPC(target string, onDirectives func(*domain.WorkerDirectives)) error {
+	g, err := grpcapi.NewClient(target, c.apiToken)
 	if err != nil {
-		return nil, fmt.Errorf("failed to register worker: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
-		return nil, c.parseError(resp)
-	}
+	g.OnDirectives(onDirectives)
+	c.grpc = g
 
-	var worker domain.Worker
-	if err := json.NewDecoder(resp.Body).Decode(&worker); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return &worker, nil
+	return nil
 }
 
-// Heartbeat sends a heartbeat to the manager
-func (c *Client) Heartbeat(ctx context.Context, status domain.WorkerStatus, currentJobID *uuid.UUID) error {
-	body := map[string]interface{}{
-		"worker_id": c.workerID,
-		"hostname":  c.hostname,
-		"status":    status,
+// Close closes the gRPC connection, if any
+func (c *Client) Close() error {
+	if c.grpc == nil {
+		return nil
 	}
+	return c.grpc.Close()
+}
 
-	if currentJobID != nil {
-		body["current_job_id"] = currentJobID.String()
+// viaGRPC makes a call with fn over gRPC and reports whether it did. It
+// reports false, for the caller to make the call over HTTP, without gRPC
+// or when the gRPC server cannot be reached.
+func (c *Client) viaGRPC(ctx context.Context, fn func(g *grpcapi.Client) error) (bool, error) {
+	if c.grpc == nil || c.grpcOff.Load() {
+		return false, nil
 	}
 
-	resp, err := c.post(ctx, "/api/v2/workers/heartbeat", body)
-	if err != nil {
-		return fmt.Errorf("failed to send heartbeat: %w", err)
+	err := fn(c.grpc)
+	if !grpcapi.IsUnreachable(err) {
+		return true, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent {
-		return c.parseError(resp)
+	if grpcapi.IsUnimplemented(err) && c.grpcOff.CompareAndSwap(false, true) {
+		logging.FromContext(ctx).Warn("manager does not serve the worker protocol over gRPC, using HTTP", "error", err)
+	} else {
+		logging.FromContext(ctx).Debug("manager gRPC unreachable, using HTTP", "error", err)
 	}
 
-	return nil
+	return false, nil
 }
 
-// GetJob fetches a specific job by ID from the manager
-func (c *Client) GetJob(ctx context.Context, jobID uuid.UUID) (*domain.Job, error) {
-	url := fmt.Sprintf("/api/v2/jobs/%s", jobID.String())
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	if c.apiToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiToken)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get job: %w", err)
+// Register registers the worker with the manager
+func (c *Client) Register(ctx context.Context) (*domain.Worker, error) {
+	var worker *domain.Worker
+	if ok, err := c.viaGRPC(ctx, func(g *grpcapi.Client) (err error) {
+		worker, err = g.Register(ctx, c.workerID)
+		return err
+	}); ok {
+		return worker, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, nil // Job not found
-	}
+	return c.api.RegisterWorker(ctx, c.workerID)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, c.parseError(resp)
-	}
+// Heartbeat sends a heartbeat to the manager and returns what the manager
+// asks of the worker, nil for nothing. The worker ID and hostname are the
+// client's. Over gRPC the answer goes to the SetGRPC handler instead, and
+// nil is returned.
+func (c *Client) Heartbeat(ctx context.Context, hb domain.WorkerHeartbeat) (*domain.WorkerDirectives, error) {
+	hb.WorkerID = c.workerID
+	hb.Hostname = c.hostname
 
-	var job domain.Job
-	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if ok, err := c.viaGRPC(ctx, func(g *grpcapi.Client) error {
+		return g.Heartbeat(ctx, hb)
+	}); ok {
+		return nil, err
 	}
 
-	return &job, nil
+	return c.api.Heartbeat(ctx, hb)
 }
 
-// ClaimJob claims a pending job from the manager
-func (c *Client) ClaimJob(ctx context.Context) (*domain.Job, error) {
-	url := fmt.Sprintf("/api/v2/workers/%s/claim", c.workerID)
-
-	resp, err := c.post(ctx, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to claim job: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, c.parseError(resp)
+// GetJob fetches a specific job by ID from the manager, nil if it does not
+// exist
+func (c *Client) GetJob(ctx context.Context, jobID uuid.UUID) (*domain.Job, error) {
+	var job *domain.Job
+	if ok, err := c.viaGRPC(ctx, func(g *grpcapi.Client) (err error) {
+		job, err = g.GetJob(ctx, jobID)
+		return err
+	}); ok {
+		return job, err
 	}
 
-	var result struct {
-		Job *domain.Job `json:"job"`
+	// Managers predating the worker route answer it with 404 too, and do
+	// not mask the proxies of GET /api/v2/jobs/{id}
+	job, err := c.api.GetWorkerJob(ctx, c.workerID, jobID)
+	if client.IsStatus(err, http.StatusNotFound) {
+		job, err = c.api.GetJob(ctx, jobID)
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if client.IsStatus(err, http.StatusNotFound) {
+		return nil, nil
 	}
 
-	return result.Job, nil
+	return job, err
 }
 
-// CompleteJob marks a job as completed
-func (c *Client) CompleteJob(ctx context.Context, jobID uuid.UUID, placesScraped int) error {
-	url := fmt.Sprintf("/api/v2/workers/%s/complete", c.workerID)
-
-	body := map[string]interface{}{
-		"job_id":         jobID.String(),
-		"places_scraped": placesScraped,
-	}
-
-	resp, err := c.post(ctx, url, body)
-	if err != nil {
-		return fmt.Errorf("failed to complete job: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusNoContent {
-		return c.parseError(resp)
+// ClaimJob claims a pending job from the manager
+func (c *Client) ClaimJob(ctx context.Context) (*domain.Job, error) {
+	var job *domain.Job
+	if ok, err := c.viaGRPC(ctx, func(g *grpcapi.Client) (err error) {
+		job, err = g.ClaimJob(ctx, c.workerID)
+		return err
+	}); ok {
+		return job, err
 	}
 
-	return nil
+	return c.api.ClaimJob(ctx, c.workerID)
 }
 
-// FailJob marks a job as failed
-func (c *Client) FailJob(ctx context.Context, jobID uuid.UUID, errMsg string) error {
-	url := fmt.Sprintf("/api/v2/workers/%s/fail", c.workerID)
-
-	body := map[string]interface{}{
-		"job_id":  jobID.String(),
-		"message": errMsg,
+// ClaimJobWait claims a pending job, letting the manager hold the request
+// up to wait for one. waited reports whether it did; over gRPC, or with a
+// manager predating long-polling, the claim is answered at once.
+func (c *Client) ClaimJobWait(ctx context.Context, wait time.Duration) (job *domain.Job, waited bool, err error) {
+	if ok, err := c.viaGRPC(ctx, func(g *grpcapi.Client) (err error) {
+		job, err = g.ClaimJob(ctx, c.workerID)
+		return err
+	}); ok {
+		return job, false, err
 	}
 
-	resp, err := c.post(ctx, url, body)
-	if err != nil {
-		return fmt.Errorf("failed to fail job: %w", err)
-	}
-	defer resp.Body.Close()
+	return c.api.ClaimJobWait(ctx, c.workerID, wait)
+}
 
-	if resp.StatusCode != http.StatusNoContent {
-		return c.parseError(resp)
-	}
+// CompleteJob marks a job as completed, reporting the keywords whose
+// search failed or never ran, why the run stopped and the job outputs that
+// could not be written
+func (c *Client) CompleteJob(ctx context.Context, jobID uuid.UUID, placesScraped, dedupedPlaces int, failedKeywords []string, stoppedReason string, outputErrors []string, parseReport *domain.JobParseReport) error {
+	if ok, err := c.viaGRPC(ctx, func(g *grpcapi.Client) error {
+		return g.CompleteJob(ctx, c.workerID, jobID, placesScraped, dedupedPlaces, failedKeywords, stoppedReason, outputErrors, parseReport)
+	}); ok {
+		return err
+	}
+
+	return c.api.CompleteJob(ctx, c.workerID, client.CompleteJobRequest{
+		JobID:          jobID,
+		PlacesScraped:  placesScraped,
+		DedupedPlaces:  dedupedPlaces,
+		FailedKeywords: failedKeywords,
+		StoppedReason:  stoppedReason,
+		OutputErrors:   outputErrors,
+		ParseReport:    parseReport,
+	})
+}
 
-	return nil
+// FailJob marks a job as failed with the kind of failure, reporting the
+// keywords whose search failed or never ran
+func (c *Client) FailJob(ctx context.Context, jobID uuid.UUID, dedupedPlaces int, errMsg string, errorCode domain.JobErrorCode, failedKeywords []string) error {
+	if ok, err := c.viaGRPC(ctx, func(g *grpcapi.Client) error {
+		return g.FailJob(ctx, c.workerID, jobID, dedupedPlaces, errMsg, errorCode, failedKeywords)
+	}); ok {
+		return err
+	}
+
+	return c.api.FailJob(ctx, c.workerID, client.FailJobRequest{
+		JobID:          jobID,
+		Message:        errMsg,
+		ErrorCode:      string(errorCode),
+		FailedKeywords: failedKeywords,
+		DedupedPlaces:  dedupedPlaces,
+	})
 }
 
 // ReleaseJob releases a job back to pending
 func (c *Client) ReleaseJob(ctx context.Context, jobID uuid.UUID) error {
-	url := fmt.Sprintf("/api/v2/workers/%s/release", c.workerID)
-
-	body := map[string]interface{}{
-		"job_id": jobID.String(),
-	}
-
-	resp, err := c.post(ctx, url, body)
-	if err != nil {
-		return fmt.Errorf("failed to release job: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusNoContent {
-		return c.parseError(resp)
+	if ok, err := c.viaGRPC(ctx, func(g *grpcapi.Client) error {
+		return g.ReleaseJob(ctx, c.workerID, jobID)
+	}); ok {
+		return err
 	}
 
-	return nil
+	return c.api.ReleaseJob(ctx, c.workerID, jobID)
 }
 
 // Unregister unregisters the worker from the manager
 func (c *Client) Unregister(ctx context.Context) error {
-	url := fmt.Sprintf("/api/v2/workers/%s", c.workerID)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.baseURL+url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	if ok, err := c.viaGRPC(ctx, func(g *grpcapi.Client) error {
+		return g.Unregister(ctx, c.workerID)
+	}); ok {
+		return err
 	}
 
-	if c.apiToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiToken)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
+	return c.api.UnregisterWorker(ctx, c.workerID)
+}
 
-	if resp.StatusCode != http.StatusNoContent {
-		return c.parseError(resp)
-	}
+// LeaseProxies leases up to count of the manager's proxies for a job, in
+// country when one is given. Leasing is HTTP only.
+func (c *Client) LeaseProxies(ctx context.Context, country string, count int) (*domain.ProxyLease, error) {
+	return c.api.LeaseProxies(ctx, c.workerID, country, count)
+}
 
-	return nil
+// ReportProxyFailures reports leased proxies that failed so the manager
+// demotes them
+func (c *Client) ReportProxyFailures(ctx context.Context, jobID *uuid.UUID, failures []*domain.ProxyFailure) error {
+	return c.api.ReportProxyFailures(ctx, domain.ProxyFailureReport{
+		WorkerID: c.workerID,
+		JobID:    jobID,
+		Failures: failures,
+	})
 }
 
-// SubmitResults submits results to the manager
-func (c *Client) SubmitResults(ctx context.Context, jobID uuid.UUID, data [][]byte) error {
-	batch := domain.ResultBatch{
-		JobID: jobID,
-		Data:  data,
-	}
+// Result submission retries network errors and 5xx responses this many
+// times, doubling the wait from submitBackoff up to submitMaxBackoff
+const (
+	submitAttempts   = 5
+	submitBackoff    = time.Second
+	submitMaxBackoff = 30 * time.Second
+)
 
-	url := fmt.Sprintf("/api/v2/jobs/%s/results", jobID.String())
-	log.Printf("[WorkerClient] Submitting %d results to %s%s", len(data), c.baseURL, url)
+// ErrResultsRejected is returned when the manager refuses a result batch
+// for good, e.g. because the job is gone or its quota is used up, so
+// sending it again does not help
+var ErrResultsRejected = errors.New("manager rejected results")
+
+// SubmitBatch submits one result batch, retrying with exponential backoff
+// while the manager is unreachable or fails. The batch ID lets the manager
+// ignore a retry of a batch it already stored.
+func (c *Client) SubmitBatch(ctx context.Context, batch domain.ResultBatch) error {
+	logger := logging.FromContext(ctx).With("batch_id", batch.BatchID, "results", len(batch.Data))
+
+	backoff := submitBackoff
+	for attempt := 1; ; attempt++ {
+		err := c.submitBatchOnce(ctx, batch)
+		if err == nil {
+			logger.Info("results submitted")
+			return nil
+		}
+		if errors.Is(err, ErrResultsRejected) || attempt == submitAttempts || ctx.Err() != nil {
+			logger.Error("submitting results failed", "attempts", attempt, "error", err)
+			return err
+		}
 
-	resp, err := c.post(ctx, url, batch)
-	if err != nil {
-		log.Printf("[WorkerClient] HTTP request failed: %v", err)
-		return fmt.Errorf("failed to submit results: %w", err)
-	}
-	defer resp.Body.Close()
+		logger.Warn("submitting results failed, retrying", "attempt", attempt, "backoff", backoff, "error", err)
 
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		body, _ := io.ReadAll(resp.Body)
-		log.Printf("[WorkerClient] SubmitResults failed with status %d: %s", resp.StatusCode, string(body))
-		return c.parseError(resp)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, submitMaxBackoff)
 	}
-
-	log.Printf("[WorkerClient] SubmitResults succeeded with status %d", resp.StatusCode)
-	return nil
 }
 
-func (c *Client) post(ctx context.Context, path string, body interface{}) (*http.Response, error) {
-	var bodyReader io.Reader
-
-	if body != nil {
-		data, err := json.Marshal(body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal body: %w", err)
+// submitBatchOnce sends the batch once. Network errors, timeouts and 5xx
+// responses are worth retrying; any other refusal is ErrResultsRejected.
+func (c *Client) submitBatchOnce(ctx context.Context, batch domain.ResultBatch) error {
+	if ok, err := c.viaGRPC(ctx, func(g *grpcapi.Client) error {
+		return g.SubmitResults(ctx, batch)
+	}); ok {
+		if grpcapi.IsRejected(err) {
+			return fmt.Errorf("%w: %w", ErrResultsRejected, err)
 		}
-		bodyReader = bytes.NewReader(data)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bodyReader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	if c.apiToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiToken)
-	}
-
-	return c.httpClient.Do(req)
-}
+	err := c.api.SubmitResults(ctx, batch)
 
-func (c *Client) parseError(resp *http.Response) error {
-	body, _ := io.ReadAll(resp.Body)
-
-	var apiErr struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		return err
 	}
-
-	if err := json.Unmarshal(body, &apiErr); err != nil {
-		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+	if apiErr.StatusCode >= http.StatusInternalServerError || apiErr.StatusCode == http.StatusRequestTimeout {
+		return err
 	}
 
-	return fmt.Errorf("request failed: %s", apiErr.Message)
+	return fmt.Errorf("%w: %w", ErrResultsRejected, err)
 }
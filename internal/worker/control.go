@@ -0,0 +1,259 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gosom/scrapemate"
+
+	"github.com/sadewadee/google-scraper/deduper"
+	"github.com/sadewadee/google-scraper/gmaps"
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/events"
+	"github.com/sadewadee/google-scraper/internal/logging"
+	"github.com/sadewadee/google-scraper/internal/queue"
+)
+
+// jobControlInterval is how often a running job's status is checked with
+// the manager. Status events from Redis, when available, arrive sooner.
+const jobControlInterval = 15 * time.Second
+
+// jobStoppedError is returned by processJob when the job was paused or
+// cancelled on the manager while it ran, taken back by the manager, or
+// stopped for a drain
+type jobStoppedError struct {
+	status  domain.JobStatus
+	drained bool
+}
+
+func (e *jobStoppedError) Error() string {
+	switch {
+	case e.status == "" && e.drained:
+		return "job was stopped for a drain"
+	case takenBack(e.status):
+		return fmt.Sprintf("job was taken back by the manager (%s)", e.status)
+	}
+	return fmt.Sprintf("job was %s", e.status)
+}
+
+// takenBack reports whether a job stopped in this status was taken back by
+// the manager: it timed out and was failed or put back to pending. The
+// manager rejects its results and the job is no longer the worker's to
+// release.
+func takenBack(status domain.JobStatus) bool {
+	return status == domain.JobStatusFailed || status == domain.JobStatusPending
+}
+
+// watchJob calls stop once the job is paused, cancelled or deleted on the
+// manager, or taken back from the worker. It returns when stop was called
+// or ctx is done.
+func (r *Runner) watchJob(ctx context.Context, jobID uuid.UUID, stop func(domain.JobStatus)) {
+	ticker := time.NewTicker(jobControlInterval)
+	defer ticker.Stop()
+
+	var pushed, streamed <-chan events.Event
+	if r.statusEvents != nil {
+		ch, unsubscribe := r.statusEvents.Subscribe(jobID)
+		defer unsubscribe()
+		pushed = ch
+	}
+	if r.pushedStops != nil {
+		ch, unsubscribe := r.pushedStops.Subscribe(jobID)
+		defer unsubscribe()
+		streamed = ch
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-pushed:
+			if !ok {
+				pushed = nil
+				continue
+			}
+			if ev.Status.StopsWorker() {
+				stop(ev.Status)
+				return
+			}
+		case ev, ok := <-streamed:
+			if !ok {
+				streamed = nil
+				continue
+			}
+			if ev.Status.StopsWorker() {
+				stop(ev.Status)
+				return
+			}
+		case <-ticker.C:
+			job, err := r.client.GetJob(ctx, jobID)
+			if err != nil {
+				logging.FromContext(ctx).Warn("failed to check job status", "error", err)
+				continue
+			}
+
+			switch {
+			case job == nil:
+				stop(domain.JobStatusCancelled)
+				return
+			case job.Status.StopsWorker():
+				stop(job.Status)
+				return
+			case job.WorkerID != nil && *job.WorkerID != r.workerID:
+				// Another worker claimed it after the manager put it back
+				stop(domain.JobStatusPending)
+				return
+			}
+		}
+	}
+}
+
+// trackingDeduper remembers the places a job claimed in the shared deduper,
+// so the ones it did not finish can be handed back when the job stops early,
+// and counts the places it skipped as already scraped
+type trackingDeduper struct {
+	deduper.Deduper
+
+	mu         sync.Mutex
+	claimed    []string
+	suppressed int
+}
+
+func newTrackingDeduper(d deduper.Deduper) *trackingDeduper {
+	return &trackingDeduper{Deduper: d}
+}
+
+func (d *trackingDeduper) AddIfNotExists(ctx context.Context, key string) bool {
+	if !d.Deduper.AddIfNotExists(ctx, key) {
+		d.mu.Lock()
+		d.suppressed++
+		d.mu.Unlock()
+
+		return false
+	}
+
+	d.mu.Lock()
+	d.claimed = append(d.claimed, key)
+	d.mu.Unlock()
+
+	return true
+}
+
+// deduped returns how many places were skipped as already scraped
+func (d *trackingDeduper) deduped() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.suppressed
+}
+
+// releaseUnfinished removes the claimed places without a result from the
+// Redis deduper, so a resumed run scrapes them while skipping the places
+// that were already submitted. With done nil every claimed place is
+// released.
+func (r *Runner) releaseUnfinished(ctx context.Context, dedup *trackingDeduper, done *MemoryWriter) {
+	shared, ok := dedup.Deduper.(*queue.Deduper)
+	if !ok {
+		return
+	}
+
+	dedup.mu.Lock()
+	claimed := append([]string(nil), dedup.claimed...)
+	dedup.mu.Unlock()
+
+	released := 0
+	for _, placeURL := range claimed {
+		if done != nil && done.HasPlace(placeURL) {
+			continue
+		}
+
+		if err := shared.Forget(ctx, placeURL); err != nil {
+			logging.FromContext(ctx).Warn("failed to release place", "place", placeURL, "error", err)
+			continue
+		}
+		released++
+	}
+
+	logging.FromContext(ctx).Info("released unfinished places", "released", released, "claimed", len(claimed))
+}
+
+// searchTracker records which seed searches of a run succeeded, so the
+// keywords whose search failed or never ran can be retried, and the
+// language each one ended in. Fast mode searches are not tracked.
+type searchTracker struct {
+	mu       sync.Mutex
+	seeds    []string          // seed job IDs in keyword order
+	keywords map[string]string // seed job ID -> keyword
+	ok       map[string]bool
+	langs    map[string]string // seed job ID -> language the search ended in
+	places   map[string]int    // seed job ID -> places the search found
+}
+
+func newSearchTracker(seeds []scrapemate.IJob) *searchTracker {
+	t := &searchTracker{
+		keywords: make(map[string]string),
+		ok:       make(map[string]bool),
+		langs:    make(map[string]string),
+		places:   make(map[string]int),
+	}
+
+	for _, seed := range seeds {
+		j, isSearch := seed.(*gmaps.GmapJob)
+		if !isSearch {
+			continue
+		}
+
+		keyword, _ := j.SearchQuery()
+		t.seeds = append(t.seeds, j.ID)
+		t.keywords[j.ID] = keyword
+		j.TaskReporter = t
+	}
+
+	return t
+}
+
+func (t *searchTracker) TaskFinished(_ context.Context, jobID, lang string, placesFound int, err error) {
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	t.ok[jobID] = true
+	t.langs[jobID] = lang
+	t.places[jobID] = placesFound
+	t.mu.Unlock()
+}
+
+// failed returns the keywords of the searches that did not succeed
+func (t *searchTracker) failed() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var keywords []string
+	for _, id := range t.seeds {
+		if !t.ok[id] {
+			keywords = append(keywords, t.keywords[id])
+		}
+	}
+
+	return keywords
+}
+
+// langFallback reports how the successful searches of a job in jobLang
+// fell back to its other languages
+func (t *searchTracker) langFallback(jobLang string) *domain.LangFallbackReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := &domain.LangFallbackReport{}
+	for _, id := range t.seeds {
+		if t.ok[id] {
+			report.Add(t.keywords[id], jobLang, t.langs[id], t.places[id])
+		}
+	}
+
+	return report
+}
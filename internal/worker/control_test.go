@@ -0,0 +1,23 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sadewadee/google-scraper/deduper"
+)
+
+func TestTrackingDeduperCountsSuppressed(t *testing.T) {
+	ctx := context.Background()
+	d := newTrackingDeduper(deduper.New())
+
+	assert.True(t, d.AddIfNotExists(ctx, "a"))
+	assert.True(t, d.AddIfNotExists(ctx, "b"))
+	assert.False(t, d.AddIfNotExists(ctx, "a"))
+	assert.False(t, d.AddIfNotExists(ctx, "a"))
+
+	assert.Equal(t, []string{"a", "b"}, d.claimed)
+	assert.Equal(t, 2, d.deduped())
+}
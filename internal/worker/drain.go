@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/events"
+)
+
+// errDrained is returned for a job the worker stopped because its drain
+// timed out. The job was released, so the queue should hand it to another
+// worker.
+var errDrained = errors.New("job stopped, worker drain timed out")
+
+// drainCheckInterval is how often a draining worker checks whether its
+// jobs are done
+const drainCheckInterval = time.Second
+
+// applyDirectives acts on the manager's response to a heartbeat, or on
+// directives it pushed on the gRPC heartbeat stream
+func (r *Runner) applyDirectives(d *domain.WorkerDirectives) {
+	if d == nil {
+		return
+	}
+
+	// watchJob stops the job
+	if d.StopJob != nil && r.pushedStops != nil {
+		r.pushedStops.Publish(context.Background(), events.StatusEvent(d.StopJob.JobID, d.StopJob.Status, ""))
+	}
+
+	if !d.Drain {
+		return
+	}
+
+	// Every heartbeat restates the time left, so the deadline follows the
+	// manager if the drain is asked again with another timeout
+	if d.DrainTimeoutSeconds != nil {
+		deadline := time.Now().Add(time.Duration(*d.DrainTimeoutSeconds) * time.Second)
+		r.drainDeadline.Store(deadline.UnixNano())
+	} else {
+		r.drainDeadline.Store(0)
+	}
+
+	if !r.draining.CompareAndSwap(false, true) {
+		return
+	}
+
+	r.logger.Info("draining: taking no new jobs, exiting after the running ones", "timeout_seconds", d.DrainTimeoutSeconds)
+
+	switch {
+	case r.useRabbitMQ && r.mqConsumer != nil:
+		if err := r.mqConsumer.Pause(); err != nil {
+			r.logger.Warn("failed to pause RabbitMQ consumer", "error", err)
+		}
+	case r.useRedis && r.queueWorker != nil:
+		r.queueWorker.Pause()
+	}
+
+	go r.waitDrained()
+}
+
+// waitDrained ends Run once no job is being handled. The jobs still
+// running at the drain deadline are stopped and released.
+func (r *Runner) waitDrained() {
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stopChan:
+			return
+		case <-ticker.C:
+		}
+
+		// Handlers started after the drain hand their job straight back
+		if r.active.Load() == 0 {
+			r.exit()
+			return
+		}
+
+		if deadline := r.drainDeadline.Load(); deadline != 0 && time.Now().UnixNano() >= deadline {
+			for _, rj := range r.runningJobs() {
+				if rj.stop != nil {
+					r.logger.Info("drain timed out, stopping the job", "job_id", rj.job.ID)
+					rj.stop()
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+// jobFailure is an error processJob fails a job with when it knows the
+// kind of failure
+type jobFailure struct {
+	code domain.JobErrorCode
+	err  error
+}
+
+func (e *jobFailure) Error() string { return e.err.Error() }
+
+func (e *jobFailure) Unwrap() error { return e.err }
+
+// failure marks err as a failure of the kind code
+func failure(code domain.JobErrorCode, err error) error {
+	return &jobFailure{code: code, err: err}
+}
+
+// errorCode classifies the error a job failed with for the manager. Errors
+// processJob did not mark are told apart by their type, and are internal
+// when nothing matches.
+func errorCode(err error) domain.JobErrorCode {
+	var (
+		failed    *jobFailure
+		netErr    net.Error
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &failed):
+		return failed.code
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return domain.JobErrorTimeout
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return domain.JobErrorParse
+	default:
+		return domain.JobErrorInternal
+	}
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+func TestErrorCode(t *testing.T) {
+	jsonErr := json.Unmarshal([]byte("{"), &struct{}{})
+
+	tests := []struct {
+		name string
+		err  error
+		want domain.JobErrorCode
+	}{
+		{"marked", failure(domain.JobErrorBlockedByGoogle, errors.New("stopped early")), domain.JobErrorBlockedByGoogle},
+		{"marked and wrapped", fmt.Errorf("run: %w", failure(domain.JobErrorManagerUnreachable, errors.New("EOF"))), domain.JobErrorManagerUnreachable},
+		{"deadline", fmt.Errorf("wait for browser contexts: %w", context.DeadlineExceeded), domain.JobErrorTimeout},
+		{"network timeout", &net.OpError{Op: "dial", Err: timeoutError{}}, domain.JobErrorTimeout},
+		{"parse", fmt.Errorf("decode: %w", jsonErr), domain.JobErrorParse},
+		{"other", errors.New("disk full"), domain.JobErrorInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, errorCode(tt.err))
+		})
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
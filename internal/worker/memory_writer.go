@@ -4,14 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/gosom/scrapemate"
+
+	"github.com/sadewadee/google-scraper/gmaps"
+	"github.com/sadewadee/google-scraper/internal/domain"
 )
 
 // MemoryWriter is a ResultWriter that stores results in memory
 type MemoryWriter struct {
 	mu      sync.Mutex
 	Results [][]byte
+	places  map[string]struct{} // Place URLs that produced a result
+	parse   domain.JobParseReport
+	first   time.Time // When the first result came in
 }
 
 // Run implements scrapemate.ResultWriter
@@ -23,12 +30,41 @@ func (w *MemoryWriter) Run(ctx context.Context, in <-chan scrapemate.Result) err
 			w.mu.Unlock()
 			return err
 		}
+		if len(w.Results) == 0 {
+			w.first = time.Now()
+		}
 		w.Results = append(w.Results, data)
+		w.markPlace(result.Job)
+		if entry, ok := result.Data.(*gmaps.Entry); ok && entry.ParseReport != nil {
+			w.parse.Add(entry.ParseReport.Missing, entry.ParseReport.Failed)
+		}
 		w.mu.Unlock()
 	}
 	return nil
 }
 
+// markPlace records the place URL behind a result. A place with email
+// extraction only produces its result from the email job.
+func (w *MemoryWriter) markPlace(job scrapemate.IJob) {
+	var placeURL string
+
+	switch j := job.(type) {
+	case *gmaps.PlaceJob:
+		placeURL = j.GetURL()
+	case *gmaps.EmailExtractJob:
+		placeURL = j.PlaceURL
+	}
+
+	if placeURL == "" {
+		return
+	}
+
+	if w.places == nil {
+		w.places = make(map[string]struct{})
+	}
+	w.places[placeURL] = struct{}{}
+}
+
 // GetResults returns a copy of the stored results
 func (w *MemoryWriter) GetResults() [][]byte {
 	w.mu.Lock()
@@ -37,3 +73,36 @@ func (w *MemoryWriter) GetResults() [][]byte {
 	copy(result, w.Results)
 	return result
 }
+
+// FirstResultAt returns when the first result came in, zero before
+func (w *MemoryWriter) FirstResultAt() time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.first
+}
+
+// HasPlace reports whether a result was stored for the place URL
+func (w *MemoryWriter) HasPlace(placeURL string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.places[placeURL]
+	return ok
+}
+
+// ParseReport counts the fields the stored places were missing or failed,
+// nil when no place was parsed from a place page
+func (w *MemoryWriter) ParseReport() *domain.JobParseReport {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.parse.Places == 0 {
+		return nil
+	}
+
+	report := domain.JobParseReport{Places: w.parse.Places, Fields: make(map[string]domain.FieldParseStats, len(w.parse.Fields))}
+	for field, stats := range w.parse.Fields {
+		report.Fields[field] = stats
+	}
+
+	return &report
+}
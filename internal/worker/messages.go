@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/queue"
+)
+
+// A handler holds the queue message it handles for messageLeaseTTL and
+// renews it every messageLeaseRenew, so the message of a crashed worker
+// can be handled again soon after
+const (
+	messageLeaseTTL   = time.Minute
+	messageLeaseRenew = 20 * time.Second
+)
+
+// messageLeases keeps two handlers from handling the same queue message at
+// once: a message published again by a manual requeue may be delivered
+// along with the original. With Redis the leases are shared by all
+// workers, else only the handlers of this worker see them. A message
+// handled before may be handled again: by then the job is finished, or
+// back to pending and due to run.
+type messageLeases struct {
+	shared *queue.Deduper // Nil without Redis
+	logger *slog.Logger
+
+	mu   sync.Mutex
+	held map[string]struct{}
+}
+
+func newMessageLeases(shared *queue.Deduper, logger *slog.Logger) *messageLeases {
+	return &messageLeases{
+		shared: shared,
+		logger: logger,
+		held:   make(map[string]struct{}),
+	}
+}
+
+// acquire leases the message with id until release is called. It reports
+// false when another handler holds it. Messages without an ID, from
+// managers that send none, are not leased.
+func (l *messageLeases) acquire(ctx context.Context, id string) (release func(), ok bool) {
+	if id == "" {
+		return func() {}, true
+	}
+
+	l.mu.Lock()
+	if _, busy := l.held[id]; busy {
+		l.mu.Unlock()
+		return nil, false
+	}
+	l.held[id] = struct{}{}
+	l.mu.Unlock()
+
+	release = func() {
+		l.mu.Lock()
+		delete(l.held, id)
+		l.mu.Unlock()
+	}
+
+	if l.shared == nil {
+		return release, true
+	}
+
+	holder := uuid.NewString()
+	ok, err := l.shared.Lease(ctx, id, holder, messageLeaseTTL)
+	if err != nil {
+		// Handling the message twice beats not handling it
+		l.logger.Warn("failed to lease queue message, handling it anyway", "message_id", id, "error", err)
+		return release, true
+	}
+	if !ok {
+		release()
+		return nil, false
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(messageLeaseRenew)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				renewCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := l.shared.RenewLease(renewCtx, id, messageLeaseTTL); err != nil {
+					l.logger.Warn("failed to renew queue message lease", "message_id", id, "error", err)
+				}
+				cancel()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+
+			// The handler's context may be done by now
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := l.shared.ReleaseLease(releaseCtx, id, holder); err != nil {
+				l.logger.Warn("failed to release queue message lease", "message_id", id, "error", err)
+			}
+			release()
+		})
+	}, true
+}
@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageLeases(t *testing.T) {
+	ctx := context.Background()
+	leases := newMessageLeases(nil, slog.Default())
+
+	release, ok := leases.acquire(ctx, "msg-1")
+	assert.True(t, ok)
+
+	// A second delivery of the message while the first is handled
+	_, ok = leases.acquire(ctx, "msg-1")
+	assert.False(t, ok)
+
+	_, ok = leases.acquire(ctx, "msg-2")
+	assert.True(t, ok)
+
+	// Handled again once released, e.g. after a retry
+	release()
+	_, ok = leases.acquire(ctx, "msg-1")
+	assert.True(t, ok)
+
+	// Messages of managers that send no ID are never held
+	_, ok = leases.acquire(ctx, "")
+	assert.True(t, ok)
+	_, ok = leases.acquire(ctx, "")
+	assert.True(t, ok)
+}
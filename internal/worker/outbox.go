@@ -0,0 +1,210 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// outboxDir is the directory in the data folder that holds what the manager
+// could not be reached for: result batches, named <job>_<batch>.json, and
+// the completions of the runs they belong to, named <job>.completion. It
+// keeps the name of the result spool it grew out of, so batches an older
+// worker spooled are still sent.
+const outboxDir = "result-spool"
+
+const completionSuffix = ".completion"
+
+// While the manager stays unreachable the outbox is flushed again after
+// outboxRetryMin, backing off to outboxRetryMax
+const (
+	outboxRetryMin = 10 * time.Second
+	outboxRetryMax = 5 * time.Minute
+)
+
+// outboxCompletion is the completion of a run whose results wait in the
+// outbox. It is reported once the manager acknowledged all of them.
+type outboxCompletion struct {
+	JobID          uuid.UUID              `json:"job_id"`
+	PlacesScraped  int                    `json:"places_scraped"` // Acknowledged and spooled
+	DedupedPlaces  int                    `json:"deduped_places"`
+	FailedKeywords []string               `json:"failed_keywords,omitempty"`
+	StoppedReason  string                 `json:"stopped_reason,omitempty"`
+	OutputErrors   []string               `json:"output_errors,omitempty"`
+	ParseReport    *domain.JobParseReport `json:"parse_report,omitempty"`
+}
+
+// writeOutbox writes v as JSON to the outbox file name. The file is renamed
+// into place so a crash never leaves half of it behind.
+func (r *Runner) writeOutbox(name string, v any) error {
+	dir := filepath.Join(r.dataFolder, outboxDir)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+// spoolBatch writes batch to the outbox
+func (r *Runner) spoolBatch(batch domain.ResultBatch) error {
+	return r.writeOutbox(fmt.Sprintf("%s_%s.json", batch.JobID, batch.BatchID), batch)
+}
+
+// deferCompletion writes the completion of a run whose results were spooled
+// to the outbox and wakes the flusher, which reports it after them
+func (r *Runner) deferCompletion(jobID uuid.UUID, outcome jobOutcome) error {
+	completion := outboxCompletion{
+		JobID:          jobID,
+		PlacesScraped:  outcome.placesScraped + outcome.spooled,
+		DedupedPlaces:  outcome.dedupedPlaces,
+		FailedKeywords: outcome.failedKeywords,
+		StoppedReason:  outcome.stoppedReason,
+		OutputErrors:   outcome.outputErrors,
+		ParseReport:    outcome.parseReport,
+	}
+	if err := r.writeOutbox(jobID.String()+completionSuffix, completion); err != nil {
+		return err
+	}
+
+	select {
+	case r.outboxKick <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// outboxLoop flushes the outbox as the worker starts, which resumes what an
+// earlier run left, and whenever a run defers its completion. While the
+// manager stays unreachable it tries again with a growing backoff. It
+// returns once the worker stops.
+func (r *Runner) outboxLoop(ctx context.Context) {
+	backoff := outboxRetryMin
+
+	for {
+		var retry <-chan time.Time
+		if r.flushOutbox(ctx) {
+			backoff = outboxRetryMin
+		} else {
+			retry = time.After(backoff)
+			backoff = min(backoff*2, outboxRetryMax)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopChan:
+			return
+		case <-r.outboxKick:
+		case <-retry:
+		}
+	}
+}
+
+// flushOutbox sends the spooled batches, then reports the completions whose
+// batches are all acknowledged. Batches the manager rejects are kept with a
+// .rejected suffix for inspection, and the completion of their run is
+// dropped. It returns false if the manager is still unreachable.
+func (r *Runner) flushOutbox(ctx context.Context) bool {
+	dir := filepath.Join(r.dataFolder, outboxDir)
+
+	batches, _ := filepath.Glob(filepath.Join(dir, "*_*.json"))
+	completions, _ := filepath.Glob(filepath.Join(dir, "*"+completionSuffix))
+	if len(batches) == 0 && len(completions) == 0 {
+		return true
+	}
+
+	r.logger.Info("flushing the outbox", "batches", len(batches), "completions", len(completions))
+
+	for _, path := range batches {
+		var batch domain.ResultBatch
+		if err := readOutbox(path, &batch); err != nil {
+			r.logger.Warn("invalid batch in the outbox", "path", path, "error", err)
+			continue
+		}
+
+		err := r.client.SubmitBatch(r.jobContext(ctx, batch.JobID), batch)
+		switch {
+		case err == nil:
+			_ = os.Remove(path)
+		case errors.Is(err, ErrResultsRejected):
+			_ = os.Rename(path, strings.TrimSuffix(path, ".json")+".rejected")
+		default:
+			// The manager is still unreachable, the rest waits too
+			if ctx.Err() == nil {
+				r.logger.Warn("outbox kept for later", "error", err)
+			}
+			return false
+		}
+	}
+
+	for _, path := range completions {
+		var completion outboxCompletion
+		if err := readOutbox(path, &completion); err != nil {
+			r.logger.Warn("invalid completion in the outbox", "path", path, "error", err)
+			continue
+		}
+
+		jobCtx := r.jobContext(ctx, completion.JobID)
+		logger := logging.FromContext(jobCtx)
+
+		prefix := filepath.Join(dir, completion.JobID.String()+"_*")
+		if pending, _ := filepath.Glob(prefix + ".json"); len(pending) > 0 {
+			continue // A batch could not be read
+		}
+		if rejected, _ := filepath.Glob(prefix + ".rejected"); len(rejected) > 0 {
+			logger.Warn("manager rejected spooled results, dropping the completion", "rejected_batches", len(rejected))
+			_ = os.Remove(path)
+			continue
+		}
+
+		if err := r.client.CompleteJob(jobCtx, completion.JobID, completion.PlacesScraped, completion.DedupedPlaces, completion.FailedKeywords,
+			completion.StoppedReason, completion.OutputErrors, completion.ParseReport); err != nil {
+			if ctx.Err() == nil {
+				logger.Warn("failed to complete job from the outbox", "error", err)
+			}
+			return false
+		}
+
+		logger.Info("job completed from the outbox", "places", completion.PlacesScraped)
+		_ = os.Remove(path)
+	}
+
+	return true
+}
+
+// readOutbox reads the outbox file at path into v. A file that is not valid
+// JSON is renamed with a .rejected suffix, so it is not read again.
+func readOutbox(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		_ = os.Rename(path, strings.TrimSuffix(path, filepath.Ext(path))+".rejected")
+		return err
+	}
+
+	return nil
+}
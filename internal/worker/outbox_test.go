@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/client"
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/runner"
+)
+
+func TestFlushOutbox(t *testing.T) {
+	accepted, rejected := uuid.New(), uuid.New()
+
+	var (
+		mu        sync.Mutex
+		submitted = map[uuid.UUID]int{}
+		completed = map[uuid.UUID]int{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if strings.HasSuffix(req.URL.Path, "/complete") {
+			var body client.CompleteJobRequest
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			completed[body.JobID] = body.PlacesScraped
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		var batch domain.ResultBatch
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&batch))
+		if batch.JobID == rejected {
+			http.Error(w, `{"error":"job finished"}`, http.StatusConflict)
+			return
+		}
+		submitted[batch.JobID] += len(batch.Data)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	r := &Runner{client: NewClient(srv.URL, "w1"), dataFolder: t.TempDir(), outboxKick: make(chan struct{}, 1), logger: slog.Default()}
+
+	for _, jobID := range []uuid.UUID{accepted, rejected} {
+		data := [][]byte{[]byte(`{"title":"a"}`), []byte(`{"title":"b"}`), []byte(`{"title":"c"}`)}
+		for _, batch := range chunkResults(jobID, data, 2, DefaultResultBatchBytes) {
+			require.NoError(t, r.spoolBatch(batch))
+		}
+		require.NoError(t, r.deferCompletion(jobID, jobOutcome{placesScraped: 1, spooled: 3}))
+	}
+
+	assert.True(t, r.flushOutbox(context.Background()))
+
+	assert.Equal(t, map[uuid.UUID]int{accepted: 3}, submitted)
+	assert.Equal(t, map[uuid.UUID]int{accepted: 4}, completed, "acknowledged and spooled places")
+
+	left, err := filepath.Glob(filepath.Join(r.dataFolder, outboxDir, "*"))
+	require.NoError(t, err)
+	require.Len(t, left, 2, "the rejected batches")
+	for _, path := range left {
+		assert.True(t, strings.HasPrefix(filepath.Base(path), rejected.String()+"_"))
+		assert.Equal(t, ".rejected", filepath.Ext(path))
+	}
+
+	_, err = os.Stat(filepath.Join(r.dataFolder, outboxDir, accepted.String()+completionSuffix))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSpooledBatchReplaysWithItsID(t *testing.T) {
+	jobID := uuid.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		stored  = map[uuid.UUID]int{}
+		batches []uuid.UUID
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var batch domain.ResultBatch
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&batch))
+		batches = append(batches, batch.BatchID)
+
+		// The manager answers a batch it stored before without storing it again
+		if _, ok := stored[batch.BatchID]; ok {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		stored[batch.BatchID] = len(batch.Data)
+
+		// The first response gets lost, and the run ends before a retry
+		if len(batches) == 1 {
+			cancel()
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	r := &Runner{client: NewClient(srv.URL, "w1"), config: &runner.Config{}, dataFolder: t.TempDir(), outboxKick: make(chan struct{}, 1), logger: slog.Default()}
+
+	submitted, spooled, err := r.submitResults(ctx, jobID, [][]byte{[]byte(`{"title":"a"}`), []byte(`{"title":"b"}`)})
+	require.NoError(t, err)
+	assert.Equal(t, 0, submitted)
+	assert.Equal(t, 2, spooled)
+
+	assert.True(t, r.flushOutbox(context.Background()))
+
+	require.Len(t, batches, 2)
+	assert.Equal(t, batches[0], batches[1], "the outbox sends the batch with its ID")
+	assert.Equal(t, map[uuid.UUID]int{batches[0]: 2}, stored)
+
+	left, err := filepath.Glob(filepath.Join(r.dataFolder, outboxDir, "*"))
+	require.NoError(t, err)
+	assert.Empty(t, left)
+}
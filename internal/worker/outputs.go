@@ -0,0 +1,311 @@
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gosom/scrapemate"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/s3uploader"
+)
+
+const (
+	// outputFlushTimeout bounds the final flush of an output, after the
+	// scrape ended
+	outputFlushTimeout = 2 * time.Minute
+
+	// s3SpoolBatch is the batch size of S3 outputs, which only bounds the
+	// writes to the spool file
+	s3SpoolBatch = 100
+
+	webhookTimeout  = 30 * time.Second
+	webhookAttempts = 3
+	webhookBackoff  = 2 * time.Second
+)
+
+// outputSink receives the NDJSON lines of a job output in batches
+type outputSink interface {
+	write(ctx context.Context, lines [][]byte) error
+	close(ctx context.Context) error
+}
+
+// OutputWriter is a ResultWriter that writes results to a job output. It
+// never returns an error, which would stop the whole scrape: the first
+// error is kept for Err and the remaining results are drained.
+type OutputWriter struct {
+	output    domain.JobOutput
+	sink      outputSink
+	batchSize int
+
+	mu  sync.Mutex
+	err error
+}
+
+// newOutputWriters creates a writer for every output of the job. Outputs
+// that cannot be set up are returned as errors instead.
+func (r *Runner) newOutputWriters(job *domain.Job) ([]*OutputWriter, []string) {
+	var (
+		writers []*OutputWriter
+		errs    []string
+	)
+
+	for _, output := range job.Config.Outputs {
+		w, err := r.newOutputWriter(job.ID, output)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", output, err))
+			continue
+		}
+		writers = append(writers, w)
+	}
+
+	return writers, errs
+}
+
+func (r *Runner) newOutputWriter(jobID uuid.UUID, output domain.JobOutput) (*OutputWriter, error) {
+	if err := output.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch output.Type {
+	case domain.OutputS3:
+		sink, err := r.newS3Sink(jobID, output)
+		if err != nil {
+			return nil, err
+		}
+		return &OutputWriter{output: output, sink: sink, batchSize: s3SpoolBatch}, nil
+	case domain.OutputWebhook:
+		sink := &webhookSink{
+			url:    output.URL,
+			jobID:  jobID,
+			client: &http.Client{Timeout: webhookTimeout},
+		}
+		return &OutputWriter{output: output, sink: sink, batchSize: output.BatchSize}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", domain.ErrInvalidOutput, output.Type)
+	}
+}
+
+// Run implements scrapemate.ResultWriter
+func (w *OutputWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	// A job that is paused or cancelled still gets the results it produced
+	ctx = context.WithoutCancel(ctx)
+
+	batch := make([][]byte, 0, w.batchSize)
+
+	for result := range in {
+		if w.Err() != nil {
+			continue
+		}
+
+		data, err := json.Marshal(result.Data)
+		if err != nil {
+			w.fail(err)
+			continue
+		}
+		batch = append(batch, data)
+
+		if len(batch) >= w.batchSize {
+			w.fail(w.sink.write(ctx, batch))
+			batch = batch[:0]
+		}
+	}
+
+	flushCtx, cancel := context.WithTimeout(ctx, outputFlushTimeout)
+	defer cancel()
+
+	if w.Err() == nil && len(batch) > 0 {
+		w.fail(w.sink.write(flushCtx, batch))
+	}
+	w.fail(w.sink.close(flushCtx))
+
+	return nil
+}
+
+// Err returns the first error writing to the output
+func (w *OutputWriter) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// fail keeps err if it is the first one
+func (w *OutputWriter) fail(err error) {
+	if err == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// outputErrors describes the outputs that failed, for the manager
+func outputErrors(writers []*OutputWriter) []string {
+	var errs []string
+	for _, w := range writers {
+		if err := w.Err(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", w.output, err))
+		}
+	}
+	return errs
+}
+
+// s3Sink spools the lines to a file and uploads it when the job ends. Each
+// run of a job writes its own object, so a resumed job does not overwrite
+// the results of the run before.
+type s3Sink struct {
+	uploader *s3uploader.Uploader
+	bucket   string
+	key      string
+	dir      string
+
+	file *os.File // Created by the first write
+	buf  *bufio.Writer
+}
+
+func (r *Runner) newS3Sink(jobID uuid.UUID, output domain.JobOutput) (*s3Sink, error) {
+	if r.config.AwsAccessKey == "" || r.config.AwsSecretKey == "" {
+		return nil, errors.New("the worker has no AWS credentials (-aws-access-key, -aws-secret-key)")
+	}
+
+	region := output.Region
+	if region == "" {
+		region = r.config.AwsRegion
+	}
+
+	uploader := s3uploader.New(r.config.AwsAccessKey, r.config.AwsSecretKey, region)
+	if uploader == nil {
+		return nil, errors.New("failed to set up the S3 client")
+	}
+
+	return &s3Sink{
+		uploader: uploader,
+		bucket:   output.Bucket,
+		key:      fmt.Sprintf("%s%s-%s.ndjson", output.Prefix, jobID, time.Now().UTC().Format("20060102T150405Z")),
+		dir:      r.jobDir(jobID),
+	}, nil
+}
+
+func (s *s3Sink) write(_ context.Context, lines [][]byte) error {
+	if s.file == nil {
+		file, err := os.CreateTemp(s.dir, "output-*.ndjson")
+		if err != nil {
+			return fmt.Errorf("failed to create spool file: %w", err)
+		}
+		s.file = file
+		s.buf = bufio.NewWriter(file)
+	}
+
+	for _, line := range lines {
+		if _, err := s.buf.Write(line); err != nil {
+			return err
+		}
+		if err := s.buf.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close uploads the spool file, even when a write failed, and removes it.
+// A run without results uploads an empty object.
+func (s *s3Sink) close(ctx context.Context) error {
+	var body io.Reader = bytes.NewReader(nil)
+
+	if s.file != nil {
+		defer os.Remove(s.file.Name())
+		defer s.file.Close()
+
+		if err := s.buf.Flush(); err != nil {
+			return err
+		}
+		if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		body = s.file
+	}
+
+	if err := s.uploader.Upload(ctx, s.bucket, s.key, body); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", s.key, err)
+	}
+
+	return nil
+}
+
+// webhookSink POSTs every batch as NDJSON. Server errors and network errors
+// are retried a few times.
+type webhookSink struct {
+	url    string
+	jobID  uuid.UUID
+	client *http.Client
+}
+
+func (s *webhookSink) write(ctx context.Context, lines [][]byte) error {
+	body := bytes.Join(lines, []byte("\n"))
+	body = append(body, '\n')
+
+	var err error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		var retry bool
+		if retry, err = s.post(ctx, body); err == nil || !retry {
+			return err
+		}
+
+		if attempt < webhookAttempts {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(webhookBackoff * time.Duration(attempt)):
+			}
+		}
+	}
+
+	return err
+}
+
+// post sends one request and tells whether a failure is worth a retry
+func (s *webhookSink) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("X-Job-ID", s.jobID.String())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// Strip the URL, which may carry a token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook returned %s", resp.Status)
+}
+
+func (s *webhookSink) close(context.Context) error {
+	return nil
+}
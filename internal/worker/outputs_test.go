@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+)
+
+func TestWebhookOutput(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches [][]string
+		status  = http.StatusOK
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+
+		body, _ := io.ReadAll(r.Body)
+		var lines []string
+		scanner := bufio.NewScanner(strings.NewReader(string(body)))
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+
+		mu.Lock()
+		batches = append(batches, lines)
+		w.WriteHeader(status)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	r := &Runner{}
+	jobID := uuid.New()
+
+	run := func(results ...string) (*OutputWriter, *MemoryWriter) {
+		output, err := r.newOutputWriter(jobID, domain.JobOutput{Type: domain.OutputWebhook, URL: srv.URL + "?token=secret", BatchSize: 2})
+		require.NoError(t, err)
+		mem := &MemoryWriter{}
+
+		in := make(chan scrapemate.Result)
+		go func() {
+			for _, res := range results {
+				in <- scrapemate.Result{Data: map[string]string{"title": res}}
+			}
+			close(in)
+		}()
+
+		require.NoError(t, newTeeWriter(mem, output).Run(context.Background(), in))
+		return output, mem
+	}
+
+	output, mem := run("a", "b", "c")
+	require.NoError(t, output.Err())
+	assert.Len(t, mem.GetResults(), 3, "every writer gets every result")
+	assert.Equal(t, [][]string{
+		{`{"title":"a"}`, `{"title":"b"}`},
+		{`{"title":"c"}`},
+	}, batches)
+
+	// A rejected batch is an output error, the other writers carry on
+	mu.Lock()
+	status = http.StatusBadRequest
+	mu.Unlock()
+	output, mem = run("d", "e", "f")
+	assert.Len(t, mem.GetResults(), 3)
+	require.Error(t, output.Err())
+
+	errs := outputErrors([]*OutputWriter{output})
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], "400")
+	assert.NotContains(t, errs[0], "secret")
+	assert.Len(t, batches, 3, "no more batches after the first error")
+}
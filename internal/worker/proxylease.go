@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// leaseDialTimeout is how long a leased proxy has to accept a connection
+// before the job starts
+const leaseDialTimeout = 5 * time.Second
+
+// leaseProxies leases a fresh set of the manager's proxies for a job, with
+// -use-manager-proxies, unless the worker or the job bring their own.
+// Proxies that do not accept a connection are reported and left out. A
+// failed lease is logged; the job then runs as it would without one.
+func (r *Runner) leaseProxies(ctx context.Context, job *domain.Job) {
+	if !r.config.UseManagerProxies || len(r.config.Proxies) > 0 || len(job.Config.Proxies) > 0 {
+		return
+	}
+
+	logger := logging.FromContext(ctx)
+
+	lease, err := r.client.LeaseProxies(ctx, job.Config.ProxyCountry, r.config.ManagerProxyCount)
+	if err != nil {
+		logger.Warn("failed to lease manager proxies", "country", job.Config.ProxyCountry, "error", err)
+		return
+	}
+
+	reachable, failures := dialProxies(ctx, lease.Proxies)
+	if len(failures) > 0 {
+		logger.Warn("leased proxies unreachable", "unreachable", len(failures), "leased", len(lease.Proxies))
+		r.reportProxyFailures(ctx, job, failures)
+	}
+
+	logger.Info("leased manager proxies", "proxies", len(reachable), "expires_at", lease.ExpiresAt)
+
+	r.jobMu.Lock()
+	if rj, ok := r.jobs[job.ID]; ok {
+		rj.proxies = reachable
+	}
+	r.jobMu.Unlock()
+}
+
+// leasedProxies returns the proxies leased for a running job
+func (r *Runner) leasedProxies(job *domain.Job) []string {
+	r.jobMu.RLock()
+	defer r.jobMu.RUnlock()
+
+	if rj, ok := r.jobs[job.ID]; ok {
+		return rj.proxies
+	}
+	return nil
+}
+
+// reportLeasedFailure reports every proxy leased for a job that failed
+// for being blocked or out of proxies, so the manager demotes them
+func (r *Runner) reportLeasedFailure(ctx context.Context, job *domain.Job, code domain.JobErrorCode, cause error) {
+	if code != domain.JobErrorProxyExhausted && code != domain.JobErrorBlockedByGoogle {
+		return
+	}
+
+	proxies := r.leasedProxies(job)
+	if len(proxies) == 0 {
+		return
+	}
+
+	failures := make([]*domain.ProxyFailure, 0, len(proxies))
+	for _, p := range proxies {
+		failures = append(failures, &domain.ProxyFailure{URL: p, Error: cause.Error()})
+	}
+	r.reportProxyFailures(ctx, job, failures)
+}
+
+func (r *Runner) reportProxyFailures(ctx context.Context, job *domain.Job, failures []*domain.ProxyFailure) {
+	if err := r.client.ReportProxyFailures(ctx, &job.ID, failures); err != nil {
+		logging.FromContext(ctx).Warn("failed to report proxy failures", "proxies", len(failures), "error", err)
+	}
+}
+
+// dialProxies checks that each leased proxy accepts a TCP connection,
+// all at once, and splits them into the reachable ones and failures
+func dialProxies(ctx context.Context, leased []*domain.LeasedProxy) ([]string, []*domain.ProxyFailure) {
+	errs := make([]error, len(leased))
+
+	var wg sync.WaitGroup
+	for i, p := range leased {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = dialProxy(ctx, p.URL)
+		}()
+	}
+	wg.Wait()
+
+	var (
+		reachable []string
+		failures  []*domain.ProxyFailure
+	)
+	for i, p := range leased {
+		if errs[i] != nil {
+			failures = append(failures, &domain.ProxyFailure{URL: p.URL, Error: errs[i].Error()})
+			continue
+		}
+		reachable = append(reachable, p.URL)
+	}
+
+	return reachable, failures
+}
+
+func dialProxy(ctx context.Context, proxyURL string) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: leaseDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
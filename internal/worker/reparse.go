@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/gmaps"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// Reparse parses every cached place page again with the current parser and
+// submits the entries as results of jobID, so parser fixes reach old pages
+// without fetching them again. Reviews fetched apart from the page and
+// emails from the place's website are not in the cache, so the entries
+// lack them. The cache is closed when Reparse returns.
+func (r *Runner) Reparse(ctx context.Context, jobID uuid.UUID) error {
+	if r.pageCache == nil {
+		return errors.New("reparse needs the page cache, set -cache")
+	}
+	defer r.pageCache.Close()
+
+	ctx = r.jobContext(ctx, jobID)
+	logger := logging.FromContext(ctx)
+
+	job, err := r.client.GetJob(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to get job %s: %w", jobID, err)
+	}
+	if job == nil {
+		return fmt.Errorf("job %s not found", jobID)
+	}
+
+	maxImages := job.Config.MaxImages
+	if maxImages <= 0 {
+		maxImages = gmaps.DefaultMaxImages
+	}
+
+	var (
+		results [][]byte
+		pages   int
+		failed  int
+	)
+
+	err = r.pageCache.Each(func(placeURL string, raw []byte) error {
+		pages++
+
+		entry, err := gmaps.EntryFromJSON(raw)
+		if err != nil {
+			failed++
+			logger.Warn("failed to parse cached page", "url", placeURL, "error", err)
+			return nil
+		}
+
+		// As PlaceJob.Process does for a fetched page
+		entry.ID = jobID.String()
+		if entry.Link == "" {
+			entry.Link = placeURL
+		}
+		if len(entry.ImageURLs) > maxImages {
+			entry.ImageURLs = entry.ImageURLs[:maxImages]
+		}
+		entry.ParseOpenHours(job.Config.Lang)
+
+		data, err := json.Marshal(&entry)
+		if err != nil {
+			return err
+		}
+		results = append(results, data)
+
+		return ctx.Err()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read page cache: %w", err)
+	}
+
+	logger.Info("cached pages parsed", "pages", pages, "failed", failed)
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	submitted, spooled, err := r.submitResults(ctx, jobID, results)
+	if err != nil {
+		return fmt.Errorf("failed to submit results: %w", err)
+	}
+	if spooled > 0 {
+		return fmt.Errorf("failed to submit results: %d results spooled to the outbox, sent when the worker starts next", spooled)
+	}
+
+	logger.Info("reparsed results submitted", "results", submitted)
+
+	return nil
+}
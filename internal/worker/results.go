@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/logging"
+)
+
+// Results are submitted in batches of at most this many entries or bytes,
+// whichever limit is reached first
+const (
+	DefaultResultBatchSize  = 500
+	DefaultResultBatchBytes = 5 << 20
+)
+
+// chunkResults splits data into batches, each with its own ID. Sizes are
+// estimated as they are sent: every entry is base64 encoded in a JSON array.
+// An entry larger than maxBytes gets a batch of its own.
+func chunkResults(jobID uuid.UUID, data [][]byte, maxEntries, maxBytes int) []domain.ResultBatch {
+	var (
+		batches []domain.ResultBatch
+		current [][]byte
+		size    int
+	)
+
+	flush := func() {
+		if len(current) > 0 {
+			batches = append(batches, domain.ResultBatch{JobID: jobID, BatchID: uuid.New(), Data: current})
+			current, size = nil, 0
+		}
+	}
+
+	for _, d := range data {
+		n := base64.StdEncoding.EncodedLen(len(d)) + 3
+		if len(current) >= maxEntries || (len(current) > 0 && size+n > maxBytes) {
+			flush()
+		}
+		current = append(current, d)
+		size += n
+	}
+	flush()
+
+	return batches
+}
+
+// submitResults submits data in batches and returns how many results the
+// manager acknowledged and how many it put in the outbox. When the manager
+// stays unreachable the remaining batches are spooled to the outbox, which
+// sends them once it is back; only a failure to spool them is an error then.
+func (r *Runner) submitResults(ctx context.Context, jobID uuid.UUID, data [][]byte) (submitted, spooled int, err error) {
+	maxEntries, maxBytes := r.config.ResultBatchSize, r.config.ResultBatchBytes
+	if maxEntries <= 0 {
+		maxEntries = DefaultResultBatchSize
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultResultBatchBytes
+	}
+
+	batches := chunkResults(jobID, data, maxEntries, maxBytes)
+
+	for i, batch := range batches {
+		err := r.client.SubmitBatch(ctx, batch)
+		if err == nil {
+			submitted += len(batch.Data)
+			continue
+		}
+		if errors.Is(err, ErrResultsRejected) {
+			return submitted, 0, err
+		}
+
+		logging.FromContext(ctx).Warn("manager unreachable, spooling results to the outbox", "batches", len(batches)-i, "error", err)
+
+		for _, b := range batches[i:] {
+			if serr := r.spoolBatch(b); serr != nil {
+				return submitted, 0, fmt.Errorf("%w (spooling failed: %v)", err, serr)
+			}
+			spooled += len(b.Data)
+		}
+
+		return submitted, spooled, nil
+	}
+
+	return submitted, 0, nil
+}
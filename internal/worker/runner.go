@@ -5,21 +5,32 @@ import (
 	"encoding/csv"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/sync/semaphore"
 
 	"github.com/sadewadee/google-scraper/deduper"
 	"github.com/sadewadee/google-scraper/exiter"
+	"github.com/sadewadee/google-scraper/gmaps"
 	"github.com/sadewadee/google-scraper/internal/domain"
 	"github.com/sadewadee/google-scraper/internal/emailvalidator"
+	"github.com/sadewadee/google-scraper/internal/events"
+	"github.com/sadewadee/google-scraper/internal/logging"
 	"github.com/sadewadee/google-scraper/internal/mq"
+	"github.com/sadewadee/google-scraper/internal/proxygate"
 	"github.com/sadewadee/google-scraper/internal/queue"
+	"github.com/sadewadee/google-scraper/pagecache"
+	"github.com/sadewadee/google-scraper/ratelimit"
 	"github.com/sadewadee/google-scraper/runner"
 	"github.com/gosom/scrapemate"
 	"github.com/gosom/scrapemate/adapters/writers/csvwriter"
@@ -36,6 +47,14 @@ type Config struct {
 	RedisPass    string
 	RedisDB      int
 	RabbitMQURL  string // RabbitMQ URL (preferred over Redis for job queue)
+
+	// ManagerGRPCURL is the manager's gRPC address (host:port); the worker
+	// talks gRPC when set and falls back to ManagerURL over HTTP
+	ManagerGRPCURL string
+
+	// Deduper remembers the places of a job. An empty or redis backend
+	// shares them through Redis when configured, else keeps them in a map.
+	Deduper deduper.Config
 }
 
 // Runner is a worker that claims and processes jobs from the manager
@@ -43,18 +62,51 @@ type Runner struct {
 	client       *Client
 	config       *runner.Config
 	dataFolder   string
-	currentJob   *domain.Job
-	jobMu        sync.RWMutex // Protects currentJob
+	jobs         map[uuid.UUID]*runningJob // Jobs being handled, reported in heartbeats
+	jobMu        sync.RWMutex              // Protects jobs
+	active       atomic.Int32              // Handlers running, with a job or not
+	handlers     sync.WaitGroup            // Done as active drops to zero
+	slots        chan struct{}             // Polling mode: one per job run at a time
+	contexts     *semaphore.Weighted       // Browser contexts open across jobs
+	jobContexts  int                       // Browser contexts of a job, its scraper's concurrency
 	stopChan     chan struct{}
 	stopOnce     sync.Once
 	workerID     string
 	queueWorker  *queue.Worker
 	useRedis     bool
 	redisDeduper *queue.Deduper
+	messages     *messageLeases // Queue messages being handled
 	mqConsumer   *mq.RabbitMQConsumer
 	useRabbitMQ  bool
+	limiter      ratelimit.Limiter    // Shared by all jobs so block signals slow the whole worker down
+	statusEvents events.Broker        // Job status changes pushed by the manager, nil without Redis
+	pushedStops  *events.MemoryBroker // Job stops pushed on the gRPC heartbeat stream, nil without gRPC
+	pageCache    *pagecache.Cache     // Raw place pages, nil without -cache
+	dedupe       deduper.Config       // Local deduper of each job, unless Redis is used
+	browsers     *browserPool         // Warm browsers kept between jobs, nil without -worker-browser-pool
+	logger       *slog.Logger         // Tags every line with the worker ID
+	longPoll     atomic.Bool          // The manager held the last claim until a job turned up
+	outboxKick   chan struct{}        // Wakes the outbox flusher
+
+	// Drain, asked by the manager in a heartbeat response
+	draining      atomic.Bool
+	drainDeadline atomic.Int64       // Unix nanoseconds, 0 for none
+	exit          context.CancelFunc // Ends Run
 }
 
+// runningJob is a job the worker handles
+type runningJob struct {
+	job      *domain.Job
+	started  time.Time
+	progress exiter.Exiter // Progress of the job's scrape, reported in heartbeats
+	stop     func()        // Stops the job's scrape for a drain
+	proxies  []string      // Leased from the manager, with -use-manager-proxies
+}
+
+// stopTimeout is how long Stop waits for the jobs still running to report
+// before it releases them
+const stopTimeout = 30 * time.Second
+
 // NewRunner creates a new worker runner
 func NewRunner(cfg *Config) (*Runner, error) {
 	if cfg.RunnerConfig == nil {
@@ -69,32 +121,69 @@ func NewRunner(cfg *Config) (*Runner, error) {
 		return nil, err
 	}
 
+	if err := cfg.Deduper.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Each job scrapes with -c browser contexts, fewer when the machine
+	// cap is lower; a job waits for its contexts while others hold them
+	jobConcurrency := max(cfg.RunnerConfig.WorkerJobConcurrency, 1)
+	jobContexts := max(cfg.RunnerConfig.Concurrency, 1)
+	maxContexts := cfg.RunnerConfig.WorkerMaxBrowserContexts
+	if maxContexts <= 0 {
+		maxContexts = jobConcurrency * jobContexts
+	}
+	jobContexts = min(jobContexts, maxContexts)
+
 	r := &Runner{
 		client:      NewClient(cfg.ManagerURL, cfg.WorkerID),
 		config:      cfg.RunnerConfig,
 		dataFolder:  cfg.RunnerConfig.DataFolder,
+		jobs:        make(map[uuid.UUID]*runningJob),
+		slots:       make(chan struct{}, jobConcurrency),
+		contexts:    semaphore.NewWeighted(int64(maxContexts)),
+		jobContexts: jobContexts,
 		workerID:    cfg.WorkerID,
 		stopChan:    make(chan struct{}),
+		outboxKick:  make(chan struct{}, 1),
 		useRedis:    false,
 		useRabbitMQ: false,
+		limiter:     ratelimit.New(ratelimit.DefaultConfig()),
+		dedupe:      cfg.Deduper,
+		logger:      slog.Default().With("component", "Worker", "worker_id", cfg.WorkerID),
+	}
+
+	// Until a claim tells otherwise, the manager is taken to hold claims
+	r.longPoll.Store(true)
+
+	if jobConcurrency > 1 {
+		r.logger.Info("running jobs concurrently", "jobs", jobConcurrency, "contexts_per_job", jobContexts, "max_contexts", maxContexts)
+	}
+
+	if cfg.ManagerGRPCURL != "" {
+		r.pushedStops = events.NewMemoryBroker()
+		if err := r.client.SetGRPC(cfg.ManagerGRPCURL, r.applyDirectives); err != nil {
+			return nil, err
+		}
+		r.logger.Info("talking to the manager over gRPC", "target", cfg.ManagerGRPCURL)
 	}
 
 	// Try to set up RabbitMQ consumer (preferred over Redis for job queue)
 	if cfg.RabbitMQURL != "" {
 		consumerCfg := mq.ConsumerConfig{
-			URL:        cfg.RabbitMQURL,
-			Prefetch:   1, // Process one job at a time per worker
-			ConsumerID: cfg.WorkerID,
+			URL:         cfg.RabbitMQURL,
+			Prefetch:    jobConcurrency, // Hold no more jobs than the worker runs
+			Concurrency: jobConcurrency,
+			ConsumerID:  cfg.WorkerID,
 		}
 
 		consumer, err := mq.NewConsumer(consumerCfg)
 		if err != nil {
-			log.Printf("WARNING: failed to connect to RabbitMQ: %v", err)
-			log.Println("falling back to Redis queue or HTTP polling mode")
+			r.logger.Warn("failed to connect to RabbitMQ, falling back to Redis queue or HTTP polling mode", "error", err)
 		} else {
 			r.mqConsumer = consumer
 			r.useRabbitMQ = true
-			log.Println("RabbitMQ consumer initialized")
+			r.logger.Info("RabbitMQ consumer initialized")
 		}
 	}
 
@@ -106,17 +195,16 @@ func NewRunner(cfg *Config) (*Runner, error) {
 			RedisAddr:   cfg.RedisAddr,
 			Password:    cfg.RedisPass,
 			DB:          cfg.RedisDB,
-			Concurrency: 1, // Process one job at a time per worker
+			Concurrency: jobConcurrency,
 		}
 
 		qw, err := queue.NewWorker(queueCfg, r.handleQueueJob)
 		if err != nil {
-			log.Printf("WARNING: failed to connect to Redis queue: %v", err)
-			log.Println("falling back to HTTP polling mode")
+			r.logger.Warn("failed to connect to Redis queue, falling back to HTTP polling mode", "error", err)
 		} else {
 			r.queueWorker = qw
 			r.useRedis = true
-			log.Println("Redis queue worker initialized")
+			r.logger.Info("Redis queue worker initialized")
 		}
 
 		// Initialize Redis deduper for distributed deduplication
@@ -126,69 +214,235 @@ func NewRunner(cfg *Config) (*Runner, error) {
 			Password:  cfg.RedisPass,
 			DB:        cfg.RedisDB,
 			Prefix:    "dedup",
+			TTL:       cfg.RunnerConfig.DedupeTTL,
 		}
 
 		dedup, err := queue.NewDeduper(dedupCfg)
 		if err != nil {
-			log.Printf("WARNING: failed to connect to Redis deduper: %v", err)
-			log.Println("using local in-memory deduplication")
+			r.logger.Warn("failed to connect to Redis deduper, using local in-memory deduplication", "error", err)
 		} else {
 			r.redisDeduper = dedup
-			log.Println("Redis distributed deduplication initialized")
+			r.logger.Info("Redis distributed deduplication initialized")
+		}
+
+		// Subscribe to job events so a pause or cancel stops work right away
+		broker, err := events.NewRedisBroker(events.RedisConfig{
+			RedisURL:  cfg.RedisURL,
+			RedisAddr: cfg.RedisAddr,
+			Password:  cfg.RedisPass,
+			DB:        cfg.RedisDB,
+		})
+		if err != nil {
+			r.logger.Warn("failed to subscribe to job events, checking job status by polling only", "error", err)
+		} else {
+			r.statusEvents = broker
+		}
+	}
+
+	var sharedLeases *queue.Deduper
+	if r.redisDeduper != nil {
+		sharedLeases = r.redisDeduper.Scope("messages")
+	}
+	r.messages = newMessageLeases(sharedLeases, r.logger)
+
+	// Browsers stay up between jobs, in whole jobs' worth of contexts
+	if n := cfg.RunnerConfig.WorkerBrowserPool; n > 0 {
+		pageReuse := 200
+		if cfg.RunnerConfig.DisablePageReuse {
+			pageReuse = 0
+		}
+		r.browsers = newBrowserPool(browserPoolConfig{
+			MaxIdle:        max(1, n/jobContexts),
+			Contexts:       jobContexts,
+			PageReuseLimit: pageReuse,
+			Recycle:        cfg.RunnerConfig.WorkerBrowserRecycle,
+			MaxMemPercent:  cfg.RunnerConfig.WorkerBrowserPoolMaxMem,
+		}, r.logger)
+		r.logger.Info("keeping browsers warm between jobs", "jobs", max(1, n/jobContexts), "contexts_per_job", jobContexts)
+	}
+
+	// The cache only saves bandwidth later, a worker without it still works
+	if dir := cfg.RunnerConfig.CacheDir; dir != "" {
+		cache, err := pagecache.Open(pagecache.Config{
+			Dir:      dir,
+			TTL:      cfg.RunnerConfig.CacheTTL,
+			MaxBytes: int64(cfg.RunnerConfig.CacheMaxMB) << 20,
+		})
+		if err != nil {
+			r.logger.Warn("failed to open page cache, place pages are not cached", "dir", dir, "error", err)
+		} else {
+			r.pageCache = cache
+			r.logger.Info("page cache opened", "dir", dir, "pages", cache.Len(), "bytes", cache.Size())
 		}
 	}
 
 	return r, nil
 }
 
-// setCurrentJob safely sets the current job with locking
-func (r *Runner) setCurrentJob(job *domain.Job) {
+// addJob adds a job the worker handles
+func (r *Runner) addJob(job *domain.Job) {
 	r.jobMu.Lock()
-	r.currentJob = job
+	r.jobs[job.ID] = &runningJob{job: job, started: time.Now()}
 	r.jobMu.Unlock()
 }
 
-// getCurrentJob safely gets the current job with locking
-func (r *Runner) getCurrentJob() *domain.Job {
+// removeJob removes a job once it was handled
+func (r *Runner) removeJob(jobID uuid.UUID) {
+	r.jobMu.Lock()
+	delete(r.jobs, jobID)
+	r.jobMu.Unlock()
+}
+
+// setProgress sets the progress of a job reported in heartbeats
+func (r *Runner) setProgress(jobID uuid.UUID, e exiter.Exiter) {
+	r.jobMu.Lock()
+	if rj := r.jobs[jobID]; rj != nil {
+		rj.progress = e
+	}
+	r.jobMu.Unlock()
+}
+
+// setStopJob sets the function that stops a job for a drain
+func (r *Runner) setStopJob(jobID uuid.UUID, stop func()) {
+	r.jobMu.Lock()
+	if rj := r.jobs[jobID]; rj != nil {
+		rj.stop = stop
+	}
+	r.jobMu.Unlock()
+}
+
+// runningJobs returns the jobs the worker handles, oldest first. The
+// entries are copies.
+func (r *Runner) runningJobs() []runningJob {
 	r.jobMu.RLock()
-	defer r.jobMu.RUnlock()
-	return r.currentJob
+	jobs := make([]runningJob, 0, len(r.jobs))
+	for _, rj := range r.jobs {
+		jobs = append(jobs, *rj)
+	}
+	r.jobMu.RUnlock()
+
+	slices.SortFunc(jobs, func(a, b runningJob) int {
+		return a.started.Compare(b.started)
+	})
+
+	return jobs
 }
 
-// Run starts the worker
+// handling counts a handler as running until the returned function is
+// called
+func (r *Runner) handling() func() {
+	r.handlers.Add(1)
+	r.active.Add(1)
+
+	return func() {
+		r.active.Add(-1)
+		r.handlers.Done()
+	}
+}
+
+// jobDir is the data folder of a job: its CSV and the spool files of its
+// outputs, apart from the other jobs the worker runs
+func (r *Runner) jobDir(jobID uuid.UUID) string {
+	return filepath.Join(r.dataFolder, jobID.String())
+}
+
+// placeJobs builds the seed jobs of a job that scrapes a list of places
+func (r *Runner) placeJobs(job *domain.Job, e exiter.Exiter, ev emailvalidator.Validator, pageCache gmaps.PageCache) ([]scrapemate.IJob, error) {
+	return runner.CreatePlaceJobs(runner.SeedJobConfig{
+		LangCode:       job.Config.Lang,
+		Email:          job.Config.ExtractEmail,
+		ExtraReviews:   r.config.ExtraReviews,
+		MaxReviews:     job.Config.MaxReviews,
+		ReviewsSort:    gmaps.ReviewSort(job.Config.ReviewsSort),
+		MaxImages:      job.Config.MaxImages,
+		Headers:        job.Config.RequestHeaders(),
+		ExitMonitor:    e,
+		EmailValidator: ev,
+		RateLimiter:    r.limiter,
+		PageCache:      pageCache,
+		EmailPages:     r.config.EmailPages,
+	}, job.Config.PlaceURLs)
+}
+
+// jobContext returns ctx with a logger that tags every line with the job
+// and this worker
+func (r *Runner) jobContext(ctx context.Context, jobID uuid.UUID) context.Context {
+	return logging.NewContext(ctx, r.logger.With("job_id", jobID))
+}
+
+// Run starts the worker. It returns nil once the worker has drained, after
+// unregistering.
 func (r *Runner) Run(ctx context.Context) error {
+	ctx, r.exit = context.WithCancel(ctx)
+	defer r.exit()
+
 	// Register with manager
 	worker, err := r.client.Register(ctx)
 	if err != nil {
 		return err
 	}
 
-	log.Printf("worker registered: %s (hostname: %s)", worker.ID, worker.Hostname)
+	r.logger.Info("worker registered", "hostname", worker.Hostname)
+
+	// Delivers what the manager could not be reached for, starting with
+	// what a previous run left
+	go r.outboxLoop(ctx)
 
 	// Start heartbeat goroutine
 	go r.heartbeatLoop(ctx)
 
+	err = r.consume(ctx)
+
+	if r.draining.Load() {
+		r.logger.Info("worker drained")
+		// Our caller cancels ctx before stopping the worker, too early to
+		// unregister
+		return r.Stop(context.WithoutCancel(ctx))
+	}
+
+	return err
+}
+
+// consume processes jobs until ctx is done
+func (r *Runner) consume(ctx context.Context) error {
 	// Use RabbitMQ if available (preferred), then Redis, then HTTP polling
 	if r.useRabbitMQ && r.mqConsumer != nil {
-		log.Println("starting RabbitMQ consumer mode")
+		r.logger.Info("starting RabbitMQ consumer mode")
 		return r.mqConsumer.Consume(ctx, r.handleMQJob)
 	}
 
 	if r.useRedis && r.queueWorker != nil {
-		log.Println("starting Redis queue worker mode")
+		r.logger.Info("starting Redis queue worker mode")
 		return r.queueWorker.Run(ctx)
 	}
 
-	log.Println("starting HTTP polling mode")
+	r.logger.Info("starting HTTP polling mode")
 	return r.workLoop(ctx)
 }
 
-// Stop gracefully stops the worker
+// Stop gracefully stops the worker. Jobs still running, which stop with the
+// context of Run, get up to stopTimeout to report before they are released.
 func (r *Runner) Stop(ctx context.Context) error {
 	r.stopOnce.Do(func() {
 		close(r.stopChan)
+		r.stop(ctx)
 	})
 
+	return nil
+}
+
+func (r *Runner) stop(ctx context.Context) {
+	idle := make(chan struct{})
+	go func() {
+		r.handlers.Wait()
+		close(idle)
+	}()
+	select {
+	case <-idle:
+	case <-time.After(stopTimeout):
+		r.logger.Warn("jobs still running, releasing them", "jobs", r.active.Load())
+	}
+
 	// Close RabbitMQ consumer if active
 	if r.mqConsumer != nil {
 		r.mqConsumer.Close()
@@ -204,95 +458,126 @@ func (r *Runner) Stop(ctx context.Context) error {
 		r.redisDeduper.Close()
 	}
 
-	// Release current job if any
-	if currentJob := r.getCurrentJob(); currentJob != nil {
-		if err := r.client.ReleaseJob(ctx, currentJob.ID); err != nil {
-			log.Printf("warning: failed to release job: %v", err)
+	// Stop listening for job events
+	if r.statusEvents != nil {
+		r.statusEvents.Close()
+	}
+	if r.pushedStops != nil {
+		r.pushedStops.Close()
+	}
+
+	// Jobs still running close their browsers when they hand them back
+	if r.browsers != nil {
+		r.browsers.Close()
+	}
+
+	// Write the queued pages and the index for the next start
+	if r.pageCache != nil {
+		if err := r.pageCache.Close(); err != nil {
+			r.logger.Warn("failed to close page cache", "error", err)
+		}
+	}
+
+	// Release the jobs still running
+	for _, rj := range r.runningJobs() {
+		if err := r.client.ReleaseJob(ctx, rj.job.ID); err != nil {
+			r.logger.Warn("failed to release job", "job_id", rj.job.ID, "error", err)
 		}
 	}
 
 	// Unregister from manager
 	if err := r.client.Unregister(ctx); err != nil {
-		log.Printf("warning: failed to unregister: %v", err)
+		r.logger.Warn("failed to unregister", "error", err)
 	}
 
-	return nil
+	if err := r.client.Close(); err != nil {
+		r.logger.Warn("failed to close gRPC connection", "error", err)
+	}
 }
 
 // handleMQJob is called by the RabbitMQ consumer for each job
 func (r *Runner) handleMQJob(ctx context.Context, msg *mq.JobMessage) error {
-	log.Printf("received job from RabbitMQ: %s", msg.JobID)
+	ctx = r.jobContext(ctx, msg.JobID)
+	logger := logging.FromContext(ctx)
+	logger.Info("received job from RabbitMQ")
+
+	defer r.handling()()
+
+	if r.draining.Load() {
+		return mq.ErrRequeue
+	}
+
+	release, ok := r.messages.acquire(ctx, msg.MessageID)
+	if !ok {
+		logger.Info("skipping message another handler has", "message_id", msg.MessageID)
+		return nil
+	}
+	defer release()
 
 	// Fetch full job details from manager
 	job, err := r.fetchJobDetails(ctx, msg.JobID)
 	if err != nil {
-		log.Printf("failed to fetch job details: %v", err)
+		logger.Error("failed to fetch job details", "error", err)
 		return err
 	}
 
 	if job == nil {
-		log.Printf("job %s not found or already completed", msg.JobID)
 		return nil // Not an error, job may have been cancelled
 	}
 
-	r.setCurrentJob(job)
+	r.addJob(job)
 
 	// Process the job
-	placesScraped, err := r.processJob(ctx, job)
-	if err != nil {
-		log.Printf("job failed: %s - %v", job.ID, err)
-		if failErr := r.client.FailJob(ctx, job.ID, err.Error()); failErr != nil {
-			log.Printf("warning: failed to mark job as failed: %v", failErr)
-		}
-		r.setCurrentJob(nil)
-		return err
-	}
+	outcome, err := r.processJob(ctx, job)
+	err = r.finishJob(ctx, job, outcome, err)
 
-	log.Printf("job completed: %s (%d places)", job.ID, placesScraped)
-	if completeErr := r.client.CompleteJob(ctx, job.ID, placesScraped); completeErr != nil {
-		log.Printf("warning: failed to mark job as completed: %v", completeErr)
-	}
+	r.removeJob(job.ID)
 
-	r.setCurrentJob(nil)
-	return nil
+	if errors.Is(err, errDrained) {
+		return fmt.Errorf("%w: %w", mq.ErrRequeue, err)
+	}
+	return err
 }
 
 // handleQueueJob is called by the Redis queue worker for each job
 func (r *Runner) handleQueueJob(ctx context.Context, payload *queue.JobPayload) error {
-	log.Printf("received job from Redis queue: %s", payload.JobID)
+	ctx = r.jobContext(ctx, payload.JobID)
+	logger := logging.FromContext(ctx)
+	logger.Info("received job from Redis queue")
+
+	defer r.handling()()
+
+	// Returned to asynq, which retries the task on another worker
+	if r.draining.Load() {
+		return errDrained
+	}
+
+	release, ok := r.messages.acquire(ctx, payload.MessageID)
+	if !ok {
+		logger.Info("skipping message another handler has", "message_id", payload.MessageID)
+		return nil
+	}
+	defer release()
 
 	// Fetch full job details from manager
 	job, err := r.fetchJobDetails(ctx, payload.JobID)
 	if err != nil {
-		log.Printf("failed to fetch job details: %v", err)
+		logger.Error("failed to fetch job details", "error", err)
 		return err
 	}
 
 	if job == nil {
-		log.Printf("job %s not found or already completed", payload.JobID)
 		return nil // Not an error, job may have been cancelled
 	}
 
-	r.setCurrentJob(job)
+	r.addJob(job)
 
 	// Process the job
-	placesScraped, err := r.processJob(ctx, job)
-	if err != nil {
-		log.Printf("job failed: %s - %v", job.ID, err)
-		if failErr := r.client.FailJob(ctx, job.ID, err.Error()); failErr != nil {
-			log.Printf("warning: failed to mark job as failed: %v", failErr)
-		}
-		r.setCurrentJob(nil)
-		return err
-	}
+	outcome, err := r.processJob(ctx, job)
+	err = r.finishJob(ctx, job, outcome, err)
 
-	log.Printf("job completed: %s (%d places)", job.ID, placesScraped)
-	if completeErr := r.client.CompleteJob(ctx, job.ID, placesScraped); completeErr != nil {
-		log.Printf("warning: failed to mark job as completed: %v", completeErr)
-	}
-
-	r.setCurrentJob(nil)
-	return nil
+	r.removeJob(job.ID)
+	return err
 }
 
 // fetchJobDetails fetches full job details from the manager API by job ID
@@ -304,13 +589,25 @@ func (r *Runner) fetchJobDetails(ctx context.Context, jobID uuid.UUID) (*domain.
 	}
 
 	if job == nil {
-		log.Printf("job %s not found (may have been cancelled)", jobID)
+		logging.FromContext(ctx).Info("job not found, it may have been cancelled")
+		return nil, nil
+	}
+
+	// A job deleted after it was enqueued is not run
+	if job.DeletedAt != nil {
+		logging.FromContext(ctx).Info("skipping deleted job")
+		return nil, nil
+	}
+
+	// A sharded job runs through its shards, enqueued on their own
+	if job.ShardCount > 0 {
+		logging.FromContext(ctx).Info("skipping sharded job", "shards", job.ShardCount)
 		return nil, nil
 	}
 
 	// Verify job is in a processable state
 	if job.Status != domain.JobStatusPending && job.Status != domain.JobStatusRunning {
-		log.Printf("job %s has status %s, skipping", jobID, job.Status)
+		logging.FromContext(ctx).Info("skipping job", "status", job.Status)
 		return nil, nil
 	}
 
@@ -328,76 +625,257 @@ func (r *Runner) heartbeatLoop(ctx context.Context) {
 		case <-r.stopChan:
 			return
 		case <-ticker.C:
-			status := domain.WorkerStatusIdle
-			var jobID *uuid.UUID
-
-			if currentJob := r.getCurrentJob(); currentJob != nil {
-				status = domain.WorkerStatusBusy
-				jobID = &currentJob.ID
+			directives, err := r.client.Heartbeat(ctx, r.heartbeat())
+			if err != nil {
+				r.logger.Warn("heartbeat failed", "error", err)
+				continue
 			}
+			r.applyDirectives(directives)
+		}
+	}
+}
 
-			if err := r.client.Heartbeat(ctx, status, jobID); err != nil {
-				log.Printf("warning: heartbeat failed: %v", err)
-			}
+// heartbeat is the state of the worker reported to the manager
+func (r *Runner) heartbeat() domain.WorkerHeartbeat {
+	hb := domain.WorkerHeartbeat{
+		Status:         domain.WorkerStatusIdle,
+		RequestDelayMs: r.limiter.Delay().Milliseconds(),
+		BlockCount:     r.limiter.Blocks(),
+	}
+
+	for i, rj := range r.runningJobs() {
+		if i == 0 {
+			hb.Status = domain.WorkerStatusBusy
+			hb.CurrentJobID = &rj.job.ID
+			hb.CurrentJobName = rj.job.Name
 		}
+
+		job := domain.WorkerJob{JobID: rj.job.ID, JobName: rj.job.Name}
+		if rj.progress != nil {
+			p := rj.progress.Progress()
+			job.SeedJobsCompleted = p.SeedCompleted
+			job.SeedJobsTotal = p.SeedCount
+			job.PlacesScraped = p.PlacesCompleted
+		}
+		hb.Jobs = append(hb.Jobs, job)
+
+		hb.SeedJobsCompleted += job.SeedJobsCompleted
+		hb.SeedJobsTotal += job.SeedJobsTotal
+		hb.JobPlacesScraped += job.PlacesScraped
 	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	hb.MemoryBytes = int64(mem.Sys)
+
+	return hb
 }
 
+// claimWait is how long the manager may hold a claim waiting for a job,
+// below the response header timeout of the client
+const claimWait = 20 * time.Second
+
+// workLoop claims a job while fewer than -worker-job-concurrency run, and
+// returns once the jobs it started ended. A manager holding claims until
+// a job turns up paces the loop, which claims again as soon as a claim is
+// answered; otherwise it claims every second.
 func (r *Runner) workLoop(ctx context.Context) error {
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	claimNow := false
 	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case <-r.stopChan:
-			return nil
-		case <-ticker.C:
-			// Try to claim a job
-			job, err := r.client.ClaimJob(ctx)
-			if err != nil {
-				log.Printf("error claiming job: %v", err)
-				continue
+		if !claimNow {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-r.stopChan:
+				return nil
+			case <-ticker.C:
 			}
+		}
+		claimNow = false
 
-			if job == nil {
-				// No pending jobs
-				continue
+		select {
+		case r.slots <- struct{}{}:
+		default:
+			continue // As many jobs as the worker runs
+		}
+
+		claimed := make(chan struct{})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-r.slots }()
+			r.claimAndProcess(ctx, claimed)
+		}()
+
+		if r.longPoll.Load() {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-r.stopChan:
+				return nil
+			case <-claimed:
 			}
+			claimNow = r.longPoll.Load()
+		}
+	}
+}
 
-			r.setCurrentJob(job)
-			log.Printf("claimed job: %s (%s)", job.Name, job.ID)
+// claimAndProcess claims a job, unless the worker is draining, and runs it.
+// claimed is closed once the claim is answered.
+func (r *Runner) claimAndProcess(ctx context.Context, claimed chan<- struct{}) {
+	defer r.handling()()
 
-			// Process the job
-			placesScraped, err := r.processJob(ctx, job)
-			if err != nil {
-				log.Printf("job failed: %s - %v", job.ID, err)
-				if failErr := r.client.FailJob(ctx, job.ID, err.Error()); failErr != nil {
-					log.Printf("warning: failed to mark job as failed: %v", failErr)
-				}
-			} else {
-				log.Printf("job completed: %s (%d places)", job.ID, placesScraped)
-				if completeErr := r.client.CompleteJob(ctx, job.ID, placesScraped); completeErr != nil {
-					log.Printf("warning: failed to mark job as completed: %v", completeErr)
-				}
-			}
+	job, err := r.claim(ctx)
+	close(claimed)
+	if err != nil {
+		r.logger.Error("failed to claim job", "error", err)
+		return
+	}
+
+	if job == nil {
+		// No pending jobs
+		return
+	}
+
+	r.addJob(job)
+	jobCtx := r.jobContext(ctx, job.ID)
+	logging.FromContext(jobCtx).Info("claimed job", "name", job.Name)
+
+	// Process the job
+	outcome, err := r.processJob(jobCtx, job)
+	_ = r.finishJob(jobCtx, job, outcome, err)
+
+	r.removeJob(job.ID)
+}
+
+// claim claims a job, unless the worker is draining, letting the manager
+// hold the claim up to claimWait. A stop ends the wait, and a job claimed
+// as the worker stops or starts draining is released.
+func (r *Runner) claim(ctx context.Context) (*domain.Job, error) {
+	if r.draining.Load() {
+		return nil, nil
+	}
+
+	claimCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-r.stopChan:
+			cancel()
+		case <-claimCtx.Done():
+		}
+	}()
+
+	job, waited, err := r.client.ClaimJobWait(claimCtx, claimWait)
+	if err != nil {
+		// Back to the ticker, so a manager that is down is not hammered
+		r.longPoll.Store(false)
+		if claimCtx.Err() != nil && ctx.Err() == nil {
+			return nil, nil // Stopped while waiting
+		}
+		return nil, err
+	}
+	r.longPoll.Store(waited)
 
-			r.setCurrentJob(nil)
+	if job != nil && (r.draining.Load() || claimCtx.Err() != nil) {
+		if err := r.client.ReleaseJob(ctx, job.ID); err != nil {
+			r.logger.Warn("failed to release job claimed while stopping", "job_id", job.ID, "error", err)
 		}
+		return nil, nil
 	}
+
+	return job, nil
 }
 
-func (r *Runner) processJob(ctx context.Context, job *domain.Job) (int, error) {
-	if len(job.Config.Keywords) == 0 {
-		return 0, errors.New("no keywords provided")
+// finishJob reports the outcome of processJob to the manager. A job that was
+// paused or cancelled while it ran is released, keeping its status, so a
+// resume can enqueue it again. A job the manager took back is left to it. A
+// job stopped by a drain is released back to pending and returns
+// errDrained; otherwise only a failed job returns an error.
+func (r *Runner) finishJob(ctx context.Context, job *domain.Job, outcome jobOutcome, err error) error {
+	var stopped *jobStoppedError
+
+	logger := logging.FromContext(ctx)
+
+	switch {
+	case errors.As(err, &stopped):
+		logger.Info("job stopped", "status", stopped.status, "places", outcome.placesScraped)
+		if takenBack(stopped.status) {
+			return nil
+		}
+		if releaseErr := r.client.ReleaseJob(ctx, job.ID); releaseErr != nil {
+			logger.Warn("failed to release stopped job", "error", releaseErr)
+		}
+		if stopped.drained {
+			return errDrained
+		}
+		return nil
+	case err != nil:
+		code := errorCode(err)
+		logger.Error("job failed", "error", err, "error_code", code, "failed_keywords", len(outcome.failedKeywords))
+		msg := err.Error()
+		if len(outcome.outputErrors) > 0 {
+			msg += "; outputs failed: " + strings.Join(outcome.outputErrors, "; ")
+		}
+		if failErr := r.client.FailJob(ctx, job.ID, outcome.dedupedPlaces, msg, code, outcome.failedKeywords); failErr != nil {
+			logger.Warn("failed to mark job as failed", "error", failErr)
+		}
+		r.reportLeasedFailure(ctx, job, code, err)
+		return err
+	}
+
+	logger.Info("job completed", "places", outcome.placesScraped, "deduped_places", outcome.dedupedPlaces, "failed_keywords", len(outcome.failedKeywords), "stopped_reason", outcome.stoppedReason)
+
+	// Completed locally, the manager hears of it after the results
+	if outcome.spooled > 0 {
+		deferErr := r.deferCompletion(job.ID, outcome)
+		if deferErr == nil {
+			logger.Info("completion deferred until the outbox is delivered", "spooled", outcome.spooled)
+			return nil
+		}
+		logger.Error("failed to defer completion to the outbox", "error", deferErr)
+	}
+
+	if completeErr := r.client.CompleteJob(ctx, job.ID, outcome.placesScraped, outcome.dedupedPlaces, outcome.failedKeywords, outcome.stoppedReason, outcome.outputErrors, outcome.parseReport); completeErr != nil {
+		logger.Warn("failed to mark job as completed", "error", completeErr)
 	}
 
-	outpath := filepath.Join(r.dataFolder, job.ID.String()+".csv")
+	return nil
+}
 
-	outfile, err := os.Create(outpath)
+// jobOutcome is what a run of processJob reports to the manager
+type jobOutcome struct {
+	placesScraped  int      // Places the manager acknowledged
+	spooled        int      // Places waiting in the outbox for the manager
+	failedKeywords []string // Keywords whose search failed or never ran
+	stoppedReason  string   // One of the domain.JobStopped constants
+	outputErrors   []string // Job outputs that could not be written
+	dedupedPlaces  int      // Places skipped as already scraped
+	parseReport    *domain.JobParseReport
+}
+
+// processJob runs a job and returns its outcome
+func (r *Runner) processJob(ctx context.Context, job *domain.Job) (jobOutcome, error) {
+	keywords := job.RunKeywords()
+	if len(keywords) == 0 && len(job.Config.PlaceURLs) == 0 {
+		return jobOutcome{}, failure(domain.JobErrorNoResults, errors.New("no keywords provided"))
+	}
+
+	// Jobs running side by side keep their files apart
+	dir := r.jobDir(job.ID)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return jobOutcome{}, err
+	}
+
+	outfile, err := os.Create(filepath.Join(dir, "results.csv"))
 	if err != nil {
-		return 0, err
+		return jobOutcome{}, err
 	}
 	defer outfile.Close()
 
@@ -405,9 +883,26 @@ func (r *Runner) processJob(ctx context.Context, job *domain.Job) (int, error) {
 	memWriter := &MemoryWriter{}
 	writers := []scrapemate.ResultWriter{csvWriter, memWriter}
 
-	mate, err := r.setupMate(ctx, writers, job)
+	// Outputs never fail the job; their errors are reported when it completes
+	outputWriters, setupErrors := r.newOutputWriters(job)
+	for _, w := range outputWriters {
+		writers = append(writers, w)
+	}
+	writers = []scrapemate.ResultWriter{newTeeWriter(writers...)}
+
+	if err := r.acquireContexts(ctx); err != nil {
+		return jobOutcome{}, err
+	}
+	defer r.contexts.Release(int64(r.jobContexts))
+
+	r.leaseProxies(ctx, job)
+
+	// Browser startup counts towards the time to the first result
+	scrapeStarted := time.Now()
+
+	mate, warm, err := r.setupMate(ctx, writers, job)
 	if err != nil {
-		return 0, err
+		return jobOutcome{}, err
 	}
 	defer mate.Close()
 
@@ -416,53 +911,95 @@ func (r *Runner) processJob(ctx context.Context, job *domain.Job) (int, error) {
 		coords = formatCoords(*job.Config.GeoLat, *job.Config.GeoLon)
 	}
 
-	// Use Redis deduper if available, otherwise use local in-memory deduper
-	var dedup deduper.Deduper
-	if r.redisDeduper != nil {
-		dedup = r.redisDeduper
-		log.Printf("job %s: using Redis distributed deduplication", job.ID)
-	} else {
-		dedup = deduper.New()
-		log.Printf("job %s: using local in-memory deduplication", job.ID)
+	logger := logging.FromContext(ctx)
+
+	// Use Redis deduper if available, otherwise use local in-memory deduper.
+	// Redis keys are scoped to the job, so a job does not skip the places
+	// another job scraped unless it asks for it.
+	var base deduper.Deduper
+	shared := r.dedupe.Backend == "" || r.dedupe.Backend == deduper.BackendRedis
+	switch {
+	case shared && r.redisDeduper != nil:
+		if job.Config.GlobalDedupe {
+			base = r.redisDeduper
+		} else {
+			base = r.redisDeduper.Scope("job:" + job.ID.String())
+		}
+		logger.Debug("using Redis distributed deduplication", "global", job.Config.GlobalDedupe)
+	case shared:
+		base = deduper.New()
+		logger.Debug("using local in-memory deduplication")
+	default:
+		// Validated by NewRunner
+		base, _ = deduper.NewFromConfig(r.dedupe)
+		logger.Debug("using local deduplication", "deduper", r.dedupe.Backend)
 	}
+	dedup := newTrackingDeduper(base)
 	exitMonitor := exiter.New()
 
-	var ev emailvalidator.Validator
-	if r.config.EmailValidatorKey != "" {
-		ev = emailvalidator.NewMoribouncerValidator(emailvalidator.Config{
-			APIKey: r.config.EmailValidatorKey,
-			APIURL: r.config.EmailValidatorURL,
-		})
+	ev, err := emailvalidator.New(r.config.EmailValidatorOptions())
+	if err != nil {
+		return jobOutcome{}, err
 	}
 
-	seedJobs, err := runner.CreateSeedJobs(
-		job.Config.FastMode,
-		job.Config.Lang,
-		strings.NewReader(strings.Join(job.Config.Keywords, "\n")),
-		job.Config.Depth,
-		job.Config.ExtractEmail,
-		coords,
-		job.Config.Zoom,
-		func() float64 {
-			if job.Config.Radius <= 0 {
-				return 10000
-			}
-			return float64(job.Config.Radius)
-		}(),
-		dedup,
-		exitMonitor,
-		ev,
-		r.config.ExtraReviews,
-	)
+	// A nil *pagecache.Cache must not become a non-nil interface
+	var pageCache gmaps.PageCache
+	if r.pageCache != nil {
+		pageCache = r.pageCache
+	}
+
+	var seedJobs []scrapemate.IJob
+	if len(job.Config.PlaceURLs) > 0 {
+		seedJobs, err = r.placeJobs(job, exitMonitor, ev, pageCache)
+	} else {
+		seedJobs, err = runner.CreateSeedJobs(
+			job.Config.FastMode,
+			job.Config.Lang,
+			strings.NewReader(strings.Join(keywords, "\n")),
+			job.Config.Depth,
+			job.Config.ExtractEmail,
+			coords,
+			job.Config.Zoom,
+			func() float64 {
+				if job.Config.Radius <= 0 {
+					return 10000
+				}
+				return float64(job.Config.Radius)
+			}(),
+			dedup,
+			exitMonitor,
+			ev,
+			r.config.ExtraReviews,
+			job.Config.MaxReviews,
+			gmaps.ReviewSort(job.Config.ReviewsSort),
+			job.Config.MaxImages,
+			job.Config.RequestHeaders(),
+			r.limiter,
+			pageCache,
+		)
+	}
 	if err != nil {
-		return 0, err
+		return jobOutcome{}, err
 	}
 
 	if len(seedJobs) == 0 {
-		return 0, nil
+		return jobOutcome{}, nil
 	}
 
-	exitMonitor.SetSeedCount(len(seedJobs))
+	runner.SetLangFallback(seedJobs, job.Config.LangFallback)
+	runner.SetEmailPages(seedJobs, r.config.EmailPages)
+	runner.SetGoogleDomain(seedJobs, job.Config.GoogleDomain)
+
+	if len(job.Config.PlaceURLs) > 0 {
+		// Place jobs are no searches, each is a place found up front
+		exitMonitor.IncrPlacesFound(len(seedJobs))
+	} else {
+		exitMonitor.SetSeedCount(len(seedJobs))
+	}
+	exitMonitor.SetMaxResults(job.Config.MaxResults)
+	r.setProgress(job.ID, exitMonitor)
+	defer r.setProgress(job.ID, nil)
+	searches := newSearchTracker(seedJobs)
 
 	allowedSeconds := max(60, len(seedJobs)*10*job.Config.Depth/50+120)
 
@@ -474,8 +1011,7 @@ func (r *Runner) processJob(ctx context.Context, job *domain.Job) (int, error) {
 		}
 	}
 
-	log.Printf("running job %s with %d seed jobs and %d allowed seconds",
-		job.ID, len(seedJobs), allowedSeconds)
+	logger.Info("running job", "seed_jobs", len(seedJobs), "allowed_seconds", allowedSeconds)
 
 	mateCtx, cancel := context.WithTimeout(ctx, time.Duration(allowedSeconds)*time.Second)
 	defer cancel()
@@ -483,59 +1019,217 @@ func (r *Runner) processJob(ctx context.Context, job *domain.Job) (int, error) {
 	exitMonitor.SetCancelFunc(cancel)
 	go exitMonitor.Run(mateCtx)
 
+	runner.SetSearchContext(seedJobs, mateCtx)
+
+	var (
+		stopMu     sync.Mutex
+		stopStatus domain.JobStatus
+		drained    bool
+	)
+
+	r.setStopJob(job.ID, func() {
+		stopMu.Lock()
+		drained = true
+		stopMu.Unlock()
+		cancel()
+	})
+	defer r.setStopJob(job.ID, nil)
+
+	go r.watchJob(mateCtx, job.ID, func(status domain.JobStatus) {
+		logger.Info("job status changed, stopping", "status", status)
+		stopMu.Lock()
+		stopStatus = status
+		stopMu.Unlock()
+		cancel()
+	})
+
 	err = mate.Start(mateCtx, seedJobs...)
 	if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
 		cancel()
-		return 0, err
+		return jobOutcome{}, err
 	}
 
+	// Read before cancel, which would mask the deadline
+	outcome := jobOutcome{stoppedReason: stoppedReason(exitMonitor.Reason(), mateCtx.Err()), dedupedPlaces: dedup.deduped()}
+
 	cancel()
 	mate.Close()
 
+	if first := memWriter.FirstResultAt(); !first.IsZero() {
+		logger.Info("scrape finished", "time_to_first_result", first.Sub(scrapeStarted).Round(time.Millisecond), "warm_browsers", warm)
+	}
+
+	// The writers, outputs included, are done once Start returned
+	outcome.outputErrors = append(setupErrors, outputErrors(outputWriters)...)
+	outcome.parseReport = memWriter.ParseReport()
+	if len(job.Config.LangFallback) > 0 {
+		if outcome.parseReport == nil {
+			outcome.parseReport = &domain.JobParseReport{}
+		}
+		outcome.parseReport.LangFallback = searches.langFallback(job.Config.Lang)
+	}
+	if len(outcome.outputErrors) > 0 {
+		logger.Warn("job outputs failed", "errors", outcome.outputErrors)
+	}
+
+	stopMu.Lock()
+	revoked := stopStatus
+	stopMu.Unlock()
+
+	// The manager rejects the results of a job it took back, and the run
+	// it is handed to scrapes every place again
+	if takenBack(revoked) {
+		logger.Warn("job was taken back by the manager, dropping results", "results", len(memWriter.GetResults()))
+		r.releaseUnfinished(ctx, dedup, nil)
+		return jobOutcome{dedupedPlaces: outcome.dedupedPlaces}, &jobStoppedError{status: revoked}
+	}
+
 	// Submit results to manager
 	results := memWriter.GetResults()
-	log.Printf("[Worker] Job %s: CSV written, MemoryWriter has %d results", job.ID, len(results))
+	logger.Debug("CSV written", "results", len(results))
 
+	// Places in flight when the cap was reached still produce results
+	if limit := job.Config.MaxResults; limit > 0 && len(results) > limit {
+		logger.Info("dropping results over max_results", "results", len(results), "max_results", limit)
+		results = results[:limit]
+	}
+
+	// Only results the manager acknowledged count towards the job's progress
 	if len(results) > 0 {
-		log.Printf("[Worker] Job %s: Submitting %d results to manager at %s", job.ID, len(results), r.client.baseURL)
-		if err := r.client.SubmitResults(ctx, job.ID, results); err != nil {
-			log.Printf("[Worker] Job %s: SubmitResults FAILED: %v", job.ID, err)
-			return 0, fmt.Errorf("failed to submit results: %w", err)
+		outcome.placesScraped, outcome.spooled, err = r.submitResults(ctx, job.ID, results)
+		if err != nil {
+			err = fmt.Errorf("failed to submit results: %w", err)
+			if !errors.Is(err, ErrResultsRejected) {
+				err = failure(domain.JobErrorManagerUnreachable, err)
+			}
+			return jobOutcome{placesScraped: outcome.placesScraped, dedupedPlaces: outcome.dedupedPlaces}, err
 		}
-		log.Printf("[Worker] Job %s: Results submitted successfully", job.ID)
 	} else {
-		log.Printf("[Worker] Job %s: No results in MemoryWriter (check UseInResults)", job.ID)
+		logger.Info("no results to submit")
+	}
+
+	stopMu.Lock()
+	stopped, drainStopped := stopStatus, drained
+	stopMu.Unlock()
+
+	// Only a completion waits for the outbox; a run that ends otherwise
+	// fails as unreachable, which keeps its spooled results acceptable
+	if outcome.spooled > 0 && (stopped != "" || drainStopped || exitMonitor.Reason() == exiter.ReasonBlocked) {
+		return jobOutcome{placesScraped: outcome.placesScraped, dedupedPlaces: outcome.dedupedPlaces}, failure(domain.JobErrorManagerUnreachable,
+			fmt.Errorf("failed to submit results: %d results spooled to the outbox", outcome.spooled))
+	}
+
+	if stopped != "" || drainStopped {
+		r.releaseUnfinished(ctx, dedup, memWriter)
+		return jobOutcome{placesScraped: outcome.placesScraped, dedupedPlaces: outcome.dedupedPlaces}, &jobStoppedError{status: stopped, drained: drainStopped}
+	}
+
+	// Partial results are kept, but the job is failed so the dashboard shows why it stopped early
+	if exitMonitor.Reason() == exiter.ReasonBlocked {
+		return jobOutcome{placesScraped: outcome.placesScraped, failedKeywords: searches.failed(), outputErrors: outcome.outputErrors, dedupedPlaces: outcome.dedupedPlaces}, failure(domain.JobErrorBlockedByGoogle, fmt.Errorf("stopped early: blocked by Google (%d block pages, current delay %s, %d partial results saved)",
+			exitMonitor.Blocked(), r.limiter.Delay().Round(time.Millisecond), outcome.placesScraped))
+	}
+
+	// The keywords a capped run never got to are not worth a retry
+	if outcome.stoppedReason != domain.JobStoppedMaxResults {
+		outcome.failedKeywords = searches.failed()
+	}
+
+	return outcome, nil
+}
+
+// stoppedReason tells why a run that was not paused, cancelled or blocked
+// ended. Scrapemate also returns once it is idle for a while, which counts
+// as exhausted.
+func stoppedReason(reason exiter.Reason, ctxErr error) string {
+	switch {
+	case reason == exiter.ReasonMaxResults:
+		return domain.JobStoppedMaxResults
+	case reason == exiter.ReasonExhausted:
+		return domain.JobStoppedExhausted
+	case errors.Is(ctxErr, context.DeadlineExceeded):
+		return domain.JobStoppedMaxTime
+	default:
+		return domain.JobStoppedExhausted
+	}
+}
+
+// acquireContexts waits until the job's browser contexts fit under
+// -worker-max-browser-contexts next to the other jobs' ones
+func (r *Runner) acquireContexts(ctx context.Context) error {
+	if r.contexts.TryAcquire(int64(r.jobContexts)) {
+		return nil
+	}
+
+	logging.FromContext(ctx).Info("waiting for other jobs to free browser contexts", "contexts", r.jobContexts)
+	if err := r.contexts.Acquire(ctx, int64(r.jobContexts)); err != nil {
+		return fmt.Errorf("wait for browser contexts: %w", err)
 	}
 
-	return len(results), nil
+	return nil
 }
 
-func (r *Runner) setupMate(_ context.Context, writers []scrapemate.ResultWriter, job *domain.Job) (*scrapemateapp.ScrapemateApp, error) {
+// mateRunner scrapes a job's seed jobs; *scrapemateapp.ScrapemateApp is
+// one, and pooledMate runs them on warm browsers
+type mateRunner interface {
+	Start(ctx context.Context, seedJobs ...scrapemate.IJob) error
+	Close() error
+}
+
+// mateInactivity ends a scrape that produced nothing for this long
+const mateInactivity = 3 * time.Minute
+
+// setupMate builds the scraper of a job. With -worker-browser-pool a job
+// using the browser runs on warm browsers when a finished job left some
+// with the same user agent and proxies, which it reports.
+func (r *Runner) setupMate(ctx context.Context, writers []scrapemate.ResultWriter, job *domain.Job) (mateRunner, bool, error) {
+	proxies, err := r.jobProxies(job)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if r.browsers != nil && !job.Config.FastMode {
+		fetcher, warm, err := r.browsers.get(newBrowserKey(job.Config.UserAgent, proxies))
+		if err != nil {
+			return nil, false, fmt.Errorf("launch browsers: %w", err)
+		}
+
+		logging.FromContext(ctx).Debug("scraper configured", "proxy", len(proxies) > 0, "user_agent", job.Config.UserAgent, "warm_browsers", warm)
+
+		return &pooledMate{
+			fetcher:          fetcher,
+			writers:          writers,
+			concurrency:      r.jobContexts,
+			exitOnInactivity: mateInactivity,
+		}, warm, nil
+	}
+
 	opts := []func(*scrapemateapp.Config) error{
-		scrapemateapp.WithConcurrency(r.config.Concurrency),
-		scrapemateapp.WithExitOnInactivity(time.Minute * 3),
+		scrapemateapp.WithConcurrency(r.jobContexts),
+		scrapemateapp.WithExitOnInactivity(mateInactivity),
 	}
 
-	if !job.Config.FastMode {
+	// The seed jobs carry the job's headers. The browser also needs its
+	// user agent set so pages see it in navigator.userAgent; the stealth
+	// fetcher sends fixed Firefox headers, so jobs with their own use the
+	// plain HTTP fetcher.
+	switch {
+	case !job.Config.FastMode && job.Config.UserAgent != "":
+		opts = append(opts,
+			scrapemateapp.WithJS(scrapemateapp.DisableImages(), scrapemateapp.WithUA(job.Config.UserAgent)),
+		)
+	case !job.Config.FastMode:
 		opts = append(opts,
 			scrapemateapp.WithJS(scrapemateapp.DisableImages()),
 		)
-	} else {
+	case job.Config.RequestHeaders() == nil:
 		opts = append(opts,
 			scrapemateapp.WithStealth("firefox"),
 		)
 	}
 
-	hasProxy := false
-
-	if len(r.config.Proxies) > 0 {
-		opts = append(opts, scrapemateapp.WithProxies(r.config.Proxies))
-		hasProxy = true
-	} else if len(job.Config.Proxies) > 0 {
-		opts = append(opts,
-			scrapemateapp.WithProxies(job.Config.Proxies),
-		)
-		hasProxy = true
+	if len(proxies) > 0 {
+		opts = append(opts, scrapemateapp.WithProxies(proxies))
 	}
 
 	if !r.config.DisablePageReuse {
@@ -545,17 +1239,42 @@ func (r *Runner) setupMate(_ context.Context, writers []scrapemate.ResultWriter,
 		)
 	}
 
-	log.Printf("job %s has proxy: %v", job.ID, hasProxy)
+	logging.FromContext(ctx).Debug("scraper configured", "proxy", len(proxies) > 0, "user_agent", job.Config.UserAgent)
 
 	matecfg, err := scrapemateapp.NewConfig(
 		writers,
 		opts...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+
+	mate, err := scrapemateapp.NewScrapeMateApp(matecfg)
+	return mate, false, err
+}
+
+// jobProxies returns the proxies a job scrapes through, none for a direct
+// connection. A geo-targeted job only uses the worker's own proxies when
+// they go through ProxyGate, which can honour the country; otherwise it
+// needs the country-matched proxies the manager attached to the job.
+func (r *Runner) jobProxies(job *domain.Job) ([]string, error) {
+	country := job.Config.ProxyCountry
+
+	switch {
+	case len(r.config.Proxies) > 0 && (country == "" || r.config.ProxyGateSessions):
+		if r.config.ProxyGateSessions {
+			return withProxySession(r.config.Proxies, proxygate.SessionUsername("job-"+job.ID.String(), country)), nil
+		}
+		return r.config.Proxies, nil
+	case len(job.Config.Proxies) > 0:
+		return job.Config.Proxies, nil
+	case len(r.leasedProxies(job)) > 0:
+		return r.leasedProxies(job), nil
+	case country != "":
+		return nil, failure(domain.JobErrorProxyExhausted, fmt.Errorf("job requires proxies in country %s but none are available", country))
 	}
 
-	return scrapemateapp.NewScrapeMateApp(matecfg)
+	return nil, nil
 }
 
 func formatCoords(lat, lon float64) string {
@@ -568,3 +1287,25 @@ func max(a, b int) int {
 	}
 	return b
 }
+
+// withProxySession sets the SOCKS5 username of ProxyGate proxy URLs to a
+// session key, so ProxyGate routes the whole job through one upstream
+// (in the job's country, if the key carries one).
+// URLs that already carry credentials are left alone.
+func withProxySession(proxies []string, session string) []string {
+	out := make([]string, 0, len(proxies))
+
+	for _, p := range proxies {
+		u, err := url.Parse(p)
+		if err != nil || u.User != nil || !strings.HasPrefix(u.Scheme, "socks5") {
+			out = append(out, p)
+			continue
+		}
+
+		// RFC 1929 requires a non-empty password; ProxyGate ignores it
+		u.User = url.UserPassword(session, "x")
+		out = append(out, u.String())
+	}
+
+	return out
+}
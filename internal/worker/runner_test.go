@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/exiter"
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/ratelimit"
+)
+
+func TestHeartbeatReportsEveryJob(t *testing.T) {
+	r := &Runner{jobs: make(map[uuid.UUID]*runningJob), limiter: ratelimit.New(ratelimit.DefaultConfig())}
+
+	hb := r.heartbeat()
+	assert.Equal(t, domain.WorkerStatusIdle, hb.Status)
+	assert.Nil(t, hb.CurrentJobID)
+	assert.Empty(t, hb.Jobs)
+
+	first := &domain.Job{ID: uuid.New(), Name: "Bakeries"}
+	second := &domain.Job{ID: uuid.New(), Name: "Dentists"}
+	r.addJob(first)
+	time.Sleep(time.Millisecond)
+	r.addJob(second)
+
+	progress := exiter.New()
+	progress.SetSeedCount(4)
+	progress.IncrSeedCompleted(1)
+	progress.IncrPlacesCompleted(7)
+	r.setProgress(second.ID, progress)
+
+	hb = r.heartbeat()
+	assert.Equal(t, domain.WorkerStatusBusy, hb.Status)
+	require.NotNil(t, hb.CurrentJobID)
+	assert.Equal(t, first.ID, *hb.CurrentJobID)
+	assert.Equal(t, "Bakeries", hb.CurrentJobName)
+	assert.Equal(t, []domain.WorkerJob{
+		{JobID: first.ID, JobName: "Bakeries"},
+		{JobID: second.ID, JobName: "Dentists", SeedJobsCompleted: 1, SeedJobsTotal: 4, PlacesScraped: 7},
+	}, hb.Jobs)
+	assert.Equal(t, 1, hb.SeedJobsCompleted)
+	assert.Equal(t, 4, hb.SeedJobsTotal)
+	assert.Equal(t, 7, hb.JobPlacesScraped)
+
+	r.removeJob(first.ID)
+	hb = r.heartbeat()
+	require.NotNil(t, hb.CurrentJobID)
+	assert.Equal(t, second.ID, *hb.CurrentJobID)
+	assert.Len(t, hb.Jobs, 1)
+}
@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+)
+
+// teeBuffer is the number of results a writer may fall behind the others
+const teeBuffer = 1000
+
+// teeWriter hands every result to each of its writers. Scrapemate's writers
+// share one results channel, so on their own every result would reach only
+// one of them.
+type teeWriter struct {
+	writers []scrapemate.ResultWriter
+}
+
+func newTeeWriter(writers ...scrapemate.ResultWriter) *teeWriter {
+	return &teeWriter{writers: writers}
+}
+
+// Run implements scrapemate.ResultWriter. A writer that returns early gets
+// no more results; its error is returned once the others are done.
+func (t *teeWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	var wg sync.WaitGroup
+
+	chans := make([]chan scrapemate.Result, len(t.writers))
+	done := make([]chan struct{}, len(t.writers))
+	errs := make([]error, len(t.writers))
+
+	for i, w := range t.writers {
+		chans[i] = make(chan scrapemate.Result, teeBuffer)
+		done[i] = make(chan struct{})
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[i])
+			errs[i] = w.Run(ctx, chans[i])
+		}()
+	}
+
+	for result := range in {
+		for i := range chans {
+			select {
+			case chans[i] <- result:
+			case <-done[i]:
+			}
+		}
+	}
+
+	for _, ch := range chans {
+		close(ch)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
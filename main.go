@@ -9,13 +9,18 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/sadewadee/google-scraper/internal/logging"
 	"github.com/sadewadee/google-scraper/internal/proxygate"
 	"github.com/sadewadee/google-scraper/runner"
 	"github.com/sadewadee/google-scraper/runner/databaserunner"
+	"github.com/sadewadee/google-scraper/runner/exportrunner"
 	"github.com/sadewadee/google-scraper/runner/filerunner"
 	"github.com/sadewadee/google-scraper/runner/installplaywright"
 	"github.com/sadewadee/google-scraper/runner/lambdaaws"
 	"github.com/sadewadee/google-scraper/runner/managerrunner"
+	"github.com/sadewadee/google-scraper/runner/migraterunner"
+	"github.com/sadewadee/google-scraper/runner/renormalizerunner"
+	"github.com/sadewadee/google-scraper/runner/rotatesecretsrunner"
 	"github.com/sadewadee/google-scraper/runner/workerrunner"
 	"golang.org/x/sync/errgroup"
 )
@@ -41,6 +46,11 @@ func main() {
 
 	cfg := runner.ParseConfig()
 
+	if err := logging.Setup(os.Stderr, cfg.LogFormat, cfg.LogLevel); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(2)
+	}
+
 	log.Printf("RunMode: %d (Manager=%v, Worker=%v)", cfg.RunMode, cfg.ManagerMode, cfg.WorkerMode)
 
 	var pg *proxygate.ProxyGate
@@ -58,6 +68,23 @@ func main() {
 			pgCfg.ListenAddr = cfg.ProxyGateAddr
 		}
 
+		pgCfg.ExplorationRatio = cfg.ProxyGateExploration
+		pgCfg.SessionTTL = cfg.ProxyGateSessionTTL
+		pgCfg.LeaseTTL = cfg.ProxyGateLeaseTTL
+		pgCfg.MaxSessions = cfg.ProxyGateMaxSessions
+		pgCfg.GeoIPURL = cfg.ProxyGateGeoIPURL
+		pgCfg.RevalidateInterval = cfg.ProxyGateRevalidateInterval
+		pgCfg.RevalidateBatch = cfg.ProxyGateRevalidateBatch
+		pgCfg.ProbeConcurrency = cfg.ProxyGateProbeConcurrency
+		pgCfg.ProbeTimeout = cfg.ProxyGateProbeTimeout
+		pgCfg.ProbeURL = cfg.ProxyGateProbeURL
+		pgCfg.ConnectivityURL = cfg.ProxyGateConnectivityURL
+		pgCfg.EventRetention = cfg.ProxyGateEventRetention
+		pgCfg.UsageFlushInterval = cfg.ProxyGateUsageFlushInterval
+		pgCfg.SourceTimeout = cfg.ProxyGateSourceTimeout
+		pgCfg.SourceMinPassRate = cfg.ProxyGateSourceMinPassRate
+		pgCfg.SourceDisableAfter = cfg.ProxyGateSourceDisableAfter
+
 		pg = proxygate.New(pgCfg)
 	}
 
@@ -68,19 +95,29 @@ func main() {
 
 		runner.Telemetry().Close()
 
-		os.Exit(1)
+		os.Exit(runner.ExitCode(err))
 	}
 
 	egroup, ctx := errgroup.WithContext(ctx)
 
-	// Start ProxyGate if enabled
-	if pg != nil {
+	// ProxyGate serves the runner, so it stops once the runner returns, e.g.
+	// a worker that drained
+	pgCtx, stopPG := context.WithCancel(ctx)
+	defer stopPG()
+
+	// Start ProxyGate if enabled; -preflight only checks its address
+	if pg != nil && cfg.RunMode != runner.RunModePreflight {
 		egroup.Go(func() error {
-			return pg.Run(ctx)
+			if err := pg.Run(pgCtx); err != nil && pgCtx.Err() == nil {
+				return err
+			}
+			return nil
 		})
 	}
 
 	egroup.Go(func() error {
+		defer stopPG()
+
 		if err := runnerInstance.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
 			return err
 		}
@@ -91,7 +128,7 @@ func main() {
 		os.Stderr.WriteString(err.Error() + "\n")
 		_ = runnerInstance.Close(ctx)
 		runner.Telemetry().Close()
-		os.Exit(1)
+		os.Exit(runner.ExitCode(err))
 	}
 
 	_ = runnerInstance.Close(ctx)
@@ -118,33 +155,17 @@ func runnerFactory(cfg *runner.Config, pg *proxygate.ProxyGate) (runner.Runner,
 		return lambdaaws.New(cfg)
 	case runner.RunModeAwsLambdaInvoker:
 		return lambdaaws.NewInvoker(cfg)
-	case runner.RunModeManager:
-		return managerrunner.New(&managerrunner.Config{
-			DatabaseURL:  cfg.Dsn,
-			Address:      cfg.Addr,
-			DataFolder:   cfg.DataFolder,
-			StaticFolder: cfg.StaticFolder,
-			RedisURL:     cfg.RedisURL,
-			RedisAddr:    cfg.RedisAddr,
-			RedisPass:    cfg.RedisPass,
-			RedisDB:      cfg.RedisDB,
-			RabbitMQURL:  cfg.RabbitMQURL,
-			// Spawner configuration
-			SpawnerType:             cfg.SpawnerType,
-			SpawnerImage:            cfg.SpawnerImage,
-			SpawnerNetwork:          cfg.SpawnerNetwork,
-			SpawnerConcurrency:      cfg.SpawnerConcurrency,
-			SpawnerMaxWorkers:       cfg.SpawnerMaxWorkers,
-			SpawnerAutoRemove:       cfg.SpawnerAutoRemove,
-			SpawnerLabels:           cfg.SpawnerLabels,
-			SpawnerConstraints:      cfg.SpawnerConstraints,
-			SpawnerManagerURL:       cfg.SpawnerManagerURL,
-			SpawnerProxies:          cfg.SpawnerProxies,
-			SpawnerLambdaFunction:   cfg.SpawnerLambdaFunction,
-			SpawnerLambdaRegion:     cfg.SpawnerLambdaRegion,
-			SpawnerLambdaInvocation: cfg.SpawnerLambdaInvocation,
-			SpawnerLambdaMaxConc:    cfg.SpawnerLambdaMaxConc,
-		}, pg)
+	case runner.RunModeManager, runner.RunModePreflight:
+		mcfg, err := managerConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg.RunMode == runner.RunModePreflight {
+			return managerrunner.NewPreflight(mcfg, pg), nil
+		}
+
+		return managerrunner.New(mcfg, pg)
 	case runner.RunModeWorker:
 		return workerrunner.New(&workerrunner.Config{
 			ManagerURL:   cfg.ManagerURL,
@@ -155,8 +176,81 @@ func runnerFactory(cfg *runner.Config, pg *proxygate.ProxyGate) (runner.Runner,
 			RedisPass:    cfg.RedisPass,
 			RedisDB:      cfg.RedisDB,
 			RabbitMQURL:  cfg.RabbitMQURL,
+			// Worker protocol over gRPC, HTTP when unset or unreachable
+			ManagerGRPCURL: cfg.ManagerGRPCURL,
+			Deduper:        cfg.Deduper,
 		})
+	case runner.RunModeExport:
+		return exportrunner.New(cfg)
+	case runner.RunModeRenormalize:
+		return renormalizerunner.New(cfg)
+	case runner.RunModeMigrateToPostgres:
+		return migraterunner.New(cfg)
+	case runner.RunModeRotateSecrets:
+		return rotatesecretsrunner.New(cfg)
 	default:
 		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
 	}
 }
+
+// managerConfig returns the manager configuration of the flags
+func managerConfig(cfg *runner.Config) (*managerrunner.Config, error) {
+	keys, err := cfg.Keyring()
+	if err != nil {
+		return nil, &runner.ExitError{Code: runner.ExitUsage, Err: err}
+	}
+
+	return &managerrunner.Config{
+		DatabaseURL:  cfg.Dsn,
+		Address:      cfg.Addr,
+		GRPCAddress:  cfg.GRPCAddr,
+		DataFolder:   cfg.DataFolder,
+		StaticFolder: cfg.StaticFolder,
+		RedisURL:     cfg.RedisURL,
+		RedisAddr:    cfg.RedisAddr,
+		RedisPass:    cfg.RedisPass,
+		RedisDB:      cfg.RedisDB,
+		RabbitMQURL:  cfg.RabbitMQURL,
+		// Queue status
+		RabbitMQManagementURL: cfg.RabbitMQManagementURL,
+		// Background email validation
+		EmailValidator:         cfg.EmailValidatorOptions(),
+		EmailValidationWorkers: cfg.EmailValidationWorkers,
+		EmailValidationTTL:     cfg.EmailValidationTTL,
+		// Background website checks
+		WebsiteCheckWorkers: cfg.WebsiteCheckWorkers,
+		WebsiteCheckTTL:     cfg.WebsiteCheckTTL,
+		WebsiteCheckProxy:   cfg.WebsiteCheckProxy,
+		// Keyword expansion limit
+		MaxExpandedKeywords: cfg.MaxExpandedKeywords,
+		// Spawner configuration
+		SpawnerType:             cfg.SpawnerType,
+		SpawnerImage:            cfg.SpawnerImage,
+		SpawnerNetwork:          cfg.SpawnerNetwork,
+		SpawnerConcurrency:      cfg.SpawnerConcurrency,
+		SpawnerMaxWorkers:       cfg.SpawnerMaxWorkers,
+		SpawnerAutoRemove:       cfg.SpawnerAutoRemove,
+		SpawnerLabels:           cfg.SpawnerLabels,
+		SpawnerConstraints:      cfg.SpawnerConstraints,
+		SpawnerManagerURL:       cfg.SpawnerManagerURL,
+		SpawnerProxies:          cfg.SpawnerProxies,
+		SpawnerScaleInterval:    cfg.SpawnerScaleInterval,
+		SpawnerIdleTimeout:      cfg.SpawnerIdleTimeout,
+		SpawnerJobsPerWorker:    cfg.SpawnerJobsPerWorker,
+		SpawnerLambdaFunction:   cfg.SpawnerLambdaFunction,
+		SpawnerLambdaRegion:     cfg.SpawnerLambdaRegion,
+		SpawnerLambdaInvocation: cfg.SpawnerLambdaInvocation,
+		SpawnerLambdaMaxConc:    cfg.SpawnerLambdaMaxConc,
+		LeaderLockTTL:           cfg.LeaderLockTTL,
+		DeletedJobRetentionDays: cfg.DeletedJobRetentionDays,
+		JobEventRetentionDays:   cfg.JobEventRetentionDays,
+		JobTimeoutGrace:         cfg.JobTimeoutGrace,
+		LateResultsWindow:       cfg.LateResultsWindow,
+		MaintenanceSchedule:     cfg.MaintenanceSchedule,
+		SMTP:                    cfg.SMTP,
+		SlowRequestThreshold:    cfg.SlowRequestThreshold,
+		SlowQueryThreshold:      cfg.SlowQueryThreshold,
+		Secrets:                 keys,
+		SkipPreflight:           cfg.SkipPreflight,
+	}, nil
+}
@@ -0,0 +1,404 @@
+// Package pagecache keeps the raw payloads of fetched Google Maps place
+// pages on disk, so that a better parser can be run over them again
+// without fetching the pages, and the proxy bandwidth, a second time.
+//
+// Layout of a cache directory:
+//
+//	index.json          the index: URL, size and times of every stored page
+//	pages/<hash>.json   the raw payload of a page, <hash> being the hex
+//	                    SHA-256 of the page URL
+//
+// The index is rewritten, through a temporary file and a rename, whenever
+// the write queue runs empty and on Close, so a restarted worker picks up
+// the cache where it left off. When the cache opens, pages missing from the
+// index are removed and index entries whose page is missing are dropped.
+package pagecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	indexFile    = "index.json"
+	pagesDir     = "pages"
+	indexVersion = 1
+
+	// DefaultQueueSize is how many pages may wait to be written. Pages put
+	// while the queue is full are dropped.
+	DefaultQueueSize = 256
+)
+
+// Config configures a cache
+type Config struct {
+	Dir       string
+	TTL       time.Duration // Age after which a page is dropped, 0 keeps pages until evicted
+	MaxBytes  int64         // Size of all pages above which the least recently used are evicted, 0 for no cap
+	QueueSize int           // Pages waiting to be written, 0 means DefaultQueueSize
+}
+
+// Entry describes a stored page in the index
+type Entry struct {
+	URL      string    `json:"url"`
+	Size     int64     `json:"size"`
+	StoredAt time.Time `json:"stored_at"`
+	UsedAt   time.Time `json:"used_at"` // Last store or read, for eviction
+}
+
+// index is the content of index.json, entries keyed by hash
+type index struct {
+	Version int               `json:"version"`
+	Entries map[string]*Entry `json:"entries"`
+}
+
+type page struct {
+	url     string
+	payload []byte
+}
+
+// Cache is a disk cache of place pages. Put never blocks and never fails:
+// pages are written by a background goroutine and write errors are only
+// logged, so a broken cache never gets in the way of scraping.
+type Cache struct {
+	cfg    Config
+	logger *slog.Logger
+
+	mu      sync.Mutex // Protects entries, size and dirty
+	entries map[string]*Entry
+	size    int64
+	dirty   bool // Index changed since it was last saved
+
+	sendMu  sync.RWMutex // Protects closed and sends on queue
+	closed  bool
+	queue   chan page
+	done    chan struct{}
+	dropped atomic.Int64
+}
+
+// Open opens the cache in cfg.Dir, creating it if needed, and starts its
+// writer
+func Open(cfg Config) (*Cache, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("cache directory is required")
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultQueueSize
+	}
+
+	if err := os.MkdirAll(filepath.Join(cfg.Dir, pagesDir), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+
+	c := &Cache{
+		cfg:     cfg,
+		logger:  slog.Default().With("component", "PageCache"),
+		entries: make(map[string]*Entry),
+		queue:   make(chan page, cfg.QueueSize),
+		done:    make(chan struct{}),
+	}
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	go c.run()
+
+	return c, nil
+}
+
+// Put queues payload to be stored for url. payload must not be modified
+// afterwards. When the queue is full, or the cache closed, the page is
+// dropped.
+func (c *Cache) Put(url string, payload []byte) {
+	c.sendMu.RLock()
+	defer c.sendMu.RUnlock()
+
+	if c.closed {
+		return
+	}
+
+	select {
+	case c.queue <- page{url: url, payload: payload}:
+	default:
+		if c.dropped.Add(1)%100 == 1 {
+			c.logger.Warn("page cache queue full, pages dropped", "dropped", c.dropped.Load())
+		}
+	}
+}
+
+// Get returns the payload stored for url, if any and not expired
+func (c *Cache) Get(url string) ([]byte, bool) {
+	hash := Hash(url)
+
+	c.mu.Lock()
+	e, ok := c.entries[hash]
+	if !ok || c.expired(e, time.Now()) {
+		c.mu.Unlock()
+		return nil, false
+	}
+	e.UsedAt = time.Now()
+	c.dirty = true
+	c.mu.Unlock()
+
+	payload, err := os.ReadFile(c.pagePath(hash))
+	if err != nil {
+		return nil, false
+	}
+
+	return payload, true
+}
+
+// Each calls fn with every page that has not expired, oldest first. Pages
+// stored while Each runs may or may not be seen.
+func (c *Cache) Each(fn func(url string, payload []byte) error) error {
+	now := time.Now()
+
+	c.mu.Lock()
+	type item struct {
+		hash string
+		e    Entry
+	}
+	items := make([]item, 0, len(c.entries))
+	for hash, e := range c.entries {
+		if !c.expired(e, now) {
+			items = append(items, item{hash: hash, e: *e})
+		}
+	}
+	c.mu.Unlock()
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].e.StoredAt.Before(items[j].e.StoredAt)
+	})
+
+	for _, it := range items {
+		payload, err := os.ReadFile(c.pagePath(it.hash))
+		if errors.Is(err, os.ErrNotExist) {
+			continue // Evicted meanwhile
+		}
+		if err != nil {
+			return fmt.Errorf("read cached page: %w", err)
+		}
+
+		if err := fn(it.e.URL, payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of stored pages, expired ones included
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Size returns the size of the stored pages in bytes
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Close writes the queued pages and saves the index
+func (c *Cache) Close() error {
+	c.sendMu.Lock()
+	if c.closed {
+		c.sendMu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.queue)
+	c.sendMu.Unlock()
+
+	<-c.done
+
+	return c.saveIndex()
+}
+
+// Hash returns the hash that names the page of url on disk
+func Hash(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+// run writes queued pages, saving the index whenever the queue runs empty
+func (c *Cache) run() {
+	defer close(c.done)
+
+	for p := range c.queue {
+		if err := c.store(p); err != nil {
+			c.logger.Warn("failed to cache page", "url", p.url, "error", err)
+		}
+
+		if len(c.queue) == 0 {
+			if err := c.saveIndex(); err != nil {
+				c.logger.Warn("failed to save page cache index", "error", err)
+			}
+		}
+	}
+}
+
+// store writes a page and evicts what no longer fits. The file is renamed
+// into place so a crash never leaves half a page behind.
+func (c *Cache) store(p page) error {
+	hash := Hash(p.url)
+	path := c.pagePath(hash)
+
+	if err := os.WriteFile(path+".tmp", p.payload, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if old, ok := c.entries[hash]; ok {
+		c.size -= old.Size
+	}
+	c.entries[hash] = &Entry{URL: p.url, Size: int64(len(p.payload)), StoredAt: now, UsedAt: now}
+	c.size += int64(len(p.payload))
+	c.dirty = true
+
+	c.evict(now)
+
+	return nil
+}
+
+// evict drops expired pages, then the least recently used ones until the
+// cache fits in MaxBytes. c.mu must be held.
+func (c *Cache) evict(now time.Time) {
+	for hash, e := range c.entries {
+		if c.expired(e, now) {
+			c.remove(hash)
+		}
+	}
+
+	if c.cfg.MaxBytes <= 0 || c.size <= c.cfg.MaxBytes {
+		return
+	}
+
+	hashes := make([]string, 0, len(c.entries))
+	for hash := range c.entries {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return c.entries[hashes[i]].UsedAt.Before(c.entries[hashes[j]].UsedAt)
+	})
+
+	for _, hash := range hashes {
+		if c.size <= c.cfg.MaxBytes {
+			break
+		}
+		c.remove(hash)
+	}
+}
+
+// remove deletes a page and its entry. c.mu must be held.
+func (c *Cache) remove(hash string) {
+	if err := os.Remove(c.pagePath(hash)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		c.logger.Warn("failed to remove cached page", "hash", hash, "error", err)
+	}
+
+	c.size -= c.entries[hash].Size
+	delete(c.entries, hash)
+	c.dirty = true
+}
+
+func (c *Cache) expired(e *Entry, now time.Time) bool {
+	return c.cfg.TTL > 0 && now.Sub(e.StoredAt) > c.cfg.TTL
+}
+
+func (c *Cache) pagePath(hash string) string {
+	return filepath.Join(c.cfg.Dir, pagesDir, hash+".json")
+}
+
+// load reads the index and reconciles it with the pages on disk. A corrupt
+// index is discarded along with the pages, which cannot be told apart
+// without it.
+func (c *Cache) load() error {
+	data, err := os.ReadFile(filepath.Join(c.cfg.Dir, indexFile))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("read cache index: %w", err)
+	default:
+		var idx index
+		if err := json.Unmarshal(data, &idx); err != nil || idx.Version != indexVersion {
+			c.logger.Warn("discarding unreadable page cache index", "error", err, "version", idx.Version)
+		} else {
+			for hash, e := range idx.Entries {
+				if e != nil {
+					c.entries[hash] = e
+				}
+			}
+		}
+	}
+
+	files, err := os.ReadDir(filepath.Join(c.cfg.Dir, pagesDir))
+	if err != nil {
+		return fmt.Errorf("read cache directory: %w", err)
+	}
+
+	onDisk := make(map[string]bool, len(files))
+	for _, f := range files {
+		hash, ok := strings.CutSuffix(f.Name(), ".json")
+		if _, known := c.entries[hash]; !ok || !known {
+			_ = os.Remove(filepath.Join(c.cfg.Dir, pagesDir, f.Name()))
+			continue
+		}
+		onDisk[hash] = true
+	}
+
+	for hash, e := range c.entries {
+		if !onDisk[hash] {
+			delete(c.entries, hash)
+			c.dirty = true
+			continue
+		}
+		c.size += e.Size
+	}
+
+	c.evict(time.Now())
+
+	return c.saveIndex()
+}
+
+// saveIndex writes the index if it changed since it was last saved
+func (c *Cache) saveIndex() error {
+	c.mu.Lock()
+	if !c.dirty {
+		c.mu.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(index{Version: indexVersion, Entries: c.entries})
+	c.dirty = false
+	c.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(c.cfg.Dir, indexFile)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
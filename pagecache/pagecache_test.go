@@ -0,0 +1,95 @@
+package pagecache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := Open(Config{Dir: dir})
+	require.NoError(t, err)
+	c.Put("https://www.google.com/maps/place/a", []byte(`["a"]`))
+	c.Put("https://www.google.com/maps/place/b", []byte(`["b"]`))
+	require.NoError(t, c.Close())
+
+	// Left over by a crash mid-write, and a page without an index entry
+	require.NoError(t, os.WriteFile(filepath.Join(dir, pagesDir, "x.json.tmp"), []byte("x"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, pagesDir, Hash("c")+".json"), []byte("c"), 0o644))
+
+	c, err = Open(Config{Dir: dir})
+	require.NoError(t, err)
+	defer c.Close()
+
+	payload, ok := c.Get("https://www.google.com/maps/place/b")
+	require.True(t, ok)
+	assert.Equal(t, `["b"]`, string(payload))
+
+	var urls []string
+	require.NoError(t, c.Each(func(url string, _ []byte) error {
+		urls = append(urls, url)
+		return nil
+	}))
+	assert.ElementsMatch(t, []string{"https://www.google.com/maps/place/a", "https://www.google.com/maps/place/b"}, urls)
+
+	files, err := os.ReadDir(filepath.Join(dir, pagesDir))
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+}
+
+func TestCacheEviction(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want []string // Pages left after storing p0..p4, 10 bytes each
+	}{
+		{
+			name: "no limits",
+			cfg:  Config{},
+			want: []string{"p0", "p1", "p2", "p3", "p4"},
+		},
+		{
+			name: "size cap keeps the most recently used",
+			cfg:  Config{MaxBytes: 30},
+			want: []string{"p0", "p3", "p4"},
+		},
+		{
+			name: "expired pages are dropped",
+			cfg:  Config{TTL: time.Nanosecond},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Dir = t.TempDir()
+			c, err := Open(tt.cfg)
+			require.NoError(t, err)
+
+			for i := range 5 {
+				require.NoError(t, c.store(page{url: fmt.Sprintf("p%d", i), payload: []byte("0123456789")}))
+				if i == 2 {
+					// Reading p0 makes p1 the least recently used
+					time.Sleep(time.Millisecond)
+					c.Get("p0")
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			var got []string
+			require.NoError(t, c.Each(func(url string, _ []byte) error {
+				got = append(got, url)
+				return nil
+			}))
+			assert.ElementsMatch(t, tt.want, got)
+			require.NoError(t, c.Close())
+		})
+	}
+}
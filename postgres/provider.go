@@ -6,18 +6,25 @@ import (
 	"database/sql"
 	"encoding/gob"
 	"fmt"
+	"log"
 	"sync"
 	"time"
 
 	"github.com/gosom/scrapemate"
 
+	"github.com/sadewadee/google-scraper/deduper"
 	"github.com/sadewadee/google-scraper/gmaps"
 )
 
 const (
 	statusNew    = "new"
 	statusQueued = "queued"
+	statusOK     = "ok"
+	statusFailed = "failed"
 	batchSize    = 10
+
+	// taskReportTimeout bounds recording how a seed job finished
+	taskReportTimeout = 10 * time.Second
 )
 
 // GmapsJobPusher interface for pushing jobs to gmaps_jobs table.
@@ -29,14 +36,22 @@ type GmapsJobPusher interface {
 
 var _ scrapemate.JobProvider = (*provider)(nil)
 var _ GmapsJobPusher = (*provider)(nil)
+var _ gmaps.TaskReporter = (*provider)(nil)
+
+// querier is a database or a transaction
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
 
 type provider struct {
-	db        *sql.DB
+	db        querier
 	mu        *sync.Mutex
 	jobc      chan scrapemate.IJob
 	errc      chan error
 	started   bool
 	batchSize int
+	dedup     deduper.Deduper // Set on the search jobs fetched, nil for none
 }
 
 func NewProvider(db *sql.DB, opts ...ProviderOption) scrapemate.JobProvider {
@@ -57,8 +72,9 @@ func NewProvider(db *sql.DB, opts ...ProviderOption) scrapemate.JobProvider {
 }
 
 // NewGmapsJobPusher creates a GmapsJobPusher for pushing jobs to gmaps_jobs.
-// Used by JobService to bridge Dashboard jobs to DSN workers.
-func NewGmapsJobPusher(db *sql.DB) GmapsJobPusher {
+// Used by JobService to bridge Dashboard jobs to DSN workers. db may be a
+// transaction, whose commit makes the pushed jobs claimable.
+func NewGmapsJobPusher(db querier) GmapsJobPusher {
 	return &provider{
 		db:        db,
 		mu:        &sync.Mutex{},
@@ -80,6 +96,14 @@ func WithBatchSize(size int) ProviderOption {
 	}
 }
 
+// WithDeduper skips the places the search jobs fetched found before,
+// remembered in d
+func WithDeduper(d deduper.Deduper) ProviderOption {
+	return func(p *provider) {
+		p.dedup = d
+	}
+}
+
 //nolint:gocritic // it contains about unnamed results
 func (p *provider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan error) {
 	outc := make(chan scrapemate.IJob)
@@ -123,31 +147,78 @@ func (p *provider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan err
 	return outc, errc
 }
 
-// Push pushes a job to the job provider
+// Push pushes a job to the job provider. A search retried in another
+// language puts its own row back to new instead.
 func (p *provider) Push(ctx context.Context, job scrapemate.IJob) error {
+	if j, ok := job.(*gmaps.GmapJob); ok && j.IsLangFallback() {
+		return p.requeueLangFallback(ctx, j)
+	}
+
 	return p.PushWithParent(ctx, job, "")
 }
 
+// requeueLangFallback stores the search in its next language in the row of
+// the search, so the seed task keeps its ID and parent
+func (p *provider) requeueLangFallback(ctx context.Context, j *gmaps.GmapJob) error {
+	// The reporter and deduper are set again when the row is claimed, gob
+	// cannot encode them
+	payload := *j
+	payload.TaskReporter = nil
+	payload.Deduper = nil
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&payload); err != nil {
+		return err
+	}
+
+	_, err := p.db.ExecContext(ctx, `
+		UPDATE gmaps_jobs
+		SET payload = $2, status = $3, lang = $4, lang_fallbacks = $5,
+			started_at = NULL, finished_at = NULL, places_found = NULL, error = NULL
+		WHERE id = $1`,
+		j.ID, buf.Bytes(), statusNew, j.LangCode, len(j.LangTried),
+	)
+
+	return err
+}
+
 // PushWithParent pushes a job with a parent job reference for Dashboard tracking.
 // The parentID links the gmaps_job back to the jobs_queue table.
 func (p *provider) PushWithParent(ctx context.Context, job scrapemate.IJob, parentID string) error {
 	q := `INSERT INTO gmaps_jobs
-		(id, priority, payload_type, payload, created_at, status, parent_job_id)
+		(id, priority, payload_type, payload, created_at, status, parent_job_id, keyword, geo, lang)
 		VALUES
-		($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING`
+		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT DO NOTHING`
 
 	var buf bytes.Buffer
 	enc := gob.NewEncoder(&buf)
 
-	var payloadType string
+	var (
+		payloadType string
+		keyword     sql.NullString
+		geo         sql.NullString
+		lang        sql.NullString
+	)
 
 	switch j := job.(type) {
 	case *gmaps.GmapJob:
 		payloadType = "search"
 
+		// The deduper is set again when the row is claimed
+		if j.Deduper != nil {
+			payload := *j
+			payload.Deduper = nil
+			j = &payload
+		}
+
 		if err := enc.Encode(j); err != nil {
 			return err
 		}
+
+		query, coords := j.SearchQuery()
+		keyword = sql.NullString{String: query, Valid: query != ""}
+		geo = sql.NullString{String: coords, Valid: coords != ""}
+		lang = sql.NullString{String: j.LangCode, Valid: j.LangCode != ""}
 	case *gmaps.PlaceJob:
 		payloadType = "place"
 
@@ -172,11 +243,36 @@ func (p *provider) PushWithParent(ctx context.Context, job scrapemate.IJob, pare
 
 	_, err := p.db.ExecContext(ctx, q,
 		job.GetID(), job.GetPriority(), payloadType, buf.Bytes(), time.Now().UTC(), statusNew, parentIDArg,
+		keyword, geo, lang,
 	)
 
 	return err
 }
 
+// TaskFinished records how a seed job claimed by this provider finished
+// and in which language. Failing to record it only costs the manager some
+// visibility, so errors are logged rather than failing the scrape.
+func (p *provider) TaskFinished(ctx context.Context, jobID, lang string, placesFound int, err error) {
+	status, errMsg := statusOK, sql.NullString{}
+	if err != nil {
+		status = statusFailed
+		errMsg = sql.NullString{String: err.Error(), Valid: true}
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), taskReportTimeout)
+	defer cancel()
+
+	_, dbErr := p.db.ExecContext(ctx, `
+		UPDATE gmaps_jobs
+		SET status = $2, finished_at = NOW(), places_found = $3, error = $4, lang = $5
+		WHERE id = $1`,
+		jobID, status, placesFound, errMsg, lang,
+	)
+	if dbErr != nil {
+		log.Printf("[JobProvider] WARNING: failed to record status of seed job %s: %v", jobID, dbErr)
+	}
+}
+
 func (p *provider) fetchJobs(ctx context.Context) {
 	defer close(p.jobc)
 	defer close(p.errc)
@@ -184,7 +280,7 @@ func (p *provider) fetchJobs(ctx context.Context) {
 	q := `
 	WITH updated AS (
 		UPDATE gmaps_jobs
-		SET status = $1
+		SET status = $1, started_at = NOW()
 		WHERE id IN (
 			SELECT id from gmaps_jobs
 			WHERE status = $2
@@ -237,12 +333,19 @@ func (p *provider) fetchJobs(ctx context.Context) {
 				return
 			}
 
+			if j, ok := job.(*gmaps.GmapJob); ok && p.dedup != nil {
+				j.Deduper = p.dedup
+			}
+
 			// Inject ParentID from database column if present
 			// This ensures the worker knows which Dashboard job this belongs to
 			if parentID.Valid && parentID.String != "" {
 				switch j := job.(type) {
 				case *gmaps.GmapJob:
 					j.ParentID = parentID.String
+					// Seed jobs of Dashboard jobs report back so the manager
+					// can track them, see TaskFinished
+					j.TaskReporter = p
 				case *gmaps.PlaceJob:
 					j.ParentID = parentID.String
 				case *gmaps.EmailExtractJob:
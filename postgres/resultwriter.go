@@ -184,26 +184,21 @@ func (r *resultWriter) batchSave(ctx context.Context, items []bufferItem) error
 	return nil
 }
 
-// syncAllParentProgress updates status and completed_tasks for parent jobs.
-// NOTE: It does NOT update scraped_places anymore, as that is handled incrementally in batchSave.
+// syncAllParentProgress updates total_tasks and completed_tasks for parent jobs.
+// It does NOT update scraped_places, as that is handled incrementally in
+// batchSave, nor the parent's status: the manager completes parent jobs
+// once their seed jobs have finished, see service.SeedTaskService.
 func (r *resultWriter) syncAllParentProgress(ctx context.Context) {
 	q := `
 	UPDATE jobs_queue
 	SET
-		completed_tasks = sub.completed,
-		-- scraped_places update REMOVED to avoid double counting
-		status = CASE
-			WHEN sub.completed >= total_tasks THEN 'completed'
-			ELSE status
-		END,
-		completed_at = CASE
-			WHEN sub.completed >= total_tasks THEN NOW()
-			ELSE completed_at
-		END
+		total_tasks = sub.total,
+		completed_tasks = sub.completed
 	FROM (
 		SELECT
 			parent_job_id,
-			COUNT(*) FILTER (WHERE status = 'queued') as completed
+			COUNT(*) as total,
+			COUNT(*) FILTER (WHERE status IN ('ok', 'failed')) as completed
 		FROM gmaps_jobs
 		WHERE parent_job_id IS NOT NULL
 		GROUP BY parent_job_id
@@ -218,7 +213,7 @@ func (r *resultWriter) syncAllParentProgress(ctx context.Context) {
 	}
 }
 
-// UpdateParentJobProgress updates the progress of a parent job based on gmaps_jobs completion.
+// UpdateParentJobProgress updates the task counts of a parent job based on gmaps_jobs completion.
 // This can be called directly to sync progress for a specific parent job.
 func UpdateParentJobProgress(ctx context.Context, db *sql.DB, parentJobID string) error {
 	if parentJobID == "" {
@@ -228,20 +223,14 @@ func UpdateParentJobProgress(ctx context.Context, db *sql.DB, parentJobID string
 	q := `
 	UPDATE jobs_queue
 	SET
-		completed_tasks = (
+		total_tasks = (
 			SELECT COUNT(*) FROM gmaps_jobs
-			WHERE parent_job_id = $1 AND status = 'queued'
+			WHERE parent_job_id = $1
 		),
-		status = CASE
-			WHEN (SELECT COUNT(*) FROM gmaps_jobs WHERE parent_job_id = $1 AND status != 'queued') = 0
-			THEN 'completed'
-			ELSE status
-		END,
-		completed_at = CASE
-			WHEN (SELECT COUNT(*) FROM gmaps_jobs WHERE parent_job_id = $1 AND status != 'queued') = 0
-			THEN NOW()
-			ELSE completed_at
-		END
+		completed_tasks = (
+			SELECT COUNT(*) FROM gmaps_jobs
+			WHERE parent_job_id = $1 AND status IN ('ok', 'failed')
+		)
 	WHERE id = $1::uuid
 	`
 
@@ -0,0 +1,157 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Limiter paces outgoing requests to Google Maps and adapts its rate to
+// block signals. A single Limiter is meant to be shared by every job a
+// worker runs so that one blocked page slows down all of them.
+type Limiter interface {
+	// Wait blocks until a request may be sent or ctx is done.
+	Wait(ctx context.Context) error
+	// OnBlock halves the current rate.
+	OnBlock()
+	// OnSuccess slowly raises the rate back towards the maximum.
+	OnSuccess()
+	// Delay returns the current interval between requests.
+	Delay() time.Duration
+	// Blocks returns the number of block signals seen so far.
+	Blocks() int64
+}
+
+// Config configures an adaptive limiter. Rates are in requests per second.
+type Config struct {
+	MaxRate      float64
+	MinRate      float64
+	Burst        int
+	RecoveryStep float64
+}
+
+// DefaultConfig starts at two requests per second, never drops below one
+// request every 30 seconds and needs about 20 successes to regain one
+// request per second after a block.
+func DefaultConfig() Config {
+	return Config{
+		MaxRate:      2,
+		MinRate:      1.0 / 30,
+		Burst:        4,
+		RecoveryStep: 0.05,
+	}
+}
+
+type adaptive struct {
+	cfg Config
+
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+
+	blocks atomic.Int64
+}
+
+// New creates a token bucket limiter that starts at cfg.MaxRate.
+// Zero values in cfg fall back to DefaultConfig.
+func New(cfg Config) Limiter {
+	def := DefaultConfig()
+
+	if cfg.MaxRate <= 0 {
+		cfg.MaxRate = def.MaxRate
+	}
+
+	if cfg.MinRate <= 0 || cfg.MinRate > cfg.MaxRate {
+		cfg.MinRate = min(def.MinRate, cfg.MaxRate)
+	}
+
+	if cfg.Burst <= 0 {
+		cfg.Burst = def.Burst
+	}
+
+	if cfg.RecoveryStep <= 0 {
+		cfg.RecoveryStep = def.RecoveryStep
+	}
+
+	return &adaptive{
+		cfg:    cfg,
+		rate:   cfg.MaxRate,
+		tokens: float64(cfg.Burst),
+		last:   time.Now(),
+	}
+}
+
+func (a *adaptive) Wait(ctx context.Context) error {
+	for {
+		wait := a.reserve()
+		if wait <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available and otherwise returns how
+// long to wait before the next one is.
+func (a *adaptive) reserve() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.refill()
+
+	if a.tokens >= 1 {
+		a.tokens--
+
+		return 0
+	}
+
+	return time.Duration((1 - a.tokens) / a.rate * float64(time.Second))
+}
+
+func (a *adaptive) refill() {
+	now := time.Now()
+	a.tokens = min(float64(a.cfg.Burst), a.tokens+now.Sub(a.last).Seconds()*a.rate)
+	a.last = now
+}
+
+func (a *adaptive) OnBlock() {
+	a.blocks.Add(1)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.refill()
+	a.rate = max(a.cfg.MinRate, a.rate/2)
+	// drop the burst so the next request pays the new delay
+	a.tokens = min(a.tokens, 0)
+}
+
+func (a *adaptive) OnSuccess() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.refill()
+	a.rate = min(a.cfg.MaxRate, a.rate+a.cfg.RecoveryStep)
+}
+
+func (a *adaptive) Delay() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return time.Duration(float64(time.Second) / a.rate)
+}
+
+func (a *adaptive) Blocks() int64 {
+	return a.blocks.Load()
+}
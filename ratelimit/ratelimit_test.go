@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDefaults(t *testing.T) {
+	l := New(Config{}).(*adaptive)
+
+	assert.Equal(t, DefaultConfig(), l.cfg)
+	assert.Equal(t, 500*time.Millisecond, l.Delay())
+
+	// A minimum above the maximum is lowered to it
+	l = New(Config{MaxRate: 0.01, MinRate: 1}).(*adaptive)
+	assert.Equal(t, 0.01, l.cfg.MinRate)
+}
+
+func TestOnBlockBacksOffToMinRate(t *testing.T) {
+	l := New(Config{MaxRate: 4, MinRate: 1})
+
+	l.OnBlock()
+	assert.Equal(t, 500*time.Millisecond, l.Delay())
+
+	l.OnBlock()
+	assert.Equal(t, time.Second, l.Delay())
+
+	// Halving again would drop below MinRate
+	l.OnBlock()
+	assert.Equal(t, time.Second, l.Delay())
+	assert.Equal(t, int64(3), l.Blocks())
+}
+
+func TestOnSuccessRecoversToMaxRate(t *testing.T) {
+	l := New(Config{MaxRate: 2, MinRate: 0.5, RecoveryStep: 0.5})
+
+	l.OnBlock()
+	l.OnBlock()
+	assert.Equal(t, 2*time.Second, l.Delay())
+
+	l.OnSuccess()
+	assert.Equal(t, time.Second, l.Delay())
+
+	l.OnSuccess()
+	l.OnSuccess()
+	assert.Equal(t, 500*time.Millisecond, l.Delay())
+
+	// The rate never rises above MaxRate
+	l.OnSuccess()
+	assert.Equal(t, 500*time.Millisecond, l.Delay())
+	assert.Equal(t, int64(2), l.Blocks())
+}
+
+func TestWait(t *testing.T) {
+	l := New(Config{MaxRate: 0.01, Burst: 1})
+
+	// The burst is spent without waiting
+	require.NoError(t, l.Wait(context.Background()))
+
+	// The next token is 100s away
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, l.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestOnBlockDropsBurst(t *testing.T) {
+	l := New(Config{MaxRate: 0.02, Burst: 4})
+
+	l.OnBlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, l.Wait(ctx), context.DeadlineExceeded)
+}
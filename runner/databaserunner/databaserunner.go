@@ -38,9 +38,20 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 		return nil, err
 	}
 
+	// Places are only deduplicated when a deduper is asked for
+	var providerOpts []postgres.ProviderOption
+	if !cfg.ProduceOnly && cfg.Deduper.Backend != "" {
+		dedup, err := runner.NewDeduper(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		providerOpts = append(providerOpts, postgres.WithDeduper(dedup))
+	}
+
 	ans := dbrunner{
 		cfg:      cfg,
-		provider: postgres.NewProvider(conn),
+		provider: postgres.NewProvider(conn, providerOpts...),
 		produce:  cfg.ProduceOnly,
 		conn:     conn,
 	}
@@ -50,7 +61,8 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 	}
 
 	// Use result writer with parent progress sync enabled
-	// This updates jobs_queue.completed_tasks and status when gmaps_jobs complete
+	// This updates jobs_queue task counts when gmaps_jobs complete; the manager
+	// moves the parent job to completed
 	psqlWriter := postgres.NewResultWriterWithSync(postgres.ResultWriterConfig{
 		DB:                 conn,
 		SyncParentProgress: true,
@@ -148,12 +160,9 @@ func (d *dbrunner) produceSeedJobs(ctx context.Context) error {
 		input = f
 	}
 
-	var ev emailvalidator.Validator
-	if d.cfg.EmailValidatorKey != "" {
-		ev = emailvalidator.NewMoribouncerValidator(emailvalidator.Config{
-			APIKey: d.cfg.EmailValidatorKey,
-			APIURL: d.cfg.EmailValidatorURL,
-		})
+	ev, err := emailvalidator.New(d.cfg.EmailValidatorOptions())
+	if err != nil {
+		return err
 	}
 
 	jobs, err := runner.CreateSeedJobs(
@@ -169,11 +178,19 @@ func (d *dbrunner) produceSeedJobs(ctx context.Context) error {
 		nil, // Exit monitor not used in produce mode typically, or we should create one? passing nil for now
 		ev,
 		d.cfg.ExtraReviews,
+		0,
+		"",
+		0,
+		nil,
+		nil,
+		nil,
 	)
 	if err != nil {
 		return err
 	}
 
+	runner.SetEmailPages(jobs, d.cfg.EmailPages)
+
 	for i := range jobs {
 		if err := d.provider.Push(ctx, jobs[i]); err != nil {
 			return err
@@ -0,0 +1,35 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/sadewadee/google-scraper/deduper"
+	"github.com/sadewadee/google-scraper/internal/queue"
+)
+
+// NewDeduper builds the deduper of the file and database modes. With the
+// redis backend the places are shared by every such process on the same
+// Redis, apart from the workers' keys.
+func NewDeduper(cfg *Config) (deduper.Deduper, error) {
+	if cfg.Deduper.Backend != deduper.BackendRedis {
+		return deduper.NewFromConfig(cfg.Deduper)
+	}
+
+	if cfg.RedisURL == "" && cfg.RedisAddr == "" {
+		return nil, fmt.Errorf("-deduper redis needs -redis-url or -redis-addr")
+	}
+
+	d, err := queue.NewDeduper(&queue.DedupeConfig{
+		RedisURL:  cfg.RedisURL,
+		RedisAddr: cfg.RedisAddr,
+		Password:  cfg.RedisPass,
+		DB:        cfg.RedisDB,
+		Prefix:    "dedup",
+		TTL:       cfg.DedupeTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the Redis deduper: %w", err)
+	}
+
+	return d.Scope("file"), nil
+}
@@ -0,0 +1,201 @@
+package exportrunner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/runner"
+)
+
+// apiSource exports through the manager's /api/v2/results/export
+type apiSource struct {
+	cfg        *runner.Config
+	baseURL    string
+	httpClient *http.Client
+}
+
+func newAPISource(cfg *runner.Config) *apiSource {
+	// No overall timeout: a large export streams for as long as it takes
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: 2 * time.Minute,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	return &apiSource{
+		cfg:        cfg,
+		baseURL:    strings.TrimRight(cfg.ManagerURL, "/"),
+		httpClient: &http.Client{Transport: transport},
+	}
+}
+
+func (s *apiSource) jobIDs(ctx context.Context) ([]uuid.UUID, error) {
+	if !s.cfg.ExportAllJobs {
+		id := uuid.MustParse(s.cfg.ExportJobID)
+
+		resp, err := s.do(ctx, http.MethodGet, "/api/v2/jobs/"+id.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return []uuid.UUID{id}, nil
+		case http.StatusNotFound:
+			return nil, &runner.ExitError{Code: runner.ExitNotFound, Err: fmt.Errorf("job %s not found", id)}
+		default:
+			return nil, apiError(resp)
+		}
+	}
+
+	var ids []uuid.UUID
+	for page := 1; ; page++ {
+		query := url.Values{"page": {strconv.Itoa(page)}, "per_page": {"100"}}
+		if s.cfg.ExportStatus != "" {
+			query.Set("status", s.cfg.ExportStatus)
+		}
+
+		resp, err := s.do(ctx, http.MethodGet, "/api/v2/jobs?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var list struct {
+			Data []struct {
+				ID uuid.UUID `json:"id"`
+			} `json:"data"`
+			TotalPages int `json:"total_pages"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			err = apiError(resp)
+		} else if err = json.NewDecoder(resp.Body).Decode(&list); err != nil {
+			err = fmt.Errorf("decode job list: %w", err)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, job := range list.Data {
+			ids = append(ids, job.ID)
+		}
+		if page >= list.TotalPages {
+			break
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, &runner.ExitError{Code: runner.ExitNotFound, Err: errors.New("no jobs match")}
+	}
+	if len(ids) > maxAPIJobs {
+		return nil, fmt.Errorf("%d jobs match but the manager exports at most %d at once; narrow -status or export from the database with -dsn", len(ids), maxAPIJobs)
+	}
+
+	return ids, nil
+}
+
+func (s *apiSource) export(ctx context.Context, w io.Writer, jobIDs []uuid.UUID) (int, error) {
+	ids := make([]string, len(jobIDs))
+	for i, id := range jobIDs {
+		ids[i] = id.String()
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"job_ids": ids,
+		"format":  s.cfg.ExportFormat,
+		"columns": s.cfg.ExportColumns,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := s.do(ctx, http.MethodPost, "/api/v2/results/export", body)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := apiError(resp)
+		// A job deleted since it was looked up
+		if resp.StatusCode == http.StatusBadRequest && strings.Contains(err.Error(), "Unknown jobs") {
+			return 0, &runner.ExitError{Code: runner.ExitNotFound, Err: err}
+		}
+		return 0, err
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return 0, &runner.ExitError{Code: runner.ExitTransport, Err: fmt.Errorf("download export: %w", err)}
+	}
+
+	// The manager sends the row count as a trailer once the export is
+	// complete; without it the file is truncated
+	rows, err := strconv.Atoi(resp.Trailer.Get("X-Total-Rows"))
+	if err != nil {
+		return 0, errors.New("the manager stopped the export before it was complete, see its log")
+	}
+
+	return rows, nil
+}
+
+func (s *apiSource) close() error {
+	s.httpClient.CloseIdleConnections()
+	return nil
+}
+
+// do sends an authenticated request; failing to reach the manager is a
+// transport error
+func (s *apiSource) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.cfg.APIToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.APIToken)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &runner.ExitError{Code: runner.ExitTransport, Err: fmt.Errorf("reach manager at %s: %w", s.baseURL, err)}
+	}
+
+	return resp, nil
+}
+
+// apiError turns an error response into an error. The jobs API answers
+// with a message, the results API with an error field.
+func apiError(resp *http.Response) error {
+	var body struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+
+	msg := body.Message
+	if msg == "" {
+		msg = body.Error
+	}
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+
+	return fmt.Errorf("manager returned %d: %s", resp.StatusCode, msg)
+}
@@ -0,0 +1,135 @@
+package exportrunner
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/repository/postgres"
+	"github.com/sadewadee/google-scraper/internal/service"
+	"github.com/sadewadee/google-scraper/runner"
+)
+
+// dbSource exports straight from PostgreSQL, without the job limit of the
+// manager API
+type dbSource struct {
+	cfg      *runner.Config
+	db       *sql.DB
+	jobs     *postgres.JobRepository
+	listings *service.BusinessListingService
+}
+
+func newDBSource(cfg *runner.Config) (*dbSource, error) {
+	keys, err := cfg.Keyring()
+	if err != nil {
+		return nil, &runner.ExitError{Code: runner.ExitUsage, Err: err}
+	}
+
+	db, err := postgres.OpenConnection(cfg.Dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := postgres.NewJobRepository(db)
+	jobs.SetKeyring(keys)
+
+	return &dbSource{
+		cfg:      cfg,
+		db:       db,
+		jobs:     jobs,
+		listings: service.NewBusinessListingService(postgres.NewBusinessListingRepository(db)),
+	}, nil
+}
+
+func (s *dbSource) jobIDs(ctx context.Context) ([]uuid.UUID, error) {
+	if !s.cfg.ExportAllJobs {
+		id := uuid.MustParse(s.cfg.ExportJobID)
+
+		job, err := s.jobs.GetByID(ctx, id)
+		if err != nil {
+			return nil, &runner.ExitError{Code: runner.ExitTransport, Err: fmt.Errorf("look up job: %w", err)}
+		}
+		if job == nil {
+			return nil, &runner.ExitError{Code: runner.ExitNotFound, Err: fmt.Errorf("job %s not found", id)}
+		}
+
+		return []uuid.UUID{id}, nil
+	}
+
+	params := domain.JobListParams{Limit: 500, OrderDir: "ASC"}
+	if s.cfg.ExportStatus != "" {
+		status := domain.JobStatus(s.cfg.ExportStatus)
+		params.Status = &status
+	}
+
+	var ids []uuid.UUID
+	for {
+		jobs, total, err := s.jobs.List(ctx, params)
+		if err != nil {
+			return nil, &runner.ExitError{Code: runner.ExitTransport, Err: fmt.Errorf("list jobs: %w", err)}
+		}
+
+		for _, job := range jobs {
+			ids = append(ids, job.ID)
+		}
+
+		params.Offset += len(jobs)
+		if len(jobs) == 0 || params.Offset >= total {
+			break
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, &runner.ExitError{Code: runner.ExitNotFound, Err: errors.New("no jobs match")}
+	}
+
+	return ids, nil
+}
+
+func (s *dbSource) export(ctx context.Context, w io.Writer, jobIDs []uuid.UUID) (int, error) {
+	var (
+		res *service.MultiJobExport
+		err error
+	)
+
+	filter := domain.BusinessListingFilter{}
+	switch s.cfg.ExportFormat {
+	case "csv":
+		res, err = s.listings.ExportCSVByJobIDs(ctx, w, jobIDs, filter, s.cfg.ExportColumns, nil)
+	case "json":
+		res, err = s.listings.ExportJSONByJobIDs(ctx, w, jobIDs, filter, nil)
+	case "xlsx":
+		res, err = s.listings.ExportXLSXByJobIDs(ctx, w, jobIDs, filter, s.cfg.ExportColumns, nil)
+	case "ndjson":
+		res, err = s.listings.ExportNDJSONByJobIDs(ctx, w, jobIDs, filter, nil)
+	}
+	if err != nil {
+		return 0, &runner.ExitError{Code: runner.ExitTransport, Err: fmt.Errorf("export: %w", err)}
+	}
+
+	return res.Rows, nil
+}
+
+// invalidColumn returns the first unknown -columns entry; the manager API
+// checks them itself
+func (s *dbSource) invalidColumn() string {
+	valid := make(map[string]bool)
+	for _, c := range s.listings.AvailableColumns() {
+		valid[c] = true
+	}
+	for _, c := range s.cfg.ExportColumns {
+		if !valid[c] {
+			return c
+		}
+	}
+	return ""
+}
+
+func (s *dbSource) close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,179 @@
+// Package exportrunner implements the export subcommand, which writes the
+// results of one or more jobs to a file without the web UI. It talks to
+// the manager API, or to the database directly when -dsn is set.
+package exportrunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/runner"
+)
+
+// maxAPIJobs is how many jobs the manager exports in one request
+const maxAPIJobs = 100
+
+// source is where the listings come from: the manager API or the database
+type source interface {
+	// jobIDs returns the jobs to export, failing with ExitNotFound when
+	// there are none
+	jobIDs(ctx context.Context) ([]uuid.UUID, error)
+	// export writes the listings of jobIDs to w and returns the number of
+	// rows
+	export(ctx context.Context, w io.Writer, jobIDs []uuid.UUID) (int, error)
+	close() error
+}
+
+type exporter struct {
+	cfg *runner.Config
+	src source
+}
+
+// New validates the export flags and connects to the manager or database
+func New(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.RunMode != runner.RunModeExport {
+		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
+	}
+
+	if err := validate(cfg); err != nil {
+		return nil, &runner.ExitError{Code: runner.ExitUsage, Err: err}
+	}
+
+	ans := exporter{cfg: cfg}
+
+	if cfg.Dsn != "" {
+		src, err := newDBSource(cfg)
+		if err != nil {
+			return nil, &runner.ExitError{Code: runner.ExitTransport, Err: err}
+		}
+		if c := src.invalidColumn(); c != "" {
+			_ = src.close()
+			return nil, &runner.ExitError{Code: runner.ExitUsage, Err: fmt.Errorf("invalid column %q", c)}
+		}
+		ans.src = src
+	} else {
+		ans.src = newAPISource(cfg)
+	}
+
+	return &ans, nil
+}
+
+// validate checks the flags and normalises the columns
+func validate(cfg *runner.Config) error {
+	switch {
+	case cfg.ExportJobID == "" && !cfg.ExportAllJobs:
+		return errors.New("export needs -job <id> or -all-jobs")
+	case cfg.ExportJobID != "" && cfg.ExportAllJobs:
+		return errors.New("-job and -all-jobs cannot be combined")
+	case cfg.ExportStatus != "" && !cfg.ExportAllJobs:
+		return errors.New("-status only applies to -all-jobs")
+	}
+
+	if cfg.ExportJobID != "" {
+		if _, err := uuid.Parse(cfg.ExportJobID); err != nil {
+			return fmt.Errorf("invalid job ID %q", cfg.ExportJobID)
+		}
+	}
+
+	if cfg.ExportStatus != "" && !domain.JobStatus(cfg.ExportStatus).IsValid() {
+		return fmt.Errorf("invalid -status %q", cfg.ExportStatus)
+	}
+
+	cfg.ExportFormat = strings.ToLower(cfg.ExportFormat)
+	switch cfg.ExportFormat {
+	case "csv", "xlsx":
+	case "json", "ndjson":
+		if len(cfg.ExportColumns) > 0 {
+			return fmt.Errorf("-columns does not apply to %s", cfg.ExportFormat)
+		}
+	default:
+		return fmt.Errorf("invalid -format %q (csv, json, xlsx, ndjson)", cfg.ExportFormat)
+	}
+
+	// Columns are the lower case names of /api/v2/results/columns
+	for i, c := range cfg.ExportColumns {
+		cfg.ExportColumns[i] = strings.ToLower(strings.TrimSpace(c))
+	}
+
+	return nil
+}
+
+func (e *exporter) Run(ctx context.Context) error {
+	jobIDs, err := e.src.jobIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	out, commit, err := e.openOutput()
+	if err != nil {
+		return err
+	}
+
+	progress := newProgress(out, os.Stderr)
+	rows, err := e.src.export(ctx, progress, jobIDs)
+	progress.stop()
+
+	if err == nil {
+		err = commit()
+	}
+	if err != nil {
+		e.discardOutput(out)
+		if progress.err != nil {
+			// The output file, not the source, failed
+			return progress.err
+		}
+		return err
+	}
+
+	progress.done(rows, len(jobIDs), e.outputName())
+
+	return nil
+}
+
+func (e *exporter) Close(context.Context) error {
+	return e.src.close()
+}
+
+func (e *exporter) outputName() string {
+	if e.cfg.ExportOutput == "" || e.cfg.ExportOutput == "-" {
+		return "stdout"
+	}
+	return e.cfg.ExportOutput
+}
+
+// openOutput opens the output. Files are written next to the target and
+// renamed by commit, so a failed export never leaves a truncated file
+// behind.
+func (e *exporter) openOutput() (io.Writer, func() error, error) {
+	if e.outputName() == "stdout" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+
+	f, err := os.Create(e.cfg.ExportOutput + ".part")
+	if err != nil {
+		return nil, nil, fmt.Errorf("create output file: %w", err)
+	}
+
+	commit := func() error {
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("write output file: %w", err)
+		}
+		return os.Rename(f.Name(), e.cfg.ExportOutput)
+	}
+
+	return f, commit, nil
+}
+
+func (e *exporter) discardOutput(out io.Writer) {
+	if f, ok := out.(*os.File); ok && f != os.Stdout {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+}
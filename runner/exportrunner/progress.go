@@ -0,0 +1,93 @@
+package exportrunner
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// progress counts the bytes written to the output and reports them on a
+// status line once a second
+type progress struct {
+	w       io.Writer
+	status  io.Writer
+	started time.Time
+	written atomic.Int64
+	err     error // First error writing the output
+	printed bool  // A status line is on screen
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newProgress(w, status io.Writer) *progress {
+	p := &progress{
+		w:       w,
+		status:  status,
+		started: time.Now(),
+		quit:    make(chan struct{}),
+	}
+
+	p.wg.Add(1)
+	go p.report()
+
+	return p
+}
+
+func (p *progress) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written.Add(int64(n))
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("write output: %w", err)
+	}
+	return n, err
+}
+
+func (p *progress) report() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.quit:
+			return
+		case <-ticker.C:
+			fmt.Fprintf(p.status, "\rexport: %s written (%s)", formatBytes(p.written.Load()), time.Since(p.started).Round(time.Second))
+			p.printed = true
+		}
+	}
+}
+
+// stop ends the status line
+func (p *progress) stop() {
+	close(p.quit)
+	p.wg.Wait()
+
+	if p.printed {
+		fmt.Fprintln(p.status)
+	}
+}
+
+// done prints the summary of a finished export
+func (p *progress) done(rows, jobs int, output string) {
+	elapsed := time.Since(p.started).Round(time.Millisecond)
+	fmt.Fprintf(p.status, "export: %d jobs, %d rows, %s written to %s in %s\n",
+		jobs, rows, formatBytes(p.written.Load()), output, elapsed)
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/sadewadee/google-scraper/deduper"
 	"github.com/sadewadee/google-scraper/exiter"
 	"github.com/sadewadee/google-scraper/internal/emailvalidator"
+	"github.com/sadewadee/google-scraper/internal/querycsv"
 	"github.com/sadewadee/google-scraper/leadsdb"
 	"github.com/sadewadee/google-scraper/runner"
 	"github.com/sadewadee/google-scraper/tlmt"
@@ -74,41 +75,61 @@ func (r *fileRunner) Run(ctx context.Context) (err error) {
 		_ = runner.Telemetry().Send(ctx, evt)
 	}()
 
-	dedup := deduper.New()
+	var dedup deduper.Deduper
+
+	dedup, err = runner.NewDeduper(r.cfg)
+	if err != nil {
+		return err
+	}
+
 	exitMonitor := exiter.New()
 
 	var ev emailvalidator.Validator
-	if r.cfg.EmailValidatorKey != "" {
-		ev = emailvalidator.NewMoribouncerValidator(emailvalidator.Config{
-			APIKey: r.cfg.EmailValidatorKey,
-			APIURL: r.cfg.EmailValidatorURL,
-		})
-	}
-
-	seedJobs, err = runner.CreateSeedJobs(
-		r.cfg.FastMode,
-		r.cfg.LangCode,
-		r.input,
-		r.cfg.MaxDepth,
-		r.cfg.Email,
-		r.cfg.GeoCoordinates,
-		r.cfg.Zoom,
-		r.cfg.Radius,
-		dedup,
-		exitMonitor,
-		ev,
-		r.cfg.ExtraReviews,
-	)
+
+	ev, err = emailvalidator.New(r.cfg.EmailValidatorOptions())
 	if err != nil {
 		return err
 	}
 
-	exitMonitor.SetSeedCount(len(seedJobs))
+	if r.cfg.CSVInput() {
+		seedJobs, err = r.csvSeedJobs(dedup, exitMonitor, ev)
+	} else {
+		seedJobs, err = runner.CreateSeedJobs(
+			r.cfg.FastMode,
+			r.cfg.LangCode,
+			r.input,
+			r.cfg.MaxDepth,
+			r.cfg.Email,
+			r.cfg.GeoCoordinates,
+			r.cfg.Zoom,
+			r.cfg.Radius,
+			dedup,
+			exitMonitor,
+			ev,
+			r.cfg.ExtraReviews,
+			0,
+			"",
+			0,
+			nil,
+			nil,
+			nil,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	runner.SetEmailPages(seedJobs, r.cfg.EmailPages)
+
+	places := runner.CountPlaceJobs(seedJobs)
+	exitMonitor.IncrPlacesFound(places)
+	exitMonitor.SetSeedCount(len(seedJobs) - places)
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	exitMonitor.SetCancelFunc(cancel)
+	runner.SetSearchContext(seedJobs, ctx)
 
 	go exitMonitor.Run(ctx)
 
@@ -117,6 +138,38 @@ func (r *fileRunner) Run(ctx context.Context) (err error) {
 	return err
 }
 
+// csvSeedJobs creates the seed jobs of a query CSV input. Invalid rows are
+// reported on stderr and skipped, or fail the run with -strict.
+func (r *fileRunner) csvSeedJobs(dedup deduper.Deduper, exitMonitor exiter.Exiter, ev emailvalidator.Validator) ([]scrapemate.IJob, error) {
+	rows, invalid, err := querycsv.Parse(r.input)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rowErr := range invalid {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", r.cfg.InputFile, rowErr)
+	}
+
+	if len(invalid) > 0 && r.cfg.Strict {
+		return nil, fmt.Errorf("%s: %d invalid rows", r.cfg.InputFile, len(invalid))
+	}
+
+	return runner.CreateSeedJobsFromRows(runner.SeedJobConfig{
+		FastMode:       r.cfg.FastMode,
+		LangCode:       r.cfg.LangCode,
+		Depth:          r.cfg.MaxDepth,
+		Email:          r.cfg.Email,
+		GeoCoordinates: r.cfg.GeoCoordinates,
+		Zoom:           r.cfg.Zoom,
+		Radius:         r.cfg.Radius,
+		ExtraReviews:   r.cfg.ExtraReviews,
+		Dedup:          dedup,
+		ExitMonitor:    exitMonitor,
+		EmailValidator: ev,
+		EmailPages:     r.cfg.EmailPages,
+	}, rows)
+}
+
 func (r *fileRunner) Close(context.Context) error {
 	if r.app != nil {
 		return r.app.Close()
@@ -14,6 +14,7 @@ import (
 	"github.com/sadewadee/google-scraper/exiter"
 	"github.com/sadewadee/google-scraper/gmaps"
 	"github.com/sadewadee/google-scraper/internal/emailvalidator"
+	"github.com/sadewadee/google-scraper/ratelimit"
 	"github.com/gosom/scrapemate"
 )
 
@@ -30,6 +31,12 @@ func CreateSeedJobs(
 	exitMonitor exiter.Exiter,
 	emailValidator emailvalidator.Validator,
 	extraReviews bool,
+	maxReviews int,
+	reviewsSort gmaps.ReviewSort,
+	maxImages int,
+	headers map[string]string,
+	rateLimiter ratelimit.Limiter,
+	pageCache gmaps.PageCache,
 ) (jobs []scrapemate.IJob, err error) {
 	var lat, lon float64
 
@@ -102,10 +109,32 @@ func CreateSeedJobs(
 				opts = append(opts, gmaps.WithEmailValidator(emailValidator))
 			}
 
-			if extraReviews {
+			// A review cap implies extra reviews, so jobs can ask for them
+			// without the worker-wide -extra-reviews flag
+			if extraReviews || maxReviews > 0 {
 				opts = append(opts, gmaps.WithExtraReviews())
 			}
 
+			if maxReviews > 0 || reviewsSort != "" {
+				opts = append(opts, gmaps.WithReviewLimit(maxReviews, reviewsSort))
+			}
+
+			if maxImages > 0 {
+				opts = append(opts, gmaps.WithMaxImages(maxImages))
+			}
+
+			if len(headers) > 0 {
+				opts = append(opts, gmaps.WithHeaders(headers))
+			}
+
+			if rateLimiter != nil {
+				opts = append(opts, gmaps.WithRateLimiter(rateLimiter))
+			}
+
+			if pageCache != nil {
+				opts = append(opts, gmaps.WithPageCache(pageCache))
+			}
+
 			job = gmaps.NewGmapJob(id, langCode, query, maxDepth, email, geoCoordinates, zoom, opts...)
 		} else {
 			jparams := gmaps.MapSearchParams{
@@ -127,6 +156,14 @@ func CreateSeedJobs(
 				opts = append(opts, gmaps.WithSearchJobExitMonitor(exitMonitor))
 			}
 
+			if rateLimiter != nil {
+				opts = append(opts, gmaps.WithSearchJobRateLimiter(rateLimiter))
+			}
+
+			if len(headers) > 0 {
+				opts = append(opts, gmaps.WithSearchJobHeaders(headers))
+			}
+
 			job = gmaps.NewSearchJob(&jparams, opts...)
 		}
 
@@ -2,12 +2,14 @@ package lambdaaws
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
@@ -22,6 +24,9 @@ var _ runner.Runner = (*invoker)(nil)
 type invoker struct {
 	lclient  *lambda.Client
 	payloads []lInput
+	uploader runner.S3Uploader
+	bucket   string
+	stitch   string // Job to stitch instead of invoking
 }
 
 func NewInvoker(cfg *runner.Config) (runner.Runner, error) {
@@ -44,7 +49,14 @@ func NewInvoker(cfg *runner.Config) (runner.Runner, error) {
 	}
 
 	ans := invoker{
-		lclient: lambda.NewFromConfig(awscfg),
+		lclient:  lambda.NewFromConfig(awscfg),
+		uploader: cfg.S3Uploader,
+		bucket:   cfg.S3Bucket,
+		stitch:   cfg.AwsLambdaStitch,
+	}
+
+	if ans.stitch != "" {
+		return &ans, nil
 	}
 
 	if err := ans.setPayloads(cfg); err != nil {
@@ -55,12 +67,55 @@ func NewInvoker(cfg *runner.Config) (runner.Runner, error) {
 }
 
 func (i *invoker) Run(ctx context.Context) error {
+	if i.stitch != "" {
+		return i.runStitch(ctx)
+	}
+
+	if err := i.writeJobManifest(ctx); err != nil {
+		return err
+	}
+
 	for j := range i.payloads {
 		if err := i.invoke(ctx, i.payloads[j]); err != nil {
 			return err
 		}
 	}
 
+	log.Printf("stitch the results once the lambdas are done with -aws-lambda-stitch %s", i.payloads[0].JobID)
+
+	return nil
+}
+
+// writeJobManifest tells the stitcher how many chunks the job has
+func (i *invoker) writeJobManifest(ctx context.Context) error {
+	if i.uploader == nil {
+		log.Println("no uploader set, the job cannot be stitched")
+		return nil
+	}
+
+	jobID := i.payloads[0].JobID
+
+	data, err := json.Marshal(jobManifest{JobID: jobID, Chunks: len(i.payloads), CreatedAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	if err := i.uploader.Upload(ctx, i.bucket, jobManifestKey(jobID), bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("upload job manifest: %w", err)
+	}
+
+	return nil
+}
+
+func (i *invoker) runStitch(ctx context.Context) error {
+	summary, err := stitch(ctx, i.uploader, i.bucket, i.stitch)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("stitched %d results of job %s into s3://%s/%s (chunks by status: %v, missing: %v)",
+		summary.Results, i.stitch, i.bucket, resultsKey(i.stitch), summary.Chunks, summary.Missing)
+
 	return nil
 }
 
@@ -129,6 +184,8 @@ func (i *invoker) setPayloads(cfg *runner.Config) error {
 				Language:     cfg.LangCode,
 				FunctionName: cfg.FunctionName,
 				ExtraReviews: cfg.ExtraReviews,
+				FlushEvery:   cfg.AwsLambdaFlushEvery,
+				ProgressURL:  cfg.AwsLambdaProgressURL,
 			}
 			i.payloads = append(i.payloads, payload)
 
@@ -148,6 +205,8 @@ func (i *invoker) setPayloads(cfg *runner.Config) error {
 			Language:     cfg.LangCode,
 			FunctionName: cfg.FunctionName,
 			ExtraReviews: cfg.ExtraReviews,
+			FlushEvery:   cfg.AwsLambdaFlushEvery,
+			ProgressURL:  cfg.AwsLambdaProgressURL,
 		}
 		i.payloads = append(i.payloads, payload)
 	}
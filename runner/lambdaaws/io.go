@@ -11,4 +11,6 @@ type lInput struct {
 	FunctionName     string   `json:"function_name"`
 	DisablePageReuse bool     `json:"disable_page_reuse"`
 	ExtraReviews     bool     `json:"extra_reviews"`
+	FlushEvery       int      `json:"flush_every,omitempty"`  // Results per uploaded part
+	ProgressURL      string   `json:"progress_url,omitempty"` // Progress is POSTed here after every part
 }
@@ -2,10 +2,8 @@ package lambdaaws
 
 import (
 	"context"
-	"encoding/csv"
 	"errors"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"os/exec"
@@ -18,7 +16,6 @@ import (
 	"github.com/sadewadee/google-scraper/exiter"
 	"github.com/sadewadee/google-scraper/runner"
 	"github.com/gosom/scrapemate"
-	"github.com/gosom/scrapemate/adapters/writers/csvwriter"
 	"github.com/gosom/scrapemate/scrapemateapp"
 )
 
@@ -60,15 +57,15 @@ func (l *lambdaAwsRunner) handler(ctx context.Context, input lInput) error {
 		return err
 	}
 
-	out, err := os.Create(filepath.Join(tmpDir, "output.csv"))
+	parts := newPartWriter(ctx, l.uploader, input)
+
+	app, err := l.getApp(ctx, input, parts)
 	if err != nil {
 		return err
 	}
 
-	defer out.Close()
-
-	app, err := l.getApp(ctx, input, out)
-	if err != nil {
+	// An empty manifest tells the stitcher the chunk started
+	if err := parts.finish(chunkRunning, nil); err != nil {
 		return err
 	}
 
@@ -91,6 +88,12 @@ func (l *lambdaAwsRunner) handler(ctx context.Context, input lInput) error {
 		exitMonitor,
 		nil, // Email validator not supported in lambda yet
 		input.ExtraReviews,
+		0,
+		"",
+		0,
+		nil,
+		nil,
+		nil,
 	)
 	if err != nil {
 		return err
@@ -98,7 +101,7 @@ func (l *lambdaAwsRunner) handler(ctx context.Context, input lInput) error {
 
 	exitMonitor.SetSeedCount(len(seedJobs))
 
-	bCtx, cancel := context.WithTimeout(ctx, time.Minute*10)
+	bCtx, cancel := context.WithTimeout(ctx, scrapeTimeout(ctx))
 	defer cancel()
 
 	exitMonitor.SetCancelFunc(cancel)
@@ -107,35 +110,44 @@ func (l *lambdaAwsRunner) handler(ctx context.Context, input lInput) error {
 
 	err = app.Start(bCtx, seedJobs...)
 	if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		if ferr := parts.finish(chunkFailed, err); ferr != nil {
+			log.Printf("failed to finish chunk: %v", ferr)
+		}
+
 		return err
 	}
 
-	out.Close()
+	status := chunkCompleted
+	if errors.Is(bCtx.Err(), context.DeadlineExceeded) {
+		status = chunkTimedOut
+	}
 
-	if l.uploader != nil {
-		key := fmt.Sprintf("%s-%d.csv", input.JobID, input.Part)
+	if l.uploader == nil {
+		log.Println("no uploader set results are at ", filepath.Join(os.TempDir(), input.JobID))
+	}
 
-		fd, err := os.Open(out.Name())
-		if err != nil {
-			return err
-		}
+	return parts.finish(status, nil)
+}
 
-		err = l.uploader.Upload(ctx, input.BucketName, key, fd)
-		if err != nil {
-			return err
-		}
-	} else {
-		log.Println("no uploader set results are at ", out.Name())
+// scrapeTimeout leaves the lambda time to upload the last part before it
+// is killed
+func scrapeTimeout(ctx context.Context) time.Duration {
+	const (
+		maxScrape    = 10 * time.Minute
+		uploadMargin = 30 * time.Second
+	)
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return maxScrape
 	}
 
-	return nil
+	return max(min(maxScrape, time.Until(deadline)-uploadMargin), time.Second)
 }
 
 //nolint:gocritic // we pass a value to the handler
-func (l *lambdaAwsRunner) getApp(_ context.Context, input lInput, out io.Writer) (*scrapemateapp.ScrapemateApp, error) {
-	csvWriter := csvwriter.NewCsvWriter(csv.NewWriter(out))
-
-	writers := []scrapemate.ResultWriter{csvWriter}
+func (l *lambdaAwsRunner) getApp(_ context.Context, input lInput, out scrapemate.ResultWriter) (*scrapemateapp.ScrapemateApp, error) {
+	writers := []scrapemate.ResultWriter{out}
 
 	opts := []func(*scrapemateapp.Config) error{
 		scrapemateapp.WithConcurrency(max(1, input.Concurrency)),
@@ -0,0 +1,274 @@
+package lambdaaws
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/sadewadee/google-scraper/runner"
+)
+
+// A lambda uploads its results while it scrapes, as parts of at most
+// flush_every results. Each part is an object of its own rather than a
+// part of an S3 multipart upload: those stay invisible until completed and
+// must be 5 MiB or more, so a lambda killed at its time limit would leave
+// nothing readable. After every part the lambda rewrites the manifest of
+// its chunk, which therefore always lists the parts that were uploaded.
+//
+//	<job id>/job.json                         written by the invoker
+//	<job id>/chunk-<chunk>/part-<n>.csv       CSV with a header row
+//	<job id>/chunk-<chunk>/manifest.json      the chunk's manifest
+//	<job id>/results.csv                      written by -aws-lambda-stitch
+
+// Statuses of a chunk manifest. A manifest left running belongs to a
+// lambda that was killed, or still runs.
+const (
+	chunkRunning   = "running"
+	chunkCompleted = "completed"
+	chunkTimedOut  = "timed_out"
+	chunkFailed    = "failed"
+)
+
+// defaultFlushEvery is the part size when the invocation does not set one
+const defaultFlushEvery = 500
+
+// jobManifest describes a job to the stitcher
+type jobManifest struct {
+	JobID     string    `json:"job_id"`
+	Chunks    int       `json:"chunks"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// chunkManifest lists the parts a lambda uploaded for its chunk
+type chunkManifest struct {
+	JobID     string         `json:"job_id"`
+	Chunk     int            `json:"chunk"`
+	Status    string         `json:"status"`
+	Error     string         `json:"error,omitempty"`
+	Results   int            `json:"results"`
+	Parts     []manifestPart `json:"parts"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type manifestPart struct {
+	Key     string `json:"key"`
+	Results int    `json:"results"`
+	Bytes   int    `json:"bytes"`
+}
+
+// chunkProgress is POSTed to the progress URL after every part
+type chunkProgress struct {
+	JobID       string `json:"job_id"`
+	Chunk       int    `json:"chunk"`
+	Status      string `json:"status"`
+	Parts       int    `json:"parts"`
+	Results     int    `json:"results"`
+	ManifestKey string `json:"manifest_key"`
+}
+
+func jobManifestKey(jobID string) string {
+	return jobID + "/job.json"
+}
+
+func chunkManifestKey(jobID string, chunk int) string {
+	return fmt.Sprintf("%s/chunk-%04d/manifest.json", jobID, chunk)
+}
+
+func partKey(jobID string, chunk, part int) string {
+	return fmt.Sprintf("%s/chunk-%04d/part-%05d.csv", jobID, chunk, part)
+}
+
+func resultsKey(jobID string) string {
+	return jobID + "/results.csv"
+}
+
+// partWriter is a ResultWriter that uploads every flushEvery results as a
+// part, then the manifest, then reports progress
+type partWriter struct {
+	ctx        context.Context // Uploads outlive the scrape, which is cancelled on timeout
+	uploader   runner.S3Uploader
+	bucket     string
+	flushEvery int
+	progress   string
+	client     *http.Client
+
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	csv      *csv.Writer
+	pending  int // Results in buf
+	manifest chunkManifest
+}
+
+//nolint:gocritic // we pass the handler's input as is
+func newPartWriter(ctx context.Context, uploader runner.S3Uploader, input lInput) *partWriter {
+	w := &partWriter{
+		ctx:        ctx,
+		uploader:   uploader,
+		bucket:     input.BucketName,
+		flushEvery: input.FlushEvery,
+		progress:   input.ProgressURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		manifest: chunkManifest{
+			JobID:  input.JobID,
+			Chunk:  input.Part,
+			Status: chunkRunning,
+			Parts:  []manifestPart{},
+		},
+	}
+	if w.flushEvery <= 0 {
+		w.flushEvery = defaultFlushEvery
+	}
+	w.csv = csv.NewWriter(&w.buf)
+
+	return w
+}
+
+// Run implements scrapemate.ResultWriter
+func (w *partWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		element, ok := result.Data.(scrapemate.CsvCapable)
+		if !ok {
+			return fmt.Errorf("%w: unexpected data type: %T", scrapemate.ErrorNotCsvCapable, result.Data)
+		}
+
+		if err := w.write(element); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *partWriter) write(element scrapemate.CsvCapable) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Every part is a CSV file of its own
+	if w.pending == 0 {
+		if err := w.csv.Write(element.CsvHeaders()); err != nil {
+			return err
+		}
+	}
+
+	if err := w.csv.Write(element.CsvRow()); err != nil {
+		return err
+	}
+	w.pending++
+
+	if w.pending >= w.flushEvery {
+		return w.flush(chunkRunning)
+	}
+
+	return nil
+}
+
+// finish uploads the last part and the final manifest
+func (w *partWriter) finish(status string, runErr error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if runErr != nil {
+		w.manifest.Error = runErr.Error()
+	}
+
+	return w.flush(status)
+}
+
+// flush uploads the pending results, if any, and the manifest with status.
+// w.mu must be held.
+func (w *partWriter) flush(status string) error {
+	if w.pending > 0 {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+
+		key := partKey(w.manifest.JobID, w.manifest.Chunk, len(w.manifest.Parts)+1)
+		if err := w.upload(key, w.buf.Bytes()); err != nil {
+			return fmt.Errorf("upload part %s: %w", key, err)
+		}
+
+		w.manifest.Parts = append(w.manifest.Parts, manifestPart{Key: key, Results: w.pending, Bytes: w.buf.Len()})
+		w.manifest.Results += w.pending
+		w.buf.Reset()
+		w.pending = 0
+	}
+
+	w.manifest.Status = status
+	w.manifest.UpdatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(w.manifest)
+	if err != nil {
+		return err
+	}
+
+	key := chunkManifestKey(w.manifest.JobID, w.manifest.Chunk)
+	if err := w.upload(key, data); err != nil {
+		return fmt.Errorf("upload manifest: %w", err)
+	}
+
+	w.reportProgress(key)
+
+	return nil
+}
+
+// upload writes an object to the bucket, or under /tmp without an uploader
+func (w *partWriter) upload(key string, data []byte) error {
+	if w.uploader == nil {
+		path := filepath.Join(os.TempDir(), key)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0o644)
+	}
+
+	return w.uploader.Upload(w.ctx, w.bucket, key, bytes.NewReader(data))
+}
+
+// reportProgress POSTs the progress of the chunk. Failures are only logged,
+// the manifest is the record.
+func (w *partWriter) reportProgress(manifestKey string) {
+	if w.progress == "" {
+		return
+	}
+
+	body, err := json.Marshal(chunkProgress{
+		JobID:       w.manifest.JobID,
+		Chunk:       w.manifest.Chunk,
+		Status:      w.manifest.Status,
+		Parts:       len(w.manifest.Parts),
+		Results:     w.manifest.Results,
+		ManifestKey: manifestKey,
+	})
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, w.progress, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("progress callback: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		log.Printf("progress callback: %v", err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		log.Printf("progress callback: status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,163 @@
+package lambdaaws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/sadewadee/google-scraper/runner"
+	"github.com/sadewadee/google-scraper/s3uploader"
+)
+
+// stitcher joins the parts of every chunk of a job into one CSV file with
+// a single header row, streamed to S3 as a multipart upload
+type stitcher struct {
+	uploader runner.S3Uploader
+	bucket   string
+	jobID    string
+
+	uploadID string
+	parts    []s3uploader.CompletedPart
+	buf      bytes.Buffer
+	header   bool // The header row is written
+}
+
+// stitchSummary counts the chunks of a job by the status of their manifest
+type stitchSummary struct {
+	Chunks  map[string]int
+	Missing []int // Chunks without a manifest, whose lambda never started
+	Results int
+}
+
+// stitch writes <job id>/results.csv from the chunk manifests. Chunks that
+// timed out or were killed contribute the parts they uploaded.
+func stitch(ctx context.Context, uploader runner.S3Uploader, bucket, jobID string) (*stitchSummary, error) {
+	if uploader == nil {
+		return nil, errors.New("stitching needs S3 credentials")
+	}
+
+	var job jobManifest
+	if err := readJSON(ctx, uploader, bucket, jobManifestKey(jobID), &job); err != nil {
+		return nil, fmt.Errorf("read job manifest: %w", err)
+	}
+
+	s := &stitcher{uploader: uploader, bucket: bucket, jobID: jobID}
+
+	uploadID, err := uploader.CreateMultipartUpload(ctx, bucket, resultsKey(jobID))
+	if err != nil {
+		return nil, fmt.Errorf("start upload: %w", err)
+	}
+	s.uploadID = uploadID
+
+	summary, err := s.run(ctx, job.Chunks)
+	if err != nil {
+		if abortErr := uploader.AbortMultipartUpload(ctx, bucket, resultsKey(jobID), uploadID); abortErr != nil {
+			log.Printf("failed to abort upload of %s: %v", resultsKey(jobID), abortErr)
+		}
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func (s *stitcher) run(ctx context.Context, chunks int) (*stitchSummary, error) {
+	summary := &stitchSummary{Chunks: make(map[string]int)}
+
+	for chunk := range chunks {
+		var m chunkManifest
+		err := readJSON(ctx, s.uploader, s.bucket, chunkManifestKey(s.jobID, chunk), &m)
+		if errors.Is(err, s3uploader.ErrNotFound) {
+			summary.Missing = append(summary.Missing, chunk)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read manifest of chunk %d: %w", chunk, err)
+		}
+
+		summary.Chunks[m.Status]++
+
+		for _, p := range m.Parts {
+			if err := s.add(ctx, p.Key); err != nil {
+				return nil, fmt.Errorf("add part %s: %w", p.Key, err)
+			}
+			summary.Results += p.Results
+		}
+	}
+
+	// The last part may be small, and a job without results still gets
+	// its file
+	if err := s.flush(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := s.uploader.CompleteMultipartUpload(ctx, s.bucket, resultsKey(s.jobID), s.uploadID, s.parts); err != nil {
+		return nil, fmt.Errorf("complete upload: %w", err)
+	}
+
+	return summary, nil
+}
+
+// add appends a part, without its header row after the first, and uploads
+// what has piled up once S3 accepts it as a part
+func (s *stitcher) add(ctx context.Context, key string) error {
+	body, err := s.uploader.Download(ctx, s.bucket, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	// Headers are plain column names, so the first line is the header row
+	if s.header {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		} else {
+			data = nil
+		}
+	}
+	s.header = true
+
+	s.buf.Write(data)
+
+	if s.buf.Len() >= s3uploader.MinPartSize {
+		return s.flush(ctx)
+	}
+
+	return nil
+}
+
+func (s *stitcher) flush(ctx context.Context) error {
+	if s.buf.Len() == 0 && len(s.parts) > 0 {
+		return nil
+	}
+
+	number := int32(len(s.parts) + 1)
+
+	etag, err := s.uploader.UploadPart(ctx, s.bucket, resultsKey(s.jobID), s.uploadID, number, bytes.NewReader(s.buf.Bytes()))
+	if err != nil {
+		return fmt.Errorf("upload part %d: %w", number, err)
+	}
+
+	s.parts = append(s.parts, s3uploader.CompletedPart{PartNumber: number, ETag: etag})
+	s.buf.Reset()
+
+	return nil
+}
+
+func readJSON(ctx context.Context, uploader runner.S3Uploader, bucket, key string, v any) error {
+	body, err := uploader.Download(ctx, bucket, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	return json.NewDecoder(body).Decode(v)
+}
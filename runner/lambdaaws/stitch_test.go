@@ -0,0 +1,98 @@
+package lambdaaws
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sadewadee/google-scraper/s3uploader"
+)
+
+// memUploader keeps objects in memory; multipart uploads are joined on
+// completion
+type memUploader struct {
+	objects map[string][]byte
+	parts   map[string]map[int32][]byte
+}
+
+func newMemUploader() *memUploader {
+	return &memUploader{objects: map[string][]byte{}, parts: map[string]map[int32][]byte{}}
+}
+
+func (m *memUploader) Upload(_ context.Context, _, key string, body io.Reader) error {
+	data, err := io.ReadAll(body)
+	m.objects[key] = data
+	return err
+}
+
+func (m *memUploader) Download(_ context.Context, _, key string) (io.ReadCloser, error) {
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", s3uploader.ErrNotFound, key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memUploader) CreateMultipartUpload(_ context.Context, _, key string) (string, error) {
+	m.parts[key] = map[int32][]byte{}
+	return key, nil
+}
+
+func (m *memUploader) UploadPart(_ context.Context, _, _, uploadID string, n int32, body io.Reader) (string, error) {
+	data, err := io.ReadAll(body)
+	m.parts[uploadID][n] = data
+	return fmt.Sprint(n), err
+}
+
+func (m *memUploader) CompleteMultipartUpload(_ context.Context, _, key, uploadID string, parts []s3uploader.CompletedPart) error {
+	var buf bytes.Buffer
+	for _, p := range parts {
+		buf.Write(m.parts[uploadID][p.PartNumber])
+	}
+	m.objects[key] = buf.Bytes()
+	return nil
+}
+
+func (m *memUploader) AbortMultipartUpload(_ context.Context, _, _, uploadID string) error {
+	delete(m.parts, uploadID)
+	return nil
+}
+
+type row string
+
+func (r row) CsvHeaders() []string { return []string{"title"} }
+func (r row) CsvRow() []string     { return []string{string(r)} }
+
+func TestStitch(t *testing.T) {
+	ctx := context.Background()
+	up := newMemUploader()
+
+	require.NoError(t, up.Upload(ctx, "b", jobManifestKey("job"), strings.NewReader(`{"job_id":"job","chunks":3}`)))
+
+	// Chunk 0 completes with two parts, chunk 1 is killed after its first
+	// part and chunk 2 never starts
+	w := newPartWriter(ctx, up, lInput{JobID: "job", Part: 0, BucketName: "b", FlushEvery: 2})
+	for _, r := range []row{"a", "b", "c"} {
+		require.NoError(t, w.write(r))
+	}
+	require.NoError(t, w.finish(chunkCompleted, nil))
+
+	w = newPartWriter(ctx, up, lInput{JobID: "job", Part: 1, BucketName: "b", FlushEvery: 2})
+	for _, r := range []row{"d", "e", "lost"} {
+		require.NoError(t, w.write(r))
+	}
+
+	summary, err := stitch(ctx, up, "b", "job")
+	require.NoError(t, err)
+
+	assert.Equal(t, "title\na\nb\nc\nd\ne\n", string(up.objects[resultsKey("job")]))
+	assert.Equal(t, 5, summary.Results)
+	assert.Equal(t, map[string]int{chunkCompleted: 1, chunkRunning: 1}, summary.Chunks)
+	assert.Equal(t, []int{2}, summary.Missing)
+}
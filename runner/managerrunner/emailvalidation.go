@@ -0,0 +1,50 @@
+package managerrunner
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/sadewadee/google-scraper/internal/cache"
+	"github.com/sadewadee/google-scraper/internal/emailvalidator"
+	"github.com/sadewadee/google-scraper/internal/repository/postgres"
+	"github.com/sadewadee/google-scraper/internal/service"
+)
+
+// newEmailValidationService validates emails through the shared Redis list
+// when Redis is connected, otherwise through a queue of this manager. Without
+// a provider only the stats are served.
+func newEmailValidationService(cfg *Config, db *sql.DB, redisCache cache.Cache) *service.EmailValidationService {
+	repo := postgres.NewEmailValidationRepository(db)
+
+	disabled := service.NewEmailValidationService(repo, nil, nil, 0, cfg.EmailValidationTTL)
+
+	if cfg.EmailValidationWorkers <= 0 {
+		log.Println("manager: background email validation disabled")
+		return disabled
+	}
+
+	provider, err := emailvalidator.New(cfg.EmailValidator)
+	if err != nil {
+		log.Printf("manager: WARNING - background email validation disabled: %v", err)
+		return disabled
+	}
+	if provider == nil {
+		log.Println("manager: background email validation disabled (no provider)")
+		return disabled
+	}
+
+	validator := emailvalidator.NewCachedValidator(provider, repo, cfg.EmailValidationTTL)
+
+	var queue emailvalidator.Queue
+	if rc, ok := redisCache.(*cache.RedisCache); ok {
+		queue = emailvalidator.NewRedisQueue(rc.Client(), emailvalidator.DefaultQueueKey)
+		log.Printf("manager: %s email validation enabled with %d workers on the Redis queue",
+			cfg.EmailValidator.ResolvedProvider(), cfg.EmailValidationWorkers)
+	} else {
+		queue = emailvalidator.NewMemoryQueue(cfg.EmailValidationWorkers * 20)
+		log.Printf("manager: %s email validation enabled with %d workers on an in-memory queue",
+			cfg.EmailValidator.ResolvedProvider(), cfg.EmailValidationWorkers)
+	}
+
+	return service.NewEmailValidationService(repo, validator, queue, cfg.EmailValidationWorkers, cfg.EmailValidationTTL)
+}
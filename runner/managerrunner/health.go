@@ -0,0 +1,145 @@
+package managerrunner
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sadewadee/google-scraper/internal/api/handlers"
+	"github.com/sadewadee/google-scraper/internal/cache"
+	"github.com/sadewadee/google-scraper/internal/migration"
+	"github.com/sadewadee/google-scraper/internal/mq"
+	"github.com/sadewadee/google-scraper/internal/proxygate"
+	"github.com/sadewadee/google-scraper/internal/queue"
+)
+
+// newHealthHandler registers a check for every dependency the manager was
+// configured with. Only the database is critical; Redis and RabbitMQ have
+// fallbacks and ProxyGate only matters to jobs that use proxies.
+func newHealthHandler(cfg *Config, db *sql.DB, isPostgres bool, jobQueue *queue.Queue, redisCache cache.Cache, mqPublisher mq.Publisher, pg *proxygate.ProxyGate) *handlers.HealthHandler {
+	health := handlers.NewHealthHandler()
+
+	health.AddCheck(handlers.HealthCheck{
+		Name:     "database",
+		Critical: true,
+		Check: func(ctx context.Context) (map[string]interface{}, error) {
+			return nil, db.PingContext(ctx)
+		},
+	})
+
+	if isPostgres {
+		health.AddCheck(handlers.HealthCheck{
+			Name:      "migrations",
+			ReadyOnly: true,
+			Check:     checkMigrations(db),
+		})
+	}
+
+	if cfg.RedisURL != "" || cfg.RedisAddr != "" {
+		health.AddCheck(handlers.HealthCheck{
+			Name: "redis",
+			Check: func(ctx context.Context) (map[string]interface{}, error) {
+				if jobQueue != nil {
+					return nil, jobQueue.Ping()
+				}
+				if rc, ok := redisCache.(*cache.RedisCache); ok {
+					return nil, rc.Client().Ping(ctx).Err()
+				}
+				return nil, errors.New("not connected since startup")
+			},
+		})
+	}
+
+	if cfg.RabbitMQURL != "" {
+		health.AddCheck(handlers.HealthCheck{
+			Name: "rabbitmq",
+			Check: func(_ context.Context) (map[string]interface{}, error) {
+				if mqPublisher == nil {
+					return nil, errors.New("not connected since startup")
+				}
+				return nil, mqPublisher.Ping()
+			},
+		})
+	}
+
+	if pg != nil {
+		health.AddCheck(handlers.HealthCheck{
+			Name: "proxygate",
+			Check: func(_ context.Context) (map[string]interface{}, error) {
+				total, healthy, lastUpdated := pg.GetStats()
+				sources := len(pg.GetSources())
+
+				details := map[string]interface{}{
+					"total_proxies":   total,
+					"healthy_proxies": healthy,
+					"sources":         sources,
+				}
+				if !lastUpdated.IsZero() {
+					details["last_updated"] = lastUpdated
+				}
+
+				if sources > 0 && healthy == 0 {
+					return details, errors.New("no healthy proxies in the pool")
+				}
+				return details, nil
+			},
+		})
+	}
+
+	return health
+}
+
+// checkMigrations fails while an embedded migration is not recorded in
+// schema_migrations, e.g. while another replica is still applying it
+func checkMigrations(db *sql.DB) handlers.HealthCheckFunc {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		entries, err := migrationsFS.ReadDir("migrations")
+		if err != nil {
+			return nil, fmt.Errorf("read migrations: %w", err)
+		}
+
+		rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+		if err != nil {
+			return nil, fmt.Errorf("read schema_migrations: %w", err)
+		}
+		defer rows.Close()
+
+		applied := make(map[string]bool)
+		for rows.Next() {
+			var version string
+			if err := rows.Scan(&version); err != nil {
+				return nil, err
+			}
+			applied[version] = true
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		var pending []string
+		for _, entry := range entries {
+			version, ok := strings.CutSuffix(entry.Name(), ".up.sql")
+			if ok && !applied[version] {
+				pending = append(pending, version)
+			}
+		}
+
+		details := map[string]interface{}{"applied": len(applied)}
+		if len(pending) > 0 {
+			details["pending"] = pending
+			return details, fmt.Errorf("%d migrations pending", len(pending))
+		}
+
+		state, err := migration.DetectMigrationState(ctx, db)
+		if err != nil {
+			return details, fmt.Errorf("detect DSN bridge state: %w", err)
+		}
+		if state != migration.StateAlreadyMigrated {
+			return details, fmt.Errorf("DSN bridge not migrated (%s)", state)
+		}
+
+		return details, nil
+	}
+}
@@ -6,28 +6,41 @@ import (
 	"embed"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/robfig/cron/v3"
 
 	"github.com/sadewadee/google-scraper/internal/api"
 	"github.com/sadewadee/google-scraper/internal/api/handlers"
+	"github.com/sadewadee/google-scraper/internal/autoscale"
 	"github.com/sadewadee/google-scraper/internal/cache"
 	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/emailvalidator"
+	"github.com/sadewadee/google-scraper/internal/events"
+	"github.com/sadewadee/google-scraper/internal/grpcapi"
 	"github.com/sadewadee/google-scraper/internal/heartbeat"
+	"github.com/sadewadee/google-scraper/internal/leader"
 	"github.com/sadewadee/google-scraper/internal/migration"
 	"github.com/sadewadee/google-scraper/internal/mq"
 	"github.com/sadewadee/google-scraper/internal/proxygate"
 	"github.com/sadewadee/google-scraper/internal/queue"
 	"github.com/sadewadee/google-scraper/internal/repository/postgres"
+	"github.com/sadewadee/google-scraper/internal/report"
 	"github.com/sadewadee/google-scraper/internal/repository/sqlite"
+	"github.com/sadewadee/google-scraper/internal/secrets"
 	"github.com/sadewadee/google-scraper/internal/service"
+	"github.com/sadewadee/google-scraper/internal/slowlog"
 	"github.com/sadewadee/google-scraper/internal/spawner"
+	"github.com/sadewadee/google-scraper/internal/websitecheck"
 	gmapspostgres "github.com/sadewadee/google-scraper/postgres"
 	"github.com/sadewadee/google-scraper/runner"
 	"golang.org/x/sync/errgroup"
@@ -44,12 +57,18 @@ type Config struct {
 	// Address is the HTTP server address
 	Address string
 
+	// GRPCAddress, when set, also serves the worker protocol over gRPC
+	GRPCAddress string
+
 	// StaticFolder is the path to static frontend files
 	StaticFolder string
 
 	// DataFolder is where to store temporary files
 	DataFolder string
 
+	// MaxExpandedKeywords caps base_keywords × locations expansion (0 = default)
+	MaxExpandedKeywords int
+
 	// Redis configuration for job queue and cache
 	RedisURL  string
 	RedisAddr string
@@ -59,6 +78,23 @@ type Config struct {
 	// RabbitMQ configuration for job queue
 	RabbitMQURL string
 
+	// RabbitMQManagementURL is the management API queue ages are read
+	// from; defaults to port 15672 of the RabbitMQURL host
+	RabbitMQManagementURL string
+
+	// Background validation of stored emails (PostgreSQL only); enabled
+	// when EmailValidator selects a provider and EmailValidationWorkers > 0
+	EmailValidator         emailvalidator.Options
+	EmailValidationWorkers int
+	EmailValidationTTL     time.Duration
+
+	// Background checks of the websites of jobs with check_website
+	// (PostgreSQL only, 0 workers = off). Checks of a domain are reused
+	// for WebsiteCheckTTL; WebsiteCheckProxy, e.g. ProxyGate, is optional.
+	WebsiteCheckWorkers int
+	WebsiteCheckTTL     time.Duration
+	WebsiteCheckProxy   string
+
 	// Spawner configuration for auto-spawning workers
 	SpawnerType        string            // none, docker, swarm, lambda
 	SpawnerImage       string            // Docker image for worker containers
@@ -71,11 +107,56 @@ type Config struct {
 	SpawnerManagerURL  string            // Manager URL for spawned workers (Dokploy: use service name)
 	SpawnerProxies     string            // Proxy URL for spawned workers (e.g., socks5://manager:8081)
 
+	// Auto-scaling policy for docker and swarm spawners
+	SpawnerScaleInterval time.Duration // How often the worker pool is evaluated
+	SpawnerIdleTimeout   time.Duration // Idle time after which a spawned worker is terminated
+	SpawnerJobsPerWorker int           // Pending jobs per additional worker
+
 	// AWS Lambda spawner configuration
 	SpawnerLambdaFunction   string // Lambda function name/ARN
 	SpawnerLambdaRegion     string // AWS region for Lambda
 	SpawnerLambdaInvocation string // Event (async) or RequestResponse (sync)
 	SpawnerLambdaMaxConc    int    // Max concurrent Lambda invocations
+
+	// LeaderLockTTL is the lifetime of the leader lock (0 = leader.DefaultTTL)
+	LeaderLockTTL time.Duration
+
+	// DeletedJobRetentionDays is how long deleted jobs are kept before they
+	// are purged with their results (0 = forever)
+	DeletedJobRetentionDays int
+
+	// JobEventRetentionDays is how long the audit trail of jobs is kept
+	// (0 = forever, PostgreSQL only)
+	JobEventRetentionDays int
+
+	// JobTimeoutGrace multiplies the max_time of running jobs into how long
+	// a worker may hold them before they are timed out (0 = never)
+	JobTimeoutGrace float64
+
+	// LateResultsWindow is how long after a job timed out or failed as
+	// unreachable the results and completion a worker delivers from its
+	// outbox still count (0 = not at all)
+	LateResultsWindow time.Duration
+
+	// MaintenanceSchedule is a cron expression for automatic database
+	// maintenance runs ("" = none)
+	MaintenanceSchedule string
+
+	// SMTP is the server job reports are emailed through (no host = none)
+	SMTP report.SMTPConfig
+
+	// SlowRequestThreshold and SlowQueryThreshold are how long requests
+	// and queries may take before they are logged as slow (0 = never)
+	SlowRequestThreshold time.Duration
+	SlowQueryThreshold   time.Duration
+
+	// Secrets encrypts the proxy credentials of jobs and the proxy pool
+	// before they are stored (nil = plaintext)
+	Secrets *secrets.Keyring
+
+	// SkipPreflight starts the manager without checking its dependencies
+	// first, see Preflight
+	SkipPreflight bool
 }
 
 // ManagerRunner runs the manager (Web UI + API) without scraping
@@ -83,6 +164,7 @@ type ManagerRunner struct {
 	cfg       *Config
 	db        *sql.DB
 	srv       *http.Server
+	grpcSrv   *grpcapi.Server // nil without GRPCAddress
 	jobSvc    *service.JobService
 	workerSvc *service.WorkerService
 	resultSvc *service.ResultService
@@ -93,6 +175,12 @@ type ManagerRunner struct {
 	mqPub     mq.Publisher
 	cache     cache.Cache
 	spawner   spawner.Spawner
+	scaler    *autoscale.Scaler
+	events    events.Broker
+	seedTasks *service.SeedTaskService
+	emails    *service.EmailValidationService
+	elector   *leader.Elector
+	locker    *leader.RedisLocker // Closed with the runner when Redis holds the lock
 }
 
 // New creates a new ManagerRunner
@@ -115,6 +203,24 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 		return nil, fmt.Errorf("failed to create data folder: %w", err)
 	}
 
+	// A misconfigured dependency fails here with a hint rather than later
+	// and obscurely
+	if !cfg.SkipPreflight {
+		results := Preflight(context.Background(), cfg, pg)
+		PrintPreflight(os.Stderr, results)
+		if err := preflightError(results); err != nil {
+			return nil, fmt.Errorf("%w (start anyway with -skip-preflight)", err)
+		}
+	}
+
+	var maintenanceSchedule cron.Schedule
+	if cfg.MaintenanceSchedule != "" {
+		var err error
+		if maintenanceSchedule, err = service.ParseMaintenanceSchedule(cfg.MaintenanceSchedule); err != nil {
+			return nil, err
+		}
+	}
+
 	var (
 		db         *sql.DB
 		jobRepo    domain.JobRepository
@@ -122,6 +228,8 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 		resultRepo domain.ResultRepository
 		proxyRepo  domain.ProxyRepository
 		businessListingRepo domain.BusinessListingRepository
+		timeSeriesRepo domain.TimeSeriesRepository
+		maintenanceRepo domain.MaintenanceRepository
 		err        error
 	)
 
@@ -130,6 +238,9 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 
 	log.Printf("manager: database type isPostgres=%v", isPostgres)
 
+	// Slow requests and queries, shown on /api/v2/admin/slowlog
+	slowLog := slowlog.New(slowlog.Thresholds{HTTP: cfg.SlowRequestThreshold, DB: cfg.SlowQueryThreshold})
+
 	if isPostgres {
 		log.Println("manager: connecting to PostgreSQL...")
 
@@ -158,10 +269,15 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 
 		// Initialize repositories
 		repos := postgres.NewRepositories(db)
-		jobRepo = repos.Jobs
+		pgJobRepo := postgres.NewJobRepository(slowlog.WrapDB(db, slowLog))
+		pgJobRepo.SetKeyring(cfg.Secrets)
+		repos.Proxies.SetKeyring(cfg.Secrets)
+		jobRepo = pgJobRepo
 		workerRepo = repos.Workers
 		resultRepo = repos.Results
 		proxyRepo = repos.Proxies
+		timeSeriesRepo = postgres.NewTimeSeriesRepository(db)
+		maintenanceRepo = postgres.NewMaintenanceRepository(db)
 
 		// Note: BusinessListingRepository is initialized later after Redis cache is ready
 		// to enable caching for expensive COUNT queries
@@ -172,10 +288,11 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 		}
 
 		// Open SQLite connection
-		db, err = sqlite.OpenConnection(cfg.DatabaseURL)
+		sqliteDB, err := sqlite.OpenConnection(cfg.DatabaseURL)
 		if err != nil {
 			return nil, fmt.Errorf("failed to connect to database: %w", err)
 		}
+		db = sqliteDB.DB
 
 		// Run migrations automatically
 		if err := sqlite.RunMigrations(db); err != nil {
@@ -184,10 +301,13 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 		}
 
 		// Initialize repositories
-		repos := sqlite.NewRepositories(db)
+		repos := sqlite.NewRepositories(sqliteDB)
+		repos.Jobs.SetKeyring(cfg.Secrets)
 		jobRepo = repos.Jobs
 		workerRepo = repos.Workers
 		resultRepo = repos.Results
+		timeSeriesRepo = repos.TimeSeries
+		maintenanceRepo = repos.Maintenance
 	}
 
 	// Initialize Redis queue (optional - gracefully handles missing Redis)
@@ -244,7 +364,7 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 	// Initialize BusinessListingRepository with caching (PostgreSQL only)
 	// This is done after Redis cache is ready to enable caching for expensive COUNT queries
 	if isPostgres {
-		cachedRepo := postgres.NewCachedBusinessListingRepository(db, redisCache)
+		cachedRepo := postgres.NewCachedBusinessListingRepository(slowlog.WrapDB(db, slowLog), redisCache)
 		businessListingRepo = cachedRepo
 		log.Println("manager: BusinessListingRepository initialized with caching support")
 
@@ -261,7 +381,10 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 	// Initialize RabbitMQ publisher (optional)
 	var mqPublisher mq.Publisher
 	if cfg.RabbitMQURL != "" {
-		pub, err := mq.NewPublisher(mq.Config{URL: cfg.RabbitMQURL})
+		pub, err := mq.NewPublisher(mq.Config{
+			URL:           cfg.RabbitMQURL,
+			ManagementURL: cfg.RabbitMQManagementURL,
+		})
 		if err != nil {
 			log.Printf("manager: WARNING - failed to connect to RabbitMQ: %v", err)
 			log.Println("manager: continuing without RabbitMQ (fallback to Redis queue if available)")
@@ -293,67 +416,62 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 		log.Println("manager: WARNING - using deprecated SQLite mode without DSN bridge")
 	}
 	workerSvc := service.NewWorkerService(workerRepo, jobRepo)
+
+	// Live job and worker events: Redis pub/sub lets every manager replica
+	// see updates handled by the others; without Redis they stay in-process
+	var jobEvents events.Broker = events.NewMemoryBroker()
+	if cfg.RedisURL != "" || cfg.RedisAddr != "" {
+		b, err := events.NewRedisBroker(events.RedisConfig{
+			RedisURL:     cfg.RedisURL,
+			RedisAddr:    cfg.RedisAddr,
+			Password:     cfg.RedisPass,
+			DB:           cfg.RedisDB,
+			WorkerEvents: true,
+		})
+		if err != nil {
+			log.Printf("manager: WARNING - failed to connect Redis events, using in-process events: %v", err)
+		} else {
+			jobEvents = b
+			log.Println("manager: job events relayed through Redis pub/sub")
+		}
+	}
+	jobSvc.SetEvents(jobEvents)
+	jobSvc.SetMaxExpandedKeywords(cfg.MaxExpandedKeywords)
+	jobSvc.SetLateResultsWindow(cfg.LateResultsWindow)
+	workerSvc.SetLateResultsWindow(cfg.LateResultsWindow)
+	jobSvc.SetWorkerConcurrency(cfg.SpawnerConcurrency * max(cfg.SpawnerMaxWorkers, 1))
+	workerSvc.SetEvents(jobEvents)
+	workerSvc.SetWorkerEvents(jobEvents)
+	jobSvc.SetClaimable(jobEvents)
+	workerSvc.SetClaimable(jobEvents)
 	resultSvc := service.NewResultService(resultRepo)
 	statsSvc := service.NewStatsService(jobRepo, workerRepo, resultRepo)
 
 	// Initialize spawner for auto-spawning workers
-	var workerSpawner spawner.Spawner
+	var (
+		workerSpawner spawner.Spawner
+		workerScaler  *autoscale.Scaler
+	)
 	if cfg.SpawnerType != "" && cfg.SpawnerType != "none" {
-		// Determine Manager URL for spawned workers
-		// For Dokploy/Swarm: use service name (e.g., http://manager:8080)
-		// For local Docker: use host.docker.internal or localhost
-		managerURL := cfg.SpawnerManagerURL
-		if managerURL == "" {
-			// Auto-detect based on spawner type
-			if cfg.SpawnerType == "swarm" {
-				// For Swarm, try to use hostname as service name
-				hostname, _ := os.Hostname()
-				managerURL = fmt.Sprintf("http://%s%s", hostname, cfg.Address)
-				log.Printf("manager: auto-detected manager URL for Swarm: %s", managerURL)
-			} else {
-				// For local Docker, use localhost
-				managerURL = "http://localhost" + cfg.Address
-			}
-		}
-
-		spawnerCfg := &spawner.Config{
-			Type:        spawner.SpawnerType(cfg.SpawnerType),
-			ManagerURL:  managerURL,
-			RabbitMQURL: cfg.RabbitMQURL,
-			RedisAddr:   cfg.RedisAddr,
-			Proxies:     cfg.SpawnerProxies,
-			Docker: spawner.DockerConfig{
-				Image:       cfg.SpawnerImage,
-				Network:     cfg.SpawnerNetwork,
-				Concurrency: cfg.SpawnerConcurrency,
-				AutoRemove:  cfg.SpawnerAutoRemove,
-				MaxWorkers:  cfg.SpawnerMaxWorkers,
-				Environment: cfg.SpawnerLabels,
-			},
-			Swarm: spawner.SwarmConfig{
-				Image:       cfg.SpawnerImage,
-				Network:     cfg.SpawnerNetwork,
-				Concurrency: cfg.SpawnerConcurrency,
-				MaxServices: cfg.SpawnerMaxWorkers,
-				Labels:      cfg.SpawnerLabels,
-				Constraints: cfg.SpawnerConstraints,
-			},
-			Lambda: spawner.LambdaConfig{
-				FunctionName:   cfg.SpawnerLambdaFunction,
-				Region:         cfg.SpawnerLambdaRegion,
-				InvocationType: cfg.SpawnerLambdaInvocation,
-				MaxConcurrent:  cfg.SpawnerLambdaMaxConc,
-			},
-		}
-
-		sp, err := spawner.New(spawnerCfg)
+		sp, err := spawner.New(newSpawnerConfig(cfg))
 		if err != nil {
 			log.Printf("manager: WARNING - failed to initialize spawner: %v", err)
 			log.Println("manager: continuing without auto-spawn (workers must be started manually)")
 		} else {
 			workerSpawner = sp
-			jobSvc.SetSpawner(sp)
 			log.Printf("manager: spawner initialized (type: %s)", cfg.SpawnerType)
+
+			if cfg.SpawnerType == string(spawner.SpawnerTypeLambda) {
+				// A Lambda invocation runs the one job it was invoked for
+				jobSvc.SetSpawner(sp)
+			} else {
+				workerScaler = autoscale.New(sp, jobSvc, workerSvc, autoscale.Config{
+					Interval:      cfg.SpawnerScaleInterval,
+					IdleTimeout:   cfg.SpawnerIdleTimeout,
+					JobsPerWorker: cfg.SpawnerJobsPerWorker,
+					MaxWorkers:    cfg.SpawnerMaxWorkers,
+				})
+			}
 		}
 	} else {
 		log.Println("manager: auto-spawn disabled (use -spawner docker|swarm|lambda to enable)")
@@ -393,26 +511,54 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 		log.Println("manager: using cached handlers for dashboard read operations")
 	}
 	workerHandler := handlers.NewWorkerHandler(workerSvc)
+	workerHandler.SetClaimable(jobEvents)
+	timeSeriesHandler := handlers.NewTimeSeriesHandler(service.NewTimeSeriesService(timeSeriesRepo, jobRepo), redisCache)
 	proxyHandler := handlers.NewProxyHandler(pg, proxyRepo)
 
+	// Results are copied in unnormalized and turned into listings by the
+	// normalizer (PostgreSQL only)
+	var normalizerSvc *service.NormalizerService
+	var normalizeQueue domain.NormalizeQueueRepository
+	if isPostgres {
+		normalizeQueue = postgres.NewNormalizeQueueRepository(db)
+		normalizerSvc = service.NewNormalizerService(normalizeQueue)
+	}
+
 	// Create BusinessListingHandler for normalized data access (PostgreSQL only)
 	var businessListingHandler *handlers.BusinessListingHandler
 	if businessListingRepo != nil {
 		businessListingSvc := service.NewBusinessListingService(businessListingRepo)
+		if normalizeQueue != nil {
+			businessListingSvc.SetNormalizeQueue(normalizeQueue)
+		}
 		businessListingHandler = handlers.NewBusinessListingHandler(businessListingSvc)
+		businessListingHandler.SetJobs(jobSvc)
 		log.Println("manager: BusinessListingHandler initialized for normalized data access")
 	}
 
 	// Create ProxyListRepository for listing individual proxies (PostgreSQL only)
 	var proxyListRepo domain.ProxyListRepository
 	if isPostgres {
-		proxyListRepo = postgres.NewProxyListRepository(db)
+		pgProxyListRepo := postgres.NewProxyListRepository(db)
+		pgProxyListRepo.SetKeyring(cfg.Secrets)
+		proxyListRepo = pgProxyListRepo
 		proxyHandler.SetProxyListRepo(proxyListRepo)
+		// Geo-targeted jobs draw their proxies from the same pool
+		jobSvc.SetProxyList(proxyListRepo)
 		// Also set pool repo for ProxyGate to persist fetched proxies
 		if pg != nil {
 			pg.SetPoolRepo(proxyListRepo)
 			log.Println("manager: ProxyGate pool connected to database for persistence")
 
+			proxyEventRepo := postgres.NewProxyEventRepository(db)
+			pg.SetEventRepo(proxyEventRepo)
+			proxyHandler.SetProxyEventRepo(proxyEventRepo)
+
+			proxyUsageRepo := postgres.NewProxyUsageRepository(db)
+			pg.SetUsageRepo(proxyUsageRepo)
+			proxyHandler.SetProxyUsageRepo(proxyUsageRepo)
+			jobSvc.SetProxyUsage(proxyUsageRepo)
+
 			// Load existing healthy proxies from database into memory pool
 			ctx := context.Background()
 			if err := pg.LoadFromDatabase(ctx); err != nil {
@@ -424,6 +570,8 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 
 	// Load sources if proxyRepo is available
 	if proxyRepo != nil && pg != nil {
+		pg.SetSourceRepo(proxyRepo)
+
 		ctx := context.Background()
 		sources, err := proxyRepo.List(ctx)
 		if err != nil {
@@ -431,7 +579,7 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 		} else {
 			count := 0
 			for _, s := range sources {
-				pg.AddSource(s.URL)
+				pg.RestoreSource(s)
 				count++
 			}
 			if count > 0 {
@@ -453,6 +601,170 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 	if cachedJobHandler != nil || cachedStatsHandler != nil || cachedResultHandler != nil {
 		router.SetCachedHandlers(cachedJobHandler, cachedStatsHandler, cachedResultHandler)
 	}
+	router.SetTimeSeries(timeSeriesHandler)
+
+	// Scoped API keys (PostgreSQL only); the legacy API_TOKEN remains the admin credential
+	var apiKeySvc *service.APIKeyService
+	if isPostgres {
+		apiKeySvc = service.NewAPIKeyService(postgres.NewAPIKeyRepository(db))
+		router.SetAPIKeys(handlers.NewAPIKeyHandler(apiKeySvc), apiKeySvc)
+		log.Println("manager: scoped API keys enabled")
+	}
+
+	// Export profiles limiting downloads, per request or per API key
+	// (PostgreSQL only)
+	if isPostgres {
+		exportProfileRepo := postgres.NewExportProfileRepository(db)
+		exportProfileSvc := service.NewExportProfileService(exportProfileRepo)
+		apiKeySvc.SetExportProfiles(exportProfileRepo)
+		if businessListingHandler != nil {
+			businessListingHandler.SetExportProfiles(exportProfileSvc)
+		}
+		router.SetExportProfiles(handlers.NewExportProfileHandler(exportProfileSvc))
+	}
+
+	// Job templates (PostgreSQL only)
+	if isPostgres {
+		templateSvc := service.NewJobTemplateService(postgres.NewJobTemplateRepository(db))
+		jobHandler.SetTemplates(templateSvc)
+		router.SetTemplates(handlers.NewTemplateHandler(templateSvc))
+		log.Println("manager: job templates enabled")
+	}
+
+	// Job defaults and other dashboard settings (PostgreSQL only)
+	if isPostgres {
+		settingsSvc := service.NewSettingsService(postgres.NewSettingsRepository(db))
+		jobHandler.SetSettings(settingsSvc)
+		router.SetSettings(handlers.NewSettingsHandler(settingsSvc))
+	}
+
+	// Normalized reviews (PostgreSQL only, populated by the results trigger)
+	if isPostgres {
+		reviewSvc := service.NewReviewService(postgres.NewReviewRepository(db))
+		router.SetReviews(handlers.NewReviewHandler(reviewSvc))
+		log.Println("manager: review endpoints enabled")
+	}
+
+	// Duplicate listings report and merge (PostgreSQL only)
+	if isPostgres {
+		duplicateSvc := service.NewDuplicateService(postgres.NewDuplicateRepository(db))
+		router.SetDuplicates(handlers.NewDuplicateHandler(duplicateSvc))
+		log.Println("manager: duplicate detection enabled")
+	}
+
+	// Results diff between two jobs (PostgreSQL only)
+	if isPostgres {
+		diffSvc := service.NewDiffService(postgres.NewListingDiffRepository(db), jobRepo)
+		router.SetDiffs(handlers.NewDiffHandler(diffSvc))
+		log.Println("manager: job results diff enabled")
+	}
+
+	// Job summary reports, emailed to notify_emails when SMTP is configured
+	// (PostgreSQL only)
+	if isPostgres {
+		var sender service.ReportSender
+		if cfg.SMTP.Enabled() {
+			mailer, err := report.NewMailer(cfg.SMTP)
+			if err != nil {
+				return nil, fmt.Errorf("invalid smtp configuration: %w", err)
+			}
+			sender = mailer
+			log.Printf("manager: job report emails enabled (smtp %s)", cfg.SMTP.Host)
+		}
+		reportSvc := service.NewReportService(postgres.NewJobReportRepository(db), jobRepo, sender)
+		reportSvc.SetNormalizer(normalizerSvc)
+		jobSvc.SetReports(reportSvc)
+		workerSvc.SetReports(reportSvc)
+		router.SetReports(handlers.NewReportHandler(reportSvc))
+	}
+
+	// Audit trail of job transitions and who caused them (PostgreSQL only)
+	if isPostgres {
+		jobEventRepo := postgres.NewJobEventRepository(db)
+		jobEventRepo.SetKeyring(cfg.Secrets)
+		jobSvc.SetAudit(jobEventRepo, time.Duration(cfg.JobEventRetentionDays)*24*time.Hour)
+		workerSvc.SetAudit(jobEventRepo)
+		log.Println("manager: job audit trail enabled")
+	}
+
+	// Usage accounting and monthly quotas per API key (PostgreSQL only,
+	// recorded by the result repository as batches are stored)
+	if isPostgres {
+		usageRepo := postgres.NewUsageRepository(db)
+		jobSvc.SetUsage(usageRepo)
+		router.SetUsage(handlers.NewUsageHandler(service.NewUsageService(usageRepo)))
+		log.Println("manager: usage accounting enabled")
+	}
+
+	// Seed tasks bridged to gmaps_jobs; their status drives the progress of
+	// jobs run by DSN workers (PostgreSQL only)
+	var seedTaskSvc *service.SeedTaskService
+	if isPostgres {
+		seedTaskRepo := postgres.NewSeedTaskRepository(db)
+		seedTaskSvc = service.NewSeedTaskService(seedTaskRepo, jobSvc)
+		jobSvc.SetSeedTasks(seedTaskRepo)
+		router.SetSeedTasks(handlers.NewSeedTaskHandler(seedTaskSvc))
+		log.Println("manager: seed task tracking enabled")
+	}
+
+	// Emails are validated in the background instead of by the email jobs
+	// (PostgreSQL only)
+	var emailValidationSvc *service.EmailValidationService
+	if isPostgres {
+		emailValidationSvc = newEmailValidationService(cfg, db, redisCache)
+		router.SetEmailValidation(handlers.NewEmailValidationHandler(emailValidationSvc))
+	}
+
+	// Websites of jobs with check_website are checked in the background
+	// (PostgreSQL only)
+	var websiteCheckSvc *service.WebsiteCheckService
+	if isPostgres && cfg.WebsiteCheckWorkers > 0 {
+		var proxy *url.URL
+		if cfg.WebsiteCheckProxy != "" {
+			if proxy, err = url.Parse(cfg.WebsiteCheckProxy); err != nil {
+				return nil, fmt.Errorf("invalid website check proxy: %w", err)
+			}
+		}
+		websiteCheckSvc = service.NewWebsiteCheckService(
+			postgres.NewWebsiteCheckRepository(db), websitecheck.New(0, proxy),
+			cfg.WebsiteCheckWorkers, cfg.WebsiteCheckTTL,
+		)
+	}
+
+	// Re-normalization of stored results into business_listings (PostgreSQL only)
+	if isPostgres {
+		renormalizeSvc := service.NewRenormalizeService(postgres.NewRenormalizeRepository(db), jobRepo)
+		router.SetRenormalize(handlers.NewRenormalizeHandler(renormalizeSvc))
+		log.Println("manager: re-normalization endpoint enabled")
+	}
+
+	// Canonical category taxonomy of business_listings (PostgreSQL only)
+	if isPostgres {
+		categorySvc := service.NewCategoryService(postgres.NewCategoryMappingRepository(db))
+		router.SetCategories(handlers.NewCategoryHandler(categorySvc))
+	}
+
+	// ANALYZE/VACUUM/REINDEX runs, on request and on the -maintenance-schedule
+	maintenanceSvc := service.NewMaintenanceService(maintenanceRepo)
+	router.SetMaintenance(handlers.NewMaintenanceHandler(maintenanceSvc))
+
+	router.SetSlowLog(handlers.NewSlowLogHandler(slowLog), slowLog)
+
+	router.SetEvents(handlers.NewEventHandler(jobSvc, jobEvents))
+	router.SetWorkerEvents(handlers.NewWorkerEventHandler(workerSvc, jobEvents))
+
+	if workerScaler != nil {
+		router.SetSpawner(handlers.NewSpawnerHandler(workerScaler))
+	}
+
+	// Background tasks run on the elected replica only, so that replicas
+	// behind a load balancer do not mark workers offline, scale or refresh
+	// proxies twice
+	elector, redisLocker := newElector(cfg, db, isPostgres)
+	router.SetCluster(handlers.NewClusterHandler(elector))
+
+	router.SetHealth(newHealthHandler(cfg, db, isPostgres, jobQueue, redisCache, mqPublisher, pg))
+
 	apiToken := os.Getenv("API_TOKEN")
 	if apiToken == "" {
 		apiToken = os.Getenv("API_KEY")
@@ -466,6 +778,17 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 
 	handler := router.Setup(apiToken)
 
+	// Worker protocol over gRPC, next to the HTTP endpoints it mirrors
+	var grpcSrv *grpcapi.Server
+	if cfg.GRPCAddress != "" {
+		grpcCfg := grpcapi.Config{Token: apiToken}
+		if apiKeySvc != nil {
+			grpcCfg.Keys = apiKeySvc
+		}
+		grpcSrv = grpcapi.NewServer(grpcCfg, workerSvc, jobSvc, resultSvc)
+		grpcSrv.SetEvents(jobEvents)
+	}
+
 	var httpHandler http.Handler = handler
 
 	// Serve static files if configured
@@ -473,8 +796,8 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 		log.Printf("manager: static folder configured at %s", cfg.StaticFolder)
 		fs := http.FileServer(http.Dir(cfg.StaticFolder))
 		httpHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Health check endpoint - always serve from API handler
-			if r.URL.Path == "/health" || r.URL.Path == "/api/v2/health" {
+			// Health and readiness endpoints - always serve from API handler
+			if r.URL.Path == "/health" || r.URL.Path == "/ready" {
 				handler.ServeHTTP(w, r)
 				return
 			}
@@ -517,13 +840,74 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 		MaxHeaderBytes:    1 << 20,
 	}
 
+	// Event streams never finish on their own; closing the broker ends them
+	// so Shutdown does not wait for the dashboard to disconnect
+	srv.RegisterOnShutdown(func() {
+		_ = jobEvents.Close()
+	})
+
 	// Create heartbeat monitor
 	hbMonitor := heartbeat.NewMonitor(workerSvc, 0)
+	hbMonitor.SetEvents(jobEvents)
+
+	elector.Go("heartbeat", hbMonitor.Run)
+	if workerScaler != nil {
+		elector.Go("autoscale", workerScaler.Run)
+	}
+	if seedTaskSvc != nil {
+		elector.Go("seed_tasks", seedTaskSvc.Run)
+	}
+	if emailValidationSvc != nil {
+		elector.Go("email_validation", emailValidationSvc.Run)
+	}
+	if websiteCheckSvc != nil {
+		elector.Go("website_checks", websiteCheckSvc.Run)
+	}
+	if normalizerSvc != nil {
+		elector.Go("normalizer", normalizerSvc.Run)
+	}
+	if pg != nil {
+		pg.SetSharedMaintenance()
+		elector.Go("proxygate_maintenance", pg.RunMaintenance)
+	}
+	if maintenanceSchedule != nil {
+		elector.Go("maintenance", func(ctx context.Context) error {
+			return maintenanceSvc.RunSchedule(ctx, maintenanceSchedule, domain.MaintenanceOptions{})
+		})
+		log.Printf("manager: database maintenance scheduled (%s)", cfg.MaintenanceSchedule)
+	}
+	if cfg.JobTimeoutGrace > 0 {
+		elector.Go("job_timeouts", func(ctx context.Context) error {
+			return workerSvc.RunJobTimeouts(ctx, cfg.JobTimeoutGrace)
+		})
+	}
+	if cfg.DeletedJobRetentionDays > 0 {
+		retention := time.Duration(cfg.DeletedJobRetentionDays) * 24 * time.Hour
+		elector.Go("deleted_job_retention", func(ctx context.Context) error {
+			return jobSvc.RunDeletedJobRetention(ctx, retention, func(ids []uuid.UUID) {
+				jobHandler.InvalidateJobs(ctx, ids)
+			})
+		})
+	}
+	if isPostgres && cfg.JobEventRetentionDays > 0 {
+		elector.Go("job_event_retention", jobSvc.RunJobEventRetention)
+	}
+	elector.Go("job_dependencies", func(ctx context.Context) error {
+		return jobSvc.RunDependencies(ctx, func(ids []uuid.UUID) {
+			jobHandler.InvalidateJobs(ctx, ids)
+		})
+	})
+	elector.Go("job_shards", func(ctx context.Context) error {
+		return jobSvc.RunShards(ctx, func(ids []uuid.UUID) {
+			jobHandler.InvalidateJobs(ctx, ids)
+		})
+	})
 
 	return &ManagerRunner{
 		cfg:       cfg,
 		db:        db,
 		srv:       srv,
+		grpcSrv:   grpcSrv,
 		jobSvc:    jobSvc,
 		workerSvc: workerSvc,
 		resultSvc: resultSvc,
@@ -534,16 +918,118 @@ func New(cfg *Config, pg *proxygate.ProxyGate) (runner.Runner, error) {
 		mqPub:     mqPublisher,
 		cache:     redisCache,
 		spawner:   workerSpawner,
+		scaler:    workerScaler,
+		events:    jobEvents,
+		seedTasks: seedTaskSvc,
+		emails:    emailValidationSvc,
+		elector:   elector,
+		locker:    redisLocker,
 	}, nil
 }
 
+// newElector picks the lock the replicas elect their leader with: Redis
+// when configured, else a lease row in PostgreSQL. SQLite runs a single
+// replica, which holds an in-process lock.
+func newElector(cfg *Config, db *sql.DB, isPostgres bool) (*leader.Elector, *leader.RedisLocker) {
+	electorCfg := leader.Config{Name: "manager", TTL: cfg.LeaderLockTTL}
+
+	if cfg.RedisURL != "" || cfg.RedisAddr != "" {
+		l, err := leader.NewRedisLocker(leader.RedisConfig{
+			RedisURL:  cfg.RedisURL,
+			RedisAddr: cfg.RedisAddr,
+			Password:  cfg.RedisPass,
+			DB:        cfg.RedisDB,
+		})
+		if err == nil {
+			electorCfg.Backend = domain.LockBackendRedis
+			log.Println("manager: leader election through Redis")
+			return leader.New(l, electorCfg), l
+		}
+		log.Printf("manager: WARNING - failed to connect Redis leader lock: %v", err)
+	}
+
+	if isPostgres {
+		electorCfg.Backend = domain.LockBackendPostgres
+		log.Println("manager: leader election through PostgreSQL")
+		return leader.New(postgres.NewLeaseRepository(db), electorCfg), nil
+	}
+
+	electorCfg.Backend = domain.LockBackendMemory
+	log.Println("manager: single replica, background tasks always run here")
+	return leader.New(leader.NewMemoryLocker(), electorCfg), nil
+}
+
+// newSpawnerConfig returns the configuration of the -spawner workers are
+// started with
+func newSpawnerConfig(cfg *Config) *spawner.Config {
+	// Determine Manager URL for spawned workers
+	// For Dokploy/Swarm: use service name (e.g., http://manager:8080)
+	// For local Docker: use host.docker.internal or localhost
+	managerURL := cfg.SpawnerManagerURL
+	if managerURL == "" {
+		// Auto-detect based on spawner type
+		if cfg.SpawnerType == "swarm" {
+			// For Swarm, try to use hostname as service name
+			hostname, _ := os.Hostname()
+			managerURL = fmt.Sprintf("http://%s%s", hostname, cfg.Address)
+			log.Printf("manager: auto-detected manager URL for Swarm: %s", managerURL)
+		} else {
+			// For local Docker, use localhost
+			managerURL = "http://localhost" + cfg.Address
+		}
+	}
+
+	return &spawner.Config{
+		Type:        spawner.SpawnerType(cfg.SpawnerType),
+		ManagerURL:  managerURL,
+		RabbitMQURL: cfg.RabbitMQURL,
+		RedisAddr:   cfg.RedisAddr,
+		Secrets:     workerSecrets(cfg),
+		Docker: spawner.DockerConfig{
+			Image:       cfg.SpawnerImage,
+			Network:     cfg.SpawnerNetwork,
+			Concurrency: cfg.SpawnerConcurrency,
+			AutoRemove:  cfg.SpawnerAutoRemove,
+			MaxWorkers:  cfg.SpawnerMaxWorkers,
+			Environment: cfg.SpawnerLabels,
+		},
+		Swarm: spawner.SwarmConfig{
+			Image:       cfg.SpawnerImage,
+			Network:     cfg.SpawnerNetwork,
+			Concurrency: cfg.SpawnerConcurrency,
+			MaxServices: cfg.SpawnerMaxWorkers,
+			Labels:      cfg.SpawnerLabels,
+			Constraints: cfg.SpawnerConstraints,
+		},
+		Lambda: spawner.LambdaConfig{
+			FunctionName:   cfg.SpawnerLambdaFunction,
+			Region:         cfg.SpawnerLambdaRegion,
+			InvocationType: cfg.SpawnerLambdaInvocation,
+			MaxConcurrent:  cfg.SpawnerLambdaMaxConc,
+		},
+	}
+}
+
+// workerSecrets returns the credentials spawned workers get as environment
+// variables: their proxies and the email validator keys
+func workerSecrets(cfg *Config) func() map[string]string {
+	return func() map[string]string {
+		return map[string]string{
+			"PROXIES":              cfg.SpawnerProxies,
+			"MORDIBOUNCER_API_KEY": cfg.EmailValidator.Mordibouncer.APIKey,
+			"ZEROBOUNCE_API_KEY":   cfg.EmailValidator.ZeroBounce.APIKey,
+		}
+	}
+}
+
 // Run starts the manager
 func (m *ManagerRunner) Run(ctx context.Context) error {
 	egroup, ctx := errgroup.WithContext(ctx)
 
-	// Start heartbeat monitor
+	// Heartbeat monitor, worker auto-scaler, seed task sync, email
+	// validation and ProxyGate maintenance, while this replica leads
 	egroup.Go(func() error {
-		return m.hbMonitor.Run(ctx)
+		return m.elector.Run(ctx)
 	})
 
 	// Start HTTP server
@@ -551,6 +1037,12 @@ func (m *ManagerRunner) Run(ctx context.Context) error {
 		return m.startServer(ctx)
 	})
 
+	if m.grpcSrv != nil {
+		egroup.Go(func() error {
+			return m.startGRPCServer(ctx)
+		})
+	}
+
 	return egroup.Wait()
 }
 
@@ -562,9 +1054,15 @@ func (m *ManagerRunner) Close(_ context.Context) error {
 	if m.mqPub != nil {
 		m.mqPub.Close()
 	}
+	if m.events != nil {
+		m.events.Close()
+	}
 	if m.cache != nil {
 		m.cache.Close()
 	}
+	if m.locker != nil {
+		m.locker.Close()
+	}
 	if m.jobQueue != nil {
 		m.jobQueue.Close()
 	}
@@ -574,6 +1072,17 @@ func (m *ManagerRunner) Close(_ context.Context) error {
 	return nil
 }
 
+func (m *ManagerRunner) startGRPCServer(ctx context.Context) error {
+	lis, err := net.Listen("tcp", m.cfg.GRPCAddress)
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC on %s: %w", m.cfg.GRPCAddress, err)
+	}
+
+	log.Printf("manager gRPC worker server starting on %s", lis.Addr())
+
+	return m.grpcSrv.Serve(ctx, lis)
+}
+
 func (m *ManagerRunner) startServer(ctx context.Context) error {
 	go func() {
 		<-ctx.Done()
@@ -602,6 +1111,15 @@ func (m *ManagerRunner) startServer(ctx context.Context) error {
 	return nil
 }
 
+// MigratePostgres brings a PostgreSQL database up to the manager's schema,
+// as the manager does when it starts
+func MigratePostgres(db *sql.DB) error {
+	if err := runEmbeddedMigrations(db); err != nil {
+		return err
+	}
+	return migration.AutoMigrate(context.Background(), db)
+}
+
 // runEmbeddedMigrations runs migrations from embedded files (for PostgreSQL)
 func runEmbeddedMigrations(db *sql.DB) error {
 	// Create migrations tracking table
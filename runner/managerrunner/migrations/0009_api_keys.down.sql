@@ -0,0 +1,8 @@
+-- Migration 0009: API Keys (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_api_keys_created_at;
+DROP TABLE IF EXISTS api_keys;
+
+COMMIT;
@@ -0,0 +1,19 @@
+-- Migration 0009: API Keys
+-- Scoped API keys replacing the single shared API_TOKEN
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,           -- First characters of the key, for identification
+    key_hash CHAR(64) NOT NULL UNIQUE,     -- SHA-256 hex digest of the full key
+    scopes JSONB NOT NULL DEFAULT '[]',    -- e.g. ["jobs:read", "results:read"]
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_created_at ON api_keys(created_at DESC);
+
+COMMIT;
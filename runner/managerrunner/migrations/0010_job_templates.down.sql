@@ -0,0 +1,8 @@
+-- Migration 0010: Job Templates (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_job_templates_name;
+DROP TABLE IF EXISTS job_templates;
+
+COMMIT;
@@ -0,0 +1,18 @@
+-- Migration 0010: Job Templates
+-- Reusable partial job configurations
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS job_templates (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    config JSONB NOT NULL DEFAULT '{}',    -- Partial JobConfig, copied into jobs on creation
+    usage_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_templates_name ON job_templates(name);
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration 0011: Worker Throttle (DOWN)
+
+BEGIN;
+
+ALTER TABLE workers DROP COLUMN IF EXISTS block_count;
+ALTER TABLE workers DROP COLUMN IF EXISTS request_delay_ms;
+
+COMMIT;
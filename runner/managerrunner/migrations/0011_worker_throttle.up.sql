@@ -0,0 +1,9 @@
+-- Migration 0011: Worker Throttle
+-- Adaptive rate limiter state reported by workers in their heartbeat
+
+BEGIN;
+
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS request_delay_ms BIGINT NOT NULL DEFAULT 0;  -- Current delay between requests
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS block_count BIGINT NOT NULL DEFAULT 0;       -- Block pages seen since worker start
+
+COMMIT;
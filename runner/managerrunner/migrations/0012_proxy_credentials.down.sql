@@ -0,0 +1,8 @@
+-- Migration 0012: Proxy Credentials (DOWN)
+
+BEGIN;
+
+ALTER TABLE proxies DROP COLUMN IF EXISTS password;
+ALTER TABLE proxies DROP COLUMN IF EXISTS username;
+
+COMMIT;
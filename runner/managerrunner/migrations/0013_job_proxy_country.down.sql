@@ -0,0 +1,7 @@
+-- Migration 0013: Job Proxy Country (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS proxy_country;
+
+COMMIT;
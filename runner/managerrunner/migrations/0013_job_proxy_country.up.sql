@@ -0,0 +1,8 @@
+-- Migration 0013: Job Proxy Country
+-- Persist the proxy country of a job so workers fetching the job see it
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS proxy_country TEXT;
+
+COMMIT;
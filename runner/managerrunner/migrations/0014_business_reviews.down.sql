@@ -0,0 +1,14 @@
+-- Migration 0014: Business Reviews (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS reviews_sort;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS max_reviews;
+
+DROP TRIGGER IF EXISTS trg_populate_business_reviews ON results;
+DROP FUNCTION IF EXISTS populate_business_reviews();
+DROP INDEX IF EXISTS idx_business_reviews_place_id;
+DROP INDEX IF EXISTS idx_business_reviews_dedup;
+DROP TABLE IF EXISTS business_reviews;
+
+COMMIT;
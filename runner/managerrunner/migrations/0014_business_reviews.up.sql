@@ -0,0 +1,81 @@
+-- Migration 0014: Business Reviews
+-- Normalized reviews extracted from results at ingestion, deduplicated across re-scrapes
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS business_reviews (
+    id BIGSERIAL PRIMARY KEY,
+    place_id TEXT NOT NULL,
+    reviewer_name TEXT NOT NULL,
+    reviewer_picture TEXT,
+    rating SMALLINT,
+    review_text TEXT NOT NULL DEFAULT '',
+    text_hash TEXT NOT NULL,               -- md5(review_text), part of the dedup key
+    relative_date TEXT,                    -- As shown by Google, e.g. "2 months ago" or "2024-5-12"
+    owner_reply TEXT,
+    images TEXT[],
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_business_reviews_dedup ON business_reviews(place_id, reviewer_name, text_hash);
+CREATE INDEX IF NOT EXISTS idx_business_reviews_place_id ON business_reviews(place_id);
+
+-- Reviews come from both the inline and the extended review arrays of a result
+CREATE OR REPLACE FUNCTION populate_business_reviews()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_place_id TEXT;
+    v_review JSONB;
+    v_text TEXT;
+BEGIN
+    v_place_id := NEW.data ->> 'place_id';
+    IF v_place_id IS NULL OR v_place_id = '' THEN
+        RETURN NEW;
+    END IF;
+
+    FOR v_review IN
+        SELECT r FROM jsonb_array_elements(
+            CASE WHEN jsonb_typeof(NEW.data -> 'user_reviews') = 'array' THEN NEW.data -> 'user_reviews' ELSE '[]'::JSONB END ||
+            CASE WHEN jsonb_typeof(NEW.data -> 'user_reviews_extended') = 'array' THEN NEW.data -> 'user_reviews_extended' ELSE '[]'::JSONB END
+        ) AS r
+    LOOP
+        IF COALESCE(v_review ->> 'name', '') = '' THEN
+            CONTINUE;
+        END IF;
+
+        v_text := COALESCE(v_review ->> 'description', '');
+
+        INSERT INTO business_reviews (
+            place_id, reviewer_name, reviewer_picture, rating, review_text, text_hash,
+            relative_date, owner_reply, images
+        ) VALUES (
+            v_place_id, v_review ->> 'name', NULLIF(v_review ->> 'profile_picture', ''),
+            NULLIF(v_review ->> 'rating', '')::SMALLINT, v_text, md5(v_text),
+            NULLIF(v_review ->> 'when', ''), NULLIF(v_review ->> 'owner_reply', ''),
+            CASE WHEN jsonb_typeof(v_review -> 'images') = 'array'
+            THEN ARRAY(SELECT jsonb_array_elements_text(v_review -> 'images')) ELSE NULL END
+        )
+        ON CONFLICT (place_id, reviewer_name, text_hash) DO UPDATE SET
+            rating = COALESCE(EXCLUDED.rating, business_reviews.rating),
+            relative_date = COALESCE(EXCLUDED.relative_date, business_reviews.relative_date),
+            owner_reply = COALESCE(EXCLUDED.owner_reply, business_reviews.owner_reply),
+            images = COALESCE(EXCLUDED.images, business_reviews.images),
+            last_seen_at = NOW();
+    END LOOP;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_populate_business_reviews ON results;
+CREATE TRIGGER trg_populate_business_reviews
+    AFTER INSERT ON results
+    FOR EACH ROW
+    EXECUTE FUNCTION populate_business_reviews();
+
+-- Review limit and order of a job, read by workers fetching the job
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS max_reviews INT NOT NULL DEFAULT 0;
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS reviews_sort TEXT;
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Migration 0015: Listing Image URLs (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS max_images;
+
+DROP TRIGGER IF EXISTS trg_populate_listing_image_urls ON business_listings;
+DROP FUNCTION IF EXISTS populate_listing_image_urls();
+ALTER TABLE business_listings DROP COLUMN IF EXISTS image_urls;
+
+COMMIT;
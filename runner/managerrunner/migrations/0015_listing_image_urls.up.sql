@@ -0,0 +1,42 @@
+-- Migration 0015: Listing Image URLs
+-- Photo URLs of a place copied from the result JSON into business_listings
+
+BEGIN;
+
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS image_urls JSONB;
+
+-- populate_normalized_listings() only copies a fixed set of fields, so the
+-- image URLs are picked up from the source result whenever a listing row is
+-- written. Fast mode results have no image_urls and leave the column NULL.
+CREATE OR REPLACE FUNCTION populate_listing_image_urls()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.result_id IS NOT NULL THEN
+        SELECT CASE WHEN jsonb_typeof(r.data -> 'image_urls') = 'array' THEN r.data -> 'image_urls' END
+        INTO NEW.image_urls
+        FROM results r
+        WHERE r.id = NEW.result_id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_populate_listing_image_urls ON business_listings;
+CREATE TRIGGER trg_populate_listing_image_urls
+    BEFORE INSERT OR UPDATE ON business_listings
+    FOR EACH ROW
+    EXECUTE FUNCTION populate_listing_image_urls();
+
+-- Backfill listings ingested before this migration
+UPDATE business_listings bl
+SET image_urls = r.data -> 'image_urls'
+FROM results r
+WHERE r.id = bl.result_id
+  AND bl.image_urls IS NULL
+  AND jsonb_typeof(r.data -> 'image_urls') = 'array';
+
+-- Image limit of a job, read by workers fetching the job
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS max_images INT NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,30 @@
+-- Migration 0016: Listing Attributes (DOWN)
+
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_populate_listing_result_fields ON business_listings;
+DROP FUNCTION IF EXISTS populate_listing_result_fields();
+DROP INDEX IF EXISTS idx_business_listings_attributes;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS attributes;
+
+-- Restore the 0014 trigger
+CREATE OR REPLACE FUNCTION populate_listing_image_urls()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.result_id IS NOT NULL THEN
+        SELECT CASE WHEN jsonb_typeof(r.data -> 'image_urls') = 'array' THEN r.data -> 'image_urls' END
+        INTO NEW.image_urls
+        FROM results r
+        WHERE r.id = NEW.result_id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_populate_listing_image_urls
+    BEFORE INSERT OR UPDATE ON business_listings
+    FOR EACH ROW
+    EXECUTE FUNCTION populate_listing_image_urls();
+
+COMMIT;
@@ -0,0 +1,47 @@
+-- Migration 0016: Listing Attributes
+-- Service options and other place attributes, grouped by section, on business_listings
+
+BEGIN;
+
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS attributes JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_business_listings_attributes ON business_listings USING GIN (attributes);
+
+-- Supersedes populate_listing_image_urls() from 0014: one lookup of the
+-- source result now fills every JSON field copied verbatim.
+DROP TRIGGER IF EXISTS trg_populate_listing_image_urls ON business_listings;
+DROP FUNCTION IF EXISTS populate_listing_image_urls();
+
+CREATE OR REPLACE FUNCTION populate_listing_result_fields()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_data JSONB;
+BEGIN
+    IF NEW.result_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    SELECT r.data INTO v_data FROM results r WHERE r.id = NEW.result_id;
+
+    NEW.image_urls := CASE WHEN jsonb_typeof(v_data -> 'image_urls') = 'array' THEN v_data -> 'image_urls' END;
+    NEW.attributes := CASE WHEN jsonb_typeof(v_data -> 'attributes') = 'object' THEN v_data -> 'attributes' END;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_populate_listing_result_fields ON business_listings;
+CREATE TRIGGER trg_populate_listing_result_fields
+    BEFORE INSERT OR UPDATE ON business_listings
+    FOR EACH ROW
+    EXECUTE FUNCTION populate_listing_result_fields();
+
+-- Backfill listings ingested before this migration
+UPDATE business_listings bl
+SET attributes = r.data -> 'attributes'
+FROM results r
+WHERE r.id = bl.result_id
+  AND bl.attributes IS NULL
+  AND jsonb_typeof(r.data -> 'attributes') = 'object';
+
+COMMIT;
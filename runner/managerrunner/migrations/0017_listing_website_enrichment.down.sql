@@ -0,0 +1,28 @@
+-- Migration 0017: Listing Website Enrichment (DOWN)
+
+BEGIN;
+
+-- Restore the 0015 function before dropping the columns it would write
+CREATE OR REPLACE FUNCTION populate_listing_result_fields()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_data JSONB;
+BEGIN
+    IF NEW.result_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    SELECT r.data INTO v_data FROM results r WHERE r.id = NEW.result_id;
+
+    NEW.image_urls := CASE WHEN jsonb_typeof(v_data -> 'image_urls') = 'array' THEN v_data -> 'image_urls' END;
+    NEW.attributes := CASE WHEN jsonb_typeof(v_data -> 'attributes') = 'object' THEN v_data -> 'attributes' END;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE business_listings DROP COLUMN IF EXISTS website_description;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS website_phone;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS social_links;
+
+COMMIT;
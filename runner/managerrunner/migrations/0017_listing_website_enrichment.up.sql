@@ -0,0 +1,39 @@
+-- Migration 0017: Listing Website Enrichment
+-- Social profile links, phone and meta description found on the business website
+
+BEGIN;
+
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS social_links JSONB;
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS website_phone TEXT;
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS website_description TEXT;
+
+CREATE OR REPLACE FUNCTION populate_listing_result_fields()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_data JSONB;
+BEGIN
+    IF NEW.result_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    SELECT r.data INTO v_data FROM results r WHERE r.id = NEW.result_id;
+
+    NEW.image_urls := CASE WHEN jsonb_typeof(v_data -> 'image_urls') = 'array' THEN v_data -> 'image_urls' END;
+    NEW.attributes := CASE WHEN jsonb_typeof(v_data -> 'attributes') = 'object' THEN v_data -> 'attributes' END;
+    NEW.social_links := CASE WHEN jsonb_typeof(v_data -> 'social_links') = 'object' THEN v_data -> 'social_links' END;
+    NEW.website_phone := NULLIF(v_data ->> 'website_phone', '');
+    NEW.website_description := NULLIF(v_data ->> 'website_description', '');
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Backfill listings ingested before this migration
+UPDATE business_listings bl
+SET social_links = r.data -> 'social_links'
+FROM results r
+WHERE r.id = bl.result_id
+  AND bl.social_links IS NULL
+  AND r.data ? 'social_links';
+
+COMMIT;
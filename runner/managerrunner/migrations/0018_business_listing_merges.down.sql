@@ -0,0 +1,10 @@
+-- Migration 0018: Business Listing Merges (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_business_listings_phone_digits;
+DROP INDEX IF EXISTS idx_business_listing_merges_merged_at;
+DROP INDEX IF EXISTS idx_business_listing_merges_survivor;
+DROP TABLE IF EXISTS business_listing_merges;
+
+COMMIT;
@@ -0,0 +1,23 @@
+-- Migration 0018: Business Listing Merges
+-- Audit trail of duplicate listings merged into a surviving row
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS business_listing_merges (
+    id BIGSERIAL PRIMARY KEY,
+    survivor_id BIGINT NOT NULL,           -- No FK: the audit row outlives the listing
+    merged_ids BIGINT[] NOT NULL,
+    criterion TEXT,
+    merged_listings JSONB NOT NULL DEFAULT '[]'::JSONB, -- Snapshot of the deleted rows
+    merged_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_business_listing_merges_survivor ON business_listing_merges(survivor_id);
+CREATE INDEX IF NOT EXISTS idx_business_listing_merges_merged_at ON business_listing_merges(merged_at DESC);
+
+-- Duplicate detection groups on normalized phone numbers
+CREATE INDEX IF NOT EXISTS idx_business_listings_phone_digits
+    ON business_listings ((regexp_replace(phone, '\D', '', 'g')))
+    WHERE phone IS NOT NULL AND phone <> '';
+
+COMMIT;
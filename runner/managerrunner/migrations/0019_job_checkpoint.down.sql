@@ -0,0 +1,8 @@
+-- Migration 0019: Job Checkpoint (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS checkpoint_places;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS paused_at;
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Migration 0019: Job Checkpoint
+-- Remember when a job was paused and how many places it had submitted by then
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS paused_at TIMESTAMPTZ;
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS checkpoint_places INT;
+
+COMMIT;
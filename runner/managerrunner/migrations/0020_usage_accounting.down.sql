@@ -0,0 +1,12 @@
+-- Migration 0020: Usage Accounting (DOWN)
+
+BEGIN;
+
+DROP TABLE IF EXISTS usage_daily;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS monthly_place_quota;
+
+DROP INDEX IF EXISTS idx_jobs_queue_tenant;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS tenant;
+
+COMMIT;
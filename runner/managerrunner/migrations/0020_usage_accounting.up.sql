@@ -0,0 +1,22 @@
+-- Migration 0020: Usage Accounting
+-- Attribute jobs to the API key (or tenant) that created them, count usage per day and cap it per key
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS tenant TEXT;   -- API key ID or X-Tenant header value
+
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_tenant ON jobs_queue(tenant) WHERE tenant IS NOT NULL;
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS monthly_place_quota INT;   -- NULL = unlimited
+
+CREATE TABLE IF NOT EXISTS usage_daily (
+    tenant TEXT NOT NULL,
+    day DATE NOT NULL,                          -- UTC
+    places_scraped BIGINT NOT NULL DEFAULT 0,
+    email_validations BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_daily_day ON usage_daily(day);
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Migration 0021: Grid Density Check (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS density_check;
+
+COMMENT ON COLUMN jobs_queue.grid_points IS 'Number of grid points generated for full coverage mode';
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Migration 0021: Grid Density Check
+-- Full coverage jobs can probe every grid cell and skip empty ones before seeding
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS density_check BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN jobs_queue.grid_points IS 'Number of grid points seeded for full coverage mode, after the density check if enabled';
+
+COMMIT;
@@ -0,0 +1,16 @@
+-- Migration 0022: Seed Task Status (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_gmaps_jobs_parent_created;
+
+ALTER TABLE gmaps_jobs DROP COLUMN IF EXISTS error;
+ALTER TABLE gmaps_jobs DROP COLUMN IF EXISTS places_found;
+ALTER TABLE gmaps_jobs DROP COLUMN IF EXISTS finished_at;
+ALTER TABLE gmaps_jobs DROP COLUMN IF EXISTS started_at;
+ALTER TABLE gmaps_jobs DROP COLUMN IF EXISTS geo;
+ALTER TABLE gmaps_jobs DROP COLUMN IF EXISTS keyword;
+
+COMMENT ON COLUMN gmaps_jobs.status IS NULL;
+
+COMMIT;
@@ -0,0 +1,17 @@
+-- Migration 0022: Seed Task Status
+-- Bridged gmaps_jobs record what they search for and how they finished
+
+BEGIN;
+
+ALTER TABLE gmaps_jobs ADD COLUMN IF NOT EXISTS keyword TEXT;
+ALTER TABLE gmaps_jobs ADD COLUMN IF NOT EXISTS geo TEXT;
+ALTER TABLE gmaps_jobs ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ;
+ALTER TABLE gmaps_jobs ADD COLUMN IF NOT EXISTS finished_at TIMESTAMPTZ;
+ALTER TABLE gmaps_jobs ADD COLUMN IF NOT EXISTS places_found INTEGER;
+ALTER TABLE gmaps_jobs ADD COLUMN IF NOT EXISTS error TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_gmaps_jobs_parent_created ON gmaps_jobs(parent_job_id, created_at);
+
+COMMENT ON COLUMN gmaps_jobs.status IS 'new, queued (claimed by a worker), ok or failed';
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Migration 0023: Retry Failed Searches (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS retry_keywords;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS failed_keywords;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS attempts;
+
+ALTER TABLE gmaps_jobs DROP COLUMN IF EXISTS attempts;
+
+COMMIT;
@@ -0,0 +1,15 @@
+-- Migration 0023: Retry Failed Searches
+-- Attempt counters for seed tasks and jobs, and the keywords a Manager/Worker run failed
+
+BEGIN;
+
+ALTER TABLE gmaps_jobs ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS failed_keywords TEXT[];
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS retry_keywords TEXT[];
+
+COMMENT ON COLUMN jobs_queue.failed_keywords IS 'Keywords whose search failed or never ran in the last Manager/Worker run';
+COMMENT ON COLUMN jobs_queue.retry_keywords IS 'Keywords a retry runs instead of keywords, cleared when the run ends';
+
+COMMIT;
@@ -0,0 +1,32 @@
+-- Migration 0024: Email Validation Queue (DOWN)
+
+BEGIN;
+
+CREATE OR REPLACE FUNCTION update_email_validation(
+    p_email TEXT, p_status TEXT, p_score NUMERIC, p_deliverable BOOLEAN,
+    p_disposable BOOLEAN, p_role_account BOOLEAN, p_free_email BOOLEAN,
+    p_catch_all BOOLEAN, p_reason TEXT
+) RETURNS BOOLEAN AS $$
+DECLARE
+    v_validation_status TEXT;
+BEGIN
+    IF p_status = 'valid' AND p_deliverable = true AND p_disposable = false AND p_role_account = false AND p_score >= 70 THEN
+        v_validation_status := 'api_valid';
+    ELSE
+        v_validation_status := 'api_invalid';
+    END IF;
+
+    UPDATE emails SET
+        validation_status = v_validation_status, api_status = p_status, api_score = p_score,
+        api_deliverable = p_deliverable, api_disposable = p_disposable, api_role_account = p_role_account,
+        api_free_email = p_free_email, api_catch_all = p_catch_all, api_reason = p_reason, api_validated_at = NOW()
+    WHERE email = lower(trim(p_email));
+    RETURN FOUND;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_emails_api_validated_at;
+
+ALTER TABLE emails DROP COLUMN IF EXISTS validation_queued_at;
+
+COMMIT;
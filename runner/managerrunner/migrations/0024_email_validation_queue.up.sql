@@ -0,0 +1,39 @@
+-- Migration 0024: Email Validation Queue
+-- Claim marker for emails handed to the manager's validator workers and an
+-- index for validation throughput
+
+BEGIN;
+
+ALTER TABLE emails ADD COLUMN IF NOT EXISTS validation_queued_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN emails.validation_queued_at IS 'When the email was queued for API validation, cleared once validated';
+
+CREATE INDEX IF NOT EXISTS idx_emails_api_validated_at ON emails(api_validated_at) WHERE api_validated_at IS NOT NULL;
+
+-- Storing a result also ends the claim. An error keeps it, so the email is
+-- queued again once the claim expires.
+CREATE OR REPLACE FUNCTION update_email_validation(
+    p_email TEXT, p_status TEXT, p_score NUMERIC, p_deliverable BOOLEAN,
+    p_disposable BOOLEAN, p_role_account BOOLEAN, p_free_email BOOLEAN,
+    p_catch_all BOOLEAN, p_reason TEXT
+) RETURNS BOOLEAN AS $$
+DECLARE
+    v_validation_status TEXT;
+BEGIN
+    IF p_status = 'valid' AND p_deliverable = true AND p_disposable = false AND p_role_account = false AND p_score >= 70 THEN
+        v_validation_status := 'api_valid';
+    ELSE
+        v_validation_status := 'api_invalid';
+    END IF;
+
+    UPDATE emails SET
+        validation_status = v_validation_status, api_status = p_status, api_score = p_score,
+        api_deliverable = p_deliverable, api_disposable = p_disposable, api_role_account = p_role_account,
+        api_free_email = p_free_email, api_catch_all = p_catch_all, api_reason = p_reason, api_validated_at = NOW(),
+        validation_queued_at = NULL
+    WHERE email = lower(trim(p_email));
+    RETURN FOUND;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Migration 0025: Job Browser Profile (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS accept_language;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS user_agent;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS browser_profile;
+
+COMMIT;
@@ -0,0 +1,13 @@
+-- Migration 0025: Job Browser Profile
+-- Persist the User-Agent and Accept-Language a job sends, so workers use them
+-- and exports can tell which profile produced the data
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS browser_profile TEXT;
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS user_agent TEXT;
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS accept_language TEXT;
+
+COMMENT ON COLUMN jobs_queue.user_agent IS 'User-Agent sent to Google Maps; NULL means the scraper default';
+
+COMMIT;
@@ -0,0 +1,15 @@
+-- Migration 0026: Incremental Jobs (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_business_listings_job_new;
+DROP INDEX IF EXISTS idx_business_listings_place_id_id;
+
+ALTER TABLE business_listings DROP COLUMN IF EXISTS first_seen_job_id;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS is_new;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS known_places;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS new_places;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS incremental;
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- Migration 0026: Incremental Jobs
+-- Incremental jobs flag the places they ingest as new or already known
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS incremental BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS new_places INT NOT NULL DEFAULT 0;
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS known_places INT NOT NULL DEFAULT 0;
+
+-- NULL for listings of jobs that are not incremental
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS is_new BOOLEAN;
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS first_seen_job_id UUID REFERENCES jobs_queue(id) ON DELETE SET NULL;
+
+-- Finds the earliest listing of a place without sorting all of them
+CREATE INDEX IF NOT EXISTS idx_business_listings_place_id_id
+    ON business_listings(place_id, id) WHERE place_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_business_listings_job_new
+    ON business_listings(job_id) WHERE is_new;
+
+COMMIT;
@@ -0,0 +1,13 @@
+-- Migration 0027: Phone E.164 (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_business_listings_job_phone_unparsed;
+
+DROP TRIGGER IF EXISTS trg_reset_listing_phone_e164 ON business_listings;
+DROP FUNCTION IF EXISTS reset_listing_phone_e164();
+
+ALTER TABLE business_listings DROP COLUMN IF EXISTS phone_valid;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS phone_e164;
+
+COMMIT;
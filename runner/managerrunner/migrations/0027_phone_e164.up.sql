@@ -0,0 +1,35 @@
+-- Migration 0027: Phone E.164
+-- Listing phone numbers normalized to E.164 at ingestion, next to the
+-- number as Google Maps displays it
+
+BEGIN;
+
+-- phone_valid is NULL until the number was parsed, then whether it was;
+-- phone_e164 is only set for valid numbers
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS phone_e164 TEXT;
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS phone_valid BOOLEAN;
+
+-- A listing updated with a different phone is parsed again
+CREATE OR REPLACE FUNCTION reset_listing_phone_e164()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.phone IS DISTINCT FROM OLD.phone THEN
+        NEW.phone_e164 := NULL;
+        NEW.phone_valid := NULL;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_reset_listing_phone_e164 ON business_listings;
+CREATE TRIGGER trg_reset_listing_phone_e164
+    BEFORE UPDATE OF phone ON business_listings
+    FOR EACH ROW
+    EXECUTE FUNCTION reset_listing_phone_e164();
+
+-- Listings of a job still waiting to be parsed
+CREATE INDEX IF NOT EXISTS idx_business_listings_job_phone_unparsed
+    ON business_listings(job_id) WHERE phone_valid IS NULL AND phone <> '';
+
+COMMIT;
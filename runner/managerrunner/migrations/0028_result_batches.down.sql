@@ -0,0 +1,7 @@
+-- Migration 0028: Result Batches (DOWN)
+
+BEGIN;
+
+DROP TABLE IF EXISTS result_batches;
+
+COMMIT;
@@ -0,0 +1,16 @@
+-- Migration 0028: Result Batches
+-- IDs of the result batches a worker submitted, so a batch retried after a
+-- lost response is not stored twice
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS result_batches (
+    batch_id UUID PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs_queue(id) ON DELETE CASCADE,
+    results INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_result_batches_job_id ON result_batches(job_id);
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration 0029: Re-normalization (DOWN)
+
+BEGIN;
+
+DROP TABLE IF EXISTS renormalize_failures;
+DROP TABLE IF EXISTS renormalize_runs;
+
+COMMIT;
@@ -0,0 +1,40 @@
+-- Migration 0029: Re-normalization
+-- Progress of re-normalizing stored results into business_listings and the
+-- results that could not be parsed
+
+BEGIN;
+
+-- One row per run over the results of a job, or of every job when job_id
+-- is NULL. A run that did not complete is resumed after last_result_id.
+CREATE TABLE IF NOT EXISTS renormalize_runs (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID REFERENCES jobs_queue(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'running'
+        CHECK (status IN ('running', 'completed', 'failed')),
+    max_result_id BIGINT NOT NULL,      -- Newer results were normalized on insert
+    last_result_id BIGINT NOT NULL DEFAULT 0,
+    total INT NOT NULL DEFAULT 0,
+    processed INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_renormalize_runs_job_id ON renormalize_runs(job_id, id DESC);
+
+-- Dead letters: results a run skipped, with the reason
+CREATE TABLE IF NOT EXISTS renormalize_failures (
+    id BIGSERIAL PRIMARY KEY,
+    run_id BIGINT NOT NULL REFERENCES renormalize_runs(id) ON DELETE CASCADE,
+    result_id BIGINT NOT NULL REFERENCES results(id) ON DELETE CASCADE,
+    job_id UUID,
+    error TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(run_id, result_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_renormalize_failures_result_id ON renormalize_failures(result_id);
+
+COMMIT;
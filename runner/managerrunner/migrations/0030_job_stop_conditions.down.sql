@@ -0,0 +1,8 @@
+-- Migration 0030: Job Stop Conditions (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS stopped_reason;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS max_results;
+
+COMMIT;
@@ -0,0 +1,12 @@
+-- Migration 0030: Job Stop Conditions
+-- Jobs may stop at a number of unique results, and record why they stopped
+
+BEGIN;
+
+-- 0 means no cap
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS max_results INT NOT NULL DEFAULT 0;
+
+-- max_results, max_time or exhausted; NULL until a run completed
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS stopped_reason TEXT;
+
+COMMIT;
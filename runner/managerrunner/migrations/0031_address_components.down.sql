@@ -0,0 +1,15 @@
+-- Migration 0031: Address Components (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_business_listings_postal_code;
+DROP INDEX IF EXISTS idx_business_listings_state;
+DROP INDEX IF EXISTS idx_business_listings_job_address_unparsed;
+
+DROP TRIGGER IF EXISTS trg_reset_listing_address_parsed ON business_listings;
+DROP FUNCTION IF EXISTS reset_listing_address_parsed();
+
+ALTER TABLE business_listings DROP COLUMN IF EXISTS address_parsed;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS address_number;
+
+COMMIT;
@@ -0,0 +1,42 @@
+-- Migration 0031: Address Components
+-- Listing addresses split into street, house number, postal code, city,
+-- state and country at ingestion, filling what complete_address lacks
+
+BEGIN;
+
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS address_number TEXT;
+
+-- address_parsed is NULL until the address was parsed, then whether it
+-- was placed in a city or postal code
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS address_parsed BOOLEAN;
+
+-- A listing updated with a different address is parsed again
+CREATE OR REPLACE FUNCTION reset_listing_address_parsed()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.address IS DISTINCT FROM OLD.address THEN
+        NEW.address_number := NULL;
+        NEW.address_parsed := NULL;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_reset_listing_address_parsed ON business_listings;
+CREATE TRIGGER trg_reset_listing_address_parsed
+    BEFORE UPDATE OF address ON business_listings
+    FOR EACH ROW
+    EXECUTE FUNCTION reset_listing_address_parsed();
+
+-- Listings of a job still waiting to be parsed
+CREATE INDEX IF NOT EXISTS idx_business_listings_job_address_unparsed
+    ON business_listings(job_id) WHERE address_parsed IS NULL AND address <> '';
+
+-- ?state= matches case-insensitively, ?postcode= is a prefix
+CREATE INDEX IF NOT EXISTS idx_business_listings_state
+    ON business_listings(lower(address_state)) WHERE address_state IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_business_listings_postal_code
+    ON business_listings(address_postal_code text_pattern_ops) WHERE address_postal_code IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Migration 0032: Worker Live Status (DOWN)
+
+BEGIN;
+
+ALTER TABLE workers DROP COLUMN IF EXISTS memory_bytes;
+ALTER TABLE workers DROP COLUMN IF EXISTS job_places_scraped;
+ALTER TABLE workers DROP COLUMN IF EXISTS seed_jobs_total;
+ALTER TABLE workers DROP COLUMN IF EXISTS seed_jobs_completed;
+
+COMMIT;
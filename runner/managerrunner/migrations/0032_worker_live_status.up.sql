@@ -0,0 +1,12 @@
+-- Migration 0032: Worker Live Status
+-- Progress of the current job and memory use reported by workers in their
+-- heartbeat
+
+BEGIN;
+
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS seed_jobs_completed INT NOT NULL DEFAULT 0;
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS seed_jobs_total INT NOT NULL DEFAULT 0;
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS job_places_scraped INT NOT NULL DEFAULT 0;  -- Of the current job, not yet submitted
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS memory_bytes BIGINT NOT NULL DEFAULT 0;     -- Held by the worker process
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Migration 0033: Worker Drain (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_workers_drain_deadline;
+ALTER TABLE workers DROP COLUMN IF EXISTS drain_deadline;
+ALTER TABLE workers DROP COLUMN IF EXISTS draining_since;
+
+COMMIT;
@@ -0,0 +1,12 @@
+-- Migration 0033: Worker Drain
+-- A draining worker takes no new jobs and exits once its current job is
+-- done. Past the deadline the manager releases the job back to pending.
+
+BEGIN;
+
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS draining_since TIMESTAMPTZ;
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS drain_deadline TIMESTAMPTZ;  -- NULL waits for the job however long it takes
+
+CREATE INDEX IF NOT EXISTS idx_workers_drain_deadline ON workers(drain_deadline) WHERE drain_deadline IS NOT NULL;
+
+COMMIT;
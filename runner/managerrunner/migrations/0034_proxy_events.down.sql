@@ -0,0 +1,7 @@
+-- Migration 0034: Proxy Events (DOWN)
+
+BEGIN;
+
+DROP TABLE IF EXISTS proxy_events;
+
+COMMIT;
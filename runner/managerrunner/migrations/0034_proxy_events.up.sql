@@ -0,0 +1,22 @@
+-- Migration 0034: Proxy Events
+-- Why ProxyGate failed, quarantined, recovered or dropped a proxy. Rows
+-- outlive the proxy (cleanup deletes dead proxies), so the source URL is
+-- copied in. ProxyGate prunes the table to a fixed number of rows.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS proxy_events (
+    id BIGSERIAL PRIMARY KEY,
+    proxy_id BIGINT NOT NULL,
+    event_type VARCHAR(20) NOT NULL,    -- failed, quarantined, recovered, dead
+    reason VARCHAR(50),                 -- connect_timeout, rate_limited, captcha, ...
+    detail TEXT,
+    source_url TEXT,
+    job_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_proxy_events_proxy ON proxy_events(proxy_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_proxy_events_created ON proxy_events(created_at);
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration 0035: Job Cloned From (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_jobs_queue_cloned_from;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS cloned_from;
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Migration 0035: Job Cloned From
+-- A job created by POST /api/v2/jobs/{id}/clone points at its source job.
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS cloned_from UUID REFERENCES jobs_queue(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_cloned_from ON jobs_queue(cloned_from) WHERE cloned_from IS NOT NULL;
+
+COMMIT;
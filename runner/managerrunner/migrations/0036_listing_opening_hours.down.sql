@@ -0,0 +1,32 @@
+-- Migration 0036: Listing Opening Hours (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_business_listings_opening_hours;
+
+-- Restore the 0016 function before dropping the columns it would write
+CREATE OR REPLACE FUNCTION populate_listing_result_fields()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_data JSONB;
+BEGIN
+    IF NEW.result_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    SELECT r.data INTO v_data FROM results r WHERE r.id = NEW.result_id;
+
+    NEW.image_urls := CASE WHEN jsonb_typeof(v_data -> 'image_urls') = 'array' THEN v_data -> 'image_urls' END;
+    NEW.attributes := CASE WHEN jsonb_typeof(v_data -> 'attributes') = 'object' THEN v_data -> 'attributes' END;
+    NEW.social_links := CASE WHEN jsonb_typeof(v_data -> 'social_links') = 'object' THEN v_data -> 'social_links' END;
+    NEW.website_phone := NULLIF(v_data ->> 'website_phone', '');
+    NEW.website_description := NULLIF(v_data ->> 'website_description', '');
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE business_listings DROP COLUMN IF EXISTS hours_parsed;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS opening_hours;
+
+COMMIT;
@@ -0,0 +1,47 @@
+-- Migration 0036: Listing Opening Hours
+-- Opening hours as a per-day schedule, parsed by the scraper from the
+-- strings Google Maps displays
+
+BEGIN;
+
+-- hours_parsed is NULL when the place lists no hours or the result predates
+-- parsing, false when its hours did not parse (opening_hours stays NULL)
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS opening_hours JSONB;
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS hours_parsed BOOLEAN;
+
+-- Results without either key keep the listing's own values, which
+-- re-normalization writes
+CREATE OR REPLACE FUNCTION populate_listing_result_fields()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_data JSONB;
+BEGIN
+    IF NEW.result_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    SELECT r.data INTO v_data FROM results r WHERE r.id = NEW.result_id;
+
+    NEW.image_urls := CASE WHEN jsonb_typeof(v_data -> 'image_urls') = 'array' THEN v_data -> 'image_urls' END;
+    NEW.attributes := CASE WHEN jsonb_typeof(v_data -> 'attributes') = 'object' THEN v_data -> 'attributes' END;
+    NEW.social_links := CASE WHEN jsonb_typeof(v_data -> 'social_links') = 'object' THEN v_data -> 'social_links' END;
+    NEW.website_phone := NULLIF(v_data ->> 'website_phone', '');
+    NEW.website_description := NULLIF(v_data ->> 'website_description', '');
+
+    IF jsonb_typeof(v_data -> 'open_hours_parsed') = 'object' THEN
+        NEW.opening_hours := v_data -> 'open_hours_parsed';
+        NEW.hours_parsed := true;
+    ELSIF v_data ? 'open_hours_parse_error' THEN
+        NEW.opening_hours := NULL;
+        NEW.hours_parsed := false;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Listings open on a day, for open_on
+CREATE INDEX IF NOT EXISTS idx_business_listings_opening_hours
+    ON business_listings USING GIN ((opening_hours -> 'days'));
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Migration 0037: Manager Leases (DOWN)
+
+BEGIN;
+
+DROP TABLE IF EXISTS manager_leases;
+
+COMMIT;
@@ -0,0 +1,14 @@
+-- Migration 0037: Manager Leases
+-- Lease locks that elect the manager replica running the background tasks,
+-- used when Redis is not configured
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS manager_leases (
+    name TEXT PRIMARY KEY,
+    holder TEXT NOT NULL,
+    acquired_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+COMMIT;
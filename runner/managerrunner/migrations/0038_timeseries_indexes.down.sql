@@ -0,0 +1,8 @@
+-- Migration 0038: Time Series Indexes (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_jobs_queue_completed_at;
+DROP INDEX IF EXISTS idx_emails_first_seen_at;
+
+COMMIT;
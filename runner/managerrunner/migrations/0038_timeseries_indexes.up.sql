@@ -0,0 +1,13 @@
+-- Migration 0038: Time Series Indexes
+-- Range scans for /api/v2/stats/timeseries. business_listings.created_at is
+-- indexed since 0004.
+
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_emails_first_seen_at ON emails(first_seen_at);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_completed_at
+    ON jobs_queue(completed_at)
+    WHERE status = 'completed';
+
+COMMIT;
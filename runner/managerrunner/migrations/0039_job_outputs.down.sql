@@ -0,0 +1,7 @@
+-- Migration 0039: Job Outputs (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS outputs;
+
+COMMIT;
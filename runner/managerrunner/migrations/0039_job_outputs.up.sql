@@ -0,0 +1,9 @@
+-- Migration 0039: Job Outputs
+-- Extra destinations (S3, webhook) the worker writes a job's results to,
+-- as a JSON array of domain.JobOutput.
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS outputs JSONB;
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration 0040: Job Soft Delete (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_jobs_queue_deleted_at;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS deleted_at;
+
+COMMIT;
@@ -0,0 +1,14 @@
+-- Migration 0040: Job Soft Delete
+-- Deleting a job marks it instead of removing it; its results stay until
+-- the job is restored, purged with ?hard=true, or swept by the retention.
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- The retention sweep looks for old deletions
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_deleted_at
+    ON jobs_queue(deleted_at)
+    WHERE deleted_at IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration 0041: Job Dedupe Scope (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS deduped_places;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS global_dedupe;
+
+COMMIT;
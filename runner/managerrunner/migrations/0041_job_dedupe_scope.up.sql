@@ -0,0 +1,11 @@
+-- Migration 0041: Job Dedupe Scope
+-- Workers skip the places a job already scraped; global_dedupe widens
+-- that to the places any job scraped recently. deduped_places counts the
+-- places the last run skipped.
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS global_dedupe BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS deduped_places INTEGER NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Migration 0042: Job Parse Report (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS parse_report;
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Migration 0042: Job Parse Report
+-- Workers count the fields they could not read from Google's place data;
+-- the counts of a job's last run are kept to notice layout changes.
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS parse_report JSONB;
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration 0043: Listing Keyset Indexes (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_business_listings_job_id_created_at_id;
+DROP INDEX IF EXISTS idx_business_listings_created_at_id_keyset;
+
+COMMIT;
@@ -0,0 +1,15 @@
+-- Migration 0043: Listing Keyset Indexes
+-- Cursor pages of /api/v2/results and /api/v2/jobs/{id}/results seek to
+-- (created_at, id) < (...) ordered by both columns in the same direction,
+-- which idx_business_listings_created_at_id (created_at DESC, id) cannot
+-- serve.
+
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_business_listings_created_at_id_keyset
+    ON business_listings(created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_business_listings_job_id_created_at_id
+    ON business_listings(job_id, created_at, id);
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Migration 0044: Job Tags (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_jobs_queue_tags;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS notes;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS tags;
+
+COMMIT;
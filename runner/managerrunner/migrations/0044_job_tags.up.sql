@@ -0,0 +1,13 @@
+-- Migration 0044: Job Tags
+-- Free-form tags and notes to organize jobs by client and campaign. The
+-- job list and the business listings filter on tags with @>.
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_tags
+    ON jobs_queue USING GIN (tags);
+
+COMMIT;
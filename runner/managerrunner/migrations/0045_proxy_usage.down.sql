@@ -0,0 +1,7 @@
+-- Migration 0045: Proxy Usage (DOWN)
+
+BEGIN;
+
+DROP TABLE IF EXISTS proxy_usage;
+
+COMMIT;
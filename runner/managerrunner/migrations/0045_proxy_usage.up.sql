@@ -0,0 +1,22 @@
+-- Migration 0045: Proxy Usage
+-- Bytes relayed and connections opened by ProxyGate per upstream proxy and
+-- job, summed per hour. Every manager adds its counters to the row of the
+-- hour on each flush. job_id is NULL for connections without a job session.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS proxy_usage (
+    bucket TIMESTAMPTZ NOT NULL,
+    proxy_id BIGINT NOT NULL,
+    job_id UUID,
+    bytes_up BIGINT NOT NULL DEFAULT 0,     -- Client to upstream
+    bytes_down BIGINT NOT NULL DEFAULT 0,   -- Upstream to client
+    requests BIGINT NOT NULL DEFAULT 0,     -- Connections relayed
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_proxy_usage_key
+    ON proxy_usage(bucket, proxy_id, COALESCE(job_id, '00000000-0000-0000-0000-000000000000'::uuid));
+CREATE INDEX IF NOT EXISTS idx_proxy_usage_job ON proxy_usage(job_id) WHERE job_id IS NOT NULL;
+
+COMMIT;
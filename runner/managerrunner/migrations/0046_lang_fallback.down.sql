@@ -0,0 +1,9 @@
+-- Migration 0046: Language Fallback (DOWN)
+
+BEGIN;
+
+ALTER TABLE gmaps_jobs DROP COLUMN IF EXISTS lang_fallbacks;
+ALTER TABLE gmaps_jobs DROP COLUMN IF EXISTS lang;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS lang_fallback;
+
+COMMIT;
@@ -0,0 +1,13 @@
+-- Migration 0046: Language Fallback
+-- Searches that find nothing in the job's language are retried in the
+-- languages of lang_fallback. Seed tasks record the language they ran in
+-- and how many times they fell back.
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS lang_fallback TEXT[];
+
+ALTER TABLE gmaps_jobs ADD COLUMN IF NOT EXISTS lang TEXT;
+ALTER TABLE gmaps_jobs ADD COLUMN IF NOT EXISTS lang_fallbacks INTEGER NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Migration 0047: Maintenance Runs (DOWN)
+
+BEGIN;
+
+DROP TABLE IF EXISTS maintenance_runs;
+
+COMMIT;
@@ -0,0 +1,20 @@
+-- Migration 0047: Maintenance Runs
+-- ANALYZE, VACUUM and REINDEX passes started from the admin API or the
+-- -maintenance-schedule, with the progress of their steps as JSON. One runs
+-- at a time, guarded by an advisory lock.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS maintenance_runs (
+    id BIGSERIAL PRIMARY KEY,
+    trigger TEXT NOT NULL,                    -- api, schedule
+    options JSONB NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL DEFAULT 'running',   -- running, completed, failed
+    steps JSONB NOT NULL DEFAULT '[]',
+    done INTEGER NOT NULL DEFAULT 0,          -- Steps completed
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration 0048: Job Error Codes (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_jobs_queue_error_code;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS error_code;
+
+COMMIT;
@@ -0,0 +1,17 @@
+-- Migration 0048: Job Error Codes
+-- Failed jobs record the kind of failure next to the free-form message, so
+-- failures can be counted and filtered by kind. Jobs that failed before are
+-- internal errors.
+
+BEGIN;
+
+-- One of proxy_exhausted, blocked_by_google, timeout, parse_error,
+-- manager_unreachable, no_results, internal; NULL unless the job failed
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS error_code TEXT;
+
+UPDATE jobs_queue SET error_code = 'internal' WHERE status = 'failed' AND error_code IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_error_code
+    ON jobs_queue (error_code) WHERE error_code IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Migration 0049: Job Notify Emails (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS notify_emails;
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration 0049: Job Notify Emails
+-- Addresses the summary report of a job is emailed to once it completes.
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS notify_emails TEXT[];
+
+COMMIT;
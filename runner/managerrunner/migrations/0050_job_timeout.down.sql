@@ -0,0 +1,8 @@
+-- Migration 0050: Job Timeouts (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS timeout_requeued;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS retry_on_timeout;
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Migration 0050: Job Timeouts
+-- The manager fails running jobs that overran their max_time, or puts them
+-- back to pending once when retry_on_timeout is set; timeout_requeued
+-- records that it did.
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS retry_on_timeout BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS timeout_requeued BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Migration 0051: Category Mappings (DOWN)
+
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_populate_listing_canonical_category ON business_listings;
+DROP FUNCTION IF EXISTS populate_listing_canonical_category();
+DROP INDEX IF EXISTS idx_business_listings_canonical_category;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS canonical_category;
+DROP TABLE IF EXISTS category_mappings;
+
+COMMIT;
@@ -0,0 +1,154 @@
+-- Migration 0051: Category Mappings
+-- Maps the localized categories of listings to a canonical taxonomy, so
+-- listings of jobs in different languages can be filtered by one name
+
+BEGIN;
+
+-- Aliases are lower-cased; every canonical name is also its own alias
+CREATE TABLE IF NOT EXISTS category_mappings (
+    alias TEXT PRIMARY KEY,
+    canonical TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_mappings_canonical ON category_mappings(canonical);
+
+INSERT INTO category_mappings (alias, canonical) VALUES
+    -- Restaurant
+    ('restaurant', 'Restaurant'), ('restaurante', 'Restaurant'), ('ristorante', 'Restaurant'),
+    ('restoran', 'Restaurant'), ('rumah makan', 'Restaurant'), ('餐厅', 'Restaurant'),
+    ('餐館', 'Restaurant'), ('レストラン', 'Restaurant'), ('음식점', 'Restaurant'),
+    -- Cafe
+    ('cafe', 'Cafe'), ('café', 'Cafe'), ('cafetería', 'Cafe'), ('cafeteria', 'Cafe'),
+    ('kafe', 'Cafe'), ('kedai kopi', 'Cafe'), ('coffee shop', 'Cafe'), ('咖啡店', 'Cafe'),
+    ('咖啡館', 'Cafe'), ('カフェ', 'Cafe'), ('카페', 'Cafe'),
+    -- Bar
+    ('bar', 'Bar'), ('kneipe', 'Bar'), ('酒吧', 'Bar'), ('バー', 'Bar'),
+    -- Bakery
+    ('bakery', 'Bakery'), ('panadería', 'Bakery'), ('padaria', 'Bakery'), ('boulangerie', 'Bakery'),
+    ('bäckerei', 'Bakery'), ('panetteria', 'Bakery'), ('bakkerij', 'Bakery'), ('toko roti', 'Bakery'),
+    ('面包店', 'Bakery'), ('パン屋', 'Bakery'),
+    -- Hotel
+    ('hotel', 'Hotel'), ('hôtel', 'Hotel'), ('albergo', 'Hotel'), ('酒店', 'Hotel'),
+    ('饭店', 'Hotel'), ('ホテル', 'Hotel'), ('호텔', 'Hotel'),
+    -- Supermarket
+    ('supermarket', 'Supermarket'), ('supermercado', 'Supermarket'), ('supermarché', 'Supermarket'),
+    ('supermarkt', 'Supermarket'), ('supermercato', 'Supermarket'), ('pasar swalayan', 'Supermarket'),
+    ('超市', 'Supermarket'), ('スーパーマーケット', 'Supermarket'),
+    -- Pharmacy
+    ('pharmacy', 'Pharmacy'), ('farmacia', 'Pharmacy'), ('farmácia', 'Pharmacy'), ('pharmacie', 'Pharmacy'),
+    ('apotheke', 'Pharmacy'), ('apotheek', 'Pharmacy'), ('apotek', 'Pharmacy'), ('药店', 'Pharmacy'),
+    ('薬局', 'Pharmacy'),
+    -- Hospital
+    ('hospital', 'Hospital'), ('hôpital', 'Hospital'), ('krankenhaus', 'Hospital'), ('ospedale', 'Hospital'),
+    ('ziekenhuis', 'Hospital'), ('rumah sakit', 'Hospital'), ('医院', 'Hospital'), ('病院', 'Hospital'),
+    -- Dentist
+    ('dentist', 'Dentist'), ('dentista', 'Dentist'), ('dentiste', 'Dentist'), ('zahnarzt', 'Dentist'),
+    ('tandarts', 'Dentist'), ('dokter gigi', 'Dentist'), ('牙医', 'Dentist'), ('歯科医院', 'Dentist'),
+    -- Doctor
+    ('doctor', 'Doctor'), ('médico', 'Doctor'), ('médecin', 'Doctor'), ('arzt', 'Doctor'),
+    ('medico', 'Doctor'), ('huisarts', 'Doctor'), ('dokter', 'Doctor'), ('医生', 'Doctor'),
+    ('医師', 'Doctor'),
+    -- Gym
+    ('gym', 'Gym'), ('gimnasio', 'Gym'), ('academia', 'Gym'), ('salle de sport', 'Gym'),
+    ('fitnessstudio', 'Gym'), ('palestra', 'Gym'), ('sportschool', 'Gym'), ('pusat kebugaran', 'Gym'),
+    ('健身房', 'Gym'), ('ジム', 'Gym'),
+    -- Hair salon
+    ('hair salon', 'Hair salon'), ('peluquería', 'Hair salon'), ('salão de cabeleireiro', 'Hair salon'),
+    ('salon de coiffure', 'Hair salon'), ('friseursalon', 'Hair salon'), ('parrucchiere', 'Hair salon'),
+    ('kapsalon', 'Hair salon'), ('salon rambut', 'Hair salon'), ('理发店', 'Hair salon'),
+    ('美容院', 'Hair salon'),
+    -- Beauty salon
+    ('beauty salon', 'Beauty salon'), ('salón de belleza', 'Beauty salon'), ('salão de beleza', 'Beauty salon'),
+    ('institut de beauté', 'Beauty salon'), ('kosmetikstudio', 'Beauty salon'),
+    ('centro estetico', 'Beauty salon'), ('schoonheidssalon', 'Beauty salon'),
+    ('salon kecantikan', 'Beauty salon'), ('美容店', 'Beauty salon'), ('エステサロン', 'Beauty salon'),
+    -- Car repair
+    ('car repair', 'Car repair'), ('auto repair shop', 'Car repair'), ('taller mecánico', 'Car repair'),
+    ('oficina mecânica', 'Car repair'), ('garage automobile', 'Car repair'), ('autowerkstatt', 'Car repair'),
+    ('officina meccanica', 'Car repair'), ('autogarage', 'Car repair'), ('bengkel mobil', 'Car repair'),
+    ('汽车修理店', 'Car repair'), ('自動車整備工場', 'Car repair'),
+    -- Gas station
+    ('gas station', 'Gas station'), ('gasolinera', 'Gas station'), ('posto de gasolina', 'Gas station'),
+    ('station-service', 'Gas station'), ('tankstelle', 'Gas station'), ('distributore di benzina', 'Gas station'),
+    ('tankstation', 'Gas station'), ('pom bensin', 'Gas station'), ('spbu', 'Gas station'),
+    ('加油站', 'Gas station'), ('ガソリンスタンド', 'Gas station'),
+    -- Bank
+    ('bank', 'Bank'), ('banco', 'Bank'), ('banque', 'Bank'), ('banca', 'Bank'), ('银行', 'Bank'),
+    ('銀行', 'Bank'), ('은행', 'Bank'),
+    -- School
+    ('school', 'School'), ('escuela', 'School'), ('escola', 'School'), ('école', 'School'),
+    ('schule', 'School'), ('scuola', 'School'), ('sekolah', 'School'), ('学校', 'School'),
+    -- Real estate agency
+    ('real estate agency', 'Real estate agency'), ('inmobiliaria', 'Real estate agency'),
+    ('imobiliária', 'Real estate agency'), ('agence immobilière', 'Real estate agency'),
+    ('immobilienmakler', 'Real estate agency'), ('agenzia immobiliare', 'Real estate agency'),
+    ('makelaardij', 'Real estate agency'), ('agen properti', 'Real estate agency'),
+    ('房地产中介', 'Real estate agency'), ('不動産会社', 'Real estate agency'),
+    -- Lawyer
+    ('lawyer', 'Lawyer'), ('law firm', 'Lawyer'), ('abogado', 'Lawyer'), ('advogado', 'Lawyer'),
+    ('avocat', 'Lawyer'), ('rechtsanwalt', 'Lawyer'), ('avvocato', 'Lawyer'), ('advocaat', 'Lawyer'),
+    ('pengacara', 'Lawyer'), ('律师事务所', 'Lawyer'), ('法律事務所', 'Lawyer'),
+    -- Clothing store
+    ('clothing store', 'Clothing store'), ('tienda de ropa', 'Clothing store'), ('loja de roupas', 'Clothing store'),
+    ('magasin de vêtements', 'Clothing store'), ('bekleidungsgeschäft', 'Clothing store'),
+    ('negozio di abbigliamento', 'Clothing store'), ('kledingwinkel', 'Clothing store'),
+    ('toko pakaian', 'Clothing store'), ('服装店', 'Clothing store'), ('衣料品店', 'Clothing store'),
+    -- Electronics store
+    ('electronics store', 'Electronics store'), ('tienda de electrónica', 'Electronics store'),
+    ('loja de eletrônicos', 'Electronics store'), ('magasin d''électronique', 'Electronics store'),
+    ('elektronikgeschäft', 'Electronics store'), ('negozio di elettronica', 'Electronics store'),
+    ('elektronicawinkel', 'Electronics store'), ('toko elektronik', 'Electronics store'),
+    ('电子产品商店', 'Electronics store'), ('家電量販店', 'Electronics store'),
+    -- Furniture store
+    ('furniture store', 'Furniture store'), ('tienda de muebles', 'Furniture store'), ('loja de móveis', 'Furniture store'),
+    ('magasin de meubles', 'Furniture store'), ('möbelgeschäft', 'Furniture store'),
+    ('negozio di mobili', 'Furniture store'), ('meubelwinkel', 'Furniture store'), ('toko mebel', 'Furniture store'),
+    ('家具店', 'Furniture store'),
+    -- Plumber
+    ('plumber', 'Plumber'), ('fontanero', 'Plumber'), ('encanador', 'Plumber'), ('plombier', 'Plumber'),
+    ('klempner', 'Plumber'), ('idraulico', 'Plumber'), ('loodgieter', 'Plumber'), ('tukang ledeng', 'Plumber'),
+    ('水管工', 'Plumber'),
+    -- Electrician
+    ('electrician', 'Electrician'), ('electricista', 'Electrician'), ('eletricista', 'Electrician'),
+    ('électricien', 'Electrician'), ('elektriker', 'Electrician'), ('elettricista', 'Electrician'),
+    ('elektricien', 'Electrician'), ('tukang listrik', 'Electrician'), ('电工', 'Electrician'),
+    -- Veterinarian
+    ('veterinarian', 'Veterinarian'), ('veterinario', 'Veterinarian'), ('veterinário', 'Veterinarian'),
+    ('vétérinaire', 'Veterinarian'), ('tierarzt', 'Veterinarian'), ('dierenarts', 'Veterinarian'),
+    ('dokter hewan', 'Veterinarian'), ('兽医', 'Veterinarian'), ('動物病院', 'Veterinarian')
+ON CONFLICT (alias) DO NOTHING;
+
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS canonical_category TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_business_listings_canonical_category ON business_listings(canonical_category);
+
+-- Looks the category up on ingestion, keeping the raw category when no
+-- alias matches
+CREATE OR REPLACE FUNCTION populate_listing_canonical_category()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.canonical_category := COALESCE(
+        (SELECT m.canonical FROM category_mappings m WHERE m.alias = lower(NEW.category)),
+        NULLIF(NEW.category, '')
+    );
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_populate_listing_canonical_category ON business_listings;
+CREATE TRIGGER trg_populate_listing_canonical_category
+    BEFORE INSERT OR UPDATE OF category ON business_listings
+    FOR EACH ROW
+    EXECUTE FUNCTION populate_listing_canonical_category();
+
+-- Backfill listings ingested before this migration
+UPDATE business_listings bl
+SET canonical_category = COALESCE(
+    (SELECT m.canonical FROM category_mappings m WHERE m.alias = lower(bl.category)),
+    NULLIF(bl.category, '')
+)
+WHERE bl.category IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration 0052: Export Profiles (DOWN)
+
+BEGIN;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS export_profile;
+DROP TABLE IF EXISTS export_profiles;
+
+COMMIT;
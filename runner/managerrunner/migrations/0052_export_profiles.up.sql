@@ -0,0 +1,20 @@
+-- Migration 0052: Export Profiles
+-- Named column whitelists with optional redaction that downloads are
+-- limited to, per request or for every download of an API key
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS export_profiles (
+    name TEXT PRIMARY KEY,
+    columns JSONB NOT NULL,                      -- Listing export keys, in order
+    mask_emails BOOLEAN NOT NULL DEFAULT FALSE,  -- Keep only the domain of emails
+    truncate_coords BOOLEAN NOT NULL DEFAULT FALSE, -- Cut coordinates to 3 decimals
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A profile cannot be deleted while keys are restricted to it
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS export_profile TEXT
+    REFERENCES export_profiles(name) ON DELETE RESTRICT;   -- NULL = unrestricted
+
+COMMIT;
@@ -0,0 +1,26 @@
+-- Migration 0053: Async Normalization (DOWN)
+-- Results still queued are left without listings; re-normalize their jobs
+-- after rolling back.
+
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_populate_normalized_listings_deferred ON results;
+DROP TRIGGER IF EXISTS trg_populate_business_reviews_deferred ON results;
+
+DROP TRIGGER IF EXISTS trg_populate_normalized_listings ON results;
+CREATE TRIGGER trg_populate_normalized_listings
+    AFTER INSERT ON results
+    FOR EACH ROW
+    EXECUTE FUNCTION populate_normalized_listings();
+
+DROP TRIGGER IF EXISTS trg_populate_business_reviews ON results;
+CREATE TRIGGER trg_populate_business_reviews
+    AFTER INSERT ON results
+    FOR EACH ROW
+    EXECUTE FUNCTION populate_business_reviews();
+
+DROP TABLE IF EXISTS normalize_queue;
+DROP INDEX IF EXISTS idx_results_unnormalized;
+ALTER TABLE results DROP COLUMN IF EXISTS normalized_at;
+
+COMMIT;
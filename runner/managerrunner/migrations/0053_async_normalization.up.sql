@@ -0,0 +1,59 @@
+-- Migration 0053: Async Normalization
+-- Results submitted by workers are stored without being normalized and
+-- queued; the manager's normalizer turns them into business listings in
+-- the background. Results inserted any other way are still normalized on
+-- insert.
+
+BEGIN;
+
+-- NULL while the result waits for the normalizer. Existing results were
+-- normalized on insert and take the time of the migration.
+ALTER TABLE results ADD COLUMN IF NOT EXISTS normalized_at TIMESTAMPTZ DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_results_unnormalized ON results(job_id) WHERE normalized_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS normalize_queue (
+    result_id BIGINT PRIMARY KEY REFERENCES results(id) ON DELETE CASCADE,
+    job_id UUID,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    enqueued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    failed_at TIMESTAMPTZ                      -- Given up on, kept for inspection
+);
+
+CREATE INDEX IF NOT EXISTS idx_normalize_queue_due ON normalize_queue(next_attempt_at) WHERE failed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_normalize_queue_job_id ON normalize_queue(job_id);
+
+-- The listing and review triggers fire on insert for results stored
+-- normalized, and when the normalizer sets normalized_at for the others.
+-- Triggers fire by name, so the reviews still come first.
+DROP TRIGGER IF EXISTS trg_populate_normalized_listings ON results;
+CREATE TRIGGER trg_populate_normalized_listings
+    AFTER INSERT ON results
+    FOR EACH ROW
+    WHEN (NEW.normalized_at IS NOT NULL)
+    EXECUTE FUNCTION populate_normalized_listings();
+
+DROP TRIGGER IF EXISTS trg_populate_normalized_listings_deferred ON results;
+CREATE TRIGGER trg_populate_normalized_listings_deferred
+    AFTER UPDATE OF normalized_at ON results
+    FOR EACH ROW
+    WHEN (OLD.normalized_at IS NULL AND NEW.normalized_at IS NOT NULL)
+    EXECUTE FUNCTION populate_normalized_listings();
+
+DROP TRIGGER IF EXISTS trg_populate_business_reviews ON results;
+CREATE TRIGGER trg_populate_business_reviews
+    AFTER INSERT ON results
+    FOR EACH ROW
+    WHEN (NEW.normalized_at IS NOT NULL)
+    EXECUTE FUNCTION populate_business_reviews();
+
+DROP TRIGGER IF EXISTS trg_populate_business_reviews_deferred ON results;
+CREATE TRIGGER trg_populate_business_reviews_deferred
+    AFTER UPDATE OF normalized_at ON results
+    FOR EACH ROW
+    WHEN (OLD.normalized_at IS NULL AND NEW.normalized_at IS NOT NULL)
+    EXECUTE FUNCTION populate_business_reviews();
+
+COMMIT;
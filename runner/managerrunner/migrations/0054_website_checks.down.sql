@@ -0,0 +1,18 @@
+-- Migration 0054: Website Checks (DOWN)
+
+BEGIN;
+
+DROP TABLE IF EXISTS website_checks;
+
+DROP INDEX IF EXISTS idx_business_listings_website_unchecked;
+DROP INDEX IF EXISTS idx_business_listings_website_status;
+
+ALTER TABLE business_listings DROP COLUMN IF EXISTS website_check_queued_at;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS website_checked_at;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS website_final_url;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS website_http_status;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS website_status;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS check_website;
+
+COMMIT;
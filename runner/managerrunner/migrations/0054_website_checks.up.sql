@@ -0,0 +1,33 @@
+-- Migration 0054: Website Checks
+-- Jobs with check_website have the websites of their listings fetched in
+-- the background to tell live sites from dead and parked ones. Checks are
+-- kept per domain too, so a domain checked recently is not fetched again.
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS check_website BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS website_status TEXT;          -- ok, parked, http_error, unreachable
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS website_http_status INTEGER;
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS website_final_url TEXT;       -- After redirects
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS website_checked_at TIMESTAMPTZ;
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS website_check_queued_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_business_listings_website_status
+    ON business_listings(website_status) WHERE website_status IS NOT NULL;
+
+-- Listings waiting for a check, found through the jobs that asked for it
+CREATE INDEX IF NOT EXISTS idx_business_listings_website_unchecked
+    ON business_listings(job_id)
+    WHERE website_checked_at IS NULL AND website IS NOT NULL AND website <> '';
+
+CREATE TABLE IF NOT EXISTS website_checks (
+    domain TEXT PRIMARY KEY,
+    status TEXT NOT NULL,
+    http_status INTEGER,
+    final_url TEXT,
+    error TEXT,
+    checked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMIT;
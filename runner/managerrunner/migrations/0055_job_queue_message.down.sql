@@ -0,0 +1,7 @@
+-- Migration 0055: Job Queue Message (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS queue_message_id;
+
+COMMIT;
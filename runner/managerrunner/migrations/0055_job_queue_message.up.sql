@@ -0,0 +1,10 @@
+-- Migration 0055: Job Queue Message
+-- The ID of the last message that enqueued a job to RabbitMQ or Redis; a
+-- manual requeue publishes it again so that workers, which drop message
+-- IDs they already handled, run the job once.
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS queue_message_id TEXT;
+
+COMMIT;
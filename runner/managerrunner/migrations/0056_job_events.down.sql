@@ -0,0 +1,7 @@
+-- Migration 0056: Job Events (DOWN)
+
+BEGIN;
+
+DROP TABLE IF EXISTS job_events;
+
+COMMIT;
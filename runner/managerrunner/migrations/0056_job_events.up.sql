@@ -0,0 +1,23 @@
+-- Migration 0056: Job Events
+-- The audit trail of jobs: every state transition and who caused it, an
+-- API key, a worker or the manager itself. Events are written in the
+-- transaction of the change they describe and pruned by the retention
+-- sweep of the manager.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS job_events (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs_queue(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    status TEXT,
+    actor TEXT NOT NULL,
+    error_code TEXT,
+    message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_events_job_id ON job_events (job_id, id);
+CREATE INDEX IF NOT EXISTS idx_job_events_created_at ON job_events (created_at);
+
+COMMIT;
@@ -0,0 +1,15 @@
+-- Migration 0057: Job Dependencies (DOWN)
+
+BEGIN;
+
+UPDATE jobs_queue SET status = 'pending' WHERE status = 'waiting';
+
+ALTER TABLE jobs_queue DROP CONSTRAINT IF EXISTS valid_status;
+ALTER TABLE jobs_queue ADD CONSTRAINT valid_status
+    CHECK (status IN ('pending', 'queued', 'running', 'paused', 'completed', 'failed', 'cancelled'));
+
+DROP INDEX IF EXISTS idx_jobs_queue_depends_on;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS run_if;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS depends_on;
+
+COMMIT;
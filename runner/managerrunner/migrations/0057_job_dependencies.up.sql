@@ -0,0 +1,17 @@
+-- Migration 0057: Job Dependencies
+-- A job with depends_on waits for its parent job to finish. run_if tells
+-- whether it runs once the parent completed only, or whatever the parent
+-- ended with; the manager's dependency sweep moves it on.
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS depends_on UUID REFERENCES jobs_queue(id) ON DELETE SET NULL;
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS run_if TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_depends_on ON jobs_queue (depends_on) WHERE depends_on IS NOT NULL;
+
+ALTER TABLE jobs_queue DROP CONSTRAINT IF EXISTS valid_status;
+ALTER TABLE jobs_queue ADD CONSTRAINT valid_status
+    CHECK (status IN ('waiting', 'pending', 'queued', 'running', 'paused', 'completed', 'failed', 'cancelled'));
+
+COMMIT;
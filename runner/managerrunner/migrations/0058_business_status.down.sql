@@ -0,0 +1,18 @@
+-- Migration 0058: Business Status (DOWN)
+
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_record_listing_status_change ON business_listings;
+DROP FUNCTION IF EXISTS record_listing_status_change();
+DROP TABLE IF EXISTS listing_status_changes;
+
+DROP TRIGGER IF EXISTS trg_populate_listing_business_status ON business_listings;
+DROP FUNCTION IF EXISTS populate_listing_business_status();
+DROP INDEX IF EXISTS idx_business_listings_business_status;
+ALTER TABLE business_listings DROP CONSTRAINT IF EXISTS valid_business_status;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS business_status;
+
+DROP FUNCTION IF EXISTS normalize_business_status(TEXT);
+DROP TABLE IF EXISTS business_status_labels;
+
+COMMIT;
@@ -0,0 +1,115 @@
+-- Migration 0058: Business Status
+-- Google words the status of a place in the language of the job. It is
+-- normalized on ingestion to open, temporarily_closed, permanently_closed
+-- or unknown, and a listing whose normalized status changes when it is
+-- upserted again has the change recorded.
+
+BEGIN;
+
+-- Labels are lower-cased phrases looked for anywhere in the status. A
+-- status with none of them is an open-now text such as "Open ⋅ Closes 5PM".
+CREATE TABLE IF NOT EXISTS business_status_labels (
+    lang TEXT NOT NULL,
+    label TEXT NOT NULL,
+    business_status TEXT NOT NULL CHECK (business_status IN ('temporarily_closed', 'permanently_closed')),
+    PRIMARY KEY (lang, label)
+);
+
+INSERT INTO business_status_labels (lang, label, business_status) VALUES
+    ('en', 'permanently closed', 'permanently_closed'), ('en', 'temporarily closed', 'temporarily_closed'),
+    ('es', 'cerrado permanentemente', 'permanently_closed'), ('es', 'cerrado temporalmente', 'temporarily_closed'),
+    ('pt', 'fechado permanentemente', 'permanently_closed'), ('pt', 'permanentemente fechado', 'permanently_closed'),
+    ('pt', 'fechado temporariamente', 'temporarily_closed'), ('pt', 'temporariamente fechado', 'temporarily_closed'),
+    ('fr', 'définitivement fermé', 'permanently_closed'), ('fr', 'fermé définitivement', 'permanently_closed'),
+    ('fr', 'temporairement fermé', 'temporarily_closed'), ('fr', 'fermé temporairement', 'temporarily_closed'),
+    ('de', 'dauerhaft geschlossen', 'permanently_closed'), ('de', 'vorübergehend geschlossen', 'temporarily_closed'),
+    ('it', 'chiuso definitivamente', 'permanently_closed'), ('it', 'chiuso temporaneamente', 'temporarily_closed'),
+    ('nl', 'permanent gesloten', 'permanently_closed'), ('nl', 'tijdelijk gesloten', 'temporarily_closed'),
+    ('id', 'tutup permanen', 'permanently_closed'), ('id', 'tutup sementara', 'temporarily_closed'),
+    ('tr', 'kalıcı olarak kapandı', 'permanently_closed'), ('tr', 'geçici olarak kapandı', 'temporarily_closed'),
+    ('pl', 'zamknięte na stałe', 'permanently_closed'), ('pl', 'tymczasowo zamknięte', 'temporarily_closed'),
+    ('ru', 'закрыто навсегда', 'permanently_closed'), ('ru', 'временно закрыто', 'temporarily_closed'),
+    ('ja', '閉業', 'permanently_closed'), ('ja', '臨時休業', 'temporarily_closed'),
+    ('zh', '永久停业', 'permanently_closed'), ('zh', '暂时停业', 'temporarily_closed'),
+    ('zh', '永久停業', 'permanently_closed'), ('zh', '暫時停業', 'temporarily_closed'),
+    ('ko', '폐업', 'permanently_closed'), ('ko', '임시 휴업', 'temporarily_closed')
+ON CONFLICT (lang, label) DO NOTHING;
+
+-- Maps a raw status to its business status. A permanent closure wins over
+-- a temporary one should a status carry both.
+CREATE OR REPLACE FUNCTION normalize_business_status(p_status TEXT)
+RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN NULLIF(btrim(p_status), '') IS NULL THEN 'unknown'
+        ELSE COALESCE(
+            (SELECT l.business_status FROM business_status_labels l
+             WHERE strpos(lower(p_status), l.label) > 0
+             ORDER BY l.business_status = 'permanently_closed' DESC
+             LIMIT 1),
+            'open')
+    END
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS business_status TEXT NOT NULL DEFAULT 'unknown';
+
+-- Backfill listings ingested before this migration, before the triggers
+-- below would record the backfill as changes
+UPDATE business_listings
+SET business_status = normalize_business_status(status)
+WHERE status IS NOT NULL AND status <> '';
+
+ALTER TABLE business_listings DROP CONSTRAINT IF EXISTS valid_business_status;
+ALTER TABLE business_listings ADD CONSTRAINT valid_business_status
+    CHECK (business_status IN ('open', 'temporarily_closed', 'permanently_closed', 'unknown'));
+
+CREATE INDEX IF NOT EXISTS idx_business_listings_business_status ON business_listings(business_status);
+
+CREATE OR REPLACE FUNCTION populate_listing_business_status()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.business_status := normalize_business_status(NEW.status);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_populate_listing_business_status ON business_listings;
+CREATE TRIGGER trg_populate_listing_business_status
+    BEFORE INSERT OR UPDATE OF status ON business_listings
+    FOR EACH ROW
+    EXECUTE FUNCTION populate_listing_business_status();
+
+-- Changes between two known statuses of the same listing; a status going
+-- missing or appearing for the first time is not a change
+CREATE TABLE IF NOT EXISTS listing_status_changes (
+    id BIGSERIAL PRIMARY KEY,
+    listing_id BIGINT NOT NULL REFERENCES business_listings(id) ON DELETE CASCADE,
+    job_id UUID,
+    place_id TEXT,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_listing_status_changes_listing ON listing_status_changes(listing_id, changed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_listing_status_changes_place ON listing_status_changes(place_id) WHERE place_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION record_listing_status_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.business_status IS DISTINCT FROM OLD.business_status
+       AND OLD.business_status <> 'unknown' AND NEW.business_status <> 'unknown' THEN
+        INSERT INTO listing_status_changes (listing_id, job_id, place_id, from_status, to_status)
+        VALUES (NEW.id, NEW.job_id, NEW.place_id, OLD.business_status, NEW.business_status);
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_record_listing_status_change ON business_listings;
+CREATE TRIGGER trg_record_listing_status_change
+    AFTER UPDATE OF status ON business_listings
+    FOR EACH ROW
+    EXECUTE FUNCTION record_listing_status_change();
+
+COMMIT;
@@ -0,0 +1,18 @@
+-- Migration 0059: Proxy Source Stats (DOWN)
+
+BEGIN;
+
+ALTER TABLE proxy_sources
+    DROP COLUMN IF EXISTS disabled_reason,
+    DROP COLUMN IF EXISTS disabled_at,
+    DROP COLUMN IF EXISTS low_refreshes,
+    DROP COLUMN IF EXISTS last_fetch_at,
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS fetch_latency_ms,
+    DROP COLUMN IF EXISTS passed,
+    DROP COLUMN IF EXISTS validated,
+    DROP COLUMN IF EXISTS parse_errors,
+    DROP COLUMN IF EXISTS fetched_count,
+    DROP COLUMN IF EXISTS active;
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- Migration 0059: Proxy Source Stats
+-- What the last finished refresh of each proxy source fetched and how many
+-- of its proxies passed validation. Sources whose pass rate stays below a
+-- threshold for several refreshes are disabled until re-enabled by hand.
+
+BEGIN;
+
+ALTER TABLE proxy_sources
+    ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS fetched_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS parse_errors INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS validated INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS passed INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS fetch_latency_ms BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_error TEXT,
+    ADD COLUMN IF NOT EXISTS last_fetch_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS low_refreshes INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS disabled_reason TEXT;
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Migration 0060: Google Domain (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS google_domain;
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Migration 0060: Google Domain
+-- The Google domain a job searches, e.g. google.de, resolved when it is
+-- created. NULL for older jobs, which searched google.com.
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS google_domain TEXT;
+
+COMMIT;
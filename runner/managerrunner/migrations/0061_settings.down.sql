@@ -0,0 +1,8 @@
+-- Migration 0061: Settings (DOWN)
+
+BEGIN;
+
+DROP TABLE IF EXISTS settings_history;
+DROP TABLE IF EXISTS settings;
+
+COMMIT;
@@ -0,0 +1,25 @@
+-- Migration 0061: Settings
+-- Server-side settings edited from the dashboard, such as the job defaults,
+-- as JSON values by key. Every change is kept in settings_history with the
+-- caller that made it.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS settings (
+    key TEXT PRIMARY KEY,
+    value JSONB NOT NULL DEFAULT '{}',
+    updated_by TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS settings_history (
+    id BIGSERIAL PRIMARY KEY,
+    key TEXT NOT NULL,
+    value JSONB NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_settings_history_key ON settings_history(key, created_at DESC);
+
+COMMIT;
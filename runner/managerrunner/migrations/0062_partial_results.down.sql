@@ -0,0 +1,20 @@
+-- Migration 0062: Partial Results (DOWN)
+
+BEGIN;
+
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS partial_places;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS backfill_of;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS place_urls;
+
+DROP INDEX IF EXISTS idx_results_backfilled_by;
+ALTER TABLE results DROP COLUMN IF EXISTS backfilled_by;
+DROP INDEX IF EXISTS idx_results_partial;
+
+DROP TRIGGER IF EXISTS trg_populate_listing_data_completeness ON business_listings;
+DROP FUNCTION IF EXISTS populate_listing_data_completeness();
+
+DROP INDEX IF EXISTS idx_business_listings_partial;
+ALTER TABLE business_listings DROP CONSTRAINT IF EXISTS valid_data_completeness;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS data_completeness;
+
+COMMIT;
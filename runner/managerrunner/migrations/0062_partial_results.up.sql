@@ -0,0 +1,47 @@
+-- Migration 0062: Partial Results
+-- A place whose page failed is kept with what the search results listed
+-- of it, its result data carrying "data_completeness": "partial". Its
+-- listing is marked partial until a backfill job scrapes the place again
+-- and upgrades the result in place.
+
+BEGIN;
+
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS data_completeness TEXT NOT NULL DEFAULT 'full';
+
+ALTER TABLE business_listings DROP CONSTRAINT IF EXISTS valid_data_completeness;
+ALTER TABLE business_listings ADD CONSTRAINT valid_data_completeness
+    CHECK (data_completeness IN ('full', 'partial'));
+
+CREATE INDEX IF NOT EXISTS idx_business_listings_partial ON business_listings(job_id) WHERE data_completeness = 'partial';
+
+-- populate_normalized_listings() inserts listings without the column
+CREATE OR REPLACE FUNCTION populate_listing_data_completeness()
+RETURNS TRIGGER AS $$
+BEGIN
+    SELECT CASE WHEN r.data ->> 'data_completeness' = 'partial' THEN 'partial' ELSE 'full' END
+    INTO NEW.data_completeness
+    FROM results r
+    WHERE r.id = NEW.result_id;
+
+    NEW.data_completeness := COALESCE(NEW.data_completeness, 'full');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_populate_listing_data_completeness ON business_listings;
+CREATE TRIGGER trg_populate_listing_data_completeness
+    BEFORE INSERT ON business_listings
+    FOR EACH ROW
+    EXECUTE FUNCTION populate_listing_data_completeness();
+
+CREATE INDEX IF NOT EXISTS idx_results_partial ON results(job_id) WHERE data ->> 'data_completeness' = 'partial';
+
+-- The backfill job whose scrape upgraded a partial result
+ALTER TABLE results ADD COLUMN IF NOT EXISTS backfilled_by UUID;
+CREATE INDEX IF NOT EXISTS idx_results_backfilled_by ON results(backfilled_by) WHERE backfilled_by IS NOT NULL;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS place_urls TEXT[];
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS backfill_of UUID REFERENCES jobs_queue(id) ON DELETE SET NULL;
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS partial_places INTEGER NOT NULL DEFAULT 0;
+
+COMMIT;
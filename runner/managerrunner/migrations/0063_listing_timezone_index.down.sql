@@ -0,0 +1,7 @@
+-- Migration 0063: Listing Timezone Index (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_business_listings_timezone;
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Migration 0063: Listing Timezone Index
+-- Results carry an IANA timezone for every place with coordinates, looked
+-- up from them when the page has none, so listings are filtered by it,
+-- e.g. ?timezone=America/Chicago.
+
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_business_listings_timezone ON business_listings(timezone);
+
+COMMIT;
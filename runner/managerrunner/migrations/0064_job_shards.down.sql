@@ -0,0 +1,9 @@
+-- Migration 0064: Job Shards (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_jobs_queue_parent_job_id;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS shard_count;
+ALTER TABLE jobs_queue DROP COLUMN IF EXISTS parent_job_id;
+
+COMMIT;
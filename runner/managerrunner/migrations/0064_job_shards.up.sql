@@ -0,0 +1,13 @@
+-- Migration 0064: Job Shards
+-- A job can be split into shards, jobs searching a part of its keywords
+-- each. The job they were split from keeps running, without a worker,
+-- until they all finished, and its results are theirs.
+
+BEGIN;
+
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS parent_job_id UUID REFERENCES jobs_queue(id) ON DELETE CASCADE;
+ALTER TABLE jobs_queue ADD COLUMN IF NOT EXISTS shard_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_parent_job_id ON jobs_queue(parent_job_id) WHERE parent_job_id IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,14 @@
+-- Migration 0065: Review Histogram (DOWN)
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_business_listings_bayesian_rating;
+
+DROP TRIGGER IF EXISTS trg_populate_listing_review_histogram ON business_listings;
+DROP FUNCTION IF EXISTS populate_listing_review_histogram();
+DROP FUNCTION IF EXISTS bayesian_rating(NUMERIC, INTEGER);
+
+ALTER TABLE business_listings DROP COLUMN IF EXISTS bayesian_rating;
+ALTER TABLE business_listings DROP COLUMN IF EXISTS reviews_per_rating;
+
+COMMIT;
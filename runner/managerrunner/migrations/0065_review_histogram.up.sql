@@ -0,0 +1,63 @@
+-- Migration 0065: Review Histogram
+-- Listings keep the review count by star the place page showed, and a
+-- bayesian rating: the rating weighed with a prior, so that a 5.0 from
+-- two reviews ranks below a 4.8 from five hundred. The prior is the
+-- rating_prior setting, {"mean": 4, "weight": 10} while none was saved;
+-- a listing keeps the one it was ingested with until renormalized.
+
+BEGIN;
+
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS reviews_per_rating JSONB;
+ALTER TABLE business_listings ADD COLUMN IF NOT EXISTS bayesian_rating NUMERIC(4,3);
+
+CREATE OR REPLACE FUNCTION bayesian_rating(p_rating NUMERIC, p_count INTEGER)
+RETURNS NUMERIC AS $$
+DECLARE
+    v_mean NUMERIC := 4;
+    v_weight INTEGER := 10;
+BEGIN
+    IF p_rating IS NULL OR COALESCE(p_count, 0) <= 0 THEN
+        RETURN NULL;
+    END IF;
+
+    SELECT COALESCE((s.value ->> 'mean')::NUMERIC, v_mean), COALESCE((s.value ->> 'weight')::INTEGER, v_weight)
+    INTO v_mean, v_weight
+    FROM settings s
+    WHERE s.key = 'rating_prior';
+
+    v_mean := COALESCE(v_mean, 4);
+    v_weight := COALESCE(v_weight, 10);
+
+    RETURN ROUND((v_weight * v_mean + p_rating * p_count) / (v_weight + p_count), 3);
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+UPDATE business_listings bl
+SET reviews_per_rating = CASE WHEN jsonb_typeof(r.data -> 'reviews_per_rating') = 'object' THEN r.data -> 'reviews_per_rating' END,
+    bayesian_rating = bayesian_rating(bl.review_rating, bl.review_count)
+FROM results r
+WHERE r.id = bl.result_id;
+
+-- populate_normalized_listings() upserts listings without the columns
+CREATE OR REPLACE FUNCTION populate_listing_review_histogram()
+RETURNS TRIGGER AS $$
+BEGIN
+    SELECT CASE WHEN jsonb_typeof(r.data -> 'reviews_per_rating') = 'object' THEN r.data -> 'reviews_per_rating' END
+    INTO NEW.reviews_per_rating
+    FROM results r
+    WHERE r.id = NEW.result_id;
+
+    NEW.bayesian_rating := bayesian_rating(NEW.review_rating, NEW.review_count);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_populate_listing_review_histogram ON business_listings;
+CREATE TRIGGER trg_populate_listing_review_histogram
+    BEFORE INSERT OR UPDATE OF review_count, review_rating ON business_listings
+    FOR EACH ROW
+    EXECUTE FUNCTION populate_listing_review_histogram();
+
+CREATE INDEX IF NOT EXISTS idx_business_listings_bayesian_rating ON business_listings(bayesian_rating DESC NULLS LAST);
+
+COMMIT;
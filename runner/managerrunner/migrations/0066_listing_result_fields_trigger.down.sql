@@ -0,0 +1,11 @@
+-- Migration 0066: Listing Result Fields Trigger (DOWN)
+
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_populate_listing_result_fields ON business_listings;
+CREATE TRIGGER trg_populate_listing_result_fields
+    BEFORE INSERT OR UPDATE ON business_listings
+    FOR EACH ROW
+    EXECUTE FUNCTION populate_listing_result_fields();
+
+COMMIT;
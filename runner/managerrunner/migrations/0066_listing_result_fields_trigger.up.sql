@@ -0,0 +1,14 @@
+-- Migration 0066: Listing Result Fields Trigger
+-- populate_listing_result_fields() copies fields from the source result
+-- when a listing is inserted or pointed at another result, rather than on
+-- every update, which overwrote the fields other writers set
+
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_populate_listing_result_fields ON business_listings;
+CREATE TRIGGER trg_populate_listing_result_fields
+    BEFORE INSERT OR UPDATE OF result_id ON business_listings
+    FOR EACH ROW
+    EXECUTE FUNCTION populate_listing_result_fields();
+
+COMMIT;
@@ -0,0 +1,71 @@
+package managerrunner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflight(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer taken.Close()
+
+	static := t.TempDir()
+
+	cfg := &Config{
+		DatabaseURL:  filepath.Join(t.TempDir(), "gmaps.db"),
+		Address:      taken.Addr().String(),
+		StaticFolder: static,
+		SpawnerType:  "none",
+	}
+
+	results := Preflight(context.Background(), cfg, nil)
+
+	status := make(map[string]string)
+	for _, r := range results {
+		status[r.Name] = r.Status()
+	}
+	assert.Equal(t, map[string]string{"database": "PASS", "static": "FAIL", "http": "FAIL"}, status)
+
+	err = preflightError(results)
+	require.ErrorIs(t, err, ErrPreflightFailed)
+	assert.EqualError(t, err, "preflight failed: static, http")
+
+	var out bytes.Buffer
+	PrintPreflight(&out, results)
+	assert.Contains(t, out.String(), "no index.html in "+static)
+	assert.Contains(t, out.String(), "-> another process listens on "+cfg.Address)
+
+	require.NoError(t, os.WriteFile(filepath.Join(static, "index.html"), []byte("<html></html>"), 0o644))
+	cfg.Address = "127.0.0.1:0"
+	assert.NoError(t, preflightError(Preflight(context.Background(), cfg, nil)))
+}
+
+func TestPreflightSkipsDependentChecks(t *testing.T) {
+	checks := []preflightCheck{
+		{name: "database", critical: true, run: func(context.Context) (string, error) { return "", errors.New("refused") }},
+		{name: "redis", hint: "check -redis-addr", run: func(context.Context) (string, error) { return "", errors.New("timeout") }},
+	}
+
+	db := runPreflightCheck(context.Background(), checks[0])
+	assert.Equal(t, "FAIL", db.Status())
+
+	redis := runPreflightCheck(context.Background(), checks[1])
+	assert.Equal(t, "WARN", redis.Status(), "redis has a fallback")
+	assert.Equal(t, "check -redis-addr", redis.Hint)
+
+	cfg := &Config{DatabaseURL: "postgres://127.0.0.1:1/gmaps?connect_timeout=1"}
+	results := Preflight(context.Background(), cfg, nil)
+	require.Len(t, results, 2)
+	assert.Equal(t, "FAIL", results[0].Status())
+	assert.Equal(t, "migrations", results[1].Name)
+	assert.Equal(t, "SKIP", results[1].Status())
+}
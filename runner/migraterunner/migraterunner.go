@@ -0,0 +1,579 @@
+// Package migraterunner implements the -migrate-to-postgres mode, which
+// copies the jobs, workers and results of the manager's SQLite database
+// into PostgreSQL through the repositories of both, so their conversions
+// of IDs, timestamps and JSON apply. Progress is kept in a file next to
+// the SQLite database; an interrupted run resumes where it stopped when
+// started again.
+package migraterunner
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/repository/postgres"
+	"github.com/sadewadee/google-scraper/internal/repository/sqlite"
+	"github.com/sadewadee/google-scraper/runner"
+	"github.com/sadewadee/google-scraper/runner/managerrunner"
+)
+
+// batchSize is how many rows are read and written at a time. Result batch
+// IDs derive from the batches, so it must not change between a run and
+// its resumption.
+const batchSize = 500
+
+// batchNamespace derives the IDs of copied result batches, which makes
+// copying a batch again after a crash a no-op
+var batchNamespace = uuid.MustParse("0c4b3f9e-5d1a-4f43-9b1e-6a0f2d7c8e31")
+
+// Tables in the order they are copied: workers and results refer to jobs
+const (
+	tableJobs    = "jobs_queue"
+	tableWorkers = "workers"
+	tableResults = "results"
+)
+
+type migrator struct {
+	source      *sqlite.DB
+	sourceRepos *sqlite.Repositories
+	target      *sql.DB
+	targetRepos *postgres.Repositories
+	progress    *progress
+	forceMerge  bool
+}
+
+// New checks the flags, opens both databases and brings their schemas up
+// to date
+func New(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.RunMode != runner.RunModeMigrateToPostgres {
+		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
+	}
+
+	if !strings.HasPrefix(cfg.Dsn, "postgres://") && !strings.HasPrefix(cfg.Dsn, "postgresql://") {
+		return nil, &runner.ExitError{Code: runner.ExitUsage, Err: errors.New("-migrate-to-postgres needs a PostgreSQL -dsn")}
+	}
+
+	if _, err := os.Stat(cfg.SQLitePath); err != nil {
+		return nil, &runner.ExitError{Code: runner.ExitNotFound, Err: fmt.Errorf("SQLite database: %w", err)}
+	}
+
+	progress, err := loadProgress(cfg.SQLitePath + ".migrate.json")
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := sqlite.OpenConnection(cfg.SQLitePath)
+	if err != nil {
+		return nil, err
+	}
+	// Older databases lack columns the repositories read
+	if err := sqlite.RunMigrations(source.DB); err != nil {
+		source.Close()
+		return nil, fmt.Errorf("migrate SQLite schema: %w", err)
+	}
+
+	target, err := postgres.OpenConnection(cfg.Dsn)
+	if err != nil {
+		source.Close()
+		return nil, &runner.ExitError{Code: runner.ExitTransport, Err: err}
+	}
+	if err := managerrunner.MigratePostgres(target); err != nil {
+		source.Close()
+		target.Close()
+		return nil, fmt.Errorf("migrate PostgreSQL schema: %w", err)
+	}
+
+	return &migrator{
+		source:      source,
+		sourceRepos: sqlite.NewRepositories(source),
+		target:      target,
+		targetRepos: postgres.NewRepositories(target),
+		progress:    progress,
+		forceMerge:  cfg.ForceMerge,
+	}, nil
+}
+
+func (m *migrator) Run(ctx context.Context) error {
+	if !m.progress.Prepared {
+		if err := m.prepare(ctx); err != nil {
+			return err
+		}
+	} else {
+		log.Printf("migrate: resuming from %s", m.progress.path)
+	}
+
+	if err := m.copyJobs(ctx); err != nil {
+		return fmt.Errorf("copy jobs: %w", err)
+	}
+	if err := m.copyWorkers(ctx); err != nil {
+		return fmt.Errorf("copy workers: %w", err)
+	}
+	if err := m.copyResults(ctx); err != nil {
+		return fmt.Errorf("copy results: %w", err)
+	}
+
+	var listings bool
+	err := m.source.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'business_listings')`).Scan(&listings)
+	if err != nil {
+		return err
+	}
+	if listings {
+		log.Printf("migrate: business_listings not copied, the manager's normalizer builds them from the results")
+	}
+
+	summary, err := m.verify(ctx)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	summary.print(os.Stdout)
+
+	if !summary.ok() {
+		return errors.New("row counts differ between SQLite and PostgreSQL, see the summary")
+	}
+
+	log.Printf("migrate: done; start the manager with -dsn to normalize the copied results into business listings")
+	return nil
+}
+
+func (m *migrator) Close(context.Context) error {
+	return errors.Join(m.source.Close(), m.target.Close())
+}
+
+// prepare refuses a target that already holds jobs or results unless
+// -force-merge is given, and records which source jobs and workers the
+// target has already, to be kept as they are
+func (m *migrator) prepare(ctx context.Context) error {
+	var used bool
+	err := m.target.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM jobs_queue) OR EXISTS (SELECT 1 FROM results)`).Scan(&used)
+	if err != nil {
+		return fmt.Errorf("check target: %w", err)
+	}
+	if used && !m.forceMerge {
+		return &runner.ExitError{Code: runner.ExitUsage, Err: errors.New("the PostgreSQL database already holds jobs or results, pass -force-merge to copy into it anyway")}
+	}
+
+	if used {
+		jobIDs, err := m.sourceJobIDs(ctx)
+		if err != nil {
+			return err
+		}
+		ids := make([]string, 0, len(jobIDs))
+		for id := range jobIDs {
+			ids = append(ids, id.String())
+		}
+		m.progress.SkippedJobs, err = existingKeys(ctx, m.target, tableJobs, ids, uuid.Parse)
+		if err != nil {
+			return err
+		}
+
+		workerIDs, err := m.sourceKeys(ctx, tableWorkers)
+		if err != nil {
+			return err
+		}
+		m.progress.SkippedWorkers, err = existingKeys(ctx, m.target, tableWorkers, workerIDs, func(s string) (string, error) { return s, nil })
+		if err != nil {
+			return err
+		}
+
+		log.Printf("migrate: merging, keeping %d jobs and %d workers PostgreSQL has already",
+			len(m.progress.SkippedJobs), len(m.progress.SkippedWorkers))
+	}
+
+	m.progress.Prepared = true
+	return m.progress.save()
+}
+
+// copyJobs copies the jobs after the last one copied. A job a crashed run
+// created already is written again.
+func (m *migrator) copyJobs(ctx context.Context) error {
+	return m.eachRow(ctx, tableJobs, func(key string) error {
+		id, err := uuid.Parse(key)
+		if err != nil {
+			return fmt.Errorf("job %q: invalid ID", key)
+		}
+		if m.progress.jobSkipped(id) {
+			return nil
+		}
+
+		job, err := m.sourceRepos.Jobs.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("read job %s: %w", id, err)
+		}
+		if job == nil {
+			return nil
+		}
+		report, err := m.sourceRepos.Jobs.GetParseReport(ctx, id)
+		if err != nil {
+			return fmt.Errorf("read parse report of job %s: %w", id, err)
+		}
+
+		existing, err := m.targetRepos.Jobs.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			if err := m.targetRepos.Jobs.Create(ctx, job); err != nil {
+				return fmt.Errorf("create job %s: %w", id, err)
+			}
+		}
+		// Create leaves out what a job gets while it runs
+		if err := m.targetRepos.Jobs.Update(ctx, job); err != nil {
+			return fmt.Errorf("update job %s: %w", id, err)
+		}
+		if report != nil {
+			if err := m.targetRepos.Jobs.SetParseReport(ctx, id, report); err != nil {
+				return fmt.Errorf("set parse report of job %s: %w", id, err)
+			}
+		}
+		if job.DeletedAt != nil {
+			if _, err := m.target.ExecContext(ctx, `UPDATE jobs_queue SET deleted_at = $2 WHERE id = $1`, id, job.DeletedAt); err != nil {
+				return fmt.Errorf("delete job %s: %w", id, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// copyWorkers copies the workers with their stats and last heartbeat, so
+// the manager sees them offline rather than freshly registered
+func (m *migrator) copyWorkers(ctx context.Context) error {
+	return m.eachRow(ctx, tableWorkers, func(id string) error {
+		if m.progress.workerSkipped(id) {
+			return nil
+		}
+
+		worker, err := m.sourceRepos.Workers.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("read worker %s: %w", id, err)
+		}
+		if worker == nil {
+			return nil
+		}
+
+		if err := m.targetRepos.Workers.Upsert(ctx, worker); err != nil {
+			return fmt.Errorf("create worker %s: %w", id, err)
+		}
+		// Upsert takes the heartbeat as now and leaves the stats alone
+		_, err = m.target.ExecContext(ctx, `
+			UPDATE workers SET
+				jobs_completed = $2, places_scraped = $3,
+				last_heartbeat = $4, created_at = $5,
+				draining_since = $6, drain_deadline = $7
+			WHERE id = $1
+		`, id, worker.JobsCompleted, worker.PlacesScraped,
+			nonZero(worker.LastHeartbeat), nonZero(worker.CreatedAt),
+			worker.DrainingSince, worker.DrainDeadline)
+		if err != nil {
+			return fmt.Errorf("update worker %s: %w", id, err)
+		}
+
+		return nil
+	})
+}
+
+// copyResults copies the results of the jobs copied, batch by batch and
+// grouped by job, keeping when they were stored. Results of other jobs,
+// or without one, are skipped.
+func (m *migrator) copyResults(ctx context.Context) error {
+	jobIDs, err := m.sourceJobIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	var total int
+	if err := m.source.QueryRowContext(ctx, `SELECT COUNT(*) FROM results`).Scan(&total); err != nil {
+		return err
+	}
+
+	for {
+		after := m.progress.LastRowID[tableResults]
+		results, err := m.sourceRepos.Results.ListAfter(ctx, after, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			return nil
+		}
+
+		var order []uuid.UUID
+		groups := make(map[uuid.UUID]*resultGroup)
+		for _, res := range results {
+			if res.JobID == nil || !jobIDs[*res.JobID] || m.progress.jobSkipped(*res.JobID) {
+				continue
+			}
+			g, ok := groups[*res.JobID]
+			if !ok {
+				g = &resultGroup{firstID: res.ID}
+				groups[*res.JobID] = g
+				order = append(order, *res.JobID)
+			}
+			createdAt := res.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = time.Now()
+			}
+			g.data = append(g.data, res.Data)
+			g.createdAt = append(g.createdAt, createdAt)
+		}
+
+		for _, jobID := range order {
+			g := groups[jobID]
+			batchID := uuid.NewSHA1(batchNamespace, fmt.Appendf(nil, "%s:%d", jobID, g.firstID))
+			err := m.targetRepos.Results.ImportBatch(ctx, jobID, batchID, g.data, g.createdAt)
+			if err != nil && !errors.Is(err, domain.ErrBatchAlreadyStored) {
+				return fmt.Errorf("job %s: %w", jobID, err)
+			}
+		}
+
+		m.progress.LastRowID[tableResults] = results[len(results)-1].ID
+		if err := m.progress.save(); err != nil {
+			return err
+		}
+		log.Printf("migrate: results up to #%d of %d rows", results[len(results)-1].ID, total)
+	}
+}
+
+type resultGroup struct {
+	firstID   int64
+	data      [][]byte
+	createdAt []time.Time
+}
+
+// eachRow calls fn with the key of each row of table after the last one
+// copied, saving the progress after every batch
+func (m *migrator) eachRow(ctx context.Context, table string, fn func(key string) error) error {
+	var total int
+	if err := m.source.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table).Scan(&total); err != nil {
+		return err
+	}
+
+	done := 0
+	for {
+		rows, err := m.source.QueryContext(ctx, `SELECT rowid, id FROM `+table+` WHERE rowid > ? ORDER BY rowid LIMIT ?`,
+			m.progress.LastRowID[table], batchSize)
+		if err != nil {
+			return err
+		}
+
+		var (
+			rowIDs []int64
+			keys   []string
+		)
+		for rows.Next() {
+			var rowID int64
+			var key string
+			if err := rows.Scan(&rowID, &key); err != nil {
+				rows.Close()
+				return err
+			}
+			rowIDs = append(rowIDs, rowID)
+			keys = append(keys, key)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(keys) == 0 {
+			return nil
+		}
+
+		for _, key := range keys {
+			if err := fn(key); err != nil {
+				return err
+			}
+		}
+
+		m.progress.LastRowID[table] = rowIDs[len(rowIDs)-1]
+		if err := m.progress.save(); err != nil {
+			return err
+		}
+
+		done += len(keys)
+		log.Printf("migrate: %s %d/%d", table, done, total)
+	}
+}
+
+// sourceKeys returns the IDs of all rows of a SQLite table
+func (m *migrator) sourceKeys(ctx context.Context, table string) ([]string, error) {
+	rows, err := m.source.QueryContext(ctx, `SELECT id FROM `+table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// sourceJobIDs returns the IDs of all SQLite jobs
+func (m *migrator) sourceJobIDs(ctx context.Context) (map[uuid.UUID]bool, error) {
+	keys, err := m.sourceKeys(ctx, tableJobs)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[uuid.UUID]bool, len(keys))
+	for _, key := range keys {
+		id, err := uuid.Parse(key)
+		if err != nil {
+			return nil, fmt.Errorf("job %q: invalid ID", key)
+		}
+		ids[id] = true
+	}
+
+	return ids, nil
+}
+
+// existingKeys returns those of keys that are IDs of rows of a PostgreSQL
+// table
+func existingKeys[K any](ctx context.Context, db *sql.DB, table string, keys []string, parse func(string) (K, error)) ([]K, error) {
+	rows, err := db.QueryContext(ctx, `SELECT id::text FROM `+table+` WHERE id::text = ANY($1)`, pq.Array(keys))
+	if err != nil {
+		return nil, fmt.Errorf("look up existing %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var existing []K
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		k, err := parse(key)
+		if err != nil {
+			return nil, err
+		}
+		existing = append(existing, k)
+	}
+
+	return existing, rows.Err()
+}
+
+// nonZero turns a timestamp SQLite could not give into NULL
+func nonZero(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// tableSummary compares a table's rows in both databases
+type tableSummary struct {
+	table    string
+	source   int // Rows in SQLite
+	expected int // Of those, rows PostgreSQL must have
+	target   int // Of those, rows PostgreSQL has
+	note     string
+}
+
+type summary []tableSummary
+
+func (s summary) ok() bool {
+	for _, t := range s {
+		if t.target != t.expected {
+			return false
+		}
+	}
+	return true
+}
+
+func (s summary) print(w *os.File) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tSQLITE\tEXPECTED\tPOSTGRES\tSTATUS\tNOTE")
+	for _, t := range s {
+		status := "ok"
+		if t.target != t.expected {
+			status = "MISMATCH"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\n", t.table, t.source, t.expected, t.target, status, t.note)
+	}
+	tw.Flush()
+}
+
+// verify counts the rows of each table in both databases. Jobs and
+// workers are all expected in PostgreSQL; results only those of the jobs
+// copied, compared job by job.
+func (m *migrator) verify(ctx context.Context) (summary, error) {
+	var s summary
+
+	for _, table := range []string{tableJobs, tableWorkers} {
+		keys, err := m.sourceKeys(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		existing, err := existingKeys(ctx, m.target, table, keys, func(s string) (string, error) { return s, nil })
+		if err != nil {
+			return nil, err
+		}
+
+		kept := len(m.progress.SkippedJobs)
+		if table == tableWorkers {
+			kept = len(m.progress.SkippedWorkers)
+		}
+		t := tableSummary{table: table, source: len(keys), expected: len(keys), target: len(existing)}
+		if kept > 0 {
+			t.note = fmt.Sprintf("%d kept as PostgreSQL had them", kept)
+		}
+		s = append(s, t)
+	}
+
+	jobIDs, err := m.sourceJobIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := tableSummary{table: tableResults}
+	if err := m.source.QueryRowContext(ctx, `SELECT COUNT(*) FROM results`).Scan(&results.source); err != nil {
+		return nil, err
+	}
+
+	mismatched := 0
+	for id := range jobIDs {
+		if m.progress.jobSkipped(id) {
+			continue
+		}
+		want, err := m.sourceRepos.Results.CountByJobID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		got, err := m.targetRepos.Results.CountByJobID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if got != want {
+			log.Printf("migrate: job %s has %d results in SQLite but %d in PostgreSQL", id, want, got)
+			mismatched++
+		}
+		results.expected += want
+		results.target += got
+	}
+
+	if skipped := results.source - results.expected; skipped > 0 {
+		results.note = fmt.Sprintf("%d of kept jobs or without a job skipped", skipped)
+	}
+	if mismatched > 0 {
+		results.note = strings.TrimPrefix(results.note+fmt.Sprintf("; %d jobs differ", mismatched), "; ")
+	}
+	s = append(s, results)
+
+	return s, nil
+}
@@ -0,0 +1,77 @@
+package migraterunner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// progress is what a migration copied so far, kept in a file next to the
+// SQLite database so an interrupted run resumes instead of starting over
+type progress struct {
+	path string
+
+	// Prepared is set once the jobs and workers the target already had
+	// were recorded as skipped
+	Prepared bool `json:"prepared"`
+	// LastRowID is the rowid of the last row copied, per table
+	LastRowID map[string]int64 `json:"last_rowid"`
+	// SkippedJobs and SkippedWorkers were in the target before the first
+	// run, which keeps them; with them go the results of the jobs
+	SkippedJobs    []uuid.UUID `json:"skipped_jobs,omitempty"`
+	SkippedWorkers []string    `json:"skipped_workers,omitempty"`
+}
+
+// loadProgress reads the progress file at path, or starts a new migration
+// when there is none
+func loadProgress(path string) (*progress, error) {
+	p := &progress{path: path, LastRowID: make(map[string]int64)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read progress: %w", err)
+	}
+
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("parse progress %s: %w", path, err)
+	}
+	if p.LastRowID == nil {
+		p.LastRowID = make(map[string]int64)
+	}
+
+	return p, nil
+}
+
+// save writes the progress file, replacing the previous one at once so a
+// crash leaves either
+func (p *progress) save() error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write progress: %w", err)
+	}
+	if err := os.Rename(tmp, p.path); err != nil {
+		return fmt.Errorf("write progress: %w", err)
+	}
+
+	return nil
+}
+
+func (p *progress) jobSkipped(id uuid.UUID) bool {
+	return slices.Contains(p.SkippedJobs, id)
+}
+
+func (p *progress) workerSkipped(id string) bool {
+	return slices.Contains(p.SkippedWorkers, id)
+}
@@ -0,0 +1,86 @@
+// Package renormalizerunner implements the -renormalize mode, which parses
+// the stored results of one or every job again with the current schema and
+// rewrites their business listings. An interrupted run resumes where it
+// stopped when started again.
+package renormalizerunner
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/domain"
+	"github.com/sadewadee/google-scraper/internal/repository/postgres"
+	"github.com/sadewadee/google-scraper/internal/service"
+	"github.com/sadewadee/google-scraper/runner"
+)
+
+type renormalizer struct {
+	db    *sql.DB
+	svc   *service.RenormalizeService
+	jobID *uuid.UUID
+}
+
+// New checks the flags and connects to the database
+func New(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.RunMode != runner.RunModeRenormalize {
+		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
+	}
+
+	if cfg.Dsn == "" {
+		return nil, &runner.ExitError{Code: runner.ExitUsage, Err: errors.New("-renormalize needs -dsn")}
+	}
+
+	ans := renormalizer{}
+
+	if cfg.ExportJobID != "" {
+		id, err := uuid.Parse(cfg.ExportJobID)
+		if err != nil {
+			return nil, &runner.ExitError{Code: runner.ExitUsage, Err: fmt.Errorf("invalid job ID %q", cfg.ExportJobID)}
+		}
+		ans.jobID = &id
+	}
+
+	keys, err := cfg.Keyring()
+	if err != nil {
+		return nil, &runner.ExitError{Code: runner.ExitUsage, Err: err}
+	}
+
+	db, err := postgres.OpenConnection(cfg.Dsn)
+	if err != nil {
+		return nil, &runner.ExitError{Code: runner.ExitTransport, Err: err}
+	}
+
+	jobs := postgres.NewJobRepository(db)
+	jobs.SetKeyring(keys)
+
+	ans.db = db
+	ans.svc = service.NewRenormalizeService(postgres.NewRenormalizeRepository(db), jobs)
+
+	return &ans, nil
+}
+
+func (r *renormalizer) Run(ctx context.Context) error {
+	run, err := r.svc.Run(ctx, r.jobID, func(run *domain.RenormalizeRun) {
+		log.Printf("renormalize: %d/%d results, %d failed", run.Processed+run.Failed, run.Total, run.Failed)
+	})
+	if errors.Is(err, service.ErrJobNotFound) {
+		return &runner.ExitError{Code: runner.ExitNotFound, Err: fmt.Errorf("job %s not found", r.jobID)}
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("renormalize: run %d completed, %d results rewritten, %d failed (see renormalize_failures)",
+		run.ID, run.Processed, run.Failed)
+
+	return nil
+}
+
+func (r *renormalizer) Close(context.Context) error {
+	return r.db.Close()
+}
@@ -0,0 +1,113 @@
+// Package rotatesecretsrunner implements the -rotate-secrets mode, which
+// re-encrypts the stored proxy credentials with -secrets-key. Values
+// encrypted with one of -secrets-old-keys and plaintext ones are sealed
+// anew, batch by batch; an interrupted run is simply started again.
+package rotatesecretsrunner
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/sadewadee/google-scraper/internal/repository/postgres"
+	"github.com/sadewadee/google-scraper/runner"
+)
+
+// batchSize is how many rows are re-encrypted per transaction
+const batchSize = 500
+
+type rotator struct {
+	db   *sql.DB
+	repo *postgres.SecretsRepository
+}
+
+// New checks the flags and connects to the database
+func New(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.RunMode != runner.RunModeRotateSecrets {
+		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
+	}
+
+	if cfg.Dsn == "" {
+		return nil, &runner.ExitError{Code: runner.ExitUsage, Err: errors.New("-rotate-secrets needs -dsn")}
+	}
+	if cfg.SecretsKey == "" {
+		return nil, &runner.ExitError{Code: runner.ExitUsage, Err: errors.New("-rotate-secrets needs -secrets-key")}
+	}
+
+	keys, err := cfg.Keyring()
+	if err != nil {
+		return nil, &runner.ExitError{Code: runner.ExitUsage, Err: err}
+	}
+
+	db, err := postgres.OpenConnection(cfg.Dsn)
+	if err != nil {
+		return nil, &runner.ExitError{Code: runner.ExitTransport, Err: err}
+	}
+
+	return &rotator{db: db, repo: postgres.NewSecretsRepository(db, keys)}, nil
+}
+
+func (r *rotator) Run(ctx context.Context) error {
+	jobs, err := r.rotateJobs(ctx)
+	if err != nil {
+		return fmt.Errorf("rotate job proxies: %w", err)
+	}
+	log.Printf("rotate-secrets: %d jobs re-encrypted", jobs)
+
+	proxies, err := rotateByID(ctx, r.repo.ResealProxyPasswords)
+	if err != nil {
+		return fmt.Errorf("rotate proxy passwords: %w", err)
+	}
+	log.Printf("rotate-secrets: %d proxies re-encrypted", proxies)
+
+	sources, err := rotateByID(ctx, r.repo.ResealProxySources)
+	if err != nil {
+		return fmt.Errorf("rotate proxy sources: %w", err)
+	}
+	log.Printf("rotate-secrets: %d proxy sources re-encrypted", sources)
+
+	return nil
+}
+
+func (r *rotator) rotateJobs(ctx context.Context) (int, error) {
+	var after uuid.UUID
+	total := 0
+
+	for {
+		last, changed, err := r.repo.ResealJobProxies(ctx, after, batchSize)
+		if err != nil {
+			return total, err
+		}
+		total += changed
+		if last == nil {
+			return total, nil
+		}
+		after = *last
+	}
+}
+
+// rotateByID runs a reseal over a table with bigint ids, batch by batch
+func rotateByID(ctx context.Context, reseal func(ctx context.Context, after int64, limit int) (int64, int, error)) (int, error) {
+	var after int64
+	total := 0
+
+	for {
+		last, changed, err := reseal(ctx, after, batchSize)
+		if err != nil {
+			return total, err
+		}
+		total += changed
+		if last == 0 {
+			return total, nil
+		}
+		after = last
+	}
+}
+
+func (r *rotator) Close(context.Context) error {
+	return r.db.Close()
+}
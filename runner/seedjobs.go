@@ -1,13 +1,17 @@
 package runner
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/gosom/scrapemate"
 	"github.com/sadewadee/google-scraper/deduper"
 	"github.com/sadewadee/google-scraper/exiter"
+	"github.com/sadewadee/google-scraper/gmaps"
 	"github.com/sadewadee/google-scraper/internal/emailvalidator"
+	"github.com/sadewadee/google-scraper/internal/querycsv"
+	"github.com/sadewadee/google-scraper/ratelimit"
 )
 
 // SeedJobConfig for creating seed jobs from API
@@ -21,9 +25,18 @@ type SeedJobConfig struct {
 	Zoom           int
 	Radius         float64
 	ExtraReviews   bool
+	MaxReviews     int // > 0 enables extra reviews, capped per place
+	ReviewsSort    gmaps.ReviewSort
+	MaxImages      int               // photo URLs kept per place, 0 means gmaps.DefaultMaxImages
+	Headers        map[string]string // User-Agent and Accept-Language, nil for the defaults
 	Dedup          deduper.Deduper
 	ExitMonitor    exiter.Exiter
 	EmailValidator emailvalidator.Validator
+	RateLimiter    ratelimit.Limiter
+	PageCache      gmaps.PageCache // Raw place pages are stored here, nil for none
+	LangFallback   []string        // Languages searches that find nothing are retried in
+	EmailPages     int             // Contact pages searched for emails besides the website
+	GoogleDomain   string          // Google domain searched, e.g. google.de; empty for google.com
 }
 
 // CreateSeedJobsFromKeywords creates seed jobs from a slice of keywords.
@@ -37,7 +50,7 @@ func CreateSeedJobsFromKeywords(cfg SeedJobConfig) ([]scrapemate.IJob, error) {
 	// Convert []string to io.Reader (adapter pattern)
 	input := strings.NewReader(strings.Join(cfg.Keywords, "\n"))
 
-	return CreateSeedJobs(
+	jobs, err := CreateSeedJobs(
 		cfg.FastMode,
 		cfg.LangCode,
 		input,
@@ -50,7 +63,170 @@ func CreateSeedJobsFromKeywords(cfg SeedJobConfig) ([]scrapemate.IJob, error) {
 		cfg.ExitMonitor,
 		cfg.EmailValidator,
 		cfg.ExtraReviews,
+		cfg.MaxReviews,
+		cfg.ReviewsSort,
+		cfg.MaxImages,
+		cfg.Headers,
+		cfg.RateLimiter,
+		cfg.PageCache,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	SetLangFallback(jobs, cfg.LangFallback)
+	SetEmailPages(jobs, cfg.EmailPages)
+	SetGoogleDomain(jobs, cfg.GoogleDomain)
+
+	return jobs, nil
+}
+
+// CreatePlaceJobs creates a place job for each of urls, which scrapes the
+// page of the place without searching for it. The search settings of cfg,
+// its Keywords included, are ignored.
+func CreatePlaceJobs(cfg SeedJobConfig, urls []string) ([]scrapemate.IJob, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("at least one place URL is required")
+	}
+
+	var opts []gmaps.PlaceJobOptions
+	if cfg.ExitMonitor != nil {
+		opts = append(opts, gmaps.WithPlaceJobExitMonitor(cfg.ExitMonitor))
+	}
+	if cfg.EmailValidator != nil {
+		opts = append(opts, gmaps.WithPlaceJobEmailValidator(cfg.EmailValidator))
+	}
+	if cfg.EmailPages > 0 {
+		opts = append(opts, gmaps.WithPlaceJobEmailPages(cfg.EmailPages))
+	}
+	if cfg.RateLimiter != nil {
+		opts = append(opts, gmaps.WithPlaceJobRateLimiter(cfg.RateLimiter))
+	}
+	if cfg.MaxReviews > 0 || cfg.ReviewsSort != "" {
+		opts = append(opts, gmaps.WithPlaceJobReviewLimit(cfg.MaxReviews, cfg.ReviewsSort))
+	}
+	if cfg.MaxImages > 0 {
+		opts = append(opts, gmaps.WithPlaceJobMaxImages(cfg.MaxImages))
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, gmaps.WithPlaceJobHeaders(cfg.Headers))
+	}
+	if cfg.PageCache != nil {
+		opts = append(opts, gmaps.WithPlaceJobPageCache(cfg.PageCache))
+	}
+
+	extraReviews := cfg.ExtraReviews || cfg.MaxReviews > 0
+
+	jobs := make([]scrapemate.IJob, 0, len(urls))
+	for _, u := range urls {
+		jobs = append(jobs, gmaps.NewPlaceJob("", cfg.LangCode, u, cfg.Email, extraReviews, opts...))
+	}
+
+	return jobs, nil
+}
+
+// CreateSeedJobsFromRows creates a seed job for each row of a query CSV,
+// a place job for the rows with a place URL. The settings a row leaves
+// blank are taken from cfg, whose Keywords are ignored.
+func CreateSeedJobsFromRows(cfg SeedJobConfig, rows []querycsv.Row) ([]scrapemate.IJob, error) {
+	jobs := make([]scrapemate.IJob, 0, len(rows))
+
+	for _, row := range rows {
+		rowCfg := cfg
+		rowCfg.Keywords = []string{row.Keyword}
+
+		if row.Lang != "" {
+			rowCfg.LangCode = row.Lang
+		}
+		if row.PlaceURL != "" {
+			placeJobs, err := CreatePlaceJobs(rowCfg, []string{row.PlaceURL})
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", row.Line, err)
+			}
+			jobs = append(jobs, placeJobs...)
+			continue
+		}
+		if row.Lat != nil && row.Lon != nil {
+			rowCfg.GeoCoordinates = FormatGeoCoordinates(*row.Lat, *row.Lon)
+		}
+		if row.Zoom > 0 {
+			rowCfg.Zoom = row.Zoom
+		}
+		if row.Depth > 0 {
+			rowCfg.Depth = row.Depth
+		}
+		if row.Radius > 0 {
+			rowCfg.Radius = float64(row.Radius)
+		}
+
+		rowJobs, err := CreateSeedJobsFromKeywords(rowCfg)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", row.Line, err)
+		}
+
+		jobs = append(jobs, rowJobs...)
+	}
+
+	return jobs, nil
+}
+
+// CountPlaceJobs counts the place jobs among jobs, which are no searches:
+// each is a place found up front
+func CountPlaceJobs(jobs []scrapemate.IJob) int {
+	n := 0
+	for _, job := range jobs {
+		if _, ok := job.(*gmaps.PlaceJob); ok {
+			n++
+		}
+	}
+	return n
+}
+
+// SetLangFallback makes the searches among jobs retry in langs, in order,
+// when they find no places. Fast mode searches do not fall back.
+func SetLangFallback(jobs []scrapemate.IJob, langs []string) {
+	if len(langs) == 0 {
+		return
+	}
+
+	for _, job := range jobs {
+		if j, ok := job.(*gmaps.GmapJob); ok {
+			j.LangFallback = langs
+		}
+	}
+}
+
+// SetEmailPages makes the email jobs of the searches among jobs also search
+// up to pages contact, imprint and about pages of each website
+func SetEmailPages(jobs []scrapemate.IJob, pages int) {
+	for _, job := range jobs {
+		if j, ok := job.(*gmaps.GmapJob); ok {
+			j.EmailPages = pages
+		}
+	}
+}
+
+// SetGoogleDomain moves the searches among jobs, in normal and fast mode,
+// to the Google domain d, e.g. google.de. Empty keeps google.com.
+func SetGoogleDomain(jobs []scrapemate.IJob, d string) {
+	for _, job := range jobs {
+		switch j := job.(type) {
+		case *gmaps.GmapJob:
+			j.SetDomain(d)
+		case *gmaps.SearchJob:
+			j.SetDomain(d)
+		}
+	}
+}
+
+// SetSearchContext makes the fast mode searches among jobs stop backing off
+// from block pages once ctx is done
+func SetSearchContext(jobs []scrapemate.IJob, ctx context.Context) {
+	for _, job := range jobs {
+		if j, ok := job.(*gmaps.SearchJob); ok {
+			j.SetContext(ctx)
+		}
+	}
 }
 
 // FormatGeoCoordinates formats latitude and longitude into a string.
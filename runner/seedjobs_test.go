@@ -1,6 +1,8 @@
 package runner
 
 import (
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -45,6 +47,26 @@ func TestCreateSeedJobsFromKeywords(t *testing.T) {
 	}
 }
 
+func TestSetGoogleDomain(t *testing.T) {
+	for _, fast := range []bool{false, true} {
+		jobs, err := CreateSeedJobsFromKeywords(SeedJobConfig{
+			Keywords:       []string{"bäckerei"},
+			FastMode:       fast,
+			LangCode:       "de",
+			Depth:          1,
+			GeoCoordinates: "52.52,13.40",
+			Zoom:           15,
+			GoogleDomain:   "google.de",
+		})
+		assert.NoError(t, err)
+		assert.Len(t, jobs, 1)
+
+		u, err := url.Parse(jobs[0].GetFullURL())
+		assert.NoError(t, err)
+		assert.True(t, strings.HasSuffix(u.Host, ".google.de"), "fast mode %v: %s", fast, u.Host)
+	}
+}
+
 func TestFormatGeoCoordinates(t *testing.T) {
 	tests := []struct {
 		name     string
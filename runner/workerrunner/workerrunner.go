@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/sadewadee/google-scraper/deduper"
 	"github.com/sadewadee/google-scraper/internal/worker"
 	"github.com/sadewadee/google-scraper/runner"
 )
@@ -31,12 +32,20 @@ type Config struct {
 
 	// RabbitMQ configuration for job queue (preferred over Redis)
 	RabbitMQURL string
+
+	// ManagerGRPCURL is the manager's gRPC address; when set the worker
+	// talks gRPC and falls back to ManagerURL over HTTP
+	ManagerGRPCURL string
+
+	// Deduper remembers the places of each job, see worker.Config
+	Deduper deduper.Config
 }
 
 // WorkerRunner runs a worker that claims and processes jobs
 type WorkerRunner struct {
-	cfg    *Config
-	runner *worker.Runner
+	cfg     *Config
+	runner  *worker.Runner
+	reparse uuid.UUID // Job the cached pages are parsed for, uuid.Nil to work normally
 }
 
 // New creates a new WorkerRunner
@@ -58,6 +67,18 @@ func New(cfg *Config) (runner.Runner, error) {
 		cfg.RunnerConfig.DataFolder = "."
 	}
 
+	var reparse uuid.UUID
+	if id := cfg.RunnerConfig.ReparseJobID; id != "" {
+		if cfg.RunnerConfig.CacheDir == "" {
+			return nil, fmt.Errorf("-reparse-from-cache requires -cache")
+		}
+
+		var err error
+		if reparse, err = uuid.Parse(id); err != nil {
+			return nil, fmt.Errorf("invalid -reparse-from-cache job ID: %w", err)
+		}
+	}
+
 	workerCfg := &worker.Config{
 		ManagerURL:   cfg.ManagerURL,
 		WorkerID:     cfg.WorkerID,
@@ -67,6 +88,9 @@ func New(cfg *Config) (runner.Runner, error) {
 		RedisPass:    cfg.RedisPass,
 		RedisDB:      cfg.RedisDB,
 		RabbitMQURL:  cfg.RabbitMQURL,
+
+		ManagerGRPCURL: cfg.ManagerGRPCURL,
+		Deduper:        cfg.Deduper,
 	}
 
 	r, err := worker.NewRunner(workerCfg)
@@ -75,13 +99,20 @@ func New(cfg *Config) (runner.Runner, error) {
 	}
 
 	return &WorkerRunner{
-		cfg:    cfg,
-		runner: r,
+		cfg:     cfg,
+		runner:  r,
+		reparse: reparse,
 	}, nil
 }
 
-// Run starts the worker
+// Run starts the worker, or parses the cached pages again and returns
 func (w *WorkerRunner) Run(ctx context.Context) error {
+	if w.reparse != uuid.Nil {
+		log.Printf("worker %s parsing cached pages for job %s", w.cfg.WorkerID, w.reparse)
+
+		return w.runner.Reparse(ctx, w.reparse)
+	}
+
 	log.Printf("starting worker %s connecting to %s", w.cfg.WorkerID, w.cfg.ManagerURL)
 
 	return w.runner.Run(ctx)
@@ -89,7 +120,8 @@ func (w *WorkerRunner) Run(ctx context.Context) error {
 
 // Close cleans up resources
 func (w *WorkerRunner) Close(ctx context.Context) error {
-	if w.runner != nil {
+	// A reparse never registered with the manager
+	if w.runner != nil && w.reparse == uuid.Nil {
 		return w.runner.Stop(ctx)
 	}
 	return nil
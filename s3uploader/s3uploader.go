@@ -2,14 +2,31 @@ package s3uploader
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
+// ErrNotFound is returned by Download for a key that does not exist
+var ErrNotFound = errors.New("s3 object not found")
+
+// MinPartSize is the smallest part S3 accepts in a multipart upload, the
+// last part excepted
+const MinPartSize = 5 << 20
+
+// CompletedPart is an uploaded part of a multipart upload, identified by
+// the ETag UploadPart returned
+type CompletedPart struct {
+	PartNumber int32
+	ETag       string
+}
+
 type Uploader struct {
 	client *s3.Client
 }
@@ -46,3 +63,80 @@ func (u *Uploader) Upload(ctx context.Context, bucketName, key string, body io.R
 
 	return nil
 }
+
+// Download returns the body of an object, which the caller must close
+func (u *Uploader) Download(ctx context.Context, bucketName, key string) (io.ReadCloser, error) {
+	out, err := u.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noKey *types.NoSuchKey
+		if errors.As(err, &noKey) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return nil, err
+	}
+
+	return out.Body, nil
+}
+
+// CreateMultipartUpload starts a multipart upload and returns its ID
+func (u *Uploader) CreateMultipartUpload(ctx context.Context, bucketName, key string) (string, error) {
+	out, err := u.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.ToString(out.UploadId), nil
+}
+
+// UploadPart uploads a part of a multipart upload and returns its ETag
+func (u *Uploader) UploadPart(ctx context.Context, bucketName, key, uploadID string, partNumber int32, body io.Reader) (string, error) {
+	out, err := u.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(bucketName),
+		Key:        aws.String(key),
+		UploadId:   aws.String(uploadID),
+		PartNumber: aws.Int32(partNumber),
+		Body:       body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.ToString(out.ETag), nil
+}
+
+// CompleteMultipartUpload joins the parts into the object
+func (u *Uploader) CompleteMultipartUpload(ctx context.Context, bucketName, key, uploadID string, parts []CompletedPart) error {
+	completed := make([]types.CompletedPart, 0, len(parts))
+	for _, p := range parts {
+		completed = append(completed, types.CompletedPart{
+			PartNumber: aws.Int32(p.PartNumber),
+			ETag:       aws.String(p.ETag),
+		})
+	}
+
+	_, err := u.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucketName),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+
+	return err
+}
+
+// AbortMultipartUpload discards a multipart upload and its parts
+func (u *Uploader) AbortMultipartUpload(ctx context.Context, bucketName, key, uploadID string) error {
+	_, err := u.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucketName),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+
+	return err
+}
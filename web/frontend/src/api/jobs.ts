@@ -1,5 +1,5 @@
 import { api } from "./client"
-import type { Job, JobCreatePayload, ResultsResponse } from "./types"
+import type { Job, JobCreatePayload, ListingFieldStats, ResultsResponse } from "./types"
 
 export const jobsApi = {
     getAll: async (): Promise<{ data: Job[] }> => {
@@ -29,6 +29,11 @@ export const jobsApi = {
         await api.post(`/jobs/${id}/resume`)
     },
 
+    backfill: async (id: string): Promise<Job> => {
+        const response = await api.post<Job>(`/jobs/${id}/backfill`)
+        return response.data
+    },
+
     delete: async (id: string): Promise<void> => {
         await api.delete(`/jobs/${id}`)
     },
@@ -40,12 +45,21 @@ export const jobsApi = {
         return response.data
     },
 
-    downloadResults: (id: string, format: 'csv' | 'json' | 'xlsx', columns?: string[]): string => {
+    fieldStats: async (id: string): Promise<ListingFieldStats> => {
+        const response = await api.get<{ data: ListingFieldStats }>(`/jobs/${id}/results/field-stats`)
+        return response.data.data
+    },
+
+    downloadResults: (id: string, format: 'csv' | 'json' | 'xlsx', columns?: string[], partial = false): string => {
         const baseUrl = api.defaults.baseURL || '/api/v2'
         const params = new URLSearchParams({ format })
         if (columns && columns.length > 0) {
             params.set('columns', columns.join(','))
         }
+        // The results of a running job are refused without it
+        if (partial) {
+            params.set('partial', 'true')
+        }
         return `${baseUrl}/jobs/${id}/download?${params.toString()}`
     }
 }
@@ -0,0 +1,14 @@
+import { api } from "./client"
+import type { JobDefaults, JobDefaultsConfig, Settings } from "./types"
+
+export const settingsApi = {
+    get: async (): Promise<Settings> => {
+        const response = await api.get<Settings>("/settings")
+        return response.data
+    },
+
+    updateJobDefaults: async (config: JobDefaultsConfig): Promise<JobDefaults> => {
+        const response = await api.put<JobDefaults>("/settings/job-defaults", config)
+        return response.data
+    }
+}
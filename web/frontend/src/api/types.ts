@@ -1,10 +1,11 @@
 export interface Job {
     id: string
     name: string
-    status: "pending" | "queued" | "running" | "paused" | "completed" | "failed" | "cancelled"
+    status: "waiting" | "pending" | "queued" | "running" | "paused" | "completed" | "failed" | "cancelled"
     priority: number
     config: {
         keywords: string[]
+        place_urls?: string[]
         lang: string
         geo_lat?: number
         geo_lon?: number
@@ -15,6 +16,7 @@ export interface Job {
         extract_email: boolean
         max_time: number
         proxies?: string[]
+        google_domain?: string
         location_name?: string
         boundingbox?: BoundingBox
         coverage_mode?: "single" | "full"
@@ -24,6 +26,7 @@ export interface Job {
         total_places: number
         scraped_places: number
         failed_places: number
+        partial_places?: number
         percentage: number
     }
     worker_id?: string
@@ -32,6 +35,22 @@ export interface Job {
     started_at?: string
     completed_at?: string
     error_message?: string
+    depends_on?: string
+    run_if?: "success" | "always"
+    backfill_of?: string
+    parent_job_id?: string
+    shard_count?: number
+    results_available?: number
+    last_result_at?: string
+    normalized_place_urls?: PlaceURLNormalization
+}
+
+export interface PlaceURLNormalization {
+    submitted: number
+    kept: number
+    empty: number
+    duplicates: number
+    invalid: { index: number; input: string; reason: string }[]
 }
 
 export interface BoundingBox {
@@ -44,6 +63,8 @@ export interface BoundingBox {
 export interface JobCreatePayload {
     name: string
     keywords: string[]
+    input_type?: "keywords" | "place_urls"
+    place_urls?: string[]
     lang: string
     zoom: number
     radius: number
@@ -52,6 +73,7 @@ export interface JobCreatePayload {
     extract_email: boolean
     priority: number
     max_time: number
+    google_domain?: string
     lat?: number
     lon?: number
     location_name?: string
@@ -74,6 +96,7 @@ export interface Worker {
 // Backend Stats structure (matches domain.Stats in Go)
 export interface JobStats {
     total: number
+    waiting: number
     pending: number
     queued: number
     running: number
@@ -127,9 +150,18 @@ export interface ProxySource {
     id: number
     url: string
     active: boolean
-    last_fetch?: string
-    status?: 'ok' | 'error'
-    error_message?: string
+    status?: 'ok' | 'error' | 'disabled'
+    fetched_count?: number
+    parse_errors?: number
+    validated?: number
+    passed?: number
+    pass_rate?: number
+    fetch_latency_ms?: number
+    last_error?: string
+    last_fetch_at?: string
+    low_refreshes?: number
+    disabled_at?: string
+    disabled_reason?: string
     created_at?: string
 }
 
@@ -217,6 +249,33 @@ export interface ResultEntry {
     user_reviews: Review[]
     user_reviews_extended: Review[]
     emails: string[]
+    data_completeness?: "partial"
+}
+
+// Column statistics of the listings of a job (matches domain.ListingFieldStats in Go)
+export interface ColumnStats {
+    column: string
+    filled: number
+    fill_rate: number
+    distinct: number
+    distinct_capped?: boolean
+    min?: number
+    avg?: number
+    max?: number
+}
+
+export interface ListingFieldStats {
+    job_id: string
+    listings: number
+    latest_result_at?: string
+    coverage: {
+        with_email: number
+        with_valid_email: number
+        with_phone: number
+        with_valid_phone: number
+        with_website: number
+    }
+    columns: ColumnStats[]
 }
 
 export interface ResultsResponse {
@@ -227,4 +286,40 @@ export interface ResultsResponse {
         total: number
         total_pages: number
     }
+    is_partial?: boolean
+}
+
+// Partial job config of the job defaults (matches domain.JobTemplateConfig in Go)
+export interface JobDefaultsConfig {
+    lang?: string
+    zoom?: number
+    radius?: number
+    depth?: number
+    fast_mode?: boolean
+    extract_email?: boolean
+    max_time?: number
+    priority?: number
+    google_domain?: string
+    coverage_mode?: "single" | "full"
+}
+
+// House defaults of job creation (matches domain.JobDefaults in Go)
+export interface JobDefaults {
+    config: JobDefaultsConfig
+    updated_by?: string
+    updated_at?: string
+}
+
+// Prior of the bayesian ratings of listings (matches domain.RatingPrior in Go)
+export interface RatingPrior {
+    mean: number
+    weight: number
+    updated_by?: string
+    updated_at?: string
+}
+
+// Server-side settings (matches domain.Settings in Go)
+export interface Settings {
+    job_defaults: JobDefaults
+    rating_prior: RatingPrior
 }
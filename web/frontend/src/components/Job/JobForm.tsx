@@ -4,6 +4,7 @@ import { useForm } from "react-hook-form"
 import { AxiosError } from "axios"
 import { toast } from "sonner"
 import { jobsApi } from "@/api/jobs"
+import { settingsApi } from "@/api/settings"
 import type { JobCreatePayload, Job, BoundingBox } from "@/api/types"
 import {
     TextField,
@@ -43,6 +44,7 @@ interface FormData {
     extract_email: boolean
     priority: number
     max_time: number
+    google_domain: string
     coverage_mode: "single" | "full"
 }
 
@@ -85,10 +87,32 @@ export function JobForm({ cloneFrom, isRetry }: JobFormProps) {
             extract_email: false,
             priority: 5,
             max_time: 600, // 10 minutes
+            google_domain: "",
             coverage_mode: "single",
         },
     })
 
+    // Pre-fill a new job with the house defaults saved in the settings
+    useEffect(() => {
+        if (cloneFrom) return
+        settingsApi.get().then(({ job_defaults }) => {
+            const d = job_defaults?.config
+            if (!d) return
+            if (d.lang) setValue("lang", d.lang)
+            if (d.zoom) setValue("zoom", d.zoom)
+            if (d.radius) setValue("radius", d.radius)
+            if (d.depth) setValue("depth", d.depth)
+            if (d.fast_mode !== undefined) setValue("fast_mode", d.fast_mode)
+            if (d.extract_email !== undefined) setValue("extract_email", d.extract_email)
+            if (d.priority) setValue("priority", d.priority)
+            if (d.max_time) setValue("max_time", d.max_time)
+            if (d.google_domain) setValue("google_domain", d.google_domain)
+            if (d.coverage_mode) setValue("coverage_mode", d.coverage_mode)
+        }).catch(() => {
+            // Without settings (SQLite mode) the built-in defaults stay
+        })
+    }, [cloneFrom, setValue])
+
     // Pre-fill form when cloning a job
     useEffect(() => {
         if (cloneFrom) {
@@ -102,6 +126,7 @@ export function JobForm({ cloneFrom, isRetry }: JobFormProps) {
             setValue("extract_email", cloneFrom.config.extract_email)
             setValue("priority", cloneFrom.priority)
             setValue("max_time", cloneFrom.config.max_time)
+            setValue("google_domain", cloneFrom.config.google_domain || "")
             if (cloneFrom.config.geo_lat) setValue("lat", String(cloneFrom.config.geo_lat))
             if (cloneFrom.config.geo_lon) setValue("lon", String(cloneFrom.config.geo_lon))
             // Restore coverage mode and location data
@@ -217,6 +242,10 @@ export function JobForm({ cloneFrom, isRetry }: JobFormProps) {
                 coverage_mode: data.coverage_mode,
             }
 
+            if (data.google_domain.trim()) {
+                payload.google_domain = data.google_domain.trim()
+            }
+
             // Add lat/lon if provided
             if (data.lat && data.lon) {
                 payload.lat = parseFloat(data.lat)
@@ -314,6 +343,14 @@ export function JobForm({ cloneFrom, isRetry }: JobFormProps) {
                                 </Grid>
                             </Grid>
 
+                    <TextField
+                        label="Google Domain"
+                        placeholder="google.com"
+                        fullWidth
+                        {...register("google_domain")}
+                        helperText="e.g. google.de or google.co.id; auto picks it from the language"
+                    />
+
                     <TextField
                         label="Max Time (seconds)"
                         type="number"
@@ -272,7 +272,10 @@ export function ResultsTable({ jobId }: ResultsTableProps) {
         queryKey: ["results", jobId, page, perPage],
         queryFn: () => jobsApi.getResults(jobId, page, perPage),
         enabled: !!jobId,
+        // A running job keeps adding results
+        refetchInterval: (query) => query.state.data?.is_partial ? 10000 : false,
     })
+    const partial = data?.is_partial ?? false
 
     // Filter results based on search
     const dataToFilter = data?.data
@@ -306,7 +309,7 @@ export function ResultsTable({ jobId }: ResultsTableProps) {
         const columnLabels = AVAILABLE_COLUMNS
             .filter(c => visibleColumns.includes(c.key))
             .map(c => c.label)
-        const url = jobsApi.downloadResults(jobId, format, columnLabels)
+        const url = jobsApi.downloadResults(jobId, format, columnLabels, partial)
         window.open(url, '_blank')
     }
 
@@ -430,7 +433,10 @@ export function ResultsTable({ jobId }: ResultsTableProps) {
                 </div>
 
                 {/* Export Buttons */}
-                <div style={{ display: 'flex', gap: 8 }}>
+                <div style={{ display: 'flex', gap: 8, alignItems: 'center' }}>
+                    {partial && (
+                        <Badge color="warning" variant="outlined" size="small" label="Partial: job still running" />
+                    )}
                     <Button variant="outlined" size="small" onClick={() => handleExport('csv')}>
                         <Download sx={{ mr: 1, fontSize: 16 }} />
                         CSV
@@ -1,6 +1,6 @@
 import { Chip } from '@mui/material';
 
-type StatusType = 'pending' | 'queued' | 'running' | 'paused' | 'completed' | 'failed' | 'cancelled' | 'online' | 'offline' | 'busy';
+type StatusType = 'waiting' | 'pending' | 'queued' | 'running' | 'paused' | 'completed' | 'failed' | 'cancelled' | 'online' | 'offline' | 'busy';
 
 interface StatusChipProps {
   status: StatusType | string;
@@ -22,6 +22,7 @@ const getStatusColors = (status: string): { bg: string; color: string } => {
     case 'busy':
     case 'processing':
       return { bg: '#DBEAFE', color: '#1E40AF' };
+    case 'waiting':
     case 'pending':
     case 'queued':
     case 'paused':
@@ -18,9 +18,10 @@ import {
   DeleteOutline,
   ContentCopy,
   Replay,
-  AccessTime,
+  ContactPhone,
   LocationOn,
-  StorageOutlined
+  StorageOutlined,
+  Sync
 } from "@mui/icons-material"
 import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query"
 import { jobsApi } from "../../api/jobs"
@@ -43,6 +44,17 @@ export default function JobDetail() {
     }
   })
 
+  // Cached until the job gets new results, so polling it is cheap
+  const { data: fieldStats } = useQuery({
+    queryKey: ["job-field-stats", id],
+    queryFn: () => jobsApi.fieldStats(id!),
+    enabled: !!id,
+    refetchInterval: job?.status === 'running' ? 15000 : false
+  })
+
+  const coverage = (n: number) =>
+    fieldStats && fieldStats.listings > 0 ? `${Math.round(n * 100 / fieldStats.listings)}%` : "--"
+
   const pauseMutation = useMutation({
     mutationFn: jobsApi.pause,
     onSuccess: () => {
@@ -76,6 +88,17 @@ export default function JobDetail() {
     }
   })
 
+  const backfillMutation = useMutation({
+    mutationFn: jobsApi.backfill,
+    onSuccess: (backfill) => {
+      toast.success("Backfill job created")
+      navigate(`/jobs/${backfill.id}`)
+    },
+    onError: () => {
+      toast.error("Failed to create backfill job")
+    }
+  })
+
   const deleteMutation = useMutation({
     mutationFn: jobsApi.delete,
     onSuccess: () => {
@@ -148,6 +171,17 @@ export default function JobDetail() {
           >
             Clone
           </Button>
+          {(job.status === 'completed' || job.status === 'failed' || job.status === 'cancelled') && !!job.progress.partial_places && (
+            <Button
+              variant="outlined"
+              onClick={() => backfillMutation.mutate(id!)}
+              disabled={backfillMutation.isPending}
+              startIcon={<Sync />}
+              sx={{ borderColor: '#000000', color: '#000000' }}
+            >
+              Backfill {job.progress.partial_places} partial
+            </Button>
+          )}
           {job.status === 'running' && (
             <Button
               variant="outlined"
@@ -207,16 +241,21 @@ export default function JobDetail() {
           <StatCard
             title="Total Results"
             value={`${job.progress.scraped_places} / ${job.progress.total_places || "-"}`}
-            subtitle={job.progress.percentage > 0 ? `${Math.round(job.progress.percentage)}% completed` : "Pending"}
+            subtitle={[
+              job.progress.percentage > 0 ? `${Math.round(job.progress.percentage)}% completed` : "Pending",
+              job.progress.partial_places ? `${job.progress.partial_places} partial` : "",
+            ].filter(Boolean).join(" · ")}
             icon={StorageOutlined}
           />
         </Grid>
         <Grid size={{ xs: 12, sm: 4 }}>
           <StatCard
-            title="Duration"
-            value="--"
-            subtitle="Processing time"
-            icon={AccessTime}
+            title="Contact Coverage"
+            value={fieldStats ? `${coverage(fieldStats.coverage.with_email)} email` : "--"}
+            subtitle={fieldStats
+              ? `${coverage(fieldStats.coverage.with_phone)} phone · ${coverage(fieldStats.coverage.with_website)} website`
+              : "Of the listings"}
+            icon={ContactPhone}
           />
         </Grid>
         <Grid size={{ xs: 12, sm: 4 }}>
@@ -248,6 +287,12 @@ export default function JobDetail() {
                   </Typography>
                 </Box>
               </Box>
+              {job.config.google_domain && (
+                <Box sx={{ display: 'flex', justifyContent: 'space-between', mt: 0.5 }}>
+                  <Typography variant="body2" sx={{ color: '#6B7280' }}>Google Domain:</Typography>
+                  <Typography variant="body2" sx={{ fontWeight: 600 }}>{job.config.google_domain}</Typography>
+                </Box>
+              )}
               {job.config.coverage_mode === 'full' && (
                 <Box sx={{ display: 'flex', justifyContent: 'space-between', mt: 0.5 }}>
                   <Typography variant="body2" sx={{ color: '#6B7280' }}>Coverage:</Typography>
@@ -117,6 +117,18 @@ export default function ProxyGate() {
     },
   });
 
+  // Re-enable a source the auto-disable policy turned off
+  const updateSourceMutation = useMutation({
+    mutationFn: ({ id, active }: { id: number; active: boolean }) => proxyApi.updateSource(id, active),
+    onSuccess: () => {
+      toast.success('Source enabled');
+      queryClient.invalidateQueries({ queryKey: ['proxygate-sources'] });
+    },
+    onError: () => {
+      toast.error('Failed to update source');
+    },
+  });
+
   // Cleanup dead proxies mutation
   const cleanupMutation = useMutation({
     mutationFn: proxyApi.cleanupDeadProxies,
@@ -369,15 +381,33 @@ export default function ProxyGate() {
                             </Tooltip>
                           </TableCell>
                           <TableCell>
-                            <Chip
-                              label={source.status === 'ok' ? 'Active' : 'Error'}
-                              size="small"
-                              sx={{
-                                bgcolor: source.status === 'ok' ? '#DCFCE7' : '#FEE2E2',
-                                color: source.status === 'ok' ? '#166534' : '#991B1B',
-                                fontWeight: 600,
-                              }}
-                            />
+                            <Tooltip title={source.disabled_reason || source.last_error || ''}>
+                              <Chip
+                                label={
+                                  source.status === 'ok'
+                                    ? 'Active'
+                                    : source.status === 'disabled'
+                                      ? 'Disabled'
+                                      : 'Error'
+                                }
+                                size="small"
+                                onClick={
+                                  source.status === 'disabled'
+                                    ? () => updateSourceMutation.mutate({ id: source.id, active: true })
+                                    : undefined
+                                }
+                                sx={{
+                                  bgcolor: source.status === 'ok' ? '#DCFCE7' : '#FEE2E2',
+                                  color: source.status === 'ok' ? '#166534' : '#991B1B',
+                                  fontWeight: 600,
+                                }}
+                              />
+                            </Tooltip>
+                            {source.validated ? (
+                              <Typography variant="caption" sx={{ color: '#6B7280', ml: 1 }}>
+                                {((source.pass_rate ?? 0) * 100).toFixed(1)}% of {source.validated + (source.parse_errors ?? 0)} passed
+                              </Typography>
+                            ) : null}
                           </TableCell>
                           <TableCell>
                             <Typography variant="body2" sx={{ color: '#6B7280' }}>
@@ -1,15 +1,166 @@
-import { Box, Typography, Paper } from '@mui/material';
+import { useEffect, useState } from 'react';
+import { AxiosError } from 'axios';
+import { toast } from 'sonner';
+import {
+  Box,
+  Typography,
+  Paper,
+  Grid,
+  TextField,
+  Button,
+  Checkbox,
+  FormControlLabel,
+  Alert,
+  Stack,
+} from '@mui/material';
+import { settingsApi } from '@/api/settings';
+import type { JobDefaults, JobDefaultsConfig } from '@/api/types';
+
+interface FieldError {
+  field: string;
+  message: string;
+}
+
+const numberFields: { key: keyof JobDefaultsConfig; label: string }[] = [
+  { key: 'depth', label: 'Depth' },
+  { key: 'radius', label: 'Radius (meters)' },
+  { key: 'zoom', label: 'Zoom' },
+  { key: 'max_time', label: 'Max time (seconds)' },
+  { key: 'priority', label: 'Priority' },
+];
 
 export default function Settings() {
+  const [defaults, setDefaults] = useState<JobDefaults | null>(null);
+  const [config, setConfig] = useState<JobDefaultsConfig>({});
+  const [errors, setErrors] = useState<FieldError[]>([]);
+  const [unavailable, setUnavailable] = useState(false);
+  const [saving, setSaving] = useState(false);
+
+  useEffect(() => {
+    settingsApi
+      .get()
+      .then(({ job_defaults }) => {
+        setDefaults(job_defaults);
+        setConfig(job_defaults.config ?? {});
+      })
+      .catch(() => setUnavailable(true));
+  }, []);
+
+  const setNumber = (key: keyof JobDefaultsConfig, value: string) => {
+    setConfig((c) => ({ ...c, [key]: value === '' ? undefined : Number(value) }));
+  };
+
+  const fieldError = (key: string) => errors.find((e) => e.field === key)?.message;
+
+  const save = async () => {
+    setSaving(true);
+    setErrors([]);
+    try {
+      const saved = await settingsApi.updateJobDefaults(config);
+      setDefaults(saved);
+      toast.success('Job defaults saved');
+    } catch (err) {
+      if (err instanceof AxiosError) {
+        setErrors(err.response?.data?.errors ?? []);
+        toast.error(err.response?.data?.message || 'Failed to save job defaults');
+      } else {
+        toast.error('An unexpected error occurred');
+      }
+    } finally {
+      setSaving(false);
+    }
+  };
+
   return (
     <Box sx={{ p: { xs: 2, md: 3 }, bgcolor: '#F9FAFB', minHeight: '100%' }}>
       <Typography variant="h4" sx={{ fontWeight: 700, color: '#000000', mb: 3 }}>
         Settings
       </Typography>
       <Paper sx={{ p: 3, border: '2px solid #000000', borderRadius: '16px' }}>
-        <Typography variant="body1" sx={{ color: '#6B7280' }}>
-          Settings page - coming soon
+        <Typography variant="h6" sx={{ fontWeight: 700, mb: 1 }}>
+          Job defaults
+        </Typography>
+        <Typography variant="body2" sx={{ color: '#6B7280', mb: 3 }}>
+          Applied to every new job, bulk uploads included, for the fields it leaves unset. Leave a
+          field empty to keep the built-in default.
         </Typography>
+
+        {unavailable ? (
+          <Alert severity="info">Job defaults need the PostgreSQL database.</Alert>
+        ) : (
+          <Stack spacing={3}>
+            <Grid container spacing={2}>
+              <Grid size={{ xs: 12, md: 4 }}>
+                <TextField
+                  label="Language"
+                  placeholder="en"
+                  fullWidth
+                  value={config.lang ?? ''}
+                  onChange={(e) => setConfig((c) => ({ ...c, lang: e.target.value || undefined }))}
+                  error={!!fieldError('lang')}
+                  helperText={fieldError('lang')}
+                />
+              </Grid>
+              <Grid size={{ xs: 12, md: 4 }}>
+                <TextField
+                  label="Google domain"
+                  placeholder="google.com or auto"
+                  fullWidth
+                  value={config.google_domain ?? ''}
+                  onChange={(e) => setConfig((c) => ({ ...c, google_domain: e.target.value || undefined }))}
+                  error={!!fieldError('google_domain')}
+                  helperText={fieldError('google_domain')}
+                />
+              </Grid>
+              {numberFields.map(({ key, label }) => (
+                <Grid key={key} size={{ xs: 12, md: 4 }}>
+                  <TextField
+                    label={label}
+                    type="number"
+                    fullWidth
+                    value={config[key] ?? ''}
+                    onChange={(e) => setNumber(key, e.target.value)}
+                    error={!!fieldError(key)}
+                    helperText={fieldError(key)}
+                  />
+                </Grid>
+              ))}
+            </Grid>
+
+            <Box>
+              <FormControlLabel
+                control={
+                  <Checkbox
+                    checked={!!config.extract_email}
+                    onChange={(e) => setConfig((c) => ({ ...c, extract_email: e.target.checked }))}
+                  />
+                }
+                label="Extract emails"
+              />
+              <FormControlLabel
+                control={
+                  <Checkbox
+                    checked={!!config.fast_mode}
+                    onChange={(e) => setConfig((c) => ({ ...c, fast_mode: e.target.checked }))}
+                  />
+                }
+                label="Fast mode"
+              />
+            </Box>
+
+            <Stack direction="row" spacing={2} sx={{ alignItems: 'center' }}>
+              <Button variant="contained" onClick={save} disabled={saving}>
+                Save defaults
+              </Button>
+              {defaults?.updated_at && (
+                <Typography variant="body2" sx={{ color: '#6B7280' }}>
+                  Last changed {new Date(defaults.updated_at).toLocaleString()}
+                  {defaults.updated_by ? ` by ${defaults.updated_by}` : ''}
+                </Typography>
+              )}
+            </Stack>
+          </Stack>
+        )}
       </Paper>
     </Box>
   );